@@ -0,0 +1,134 @@
+// mcp-record is a command-line tool that records an MCP session over stdio.
+// It sits between an MCP client and a server command, passing every message
+// through unchanged and writing each client request with the server's
+// response to a session file that the golden-file protocol tests replay.
+//
+//	mcp-record -out internal/mcp/testdata/sessions/search.jsonl -- ./server -mode=stdio
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"lerian-mcp-memory/internal/replay"
+)
+
+// maxMessageBytes bounds one JSON-RPC message
+const maxMessageBytes = 16 << 20
+
+func main() {
+	var (
+		out       = flag.String("out", "session.jsonl", "Session file to write")
+		appendOut = flag.Bool("append", false, "Append to the session file instead of replacing it")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: mcp-record [-out session.jsonl] [-append] -- <server command> [args...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	code, err := record(*out, *appendOut, flag.Args())
+	if err != nil {
+		log.Printf("mcp-record: %v", err)
+		if code == 0 {
+			code = 1
+		}
+	}
+	os.Exit(code)
+}
+
+// record runs the server command, relaying stdio through a recorder, and
+// returns the server's exit code
+func record(path string, appendOut bool, command []string) (int, error) {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendOut {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(path, flags, 0o600) // #nosec G304 -- path is the operator's choice
+	if err != nil {
+		return 1, fmt.Errorf("failed to open session file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	recorder := replay.NewRecorder(file)
+
+	cmd := exec.Command(command[0], command[1:]...) // #nosec G204 -- running the given server is the tool's purpose
+	cmd.Stderr = os.Stderr
+	serverIn, err := cmd.StdinPipe()
+	if err != nil {
+		return 1, err
+	}
+	serverOut, err := cmd.StdoutPipe()
+	if err != nil {
+		return 1, err
+	}
+	if err := cmd.Start(); err != nil {
+		return 1, fmt.Errorf("failed to start server: %w", err)
+	}
+
+	// Forward interrupts so the server shuts down cleanly
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		for sig := range signals {
+			_ = cmd.Process.Signal(sig)
+		}
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		relay(serverOut, os.Stdout, recorder.ServerMessage)
+	}()
+	relay(os.Stdin, serverIn, recorder.ClientMessage)
+	_ = serverIn.Close()
+	wg.Wait()
+
+	err = cmd.Wait()
+	signal.Stop(signals)
+	close(signals)
+	if pending := recorder.Pending(); pending > 0 {
+		log.Printf("mcp-record: %d requests got no response and were not recorded", pending)
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	return 0, err
+}
+
+// relay copies newline-delimited messages from src to dst, handing each to
+// observe. Recording failures are logged; the session itself goes on.
+func relay(src io.Reader, dst io.Writer, observe func([]byte) error) {
+	scanner := bufio.NewScanner(src)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageBytes)
+	for scanner.Scan() {
+		// Copy the line: appending the newline must not reach into the scanner's buffer
+		line := make([]byte, len(scanner.Bytes())+1)
+		copy(line, scanner.Bytes())
+		line[len(line)-1] = '\n'
+		if err := observe(line[:len(line)-1]); err != nil {
+			log.Printf("mcp-record: failed to record message: %v", err)
+		}
+		if _, err := dst.Write(line); err != nil {
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Printf("mcp-record: %v", err)
+	}
+}
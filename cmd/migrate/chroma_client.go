@@ -0,0 +1,276 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"lerian-mcp-memory/internal/retry"
+	"lerian-mcp-memory/pkg/types"
+)
+
+const (
+	// chromaPageSize is the number of records fetched per request
+	chromaPageSize = 500
+
+	// chromaRequestTimeout bounds a single request to the Chroma server
+	chromaRequestTimeout = 60 * time.Second
+
+	defaultChromaTenant     = "default_tenant"
+	defaultChromaDatabase   = "default_database"
+	defaultChromaCollection = "claude_memory"
+)
+
+// chromaClient reads collections from a Chroma server over its v2 HTTP API.
+// Connections are pooled across requests, and transient failures (network
+// errors and 5xx responses) are retried with backoff. Every request is a
+// read, so retrying is always safe.
+type chromaClient struct {
+	baseURL  string
+	tenant   string
+	database string
+	token    string
+	http     *http.Client
+	retrier  *retry.Retrier
+}
+
+// chromaCollection is a collection as described by the Chroma API
+type chromaCollection struct {
+	ID        string                 `json:"id"`
+	Name      string                 `json:"name"`
+	Dimension *int                   `json:"dimension"`
+	Metadata  map[string]interface{} `json:"metadata"`
+}
+
+// chromaGetResult is a page of records returned by the collection get endpoint
+type chromaGetResult struct {
+	IDs        []string                 `json:"ids"`
+	Documents  []*string                `json:"documents"`
+	Metadatas  []map[string]interface{} `json:"metadatas"`
+	Embeddings [][]float64              `json:"embeddings"`
+}
+
+// chromaStatusError is a non-2xx response from the Chroma server
+type chromaStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *chromaStatusError) Error() string {
+	return fmt.Sprintf("chroma returned %d: %s", e.StatusCode, e.Body)
+}
+
+// newChromaClient creates a client for the Chroma server at baseURL, scoped to a tenant and database
+func newChromaClient(baseURL, tenant, database, token string, retryAttempts int) *chromaClient {
+	if tenant == "" {
+		tenant = defaultChromaTenant
+	}
+	if database == "" {
+		database = defaultChromaDatabase
+	}
+	if retryAttempts <= 0 {
+		retryAttempts = 3
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 16
+	transport.MaxIdleConnsPerHost = 16
+	transport.IdleConnTimeout = 90 * time.Second
+
+	retryConfig := retry.ExponentialBackoff(retryAttempts)
+	retryConfig.MaxDelay = 10 * time.Second
+
+	return &chromaClient{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		tenant:   tenant,
+		database: database,
+		token:    token,
+		http:     &http.Client{Transport: transport, Timeout: chromaRequestTimeout},
+		retrier:  retry.New(retryConfig),
+	}
+}
+
+// collectionPath returns the API path for collections in the client's tenant and database
+func (c *chromaClient) collectionPath(parts ...string) string {
+	segments := []string{"api", "v2", "tenants", url.PathEscape(c.tenant), "databases", url.PathEscape(c.database), "collections"}
+	for _, part := range parts {
+		segments = append(segments, url.PathEscape(part))
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// getCollection looks up a collection by name
+func (c *chromaClient) getCollection(ctx context.Context, name string) (*chromaCollection, error) {
+	var collection chromaCollection
+	if err := c.do(ctx, http.MethodGet, c.collectionPath(name), nil, &collection); err != nil {
+		return nil, fmt.Errorf("failed to get collection %q in %s/%s: %w", name, c.tenant, c.database, err)
+	}
+	return &collection, nil
+}
+
+// getRecords fetches one page of records with their documents, metadata and embeddings
+func (c *chromaClient) getRecords(ctx context.Context, collectionID string, limit, offset int) (*chromaGetResult, error) {
+	body := map[string]interface{}{
+		"limit":   limit,
+		"offset":  offset,
+		"include": []string{"documents", "metadatas", "embeddings"},
+	}
+	var result chromaGetResult
+	if err := c.do(ctx, http.MethodPost, c.collectionPath(collectionID, "get"), body, &result); err != nil {
+		return nil, fmt.Errorf("failed to get records at offset %d: %w", offset, err)
+	}
+	return &result, nil
+}
+
+// readCollection reads every record of a collection as conversation chunks
+func (c *chromaClient) readCollection(ctx context.Context, name string) ([]types.ConversationChunk, error) {
+	collection, err := c.getCollection(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make([]types.ConversationChunk, 0)
+	for offset := 0; ; offset += chromaPageSize {
+		page, err := c.getRecords(ctx, collection.ID, chromaPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		for i := range page.IDs {
+			chunks = append(chunks, chromaRecordToChunk(page, i))
+		}
+		if len(page.IDs) < chromaPageSize {
+			break
+		}
+	}
+	return chunks, nil
+}
+
+// do sends a request, retrying network errors and 5xx responses
+func (c *chromaClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	result := c.retrier.Do(ctx, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(payload))
+		if err != nil {
+			return &retry.PermanentError{Err: err}
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return &retry.TemporaryError{Err: err}
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode >= 300 {
+			respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+			statusErr := &chromaStatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(respBody))}
+			if resp.StatusCode >= 500 {
+				return &retry.TemporaryError{Err: statusErr}
+			}
+			return &retry.PermanentError{Err: statusErr}
+		}
+
+		if out == nil {
+			return nil
+		}
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return &retry.PermanentError{Err: fmt.Errorf("failed to decode response: %w", err)}
+		}
+		return nil
+	})
+	return result.Err
+}
+
+// chromaRecordToChunk converts a Chroma record to a conversation chunk. Chunk
+// fields stored as metadata are restored; other metadata is kept as extended metadata.
+func chromaRecordToChunk(page *chromaGetResult, i int) types.ConversationChunk {
+	chunk := types.ConversationChunk{
+		ID:            page.IDs[i],
+		Type:          types.ChunkTypeDiscussion,
+		Timestamp:     time.Now().UTC(),
+		RelatedChunks: []string{},
+		Metadata: types.ChunkMetadata{
+			Outcome:    types.OutcomeInProgress,
+			Difficulty: types.DifficultySimple,
+		},
+	}
+	if i < len(page.Documents) && page.Documents[i] != nil {
+		chunk.Content = *page.Documents[i]
+	}
+	if i < len(page.Embeddings) {
+		chunk.Embeddings = page.Embeddings[i]
+	}
+	if i >= len(page.Metadatas) {
+		return chunk
+	}
+
+	for key, value := range page.Metadatas[i] {
+		s, _ := value.(string)
+		switch key {
+		case "session_id":
+			chunk.SessionID = s
+		case "type":
+			if chunkType := types.ChunkType(s); chunkType.Valid() {
+				chunk.Type = chunkType
+			}
+		case "summary":
+			chunk.Summary = s
+		case "timestamp":
+			if t, err := time.Parse(time.RFC3339, s); err == nil {
+				chunk.Timestamp = t
+			}
+		case "repository":
+			chunk.Metadata.Repository = s
+		case "branch":
+			chunk.Metadata.Branch = s
+		case "outcome":
+			chunk.Metadata.Outcome = types.Outcome(s)
+		case "difficulty":
+			chunk.Metadata.Difficulty = types.Difficulty(s)
+		case "tags":
+			chunk.Metadata.Tags = splitChromaList(s)
+		case "files_modified":
+			chunk.Metadata.FilesModified = splitChromaList(s)
+		case "tools_used":
+			chunk.Metadata.ToolsUsed = splitChromaList(s)
+		default:
+			if chunk.Metadata.ExtendedMetadata == nil {
+				chunk.Metadata.ExtendedMetadata = make(map[string]interface{})
+			}
+			chunk.Metadata.ExtendedMetadata[key] = value
+		}
+	}
+	return chunk
+}
+
+// splitChromaList decodes a list stored in Chroma metadata, which only holds
+// scalars, as either a JSON array or a comma-separated string
+func splitChromaList(s string) []string {
+	var list []string
+	if err := json.Unmarshal([]byte(s), &list); err == nil {
+		return list
+	}
+	list = make([]string, 0)
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"lerian-mcp-memory/pkg/types"
+)
+
+func TestChromaClientReadCollection(t *testing.T) {
+	var failures atomic.Int32
+	records := chromaPageSize + 2
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v2/tenants/acme/databases/memory/collections/claude_memory":
+			// The first lookup hits a transient failure
+			if failures.Add(1) == 1 {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"id": "col-1", "name": "claude_memory"})
+		case "/api/v2/tenants/acme/databases/memory/collections/col-1/get":
+			var body struct {
+				Limit  int `json:"limit"`
+				Offset int `json:"offset"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			page := chromaGetResult{}
+			for i := body.Offset; i < min(records, body.Offset+body.Limit); i++ {
+				doc := fmt.Sprintf("document %d", i)
+				page.IDs = append(page.IDs, fmt.Sprintf("id-%d", i))
+				page.Documents = append(page.Documents, &doc)
+				page.Embeddings = append(page.Embeddings, []float64{0.1, 0.2})
+				page.Metadatas = append(page.Metadatas, map[string]interface{}{
+					"session_id": "s1",
+					"type":       "solution",
+					"repository": "github.com/acme/api",
+					"tags":       `["go","retry"]`,
+					"custom":     "kept",
+				})
+			}
+			_ = json.NewEncoder(w).Encode(page)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := newChromaClient(server.URL, "acme", "memory", "secret", 3)
+	chunks, err := client.readCollection(context.Background(), "claude_memory")
+	if err != nil {
+		t.Fatalf("readCollection: %v", err)
+	}
+	if len(chunks) != records {
+		t.Fatalf("got %d chunks, want %d", len(chunks), records)
+	}
+
+	chunk := chunks[records-1]
+	if chunk.ID != fmt.Sprintf("id-%d", records-1) || chunk.Content != fmt.Sprintf("document %d", records-1) {
+		t.Errorf("unexpected chunk %+v", chunk)
+	}
+	if chunk.Type != types.ChunkTypeSolution || chunk.SessionID != "s1" || chunk.Metadata.Repository != "github.com/acme/api" {
+		t.Errorf("chunk fields not restored: %+v", chunk)
+	}
+	if len(chunk.Metadata.Tags) != 2 || chunk.Metadata.ExtendedMetadata["custom"] != "kept" {
+		t.Errorf("metadata not restored: %+v", chunk.Metadata)
+	}
+}
+
+func TestChromaClientDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "collection not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := newChromaClient(server.URL, "", "", "", 3)
+	if _, err := client.getCollection(context.Background(), "missing"); err == nil {
+		t.Fatal("expected an error for a missing collection")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("got %d requests, want 1", calls.Load())
+	}
+}
+
+func TestSplitChromaList(t *testing.T) {
+	if got := splitChromaList(`["a","b"]`); len(got) != 2 || got[1] != "b" {
+		t.Errorf("JSON list = %v", got)
+	}
+	if got := splitChromaList("a, b ,,c"); len(got) != 3 || got[2] != "c" {
+		t.Errorf("comma list = %v", got)
+	}
+}
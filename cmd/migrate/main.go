@@ -44,12 +44,20 @@ type MigrationTool struct {
 	validateOnly bool
 	isJSONExport bool
 	stats        *MigrationStats
+
+	// chroma reads from a running Chroma server instead of inputPath
+	chroma           *chromaClient
+	chromaCollection string
 }
 
 func main() {
 	var (
 		chromaDBPath = flag.String("chroma-path", "", "Path to ChromaDB data directory")
 		chromaExport = flag.String("chroma-export", "", "Path to ChromaDB JSON export file")
+		chromaURL    = flag.String("chroma-url", os.Getenv("CHROMA_URL"), "URL of a running Chroma server to read from")
+		chromaTenant = flag.String("chroma-tenant", envOrDefault("CHROMA_TENANT", defaultChromaTenant), "Chroma tenant")
+		chromaDB     = flag.String("chroma-database", envOrDefault("CHROMA_DATABASE", defaultChromaDatabase), "Chroma database")
+		chromaColl   = flag.String("chroma-collection", envOrDefault("CHROMA_COLLECTION", defaultChromaCollection), "Chroma collection to migrate")
 		_            = flag.String("config", "configs/dev/config.yaml", "Path to configuration file (unused - uses env vars)")
 		backupDir    = flag.String("backup-dir", "./migration-backup", "Directory for migration backups")
 		dryRun       = flag.Bool("dry-run", false, "Perform dry run without writing to Qdrant")
@@ -59,14 +67,20 @@ func main() {
 	)
 	flag.Parse()
 
-	if *chromaDBPath == "" && *chromaExport == "" {
-		fmt.Fprintf(os.Stderr, "Error: Either -chroma-path or -chroma-export is required\n")
+	sources := 0
+	for _, source := range []string{*chromaDBPath, *chromaExport, *chromaURL} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources == 0 {
+		fmt.Fprintf(os.Stderr, "Error: One of -chroma-path, -chroma-export or -chroma-url is required\n")
 		flag.Usage()
 		os.Exit(1)
 	}
 
-	if *chromaDBPath != "" && *chromaExport != "" {
-		fmt.Fprintf(os.Stderr, "Error: Cannot specify both -chroma-path and -chroma-export\n")
+	if sources > 1 {
+		fmt.Fprintf(os.Stderr, "Error: Only one of -chroma-path, -chroma-export and -chroma-url can be specified\n")
 		flag.Usage()
 		os.Exit(1)
 	}
@@ -88,7 +102,9 @@ func main() {
 		inputPath = *chromaExport
 	}
 
-	if _, err := os.Stat(inputPath); os.IsNotExist(err) {
+	if *chromaURL != "" {
+		inputPath = *chromaURL
+	} else if _, err := os.Stat(inputPath); os.IsNotExist(err) {
 		log.Fatalf("Input path does not exist: %s", inputPath)
 	}
 
@@ -97,6 +113,11 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to create migration tool: %v", err)
 	}
+	if *chromaURL != "" {
+		// The token is read from the environment only, so it never shows up in process listings
+		migrator.chroma = newChromaClient(*chromaURL, *chromaTenant, *chromaDB, os.Getenv("CHROMA_TOKEN"), cfg.Qdrant.RetryAttempts)
+		migrator.chromaCollection = *chromaColl
+	}
 
 	// Run migration
 	if err := migrator.Migrate(context.Background(), *force); err != nil {
@@ -256,7 +277,17 @@ func (mt *MigrationTool) readJSONExport() ([]types.ConversationChunk, error) {
 
 // readDirectChromaDB reads data directly from ChromaDB
 func (mt *MigrationTool) readDirectChromaDB(ctx context.Context) ([]types.ConversationChunk, error) {
-	_ = ctx // unused in placeholder implementation
+	if mt.chroma != nil {
+		log.Printf("Reading ChromaDB collection: url=%s, tenant=%s, database=%s, collection=%s",
+			mt.inputPath, mt.chroma.tenant, mt.chroma.database, mt.chromaCollection)
+		chunks, err := mt.chroma.readCollection(ctx, mt.chromaCollection)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Loaded chunks from ChromaDB: count=%d", len(chunks))
+		return chunks, nil
+	}
+
 	log.Printf("Reading ChromaDB data: path=%s", mt.inputPath)
 
 	// NOTE: This is a placeholder for ChromaDB reading logic
@@ -274,7 +305,7 @@ func (mt *MigrationTool) readDirectChromaDB(ctx context.Context) ([]types.Conver
 	log.Printf("2. Implement readDirectChromaDB to query existing ChromaDB")
 	log.Printf("3. Convert ChromaDB documents to ConversationChunk format")
 	log.Printf("")
-	log.Printf("RECOMMENDED: Use the JSON export approach instead:")
+	log.Printf("RECOMMENDED: Read from a running Chroma server with -chroma-url, or use the JSON export approach:")
 	log.Printf("1. Run: python scripts/export_chromadb.py /path/to/chromadb")
 	log.Printf("2. Then: go run cmd/migrate/main.go -chroma-export=chromadb_export.json")
 
@@ -478,3 +509,11 @@ func minInt(a, b int) int {
 	}
 	return b
 }
+
+// envOrDefault returns the environment variable, or fallback when it is unset
+func envOrDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -1,15 +1,21 @@
 // openapi is a command-line tool for working with OpenAPI specifications,
-// providing validation, documentation serving, and code generation capabilities.
+// providing validation, documentation serving, and generation of the
+// TypeScript client from the server's tool manifest.
 package main
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"time"
 
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/mcp"
+	"lerian-mcp-memory/internal/tsgen"
+
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/gorilla/mux"
 	yaml "gopkg.in/yaml.v3"
@@ -21,7 +27,7 @@ func main() {
 		fmt.Println("Commands:")
 		fmt.Println("  serve    - Serve OpenAPI documentation")
 		fmt.Println("  validate - Validate OpenAPI specification")
-		fmt.Println("  generate - Generate code from OpenAPI spec")
+		fmt.Println("  generate - Generate the TypeScript client from the tool manifest")
 		os.Exit(1)
 	}
 
@@ -33,7 +39,7 @@ func main() {
 	case "validate":
 		validateSpec()
 	case "generate":
-		generateCode()
+		generateCode(os.Args[2:])
 	default:
 		fmt.Printf("Unknown command: %s\n", command)
 		os.Exit(1)
@@ -126,14 +132,24 @@ func validateSpec() {
 	fmt.Printf("- Operations: %d\n", countOperations(doc))
 }
 
-func generateCode() {
-	fmt.Println("Code generation from OpenAPI spec")
-	fmt.Println("This would generate:")
-	fmt.Println("- Client SDKs (Go, TypeScript, Python)")
-	fmt.Println("- Server stubs")
-	fmt.Println("- Model definitions")
-	fmt.Println("- Request/Response validators")
-	fmt.Println("\nNote: Actual code generation requires additional tooling like openapi-generator")
+func generateCode(args []string) {
+	defaults := tsgen.DefaultOptions()
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	out := flags.String("out", "clients/typescript", "Directory to write the TypeScript client to")
+	name := flags.String("package", defaults.PackageName, "npm package name")
+	version := flags.String("version", defaults.Version, "npm package version")
+	_ = flags.Parse(args)
+
+	tools, err := mcp.ConsolidatedToolManifest(config.DefaultConfig())
+	if err != nil {
+		fmt.Printf("Error building tool manifest: %v\n", err)
+		os.Exit(1)
+	}
+	if err := tsgen.Write(tools, tsgen.Options{PackageName: *name, Version: *version}, *out); err != nil {
+		fmt.Printf("Error generating client: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ Generated %s %s with %d tools in %s\n", *name, *version, len(tools), *out)
 }
 
 func loadSpec() (*openapi3.T, error) {
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"lerian-mcp-memory/internal/auth"
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/security"
+)
+
+// apiKeyHeader carries an API key; keys are also accepted as bearer tokens
+const apiKeyHeader = "X-API-Key"
+
+// restEndpointTools are the tools whose data the REST endpoints serve, so a
+// key reaches an endpoint only if it may call the matching tool
+var restEndpointTools = map[string]string{
+	"/timeline":       "memory_timeline",
+	"/api/v1/context": "memory_read",
+}
+
+// apiKeyGuard authenticates requests to the MCP endpoints with API keys. The
+// key travels with the request so the memory server holds tool calls to the
+// tools it allows. Without a key, requests are left to OAuth when it is
+// enabled and refused otherwise. Keys sent to admin endpoints are checked
+// too, and requests without one are left to the endpoint (see adminTenant).
+type apiKeyGuard struct {
+	keys  *auth.Store
+	oauth bool
+	next  http.Handler
+}
+
+// withAPIKeys wraps handler with API key authentication, returning it
+// unchanged when API keys are disabled
+func withAPIKeys(cfg *config.Config, keys *auth.Store, handler http.Handler) http.Handler {
+	if !cfg.Security.APIKeys.Enabled || keys == nil {
+		return handler
+	}
+	log.Printf("🔑 API key authentication enabled")
+	return &apiKeyGuard{keys: keys, oauth: cfg.Security.OAuth.Enabled, next: handler}
+}
+
+// ServeHTTP checks the API key of requests to MCP and admin endpoints
+func (g *apiKeyGuard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	admin := false
+	switch endpointForPath(r.URL.Path) {
+	case security.EndpointMCP, security.EndpointSSE, security.EndpointWS:
+	case security.EndpointAdmin:
+		admin = true
+	default:
+		g.next.ServeHTTP(w, r)
+		return
+	}
+	if r.Method == methodOptions || strings.HasPrefix(r.URL.Path, security.ProtectedResourcePath) {
+		g.next.ServeHTTP(w, r)
+		return
+	}
+
+	secret := apiKeyFromRequest(r)
+	if secret == "" {
+		if g.oauth || admin {
+			g.next.ServeHTTP(w, r)
+			return
+		}
+		rejectRequest(w, r, http.StatusUnauthorized, "api_key_required", "an API key is required in the "+apiKeyHeader+" header")
+		return
+	}
+
+	key, err := g.keys.Authenticate(secret)
+	if err != nil {
+		log.Printf("Rejected API key from %s: %v", r.RemoteAddr, err)
+		rejectRequest(w, r, http.StatusUnauthorized, "invalid_api_key", err.Error())
+		return
+	}
+	if tool, ok := restEndpointTools[r.URL.Path]; ok && !key.Allows(tool) {
+		rejectRequest(w, r, http.StatusForbidden, "tool_not_allowed", "the API key does not allow "+tool)
+		return
+	}
+
+	g.next.ServeHTTP(w, r.WithContext(auth.WithKey(r.Context(), key)))
+}
+
+// apiKeyFromRequest returns the API key of a request: the X-API-Key header,
+// or a bearer token that is an API key rather than an OAuth token. WebSocket
+// upgrades may also carry it in the access_token query parameter.
+func apiKeyFromRequest(r *http.Request) string {
+	if key := strings.TrimSpace(r.Header.Get(apiKeyHeader)); key != "" {
+		return key
+	}
+	token, err := security.BearerToken(r)
+	if endpointForPath(r.URL.Path) == security.EndpointWS {
+		token, err = security.UpgradeToken(r)
+	}
+	if err == nil && strings.HasPrefix(token, auth.KeyPrefix) {
+		return token
+	}
+	return ""
+}
+
+// rejectRequest answers a request refused for its credentials with a JSON error
+func rejectRequest(w http.ResponseWriter, r *http.Request, status int, code, description string) {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		origin = defaultLocalOrigin
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	w.Header().Set("Access-Control-Allow-Credentials", "true")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]string{"error": code, "error_description": description}); err != nil {
+		log.Printf("Error encoding API key error: %v", err)
+	}
+}
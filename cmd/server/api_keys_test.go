@@ -0,0 +1,86 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"lerian-mcp-memory/internal/auth"
+	"lerian-mcp-memory/internal/config"
+)
+
+func TestWithAPIKeys(t *testing.T) {
+	keys := auth.NewStore("")
+	readSecret, _, err := keys.Create("reader", []string{"memory_read"}, nil, 0)
+	if err != nil {
+		t.Fatalf("create key: %v", err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.Security.APIKeys.Enabled = true
+
+	var seen *auth.Key
+	handler := withAPIKeys(cfg, keys, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = auth.KeyFrom(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name   string
+		path   string
+		header string
+		value  string
+		want   int
+	}{
+		{"no key", "/mcp", "", "", http.StatusUnauthorized},
+		{"unknown key", "/mcp", apiKeyHeader, auth.KeyPrefix + "nope_secret", http.StatusUnauthorized},
+		{"key header", "/mcp", apiKeyHeader, readSecret, http.StatusOK},
+		{"bearer key", "/sse", "Authorization", "Bearer " + readSecret, http.StatusOK},
+		{"rest endpoint of an allowed tool", "/api/v1/context", apiKeyHeader, readSecret, http.StatusOK},
+		{"rest endpoint of another tool", "/timeline", apiKeyHeader, readSecret, http.StatusForbidden},
+		{"health is public", "/health", "", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen = nil
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+			if tt.want == http.StatusOK && tt.value != "" && (seen == nil || seen.Name != "reader") {
+				t.Errorf("the handler did not get the request's key, got %+v", seen)
+			}
+		})
+	}
+
+	// Browsers cannot set headers on WebSocket upgrades, which may carry the key in the query
+	for path, want := range map[string]int{
+		"/ws?access_token=" + readSecret:  http.StatusOK,
+		"/mcp?access_token=" + readSecret: http.StatusUnauthorized,
+	} {
+		seen = nil
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != want {
+			t.Errorf("%s: got status %d, want %d", path, rec.Code, want)
+		}
+		if want == http.StatusOK && (seen == nil || seen.Name != "reader") {
+			t.Errorf("%s: the handler did not get the query's key, got %+v", path, seen)
+		}
+	}
+
+	// With OAuth enabled, requests without a key are left to it
+	cfg.Security.OAuth.Enabled = true
+	handler = withAPIKeys(cfg, keys, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/mcp", nil))
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("a request without a key was not passed on to OAuth, got %d", rec.Code)
+	}
+}
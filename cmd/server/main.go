@@ -1,43 +1,69 @@
 // server is the main MCP Memory Server binary that provides persistent memory capabilities
-// for AI assistants through multiple transport protocols (stdio, HTTP, WebSocket, SSE).
+// for AI assistants through multiple transport protocols (stdio, HTTP, WebSocket, SSE, gRPC).
 package main
 
 import (
+	"bufio"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"html/template"
+	"io"
+	"lerian-mcp-memory/internal/auth"
 	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/grpctransport"
 	"lerian-mcp-memory/internal/mcp"
+	"lerian-mcp-memory/internal/monitoring"
+	"lerian-mcp-memory/internal/security"
+	"lerian-mcp-memory/internal/tenancy"
 	mcpwebsocket "lerian-mcp-memory/internal/websocket"
 	"log"
+	"net"
 	"net/http"
+	"os"
 	"os/signal"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/fredcamaral/gomcp-sdk/protocol"
-	"github.com/fredcamaral/gomcp-sdk/server"
 	"github.com/fredcamaral/gomcp-sdk/transport"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"golang.org/x/crypto/acme/autocert"
+	"google.golang.org/grpc"
 )
 
 const (
 	// HTTP method constants
 	methodOptions = "OPTIONS"
 
+	// stdioConnectionID identifies the single stdio client connection
+	stdioConnectionID = "stdio"
+
+	// maxStdioMessageSize bounds a message read from stdin
+	maxStdioMessageSize = 10 << 20
+
+	// shutdownTimeout bounds how long shutdown waits for requests in flight
+	shutdownTimeout = 30 * time.Second
+
 	// Default origins for CORS
 	defaultLocalOrigin = "http://localhost:2001"
 	defaultDevOrigin   = "http://localhost:3000"
+
+	// signedRequestHeaders are the request headers allowed by CORS on JSON-RPC endpoints
+	signedRequestHeaders = "Authorization, Content-Type, X-API-Key, X-CSRF-Token, " + security.HeaderTimestamp + ", " + security.HeaderNonce + ", " + security.HeaderSignature
 )
 
 func main() {
 	// Parse command line flags
 	var (
-		mode = flag.String("mode", "stdio", "Server mode: stdio or http")
+		mode = flag.String("mode", "", "Server mode: stdio or http (default: the configured transport, stdio)")
 		addr = flag.String("addr", ":9080", "HTTP server address (when mode=http)")
 	)
 	flag.Parse()
@@ -47,6 +73,9 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	if *mode == "" {
+		*mode = cfg.Server.Transport
+	}
 
 	// Create memory server
 	memoryServer, err := mcp.NewMemoryServer(cfg)
@@ -60,116 +89,429 @@ func main() {
 		log.Fatalf("Failed to start memory server: %v", err)
 	}
 
-	// Get the underlying MCP server
-	mcpServer := memoryServer.GetMCPServer()
-
-	// Set up graceful shutdown
-	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	// SIGINT and SIGTERM stop serving: Shutdown refuses new requests, lets
+	// those in flight finish and then closes the transports. Requests are
+	// served with ctx, which is only cancelled once they drained, so a
+	// signal does not abort tool calls such as bulk imports midway.
+	stopCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	switch *mode {
 	case "stdio":
 		log.Printf("🚀 Starting MCP Memory Server in stdio mode")
-		// Set up stdio transport for MCP protocol
-		stdioTransport := transport.NewStdioTransport()
-		mcpServer.SetTransport(stdioTransport)
-
-		// Start the MCP server
-		if err := mcpServer.Start(ctx); err != nil {
-			if !errors.Is(err, context.Canceled) {
-				cancel()
+		// Serve MCP over stdio through the memory server's middleware chain.
+		// Responses and notifications share stdout, so writes are serialized.
+		stdout := &syncWriter{w: os.Stdout}
+		memoryServer.AddNotificationSender(stdout.notify)
+		stdioCtx := mcp.WithConnectionID(mcp.WithNotificationSender(ctx, stdout.notify), stdioConnectionID)
+		stdioCtx = mcp.WithRequestSender(stdioCtx, stdout.request)
+		// Client responses to server requests (sampling) and batches are
+		// taken off stdin before the transport, which serves one request at a time.
+		stdin := filterClientMessages(stdioCtx, os.Stdin, memoryServer, stdioConnectionID, stdout)
+		stdioTransport := transport.NewStdioTransportWithIO(stdin, stdout)
+		served := make(chan error, 1)
+		go func() {
+			served <- stdioTransport.Start(stdioCtx, memoryServer)
+		}()
+		select {
+		case err := <-served:
+			if err != nil && !errors.Is(err, context.Canceled) {
 				log.Printf("MCP server failed: %v", err)
-				return
 			}
+		case <-stopCtx.Done():
 		}
 
 	case "http":
 		log.Printf("🚀 Starting MCP Memory Server in HTTP mode on %s", *addr)
 		log.Printf("📡 Ready to receive requests from mcp-proxy.js")
 		// Set up HTTP server for MCP-over-HTTP
-		if err := startHTTPServer(ctx, mcpServer, *addr); err != nil {
-			if !errors.Is(err, context.Canceled) {
-				cancel()
-				log.Printf("HTTP server failed: %v", err)
-				return
-			}
+		if err := startHTTPServer(ctx, stopCtx, cfg, memoryServer, *addr); err != nil {
+			log.Printf("HTTP server failed: %v", err)
 		}
 
 	default:
-		cancel()
 		log.Printf("Invalid mode: %s. Use 'stdio' or 'http'", *mode)
 		return
 	}
 
-	// Close resources
-	if err := memoryServer.Close(); err != nil {
+	// Drain requests, flush notifications and close transports and storage
+	log.Printf("Shutting down, waiting up to %s for requests in flight", shutdownTimeout)
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelShutdown()
+	if err := memoryServer.Shutdown(shutdownCtx); err != nil {
 		log.Printf("Error during shutdown: %v", err)
 	}
 }
 
-func startHTTPServer(ctx context.Context, mcpServer *server.Server, addr string) error {
+// syncWriter serializes writes to the stdio transport's output. The
+// transport writes each response with a single Write, so whole messages
+// from it and from notify never interleave.
+type syncWriter struct {
+	w     io.Writer
+	mutex sync.Mutex
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.w.Write(p)
+}
+
+// notify writes a JSON-RPC notification as one line
+func (s *syncWriter) notify(method string, params interface{}) error {
+	return s.request(&protocol.JSONRPCRequest{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// request writes a server-to-client JSON-RPC request or notification as one line
+func (s *syncWriter) request(req *protocol.JSONRPCRequest) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", req.Method, err)
+	}
+	_, err = s.Write(append(data, '\n'))
+	return err
+}
+
+// batch serves a JSON-RPC batch and writes its response as one line
+func (s *syncWriter) batch(ctx context.Context, memoryServer *mcp.MemoryServer, data []byte) {
+	resp := memoryServer.HandleBatch(ctx, data)
+	if resp == nil {
+		return
+	}
+	encoded, err := json.Marshal(resp)
+	if err != nil {
+		log.Printf("Error encoding batch response: %v", err)
+		return
+	}
+	if _, err := s.Write(append(encoded, '\n')); err != nil {
+		log.Printf("Error writing batch response: %v", err)
+	}
+}
+
+// filterClientMessages passes stdin lines through to the transport, except
+// client responses to server requests, which go to the memory server, and
+// batches, which are served here with their response written to out
+func filterClientMessages(ctx context.Context, in io.Reader, memoryServer *mcp.MemoryServer, connectionID string, out *syncWriter) io.Reader {
+	reader, writer := io.Pipe()
+	go func() {
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxStdioMessageSize)
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			if mcp.IsBatch(line) {
+				// Served concurrently, so tools can still receive sampling responses
+				go out.batch(ctx, memoryServer, append([]byte(nil), line...))
+				continue
+			}
+			if memoryServer.HandleClientMessage(connectionID, line) {
+				continue
+			}
+			if _, err := writer.Write(append(append([]byte(nil), line...), '\n')); err != nil {
+				return
+			}
+		}
+		writer.CloseWithError(scanner.Err())
+	}()
+	return reader
+}
+
+// startHTTPServer serves the HTTP transports with ctx until stop is done or
+// the listener fails. The memory server's Shutdown closes them.
+func startHTTPServer(ctx, stop context.Context, cfg *config.Config, memoryServer *mcp.MemoryServer, addr string) error {
 	// Initialize core components
-	wsHub, _, err := initializeServerComponents(ctx)
+	wsHub := initializeServerComponents(ctx, memoryServer)
+
+	// Setup HTTP routes
+	mux := setupHTTPRoutes(ctx, cfg, memoryServer, wsHub, newReplayGuard(cfg))
+
+	// Hold authenticated clients to their tenant's projects
+	handler := withTenancy(cfg, mux)
+
+	// Require OAuth bearer tokens when authorization is configured
+	handler, err := withOAuth(cfg, memoryServer, handler)
 	if err != nil {
 		return err
 	}
 
-	// Setup HTTP routes
-	mux := setupHTTPRoutes(ctx, mcpServer, wsHub)
+	// Authenticate clients with API keys when they are enabled
+	handler = withAPIKeys(cfg, memoryServer.GetContainer().GetAPIKeys(), handler)
+
+	// Restrict client networks when a policy is configured
+	handler, err = withNetworkPolicy(cfg, handler)
+	if err != nil {
+		return err
+	}
+
+	// Serve MCP over gRPC alongside HTTP when enabled
+	if cfg.Server.GRPC.Enabled {
+		if err := startGRPCServer(ctx, cfg.Server.GRPC, memoryServer); err != nil {
+			return err
+		}
+	}
+
+	// Serve HTTPS when a certificate or autocert domains are configured
+	var tlsConfig *tls.Config
+	if cfg.Server.TLS.Enabled() {
+		var manager *autocert.Manager
+		tlsConfig, manager, err = newHTTPTLS(cfg.Server.TLS)
+		if err != nil {
+			return err
+		}
+		if manager != nil && cfg.Server.TLS.AutocertHTTPAddress != "" {
+			startACMEChallengeServer(manager, cfg.Server.TLS.AutocertHTTPAddress, memoryServer)
+		}
+	}
 
 	// Create and start HTTP server
-	return startAndRunHTTPServer(ctx, mux, addr)
+	return startAndRunHTTPServer(stop, memoryServer, handler, addr, tlsConfig)
 }
 
-// initializeServerComponents initializes WebSocket hub and memory server
-func initializeServerComponents(ctx context.Context) (*mcpwebsocket.Hub, *mcp.MemoryServer, error) {
-	// Create WebSocket hub for real-time updates
-	wsHub := mcpwebsocket.NewHub()
-	go wsHub.Run(ctx)
+// startACMEChallengeServer answers ACME HTTP-01 challenges on addr and
+// redirects other plain HTTP requests to HTTPS
+func startACMEChallengeServer(manager *autocert.Manager, addr string, memoryServer *mcp.MemoryServer) {
+	challengeServer := &http.Server{
+		Addr:              addr,
+		Handler:           manager.HTTPHandler(nil),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	memoryServer.AddShutdownHook(challengeServer.Shutdown)
+	go func() {
+		log.Printf("🔐 ACME challenges served on http://localhost%s", addr)
+		if err := challengeServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("ACME challenge server failed: %v", err)
+		}
+	}()
+}
 
-	// Create memory server instance to access DI container
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to load config for GraphQL: %w", err)
+// withNetworkPolicy wraps handler with the configured IP allowlists and network
+// zones, returning it unchanged when no policy is configured
+func withNetworkPolicy(cfg *config.Config, handler http.Handler) (http.Handler, error) {
+	if !cfg.HasNetworkPolicy() {
+		return handler, nil
 	}
 
-	memoryServer, err := mcp.NewMemoryServer(cfg) //nolint:contextcheck // Constructor doesn't need context, Start() method does
+	var policy *security.NetworkPolicy
+	var err error
+	if cfg.Security.NetworkPolicyFile != "" {
+		policy, err = security.LoadNetworkPolicy(cfg.Security.NetworkPolicyFile)
+	} else {
+		policy, err = security.NewAllowlistPolicy(cfg.Security.IPAllowlist, cfg.Security.AdminIPAllowlist, cfg.Security.TrustProxyHeaders)
+	}
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create memory server: %w", err)
+		return nil, fmt.Errorf("invalid network policy: %w", err)
 	}
+	log.Printf("🛡️ Network policy enabled with %d zone(s)", len(policy.Zones))
 
-	// Initialize memory server
-	if err := memoryServer.Start(ctx); err != nil {
-		return nil, nil, fmt.Errorf("failed to start memory server: %w", err)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		endpoint := endpointForPath(r.URL.Path)
+		if err := policy.Check(r, endpoint); err != nil {
+			log.Printf("Blocked %s %s from %s: %v", r.Method, r.URL.Path, r.RemoteAddr, err)
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		if policy.AdminZone(r) {
+			r = r.WithContext(context.WithValue(r.Context(), adminNetworkKey{}, true))
+		}
+		handler.ServeHTTP(w, r)
+	}), nil
+}
+
+// adminNetworkKey marks requests from a network zone the policy sets apart
+// for the admin endpoints
+type adminNetworkKey struct{}
+
+// fromAdminNetwork reports whether the request served with ctx came from an admin network
+func fromAdminNetwork(ctx context.Context) bool {
+	admin, _ := ctx.Value(adminNetworkKey{}).(bool)
+	return admin
+}
+
+// endpointForPath maps a request path to its network policy endpoint group.
+// OAuth metadata goes with the MCP endpoints its clients discover it from;
+// unknown paths are treated as admin endpoints.
+func endpointForPath(path string) string {
+	if strings.HasPrefix(path, security.ProtectedResourcePath) {
+		return security.EndpointMCP
 	}
+	switch path {
+	case "/mcp", "/timeline", "/api/v1/context":
+		return security.EndpointMCP
+	case "/sse":
+		return security.EndpointSSE
+	case "/ws":
+		return security.EndpointWS
+	case "/health":
+		return security.EndpointHealth
+	default:
+		return security.EndpointAdmin
+	}
+}
+
+// initializeServerComponents initializes the WebSocket hub and attaches it to the
+// memory server that serves MCP requests, so tool calls broadcast their changes
+func initializeServerComponents(ctx context.Context, memoryServer *mcp.MemoryServer) *mcpwebsocket.Hub {
+	// Create WebSocket hub for real-time updates
+	wsHub := mcpwebsocket.NewHub()
+	go wsHub.Run(ctx)
 
 	// Set the WebSocket hub in the memory server for broadcasting
 	memoryServer.SetWebSocketHub(wsHub)
 
-	return wsHub, memoryServer, nil
+	// Close connections cleanly on shutdown, after their last messages
+	memoryServer.AddShutdownHook(wsHub.Shutdown)
+
+	// Serve MCP JSON-RPC over WebSocket connections; each response goes
+	// back on the connection its request arrived on
+	wsHub.SetRPCHandler(func(ctx context.Context, client *mcpwebsocket.Client, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+		return memoryServer.HandleRequest(websocketContext(ctx, client), req)
+	})
+	wsHub.SetBatchHandler(func(ctx context.Context, client *mcpwebsocket.Client, data []byte) interface{} {
+		return memoryServer.HandleBatch(websocketContext(ctx, client), data)
+	})
+	wsHub.SetResponseHandler(func(client *mcpwebsocket.Client, data []byte) bool {
+		return memoryServer.HandleClientMessage(client.ID, data)
+	})
+	wsHub.SetDisconnectHandler(func(client *mcpwebsocket.Client) {
+		memoryServer.CloseConnection(client.ID)
+	})
+
+	return wsHub
+}
+
+// websocketContext routes notifications and server-to-client requests made
+// while serving a request back to the client's connection
+func websocketContext(ctx context.Context, client *mcpwebsocket.Client) context.Context {
+	ctx = mcp.WithConnectionID(mcp.WithNotificationSender(ctx, client.SendNotification), client.ID)
+	if client.APIKey != nil {
+		ctx = auth.WithKey(ctx, client.APIKey)
+	}
+	if client.TokenClaims != nil {
+		ctx = security.WithTokenClaims(ctx, client.TokenClaims)
+	}
+	if client.Tenant != nil {
+		ctx = tenancy.WithTenant(ctx, client.Tenant)
+	}
+	return mcp.WithRequestSender(ctx, client.SendRequest)
+}
+
+// startGRPCServer serves MCP over gRPC on the configured address with ctx.
+// The memory server's Shutdown drains and closes it.
+func startGRPCServer(ctx context.Context, cfg config.GRPCConfig, memoryServer *mcp.MemoryServer) error {
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(maxStdioMessageSize),
+		grpc.MaxSendMsgSize(maxStdioMessageSize),
+	}
+	if cfg.TLSCertFile != "" {
+		creds, err := grpctransport.ServerCredentials(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+	grpcServer := grpctransport.NewServer(opts...)
+
+	grpcServer.SetRPCHandler(func(reqCtx context.Context, conn *grpctransport.Conn, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+		return memoryServer.HandleRequest(grpcContext(reqCtx, conn), req)
+	})
+	grpcServer.SetBatchHandler(func(reqCtx context.Context, conn *grpctransport.Conn, data []byte) interface{} {
+		return memoryServer.HandleBatch(grpcContext(reqCtx, conn), data)
+	})
+	grpcServer.SetResponseHandler(func(conn *grpctransport.Conn, data []byte) bool {
+		return memoryServer.HandleClientMessage(conn.ID, data)
+	})
+	grpcServer.SetDisconnectHandler(func(conn *grpctransport.Conn) {
+		memoryServer.CloseConnection(conn.ID)
+	})
+	memoryServer.AddNotificationSender(grpcServer.BroadcastNotification)
+	memoryServer.AddShutdownHook(grpcServer.Shutdown)
+
+	lis, err := (&net.ListenConfig{}).Listen(ctx, "tcp", cfg.Address)
+	if err != nil {
+		return fmt.Errorf("failed to listen for gRPC on %s: %w", cfg.Address, err)
+	}
+	transportSecurity := "plaintext"
+	switch {
+	case cfg.ClientCAFile != "":
+		transportSecurity = "mTLS"
+	case cfg.TLSCertFile != "":
+		transportSecurity = "TLS"
+	}
+	log.Printf("🛰️ gRPC endpoint (%s): %s", transportSecurity, cfg.Address)
+	go func() {
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Printf("gRPC server failed: %v", err)
+		}
+	}()
+	return nil
+}
+
+// grpcContext routes notifications and server-to-client requests made
+// while serving a request back to the client's gRPC stream. gRPC clients
+// carry no API key or token, so they are served as operators; configuration
+// refuses gRPC under tenant isolation.
+func grpcContext(ctx context.Context, conn *grpctransport.Conn) context.Context {
+	ctx = mcp.WithConnectionID(mcp.WithNotificationSender(ctx, conn.SendNotification), conn.ID)
+	return mcp.WithRequestSender(ctx, conn.SendRequest)
+}
+
+// newReplayGuard returns the signed request verifier, or nil when replay protection is disabled
+func newReplayGuard(cfg *config.Config) *security.ReplayGuard {
+	if !cfg.Security.ReplayProtection {
+		return nil
+	}
+	log.Printf("🔐 Replay protection enabled: JSON-RPC requests must be signed")
+	return security.NewReplayGuard(cfg.Security.SigningSecret, time.Duration(cfg.Security.MaxClockSkew)*time.Second, cfg.Security.NonceCacheSize)
 }
 
 // setupHTTPRoutes configures all HTTP routes and handlers
-func setupHTTPRoutes(ctx context.Context, mcpServer *server.Server, wsHub *mcpwebsocket.Hub) *http.ServeMux {
+func setupHTTPRoutes(ctx context.Context, cfg *config.Config, memoryServer *mcp.MemoryServer, wsHub *mcpwebsocket.Hub, guard *security.ReplayGuard) *http.ServeMux {
 	mux := http.NewServeMux()
 
-	// Setup MCP endpoint
-	setupMCPHandler(mux, mcpServer)
+	// Setup MCP endpoint; requests go through the memory server's middleware chain
+	setupMCPHandler(mux, memoryServer, guard)
 
-	// Setup SSE endpoint
-	setupSSEHandler(mux, mcpServer)
+	// Setup SSE endpoint; sessions that end drop their state in the server
+	broker := newSSEBroker(memoryServer.GetNotifier())
+	broker.onEnd = memoryServer.CloseConnection
+	memoryServer.AddShutdownHook(func(context.Context) error {
+		broker.shutdown()
+		return nil
+	})
+	setupSSEHandler(mux, memoryServer, broker, guard)
 
 	// Setup WebSocket endpoint
-	setupWebSocketHandler(mux, ctx, wsHub)
+	setupWebSocketHandler(mux, ctx, wsHub, guard)
 
 	// Setup health check endpoint
 	setupHealthHandler(mux)
 
+	// Setup Prometheus metrics endpoint
+	setupMetricsHandler(mux, cfg, memoryServer.GetToolMetrics(), memoryServer.GetHealthScores())
+
+	// Setup memory health dashboard
+	setupMemoryHealthHandler(mux, cfg, memoryServer)
+
+	// Setup memory timeline endpoint
+	setupTimelineHandler(mux, memoryServer, guard)
+
+	// Setup editor context endpoint
+	setupEditorContextHandler(mux, memoryServer, guard)
+
 	return mux
 }
 
-// setupMCPHandler configures the MCP-over-HTTP endpoint
-func setupMCPHandler(mux *http.ServeMux, mcpServer *server.Server) {
+// sessionServer keeps client sessions for transports without connections
+type sessionServer interface {
+	Sessions() *mcp.SessionManager
+}
+
+// setupMCPHandler configures the MCP-over-HTTP endpoint. initialize starts
+// a session whose ID comes back in the Mcp-Session-Id header; requests that
+// send it back share the session's state, and DELETE ends it.
+func setupMCPHandler(mux *http.ServeMux, mcpServer transport.RequestHandler, guard *security.ReplayGuard) {
+	sessions, _ := mcpServer.(sessionServer)
 	mux.HandleFunc("/mcp", func(w http.ResponseWriter, r *http.Request) {
 		// Set CORS headers with specific origin to allow credentials
 		origin := r.Header.Get("Origin")
@@ -177,8 +519,9 @@ func setupMCPHandler(mux *http.ServeMux, mcpServer *server.Server) {
 			origin = defaultLocalOrigin
 		}
 		w.Header().Set("Access-Control-Allow-Origin", origin)
-		w.Header().Set("Access-Control-Allow-Methods", "POST, "+methodOptions)
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-CSRF-Token")
+		w.Header().Set("Access-Control-Allow-Methods", "POST, DELETE, "+methodOptions)
+		w.Header().Set("Access-Control-Allow-Headers", mcpSessionHeader+", "+signedRequestHeaders)
+		w.Header().Set("Access-Control-Expose-Headers", mcpSessionHeader)
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 		w.Header().Set("Content-Type", "application/json")
 
@@ -187,20 +530,53 @@ func setupMCPHandler(mux *http.ServeMux, mcpServer *server.Server) {
 			return
 		}
 
+		if r.Method == "DELETE" && sessions != nil {
+			handleMCPSessionDelete(w, r, sessions.Sessions(), guard)
+			return
+		}
+
 		if r.Method != "POST" {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
 
-		// Parse the JSON-RPC request
+		if !verifySignedRequest(w, r, guard) {
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+
+		// Requests are rate limited by client address
+		ctx := mcp.WithClientID(r.Context(), clientHost(r))
+
+		// Parse the JSON-RPC request; a batch is served as a whole
+		batch := mcp.IsBatch(body)
 		var req protocol.JSONRPCRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		if !batch {
+			if err := json.Unmarshal(body, &req); err != nil {
+				http.Error(w, "Invalid JSON", http.StatusBadRequest)
+				return
+			}
+		}
+
+		if sessions != nil {
+			var ok bool
+			if ctx, ok = mcpSessionContext(ctx, w, r, sessions.Sessions(), req.Method); !ok {
+				return
+			}
+		}
+
+		if batch {
+			writeBatchResponse(ctx, w, mcpServer, body)
 			return
 		}
 
 		// Process the request through MCP server
-		resp := mcpServer.HandleRequest(r.Context(), &req)
+		resp := mcpServer.HandleRequest(ctx, &req)
 
 		// Send the response
 		w.Header().Set("Content-Type", "application/json")
@@ -210,8 +586,96 @@ func setupMCPHandler(mux *http.ServeMux, mcpServer *server.Server) {
 	})
 }
 
-// setupSSEHandler configures the Server-Sent Events endpoint
-func setupSSEHandler(mux *http.ServeMux, mcpServer *server.Server) {
+// mcpSessionContext attaches the request's session: initialize opens one,
+// other requests name theirs in the Mcp-Session-Id header. Requests naming
+// an unknown or expired session are rejected, reporting false.
+func mcpSessionContext(ctx context.Context, w http.ResponseWriter, r *http.Request, sessions *mcp.SessionManager, method string) (context.Context, bool) {
+	sessionID := r.Header.Get(mcpSessionHeader)
+	if method == "initialize" {
+		sessionID = sessions.Open("").ID()
+	} else if sessionID == "" {
+		return ctx, true
+	} else if _, ok := sessions.Get(sessionID); !ok {
+		http.Error(w, errSSESessionNotFound.Error(), http.StatusNotFound)
+		return ctx, false
+	}
+	w.Header().Set(mcpSessionHeader, sessionID)
+	return mcp.WithSessionID(ctx, sessionID), true
+}
+
+// handleMCPSessionDelete ends the session named by the Mcp-Session-Id header
+func handleMCPSessionDelete(w http.ResponseWriter, r *http.Request, sessions *mcp.SessionManager, guard *security.ReplayGuard) {
+	if !verifySignedRequest(w, r, guard) {
+		return
+	}
+
+	sessionID := r.Header.Get(mcpSessionHeader)
+	if sessionID == "" {
+		http.Error(w, mcpSessionHeader+" header required", http.StatusBadRequest)
+		return
+	}
+	if !sessions.Close(sessionID) {
+		http.Error(w, errSSESessionNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// batchHandler serves JSON-RPC batches
+type batchHandler interface {
+	HandleBatch(ctx context.Context, data []byte) interface{}
+}
+
+// writeBatchResponse serves a JSON-RPC batch and writes its response. A batch
+// of notifications is accepted without a body.
+func writeBatchResponse(ctx context.Context, w http.ResponseWriter, mcpServer transport.RequestHandler, body []byte) {
+	batcher, ok := mcpServer.(batchHandler)
+	if !ok {
+		http.Error(w, "JSON-RPC batches are not supported", http.StatusBadRequest)
+		return
+	}
+
+	resp := batcher.HandleBatch(ctx, body)
+	if resp == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("Error encoding batch response: %v", err)
+	}
+}
+
+// clientHost returns the address of the client that sent r, without its port
+func clientHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// verifySignedRequest rejects unsigned or replayed requests when replay
+// protection is enabled, reporting whether the request may proceed
+func verifySignedRequest(w http.ResponseWriter, r *http.Request, guard *security.ReplayGuard) bool {
+	if guard == nil {
+		return true
+	}
+	if err := guard.VerifyRequest(r); err != nil {
+		log.Printf("Rejected signed request from %s: %v", r.RemoteAddr, err)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// setupSSEHandler configures the Server-Sent Events endpoint. It follows the
+// MCP streamable HTTP transport: initialize assigns an Mcp-Session-Id, GET
+// opens the session's stream (resumable with Last-Event-ID) and DELETE ends
+// the session.
+func setupSSEHandler(mux *http.ServeMux, mcpServer transport.RequestHandler, broker *sseBroker, guard *security.ReplayGuard) {
 	mux.HandleFunc("/sse", func(w http.ResponseWriter, r *http.Request) {
 		// Handle CORS preflight
 		if r.Method == methodOptions {
@@ -220,48 +684,121 @@ func setupSSEHandler(mux *http.ServeMux, mcpServer *server.Server) {
 				origin = defaultLocalOrigin
 			}
 			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, "+methodOptions)
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Cache-Control, X-CSRF-Token")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, "+methodOptions)
+			w.Header().Set("Access-Control-Allow-Headers", "Cache-Control, Last-Event-ID, "+mcpSessionHeader+", "+signedRequestHeaders)
+			w.Header().Set("Access-Control-Expose-Headers", mcpSessionHeader)
 			w.Header().Set("Access-Control-Allow-Credentials", "true")
 			w.WriteHeader(http.StatusOK)
 			return
 		}
 
-		// Handle POST requests for MCP JSON-RPC
-		if r.Method == "POST" {
-			handleSSEPost(w, r, mcpServer)
-			return
-		}
-
-		// Handle GET requests for SSE stream
-		if r.Method != "GET" {
+		switch r.Method {
+		case "POST":
+			// Handle POST requests for MCP JSON-RPC
+			handleSSEPost(w, r, mcpServer, broker, guard)
+		case "GET":
+			// Handle GET requests for SSE stream
+			handleSSEStream(w, r, broker)
+		case "DELETE":
+			handleSSEDelete(w, r, broker, guard)
+		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
 		}
-
-		handleSSEStream(w, r)
 	})
 }
 
+// sseSessionID returns the session named by the Mcp-Session-Id header, or by
+// the session_id query parameter for EventSource clients that cannot set headers
+func sseSessionID(r *http.Request) string {
+	if sessionID := r.Header.Get(mcpSessionHeader); sessionID != "" {
+		return sessionID
+	}
+	return r.URL.Query().Get("session_id")
+}
+
+// clientMessageHandler takes client responses to server-to-client requests
+type clientMessageHandler interface {
+	HandleClientMessage(connectionID string, data []byte) bool
+}
+
 // handleSSEPost handles POST requests to the SSE endpoint
-func handleSSEPost(w http.ResponseWriter, r *http.Request, mcpServer *server.Server) {
+func handleSSEPost(w http.ResponseWriter, r *http.Request, mcpServer transport.RequestHandler, broker *sseBroker, guard *security.ReplayGuard) {
 	origin := r.Header.Get("Origin")
 	if origin == "" {
 		origin = defaultLocalOrigin
 	}
 	w.Header().Set("Access-Control-Allow-Origin", origin)
 	w.Header().Set("Access-Control-Allow-Credentials", "true")
+	w.Header().Set("Access-Control-Expose-Headers", mcpSessionHeader)
 	w.Header().Set("Content-Type", "application/json")
 
-	// Parse JSON-RPC request
+	if !verifySignedRequest(w, r, guard) {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	// A session's client answers requests the server sent it (sampling) by POSTing the response
+	if sessionID := r.Header.Get(mcpSessionHeader); sessionID != "" {
+		if responder, ok := mcpServer.(clientMessageHandler); ok && responder.HandleClientMessage(sessionID, body) {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+	}
+
+	// Parse JSON-RPC request; a batch is served as a whole within the session
+	batch := mcp.IsBatch(body)
 	var req protocol.JSONRPCRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid JSON-RPC request", http.StatusBadRequest)
+	if !batch {
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, "Invalid JSON-RPC request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// initialize starts a session; later requests may name theirs, and
+	// notifications for them (e.g. progress) go to the session's stream
+	ctx := r.Context()
+	if req.Method == "initialize" {
+		sessionID, err := broker.createSession()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set(mcpSessionHeader, sessionID)
+		ctx = mcp.WithConnectionID(ctx, sessionID)
+	} else if sessionID := r.Header.Get(mcpSessionHeader); sessionID != "" {
+		if err := broker.touchSession(sessionID); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.Header().Set(mcpSessionHeader, sessionID)
+		// Subscriptions of a session that ended are dropped on the next
+		// update, when delivering to it fails
+		ctx = mcp.WithConnectionID(mcp.WithNotificationSender(ctx, broker.sender(sessionID)), sessionID)
+		ctx = mcp.WithRequestSender(ctx, broker.requestSender(sessionID))
+	} else {
+		// Sessionless requests are rate limited by the address they come from
+		ctx = mcp.WithClientID(ctx, clientHost(r))
+	}
+
+	if batch {
+		writeBatchResponse(ctx, w, mcpServer, body)
 		return
 	}
 
 	// Process MCP request
-	resp := mcpServer.HandleRequest(r.Context(), &req)
+	resp := mcpServer.HandleRequest(ctx, &req)
+
+	// Notifications, such as roots list changes, get no response
+	if resp == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
 
 	// Send JSON-RPC response
 	w.WriteHeader(http.StatusOK)
@@ -270,8 +807,46 @@ func handleSSEPost(w http.ResponseWriter, r *http.Request, mcpServer *server.Ser
 	}
 }
 
+// handleSSEDelete ends the session named by the Mcp-Session-Id header
+func handleSSEDelete(w http.ResponseWriter, r *http.Request, broker *sseBroker, guard *security.ReplayGuard) {
+	if !verifySignedRequest(w, r, guard) {
+		return
+	}
+
+	sessionID := r.Header.Get(mcpSessionHeader)
+	if sessionID == "" {
+		http.Error(w, mcpSessionHeader+" header required", http.StatusBadRequest)
+		return
+	}
+	if err := broker.deleteSession(sessionID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // handleSSEStream handles GET requests for SSE streaming
-func handleSSEStream(w http.ResponseWriter, r *http.Request) {
+func handleSSEStream(w http.ResponseWriter, r *http.Request, broker *sseBroker) {
+	// Keep connection alive
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	// Subscribe to server notifications (e.g. resources/updated), replaying
+	// what the client missed since its last event
+	sessionID, replay, events, detach, err := broker.attach(sseSessionID(r), r.Header.Get("Last-Event-ID"))
+	switch {
+	case errors.Is(err, errSSESessionNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer detach()
+
 	// Set SSE headers
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -281,18 +856,16 @@ func handleSSEStream(w http.ResponseWriter, r *http.Request) {
 		origin = defaultLocalOrigin
 	}
 	w.Header().Set("Access-Control-Allow-Origin", origin)
-	w.Header().Set("Access-Control-Allow-Headers", "Cache-Control, X-CSRF-Token")
+	w.Header().Set("Access-Control-Allow-Headers", "Authorization, Cache-Control, Last-Event-ID, "+mcpSessionHeader+", "+apiKeyHeader+", X-CSRF-Token")
+	w.Header().Set("Access-Control-Expose-Headers", mcpSessionHeader)
 	w.Header().Set("Access-Control-Allow-Credentials", "true")
-
-	// Keep connection alive
-	flusher, ok := w.(http.Flusher)
-	if !ok {
-		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
-		return
-	}
+	w.Header().Set(mcpSessionHeader, sessionID)
 
 	// Send initial connection message
-	_, _ = fmt.Fprintf(w, "data: {\"type\":\"connected\",\"server\":\"lerian-mcp-memory\",\"protocols\":[\"json-rpc\",\"sse\"]}\n\n")
+	_, _ = fmt.Fprintf(w, "retry: 3000\ndata: {\"type\":\"connected\",\"server\":\"lerian-mcp-memory\",\"session_id\":%q,\"protocols\":[\"json-rpc\",\"sse\"]}\n\n", sessionID)
+	for _, event := range replay {
+		writeSSEEvent(w, event)
+	}
 	flusher.Flush()
 
 	// Keep connection open and send periodic heartbeats
@@ -305,14 +878,28 @@ func handleSSEStream(w http.ResponseWriter, r *http.Request) {
 			// Send heartbeat
 			_, _ = fmt.Fprintf(w, "data: {\"type\":\"heartbeat\",\"timestamp\":\"%s\"}\n\n", time.Now().UTC().Format(time.RFC3339))
 			flusher.Flush()
+		case event, ok := <-events:
+			if !ok {
+				// Session deleted or taken over by a newer stream
+				return
+			}
+			// Forward MCP notification as a JSON-RPC message
+			writeSSEEvent(w, event)
+			flusher.Flush()
 		case <-r.Context().Done():
 			return
 		}
 	}
 }
 
-// setupWebSocketHandler configures the WebSocket endpoint
-func setupWebSocketHandler(mux *http.ServeMux, ctx context.Context, wsHub *mcpwebsocket.Hub) {
+// writeSSEEvent writes a numbered notification event
+func writeSSEEvent(w http.ResponseWriter, event sseEvent) {
+	_, _ = fmt.Fprintf(w, "id: %d\nevent: message\ndata: %s\n\n", event.ID, event.Data)
+}
+
+// setupWebSocketHandler configures the WebSocket endpoint, which streams
+// memory events and serves MCP JSON-RPC for long-lived sessions
+func setupWebSocketHandler(mux *http.ServeMux, ctx context.Context, wsHub *mcpwebsocket.Hub, guard *security.ReplayGuard) {
 	// WebSocket upgrader with specific origin check
 	var upgrader = websocket.Upgrader{
 		CheckOrigin: func(r *http.Request) bool {
@@ -322,7 +909,7 @@ func setupWebSocketHandler(mux *http.ServeMux, ctx context.Context, wsHub *mcpwe
 		},
 	}
 
-	// WebSocket endpoint for real-time memory updates
+	// WebSocket endpoint for real-time memory updates and MCP requests
 	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
 		// Check if it's a WebSocket upgrade request
 		if !strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade") ||
@@ -331,6 +918,11 @@ func setupWebSocketHandler(mux *http.ServeMux, ctx context.Context, wsHub *mcpwe
 			return
 		}
 
+		// The connection carries JSON-RPC, so the upgrade request is signed
+		if !verifySignedRequest(w, r, guard) {
+			return
+		}
+
 		// Upgrade the HTTP connection to WebSocket
 		conn, err := upgrader.Upgrade(w, r, nil)
 		if err != nil {
@@ -345,6 +937,9 @@ func setupWebSocketHandler(mux *http.ServeMux, ctx context.Context, wsHub *mcpwe
 		// Create a new client
 		clientID := uuid.New().String()
 		client := mcpwebsocket.NewClient(clientID, conn, wsHub, repository, sessionID)
+		client.APIKey = auth.KeyFrom(r.Context())
+		client.TokenClaims = security.TokenClaimsFrom(r.Context())
+		client.Tenant = tenancy.FromContext(r.Context())
 
 		// Register client with hub
 		wsHub.RegisterClient(client)
@@ -353,7 +948,11 @@ func setupWebSocketHandler(mux *http.ServeMux, ctx context.Context, wsHub *mcpwe
 		go client.WritePump(ctx)
 		go client.ReadPump(ctx)
 
-		log.Printf("WebSocket client %s connected from %s", clientID, r.RemoteAddr)
+		if principal := client.Principal(); principal != "" {
+			log.Printf("WebSocket client %s connected from %s as %s", clientID, r.RemoteAddr, principal)
+		} else {
+			log.Printf("WebSocket client %s connected from %s", clientID, r.RemoteAddr)
+		}
 	})
 }
 
@@ -370,37 +969,260 @@ func setupHealthHandler(mux *http.ServeMux) {
 	})
 }
 
-// startAndRunHTTPServer creates and runs the HTTP server
-func startAndRunHTTPServer(ctx context.Context, mux *http.ServeMux, addr string) error {
+// setupMetricsHandler configures the Prometheus metrics endpoint. Callers
+// held to a tenant (see withAdminAccess) get the health gauges of their own
+// repositories only, without the tool metrics of every tenant.
+func setupMetricsHandler(mux *http.ServeMux, cfg *config.Config, toolMetrics *monitoring.ToolMetrics, healthScores *monitoring.HealthScores) {
+	mux.HandleFunc("/metrics", withAdminAccess(cfg, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if tenant := tenancy.FromContext(r.Context()); tenant != nil {
+			if err := monitoring.WriteHealthPrometheus(w, monitoring.FilterHealth(healthScores.Scores(), tenant.Allows)); err != nil {
+				log.Printf("Error writing metrics: %v", err)
+			}
+			return
+		}
+		if err := toolMetrics.WritePrometheus(w); err != nil {
+			log.Printf("Error writing metrics: %v", err)
+			return
+		}
+		if err := healthScores.WritePrometheus(w); err != nil {
+			log.Printf("Error writing metrics: %v", err)
+		}
+	}))
+}
+
+// memoryHealthSource serves the memory health score of every repository the
+// request's tenant owns
+type memoryHealthSource interface {
+	MemoryHealth(ctx context.Context) ([]monitoring.RepositoryHealth, error)
+}
+
+// memoryHealthPage renders the memory health dashboard
+var memoryHealthPage = template.Must(template.New("memory-health").Funcs(template.FuncMap{
+	"percent": func(ratio float64) string { return fmt.Sprintf("%.0f%%", ratio*100) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Memory health</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { padding: 0.4em 0.8em; border-bottom: 1px solid #ddd; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+</style>
+</head>
+<body>
+<h1>Memory health</h1>
+<table>
+<tr><th>Repository</th><th>Score</th><th>Memories</th><th>Recent activity</th><th>Stale</th><th>Conflicts</th><th>Untagged</th><th>Verified solutions</th></tr>
+{{range .}}<tr><td>{{.Repository}}</td><td>{{printf "%.1f" .Score}}</td><td>{{.Memories}}</td><td>{{percent .Components.ActivityCoverage}}</td><td>{{percent .Components.StaleRatio}}</td><td>{{.Components.Conflicts}}</td><td>{{percent .Components.UntaggedRatio}}</td><td>{{if .Components.Solutions}}{{percent .Components.VerifiedSolutionRatio}}{{else}}-{{end}}</td></tr>
+{{else}}<tr><td colspan="8">No memories yet</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// setupMemoryHealthHandler configures the admin dashboard of per-repository
+// memory health scores, lowest first. ?format=json returns the scores as
+// JSON. Like /metrics, it is an admin endpoint, and callers held to a tenant
+// see their own repositories only.
+func setupMemoryHealthHandler(mux *http.ServeMux, cfg *config.Config, source memoryHealthSource) {
+	mux.HandleFunc("/admin/memory-health", withAdminAccess(cfg, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		scores, err := source.MemoryHealth(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if r.URL.Query().Get("format") == "json" {
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(map[string]interface{}{"repositories": scores}); err != nil {
+				log.Printf("Error encoding memory health: %v", err)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := memoryHealthPage.Execute(w, scores); err != nil {
+			log.Printf("Error rendering memory health: %v", err)
+		}
+	}))
+}
+
+// timelineSource serves timeline queries
+type timelineSource interface {
+	Timeline(ctx context.Context, args map[string]interface{}) (interface{}, error)
+}
+
+// setupTimelineHandler configures the memory timeline endpoint. It takes the
+// memory_timeline arguments as query parameters, with types comma-separated,
+// so the web UI and CLI can browse activity without a JSON-RPC session.
+func setupTimelineHandler(mux *http.ServeMux, source timelineSource, guard *security.ReplayGuard) {
+	mux.HandleFunc("/timeline", func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+		if r.Method != "GET" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !verifySignedRequest(w, r, guard) {
+			return
+		}
+
+		args := make(map[string]interface{})
+		for _, name := range []string{"repository", "granularity", "from", "to", "bucket", "session_id"} {
+			if value := r.URL.Query().Get(name); value != "" {
+				args[name] = value
+			}
+		}
+		if value := r.URL.Query().Get("types"); value != "" {
+			chunkTypes := make([]interface{}, 0)
+			for _, t := range strings.Split(value, ",") {
+				chunkTypes = append(chunkTypes, strings.TrimSpace(t))
+			}
+			args["types"] = chunkTypes
+		}
+		for _, name := range []string{"limit", "offset"} {
+			if value := r.URL.Query().Get(name); value != "" {
+				n, err := strconv.Atoi(value)
+				if err != nil {
+					http.Error(w, fmt.Sprintf("invalid %s: %q", name, value), http.StatusBadRequest)
+					return
+				}
+				args[name] = float64(n)
+			}
+		}
+
+		result, err := source.Timeline(r.Context(), args)
+		w.Header().Set("Content-Type", "application/json")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			log.Printf("Error encoding timeline: %v", err)
+		}
+	})
+}
+
+// editorContextSource serves editor context lookups
+type editorContextSource interface {
+	EditorContext(ctx context.Context, query mcp.EditorContextQuery) (*mcp.EditorContext, error)
+}
+
+// setupEditorContextHandler serves the memories relevant to an editor
+// position to editor plugins, answering from memory as the cursor moves
+func setupEditorContextHandler(mux *http.ServeMux, source editorContextSource, guard *security.ReplayGuard) {
+	mux.HandleFunc("/api/v1/context", func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+		if r.Method != "GET" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !verifySignedRequest(w, r, guard) {
+			return
+		}
+
+		params := r.URL.Query()
+		query := mcp.EditorContextQuery{
+			Repository: params.Get("repository"),
+			FilePath:   params.Get("file_path"),
+			Symbol:     params.Get("symbol"),
+		}
+		if value := params.Get("limit"); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid limit: %q", value), http.StatusBadRequest)
+				return
+			}
+			query.Limit = n
+		}
+
+		start := time.Now()
+		result, err := source.EditorContext(r.Context(), query)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Server-Timing", fmt.Sprintf("context;dur=%.1f", float64(time.Since(start).Microseconds())/1000))
+		if err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, tenancy.ErrCrossTenant) {
+				status = http.StatusForbidden
+			}
+			w.WriteHeader(status)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			log.Printf("Error encoding editor context: %v", err)
+		}
+	})
+}
+
+// startAndRunHTTPServer creates and runs the HTTP server until stop is done
+// or the listener fails. The server is shut down by the memory server's
+// Shutdown, once requests drained and streams closed.
+func startAndRunHTTPServer(stop context.Context, memoryServer *mcp.MemoryServer, handler http.Handler, addr string, tlsConfig *tls.Config) error {
 	httpServer := &http.Server{
 		Addr:              addr,
-		Handler:           mux,
+		Handler:           handler,
 		ReadHeaderTimeout: 10 * time.Second,
 		ReadTimeout:       30 * time.Second,
 		WriteTimeout:      30 * time.Second,
 		IdleTimeout:       120 * time.Second,
+		TLSConfig:         tlsConfig,
 	}
+	memoryServer.AddShutdownHook(func(ctx context.Context) error {
+		if err := httpServer.Shutdown(ctx); err != nil {
+			_ = httpServer.Close()
+			return fmt.Errorf("failed to shut down HTTP server: %w", err)
+		}
+		return nil
+	})
 
 	// Start server in goroutine
+	failed := make(chan error, 1)
+	scheme, wsScheme := "http", "ws"
+	if tlsConfig != nil {
+		scheme, wsScheme = "https", "wss"
+	}
 	go func() {
-		log.Printf("✅ MCP Memory Server listening on http://localhost%s", addr)
-		log.Printf("🔗 MCP endpoint: http://localhost%s/mcp", addr)
-		log.Printf("📡 SSE endpoint: http://localhost%s/sse", addr)
-		log.Printf("🔌 WebSocket endpoint: ws://localhost%s/ws", addr)
-		log.Printf("💚 Health check: http://localhost%s/health", addr)
-		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Printf("HTTP server error: %v", err)
+		log.Printf("✅ MCP Memory Server listening on %s://localhost%s", scheme, addr)
+		log.Printf("🔗 MCP endpoint: %s://localhost%s/mcp", scheme, addr)
+		log.Printf("📡 SSE endpoint: %s://localhost%s/sse", scheme, addr)
+		log.Printf("🔌 WebSocket endpoint: %s://localhost%s/ws", wsScheme, addr)
+		log.Printf("💚 Health check: %s://localhost%s/health", scheme, addr)
+		log.Printf("📊 Metrics: %s://localhost%s/metrics", scheme, addr)
+		log.Printf("🩺 Memory health: %s://localhost%s/admin/memory-health", scheme, addr)
+		log.Printf("🗓️ Timeline: %s://localhost%s/timeline", scheme, addr)
+		log.Printf("🧭 Editor context: %s://localhost%s/api/v1/context", scheme, addr)
+		var err error
+		if tlsConfig != nil {
+			// The certificate comes from TLSConfig, loaded from files or ACME
+			err = httpServer.ListenAndServeTLS("", "")
+		} else {
+			err = httpServer.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			failed <- err
 		}
 	}()
 
-	// Wait for context cancellation
-	<-ctx.Done()
-
-	// Create a timeout context for shutdown
-	// We use context.Background() here because the parent context is already cancelled
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	// Shutdown server gracefully
-	return httpServer.Shutdown(shutdownCtx) //nolint:contextcheck // Fresh context needed for shutdown when parent is cancelled
+	select {
+	case <-stop.Done():
+		return nil
+	case err := <-failed:
+		return err
+	}
 }
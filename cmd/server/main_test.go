@@ -1,8 +1,24 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/auth"
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/mcp"
+	"lerian-mcp-memory/internal/monitoring"
+	"lerian-mcp-memory/internal/tenancy"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
 )
 
 // Since main() calls log.Fatalf on error, we test the testable parts
@@ -20,3 +36,362 @@ func TestMain(t *testing.T) {
 		t.Skip("Skipping main test in short mode")
 	}
 }
+
+func TestWithNetworkPolicy(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Security.AdminIPAllowlist = []string{"127.0.0.1"}
+
+	handler, err := withNetworkPolicy(cfg, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	if err != nil {
+		t.Fatalf("withNetworkPolicy: %v", err)
+	}
+
+	tests := []struct {
+		remoteAddr string
+		path       string
+		want       int
+	}{
+		{"203.0.113.9:4000", "/mcp", http.StatusOK},
+		{"203.0.113.9:4000", "/timeline", http.StatusOK},
+		{"203.0.113.9:4000", "/api/v1/context", http.StatusOK},
+		{"203.0.113.9:4000", "/metrics", http.StatusForbidden},
+		{"127.0.0.1:4000", "/metrics", http.StatusOK},
+		{"203.0.113.9:4000", "/admin/memory-health", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		req.RemoteAddr = tt.remoteAddr
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s %s: got status %d, want %d", tt.remoteAddr, tt.path, rec.Code, tt.want)
+		}
+	}
+}
+
+type fakeMemoryHealth struct{}
+
+func (fakeMemoryHealth) MemoryHealth(context.Context) ([]monitoring.RepositoryHealth, error) {
+	return []monitoring.RepositoryHealth{{
+		Repository: "github.com/acme/<api>",
+		Memories:   12,
+		Score:      41.5,
+		Components: monitoring.HealthComponents{StaleRatio: 0.25},
+	}}, nil
+}
+
+func TestMemoryHealthHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	setupMemoryHealthHandler(mux, config.DefaultConfig(), fakeMemoryHealth{})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/memory-health", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"github.com/acme/&lt;api&gt;", "<td>41.5</td>", "<td>25%</td>"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("dashboard lacks %q", want)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/memory-health?format=json", nil))
+	var response struct {
+		Repositories []monitoring.RepositoryHealth `json:"repositories"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(response.Repositories) != 1 || response.Repositories[0].Score != 41.5 {
+		t.Errorf("repositories = %+v", response.Repositories)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/admin/memory-health", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST: status = %d, want 405", rec.Code)
+	}
+}
+
+// tenantMemoryHealth serves the scores of the repositories of the request's tenant
+type tenantMemoryHealth struct {
+	scores *monitoring.HealthScores
+}
+
+func (f tenantMemoryHealth) MemoryHealth(ctx context.Context) ([]monitoring.RepositoryHealth, error) {
+	if tenant := tenancy.FromContext(ctx); tenant != nil {
+		return monitoring.FilterHealth(f.scores.Scores(), tenant.Allows), nil
+	}
+	return f.scores.Scores(), nil
+}
+
+func TestAdminEndpointsNeedOperatorAccess(t *testing.T) {
+	keys := auth.NewStore("")
+	tenantSecret, _, err := keys.Create("acme", []string{"*"}, []string{"github.com/acme/api"}, 0)
+	if err != nil {
+		t.Fatalf("create key: %v", err)
+	}
+	operatorSecret, _, err := keys.Create("operator", []string{"*"}, []string{tenancy.AllProjects}, 0)
+	if err != nil {
+		t.Fatalf("create key: %v", err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.Security.APIKeys.Enabled = true
+
+	scores := monitoring.NewHealthScores()
+	scores.Replace([]monitoring.RepositoryHealth{
+		{Repository: "github.com/acme/api", Score: 80},
+		{Repository: "github.com/rival/app", Score: 40},
+	}, time.Now())
+	mux := http.NewServeMux()
+	setupMetricsHandler(mux, cfg, monitoring.NewToolMetrics(), scores)
+	setupMemoryHealthHandler(mux, cfg, tenantMemoryHealth{scores: scores})
+	guarded := withAPIKeys(cfg, keys, mux)
+
+	// With an admin allowlist, admin endpoints trust the admin network
+	adminCfg := *cfg
+	adminCfg.Security.AdminIPAllowlist = []string{"127.0.0.1"}
+	zoned, err := withNetworkPolicy(&adminCfg, guarded)
+	if err != nil {
+		t.Fatalf("withNetworkPolicy: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		handler    http.Handler
+		remoteAddr string
+		secret     string
+		want       int
+		repos      []string
+	}{
+		{"no credentials", guarded, "203.0.113.9:4000", "", http.StatusUnauthorized, nil},
+		{"unknown key", guarded, "203.0.113.9:4000", auth.KeyPrefix + "nope_secret", http.StatusUnauthorized, nil},
+		{"tenant key", guarded, "203.0.113.9:4000", tenantSecret, http.StatusOK, []string{"github.com/acme/api"}},
+		{"operator key", guarded, "203.0.113.9:4000", operatorSecret, http.StatusOK, []string{"github.com/acme/api", "github.com/rival/app"}},
+		{"admin network", zoned, "127.0.0.1:4000", "", http.StatusOK, []string{"github.com/acme/api", "github.com/rival/app"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, path := range []string{"/metrics", "/admin/memory-health?format=json"} {
+				req := httptest.NewRequest("GET", path, nil)
+				req.RemoteAddr = tt.remoteAddr
+				if tt.secret != "" {
+					req.Header.Set(apiKeyHeader, tt.secret)
+				}
+				rec := httptest.NewRecorder()
+				tt.handler.ServeHTTP(rec, req)
+				if rec.Code != tt.want {
+					t.Fatalf("%s: got status %d, want %d: %s", path, rec.Code, tt.want, rec.Body.String())
+				}
+				for _, repository := range []string{"github.com/acme/api", "github.com/rival/app"} {
+					if want := slices.Contains(tt.repos, repository); strings.Contains(rec.Body.String(), repository) != want {
+						t.Errorf("%s: reports %s = %v, want %v", path, repository, !want, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+type fakeTimeline struct {
+	args map[string]interface{}
+}
+
+func (f *fakeTimeline) Timeline(_ context.Context, args map[string]interface{}) (interface{}, error) {
+	f.args = args
+	if args["repository"] == nil {
+		return nil, errors.New("repository parameter is required")
+	}
+	return map[string]interface{}{"status": "success"}, nil
+}
+
+func TestTimelineHandler(t *testing.T) {
+	source := &fakeTimeline{}
+	mux := http.NewServeMux()
+	setupTimelineHandler(mux, source, nil)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/timeline?repository=github.com/acme/api&granularity=week&types=problem,%20solution&limit=20", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	if source.args["granularity"] != "week" || source.args["limit"] != float64(20) {
+		t.Errorf("args = %v", source.args)
+	}
+	if chunkTypes, _ := source.args["types"].([]interface{}); len(chunkTypes) != 2 || chunkTypes[1] != "solution" {
+		t.Errorf("types = %v, want [problem solution]", source.args["types"])
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/timeline", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("missing repository: status = %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/timeline?repository=r&limit=many", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid limit: status = %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/timeline", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST: status = %d, want 405", rec.Code)
+	}
+}
+
+type fakeEditorContext struct {
+	query mcp.EditorContextQuery
+}
+
+func (f *fakeEditorContext) EditorContext(_ context.Context, query mcp.EditorContextQuery) (*mcp.EditorContext, error) {
+	f.query = query
+	if query.Repository == "" {
+		return nil, errors.New("repository is required")
+	}
+	return &mcp.EditorContext{Repository: query.Repository, Items: []mcp.EditorContextItem{{ChunkID: "chunk-1"}}}, nil
+}
+
+func TestEditorContextHandler(t *testing.T) {
+	source := &fakeEditorContext{}
+	mux := http.NewServeMux()
+	setupEditorContextHandler(mux, source, nil)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/context?repository=github.com/acme/api&file_path=internal/auth/token.go&symbol=RefreshToken&limit=3", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	want := mcp.EditorContextQuery{Repository: "github.com/acme/api", FilePath: "internal/auth/token.go", Symbol: "RefreshToken", Limit: 3}
+	if source.query != want {
+		t.Errorf("query = %+v, want %+v", source.query, want)
+	}
+	if !strings.HasPrefix(rec.Header().Get("Server-Timing"), "context;dur=") {
+		t.Errorf("Server-Timing = %q", rec.Header().Get("Server-Timing"))
+	}
+	if !strings.Contains(rec.Body.String(), `"chunk_id":"chunk-1"`) {
+		t.Errorf("body = %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/context?symbol=Parse", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("missing repository: status = %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/context?repository=r&limit=many", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid limit: status = %d, want 400", rec.Code)
+	}
+}
+
+// fakeBatcher answers single requests and batches with fixed results
+type fakeBatcher struct {
+	batches int
+}
+
+func (f *fakeBatcher) HandleRequest(_ context.Context, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+	return &protocol.JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: req.Method}
+}
+
+func (f *fakeBatcher) HandleBatch(_ context.Context, data []byte) interface{} {
+	f.batches++
+	if strings.Contains(string(data), `"id"`) {
+		return []*protocol.JSONRPCResponse{{JSONRPC: "2.0", ID: 1, Result: "batched"}}
+	}
+	return nil
+}
+
+func TestMCPHandler_Batch(t *testing.T) {
+	server := &fakeBatcher{}
+	mux := http.NewServeMux()
+	setupMCPHandler(mux, server, nil)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/mcp", strings.NewReader(`[{"jsonrpc":"2.0","id":1,"method":"tools/list"}]`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("batch: status = %d, want 200", rec.Code)
+	}
+	var responses []protocol.JSONRPCResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &responses); err != nil || len(responses) != 1 || responses[0].Result != "batched" {
+		t.Errorf("batch response = %s (%v)", rec.Body.String(), err)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/mcp", strings.NewReader(`[{"jsonrpc":"2.0","method":"notifications/initialized"}]`)))
+	if rec.Code != http.StatusAccepted || rec.Body.Len() != 0 {
+		t.Errorf("notification batch: status = %d, body = %q, want 202 and no body", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":7,"method":"ping"}`)))
+	var single protocol.JSONRPCResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &single); err != nil || single.Result != "ping" {
+		t.Errorf("single response = %s (%v)", rec.Body.String(), err)
+	}
+	if server.batches != 2 {
+		t.Errorf("batches served = %d, want 2", server.batches)
+	}
+}
+
+// fakeSessionServer answers requests and keeps client sessions
+type fakeSessionServer struct {
+	fakeBatcher
+	sessions mcp.SessionManager
+}
+
+func (f *fakeSessionServer) Sessions() *mcp.SessionManager {
+	return &f.sessions
+}
+
+func TestMCPHandler_Sessions(t *testing.T) {
+	server := &fakeSessionServer{}
+	mux := http.NewServeMux()
+	setupMCPHandler(mux, server, nil)
+	post := func(body, sessionID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/mcp", strings.NewReader(body))
+		if sessionID != "" {
+			req.Header.Set(mcpSessionHeader, sessionID)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post(`{"jsonrpc":"2.0","id":1,"method":"initialize"}`, "")
+	sessionID := rec.Header().Get(mcpSessionHeader)
+	if rec.Code != http.StatusOK || sessionID == "" {
+		t.Fatalf("initialize: status = %d, session = %q, want 200 and a session", rec.Code, sessionID)
+	}
+	if _, ok := server.sessions.Get(sessionID); !ok {
+		t.Fatalf("session %s was not opened", sessionID)
+	}
+
+	rec = post(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`, sessionID)
+	if rec.Code != http.StatusOK || rec.Header().Get(mcpSessionHeader) != sessionID {
+		t.Errorf("in session: status = %d, session = %q", rec.Code, rec.Header().Get(mcpSessionHeader))
+	}
+	if rec = post(`{"jsonrpc":"2.0","id":3,"method":"tools/list"}`, ""); rec.Code != http.StatusOK {
+		t.Errorf("sessionless: status = %d, want 200", rec.Code)
+	}
+	if rec = post(`{"jsonrpc":"2.0","id":4,"method":"tools/list"}`, "unknown"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown session: status = %d, want 404", rec.Code)
+	}
+
+	del := httptest.NewRequest("DELETE", "/mcp", nil)
+	del.Header.Set(mcpSessionHeader, sessionID)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, del)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE: status = %d, want 204", rec.Code)
+	}
+	if rec = post(`{"jsonrpc":"2.0","id":5,"method":"tools/list"}`, sessionID); rec.Code != http.StatusNotFound {
+		t.Errorf("ended session: status = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"lerian-mcp-memory/internal/auth"
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/mcp"
+	"lerian-mcp-memory/internal/security"
+)
+
+// maxAuthorizedBodySize bounds the request body read to find the tools a request calls
+const maxAuthorizedBodySize = 10 << 20
+
+// tokenValidator validates bearer tokens
+type tokenValidator interface {
+	Validate(ctx context.Context, token string) (*security.TokenClaims, error)
+}
+
+// oauthGuard authorizes requests to the MCP endpoints with OAuth bearer
+// tokens and publishes the protected resource metadata clients discover the
+// authorization servers from
+type oauthGuard struct {
+	cfg         config.OAuthConfig
+	validator   tokenValidator
+	tools       mcp.ToolAnnotator
+	metadataURL string
+	next        http.Handler
+}
+
+// withOAuth wraps handler with OAuth authorization, returning it unchanged
+// when OAuth is disabled
+func withOAuth(cfg *config.Config, tools mcp.ToolAnnotator, handler http.Handler) (http.Handler, error) {
+	oauth := cfg.Security.OAuth
+	if !oauth.Enabled {
+		return handler, nil
+	}
+	metadataURL, err := security.ProtectedResourceMetadataURL(oauth.Resource)
+	if err != nil {
+		return nil, err
+	}
+	validator := security.NewOAuthValidator(oauth.TokenIssuer(), oauth.TokenAudience(), oauth.JWKSURL,
+		time.Duration(oauth.JWKSCacheTTL)*time.Second, time.Duration(oauth.ClockSkew)*time.Second)
+	log.Printf("🔐 OAuth authorization enabled: tokens from %s for %s", oauth.TokenIssuer(), oauth.TokenAudience())
+
+	return &oauthGuard{cfg: oauth, validator: validator, tools: tools, metadataURL: metadataURL, next: handler}, nil
+}
+
+// ServeHTTP serves the metadata, and checks the token and its scopes on MCP
+// endpoints. Tokens sent to admin endpoints are checked too, and requests
+// without one are left to the endpoint (see adminTenant).
+func (g *oauthGuard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, security.ProtectedResourcePath) {
+		g.serveMetadata(w, r)
+		return
+	}
+
+	switch endpointForPath(r.URL.Path) {
+	case security.EndpointMCP, security.EndpointSSE, security.EndpointWS:
+	case security.EndpointAdmin:
+		g.serveAdmin(w, r)
+		return
+	default:
+		g.next.ServeHTTP(w, r)
+		return
+	}
+	// Preflights carry no credentials, and requests with an API key were authenticated by it
+	if r.Method == methodOptions || auth.KeyFrom(r.Context()) != nil {
+		g.next.ServeHTTP(w, r)
+		return
+	}
+
+	token, err := security.BearerToken(r)
+	if endpointForPath(r.URL.Path) == security.EndpointWS {
+		token, err = security.UpgradeToken(r)
+	}
+	if err != nil {
+		g.reject(w, r, http.StatusUnauthorized, err, "")
+		return
+	}
+	claims, err := g.validator.Validate(r.Context(), token)
+	if err != nil {
+		log.Printf("Rejected bearer token from %s: %v", r.RemoteAddr, err)
+		g.reject(w, r, http.StatusUnauthorized, err, "")
+		return
+	}
+
+	required, err := g.requiredScopes(r)
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+	var missing []string
+	for _, scope := range required {
+		if !claims.HasScope(scope) {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) > 0 {
+		g.reject(w, r, http.StatusForbidden, security.ErrInsufficientScope, strings.Join(missing, " "))
+		return
+	}
+
+	g.next.ServeHTTP(w, r.WithContext(security.WithTokenClaims(r.Context(), claims)))
+}
+
+// serveAdmin validates the bearer token of a request to an admin endpoint,
+// if it carries one that is not an API key. Admin endpoints need no scope;
+// what they report depends on the token's tenant.
+func (g *oauthGuard) serveAdmin(w http.ResponseWriter, r *http.Request) {
+	token, err := security.BearerToken(r)
+	if r.Method == methodOptions || auth.KeyFrom(r.Context()) != nil || err != nil {
+		g.next.ServeHTTP(w, r)
+		return
+	}
+	claims, err := g.validator.Validate(r.Context(), token)
+	if err != nil {
+		log.Printf("Rejected bearer token from %s: %v", r.RemoteAddr, err)
+		g.reject(w, r, http.StatusUnauthorized, err, "")
+		return
+	}
+	g.next.ServeHTTP(w, r.WithContext(security.WithTokenClaims(r.Context(), claims)))
+}
+
+// serveMetadata serves the protected resource metadata (RFC 9728)
+func (g *oauthGuard) serveMetadata(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if r.Method == methodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(security.ProtectedResourceMetadata{
+		Resource:               g.cfg.Resource,
+		AuthorizationServers:   g.cfg.AuthorizationServers,
+		ScopesSupported:        g.cfg.Scopes(),
+		BearerMethodsSupported: []string{"header"},
+	}); err != nil {
+		log.Printf("Error encoding protected resource metadata: %v", err)
+	}
+}
+
+// requiredScopes returns the scopes a request needs. JSON-RPC posts need the
+// scopes of the methods and tools they call; WebSocket connections, which
+// call tools after the upgrade, need both scopes, and mcp.OAuthScopeMiddleware
+// checks each of their calls against the scope of its tool; other requests
+// read memory.
+func (g *oauthGuard) requiredScopes(r *http.Request) ([]string, error) {
+	switch {
+	case r.Method == http.MethodDelete:
+		return nil, nil
+	case r.URL.Path == "/ws":
+		return []string{g.cfg.ReadScope, g.cfg.WriteScope}, nil
+	case r.Method != http.MethodPost || (r.URL.Path != "/mcp" && r.URL.Path != "/sse"):
+		return []string{g.cfg.ReadScope}, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxAuthorizedBodySize))
+	if err != nil {
+		return nil, err
+	}
+	_ = r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	messages := []json.RawMessage{body}
+	if mcp.IsBatch(body) {
+		if err := json.Unmarshal(body, &messages); err != nil {
+			return nil, nil // The handler answers malformed batches
+		}
+	}
+	var scopes []string
+	for _, message := range messages {
+		var call struct {
+			Method string `json:"method"`
+			Params struct {
+				Name string `json:"name"`
+			} `json:"params"`
+		}
+		if err := json.Unmarshal(message, &call); err != nil {
+			continue
+		}
+		scopes = append(scopes, g.methodScope(call.Method, call.Params.Name))
+	}
+	return scopes, nil
+}
+
+// methodScope is the scope a JSON-RPC method needs. Tool calls need the
+// scope of the tool; handshakes, notifications and responses need none.
+func (g *oauthGuard) methodScope(method, tool string) string {
+	switch {
+	case method == "tools/call":
+		return g.toolScope(tool)
+	case method == "", method == "initialize", method == "ping", strings.HasPrefix(method, "notifications/"):
+		return ""
+	default:
+		return g.cfg.ReadScope
+	}
+}
+
+// toolScope is the scope a tool needs; see mcp.ToolScope
+func (g *oauthGuard) toolScope(tool string) string {
+	return mcp.ToolScope(&g.cfg, g.tools, tool)
+}
+
+// reject answers an unauthorized or forbidden request with a bearer challenge
+// pointing the client at the protected resource metadata
+func (g *oauthGuard) reject(w http.ResponseWriter, r *http.Request, status int, err error, scope string) {
+	errorCode, description := "invalid_token", err.Error()
+	switch {
+	case errors.Is(err, security.ErrMissingToken):
+		errorCode, description = "", ""
+	case status == http.StatusForbidden:
+		errorCode = "insufficient_scope"
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		origin = defaultLocalOrigin
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	w.Header().Set("Access-Control-Allow-Credentials", "true")
+	w.Header().Set("Access-Control-Expose-Headers", "WWW-Authenticate")
+	w.Header().Set("WWW-Authenticate", security.BearerChallenge(g.metadataURL, errorCode, description, scope))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	body := map[string]string{"error": errorCode, "error_description": err.Error()}
+	if errorCode == "" {
+		body["error"] = "unauthorized"
+	}
+	if encodeErr := json.NewEncoder(w).Encode(body); encodeErr != nil {
+		log.Printf("Error encoding authorization error: %v", encodeErr)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/mcp"
+	"lerian-mcp-memory/internal/security"
+)
+
+// fakeValidator accepts tokens named after the scopes they grant
+type fakeValidator struct{}
+
+func (fakeValidator) Validate(_ context.Context, token string) (*security.TokenClaims, error) {
+	if token == "invalid" {
+		return nil, security.ErrExpiredToken
+	}
+	return &security.TokenClaims{Subject: "agent", Scopes: strings.Split(token, "+")}, nil
+}
+
+// fakeAnnotator marks memory_read read-only
+type fakeAnnotator struct{}
+
+func (fakeAnnotator) ToolAnnotationsFor(name string) (mcp.ToolAnnotations, bool) {
+	readOnly := name == "memory_read"
+	return mcp.ToolAnnotations{ReadOnlyHint: &readOnly}, true
+}
+
+func newTestOAuthGuard(t *testing.T) http.Handler {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.Security.OAuth.Enabled = true
+	cfg.Security.OAuth.Resource = "https://memory.example.com/mcp"
+	cfg.Security.OAuth.AuthorizationServers = []string{"https://auth.example.com"}
+	cfg.Security.OAuth.ToolScopes = map[string]string{"memory_system": "memory:admin"}
+
+	handler, err := withOAuth(cfg, fakeAnnotator{}, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	if err != nil {
+		t.Fatalf("withOAuth: %v", err)
+	}
+	guard, ok := handler.(*oauthGuard)
+	if !ok {
+		t.Fatalf("withOAuth returned %T, want the OAuth guard", handler)
+	}
+	guard.validator = fakeValidator{}
+	return guard
+}
+
+func TestWithOAuthDisabled(t *testing.T) {
+	next := http.NotFoundHandler()
+	handler, err := withOAuth(config.DefaultConfig(), fakeAnnotator{}, next)
+	if err != nil || handler == nil {
+		t.Fatalf("withOAuth: %v", err)
+	}
+	if _, guarded := handler.(*oauthGuard); guarded {
+		t.Error("OAuth is disabled by default")
+	}
+}
+
+func TestOAuthGuard(t *testing.T) {
+	handler := newTestOAuthGuard(t)
+	toolCall := func(tool string) string {
+		return `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"` + tool + `","arguments":{}}}`
+	}
+
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		token     string
+		body      string
+		want      int
+		challenge string
+	}{
+		{"no token", "POST", "/mcp", "", toolCall("memory_read"), http.StatusUnauthorized, `Bearer resource_metadata="https://memory.example.com/.well-known/oauth-protected-resource/mcp"`},
+		{"invalid token", "POST", "/mcp", "invalid", toolCall("memory_read"), http.StatusUnauthorized, `error="invalid_token"`},
+		{"read-only tool", "POST", "/mcp", "memory:read", toolCall("memory_read"), http.StatusOK, ""},
+		{"write tool without write scope", "POST", "/mcp", "memory:read", toolCall("memory_create"), http.StatusForbidden, `error="insufficient_scope", error_description="bearer token lacks the required scope", scope="memory:write"`},
+		{"write tool", "POST", "/sse", "memory:write", toolCall("memory_create"), http.StatusOK, ""},
+		{"configured tool scope", "POST", "/mcp", "memory:read+memory:write", toolCall("memory_system"), http.StatusForbidden, `scope="memory:admin"`},
+		{"batch needs every scope", "POST", "/mcp", "memory:read", "[" + toolCall("memory_read") + "," + toolCall("memory_delete") + "]", http.StatusForbidden, `scope="memory:write"`},
+		{"handshake", "POST", "/mcp", "none", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`, http.StatusOK, ""},
+		{"listing tools reads", "POST", "/mcp", "none", `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`, http.StatusForbidden, `scope="memory:read"`},
+		{"timeline reads", "GET", "/timeline", "memory:read", "", http.StatusOK, ""},
+		{"websocket calls tools", "GET", "/ws", "memory:read", "", http.StatusForbidden, `scope="memory:write"`},
+		{"websocket token in the query", "GET", "/ws?access_token=memory:read%2Bmemory:write", "", "", http.StatusOK, ""},
+		{"query token elsewhere", "GET", "/timeline?access_token=memory:read", "", "", http.StatusUnauthorized, `Bearer resource_metadata=`},
+		{"preflight", "OPTIONS", "/mcp", "", "", http.StatusOK, ""},
+		{"health is public", "GET", "/health", "", "", http.StatusOK, ""},
+		{"admin endpoints decide without a token", "GET", "/metrics", "", "", http.StatusOK, ""},
+		{"admin endpoints check tokens", "GET", "/metrics", "invalid", "", http.StatusUnauthorized, `error="invalid_token"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+			if challenge := rec.Header().Get("WWW-Authenticate"); !strings.Contains(challenge, tt.challenge) || (tt.challenge == "") != (challenge == "") {
+				t.Errorf("WWW-Authenticate = %q, want it to contain %q", challenge, tt.challenge)
+			}
+		})
+	}
+}
+
+func TestOAuthProtectedResourceMetadata(t *testing.T) {
+	handler := newTestOAuthGuard(t)
+
+	for _, path := range []string{"/.well-known/oauth-protected-resource", "/.well-known/oauth-protected-resource/mcp"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: status %d", path, rec.Code)
+		}
+		var metadata security.ProtectedResourceMetadata
+		if err := json.Unmarshal(rec.Body.Bytes(), &metadata); err != nil {
+			t.Fatalf("decode metadata: %v", err)
+		}
+		if metadata.Resource != "https://memory.example.com/mcp" || len(metadata.AuthorizationServers) != 1 {
+			t.Errorf("unexpected metadata %+v", metadata)
+		}
+		if strings.Join(metadata.ScopesSupported, " ") != "memory:read memory:write memory:admin" {
+			t.Errorf("scopes_supported = %v", metadata.ScopesSupported)
+		}
+	}
+}
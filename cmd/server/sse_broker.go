@@ -0,0 +1,322 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"lerian-mcp-memory/internal/mcp"
+
+	"github.com/fredcamaral/gomcp-sdk/notifications"
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/google/uuid"
+)
+
+// sseTransportType identifies the SSE delivery handler registered with the notifier
+const sseTransportType = "sse"
+
+// mcpSessionHeader carries the session ID of the streamable HTTP transport
+const mcpSessionHeader = "Mcp-Session-Id"
+
+const (
+	// sseReplayBufferSize is how many events a session keeps for resumption
+	sseReplayBufferSize = 256
+
+	// sseSessionIdleTimeout expires sessions without a stream or request
+	sseSessionIdleTimeout = 30 * time.Minute
+
+	// maxSSESessions caps concurrently tracked sessions
+	maxSSESessions = 1000
+)
+
+var (
+	// errSSESessionNotFound is returned for unknown or expired session IDs
+	errSSESessionNotFound = errors.New("session not found")
+
+	// errSSESessionLimit is returned when no more sessions can be created
+	errSSESessionLimit = errors.New("too many active sessions")
+
+	// errSSEShuttingDown is returned for sessions requested during shutdown
+	errSSEShuttingDown = errors.New("server is shutting down")
+)
+
+// sseEvent is a notification numbered within its session, so clients can
+// resume with Last-Event-ID after a dropped connection
+type sseEvent struct {
+	ID   uint64
+	Data []byte
+}
+
+// sseSession is one client session: the events it was sent and the stream,
+// if any, currently attached to it
+type sseSession struct {
+	id        string
+	ephemeral bool
+	lastSeen  time.Time
+	nextID    uint64
+	history   []sseEvent
+	stream    chan sseEvent
+}
+
+// sseBroker fans MCP notifications out to SSE sessions. Sessions created by
+// initialize outlive their streams and buffer recent events for replay;
+// streams opened without a session get an ephemeral one that ends with them.
+type sseBroker struct {
+	notifier *notifications.Notifier
+	sessions map[string]*sseSession
+	closed   bool
+	mutex    sync.Mutex
+
+	// onEnd, if set, is told about sessions that ended, so the server can
+	// drop their state
+	onEnd func(sessionID string)
+}
+
+// newSSEBroker creates a broker and registers it as the notifier's SSE delivery handler
+func newSSEBroker(notifier *notifications.Notifier) *sseBroker {
+	broker := &sseBroker{
+		notifier: notifier,
+		sessions: make(map[string]*sseSession),
+	}
+	if notifier != nil {
+		notifier.RegisterHandler(sseTransportType, broker.deliver)
+	}
+	return broker
+}
+
+// createSession starts a session that persists across streams until it is
+// deleted or idles out
+func (b *sseBroker) createSession() (string, error) {
+	return b.newSession(false)
+}
+
+// newSession registers a session with the broker and the notifier
+func (b *sseBroker) newSession(ephemeral bool) (string, error) {
+	b.mutex.Lock()
+	if b.closed {
+		b.mutex.Unlock()
+		return "", errSSEShuttingDown
+	}
+	expired := b.expireIdleSessions()
+	full := len(b.sessions) >= maxSSESessions
+	sessionID := uuid.New().String()
+	if !full {
+		b.sessions[sessionID] = &sseSession{id: sessionID, ephemeral: ephemeral, lastSeen: time.Now()}
+	}
+	b.mutex.Unlock()
+
+	for _, id := range expired {
+		b.ended(id)
+	}
+	if full {
+		return "", errSSESessionLimit
+	}
+
+	if b.notifier != nil {
+		b.notifier.RegisterClient(sessionID, false, false)
+	}
+	return sessionID, nil
+}
+
+// touchSession records activity on a session, failing if it does not exist
+func (b *sseBroker) touchSession(sessionID string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	session, ok := b.sessions[sessionID]
+	if !ok {
+		return errSSESessionNotFound
+	}
+	session.lastSeen = time.Now()
+	return nil
+}
+
+// deleteSession ends a session and closes its stream
+func (b *sseBroker) deleteSession(sessionID string) error {
+	b.mutex.Lock()
+	session, ok := b.sessions[sessionID]
+	if ok {
+		b.removeSession(session)
+	}
+	b.mutex.Unlock()
+
+	if !ok {
+		return errSSESessionNotFound
+	}
+	b.ended(sessionID)
+	return nil
+}
+
+// attach opens a stream on a session, creating an ephemeral session when
+// sessionID is empty. Events after lastEventID are returned for replay and
+// later events arrive on the channel; any earlier stream on the session is
+// closed. The returned function detaches the stream.
+func (b *sseBroker) attach(sessionID, lastEventID string) (string, []sseEvent, <-chan sseEvent, func(), error) {
+	if sessionID == "" {
+		var err error
+		if sessionID, err = b.newSession(true); err != nil {
+			return "", nil, nil, nil, err
+		}
+	}
+
+	b.mutex.Lock()
+	session, ok := b.sessions[sessionID]
+	if !ok {
+		b.mutex.Unlock()
+		return "", nil, nil, nil, errSSESessionNotFound
+	}
+
+	var replay []sseEvent
+	if after, err := strconv.ParseUint(lastEventID, 10, 64); err == nil {
+		for _, event := range session.history {
+			if event.ID > after {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	if session.stream != nil {
+		close(session.stream)
+	}
+	stream := make(chan sseEvent, 32)
+	session.stream = stream
+	session.lastSeen = time.Now()
+	b.mutex.Unlock()
+
+	detach := func() {
+		b.mutex.Lock()
+		ephemeral := false
+		if session.stream == stream {
+			session.stream = nil
+			session.lastSeen = time.Now()
+			if session.ephemeral {
+				b.removeSession(session)
+				ephemeral = true
+			}
+		}
+		b.mutex.Unlock()
+
+		if ephemeral {
+			b.ended(sessionID)
+		}
+	}
+	return sessionID, replay, stream, detach, nil
+}
+
+// sender returns a NotificationSender that delivers to one session, for
+// notifications about a request made in it
+func (b *sseBroker) sender(sessionID string) mcp.NotificationSender {
+	return func(method string, params interface{}) error {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("failed to encode notification params: %w", err)
+		}
+		return b.deliver(sessionID, &notifications.Notification{JSONRPC: "2.0", Method: method, Params: data})
+	}
+}
+
+// requestSender returns a RequestSender that delivers server-to-client
+// requests, such as sampling/createMessage, to one session. The client
+// POSTs its response back.
+func (b *sseBroker) requestSender(sessionID string) mcp.RequestSender {
+	return func(req *protocol.JSONRPCRequest) error {
+		data, err := json.Marshal(req)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		return b.deliverEvent(sessionID, data)
+	}
+}
+
+// deliver numbers a notification, keeps it for replay and forwards it to the
+// session's stream without blocking the notifier. A session without a
+// stream still accepts the event, so a reconnecting client can catch up.
+func (b *sseBroker) deliver(sessionID string, notification *notifications.Notification) error {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	return b.deliverEvent(sessionID, data)
+}
+
+// deliverEvent numbers an encoded message and sends it to a session as deliver does
+func (b *sseBroker) deliverEvent(sessionID string, data []byte) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	session, ok := b.sessions[sessionID]
+	if !ok {
+		return fmt.Errorf("sse session %s not connected", sessionID)
+	}
+
+	session.nextID++
+	event := sseEvent{ID: session.nextID, Data: data}
+	session.history = append(session.history, event)
+	if len(session.history) > sseReplayBufferSize {
+		session.history = session.history[len(session.history)-sseReplayBufferSize:]
+	}
+
+	if session.stream != nil {
+		select {
+		case session.stream <- event:
+		default:
+			// The client can recover the event with Last-Event-ID
+			return fmt.Errorf("sse session %s buffer full", sessionID)
+		}
+	}
+	return nil
+}
+
+// shutdown ends every session and refuses new ones. Streams write the
+// events queued for them before they end.
+func (b *sseBroker) shutdown() {
+	b.mutex.Lock()
+	b.closed = true
+	ids := make([]string, 0, len(b.sessions))
+	for _, session := range b.sessions {
+		b.removeSession(session)
+		ids = append(ids, session.id)
+	}
+	b.mutex.Unlock()
+
+	for _, id := range ids {
+		b.ended(id)
+	}
+}
+
+// expireIdleSessions drops streamless sessions idle longer than the timeout
+// and returns their IDs. Callers hold the mutex.
+func (b *sseBroker) expireIdleSessions() []string {
+	var expired []string
+	cutoff := time.Now().Add(-sseSessionIdleTimeout)
+	for _, session := range b.sessions {
+		if session.stream == nil && session.lastSeen.Before(cutoff) {
+			b.removeSession(session)
+			expired = append(expired, session.id)
+		}
+	}
+	return expired
+}
+
+// ended unregisters a removed session from the notifier and reports it to
+// onEnd. Callers do not hold the mutex.
+func (b *sseBroker) ended(sessionID string) {
+	if b.notifier != nil {
+		b.notifier.UnregisterClient(sessionID)
+	}
+	if b.onEnd != nil {
+		b.onEnd(sessionID)
+	}
+}
+
+// removeSession forgets a session and closes its stream. Callers hold the mutex.
+func (b *sseBroker) removeSession(session *sseSession) {
+	if session.stream != nil {
+		close(session.stream)
+		session.stream = nil
+	}
+	delete(b.sessions, session.id)
+}
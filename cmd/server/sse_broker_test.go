@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fredcamaral/gomcp-sdk/notifications"
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+func TestSSEBrokerReplaysMissedEvents(t *testing.T) {
+	broker := newSSEBroker(nil)
+	sessionID, err := broker.createSession()
+	if err != nil {
+		t.Fatalf("createSession: %v", err)
+	}
+
+	// Events sent while no stream is attached are buffered
+	for i := 0; i < 3; i++ {
+		if err := broker.deliver(sessionID, &notifications.Notification{Method: "notifications/resources/updated"}); err != nil {
+			t.Fatalf("deliver: %v", err)
+		}
+	}
+
+	_, replay, events, detach, err := broker.attach(sessionID, "1")
+	if err != nil {
+		t.Fatalf("attach: %v", err)
+	}
+	defer detach()
+	if len(replay) != 2 || replay[0].ID != 2 || replay[1].ID != 3 {
+		t.Fatalf("replay = %+v, want events 2 and 3", replay)
+	}
+
+	if err := broker.deliver(sessionID, &notifications.Notification{Method: "notifications/resources/updated"}); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if event := <-events; event.ID != 4 {
+		t.Errorf("live event ID = %d, want 4", event.ID)
+	}
+
+	// A newer stream takes the session over and closes the older one
+	_, _, _, detachNewer, err := broker.attach(sessionID, "")
+	if err != nil {
+		t.Fatalf("attach: %v", err)
+	}
+	defer detachNewer()
+	if _, ok := <-events; ok {
+		t.Error("expected the earlier stream to be closed")
+	}
+}
+
+func TestSSEBrokerSessionLifecycle(t *testing.T) {
+	broker := newSSEBroker(nil)
+	var ended []string
+	broker.onEnd = func(sessionID string) { ended = append(ended, sessionID) }
+
+	if _, _, _, _, err := broker.attach("unknown", ""); !errors.Is(err, errSSESessionNotFound) {
+		t.Errorf("attach unknown session: got %v, want errSSESessionNotFound", err)
+	}
+
+	// Streams without a session get an ephemeral one that ends with the stream
+	sessionID, _, _, detach, err := broker.attach("", "")
+	if err != nil {
+		t.Fatalf("attach: %v", err)
+	}
+	if err := broker.touchSession(sessionID); err != nil {
+		t.Errorf("touchSession during stream: %v", err)
+	}
+	detach()
+	if err := broker.touchSession(sessionID); !errors.Is(err, errSSESessionNotFound) {
+		t.Errorf("ephemeral session survived its stream: %v", err)
+	}
+	if len(ended) != 1 || ended[0] != sessionID {
+		t.Errorf("ended sessions = %v, want the ephemeral one", ended)
+	}
+
+	sessionID, err = broker.createSession()
+	if err != nil {
+		t.Fatalf("createSession: %v", err)
+	}
+	_, _, _, detach, err = broker.attach(sessionID, "")
+	if err != nil {
+		t.Fatalf("attach: %v", err)
+	}
+	detach()
+	if err := broker.touchSession(sessionID); err != nil {
+		t.Errorf("session ended with its stream: %v", err)
+	}
+
+	req := httptest.NewRequest("DELETE", "/sse", nil)
+	req.Header.Set(mcpSessionHeader, sessionID)
+	rec := httptest.NewRecorder()
+	handleSSEDelete(rec, req, broker, nil)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if len(ended) != 2 || ended[1] != sessionID {
+		t.Errorf("ended sessions = %v, want the deleted one last", ended)
+	}
+
+	rec = httptest.NewRecorder()
+	handleSSEDelete(rec, req, broker, nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("repeated DELETE status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestSSEBrokerShutdown(t *testing.T) {
+	broker := newSSEBroker(nil)
+	var ended []string
+	broker.onEnd = func(sessionID string) { ended = append(ended, sessionID) }
+
+	sessionID, err := broker.createSession()
+	if err != nil {
+		t.Fatalf("createSession: %v", err)
+	}
+	_, _, stream, detach, err := broker.attach(sessionID, "")
+	if err != nil {
+		t.Fatalf("attach: %v", err)
+	}
+	defer detach()
+
+	broker.shutdown()
+	if _, ok := <-stream; ok {
+		t.Error("stream stayed open after shutdown")
+	}
+	if len(ended) != 1 || ended[0] != sessionID {
+		t.Errorf("ended sessions = %v, want the open one", ended)
+	}
+	if _, err := broker.createSession(); !errors.Is(err, errSSEShuttingDown) {
+		t.Errorf("createSession after shutdown: got %v, want errSSEShuttingDown", err)
+	}
+}
+
+func TestSSEBrokerSendsRequests(t *testing.T) {
+	broker := newSSEBroker(nil)
+	sessionID, err := broker.createSession()
+	if err != nil {
+		t.Fatalf("createSession: %v", err)
+	}
+
+	send := broker.requestSender(sessionID)
+	if err := send(&protocol.JSONRPCRequest{JSONRPC: "2.0", ID: "server-1", Method: "sampling/createMessage"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	_, replay, _, detach, err := broker.attach(sessionID, "0")
+	if err != nil {
+		t.Fatalf("attach: %v", err)
+	}
+	defer detach()
+	if len(replay) != 1 || !strings.Contains(string(replay[0].Data), `"id":"server-1"`) {
+		t.Fatalf("replay = %+v, want the request", replay)
+	}
+
+	if err := broker.requestSender("unknown")(&protocol.JSONRPCRequest{JSONRPC: "2.0", ID: "server-2", Method: "sampling/createMessage"}); err == nil {
+		t.Error("expected sending to an unknown session to fail")
+	}
+}
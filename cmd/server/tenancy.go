@@ -0,0 +1,92 @@
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"lerian-mcp-memory/internal/auth"
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/security"
+	"lerian-mcp-memory/internal/tenancy"
+)
+
+// withTenancy wraps handler so requests authenticated by an API key or a
+// bearer token carry their tenant, which the storage layer holds them to.
+// WebSocket connections, which stream events of the repository they name,
+// need credentials and must name one of the tenant's projects. It returns
+// handler unchanged when tenancy is disabled; wrap it inside the
+// authentication guards.
+func withTenancy(cfg *config.Config, handler http.Handler) http.Handler {
+	if !cfg.Security.Tenancy.Enabled {
+		return handler
+	}
+	log.Printf("🏢 Tenant isolation enabled")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant := requestTenant(r)
+		if tenant == nil && r.URL.Path == "/ws" {
+			log.Printf("Denied WebSocket connection without credentials from %s", r.RemoteAddr)
+			rejectRequest(w, r, http.StatusUnauthorized, "unauthorized", "WebSocket connections need an API key or bearer token when tenant isolation is enabled")
+			return
+		}
+		if tenant == nil {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		if repository := r.URL.Query().Get("repository"); r.URL.Path == "/ws" && !tenant.Allows(repository) {
+			log.Printf("Denied WebSocket subscription of tenant %s to repository %q", tenant.ID, repository)
+			rejectRequest(w, r, http.StatusForbidden, "cross_tenant", "the repository parameter must name one of the tenant's projects")
+			return
+		}
+		handler.ServeHTTP(w, r.WithContext(tenancy.WithTenant(r.Context(), tenant)))
+	})
+}
+
+// requestTenant returns the tenant of the credentials that authenticated r, or nil
+func requestTenant(r *http.Request) *tenancy.Tenant {
+	if key := auth.KeyFrom(r.Context()); key != nil {
+		return tenancy.FromKey(key)
+	}
+	if claims := security.TokenClaimsFrom(r.Context()); claims != nil {
+		return tenancy.FromClaims(claims)
+	}
+	return nil
+}
+
+// adminTenant returns the tenant whose repositories the admin endpoints may
+// report to r, or nil for every repository. Without API keys, OAuth or
+// tenancy, admin endpoints are left to the network policy and report every
+// repository. Otherwise only requests from an admin network or with operator
+// credentials, whose tenant owns every project, see every repository; other
+// credentials see their tenant's, and requests without any are refused.
+func adminTenant(cfg *config.Config, r *http.Request) (tenant *tenancy.Tenant, ok bool) {
+	if !cfg.Security.APIKeys.Enabled && !cfg.Security.OAuth.Enabled && !cfg.Security.Tenancy.Enabled {
+		return nil, true
+	}
+	if fromAdminNetwork(r.Context()) {
+		return nil, true
+	}
+	tenant = requestTenant(r)
+	if tenant == nil {
+		return nil, false
+	}
+	if tenant.AllowsAll() {
+		return nil, true
+	}
+	return tenant, true
+}
+
+// withAdminAccess serves an admin endpoint held to the tenant adminTenant
+// returns, refusing requests it finds no access for
+func withAdminAccess(cfg *config.Config, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tenant, ok := adminTenant(cfg, r)
+		if !ok {
+			log.Printf("Denied %s %s without credentials from %s", r.Method, r.URL.Path, r.RemoteAddr)
+			rejectRequest(w, r, http.StatusUnauthorized, "unauthorized", "admin endpoints need operator credentials or a request from an admin network")
+			return
+		}
+		handler(w, r.WithContext(tenancy.WithTenant(r.Context(), tenant)))
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"lerian-mcp-memory/internal/auth"
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/tenancy"
+)
+
+func TestWithTenancy(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Security.Tenancy.Enabled = true
+
+	var seen *tenancy.Tenant
+	handler := withTenancy(cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = tenancy.FromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+	key := &auth.Key{ID: "k1", Projects: []string{"acme/api"}}
+
+	tests := []struct {
+		name   string
+		target string
+		key    *auth.Key
+		want   int
+		tenant bool
+	}{
+		{"no credentials", "/mcp", nil, http.StatusOK, false},
+		{"key", "/mcp", key, http.StatusOK, true},
+		{"websocket on an owned project", "/ws?repository=acme/api", key, http.StatusOK, true},
+		{"websocket on another project", "/ws?repository=rival/app", key, http.StatusForbidden, false},
+		{"websocket on every project", "/ws", key, http.StatusForbidden, false},
+		{"websocket without credentials", "/ws?repository=acme/api", nil, http.StatusUnauthorized, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen = nil
+			req := httptest.NewRequest("GET", tt.target, nil)
+			if tt.key != nil {
+				req = req.WithContext(auth.WithKey(req.Context(), tt.key))
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("got status %d, want %d", rec.Code, tt.want)
+			}
+			if (seen != nil) != tt.tenant {
+				t.Errorf("got tenant %+v, want one: %v", seen, tt.tenant)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"slices"
+
+	"lerian-mcp-memory/internal/config"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// tlsVersions maps the configured minimum TLS version to its constant
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// newHTTPTLS builds the TLS configuration of the HTTP transport. With autocert
+// domains it also returns the ACME manager, whose HTTP handler answers HTTP-01
+// challenges; TLS-ALPN-01 challenges are answered by the returned config.
+func newHTTPTLS(cfg config.TLSConfig) (*tls.Config, *autocert.Manager, error) {
+	minVersion, ok := tlsVersions[cfg.MinVersion]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported TLS minimum version %q", cfg.MinVersion)
+	}
+
+	var tlsConfig *tls.Config
+	var manager *autocert.Manager
+	if len(cfg.AutocertDomains) > 0 {
+		manager = &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.AutocertDomains...),
+			Cache:      autocert.DirCache(cfg.AutocertCacheDir),
+			Email:      cfg.AutocertEmail,
+		}
+		tlsConfig = manager.TLSConfig()
+	} else {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+	tlsConfig.MinVersion = minVersion
+
+	if cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.ClientCAFile) // #nosec G304 -- path comes from server configuration
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read TLS client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, nil, fmt.Errorf("no certificates found in TLS client CA %s", cfg.ClientCAFile)
+		}
+		if manager != nil {
+			// ACME CAs present no client certificate when they validate a domain
+			challengeConfig := tlsConfig.Clone()
+			tlsConfig.GetConfigForClient = func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+				if slices.Contains(hello.SupportedProtos, acme.ALPNProto) {
+					return challengeConfig, nil
+				}
+				return nil, nil
+			}
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, manager, nil
+}
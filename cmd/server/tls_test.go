@@ -0,0 +1,149 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/config"
+
+	"golang.org/x/crypto/acme"
+)
+
+// testCertificate issues a certificate signed by parent, or a self-signed CA
+// when parent is nil, and writes it and its key as PEM files in dir
+func testCertificate(t *testing.T, dir, name string, template *x509.Certificate, parent *tls.Certificate) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.Subject = pkix.Name{CommonName: name}
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	signer, signerKey := template, interface{}(key)
+	if parent != nil {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(filepath.Join(dir, name+".crt"), certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".key"), keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("load key pair: %v", err)
+	}
+	return cert
+}
+
+func TestHTTPTLSRequiresClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+	ca := testCertificate(t, dir, "ca", &x509.Certificate{IsCA: true, BasicConstraintsValid: true, KeyUsage: x509.KeyUsageCertSign}, nil)
+	testCertificate(t, dir, "server", &x509.Certificate{
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, &ca)
+	client := testCertificate(t, dir, "client", &x509.Certificate{ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}, &ca)
+
+	tlsConfig, manager, err := newHTTPTLS(config.TLSConfig{
+		CertFile:     filepath.Join(dir, "server.crt"),
+		KeyFile:      filepath.Join(dir, "server.key"),
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+		MinVersion:   "1.3",
+	})
+	if err != nil {
+		t.Fatalf("newHTTPTLS: %v", err)
+	}
+	if manager != nil {
+		t.Fatal("certificate files need no ACME manager")
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.TLS = tlsConfig
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Leaf)
+	get := func(clientConfig *tls.Config) error {
+		httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: clientConfig}}
+		resp, err := httpClient.Get(server.URL)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+
+	if err := get(&tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}); err == nil {
+		t.Error("a client without a certificate was accepted")
+	}
+	if err := get(&tls.Config{RootCAs: roots, Certificates: []tls.Certificate{client}, MinVersion: tls.VersionTLS12, MaxVersion: tls.VersionTLS12}); err == nil {
+		t.Error("a TLS 1.2 client was accepted with a 1.3 minimum")
+	}
+	if err := get(&tls.Config{RootCAs: roots, Certificates: []tls.Certificate{client}, MinVersion: tls.VersionTLS13}); err != nil {
+		t.Errorf("a client with a certificate was refused: %v", err)
+	}
+}
+
+func TestHTTPTLSAutocert(t *testing.T) {
+	dir := t.TempDir()
+	testCertificate(t, dir, "ca", &x509.Certificate{IsCA: true, BasicConstraintsValid: true, KeyUsage: x509.KeyUsageCertSign}, nil)
+
+	tlsConfig, manager, err := newHTTPTLS(config.TLSConfig{
+		AutocertDomains:  []string{"memory.example.com"},
+		AutocertCacheDir: filepath.Join(dir, "autocert"),
+		ClientCAFile:     filepath.Join(dir, "ca.crt"),
+		MinVersion:       "1.2",
+	})
+	if err != nil {
+		t.Fatalf("newHTTPTLS: %v", err)
+	}
+	if manager == nil || tlsConfig.GetCertificate == nil {
+		t.Fatal("autocert domains need an ACME manager serving certificates")
+	}
+	if tlsConfig.ClientAuth != tls.RequireAndVerifyClientCert {
+		t.Errorf("ClientAuth = %v, want client certificates required", tlsConfig.ClientAuth)
+	}
+
+	challenge, err := tlsConfig.GetConfigForClient(&tls.ClientHelloInfo{SupportedProtos: []string{acme.ALPNProto}})
+	if err != nil || challenge == nil || challenge.ClientAuth != tls.NoClientCert {
+		t.Errorf("TLS-ALPN-01 challenges must not require a client certificate, got %+v, %v", challenge, err)
+	}
+	regular, err := tlsConfig.GetConfigForClient(&tls.ClientHelloInfo{SupportedProtos: []string{"h2"}})
+	if err != nil || regular != nil {
+		t.Errorf("regular clients keep the server config, got %+v, %v", regular, err)
+	}
+
+	if _, _, err := newHTTPTLS(config.TLSConfig{CertFile: filepath.Join(dir, "missing.crt"), KeyFile: filepath.Join(dir, "missing.key"), MinVersion: "1.2"}); err == nil {
+		t.Error("a missing certificate was accepted")
+	}
+}
@@ -0,0 +1,183 @@
+// ai-assistant is an example of an assistant that keeps its memory in the
+// memory server through the pkg/client library, instead of in process. Notes
+// it remembers persist across runs, are found again by meaning rather than by
+// substring, carry tags, and are shared by every assistant process pointed at
+// the same server, which serializes their writes.
+//
+//	go run ./examples/ai-assistant remember -tags auth,bug "Login fails when the session cookie is older than the JWT"
+//	go run ./examples/ai-assistant recall "why do users get logged out?"
+//	go run ./examples/ai-assistant recall -tag auth "token expiry"
+//	go run ./examples/ai-assistant chain -query "token expiry" chain.json
+//
+// The chain command runs a pkg/mcp/chain definition, whose steps reach the
+// query as $.input.query, and prints what each step did.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"lerian-mcp-memory/pkg/client"
+	"lerian-mcp-memory/pkg/mcp/chain"
+	mcpclient "lerian-mcp-memory/pkg/mcp/client"
+)
+
+// Assistant remembers and recalls notes through the memory server
+type Assistant struct {
+	memory     *client.Client
+	repository string
+	sessionID  string
+}
+
+// Remember stores a note with its tags
+func (a *Assistant) Remember(ctx context.Context, note string, tags []string) (*client.StoredChunk, error) {
+	return a.memory.StoreChunk(ctx, client.StoreChunkRequest{
+		Repository: a.repository,
+		SessionID:  a.sessionID,
+		Content:    note,
+		Tags:       tags,
+	})
+}
+
+// Recall finds the notes closest in meaning to query. With a tag, only notes
+// carrying it are returned.
+func (a *Assistant) Recall(ctx context.Context, query, tag string, limit int) ([]string, error) {
+	response, err := a.memory.Search(ctx, client.SearchRequest{
+		Repository: a.repository,
+		Query:      query,
+		Limit:      limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	notes := make([]string, 0, len(response.Results))
+	for i := range response.Results {
+		chunk := &response.Results[i].Chunk
+		if tag != "" && !slices.Contains(chunk.Metadata.Tags, tag) {
+			continue
+		}
+		line := fmt.Sprintf("%.2f  %s", response.Results[i].Score, chunk.Content)
+		if len(chunk.Metadata.Tags) > 0 {
+			line += fmt.Sprintf("  [%s]", strings.Join(chunk.Metadata.Tags, ", "))
+		}
+		notes = append(notes, line)
+	}
+	return notes, nil
+}
+
+func main() {
+	server := flag.String("server", "http://localhost:9080/mcp", "MCP endpoint of the memory server")
+	repository := flag.String("repository", "github.com/example/ai-assistant", "repository the notes belong to")
+	session := flag.String("session", "ai-assistant", "session the notes are stored under")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] remember [-tags a,b] NOTE | recall [-tag t] [-limit n] QUERY | chain [-query q] FILE\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	memory, err := client.Connect(ctx, mcpclient.NewHTTPTransport(*server))
+	if err != nil {
+		log.Fatalf("failed to connect to the memory server: %v", err)
+	}
+	defer func() { _ = memory.Close() }()
+	assistant := &Assistant{memory: memory, repository: *repository, sessionID: *session}
+
+	if err := run(ctx, assistant, flag.Arg(0), flag.Args()[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run executes one command of the assistant
+func run(ctx context.Context, assistant *Assistant, command string, args []string) error {
+	switch command {
+	case "remember":
+		flags := flag.NewFlagSet("remember", flag.ExitOnError)
+		tags := flags.String("tags", "", "comma-separated tags")
+		_ = flags.Parse(args)
+		note := strings.Join(flags.Args(), " ")
+		if note == "" {
+			return errors.New("remember needs a note")
+		}
+		var tagList []string
+		for _, tag := range strings.Split(*tags, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tagList = append(tagList, tag)
+			}
+		}
+		stored, err := assistant.Remember(ctx, note, tagList)
+		if err != nil {
+			return fmt.Errorf("failed to remember: %w", err)
+		}
+		fmt.Printf("Remembered %s (%s)\n", stored.ChunkID, stored.Type)
+		return nil
+
+	case "recall":
+		flags := flag.NewFlagSet("recall", flag.ExitOnError)
+		tag := flags.String("tag", "", "only notes with this tag")
+		limit := flags.Int("limit", 5, "most notes to recall")
+		_ = flags.Parse(args)
+		query := strings.Join(flags.Args(), " ")
+		if query == "" {
+			return errors.New("recall needs a query")
+		}
+		notes, err := assistant.Recall(ctx, query, *tag, *limit)
+		if err != nil {
+			return fmt.Errorf("failed to recall: %w", err)
+		}
+		if len(notes) == 0 {
+			fmt.Println("Nothing remembered about that yet")
+		}
+		for _, note := range notes {
+			fmt.Println(note)
+		}
+		return nil
+
+	case "chain":
+		flags := flag.NewFlagSet("chain", flag.ExitOnError)
+		query := flags.String("query", "", "query the steps reach as $.input.query")
+		_ = flags.Parse(args)
+		if flags.NArg() != 1 {
+			return errors.New("chain needs a chain definition file")
+		}
+		definition, err := os.ReadFile(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		c, err := chain.Parse(definition)
+		if err != nil {
+			return err
+		}
+		result, runErr := c.Run(ctx, assistant.memory.Conn(), map[string]interface{}{
+			"query":      *query,
+			"repository": assistant.repository,
+			"session_id": assistant.sessionID,
+		})
+		if result != nil {
+			report, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(report))
+		}
+		return runErr
+
+	default:
+		return fmt.Errorf("unknown command %q: use remember, recall or chain", command)
+	}
+}
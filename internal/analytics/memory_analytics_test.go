@@ -38,6 +38,16 @@ func (m *MockStore) GetByID(_ context.Context, id string) (*types.ConversationCh
 	return chunk, nil
 }
 
+func (m *MockStore) GetByIDs(_ context.Context, ids []string) ([]types.ConversationChunk, error) {
+	results := make([]types.ConversationChunk, 0, len(ids))
+	for _, id := range ids {
+		if chunk, exists := m.chunks[id]; exists {
+			results = append(results, *chunk)
+		}
+	}
+	return results, nil
+}
+
 func (m *MockStore) Update(_ context.Context, chunk *types.ConversationChunk) error {
 	return m.updateChunk(chunk)
 }
@@ -23,8 +23,18 @@ const (
 	contextKeySessionID  contextKey = "session_id"
 	contextKeyUserID     contextKey = "user_id"
 	contextKeyRepository contextKey = "repository"
+	contextKeyPersonID   contextKey = "person_id"
 )
 
+// WithPersonID returns a context whose audit events name the person, from
+// the people directory, behind the operation
+func WithPersonID(ctx context.Context, personID string) context.Context {
+	if personID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKeyPersonID, personID)
+}
+
 // EventType represents the type of audit event
 type EventType string
 
@@ -55,6 +65,8 @@ const (
 	EventTypeSystemShutdown EventType = "system_shutdown"
 	// EventTypeError represents error events
 	EventTypeError EventType = "error"
+	// EventTypeAccessDenied represents calls refused for reaching another tenant's data
+	EventTypeAccessDenied EventType = "access_denied"
 )
 
 // Event represents a single audit log entry
@@ -63,6 +75,7 @@ type Event struct {
 	Timestamp  time.Time              `json:"timestamp"`
 	EventType  EventType              `json:"event_type"`
 	UserID     string                 `json:"user_id,omitempty"`
+	PersonID   string                 `json:"person_id,omitempty"`
 	SessionID  string                 `json:"session_id,omitempty"`
 	Repository string                 `json:"repository,omitempty"`
 	Action     string                 `json:"action"`
@@ -144,6 +157,9 @@ func (al *Logger) LogEvent(ctx context.Context, eventType EventType, action, res
 	if userID, ok := ctx.Value(contextKeyUserID).(string); ok {
 		event.UserID = userID
 	}
+	if personID, ok := ctx.Value(contextKeyPersonID).(string); ok {
+		event.PersonID = personID
+	}
 	if repo, ok := ctx.Value(contextKeyRepository).(string); ok {
 		event.Repository = repo
 	}
@@ -171,6 +187,9 @@ func (al *Logger) LogError(ctx context.Context, eventType EventType, action, res
 	if userID, ok := ctx.Value("user_id").(string); ok {
 		event.UserID = userID
 	}
+	if personID, ok := ctx.Value(contextKeyPersonID).(string); ok {
+		event.PersonID = personID
+	}
 
 	al.addEvent(&event)
 	al.errorCount++
@@ -197,6 +216,9 @@ func (al *Logger) LogEventWithDuration(ctx context.Context, eventType EventType,
 	if userID, ok := ctx.Value("user_id").(string); ok {
 		event.UserID = userID
 	}
+	if personID, ok := ctx.Value(contextKeyPersonID).(string); ok {
+		event.PersonID = personID
+	}
 	if repo, ok := ctx.Value("repository").(string); ok {
 		event.Repository = repo
 	}
@@ -338,8 +360,12 @@ func (al *Logger) GetStatistics() map[string]interface{} {
 	return stats
 }
 
-// Search searches audit logs
+// Search searches audit logs, including events not yet flushed to disk
 func (al *Logger) Search(_ context.Context, criteria *SearchCriteria) ([]Event, error) {
+	al.mu.Lock()
+	al.flush()
+	al.mu.Unlock()
+
 	events := []Event{}
 
 	// Get list of files to search
@@ -454,6 +480,7 @@ type SearchCriteria struct {
 	EventTypes []EventType
 	SessionID  string
 	UserID     string
+	PersonID   string
 	Repository string
 	Resource   string
 	Success    *bool
@@ -497,6 +524,7 @@ func (sc *SearchCriteria) matchesEventTypes(event *Event) bool {
 func (sc *SearchCriteria) matchesStringFields(event *Event) bool {
 	return sc.matchesSessionID(event) &&
 		sc.matchesUserID(event) &&
+		sc.matchesPersonID(event) &&
 		sc.matchesRepository(event) &&
 		sc.matchesResource(event)
 }
@@ -511,6 +539,11 @@ func (sc *SearchCriteria) matchesUserID(event *Event) bool {
 	return sc.UserID == "" || event.UserID == sc.UserID
 }
 
+// matchesPersonID checks if the person ID matches the criteria
+func (sc *SearchCriteria) matchesPersonID(event *Event) bool {
+	return sc.PersonID == "" || event.PersonID == sc.PersonID
+}
+
 // matchesRepository checks if the repository matches the criteria
 func (sc *SearchCriteria) matchesRepository(event *Event) bool {
 	return sc.Repository == "" || event.Repository == sc.Repository
@@ -31,6 +31,7 @@ func TestAuditLogger_LogEvent(t *testing.T) {
 	ctx = context.WithValue(ctx, contextKeySessionID, "test-session")
 	ctx = context.WithValue(ctx, contextKeyUserID, "test-user")
 	ctx = context.WithValue(ctx, contextKeyRepository, "test-repo")
+	ctx = WithPersonID(ctx, "person-1")
 
 	// Log some events
 	logger.LogEvent(ctx, EventTypeMemoryStore, "Store memory chunk", "memory", "chunk-123", map[string]interface{}{
@@ -104,7 +105,7 @@ func TestAuditLogger_Search(t *testing.T) {
 
 	// Log various events
 	logger.LogEvent(ctx, EventTypeMemoryStore, "Store chunk 1", "memory", "chunk-1", nil)
-	logger.LogEvent(ctx, EventTypeMemoryStore, "Store chunk 2", "memory", "chunk-2", nil)
+	logger.LogEvent(WithPersonID(ctx, "person-1"), EventTypeMemoryStore, "Store chunk 2", "memory", "chunk-2", nil)
 	logger.LogEvent(ctx, EventTypeMemorySearch, "Search memories", "memory", "", nil)
 	logger.LogError(ctx, EventTypeError, "Test error", "system", errors.New("test error"), nil)
 
@@ -142,6 +143,20 @@ func TestAuditLogger_Search(t *testing.T) {
 	if len(events) != 1 {
 		t.Errorf("Expected 1 error event, got %d", len(events))
 	}
+
+	// Search for the events of a person
+	criteria = SearchCriteria{
+		PersonID: "person-1",
+	}
+
+	events, err = logger.Search(ctx, &criteria)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if len(events) != 1 || events[0].ResourceID != "chunk-2" {
+		t.Errorf("Expected the person's chunk-2 event, got %v", events)
+	}
 }
 
 func TestAuditLogger_FileRotation(t *testing.T) {
@@ -233,6 +248,7 @@ func TestSearchCriteria_Matches(t *testing.T) {
 		EventType:  EventTypeMemoryStore,
 		SessionID:  "session-1",
 		UserID:     "user-1",
+		PersonID:   "person-1",
 		Repository: "repo-1",
 		Resource:   "memory",
 		Success:    true,
@@ -276,6 +292,20 @@ func TestSearchCriteria_Matches(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "Match person ID",
+			criteria: SearchCriteria{
+				PersonID: "person-1",
+			},
+			want: true,
+		},
+		{
+			name: "No match person ID",
+			criteria: SearchCriteria{
+				PersonID: "person-2",
+			},
+			want: false,
+		},
 		{
 			name: "Match success",
 			criteria: SearchCriteria{
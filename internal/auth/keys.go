@@ -0,0 +1,382 @@
+// Package auth issues and verifies the API keys clients of the HTTP transports
+// authenticate with. Each key may be limited to a list of tools and of
+// projects, expires, and can be rotated or revoked; only a hash of its secret
+// is ever stored.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// KeyPrefix starts every API key, so keys are recognizable in headers and secret scanners
+	KeyPrefix = "mcpk_"
+
+	// AllTools in a key's allowed tools lets it call every tool
+	AllTools = "*"
+
+	// keyIDBytes and keySecretBytes size the random parts of a key
+	keyIDBytes     = 6
+	keySecretBytes = 32
+
+	// MaxRotationGrace bounds how long a rotated key keeps working
+	MaxRotationGrace = 7 * 24 * time.Hour
+)
+
+var (
+	// ErrInvalidKey is returned for keys that are malformed or unknown
+	ErrInvalidKey = errors.New("invalid API key")
+
+	// ErrExpiredKey is returned for keys past their expiry
+	ErrExpiredKey = errors.New("API key has expired")
+
+	// ErrRevokedKey is returned for revoked keys
+	ErrRevokedKey = errors.New("API key has been revoked")
+
+	// ErrKeyNotFound is returned when no key has the given ID
+	ErrKeyNotFound = errors.New("API key not found")
+)
+
+// Key is an issued API key. The secret itself is only returned when the key
+// is created or rotated.
+type Key struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Hash         string     `json:"hash,omitempty"`
+	AllowedTools []string   `json:"allowed_tools"`
+	Projects     []string   `json:"projects,omitempty"` // repositories the key's tenant owns, when tenancy is enforced
+	CreatedAt    time.Time  `json:"created_at"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	RotatedTo    string     `json:"rotated_to,omitempty"`
+}
+
+// Allows reports whether the key may call tool
+func (k *Key) Allows(tool string) bool {
+	return slices.Contains(k.AllowedTools, AllTools) || slices.Contains(k.AllowedTools, tool)
+}
+
+// check reports why the key cannot be used at now, if it cannot
+func (k *Key) check(now time.Time) error {
+	if k.RevokedAt != nil {
+		return ErrRevokedKey
+	}
+	if k.ExpiresAt != nil && !now.Before(*k.ExpiresAt) {
+		return ErrExpiredKey
+	}
+	return nil
+}
+
+// redacted returns a copy of the key without its hash
+func (k *Key) redacted() *Key {
+	copied := *k
+	copied.Hash = ""
+	copied.AllowedTools = slices.Clone(k.AllowedTools)
+	copied.Projects = slices.Clone(k.Projects)
+	return &copied
+}
+
+type keyContextKey struct{}
+
+// WithKey returns a context carrying the API key that authenticated a request
+func WithKey(ctx context.Context, key *Key) context.Context {
+	return context.WithValue(ctx, keyContextKey{}, key)
+}
+
+// KeyFrom returns the API key that authenticated the request served with ctx, or nil
+func KeyFrom(ctx context.Context) *Key {
+	key, _ := ctx.Value(keyContextKey{}).(*Key)
+	return key
+}
+
+// Store keeps API keys, persisted to an optional JSON file so they survive restarts
+type Store struct {
+	mu   sync.Mutex
+	path string
+	keys map[string]*Key // ID -> key
+	now  func() time.Time
+}
+
+// NewStore creates a key store persisted at path; an empty path keeps it in memory
+func NewStore(path string) *Store {
+	return &Store{
+		path: path,
+		keys: make(map[string]*Key),
+		now:  time.Now,
+	}
+}
+
+// Load reads the store from disk. A missing file is not an error.
+func (s *Store) Load() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read API keys: %w", err)
+	}
+
+	var saved []*Key
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("failed to parse API keys: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range saved {
+		s.keys[key.ID] = key
+	}
+	return nil
+}
+
+// Create issues a key named name that may call tools, or every tool when
+// tools holds AllTools, and reach projects. A positive ttl makes the key
+// expire. It returns the secret, which is not stored and cannot be shown
+// again, and the key.
+func (s *Store) Create(name string, tools, projects []string, ttl time.Duration) (string, *Key, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", nil, errors.New("key name is required")
+	}
+	if len(tools) == 0 {
+		return "", nil, errors.New("at least one allowed tool is required (use \"*\" for all tools)")
+	}
+	if ttl < 0 {
+		return "", nil, errors.New("key TTL cannot be negative")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secret, key, err := s.issueLocked(name, tools, projects, ttl)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := s.persistLocked(); err != nil {
+		delete(s.keys, key.ID)
+		return "", nil, err
+	}
+	return secret, key.redacted(), nil
+}
+
+// Authenticate returns the key a secret belongs to, if it is valid and usable
+func (s *Store) Authenticate(secret string) (*Key, error) {
+	id, ok := keyID(secret)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[id]
+	if !ok || subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hashSecret(secret))) != 1 {
+		return nil, ErrInvalidKey
+	}
+	if err := key.check(s.now()); err != nil {
+		return nil, err
+	}
+	return key.redacted(), nil
+}
+
+// Get returns the current state of a key, so a long-lived connection notices
+// when its key is revoked or its tools change
+func (s *Store) Get(id string) (*Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	if err := key.check(s.now()); err != nil {
+		return nil, err
+	}
+	return key.redacted(), nil
+}
+
+// Revoke stops a key from working. Revoking a revoked key is not an error.
+func (s *Store) Revoke(id string) (*Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	if key.RevokedAt != nil {
+		return key.redacted(), nil
+	}
+
+	now := s.now().UTC()
+	key.RevokedAt = &now
+	if err := s.persistLocked(); err != nil {
+		key.RevokedAt = nil
+		return nil, err
+	}
+	return key.redacted(), nil
+}
+
+// Rotate issues a replacement for a key, with the same name, tools, projects
+// and lifetime. The old key keeps working for grace so clients can switch over,
+// or stops at once when grace is zero.
+func (s *Store) Rotate(id string, grace time.Duration) (string, *Key, error) {
+	if grace < 0 || grace > MaxRotationGrace {
+		return "", nil, fmt.Errorf("rotation grace must be between 0 and %s", MaxRotationGrace)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.keys[id]
+	if !ok {
+		return "", nil, ErrKeyNotFound
+	}
+	now := s.now().UTC()
+	if err := old.check(now); err != nil {
+		return "", nil, err
+	}
+
+	var ttl time.Duration
+	if old.ExpiresAt != nil {
+		ttl = old.ExpiresAt.Sub(old.CreatedAt)
+	}
+	secret, replacement, err := s.issueLocked(old.Name, old.AllowedTools, old.Projects, ttl)
+	if err != nil {
+		return "", nil, err
+	}
+
+	previous := *old
+	cutoff := now.Add(grace)
+	if grace == 0 {
+		old.RevokedAt = &now
+	} else if old.ExpiresAt == nil || cutoff.Before(*old.ExpiresAt) {
+		old.ExpiresAt = &cutoff
+	}
+	old.RotatedTo = replacement.ID
+
+	if err := s.persistLocked(); err != nil {
+		*old = previous
+		delete(s.keys, replacement.ID)
+		return "", nil, err
+	}
+	return secret, replacement.redacted(), nil
+}
+
+// List returns every key, newest first, without their hashes
+func (s *Store) List() []*Key {
+	s.mu.Lock()
+	keys := make([]*Key, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key.redacted())
+	}
+	s.mu.Unlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.After(keys[j].CreatedAt)
+		}
+		return keys[i].ID < keys[j].ID
+	})
+	return keys
+}
+
+// issueLocked creates and stores a new key; callers must hold the lock and persist
+func (s *Store) issueLocked(name string, tools, projects []string, ttl time.Duration) (string, *Key, error) {
+	id, err := randomHex(keyIDBytes)
+	if err != nil {
+		return "", nil, err
+	}
+	secretPart, err := randomHex(keySecretBytes)
+	if err != nil {
+		return "", nil, err
+	}
+	secret := KeyPrefix + id + "_" + secretPart
+
+	now := s.now().UTC()
+	key := &Key{
+		ID:           id,
+		Name:         strings.TrimSpace(name),
+		Hash:         hashSecret(secret),
+		AllowedTools: slices.Clone(tools),
+		Projects:     slices.Clone(projects),
+		CreatedAt:    now,
+	}
+	if ttl > 0 {
+		expiresAt := now.Add(ttl)
+		key.ExpiresAt = &expiresAt
+	}
+	s.keys[id] = key
+	return secret, key, nil
+}
+
+// persistLocked writes the keys to disk; callers must hold the lock
+func (s *Store) persistLocked() error {
+	if s.path == "" {
+		return nil
+	}
+
+	keys := make([]*Key, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode API keys: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o750); err != nil {
+		return fmt.Errorf("failed to create API key directory: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write API keys: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// keyID extracts the ID of a key secret: mcpk_<id>_<secret>
+func keyID(secret string) (string, bool) {
+	rest, ok := strings.CutPrefix(secret, KeyPrefix)
+	if !ok {
+		return "", false
+	}
+	id, _, ok := strings.Cut(rest, "_")
+	return id, ok && id != ""
+}
+
+// hashSecret hashes a key secret. Keys are long and random, so a fast hash
+// is as safe as a password hash and keeps authentication cheap.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomHex returns n random bytes hex encoded
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAndAuthenticate(t *testing.T) {
+	s := NewStore("")
+
+	secret, key, err := s.Create("ci-bot", []string{"memory_read"}, nil, 0)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, KeyPrefix+key.ID+"_"))
+	assert.Empty(t, key.Hash, "hashes are never handed out")
+	assert.Nil(t, key.ExpiresAt)
+
+	authenticated, err := s.Authenticate(secret)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, authenticated.ID)
+	assert.True(t, authenticated.Allows("memory_read"))
+	assert.False(t, authenticated.Allows("memory_delete"))
+
+	for _, invalid := range []string{"", "not-a-key", KeyPrefix + key.ID, KeyPrefix + key.ID + "_guess", KeyPrefix + "unknown_" + strings.Repeat("0", 64)} {
+		_, err := s.Authenticate(invalid)
+		assert.ErrorIs(t, err, ErrInvalidKey, invalid)
+	}
+
+	_, _, err = s.Create("", []string{AllTools}, nil, 0)
+	assert.Error(t, err)
+	_, _, err = s.Create("no-tools", nil, nil, 0)
+	assert.Error(t, err)
+
+	admin, _, err := s.Create("admin", []string{AllTools}, nil, 0)
+	require.NoError(t, err)
+	adminKey, err := s.Authenticate(admin)
+	require.NoError(t, err)
+	assert.True(t, adminKey.Allows("auth_create_key"))
+}
+
+func TestExpiryAndRevocation(t *testing.T) {
+	s := NewStore("")
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	secret, key, err := s.Create("temporary", []string{AllTools}, nil, time.Hour)
+	require.NoError(t, err)
+	require.NotNil(t, key.ExpiresAt)
+
+	now = now.Add(time.Hour)
+	_, err = s.Authenticate(secret)
+	assert.ErrorIs(t, err, ErrExpiredKey)
+
+	secret, key, err = s.Create("revoked", []string{AllTools}, nil, 0)
+	require.NoError(t, err)
+	revoked, err := s.Revoke(key.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, revoked.RevokedAt)
+	_, err = s.Authenticate(secret)
+	assert.ErrorIs(t, err, ErrRevokedKey)
+	_, err = s.Get(key.ID)
+	assert.ErrorIs(t, err, ErrRevokedKey, "connections authenticated earlier see the revocation")
+	_, err = s.Revoke("missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestRotate(t *testing.T) {
+	s := NewStore("")
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	oldSecret, old, err := s.Create("deploy", []string{"memory_create", "memory_read"}, []string{"github.com/acme/api"}, 30*24*time.Hour)
+	require.NoError(t, err)
+
+	newSecret, replacement, err := s.Rotate(old.ID, time.Hour)
+	require.NoError(t, err)
+	assert.NotEqual(t, old.ID, replacement.ID)
+	assert.Equal(t, old.AllowedTools, replacement.AllowedTools)
+	assert.Equal(t, []string{"github.com/acme/api"}, replacement.Projects)
+	assert.Equal(t, now.Add(30*24*time.Hour).UTC(), *replacement.ExpiresAt, "the replacement gets the same lifetime")
+
+	_, err = s.Authenticate(oldSecret)
+	assert.NoError(t, err, "the old key works during the grace period")
+	now = now.Add(time.Hour)
+	_, err = s.Authenticate(oldSecret)
+	assert.ErrorIs(t, err, ErrExpiredKey)
+	_, err = s.Authenticate(newSecret)
+	assert.NoError(t, err)
+
+	_, _, err = s.Rotate(old.ID, 0)
+	assert.ErrorIs(t, err, ErrExpiredKey, "expired keys cannot be rotated")
+	secret, _, err := s.Rotate(replacement.ID, 0)
+	require.NoError(t, err)
+	_, err = s.Authenticate(newSecret)
+	assert.ErrorIs(t, err, ErrRevokedKey, "rotating without grace revokes at once")
+	_, err = s.Authenticate(secret)
+	assert.NoError(t, err)
+}
+
+func TestStorePersistsHashesOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	s := NewStore(path)
+	secret, key, err := s.Create("persisted", []string{AllTools}, nil, 0)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), secret)
+
+	reloaded := NewStore(path)
+	require.NoError(t, reloaded.Load())
+	authenticated, err := reloaded.Authenticate(secret)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, authenticated.ID)
+	require.Len(t, reloaded.List(), 1)
+
+	require.NoError(t, NewStore(filepath.Join(t.TempDir(), "missing.json")).Load())
+}
+
+func TestKeyContext(t *testing.T) {
+	assert.Nil(t, KeyFrom(context.Background()))
+	key := &Key{ID: "abc"}
+	assert.Same(t, key, KeyFrom(WithKey(context.Background(), key)))
+}
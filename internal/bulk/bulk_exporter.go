@@ -10,6 +10,7 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"lerian-mcp-memory/internal/progress"
 	"lerian-mcp-memory/internal/storage"
 	"lerian-mcp-memory/pkg/types"
 	"log"
@@ -115,6 +116,10 @@ type ExportMeta struct {
 	Custom        map[string]interface{} `json:"custom,omitempty"`
 }
 
+// exportSteps is the number of phases Export reports progress for: query,
+// format, compress and done
+const exportSteps = 4
+
 // Exporter handles exporting memories to various formats
 type Exporter struct {
 	storage storage.VectorStore
@@ -135,7 +140,10 @@ func NewExporter(vectorStore storage.VectorStore, logger *log.Logger) *Exporter
 
 // Export exports memories based on the provided options
 func (exp *Exporter) Export(ctx context.Context, options *ExportOptions) (*ExportResult, error) {
+	reporter := progress.FromContext(ctx)
+
 	// Query chunks based on filter
+	reporter.Report(0, exportSteps, "Querying memories")
 	chunks, err := exp.queryChunks(ctx, &options.Filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query chunks: %w", err)
@@ -151,6 +159,7 @@ func (exp *Exporter) Export(ctx context.Context, options *ExportOptions) (*Expor
 	metadata := exp.generateMetadata(chunks)
 
 	// Export based on format
+	reporter.Report(1, exportSteps, fmt.Sprintf("Formatting %d memories as %s", len(chunks), options.Format))
 	var data string
 	var dataSize int64
 
@@ -173,6 +182,7 @@ func (exp *Exporter) Export(ctx context.Context, options *ExportOptions) (*Expor
 
 	// Apply compression if requested
 	if options.Compression != CompressionNone {
+		reporter.Report(2, exportSteps, fmt.Sprintf("Compressing %d bytes with %s", dataSize, options.Compression))
 		data, dataSize, err = exp.compressData(data, options.Compression)
 		if err != nil {
 			return nil, fmt.Errorf("failed to compress data: %w", err)
@@ -188,6 +198,7 @@ func (exp *Exporter) Export(ctx context.Context, options *ExportOptions) (*Expor
 		Metadata:      metadata,
 		GeneratedAt:   time.Now().UTC(),
 	}
+	reporter.Report(exportSteps, exportSteps, fmt.Sprintf("Exported %d memories", len(chunks)))
 
 	return result, nil
 }
@@ -453,6 +464,9 @@ func (exp *Exporter) exportMarkdown(chunks []types.ConversationChunk, options *E
 				if len(chunk.Metadata.Tags) > 0 {
 					builder.WriteString(fmt.Sprintf("- Tags: %s\n", strings.Join(chunk.Metadata.Tags, ", ")))
 				}
+				if p := chunk.Metadata.Provenance; p != nil {
+					builder.WriteString(fmt.Sprintf("- Source: %s\n", formatProvenance(p)))
+				}
 				builder.WriteString("\n")
 			}
 
@@ -477,6 +491,7 @@ func (exp *Exporter) exportCSV(chunks []types.ConversationChunk, options *Export
 
 	if options.IncludeMetadata {
 		header = append(header, "branch", "files_modified", "tools_used")
+		header = append(header, provenanceColumns...)
 	}
 
 	if err := writer.Write(header); err != nil {
@@ -505,6 +520,7 @@ func (exp *Exporter) exportCSV(chunks []types.ConversationChunk, options *Export
 				strings.Join(chunk.Metadata.FilesModified, ";"),
 				strings.Join(chunk.Metadata.ToolsUsed, ";"),
 			)
+			record = append(record, provenanceRecord(chunk.Metadata.Provenance)...)
 		}
 
 		if err := writer.Write(record); err != nil {
@@ -521,6 +537,44 @@ func (exp *Exporter) exportCSV(chunks []types.ConversationChunk, options *Export
 	return data, int64(len(data)), nil
 }
 
+// provenanceColumns are the CSV columns carrying chunk provenance
+var provenanceColumns = []string{
+	"source_system", "source_url", "commit_sha", "author", "capture_tool", "capture_tool_version",
+}
+
+// provenanceRecord returns provenance values in provenanceColumns order
+func provenanceRecord(p *types.Provenance) []string {
+	if p == nil {
+		return make([]string, len(provenanceColumns))
+	}
+	return []string{p.SourceSystem, p.SourceURL, p.CommitSHA, p.Author, p.CaptureTool, p.CaptureToolVersion}
+}
+
+// formatProvenance renders provenance as a single human-readable line
+func formatProvenance(p *types.Provenance) string {
+	parts := make([]string, 0, 5)
+	if p.SourceSystem != "" {
+		parts = append(parts, p.SourceSystem)
+	}
+	if p.Author != "" {
+		parts = append(parts, "by "+p.Author)
+	}
+	if p.CommitSHA != "" {
+		parts = append(parts, "at "+p.CommitSHA)
+	}
+	if p.SourceURL != "" {
+		parts = append(parts, p.SourceURL)
+	}
+	if p.CaptureTool != "" {
+		tool := p.CaptureTool
+		if p.CaptureToolVersion != "" {
+			tool += " " + p.CaptureToolVersion
+		}
+		parts = append(parts, "via "+tool)
+	}
+	return strings.Join(parts, " ")
+}
+
 // exportArchive exports chunks as a compressed archive
 func (exp *Exporter) exportArchive(chunks []types.ConversationChunk, options *ExportOptions, metadata *ExportMeta) (archiveData string, dataSize int64, err error) {
 	var buffer bytes.Buffer
@@ -12,6 +12,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"lerian-mcp-memory/internal/progress"
 	"lerian-mcp-memory/pkg/types"
 	"log"
 	"path/filepath"
@@ -49,6 +50,8 @@ type ImportOptions struct {
 	ConflictPolicy   ConflictPolicy   `json:"conflict_policy"`
 	ValidateChunks   bool             `json:"validate_chunks"`
 	Metadata         ImportMetadata   `json:"metadata,omitempty"`
+	// Provenance is stamped on imported chunks that do not carry their own
+	Provenance *types.Provenance `json:"provenance,omitempty"`
 }
 
 // ChunkingStrategy defines how to chunk imported data
@@ -65,6 +68,9 @@ const (
 	ChunkingConversationTurns ChunkingStrategy = "conversation_turns"
 )
 
+// ImporterCaptureTool identifies the bulk importer in chunk provenance
+const ImporterCaptureTool = "bulk_importer"
+
 // ImportMetadata contains metadata about the import
 type ImportMetadata struct {
 	SourceSystem string                 `json:"source_system,omitempty"`
@@ -174,18 +180,18 @@ func (imp *Importer) detectFormat(data string) ImportFormat {
 }
 
 // importJSON imports data from JSON format
-func (imp *Importer) importJSON(_ context.Context, data string, options *ImportOptions, result *ImportResult) (*ImportResult, error) {
+func (imp *Importer) importJSON(ctx context.Context, data string, options *ImportOptions, result *ImportResult) (*ImportResult, error) {
 	// Try to parse as array of chunks first
 	var chunks []types.ConversationChunk
 	if err := json.Unmarshal([]byte(data), &chunks); err == nil {
-		return imp.processChunks(chunks, options, result)
+		return imp.processChunks(ctx, chunks, options, result)
 	}
 
 	// Try to parse as single chunk
 	var chunk types.ConversationChunk
 	if err := json.Unmarshal([]byte(data), &chunk); err == nil {
 		chunks = []types.ConversationChunk{chunk}
-		return imp.processChunks(chunks, options, result)
+		return imp.processChunks(ctx, chunks, options, result)
 	}
 
 	// Try to parse as generic conversation data
@@ -200,21 +206,21 @@ func (imp *Importer) importJSON(_ context.Context, data string, options *ImportO
 		return nil, fmt.Errorf("failed to convert conversation data: %w", err)
 	}
 
-	return imp.processChunks(chunks, options, result)
+	return imp.processChunks(ctx, chunks, options, result)
 }
 
 // importMarkdown imports data from markdown format
-func (imp *Importer) importMarkdown(_ context.Context, data string, options *ImportOptions, result *ImportResult) (*ImportResult, error) {
+func (imp *Importer) importMarkdown(ctx context.Context, data string, options *ImportOptions, result *ImportResult) (*ImportResult, error) {
 	chunks, err := imp.parseMarkdown(data, options)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse markdown: %w", err)
 	}
 
-	return imp.processChunks(chunks, options, result)
+	return imp.processChunks(ctx, chunks, options, result)
 }
 
 // importCSV imports data from CSV format
-func (imp *Importer) importCSV(_ context.Context, data string, options *ImportOptions, result *ImportResult) (*ImportResult, error) {
+func (imp *Importer) importCSV(ctx context.Context, data string, options *ImportOptions, result *ImportResult) (*ImportResult, error) {
 	reader := csv.NewReader(strings.NewReader(data))
 	records, err := reader.ReadAll()
 	if err != nil {
@@ -245,7 +251,7 @@ func (imp *Importer) importCSV(_ context.Context, data string, options *ImportOp
 	}
 
 	result.TotalItems = len(records) - 1
-	return imp.processChunks(chunks, options, result)
+	return imp.processChunks(ctx, chunks, options, result)
 }
 
 // importArchive imports data from archive format (base64 encoded tar.gz or zip)
@@ -454,6 +460,13 @@ func (imp *Importer) parseCSVRecord(header, record []string, options *ImportOpti
 		metadata.Tags = append(metadata.Tags, strings.Split(tags, ";")...)
 	}
 
+	// Provenance columns written by CSV exports
+	provenanceFields := make(map[string]interface{})
+	for key, value := range data {
+		provenanceFields[key] = value
+	}
+	metadata.Provenance = types.ProvenanceFromMap(provenanceFields)
+
 	// Create chunk
 	chunk := &types.ConversationChunk{
 		ID:        "imported_" + strconv.FormatInt(time.Now().Unix(), 10) + "_" + strconv.Itoa(lineNum),
@@ -573,11 +586,19 @@ func (imp *Importer) convertMessageToChunk(message map[string]interface{}, optio
 }
 
 // processChunks processes the parsed chunks with validation and conflict resolution
-func (imp *Importer) processChunks(chunks []types.ConversationChunk, options *ImportOptions, result *ImportResult) (*ImportResult, error) {
+func (imp *Importer) processChunks(ctx context.Context, chunks []types.ConversationChunk, options *ImportOptions, result *ImportResult) (*ImportResult, error) {
 	result.TotalItems = len(chunks)
+	reporter := progress.FromContext(ctx)
+	total := float64(len(chunks))
+	reporter.Report(0, total, fmt.Sprintf("Importing %d items", len(chunks)))
 
 	for i := range chunks {
+		if i > 0 {
+			reporter.Report(float64(i), total, fmt.Sprintf("Processed %d of %d items", i, len(chunks)))
+		}
 		chunk := &chunks[i]
+		imp.stampProvenance(chunk, options)
+
 		// Validate if requested
 		if options.ValidateChunks {
 			if err := chunk.Validate(); err != nil {
@@ -607,10 +628,31 @@ func (imp *Importer) processChunks(chunks []types.ConversationChunk, options *Im
 
 	// Generate summary
 	result.Summary = imp.generateImportSummary(result)
+	reporter.Report(total, total, fmt.Sprintf("Processed %d of %d items", len(chunks), len(chunks)))
 
 	return result, nil
 }
 
+// stampProvenance records import provenance on chunks that arrive without any,
+// keeping original provenance from exports of another memory server intact
+func (imp *Importer) stampProvenance(chunk *types.ConversationChunk, options *ImportOptions) {
+	if chunk.Metadata.Provenance != nil {
+		return
+	}
+
+	provenance := types.Provenance{CaptureTool: ImporterCaptureTool}
+	if options.Provenance != nil {
+		provenance = *options.Provenance
+	}
+	if provenance.SourceSystem == "" {
+		provenance.SourceSystem = options.Metadata.SourceSystem
+	}
+	if provenance.SourceSystem == "" {
+		provenance.SourceSystem = types.SourceSystemImport
+	}
+	chunk.Metadata.Provenance = &provenance
+}
+
 // handleConflict handles conflicts based on the specified policy
 func (imp *Importer) handleConflict(policy ConflictPolicy, result *ImportResult) error {
 	// This would check for existing chunks with same ID or content
@@ -658,7 +700,7 @@ func (imp *Importer) isZipArchive(data []byte) bool {
 }
 
 // extractAndImportTarGz extracts and imports from tar.gz archive
-func (imp *Importer) extractAndImportTarGz(_ context.Context, reader io.Reader, options *ImportOptions, result *ImportResult) (*ImportResult, error) {
+func (imp *Importer) extractAndImportTarGz(ctx context.Context, reader io.Reader, options *ImportOptions, result *ImportResult) (*ImportResult, error) {
 	gzipReader, err := gzip.NewReader(reader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create gzip reader: %w", err)
@@ -713,11 +755,11 @@ func (imp *Importer) extractAndImportTarGz(_ context.Context, reader io.Reader,
 	}
 
 	result.TotalItems = len(allChunks)
-	return imp.processChunks(allChunks, options, result)
+	return imp.processChunks(ctx, allChunks, options, result)
 }
 
 // extractAndImportZip extracts and imports from zip archive
-func (imp *Importer) extractAndImportZip(_ context.Context, data []byte, options *ImportOptions, result *ImportResult) (*ImportResult, error) {
+func (imp *Importer) extractAndImportZip(ctx context.Context, data []byte, options *ImportOptions, result *ImportResult) (*ImportResult, error) {
 	reader := bytes.NewReader(data)
 	zipReader, err := zip.NewReader(reader, int64(len(data)))
 	if err != nil {
@@ -772,7 +814,7 @@ func (imp *Importer) extractAndImportZip(_ context.Context, data []byte, options
 	}
 
 	result.TotalItems = len(allChunks)
-	return imp.processChunks(allChunks, options, result)
+	return imp.processChunks(ctx, allChunks, options, result)
 }
 
 // processArchiveFile processes a single file from an archive
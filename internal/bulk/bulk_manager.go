@@ -124,6 +124,7 @@ type Manager struct {
 	operations    map[string]*Request
 	operationsMux sync.RWMutex
 	logger        *log.Logger
+	running       sync.WaitGroup
 }
 
 // NewManager creates a new bulk operations manager
@@ -171,7 +172,9 @@ func (m *Manager) SubmitOperation(ctx context.Context, req *Request) (*Progress,
 	}
 
 	// Start processing asynchronously
+	m.running.Add(1)
 	go func() {
+		defer m.running.Done()
 		if err := m.processOperation(ctx, req.ID); err != nil {
 			m.logger.Printf("Error processing bulk operation %s: %v", req.ID, err)
 		}
@@ -180,6 +183,22 @@ func (m *Manager) SubmitOperation(ctx context.Context, req *Request) (*Progress,
 	return initialProgress, nil
 }
 
+// Wait blocks until every submitted operation finished or ctx is done. No
+// operations may be submitted while it waits.
+func (m *Manager) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		m.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // GetProgress returns the current progress of an operation
 func (m *Manager) GetProgress(operationID string) (*Progress, error) {
 	m.operationsMux.RLock()
@@ -53,6 +53,9 @@ func (s *SimpleMockStorage) Search(_ context.Context, _ *types.MemoryQuery, _ []
 func (s *SimpleMockStorage) GetByID(_ context.Context, _ string) (*types.ConversationChunk, error) {
 	return nil, errors.New("not found")
 }
+func (s *SimpleMockStorage) GetByIDs(_ context.Context, _ []string) ([]types.ConversationChunk, error) {
+	return []types.ConversationChunk{}, nil
+}
 func (s *SimpleMockStorage) ListByRepository(_ context.Context, _ string, _, _ int) ([]types.ConversationChunk, error) {
 	return []types.ConversationChunk{}, nil
 }
@@ -153,6 +156,37 @@ func TestBulkManager_SubmitStoreOperation(t *testing.T) {
 	}
 }
 
+func TestBulkManager_Wait(t *testing.T) {
+	ctx := context.Background()
+	vectorStore := NewSimpleMockStorage()
+	manager := NewManager(vectorStore, nil)
+
+	request := Request{
+		Operation: OperationStore,
+		Chunks: []types.ConversationChunk{
+			{ID: "wait-chunk", SessionID: "test-session", Content: "Test content", Type: types.ChunkTypeDiscussion, Timestamp: time.Now()},
+		},
+		Options: Options{BatchSize: 10, MaxConcurrency: 1},
+	}
+	progress, err := manager.SubmitOperation(ctx, &request)
+	if err != nil {
+		t.Fatalf("SubmitOperation failed: %v", err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := manager.Wait(waitCtx); err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	progress, err = manager.GetProgress(progress.OperationID)
+	if err != nil {
+		t.Fatalf("GetProgress failed: %v", err)
+	}
+	if progress.Status != StatusCompleted {
+		t.Errorf("Expected operation to be completed after Wait, got %s", progress.Status)
+	}
+}
+
 func TestBulkManager_GetProgress(t *testing.T) {
 	ctx := context.Background()
 	vectorStore := NewSimpleMockStorage()
@@ -0,0 +1,209 @@
+// Package chaos injects configurable failures and latency into the server's
+// dependencies so resilience features (retries, circuit breakers, search
+// fallbacks) can be exercised under failure.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fault injection targets
+const (
+	// TargetVectorStore covers the storage backend (Qdrant or the keyword
+	// store), which is the server's only database
+	TargetVectorStore = "vector_store"
+	TargetEmbeddings  = "embeddings"
+)
+
+// MaxLatency bounds the latency a fault may add to a call
+const MaxLatency = time.Minute
+
+// ErrInjectedFault is matched by every error produced by the injector
+var ErrInjectedFault = errors.New("injected fault")
+
+// validTargets lists the targets faults can be set on
+var validTargets = map[string]bool{TargetVectorStore: true, TargetEmbeddings: true}
+
+// Targets returns the fault injection targets
+func Targets() []string {
+	return []string{TargetVectorStore, TargetEmbeddings}
+}
+
+// FaultError is returned for calls the injector fails. It reads as a
+// transient outage so the retry layers treat it like a real one.
+type FaultError struct {
+	Target    string
+	Operation string
+}
+
+func (e *FaultError) Error() string {
+	return fmt.Sprintf("injected fault in %s.%s: service unavailable (503)", e.Target, e.Operation)
+}
+
+// Unwrap makes errors.Is(err, ErrInjectedFault) match
+func (e *FaultError) Unwrap() error {
+	return ErrInjectedFault
+}
+
+// Temporary reports the fault as transient
+func (e *FaultError) Temporary() bool {
+	return true
+}
+
+// Fault describes the failures injected into a target
+type Fault struct {
+	// ErrorRate is the probability, from 0 to 1, that a call fails
+	ErrorRate float64 `json:"error_rate"`
+	// LatencyMs is added to every call before it runs
+	LatencyMs int `json:"latency_ms"`
+}
+
+// Validate checks the fault is within bounds
+func (f Fault) Validate() error {
+	if f.ErrorRate < 0 || f.ErrorRate > 1 {
+		return fmt.Errorf("error rate must be between 0 and 1, got %v", f.ErrorRate)
+	}
+	if f.LatencyMs < 0 || time.Duration(f.LatencyMs)*time.Millisecond > MaxLatency {
+		return fmt.Errorf("latency must be between 0 and %d ms, got %d", MaxLatency.Milliseconds(), f.LatencyMs)
+	}
+	return nil
+}
+
+// TargetStats counts what the injector did to a target's calls
+type TargetStats struct {
+	Calls          int64 `json:"calls"`
+	InjectedErrors int64 `json:"injected_errors"`
+	DelayedCalls   int64 `json:"delayed_calls"`
+}
+
+// TargetStatus is the fault and counters of one target
+type TargetStatus struct {
+	Target string      `json:"target"`
+	Fault  Fault       `json:"fault"`
+	Stats  TargetStats `json:"stats"`
+}
+
+// Status is a snapshot of the injector
+type Status struct {
+	Active  bool           `json:"active"`
+	Targets []TargetStatus `json:"targets"`
+}
+
+// Injector decides, per call, whether to delay or fail it. Faults can be
+// changed at runtime; while the injector is inactive every call passes
+// through untouched.
+type Injector struct {
+	mutex  sync.Mutex
+	active bool
+	faults map[string]Fault
+	stats  map[string]*TargetStats
+}
+
+// NewInjector creates an active injector with no faults
+func NewInjector() *Injector {
+	stats := make(map[string]*TargetStats, len(validTargets))
+	for target := range validTargets {
+		stats[target] = &TargetStats{}
+	}
+	return &Injector{
+		active: true,
+		faults: make(map[string]Fault),
+		stats:  stats,
+	}
+}
+
+// SetFault replaces the fault injected into target
+func (i *Injector) SetFault(target string, fault Fault) error {
+	if !validTargets[target] {
+		return fmt.Errorf("unknown fault target %q (must be %s or %s)", target, TargetVectorStore, TargetEmbeddings)
+	}
+	if err := fault.Validate(); err != nil {
+		return err
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.faults[target] = fault
+	return nil
+}
+
+// ClearFault removes the fault from target, or from every target when target is empty
+func (i *Injector) ClearFault(target string) error {
+	if target != "" && !validTargets[target] {
+		return fmt.Errorf("unknown fault target %q (must be %s or %s)", target, TargetVectorStore, TargetEmbeddings)
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if target == "" {
+		i.faults = make(map[string]Fault)
+		return nil
+	}
+	delete(i.faults, target)
+	return nil
+}
+
+// SetActive turns injection on or off without forgetting the configured faults
+func (i *Injector) SetActive(active bool) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.active = active
+}
+
+// Status returns the faults and counters of every target
+func (i *Injector) Status() Status {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	status := Status{Active: i.active, Targets: make([]TargetStatus, 0, len(i.stats))}
+	for target, stats := range i.stats {
+		status.Targets = append(status.Targets, TargetStatus{Target: target, Fault: i.faults[target], Stats: *stats})
+	}
+	sort.Slice(status.Targets, func(a, b int) bool {
+		return status.Targets[a].Target < status.Targets[b].Target
+	})
+	return status
+}
+
+// Inject applies the target's fault to one call: it waits for the configured
+// latency and then fails the call with the configured probability. It
+// returns the context error if ctx ends while waiting.
+func (i *Injector) Inject(ctx context.Context, target, operation string) error {
+	i.mutex.Lock()
+	fault, ok := i.faults[target]
+	if !i.active || !ok {
+		i.mutex.Unlock()
+		return nil
+	}
+	stats := i.stats[target]
+	stats.Calls++
+	fail := fault.ErrorRate > 0 && rand.Float64() < fault.ErrorRate //nolint:gosec // Fault sampling is not security sensitive
+	if fail {
+		stats.InjectedErrors++
+	}
+	if fault.LatencyMs > 0 {
+		stats.DelayedCalls++
+	}
+	i.mutex.Unlock()
+
+	if fault.LatencyMs > 0 {
+		timer := time.NewTimer(time.Duration(fault.LatencyMs) * time.Millisecond)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+
+	if fail {
+		return &FaultError{Target: target, Operation: operation}
+	}
+	return nil
+}
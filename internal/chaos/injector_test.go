@@ -0,0 +1,91 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestInjectorFailsAtConfiguredRate(t *testing.T) {
+	ctx := context.Background()
+	injector := NewInjector()
+
+	if err := injector.Inject(ctx, TargetVectorStore, "Search"); err != nil {
+		t.Fatalf("expected no fault without configuration, got %v", err)
+	}
+
+	if err := injector.SetFault(TargetVectorStore, Fault{ErrorRate: 1}); err != nil {
+		t.Fatal(err)
+	}
+	err := injector.Inject(ctx, TargetVectorStore, "Search")
+	if !errors.Is(err, ErrInjectedFault) {
+		t.Fatalf("expected injected fault, got %v", err)
+	}
+	var faultErr *FaultError
+	if !errors.As(err, &faultErr) || !faultErr.Temporary() || faultErr.Operation != "Search" {
+		t.Fatalf("expected temporary fault for Search, got %#v", err)
+	}
+	if err := injector.Inject(ctx, TargetEmbeddings, "GenerateEmbedding"); err != nil {
+		t.Fatalf("expected other targets unaffected, got %v", err)
+	}
+
+	injector.SetActive(false)
+	if err := injector.Inject(ctx, TargetVectorStore, "Search"); err != nil {
+		t.Fatalf("expected no fault while inactive, got %v", err)
+	}
+
+	injector.SetActive(true)
+	if err := injector.ClearFault(""); err != nil {
+		t.Fatal(err)
+	}
+	if err := injector.Inject(ctx, TargetVectorStore, "Search"); err != nil {
+		t.Fatalf("expected no fault after clear, got %v", err)
+	}
+
+	status := injector.Status()
+	if !status.Active || len(status.Targets) != 2 {
+		t.Fatalf("unexpected status %#v", status)
+	}
+	if stats := status.Targets[1].Stats; status.Targets[1].Target != TargetVectorStore || stats.Calls != 1 || stats.InjectedErrors != 1 {
+		t.Fatalf("unexpected vector store stats %#v", status.Targets[1])
+	}
+}
+
+func TestInjectorLatency(t *testing.T) {
+	injector := NewInjector()
+	if err := injector.SetFault(TargetEmbeddings, Fault{LatencyMs: 20}); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if err := injector.Inject(context.Background(), TargetEmbeddings, "GenerateEmbedding"); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Fatalf("expected at least 20ms latency, got %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := injector.Inject(ctx, TargetEmbeddings, "GenerateEmbedding"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context error, got %v", err)
+	}
+}
+
+func TestInjectorRejectsInvalidFaults(t *testing.T) {
+	injector := NewInjector()
+	for _, tc := range []struct {
+		target string
+		fault  Fault
+	}{
+		{"database", Fault{ErrorRate: 0.5}},
+		{TargetVectorStore, Fault{ErrorRate: 1.5}},
+		{TargetVectorStore, Fault{LatencyMs: -1}},
+		{TargetVectorStore, Fault{LatencyMs: int(2 * MaxLatency / time.Millisecond)}},
+	} {
+		if err := injector.SetFault(tc.target, tc.fault); err == nil {
+			t.Errorf("expected error for %s %+v", tc.target, tc.fault)
+		}
+	}
+}
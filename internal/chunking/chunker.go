@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"lerian-mcp-memory/internal/config"
 	"lerian-mcp-memory/internal/embeddings"
+	"lerian-mcp-memory/internal/llm"
+	"lerian-mcp-memory/internal/logging"
 	"lerian-mcp-memory/pkg/types"
 	"os"
 	"regexp"
@@ -23,10 +25,18 @@ const (
 	SignificanceLow    = "low"
 )
 
+// Enhancer enriches a chunk before it is embedded, e.g. with an AI-written
+// summary, extracted decisions and suggested tags
+type Enhancer interface {
+	Enhance(ctx context.Context, chunk *types.ConversationChunk) error
+}
+
 // Service handles the intelligent chunking of conversations
 type Service struct {
 	config           *config.ChunkingConfig
 	embeddingService embeddings.EmbeddingService
+	summarizer       llm.Provider // optional; extractive summaries are used when nil
+	enhancer         Enhancer     // optional AI enrichment run before embedding
 
 	// State tracking for smart chunking
 	currentContext *types.ChunkingContext
@@ -60,6 +70,17 @@ func NewService(cfg *config.ChunkingConfig, embeddingService embeddings.Embeddin
 	return cs
 }
 
+// SetSummarizer sets the LLM provider used for abstractive summaries
+func (cs *Service) SetSummarizer(provider llm.Provider) {
+	cs.summarizer = provider
+}
+
+// SetEnhancer enables AI enrichment of new chunks. The enhancer replaces LLM
+// summarization, so each chunk costs a single LLM call.
+func (cs *Service) SetEnhancer(enhancer Enhancer) {
+	cs.enhancer = enhancer
+}
+
 // initializePatterns sets up regex patterns for content analysis
 func (cs *Service) initializePatterns() {
 	// Problem identification patterns
@@ -192,9 +213,15 @@ func (cs *Service) CreateChunk(ctx context.Context, sessionID, content string, m
 		return nil, fmt.Errorf("failed to create chunk: %w", err)
 	}
 
-	// Generate summary
-	summary := cs.generateSummary(ctx, content, chunkType)
-	chunk.Summary = summary
+	// Generate summary, or let the enhancer write it along with decisions and tags
+	if cs.enhancer != nil {
+		chunk.Summary = cs.generateSimpleSummary(content)
+		if err := cs.enhancer.Enhance(ctx, chunk); err != nil {
+			logging.Warn("AI enrichment failed, storing chunk without it", "chunk_id", chunk.ID, "error", err)
+		}
+	} else {
+		chunk.Summary = cs.generateSummary(ctx, content, chunkType)
+	}
 
 	// Generate embeddings
 	embedding, err := cs.embeddingService.GenerateEmbedding(ctx, cs.prepareContentForEmbedding(chunk))
@@ -468,11 +495,26 @@ func (cs *Service) assessOutcome(content string) types.Outcome {
 	return types.OutcomeInProgress // Default assumption
 }
 
-// generateSummary creates an AI-powered summary of the content
-func (cs *Service) generateSummary(_ context.Context, content string, _ types.ChunkType) string {
-	// For now, implement a simple extractive summary
-	// In a full implementation, this would use an LLM for abstractive summarization
-	return cs.generateSimpleSummary(content)
+// minLLMSummaryLength is the content length below which an extractive summary is good enough
+const minLLMSummaryLength = 300
+
+// generateSummary creates an AI-powered summary of the content, falling back
+// to an extractive summary when no LLM is configured or the call fails
+func (cs *Service) generateSummary(ctx context.Context, content string, chunkType types.ChunkType) string {
+	if cs.summarizer == nil || len(content) < minLLMSummaryLength {
+		return cs.generateSimpleSummary(content)
+	}
+
+	resp, err := cs.summarizer.Complete(ctx, llm.Request{
+		System:    "You summarize engineering conversation notes for a searchable memory store. Reply with one or two plain sentences, no preamble.",
+		Prompt:    fmt.Sprintf("Summarize this %s:\n\n%s", strings.ReplaceAll(string(chunkType), "_", " "), content),
+		MaxTokens: 120,
+	})
+	if err != nil || resp.Text == "" {
+		logging.Warn("LLM summarization failed, using extractive summary", "provider", cs.summarizer.Name(), "error", err)
+		return cs.generateSimpleSummary(content)
+	}
+	return resp.Text
 }
 
 // generateSimpleSummary creates a simple extractive summary
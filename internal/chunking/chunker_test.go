@@ -2,9 +2,12 @@ package chunking
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/llm"
 	"lerian-mcp-memory/pkg/types"
 )
 
@@ -122,3 +125,64 @@ func TestCreateChunk(t *testing.T) {
 		t.Errorf("Expected chunk type %v, got %v", types.ChunkTypeSolution, chunk.Type)
 	}
 }
+
+// stubSummarizer returns a fixed summary or error
+type stubSummarizer struct {
+	text string
+	err  error
+}
+
+func (s *stubSummarizer) Name() string  { return "stub" }
+func (s *stubSummarizer) Model() string { return "stub-1" }
+
+func (s *stubSummarizer) Complete(_ context.Context, _ llm.Request) (*llm.Response, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &llm.Response{Text: s.text}, nil
+}
+
+func TestGenerateSummaryWithLLM(t *testing.T) {
+	cs := NewService(&config.ChunkingConfig{MaxContentLength: 10000}, &MockEmbeddingService{})
+	ctx := context.Background()
+	long := "Investigated intermittent test failures in the payment service.\n" + strings.Repeat("More detail about the investigation. ", 20)
+
+	cs.SetSummarizer(&stubSummarizer{text: "Fixed flaky payment tests."})
+	if got := cs.generateSummary(ctx, long, types.ChunkTypeSolution); got != "Fixed flaky payment tests." {
+		t.Errorf("Expected LLM summary, got %q", got)
+	}
+	if got := cs.generateSummary(ctx, "short note about tests", types.ChunkTypeSolution); got != "short note about tests" {
+		t.Errorf("Expected short content to skip the LLM, got %q", got)
+	}
+
+	cs.SetSummarizer(&stubSummarizer{err: errors.New("rate limited")})
+	if got := cs.generateSummary(ctx, long, types.ChunkTypeSolution); got != "Investigated intermittent test failures in the payment service." {
+		t.Errorf("Expected extractive fallback, got %q", got)
+	}
+}
+
+// taggingEnhancer overwrites the summary and adds a tag
+type taggingEnhancer struct{}
+
+func (taggingEnhancer) Enhance(_ context.Context, chunk *types.ConversationChunk) error {
+	chunk.Summary = "enhanced summary"
+	chunk.Metadata.Tags = append(chunk.Metadata.Tags, "enhanced")
+	return nil
+}
+
+func TestCreateChunkRunsEnhancer(t *testing.T) {
+	cs := NewService(&config.ChunkingConfig{MaxContentLength: 10000}, &MockEmbeddingService{})
+	cs.SetSummarizer(&stubSummarizer{err: errors.New("summarizer must not be called when an enhancer is set")})
+	cs.SetEnhancer(taggingEnhancer{})
+
+	chunk, err := cs.CreateChunk(context.Background(), "session-1", "I fixed the issue by updating the dependencies", &types.ChunkMetadata{Repository: "test-repo"})
+	if err != nil {
+		t.Fatalf("CreateChunk failed: %v", err)
+	}
+	if chunk.Summary != "enhanced summary" {
+		t.Errorf("Expected enhanced summary, got %q", chunk.Summary)
+	}
+	if len(chunk.Metadata.Tags) == 0 || chunk.Metadata.Tags[len(chunk.Metadata.Tags)-1] != "enhanced" {
+		t.Errorf("Expected enhancer tag, got %v", chunk.Metadata.Tags)
+	}
+}
@@ -3,32 +3,200 @@
 package config
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"net"
+	"net/netip"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 // Config represents the application configuration
 type Config struct {
-	Server   ServerConfig   `json:"server"`
-	Qdrant   QdrantConfig   `json:"qdrant"`
-	OpenAI   OpenAIConfig   `json:"openai"`
-	Storage  StorageConfig  `json:"storage"`
-	Chunking ChunkingConfig `json:"chunking"`
-	Search   SearchConfig   `json:"search"`
-	Logging  LoggingConfig  `json:"logging"`
+	Server    ServerConfig    `json:"server"`
+	Qdrant    QdrantConfig    `json:"qdrant"`
+	PgVector  PgVectorConfig  `json:"pgvector"`
+	OpenAI    OpenAIConfig    `json:"openai"`
+	Embedding EmbeddingConfig `json:"embedding"`
+	Storage   StorageConfig   `json:"storage"`
+	Chunking  ChunkingConfig  `json:"chunking"`
+	Search    SearchConfig    `json:"search"`
+	Logging   LoggingConfig   `json:"logging"`
+	Digest    DigestConfig    `json:"digest"`
+	LLM       LLMConfig       `json:"llm"`
+	Security  SecurityConfig  `json:"security"`
+	Chaos     ChaosConfig     `json:"chaos"`
+	RateLimit RateLimitConfig `json:"rate_limit"`
+	QoS       QoSConfig       `json:"qos"`
+	PageSync  PageSyncConfig  `json:"page_sync"`
+	SlackSync SlackSyncConfig `json:"slack_sync"`
+	Site      SiteConfig      `json:"site"`
+	HTTPTools HTTPToolsConfig `json:"http_tools"`
+	Usage     UsageConfig     `json:"usage"`
+	Demo      DemoConfig      `json:"demo"`
+
+	Intelligence IntelligenceConfig `json:"intelligence"`
 }
 
+// Embedding and storage providers
+const (
+	EmbeddingProviderOpenAI = "openai"
+	// EmbeddingProviderNone runs the server in lite mode: no embeddings, keyword search only
+	EmbeddingProviderNone = "none"
+	// EmbeddingProviderFake produces deterministic hash-based embeddings without network calls, for tests and demos
+	EmbeddingProviderFake = "fake"
+
+	StorageProviderQdrant  = "qdrant"
+	StorageProviderKeyword = "keyword"
+	// StorageProviderPgVector keeps chunks and their embeddings in PostgreSQL with the pgvector extension
+	StorageProviderPgVector = "pgvector"
+	// StorageProviderLocal keeps chunks on disk with an in-process vector index, for running offline
+	StorageProviderLocal = "local"
+)
+
+// Read preferences for Qdrant read replicas
+const (
+	// ReadPreferencePrimary sends every read to the primary
+	ReadPreferencePrimary = "primary"
+	// ReadPreferenceReplica sends search reads to replicas, falling back to the primary
+	ReadPreferenceReplica = "replica"
+)
+
+// Vector quantization modes for Qdrant collections
+const (
+	// QuantizationNone stores full float32 vectors only
+	QuantizationNone = "none"
+	// QuantizationScalar adds int8 copies of vectors, a quarter of their size
+	QuantizationScalar = "scalar"
+	// QuantizationProduct adds product-quantized copies, 4 to 64 times smaller
+	QuantizationProduct = "product"
+)
+
+// Search modes, mirroring types.SearchMode
+const (
+	SearchModeVector  = "vector"
+	SearchModeKeyword = "keyword"
+	SearchModeHybrid  = "hybrid"
+)
+
+// searchModes lists the valid search modes
+var searchModes = []string{SearchModeVector, SearchModeKeyword, SearchModeHybrid}
+
+// Text analyzers that turn memories and queries into keyword search terms
+const (
+	// AnalyzerStandard normalizes Unicode, folds case and diacritics and
+	// splits CJK text into overlapping bigrams
+	AnalyzerStandard = "standard"
+	// AnalyzerAccentSensitive is AnalyzerStandard keeping diacritics, for
+	// languages where they tell words apart
+	AnalyzerAccentSensitive = "accent_sensitive"
+	// AnalyzerSimple lowercases and splits on anything but letters and digits
+	AnalyzerSimple = "simple"
+)
+
+// searchAnalyzers lists the valid text analyzers
+var searchAnalyzers = []string{AnalyzerStandard, AnalyzerAccentSensitive, AnalyzerSimple}
+
+// LLM providers for intelligence features
+const (
+	LLMProviderNone      = "none"
+	LLMProviderOpenAI    = "openai"
+	LLMProviderAnthropic = "anthropic"
+	LLMProviderGemini    = "gemini"
+	LLMProviderOllama    = "ollama"
+)
+
 // ServerConfig represents server configuration
 type ServerConfig struct {
 	Port         int    `json:"port"`
 	Host         string `json:"host"`
 	ReadTimeout  int    `json:"read_timeout_seconds"`
 	WriteTimeout int    `json:"write_timeout_seconds"`
+	// BatchConcurrency is how many requests of a JSON-RPC batch run at once
+	BatchConcurrency int `json:"batch_concurrency"`
+	// MaxBatchSize is the most requests a JSON-RPC batch may hold
+	MaxBatchSize int `json:"max_batch_size"`
+	// ListPageSize is how many items a tools/list, resources/list or
+	// prompts/list page holds; 0 lists everything in one response
+	ListPageSize int `json:"list_page_size"`
+	// MaxResponseBytes bounds the JSON of a tool result. Larger results keep
+	// their structure but lose the middle of their biggest lists, which the
+	// client pages through with a continuation cursor; 0 sends results whole.
+	MaxResponseBytes int `json:"max_response_bytes"`
+	// ResponseLimits overrides MaxResponseBytes for the named tools
+	ResponseLimits map[string]int `json:"response_limits,omitempty"`
+	// ResultCacheTTL is how long the elided parts of truncated results stay
+	// cached for continue_result
+	ResultCacheTTL int `json:"result_cache_ttl_seconds"`
+	// GRPC serves MCP over gRPC alongside HTTP
+	GRPC GRPCConfig `json:"grpc"`
+	// TLS serves the HTTP transport over HTTPS
+	TLS TLSConfig `json:"tls"`
+	// Name and Version identify the server to MCP clients on initialize
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Transport is how MCP is served when cmd/server has no -mode flag:
+	// "stdio" or "http"
+	Transport    string             `json:"transport"`
+	Capabilities CapabilitiesConfig `json:"capabilities"`
+}
+
+// CapabilitiesConfig toggles the optional MCP capabilities the server
+// advertises and serves. Tools are always served.
+type CapabilitiesConfig struct {
+	Resources bool `json:"resources"`
+	Prompts   bool `json:"prompts"`
+}
+
+// Transports the server serves MCP over
+const (
+	TransportStdio = "stdio"
+	TransportHTTP  = "http"
+)
+
+// TLSConfig configures HTTPS for the HTTP transport. A certificate comes either
+// from files or from an ACME CA such as Let's Encrypt for AutocertDomains; with a
+// client CA, clients must present a certificate signed by it (mTLS).
+type TLSConfig struct {
+	CertFile     string `json:"cert_file,omitempty"`
+	KeyFile      string `json:"key_file,omitempty"`
+	ClientCAFile string `json:"client_ca_file,omitempty"`
+	// MinVersion is the oldest TLS version accepted: "1.2" or "1.3"
+	MinVersion string `json:"min_version"`
+	// AutocertDomains are the host names certificates are requested for
+	AutocertDomains []string `json:"autocert_domains,omitempty"`
+	AutocertEmail   string   `json:"autocert_email,omitempty"`
+	// AutocertCacheDir keeps issued certificates across restarts
+	AutocertCacheDir string `json:"autocert_cache_dir"`
+	// AutocertHTTPAddress serves ACME HTTP-01 challenges, and redirects other
+	// requests to HTTPS; empty relies on TLS-ALPN-01 challenges alone
+	AutocertHTTPAddress string `json:"autocert_http_address,omitempty"`
+}
+
+// Enabled reports whether the HTTP transport serves HTTPS
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != "" || len(t.AutocertDomains) > 0
+}
+
+// GRPCConfig configures the gRPC transport. Without a certificate it serves
+// plaintext, for load balancers that terminate TLS; with a client CA it
+// requires client certificates (mTLS). gRPC clients are operators: they are
+// neither bound by API key tool scopes nor held to a tenant.
+type GRPCConfig struct {
+	Enabled      bool   `json:"enabled"`
+	Address      string `json:"address"`
+	TLSCertFile  string `json:"tls_cert_file,omitempty"`
+	TLSKeyFile   string `json:"tls_key_file,omitempty"`
+	ClientCAFile string `json:"client_ca_file,omitempty"`
 }
 
 // QdrantConfig represents Qdrant vector database configuration
@@ -42,6 +210,62 @@ type QdrantConfig struct {
 	HealthCheck    bool         `json:"health_check"`
 	RetryAttempts  int          `json:"retry_attempts"`
 	TimeoutSeconds int          `json:"timeout_seconds"`
+
+	ReadReplicas ReadReplicaConfig  `json:"read_replicas"`
+	Quantization QuantizationConfig `json:"quantization"`
+}
+
+// PgVectorConfig represents the PostgreSQL pgvector store configuration.
+// Chunks live in Table and relationships in Table_relationships; the store
+// creates both, with an HNSW index over the embeddings, when it starts.
+type PgVectorConfig struct {
+	DSN       string `json:"-"` // Connection string; may carry a password
+	Table     string `json:"table"`
+	Dimension int    `json:"dimension"`
+	// HNSWM and HNSWEfConstruction tune the HNSW index: links per node and
+	// the candidate list size while building. Larger values improve recall
+	// at the cost of index size and build time.
+	HNSWM              int `json:"hnsw_m"`
+	HNSWEfConstruction int `json:"hnsw_ef_construction"`
+	// HNSWEfSearch is the candidate list size of searches; 0 keeps the server's setting
+	HNSWEfSearch int `json:"hnsw_ef_search"`
+}
+
+// QuantizationConfig compresses stored embeddings. Qdrant keeps a quantized
+// copy of every vector for search, optionally in RAM with the originals on
+// disk, and re-scores the best candidates with the originals.
+type QuantizationConfig struct {
+	Mode string `json:"mode"`
+	// Compression is the product quantization ratio: x4, x8, x16, x32 or x64
+	Compression string `json:"compression"`
+	// Quantile bounds the values scalar quantization covers, dropping outliers
+	Quantile float64 `json:"quantile"`
+	// AlwaysRAM keeps quantized vectors in RAM and moves the originals to disk
+	AlwaysRAM bool `json:"always_ram"`
+	// Rescore re-ranks search candidates with the original vectors
+	Rescore bool `json:"rescore"`
+	// Oversampling is how many times more candidates than needed are re-scored
+	Oversampling float64 `json:"oversampling"`
+	// Collections overrides Mode per collection name
+	Collections map[string]string `json:"collections,omitempty"`
+}
+
+// ForCollection returns the quantization settings of a collection
+func (q QuantizationConfig) ForCollection(collection string) QuantizationConfig {
+	if mode, ok := q.Collections[collection]; ok {
+		q.Mode = mode
+	}
+	q.Collections = nil
+	return q
+}
+
+// ReadReplicaConfig routes search reads to Qdrant read replicas. Writes always
+// go to the primary. A replica may lag the primary by up to MaxStalenessMs, so
+// reads within that window of a write are served by the primary instead.
+type ReadReplicaConfig struct {
+	Hosts          []string `json:"hosts"` // host or host:port; the primary's port is the default
+	ReadPreference string   `json:"read_preference"`
+	MaxStalenessMs int      `json:"max_staleness_ms"`
 }
 
 // DockerConfig represents Docker-specific configuration
@@ -56,19 +280,61 @@ type DockerConfig struct {
 type OpenAIConfig struct {
 	APIKey         string  `json:"-"` // Never serialize API key
 	EmbeddingModel string  `json:"embedding_model"`
+	BaseURL        string  `json:"base_url,omitempty"` // OpenAI-compatible endpoint, defaults to api.openai.com
 	MaxTokens      int     `json:"max_tokens"`
 	Temperature    float64 `json:"temperature"`
 	RequestTimeout int     `json:"request_timeout_seconds"`
 	RateLimitRPM   int     `json:"rate_limit_rpm"`
 }
 
+// EmbeddingConfig selects the embedding provider and how long content is embedded.
+// Content longer than PassageRunes is also split into overlapping passages with
+// their own vectors, so searches can match any section of it; 0 disables passages.
+// Concurrent single-text requests are coalesced into provider batch requests of
+// up to BatchSize texts and BatchMaxTokens tokens; a BatchSize of 0 or 1 disables it.
+type EmbeddingConfig struct {
+	Provider       string `json:"provider"`
+	PassageRunes   int    `json:"passage_runes"`
+	PassageOverlap int    `json:"passage_overlap"`
+	BatchSize      int    `json:"batch_size"`       // Texts per provider request
+	BatchMaxTokens int    `json:"batch_max_tokens"` // Estimated tokens per provider request
+	BatchWaitMs    int    `json:"batch_wait_ms"`    // Longest a request waits for others to join its batch
+}
+
 // StorageConfig represents storage configuration
 type StorageConfig struct {
-	Provider       string                `json:"provider"`
-	RetentionDays  int                   `json:"retention_days"`
-	BackupEnabled  bool                  `json:"backup_enabled"`
-	BackupInterval int                   `json:"backup_interval_hours"`
-	Repositories   map[string]RepoConfig `json:"repositories"`
+	Provider           string                `json:"provider"`
+	KeywordPath        string                `json:"keyword_path,omitempty"`
+	LocalPath          string                `json:"local_path,omitempty"` // Directory of the local store
+	RetentionDays      int                   `json:"retention_days"`
+	BackupEnabled      bool                  `json:"backup_enabled"`
+	BackupInterval     int                   `json:"backup_interval_hours"`
+	TrashRetentionDays int                   `json:"trash_retention_days"` // Days soft-deleted chunks stay restorable
+	Repositories       map[string]RepoConfig `json:"repositories"`
+	Ingestion          IngestionConfig       `json:"ingestion"`
+	MemoryClasses      MemoryClassConfig     `json:"memory_classes"`
+}
+
+// MemoryClassConfig sets how long memories of each class are kept before
+// they move to the trash; 0 keeps them until deleted. With PromoteOnExpiry,
+// a session's expiring episodic memories are first consolidated into one
+// semantic memory, so what was learned outlives the session log.
+type MemoryClassConfig struct {
+	EpisodicRetentionDays   int  `json:"episodic_retention_days"`
+	SemanticRetentionDays   int  `json:"semantic_retention_days"`
+	ProceduralRetentionDays int  `json:"procedural_retention_days"`
+	PromoteOnExpiry         bool `json:"promote_on_expiry"`
+}
+
+// IngestionConfig controls write batching. When enabled, chunk stores are
+// queued and upserted to the vector store in batches; queued chunks are
+// recorded in a spill file first so they survive a restart.
+type IngestionConfig struct {
+	Enabled         bool   `json:"enabled"`
+	BatchSize       int    `json:"batch_size"`        // Chunks per upsert; a full batch flushes immediately
+	FlushIntervalMs int    `json:"flush_interval_ms"` // Longest a partial batch waits before flushing
+	MaxPending      int    `json:"max_pending"`       // Queued chunks beyond which stores are rejected
+	SpillPath       string `json:"spill_path"`        // Durable record of queued chunks
 }
 
 // RepoConfig represents repository-specific configuration
@@ -98,6 +364,74 @@ type SearchConfig struct {
 	EnableProgressiveSearch  bool    `json:"enable_progressive_search"`
 	EnableRepositoryFallback bool    `json:"enable_repository_fallback"`
 	MaxRelatedRepos          int     `json:"max_related_repos"`
+
+	// Plan replaces the default progressive search steps when set
+	Plan []SearchStep `json:"plan,omitempty"`
+	// EarlyExitScore, when above zero, also requires a step's best result to
+	// score at least this much before the search stops relaxing
+	EarlyExitScore float64 `json:"early_exit_score"`
+	// StepBudgetMs bounds steps that set no budget of their own; 0 is unbounded
+	StepBudgetMs int `json:"step_budget_ms"`
+
+	// KeywordIndex keeps an in-memory inverted index of chunk terms for
+	// keyword and hybrid search modes
+	KeywordIndex bool `json:"keyword_index"`
+	// DefaultMode is the search mode of searches that name none: vector,
+	// keyword or hybrid
+	DefaultMode string `json:"default_mode"`
+	// RRFK is the rank constant hybrid search fuses rankings with
+	RRFK int `json:"rrf_k"`
+	// Analyzer turns memories and queries into keyword search terms:
+	// standard, accent_sensitive or simple
+	Analyzer string `json:"analyzer"`
+	// RepositoryAnalyzers overrides Analyzer per repository
+	RepositoryAnalyzers map[string]string `json:"repository_analyzers,omitempty"`
+
+	planErr error // Set when MCP_MEMORY_SEARCH_PLAN could not be parsed
+}
+
+// AnalyzerFor returns the text analyzer of a repository
+func (s *SearchConfig) AnalyzerFor(repository string) string {
+	if analyzer, ok := s.RepositoryAnalyzers[repository]; ok {
+		return analyzer
+	}
+	return s.Analyzer
+}
+
+// SearchStep is one step of progressive search. Each step runs a relaxed copy
+// of the original query, until one is satisfied.
+type SearchStep struct {
+	Name string `json:"name"`
+	// MinRelevance overrides the query's minimum relevance; 0 keeps it
+	MinRelevance float64 `json:"min_relevance,omitempty"`
+	// RelatedRepositories searches repositories with names related to the
+	// query's, up to MaxRelatedRepos, instead of the repository itself
+	RelatedRepositories bool `json:"related_repositories,omitempty"`
+	DropRepository      bool `json:"drop_repository,omitempty"`
+	DropTypes           bool `json:"drop_types,omitempty"`
+	// TimeBudgetMs bounds the step; a step out of time is skipped
+	TimeBudgetMs int `json:"time_budget_ms,omitempty"`
+}
+
+// SearchPlan returns the configured plan, or the default one: strict, relaxed
+// relevance, related repositories, any repository, then broadest. Repository
+// steps are left out when repository fallback is disabled.
+func (s *SearchConfig) SearchPlan() []SearchStep {
+	if len(s.Plan) > 0 {
+		return s.Plan
+	}
+
+	plan := []SearchStep{
+		{Name: "strict"},
+		{Name: "relaxed", MinRelevance: s.RelaxedMinRelevance},
+	}
+	if s.EnableRepositoryFallback {
+		plan = append(plan,
+			SearchStep{Name: "related_repositories", MinRelevance: s.RelaxedMinRelevance, RelatedRepositories: true},
+			SearchStep{Name: "any_repository", MinRelevance: s.RelaxedMinRelevance, DropRepository: true},
+		)
+	}
+	return append(plan, SearchStep{Name: "broadest", MinRelevance: s.BroadestMinRelevance, DropRepository: true, DropTypes: true})
 }
 
 // LoggingConfig represents logging configuration
@@ -110,14 +444,298 @@ type LoggingConfig struct {
 	MaxAge     int    `json:"max_age_days"`
 }
 
+// DigestConfig represents scheduled digest configuration
+type DigestConfig struct {
+	Enabled       bool   `json:"enabled"`
+	SchedulesFile string `json:"schedules_file"`
+	StaleTaskDays int    `json:"stale_task_days"`
+	SMTPHost      string `json:"smtp_host"`
+	SMTPPort      int    `json:"smtp_port"`
+	SMTPUsername  string `json:"smtp_username"`
+	SMTPPassword  string `json:"-"` // Never serialize SMTP password
+	SMTPFrom      string `json:"smtp_from"`
+}
+
+// PageSyncConfig imports Notion and Confluence pages as memories and
+// re-syncs them when they change. The synced spaces are listed in SourcesFile.
+type PageSyncConfig struct {
+	Enabled           bool   `json:"enabled"`
+	SourcesFile       string `json:"sources_file"`
+	StatePath         string `json:"state_path"` // Which page versions were imported, per source
+	NotionToken       string `json:"-"`          // Never serialize API tokens
+	ConfluenceBaseURL string `json:"confluence_base_url,omitempty"`
+	ConfluenceEmail   string `json:"confluence_email,omitempty"` // With ConfluenceToken for Cloud basic auth; empty sends the token as a bearer token
+	ConfluenceToken   string `json:"-"`
+}
+
+// SlackSyncConfig imports the history of Slack channels as conversation
+// memories and keeps importing new messages. The channels are listed in ChannelsFile.
+type SlackSyncConfig struct {
+	Enabled      bool   `json:"enabled"`
+	ChannelsFile string `json:"channels_file"`
+	StatePath    string `json:"state_path"` // Sync cursors and imported threads, per channel
+	Token        string `json:"-"`          // Bot token; never serialize API tokens
+}
+
+// HTTPToolsConfig exposes external HTTP APIs as MCP tools declared in a YAML
+// file, which is reloaded when it changes
+type HTTPToolsConfig struct {
+	File          string `json:"file,omitempty"`
+	ReloadSeconds int    `json:"reload_seconds"` // How often the file is checked for changes
+}
+
+// UsageConfig meters what each tenant uses, month by month, for usage
+// reports. Counters are kept in Path and saved every FlushSeconds.
+type UsageConfig struct {
+	Enabled      bool   `json:"enabled"`
+	Path         string `json:"path"`
+	FlushSeconds int    `json:"flush_seconds"`
+}
+
+// SiteConfig controls the static site export of project knowledge bases
+type SiteConfig struct {
+	// OutputDir holds one exported site per project
+	OutputDir string `json:"output_dir"`
+}
+
+// LLMConfig selects the LLM provider used by each intelligence feature.
+// A feature set to "none" keeps its rule-based behavior.
+type LLMConfig struct {
+	SummarizationProvider        string            `json:"summarization_provider"`
+	ConflictVerificationProvider string            `json:"conflict_verification_provider"`
+	InsightsProvider             string            `json:"insights_provider"`
+	EnrichmentEnabled            bool              `json:"enrichment_enabled"` // AI chunk enrichment with the summarization provider
+	RequestTimeout               int               `json:"request_timeout_seconds"`
+	MaxTokens                    int               `json:"max_tokens"`
+	OpenAI                       LLMProviderConfig `json:"openai"`
+	Anthropic                    LLMProviderConfig `json:"anthropic"`
+	Gemini                       LLMProviderConfig `json:"gemini"`
+	Ollama                       LLMProviderConfig `json:"ollama"`
+}
+
+// LLMProviderConfig represents the connection settings of one LLM provider
+type LLMProviderConfig struct {
+	APIKey  string `json:"-"` // Never serialize API key
+	Model   string `json:"model"`
+	BaseURL string `json:"base_url,omitempty"`
+}
+
+// SecurityConfig represents HTTP transport security configuration
+type SecurityConfig struct {
+	ReplayProtection bool   `json:"replay_protection"` // Require signed, single-use requests on /mcp
+	SigningSecret    string `json:"-"`                 // Never serialize the request signing secret
+	MaxClockSkew     int    `json:"max_clock_skew_seconds"`
+	NonceCacheSize   int    `json:"nonce_cache_size"`
+
+	// Network policy: a YAML policy file takes precedence over the allowlists
+	NetworkPolicyFile string   `json:"network_policy_file,omitempty"`
+	IPAllowlist       []string `json:"ip_allowlist,omitempty"`       // CIDRs or IPs allowed to reach the server
+	AdminIPAllowlist  []string `json:"admin_ip_allowlist,omitempty"` // CIDRs or IPs allowed to reach admin endpoints
+	TrustProxyHeaders bool     `json:"trust_proxy_headers"`          // Use X-Forwarded-For / X-Real-IP for client addresses
+
+	// Resource policy: which file:// and database:// resources tools may access
+	ResourcePolicyFile string `json:"resource_policy_file,omitempty"`
+
+	OAuth   OAuthConfig   `json:"oauth"`
+	APIKeys APIKeysConfig `json:"api_keys"`
+	Tenancy TenancyConfig `json:"tenancy"`
+}
+
+// TenancyConfig holds clients authenticated with an API key or a bearer token
+// to their tenant's projects: the key's projects or the token's projects
+// claim. Storage calls reaching other repositories are refused and audited.
+type TenancyConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+// APIKeysConfig enables API key authentication of the HTTP transport. Keys
+// are issued with the auth_create_key tool and limited to the tools they list.
+type APIKeysConfig struct {
+	Enabled bool   `json:"enabled"`
+	Path    string `json:"path"` // File the key hashes are kept in
+}
+
+// OAuthConfig enables OAuth 2.1 authorization of the HTTP transport, following
+// the MCP authorization spec: requests carry bearer tokens issued for this
+// server by one of its authorization servers, and tool calls need the scope
+// of the tool. Read-only tools need ReadScope and the others WriteScope,
+// unless ToolScopes names another scope for a tool. Managing API keys needs
+// AdminScope.
+type OAuthConfig struct {
+	Enabled              bool              `json:"enabled"`
+	Resource             string            `json:"resource,omitempty"`              // Canonical URI of this server, e.g. https://memory.example.com/mcp
+	AuthorizationServers []string          `json:"authorization_servers,omitempty"` // Issuer URLs advertised to clients
+	Issuer               string            `json:"issuer,omitempty"`                // Expected token issuer, defaults to the first authorization server
+	Audience             string            `json:"audience,omitempty"`              // Expected token audience, defaults to the resource
+	JWKSURL              string            `json:"jwks_url,omitempty"`              // Discovered from the issuer's metadata when empty
+	JWKSCacheTTL         int               `json:"jwks_cache_ttl_seconds"`
+	ClockSkew            int               `json:"clock_skew_seconds"`
+	ReadScope            string            `json:"read_scope"`
+	WriteScope           string            `json:"write_scope"`
+	AdminScope           string            `json:"admin_scope"`
+	ToolScopes           map[string]string `json:"tool_scopes,omitempty"`
+}
+
+// TokenIssuer is the issuer tokens must come from
+func (o OAuthConfig) TokenIssuer() string {
+	if o.Issuer == "" && len(o.AuthorizationServers) > 0 {
+		return o.AuthorizationServers[0]
+	}
+	return o.Issuer
+}
+
+// TokenAudience is the audience tokens must be issued for
+func (o OAuthConfig) TokenAudience() string {
+	if o.Audience == "" {
+		return o.Resource
+	}
+	return o.Audience
+}
+
+// Scopes lists the scopes tokens may need, for protected resource metadata
+func (o OAuthConfig) Scopes() []string {
+	var scopes []string
+	for _, scope := range append([]string{o.ReadScope, o.WriteScope, o.AdminScope}, sortedValues(o.ToolScopes)...) {
+		if scope != "" && !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// sortedValues returns the values of m ordered by key
+func sortedValues(m map[string]string) []string {
+	values := make([]string, 0, len(m))
+	for _, key := range slices.Sorted(maps.Keys(m)) {
+		values = append(values, m[key])
+	}
+	return values
+}
+
+// ChaosConfig enables fault injection into the vector store and embedding
+// service for resilience testing. Faults can be changed at runtime with the
+// system_chaos tool, which is only registered when fault injection is enabled.
+type ChaosConfig struct {
+	Enabled              bool    `json:"enabled"`
+	VectorStoreErrorRate float64 `json:"vector_store_error_rate"` // Probability from 0 to 1 that a call fails
+	VectorStoreLatencyMs int     `json:"vector_store_latency_ms"` // Latency added to every call
+	EmbeddingsErrorRate  float64 `json:"embeddings_error_rate"`
+	EmbeddingsLatencyMs  int     `json:"embeddings_latency_ms"`
+}
+
+// RateLimitConfig throttles MCP tool calls with token buckets kept per
+// client. Every client gets RequestsPerMinute across all tools (or its entry
+// in Clients), and tools listed in Tools get a separate, usually lower, limit
+// per client. Tool keys are a tool name, or "tool:operation" for one
+// operation of a consolidated tool, e.g. "memory_read:search".
+type RateLimitConfig struct {
+	Enabled           bool           `json:"enabled"`
+	RequestsPerMinute int            `json:"requests_per_minute"` // Per client across all tools; 0 disables the client limit
+	Burst             int            `json:"burst"`               // Calls a client may make at once; defaults to RequestsPerMinute
+	Tools             map[string]int `json:"tools,omitempty"`     // Requests per minute per client for a tool or tool:operation
+	Clients           map[string]int `json:"clients,omitempty"`   // Requests per minute for specific clients, instead of RequestsPerMinute
+}
+
+// QoS classes requests are scheduled in
+const (
+	QoSInteractive = "interactive"
+	QoSBulk        = "bulk"
+	QoSAdmin       = "admin"
+)
+
+// QoSClassConfig bounds one QoS class: how many of its requests run at once
+// and how many more may wait for a turn
+type QoSClassConfig struct {
+	Concurrency int `json:"concurrency"`
+	QueueSize   int `json:"queue_size"`
+}
+
+// QoSConfig schedules tool calls and resource reads in QoS classes with
+// their own concurrency and queue limits, so background bulk work cannot
+// starve interactive calls. MaxConcurrent caps requests running across all
+// classes; when it is reached, waiting interactive requests go first, then
+// admin, then bulk. Tools maps a tool, or "tool:operation", to its class,
+// overriding the built-in classification.
+type QoSConfig struct {
+	Enabled             bool              `json:"enabled"`
+	MaxConcurrent       int               `json:"max_concurrent"`        // Across all classes; 0 leaves only the class limits
+	QueueTimeoutSeconds int               `json:"queue_timeout_seconds"` // How long a request may wait for a turn
+	Interactive         QoSClassConfig    `json:"interactive"`
+	Bulk                QoSClassConfig    `json:"bulk"`
+	Admin               QoSClassConfig    `json:"admin"`
+	Tools               map[string]string `json:"tools,omitempty"`
+}
+
+// Class returns the limits of a QoS class
+func (c *QoSConfig) Class(name string) QoSClassConfig {
+	switch name {
+	case QoSBulk:
+		return c.Bulk
+	case QoSAdmin:
+		return c.Admin
+	}
+	return c.Interactive
+}
+
+// IntelligenceConfig toggles the rule-based intelligence features that run
+// when chunks are stored
+type IntelligenceConfig struct {
+	// DecisionExtraction promotes decision-like statements in stored chunks
+	// ("we decided to…", "chose X over Y because…") into decision records
+	DecisionExtraction bool `json:"decision_extraction"`
+}
+
+// DemoConfig runs the server as a public playground of the memory tools. The
+// demo profile serves a seeded dataset from an in-memory store with fake
+// embeddings, drops the credentials of outside services, turns off the
+// integrations that reach them and enforces strict rate limits. Clients may
+// read memories and add new ones, and every ResetMinutes the dataset is
+// restored to its seed; 0 never resets it.
+type DemoConfig struct {
+	Enabled           bool `json:"enabled"`
+	ResetMinutes      int  `json:"reset_minutes"`
+	RequestsPerMinute int  `json:"requests_per_minute"` // Per client across all tools
+	WritesPerMinute   int  `json:"writes_per_minute"`   // Per client for memory_create
+}
+
+// Tool response size limits
+const (
+	// DefaultMaxResponseBytes is the default bound on the JSON of a tool result
+	DefaultMaxResponseBytes = 256 * 1024
+	// MinResponseBytes is the smallest limit that still fits a truncation notice
+	MinResponseBytes = 1024
+)
+
+// maxChaosLatencyMs bounds injected latency
+const maxChaosLatencyMs = 60000
+
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port:         8080,
-			Host:         "localhost",
-			ReadTimeout:  30,
-			WriteTimeout: 30,
+			Name:      "claude-memory",
+			Version:   "VERSION_PLACEHOLDER",
+			Transport: TransportStdio,
+			Capabilities: CapabilitiesConfig{
+				Resources: true,
+				Prompts:   true,
+			},
+			Port:             8080,
+			Host:             "localhost",
+			ReadTimeout:      30,
+			WriteTimeout:     30,
+			BatchConcurrency: 8,
+			MaxBatchSize:     100,
+			ListPageSize:     100,
+			MaxResponseBytes: DefaultMaxResponseBytes,
+			ResultCacheTTL:   900,
+			GRPC: GRPCConfig{
+				Address: ":9090",
+			},
+			TLS: TLSConfig{
+				MinVersion:       "1.2",
+				AutocertCacheDir: "./data/autocert",
+			},
 		},
 		Qdrant: QdrantConfig{
 			Host:           "localhost",
@@ -133,6 +751,23 @@ func DefaultConfig() *Config {
 				VolumePath:    "./data/qdrant",
 				Image:         "qdrant/qdrant:latest",
 			},
+			ReadReplicas: ReadReplicaConfig{
+				ReadPreference: ReadPreferenceReplica,
+				MaxStalenessMs: 5000,
+			},
+			Quantization: QuantizationConfig{
+				Mode:         QuantizationNone,
+				Compression:  "x8",
+				Quantile:     0.99,
+				Rescore:      true,
+				Oversampling: 2.0,
+			},
+		},
+		PgVector: PgVectorConfig{
+			Table:              "memory_chunks",
+			Dimension:          1536,
+			HNSWM:              16,
+			HNSWEfConstruction: 64,
 		},
 		OpenAI: OpenAIConfig{
 			EmbeddingModel: "text-embedding-ada-002",
@@ -141,12 +776,34 @@ func DefaultConfig() *Config {
 			RequestTimeout: 60,
 			RateLimitRPM:   60,
 		},
+		Embedding: EmbeddingConfig{
+			Provider:       EmbeddingProviderOpenAI,
+			PassageRunes:   4000,
+			PassageOverlap: 400,
+			BatchSize:      64,
+			BatchMaxTokens: 50000,
+			BatchWaitMs:    10,
+		},
 		Storage: StorageConfig{
-			Provider:       "qdrant",
-			RetentionDays:  90,
-			BackupEnabled:  false,
-			BackupInterval: 24,
-			Repositories:   make(map[string]RepoConfig),
+			Provider:           StorageProviderQdrant,
+			KeywordPath:        "./data/keyword_store.json",
+			LocalPath:          "./data/local",
+			RetentionDays:      90,
+			BackupEnabled:      false,
+			BackupInterval:     24,
+			TrashRetentionDays: 30,
+			Repositories:       make(map[string]RepoConfig),
+			Ingestion: IngestionConfig{
+				Enabled:         false,
+				BatchSize:       64,
+				FlushIntervalMs: 200,
+				MaxPending:      10000,
+				SpillPath:       "./data/ingestion_queue.jsonl",
+			},
+			MemoryClasses: MemoryClassConfig{
+				EpisodicRetentionDays: 90,
+				PromoteOnExpiry:       true,
+			},
 		},
 		Chunking: ChunkingConfig{
 			Strategy:              "smart",
@@ -164,6 +821,10 @@ func DefaultConfig() *Config {
 			EnableProgressiveSearch:  true,
 			EnableRepositoryFallback: true,
 			MaxRelatedRepos:          3,
+			KeywordIndex:             true,
+			DefaultMode:              SearchModeVector,
+			RRFK:                     60,
+			Analyzer:                 AnalyzerStandard,
 		},
 		Logging: LoggingConfig{
 			Level:      "info",
@@ -172,6 +833,80 @@ func DefaultConfig() *Config {
 			MaxBackups: 3,
 			MaxAge:     30,
 		},
+		Digest: DigestConfig{
+			Enabled:       false,
+			StaleTaskDays: 7,
+			SMTPPort:      587,
+		},
+		PageSync: PageSyncConfig{
+			Enabled:   false,
+			StatePath: "./data/page_sync.json",
+		},
+		SlackSync: SlackSyncConfig{
+			Enabled:   false,
+			StatePath: "./data/slack_sync.json",
+		},
+		Site: SiteConfig{
+			OutputDir: "./data/site",
+		},
+		HTTPTools: HTTPToolsConfig{
+			ReloadSeconds: 10,
+		},
+		Usage: UsageConfig{
+			Path:         "./data/usage.json",
+			FlushSeconds: 60,
+		},
+		LLM: LLMConfig{
+			SummarizationProvider:        LLMProviderNone,
+			ConflictVerificationProvider: LLMProviderNone,
+			InsightsProvider:             LLMProviderNone,
+			RequestTimeout:               60,
+			MaxTokens:                    1024,
+			OpenAI:                       LLMProviderConfig{Model: "gpt-4o-mini"},
+			Anthropic:                    LLMProviderConfig{Model: "claude-3-5-haiku-latest"},
+			Gemini:                       LLMProviderConfig{Model: "gemini-1.5-flash"},
+			Ollama:                       LLMProviderConfig{Model: "llama3.1", BaseURL: "http://localhost:11434"},
+		},
+		Security: SecurityConfig{
+			ReplayProtection: false,
+			MaxClockSkew:     300,
+			NonceCacheSize:   100000,
+			OAuth: OAuthConfig{
+				JWKSCacheTTL: 3600,
+				ClockSkew:    60,
+				ReadScope:    "memory:read",
+				WriteScope:   "memory:write",
+				AdminScope:   "memory:admin",
+			},
+			APIKeys: APIKeysConfig{
+				Path: "./data/api_keys.json",
+			},
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:           false,
+			RequestsPerMinute: 600,
+			Tools: map[string]int{
+				"memory_read:search":          120,
+				"memory_delete:bulk_delete":   10,
+				"memory_transfer:bulk_export": 10,
+			},
+		},
+		QoS: QoSConfig{
+			Enabled:             true,
+			MaxConcurrent:       64,
+			QueueTimeoutSeconds: 30,
+			Interactive:         QoSClassConfig{Concurrency: 48, QueueSize: 256},
+			Bulk:                QoSClassConfig{Concurrency: 4, QueueSize: 32},
+			Admin:               QoSClassConfig{Concurrency: 8, QueueSize: 32},
+		},
+		Demo: DemoConfig{
+			ResetMinutes:      60,
+			RequestsPerMinute: 30,
+			WritesPerMinute:   5,
+		},
+		Intelligence: IntelligenceConfig{
+			DecisionExtraction: true,
+		},
 	}
 }
 
@@ -187,6 +922,13 @@ func LoadConfig() (*Config, error) {
 
 	config := DefaultConfig()
 
+	// Override with the server configuration file, if any
+	if path := os.Getenv("MCP_MEMORY_CONFIG_FILE"); path != "" {
+		if err := LoadFile(config, path); err != nil {
+			return nil, err
+		}
+	}
+
 	// Override with environment variables
 	loadFromEnv(config)
 
@@ -202,15 +944,44 @@ func LoadConfig() (*Config, error) {
 func loadFromEnv(config *Config) {
 	loadServerConfig(config)
 	loadQdrantConfig(config)
+	loadPgVectorConfig(config)
 	loadStorageAndOtherConfig(config)
+	loadIngestionConfig(config)
 	loadOpenAIConfig(config)
+	loadEmbeddingConfig(config)
 	loadDecayConfig(config)
 	loadIntelligenceConfig(config)
 	loadPerformanceConfig(config)
+	loadDigestConfig(config)
+	loadPageSyncConfig(config)
+	loadSlackSyncConfig(config)
+	loadSiteConfig(config)
+	loadHTTPToolsConfig(config)
+	loadUsageConfig(config)
+	loadLLMConfig(config)
+	loadSecurityConfig(config)
+	loadChaosConfig(config)
+	loadRateLimitConfig(config)
+	loadQoSConfig(config)
+	loadSearchConfig(config)
+	loadDemoConfig(config)
 }
 
 // loadServerConfig loads server configuration from environment
 func loadServerConfig(config *Config) {
+	// Server identity, transport and capabilities
+	if name := os.Getenv("SERVICE_NAME"); name != "" {
+		config.Server.Name = name
+	}
+	if version := os.Getenv("SERVICE_VERSION"); version != "" {
+		config.Server.Version = version
+	}
+	if transport := os.Getenv("MCP_MEMORY_TRANSPORT"); transport != "" {
+		config.Server.Transport = transport
+	}
+	config.Server.Capabilities.Resources = getBoolEnvWithDefault("MCP_MEMORY_RESOURCES_ENABLED", config.Server.Capabilities.Resources)
+	config.Server.Capabilities.Prompts = getBoolEnvWithDefault("MCP_MEMORY_PROMPTS_ENABLED", config.Server.Capabilities.Prompts)
+
 	// Server configuration
 	if port := os.Getenv("MCP_MEMORY_PORT"); port != "" {
 		if p, err := strconv.Atoi(port); err == nil {
@@ -232,6 +1003,62 @@ func loadServerConfig(config *Config) {
 			config.Server.WriteTimeout = wt
 		}
 	}
+
+	// JSON-RPC batches
+	config.Server.BatchConcurrency = getIntEnvWithDefault("MCP_MEMORY_BATCH_CONCURRENCY", config.Server.BatchConcurrency)
+	config.Server.MaxBatchSize = getIntEnvWithDefault("MCP_MEMORY_MAX_BATCH_SIZE", config.Server.MaxBatchSize)
+
+	// List pagination
+	config.Server.ListPageSize = getIntEnvWithDefault("MCP_MEMORY_LIST_PAGE_SIZE", config.Server.ListPageSize)
+
+	// Tool response size limits
+	config.Server.MaxResponseBytes = getIntEnvWithDefault("MCP_MEMORY_MAX_RESPONSE_BYTES", config.Server.MaxResponseBytes)
+	config.Server.ResponseLimits = getLimitsEnvWithDefault("MCP_MEMORY_RESPONSE_LIMITS", config.Server.ResponseLimits)
+	config.Server.ResultCacheTTL = getIntEnvWithDefault("MCP_MEMORY_RESULT_CACHE_TTL_SECONDS", config.Server.ResultCacheTTL)
+
+	// gRPC transport
+	config.Server.GRPC.Enabled = getBoolEnvWithDefault("MCP_MEMORY_GRPC_ENABLED", config.Server.GRPC.Enabled)
+	if address := os.Getenv("MCP_MEMORY_GRPC_ADDRESS"); address != "" {
+		config.Server.GRPC.Address = address
+	}
+	if certFile := os.Getenv("MCP_MEMORY_GRPC_TLS_CERT_FILE"); certFile != "" {
+		config.Server.GRPC.TLSCertFile = certFile
+	}
+	if keyFile := os.Getenv("MCP_MEMORY_GRPC_TLS_KEY_FILE"); keyFile != "" {
+		config.Server.GRPC.TLSKeyFile = keyFile
+	}
+	if caFile := os.Getenv("MCP_MEMORY_GRPC_CLIENT_CA_FILE"); caFile != "" {
+		config.Server.GRPC.ClientCAFile = caFile
+	}
+
+	// HTTPS for the HTTP transport
+	loadTLSConfig(config)
+}
+
+// loadTLSConfig loads HTTPS configuration from environment
+func loadTLSConfig(config *Config) {
+	if certFile := os.Getenv("MCP_MEMORY_TLS_CERT_FILE"); certFile != "" {
+		config.Server.TLS.CertFile = certFile
+	}
+	if keyFile := os.Getenv("MCP_MEMORY_TLS_KEY_FILE"); keyFile != "" {
+		config.Server.TLS.KeyFile = keyFile
+	}
+	if caFile := os.Getenv("MCP_MEMORY_TLS_CLIENT_CA_FILE"); caFile != "" {
+		config.Server.TLS.ClientCAFile = caFile
+	}
+	if minVersion := os.Getenv("MCP_MEMORY_TLS_MIN_VERSION"); minVersion != "" {
+		config.Server.TLS.MinVersion = minVersion
+	}
+	config.Server.TLS.AutocertDomains = getListEnvWithDefault("MCP_MEMORY_TLS_AUTOCERT_DOMAINS", config.Server.TLS.AutocertDomains)
+	if email := os.Getenv("MCP_MEMORY_TLS_AUTOCERT_EMAIL"); email != "" {
+		config.Server.TLS.AutocertEmail = email
+	}
+	if cacheDir := os.Getenv("MCP_MEMORY_TLS_AUTOCERT_CACHE_DIR"); cacheDir != "" {
+		config.Server.TLS.AutocertCacheDir = cacheDir
+	}
+	if httpAddress := os.Getenv("MCP_MEMORY_TLS_AUTOCERT_HTTP_ADDRESS"); httpAddress != "" {
+		config.Server.TLS.AutocertHTTPAddress = httpAddress
+	}
 }
 
 // loadQdrantConfig loads Qdrant configuration from environment
@@ -244,6 +1071,8 @@ func loadQdrantConfig(config *Config) {
 func loadQdrantBasicConfig(config *Config) {
 	loadQdrantConnectionSettings(config)
 	loadQdrantServiceSettings(config)
+	loadQdrantReplicaSettings(config)
+	loadQdrantQuantizationSettings(config)
 }
 
 // loadQdrantConnectionSettings loads host, port, API key, and TLS settings
@@ -262,6 +1091,76 @@ func loadQdrantServiceSettings(config *Config) {
 	config.Qdrant.TimeoutSeconds = getIntEnvWithDefault("MCP_MEMORY_QDRANT_TIMEOUT_SECONDS", config.Qdrant.TimeoutSeconds)
 }
 
+// loadQdrantReplicaSettings loads read replica routing settings
+func loadQdrantReplicaSettings(config *Config) {
+	config.Qdrant.ReadReplicas.Hosts = getListEnvWithDefault("MCP_MEMORY_QDRANT_READ_REPLICAS", config.Qdrant.ReadReplicas.Hosts)
+	if preference := os.Getenv("MCP_MEMORY_QDRANT_READ_PREFERENCE"); preference != "" {
+		config.Qdrant.ReadReplicas.ReadPreference = preference
+	}
+	config.Qdrant.ReadReplicas.MaxStalenessMs = getIntEnvWithDefault("MCP_MEMORY_QDRANT_MAX_STALENESS_MS", config.Qdrant.ReadReplicas.MaxStalenessMs)
+}
+
+// loadQdrantQuantizationSettings loads vector quantization settings. Per-collection
+// modes are comma-separated collection=mode pairs, e.g. "claude_memory=product".
+func loadQdrantQuantizationSettings(config *Config) {
+	quantization := &config.Qdrant.Quantization
+	if mode := os.Getenv("MCP_MEMORY_QDRANT_QUANTIZATION"); mode != "" {
+		quantization.Mode = mode
+	}
+	if compression := os.Getenv("MCP_MEMORY_QDRANT_QUANTIZATION_COMPRESSION"); compression != "" {
+		quantization.Compression = compression
+	}
+	quantization.Quantile = getFloatEnvWithDefault("MCP_MEMORY_QDRANT_QUANTIZATION_QUANTILE", quantization.Quantile)
+	quantization.AlwaysRAM = getBoolEnvWithDefault("MCP_MEMORY_QDRANT_QUANTIZATION_ALWAYS_RAM", quantization.AlwaysRAM)
+	quantization.Rescore = getBoolEnvWithDefault("MCP_MEMORY_QDRANT_QUANTIZATION_RESCORE", quantization.Rescore)
+	quantization.Oversampling = getFloatEnvWithDefault("MCP_MEMORY_QDRANT_QUANTIZATION_OVERSAMPLING", quantization.Oversampling)
+	for _, item := range getListEnvWithDefault("MCP_MEMORY_QDRANT_QUANTIZATION_COLLECTIONS", nil) {
+		collection, mode, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		if quantization.Collections == nil {
+			quantization.Collections = make(map[string]string)
+		}
+		quantization.Collections[strings.TrimSpace(collection)] = strings.TrimSpace(mode)
+	}
+}
+
+// loadSearchConfig loads progressive search planning from environment. The
+// plan is a JSON array of steps, e.g.
+// [{"name":"strict"},{"name":"broad","min_relevance":0.2,"drop_types":true}]
+func loadSearchConfig(config *Config) {
+	config.Search.EnableProgressiveSearch = getBoolEnvWithDefault("MCP_MEMORY_SEARCH_PROGRESSIVE", config.Search.EnableProgressiveSearch)
+	config.Search.EarlyExitScore = getFloatEnvWithDefault("MCP_MEMORY_SEARCH_EARLY_EXIT_SCORE", config.Search.EarlyExitScore)
+	config.Search.StepBudgetMs = getIntEnvWithDefault("MCP_MEMORY_SEARCH_STEP_BUDGET_MS", config.Search.StepBudgetMs)
+	config.Search.KeywordIndex = getBoolEnvWithDefault("MCP_MEMORY_SEARCH_KEYWORD_INDEX", config.Search.KeywordIndex)
+	if mode := os.Getenv("MCP_MEMORY_SEARCH_DEFAULT_MODE"); mode != "" {
+		config.Search.DefaultMode = mode
+	}
+	config.Search.RRFK = getIntEnvWithDefault("MCP_MEMORY_SEARCH_RRF_K", config.Search.RRFK)
+	if analyzer := os.Getenv("MCP_MEMORY_SEARCH_ANALYZER"); analyzer != "" {
+		config.Search.Analyzer = analyzer
+	}
+	for _, item := range getListEnvWithDefault("MCP_MEMORY_SEARCH_REPOSITORY_ANALYZERS", nil) {
+		repository, analyzer, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		if config.Search.RepositoryAnalyzers == nil {
+			config.Search.RepositoryAnalyzers = make(map[string]string)
+		}
+		config.Search.RepositoryAnalyzers[strings.TrimSpace(repository)] = strings.TrimSpace(analyzer)
+	}
+	if plan := os.Getenv("MCP_MEMORY_SEARCH_PLAN"); plan != "" {
+		var steps []SearchStep
+		if err := json.Unmarshal([]byte(plan), &steps); err != nil {
+			config.Search.planErr = fmt.Errorf("invalid MCP_MEMORY_SEARCH_PLAN: %w", err)
+		} else {
+			config.Search.Plan = steps
+		}
+	}
+}
+
 // getStringEnvWithFallback gets string environment variable with fallback to alternate key
 func getStringEnvWithFallback(primaryKey, fallbackKey, defaultValue string) string {
 	if value := os.Getenv(primaryKey); value != "" {
@@ -341,6 +1240,18 @@ func loadQdrantDockerConfig(config *Config) {
 	}
 }
 
+// loadPgVectorConfig loads the pgvector store configuration from environment
+func loadPgVectorConfig(config *Config) {
+	config.PgVector.DSN = getStringEnvWithFallback("MCP_MEMORY_PGVECTOR_DSN", "DATABASE_URL", config.PgVector.DSN)
+	if table := os.Getenv("MCP_MEMORY_PGVECTOR_TABLE"); table != "" {
+		config.PgVector.Table = table
+	}
+	config.PgVector.Dimension = getIntEnvWithDefault("MCP_MEMORY_PGVECTOR_DIMENSION", config.PgVector.Dimension)
+	config.PgVector.HNSWM = getIntEnvWithDefault("MCP_MEMORY_PGVECTOR_HNSW_M", config.PgVector.HNSWM)
+	config.PgVector.HNSWEfConstruction = getIntEnvWithDefault("MCP_MEMORY_PGVECTOR_HNSW_EF_CONSTRUCTION", config.PgVector.HNSWEfConstruction)
+	config.PgVector.HNSWEfSearch = getIntEnvWithDefault("MCP_MEMORY_PGVECTOR_HNSW_EF_SEARCH", config.PgVector.HNSWEfSearch)
+}
+
 func loadStorageAndOtherConfig(config *Config) {
 	loadStorageConfig(config)
 	loadChunkingConfig(config)
@@ -349,6 +1260,10 @@ func loadStorageAndOtherConfig(config *Config) {
 
 // loadStorageConfig loads storage configuration from environment
 func loadStorageConfig(config *Config) {
+	// VECTOR_BACKEND names the storage provider too; MCP_MEMORY_STORAGE_PROVIDER wins
+	if backend := os.Getenv("VECTOR_BACKEND"); backend != "" {
+		config.Storage.Provider = backend
+	}
 	if provider := os.Getenv("MCP_MEMORY_STORAGE_PROVIDER"); provider != "" {
 		config.Storage.Provider = provider
 	}
@@ -357,6 +1272,18 @@ func loadStorageConfig(config *Config) {
 			config.Storage.RetentionDays = r
 		}
 	}
+	config.Storage.TrashRetentionDays = getIntEnvWithDefault("MCP_MEMORY_TRASH_RETENTION_DAYS", config.Storage.TrashRetentionDays)
+	classes := &config.Storage.MemoryClasses
+	classes.EpisodicRetentionDays = getIntEnvWithDefault("MCP_MEMORY_EPISODIC_RETENTION_DAYS", classes.EpisodicRetentionDays)
+	classes.SemanticRetentionDays = getIntEnvWithDefault("MCP_MEMORY_SEMANTIC_RETENTION_DAYS", classes.SemanticRetentionDays)
+	classes.ProceduralRetentionDays = getIntEnvWithDefault("MCP_MEMORY_PROCEDURAL_RETENTION_DAYS", classes.ProceduralRetentionDays)
+	classes.PromoteOnExpiry = getBoolEnvWithDefault("MCP_MEMORY_PROMOTE_ON_EXPIRY", classes.PromoteOnExpiry)
+	if keywordPath := os.Getenv("MCP_MEMORY_KEYWORD_STORE_PATH"); keywordPath != "" {
+		config.Storage.KeywordPath = keywordPath
+	}
+	if localPath := os.Getenv("MCP_MEMORY_LOCAL_STORE_PATH"); localPath != "" {
+		config.Storage.LocalPath = localPath
+	}
 	if backupEnabled := os.Getenv("MCP_MEMORY_BACKUP_ENABLED"); backupEnabled != "" {
 		if be, err := strconv.ParseBool(backupEnabled); err == nil {
 			config.Storage.BackupEnabled = be
@@ -427,6 +1354,7 @@ func loadOpenAIConfig(config *Config) {
 	if model := os.Getenv("OPENAI_EMBEDDING_MODEL"); model != "" {
 		config.OpenAI.EmbeddingModel = model
 	}
+	config.OpenAI.BaseURL = getStringEnvWithFallback("MCP_MEMORY_OPENAI_BASE_URL", "OPENAI_BASE_URL", config.OpenAI.BaseURL)
 	if maxTokens := os.Getenv("MCP_MEMORY_OPENAI_MAX_TOKENS"); maxTokens != "" {
 		if mt, err := strconv.Atoi(maxTokens); err == nil {
 			config.OpenAI.MaxTokens = mt
@@ -455,8 +1383,8 @@ func loadDecayConfig(_ *Config) {
 }
 
 // loadIntelligenceConfig loads intelligence configuration from environment
-func loadIntelligenceConfig(_ *Config) {
-	// Add intelligence config loading if needed
+func loadIntelligenceConfig(config *Config) {
+	config.Intelligence.DecisionExtraction = getBoolEnvWithDefault("MCP_MEMORY_DECISION_EXTRACTION_ENABLED", config.Intelligence.DecisionExtraction)
 }
 
 // loadPerformanceConfig loads performance configuration from environment
@@ -464,6 +1392,353 @@ func loadPerformanceConfig(config *Config) {
 	// Add performance config loading if needed
 }
 
+// loadEmbeddingConfig loads the embedding provider from environment. Lite mode
+// (provider "none") implies the keyword storage provider, since vector stores need embeddings.
+func loadEmbeddingConfig(config *Config) {
+	if provider := os.Getenv("MCP_MEMORY_EMBEDDING_PROVIDER"); provider != "" {
+		config.Embedding.Provider = strings.ToLower(provider)
+	}
+	if getBoolEnvWithDefault("MCP_MEMORY_LITE_MODE", false) {
+		config.Embedding.Provider = EmbeddingProviderNone
+	}
+	config.Embedding.PassageRunes = getIntEnvWithDefault("MCP_MEMORY_EMBEDDING_PASSAGE_RUNES", config.Embedding.PassageRunes)
+	config.Embedding.PassageOverlap = getIntEnvWithDefault("MCP_MEMORY_EMBEDDING_PASSAGE_OVERLAP", config.Embedding.PassageOverlap)
+	config.Embedding.BatchSize = getIntEnvWithDefault("MCP_MEMORY_EMBEDDING_BATCH_SIZE", config.Embedding.BatchSize)
+	config.Embedding.BatchMaxTokens = getIntEnvWithDefault("MCP_MEMORY_EMBEDDING_BATCH_MAX_TOKENS", config.Embedding.BatchMaxTokens)
+	config.Embedding.BatchWaitMs = getIntEnvWithDefault("MCP_MEMORY_EMBEDDING_BATCH_WAIT_MS", config.Embedding.BatchWaitMs)
+	// Lite mode searches by keyword; the local store does so without embeddings too
+	if config.IsLiteMode() && config.Storage.Provider != StorageProviderLocal {
+		config.Storage.Provider = StorageProviderKeyword
+	}
+}
+
+// loadDigestConfig loads scheduled digest configuration from environment
+func loadDigestConfig(config *Config) {
+	config.Digest.Enabled = getBoolEnvWithDefault("MCP_MEMORY_DIGEST_ENABLED", config.Digest.Enabled)
+	if schedulesFile := os.Getenv("MCP_MEMORY_DIGEST_SCHEDULES_FILE"); schedulesFile != "" {
+		config.Digest.SchedulesFile = schedulesFile
+	}
+	config.Digest.StaleTaskDays = getIntEnvWithDefault("MCP_MEMORY_DIGEST_STALE_TASK_DAYS", config.Digest.StaleTaskDays)
+	config.Digest.SMTPHost = getStringEnvWithFallback("MCP_MEMORY_DIGEST_SMTP_HOST", "SMTP_HOST", config.Digest.SMTPHost)
+	config.Digest.SMTPPort = getIntEnvWithFallback("MCP_MEMORY_DIGEST_SMTP_PORT", "SMTP_PORT", config.Digest.SMTPPort)
+	config.Digest.SMTPUsername = getStringEnvWithFallback("MCP_MEMORY_DIGEST_SMTP_USERNAME", "SMTP_USERNAME", config.Digest.SMTPUsername)
+	config.Digest.SMTPPassword = getStringEnvWithFallback("MCP_MEMORY_DIGEST_SMTP_PASSWORD", "SMTP_PASSWORD", config.Digest.SMTPPassword)
+	config.Digest.SMTPFrom = getStringEnvWithFallback("MCP_MEMORY_DIGEST_SMTP_FROM", "SMTP_FROM", config.Digest.SMTPFrom)
+}
+
+// loadPageSyncConfig loads Notion and Confluence page sync configuration from environment
+func loadPageSyncConfig(config *Config) {
+	config.PageSync.Enabled = getBoolEnvWithDefault("MCP_MEMORY_PAGE_SYNC_ENABLED", config.PageSync.Enabled)
+	if sourcesFile := os.Getenv("MCP_MEMORY_PAGE_SYNC_SOURCES_FILE"); sourcesFile != "" {
+		config.PageSync.SourcesFile = sourcesFile
+	}
+	if statePath := os.Getenv("MCP_MEMORY_PAGE_SYNC_STATE_PATH"); statePath != "" {
+		config.PageSync.StatePath = statePath
+	}
+	if token := os.Getenv("MCP_MEMORY_NOTION_TOKEN"); token != "" {
+		config.PageSync.NotionToken = token
+	}
+	if baseURL := os.Getenv("MCP_MEMORY_CONFLUENCE_BASE_URL"); baseURL != "" {
+		config.PageSync.ConfluenceBaseURL = baseURL
+	}
+	if email := os.Getenv("MCP_MEMORY_CONFLUENCE_EMAIL"); email != "" {
+		config.PageSync.ConfluenceEmail = email
+	}
+	if token := os.Getenv("MCP_MEMORY_CONFLUENCE_TOKEN"); token != "" {
+		config.PageSync.ConfluenceToken = token
+	}
+}
+
+// loadSlackSyncConfig loads Slack channel sync configuration from environment
+func loadSlackSyncConfig(config *Config) {
+	config.SlackSync.Enabled = getBoolEnvWithDefault("MCP_MEMORY_SLACK_SYNC_ENABLED", config.SlackSync.Enabled)
+	if channelsFile := os.Getenv("MCP_MEMORY_SLACK_CHANNELS_FILE"); channelsFile != "" {
+		config.SlackSync.ChannelsFile = channelsFile
+	}
+	if statePath := os.Getenv("MCP_MEMORY_SLACK_SYNC_STATE_PATH"); statePath != "" {
+		config.SlackSync.StatePath = statePath
+	}
+	if token := os.Getenv("MCP_MEMORY_SLACK_TOKEN"); token != "" {
+		config.SlackSync.Token = token
+	}
+}
+
+// loadSiteConfig loads static site export configuration from environment
+func loadSiteConfig(config *Config) {
+	if outputDir := os.Getenv("MCP_MEMORY_SITE_OUTPUT_DIR"); outputDir != "" {
+		config.Site.OutputDir = outputDir
+	}
+}
+
+// loadHTTPToolsConfig loads external HTTP tool configuration from environment
+func loadHTTPToolsConfig(config *Config) {
+	if file := os.Getenv("MCP_MEMORY_HTTP_TOOLS_FILE"); file != "" {
+		config.HTTPTools.File = file
+	}
+	config.HTTPTools.ReloadSeconds = getIntEnvWithDefault("MCP_MEMORY_HTTP_TOOLS_RELOAD_SECONDS", config.HTTPTools.ReloadSeconds)
+}
+
+// loadUsageConfig loads usage metering configuration from environment
+func loadUsageConfig(config *Config) {
+	config.Usage.Enabled = getBoolEnvWithDefault("MCP_MEMORY_USAGE_METERING", config.Usage.Enabled)
+	if path := os.Getenv("MCP_MEMORY_USAGE_PATH"); path != "" {
+		config.Usage.Path = path
+	}
+	config.Usage.FlushSeconds = getIntEnvWithDefault("MCP_MEMORY_USAGE_FLUSH_SECONDS", config.Usage.FlushSeconds)
+}
+
+// loadDemoConfig loads the demo profile from environment. It runs after the
+// other loaders, since the profile overrides what they configured.
+func loadDemoConfig(config *Config) {
+	config.Demo.Enabled = getBoolEnvWithDefault("MCP_MEMORY_DEMO_MODE", config.Demo.Enabled)
+	config.Demo.ResetMinutes = getIntEnvWithDefault("MCP_MEMORY_DEMO_RESET_MINUTES", config.Demo.ResetMinutes)
+	config.Demo.RequestsPerMinute = getIntEnvWithDefault("MCP_MEMORY_DEMO_RPM", config.Demo.RequestsPerMinute)
+	config.Demo.WritesPerMinute = getIntEnvWithDefault("MCP_MEMORY_DEMO_WRITES_PER_MINUTE", config.Demo.WritesPerMinute)
+	if config.Demo.Enabled {
+		config.ApplyDemoProfile()
+	}
+}
+
+// ApplyDemoProfile configures the server for a public demo, whatever else
+// was configured: nothing it serves may cost money, reach another service
+// or outlive a reset
+func (c *Config) ApplyDemoProfile() {
+	c.Embedding.Provider = EmbeddingProviderFake
+	c.Storage.Provider = StorageProviderLocal
+	c.Storage.LocalPath = ""
+	c.Storage.Ingestion.Enabled = false
+	c.Usage.Enabled = false
+	c.Chaos.Enabled = false
+
+	// Credentials of outside services
+	c.OpenAI.APIKey = ""
+	c.LLM.OpenAI.APIKey = ""
+	c.LLM.Anthropic.APIKey = ""
+	c.LLM.Gemini.APIKey = ""
+	c.LLM.SummarizationProvider = LLMProviderNone
+	c.LLM.ConflictVerificationProvider = LLMProviderNone
+	c.LLM.InsightsProvider = LLMProviderNone
+	c.LLM.EnrichmentEnabled = false
+	c.Digest.Enabled = false
+	c.Digest.SMTPPassword = ""
+	c.PageSync.Enabled = false
+	c.PageSync.NotionToken = ""
+	c.PageSync.ConfluenceToken = ""
+	c.SlackSync.Enabled = false
+	c.SlackSync.Token = ""
+	c.HTTPTools.File = ""
+
+	c.RateLimit.Enabled = true
+	c.RateLimit.RequestsPerMinute = c.Demo.RequestsPerMinute
+	c.RateLimit.Burst = 0
+	c.RateLimit.Tools = map[string]int{"memory_create": c.Demo.WritesPerMinute}
+	c.RateLimit.Clients = nil
+}
+
+// loadLLMConfig loads LLM provider configuration from environment. The OpenAI
+// key is shared with embeddings unless overridden.
+func loadLLMConfig(config *Config) {
+	if provider := os.Getenv("MCP_MEMORY_LLM_PROVIDER"); provider != "" {
+		provider = strings.ToLower(provider)
+		config.LLM.SummarizationProvider = provider
+		config.LLM.ConflictVerificationProvider = provider
+		config.LLM.InsightsProvider = provider
+	}
+	if provider := os.Getenv("MCP_MEMORY_LLM_SUMMARIZATION_PROVIDER"); provider != "" {
+		config.LLM.SummarizationProvider = strings.ToLower(provider)
+	}
+	if provider := os.Getenv("MCP_MEMORY_LLM_CONFLICT_VERIFICATION_PROVIDER"); provider != "" {
+		config.LLM.ConflictVerificationProvider = strings.ToLower(provider)
+	}
+	if provider := os.Getenv("MCP_MEMORY_LLM_INSIGHTS_PROVIDER"); provider != "" {
+		config.LLM.InsightsProvider = strings.ToLower(provider)
+	}
+	config.LLM.EnrichmentEnabled = getBoolEnvWithDefault("MCP_MEMORY_AI_ENRICHMENT_ENABLED", config.LLM.EnrichmentEnabled)
+	config.LLM.RequestTimeout = getIntEnvWithDefault("MCP_MEMORY_LLM_REQUEST_TIMEOUT_SECONDS", config.LLM.RequestTimeout)
+	config.LLM.MaxTokens = getIntEnvWithDefault("MCP_MEMORY_LLM_MAX_TOKENS", config.LLM.MaxTokens)
+
+	config.LLM.OpenAI.APIKey = getStringEnvWithFallback("MCP_MEMORY_LLM_OPENAI_API_KEY", "OPENAI_API_KEY", config.LLM.OpenAI.APIKey)
+	config.LLM.OpenAI.Model = getStringEnvWithFallback("MCP_MEMORY_LLM_OPENAI_MODEL", "OPENAI_CHAT_MODEL", config.LLM.OpenAI.Model)
+	config.LLM.OpenAI.BaseURL = getStringEnvWithFallback("MCP_MEMORY_LLM_OPENAI_BASE_URL", "OPENAI_BASE_URL", config.LLM.OpenAI.BaseURL)
+	config.LLM.Anthropic.APIKey = getStringEnvWithFallback("MCP_MEMORY_LLM_ANTHROPIC_API_KEY", "ANTHROPIC_API_KEY", config.LLM.Anthropic.APIKey)
+	config.LLM.Anthropic.Model = getStringEnvWithFallback("MCP_MEMORY_LLM_ANTHROPIC_MODEL", "ANTHROPIC_MODEL", config.LLM.Anthropic.Model)
+	config.LLM.Anthropic.BaseURL = getStringEnvWithFallback("MCP_MEMORY_LLM_ANTHROPIC_BASE_URL", "ANTHROPIC_BASE_URL", config.LLM.Anthropic.BaseURL)
+	config.LLM.Gemini.APIKey = getStringEnvWithFallback("MCP_MEMORY_LLM_GEMINI_API_KEY", "GEMINI_API_KEY", config.LLM.Gemini.APIKey)
+	config.LLM.Gemini.Model = getStringEnvWithFallback("MCP_MEMORY_LLM_GEMINI_MODEL", "GEMINI_MODEL", config.LLM.Gemini.Model)
+	config.LLM.Gemini.BaseURL = getStringEnvWithFallback("MCP_MEMORY_LLM_GEMINI_BASE_URL", "GEMINI_BASE_URL", config.LLM.Gemini.BaseURL)
+	config.LLM.Ollama.Model = getStringEnvWithFallback("MCP_MEMORY_LLM_OLLAMA_MODEL", "OLLAMA_MODEL", config.LLM.Ollama.Model)
+	config.LLM.Ollama.BaseURL = getStringEnvWithFallback("MCP_MEMORY_LLM_OLLAMA_BASE_URL", "OLLAMA_HOST", config.LLM.Ollama.BaseURL)
+}
+
+// loadSecurityConfig loads HTTP transport security configuration from environment
+func loadSecurityConfig(config *Config) {
+	config.Security.ReplayProtection = getBoolEnvWithDefault("MCP_MEMORY_REPLAY_PROTECTION_ENABLED", config.Security.ReplayProtection)
+	if secret := os.Getenv("MCP_MEMORY_REQUEST_SIGNING_SECRET"); secret != "" {
+		config.Security.SigningSecret = secret
+	}
+	config.Security.MaxClockSkew = getIntEnvWithDefault("MCP_MEMORY_REPLAY_MAX_CLOCK_SKEW_SECONDS", config.Security.MaxClockSkew)
+	config.Security.NonceCacheSize = getIntEnvWithDefault("MCP_MEMORY_REPLAY_NONCE_CACHE_SIZE", config.Security.NonceCacheSize)
+
+	if policyFile := os.Getenv("MCP_MEMORY_NETWORK_POLICY_FILE"); policyFile != "" {
+		config.Security.NetworkPolicyFile = policyFile
+	}
+	config.Security.IPAllowlist = getListEnvWithDefault("MCP_MEMORY_IP_ALLOWLIST", config.Security.IPAllowlist)
+	config.Security.AdminIPAllowlist = getListEnvWithDefault("MCP_MEMORY_ADMIN_IP_ALLOWLIST", config.Security.AdminIPAllowlist)
+	config.Security.TrustProxyHeaders = getBoolEnvWithDefault("MCP_MEMORY_TRUST_PROXY_HEADERS", config.Security.TrustProxyHeaders)
+	if policyFile := os.Getenv("MCP_MEMORY_RESOURCE_POLICY_FILE"); policyFile != "" {
+		config.Security.ResourcePolicyFile = policyFile
+	}
+	loadOAuthConfig(config)
+
+	config.Security.APIKeys.Enabled = getBoolEnvWithDefault("MCP_MEMORY_API_KEYS_ENABLED", config.Security.APIKeys.Enabled)
+	if path := os.Getenv("MCP_MEMORY_API_KEYS_PATH"); path != "" {
+		config.Security.APIKeys.Path = path
+	}
+	config.Security.Tenancy.Enabled = getBoolEnvWithDefault("MCP_MEMORY_TENANCY_ENABLED", config.Security.Tenancy.Enabled)
+}
+
+// loadOAuthConfig loads OAuth authorization of the HTTP transport from environment
+func loadOAuthConfig(config *Config) {
+	oauth := &config.Security.OAuth
+	oauth.Enabled = getBoolEnvWithDefault("MCP_MEMORY_OAUTH_ENABLED", oauth.Enabled)
+	if resource := os.Getenv("MCP_MEMORY_OAUTH_RESOURCE"); resource != "" {
+		oauth.Resource = resource
+	}
+	oauth.AuthorizationServers = getListEnvWithDefault("MCP_MEMORY_OAUTH_AUTHORIZATION_SERVERS", oauth.AuthorizationServers)
+	if issuer := os.Getenv("MCP_MEMORY_OAUTH_ISSUER"); issuer != "" {
+		oauth.Issuer = issuer
+	}
+	if audience := os.Getenv("MCP_MEMORY_OAUTH_AUDIENCE"); audience != "" {
+		oauth.Audience = audience
+	}
+	if jwksURL := os.Getenv("MCP_MEMORY_OAUTH_JWKS_URL"); jwksURL != "" {
+		oauth.JWKSURL = jwksURL
+	}
+	oauth.JWKSCacheTTL = getIntEnvWithDefault("MCP_MEMORY_OAUTH_JWKS_CACHE_TTL_SECONDS", oauth.JWKSCacheTTL)
+	oauth.ClockSkew = getIntEnvWithDefault("MCP_MEMORY_OAUTH_CLOCK_SKEW_SECONDS", oauth.ClockSkew)
+	if scope := os.Getenv("MCP_MEMORY_OAUTH_READ_SCOPE"); scope != "" {
+		oauth.ReadScope = scope
+	}
+	if scope := os.Getenv("MCP_MEMORY_OAUTH_WRITE_SCOPE"); scope != "" {
+		oauth.WriteScope = scope
+	}
+	if scope := os.Getenv("MCP_MEMORY_OAUTH_ADMIN_SCOPE"); scope != "" {
+		oauth.AdminScope = scope
+	}
+	for _, item := range getListEnvWithDefault("MCP_MEMORY_OAUTH_TOOL_SCOPES", nil) {
+		tool, scope, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		if oauth.ToolScopes == nil {
+			oauth.ToolScopes = make(map[string]string)
+		}
+		oauth.ToolScopes[strings.TrimSpace(tool)] = strings.TrimSpace(scope)
+	}
+}
+
+// loadIngestionConfig loads write batching configuration from environment
+func loadIngestionConfig(config *Config) {
+	config.Storage.Ingestion.Enabled = getBoolEnvWithDefault("MCP_MEMORY_INGESTION_BATCHING", config.Storage.Ingestion.Enabled)
+	config.Storage.Ingestion.BatchSize = getIntEnvWithDefault("MCP_MEMORY_INGESTION_BATCH_SIZE", config.Storage.Ingestion.BatchSize)
+	config.Storage.Ingestion.FlushIntervalMs = getIntEnvWithDefault("MCP_MEMORY_INGESTION_FLUSH_INTERVAL_MS", config.Storage.Ingestion.FlushIntervalMs)
+	config.Storage.Ingestion.MaxPending = getIntEnvWithDefault("MCP_MEMORY_INGESTION_MAX_PENDING", config.Storage.Ingestion.MaxPending)
+	if spillPath := os.Getenv("MCP_MEMORY_INGESTION_SPILL_PATH"); spillPath != "" {
+		config.Storage.Ingestion.SpillPath = spillPath
+	}
+}
+
+// loadChaosConfig loads fault injection configuration from environment
+func loadChaosConfig(config *Config) {
+	config.Chaos.Enabled = getBoolEnvWithDefault("MCP_MEMORY_CHAOS_ENABLED", config.Chaos.Enabled)
+	config.Chaos.VectorStoreErrorRate = getFloatEnvWithDefault("MCP_MEMORY_CHAOS_VECTOR_STORE_ERROR_RATE", config.Chaos.VectorStoreErrorRate)
+	config.Chaos.VectorStoreLatencyMs = getIntEnvWithDefault("MCP_MEMORY_CHAOS_VECTOR_STORE_LATENCY_MS", config.Chaos.VectorStoreLatencyMs)
+	config.Chaos.EmbeddingsErrorRate = getFloatEnvWithDefault("MCP_MEMORY_CHAOS_EMBEDDINGS_ERROR_RATE", config.Chaos.EmbeddingsErrorRate)
+	config.Chaos.EmbeddingsLatencyMs = getIntEnvWithDefault("MCP_MEMORY_CHAOS_EMBEDDINGS_LATENCY_MS", config.Chaos.EmbeddingsLatencyMs)
+}
+
+// loadRateLimitConfig loads tool call rate limits from environment
+func loadRateLimitConfig(config *Config) {
+	config.RateLimit.Enabled = getBoolEnvWithDefault("MCP_MEMORY_RATE_LIMIT_ENABLED", config.RateLimit.Enabled)
+	config.RateLimit.RequestsPerMinute = getIntEnvWithDefault("MCP_MEMORY_RATE_LIMIT_RPM", config.RateLimit.RequestsPerMinute)
+	config.RateLimit.Burst = getIntEnvWithDefault("MCP_MEMORY_RATE_LIMIT_BURST", config.RateLimit.Burst)
+	config.RateLimit.Tools = getLimitsEnvWithDefault("MCP_MEMORY_RATE_LIMIT_TOOLS", config.RateLimit.Tools)
+	config.RateLimit.Clients = getLimitsEnvWithDefault("MCP_MEMORY_RATE_LIMIT_CLIENTS", config.RateLimit.Clients)
+}
+
+// loadQoSConfig loads request scheduling classes from environment
+func loadQoSConfig(config *Config) {
+	config.QoS.Enabled = getBoolEnvWithDefault("MCP_MEMORY_QOS_ENABLED", config.QoS.Enabled)
+	config.QoS.MaxConcurrent = getIntEnvWithDefault("MCP_MEMORY_QOS_MAX_CONCURRENT", config.QoS.MaxConcurrent)
+	config.QoS.QueueTimeoutSeconds = getIntEnvWithDefault("MCP_MEMORY_QOS_QUEUE_TIMEOUT_SECONDS", config.QoS.QueueTimeoutSeconds)
+	for _, class := range []struct {
+		prefix string
+		limits *QoSClassConfig
+	}{
+		{"MCP_MEMORY_QOS_INTERACTIVE", &config.QoS.Interactive},
+		{"MCP_MEMORY_QOS_BULK", &config.QoS.Bulk},
+		{"MCP_MEMORY_QOS_ADMIN", &config.QoS.Admin},
+	} {
+		class.limits.Concurrency = getIntEnvWithDefault(class.prefix+"_CONCURRENCY", class.limits.Concurrency)
+		class.limits.QueueSize = getIntEnvWithDefault(class.prefix+"_QUEUE_SIZE", class.limits.QueueSize)
+	}
+	for _, item := range getListEnvWithDefault("MCP_MEMORY_QOS_TOOLS", nil) {
+		tool, class, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		if config.QoS.Tools == nil {
+			config.QoS.Tools = make(map[string]string)
+		}
+		config.QoS.Tools[strings.TrimSpace(tool)] = strings.TrimSpace(class)
+	}
+}
+
+// getFloatEnvWithDefault gets float environment variable with default value
+func getFloatEnvWithDefault(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getListEnvWithDefault reads a comma-separated list, ignoring empty entries
+func getListEnvWithDefault(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getLimitsEnvWithDefault reads comma-separated name=limit pairs, e.g.
+// "memory_read:search=60,memory_transfer=10". Malformed pairs are ignored.
+func getLimitsEnvWithDefault(key string, defaultValue map[string]int) map[string]int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	limits := make(map[string]int)
+	for _, item := range strings.Split(value, ",") {
+		name, limit, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		if parsed, err := strconv.Atoi(strings.TrimSpace(limit)); err == nil {
+			limits[strings.TrimSpace(name)] = parsed
+		}
+	}
+	return limits
+}
+
 // Validate validates the configuration
 func (c *Config) Validate() error {
 	if err := c.validateServerConfig(); err != nil {
@@ -474,7 +1749,7 @@ func (c *Config) Validate() error {
 		return err
 	}
 
-	if err := c.validateOpenAIConfig(); err != nil {
+	if err := c.validateEmbeddingConfig(); err != nil {
 		return err
 	}
 
@@ -486,17 +1761,183 @@ func (c *Config) Validate() error {
 		return err
 	}
 
+	if err := c.validateLLMConfig(); err != nil {
+		return err
+	}
+
+	if err := c.validateSecurityConfig(); err != nil {
+		return err
+	}
+
+	if err := c.validateChaosConfig(); err != nil {
+		return err
+	}
+
+	if c.Demo.Enabled && (c.Demo.ResetMinutes < 0 || c.Demo.RequestsPerMinute < 1 || c.Demo.WritesPerMinute < 1) {
+		return fmt.Errorf("demo mode requires a non-negative reset interval and positive rate limits, got %d minutes, %d and %d requests per minute",
+			c.Demo.ResetMinutes, c.Demo.RequestsPerMinute, c.Demo.WritesPerMinute)
+	}
+
+	if err := c.validateRateLimitConfig(); err != nil {
+		return err
+	}
+
+	if err := c.validateQoSConfig(); err != nil {
+		return err
+	}
+
+	if c.HTTPTools.File != "" && c.HTTPTools.ReloadSeconds < 1 {
+		return fmt.Errorf("http tools reload interval must be at least 1 second, got %d", c.HTTPTools.ReloadSeconds)
+	}
+	if c.Usage.Enabled && (c.Usage.Path == "" || c.Usage.FlushSeconds < 1) {
+		return fmt.Errorf("usage metering requires a path and a flush interval of at least 1 second, got %q and %d", c.Usage.Path, c.Usage.FlushSeconds)
+	}
+
+	if err := c.validateSearchConfig(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateSearchConfig validates the progressive search plan
+func (c *Config) validateSearchConfig() error {
+	if c.Search.planErr != nil {
+		return c.Search.planErr
+	}
+	if c.Search.EarlyExitScore < 0 || c.Search.EarlyExitScore > 1 {
+		return fmt.Errorf("search early exit score must be between 0 and 1, got %g", c.Search.EarlyExitScore)
+	}
+	if c.Search.StepBudgetMs < 0 {
+		return errors.New("search step budget cannot be negative")
+	}
+	if c.Search.DefaultMode != "" && !slices.Contains(searchModes, c.Search.DefaultMode) {
+		return fmt.Errorf("invalid default search mode: %q (valid: %s)", c.Search.DefaultMode, strings.Join(searchModes, ", "))
+	}
+	if c.Search.DefaultMode != "" && c.Search.DefaultMode != SearchModeVector && !c.Search.KeywordIndex && !c.IsLiteMode() {
+		return fmt.Errorf("default search mode %q requires the keyword index (MCP_MEMORY_SEARCH_KEYWORD_INDEX=true)", c.Search.DefaultMode)
+	}
+	if c.Search.RRFK < 0 {
+		return fmt.Errorf("search RRF k cannot be negative, got %d", c.Search.RRFK)
+	}
+	if c.Search.Analyzer != "" && !slices.Contains(searchAnalyzers, c.Search.Analyzer) {
+		return fmt.Errorf("invalid search analyzer: %q (valid: %s)", c.Search.Analyzer, strings.Join(searchAnalyzers, ", "))
+	}
+	for repository, analyzer := range c.Search.RepositoryAnalyzers {
+		if !slices.Contains(searchAnalyzers, analyzer) {
+			return fmt.Errorf("invalid search analyzer for repository %s: %q (valid: %s)", repository, analyzer, strings.Join(searchAnalyzers, ", "))
+		}
+	}
+
+	names := make(map[string]bool, len(c.Search.Plan))
+	for i, step := range c.Search.Plan {
+		if step.Name == "" {
+			return fmt.Errorf("search plan step %d has no name", i+1)
+		}
+		if names[step.Name] {
+			return fmt.Errorf("search plan step %q appears twice", step.Name)
+		}
+		names[step.Name] = true
+		if step.MinRelevance < 0 || step.MinRelevance > 1 {
+			return fmt.Errorf("search plan step %q: min relevance must be between 0 and 1", step.Name)
+		}
+		if step.TimeBudgetMs < 0 {
+			return fmt.Errorf("search plan step %q: time budget cannot be negative", step.Name)
+		}
+		if step.RelatedRepositories && step.DropRepository {
+			return fmt.Errorf("search plan step %q cannot both search related repositories and drop the repository", step.Name)
+		}
+	}
 	return nil
 }
 
 // validateServerConfig validates server configuration settings
 func (c *Config) validateServerConfig() error {
+	if c.Server.Name == "" {
+		return errors.New("server name cannot be empty")
+	}
+	if c.Server.Transport != TransportStdio && c.Server.Transport != TransportHTTP {
+		return fmt.Errorf("server transport must be %s or %s, got %q", TransportStdio, TransportHTTP, c.Server.Transport)
+	}
 	if c.Server.Port < 1 || c.Server.Port > 65535 {
 		return fmt.Errorf("invalid server port: %d", c.Server.Port)
 	}
 	if c.Server.Host == "" {
 		return errors.New("server host cannot be empty")
 	}
+	if c.Server.BatchConcurrency < 1 {
+		return fmt.Errorf("batch concurrency must be at least 1, got %d", c.Server.BatchConcurrency)
+	}
+	if c.Server.MaxBatchSize < 1 {
+		return fmt.Errorf("max batch size must be at least 1, got %d", c.Server.MaxBatchSize)
+	}
+	if c.Server.ListPageSize < 0 {
+		return fmt.Errorf("list page size cannot be negative, got %d", c.Server.ListPageSize)
+	}
+	if err := validateResponseLimit("server", c.Server.MaxResponseBytes); err != nil {
+		return err
+	}
+	for tool, limit := range c.Server.ResponseLimits {
+		if err := validateResponseLimit(fmt.Sprintf("tool %q", tool), limit); err != nil {
+			return err
+		}
+	}
+	if c.Server.ResultCacheTTL <= 0 {
+		return fmt.Errorf("result cache TTL must be positive, got %d", c.Server.ResultCacheTTL)
+	}
+	if err := c.validateTLSConfig(); err != nil {
+		return err
+	}
+	return c.validateGRPCConfig()
+}
+
+// validateTLSConfig validates HTTPS for the HTTP transport
+func (c *Config) validateTLSConfig() error {
+	tlsConfig := c.Server.TLS
+	if tlsConfig.MinVersion != "1.2" && tlsConfig.MinVersion != "1.3" {
+		return fmt.Errorf("TLS minimum version must be 1.2 or 1.3, got %q", tlsConfig.MinVersion)
+	}
+	if (tlsConfig.CertFile == "") != (tlsConfig.KeyFile == "") {
+		return errors.New("TLS requires both a certificate and a key file")
+	}
+	if tlsConfig.CertFile != "" && len(tlsConfig.AutocertDomains) > 0 {
+		return errors.New("TLS certificate files and autocert domains are mutually exclusive")
+	}
+	if len(tlsConfig.AutocertDomains) > 0 && tlsConfig.AutocertCacheDir == "" {
+		return errors.New("autocert requires a cache directory (set MCP_MEMORY_TLS_AUTOCERT_CACHE_DIR)")
+	}
+	if tlsConfig.ClientCAFile != "" && !tlsConfig.Enabled() {
+		return errors.New("TLS client certificate verification requires a certificate or autocert domains")
+	}
+	return nil
+}
+
+// validateGRPCConfig validates the gRPC transport. gRPC requests are not
+// signed, authorized with OAuth or API keys, or checked against the network
+// policy, so servers that require any of these must require client
+// certificates on gRPC instead, and the clients holding one are operators.
+// They carry no key or token a tenant could come from, so gRPC cannot be
+// served under tenant isolation.
+func (c *Config) validateGRPCConfig() error {
+	grpcConfig := c.Server.GRPC
+	if !grpcConfig.Enabled {
+		return nil
+	}
+	if c.Security.Tenancy.Enabled {
+		return errors.New("gRPC cannot be enabled with tenant isolation: gRPC clients carry no API key or token to hold them to a tenant")
+	}
+	if grpcConfig.Address == "" {
+		return errors.New("gRPC address cannot be empty (set MCP_MEMORY_GRPC_ADDRESS)")
+	}
+	if (grpcConfig.TLSCertFile == "") != (grpcConfig.TLSKeyFile == "") {
+		return errors.New("gRPC TLS requires both a certificate and a key file")
+	}
+	if grpcConfig.ClientCAFile != "" && grpcConfig.TLSCertFile == "" {
+		return errors.New("gRPC client certificate verification requires a TLS certificate and key")
+	}
+	if grpcConfig.ClientCAFile == "" && (c.Security.ReplayProtection || c.HasNetworkPolicy() || c.Security.OAuth.Enabled || c.Security.APIKeys.Enabled) {
+		return errors.New("gRPC requires mTLS (set MCP_MEMORY_GRPC_CLIENT_CA_FILE) when replay protection, a network policy, OAuth or API keys are enabled")
+	}
 	return nil
 }
 
@@ -514,13 +1955,116 @@ func (c *Config) validateQdrantConfig() error {
 	if c.Qdrant.Docker.Enabled && c.Qdrant.Docker.ContainerName == "" {
 		return errors.New("docker container name cannot be empty when docker is enabled")
 	}
+	if err := c.validateQuantizationConfig(); err != nil {
+		return err
+	}
+	return c.validateReadReplicaConfig()
+}
+
+// validateQuantizationConfig validates vector quantization settings
+func (c *Config) validateQuantizationConfig() error {
+	quantization := c.Qdrant.Quantization
+	modes := map[string]string{"": quantization.Mode}
+	for collection, mode := range quantization.Collections {
+		modes[collection] = mode
+	}
+	for collection, mode := range modes {
+		switch mode {
+		case QuantizationNone, QuantizationScalar, QuantizationProduct:
+		default:
+			if collection != "" {
+				return fmt.Errorf("invalid qdrant quantization mode for collection %s: %s (must be %s, %s or %s)", collection, mode, QuantizationNone, QuantizationScalar, QuantizationProduct)
+			}
+			return fmt.Errorf("invalid qdrant quantization mode: %s (must be %s, %s or %s)", mode, QuantizationNone, QuantizationScalar, QuantizationProduct)
+		}
+	}
+	switch quantization.Compression {
+	case "x4", "x8", "x16", "x32", "x64":
+	default:
+		return fmt.Errorf("invalid qdrant product quantization compression: %s (must be x4, x8, x16, x32 or x64)", quantization.Compression)
+	}
+	if quantization.Quantile <= 0.5 || quantization.Quantile > 1 {
+		return fmt.Errorf("qdrant quantization quantile must be in (0.5, 1], got %g", quantization.Quantile)
+	}
+	if quantization.Oversampling < 1 {
+		return fmt.Errorf("qdrant quantization oversampling must be at least 1, got %g", quantization.Oversampling)
+	}
 	return nil
 }
 
+// validateReadReplicaConfig validates read replica routing settings
+func (c *Config) validateReadReplicaConfig() error {
+	replicas := c.Qdrant.ReadReplicas
+	switch replicas.ReadPreference {
+	case ReadPreferencePrimary, ReadPreferenceReplica:
+	default:
+		return fmt.Errorf("invalid qdrant read preference: %s (must be %s or %s)", replicas.ReadPreference, ReadPreferencePrimary, ReadPreferenceReplica)
+	}
+	if replicas.MaxStalenessMs < 0 {
+		return errors.New("qdrant max staleness cannot be negative")
+	}
+	for _, host := range replicas.Hosts {
+		if _, _, err := SplitReplicaHost(host, c.Qdrant.Port); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SplitReplicaHost parses a read replica address, host or host:port, using
+// defaultPort when none is given
+func SplitReplicaHost(address string, defaultPort int) (string, int, error) {
+	host, portText, err := net.SplitHostPort(address)
+	if err != nil {
+		// No port; the whole address is the host
+		host, portText = address, ""
+	}
+	if host == "" {
+		return "", 0, fmt.Errorf("invalid qdrant read replica %q: empty host", address)
+	}
+	if portText == "" {
+		return host, defaultPort, nil
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil || port <= 0 || port > 65535 {
+		return "", 0, fmt.Errorf("invalid qdrant read replica %q: bad port", address)
+	}
+	return host, port, nil
+}
+
+// validateEmbeddingConfig validates the embedding provider and its settings
+func (c *Config) validateEmbeddingConfig() error {
+	if c.Embedding.PassageRunes < 0 {
+		return fmt.Errorf("embedding passage runes cannot be negative: %d", c.Embedding.PassageRunes)
+	}
+	if c.Embedding.PassageOverlap < 0 || (c.Embedding.PassageRunes > 0 && c.Embedding.PassageOverlap >= c.Embedding.PassageRunes) {
+		return fmt.Errorf("embedding passage overlap must be at least 0 and below the passage runes (%d): %d", c.Embedding.PassageRunes, c.Embedding.PassageOverlap)
+	}
+	if c.Embedding.BatchSize < 0 {
+		return fmt.Errorf("embedding batch size cannot be negative: %d", c.Embedding.BatchSize)
+	}
+	if c.Embedding.BatchSize > 1 && (c.Embedding.BatchMaxTokens <= 0 || c.Embedding.BatchWaitMs <= 0) {
+		return errors.New("embedding batch max tokens and batch wait must be positive when batching")
+	}
+	switch c.Embedding.Provider {
+	case EmbeddingProviderOpenAI:
+		return c.validateOpenAIConfig()
+	case EmbeddingProviderNone:
+		if c.Storage.Provider != StorageProviderKeyword && c.Storage.Provider != StorageProviderLocal {
+			return fmt.Errorf("lite mode requires the %q storage provider (or %q), got %q", StorageProviderKeyword, StorageProviderLocal, c.Storage.Provider)
+		}
+		return nil
+	case EmbeddingProviderFake:
+		return nil
+	default:
+		return fmt.Errorf("invalid embedding provider: %s (must be %s, %s or %s)", c.Embedding.Provider, EmbeddingProviderOpenAI, EmbeddingProviderFake, EmbeddingProviderNone)
+	}
+}
+
 // validateOpenAIConfig validates OpenAI API configuration
 func (c *Config) validateOpenAIConfig() error {
 	if c.OpenAI.APIKey == "" {
-		return errors.New("OpenAI API key is required")
+		return errors.New("OpenAI API key is required (set MCP_MEMORY_LITE_MODE=true to run without embeddings)")
 	}
 	if c.OpenAI.EmbeddingModel == "" {
 		return errors.New("OpenAI embedding model cannot be empty")
@@ -533,9 +2077,260 @@ func (c *Config) validateStorageConfig() error {
 	if c.Storage.RetentionDays <= 0 {
 		return errors.New("retention days must be positive")
 	}
+	if c.Storage.TrashRetentionDays < 0 {
+		return errors.New("trash retention days cannot be negative")
+	}
+	if classes := c.Storage.MemoryClasses; classes.EpisodicRetentionDays < 0 || classes.SemanticRetentionDays < 0 || classes.ProceduralRetentionDays < 0 {
+		return errors.New("memory class retention days cannot be negative")
+	}
+	if ingestion := c.Storage.Ingestion; ingestion.Enabled {
+		if ingestion.BatchSize <= 0 {
+			return errors.New("ingestion batch size must be positive")
+		}
+		if ingestion.FlushIntervalMs <= 0 {
+			return errors.New("ingestion flush interval must be positive")
+		}
+		if ingestion.MaxPending < ingestion.BatchSize {
+			return fmt.Errorf("ingestion max pending (%d) must be at least the batch size (%d)", ingestion.MaxPending, ingestion.BatchSize)
+		}
+		if ingestion.SpillPath == "" {
+			return errors.New("ingestion spill path is required")
+		}
+	}
+	if c.Storage.Provider == StorageProviderPgVector {
+		return c.validatePgVectorConfig()
+	}
+	return nil
+}
+
+// validatePgVectorConfig validates the pgvector store settings
+func (c *Config) validatePgVectorConfig() error {
+	pg := c.PgVector
+	if pg.DSN == "" {
+		return errors.New("the pgvector storage provider requires MCP_MEMORY_PGVECTOR_DSN")
+	}
+	if !ValidTableName(pg.Table) {
+		return fmt.Errorf("invalid pgvector table %q: use lowercase letters, digits and underscores, up to 48 characters", pg.Table)
+	}
+	// pgvector indexes vectors of at most 2000 dimensions
+	if pg.Dimension <= 0 || pg.Dimension > 2000 {
+		return fmt.Errorf("pgvector dimension must be between 1 and 2000: %d", pg.Dimension)
+	}
+	if pg.HNSWM < 2 || pg.HNSWM > 100 {
+		return fmt.Errorf("pgvector hnsw m must be between 2 and 100: %d", pg.HNSWM)
+	}
+	if pg.HNSWEfConstruction < 2*pg.HNSWM {
+		return fmt.Errorf("pgvector hnsw ef_construction (%d) must be at least twice m (%d)", pg.HNSWEfConstruction, pg.HNSWM)
+	}
+	if pg.HNSWEfSearch < 0 {
+		return fmt.Errorf("pgvector hnsw ef_search cannot be negative: %d", pg.HNSWEfSearch)
+	}
+	return nil
+}
+
+// tableNamePattern is what ValidTableName accepts; the prefix leaves room
+// for suffixes such as _relationships within PostgreSQL's 63 byte limit
+var tableNamePattern = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,47}$`)
+
+// ValidTableName reports whether name can be used unquoted as a SQL table name
+func ValidTableName(name string) bool {
+	return tableNamePattern.MatchString(name)
+}
+
+// validateLLMConfig checks that every feature uses a known provider with credentials
+func (c *Config) validateLLMConfig() error {
+	if c.LLM.EnrichmentEnabled && (c.LLM.SummarizationProvider == "" || c.LLM.SummarizationProvider == LLMProviderNone) {
+		return errors.New("AI enrichment requires a summarization LLM provider (set MCP_MEMORY_LLM_SUMMARIZATION_PROVIDER)")
+	}
+	features := map[string]string{
+		"summarization":         c.LLM.SummarizationProvider,
+		"conflict verification": c.LLM.ConflictVerificationProvider,
+		"insights":              c.LLM.InsightsProvider,
+	}
+	for feature, provider := range features {
+		switch provider {
+		case LLMProviderNone, "":
+		case LLMProviderOpenAI, LLMProviderAnthropic, LLMProviderGemini:
+			if c.LLMProvider(provider).APIKey == "" {
+				return fmt.Errorf("%s LLM provider %q requires an API key", feature, provider)
+			}
+		case LLMProviderOllama:
+			if c.LLM.Ollama.BaseURL == "" {
+				return fmt.Errorf("%s LLM provider %q requires a base URL", feature, provider)
+			}
+		default:
+			return fmt.Errorf("invalid %s LLM provider: %s (must be one of %s, %s, %s, %s, %s)", feature, provider,
+				LLMProviderNone, LLMProviderOpenAI, LLMProviderAnthropic, LLMProviderGemini, LLMProviderOllama)
+		}
+	}
+	return nil
+}
+
+// validateSecurityConfig validates network allowlists and replay protection settings
+func (c *Config) validateSecurityConfig() error {
+	for _, entry := range append(append([]string{}, c.Security.IPAllowlist...), c.Security.AdminIPAllowlist...) {
+		if !validAddressRange(entry) {
+			return fmt.Errorf("invalid IP allowlist entry %q (expected a CIDR such as 10.0.0.0/8 or an IP address)", entry)
+		}
+	}
+
+	if err := c.validateOAuthConfig(); err != nil {
+		return err
+	}
+	if c.Security.APIKeys.Enabled && c.Security.APIKeys.Path == "" {
+		return errors.New("API keys require a key file path (set MCP_MEMORY_API_KEYS_PATH)")
+	}
+	if c.Security.Tenancy.Enabled && !c.Security.APIKeys.Enabled && !c.Security.OAuth.Enabled {
+		return errors.New("tenancy requires API keys or OAuth to authenticate tenants")
+	}
+
+	if !c.Security.ReplayProtection {
+		return nil
+	}
+	if len(c.Security.SigningSecret) < 32 {
+		return errors.New("replay protection requires a request signing secret of at least 32 characters (set MCP_MEMORY_REQUEST_SIGNING_SECRET)")
+	}
+	if c.Security.MaxClockSkew <= 0 {
+		return errors.New("replay protection max clock skew must be positive")
+	}
+	if c.Security.NonceCacheSize <= 0 {
+		return errors.New("replay protection nonce cache size must be positive")
+	}
+	return nil
+}
+
+// validateOAuthConfig validates OAuth authorization of the HTTP transport
+func (c *Config) validateOAuthConfig() error {
+	oauth := c.Security.OAuth
+	if !oauth.Enabled {
+		return nil
+	}
+	if !absoluteURL(oauth.Resource) {
+		return fmt.Errorf("OAuth requires the absolute URI of this server as resource (set MCP_MEMORY_OAUTH_RESOURCE), got %q", oauth.Resource)
+	}
+	if len(oauth.AuthorizationServers) == 0 {
+		return errors.New("OAuth requires at least one authorization server (set MCP_MEMORY_OAUTH_AUTHORIZATION_SERVERS)")
+	}
+	for _, server := range oauth.AuthorizationServers {
+		if !absoluteURL(server) {
+			return fmt.Errorf("invalid OAuth authorization server %q (expected an absolute URL)", server)
+		}
+	}
+	if oauth.JWKSURL != "" && !absoluteURL(oauth.JWKSURL) {
+		return fmt.Errorf("invalid OAuth JWKS URL %q (expected an absolute URL)", oauth.JWKSURL)
+	}
+	if oauth.JWKSURL == "" && !absoluteURL(oauth.TokenIssuer()) {
+		return fmt.Errorf("OAuth needs a JWKS URL or an issuer URL to discover it from, got issuer %q", oauth.TokenIssuer())
+	}
+	if oauth.JWKSCacheTTL <= 0 {
+		return errors.New("OAuth JWKS cache TTL must be positive")
+	}
+	if oauth.ClockSkew < 0 {
+		return errors.New("OAuth clock skew cannot be negative")
+	}
+	return nil
+}
+
+// absoluteURL reports whether value is an absolute http(s) URL without a fragment
+func absoluteURL(value string) bool {
+	parsed, err := url.Parse(value)
+	return err == nil && (parsed.Scheme == "https" || parsed.Scheme == "http") && parsed.Host != "" && parsed.Fragment == ""
+}
+
+// validateChaosConfig validates fault injection rates and latencies
+func (c *Config) validateChaosConfig() error {
+	for _, rate := range []float64{c.Chaos.VectorStoreErrorRate, c.Chaos.EmbeddingsErrorRate} {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("chaos error rate must be between 0 and 1, got %v", rate)
+		}
+	}
+	for _, latency := range []int{c.Chaos.VectorStoreLatencyMs, c.Chaos.EmbeddingsLatencyMs} {
+		if latency < 0 || latency > maxChaosLatencyMs {
+			return fmt.Errorf("chaos latency must be between 0 and %d ms, got %d", maxChaosLatencyMs, latency)
+		}
+	}
 	return nil
 }
 
+// validateResponseLimit checks a tool response size limit leaves room for
+// the truncation notice; 0 turns the limit off
+func validateResponseLimit(scope string, limit int) error {
+	if limit < 0 || (limit > 0 && limit < MinResponseBytes) {
+		return fmt.Errorf("%s response limit must be 0 or at least %d bytes, got %d", scope, MinResponseBytes, limit)
+	}
+	return nil
+}
+
+// validateRateLimitConfig validates tool call rate limits
+func (c *Config) validateRateLimitConfig() error {
+	if !c.RateLimit.Enabled {
+		return nil
+	}
+	if c.RateLimit.RequestsPerMinute < 0 || c.RateLimit.Burst < 0 {
+		return fmt.Errorf("rate limit requests per minute and burst must not be negative, got %d and %d", c.RateLimit.RequestsPerMinute, c.RateLimit.Burst)
+	}
+	for _, limits := range []map[string]int{c.RateLimit.Tools, c.RateLimit.Clients} {
+		for name, limit := range limits {
+			if name == "" || limit <= 0 {
+				return fmt.Errorf("rate limit for %q must be positive, got %d", name, limit)
+			}
+		}
+	}
+	return nil
+}
+
+// validateQoSConfig validates request scheduling classes
+func (c *Config) validateQoSConfig() error {
+	if !c.QoS.Enabled {
+		return nil
+	}
+	if c.QoS.MaxConcurrent < 0 || c.QoS.QueueTimeoutSeconds < 0 {
+		return fmt.Errorf("qos max concurrent and queue timeout must not be negative, got %d and %d", c.QoS.MaxConcurrent, c.QoS.QueueTimeoutSeconds)
+	}
+	for _, class := range []string{QoSInteractive, QoSBulk, QoSAdmin} {
+		limits := c.QoS.Class(class)
+		if limits.Concurrency < 1 || limits.QueueSize < 0 {
+			return fmt.Errorf("qos class %s needs a concurrency of at least 1 and a queue size of at least 0, got %d and %d", class, limits.Concurrency, limits.QueueSize)
+		}
+	}
+	for tool, class := range c.QoS.Tools {
+		if tool == "" || (class != QoSInteractive && class != QoSBulk && class != QoSAdmin) {
+			return fmt.Errorf("invalid qos class %q for %q: must be interactive, bulk or admin", class, tool)
+		}
+	}
+	return nil
+}
+
+// validAddressRange reports whether value is a CIDR range or an IP address
+func validAddressRange(value string) bool {
+	if _, err := netip.ParsePrefix(value); err == nil {
+		return true
+	}
+	_, err := netip.ParseAddr(value)
+	return err == nil
+}
+
+// HasNetworkPolicy reports whether client addresses are restricted
+func (c *Config) HasNetworkPolicy() bool {
+	return c.Security.NetworkPolicyFile != "" || len(c.Security.IPAllowlist) > 0 || len(c.Security.AdminIPAllowlist) > 0
+}
+
+// LLMProvider returns the settings of the named LLM provider
+func (c *Config) LLMProvider(name string) LLMProviderConfig {
+	switch name {
+	case LLMProviderOpenAI:
+		return c.LLM.OpenAI
+	case LLMProviderAnthropic:
+		return c.LLM.Anthropic
+	case LLMProviderGemini:
+		return c.LLM.Gemini
+	case LLMProviderOllama:
+		return c.LLM.Ollama
+	default:
+		return LLMProviderConfig{}
+	}
+}
+
 // validateChunkingConfig validates chunking algorithm configuration
 func (c *Config) validateChunkingConfig() error {
 	if c.Chunking.MinContentLength <= 0 {
@@ -550,6 +2345,11 @@ func (c *Config) validateChunkingConfig() error {
 	return nil
 }
 
+// IsLiteMode reports whether the server runs without an embedding provider
+func (c *Config) IsLiteMode() bool {
+	return c.Embedding.Provider == EmbeddingProviderNone
+}
+
 // GetDataDir returns the data directory path, creating it if necessary
 func (c *Config) GetDataDir() (string, error) {
 	dataDir := c.Qdrant.Docker.VolumePath
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -44,11 +45,20 @@ func TestDefaultConfig(t *testing.T) {
 	assert.Equal(t, 60, cfg.OpenAI.RequestTimeout)
 	assert.Equal(t, 60, cfg.OpenAI.RateLimitRPM)
 
+	// Embedding defaults
+	assert.Equal(t, "openai", cfg.Embedding.Provider)
+	assert.False(t, cfg.IsLiteMode())
+
 	// Storage defaults
 	assert.Equal(t, "qdrant", cfg.Storage.Provider)
+	assert.Equal(t, "./data/keyword_store.json", cfg.Storage.KeywordPath)
 	assert.Equal(t, 90, cfg.Storage.RetentionDays)
 	assert.False(t, cfg.Storage.BackupEnabled)
 	assert.Equal(t, 24, cfg.Storage.BackupInterval)
+	assert.Equal(t, 30, cfg.Storage.TrashRetentionDays)
+	assert.Equal(t, 90, cfg.Storage.MemoryClasses.EpisodicRetentionDays)
+	assert.Zero(t, cfg.Storage.MemoryClasses.SemanticRetentionDays)
+	assert.True(t, cfg.Storage.MemoryClasses.PromoteOnExpiry)
 	assert.NotNil(t, cfg.Storage.Repositories)
 
 	// Chunking defaults
@@ -66,6 +76,14 @@ func TestDefaultConfig(t *testing.T) {
 	assert.Equal(t, 10, cfg.Logging.MaxSize)
 	assert.Equal(t, 3, cfg.Logging.MaxBackups)
 	assert.Equal(t, 30, cfg.Logging.MaxAge)
+
+	// Digest defaults
+	assert.False(t, cfg.Digest.Enabled)
+	assert.Equal(t, 7, cfg.Digest.StaleTaskDays)
+	assert.Equal(t, 587, cfg.Digest.SMTPPort)
+
+	// Intelligence defaults
+	assert.True(t, cfg.Intelligence.DecisionExtraction)
 }
 
 func TestConfig_Validate(t *testing.T) {
@@ -161,6 +179,150 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "OpenAI API key is required",
 		},
+		{
+			name: "lite mode without OpenAI API key",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.Embedding.Provider = EmbeddingProviderNone
+				cfg.Storage.Provider = StorageProviderKeyword
+				return cfg
+			},
+			wantErr: false,
+		},
+		{
+			name: "lite mode with vector storage",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.Embedding.Provider = EmbeddingProviderNone
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "lite mode requires the \"keyword\" storage provider",
+		},
+		{
+			name: "fake embedding provider without OpenAI API key",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.Embedding.Provider = EmbeddingProviderFake
+				return cfg
+			},
+			wantErr: false,
+		},
+		{
+			name: "chaos error rate out of range",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Chaos.Enabled = true
+				cfg.Chaos.VectorStoreErrorRate = 1.5
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "chaos error rate must be between 0 and 1",
+		},
+		{
+			name: "ingestion max pending below batch size",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Storage.Ingestion.Enabled = true
+				cfg.Storage.Ingestion.MaxPending = 10
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "ingestion max pending (10) must be at least the batch size (64)",
+		},
+		{
+			name: "pgvector without dsn",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Storage.Provider = StorageProviderPgVector
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "requires MCP_MEMORY_PGVECTOR_DSN",
+		},
+		{
+			name: "pgvector table that is not an identifier",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Storage.Provider = StorageProviderPgVector
+				cfg.PgVector.DSN = "postgres://localhost/mcp_memory"
+				cfg.PgVector.Table = "chunks; DROP TABLE users"
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "invalid pgvector table",
+		},
+		{
+			name: "pgvector ef_construction below twice m",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Storage.Provider = StorageProviderPgVector
+				cfg.PgVector.DSN = "postgres://localhost/mcp_memory"
+				cfg.PgVector.HNSWEfConstruction = 20
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "pgvector hnsw ef_construction (20) must be at least twice m (16)",
+		},
+		{
+			name: "invalid read preference",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Qdrant.ReadReplicas.ReadPreference = "nearest"
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "invalid qdrant read preference: nearest",
+		},
+		{
+			name: "read replica with bad port",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Qdrant.ReadReplicas.Hosts = []string{"replica-1", "replica-2:http"}
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  `invalid qdrant read replica "replica-2:http": bad port`,
+		},
+		{
+			name: "search plan step without name",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Search.Plan = []SearchStep{{Name: "strict"}, {MinRelevance: 0.2}}
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "search plan step 2 has no name",
+		},
+		{
+			name: "search early exit score out of range",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Search.EarlyExitScore = 1.5
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "search early exit score must be between 0 and 1",
+		},
+		{
+			name: "invalid embedding provider",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.Embedding.Provider = "word2vec"
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "invalid embedding provider",
+		},
 		{
 			name: "empty embedding model",
 			config: func() *Config {
@@ -227,6 +389,309 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "similarity threshold must be between 0 and 1",
 		},
+		{
+			name: "invalid llm provider",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.LLM.InsightsProvider = "unknown"
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "invalid insights LLM provider",
+		},
+		{
+			name: "llm provider without api key",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.LLM.ConflictVerificationProvider = LLMProviderAnthropic
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "requires an API key",
+		},
+		{
+			name: "local llm provider needs no api key",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.LLM.SummarizationProvider = LLMProviderOllama
+				return cfg
+			},
+			wantErr: false,
+		},
+		{
+			name: "replay protection without signing secret",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Security.ReplayProtection = true
+				cfg.Security.SigningSecret = "too-short"
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "request signing secret",
+		},
+		{
+			name: "invalid ip allowlist entry",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Security.IPAllowlist = []string{"10.0.0.0/8", "10.0.0.300"}
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "invalid IP allowlist entry",
+		},
+		{
+			name: "replay protection with signing secret",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Security.ReplayProtection = true
+				cfg.Security.SigningSecret = "0123456789abcdef0123456789abcdef"
+				return cfg
+			},
+			wantErr: false,
+		},
+		{
+			name: "grpc certificate without key",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Server.GRPC.Enabled = true
+				cfg.Server.GRPC.TLSCertFile = "server.crt"
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "both a certificate and a key",
+		},
+		{
+			name: "grpc without mtls under replay protection",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Security.ReplayProtection = true
+				cfg.Security.SigningSecret = "0123456789abcdef0123456789abcdef"
+				cfg.Server.GRPC.Enabled = true
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "gRPC requires mTLS",
+		},
+		{
+			name: "grpc with mtls",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Security.IPAllowlist = []string{"10.0.0.0/8"}
+				cfg.Server.GRPC = GRPCConfig{Enabled: true, Address: ":9090", TLSCertFile: "server.crt", TLSKeyFile: "server.key", ClientCAFile: "ca.crt"}
+				return cfg
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown quantization mode for a collection",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Qdrant.Quantization.Collections = map[string]string{"claude_memory": "binary"}
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "quantization mode for collection claude_memory",
+		},
+		{
+			name: "product quantization with unsupported compression",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Qdrant.Quantization.Mode = QuantizationProduct
+				cfg.Qdrant.Quantization.Compression = "x3"
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "compression",
+		},
+		{
+			name: "scalar quantization",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Qdrant.Quantization.Mode = QuantizationScalar
+				cfg.Qdrant.Quantization.AlwaysRAM = true
+				return cfg
+			},
+			wantErr: false,
+		},
+		{
+			name: "tls with unsupported minimum version",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Server.TLS.MinVersion = "1.1"
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "TLS minimum version",
+		},
+		{
+			name: "tls certificate files and autocert",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Server.TLS.CertFile = "server.crt"
+				cfg.Server.TLS.KeyFile = "server.key"
+				cfg.Server.TLS.AutocertDomains = []string{"memory.example.com"}
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "mutually exclusive",
+		},
+		{
+			name: "tls client ca without certificate",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Server.TLS.ClientCAFile = "ca.crt"
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "client certificate verification",
+		},
+		{
+			name: "autocert with mtls",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Server.TLS.AutocertDomains = []string{"memory.example.com"}
+				cfg.Server.TLS.ClientCAFile = "ca.crt"
+				cfg.Server.TLS.MinVersion = "1.3"
+				return cfg
+			},
+			wantErr: false,
+		},
+		{
+			name: "oauth without resource",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Security.OAuth.Enabled = true
+				cfg.Security.OAuth.AuthorizationServers = []string{"https://auth.example.com"}
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "MCP_MEMORY_OAUTH_RESOURCE",
+		},
+		{
+			name: "oauth with relative authorization server",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Security.OAuth.Enabled = true
+				cfg.Security.OAuth.Resource = "https://memory.example.com/mcp"
+				cfg.Security.OAuth.AuthorizationServers = []string{"auth.example.com"}
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "invalid OAuth authorization server",
+		},
+		{
+			name: "oauth without grpc mtls",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Security.OAuth.Enabled = true
+				cfg.Security.OAuth.Resource = "https://memory.example.com/mcp"
+				cfg.Security.OAuth.AuthorizationServers = []string{"https://auth.example.com"}
+				cfg.Server.GRPC = GRPCConfig{Enabled: true, Address: ":9090"}
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "gRPC requires mTLS",
+		},
+		{
+			name: "http tools without a reload interval",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.HTTPTools = HTTPToolsConfig{File: "tools.yaml"}
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "http tools reload interval must be at least 1 second",
+		},
+		{
+			name: "unknown transport",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Server.Transport = "sse"
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "server transport must be stdio or http",
+		},
+		{
+			name: "api keys without a path",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Security.APIKeys = APIKeysConfig{Enabled: true}
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "API keys require a key file path",
+		},
+		{
+			name: "api keys without grpc mtls",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Security.APIKeys.Enabled = true
+				cfg.Server.GRPC = GRPCConfig{Enabled: true, Address: ":9090"}
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "gRPC requires mTLS",
+		},
+		{
+			name: "grpc under tenancy",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Security.APIKeys = APIKeysConfig{Enabled: true, Path: "keys.json"}
+				cfg.Security.Tenancy.Enabled = true
+				cfg.Server.GRPC = GRPCConfig{Enabled: true, Address: ":9090", TLSCertFile: "server.crt", TLSKeyFile: "server.key", ClientCAFile: "ca.crt"}
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "gRPC cannot be enabled with tenant isolation",
+		},
+		{
+			name: "tenancy without authentication",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Security.Tenancy.Enabled = true
+				return cfg
+			},
+			wantErr: true,
+			errMsg:  "tenancy requires API keys or OAuth",
+		},
+		{
+			name: "valid oauth",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OpenAI.APIKey = testAPIKey
+				cfg.Security.OAuth.Enabled = true
+				cfg.Security.OAuth.Resource = "https://memory.example.com/mcp"
+				cfg.Security.OAuth.AuthorizationServers = []string{"https://auth.example.com"}
+				return cfg
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -262,6 +727,8 @@ func TestLoadConfig_WithEnvVars(t *testing.T) {
 		"MCP_MEMORY_LOG_LEVEL":   "debug",
 		"MCP_MEMORY_LOG_FORMAT":  "text",
 		"MCP_MEMORY_LOG_FILE":    "/var/log/memory.log",
+
+		"MCP_MEMORY_DECISION_EXTRACTION_ENABLED": "false",
 	}
 
 	// Set environment variables
@@ -293,6 +760,7 @@ func TestLoadConfig_WithEnvVars(t *testing.T) {
 	assert.Equal(t, "debug", cfg.Logging.Level)
 	assert.Equal(t, "text", cfg.Logging.Format)
 	assert.Equal(t, "/var/log/memory.log", cfg.Logging.File)
+	assert.False(t, cfg.Intelligence.DecisionExtraction)
 }
 
 func TestLoadConfig_WithInvalidEnvVars(t *testing.T) {
@@ -312,6 +780,366 @@ func TestLoadConfig_WithInvalidEnvVars(t *testing.T) {
 	assert.Equal(t, 8080, cfg.Server.Port)
 }
 
+func TestLoadConfig_SearchPlan(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", testAPIKey)
+	t.Setenv("MCP_MEMORY_SEARCH_PLAN", `[{"name":"strict"},{"name":"broad","min_relevance":0.2,"drop_types":true,"time_budget_ms":500}]`)
+	t.Setenv("MCP_MEMORY_SEARCH_EARLY_EXIT_SCORE", "0.75")
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 0.75, cfg.Search.EarlyExitScore)
+	assert.Equal(t, []SearchStep{
+		{Name: "strict"},
+		{Name: "broad", MinRelevance: 0.2, DropTypes: true, TimeBudgetMs: 500},
+	}, cfg.Search.SearchPlan())
+
+	t.Setenv("MCP_MEMORY_SEARCH_PLAN", `[{"name":`)
+	_, err = LoadConfig()
+	assert.ErrorContains(t, err, "invalid MCP_MEMORY_SEARCH_PLAN")
+}
+
+func TestLoadConfig_SearchModes(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", testAPIKey)
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.True(t, cfg.Search.KeywordIndex)
+	assert.Equal(t, SearchModeVector, cfg.Search.DefaultMode)
+	assert.Equal(t, 60, cfg.Search.RRFK)
+
+	t.Setenv("MCP_MEMORY_SEARCH_DEFAULT_MODE", "hybrid")
+	t.Setenv("MCP_MEMORY_SEARCH_RRF_K", "20")
+	cfg, err = LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, SearchModeHybrid, cfg.Search.DefaultMode)
+	assert.Equal(t, 20, cfg.Search.RRFK)
+
+	t.Setenv("MCP_MEMORY_SEARCH_KEYWORD_INDEX", "false")
+	_, err = LoadConfig()
+	assert.ErrorContains(t, err, "requires the keyword index")
+	t.Setenv("MCP_MEMORY_SEARCH_KEYWORD_INDEX", "")
+
+	t.Setenv("MCP_MEMORY_SEARCH_DEFAULT_MODE", "fuzzy")
+	_, err = LoadConfig()
+	assert.ErrorContains(t, err, "invalid default search mode")
+}
+
+func TestLoadConfig_SearchAnalyzers(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", testAPIKey)
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, AnalyzerStandard, cfg.Search.Analyzer)
+	assert.Empty(t, cfg.Search.RepositoryAnalyzers)
+
+	t.Setenv("MCP_MEMORY_SEARCH_ANALYZER", "accent_sensitive")
+	t.Setenv("MCP_MEMORY_SEARCH_REPOSITORY_ANALYZERS", "github.com/acme/legacy=simple, broken")
+	cfg, err = LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"github.com/acme/legacy": AnalyzerSimple}, cfg.Search.RepositoryAnalyzers)
+	assert.Equal(t, AnalyzerSimple, cfg.Search.AnalyzerFor("github.com/acme/legacy"))
+	assert.Equal(t, AnalyzerAccentSensitive, cfg.Search.AnalyzerFor("github.com/acme/api"))
+
+	t.Setenv("MCP_MEMORY_SEARCH_REPOSITORY_ANALYZERS", "github.com/acme/legacy=stemmed")
+	_, err = LoadConfig()
+	assert.ErrorContains(t, err, "invalid search analyzer for repository github.com/acme/legacy")
+
+	t.Setenv("MCP_MEMORY_SEARCH_REPOSITORY_ANALYZERS", "")
+	t.Setenv("MCP_MEMORY_SEARCH_ANALYZER", "stemmed")
+	_, err = LoadConfig()
+	assert.ErrorContains(t, err, "invalid search analyzer")
+}
+
+func TestLoadConfig_ResponseLimits(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", testAPIKey)
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultMaxResponseBytes, cfg.Server.MaxResponseBytes)
+
+	t.Setenv("MCP_MEMORY_MAX_RESPONSE_BYTES", "65536")
+	t.Setenv("MCP_MEMORY_RESPONSE_LIMITS", "memory_read=1048576, system_snapshot=0")
+	cfg, err = LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 65536, cfg.Server.MaxResponseBytes)
+	assert.Equal(t, map[string]int{"memory_read": 1048576, "system_snapshot": 0}, cfg.Server.ResponseLimits)
+
+	t.Setenv("MCP_MEMORY_RESPONSE_LIMITS", "memory_read=100")
+	_, err = LoadConfig()
+	assert.ErrorContains(t, err, "response limit must be 0 or at least")
+
+	t.Setenv("MCP_MEMORY_RESPONSE_LIMITS", "")
+	t.Setenv("MCP_MEMORY_RESULT_CACHE_TTL_SECONDS", "0")
+	_, err = LoadConfig()
+	assert.ErrorContains(t, err, "result cache TTL must be positive")
+}
+
+func TestLoadConfig_EmbeddingBatching(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", testAPIKey)
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 64, cfg.Embedding.BatchSize)
+
+	t.Setenv("MCP_MEMORY_EMBEDDING_BATCH_SIZE", "128")
+	t.Setenv("MCP_MEMORY_EMBEDDING_BATCH_MAX_TOKENS", "20000")
+	t.Setenv("MCP_MEMORY_EMBEDDING_BATCH_WAIT_MS", "25")
+	cfg, err = LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, EmbeddingConfig{Provider: EmbeddingProviderOpenAI, PassageRunes: 4000, PassageOverlap: 400, BatchSize: 128, BatchMaxTokens: 20000, BatchWaitMs: 25}, cfg.Embedding)
+
+	t.Setenv("MCP_MEMORY_EMBEDDING_BATCH_WAIT_MS", "0")
+	_, err = LoadConfig()
+	assert.ErrorContains(t, err, "batch wait must be positive")
+}
+
+func TestLoadConfig_Usage(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", testAPIKey)
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, UsageConfig{Path: "./data/usage.json", FlushSeconds: 60}, cfg.Usage)
+
+	t.Setenv("MCP_MEMORY_USAGE_METERING", "true")
+	t.Setenv("MCP_MEMORY_USAGE_PATH", "/var/lib/memory/usage.json")
+	t.Setenv("MCP_MEMORY_USAGE_FLUSH_SECONDS", "30")
+	cfg, err = LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, UsageConfig{Enabled: true, Path: "/var/lib/memory/usage.json", FlushSeconds: 30}, cfg.Usage)
+
+	t.Setenv("MCP_MEMORY_USAGE_FLUSH_SECONDS", "0")
+	_, err = LoadConfig()
+	assert.ErrorContains(t, err, "usage metering")
+}
+
+func TestLoadConfig_Demo(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", testAPIKey)
+	t.Setenv("MCP_MEMORY_SLACK_SYNC_ENABLED", "true")
+	t.Setenv("MCP_MEMORY_DEMO_MODE", "true")
+	t.Setenv("MCP_MEMORY_DEMO_RESET_MINUTES", "15")
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, DemoConfig{Enabled: true, ResetMinutes: 15, RequestsPerMinute: 30, WritesPerMinute: 5}, cfg.Demo)
+	assert.Equal(t, EmbeddingProviderFake, cfg.Embedding.Provider)
+	assert.Equal(t, StorageProviderLocal, cfg.Storage.Provider)
+	assert.Empty(t, cfg.Storage.LocalPath, "the demo store is in memory")
+	assert.Empty(t, cfg.OpenAI.APIKey, "real credentials are dropped")
+	assert.False(t, cfg.SlackSync.Enabled)
+	assert.True(t, cfg.RateLimit.Enabled)
+	assert.Equal(t, 30, cfg.RateLimit.RequestsPerMinute)
+	assert.Equal(t, map[string]int{"memory_create": 5}, cfg.RateLimit.Tools)
+
+	t.Setenv("MCP_MEMORY_DEMO_RPM", "0")
+	_, err = LoadConfig()
+	assert.ErrorContains(t, err, "demo mode")
+}
+
+func TestLoadConfig_RateLimit(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", testAPIKey)
+	t.Setenv("MCP_MEMORY_RATE_LIMIT_ENABLED", "true")
+	t.Setenv("MCP_MEMORY_RATE_LIMIT_TOOLS", "memory_read:search=30, memory_transfer=5,broken")
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.True(t, cfg.RateLimit.Enabled)
+	assert.Equal(t, 600, cfg.RateLimit.RequestsPerMinute)
+	assert.Equal(t, map[string]int{"memory_read:search": 30, "memory_transfer": 5}, cfg.RateLimit.Tools)
+
+	t.Setenv("MCP_MEMORY_RATE_LIMIT_CLIENTS", "ci-bot=0")
+	_, err = LoadConfig()
+	assert.ErrorContains(t, err, "rate limit")
+}
+
+func TestLoadConfig_OAuth(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", testAPIKey)
+	t.Setenv("MCP_MEMORY_OAUTH_ENABLED", "true")
+	t.Setenv("MCP_MEMORY_OAUTH_RESOURCE", "https://memory.example.com/mcp")
+	t.Setenv("MCP_MEMORY_OAUTH_AUTHORIZATION_SERVERS", "https://auth.example.com, https://backup.example.com")
+	t.Setenv("MCP_MEMORY_OAUTH_TOOL_SCOPES", "memory_system=memory:admin,broken")
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	oauth := cfg.Security.OAuth
+	assert.True(t, oauth.Enabled)
+	assert.Equal(t, "https://auth.example.com", oauth.TokenIssuer())
+	assert.Equal(t, "https://memory.example.com/mcp", oauth.TokenAudience())
+	assert.Equal(t, map[string]string{"memory_system": "memory:admin"}, oauth.ToolScopes)
+	assert.Equal(t, []string{"memory:read", "memory:write", "memory:admin"}, oauth.Scopes())
+}
+
+func TestLoadConfig_APIKeys(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", testAPIKey)
+	t.Setenv("MCP_MEMORY_API_KEYS_ENABLED", "true")
+	t.Setenv("MCP_MEMORY_API_KEYS_PATH", "/var/lib/mcp/keys.json")
+	t.Setenv("MCP_MEMORY_TENANCY_ENABLED", "true")
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, APIKeysConfig{Enabled: true, Path: "/var/lib/mcp/keys.json"}, cfg.Security.APIKeys)
+	assert.True(t, cfg.Security.Tenancy.Enabled)
+}
+
+func TestLoadConfig_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+server:
+  name: team-memory
+  version: 2.1.0
+capabilities:
+  prompts: false
+transports:
+  default: http
+  http:
+    host: 0.0.0.0
+    port: 9443
+  grpc:
+    enabled: true
+    address: ":9444"
+    tls_cert_file: /etc/mcp/grpc.crt
+    tls_key_file: /etc/mcp/grpc.key
+    client_ca_file: /etc/mcp/ca.crt
+auth:
+  api_keys:
+    enabled: true
+    path: /var/lib/mcp/keys.json
+rate_limit:
+  enabled: true
+  requests_per_minute: 120
+  tools:
+    memory_store: 10
+logging:
+  level: debug
+`), 0o600))
+	t.Setenv("OPENAI_API_KEY", testAPIKey)
+	t.Setenv("MCP_MEMORY_CONFIG_FILE", path)
+	t.Setenv("MCP_MEMORY_LOG_LEVEL", "warn")
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "team-memory", cfg.Server.Name)
+	assert.Equal(t, "2.1.0", cfg.Server.Version)
+	assert.Equal(t, CapabilitiesConfig{Resources: true, Prompts: false}, cfg.Server.Capabilities)
+	assert.Equal(t, TransportHTTP, cfg.Server.Transport)
+	assert.Equal(t, "0.0.0.0", cfg.Server.Host)
+	assert.Equal(t, 9443, cfg.Server.Port)
+	assert.Equal(t, 30, cfg.Server.ReadTimeout, "settings the file leaves out keep their defaults")
+	assert.True(t, cfg.Server.GRPC.Enabled)
+	assert.Equal(t, APIKeysConfig{Enabled: true, Path: "/var/lib/mcp/keys.json"}, cfg.Security.APIKeys)
+	assert.Equal(t, 120, cfg.RateLimit.RequestsPerMinute)
+	assert.Equal(t, 10, cfg.RateLimit.Tools["memory_store"])
+	assert.Equal(t, 120, cfg.RateLimit.Tools["memory_read:search"], "tool limits the file leaves out keep their defaults")
+	assert.Equal(t, "warn", cfg.Logging.Level, "environment variables override the file")
+	assert.Equal(t, "json", cfg.Logging.Format)
+}
+
+func TestLoadFile_Example(t *testing.T) {
+	cfg := DefaultConfig()
+	require.NoError(t, LoadFile(cfg, filepath.Join("..", "..", "configs", "server.example.yaml")))
+	cfg.OpenAI.APIKey = testAPIKey
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestLoadFile_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		errMsg  string
+	}{
+		{name: "unknown key", content: "server:\n  nmae: typo\n", errMsg: `unknown field "nmae"`},
+		{name: "wrong type", content: "transports:\n  http:\n    port: high\n", errMsg: "invalid config file"},
+		{name: "malformed yaml", content: "server: [name\n", errMsg: "failed to parse config file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "_")+".yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+			err := LoadFile(DefaultConfig(), path)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+
+	cfg := DefaultConfig()
+	require.Error(t, LoadFile(cfg, filepath.Join(dir, "missing.yaml")))
+	empty := filepath.Join(dir, "empty.yaml")
+	require.NoError(t, os.WriteFile(empty, nil, 0o600))
+	require.NoError(t, LoadFile(cfg, empty))
+	assert.Equal(t, DefaultConfig(), cfg, "an empty file changes nothing")
+}
+
+func TestLoadConfig_LiteMode(t *testing.T) {
+	_ = os.Setenv("MCP_MEMORY_LITE_MODE", "true")
+	_ = os.Setenv("MCP_MEMORY_KEYWORD_STORE_PATH", "/tmp/memory.json")
+	_ = os.Unsetenv("OPENAI_API_KEY")
+
+	defer func() {
+		_ = os.Unsetenv("MCP_MEMORY_LITE_MODE")
+		_ = os.Unsetenv("MCP_MEMORY_KEYWORD_STORE_PATH")
+	}()
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+
+	assert.True(t, cfg.IsLiteMode())
+	assert.Equal(t, EmbeddingProviderNone, cfg.Embedding.Provider)
+	assert.Equal(t, StorageProviderKeyword, cfg.Storage.Provider)
+	assert.Equal(t, "/tmp/memory.json", cfg.Storage.KeywordPath)
+}
+
+func TestLoadConfig_VectorBackend(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", testAPIKey)
+	t.Setenv("VECTOR_BACKEND", StorageProviderKeyword)
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, StorageProviderKeyword, cfg.Storage.Provider)
+
+	t.Setenv("MCP_MEMORY_STORAGE_PROVIDER", StorageProviderQdrant)
+	cfg, err = LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, StorageProviderQdrant, cfg.Storage.Provider, "MCP_MEMORY_STORAGE_PROVIDER takes precedence")
+}
+
+func TestLoadConfig_LocalStore(t *testing.T) {
+	t.Setenv("MCP_MEMORY_LITE_MODE", "true")
+	t.Setenv("MCP_MEMORY_STORAGE_PROVIDER", StorageProviderLocal)
+	t.Setenv("MCP_MEMORY_LOCAL_STORE_PATH", "/tmp/memory-local")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, StorageProviderLocal, cfg.Storage.Provider, "lite mode keeps the local store")
+	assert.Equal(t, "/tmp/memory-local", cfg.Storage.LocalPath)
+
+	t.Setenv("MCP_MEMORY_LITE_MODE", "false")
+	t.Setenv("MCP_MEMORY_EMBEDDING_PROVIDER", EmbeddingProviderFake)
+	cfg, err = LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, StorageProviderLocal, cfg.Storage.Provider)
+	assert.Equal(t, EmbeddingProviderFake, cfg.Embedding.Provider, "fake embeddings give the local store vectors offline")
+}
+
+func TestLoadConfig_PgVector(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", testAPIKey)
+	t.Setenv("VECTOR_BACKEND", StorageProviderPgVector)
+	t.Setenv("DATABASE_URL", "postgres://localhost/fallback")
+	t.Setenv("MCP_MEMORY_PGVECTOR_DSN", "postgres://localhost/mcp_memory")
+	t.Setenv("MCP_MEMORY_PGVECTOR_TABLE", "team_memory")
+	t.Setenv("MCP_MEMORY_PGVECTOR_DIMENSION", "768")
+	t.Setenv("MCP_MEMORY_PGVECTOR_HNSW_EF_SEARCH", "100")
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, StorageProviderPgVector, cfg.Storage.Provider)
+	assert.Equal(t, "postgres://localhost/mcp_memory", cfg.PgVector.DSN, "MCP_MEMORY_PGVECTOR_DSN takes precedence over DATABASE_URL")
+	assert.Equal(t, "team_memory", cfg.PgVector.Table)
+	assert.Equal(t, 768, cfg.PgVector.Dimension)
+	assert.Equal(t, 16, cfg.PgVector.HNSWM)
+	assert.Equal(t, 100, cfg.PgVector.HNSWEfSearch)
+}
+
 func TestConfig_GetDataDir(t *testing.T) {
 	cfg := DefaultConfig()
 
@@ -443,3 +1271,30 @@ func TestLoadConfig_InvalidConfig(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid configuration")
 }
+
+func TestLoadConfig_QoS(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", testAPIKey)
+	t.Setenv("MCP_MEMORY_QOS_BULK_CONCURRENCY", "1")
+	t.Setenv("MCP_MEMORY_QOS_BULK_QUEUE_SIZE", "0")
+	t.Setenv("MCP_MEMORY_QOS_TOOLS", "memory_analyze=bulk, memory_read:search=interactive, broken")
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.True(t, cfg.QoS.Enabled)
+	assert.Equal(t, QoSClassConfig{Concurrency: 1, QueueSize: 0}, cfg.QoS.Class(QoSBulk))
+	assert.Equal(t, 48, cfg.QoS.Class(QoSInteractive).Concurrency)
+	assert.Equal(t, map[string]string{"memory_analyze": QoSBulk, "memory_read:search": QoSInteractive}, cfg.QoS.Tools)
+
+	t.Setenv("MCP_MEMORY_QOS_TOOLS", "memory_analyze=background")
+	_, err = LoadConfig()
+	assert.ErrorContains(t, err, `invalid qos class "background"`)
+
+	t.Setenv("MCP_MEMORY_QOS_TOOLS", "")
+	t.Setenv("MCP_MEMORY_QOS_ADMIN_CONCURRENCY", "0")
+	_, err = LoadConfig()
+	assert.ErrorContains(t, err, "qos class admin needs a concurrency of at least 1")
+
+	t.Setenv("MCP_MEMORY_QOS_ENABLED", "false")
+	_, err = LoadConfig()
+	assert.NoError(t, err, "disabled QoS scheduling is not validated")
+}
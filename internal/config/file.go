@@ -0,0 +1,108 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// serverFile is the layout of a server configuration file. Its sections
+// point into the configuration being loaded, so settings the file leaves
+// out keep their current values. Keys follow the JSON names of the
+// configuration they set; secrets such as signing keys are not read from
+// the file and stay in the environment.
+type serverFile struct {
+	Server     *serverFileIdentity   `json:"server"`
+	Transports *serverFileTransports `json:"transports"`
+	Auth       *serverFileAuth       `json:"auth"`
+	RateLimit  *RateLimitConfig      `json:"rate_limit"`
+	QoS        *QoSConfig            `json:"qos"`
+	Logging    *LoggingConfig        `json:"logging"`
+	Demo       *DemoConfig           `json:"demo"`
+
+	Capabilities *CapabilitiesConfig `json:"capabilities"`
+}
+
+type serverFileIdentity struct {
+	Name    *string `json:"name"`
+	Version *string `json:"version"`
+}
+
+type serverFileTransports struct {
+	Default *string         `json:"default"`
+	HTTP    *serverFileHTTP `json:"http"`
+	GRPC    *GRPCConfig     `json:"grpc"`
+	TLS     *TLSConfig      `json:"tls"`
+}
+
+type serverFileHTTP struct {
+	Host         *string `json:"host"`
+	Port         *int    `json:"port"`
+	ReadTimeout  *int    `json:"read_timeout_seconds"`
+	WriteTimeout *int    `json:"write_timeout_seconds"`
+}
+
+type serverFileAuth struct {
+	OAuth   *OAuthConfig   `json:"oauth"`
+	APIKeys *APIKeysConfig `json:"api_keys"`
+	Tenancy *TenancyConfig `json:"tenancy"`
+}
+
+// LoadFile applies a YAML server configuration file to config: the server's
+// name and version, its capabilities, transports, auth, rate limits,
+// QoS classes, logging and demo profile. Unknown keys are rejected so typos do not go unnoticed.
+// LoadConfig applies the file named by MCP_MEMORY_CONFIG_FILE over the
+// defaults, and environment variables still override it.
+func LoadFile(config *Config, path string) error {
+	data, err := os.ReadFile(path) //nolint:gosec // Path comes from operator configuration
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if document == nil {
+		return nil // An empty file changes nothing
+	}
+	// Decode through JSON, so the file shares the configuration's key names
+	encoded, err := json.Marshal(document)
+	if err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	file := serverFile{
+		Server: &serverFileIdentity{Name: &config.Server.Name, Version: &config.Server.Version},
+		Transports: &serverFileTransports{
+			Default: &config.Server.Transport,
+			HTTP: &serverFileHTTP{
+				Host:         &config.Server.Host,
+				Port:         &config.Server.Port,
+				ReadTimeout:  &config.Server.ReadTimeout,
+				WriteTimeout: &config.Server.WriteTimeout,
+			},
+			GRPC: &config.Server.GRPC,
+			TLS:  &config.Server.TLS,
+		},
+		Auth: &serverFileAuth{
+			OAuth:   &config.Security.OAuth,
+			APIKeys: &config.Security.APIKeys,
+			Tenancy: &config.Security.Tenancy,
+		},
+		RateLimit:    &config.RateLimit,
+		QoS:          &config.QoS,
+		Logging:      &config.Logging,
+		Demo:         &config.Demo,
+		Capabilities: &config.Server.Capabilities,
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return nil
+}
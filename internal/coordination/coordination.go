@@ -0,0 +1,400 @@
+// Package coordination provides the primitives agents sharing a repository use
+// to stay out of each other's way: named locks, shared scratchpads and task claims
+package coordination
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultTTL is the lease length used when no TTL is requested
+	DefaultTTL = 15 * time.Minute
+
+	// MaxTTL bounds lease length so the leases of a vanished agent always expire
+	MaxTTL = 24 * time.Hour
+
+	// MaxScratchpadSize bounds the content of one scratchpad
+	MaxScratchpadSize = 64 * 1024
+)
+
+var (
+	// ErrHeld is returned when a lock or task is held by another owner
+	ErrHeld = errors.New("held by another owner")
+
+	// ErrNotHeld is returned when a token does not match the live lease
+	ErrNotHeld = errors.New("lease not held")
+
+	// ErrVersionConflict is returned when a scratchpad changed since the writer read it
+	ErrVersionConflict = errors.New("scratchpad version conflict")
+)
+
+// Lease is a named lock or a task claim held by one owner until it expires
+type Lease struct {
+	Repository string    `json:"repository"`
+	Name       string    `json:"name"`
+	Owner      string    `json:"owner"`
+	Token      string    `json:"token"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Scratchpad is a named note shared by the agents working on a repository
+type Scratchpad struct {
+	Repository string    `json:"repository"`
+	Name       string    `json:"name"`
+	Content    string    `json:"content"`
+	Version    int       `json:"version"`
+	UpdatedBy  string    `json:"updated_by,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// state is the persisted form of a store
+type state struct {
+	Locks       []Lease      `json:"locks"`
+	Claims      []Lease      `json:"claims"`
+	Scratchpads []Scratchpad `json:"scratchpads"`
+}
+
+// Store keeps locks, task claims and scratchpads, persisted to an optional
+// JSON file so they survive restarts. Locks and claims are leases: they expire
+// after their TTL so a crashed agent never blocks the others for long.
+type Store struct {
+	mu          sync.Mutex
+	path        string
+	locks       map[string]map[string]Lease      // repository -> name -> lock
+	claims      map[string]map[string]Lease      // repository -> task ID -> claim
+	scratchpads map[string]map[string]Scratchpad // repository -> name -> scratchpad
+	now         func() time.Time
+}
+
+// NewStore creates a coordination store persisted at path; an empty path keeps it in memory
+func NewStore(path string) *Store {
+	return &Store{
+		path:        path,
+		locks:       make(map[string]map[string]Lease),
+		claims:      make(map[string]map[string]Lease),
+		scratchpads: make(map[string]map[string]Scratchpad),
+		now:         time.Now,
+	}
+}
+
+// Load reads the store from disk. A missing file is not an error.
+func (s *Store) Load() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read coordination state: %w", err)
+	}
+
+	var saved state
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("failed to parse coordination state: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range saved.Locks {
+		putLease(s.locks, &saved.Locks[i])
+	}
+	for i := range saved.Claims {
+		putLease(s.claims, &saved.Claims[i])
+	}
+	for i := range saved.Scratchpads {
+		pad := saved.Scratchpads[i]
+		if s.scratchpads[pad.Repository] == nil {
+			s.scratchpads[pad.Repository] = make(map[string]Scratchpad)
+		}
+		s.scratchpads[pad.Repository][pad.Name] = pad
+	}
+	return nil
+}
+
+// AcquireLock takes the named lock of a repository for owner. Re-acquiring a
+// live lock with the same owner extends the lease and keeps the token.
+func (s *Store) AcquireLock(repository, name, owner string, ttl time.Duration) (*Lease, error) {
+	if name == "" {
+		return nil, errors.New("lock name is required")
+	}
+	return s.acquire(s.locks, repository, name, owner, ttl)
+}
+
+// ReleaseLock drops the named lock if token matches the live lease
+func (s *Store) ReleaseLock(repository, name, token string) error {
+	return s.release(s.locks, repository, name, token)
+}
+
+// Locks returns the live locks of a repository sorted by name
+func (s *Store) Locks(repository string) []Lease {
+	return s.live(s.locks, repository)
+}
+
+// ClaimTask claims a task for owner. Re-claiming a live claim with the same
+// owner extends the lease and keeps the token.
+func (s *Store) ClaimTask(repository, taskID, owner string, ttl time.Duration) (*Lease, error) {
+	if taskID == "" {
+		return nil, errors.New("task ID is required")
+	}
+	return s.acquire(s.claims, repository, taskID, owner, ttl)
+}
+
+// ReleaseTask drops a task claim if token matches the live lease
+func (s *Store) ReleaseTask(repository, taskID, token string) error {
+	return s.release(s.claims, repository, taskID, token)
+}
+
+// Claims returns the live task claims of a repository sorted by task ID
+func (s *Store) Claims(repository string) []Lease {
+	return s.live(s.claims, repository)
+}
+
+// Scratchpad returns a repository's scratchpad by name
+func (s *Store) Scratchpad(repository, name string) (Scratchpad, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pad, ok := s.scratchpads[repository][name]
+	return pad, ok
+}
+
+// Scratchpads returns a repository's scratchpads sorted by name
+func (s *Store) Scratchpads(repository string) []Scratchpad {
+	s.mu.Lock()
+	pads := make([]Scratchpad, 0, len(s.scratchpads[repository]))
+	for name := range s.scratchpads[repository] {
+		pads = append(pads, s.scratchpads[repository][name])
+	}
+	s.mu.Unlock()
+
+	sort.Slice(pads, func(i, j int) bool { return pads[i].Name < pads[j].Name })
+	return pads
+}
+
+// WriteScratchpad replaces a scratchpad's content, or appends a line to it.
+// A non-negative expectedVersion makes the write conditional: it fails with
+// ErrVersionConflict unless the scratchpad is still at that version, where 0
+// means it must not exist yet.
+func (s *Store) WriteScratchpad(repository, name, content, author string, appendLine bool, expectedVersion int) (*Scratchpad, error) {
+	if repository == "" || name == "" {
+		return nil, errors.New("repository and scratchpad name are required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.scratchpads[repository][name]
+	if expectedVersion >= 0 && previous.Version != expectedVersion {
+		return nil, fmt.Errorf("%w: %s is at version %d, not %d", ErrVersionConflict, name, previous.Version, expectedVersion)
+	}
+	if appendLine && previous.Content != "" {
+		content = strings.TrimRight(previous.Content, "\n") + "\n" + content
+	}
+	if len(content) > MaxScratchpadSize {
+		return nil, fmt.Errorf("scratchpad %s would exceed %d bytes", name, MaxScratchpadSize)
+	}
+
+	pad := Scratchpad{
+		Repository: repository,
+		Name:       name,
+		Content:    content,
+		Version:    previous.Version + 1,
+		UpdatedBy:  author,
+		UpdatedAt:  s.now().UTC(),
+	}
+	if s.scratchpads[repository] == nil {
+		s.scratchpads[repository] = make(map[string]Scratchpad)
+	}
+	s.scratchpads[repository][name] = pad
+	if err := s.persistLocked(); err != nil {
+		if existed {
+			s.scratchpads[repository][name] = previous
+		} else {
+			delete(s.scratchpads[repository], name)
+		}
+		return nil, err
+	}
+	return &pad, nil
+}
+
+// DeleteScratchpad removes a scratchpad
+func (s *Store) DeleteScratchpad(repository, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.scratchpads[repository][name]
+	if !ok {
+		return fmt.Errorf("scratchpad %q not found for %s", name, repository)
+	}
+	delete(s.scratchpads[repository], name)
+	if err := s.persistLocked(); err != nil {
+		s.scratchpads[repository][name] = previous
+		return err
+	}
+	return nil
+}
+
+// acquire takes or extends a lease in leases
+func (s *Store) acquire(leases map[string]map[string]Lease, repository, name, owner string, ttl time.Duration) (*Lease, error) {
+	if repository == "" {
+		return nil, errors.New("repository is required")
+	}
+	if owner == "" {
+		return nil, errors.New("owner is required")
+	}
+	ttl = clampTTL(ttl)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now().UTC()
+	previous, existed := leases[repository][name]
+	lease := Lease{
+		Repository: repository,
+		Name:       name,
+		Owner:      owner,
+		Token:      uuid.New().String(),
+		AcquiredAt: now,
+		ExpiresAt:  now.Add(ttl),
+	}
+	if existed && now.Before(previous.ExpiresAt) {
+		if previous.Owner != owner {
+			return nil, fmt.Errorf("%s is %w %s until %s", name, ErrHeld, previous.Owner, previous.ExpiresAt.Format(time.RFC3339))
+		}
+		lease.Token, lease.AcquiredAt = previous.Token, previous.AcquiredAt
+	}
+
+	putLease(leases, &lease)
+	if err := s.persistLocked(); err != nil {
+		if existed {
+			leases[repository][name] = previous
+		} else {
+			delete(leases[repository], name)
+		}
+		return nil, err
+	}
+	return &lease, nil
+}
+
+// release drops a lease in leases if token matches it
+func (s *Store) release(leases map[string]map[string]Lease, repository, name, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := leases[repository][name]
+	if !ok || !s.now().Before(previous.ExpiresAt) || previous.Token != token {
+		return ErrNotHeld
+	}
+	delete(leases[repository], name)
+	if err := s.persistLocked(); err != nil {
+		leases[repository][name] = previous
+		return err
+	}
+	return nil
+}
+
+// live returns the unexpired leases of a repository sorted by name
+func (s *Store) live(leases map[string]map[string]Lease, repository string) []Lease {
+	s.mu.Lock()
+	now := s.now()
+	result := make([]Lease, 0, len(leases[repository]))
+	for name := range leases[repository] {
+		if now.Before(leases[repository][name].ExpiresAt) {
+			result = append(result, leases[repository][name])
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// persistLocked writes the store to disk, leaving out expired leases; callers must hold the lock
+func (s *Store) persistLocked() error {
+	if s.path == "" {
+		return nil
+	}
+
+	now := s.now()
+	saved := state{
+		Locks:       liveLeases(s.locks, now),
+		Claims:      liveLeases(s.claims, now),
+		Scratchpads: make([]Scratchpad, 0),
+	}
+	for repository := range s.scratchpads {
+		for name := range s.scratchpads[repository] {
+			saved.Scratchpads = append(saved.Scratchpads, s.scratchpads[repository][name])
+		}
+	}
+	sort.Slice(saved.Scratchpads, func(i, j int) bool {
+		if saved.Scratchpads[i].Repository != saved.Scratchpads[j].Repository {
+			return saved.Scratchpads[i].Repository < saved.Scratchpads[j].Repository
+		}
+		return saved.Scratchpads[i].Name < saved.Scratchpads[j].Name
+	})
+
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode coordination state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o750); err != nil {
+		return fmt.Errorf("failed to create coordination state directory: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write coordination state: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// putLease stores a lease under its repository and name
+func putLease(leases map[string]map[string]Lease, lease *Lease) {
+	if leases[lease.Repository] == nil {
+		leases[lease.Repository] = make(map[string]Lease)
+	}
+	leases[lease.Repository][lease.Name] = *lease
+}
+
+// liveLeases lists the unexpired leases of every repository in a stable order
+func liveLeases(leases map[string]map[string]Lease, now time.Time) []Lease {
+	result := make([]Lease, 0)
+	for repository := range leases {
+		for name := range leases[repository] {
+			if now.Before(leases[repository][name].ExpiresAt) {
+				result = append(result, leases[repository][name])
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Repository != result[j].Repository {
+			return result[i].Repository < result[j].Repository
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func clampTTL(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return DefaultTTL
+	}
+	if ttl > MaxTTL {
+		return MaxTTL
+	}
+	return ttl
+}
@@ -0,0 +1,115 @@
+package coordination
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const repo = "github.com/acme/api"
+
+func TestLocks(t *testing.T) {
+	s := NewStore("")
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	lock, err := s.AcquireLock(repo, "migrations", "agent-a", time.Minute)
+	require.NoError(t, err)
+	assert.NotEmpty(t, lock.Token)
+
+	_, err = s.AcquireLock(repo, "migrations", "agent-b", time.Minute)
+	assert.ErrorIs(t, err, ErrHeld)
+	_, err = s.AcquireLock("github.com/acme/web", "migrations", "agent-b", time.Minute)
+	assert.NoError(t, err, "lock names are scoped to their repository")
+
+	renewed, err := s.AcquireLock(repo, "migrations", "agent-a", time.Hour)
+	require.NoError(t, err, "the holder can extend its lease")
+	assert.Equal(t, lock.Token, renewed.Token)
+	assert.Equal(t, now.Add(time.Hour).UTC(), renewed.ExpiresAt)
+
+	assert.ErrorIs(t, s.ReleaseLock(repo, "migrations", "wrong-token"), ErrNotHeld)
+	require.NoError(t, s.ReleaseLock(repo, "migrations", lock.Token))
+	assert.Empty(t, s.Locks(repo))
+
+	_, err = s.AcquireLock(repo, "migrations", "agent-b", 0)
+	require.NoError(t, err)
+	now = now.Add(DefaultTTL)
+	assert.Empty(t, s.Locks(repo), "leases lapse at their expiry")
+	_, err = s.AcquireLock(repo, "migrations", "agent-a", 48*time.Hour)
+	require.NoError(t, err, "an expired lease can be taken over")
+	assert.Equal(t, "agent-a", s.Locks(repo)[0].Owner)
+	assert.Equal(t, now.Add(MaxTTL).UTC(), s.Locks(repo)[0].ExpiresAt)
+}
+
+func TestClaims(t *testing.T) {
+	s := NewStore("")
+
+	claim, err := s.ClaimTask(repo, "task-1", "agent-a", 0)
+	require.NoError(t, err)
+	_, err = s.ClaimTask(repo, "task-1", "agent-b", 0)
+	assert.ErrorIs(t, err, ErrHeld)
+	_, err = s.AcquireLock(repo, "task-1", "agent-b", 0)
+	assert.NoError(t, err, "claims and locks do not share names")
+
+	require.Len(t, s.Claims(repo), 1)
+	assert.Equal(t, "agent-a", s.Claims(repo)[0].Owner)
+	require.NoError(t, s.ReleaseTask(repo, "task-1", claim.Token))
+	assert.ErrorIs(t, s.ReleaseTask(repo, "task-1", claim.Token), ErrNotHeld)
+}
+
+func TestScratchpads(t *testing.T) {
+	s := NewStore("")
+
+	pad, err := s.WriteScratchpad(repo, "plan", "1. split the handler", "agent-a", false, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, pad.Version)
+
+	_, err = s.WriteScratchpad(repo, "plan", "rewrite", "agent-b", false, 0)
+	assert.ErrorIs(t, err, ErrVersionConflict, "version 0 only creates")
+
+	pad, err = s.WriteScratchpad(repo, "plan", "2. add tests", "agent-b", true, -1)
+	require.NoError(t, err)
+	assert.Equal(t, "1. split the handler\n2. add tests", pad.Content)
+	assert.Equal(t, 2, pad.Version)
+	assert.Equal(t, "agent-b", pad.UpdatedBy)
+
+	_, err = s.WriteScratchpad(repo, "plan", "stale", "agent-a", false, 1)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+
+	_, err = s.WriteScratchpad(repo, "plan", string(make([]byte, MaxScratchpadSize+1)), "agent-a", false, -1)
+	assert.Error(t, err)
+
+	require.NoError(t, s.DeleteScratchpad(repo, "plan"))
+	_, ok := s.Scratchpad(repo, "plan")
+	assert.False(t, ok)
+}
+
+func TestStorePersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "coordination.json")
+	s := NewStore(path)
+
+	lock, err := s.AcquireLock(repo, "release", "agent-a", time.Hour)
+	require.NoError(t, err)
+	_, err = s.ClaimTask(repo, "task-1", "agent-a", time.Millisecond)
+	require.NoError(t, err)
+	_, err = s.WriteScratchpad(repo, "notes", "deploy frozen", "agent-a", false, -1)
+	require.NoError(t, err)
+	time.Sleep(2 * time.Millisecond)
+	_, err = s.WriteScratchpad(repo, "notes", "deploy open", "agent-a", false, -1)
+	require.NoError(t, err)
+
+	reloaded := NewStore(path)
+	require.NoError(t, reloaded.Load())
+	require.Len(t, reloaded.Locks(repo), 1)
+	assert.Equal(t, lock.Token, reloaded.Locks(repo)[0].Token)
+	assert.Empty(t, reloaded.Claims(repo), "expired claims are not persisted")
+	pad, ok := reloaded.Scratchpad(repo, "notes")
+	require.True(t, ok)
+	assert.Equal(t, "deploy open", pad.Content)
+	assert.Equal(t, 2, pad.Version)
+
+	assert.NoError(t, NewStore(filepath.Join(t.TempDir(), "missing.json")).Load())
+}
@@ -1,4 +1,5 @@
-// Package decay implements memory decay with smart summarization
+// Package decay implements memory decay: per-repository policies that archive
+// or delete memories that fell out of use, and smart summarization
 package decay
 
 import (
@@ -0,0 +1,325 @@
+package decay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"lerian-mcp-memory/internal/tenancy"
+	"lerian-mcp-memory/pkg/types"
+)
+
+// DefaultPolicyRepository names the policy applied to repositories without
+// one of their own
+const DefaultPolicyRepository = "*"
+
+// Actions a policy takes on memories that decayed
+const (
+	// ActionArchive moves memories to the archive tier, out of default searches
+	ActionArchive = "archive"
+	// ActionDelete moves memories to the trash, from which they are purged
+	ActionDelete = "delete"
+)
+
+// Policy decides which memories of a repository decay out of active use.
+// A memory's relevance halves every HalfLifeDays since it was stored or last
+// accessed, whichever is later; memories whose relevance falls below
+// Threshold are archived or deleted by Action, unless they were accessed at
+// least MinAccessCount times or are of a protected type.
+type Policy struct {
+	Repository     string            `json:"repository"`
+	HalfLifeDays   float64           `json:"half_life_days"`
+	Threshold      float64           `json:"threshold"`
+	MinAccessCount int               `json:"min_access_count"`
+	Action         string            `json:"action"`
+	ProtectedTypes []types.ChunkType `json:"protected_types,omitempty"`
+	// Tenant that set the policy, if it was held to one; scheduled runs
+	// apply the policy on the tenant's behalf
+	Tenant *tenancy.Tenant `json:"tenant,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DefaultPolicy returns the policy a repository starts from: archive what
+// fell below a quarter of its relevance, with a 90-day half-life, unless it
+// was used three times or records an architecture decision
+func DefaultPolicy(repository string) Policy {
+	return Policy{
+		Repository:     repository,
+		HalfLifeDays:   90,
+		Threshold:      0.25,
+		MinAccessCount: 3,
+		Action:         ActionArchive,
+		ProtectedTypes: []types.ChunkType{types.ChunkTypeArchitectureDecision},
+	}
+}
+
+// Validate checks a policy's settings
+func (p *Policy) Validate() error {
+	switch {
+	case p.Repository == "":
+		return errors.New("repository is required for a decay policy")
+	case p.HalfLifeDays <= 0:
+		return fmt.Errorf("half_life_days must be positive, got %g", p.HalfLifeDays)
+	case p.Threshold <= 0 || p.Threshold >= 1:
+		return fmt.Errorf("threshold must be between 0 and 1, got %g", p.Threshold)
+	case p.MinAccessCount < 0:
+		return fmt.Errorf("min_access_count must not be negative, got %d", p.MinAccessCount)
+	case p.Action != ActionArchive && p.Action != ActionDelete:
+		return fmt.Errorf("invalid action %q: use %s or %s", p.Action, ActionArchive, ActionDelete)
+	}
+	for _, chunkType := range p.ProtectedTypes {
+		if !chunkType.Valid() {
+			return fmt.Errorf("invalid chunk type in protected_types: %s", chunkType)
+		}
+	}
+	return nil
+}
+
+// Candidate is a memory a policy would archive or delete
+type Candidate struct {
+	ChunkID      string          `json:"chunk_id"`
+	Type         types.ChunkType `json:"type"`
+	Summary      string          `json:"summary,omitempty"`
+	Relevance    float64         `json:"relevance"`
+	IdleDays     int             `json:"idle_days"`
+	AccessCount  int             `json:"access_count"`
+	LastAccessed *time.Time      `json:"last_accessed,omitempty"`
+	Action       string          `json:"action"`
+}
+
+// Relevance returns how relevant a memory still is at time now, from 1 when
+// just stored or accessed down towards 0
+func (p *Policy) Relevance(chunk *types.ConversationChunk, now time.Time) float64 {
+	idle := idleDays(chunk, now)
+	return math.Pow(0.5, float64(idle)/p.HalfLifeDays)
+}
+
+// Evaluate returns the memories among chunks the policy would archive or
+// delete at time now, least relevant first. Trashed, archived and passage
+// chunks are left alone.
+func (p *Policy) Evaluate(chunks []types.ConversationChunk, now time.Time) []Candidate {
+	candidates := make([]Candidate, 0)
+	for i := range chunks {
+		chunk := &chunks[i]
+		if chunk.IsDeleted() || chunk.IsArchived() || chunk.IsPassage() || slices.Contains(p.ProtectedTypes, chunk.Type) {
+			continue
+		}
+		accessCount := AccessCount(chunk)
+		if p.MinAccessCount > 0 && accessCount >= p.MinAccessCount {
+			continue
+		}
+		relevance := p.Relevance(chunk, now)
+		if relevance >= p.Threshold {
+			continue
+		}
+		candidates = append(candidates, Candidate{
+			ChunkID:      chunk.ID,
+			Type:         chunk.Type,
+			Summary:      chunk.Summary,
+			Relevance:    relevance,
+			IdleDays:     idleDays(chunk, now),
+			AccessCount:  accessCount,
+			LastAccessed: LastAccessed(chunk),
+			Action:       p.Action,
+		})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Relevance < candidates[j].Relevance })
+	return candidates
+}
+
+// AccessCount returns how often a memory was accessed, as recorded by usage
+// analytics
+func AccessCount(chunk *types.ConversationChunk) int {
+	switch count := chunk.Metadata.ExtendedMetadata[types.EMKeyAccessCount].(type) {
+	case int:
+		return count
+	case float64:
+		return int(count)
+	}
+	return 0
+}
+
+// LastAccessed returns when a memory was last accessed, or nil if never
+func LastAccessed(chunk *types.ConversationChunk) *time.Time {
+	value, _ := chunk.Metadata.ExtendedMetadata[types.EMKeyLastAccessed].(string)
+	accessed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+	return &accessed
+}
+
+// idleDays counts the whole days since a memory was stored or last accessed,
+// so a memory's relevance is stable within a day
+func idleDays(chunk *types.ConversationChunk, now time.Time) int {
+	since := chunk.Timestamp
+	if accessed := LastAccessed(chunk); accessed != nil && accessed.After(since) {
+		since = *accessed
+	}
+	if since.IsZero() {
+		return 0
+	}
+	return int(math.Max(0, math.Floor(now.Sub(since).Hours()/24)))
+}
+
+// PolicyStore keeps decay policies per repository. Policies are persisted to
+// an optional JSON file so they survive restarts.
+type PolicyStore struct {
+	mu       sync.RWMutex
+	path     string
+	policies map[string]Policy // repository -> policy
+}
+
+// NewPolicyStore creates a policy store persisted at path; an empty path keeps it in memory
+func NewPolicyStore(path string) *PolicyStore {
+	return &PolicyStore{
+		path:     path,
+		policies: make(map[string]Policy),
+	}
+}
+
+// Load reads policies from disk. A missing file is not an error.
+func (s *PolicyStore) Load() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read decay policies: %w", err)
+	}
+
+	var policies []Policy
+	if err := json.Unmarshal(data, &policies); err != nil {
+		return fmt.Errorf("failed to parse decay policies: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range policies {
+		s.policies[policies[i].Repository] = policies[i]
+	}
+	return nil
+}
+
+// Set creates or replaces a repository's policy
+func (s *PolicyStore) Set(policy Policy) (*Policy, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	previous, existed := s.policies[policy.Repository]
+	policy.CreatedAt = now
+	if existed {
+		policy.CreatedAt = previous.CreatedAt
+	}
+	policy.UpdatedAt = now
+	s.policies[policy.Repository] = policy
+	if err := s.persistLocked(); err != nil {
+		if existed {
+			s.policies[policy.Repository] = previous
+		} else {
+			delete(s.policies, policy.Repository)
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// Get returns the policy set for a repository itself
+func (s *PolicyStore) Get(repository string) (Policy, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	policy, ok := s.policies[repository]
+	return policy, ok
+}
+
+// For returns the policy applied to a repository: its own, else the default
+// policy when one is set
+func (s *PolicyStore) For(repository string) (Policy, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if policy, ok := s.policies[repository]; ok {
+		return policy, true
+	}
+	policy, ok := s.policies[DefaultPolicyRepository]
+	if ok {
+		policy.Repository = repository
+	}
+	return policy, ok
+}
+
+// List returns every policy sorted by repository
+func (s *PolicyStore) List() []Policy {
+	s.mu.RLock()
+	policies := make([]Policy, 0, len(s.policies))
+	for repository := range s.policies {
+		policies = append(policies, s.policies[repository])
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Repository < policies[j].Repository })
+	return policies
+}
+
+// Delete removes a repository's policy, leaving its memories to the default
+// policy if one is set
+func (s *PolicyStore) Delete(repository string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	policy, ok := s.policies[repository]
+	if !ok {
+		return fmt.Errorf("no decay policy for %s", repository)
+	}
+	delete(s.policies, repository)
+	if err := s.persistLocked(); err != nil {
+		s.policies[repository] = policy
+		return err
+	}
+	return nil
+}
+
+// persistLocked writes all policies to disk; callers must hold the write lock
+func (s *PolicyStore) persistLocked() error {
+	if s.path == "" {
+		return nil
+	}
+
+	policies := make([]Policy, 0, len(s.policies))
+	for repository := range s.policies {
+		policies = append(policies, s.policies[repository])
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Repository < policies[j].Repository })
+
+	data, err := json.MarshalIndent(policies, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode decay policies: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o750); err != nil {
+		return fmt.Errorf("failed to create decay policies directory: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write decay policies: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
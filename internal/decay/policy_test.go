@@ -0,0 +1,129 @@
+package decay
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/pkg/types"
+)
+
+func policyChunk(id string, chunkType types.ChunkType, stored time.Time, extended map[string]interface{}) types.ConversationChunk {
+	return types.ConversationChunk{
+		ID:        id,
+		Type:      chunkType,
+		Timestamp: stored,
+		Metadata: types.ChunkMetadata{
+			Repository:       "github.com/acme/api",
+			ExtendedMetadata: extended,
+		},
+	}
+}
+
+func TestPolicyEvaluate(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	policy := DefaultPolicy("github.com/acme/api")
+	deleted := now.Add(-time.Hour)
+
+	chunks := []types.ConversationChunk{
+		policyChunk("fresh", types.ChunkTypeDiscussion, now.AddDate(0, 0, -10), nil),
+		policyChunk("stale", types.ChunkTypeDiscussion, now.AddDate(0, 0, -200), nil),
+		policyChunk("staler", types.ChunkTypeProblem, now.AddDate(0, 0, -400), nil),
+		policyChunk("used", types.ChunkTypeDiscussion, now.AddDate(0, 0, -400), map[string]interface{}{types.EMKeyAccessCount: float64(5)}),
+		policyChunk("recently-read", types.ChunkTypeDiscussion, now.AddDate(0, 0, -400), map[string]interface{}{
+			types.EMKeyAccessCount:  1,
+			types.EMKeyLastAccessed: now.AddDate(0, 0, -3).Format(time.RFC3339),
+		}),
+		policyChunk("decision", types.ChunkTypeArchitectureDecision, now.AddDate(0, 0, -400), nil),
+		policyChunk("archived", types.ChunkTypeDiscussion, now.AddDate(0, 0, -400), map[string]interface{}{types.EMKeyArchivedAt: now.Format(time.RFC3339)}),
+	}
+	trashed := policyChunk("trashed", types.ChunkTypeDiscussion, now.AddDate(0, 0, -400), nil)
+	trashed.Metadata.DeletedAt = &deleted
+	chunks = append(chunks, trashed)
+
+	candidates := policy.Evaluate(chunks, now)
+	if len(candidates) != 2 {
+		t.Fatalf("Expected 2 candidates, got %d: %+v", len(candidates), candidates)
+	}
+	if candidates[0].ChunkID != "staler" || candidates[1].ChunkID != "stale" {
+		t.Errorf("Expected least relevant first, got %s then %s", candidates[0].ChunkID, candidates[1].ChunkID)
+	}
+	if candidates[1].IdleDays != 200 || candidates[1].Action != ActionArchive {
+		t.Errorf("Unexpected candidate %+v", candidates[1])
+	}
+
+	// Without a minimum access count, heavily used memories decay too
+	policy.MinAccessCount = 0
+	if got := len(policy.Evaluate(chunks, now)); got != 3 {
+		t.Errorf("Expected 3 candidates without a minimum access count, got %d", got)
+	}
+}
+
+func TestPolicyRelevanceHalvesEveryHalfLife(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	policy := DefaultPolicy("github.com/acme/api")
+	chunk := policyChunk("c", types.ChunkTypeDiscussion, now.AddDate(0, 0, -90), nil)
+	if got := policy.Relevance(&chunk, now); got != 0.5 {
+		t.Errorf("Expected relevance 0.5 after one half-life, got %g", got)
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	invalid := []func(*Policy){
+		func(p *Policy) { p.Repository = "" },
+		func(p *Policy) { p.HalfLifeDays = 0 },
+		func(p *Policy) { p.Threshold = 1 },
+		func(p *Policy) { p.MinAccessCount = -1 },
+		func(p *Policy) { p.Action = "shred" },
+		func(p *Policy) { p.ProtectedTypes = []types.ChunkType{"nonsense"} },
+	}
+	for i, mutate := range invalid {
+		policy := DefaultPolicy("github.com/acme/api")
+		mutate(&policy)
+		if err := policy.Validate(); err == nil {
+			t.Errorf("Case %d: expected a validation error for %+v", i, policy)
+		}
+	}
+}
+
+func TestPolicyStorePersistsAndFallsBack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decay_policies.json")
+	store := NewPolicyStore(path)
+
+	if _, ok := store.For("github.com/acme/api"); ok {
+		t.Fatal("Expected no policy before any is set")
+	}
+
+	fallback := DefaultPolicy(DefaultPolicyRepository)
+	fallback.Action = ActionDelete
+	if _, err := store.Set(fallback); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	own := DefaultPolicy("github.com/acme/api")
+	own.HalfLifeDays = 30
+	if _, err := store.Set(own); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	reloaded := NewPolicyStore(path)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if policy, _ := reloaded.For("github.com/acme/api"); policy.HalfLifeDays != 30 {
+		t.Errorf("Expected the repository's own policy, got %+v", policy)
+	}
+	policy, ok := reloaded.For("github.com/acme/web")
+	if !ok || policy.Action != ActionDelete || policy.Repository != "github.com/acme/web" {
+		t.Errorf("Expected the default policy for an unconfigured repository, got %+v", policy)
+	}
+
+	if err := reloaded.Delete("github.com/acme/api"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if policy, _ := reloaded.For("github.com/acme/api"); policy.Action != ActionDelete {
+		t.Errorf("Expected the default policy after deleting the repository's own, got %+v", policy)
+	}
+	if err := reloaded.Delete("github.com/acme/api"); err == nil {
+		t.Error("Expected an error deleting a missing policy")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"lerian-mcp-memory/internal/llm"
 	"lerian-mcp-memory/pkg/types"
 	"math"
 	"sort"
@@ -356,12 +357,12 @@ type embeddedChunk struct {
 	embedding []float32
 }
 
-// LLMSummarizer uses an LLM for more intelligent summarization
+// LLMSummarizer uses an LLM for more intelligent summarization. Without a
+// provider it builds a structured narrative summary from embeddings and heuristics.
 type LLMSummarizer struct {
-	// This would integrate with an LLM API
-	// For demonstration, we'll extend DefaultSummarizer
 	*DefaultSummarizer
 	embeddingGen EmbeddingGenerator
+	provider     llm.Provider
 }
 
 // NewLLMSummarizer creates a new LLM-based summarizer
@@ -372,6 +373,11 @@ func NewLLMSummarizer(embeddingGen EmbeddingGenerator) *LLMSummarizer {
 	}
 }
 
+// SetProvider sets the LLM used to write summaries
+func (l *LLMSummarizer) SetProvider(provider llm.Provider) {
+	l.provider = provider
+}
+
 // Summarize uses LLM to create an intelligent summary
 func (l *LLMSummarizer) Summarize(ctx context.Context, chunks []types.ConversationChunk) (string, error) {
 	if len(chunks) == 0 {
@@ -395,6 +401,18 @@ func (l *LLMSummarizer) Summarize(ctx context.Context, chunks []types.Conversati
 	// Phase 4: Generate intelligent summary using narrative structure
 	summary := l.generateIntelligentSummary(narrative, &criticalInfo)
 
+	// Phase 5: Let the LLM rewrite the structured summary when one is configured
+	if l.provider != nil {
+		resp, err := l.provider.Complete(ctx, llm.Request{
+			System:    "You condense engineering memory notes. Keep decisions, solutions, open problems and file names. Reply with the summary only.",
+			Prompt:    summary,
+			MaxTokens: 400,
+		})
+		if err == nil && resp.Text != "" {
+			return resp.Text, nil
+		}
+	}
+
 	return summary, nil
 }
 
@@ -0,0 +1,208 @@
+// Package dedup finds near-duplicate memories stored within one session, as
+// bulk imports from chat logs tend to produce, and folds them into a
+// canonical memory
+package dedup
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"lerian-mcp-memory/internal/decay"
+	"lerian-mcp-memory/pkg/types"
+)
+
+// DefaultThreshold is the similarity above which two memories of a session
+// are duplicates
+const DefaultThreshold = 0.95
+
+// Duplicate is a memory found to duplicate a group's canonical memory
+type Duplicate struct {
+	ChunkID    string  `json:"chunk_id"`
+	Summary    string  `json:"summary,omitempty"`
+	Similarity float64 `json:"similarity"`
+}
+
+// Group is a canonical memory and the near-duplicates to merge into it
+type Group struct {
+	SessionID        string          `json:"session_id"`
+	Type             types.ChunkType `json:"type"`
+	CanonicalID      string          `json:"canonical_id"`
+	CanonicalSummary string          `json:"canonical_summary,omitempty"`
+	Duplicates       []Duplicate     `json:"duplicates"`
+}
+
+// Find groups near-duplicates among chunks: memories of the same session and
+// type whose similarity exceeds threshold. The earliest stored memory of a
+// group is its canonical one. Trashed, archived and passage chunks are left
+// out. Groups are ordered by session, then by when the canonical was stored.
+func Find(chunks []types.ConversationChunk, threshold float64) []Group {
+	bySession := make(map[string][]*types.ConversationChunk)
+	for i := range chunks {
+		chunk := &chunks[i]
+		if chunk.SessionID == "" || chunk.IsDeleted() || chunk.IsArchived() || chunk.IsPassage() {
+			continue
+		}
+		bySession[chunk.SessionID] = append(bySession[chunk.SessionID], chunk)
+	}
+	sessions := make([]string, 0, len(bySession))
+	for session := range bySession {
+		sessions = append(sessions, session)
+	}
+	sort.Strings(sessions)
+
+	groups := make([]Group, 0)
+	for _, session := range sessions {
+		members := bySession[session]
+		sort.SliceStable(members, func(i, j int) bool {
+			if !members[i].Timestamp.Equal(members[j].Timestamp) {
+				return members[i].Timestamp.Before(members[j].Timestamp)
+			}
+			return members[i].ID < members[j].ID
+		})
+
+		// Each memory joins the first earlier canonical it duplicates, or
+		// becomes a canonical itself
+		canonicals := make([]*types.ConversationChunk, 0)
+		found := make(map[string]*Group)
+		for _, chunk := range members {
+			var (
+				canonical  *types.ConversationChunk
+				similarity float64
+			)
+			for _, candidate := range canonicals {
+				if candidate.Type != chunk.Type {
+					continue
+				}
+				if s := Similarity(candidate, chunk); s > threshold {
+					canonical, similarity = candidate, s
+					break
+				}
+			}
+			if canonical == nil {
+				canonicals = append(canonicals, chunk)
+				continue
+			}
+
+			group, ok := found[canonical.ID]
+			if !ok {
+				group = &Group{
+					SessionID:        session,
+					Type:             canonical.Type,
+					CanonicalID:      canonical.ID,
+					CanonicalSummary: canonical.Summary,
+				}
+				found[canonical.ID] = group
+			}
+			group.Duplicates = append(group.Duplicates, Duplicate{ChunkID: chunk.ID, Summary: chunk.Summary, Similarity: similarity})
+		}
+		for _, canonical := range canonicals {
+			if group, ok := found[canonical.ID]; ok {
+				groups = append(groups, *group)
+			}
+		}
+	}
+	return groups
+}
+
+// Similarity returns the cosine similarity of two chunks' embeddings. Chunks
+// without comparable embeddings are similar only when their content is the
+// same apart from case and surrounding whitespace.
+func Similarity(a, b *types.ConversationChunk) float64 {
+	if len(a.Embeddings) == 0 || len(a.Embeddings) != len(b.Embeddings) {
+		if strings.EqualFold(strings.TrimSpace(a.Content), strings.TrimSpace(b.Content)) {
+			return 1
+		}
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a.Embeddings {
+		dot += a.Embeddings[i] * b.Embeddings[i]
+		normA += a.Embeddings[i] * a.Embeddings[i]
+		normB += b.Embeddings[i] * b.Embeddings[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// Merge folds a duplicate's metadata into the canonical chunk at time now:
+// tags, files, tools and related chunks are combined, access counts added up,
+// a finished outcome replaces one still in progress, and extended metadata
+// the canonical lacks is copied over. The duplicate and the duplicates merged
+// into it earlier are recorded in the canonical's merge history, and the
+// duplicate is marked as merged into the canonical.
+func Merge(canonical, duplicate *types.ConversationChunk, similarity float64, now time.Time) {
+	into, from := &canonical.Metadata, &duplicate.Metadata
+	into.Tags = union(into.Tags, from.Tags)
+	into.FilesModified = union(into.FilesModified, from.FilesModified)
+	into.ToolsUsed = union(into.ToolsUsed, from.ToolsUsed)
+	if into.Outcome == types.OutcomeInProgress && from.Outcome != "" {
+		into.Outcome = from.Outcome
+	}
+	if into.TimeSpent == nil {
+		into.TimeSpent = from.TimeSpent
+	}
+
+	related := union(canonical.RelatedChunks, duplicate.RelatedChunks)
+	canonical.RelatedChunks = slices.DeleteFunc(related, func(id string) bool {
+		return id == canonical.ID || id == duplicate.ID
+	})
+
+	if into.ExtendedMetadata == nil {
+		into.ExtendedMetadata = make(map[string]interface{})
+	}
+	for key, value := range from.ExtendedMetadata {
+		if _, set := into.ExtendedMetadata[key]; set || unmergedKeys[key] {
+			continue
+		}
+		into.ExtendedMetadata[key] = value
+	}
+	if count := decay.AccessCount(canonical) + decay.AccessCount(duplicate); count > 0 {
+		into.ExtendedMetadata[types.EMKeyAccessCount] = count
+	}
+	if accessed := decay.LastAccessed(duplicate); accessed != nil {
+		if current := decay.LastAccessed(canonical); current == nil || accessed.After(*current) {
+			into.ExtendedMetadata[types.EMKeyLastAccessed] = accessed.UTC().Format(time.RFC3339)
+		}
+	}
+
+	canonical.RecordMerge(append(duplicate.MergeHistory(), types.MergeRecord{
+		ChunkID:    duplicate.ID,
+		SessionID:  duplicate.SessionID,
+		Summary:    duplicate.Summary,
+		Similarity: similarity,
+		StoredAt:   duplicate.Timestamp,
+		MergedAt:   now.UTC(),
+	})...)
+
+	if from.ExtendedMetadata == nil {
+		from.ExtendedMetadata = make(map[string]interface{})
+	}
+	from.ExtendedMetadata[types.EMKeyMergedInto] = canonical.ID
+}
+
+// unmergedKeys are extended metadata keys that describe one chunk's own state
+// and are never copied from a duplicate
+var unmergedKeys = map[string]bool{
+	types.EMKeyMergeHistory: true,
+	types.EMKeyMergedInto:   true,
+	types.EMKeyArchivedAt:   true,
+	types.EMKeyArchivedBy:   true,
+	types.EMKeyAccessCount:  true,
+	types.EMKeyLastAccessed: true,
+}
+
+// union appends the values of b missing from a, keeping a's order
+func union(a, b []string) []string {
+	for _, value := range b {
+		if !slices.Contains(a, value) {
+			a = append(a, value)
+		}
+	}
+	return a
+}
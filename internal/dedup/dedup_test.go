@@ -0,0 +1,100 @@
+package dedup
+
+import (
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var stored = time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+
+func dedupChunk(id, session string, minutes int, chunkType types.ChunkType, embeddings []float64) types.ConversationChunk {
+	return types.ConversationChunk{
+		ID:         id,
+		SessionID:  session,
+		Timestamp:  stored.Add(time.Duration(minutes) * time.Minute),
+		Type:       chunkType,
+		Content:    "content of " + id,
+		Embeddings: embeddings,
+		Metadata:   types.ChunkMetadata{Repository: "github.com/acme/api", Outcome: types.OutcomeInProgress},
+	}
+}
+
+func TestFind(t *testing.T) {
+	deletedAt := stored
+	trashed := dedupChunk("trashed", "s1", 4, types.ChunkTypeProblem, []float64{1, 0, 0})
+	trashed.Metadata.DeletedAt = &deletedAt
+	plain := dedupChunk("plain-copy", "s3", 1, types.ChunkTypeDiscussion, nil)
+	plain.Content = "  CONTENT of plain "
+
+	chunks := []types.ConversationChunk{
+		dedupChunk("copy", "s1", 2, types.ChunkTypeProblem, []float64{0.99, 0.05, 0}),
+		dedupChunk("original", "s1", 1, types.ChunkTypeProblem, []float64{1, 0, 0}),
+		dedupChunk("different", "s1", 3, types.ChunkTypeProblem, []float64{0, 1, 0}),
+		dedupChunk("other-type", "s1", 3, types.ChunkTypeSolution, []float64{1, 0, 0}),
+		dedupChunk("other-session", "s2", 3, types.ChunkTypeProblem, []float64{1, 0, 0}),
+		trashed,
+		dedupChunk("plain", "s3", 0, types.ChunkTypeDiscussion, nil),
+		plain,
+	}
+
+	groups := Find(chunks, DefaultThreshold)
+	require.Len(t, groups, 2)
+	assert.Equal(t, "original", groups[0].CanonicalID, "the earliest memory is canonical")
+	require.Len(t, groups[0].Duplicates, 1)
+	assert.Equal(t, "copy", groups[0].Duplicates[0].ChunkID)
+	assert.Greater(t, groups[0].Duplicates[0].Similarity, DefaultThreshold)
+	assert.Equal(t, "plain", groups[1].CanonicalID, "memories without embeddings match on content")
+	assert.Equal(t, "plain-copy", groups[1].Duplicates[0].ChunkID)
+
+	strict := Find(chunks, 0.9999)
+	require.Len(t, strict, 1, "a stricter threshold leaves only exact matches")
+	assert.Equal(t, "plain", strict[0].CanonicalID)
+}
+
+func TestMerge(t *testing.T) {
+	canonical := dedupChunk("original", "s1", 1, types.ChunkTypeProblem, nil)
+	canonical.Metadata.Tags = []string{"deploy"}
+	canonical.RelatedChunks = []string{"a"}
+	canonical.Metadata.ExtendedMetadata = map[string]interface{}{
+		types.EMKeyAccessCount:  float64(2),
+		types.EMKeyLastAccessed: "2026-05-02T00:00:00Z",
+	}
+
+	duplicate := dedupChunk("copy", "s1", 2, types.ChunkTypeProblem, nil)
+	duplicate.Metadata.Tags = []string{"deploy", "helm"}
+	duplicate.Metadata.FilesModified = []string{"chart.yaml"}
+	duplicate.Metadata.Outcome = types.OutcomeSuccess
+	duplicate.RelatedChunks = []string{"a", "b", "original"}
+	duplicate.Metadata.ExtendedMetadata = map[string]interface{}{
+		types.EMKeyAccessCount:  1,
+		types.EMKeyLastAccessed: "2026-05-03T00:00:00Z",
+		types.EMKeyGitBranch:    "main",
+		types.EMKeyArchivedAt:   "2026-05-03T00:00:00Z",
+	}
+	duplicate.RecordMerge(types.MergeRecord{ChunkID: "older-copy", Similarity: 0.97})
+
+	now := stored.Add(24 * time.Hour)
+	Merge(&canonical, &duplicate, 0.98, now)
+
+	assert.Equal(t, []string{"deploy", "helm"}, canonical.Metadata.Tags)
+	assert.Equal(t, []string{"chart.yaml"}, canonical.Metadata.FilesModified)
+	assert.Equal(t, types.OutcomeSuccess, canonical.Metadata.Outcome)
+	assert.Equal(t, []string{"a", "b"}, canonical.RelatedChunks)
+	assert.Equal(t, 3, canonical.Metadata.ExtendedMetadata[types.EMKeyAccessCount])
+	assert.Equal(t, "2026-05-03T00:00:00Z", canonical.Metadata.ExtendedMetadata[types.EMKeyLastAccessed])
+	assert.Equal(t, "main", canonical.Metadata.ExtendedMetadata[types.EMKeyGitBranch])
+	assert.False(t, canonical.IsArchived(), "a duplicate's own state is not copied")
+
+	history := canonical.MergeHistory()
+	require.Len(t, history, 2)
+	assert.Equal(t, "older-copy", history[0].ChunkID)
+	assert.Equal(t, "copy", history[1].ChunkID)
+	assert.InDelta(t, 0.98, history[1].Similarity, 1e-9)
+	assert.Equal(t, now, history[1].MergedAt)
+	assert.Equal(t, "original", duplicate.MergedInto())
+}
@@ -6,17 +6,29 @@ import (
 	"fmt"
 	"lerian-mcp-memory/internal/analytics"
 	"lerian-mcp-memory/internal/audit"
+	"lerian-mcp-memory/internal/auth"
 	"lerian-mcp-memory/internal/chains"
+	"lerian-mcp-memory/internal/chaos"
 	"lerian-mcp-memory/internal/chunking"
 	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/coordination"
+	"lerian-mcp-memory/internal/decay"
+	"lerian-mcp-memory/internal/digest"
 	"lerian-mcp-memory/internal/embeddings"
 	"lerian-mcp-memory/internal/intelligence"
+	"lerian-mcp-memory/internal/llm"
+	"lerian-mcp-memory/internal/people"
 	"lerian-mcp-memory/internal/persistence"
+	"lerian-mcp-memory/internal/projects"
 	"lerian-mcp-memory/internal/relationships"
+	"lerian-mcp-memory/internal/scoring"
 	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/tags"
 	"lerian-mcp-memory/internal/threading"
+	"lerian-mcp-memory/internal/usage"
 	"lerian-mcp-memory/internal/workflow"
 	"os"
+	"time"
 )
 
 const envValueTrue = "true"
@@ -29,6 +41,7 @@ type Container struct {
 	ChunkingService     *chunking.Service
 	ContextSuggester    *workflow.ContextSuggester
 	BackupManager       *persistence.BackupManager
+	SnapshotManager     *persistence.SnapshotManager
 	LearningEngine      *intelligence.LearningEngine
 	PatternAnalyzer     *workflow.PatternAnalyzer
 	TodoTracker         *workflow.TodoTracker
@@ -39,10 +52,34 @@ type Container struct {
 	ChainBuilder        *chains.ChainBuilder
 	ChainStore          chains.ChainStore
 	RelationshipManager *relationships.Manager
+	RelationTaxonomy    *relationships.Taxonomy
+	ScoringProfiles     *scoring.Store
+	People              *people.Store
+	Projects            *projects.Store
+	Tags                *tags.Store
+	DecayPolicies       *decay.PolicyStore
+	Subscriptions       *digest.SubscriptionStore
+	Coordination        *coordination.Store
 	ThreadManager       *threading.ThreadManager
 	ThreadStore         threading.ThreadStore
 	MemoryAnalytics     *analytics.MemoryAnalytics
 	AuditLogger         *audit.Logger
+	LLM                 *llm.Router
+	FaultInjector       *chaos.Injector                    // nil unless fault injection is enabled
+	APIKeys             *auth.Store                        // nil unless API keys are enabled
+	TenantStore         *storage.TenantIsolatedVectorStore // nil unless tenancy is enforced
+	UsageMeter          *usage.Meter                       // nil unless usage metering is enabled
+
+	// IngestionQueue batches vector store writes; nil unless write batching is enabled
+	IngestionQueue *storage.BatchingVectorStore
+	// ReplicaRouter sends search reads to Qdrant read replicas; nil unless replicas are configured
+	ReplicaRouter *storage.ReplicaRoutingStore
+	// Qdrant is the primary Qdrant store beneath the storage wrappers; nil with the keyword store
+	Qdrant *storage.QdrantStore
+	// ChangeFeed reports chunks written through the vector store
+	ChangeFeed *storage.ChangeFeed
+	// HybridSearch serves keyword and hybrid searches from an inverted index; nil unless the index is enabled
+	HybridSearch *storage.HybridSearchStore
 }
 
 // NewContainer creates a new dependency injection container
@@ -52,28 +89,117 @@ func NewContainer(cfg *config.Config) (*Container, error) {
 	}
 
 	// Initialize in dependency order
+	container.initializeFaultInjection()
 	container.initializeStorage()
+	container.initializeKeywordIndex()
+	container.initializeEmbeddingService()
+	container.initializeUsage()
+	container.initializePassages()
+	container.ChangeFeed = storage.NewChangeFeed(container.VectorStore)
+	container.VectorStore = container.ChangeFeed
+	container.initializeProjects()
+	container.VectorStore = storage.NewProjectGuardedVectorStore(container.VectorStore, container.Projects)
+	// Archived memories stay stored but leave default searches
+	container.VectorStore = storage.NewArchiveTierVectorStore(container.VectorStore)
+	if cfg.Security.Tenancy.Enabled {
+		// Outermost, so every service's storage calls are held to the caller's tenant
+		container.TenantStore = storage.NewTenantIsolatedVectorStore(container.VectorStore)
+		container.VectorStore = container.TenantStore
+	}
 
 	container.initializeServices()
+	if container.TenantStore != nil && container.AuditLogger != nil {
+		container.TenantStore.SetAuditLogger(container.AuditLogger)
+	}
 	container.initializeIntelligence()
 	container.initializeWorkflow()
 
 	return container, nil
 }
 
+// initializeProjects loads the project registry writes are checked against
+func (c *Container) initializeProjects() {
+	projectsPath := os.Getenv("MCP_MEMORY_PROJECTS_PATH")
+	if projectsPath == "" {
+		projectsPath = "./data/projects.json"
+	}
+	c.Projects = projects.NewStore(projectsPath)
+	c.Projects.SetRequireRegistration(os.Getenv("MCP_MEMORY_PROJECTS_REQUIRE_REGISTRATION") == envValueTrue)
+	if err := c.Projects.Load(); err != nil {
+		fmt.Printf("Warning: Failed to load project registry: %v\n", err)
+	}
+}
+
+// initializeFaultInjection creates the chaos injector when fault injection is
+// enabled, seeded with the faults from configuration
+func (c *Container) initializeFaultInjection() {
+	if !c.Config.Chaos.Enabled {
+		return
+	}
+
+	c.FaultInjector = chaos.NewInjector()
+	initial := map[string]chaos.Fault{
+		chaos.TargetVectorStore: {ErrorRate: c.Config.Chaos.VectorStoreErrorRate, LatencyMs: c.Config.Chaos.VectorStoreLatencyMs},
+		chaos.TargetEmbeddings:  {ErrorRate: c.Config.Chaos.EmbeddingsErrorRate, LatencyMs: c.Config.Chaos.EmbeddingsLatencyMs},
+	}
+	for target, fault := range initial {
+		if fault == (chaos.Fault{}) {
+			continue
+		}
+		if err := c.FaultInjector.SetFault(target, fault); err != nil {
+			fmt.Printf("Warning: Failed to configure %s fault: %v\n", target, err)
+		}
+	}
+	fmt.Printf("Warning: Fault injection is enabled; do not use this configuration in production\n")
+}
+
+// withStoreFaults wraps store with the fault injector when enabled
+func (c *Container) withStoreFaults(store storage.VectorStore) storage.VectorStore {
+	if c.FaultInjector == nil {
+		return store
+	}
+	return storage.NewFaultInjectingVectorStore(store, c.FaultInjector)
+}
+
+// withEmbeddingFaults wraps service with the fault injector when enabled
+func (c *Container) withEmbeddingFaults(service embeddings.EmbeddingService) embeddings.EmbeddingService {
+	if c.FaultInjector == nil {
+		return service
+	}
+	return embeddings.NewFaultInjectingEmbeddingService(service, c.FaultInjector)
+}
+
 // initializeStorage sets up storage layer
 func (c *Container) initializeStorage() {
 	var baseStore storage.VectorStore
 
 	// Initialize vector store based on provider
 	switch c.Config.Storage.Provider {
-	case "qdrant":
-		baseStore = storage.NewQdrantStore(&c.Config.Qdrant)
+	case config.StorageProviderQdrant:
+		c.Qdrant = storage.NewQdrantStore(&c.Config.Qdrant)
+		baseStore = c.Qdrant
+	case config.StorageProviderPgVector:
+		baseStore = storage.NewPgVectorStore(&c.Config.PgVector)
+	case config.StorageProviderKeyword:
+		// Keyword store is in-process; retry and circuit breaker wrappers add nothing
+		keywordStore := storage.NewKeywordStore(c.Config.Storage.KeywordPath)
+		keywordStore.SetAnalyzers(c.searchAnalyzers())
+		c.VectorStore = c.withStoreFaults(keywordStore)
+		return
+	case config.StorageProviderLocal:
+		localStore := storage.NewLocalVectorStore(c.Config.Storage.LocalPath)
+		localStore.SetAnalyzers(c.searchAnalyzers())
+		c.VectorStore = c.withStoreFaults(localStore)
+		return
 	default:
 		// Default to Qdrant for new installations
-		baseStore = storage.NewQdrantStore(&c.Config.Qdrant)
+		c.Qdrant = storage.NewQdrantStore(&c.Config.Qdrant)
+		baseStore = c.Qdrant
 	}
 
+	// Inject faults beneath the resilience wrappers so they react to them
+	baseStore = c.withStoreFaults(baseStore)
+
 	// Wrap with retry logic
 	retryStore := storage.NewRetryableVectorStore(baseStore, nil)
 
@@ -83,12 +209,102 @@ func (c *Container) initializeStorage() {
 	} else {
 		c.VectorStore = retryStore
 	}
+
+	// Route search reads to replicas; a failed replica read falls back to the resilient primary
+	c.initializeReadReplicas()
+
+	// Queue writes outermost so batches go through retries and the circuit breaker
+	if c.Config.Storage.Ingestion.Enabled {
+		queue, err := storage.NewBatchingVectorStore(c.VectorStore, &c.Config.Storage.Ingestion)
+		if err != nil {
+			fmt.Printf("Warning: Failed to start ingestion queue, storing chunks one at a time: %v\n", err)
+			return
+		}
+		c.IngestionQueue = queue
+		c.VectorStore = queue
+	}
 }
 
-// initializeServices sets up core services
-func (c *Container) initializeServices() {
-	// Initialize embedding service
-	baseEmbedding := embeddings.NewOpenAIEmbeddingService(&c.Config.OpenAI)
+// initializeReadReplicas wraps the vector store with read replica routing
+// when replicas are configured and reads may use them. Replicas are Qdrant
+// instances, so other storage providers never use them.
+func (c *Container) initializeReadReplicas() {
+	replicaConfig := c.Config.Qdrant.ReadReplicas
+	if c.Qdrant == nil || len(replicaConfig.Hosts) == 0 || replicaConfig.ReadPreference != config.ReadPreferenceReplica {
+		return
+	}
+
+	replicas := make([]storage.ReadReplica, 0, len(replicaConfig.Hosts))
+	for _, address := range replicaConfig.Hosts {
+		host, port, err := config.SplitReplicaHost(address, c.Config.Qdrant.Port)
+		if err != nil {
+			fmt.Printf("Warning: Skipping read replica: %v\n", err)
+			continue
+		}
+		replicaQdrant := c.Config.Qdrant
+		replicaQdrant.Host = host
+		replicaQdrant.Port = port
+		replicas = append(replicas, storage.ReadReplica{Name: address, Store: storage.NewQdrantStore(&replicaQdrant)})
+	}
+	if len(replicas) == 0 {
+		return
+	}
+
+	maxStaleness := time.Duration(replicaConfig.MaxStalenessMs) * time.Millisecond
+	c.ReplicaRouter = storage.NewReplicaRoutingStore(c.VectorStore, replicas, maxStaleness)
+	c.VectorStore = c.ReplicaRouter
+}
+
+// initializeKeywordIndex indexes chunk terms for keyword and hybrid search.
+// It sits beneath passages, so passages are indexed and keyword hits on them
+// fold into their parents. Lite mode ranks every search by keyword already.
+func (c *Container) initializeKeywordIndex() {
+	if !c.Config.Search.KeywordIndex || c.Config.IsLiteMode() || c.Config.Storage.Provider == config.StorageProviderKeyword {
+		return
+	}
+	c.HybridSearch = storage.NewHybridSearchStore(c.VectorStore, c.Config.Search.RRFK)
+	c.HybridSearch.SetAnalyzers(c.searchAnalyzers())
+	c.VectorStore = c.HybridSearch
+}
+
+// searchAnalyzers builds the text analyzers keyword search splits memories
+// and queries with; an invalid configuration falls back to the standard one
+func (c *Container) searchAnalyzers() *storage.Analyzers {
+	analyzers, err := storage.NewAnalyzers(&c.Config.Search)
+	if err != nil {
+		fmt.Printf("Warning: Failed to configure search analyzers, using %s: %v\n", config.AnalyzerStandard, err)
+		return nil
+	}
+	return analyzers
+}
+
+// initializePassages indexes long chunks as passages with vectors of their
+// own. Lite mode has no vectors, and keyword search reads whole chunks anyway.
+func (c *Container) initializePassages() {
+	if c.Config.IsLiteMode() || c.Config.Embedding.PassageRunes <= 0 {
+		return
+	}
+	c.VectorStore = storage.NewPassageStore(c.VectorStore, c.EmbeddingService, c.Config.Embedding.PassageRunes, c.Config.Embedding.PassageOverlap)
+}
+
+// initializeEmbeddingService selects the embedding provider; lite mode uses a disabled service
+func (c *Container) initializeEmbeddingService() {
+	if c.Config.IsLiteMode() {
+		c.EmbeddingService = embeddings.NewDisabledEmbeddingService()
+		return
+	}
+	if c.Config.Embedding.Provider == config.EmbeddingProviderFake {
+		c.EmbeddingService = c.withEmbeddingFaults(embeddings.NewFakeEmbeddingService())
+		return
+	}
+
+	baseEmbedding := c.withEmbeddingFaults(embeddings.NewOpenAIEmbeddingService(&c.Config.OpenAI))
+
+	// Coalesce concurrent requests into batches beneath the retries, so a
+	// failed batch is retried text by text and forms new batches
+	if c.Config.Embedding.BatchSize > 1 {
+		baseEmbedding = embeddings.NewBatchingEmbeddingService(baseEmbedding, &c.Config.Embedding)
+	}
 
 	// Wrap with retry logic
 	retryEmbedding := embeddings.NewRetryableEmbeddingService(baseEmbedding, nil)
@@ -99,9 +315,34 @@ func (c *Container) initializeServices() {
 	} else {
 		c.EmbeddingService = retryEmbedding
 	}
+}
+
+// initializeUsage creates the usage meter when metering is enabled and counts
+// the embeddings generated for each tenant
+func (c *Container) initializeUsage() {
+	if !c.Config.Usage.Enabled {
+		return
+	}
+	c.UsageMeter = usage.NewMeter(c.Config.Usage.Path)
+	if err := c.UsageMeter.Load(); err != nil {
+		fmt.Printf("Warning: Failed to load usage: %v\n", err)
+	}
+	if embeddings.IsEnabled(c.EmbeddingService) {
+		c.EmbeddingService = embeddings.NewMeteringEmbeddingService(c.EmbeddingService, c.UsageMeter)
+	}
+}
+
+// initializeServices sets up core services
+func (c *Container) initializeServices() {
+	// Initialize per-feature LLM providers
+	c.initializeLLM()
 
 	// Initialize chunking service
 	c.ChunkingService = chunking.NewService(&c.Config.Chunking, c.EmbeddingService)
+	c.ChunkingService.SetSummarizer(c.LLM.For(llm.FeatureSummarization))
+	if provider := c.LLM.For(llm.FeatureSummarization); provider != nil && c.Config.LLM.EnrichmentEnabled {
+		c.ChunkingService.SetEnhancer(intelligence.NewLLMMemoryEnhancer(provider))
+	}
 
 	// Initialize backup manager
 	backupDir := os.Getenv("MCP_MEMORY_BACKUP_DIRECTORY")
@@ -110,9 +351,97 @@ func (c *Container) initializeServices() {
 	}
 	c.BackupManager = persistence.NewBackupManager(c.VectorStore, backupDir)
 
+	// Initialize point-in-time snapshots, drained through the ingestion queue when batching
+	snapshotDir := os.Getenv("MCP_MEMORY_SNAPSHOT_DIRECTORY")
+	if snapshotDir == "" {
+		snapshotDir = "./snapshots"
+	}
+	c.SnapshotManager = persistence.NewSnapshotManager(c.VectorStore, snapshotDir)
+	if c.IngestionQueue != nil {
+		c.SnapshotManager.SetWriteQueue(c.IngestionQueue)
+	}
+
 	// Initialize relationship manager
 	c.RelationshipManager = relationships.NewManager()
 
+	// Initialize relation type taxonomy
+	taxonomyPath := os.Getenv("MCP_MEMORY_RELATION_TAXONOMY_PATH")
+	if taxonomyPath == "" {
+		taxonomyPath = "./data/relation_taxonomy.json"
+	}
+	c.RelationTaxonomy = relationships.NewTaxonomy(taxonomyPath)
+	if err := c.RelationTaxonomy.Load(); err != nil {
+		fmt.Printf("Warning: Failed to load relation taxonomy: %v\n", err)
+	}
+
+	// Initialize per-repository search scoring profiles
+	scoringPath := os.Getenv("MCP_MEMORY_SCORING_PROFILES_PATH")
+	if scoringPath == "" {
+		scoringPath = "./data/scoring_profiles.json"
+	}
+	c.ScoringProfiles = scoring.NewStore(scoringPath)
+	if err := c.ScoringProfiles.Load(); err != nil {
+		fmt.Printf("Warning: Failed to load scoring profiles: %v\n", err)
+	}
+
+	// Initialize the directory of people behind memories
+	peoplePath := os.Getenv("MCP_MEMORY_PEOPLE_PATH")
+	if peoplePath == "" {
+		peoplePath = "./data/people.json"
+	}
+	c.People = people.NewStore(peoplePath)
+	if err := c.People.Load(); err != nil {
+		fmt.Printf("Warning: Failed to load people directory: %v\n", err)
+	}
+
+	// Initialize the registry of tags and their former names
+	tagsPath := os.Getenv("MCP_MEMORY_TAGS_PATH")
+	if tagsPath == "" {
+		tagsPath = "./data/tags.json"
+	}
+	c.Tags = tags.NewStore(tagsPath)
+	if err := c.Tags.Load(); err != nil {
+		fmt.Printf("Warning: Failed to load tag registry: %v\n", err)
+	}
+
+	// Initialize the per-repository decay policies
+	decayPoliciesPath := os.Getenv("MCP_MEMORY_DECAY_POLICIES_PATH")
+	if decayPoliciesPath == "" {
+		decayPoliciesPath = "./data/decay_policies.json"
+	}
+	c.DecayPolicies = decay.NewPolicyStore(decayPoliciesPath)
+	if err := c.DecayPolicies.Load(); err != nil {
+		fmt.Printf("Warning: Failed to load decay policies: %v\n", err)
+	}
+
+	// Initialize per-person notification subscriptions
+	subscriptionsPath := os.Getenv("MCP_MEMORY_SUBSCRIPTIONS_PATH")
+	if subscriptionsPath == "" {
+		subscriptionsPath = "./data/notification_subscriptions.json"
+	}
+	c.Subscriptions = digest.NewSubscriptionStore(subscriptionsPath)
+	if err := c.Subscriptions.Load(); err != nil {
+		fmt.Printf("Warning: Failed to load notification subscriptions: %v\n", err)
+	}
+
+	// Initialize multi-agent coordination state
+	coordinationPath := os.Getenv("MCP_MEMORY_COORDINATION_PATH")
+	if coordinationPath == "" {
+		coordinationPath = "./data/coordination.json"
+	}
+	c.Coordination = coordination.NewStore(coordinationPath)
+	if err := c.Coordination.Load(); err != nil {
+		fmt.Printf("Warning: Failed to load coordination state: %v\n", err)
+	}
+
+	// Initialize API keys when clients authenticate with them
+	if c.Config.Security.APIKeys.Enabled {
+		c.APIKeys = auth.NewStore(c.Config.Security.APIKeys.Path)
+		if err := c.APIKeys.Load(); err != nil {
+			fmt.Printf("Warning: Failed to load API keys: %v\n", err)
+		}
+	}
+
 	// Initialize chain components
 	c.ChainStore = chains.NewInMemoryChainStore()
 	chainAnalyzer := chains.NewDefaultChainAnalyzer(c.EmbeddingService)
@@ -138,10 +467,28 @@ func (c *Container) initializeServices() {
 	}
 }
 
+// initializeLLM creates the LLM providers selected for intelligence features.
+// Features fall back to rule-based behavior when providers cannot be created.
+func (c *Container) initializeLLM() {
+	router, err := llm.NewRouter(c.Config)
+	if err != nil {
+		fmt.Printf("Warning: Failed to initialize LLM providers: %v\n", err)
+		router, _ = llm.NewRouter(config.DefaultConfig())
+	}
+	c.LLM = router
+}
+
 // initializeIntelligence sets up intelligence layer
 func (c *Container) initializeIntelligence() {
-	// Initialize pattern engine with adapter
-	patternStorage := storage.NewPatternStorageAdapter(c.VectorStore)
+	// Initialize pattern engine with the detected patterns persisted to disk
+	patternsPath := os.Getenv("MCP_MEMORY_PATTERNS_PATH")
+	if patternsPath == "" {
+		patternsPath = "./data/patterns.json"
+	}
+	patternStorage := intelligence.NewFilePatternStore(patternsPath)
+	if err := patternStorage.Load(); err != nil {
+		fmt.Printf("Warning: Failed to load patterns: %v\n", err)
+	}
 	c.PatternEngine = intelligence.NewPatternEngine(patternStorage)
 
 	// Initialize graph builder
@@ -198,6 +545,12 @@ func (c *Container) Shutdown() error {
 		c.AuditLogger.Stop()
 	}
 
+	if c.UsageMeter != nil {
+		if err := c.UsageMeter.Flush(); err != nil {
+			fmt.Printf("Warning: Failed to save usage: %v\n", err)
+		}
+	}
+
 	if c.VectorStore != nil {
 		if err := c.VectorStore.Close(); err != nil {
 			return fmt.Errorf("failed to close vector store: %w", err)
@@ -213,6 +566,16 @@ func (c *Container) GetVectorStore() storage.VectorStore {
 	return c.VectorStore
 }
 
+// GetIngestionQueue returns the write batching queue, or nil when batching is disabled
+func (c *Container) GetIngestionQueue() *storage.BatchingVectorStore {
+	return c.IngestionQueue
+}
+
+// GetReplicaRouter returns the read replica router, or nil when reads all go to the primary
+func (c *Container) GetReplicaRouter() *storage.ReplicaRoutingStore {
+	return c.ReplicaRouter
+}
+
 // GetEmbeddingService returns the embedding service instance
 func (c *Container) GetEmbeddingService() embeddings.EmbeddingService {
 	return c.EmbeddingService
@@ -233,11 +596,81 @@ func (c *Container) GetBackupManager() *persistence.BackupManager {
 	return c.BackupManager
 }
 
+// GetSnapshotManager returns the snapshot manager instance
+func (c *Container) GetSnapshotManager() *persistence.SnapshotManager {
+	return c.SnapshotManager
+}
+
 // GetRelationshipManager returns the relationship manager instance
 func (c *Container) GetRelationshipManager() *relationships.Manager {
 	return c.RelationshipManager
 }
 
+// GetRelationTaxonomy returns the relation type taxonomy instance
+func (c *Container) GetRelationTaxonomy() *relationships.Taxonomy {
+	return c.RelationTaxonomy
+}
+
+// GetChangeFeed returns the feed of chunk writes, or nil when the vector store is not watched
+func (c *Container) GetChangeFeed() *storage.ChangeFeed {
+	return c.ChangeFeed
+}
+
+// GetQdrant returns the primary Qdrant store, or nil with the keyword store
+func (c *Container) GetQdrant() *storage.QdrantStore {
+	return c.Qdrant
+}
+
+// GetScoringProfiles returns the search scoring profile store
+func (c *Container) GetScoringProfiles() *scoring.Store {
+	return c.ScoringProfiles
+}
+
+// GetPeople returns the directory of people behind memories
+func (c *Container) GetPeople() *people.Store {
+	return c.People
+}
+
+// GetProjects returns the project registry
+func (c *Container) GetProjects() *projects.Store {
+	return c.Projects
+}
+
+// GetTags returns the tag registry
+func (c *Container) GetTags() *tags.Store {
+	return c.Tags
+}
+
+// GetDecayPolicies returns the per-repository decay policies
+func (c *Container) GetDecayPolicies() *decay.PolicyStore {
+	return c.DecayPolicies
+}
+
+// GetSubscriptions returns the store of notification subscriptions
+func (c *Container) GetSubscriptions() *digest.SubscriptionStore {
+	return c.Subscriptions
+}
+
+// GetUsageMeter returns the usage meter, or nil when metering is disabled
+func (c *Container) GetUsageMeter() *usage.Meter {
+	return c.UsageMeter
+}
+
+// GetCoordination returns the store of agent locks, task claims and scratchpads
+func (c *Container) GetCoordination() *coordination.Store {
+	return c.Coordination
+}
+
+// GetAPIKeys returns the API key store, or nil when API keys are disabled
+func (c *Container) GetAPIKeys() *auth.Store {
+	return c.APIKeys
+}
+
+// GetPatternEngine returns the pattern engine instance
+func (c *Container) GetPatternEngine() *intelligence.PatternEngine {
+	return c.PatternEngine
+}
+
 // GetLearningEngine returns the learning engine instance
 func (c *Container) GetLearningEngine() *intelligence.LearningEngine {
 	return c.LearningEngine
@@ -278,6 +711,16 @@ func (c *Container) GetThreadStore() threading.ThreadStore {
 	return c.ThreadStore
 }
 
+// GetLLMRouter returns the per-feature LLM provider router
+func (c *Container) GetLLMRouter() *llm.Router {
+	return c.LLM
+}
+
+// GetFaultInjector returns the chaos injector, or nil when fault injection is disabled
+func (c *Container) GetFaultInjector() *chaos.Injector {
+	return c.FaultInjector
+}
+
 // GetMultiRepoEngine returns the multi-repository engine instance
 func (c *Container) GetMultiRepoEngine() *intelligence.MultiRepoEngine {
 	return c.MultiRepoEngine
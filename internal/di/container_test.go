@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/embeddings"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -347,6 +348,18 @@ func TestContainerConfigVariations(t *testing.T) {
 	}
 }
 
+func TestContainerFakeEmbeddingProvider(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Embedding.Provider = config.EmbeddingProviderFake
+
+	container, err := NewContainer(cfg)
+	require.NoError(t, err)
+	defer func() { _ = container.Shutdown() }()
+
+	_, ok := container.GetEmbeddingService().(*embeddings.FakeEmbeddingService)
+	assert.True(t, ok)
+}
+
 func TestEnvironmentVariableHandling(t *testing.T) {
 	tests := []struct {
 		name          string
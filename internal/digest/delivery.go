@@ -0,0 +1,210 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TargetType identifies a delivery channel
+type TargetType string
+
+const (
+	// TargetWebhook posts the digest as JSON to an HTTP endpoint
+	TargetWebhook TargetType = "webhook"
+	// TargetSlack posts the Markdown digest to a Slack incoming webhook
+	TargetSlack TargetType = "slack"
+	// TargetEmail sends the digest by email through the configured SMTP server
+	TargetEmail TargetType = "email"
+)
+
+// Target describes where a digest is delivered
+type Target struct {
+	Type TargetType `json:"type" yaml:"type"`
+	URL  string     `json:"url,omitempty" yaml:"url,omitempty"`
+	To   []string   `json:"to,omitempty" yaml:"to,omitempty"`
+}
+
+// Validate checks that the target has the fields its channel needs
+func (t Target) Validate() error {
+	switch t.Type {
+	case TargetWebhook, TargetSlack:
+		if t.URL == "" {
+			return fmt.Errorf("%s target requires url", t.Type)
+		}
+	case TargetEmail:
+		if len(t.To) == 0 {
+			return errors.New("email target requires at least one recipient in to")
+		}
+		for _, to := range t.To {
+			if strings.ContainsAny(to, "\r\n") {
+				return fmt.Errorf("email recipient %q contains a line break", to)
+			}
+			if _, err := mail.ParseAddress(to); err != nil {
+				return fmt.Errorf("invalid email recipient %q: %w", to, err)
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported target type: %s (valid: webhook, slack, email)", t.Type)
+	}
+	return nil
+}
+
+// Message is a rendered digest, or a list of subscribed events, ready for delivery
+type Message struct {
+	Digest  *Digest
+	Events  []Event
+	Format  Format
+	Body    string
+	Subject string
+}
+
+// Deliverer sends a rendered digest to a single target
+type Deliverer interface {
+	Deliver(ctx context.Context, target Target, msg *Message) error
+}
+
+// SMTPConfig holds the outgoing mail server settings for email delivery
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Dispatcher routes messages to the deliverer for each target type
+type Dispatcher struct {
+	client *http.Client
+	smtp   SMTPConfig
+}
+
+// NewDispatcher creates a dispatcher for webhook, Slack, and email delivery
+func NewDispatcher(smtpConfig SMTPConfig) *Dispatcher {
+	return &Dispatcher{
+		client: &http.Client{Timeout: 30 * time.Second},
+		smtp:   smtpConfig,
+	}
+}
+
+// Deliver sends the message to the target using the matching channel
+func (d *Dispatcher) Deliver(ctx context.Context, target Target, msg *Message) error {
+	if err := target.Validate(); err != nil {
+		return err
+	}
+
+	switch target.Type {
+	case TargetWebhook:
+		payload := map[string]interface{}{
+			"subject": msg.Subject,
+			"format":  msg.Format,
+			"body":    msg.Body,
+		}
+		if msg.Digest != nil {
+			payload["digest"] = msg.Digest
+		}
+		if len(msg.Events) > 0 {
+			payload["events"] = msg.Events
+		}
+		return d.postJSON(ctx, target.URL, payload)
+	case TargetSlack:
+		// Slack incoming webhooks only accept mrkdwn text, so always send the Markdown rendering
+		text := msg.Body
+		if msg.Format != FormatMarkdown && msg.Digest != nil {
+			rendered, err := Render(msg.Digest, FormatMarkdown)
+			if err != nil {
+				return err
+			}
+			text = rendered
+		}
+		return d.postJSON(ctx, target.URL, map[string]string{"text": text})
+	case TargetEmail:
+		return d.sendEmail(target.To, msg)
+	default:
+		return fmt.Errorf("unsupported target type: %s", target.Type)
+	}
+}
+
+// emailMessage builds the email carrying msg. Line breaks in header values
+// are replaced by spaces, so a subject built from project names or event
+// text cannot add headers of its own.
+func emailMessage(from string, to []string, msg *Message) []byte {
+	contentType := "text/markdown; charset=UTF-8"
+	switch msg.Format {
+	case FormatHTML:
+		contentType = "text/html; charset=UTF-8"
+	case FormatCSV:
+		contentType = "text/csv; charset=UTF-8"
+	case FormatJSON:
+		contentType = "application/json; charset=UTF-8"
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("From: " + headerValue(from) + "\r\n")
+	buf.WriteString("To: " + headerValue(strings.Join(to, ", ")) + "\r\n")
+	buf.WriteString("Subject: " + headerValue(msg.Subject) + "\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: " + contentType + "\r\n\r\n")
+	buf.WriteString(msg.Body)
+	return buf.Bytes()
+}
+
+// headerLineBreaks replaces the line breaks of header values
+var headerLineBreaks = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ")
+
+// headerValue returns value fit for a single header line
+func headerValue(value string) string {
+	return headerLineBreaks.Replace(value)
+}
+
+// postJSON posts a JSON payload and fails on non-2xx responses
+func (d *Dispatcher) postJSON(ctx context.Context, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode digest payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create delivery request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("digest delivery failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("digest delivery failed with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// sendEmail delivers the message through the configured SMTP server
+func (d *Dispatcher) sendEmail(to []string, msg *Message) error {
+	if d.smtp.Host == "" || d.smtp.From == "" {
+		return errors.New("email delivery requires SMTP host and from address to be configured")
+	}
+
+	var auth smtp.Auth
+	if d.smtp.Username != "" {
+		auth = smtp.PlainAuth("", d.smtp.Username, d.smtp.Password, d.smtp.Host)
+	}
+
+	addr := net.JoinHostPort(d.smtp.Host, strconv.Itoa(d.smtp.Port))
+	if err := smtp.SendMail(addr, auth, d.smtp.From, to, emailMessage(d.smtp.From, to, msg)); err != nil {
+		return fmt.Errorf("failed to send digest email: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+// Package digest builds periodic project summaries from stored memories and
+// delivers them to external channels on a per-project schedule.
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"lerian-mcp-memory/internal/intelligence"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+)
+
+// Period represents how often a digest is produced and the window it covers
+type Period string
+
+const (
+	// PeriodDaily covers the last 24 hours
+	PeriodDaily Period = "daily"
+	// PeriodWeekly covers the last 7 days
+	PeriodWeekly Period = "weekly"
+)
+
+// Valid checks if the period is supported
+func (p Period) Valid() bool {
+	return p == PeriodDaily || p == PeriodWeekly
+}
+
+// Duration returns the length of the window covered by the period
+func (p Period) Duration() time.Duration {
+	if p == PeriodWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+const (
+	// defaultStaleTaskAge is how long an open task may go untouched before it is reported
+	defaultStaleTaskAge = 7 * 24 * time.Hour
+
+	// maxDigestChunks caps the number of chunks loaded for a single digest
+	maxDigestChunks = 1000
+)
+
+// Item is a single entry in a digest section
+type Item struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Timestamp time.Time `json:"timestamp"`
+	Status    string    `json:"status,omitempty"`
+}
+
+// ConflictItem is an unresolved conflict between two memories
+type ConflictItem struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+}
+
+// Digest is a summary of project activity over a period
+type Digest struct {
+	Project          string         `json:"project"`
+	Period           Period         `json:"period"`
+	Start            time.Time      `json:"start"`
+	End              time.Time      `json:"end"`
+	NewDecisions     []Item         `json:"new_decisions"`
+	ResolvedProblems []Item         `json:"resolved_problems"`
+	OpenConflicts    []ConflictItem `json:"open_conflicts"`
+	StaleTasks       []Item         `json:"stale_tasks"`
+	GeneratedAt      time.Time      `json:"generated_at"`
+}
+
+// IsEmpty reports whether the digest has nothing to report
+func (d *Digest) IsEmpty() bool {
+	return len(d.NewDecisions) == 0 && len(d.ResolvedProblems) == 0 &&
+		len(d.OpenConflicts) == 0 && len(d.StaleTasks) == 0
+}
+
+// Generator builds digests from the vector store
+type Generator struct {
+	store        storage.VectorStore
+	detector     *intelligence.ConflictDetector
+	staleTaskAge time.Duration
+}
+
+// NewGenerator creates a new digest generator
+func NewGenerator(store storage.VectorStore) *Generator {
+	return &Generator{
+		store:        store,
+		detector:     intelligence.NewConflictDetector(),
+		staleTaskAge: defaultStaleTaskAge,
+	}
+}
+
+// SetStaleTaskAge sets how long an open task may go untouched before it is reported as stale
+func (g *Generator) SetStaleTaskAge(age time.Duration) {
+	if age > 0 {
+		g.staleTaskAge = age
+	}
+}
+
+// Generate produces the digest for a project covering the period ending at now
+func (g *Generator) Generate(ctx context.Context, project string, period Period, now time.Time) (*Digest, error) {
+	if project == "" {
+		return nil, errors.New("project is required")
+	}
+	if !period.Valid() {
+		return nil, fmt.Errorf("invalid digest period: %s (valid: %s, %s)", period, PeriodDaily, PeriodWeekly)
+	}
+
+	chunks, err := g.store.ListByRepository(ctx, project, maxDigestChunks, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project memories: %w", err)
+	}
+
+	digest := &Digest{
+		Project:          project,
+		Period:           period,
+		Start:            now.Add(-period.Duration()),
+		End:              now,
+		NewDecisions:     []Item{},
+		ResolvedProblems: []Item{},
+		OpenConflicts:    []ConflictItem{},
+		StaleTasks:       []Item{},
+		GeneratedAt:      time.Now(),
+	}
+
+	// Trashed memories are left out of every section
+	live := chunks[:0]
+	for i := range chunks {
+		if !chunks[i].IsDeleted() {
+			live = append(live, chunks[i])
+		}
+	}
+	chunks = live
+
+	for i := range chunks {
+		g.classifyChunk(digest, &chunks[i], now)
+	}
+
+	conflicts, err := g.openConflicts(ctx, chunks)
+	if err != nil {
+		return nil, err
+	}
+	digest.OpenConflicts = conflicts
+
+	sortItems(digest.NewDecisions)
+	sortItems(digest.ResolvedProblems)
+	sortItems(digest.StaleTasks)
+
+	return digest, nil
+}
+
+// classifyChunk places a chunk into the digest sections it belongs to
+func (g *Generator) classifyChunk(digest *Digest, chunk *types.ConversationChunk, now time.Time) {
+	inWindow := !chunk.Timestamp.Before(digest.Start) && !chunk.Timestamp.After(digest.End)
+
+	switch chunk.Type {
+	case types.ChunkTypeArchitectureDecision:
+		if inWindow {
+			digest.NewDecisions = append(digest.NewDecisions, newItem(chunk))
+		}
+	case types.ChunkTypeProblem, types.ChunkTypeSolution:
+		if inWindow && chunk.Metadata.Outcome == types.OutcomeSuccess {
+			digest.ResolvedProblems = append(digest.ResolvedProblems, newItem(chunk))
+		}
+	case types.ChunkTypeTask:
+		if isOpenTask(chunk) && now.Sub(chunk.Timestamp) >= g.staleTaskAge {
+			digest.StaleTasks = append(digest.StaleTasks, newItem(chunk))
+		}
+	}
+}
+
+// openConflicts runs conflict detection over the project and keeps unresolved conflicts
+func (g *Generator) openConflicts(ctx context.Context, chunks []types.ConversationChunk) ([]ConflictItem, error) {
+	items := []ConflictItem{}
+	if len(chunks) < 2 {
+		return items, nil
+	}
+
+	result, err := g.detector.DetectConflicts(ctx, chunks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect conflicts: %w", err)
+	}
+
+	for i := range result.Conflicts {
+		conflict := &result.Conflicts[i]
+		if conflict.Resolved {
+			continue
+		}
+		items = append(items, ConflictItem{
+			ID:          conflict.ID,
+			Title:       conflict.Title,
+			Severity:    string(conflict.Severity),
+			Description: conflict.Description,
+		})
+	}
+
+	return items, nil
+}
+
+// isOpenTask checks whether a task chunk still needs work
+func isOpenTask(chunk *types.ConversationChunk) bool {
+	if chunk.Metadata.TaskStatus == nil {
+		return true
+	}
+
+	switch *chunk.Metadata.TaskStatus {
+	case types.TaskStatusCompleted, types.TaskStatusCancelled:
+		return false
+	default:
+		return true
+	}
+}
+
+// newItem converts a chunk into a digest item
+func newItem(chunk *types.ConversationChunk) Item {
+	item := Item{
+		ID:        chunk.ID,
+		Title:     chunkTitle(chunk),
+		Timestamp: chunk.Timestamp,
+	}
+	if chunk.Metadata.TaskStatus != nil {
+		item.Status = string(*chunk.Metadata.TaskStatus)
+	}
+	return item
+}
+
+// chunkTitle returns a short one-line title for a chunk
+func chunkTitle(chunk *types.ConversationChunk) string {
+	title := chunk.Summary
+	if title == "" {
+		title = chunk.Content
+	}
+
+	for i, r := range title {
+		if r == '\n' {
+			title = title[:i]
+			break
+		}
+	}
+
+	const maxTitleLength = 120
+	if len(title) > maxTitleLength {
+		title = title[:maxTitleLength] + "..."
+	}
+	return title
+}
+
+// sortItems orders items newest first
+func sortItems(items []Item) {
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Timestamp.After(items[j].Timestamp)
+	})
+}
@@ -0,0 +1,425 @@
+package digest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/tenancy"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func storeChunk(t *testing.T, store storage.VectorStore, chunkType types.ChunkType, content string, outcome types.Outcome, ts time.Time, status *types.TaskStatus) {
+	t.Helper()
+	chunk, err := types.NewConversationChunk("session-1", content, chunkType, &types.ChunkMetadata{
+		Repository: "github.com/acme/api",
+		Outcome:    outcome,
+		Difficulty: types.DifficultySimple,
+		TaskStatus: status,
+	})
+	require.NoError(t, err)
+	chunk.Timestamp = ts
+	chunk.Embeddings = []float64{0.1, 0.2}
+	require.NoError(t, store.Store(context.Background(), chunk))
+}
+
+func TestGenerate(t *testing.T) {
+	now := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	store := storage.NewSimpleMockVectorStore()
+	todo := types.TaskStatusTodo
+	done := types.TaskStatusCompleted
+
+	storeChunk(t, store, types.ChunkTypeArchitectureDecision, "Use Qdrant for vectors", types.OutcomeSuccess, now.Add(-2*time.Hour), nil)
+	storeChunk(t, store, types.ChunkTypeArchitectureDecision, "Old decision", types.OutcomeSuccess, now.Add(-72*time.Hour), nil)
+	storeChunk(t, store, types.ChunkTypeSolution, "Fixed login timeout", types.OutcomeSuccess, now.Add(-time.Hour), nil)
+	storeChunk(t, store, types.ChunkTypeProblem, "Flaky CI still failing", types.OutcomeFailed, now.Add(-time.Hour), nil)
+	storeChunk(t, store, types.ChunkTypeTask, "TASK: migrate schema", types.OutcomeInProgress, now.Add(-10*24*time.Hour), &todo)
+	storeChunk(t, store, types.ChunkTypeTask, "TASK: finished work", types.OutcomeSuccess, now.Add(-10*24*time.Hour), &done)
+	storeChunk(t, store, types.ChunkTypeTask, "TASK: fresh task", types.OutcomeInProgress, now.Add(-24*time.Hour), &todo)
+	trashed, err := types.NewConversationChunk("session-1", "Trashed decision", types.ChunkTypeArchitectureDecision, &types.ChunkMetadata{
+		Repository: "github.com/acme/api",
+		Outcome:    types.OutcomeSuccess,
+		Difficulty: types.DifficultySimple,
+		DeletedAt:  &now,
+	})
+	require.NoError(t, err)
+	trashed.Timestamp = now.Add(-time.Hour)
+	trashed.Embeddings = []float64{0.1, 0.2}
+	require.NoError(t, store.Store(context.Background(), trashed))
+
+	generator := NewGenerator(store)
+
+	daily, err := generator.Generate(context.Background(), "github.com/acme/api", PeriodDaily, now)
+	require.NoError(t, err)
+	require.Len(t, daily.NewDecisions, 1)
+	assert.Equal(t, "Use Qdrant for vectors", daily.NewDecisions[0].Title)
+	require.Len(t, daily.ResolvedProblems, 1)
+	assert.Equal(t, "Fixed login timeout", daily.ResolvedProblems[0].Title)
+	require.Len(t, daily.StaleTasks, 1)
+	assert.Equal(t, "TASK: migrate schema", daily.StaleTasks[0].Title)
+	assert.Equal(t, string(types.TaskStatusTodo), daily.StaleTasks[0].Status)
+
+	weekly, err := generator.Generate(context.Background(), "github.com/acme/api", PeriodWeekly, now)
+	require.NoError(t, err)
+	assert.Len(t, weekly.NewDecisions, 2)
+
+	_, err = generator.Generate(context.Background(), "github.com/acme/api", Period("hourly"), now)
+	assert.Error(t, err)
+	_, err = generator.Generate(context.Background(), "", PeriodDaily, now)
+	assert.Error(t, err)
+}
+
+func TestRender(t *testing.T) {
+	d := &Digest{
+		Project:      "github.com/acme/api",
+		Period:       PeriodWeekly,
+		Start:        time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC),
+		End:          time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC),
+		NewDecisions: []Item{{ID: "1", Title: "Adopt <gRPC>", Timestamp: time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)}},
+		OpenConflicts: []ConflictItem{
+			{ID: "c1", Title: "REST vs gRPC", Severity: "high"},
+		},
+	}
+
+	markdown, err := Render(d, FormatMarkdown)
+	require.NoError(t, err)
+	assert.Contains(t, markdown, "# Weekly digest: github.com/acme/api")
+	assert.Contains(t, markdown, "- Adopt <gRPC> (2025-03-05)")
+	assert.Contains(t, markdown, "**[high]** REST vs gRPC")
+	assert.Contains(t, markdown, "## Stale tasks (0)\n_None_")
+
+	html, err := Render(d, FormatHTML)
+	require.NoError(t, err)
+	assert.Contains(t, html, "<h1>Weekly digest: github.com/acme/api</h1>")
+	assert.Contains(t, html, "Adopt &lt;gRPC&gt;")
+
+	_, err = Render(d, Format("pdf"))
+	assert.Error(t, err)
+}
+
+func TestScheduleNextRun(t *testing.T) {
+	// Wednesday 2025-03-05 10:30 UTC
+	now := time.Date(2025, 3, 5, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		expected time.Time
+	}{
+		{"daily later today", Schedule{Period: PeriodDaily, Hour: 18}, time.Date(2025, 3, 5, 18, 0, 0, 0, time.UTC)},
+		{"daily already passed", Schedule{Period: PeriodDaily, Hour: 9}, time.Date(2025, 3, 6, 9, 0, 0, 0, time.UTC)},
+		{"weekly defaults to monday", Schedule{Period: PeriodWeekly, Hour: 9}, time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)},
+		{"weekly friday", Schedule{Period: PeriodWeekly, Hour: 9, Weekday: "Friday"}, time.Date(2025, 3, 7, 9, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.schedule.NextRun(now))
+		})
+	}
+}
+
+func TestScheduleValidate(t *testing.T) {
+	valid := Schedule{Project: "p", Period: PeriodDaily, Targets: []Target{{Type: TargetWebhook, URL: "http://example.com"}}}
+	assert.NoError(t, valid.Validate())
+
+	noTargets := valid
+	noTargets.Targets = nil
+	assert.Error(t, noTargets.Validate())
+
+	badHour := valid
+	badHour.Hour = 24
+	assert.Error(t, badHour.Validate())
+
+	badEmail := valid
+	badEmail.Targets = []Target{{Type: TargetEmail}}
+	assert.Error(t, badEmail.Validate())
+
+	badWeekday := valid
+	badWeekday.Weekday = "someday"
+	assert.Error(t, badWeekday.Validate())
+
+	for _, to := range []string{"team@example.com\r\nBcc: leak@example.com", "not an address"} {
+		injected := valid
+		injected.Targets = []Target{{Type: TargetEmail, To: []string{to}}}
+		assert.Error(t, injected.Validate(), to)
+	}
+}
+
+func TestEmailMessageKeepsHeadersOnOneLine(t *testing.T) {
+	email := string(emailMessage("digest@example.com", []string{"team@example.com"}, &Message{
+		Subject: "Digest for acme\r\nBcc: leak@example.com",
+		Body:    "body",
+		Format:  FormatMarkdown,
+	}))
+	assert.Contains(t, email, "Subject: Digest for acme Bcc: leak@example.com\r\n")
+	assert.NotContains(t, email, "\r\nBcc:")
+	assert.True(t, strings.HasSuffix(email, "\r\n\r\nbody"))
+}
+
+func TestDispatcherWebhookAndSlack(t *testing.T) {
+	var mu sync.Mutex
+	received := map[string]map[string]interface{}{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		received[r.URL.Path] = payload
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	d := &Digest{Project: "p", Period: PeriodDaily}
+	body, err := Render(d, FormatHTML)
+	require.NoError(t, err)
+	msg := &Message{Digest: d, Format: FormatHTML, Body: body, Subject: "daily digest"}
+
+	dispatcher := NewDispatcher(SMTPConfig{})
+	require.NoError(t, dispatcher.Deliver(context.Background(), Target{Type: TargetWebhook, URL: server.URL + "/hook"}, msg))
+	require.NoError(t, dispatcher.Deliver(context.Background(), Target{Type: TargetSlack, URL: server.URL + "/slack"}, msg))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, "daily digest", received["/hook"]["subject"])
+	assert.NotNil(t, received["/hook"]["digest"])
+	// Slack always receives Markdown regardless of the schedule format
+	assert.True(t, strings.HasPrefix(received["/slack"]["text"].(string), "# Daily digest: p"))
+
+	err = dispatcher.Deliver(context.Background(), Target{Type: TargetEmail, To: []string{"a@example.com"}}, msg)
+	assert.Error(t, err, "email without SMTP configuration must fail")
+}
+
+type recordingDeliverer struct {
+	mu       sync.Mutex
+	messages []*Message
+}
+
+func (r *recordingDeliverer) Deliver(_ context.Context, _ Target, msg *Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, msg)
+	return nil
+}
+
+func TestSchedulerRunDue(t *testing.T) {
+	store := storage.NewSimpleMockVectorStore()
+	deliverer := &recordingDeliverer{}
+	scheduler := NewScheduler(NewGenerator(store), deliverer)
+
+	start := time.Date(2025, 3, 5, 8, 0, 0, 0, time.UTC)
+	require.NoError(t, scheduler.AddSchedule(Schedule{
+		Project: "github.com/acme/api",
+		Period:  PeriodDaily,
+		Hour:    9,
+		Targets: []Target{{Type: TargetWebhook, URL: "http://example.com"}},
+	}, start))
+	require.NoError(t, scheduler.AddSchedule(Schedule{
+		Project:   "github.com/acme/quiet",
+		Period:    PeriodDaily,
+		Hour:      9,
+		SkipEmpty: true,
+		Targets:   []Target{{Type: TargetWebhook, URL: "http://example.com"}},
+	}, start))
+
+	assert.Equal(t, 0, scheduler.RunDue(context.Background(), start.Add(30*time.Minute)))
+	assert.Equal(t, 2, scheduler.RunDue(context.Background(), start.Add(time.Hour)))
+	// Already delivered today; next run is tomorrow
+	assert.Equal(t, 0, scheduler.RunDue(context.Background(), start.Add(2*time.Hour)))
+
+	require.Len(t, deliverer.messages, 1, "empty digest with skip_empty must not be delivered")
+	assert.Equal(t, "github.com/acme/api", deliverer.messages[0].Digest.Project)
+	assert.Equal(t, FormatMarkdown, deliverer.messages[0].Format)
+
+	assert.True(t, scheduler.RemoveSchedule("github.com/acme/api", PeriodDaily))
+	assert.Len(t, scheduler.Schedules(), 1)
+}
+
+func TestSchedulerDeliversOnBehalfOfTheTenant(t *testing.T) {
+	store := storage.NewTenantIsolatedVectorStore(storage.NewSimpleMockVectorStore())
+	scheduler := NewScheduler(NewGenerator(store), &recordingDeliverer{})
+	now := time.Date(2025, 3, 5, 9, 0, 0, 0, time.UTC)
+
+	owned := Schedule{
+		Project: "github.com/acme/api",
+		Period:  PeriodDaily,
+		Targets: []Target{{Type: TargetWebhook, URL: "http://example.com"}},
+		Tenant:  &tenancy.Tenant{ID: "api_key:k1", Projects: []string{"github.com/acme/api"}},
+	}
+	require.NoError(t, scheduler.Deliver(context.Background(), &owned, now))
+
+	foreign := owned
+	foreign.Tenant = &tenancy.Tenant{ID: "api_key:k2", Projects: []string{"github.com/rival/app"}}
+	assert.ErrorIs(t, scheduler.Deliver(context.Background(), &foreign, now), tenancy.ErrCrossTenant)
+}
+
+func TestSubscriptionStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "subscriptions.json")
+	store := NewSubscriptionStore(path)
+	slack := []Target{{Type: TargetSlack, URL: "https://hooks.slack.com/x"}}
+
+	_, err := store.Upsert(Subscription{PersonID: "person_1"})
+	assert.Error(t, err, "a subscription needs a channel")
+	_, err = store.Upsert(Subscription{PersonID: "person_1", Channels: slack, Mode: DeliveryDigest})
+	assert.Error(t, err, "digest mode needs a period")
+	_, err = store.Upsert(Subscription{PersonID: "person_1", Channels: slack, EventTypes: []EventType{"deploy"}})
+	assert.Error(t, err)
+
+	immediate, err := store.Upsert(Subscription{
+		PersonID:   "person_1",
+		Projects:   []string{"github.com/acme/api"},
+		EventTypes: []EventType{EventDecision},
+		Channels:   slack,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, DeliveryImmediate, immediate.Mode)
+	everything, err := store.Upsert(Subscription{PersonID: "person_2", Channels: slack, Mode: DeliveryDigest, Period: PeriodDaily, Hour: 9})
+	require.NoError(t, err)
+
+	assert.Len(t, store.Matching("github.com/acme/api", EventDecision), 2)
+	matching := store.Matching("github.com/acme/web", EventDecision)
+	require.Len(t, matching, 1)
+	assert.Equal(t, everything.ID, matching[0].ID)
+	assert.Len(t, store.List("person_1"), 1)
+
+	everything.Paused = true
+	_, err = store.Upsert(*everything)
+	require.NoError(t, err)
+	assert.Empty(t, store.Matching("github.com/acme/web", EventDecision))
+
+	tenant := &tenancy.Tenant{ID: "api_key:k1", Projects: []string{"github.com/acme/api"}}
+	held, err := store.Upsert(Subscription{PersonID: "person_3", Channels: slack, Tenant: tenant})
+	require.NoError(t, err)
+	assert.Len(t, store.Matching("github.com/acme/api", EventDecision), 2)
+	assert.Empty(t, store.Matching("github.com/acme/web", EventDecision), "a tenant's subscription only covers its projects")
+
+	reloaded := NewSubscriptionStore(path)
+	require.NoError(t, reloaded.Load())
+	assert.Len(t, reloaded.List(), 3)
+	persisted, ok := reloaded.Get(held.ID)
+	require.True(t, ok)
+	assert.Equal(t, tenant, persisted.Tenant)
+	require.NoError(t, reloaded.Delete(held.ID))
+	require.NoError(t, reloaded.Delete(immediate.ID))
+	assert.Error(t, reloaded.Delete(immediate.ID))
+	assert.Len(t, reloaded.List(), 1)
+}
+
+func TestNotifier(t *testing.T) {
+	ctx := context.Background()
+	store := NewSubscriptionStore("")
+	deliverer := &recordingDeliverer{}
+	notifier := NewNotifier(store, deliverer)
+	webhook := []Target{{Type: TargetWebhook, URL: "http://example.com"}}
+
+	_, err := store.Upsert(Subscription{PersonID: "person_1", EventTypes: []EventType{EventTaskStatus}, Channels: webhook})
+	require.NoError(t, err)
+	batched, err := store.Upsert(Subscription{PersonID: "person_2", Channels: webhook, Mode: DeliveryDigest, Period: PeriodDaily, Hour: 9})
+	require.NoError(t, err)
+
+	start := time.Date(2025, 3, 5, 8, 0, 0, 0, time.UTC)
+	require.NoError(t, notifier.Notify(ctx, &Event{Type: EventTaskStatus, Project: "github.com/acme/api", Title: "Rotate keys: completed", OccurredAt: start}))
+	require.NoError(t, notifier.Notify(ctx, &Event{Type: EventDecision, Project: "github.com/acme/api", Title: "Use Qdrant", OccurredAt: start.Add(time.Minute)}))
+
+	require.Len(t, deliverer.messages, 1, "only the immediate subscription hears at once")
+	assert.Contains(t, deliverer.messages[0].Body, "Rotate keys")
+	assert.Equal(t, 2, notifier.Pending(batched.ID))
+
+	assert.Equal(t, 0, notifier.FlushDue(ctx, start.Add(30*time.Minute)))
+	assert.Equal(t, 1, notifier.FlushDue(ctx, start.Add(time.Hour)))
+	require.Len(t, deliverer.messages, 2)
+	assert.Len(t, deliverer.messages[1].Events, 2)
+	assert.Zero(t, notifier.Pending(batched.ID))
+
+	// Scheduled project digests also reach digest subscribers
+	scheduler := NewScheduler(NewGenerator(storage.NewSimpleMockVectorStore()), deliverer)
+	scheduler.SetNotifier(notifier)
+	schedule := Schedule{Project: "github.com/acme/api", Period: PeriodDaily, Targets: webhook}
+	require.NoError(t, scheduler.Deliver(ctx, &schedule, start))
+	require.Len(t, deliverer.messages, 4, "the schedule's target and the subscriber")
+	assert.Equal(t, deliverer.messages[2], deliverer.messages[3])
+}
+
+type staticUsageReporter struct {
+	tenant, month, format string
+}
+
+func (r *staticUsageReporter) RenderUsage(_ context.Context, tenant, month, format string) (string, error) {
+	r.tenant, r.month, r.format = tenant, month, format
+	return "month,tenant\n" + month + "," + tenant + "\n", nil
+}
+
+func TestUsageScheduleNextRun(t *testing.T) {
+	schedule := UsageSchedule{Day: 3, Hour: 6}
+	assert.Equal(t, time.Date(2026, 9, 3, 6, 0, 0, 0, time.UTC), schedule.NextRun(time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2026, 10, 3, 6, 0, 0, 0, time.UTC), schedule.NextRun(time.Date(2026, 9, 3, 6, 0, 0, 0, time.UTC)))
+
+	schedule = UsageSchedule{}
+	assert.Equal(t, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), schedule.NextRun(time.Date(2026, 12, 15, 0, 0, 0, 0, time.UTC)))
+
+	invalid := UsageSchedule{Day: 31, Targets: []Target{{Type: TargetWebhook, URL: "http://example.com"}}}
+	assert.Error(t, invalid.Validate())
+	invalid = UsageSchedule{Format: FormatHTML, Targets: []Target{{Type: TargetWebhook, URL: "http://example.com"}}}
+	assert.Error(t, invalid.Validate())
+}
+
+func TestSchedulerDeliversUsageReports(t *testing.T) {
+	deliverer := &recordingDeliverer{}
+	scheduler := NewScheduler(NewGenerator(storage.NewSimpleMockVectorStore()), deliverer)
+	schedule := UsageSchedule{Tenant: "acme", Targets: []Target{{Type: TargetWebhook, URL: "http://example.com"}}}
+
+	start := time.Date(2026, 9, 14, 8, 0, 0, 0, time.UTC)
+	require.Error(t, scheduler.AddUsageSchedule(schedule, start), "usage reports need a reporter")
+
+	reporter := &staticUsageReporter{}
+	scheduler.SetUsageReporter(reporter)
+	require.NoError(t, scheduler.AddUsageSchedule(schedule, start))
+	assert.Len(t, scheduler.UsageSchedules(), 1)
+
+	assert.Equal(t, 0, scheduler.RunDue(context.Background(), start.Add(24*time.Hour)))
+	assert.Equal(t, 1, scheduler.RunDue(context.Background(), time.Date(2026, 10, 1, 0, 30, 0, 0, time.UTC)))
+	assert.Equal(t, 0, scheduler.RunDue(context.Background(), time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)))
+
+	require.Len(t, deliverer.messages, 1)
+	assert.Equal(t, "2026-09", reporter.month, "the report covers the month before delivery")
+	assert.Equal(t, "csv", reporter.format)
+	assert.Equal(t, FormatCSV, deliverer.messages[0].Format)
+	assert.Equal(t, "Usage report for acme (2026-09)", deliverer.messages[0].Subject)
+	assert.Contains(t, deliverer.messages[0].Body, "2026-09,acme")
+}
+
+func TestLoadUsageSchedules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+schedules:
+  - project: github.com/acme/api
+    period: weekly
+    targets: [{type: webhook, url: "http://example.com"}]
+usage_reports:
+  - format: json
+    day: 2
+    targets: [{type: email, to: [finance@example.com]}]
+`), 0o600))
+
+	schedules, err := LoadSchedules(path)
+	require.NoError(t, err)
+	assert.Len(t, schedules, 1)
+
+	usageSchedules, err := LoadUsageSchedules(path)
+	require.NoError(t, err)
+	require.Len(t, usageSchedules, 1)
+	assert.Empty(t, usageSchedules[0].Tenant)
+	assert.Equal(t, FormatJSON, usageSchedules[0].Format)
+	assert.Equal(t, 2, usageSchedules[0].Day)
+}
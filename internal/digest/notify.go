@@ -0,0 +1,193 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"lerian-mcp-memory/internal/logging"
+)
+
+// maxPendingEvents bounds the events batched for one digest-mode subscription;
+// the oldest are dropped first
+const maxPendingEvents = 200
+
+// Event is something that happened in a project that subscribers may hear about
+type Event struct {
+	Type       EventType              `json:"type"`
+	Project    string                 `json:"project"`
+	Title      string                 `json:"title"`
+	ChunkID    string                 `json:"chunk_id,omitempty"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	OccurredAt time.Time              `json:"occurred_at"`
+}
+
+// Notifier delivers project events to the channels of the subscriptions
+// covering them: immediate subscriptions get each event as it happens,
+// digest subscriptions get a batch on their schedule. Batches are kept in
+// memory and do not survive a restart.
+type Notifier struct {
+	subscriptions *SubscriptionStore
+	deliverer     Deliverer
+	interval      time.Duration
+
+	mutex    sync.Mutex
+	pending  map[string][]Event   // subscription id -> batched events
+	nextRuns map[string]time.Time // subscription id -> when its batch is due
+}
+
+// NewNotifier creates a notifier that consults subscriptions and sends
+// through deliverer
+func NewNotifier(subscriptions *SubscriptionStore, deliverer Deliverer) *Notifier {
+	return &Notifier{
+		subscriptions: subscriptions,
+		deliverer:     deliverer,
+		interval:      time.Minute,
+		pending:       make(map[string][]Event),
+		nextRuns:      make(map[string]time.Time),
+	}
+}
+
+// Notify sends an event to immediate subscriptions and batches it for
+// digest subscriptions. It returns the delivery failures.
+func (n *Notifier) Notify(ctx context.Context, event *Event) error {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+
+	var errs []error
+	for _, subscription := range n.subscriptions.Matching(event.Project, event.Type) {
+		if subscription.Mode == DeliveryDigest {
+			n.enqueue(&subscription, event)
+			continue
+		}
+		if err := n.Send(ctx, &subscription, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Send delivers one event to a subscription's channels right away,
+// whatever its mode; it is also used to test a subscription's channels
+func (n *Notifier) Send(ctx context.Context, subscription *Subscription, event *Event) error {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+	msg := &Message{
+		Format:  FormatMarkdown,
+		Subject: fmt.Sprintf("[%s] %s: %s", event.Project, event.Type, event.Title),
+		Body:    renderEvents([]Event{*event}),
+		Events:  []Event{*event},
+	}
+	return n.send(ctx, subscription, msg)
+}
+
+// DeliverDigest sends a rendered project digest to the subscriptions
+// covering digests of its project
+func (n *Notifier) DeliverDigest(ctx context.Context, msg *Message) error {
+	var errs []error
+	for _, subscription := range n.subscriptions.Matching(msg.Digest.Project, EventDigest) {
+		if err := n.send(ctx, &subscription, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Pending returns the number of events batched for a subscription
+func (n *Notifier) Pending(subscriptionID string) int {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return len(n.pending[subscriptionID])
+}
+
+// Run sends due batches until the context is cancelled
+func (n *Notifier) Run(ctx context.Context) {
+	ticker := time.NewTicker(n.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logging.Info("Stopping notification batches due to context cancellation")
+			return
+		case now := <-ticker.C:
+			n.FlushDue(ctx, now)
+		}
+	}
+}
+
+// FlushDue sends every batch whose subscription is due at or before now and
+// returns how many were sent. Batches of subscriptions that were deleted,
+// paused or switched to immediate delivery are dropped.
+func (n *Notifier) FlushDue(ctx context.Context, now time.Time) int {
+	n.mutex.Lock()
+	due := make(map[string][]Event)
+	for id, next := range n.nextRuns {
+		if !next.After(now) {
+			due[id] = n.pending[id]
+			delete(n.pending, id)
+			delete(n.nextRuns, id)
+		}
+	}
+	n.mutex.Unlock()
+
+	sent := 0
+	for id, events := range due {
+		subscription, ok := n.subscriptions.Get(id)
+		if !ok || subscription.Paused || subscription.Mode != DeliveryDigest || len(events) == 0 {
+			continue
+		}
+		msg := &Message{
+			Format:  FormatMarkdown,
+			Subject: fmt.Sprintf("%s notifications: %d updates (%s)", subscription.Period, len(events), now.UTC().Format("2006-01-02")),
+			Body:    renderEvents(events),
+			Events:  events,
+		}
+		if err := n.send(ctx, &subscription, msg); err != nil {
+			logging.Error("Notification batch delivery failed", "subscription", id, "error", err)
+			continue
+		}
+		sent++
+	}
+	return sent
+}
+
+// enqueue adds an event to a digest subscription's batch
+func (n *Notifier) enqueue(subscription *Subscription, event *Event) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	events := append(n.pending[subscription.ID], *event)
+	if len(events) > maxPendingEvents {
+		events = events[len(events)-maxPendingEvents:]
+	}
+	n.pending[subscription.ID] = events
+	if _, scheduled := n.nextRuns[subscription.ID]; !scheduled {
+		n.nextRuns[subscription.ID] = subscription.NextRun(event.OccurredAt)
+	}
+}
+
+// send delivers a message to every channel of a subscription
+func (n *Notifier) send(ctx context.Context, subscription *Subscription, msg *Message) error {
+	var errs []error
+	for _, channel := range subscription.Channels {
+		if err := n.deliverer.Deliver(ctx, channel, msg); err != nil {
+			errs = append(errs, fmt.Errorf("subscription %s, %s: %w", subscription.ID, channel.Type, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// renderEvents lists events as Markdown, oldest first
+func renderEvents(events []Event) string {
+	var b strings.Builder
+	for i := range events {
+		fmt.Fprintf(&b, "- **%s** %s: %s (%s)\n", events[i].Type, events[i].Project, events[i].Title, events[i].OccurredAt.UTC().Format("2006-01-02 15:04"))
+	}
+	return b.String()
+}
@@ -0,0 +1,100 @@
+package digest
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	"text/template"
+)
+
+// Format represents the rendering format of a digest
+type Format string
+
+const (
+	// FormatMarkdown renders the digest as Markdown
+	FormatMarkdown Format = "markdown"
+	// FormatHTML renders the digest as an HTML document
+	FormatHTML Format = "html"
+	// FormatCSV is a usage report as CSV, one row per tenant
+	FormatCSV Format = "csv"
+	// FormatJSON is a usage report as JSON
+	FormatJSON Format = "json"
+)
+
+const markdownTemplate = `# {{ title .Period }} digest: {{ .Project }}
+
+_{{ .Start.Format "2006-01-02 15:04" }} – {{ .End.Format "2006-01-02 15:04" }} UTC_
+
+## New decisions ({{ len .NewDecisions }})
+{{ range .NewDecisions }}- {{ .Title }} ({{ .Timestamp.Format "2006-01-02" }})
+{{ else }}_None_
+{{ end }}
+## Resolved problems ({{ len .ResolvedProblems }})
+{{ range .ResolvedProblems }}- {{ .Title }} ({{ .Timestamp.Format "2006-01-02" }})
+{{ else }}_None_
+{{ end }}
+## Open conflicts ({{ len .OpenConflicts }})
+{{ range .OpenConflicts }}- **[{{ .Severity }}]** {{ .Title }}
+{{ else }}_None_
+{{ end }}
+## Stale tasks ({{ len .StaleTasks }})
+{{ range .StaleTasks }}- {{ .Title }}{{ if .Status }} — {{ .Status }}{{ end }} (since {{ .Timestamp.Format "2006-01-02" }})
+{{ else }}_None_
+{{ end }}`
+
+const htmlTemplate = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{ title .Period }} digest: {{ .Project }}</title></head>
+<body>
+<h1>{{ title .Period }} digest: {{ .Project }}</h1>
+<p><em>{{ .Start.Format "2006-01-02 15:04" }} – {{ .End.Format "2006-01-02 15:04" }} UTC</em></p>
+<h2>New decisions ({{ len .NewDecisions }})</h2>
+<ul>{{ range .NewDecisions }}<li>{{ .Title }} ({{ .Timestamp.Format "2006-01-02" }})</li>{{ else }}<li><em>None</em></li>{{ end }}</ul>
+<h2>Resolved problems ({{ len .ResolvedProblems }})</h2>
+<ul>{{ range .ResolvedProblems }}<li>{{ .Title }} ({{ .Timestamp.Format "2006-01-02" }})</li>{{ else }}<li><em>None</em></li>{{ end }}</ul>
+<h2>Open conflicts ({{ len .OpenConflicts }})</h2>
+<ul>{{ range .OpenConflicts }}<li><strong>[{{ .Severity }}]</strong> {{ .Title }}</li>{{ else }}<li><em>None</em></li>{{ end }}</ul>
+<h2>Stale tasks ({{ len .StaleTasks }})</h2>
+<ul>{{ range .StaleTasks }}<li>{{ .Title }}{{ if .Status }} — {{ .Status }}{{ end }} (since {{ .Timestamp.Format "2006-01-02" }})</li>{{ else }}<li><em>None</em></li>{{ end }}</ul>
+</body>
+</html>
+`
+
+var templateFuncs = map[string]interface{}{
+	"title": func(p Period) string {
+		s := string(p)
+		if s == "" {
+			return s
+		}
+		return strings.ToUpper(s[:1]) + s[1:]
+	},
+}
+
+var (
+	markdownTmpl = template.Must(template.New("digest-markdown").Funcs(templateFuncs).Parse(markdownTemplate))
+	htmlTmpl     = htmltemplate.Must(htmltemplate.New("digest-html").Funcs(templateFuncs).Parse(htmlTemplate))
+)
+
+// Render renders the digest in the requested format
+func Render(d *Digest, format Format) (string, error) {
+	var buf bytes.Buffer
+	utc := *d
+	utc.Start = d.Start.UTC()
+	utc.End = d.End.UTC()
+
+	var err error
+	switch format {
+	case FormatMarkdown, "":
+		err = markdownTmpl.Execute(&buf, &utc)
+	case FormatHTML:
+		err = htmlTmpl.Execute(&buf, &utc)
+	default:
+		return "", fmt.Errorf("unsupported digest format: %s (valid: %s, %s)", format, FormatMarkdown, FormatHTML)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to render digest: %w", err)
+	}
+
+	return buf.String(), nil
+}
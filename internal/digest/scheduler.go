@@ -0,0 +1,307 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/tenancy"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schedule describes when and where a project's digest is delivered
+type Schedule struct {
+	Project string   `json:"project" yaml:"project"`
+	Period  Period   `json:"period" yaml:"period"`
+	Format  Format   `json:"format,omitempty" yaml:"format,omitempty"`
+	Hour    int      `json:"hour" yaml:"hour"`                           // UTC hour of day to deliver
+	Weekday string   `json:"weekday,omitempty" yaml:"weekday,omitempty"` // weekly only, defaults to monday
+	Targets []Target `json:"targets" yaml:"targets"`
+	// SkipEmpty suppresses delivery when there is nothing to report
+	SkipEmpty bool `json:"skip_empty,omitempty" yaml:"skip_empty,omitempty"`
+	// Tenant that added the schedule, if it was held to one; its digests are
+	// generated on the tenant's behalf. Schedules from the file have none.
+	Tenant *tenancy.Tenant `json:"tenant,omitempty" yaml:"-"`
+}
+
+// Validate checks the schedule for missing or invalid fields
+func (s *Schedule) Validate() error {
+	if s.Project == "" {
+		return errors.New("schedule project is required")
+	}
+	if !s.Period.Valid() {
+		return fmt.Errorf("schedule for %s has invalid period: %q (valid: daily, weekly)", s.Project, s.Period)
+	}
+	if s.Format != "" && s.Format != FormatMarkdown && s.Format != FormatHTML {
+		return fmt.Errorf("schedule for %s has invalid format: %q (valid: markdown, html)", s.Project, s.Format)
+	}
+	if s.Hour < 0 || s.Hour > 23 {
+		return fmt.Errorf("schedule for %s has invalid hour: %d (must be 0-23)", s.Project, s.Hour)
+	}
+	if _, err := parseWeekday(s.Weekday); err != nil {
+		return fmt.Errorf("schedule for %s: %w", s.Project, err)
+	}
+	if len(s.Targets) == 0 {
+		return fmt.Errorf("schedule for %s requires at least one target", s.Project)
+	}
+	for _, target := range s.Targets {
+		if err := target.Validate(); err != nil {
+			return fmt.Errorf("schedule for %s: %w", s.Project, err)
+		}
+	}
+	return nil
+}
+
+// NextRun returns the first delivery time strictly after the given time
+func (s *Schedule) NextRun(after time.Time) time.Time {
+	after = after.UTC()
+	next := time.Date(after.Year(), after.Month(), after.Day(), s.Hour, 0, 0, 0, time.UTC)
+	if !next.After(after) {
+		next = next.AddDate(0, 0, 1)
+	}
+
+	if s.Period == PeriodWeekly {
+		weekday, _ := parseWeekday(s.Weekday)
+		for next.Weekday() != weekday {
+			next = next.AddDate(0, 0, 1)
+		}
+	}
+
+	return next
+}
+
+// parseWeekday parses a weekday name, defaulting to Monday
+func parseWeekday(name string) (time.Weekday, error) {
+	if name == "" {
+		return time.Monday, nil
+	}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(d.String(), name) {
+			return d, nil
+		}
+	}
+	return time.Monday, fmt.Errorf("invalid weekday: %q", name)
+}
+
+// scheduleFile is the on-disk layout of the schedules file
+type scheduleFile struct {
+	Schedules    []Schedule      `yaml:"schedules"`
+	UsageReports []UsageSchedule `yaml:"usage_reports"`
+}
+
+// readScheduleFile reads a YAML or JSON schedules file
+func readScheduleFile(path string) (*scheduleFile, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // Path comes from operator configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read digest schedules: %w", err)
+	}
+
+	var file scheduleFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse digest schedules: %w", err)
+	}
+	return &file, nil
+}
+
+// LoadSchedules reads and validates digest schedules from a YAML or JSON file
+func LoadSchedules(path string) ([]Schedule, error) {
+	file, err := readScheduleFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range file.Schedules {
+		if err := file.Schedules[i].Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return file.Schedules, nil
+}
+
+// Scheduler runs digest and usage report schedules and delivers the results
+type Scheduler struct {
+	generator     *Generator
+	deliverer     Deliverer
+	notifier      *Notifier     // also sends digests to subscribers, when set
+	usageReporter UsageReporter // renders usage reports, when metering is enabled
+	interval      time.Duration
+
+	mutex          sync.Mutex
+	schedules      map[string]*Schedule
+	nextRuns       map[string]time.Time
+	usageSchedules map[string]*UsageSchedule // tenant -> schedule
+	usageRuns      map[string]time.Time
+}
+
+// NewScheduler creates a scheduler that checks for due digests every minute
+func NewScheduler(generator *Generator, deliverer Deliverer) *Scheduler {
+	return &Scheduler{
+		generator:      generator,
+		deliverer:      deliverer,
+		interval:       time.Minute,
+		schedules:      make(map[string]*Schedule),
+		nextRuns:       make(map[string]time.Time),
+		usageSchedules: make(map[string]*UsageSchedule),
+		usageRuns:      make(map[string]time.Time),
+	}
+}
+
+// SetNotifier makes the scheduler also send each digest to the people
+// subscribed to digests of its project
+func (s *Scheduler) SetNotifier(notifier *Notifier) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.notifier = notifier
+}
+
+// scheduleKey identifies a schedule; a project may have one daily and one weekly digest
+func scheduleKey(project string, period Period) string {
+	return project + "|" + string(period)
+}
+
+// AddSchedule adds or replaces the schedule for a project and period
+func (s *Scheduler) AddSchedule(schedule Schedule, now time.Time) error {
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := scheduleKey(schedule.Project, schedule.Period)
+	s.schedules[key] = &schedule
+	s.nextRuns[key] = schedule.NextRun(now)
+	return nil
+}
+
+// RemoveSchedule removes the schedule for a project and period
+func (s *Scheduler) RemoveSchedule(project string, period Period) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := scheduleKey(project, period)
+	if _, ok := s.schedules[key]; !ok {
+		return false
+	}
+	delete(s.schedules, key)
+	delete(s.nextRuns, key)
+	return true
+}
+
+// Schedules returns a copy of the configured schedules
+func (s *Scheduler) Schedules() []Schedule {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result := make([]Schedule, 0, len(s.schedules))
+	for _, schedule := range s.schedules {
+		result = append(result, *schedule)
+	}
+	return result
+}
+
+// Run checks for due schedules until the context is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logging.Info("Stopping digest scheduler due to context cancellation")
+			return
+		case now := <-ticker.C:
+			s.RunDue(ctx, now)
+		}
+	}
+}
+
+// RunDue generates and delivers every digest and usage report whose next run is at or before now
+func (s *Scheduler) RunDue(ctx context.Context, now time.Time) int {
+	s.mutex.Lock()
+	due := make([]Schedule, 0)
+	for key, next := range s.nextRuns {
+		if !next.After(now) {
+			due = append(due, *s.schedules[key])
+			s.nextRuns[key] = s.schedules[key].NextRun(now)
+		}
+	}
+	s.mutex.Unlock()
+
+	for i := range due {
+		if err := s.Deliver(ctx, &due[i], now); err != nil {
+			logging.Error("Digest delivery failed", "project", due[i].Project, "period", due[i].Period, "error", err)
+		}
+	}
+
+	dueUsage := s.dueUsageSchedules(now)
+	for i := range dueUsage {
+		if err := s.DeliverUsage(ctx, &dueUsage[i], now); err != nil {
+			logging.Error("Usage report delivery failed", "tenant", dueUsage[i].name(), "error", err)
+		}
+	}
+	return len(due) + len(dueUsage)
+}
+
+// Deliver generates the digest for a schedule and sends it to all of its targets
+func (s *Scheduler) Deliver(ctx context.Context, schedule *Schedule, now time.Time) error {
+	if schedule.Tenant != nil {
+		ctx = tenancy.WithTenant(ctx, schedule.Tenant)
+	}
+	digest, err := s.generator.Generate(ctx, schedule.Project, schedule.Period, now)
+	if err != nil {
+		return err
+	}
+
+	if schedule.SkipEmpty && digest.IsEmpty() {
+		logging.Info("Skipping empty digest", "project", schedule.Project, "period", schedule.Period)
+		return nil
+	}
+
+	format := schedule.Format
+	if format == "" {
+		format = FormatMarkdown
+	}
+
+	body, err := Render(digest, format)
+	if err != nil {
+		return err
+	}
+
+	msg := &Message{
+		Digest:  digest,
+		Format:  format,
+		Body:    body,
+		Subject: fmt.Sprintf("%s digest for %s (%s)", schedule.Period, schedule.Project, now.UTC().Format("2006-01-02")),
+	}
+
+	var errs []error
+	for _, target := range schedule.Targets {
+		if err := s.deliverer.Deliver(ctx, target, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", target.Type, err))
+		}
+	}
+
+	s.mutex.Lock()
+	notifier := s.notifier
+	s.mutex.Unlock()
+	if notifier != nil {
+		if err := notifier.DeliverDigest(ctx, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	logging.Info("Digest delivered", "project", schedule.Project, "period", schedule.Period, "targets", len(schedule.Targets))
+	return nil
+}
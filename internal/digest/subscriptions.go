@@ -0,0 +1,316 @@
+package digest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"lerian-mcp-memory/internal/tenancy"
+
+	"github.com/google/uuid"
+)
+
+// EventType is a kind of project event people can subscribe to
+type EventType string
+
+const (
+	// EventDigest is a project's scheduled digest
+	EventDigest EventType = "digest"
+	// EventTaskStatus is a task moving to another status
+	EventTaskStatus EventType = "task_status"
+	// EventDecision is a new architecture decision
+	EventDecision EventType = "decision"
+	// EventVerification is a solution verified or found failing
+	EventVerification EventType = "verification"
+)
+
+// EventTypes lists the event types a subscription may select
+var EventTypes = []EventType{EventDigest, EventTaskStatus, EventDecision, EventVerification}
+
+// Valid checks if the event type is supported
+func (e EventType) Valid() bool {
+	for _, known := range EventTypes {
+		if e == known {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliveryMode is how a subscription receives events
+type DeliveryMode string
+
+const (
+	// DeliveryImmediate sends each event as it happens
+	DeliveryImmediate DeliveryMode = "immediate"
+	// DeliveryDigest batches events and sends them on the subscription's schedule
+	DeliveryDigest DeliveryMode = "digest"
+)
+
+// Subscription is one person's choice of which project events reach them,
+// through which channels, and how often
+type Subscription struct {
+	ID       string `json:"id"`
+	PersonID string `json:"person_id"`
+	// Projects the subscription covers; empty covers every project
+	Projects []string `json:"projects,omitempty"`
+	// EventTypes the subscription covers; empty covers every type
+	EventTypes []EventType  `json:"event_types,omitempty"`
+	Channels   []Target     `json:"channels"`
+	Mode       DeliveryMode `json:"mode"`
+	// Period, Hour (UTC) and Weekday schedule batches in digest mode
+	Period  Period `json:"period,omitempty"`
+	Hour    int    `json:"hour"`
+	Weekday string `json:"weekday,omitempty"`
+	// Paused subscriptions keep their settings but receive nothing
+	Paused bool `json:"paused,omitempty"`
+	// Tenant that created the subscription, if it was held to one; it only
+	// receives events of the tenant's projects
+	Tenant *tenancy.Tenant `json:"tenant,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks the subscription for missing or invalid fields
+func (s *Subscription) Validate() error {
+	if s.PersonID == "" {
+		return errors.New("subscription person_id is required")
+	}
+	for _, eventType := range s.EventTypes {
+		if !eventType.Valid() {
+			return fmt.Errorf("invalid event type: %q (valid: digest, task_status, decision, verification)", eventType)
+		}
+	}
+	if len(s.Channels) == 0 {
+		return errors.New("subscription requires at least one channel")
+	}
+	for _, channel := range s.Channels {
+		if err := channel.Validate(); err != nil {
+			return err
+		}
+	}
+	switch s.Mode {
+	case DeliveryImmediate:
+	case DeliveryDigest:
+		if !s.Period.Valid() {
+			return fmt.Errorf("digest subscriptions require a period: %q (valid: daily, weekly)", s.Period)
+		}
+		if s.Hour < 0 || s.Hour > 23 {
+			return fmt.Errorf("invalid hour: %d (must be 0-23)", s.Hour)
+		}
+		if _, err := parseWeekday(s.Weekday); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid mode: %q (valid: immediate, digest)", s.Mode)
+	}
+	return nil
+}
+
+// Matches reports whether an event of a project is covered by the
+// subscription, which never covers projects its tenant does not own
+func (s *Subscription) Matches(project string, eventType EventType) bool {
+	if s.Paused || (s.Tenant != nil && !s.Tenant.Allows(project)) {
+		return false
+	}
+	return (len(s.Projects) == 0 || contains(s.Projects, project)) &&
+		(len(s.EventTypes) == 0 || contains(s.EventTypes, eventType))
+}
+
+// NextRun returns when the subscription's next batch is due after the given time
+func (s *Subscription) NextRun(after time.Time) time.Time {
+	schedule := Schedule{Period: s.Period, Hour: s.Hour, Weekday: s.Weekday}
+	return schedule.NextRun(after)
+}
+
+// contains reports whether a slice holds a value
+func contains[T comparable](values []T, value T) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// SubscriptionStore keeps notification subscriptions. They are persisted to
+// an optional JSON file so they survive restarts.
+type SubscriptionStore struct {
+	mu            sync.RWMutex
+	path          string
+	subscriptions map[string]Subscription // id -> subscription
+}
+
+// NewSubscriptionStore creates a store persisted at path; an empty path keeps it in memory
+func NewSubscriptionStore(path string) *SubscriptionStore {
+	return &SubscriptionStore{
+		path:          path,
+		subscriptions: make(map[string]Subscription),
+	}
+}
+
+// Load reads subscriptions from disk. A missing file is not an error.
+func (s *SubscriptionStore) Load() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read notification subscriptions: %w", err)
+	}
+
+	var subscriptions []Subscription
+	if err := json.Unmarshal(data, &subscriptions); err != nil {
+		return fmt.Errorf("failed to parse notification subscriptions: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range subscriptions {
+		s.subscriptions[subscriptions[i].ID] = subscriptions[i]
+	}
+	return nil
+}
+
+// Upsert creates a subscription, or replaces the one with the same ID
+func (s *SubscriptionStore) Upsert(subscription Subscription) (*Subscription, error) {
+	if subscription.Mode == "" {
+		subscription.Mode = DeliveryImmediate
+	}
+	if err := subscription.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	if subscription.ID == "" {
+		subscription.ID = uuid.New().String()
+		subscription.CreatedAt = now
+	} else {
+		previous, ok := s.subscriptions[subscription.ID]
+		if !ok {
+			return nil, fmt.Errorf("subscription %q not found", subscription.ID)
+		}
+		subscription.CreatedAt = previous.CreatedAt
+	}
+	subscription.UpdatedAt = now
+
+	previous, existed := s.subscriptions[subscription.ID]
+	s.subscriptions[subscription.ID] = subscription
+	if err := s.persistLocked(); err != nil {
+		if existed {
+			s.subscriptions[subscription.ID] = previous
+		} else {
+			delete(s.subscriptions, subscription.ID)
+		}
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// Delete removes a subscription
+func (s *SubscriptionStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.subscriptions[id]
+	if !ok {
+		return fmt.Errorf("subscription %q not found", id)
+	}
+	delete(s.subscriptions, id)
+	if err := s.persistLocked(); err != nil {
+		s.subscriptions[id] = previous
+		return err
+	}
+	return nil
+}
+
+// Get returns a subscription by ID
+func (s *SubscriptionStore) Get(id string) (Subscription, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	subscription, ok := s.subscriptions[id]
+	return subscription, ok
+}
+
+// List returns the subscriptions of the given people, or of everyone when
+// none are given, sorted by creation
+func (s *SubscriptionStore) List(personIDs ...string) []Subscription {
+	s.mu.RLock()
+	subscriptions := make([]Subscription, 0, len(s.subscriptions))
+	for id := range s.subscriptions {
+		if len(personIDs) == 0 || contains(personIDs, s.subscriptions[id].PersonID) {
+			subscriptions = append(subscriptions, s.subscriptions[id])
+		}
+	}
+	s.mu.RUnlock()
+
+	sortSubscriptions(subscriptions)
+	return subscriptions
+}
+
+// Matching returns the active subscriptions covering an event of a project
+func (s *SubscriptionStore) Matching(project string, eventType EventType) []Subscription {
+	s.mu.RLock()
+	subscriptions := make([]Subscription, 0)
+	for id := range s.subscriptions {
+		subscription := s.subscriptions[id]
+		if subscription.Matches(project, eventType) {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	s.mu.RUnlock()
+
+	sortSubscriptions(subscriptions)
+	return subscriptions
+}
+
+// sortSubscriptions orders subscriptions by creation, then ID
+func sortSubscriptions(subscriptions []Subscription) {
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if !subscriptions[i].CreatedAt.Equal(subscriptions[j].CreatedAt) {
+			return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+		}
+		return subscriptions[i].ID < subscriptions[j].ID
+	})
+}
+
+// persistLocked writes all subscriptions to disk; callers must hold the write lock
+func (s *SubscriptionStore) persistLocked() error {
+	if s.path == "" {
+		return nil
+	}
+
+	subscriptions := make([]Subscription, 0, len(s.subscriptions))
+	for id := range s.subscriptions {
+		subscriptions = append(subscriptions, s.subscriptions[id])
+	}
+	sortSubscriptions(subscriptions)
+
+	data, err := json.MarshalIndent(subscriptions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode notification subscriptions: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o750); err != nil {
+		return fmt.Errorf("failed to create notification subscriptions directory: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write notification subscriptions: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,179 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"lerian-mcp-memory/internal/logging"
+)
+
+// maxUsageReportDay is the last day of the month a usage report may be scheduled on, so every month has it
+const maxUsageReportDay = 28
+
+// UsageReporter renders the usage report of a tenant, or of every tenant
+// when tenant is empty, for a month written like "2026-09"
+type UsageReporter interface {
+	RenderUsage(ctx context.Context, tenant, month, format string) (string, error)
+}
+
+// UsageSchedule describes when and where the monthly usage report is
+// delivered. Each report covers the month before its delivery.
+type UsageSchedule struct {
+	Tenant  string   `json:"tenant,omitempty" yaml:"tenant,omitempty"` // empty reports every tenant
+	Format  Format   `json:"format,omitempty" yaml:"format,omitempty"` // csv or json, defaults to csv
+	Day     int      `json:"day,omitempty" yaml:"day,omitempty"`       // day of month to deliver, defaults to 1
+	Hour    int      `json:"hour" yaml:"hour"`                         // UTC hour of day to deliver
+	Targets []Target `json:"targets" yaml:"targets"`
+}
+
+// name describes whose usage the schedule reports
+func (s *UsageSchedule) name() string {
+	if s.Tenant == "" {
+		return "all tenants"
+	}
+	return s.Tenant
+}
+
+// Validate checks the schedule for invalid fields
+func (s *UsageSchedule) Validate() error {
+	if s.Format != "" && s.Format != FormatCSV && s.Format != FormatJSON {
+		return fmt.Errorf("usage report schedule for %s has invalid format: %q (valid: csv, json)", s.name(), s.Format)
+	}
+	if s.Day < 0 || s.Day > maxUsageReportDay {
+		return fmt.Errorf("usage report schedule for %s has invalid day: %d (must be 1-%d)", s.name(), s.Day, maxUsageReportDay)
+	}
+	if s.Hour < 0 || s.Hour > 23 {
+		return fmt.Errorf("usage report schedule for %s has invalid hour: %d (must be 0-23)", s.name(), s.Hour)
+	}
+	if len(s.Targets) == 0 {
+		return fmt.Errorf("usage report schedule for %s requires at least one target", s.name())
+	}
+	for _, target := range s.Targets {
+		if err := target.Validate(); err != nil {
+			return fmt.Errorf("usage report schedule for %s: %w", s.name(), err)
+		}
+	}
+	return nil
+}
+
+// NextRun returns the first delivery time strictly after the given time
+func (s *UsageSchedule) NextRun(after time.Time) time.Time {
+	day := s.Day
+	if day == 0 {
+		day = 1
+	}
+	after = after.UTC()
+	next := time.Date(after.Year(), after.Month(), day, s.Hour, 0, 0, 0, time.UTC)
+	if !next.After(after) {
+		next = next.AddDate(0, 1, 0)
+	}
+	return next
+}
+
+// LoadUsageSchedules reads and validates the usage report schedules of a
+// schedules file, listed under usage_reports
+func LoadUsageSchedules(path string) ([]UsageSchedule, error) {
+	file, err := readScheduleFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for i := range file.UsageReports {
+		if err := file.UsageReports[i].Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return file.UsageReports, nil
+}
+
+// SetUsageReporter makes the scheduler deliver usage reports rendered by reporter
+func (s *Scheduler) SetUsageReporter(reporter UsageReporter) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.usageReporter = reporter
+}
+
+// AddUsageSchedule adds or replaces the usage report schedule of a tenant
+func (s *Scheduler) AddUsageSchedule(schedule UsageSchedule, now time.Time) error {
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.usageReporter == nil {
+		return errors.New("usage reports require usage metering to be enabled")
+	}
+	s.usageSchedules[schedule.Tenant] = &schedule
+	s.usageRuns[schedule.Tenant] = schedule.NextRun(now)
+	return nil
+}
+
+// UsageSchedules returns a copy of the usage report schedules
+func (s *Scheduler) UsageSchedules() []UsageSchedule {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result := make([]UsageSchedule, 0, len(s.usageSchedules))
+	for _, schedule := range s.usageSchedules {
+		result = append(result, *schedule)
+	}
+	return result
+}
+
+// dueUsageSchedules returns the usage schedules due at now and moves them to their next run
+func (s *Scheduler) dueUsageSchedules(now time.Time) []UsageSchedule {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	due := make([]UsageSchedule, 0)
+	for tenant, next := range s.usageRuns {
+		if !next.After(now) {
+			due = append(due, *s.usageSchedules[tenant])
+			s.usageRuns[tenant] = s.usageSchedules[tenant].NextRun(now)
+		}
+	}
+	return due
+}
+
+// DeliverUsage renders the usage report of the month before now and sends it to all of the schedule's targets
+func (s *Scheduler) DeliverUsage(ctx context.Context, schedule *UsageSchedule, now time.Time) error {
+	s.mutex.Lock()
+	reporter := s.usageReporter
+	s.mutex.Unlock()
+	if reporter == nil {
+		return errors.New("usage reports require usage metering to be enabled")
+	}
+
+	format := schedule.Format
+	if format == "" {
+		format = FormatCSV
+	}
+	now = now.UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0).Format("2006-01")
+
+	body, err := reporter.RenderUsage(ctx, schedule.Tenant, month, string(format))
+	if err != nil {
+		return err
+	}
+
+	msg := &Message{
+		Format:  format,
+		Body:    body,
+		Subject: fmt.Sprintf("Usage report for %s (%s)", schedule.name(), month),
+	}
+
+	var errs []error
+	for _, target := range schedule.Targets {
+		if err := s.deliverer.Deliver(ctx, target, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", target.Type, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	logging.Info("Usage report delivered", "tenant", schedule.name(), "month", month, "targets", len(schedule.Targets))
+	return nil
+}
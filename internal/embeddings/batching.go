@@ -0,0 +1,230 @@
+package embeddings
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/packing"
+)
+
+// BatchingEmbeddingService wraps an EmbeddingService so that concurrent
+// GenerateEmbedding calls are coalesced into GenerateBatchEmbeddings
+// requests of up to maxTexts texts and maxTokens estimated tokens. A
+// request waits at most wait for others to join its batch, and a full
+// batch is sent at once. Batch calls are split along the same limits.
+//
+// A batch runs on the context of its first request, without its deadline
+// or cancellation; a caller whose context ends stops waiting, and a caller
+// whose context ended before its batch was sent is left out of it.
+type BatchingEmbeddingService struct {
+	service   EmbeddingService
+	maxTexts  int
+	maxTokens int
+	wait      time.Duration
+	profile   packing.ModelProfile
+
+	mutex   sync.Mutex
+	pending *embeddingBatch
+	stats   EmbeddingBatchStats
+}
+
+// EmbeddingBatchStats describes the batches sent to the provider
+type EmbeddingBatchStats struct {
+	Requests int64 `json:"requests"` // Single-text requests received
+	Batches  int64 `json:"batches"`  // Provider batch requests made
+	Texts    int64 `json:"texts"`    // Texts embedded in those batches
+	Failed   int64 `json:"failed_batches"`
+}
+
+// embeddingBatch is a batch of requests waiting to be sent
+type embeddingBatch struct {
+	ctx      context.Context
+	requests []*embeddingRequest
+	tokens   int
+	timer    *time.Timer
+}
+
+// embeddingRequest is a text waiting for its embedding
+type embeddingRequest struct {
+	ctx       context.Context
+	text      string
+	tokens    int
+	embedding []float64
+	err       error
+	done      chan struct{}
+}
+
+// NewBatchingEmbeddingService coalesces the single-text requests of service
+// along the batch settings of cfg
+func NewBatchingEmbeddingService(service EmbeddingService, cfg *config.EmbeddingConfig) *BatchingEmbeddingService {
+	return &BatchingEmbeddingService{
+		service:   service,
+		maxTexts:  cfg.BatchSize,
+		maxTokens: cfg.BatchMaxTokens,
+		wait:      time.Duration(cfg.BatchWaitMs) * time.Millisecond,
+		profile:   packing.ProfileFor(service.GetModel()),
+	}
+}
+
+// GenerateEmbedding queues text for the next batch and waits for its embedding
+func (s *BatchingEmbeddingService) GenerateEmbedding(ctx context.Context, text string) ([]float64, error) {
+	if text == "" {
+		return s.service.GenerateEmbedding(ctx, text)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	request := &embeddingRequest{ctx: ctx, text: text, tokens: s.profile.EstimateTokens(text), done: make(chan struct{})}
+	s.enqueue(request)
+
+	select {
+	case <-request.done:
+		return request.embedding, request.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// GenerateBatchEmbeddings generates embeddings for texts in provider
+// requests no larger than the batch limits
+func (s *BatchingEmbeddingService) GenerateBatchEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	if len(texts) == 0 {
+		return s.service.GenerateBatchEmbeddings(ctx, texts)
+	}
+
+	results := make([][]float64, 0, len(texts))
+	for start := 0; start < len(texts); {
+		end, tokens := start, 0
+		for end < len(texts) && end-start < s.maxTexts {
+			estimate := s.profile.EstimateTokens(texts[end])
+			if end > start && tokens+estimate > s.maxTokens {
+				break
+			}
+			tokens += estimate
+			end++
+		}
+
+		embeddings, err := s.service.GenerateBatchEmbeddings(ctx, texts[start:end])
+		s.record(end-start, err)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, embeddings...)
+		start = end
+	}
+	return results, nil
+}
+
+// enqueue adds request to the pending batch, sending the batch once it is full
+func (s *BatchingEmbeddingService) enqueue(request *embeddingRequest) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.stats.Requests++
+
+	if s.pending != nil && s.pending.tokens+request.tokens > s.maxTokens {
+		go s.send(s.takeLocked())
+	}
+	if s.pending == nil {
+		batch := &embeddingBatch{ctx: context.WithoutCancel(request.ctx)}
+		batch.timer = time.AfterFunc(s.wait, func() { s.flush(batch) })
+		s.pending = batch
+	}
+	s.pending.requests = append(s.pending.requests, request)
+	s.pending.tokens += request.tokens
+	if len(s.pending.requests) >= s.maxTexts {
+		go s.send(s.takeLocked())
+	}
+}
+
+// flush sends batch if it is still the pending one
+func (s *BatchingEmbeddingService) flush(batch *embeddingBatch) {
+	s.mutex.Lock()
+	if s.pending != batch {
+		s.mutex.Unlock()
+		return
+	}
+	s.takeLocked()
+	s.mutex.Unlock()
+	s.send(batch)
+}
+
+// takeLocked removes the pending batch and returns it
+func (s *BatchingEmbeddingService) takeLocked() *embeddingBatch {
+	batch := s.pending
+	s.pending = nil
+	batch.timer.Stop()
+	return batch
+}
+
+// send embeds the texts of batch whose callers are still waiting and hands each its result
+func (s *BatchingEmbeddingService) send(batch *embeddingBatch) {
+	waiting := make([]*embeddingRequest, 0, len(batch.requests))
+	texts := make([]string, 0, len(batch.requests))
+	for _, request := range batch.requests {
+		if err := request.ctx.Err(); err != nil {
+			request.err = err
+			close(request.done)
+			continue
+		}
+		waiting = append(waiting, request)
+		texts = append(texts, request.text)
+	}
+	if len(waiting) == 0 {
+		return
+	}
+
+	embeddings, err := s.service.GenerateBatchEmbeddings(batch.ctx, texts)
+	if err == nil && len(embeddings) != len(texts) {
+		err = fmt.Errorf("batch returned %d embeddings for %d texts", len(embeddings), len(texts))
+	}
+	s.record(len(texts), err)
+
+	for i, request := range waiting {
+		switch {
+		case err != nil:
+			request.err = err
+		case len(embeddings[i]) == 0:
+			request.err = fmt.Errorf("batch returned no embedding for text %d", i)
+		default:
+			request.embedding = embeddings[i]
+		}
+		close(request.done)
+	}
+}
+
+// record counts a batch of texts sent to the provider
+func (s *BatchingEmbeddingService) record(texts int, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.stats.Batches++
+	s.stats.Texts += int64(texts)
+	if err != nil {
+		s.stats.Failed++
+	}
+}
+
+// Stats returns the batches sent so far
+func (s *BatchingEmbeddingService) Stats() EmbeddingBatchStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.stats
+}
+
+// HealthCheck checks the wrapped service
+func (s *BatchingEmbeddingService) HealthCheck(ctx context.Context) error {
+	return s.service.HealthCheck(ctx)
+}
+
+// GetDimension returns the embedding dimension
+func (s *BatchingEmbeddingService) GetDimension() int {
+	return s.service.GetDimension()
+}
+
+// GetModel returns the model name
+func (s *BatchingEmbeddingService) GetModel() string {
+	return s.service.GetModel()
+}
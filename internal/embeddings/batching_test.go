@@ -0,0 +1,135 @@
+package embeddings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingEmbeddingService embeds with the fake service and records its batches
+type recordingEmbeddingService struct {
+	FakeEmbeddingService
+	mutex   sync.Mutex
+	batches [][]string
+	err     error
+}
+
+func (s *recordingEmbeddingService) GenerateBatchEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	s.mutex.Lock()
+	s.batches = append(s.batches, append([]string(nil), texts...))
+	err := s.err
+	s.mutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return s.FakeEmbeddingService.GenerateBatchEmbeddings(ctx, texts)
+}
+
+func (s *recordingEmbeddingService) batchSizes() []int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sizes := make([]int, len(s.batches))
+	for i := range s.batches {
+		sizes[i] = len(s.batches[i])
+	}
+	return sizes
+}
+
+// generateConcurrently embeds texts from one goroutine each
+func generateConcurrently(ctx context.Context, service EmbeddingService, texts []string) ([][]float64, []error) {
+	embeddings := make([][]float64, len(texts))
+	errs := make([]error, len(texts))
+	var wg sync.WaitGroup
+	for i := range texts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			embeddings[i], errs[i] = service.GenerateEmbedding(ctx, texts[i])
+		}(i)
+	}
+	wg.Wait()
+	return embeddings, errs
+}
+
+func TestBatchingEmbeddingService_CoalescesConcurrentRequests(t *testing.T) {
+	provider := &recordingEmbeddingService{}
+	service := NewBatchingEmbeddingService(provider, &config.EmbeddingConfig{BatchSize: 5, BatchMaxTokens: 10000, BatchWaitMs: 50})
+
+	texts := make([]string, 12)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("memory %d about websocket reconnects", i)
+	}
+	embeddings, errs := generateConcurrently(context.Background(), service, texts)
+	for i := range texts {
+		require.NoError(t, errs[i])
+		assert.Equal(t, HashEmbedding(texts[i], FakeDimension), embeddings[i], "each caller gets the embedding of its own text")
+	}
+
+	assert.ElementsMatch(t, []int{5, 5, 2}, provider.batchSizes(), "full batches go at once and the rest after the wait")
+	stats := service.Stats()
+	assert.Equal(t, int64(12), stats.Requests)
+	assert.Equal(t, int64(3), stats.Batches)
+	assert.Equal(t, int64(12), stats.Texts)
+}
+
+func TestBatchingEmbeddingService_TokenLimit(t *testing.T) {
+	provider := &recordingEmbeddingService{}
+	service := NewBatchingEmbeddingService(provider, &config.EmbeddingConfig{BatchSize: 100, BatchMaxTokens: 100, BatchWaitMs: 20})
+
+	// About 60 tokens each, so no two fit one batch
+	texts := []string{strings.Repeat("a", 240), strings.Repeat("b", 240), strings.Repeat("c", 240)}
+	_, errs := generateConcurrently(context.Background(), service, texts)
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []int{1, 1, 1}, provider.batchSizes())
+
+	// Batch calls are split along the same limits
+	provider.batches = nil
+	embeddings, err := service.GenerateBatchEmbeddings(context.Background(), []string{"short", strings.Repeat("d", 240), strings.Repeat("e", 240), "tail"})
+	require.NoError(t, err)
+	require.Len(t, embeddings, 4)
+	assert.Equal(t, HashEmbedding("tail", FakeDimension), embeddings[3])
+	assert.Equal(t, []int{2, 2}, provider.batchSizes())
+}
+
+func TestBatchingEmbeddingService_Failures(t *testing.T) {
+	provider := &recordingEmbeddingService{err: errors.New("provider unavailable")}
+	service := NewBatchingEmbeddingService(provider, &config.EmbeddingConfig{BatchSize: 3, BatchMaxTokens: 10000, BatchWaitMs: 1000})
+
+	_, errs := generateConcurrently(context.Background(), service, []string{"one", "two", "three"})
+	for _, err := range errs {
+		assert.ErrorContains(t, err, "provider unavailable", "a failed batch fails all its callers")
+	}
+	assert.Equal(t, int64(1), service.Stats().Failed)
+
+	// A caller whose context ends stops waiting and is left out of the batch
+	provider.err = nil
+	provider.batches = nil
+	service = NewBatchingEmbeddingService(provider, &config.EmbeddingConfig{BatchSize: 10, BatchMaxTokens: 10000, BatchWaitMs: 100})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, err := service.GenerateEmbedding(context.Background(), "kept")
+		assert.NoError(t, err)
+	}()
+	_, err := service.GenerateEmbedding(ctx, "cancelled")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	wg.Wait()
+	assert.Equal(t, [][]string{{"kept"}}, provider.batches)
+
+	_, err = service.GenerateEmbedding(context.Background(), "")
+	assert.Error(t, err, "empty texts are rejected by the wrapped service")
+}
@@ -0,0 +1,52 @@
+package embeddings
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrEmbeddingsDisabled is reported by features that need vector embeddings when the server runs in lite mode
+var ErrEmbeddingsDisabled = errors.New("embeddings are disabled (lite mode): semantic features are unavailable")
+
+// DisabledModel is the model name reported by DisabledEmbeddingService
+const DisabledModel = "none"
+
+// DisabledEmbeddingService is the embedding service used in lite mode. It produces no
+// vectors, so chunks are stored without embeddings and search falls back to keyword ranking.
+type DisabledEmbeddingService struct{}
+
+// NewDisabledEmbeddingService creates an embedding service that never calls a provider
+func NewDisabledEmbeddingService() *DisabledEmbeddingService {
+	return &DisabledEmbeddingService{}
+}
+
+// GenerateEmbedding returns no vector
+func (s *DisabledEmbeddingService) GenerateEmbedding(ctx context.Context, text string) ([]float64, error) {
+	return nil, nil
+}
+
+// GenerateBatchEmbeddings returns one empty vector per text
+func (s *DisabledEmbeddingService) GenerateBatchEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	return make([][]float64, len(texts)), nil
+}
+
+// GetDimension returns 0 because no vectors are produced
+func (s *DisabledEmbeddingService) GetDimension() int {
+	return 0
+}
+
+// GetModel returns DisabledModel
+func (s *DisabledEmbeddingService) GetModel() string {
+	return DisabledModel
+}
+
+// HealthCheck always succeeds
+func (s *DisabledEmbeddingService) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+// IsEnabled reports whether service produces vector embeddings
+func IsEnabled(service EmbeddingService) bool {
+	_, disabled := service.(*DisabledEmbeddingService)
+	return service != nil && !disabled
+}
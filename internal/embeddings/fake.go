@@ -0,0 +1,105 @@
+package embeddings
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Fake embedding model name and dimension. The dimension matches OpenAI's so
+// fake vectors fit the Qdrant collection.
+const (
+	FakeModel     = "fake-hash-v1"
+	FakeDimension = 1536
+)
+
+// FakeEmbeddingService produces deterministic embeddings without network calls.
+// Vectors are hashed bags of words: the same text always yields the same
+// vector and texts sharing words are similar, so demos, local runs and CI get
+// reproducible search results.
+type FakeEmbeddingService struct{}
+
+// NewFakeEmbeddingService creates a deterministic hash-based embedding service
+func NewFakeEmbeddingService() *FakeEmbeddingService {
+	return &FakeEmbeddingService{}
+}
+
+// GenerateEmbedding returns the hashed embedding of text
+func (s *FakeEmbeddingService) GenerateEmbedding(ctx context.Context, text string) ([]float64, error) {
+	if text == "" {
+		return nil, errors.New("text cannot be empty")
+	}
+	return HashEmbedding(text, FakeDimension), nil
+}
+
+// GenerateBatchEmbeddings returns the hashed embedding of each text
+func (s *FakeEmbeddingService) GenerateBatchEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	if len(texts) == 0 {
+		return nil, errors.New("texts cannot be empty")
+	}
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vector, err := s.GenerateEmbedding(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+// GetDimension returns FakeDimension
+func (s *FakeEmbeddingService) GetDimension() int {
+	return FakeDimension
+}
+
+// GetModel returns FakeModel
+func (s *FakeEmbeddingService) GetModel() string {
+	return FakeModel
+}
+
+// HealthCheck always succeeds
+func (s *FakeEmbeddingService) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+// HashEmbedding returns a unit vector of the given dimension for text. Each
+// lowercased word is hashed to a position and a sign (feature hashing), so
+// the result is stable across runs, platforms and Go versions.
+func HashEmbedding(text string, dimension int) []float64 {
+	vector := make([]float64, dimension)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for _, word := range words {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(word))
+		sum := h.Sum64()
+		sign := 1.0
+		if sum>>63 == 1 {
+			sign = -1.0
+		}
+		vector[sum%uint64(dimension)] += sign //nolint:gosec // dimension is positive
+	}
+	if len(words) == 0 {
+		vector[0] = 1
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += v * v
+	}
+	if norm == 0 {
+		// Words cancelled each other out; fall back to a fixed direction
+		vector[0] = 1
+		return vector
+	}
+	norm = math.Sqrt(norm)
+	for i := range vector {
+		vector[i] /= norm
+	}
+	return vector
+}
@@ -0,0 +1,58 @@
+package embeddings
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeEmbeddingServiceIsDeterministic(t *testing.T) {
+	ctx := context.Background()
+	service := NewFakeEmbeddingService()
+
+	first, err := service.GenerateEmbedding(ctx, "Fix websocket reconnect with backoff")
+	require.NoError(t, err)
+	second, err := service.GenerateEmbedding(ctx, "Fix websocket reconnect with backoff")
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.Len(t, first, FakeDimension)
+	assert.Equal(t, FakeDimension, service.GetDimension())
+	assert.Equal(t, FakeModel, service.GetModel())
+	assert.True(t, IsEnabled(service))
+
+	var norm float64
+	for _, v := range first {
+		norm += v * v
+	}
+	assert.InDelta(t, 1.0, math.Sqrt(norm), 1e-9)
+
+	_, err = service.GenerateEmbedding(ctx, "")
+	assert.Error(t, err)
+}
+
+func TestFakeEmbeddingServiceSimilarity(t *testing.T) {
+	ctx := context.Background()
+	service := NewFakeEmbeddingService()
+
+	vectors, err := service.GenerateBatchEmbeddings(ctx, []string{
+		"websocket reconnect backoff",
+		"Websocket reconnect: add backoff!",
+		"database migration rollback",
+	})
+	require.NoError(t, err)
+	require.Len(t, vectors, 3)
+
+	dot := func(a, b []float64) float64 {
+		var sum float64
+		for i := range a {
+			sum += a[i] * b[i]
+		}
+		return sum
+	}
+	assert.Greater(t, dot(vectors[0], vectors[1]), dot(vectors[0], vectors[2]))
+	assert.InDelta(t, 0.866, dot(vectors[0], vectors[1]), 0.01)
+}
@@ -0,0 +1,56 @@
+package embeddings
+
+import (
+	"context"
+	"lerian-mcp-memory/internal/chaos"
+)
+
+// FaultInjectingEmbeddingService wraps an EmbeddingService with the chaos
+// injector, so calls can be delayed or failed on demand. It sits beneath the
+// retry and circuit breaker wrappers.
+type FaultInjectingEmbeddingService struct {
+	service  EmbeddingService
+	injector *chaos.Injector
+}
+
+// NewFaultInjectingEmbeddingService creates a fault injecting service
+func NewFaultInjectingEmbeddingService(service EmbeddingService, injector *chaos.Injector) *FaultInjectingEmbeddingService {
+	return &FaultInjectingEmbeddingService{
+		service:  service,
+		injector: injector,
+	}
+}
+
+// GenerateEmbedding generates an embedding unless a fault is injected
+func (s *FaultInjectingEmbeddingService) GenerateEmbedding(ctx context.Context, text string) ([]float64, error) {
+	if err := s.injector.Inject(ctx, chaos.TargetEmbeddings, "GenerateEmbedding"); err != nil {
+		return nil, err
+	}
+	return s.service.GenerateEmbedding(ctx, text)
+}
+
+// GenerateBatchEmbeddings generates batch embeddings unless a fault is injected
+func (s *FaultInjectingEmbeddingService) GenerateBatchEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	if err := s.injector.Inject(ctx, chaos.TargetEmbeddings, "GenerateBatchEmbeddings"); err != nil {
+		return nil, err
+	}
+	return s.service.GenerateBatchEmbeddings(ctx, texts)
+}
+
+// HealthCheck performs a health check unless a fault is injected
+func (s *FaultInjectingEmbeddingService) HealthCheck(ctx context.Context) error {
+	if err := s.injector.Inject(ctx, chaos.TargetEmbeddings, "HealthCheck"); err != nil {
+		return err
+	}
+	return s.service.HealthCheck(ctx)
+}
+
+// GetDimension returns the embedding dimension
+func (s *FaultInjectingEmbeddingService) GetDimension() int {
+	return s.service.GetDimension()
+}
+
+// GetModel returns the model name
+func (s *FaultInjectingEmbeddingService) GetModel() string {
+	return s.service.GetModel()
+}
@@ -0,0 +1,59 @@
+package embeddings
+
+import "context"
+
+// UsageRecorder counts the embeddings generated for the caller of a context
+type UsageRecorder interface {
+	RecordEmbeddings(ctx context.Context, count int)
+}
+
+// MeteringEmbeddingService wraps an EmbeddingService so that the embeddings
+// it generates are counted for the tenant asking for them
+type MeteringEmbeddingService struct {
+	service  EmbeddingService
+	recorder UsageRecorder
+}
+
+// NewMeteringEmbeddingService creates a metering service
+func NewMeteringEmbeddingService(service EmbeddingService, recorder UsageRecorder) *MeteringEmbeddingService {
+	return &MeteringEmbeddingService{service: service, recorder: recorder}
+}
+
+// GenerateEmbedding generates an embedding and counts it
+func (s *MeteringEmbeddingService) GenerateEmbedding(ctx context.Context, text string) ([]float64, error) {
+	embedding, err := s.service.GenerateEmbedding(ctx, text)
+	if err == nil {
+		s.recorder.RecordEmbeddings(ctx, 1)
+	}
+	return embedding, err
+}
+
+// GenerateBatchEmbeddings generates batch embeddings and counts them
+func (s *MeteringEmbeddingService) GenerateBatchEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	embeddings, err := s.service.GenerateBatchEmbeddings(ctx, texts)
+	if err == nil {
+		generated := 0
+		for i := range embeddings {
+			if len(embeddings[i]) > 0 {
+				generated++
+			}
+		}
+		s.recorder.RecordEmbeddings(ctx, generated)
+	}
+	return embeddings, err
+}
+
+// HealthCheck checks the wrapped service
+func (s *MeteringEmbeddingService) HealthCheck(ctx context.Context) error {
+	return s.service.HealthCheck(ctx)
+}
+
+// GetDimension returns the embedding dimension
+func (s *MeteringEmbeddingService) GetDimension() int {
+	return s.service.GetDimension()
+}
+
+// GetModel returns the model name
+func (s *MeteringEmbeddingService) GetModel() string {
+	return s.service.GetModel()
+}
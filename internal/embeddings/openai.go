@@ -105,7 +105,11 @@ func (rl *RateLimiter) Wait(ctx context.Context) error {
 
 // NewOpenAIEmbeddingService creates a new OpenAI embedding service
 func NewOpenAIEmbeddingService(cfg *config.OpenAIConfig) *OpenAIEmbeddingService {
-	client := openai.NewClient(cfg.APIKey)
+	clientConfig := openai.DefaultConfig(cfg.APIKey)
+	if cfg.BaseURL != "" {
+		clientConfig.BaseURL = cfg.BaseURL
+	}
+	client := openai.NewClientWithConfig(clientConfig)
 
 	// Create rate limiter: allow 1 request per minute / max_rpm
 	// Ensure RateLimitRPM is at least 1 to avoid divide by zero
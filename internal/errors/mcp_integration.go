@@ -248,6 +248,8 @@ func (h *MCPErrorHandler) ValidateMemoryCreateParams(operation string, options m
 		return h.ValidateRequiredParams(options, []string{"source_chunk_id", "target_chunk_id", "relation_type"})
 	case "import_context":
 		return h.ValidateRequiredParams(options, []string{"session_id", "data"})
+	case "define_relation_type":
+		return h.ValidateRequiredParams(options, []string{"name", "description"})
 	default:
 		return NewValidationError("operation", "unsupported operation", operation)
 	}
@@ -279,7 +281,9 @@ func (h *MCPErrorHandler) ValidateMemoryReadParams(operation string, options map
 		return h.ValidateRequiredParams(options, []string{"alias_name"})
 	case "get_bulk_progress":
 		return h.ValidateRequiredParams(options, []string{"operation_id"})
-	case "get_context", "get_patterns", "get_threads", "search_explained", "list_aliases":
+	case "get_chunks":
+		return h.ValidateRequiredParams(options, []string{"chunk_ids"})
+	case "get_context", "get_patterns", "get_threads", "search_explained", "list_aliases", "list_relation_types":
 		return nil // Only repository required
 	default:
 		return NewValidationError("operation", "unsupported operation", operation)
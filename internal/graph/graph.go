@@ -0,0 +1,490 @@
+// Package graph answers typed traversal queries over the memory relationship
+// graph: from a start chunk, follow relationships of chosen types in a chosen
+// direction, breadth- or depth-first, up to a depth, and return the reached
+// nodes and edges for visualization with each node's path scored by the
+// cumulative confidence of its relationships.
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"lerian-mcp-memory/pkg/types"
+)
+
+// Strategy is the order a traversal visits nodes in
+type Strategy string
+
+const (
+	// StrategyBFS visits nodes level by level; each node is reached by its
+	// highest-scoring path among the shortest ones
+	StrategyBFS Strategy = "bfs"
+	// StrategyDFS follows the most confident relationship as deep as it goes
+	// before backtracking; each node is reached by the first path found
+	StrategyDFS Strategy = "dfs"
+)
+
+// Direction selects which relationships of a node a traversal follows
+type Direction string
+
+const (
+	// DirectionOutgoing follows relationships from their source to their target
+	DirectionOutgoing Direction = "outgoing"
+	// DirectionIncoming follows relationships from their target back to their source
+	DirectionIncoming Direction = "incoming"
+	// DirectionBoth follows relationships either way
+	DirectionBoth Direction = "both"
+)
+
+const (
+	// DefaultMaxDepth is the depth of a query that sets none
+	DefaultMaxDepth = 2
+	// MaxDepth bounds the depth of a query
+	MaxDepth = 6
+	// DefaultMaxNodes is the node budget of a query that sets none
+	DefaultMaxNodes = 100
+	// MaxNodes bounds the node budget of a query
+	MaxNodes = 500
+	// DefaultMinConfidence is the weakest relationship a query follows by default
+	DefaultMinConfidence = 0.5
+	// DefaultMaxPaths is the number of paths a query returns by default
+	DefaultMaxPaths = 20
+)
+
+// Query is a typed graph traversal
+type Query struct {
+	Start         string               `json:"start_chunk_id"`
+	RelationTypes []types.RelationType `json:"relation_types,omitempty"`
+	Direction     Direction            `json:"direction"`
+	Strategy      Strategy             `json:"strategy"`
+	MaxDepth      int                  `json:"max_depth"`
+	MinConfidence float64              `json:"min_confidence"`
+	// Repository, when set, keeps the traversal inside one repository
+	Repository string `json:"repository,omitempty"`
+	MaxNodes   int    `json:"max_nodes"`
+	MaxPaths   int    `json:"max_paths"`
+}
+
+// Normalize fills in defaults and validates the query
+func (q *Query) Normalize() error {
+	if q.Start == "" {
+		return errors.New("start chunk is required")
+	}
+	switch q.Direction {
+	case "":
+		q.Direction = DirectionOutgoing
+	case DirectionOutgoing, DirectionIncoming, DirectionBoth:
+	default:
+		return fmt.Errorf("invalid direction %q: must be %s, %s or %s", q.Direction, DirectionOutgoing, DirectionIncoming, DirectionBoth)
+	}
+	switch q.Strategy {
+	case "":
+		q.Strategy = StrategyBFS
+	case StrategyBFS, StrategyDFS:
+	default:
+		return fmt.Errorf("invalid strategy %q: must be %s or %s", q.Strategy, StrategyBFS, StrategyDFS)
+	}
+	if q.MaxDepth == 0 {
+		q.MaxDepth = DefaultMaxDepth
+	}
+	if q.MaxDepth < 0 || q.MaxDepth > MaxDepth {
+		return fmt.Errorf("max depth must be between 1 and %d, got %d", MaxDepth, q.MaxDepth)
+	}
+	if q.MinConfidence < 0 || q.MinConfidence > 1 {
+		return fmt.Errorf("min confidence must be between 0 and 1, got %g", q.MinConfidence)
+	}
+	if q.MaxNodes <= 0 {
+		q.MaxNodes = DefaultMaxNodes
+	}
+	q.MaxNodes = min(q.MaxNodes, MaxNodes)
+	if q.MaxPaths <= 0 {
+		q.MaxPaths = DefaultMaxPaths
+	}
+	return nil
+}
+
+// follows reports whether the query follows relationships of a type
+func (q *Query) follows(relationType types.RelationType) bool {
+	if len(q.RelationTypes) == 0 {
+		return true
+	}
+	for _, t := range q.RelationTypes {
+		if t == relationType {
+			return true
+		}
+	}
+	return false
+}
+
+// Node is a chunk reached by a traversal
+type Node struct {
+	ID         string          `json:"id"`
+	Label      string          `json:"label"`
+	Type       types.ChunkType `json:"type"`
+	Repository string          `json:"repository,omitempty"`
+	Depth      int             `json:"depth"`
+	// Score is the cumulative confidence of the path the node was reached by
+	Score  float64 `json:"score"`
+	Degree int     `json:"degree"`
+}
+
+// Edge is a relationship between two reached nodes, in its stored direction
+type Edge struct {
+	ID         string             `json:"id"`
+	Source     string             `json:"source"`
+	Target     string             `json:"target"`
+	Type       types.RelationType `json:"type"`
+	Confidence float64            `json:"confidence"`
+}
+
+// Path is how a traversal reached a node from the start
+type Path struct {
+	ChunkIDs      []string             `json:"chunk_ids"`
+	RelationTypes []types.RelationType `json:"relation_types"`
+	Depth         int                  `json:"depth"`
+	// Score multiplies the confidences of the path's relationships
+	Score float64 `json:"score"`
+}
+
+// Result is the subgraph a traversal reached. Nodes are in visiting order,
+// starting with the start chunk; paths are best scoring first.
+type Result struct {
+	Query     Query  `json:"query"`
+	Nodes     []Node `json:"nodes"`
+	Edges     []Edge `json:"edges"`
+	Paths     []Path `json:"paths"`
+	Truncated bool   `json:"truncated"`
+}
+
+// Store is the part of the vector store a traversal reads
+type Store interface {
+	GetRelationships(ctx context.Context, query *types.RelationshipQuery) ([]types.RelationshipResult, error)
+	GetByIDs(ctx context.Context, ids []string) ([]types.ConversationChunk, error)
+}
+
+// step is a relationship a traversal may take from a node
+type step struct {
+	relationship types.MemoryRelationship
+	to           string
+}
+
+// visit records how a node was reached
+type visit struct {
+	chunk  types.ConversationChunk
+	depth  int
+	score  float64
+	parent string
+	via    types.RelationType
+}
+
+// traversal holds the state of one query
+type traversal struct {
+	ctx     context.Context
+	store   Store
+	query   *Query
+	visited map[string]*visit
+	order   []string
+	edges   map[string]types.MemoryRelationship
+	steps   map[string][]step
+	full    bool
+}
+
+// Traverse runs a query against the store
+func Traverse(ctx context.Context, store Store, query Query) (*Result, error) {
+	if err := query.Normalize(); err != nil {
+		return nil, err
+	}
+
+	t := &traversal{
+		ctx:     ctx,
+		store:   store,
+		query:   &query,
+		visited: make(map[string]*visit),
+		edges:   make(map[string]types.MemoryRelationship),
+		steps:   make(map[string][]step),
+	}
+	start, err := t.load([]string{query.Start})
+	if err != nil {
+		return nil, err
+	}
+	if len(start) == 0 {
+		return nil, fmt.Errorf("chunk not found: %s", query.Start)
+	}
+	t.add(query.Start, &visit{chunk: start[query.Start], score: 1})
+
+	if query.Strategy == StrategyDFS {
+		err = t.depthFirst(query.Start)
+	} else {
+		err = t.breadthFirst()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t.result(), nil
+}
+
+// breadthFirst expands the graph one level at a time. Among the paths that
+// reach a node on its level, the most confident one is kept.
+func (t *traversal) breadthFirst() error {
+	frontier := []string{t.query.Start}
+	for depth := 1; depth <= t.query.MaxDepth && len(frontier) > 0 && !t.full; depth++ {
+		candidates := make(map[string]*visit)
+		var discovered []string
+		for _, from := range frontier {
+			steps, err := t.stepsFrom(from)
+			if err != nil {
+				return err
+			}
+			for _, s := range steps {
+				if _, seen := t.visited[s.to]; seen {
+					continue
+				}
+				score := t.visited[from].score * s.relationship.Confidence
+				if existing, ok := candidates[s.to]; ok {
+					if score > existing.score {
+						existing.score, existing.parent, existing.via = score, from, s.relationship.RelationType
+					}
+					continue
+				}
+				candidates[s.to] = &visit{depth: depth, score: score, parent: from, via: s.relationship.RelationType}
+				discovered = append(discovered, s.to)
+			}
+		}
+
+		chunks, err := t.load(discovered)
+		if err != nil {
+			return err
+		}
+		frontier = frontier[:0]
+		for _, id := range discovered {
+			chunk, ok := chunks[id]
+			if !ok {
+				continue
+			}
+			if t.full = len(t.order) >= t.query.MaxNodes; t.full {
+				break
+			}
+			candidates[id].chunk = chunk
+			t.add(id, candidates[id])
+			frontier = append(frontier, id)
+		}
+	}
+	return nil
+}
+
+// depthFirst follows the most confident relationships first, as deep as the
+// query allows
+func (t *traversal) depthFirst(from string) error {
+	current := t.visited[from]
+	if current.depth >= t.query.MaxDepth {
+		return nil
+	}
+	steps, err := t.stepsFrom(from)
+	if err != nil {
+		return err
+	}
+	for _, s := range steps {
+		if _, seen := t.visited[s.to]; seen {
+			continue
+		}
+		if t.full = len(t.order) >= t.query.MaxNodes; t.full {
+			return nil
+		}
+		chunks, err := t.load([]string{s.to})
+		if err != nil {
+			return err
+		}
+		chunk, ok := chunks[s.to]
+		if !ok {
+			continue
+		}
+		t.add(s.to, &visit{
+			chunk:  chunk,
+			depth:  current.depth + 1,
+			score:  current.score * s.relationship.Confidence,
+			parent: from,
+			via:    s.relationship.RelationType,
+		})
+		if err := t.depthFirst(s.to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add marks a node visited
+func (t *traversal) add(id string, v *visit) {
+	t.visited[id] = v
+	t.order = append(t.order, id)
+}
+
+// stepsFrom returns the relationships the query follows from a node, most
+// confident first. Every relationship seen is kept as a candidate edge.
+func (t *traversal) stepsFrom(id string) ([]step, error) {
+	if steps, ok := t.steps[id]; ok {
+		return steps, nil
+	}
+	if err := t.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	query := types.NewRelationshipQuery(id)
+	query.Direction = string(t.query.Direction)
+	query.RelationTypes = t.query.RelationTypes
+	query.MinConfidence = t.query.MinConfidence
+	query.MaxDepth = 1
+	query.IncludeChunks = false
+	query.Limit = 0
+	results, err := t.store.GetRelationships(t.ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read relationships of %s: %w", id, err)
+	}
+
+	steps := make([]step, 0, len(results))
+	for i := range results {
+		rel := results[i].Relationship
+		// Stores may not apply every filter; check them again
+		if !t.query.follows(rel.RelationType) || rel.Confidence < t.query.MinConfidence {
+			continue
+		}
+		var to string
+		switch {
+		case rel.SourceChunkID == id && t.query.Direction != DirectionIncoming:
+			to = rel.TargetChunkID
+		case rel.TargetChunkID == id && t.query.Direction != DirectionOutgoing:
+			to = rel.SourceChunkID
+		default:
+			continue
+		}
+		if to == id {
+			continue
+		}
+		t.edges[rel.ID] = rel
+		steps = append(steps, step{relationship: rel, to: to})
+	}
+	sort.SliceStable(steps, func(i, j int) bool {
+		if steps[i].relationship.Confidence != steps[j].relationship.Confidence {
+			return steps[i].relationship.Confidence > steps[j].relationship.Confidence
+		}
+		return steps[i].to < steps[j].to
+	})
+	t.steps[id] = steps
+	return steps, nil
+}
+
+// load returns the live chunks of ids that the query may visit
+func (t *traversal) load(ids []string) (map[string]types.ConversationChunk, error) {
+	chunks := make(map[string]types.ConversationChunk, len(ids))
+	if len(ids) == 0 {
+		return chunks, nil
+	}
+	found, err := t.store.GetByIDs(t.ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chunks: %w", err)
+	}
+	for i := range found {
+		chunk := found[i]
+		if chunk.IsDeleted() {
+			continue
+		}
+		if t.query.Repository != "" && chunk.Metadata.Repository != t.query.Repository {
+			continue
+		}
+		chunks[chunk.ID] = chunk
+	}
+	return chunks, nil
+}
+
+// result assembles the reached subgraph
+func (t *traversal) result() *Result {
+	result := &Result{Query: *t.query, Truncated: t.full, Nodes: []Node{}, Edges: []Edge{}, Paths: []Path{}}
+
+	degrees := make(map[string]int)
+	for id := range t.edges {
+		rel := t.edges[id]
+		if t.visited[rel.SourceChunkID] == nil || t.visited[rel.TargetChunkID] == nil {
+			continue
+		}
+		result.Edges = append(result.Edges, Edge{
+			ID:         rel.ID,
+			Source:     rel.SourceChunkID,
+			Target:     rel.TargetChunkID,
+			Type:       rel.RelationType,
+			Confidence: rel.Confidence,
+		})
+		degrees[rel.SourceChunkID]++
+		degrees[rel.TargetChunkID]++
+	}
+	sort.Slice(result.Edges, func(i, j int) bool {
+		if result.Edges[i].Confidence != result.Edges[j].Confidence {
+			return result.Edges[i].Confidence > result.Edges[j].Confidence
+		}
+		return result.Edges[i].ID < result.Edges[j].ID
+	})
+
+	for _, id := range t.order {
+		v := t.visited[id]
+		result.Nodes = append(result.Nodes, Node{
+			ID:         id,
+			Label:      label(&v.chunk),
+			Type:       v.chunk.Type,
+			Repository: v.chunk.Metadata.Repository,
+			Depth:      v.depth,
+			Score:      v.score,
+			Degree:     degrees[id],
+		})
+		if id != t.query.Start {
+			result.Paths = append(result.Paths, t.pathTo(id))
+		}
+	}
+	sort.SliceStable(result.Paths, func(i, j int) bool {
+		if result.Paths[i].Score != result.Paths[j].Score {
+			return result.Paths[i].Score > result.Paths[j].Score
+		}
+		return result.Paths[i].Depth < result.Paths[j].Depth
+	})
+	if len(result.Paths) > t.query.MaxPaths {
+		result.Paths = result.Paths[:t.query.MaxPaths]
+	}
+	return result
+}
+
+// pathTo walks parents back from a node to the start
+func (t *traversal) pathTo(id string) Path {
+	v := t.visited[id]
+	path := Path{Depth: v.depth, Score: v.score}
+	for current := id; ; {
+		node := t.visited[current]
+		path.ChunkIDs = append(path.ChunkIDs, current)
+		if node.parent == "" {
+			break
+		}
+		path.RelationTypes = append(path.RelationTypes, node.via)
+		current = node.parent
+	}
+	for i, j := 0, len(path.ChunkIDs)-1; i < j; i, j = i+1, j-1 {
+		path.ChunkIDs[i], path.ChunkIDs[j] = path.ChunkIDs[j], path.ChunkIDs[i]
+	}
+	for i, j := 0, len(path.RelationTypes)-1; i < j; i, j = i+1, j-1 {
+		path.RelationTypes[i], path.RelationTypes[j] = path.RelationTypes[j], path.RelationTypes[i]
+	}
+	return path
+}
+
+// maxLabelRunes shortens the labels of chunks without a summary
+const maxLabelRunes = 80
+
+// label names a node for display: its summary, or the start of its content
+func label(chunk *types.ConversationChunk) string {
+	text := chunk.Summary
+	if text == "" {
+		text = chunk.Content
+	}
+	runes := []rune(text)
+	if len(runes) > maxLabelRunes {
+		return string(runes[:maxLabelRunes-3]) + "..."
+	}
+	return text
+}
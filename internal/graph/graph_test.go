@@ -0,0 +1,169 @@
+package graph
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testGraph is a problem with two routes to a follow-up:
+//
+//	reference -references-> problem -led_to .9-> solution -led_to .5-> followup
+//	                        problem -related_to .6-> discussion -led_to .95-> followup
+//	                        problem -related_to .3-> weak
+type testGraph struct {
+	store                                                           *storage.KeywordStore
+	problem, solution, discussion, followup, reference, weak, other string
+}
+
+func newTestGraph(t *testing.T) *testGraph {
+	t.Helper()
+	ctx := context.Background()
+	g := &testGraph{store: storage.NewKeywordStore("")}
+
+	add := func(repository, content string, chunkType types.ChunkType) string {
+		chunk, err := types.NewConversationChunk("session-1", content, chunkType, &types.ChunkMetadata{
+			Repository: repository,
+			Outcome:    types.OutcomeSuccess,
+			Difficulty: types.DifficultySimple,
+		})
+		require.NoError(t, err)
+		require.NoError(t, g.store.Store(ctx, chunk))
+		return chunk.ID
+	}
+	link := func(source, target string, relationType types.RelationType, confidence float64) {
+		_, err := g.store.StoreRelationship(ctx, source, target, relationType, confidence, types.ConfidenceExplicit)
+		require.NoError(t, err)
+	}
+
+	g.problem = add("repo-a", "Checkout times out", types.ChunkTypeProblem)
+	g.solution = add("repo-a", "Raise the provider timeout", types.ChunkTypeSolution)
+	g.discussion = add("repo-a", "Discussed idempotent retries", types.ChunkTypeDiscussion)
+	g.followup = add("repo-a", "Retries shipped behind a flag", types.ChunkTypeCodeChange)
+	g.reference = add("repo-a", "Incident review", types.ChunkTypeDiscussion)
+	g.weak = add("repo-a", "Unrelated musing", types.ChunkTypeDiscussion)
+	g.other = add("repo-b", "Same timeout in billing", types.ChunkTypeProblem)
+
+	link(g.problem, g.solution, types.RelationLedTo, 0.9)
+	link(g.problem, g.discussion, types.RelationRelatedTo, 0.6)
+	link(g.solution, g.followup, types.RelationLedTo, 0.5)
+	link(g.discussion, g.followup, types.RelationLedTo, 0.95)
+	link(g.reference, g.problem, types.RelationReferences, 0.7)
+	link(g.problem, g.weak, types.RelationRelatedTo, 0.3)
+	link(g.solution, g.other, types.RelationRelatedTo, 0.8)
+	return g
+}
+
+func nodeIDs(result *Result) []string {
+	ids := make([]string, 0, len(result.Nodes))
+	for _, node := range result.Nodes {
+		ids = append(ids, node.ID)
+	}
+	return ids
+}
+
+func nodeByID(t *testing.T, result *Result, id string) Node {
+	t.Helper()
+	for _, node := range result.Nodes {
+		if node.ID == id {
+			return node
+		}
+	}
+	t.Fatalf("node %s not reached", id)
+	return Node{}
+}
+
+func TestTraverseBreadthFirst(t *testing.T) {
+	g := newTestGraph(t)
+
+	result, err := Traverse(context.Background(), g.store, Query{Start: g.problem, MinConfidence: 0.5, Repository: "repo-a"})
+	require.NoError(t, err)
+	assert.Equal(t, StrategyBFS, result.Query.Strategy)
+	assert.Equal(t, DirectionOutgoing, result.Query.Direction)
+	assert.Equal(t, []string{g.problem, g.solution, g.discussion, g.followup}, nodeIDs(result), "the weak relationship, the incoming reference and the other repository are left out")
+	assert.Len(t, result.Edges, 4)
+	assert.False(t, result.Truncated)
+
+	// The follow-up is reached through the discussion: .6 * .95 beats .9 * .5
+	followup := nodeByID(t, result, g.followup)
+	assert.Equal(t, 2, followup.Depth)
+	assert.InDelta(t, 0.57, followup.Score, 1e-9)
+	assert.Equal(t, 2, followup.Degree)
+	assert.Equal(t, "Retries shipped behind a flag", followup.Label)
+
+	require.Len(t, result.Paths, 3)
+	assert.Equal(t, []string{g.problem, g.solution}, result.Paths[0].ChunkIDs, "paths are best scoring first")
+	assert.Equal(t, []string{g.problem, g.discussion, g.followup}, result.Paths[2].ChunkIDs)
+	assert.Equal(t, []types.RelationType{types.RelationRelatedTo, types.RelationLedTo}, result.Paths[2].RelationTypes)
+}
+
+func TestTraverseDepthFirst(t *testing.T) {
+	g := newTestGraph(t)
+
+	result, err := Traverse(context.Background(), g.store, Query{Start: g.problem, Strategy: StrategyDFS, MinConfidence: 0.5, Repository: "repo-a"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{g.problem, g.solution, g.followup, g.discussion}, nodeIDs(result), "the most confident branch is followed to the end first")
+
+	followup := nodeByID(t, result, g.followup)
+	assert.InDelta(t, 0.45, followup.Score, 1e-9, "the first path found reaches the follow-up")
+}
+
+func TestTraverseFilters(t *testing.T) {
+	ctx := context.Background()
+	g := newTestGraph(t)
+
+	result, err := Traverse(ctx, g.store, Query{Start: g.problem, RelationTypes: []types.RelationType{types.RelationLedTo}, MinConfidence: 0.5})
+	require.NoError(t, err)
+	assert.Equal(t, []string{g.problem, g.solution, g.followup}, nodeIDs(result))
+
+	result, err = Traverse(ctx, g.store, Query{Start: g.problem, Direction: DirectionIncoming, MinConfidence: 0.5})
+	require.NoError(t, err)
+	assert.Equal(t, []string{g.problem, g.reference}, nodeIDs(result))
+	assert.Equal(t, g.reference, result.Edges[0].Source, "edges keep their stored direction")
+
+	result, err = Traverse(ctx, g.store, Query{Start: g.problem, Direction: DirectionBoth, MaxDepth: 1, MinConfidence: 0.5})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{g.problem, g.solution, g.discussion, g.reference}, nodeIDs(result))
+
+	result, err = Traverse(ctx, g.store, Query{Start: g.problem, MinConfidence: 0.1, MaxNodes: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{g.problem, g.solution}, nodeIDs(result))
+	assert.True(t, result.Truncated)
+
+	// Trashed memories are not visited
+	solution, err := g.store.GetByID(ctx, g.solution)
+	require.NoError(t, err)
+	now := time.Now()
+	solution.Metadata.DeletedAt = &now
+	require.NoError(t, g.store.Update(ctx, solution))
+	result, err = Traverse(ctx, g.store, Query{Start: g.problem, MinConfidence: 0.5, Repository: "repo-a"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{g.problem, g.discussion, g.followup}, nodeIDs(result))
+}
+
+func TestQueryNormalize(t *testing.T) {
+	for name, query := range map[string]Query{
+		"no start":          {},
+		"unknown direction": {Start: "a", Direction: "sideways"},
+		"unknown strategy":  {Start: "a", Strategy: "random"},
+		"too deep":          {Start: "a", MaxDepth: MaxDepth + 1},
+		"bad confidence":    {Start: "a", MinConfidence: 1.5},
+	} {
+		assert.Error(t, query.Normalize(), name)
+	}
+
+	query := Query{Start: "a", MaxNodes: MaxNodes * 2}
+	require.NoError(t, query.Normalize())
+	assert.Equal(t, DefaultMaxDepth, query.MaxDepth)
+	assert.Equal(t, MaxNodes, query.MaxNodes)
+	assert.Equal(t, DefaultMaxPaths, query.MaxPaths)
+
+	_, err := Traverse(context.Background(), storage.NewKeywordStore(""), Query{Start: "missing"})
+	assert.ErrorContains(t, err, "chunk not found")
+}
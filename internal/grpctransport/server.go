@@ -0,0 +1,513 @@
+// Package grpctransport serves MCP over gRPC, so the server can run behind
+// standard gRPC load balancers. Each JSON-RPC message travels as an
+// mcp.v1.Message (api/proto/mcp/v1/mcp.proto): a unary Call serves one
+// request or batch, and a Connect stream carries a session in both
+// directions, including notifications and server-to-client requests such as
+// sampling. A call's deadline, or a streamed request's timeout_ms, becomes
+// the deadline of the request's context. The standard gRPC health service
+// reports the server serving until it shuts down.
+package grpctransport
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"lerian-mcp-memory/pkg/mcp/mcppb"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// keepaliveTime pings idle connections so proxies keep them open
+	keepaliveTime = 30 * time.Second
+
+	// keepaliveTimeout closes connections whose pings go unanswered
+	keepaliveTimeout = 10 * time.Second
+
+	// minClientPingInterval is the most often clients may ping
+	minClientPingInterval = 10 * time.Second
+
+	// outboxSize is how many messages a connection queues for its stream
+	outboxSize = 64
+)
+
+// errShuttingDown refuses calls and streams once Shutdown began
+var errShuttingDown = status.Error(codes.Unavailable, "server is shutting down")
+
+// RPCHandler serves a JSON-RPC request received on a connection. The
+// context carries the request's deadline and is cancelled when the
+// connection closes.
+type RPCHandler func(ctx context.Context, conn *Conn, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse
+
+// BatchHandler serves a JSON-RPC batch received on a connection and
+// returns the message to send back, or nil when nothing is owed
+type BatchHandler func(ctx context.Context, conn *Conn, data []byte) interface{}
+
+// ResponseHandler takes a JSON-RPC response from a client that may answer a
+// request the server sent it, reporting whether it did
+type ResponseHandler func(conn *Conn, data []byte) bool
+
+// Conn is a client connection: a Connect stream, or a single Call that
+// cannot carry notifications or server requests
+type Conn struct {
+	ID string
+
+	// outbox queues messages for the stream's writer; nil for a Call
+	outbox chan *mcppb.Message
+
+	// requests tracks requests of the stream being served
+	requests sync.WaitGroup
+
+	// closing is closed when the server shuts down
+	closing   chan struct{}
+	closeOnce sync.Once
+
+	// done is closed once the connection ended
+	done chan struct{}
+}
+
+// Server serves the MCP service over gRPC
+type Server struct {
+	grpcServer *grpc.Server
+	health     *health.Server
+
+	mutex    sync.RWMutex
+	conns    map[*Conn]bool
+	shutdown bool
+
+	rpcHandler        RPCHandler
+	batchHandler      BatchHandler
+	responseHandler   ResponseHandler
+	disconnectHandler func(*Conn)
+}
+
+// NewServer creates a gRPC server for MCP. opts, such as credentials from
+// ServerCredentials or a message size limit, add to the server's keepalive
+// settings.
+func NewServer(opts ...grpc.ServerOption) *Server {
+	s := &Server{
+		health: health.NewServer(),
+		conns:  make(map[*Conn]bool),
+	}
+	opts = append([]grpc.ServerOption{
+		grpc.KeepaliveParams(keepalive.ServerParameters{Time: keepaliveTime, Timeout: keepaliveTimeout}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{MinTime: minClientPingInterval, PermitWithoutStream: true}),
+	}, opts...)
+	s.grpcServer = grpc.NewServer(opts...)
+	mcppb.RegisterMCPServer(s.grpcServer, &service{server: s})
+	healthpb.RegisterHealthServer(s.grpcServer, s.health)
+	s.health.SetServingStatus(mcppb.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	return s
+}
+
+// ServerCredentials loads the server's TLS certificate. With clientCAFile,
+// clients must present a certificate signed by one of its CAs (mTLS).
+func ServerCredentials(certFile, keyFile, clientCAFile string) (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load gRPC TLS certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if clientCAFile != "" {
+		pem, err := os.ReadFile(clientCAFile) // #nosec G304 -- path comes from server configuration
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gRPC client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in gRPC client CA %s", clientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// Serve accepts connections on lis until the server shuts down
+func (s *Server) Serve(lis net.Listener) error {
+	return s.grpcServer.Serve(lis)
+}
+
+// Shutdown stops the server gracefully. The health service reports it not
+// serving and new calls and streams are refused; streams end once their
+// requests in flight were answered and their queued messages sent. If ctx
+// is done first, remaining connections are closed.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mutex.Lock()
+	s.shutdown = true
+	conns := make([]*Conn, 0, len(s.conns))
+	for conn := range s.conns {
+		conns = append(conns, conn)
+	}
+	s.mutex.Unlock()
+
+	s.health.Shutdown()
+	for _, conn := range conns {
+		conn.close()
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		return fmt.Errorf("failed to shut down gRPC server: %w", ctx.Err())
+	}
+}
+
+// BroadcastNotification queues a JSON-RPC notification, such as
+// notifications/tools/list_changed, for every connected stream
+func (s *Server) BroadcastNotification(method string, params interface{}) error {
+	s.mutex.RLock()
+	conns := make([]*Conn, 0, len(s.conns))
+	for conn := range s.conns {
+		conns = append(conns, conn)
+	}
+	s.mutex.RUnlock()
+
+	var errs []error
+	for _, conn := range conns {
+		if err := conn.SendNotification(method, params); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SetRPCHandler enables JSON-RPC requests
+func (s *Server) SetRPCHandler(handler RPCHandler) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.rpcHandler = handler
+}
+
+// SetBatchHandler enables JSON-RPC batches
+func (s *Server) SetBatchHandler(handler BatchHandler) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.batchHandler = handler
+}
+
+// SetResponseHandler lets clients answer requests the server sends them
+func (s *Server) SetResponseHandler(handler ResponseHandler) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.responseHandler = handler
+}
+
+// SetDisconnectHandler calls handler with each connection that ended,
+// streams and calls alike
+func (s *Server) SetDisconnectHandler(handler func(*Conn)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.disconnectHandler = handler
+}
+
+// GetConnectionCount returns the number of open streams
+func (s *Server) GetConnectionCount() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.conns)
+}
+
+// handlers returns the handlers set on the server
+func (s *Server) handlers() (RPCHandler, BatchHandler, ResponseHandler) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.rpcHandler, s.batchHandler, s.responseHandler
+}
+
+// open registers a connection, failing once Shutdown began. Streams get an
+// outbox and are reached by broadcasts.
+func (s *Server) open(stream bool) (*Conn, error) {
+	conn := &Conn{
+		ID:      "grpc-" + uuid.New().String(),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.shutdown {
+		return nil, errShuttingDown
+	}
+	if stream {
+		conn.outbox = make(chan *mcppb.Message, outboxSize)
+		s.conns[conn] = true
+	}
+	return conn, nil
+}
+
+// end unregisters a connection and tells the disconnect handler
+func (s *Server) end(conn *Conn) {
+	s.mutex.Lock()
+	delete(s.conns, conn)
+	handler := s.disconnectHandler
+	s.mutex.Unlock()
+
+	close(conn.done)
+	if handler != nil {
+		handler(conn)
+	}
+}
+
+// handle serves a request, batch or notification and returns the reply
+// owed, or nil when nothing is owed
+func (s *Server) handle(ctx context.Context, conn *Conn, message *mcppb.Message) *mcppb.Message {
+	rpcHandler, batchHandler, _ := s.handlers()
+	data, err := message.JSONRPC()
+	if err != nil {
+		return errorReply(message.GetId(), protocol.InvalidRequest, "Invalid request: "+err.Error())
+	}
+
+	if len(message.GetBatch()) > 0 {
+		if batchHandler == nil {
+			return errorReply(nil, protocol.InvalidRequest, "JSON-RPC batches are not enabled on this server")
+		}
+		return reply(batchHandler(ctx, conn, data))
+	}
+
+	var req protocol.JSONRPCRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return errorReply(message.GetId(), protocol.InvalidRequest, "Invalid request: "+err.Error())
+	}
+	if rpcHandler == nil {
+		if req.ID == nil {
+			return nil
+		}
+		return errorReply(message.GetId(), protocol.MethodNotFound, "JSON-RPC is not enabled on this server")
+	}
+	resp := rpcHandler(ctx, conn, &req)
+	if req.ID == nil || resp == nil {
+		return nil
+	}
+	return reply(resp)
+}
+
+// reply converts a handler's response to a message
+func reply(resp interface{}) *mcppb.Message {
+	if resp == nil {
+		return nil
+	}
+	message, err := mcppb.MessageOf(resp)
+	if err != nil {
+		return errorReply(nil, protocol.InternalError, err.Error())
+	}
+	return message
+}
+
+// errorReply builds a JSON-RPC error response
+func errorReply(id []byte, code int, message string) *mcppb.Message {
+	return &mcppb.Message{Id: id, Error: &mcppb.Error{Code: int32(code), Message: message}} // #nosec G115 -- JSON-RPC codes fit in int32
+}
+
+// service implements the MCP gRPC service for a Server
+type service struct {
+	server *Server
+}
+
+// Call serves one request or batch within the call's deadline. Calls
+// without a reply owed, such as notifications, get an empty message.
+func (svc *service) Call(ctx context.Context, message *mcppb.Message) (*mcppb.Message, error) {
+	if message.GetMethod() == "" && len(message.GetBatch()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Call takes requests; answer server requests on a Connect stream")
+	}
+	conn, err := svc.server.open(false)
+	if err != nil {
+		return nil, err
+	}
+	defer svc.server.end(conn)
+
+	if resp := svc.server.handle(ctx, conn, message); resp != nil {
+		return resp, nil
+	}
+	return &mcppb.Message{}, nil
+}
+
+// Connect serves a stream's requests concurrently, each within its
+// timeout_ms and the stream's deadline, and writes replies, notifications
+// and server requests back on the stream. When the client stops sending or
+// the server shuts down, the stream ends once requests in flight were
+// answered.
+func (svc *service) Connect(stream grpc.BidiStreamingServer[mcppb.Message, mcppb.Message]) error {
+	s := svc.server
+	conn, err := s.open(true)
+	if err != nil {
+		return err
+	}
+	defer s.end(conn)
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	// The writer owns stream.Send, so slow clients only fill their outbox
+	flushed := make(chan struct{})
+	written := make(chan error, 1)
+	go func() {
+		written <- conn.write(stream, flushed)
+	}()
+
+	received := make(chan *mcppb.Message)
+	failed := make(chan error, 1)
+	go func() {
+		for {
+			message, err := stream.Recv()
+			if err != nil {
+				failed <- err
+				return
+			}
+			select {
+			case received <- message:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var result error
+serve:
+	for {
+		select {
+		case message := <-received:
+			conn.requests.Add(1)
+			go func() {
+				defer conn.requests.Done()
+				s.serve(ctx, conn, message)
+			}()
+		case err := <-failed:
+			if !errors.Is(err, io.EOF) {
+				result = err
+			}
+			break serve
+		case err := <-written:
+			cancel()
+			conn.requests.Wait()
+			return err
+		case <-conn.closing:
+			break serve
+		}
+	}
+
+	conn.requests.Wait()
+	close(flushed)
+	if err := <-written; err != nil && result == nil {
+		result = err
+	}
+	return result
+}
+
+// serve handles a message received on a stream: responses go to the
+// response handler, requests and batches are served and their replies queued
+func (s *Server) serve(ctx context.Context, conn *Conn, message *mcppb.Message) {
+	if message.GetMethod() == "" && len(message.GetBatch()) == 0 {
+		_, _, responseHandler := s.handlers()
+		data, err := message.JSONRPC()
+		if err != nil || responseHandler == nil || !responseHandler(conn, data) {
+			log.Printf("Warning: Dropping unexpected response on gRPC connection %s", conn.ID)
+		}
+		return
+	}
+
+	if timeout := message.GetTimeoutMs(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*time.Millisecond)
+		defer cancel()
+	}
+	if resp := s.handle(ctx, conn, message); resp != nil {
+		if err := conn.send(resp); err != nil {
+			log.Printf("Warning: Dropping response on gRPC connection %s: %v", conn.ID, err)
+		}
+	}
+}
+
+// write sends queued messages on the stream until flushed is closed, then
+// sends what is still queued and returns
+func (c *Conn) write(stream grpc.BidiStreamingServer[mcppb.Message, mcppb.Message], flushed <-chan struct{}) error {
+	for {
+		select {
+		case message := <-c.outbox:
+			if err := stream.Send(message); err != nil {
+				return err
+			}
+		case <-flushed:
+			for {
+				select {
+				case message := <-c.outbox:
+					if err := stream.Send(message); err != nil {
+						return err
+					}
+				default:
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// send queues a message for the stream
+func (c *Conn) send(message *mcppb.Message) error {
+	if c.outbox == nil {
+		return fmt.Errorf("gRPC connection %s is a single call; use Connect for notifications and server requests", c.ID)
+	}
+	select {
+	case <-c.done:
+		return fmt.Errorf("gRPC connection %s is closed", c.ID)
+	default:
+	}
+	select {
+	case c.outbox <- message:
+		return nil
+	default:
+		return fmt.Errorf("message queue full for gRPC connection %s", c.ID)
+	}
+}
+
+// SendNotification queues a JSON-RPC notification, such as
+// notifications/progress for a request in flight
+func (c *Conn) SendNotification(method string, params interface{}) error {
+	message, err := mcppb.MessageOf(&protocol.JSONRPCRequest{JSONRPC: "2.0", Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	return c.send(message)
+}
+
+// SendRequest queues a server-to-client JSON-RPC request, such as
+// sampling/createMessage
+func (c *Conn) SendRequest(req *protocol.JSONRPCRequest) error {
+	message, err := mcppb.MessageOf(req)
+	if err != nil {
+		return err
+	}
+	return c.send(message)
+}
+
+// close asks the connection's stream to end
+func (c *Conn) close() {
+	c.closeOnce.Do(func() { close(c.closing) })
+}
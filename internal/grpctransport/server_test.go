@@ -0,0 +1,193 @@
+package grpctransport
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/pkg/mcp/mcppb"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// startTestServer serves s over an in-memory listener and returns a client connection
+func startTestServer(t *testing.T, s *Server) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(func() { _ = s.Shutdown(context.Background()) })
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// request builds a request message
+func request(t *testing.T, id int, method string, params interface{}) *mcppb.Message {
+	t.Helper()
+	message, err := mcppb.MessageOf(&protocol.JSONRPCRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	require.NoError(t, err)
+	return message
+}
+
+// echo answers requests with their method, the deadline they were served with
+// and the connection they arrived on
+func echo(ctx context.Context, conn *Conn, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+	result := map[string]interface{}{"method": req.Method, "connection": conn.ID}
+	if deadline, ok := ctx.Deadline(); ok {
+		result["deadline_ms"] = time.Until(deadline).Milliseconds()
+	}
+	return &protocol.JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+func TestServer_Call(t *testing.T) {
+	s := NewServer()
+	s.SetRPCHandler(echo)
+	disconnected := make(chan string, 4)
+	s.SetDisconnectHandler(func(conn *Conn) { disconnected <- conn.ID })
+	client := mcppb.NewMCPClient(startTestServer(t, s))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := client.Call(ctx, request(t, 1, "tools/list", nil))
+	require.NoError(t, err)
+	assert.JSONEq(t, "1", string(resp.GetId()))
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(resp.GetResult(), &result))
+	assert.Equal(t, "tools/list", result["method"])
+	assert.Greater(t, result["deadline_ms"], float64(1000), "the call's deadline reaches the handler")
+	assert.Equal(t, result["connection"], <-disconnected, "a call's connection ends with it")
+
+	resp, err = client.Call(ctx, &mcppb.Message{Method: "notifications/initialized"})
+	require.NoError(t, err)
+	assert.Empty(t, resp.GetResult(), "notifications owe no reply")
+
+	_, err = client.Call(ctx, &mcppb.Message{Id: []byte("1"), Result: []byte("{}")})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_Connect(t *testing.T) {
+	s := NewServer()
+	answered := make(chan string, 1)
+	s.SetRPCHandler(func(ctx context.Context, conn *Conn, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+		assert.NoError(t, conn.SendNotification("notifications/progress", map[string]interface{}{"progress": 1}))
+		assert.NoError(t, conn.SendRequest(&protocol.JSONRPCRequest{JSONRPC: "2.0", ID: "s-1", Method: "sampling/createMessage"}))
+		return echo(ctx, conn, req)
+	})
+	s.SetResponseHandler(func(_ *Conn, data []byte) bool {
+		answered <- string(data)
+		return true
+	})
+	client := mcppb.NewMCPClient(startTestServer(t, s))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.Connect(ctx)
+	require.NoError(t, err)
+
+	message := request(t, 7, "tools/call", map[string]interface{}{"name": "memory_read"})
+	message.TimeoutMs = 200
+	require.NoError(t, stream.Send(message))
+
+	methods := make([]string, 0, 3)
+	var result map[string]interface{}
+	for len(methods) < 3 {
+		got, err := stream.Recv()
+		require.NoError(t, err)
+		if got.GetMethod() == "sampling/createMessage" {
+			require.NoError(t, stream.Send(&mcppb.Message{Id: got.GetId(), Result: []byte(`{"content":"ok"}`)}))
+		}
+		if got.GetMethod() == "" {
+			require.NoError(t, json.Unmarshal(got.GetResult(), &result))
+		}
+		methods = append(methods, got.GetMethod())
+	}
+	assert.Equal(t, []string{"notifications/progress", "sampling/createMessage", ""}, methods)
+	assert.LessOrEqual(t, result["deadline_ms"], float64(200), "timeout_ms bounds the request")
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":"s-1","result":{"content":"ok"}}`, <-answered)
+
+	require.NoError(t, s.BroadcastNotification("notifications/tools/list_changed", struct{}{}))
+	got, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "notifications/tools/list_changed", got.GetMethod())
+
+	require.NoError(t, stream.CloseSend())
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestServer_Batch(t *testing.T) {
+	s := NewServer()
+	s.SetBatchHandler(func(_ context.Context, _ *Conn, data []byte) interface{} {
+		var batch []protocol.JSONRPCRequest
+		assert.NoError(t, json.Unmarshal(data, &batch))
+		responses := make([]protocol.JSONRPCResponse, 0, len(batch))
+		for _, req := range batch {
+			responses = append(responses, protocol.JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: req.Method})
+		}
+		return responses
+	})
+	client := mcppb.NewMCPClient(startTestServer(t, s))
+
+	resp, err := client.Call(context.Background(), &mcppb.Message{Batch: []*mcppb.Message{
+		request(t, 1, "ping", nil),
+		request(t, 2, "tools/list", nil),
+	}})
+	require.NoError(t, err)
+	require.Len(t, resp.GetBatch(), 2)
+	assert.Equal(t, `"tools/list"`, string(resp.GetBatch()[1].GetResult()))
+}
+
+func TestServer_Shutdown(t *testing.T) {
+	s := NewServer()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	s.SetRPCHandler(func(ctx context.Context, conn *Conn, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+		close(started)
+		<-release
+		return echo(ctx, conn, req)
+	})
+	cc := startTestServer(t, s)
+	client := mcppb.NewMCPClient(cc)
+	health := healthpb.NewHealthClient(cc)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	check, err := health.Check(ctx, &healthpb.HealthCheckRequest{Service: mcppb.ServiceName})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check.GetStatus())
+
+	stream, err := client.Connect(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(request(t, 1, "tools/call", nil)))
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- s.Shutdown(ctx) }()
+	require.Eventually(t, func() bool {
+		check, err := s.health.Check(ctx, &healthpb.HealthCheckRequest{Service: mcppb.ServiceName})
+		return err == nil && check.GetStatus() == healthpb.HealthCheckResponse_NOT_SERVING
+	}, time.Second, 10*time.Millisecond, "load balancers see the server draining")
+
+	close(release)
+	got, err := stream.Recv()
+	require.NoError(t, err, "requests in flight are answered before the stream ends")
+	assert.JSONEq(t, "1", string(got.GetId()))
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+	require.NoError(t, <-shutdown)
+}
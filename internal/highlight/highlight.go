@@ -0,0 +1,252 @@
+// Package highlight extracts matched-term snippets from chunk content for search results
+package highlight
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"lerian-mcp-memory/pkg/types"
+)
+
+const (
+	// DefaultContextSentences is the number of sentences kept on each side of the best passage
+	DefaultContextSentences = 1
+
+	// MaxContextSentences bounds the context window requested by clients
+	MaxContextSentences = 5
+
+	// MaxSnippetLength caps snippet size in bytes
+	MaxSnippetLength = 600
+
+	// minPrefixMatch is the shortest query term allowed to match longer words ("connect" -> "connections")
+	minPrefixMatch = 4
+
+	marker   = "**"
+	ellipsis = "…"
+)
+
+// stopWords are ignored when matching query terms
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"by": true, "for": true, "from": true, "how": true, "in": true, "is": true, "it": true,
+	"of": true, "on": true, "or": true, "that": true, "the": true, "to": true, "was": true,
+	"what": true, "when": true, "with": true,
+}
+
+// span is a byte range within a string
+type span struct {
+	start, end int
+}
+
+// Compute returns the best-matching passage of content for query with
+// contextSentences sentences of context on each side. Passages are ranked by
+// query-term overlap; when no term matches, as can happen for purely semantic
+// hits, the opening passage is returned without highlights.
+func Compute(content, query string, contextSentences int) *types.SearchHighlight {
+	if content == "" {
+		return nil
+	}
+	if contextSentences < 0 {
+		contextSentences = 0
+	}
+	if contextSentences > MaxContextSentences {
+		contextSentences = MaxContextSentences
+	}
+
+	terms := queryTerms(query)
+	sentences := splitSentences(content)
+	if len(sentences) == 0 {
+		return nil
+	}
+
+	best := bestSentence(content, sentences, terms)
+	first := max(best-contextSentences, 0)
+	last := min(best+contextSentences, len(sentences)-1)
+	window := span{sentences[first].start, sentences[last].end}
+
+	if window.end-window.start > MaxSnippetLength {
+		window = sentences[best]
+	}
+
+	snippet := content[window.start:window.end]
+	prefix, suffix := "", ""
+	if len(snippet) > MaxSnippetLength {
+		snippet, prefix, suffix = truncateAround(snippet, terms)
+	}
+	if window.start > sentences[0].start {
+		prefix = ellipsis
+	}
+	if window.end < sentences[len(sentences)-1].end {
+		suffix = ellipsis
+	}
+
+	matches, matched := findMatches(snippet, terms)
+
+	return &types.SearchHighlight{
+		Snippet:      prefix + snippet + suffix,
+		Highlighted:  prefix + mark(snippet, matches) + suffix,
+		MatchedTerms: matched,
+		Spans:        offsetSpans(matches, len(prefix)),
+	}
+}
+
+// queryTerms lowercases and deduplicates the meaningful words of a query
+func queryTerms(query string) []string {
+	seen := make(map[string]bool)
+	terms := make([]string, 0)
+	for _, word := range words(query) {
+		term := strings.ToLower(query[word.start:word.end])
+		if stopWords[term] || seen[term] {
+			continue
+		}
+		seen[term] = true
+		terms = append(terms, term)
+	}
+	return terms
+}
+
+// words returns the byte ranges of alphanumeric runs in text
+func words(text string) []span {
+	result := make([]span, 0)
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			result = append(result, span{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		result = append(result, span{start, len(text)})
+	}
+	return result
+}
+
+// splitSentences returns trimmed sentence ranges. Sentences end at . ! or ?
+// followed by whitespace, and at line breaks.
+func splitSentences(content string) []span {
+	sentences := make([]span, 0)
+	start := 0
+	emit := func(end int) {
+		s := span{start, end}
+		for s.start < s.end && isSpace(content[s.start]) {
+			s.start++
+		}
+		for s.end > s.start && isSpace(content[s.end-1]) {
+			s.end--
+		}
+		if s.end > s.start {
+			sentences = append(sentences, s)
+		}
+	}
+
+	for i := 0; i < len(content); i++ {
+		switch c := content[i]; {
+		case c == '\n':
+			emit(i)
+			start = i + 1
+		case (c == '.' || c == '!' || c == '?') && (i+1 == len(content) || isSpace(content[i+1])):
+			emit(i + 1)
+			start = i + 1
+		}
+	}
+	emit(len(content))
+	return sentences
+}
+
+// bestSentence returns the index of the sentence matching the most distinct terms
+func bestSentence(content string, sentences []span, terms []string) int {
+	best, bestScore := 0, 0.0
+	for i, s := range sentences {
+		matches, matched := findMatches(content[s.start:s.end], terms)
+		score := float64(len(matched)) + 0.1*float64(len(matches))
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return best
+}
+
+// findMatches locates words of text matching any term, returning their ranges
+// and the distinct terms matched in query order
+func findMatches(text string, terms []string) ([]span, []string) {
+	matches := make([]span, 0)
+	hit := make(map[string]bool)
+	for _, word := range words(text) {
+		token := strings.ToLower(text[word.start:word.end])
+		for _, term := range terms {
+			if token == term || (len(term) >= minPrefixMatch && strings.HasPrefix(token, term)) {
+				matches = append(matches, word)
+				hit[term] = true
+				break
+			}
+		}
+	}
+
+	matched := make([]string, 0, len(hit))
+	for _, term := range terms {
+		if hit[term] {
+			matched = append(matched, term)
+		}
+	}
+	return matches, matched
+}
+
+// truncateAround cuts an oversized snippet to MaxSnippetLength around its first match
+func truncateAround(snippet string, terms []string) (text, prefix, suffix string) {
+	start := 0
+	if matches, _ := findMatches(snippet, terms); len(matches) > 0 {
+		start = max(matches[0].start-MaxSnippetLength/4, 0)
+	}
+	end := min(start+MaxSnippetLength, len(snippet))
+	for start > 0 && !utf8.RuneStart(snippet[start]) {
+		start--
+	}
+	for end < len(snippet) && !utf8.RuneStart(snippet[end]) {
+		end--
+	}
+
+	if start > 0 {
+		prefix = ellipsis
+	}
+	if end < len(snippet) {
+		suffix = ellipsis
+	}
+	return snippet[start:end], prefix, suffix
+}
+
+// mark wraps each match in highlight markers; matches must be in text order
+func mark(text string, matches []span) string {
+	var b strings.Builder
+	b.Grow(len(text) + len(matches)*2*len(marker))
+	last := 0
+	for _, m := range matches {
+		b.WriteString(text[last:m.start])
+		b.WriteString(marker)
+		b.WriteString(text[m.start:m.end])
+		b.WriteString(marker)
+		last = m.end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// offsetSpans converts match ranges to exported spans shifted by offset
+func offsetSpans(matches []span, offset int) []types.HighlightSpan {
+	if len(matches) == 0 {
+		return nil
+	}
+	spans := make([]types.HighlightSpan, len(matches))
+	for i, m := range matches {
+		spans[i] = types.HighlightSpan{Start: m.start + offset, End: m.end + offset}
+	}
+	return spans
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,52 @@
+package highlight
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const content = "We upgraded the build. The Postgres connection pool was exhausted under load. " +
+	"Raising the pool size fixed it. Later we also tuned the frontend bundle. Nothing else changed."
+
+func TestComputeSelectsBestPassageWithContext(t *testing.T) {
+	h := Compute(content, "postgres connection pool", 1)
+	require.NotNil(t, h)
+
+	assert.Equal(t, "We upgraded the build. The Postgres connection pool was exhausted under load. Raising the pool size fixed it.…", h.Snippet)
+	assert.Equal(t, []string{"postgres", "connection", "pool"}, h.MatchedTerms)
+	assert.Contains(t, h.Highlighted, "**Postgres** **connection** **pool**")
+	for _, s := range h.Spans {
+		assert.Contains(t, []string{"postgres", "connection", "pool"}, strings.ToLower(h.Snippet[s.Start:s.End]))
+	}
+}
+
+func TestComputeWithoutContext(t *testing.T) {
+	h := Compute(content, "frontend", 0)
+	require.NotNil(t, h)
+	assert.Equal(t, "…Later we also tuned the frontend bundle.…", h.Snippet)
+}
+
+func TestComputePrefixMatch(t *testing.T) {
+	h := Compute("Too many connections were opened.", "connection", 0)
+	require.NotNil(t, h)
+	assert.Equal(t, "Too many **connections** were opened.", h.Highlighted)
+}
+
+func TestComputeSemanticMatchFallsBackToOpening(t *testing.T) {
+	h := Compute(content, "database saturation", 0)
+	require.NotNil(t, h)
+	assert.Equal(t, "We upgraded the build.…", h.Snippet)
+	assert.Empty(t, h.MatchedTerms)
+	assert.Empty(t, h.Spans)
+}
+
+func TestComputeTruncatesLongSentences(t *testing.T) {
+	long := strings.Repeat("filler ", 200) + "needle " + strings.Repeat("filler ", 200)
+	h := Compute(long, "needle", 1)
+	require.NotNil(t, h)
+	assert.LessOrEqual(t, len(h.Snippet), MaxSnippetLength+2*len(ellipsis))
+	assert.Contains(t, h.Highlighted, "**needle**")
+}
@@ -0,0 +1,314 @@
+// Package httptools exposes external HTTP APIs as MCP tools. Operators
+// declare the tools in a YAML file: the request each tool sends, the input it
+// takes and the credentials it authenticates to its API with. The file is
+// watched, so tools are added, changed and withdrawn without a restart.
+package httptools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// defaultTimeoutSeconds bounds a tool's request unless configured
+	defaultTimeoutSeconds = 30
+	// maxResponseBytes caps how much of a response is read
+	maxResponseBytes = 1 << 20
+	// maxErrorBodyRunes caps how much of an error response is reported
+	maxErrorBodyRunes = 500
+)
+
+// Auth types a tool authenticates to its API with
+const (
+	AuthNone   = ""
+	AuthBearer = "bearer"
+	AuthHeader = "header"
+	AuthBasic  = "basic"
+)
+
+// toolNamePattern is what MCP clients accept as tool names
+var toolNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// pathParamPattern finds {name} placeholders in a URL
+var pathParamPattern = regexp.MustCompile(`\{([a-zA-Z0-9_]+)\}`)
+
+// AuthSpec is how a tool authenticates to its API. Secrets are never
+// written in the tools file: SecretEnv names the environment variable
+// holding the token, header value, or "user:password" for basic auth.
+type AuthSpec struct {
+	Type      string `yaml:"type" json:"type"`
+	Header    string `yaml:"header,omitempty" json:"header,omitempty"` // Header carrying the secret for the header type
+	SecretEnv string `yaml:"secret_env" json:"secret_env"`
+}
+
+// Spec declares one HTTP tool
+type Spec struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description"`
+	// Method is the HTTP method, GET unless set
+	Method string `yaml:"method,omitempty" json:"method,omitempty"`
+	// URL may hold {argument} placeholders, filled in from the tool's arguments.
+	// Other arguments are sent as query parameters for GET and DELETE, and as
+	// a JSON body otherwise.
+	URL     string            `yaml:"url" json:"url"`
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	// InputSchema is the JSON schema of the tool's arguments
+	InputSchema    map[string]interface{} `yaml:"input_schema,omitempty" json:"input_schema,omitempty"`
+	Auth           AuthSpec               `yaml:"auth,omitempty" json:"auth,omitempty"`
+	TimeoutSeconds int                    `yaml:"timeout_seconds,omitempty" json:"timeout_seconds,omitempty"`
+}
+
+// Validate checks the spec for missing or invalid fields, and that the
+// secret its auth needs is set
+func (s *Spec) Validate() error {
+	if !toolNamePattern.MatchString(s.Name) {
+		return fmt.Errorf("http tool name %q must be 1-64 letters, digits, '_' or '-'", s.Name)
+	}
+	if s.Description == "" {
+		return fmt.Errorf("http tool %s requires a description", s.Name)
+	}
+	switch s.method() {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return fmt.Errorf("http tool %s has unsupported method %s", s.Name, s.Method)
+	}
+	target, err := url.Parse(pathParamPattern.ReplaceAllString(s.URL, "x"))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("http tool %s requires an absolute http or https url", s.Name)
+	}
+	if s.TimeoutSeconds < 0 {
+		return fmt.Errorf("http tool %s timeout_seconds cannot be negative", s.Name)
+	}
+
+	switch s.Auth.Type {
+	case AuthNone:
+		return nil
+	case AuthHeader:
+		if s.Auth.Header == "" {
+			return fmt.Errorf("http tool %s header auth requires a header", s.Name)
+		}
+	case AuthBearer, AuthBasic:
+	default:
+		return fmt.Errorf("http tool %s has unsupported auth type %s", s.Name, s.Auth.Type)
+	}
+	if s.Auth.SecretEnv == "" {
+		return fmt.Errorf("http tool %s auth requires secret_env", s.Name)
+	}
+	if os.Getenv(s.Auth.SecretEnv) == "" {
+		return fmt.Errorf("http tool %s auth secret %s is not set", s.Name, s.Auth.SecretEnv)
+	}
+	if s.Auth.Type == AuthBasic && !strings.Contains(os.Getenv(s.Auth.SecretEnv), ":") {
+		return fmt.Errorf("http tool %s basic auth secret %s must be user:password", s.Name, s.Auth.SecretEnv)
+	}
+	return nil
+}
+
+// method returns the spec's HTTP method
+func (s *Spec) method() string {
+	if s.Method == "" {
+		return http.MethodGet
+	}
+	return strings.ToUpper(s.Method)
+}
+
+// ReadOnly reports whether the tool only reads from its API
+func (s *Spec) ReadOnly() bool {
+	return s.method() == http.MethodGet
+}
+
+// Schema returns the tool's input schema, an object without properties unless declared
+func (s *Spec) Schema() map[string]interface{} {
+	if len(s.InputSchema) == 0 {
+		return map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+	}
+	return s.InputSchema
+}
+
+// toolsFile is the YAML layout of an HTTP tools file
+type toolsFile struct {
+	Tools []Spec `yaml:"tools"`
+}
+
+// LoadSpecs reads tool specs from a YAML file
+func LoadSpecs(path string) ([]Spec, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // Path comes from operator configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read http tools: %w", err)
+	}
+
+	var file toolsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse http tools: %w", err)
+	}
+
+	names := make(map[string]bool, len(file.Tools))
+	for i := range file.Tools {
+		if err := file.Tools[i].Validate(); err != nil {
+			return nil, err
+		}
+		if names[file.Tools[i].Name] {
+			return nil, fmt.Errorf("duplicate http tool %s", file.Tools[i].Name)
+		}
+		names[file.Tools[i].Name] = true
+	}
+	return file.Tools, nil
+}
+
+// Tool calls the API of a spec
+type Tool struct {
+	Spec   Spec
+	client *http.Client
+}
+
+// NewTool creates a tool for spec; a nil client uses http.DefaultClient
+func NewTool(spec *Spec, client *http.Client) *Tool {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Tool{Spec: *spec, client: client}
+}
+
+// Call sends the tool's request with args and returns the response: decoded
+// JSON, or the body as text. Responses with an error status fail the call.
+func (t *Tool) Call(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	timeout := t.Spec.TimeoutSeconds
+	if timeout == 0 {
+		timeout = defaultTimeoutSeconds
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	req, err := t.newRequest(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http tool %s request failed: %w", t.Spec.Name, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("http tool %s failed to read response: %w", t.Spec.Name, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("http tool %s: %s: %s", t.Spec.Name, resp.Status, truncate(string(body), maxErrorBodyRunes))
+	}
+
+	var decoded interface{}
+	if json.Unmarshal(body, &decoded) == nil {
+		return decoded, nil
+	}
+	return string(body), nil
+}
+
+// newRequest builds the tool's request for args
+func (t *Tool) newRequest(ctx context.Context, args map[string]interface{}) (*http.Request, error) {
+	remaining := make(map[string]interface{}, len(args))
+	for name, value := range args {
+		remaining[name] = value
+	}
+
+	var missing []string
+	target := pathParamPattern.ReplaceAllStringFunc(t.Spec.URL, func(placeholder string) string {
+		name := placeholder[1 : len(placeholder)-1]
+		value, ok := remaining[name]
+		if !ok {
+			missing = append(missing, name)
+			return placeholder
+		}
+		delete(remaining, name)
+		return url.PathEscape(fmt.Sprint(value))
+	})
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("http tool %s requires %s", t.Spec.Name, strings.Join(missing, ", "))
+	}
+
+	method := t.Spec.method()
+	var body io.Reader
+	if method == http.MethodGet || method == http.MethodDelete {
+		if len(remaining) > 0 {
+			parsed, err := url.Parse(target)
+			if err != nil {
+				return nil, fmt.Errorf("http tool %s has an invalid url: %w", t.Spec.Name, err)
+			}
+			query := parsed.Query()
+			for name, value := range remaining {
+				query.Set(name, fmt.Sprint(value))
+			}
+			parsed.RawQuery = query.Encode()
+			target = parsed.String()
+		}
+	} else {
+		encoded, err := json.Marshal(remaining)
+		if err != nil {
+			return nil, fmt.Errorf("http tool %s failed to encode arguments: %w", t.Spec.Name, err)
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("http tool %s failed to build request: %w", t.Spec.Name, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	for name, value := range t.Spec.Headers {
+		req.Header.Set(name, value)
+	}
+	if err := t.authenticate(req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// authenticate adds the tool's credentials to req. The secret is read on
+// every call, so the tool fails clearly once it is unset.
+func (t *Tool) authenticate(req *http.Request) error {
+	if t.Spec.Auth.Type == AuthNone {
+		return nil
+	}
+	secret := os.Getenv(t.Spec.Auth.SecretEnv)
+	if secret == "" {
+		return fmt.Errorf("http tool %s auth secret %s is not set", t.Spec.Name, t.Spec.Auth.SecretEnv)
+	}
+	switch t.Spec.Auth.Type {
+	case AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+secret)
+	case AuthHeader:
+		req.Header.Set(t.Spec.Auth.Header, secret)
+	case AuthBasic:
+		user, password, ok := strings.Cut(secret, ":")
+		if !ok {
+			return fmt.Errorf("http tool %s basic auth secret %s must be user:password", t.Spec.Name, t.Spec.Auth.SecretEnv)
+		}
+		req.SetBasicAuth(user, password)
+	default:
+		return errors.New("unsupported auth type " + t.Spec.Auth.Type)
+	}
+	return nil
+}
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	runes := []rune(strings.TrimSpace(s))
+	if len(runes) <= n {
+		return string(runes)
+	}
+	return string(runes[:n]) + "…"
+}
@@ -0,0 +1,159 @@
+package httptools
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"lerian-mcp-memory/internal/logging"
+)
+
+// defaultReloadInterval is how often the tools file is checked for changes
+const defaultReloadInterval = 10 * time.Second
+
+// Registrar adds and withdraws the tools of a registry on the MCP server
+type Registrar interface {
+	// HasTool reports whether a tool of that name is registered
+	HasTool(name string) bool
+	// AddHTTPTool registers tool, replacing an earlier version of it
+	AddHTTPTool(tool *Tool)
+	// RemoveHTTPTool withdraws a tool
+	RemoveHTTPTool(name string)
+}
+
+// Registry keeps the tools of a tools file registered. When the file
+// changes, new tools are added, changed ones replaced and those no longer
+// declared withdrawn. A file that fails to load leaves the tools as they
+// were, and tools named like one registered elsewhere are skipped.
+type Registry struct {
+	path      string
+	registrar Registrar
+	interval  time.Duration
+	client    *http.Client
+
+	mu        sync.Mutex
+	tools     map[string]*Tool
+	modTime   time.Time
+	size      int64
+	lastError string
+}
+
+// NewRegistry creates a registry for the tools file at path
+func NewRegistry(path string, registrar Registrar) *Registry {
+	return &Registry{
+		path:      path,
+		registrar: registrar,
+		interval:  defaultReloadInterval,
+		tools:     make(map[string]*Tool),
+	}
+}
+
+// SetInterval sets how often the file is checked for changes. Set it before Run.
+func (r *Registry) SetInterval(interval time.Duration) {
+	if interval > 0 {
+		r.interval = interval
+	}
+}
+
+// SetHTTPClient sets the client tools send their requests with. Set it before Reload.
+func (r *Registry) SetHTTPClient(client *http.Client) {
+	r.client = client
+}
+
+// Reload loads the tools file and brings the registered tools in line with it
+func (r *Registry) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	info, err := os.Stat(r.path)
+	if err == nil {
+		r.modTime, r.size = info.ModTime(), info.Size()
+	}
+	specs, err := LoadSpecs(r.path)
+	if err != nil {
+		r.lastError = err.Error()
+		return err
+	}
+	r.lastError = ""
+
+	declared := make(map[string]bool, len(specs))
+	for i := range specs {
+		spec := &specs[i]
+		declared[spec.Name] = true
+		current, owned := r.tools[spec.Name]
+		if owned && reflect.DeepEqual(current.Spec, *spec) {
+			continue
+		}
+		if !owned && r.registrar.HasTool(spec.Name) {
+			logging.Warn("Skipping http tool named like a registered tool", "tool", spec.Name, "file", r.path)
+			continue
+		}
+		tool := NewTool(spec, r.client)
+		r.tools[spec.Name] = tool
+		r.registrar.AddHTTPTool(tool)
+		logging.Info("Registered http tool", "tool", spec.Name, "method", spec.method(), "url", spec.URL)
+	}
+	for name := range r.tools {
+		if !declared[name] {
+			delete(r.tools, name)
+			r.registrar.RemoveHTTPTool(name)
+			logging.Info("Withdrew http tool", "tool", name)
+		}
+	}
+	return nil
+}
+
+// changed reports whether the tools file changed since it was last loaded
+func (r *Registry) changed() bool {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !info.ModTime().Equal(r.modTime) || info.Size() != r.size
+}
+
+// Run reloads the tools file whenever it changes, until ctx is cancelled
+func (r *Registry) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logging.Info("Stopping http tools reload due to context cancellation")
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				logging.Error("Failed to reload http tools; keeping the current tools", "file", r.path, "error", err)
+			}
+		}
+	}
+}
+
+// Status describes the registered tools and the outcome of the last reload
+type Status struct {
+	File      string `json:"file"`
+	Tools     []Spec `json:"tools"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// Status returns the registered tools, by name, and the last reload error
+func (r *Registry) Status() Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	status := Status{File: r.path, Tools: make([]Spec, 0, len(r.tools)), LastError: r.lastError}
+	for _, tool := range r.tools {
+		status.Tools = append(status.Tools, tool.Spec)
+	}
+	sort.Slice(status.Tools, func(i, j int) bool { return status.Tools[i].Name < status.Tools[j].Name })
+	return status
+}
@@ -0,0 +1,298 @@
+//go:build integration
+
+package integration
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+// callTimeout bounds a single JSON-RPC call
+const callTimeout = 60 * time.Second
+
+// Response is a JSON-RPC response with its result left undecoded
+type Response struct {
+	JSONRPC string                 `json:"jsonrpc"`
+	ID      json.RawMessage        `json:"id,omitempty"`
+	Result  json.RawMessage        `json:"result,omitempty"`
+	Error   *protocol.JSONRPCError `json:"error,omitempty"`
+}
+
+// transport sends one JSON-RPC request and returns its response
+type transport interface {
+	roundTrip(ctx context.Context, id int64, body []byte) (*Response, error)
+}
+
+// Client is an MCP client bound to a running server
+type Client struct {
+	// Name identifies the transport, "stdio" or "http"
+	Name string
+
+	transport transport
+	nextID    atomic.Int64
+}
+
+// Call sends a JSON-RPC request and returns the response
+func (c *Client) Call(ctx context.Context, method string, params interface{}) (*Response, error) {
+	id := c.nextID.Add(1)
+	body, err := json.Marshal(protocol.JSONRPCRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	return c.transport.roundTrip(ctx, id, body)
+}
+
+// call sends a request and fails the test on transport or JSON-RPC errors
+func (c *Client) call(t *testing.T, method string, params interface{}) json.RawMessage {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+
+	resp, err := c.Call(ctx, method, params)
+	require.NoError(t, err, "%s over %s", method, c.Name)
+	require.Nil(t, resp.Error, "%s over %s returned an error", method, c.Name)
+	return resp.Result
+}
+
+// Initialize performs the MCP handshake
+func (c *Client) Initialize(t *testing.T) {
+	t.Helper()
+	c.call(t, "initialize", map[string]interface{}{
+		"protocolVersion": protocol.Version,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]interface{}{"name": "integration-test", "version": "1.0.0"},
+	})
+}
+
+// ListTools returns the names of the registered tools
+func (c *Client) ListTools(t *testing.T) []string {
+	t.Helper()
+	var result struct {
+		Tools []protocol.Tool `json:"tools"`
+	}
+	require.NoError(t, json.Unmarshal(c.call(t, "tools/list", map[string]interface{}{}), &result))
+
+	names := make([]string, 0, len(result.Tools))
+	for i := range result.Tools {
+		names = append(names, result.Tools[i].Name)
+	}
+	return names
+}
+
+// CallToolResult calls a tool and returns the raw MCP tool result
+func (c *Client) CallToolResult(t *testing.T, name string, args map[string]interface{}) *protocol.ToolCallResult {
+	t.Helper()
+	var result protocol.ToolCallResult
+	raw := c.call(t, "tools/call", map[string]interface{}{"name": name, "arguments": args})
+	require.NoError(t, json.Unmarshal(raw, &result))
+	return &result
+}
+
+// CallTool calls a tool, fails the test if the tool reports an error and
+// decodes its JSON result
+func (c *Client) CallTool(t *testing.T, name string, args map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	result := c.CallToolResult(t, name, args)
+	require.NotEmpty(t, result.Content, "%s returned no content", name)
+	require.False(t, result.IsError, "%s failed: %s", name, result.Content[0].Text)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &decoded), "%s returned non-JSON content: %s", name, result.Content[0].Text)
+	return decoded
+}
+
+// httpTransport posts JSON-RPC requests to /mcp
+type httpTransport struct {
+	url    string
+	client *http.Client
+}
+
+func (h *httpTransport) roundTrip(ctx context.Context, _ int64, body []byte) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url+"/mcp", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %s: %s", resp.Status, data)
+	}
+
+	var response Response
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &response, nil
+}
+
+// stdioTransport writes newline-delimited JSON-RPC to the server's stdin. The
+// server also logs to stdout, so only lines that are JSON-RPC responses are
+// matched against pending requests.
+type stdioTransport struct {
+	writeMutex sync.Mutex
+	stdin      io.Writer
+
+	mutex   sync.Mutex
+	pending map[int64]chan *Response
+	closed  chan struct{}
+}
+
+func newStdioTransport(stdin io.Writer, stdout io.Reader, output io.Writer) *stdioTransport {
+	s := &stdioTransport{
+		stdin:   stdin,
+		pending: make(map[int64]chan *Response),
+		closed:  make(chan struct{}),
+	}
+	go s.readLoop(stdout, output)
+	return s
+}
+
+// readLoop dispatches responses to their callers and copies everything else to output
+func (s *stdioTransport) readLoop(stdout io.Reader, output io.Writer) {
+	defer close(s.closed)
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+
+		var response Response
+		var id int64
+		if json.Unmarshal(line, &response) != nil || response.JSONRPC != "2.0" || json.Unmarshal(response.ID, &id) != nil {
+			_, _ = output.Write(line)
+			_, _ = output.Write([]byte("\n"))
+			continue
+		}
+
+		s.mutex.Lock()
+		ch, ok := s.pending[id]
+		delete(s.pending, id)
+		s.mutex.Unlock()
+		if ok {
+			ch <- &response
+		}
+	}
+}
+
+func (s *stdioTransport) roundTrip(ctx context.Context, id int64, body []byte) (*Response, error) {
+	ch := make(chan *Response, 1)
+	s.mutex.Lock()
+	s.pending[id] = ch
+	s.mutex.Unlock()
+	defer func() {
+		s.mutex.Lock()
+		delete(s.pending, id)
+		s.mutex.Unlock()
+	}()
+
+	s.writeMutex.Lock()
+	_, err := s.stdin.Write(append(body, '\n'))
+	s.writeMutex.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write request: %w", err)
+	}
+
+	select {
+	case response := <-ch:
+		return response, nil
+	case <-s.closed:
+		return nil, fmt.Errorf("server closed stdout before responding to request %d", id)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// StartStdio runs the server in stdio mode and returns an initialized client
+func (e *Environment) StartStdio(t *testing.T) *Client {
+	t.Helper()
+	cmd, output := e.command(t, "-mode", "stdio")
+	stdin, err := cmd.StdinPipe()
+	require.NoError(t, err)
+	// An io.Pipe rather than StdoutPipe, so the process can be waited on
+	// while responses are still being read
+	stdout, stdoutWriter := io.Pipe()
+	cmd.Stdout = stdoutWriter
+
+	process := start(t, cmd, output)
+	go func() {
+		<-process.done
+		_ = stdoutWriter.Close()
+	}()
+	t.Cleanup(func() { _ = stdin.Close() })
+
+	client := &Client{Name: "stdio", transport: newStdioTransport(stdin, stdout, process.output)}
+	client.Initialize(t)
+	return client
+}
+
+// StartHTTP runs the server in HTTP mode, waits for /health and returns an
+// initialized client
+func (e *Environment) StartHTTP(t *testing.T) *Client {
+	t.Helper()
+	client, _ := e.StartHTTPServer(t)
+	return client
+}
+
+// StartHTTPServer is StartHTTP that also returns the server base URL, for
+// tests that exercise endpoints besides /mcp
+func (e *Environment) StartHTTPServer(t *testing.T) (*Client, string) {
+	t.Helper()
+	addr := freeAddr(t)
+	baseURL := "http://" + addr
+
+	cmd, output := e.command(t, "-mode", "http", "-addr", addr)
+	cmd.Stdout = output
+	process := start(t, cmd, output)
+
+	httpClient := &http.Client{Timeout: callTimeout}
+	deadline := time.Now().Add(startupTimeout)
+	for !healthy(httpClient, baseURL) {
+		require.False(t, process.exited(), "server exited during startup")
+		require.True(t, time.Now().Before(deadline), "server did not become healthy")
+		time.Sleep(250 * time.Millisecond)
+	}
+
+	client := &Client{Name: "http", transport: &httpTransport{url: baseURL, client: httpClient}}
+	client.Initialize(t)
+	return client, baseURL
+}
+
+// healthy reports whether the server at baseURL answers its health check
+func healthy(client *http.Client, baseURL string) bool {
+	resp, err := client.Get(baseURL + "/health") //nolint:noctx // Startup polling
+	if err != nil {
+		return false
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
+
+// freeAddr returns a loopback address with a currently unused port
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+	return addr
+}
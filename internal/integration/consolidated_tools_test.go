@@ -0,0 +1,123 @@
+//go:build integration
+
+package integration
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	Cleanup()
+	os.Exit(code)
+}
+
+func TestConsolidatedToolsOverTransports(t *testing.T) {
+	env := NewEnvironment(t)
+
+	transports := []struct {
+		name  string
+		start func(*testing.T) *Client
+	}{
+		{"stdio", env.StartStdio},
+		{"http", env.StartHTTP},
+	}
+
+	for _, tt := range transports {
+		t.Run(tt.name, func(t *testing.T) {
+			client := tt.start(t)
+			repository := "github.com/integration/" + tt.name
+
+			tools := client.ListTools(t)
+			for _, name := range []string{"memory_create", "memory_read", "memory_update", "memory_delete", "memory_analyze", "memory_system", "system_tool_stats"} {
+				assert.Contains(t, tools, name)
+			}
+
+			created := client.CallTool(t, "memory_create", map[string]interface{}{
+				"operation": "store_chunk",
+				"scope":     "single",
+				"options": map[string]interface{}{
+					"content":    "Fixed flaky websocket reconnect by adding exponential backoff over " + tt.name,
+					"session_id": "integration-" + tt.name,
+					"repository": repository,
+					"tags":       []string{"websocket", "bugfix"},
+				},
+			})
+			chunkID, _ := created["chunk_id"].(string)
+			require.NotEmpty(t, chunkID)
+
+			search := map[string]interface{}{
+				"operation": "search",
+				"scope":     "single",
+				"options": map[string]interface{}{
+					"query":      "websocket reconnect backoff",
+					"repository": repository,
+					"limit":      5,
+				},
+			}
+			deadline := time.Now().Add(10 * time.Second)
+			for !containsChunk(client.CallTool(t, "memory_read", search), chunkID) {
+				require.True(t, time.Now().Before(deadline), "stored chunk not found by search")
+				time.Sleep(200 * time.Millisecond)
+			}
+
+			report := client.CallTool(t, "memory_analyze", map[string]interface{}{
+				"operation": "knowledge_gaps",
+				"scope":     "single",
+				"options":   map[string]interface{}{"repository": repository},
+			})
+			assert.Equal(t, "success", report["status"])
+
+			health := client.CallTool(t, "memory_system", map[string]interface{}{
+				"operation": "health",
+				"scope":     "system",
+				"options":   map[string]interface{}{},
+			})
+			assert.NotEmpty(t, health)
+
+			stats := client.CallTool(t, "system_tool_stats", map[string]interface{}{"tool": "memory_create"})
+			toolStats, _ := stats["tools"].([]interface{})
+			require.Len(t, toolStats, 1)
+			assert.EqualValues(t, 1, toolStats[0].(map[string]interface{})["calls"])
+		})
+	}
+}
+
+// containsChunk reports whether a memory_read search result includes chunkID
+func containsChunk(found map[string]interface{}, chunkID string) bool {
+	results, _ := found["results"].([]interface{})
+	for _, result := range results {
+		r, _ := result.(map[string]interface{})
+		if chunk, ok := r["chunk"].(map[string]interface{}); ok && chunk["id"] == chunkID {
+			return true
+		}
+	}
+	return false
+}
+
+func TestHTTPEndpoints(t *testing.T) {
+	env := NewEnvironment(t)
+	client, baseURL := env.StartHTTPServer(t)
+
+	client.CallTool(t, "memory_system", map[string]interface{}{
+		"operation": "health",
+		"scope":     "system",
+		"options":   map[string]interface{}{},
+	})
+
+	resp, err := http.Get(baseURL + "/metrics") //nolint:noctx // Test request
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `mcp_tool_calls_total{tool="memory_system"} 1`)
+}
@@ -0,0 +1,22 @@
+// Package integration provides an end-to-end test harness for the MCP memory
+// server. It starts the backing services in Docker via dockertest, builds and
+// runs the real server binary, and talks to it over the stdio and HTTP
+// transports exactly as an MCP client would.
+//
+// The tests carry the integration build tag and skip when Docker is not
+// reachable:
+//
+//	go test -tags=integration ./internal/integration/...
+//
+// The server runs against Qdrant: it keeps all of its state in the vector
+// store, so there is no relational database to provision. Embeddings come
+// from an in-process OpenAI-compatible fake, so no API key is needed. A
+// PostgreSQL container with pgvector is started only for the tests that ask
+// for one with PgVectorDSN, such as the pgvector backend's compliance run.
+//
+// Contributors adding a tool can reuse the fixtures:
+//
+//	env := integration.NewEnvironment(t)
+//	client := env.StartHTTP(t) // or env.StartStdio(t)
+//	result := client.CallTool(t, "memory_read", map[string]interface{}{...})
+package integration
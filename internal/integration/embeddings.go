@@ -0,0 +1,71 @@
+//go:build integration
+
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"lerian-mcp-memory/internal/embeddings"
+)
+
+// embeddingDimension matches the vector size of the Qdrant collection
+const embeddingDimension = embeddings.FakeDimension
+
+// embeddingRequest is the subset of the OpenAI embeddings request the fake reads
+type embeddingRequest struct {
+	Model string          `json:"model"`
+	Input json.RawMessage `json:"input"`
+}
+
+// startFakeEmbeddings starts an OpenAI-compatible embeddings endpoint serving
+// the fake provider's hashed vectors, so the real OpenAI client is exercised
+// while search results stay deterministic.
+func startFakeEmbeddings(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/embeddings", func(w http.ResponseWriter, r *http.Request) {
+		var req embeddingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var inputs []string
+		if err := json.Unmarshal(req.Input, &inputs); err != nil {
+			var single string
+			if err := json.Unmarshal(req.Input, &single); err != nil {
+				http.Error(w, "input must be a string or an array of strings", http.StatusBadRequest)
+				return
+			}
+			inputs = []string{single}
+		}
+
+		data := make([]map[string]interface{}, 0, len(inputs))
+		tokens := 0
+		for i, input := range inputs {
+			data = append(data, map[string]interface{}{
+				"object":    "embedding",
+				"index":     i,
+				"embedding": embeddings.HashEmbedding(input, embeddingDimension),
+			})
+			tokens += len(strings.Fields(input))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"object": "list",
+			"model":  req.Model,
+			"data":   data,
+			"usage":  map[string]int{"prompt_tokens": tokens, "total_tokens": tokens},
+		})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
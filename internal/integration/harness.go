@@ -0,0 +1,319 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// qdrantRepository and qdrantTag pin the vector store image under test
+	qdrantRepository = "qdrant/qdrant"
+	qdrantTag        = "v1.14.0"
+
+	// startupTimeout bounds container and server startup
+	startupTimeout = 2 * time.Minute
+
+	// containerExpirySeconds makes Docker reap the container if the test binary dies
+	containerExpirySeconds = 600
+
+	// shutdownTimeout bounds graceful server shutdown before the process is killed
+	shutdownTimeout = 10 * time.Second
+)
+
+// errDockerUnavailable marks fixture failures caused by a missing Docker daemon
+var errDockerUnavailable = errors.New("docker is not available")
+
+// fixtures are shared by every test in the process: one Qdrant container and
+// one server binary. Tests isolate their data with a collection per environment.
+type fixtures struct {
+	pool       *dockertest.Pool
+	qdrant     *dockertest.Resource
+	qdrantHost string
+	qdrantPort int
+	binary     string
+	buildDir   string
+}
+
+var (
+	sharedOnce     sync.Once
+	sharedFixtures *fixtures
+	sharedErr      error
+)
+
+// getFixtures starts the shared fixtures on first use
+func getFixtures() (*fixtures, error) {
+	sharedOnce.Do(func() {
+		sharedFixtures, sharedErr = startFixtures()
+	})
+	return sharedFixtures, sharedErr
+}
+
+// startFixtures builds the server binary and starts Qdrant
+func startFixtures() (*fixtures, error) {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errDockerUnavailable, err)
+	}
+	if err := pool.Client.Ping(); err != nil {
+		return nil, fmt.Errorf("%w: %w", errDockerUnavailable, err)
+	}
+	pool.MaxWait = startupTimeout
+
+	f := &fixtures{pool: pool}
+	if err := f.buildServer(); err != nil {
+		f.close()
+		return nil, err
+	}
+	if err := f.startQdrant(); err != nil {
+		f.close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// buildServer compiles cmd/server into a temporary directory
+func (f *fixtures) buildServer() error {
+	dir, err := os.MkdirTemp("", "mcp-memory-integration-")
+	if err != nil {
+		return fmt.Errorf("failed to create build directory: %w", err)
+	}
+	f.buildDir = dir
+	f.binary = filepath.Join(dir, "lerian-mcp-memory-server")
+
+	cmd := exec.Command("go", "build", "-o", f.binary, "./cmd/server") //nolint:gosec // Fixed arguments
+	cmd.Dir = moduleRoot()
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to build server: %w\n%s", err, output)
+	}
+	return nil
+}
+
+// startQdrant runs Qdrant and waits until it reports ready
+func (f *fixtures) startQdrant() error {
+	resource, err := f.pool.RunWithOptions(&dockertest.RunOptions{
+		Repository:   qdrantRepository,
+		Tag:          qdrantTag,
+		ExposedPorts: []string{"6333/tcp", "6334/tcp"},
+	}, func(hc *docker.HostConfig) {
+		hc.AutoRemove = true
+		hc.RestartPolicy = docker.RestartPolicy{Name: "no"}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start qdrant: %w", err)
+	}
+	f.qdrant = resource
+	_ = resource.Expire(containerExpirySeconds)
+
+	host, port, err := net.SplitHostPort(resource.GetHostPort("6334/tcp"))
+	if err != nil {
+		return fmt.Errorf("failed to resolve qdrant address: %w", err)
+	}
+	if host == "" || host == "0.0.0.0" {
+		host = "localhost"
+	}
+	f.qdrantHost = host
+	if f.qdrantPort, err = strconv.Atoi(port); err != nil {
+		return fmt.Errorf("invalid qdrant port %q: %w", port, err)
+	}
+
+	readyURL := fmt.Sprintf("http://%s/readyz", net.JoinHostPort(host, resource.GetPort("6333/tcp")))
+	return f.pool.Retry(func() error {
+		resp, err := http.Get(readyURL) //nolint:gosec,noctx // Test fixture polling a local container
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("qdrant not ready: %s", resp.Status)
+		}
+		return nil
+	})
+}
+
+// close removes the container and the built binary
+func (f *fixtures) close() {
+	if f.qdrant != nil {
+		_ = f.pool.Purge(f.qdrant)
+	}
+	if f.buildDir != "" {
+		_ = os.RemoveAll(f.buildDir)
+	}
+}
+
+// Cleanup releases the shared fixtures. Call it from TestMain after m.Run.
+func Cleanup() {
+	if sharedFixtures != nil {
+		sharedFixtures.close()
+	}
+	if sharedPostgres != nil {
+		sharedPostgres.close()
+	}
+}
+
+// moduleRoot returns the repository root, two levels above this package
+func moduleRoot() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..")
+}
+
+// Environment is the configuration a test runs the server with: a dedicated
+// Qdrant collection and a fake embeddings endpoint
+type Environment struct {
+	// Collection is the Qdrant collection the server stores memories in
+	Collection string
+
+	fixtures *fixtures
+	env      map[string]string
+}
+
+// NewEnvironment prepares an isolated server environment, skipping the test
+// when Docker is not available
+func NewEnvironment(t *testing.T) *Environment {
+	t.Helper()
+
+	f, err := getFixtures()
+	if errors.Is(err, errDockerUnavailable) {
+		t.Skipf("skipping integration test: %v", err)
+	}
+	require.NoError(t, err)
+
+	embeddings := startFakeEmbeddings(t)
+	collection := "integration_" + strings.ReplaceAll(uuid.New().String(), "-", "")[:12]
+
+	return &Environment{
+		Collection: collection,
+		fixtures:   f,
+		env: map[string]string{
+			"MCP_MEMORY_QDRANT_HOST":           f.qdrantHost,
+			"MCP_MEMORY_QDRANT_PORT":           strconv.Itoa(f.qdrantPort),
+			"MCP_MEMORY_QDRANT_COLLECTION":     collection,
+			"MCP_MEMORY_QDRANT_DOCKER_ENABLED": "false",
+			"MCP_MEMORY_STORAGE_PROVIDER":      "qdrant",
+			"MCP_MEMORY_EMBEDDING_PROVIDER":    "openai",
+			"OPENAI_API_KEY":                   "integration-test-key",
+			"MCP_MEMORY_OPENAI_BASE_URL":       embeddings.URL + "/v1",
+			"MCP_MEMORY_OPENAI_RATE_LIMIT_RPM": "6000",
+		},
+	}
+}
+
+// Setenv overrides a server environment variable for servers started afterwards
+func (e *Environment) Setenv(key, value string) {
+	e.env[key] = value
+}
+
+// environ returns the server process environment. The parent environment is
+// not inherited beyond what the toolchain needs, so a developer's own
+// configuration cannot leak into the test.
+func (e *Environment) environ() []string {
+	environ := make([]string, 0, len(e.env)+2)
+	for _, key := range []string{"PATH", "HOME"} {
+		if value, ok := os.LookupEnv(key); ok {
+			environ = append(environ, key+"="+value)
+		}
+	}
+	for key, value := range e.env {
+		environ = append(environ, key+"="+value)
+	}
+	return environ
+}
+
+// serverProcess is a running server binary
+type serverProcess struct {
+	cmd    *exec.Cmd
+	output *syncBuffer
+	done   chan struct{}
+}
+
+// command prepares the server binary with the given arguments. It runs from a
+// temporary directory so no .env file or data directory from the checkout is used.
+func (e *Environment) command(t *testing.T, args ...string) (*exec.Cmd, *syncBuffer) {
+	t.Helper()
+	cmd := exec.Command(e.fixtures.binary, args...) //nolint:gosec // Binary built by the harness
+	cmd.Dir = t.TempDir()
+	cmd.Env = e.environ()
+	output := &syncBuffer{}
+	cmd.Stderr = output
+	return cmd, output
+}
+
+// start runs cmd and stops it when the test ends, logging its output on failure
+func start(t *testing.T, cmd *exec.Cmd, output *syncBuffer) *serverProcess {
+	t.Helper()
+	require.NoError(t, cmd.Start())
+
+	process := &serverProcess{cmd: cmd, output: output, done: make(chan struct{})}
+	go func() {
+		_ = cmd.Wait()
+		close(process.done)
+	}()
+
+	t.Cleanup(func() {
+		process.stop()
+		if t.Failed() {
+			t.Logf("server output:\n%s", output.String())
+		}
+	})
+	return process
+}
+
+// exited reports whether the process has terminated
+func (p *serverProcess) exited() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// stop interrupts the server and kills it if it does not shut down in time
+func (p *serverProcess) stop() {
+	if p.exited() {
+		return
+	}
+	_ = p.cmd.Process.Signal(os.Interrupt)
+	select {
+	case <-p.done:
+	case <-time.After(shutdownTimeout):
+		_ = p.cmd.Process.Kill()
+		<-p.done
+	}
+}
+
+// syncBuffer collects process output written from several goroutines
+type syncBuffer struct {
+	mutex sync.Mutex
+	buf   bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.String()
+}
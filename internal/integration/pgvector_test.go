@@ -0,0 +1,36 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/storage/storagetest"
+
+	"github.com/google/uuid"
+)
+
+// complianceDimension is the embedding size of the compliance run, that of
+// OpenAI's embeddings
+const complianceDimension = 1536
+
+// TestPgVectorStoreCompliance runs the VectorStore compliance suite against
+// PostgreSQL with pgvector in Docker
+func TestPgVectorStoreCompliance(t *testing.T) {
+	dsn := PgVectorDSN(t)
+
+	suite := &storagetest.Suite{
+		NewStore: func(t *testing.T) storage.VectorStore {
+			table := "compliance_" + strings.ReplaceAll(uuid.New().String()[:8], "-", "")
+			store := storage.NewPgVectorStore(&config.PgVectorConfig{DSN: dsn, Table: table, Dimension: complianceDimension})
+			t.Cleanup(func() { _ = store.DeleteCollection(context.Background(), table) })
+			return store
+		},
+		Dimension: complianceDimension,
+	}
+	suite.Run(t)
+}
@@ -0,0 +1,115 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/lib/pq" // PostgreSQL driver for the readiness check
+)
+
+const (
+	// pgvectorRepository and pgvectorTag pin the PostgreSQL image under test
+	pgvectorRepository = "pgvector/pgvector"
+	pgvectorTag        = "pg16"
+
+	// postgresPassword is the superuser password of the throwaway database
+	postgresPassword = "integration"
+)
+
+// postgresFixture is a PostgreSQL container with the pgvector extension,
+// shared by every test in the process. Tests isolate their data with a
+// table per store.
+type postgresFixture struct {
+	pool     *dockertest.Pool
+	resource *dockertest.Resource
+	dsn      string
+}
+
+var (
+	postgresOnce   sync.Once
+	sharedPostgres *postgresFixture
+	postgresErr    error
+)
+
+// PgVectorDSN returns the DSN of a PostgreSQL database with pgvector,
+// starting its container on first use, and skips the test when Docker is
+// not available
+func PgVectorDSN(t *testing.T) string {
+	t.Helper()
+
+	postgresOnce.Do(func() {
+		sharedPostgres, postgresErr = startPostgres()
+	})
+	if errors.Is(postgresErr, errDockerUnavailable) {
+		t.Skipf("skipping integration test: %v", postgresErr)
+	}
+	require.NoError(t, postgresErr)
+	return sharedPostgres.dsn
+}
+
+// startPostgres runs pgvector and waits until it accepts connections
+func startPostgres() (*postgresFixture, error) {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errDockerUnavailable, err)
+	}
+	if err := pool.Client.Ping(); err != nil {
+		return nil, fmt.Errorf("%w: %w", errDockerUnavailable, err)
+	}
+	pool.MaxWait = startupTimeout
+
+	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
+		Repository: pgvectorRepository,
+		Tag:        pgvectorTag,
+		Env:        []string{"POSTGRES_PASSWORD=" + postgresPassword, "POSTGRES_DB=memory"},
+	}, func(hc *docker.HostConfig) {
+		hc.AutoRemove = true
+		hc.RestartPolicy = docker.RestartPolicy{Name: "no"}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start pgvector: %w", err)
+	}
+	_ = resource.Expire(containerExpirySeconds)
+	f := &postgresFixture{pool: pool, resource: resource}
+
+	host, port, err := net.SplitHostPort(resource.GetHostPort("5432/tcp"))
+	if err != nil {
+		f.close()
+		return nil, fmt.Errorf("failed to resolve pgvector address: %w", err)
+	}
+	if host == "" || host == "0.0.0.0" {
+		host = "localhost"
+	}
+	f.dsn = fmt.Sprintf("postgres://postgres:%s@%s/memory?sslmode=disable", postgresPassword, net.JoinHostPort(host, port))
+
+	if err := pool.Retry(func() error {
+		db, err := sql.Open("postgres", f.dsn)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = db.Close() }()
+		return db.PingContext(context.Background())
+	}); err != nil {
+		f.close()
+		return nil, fmt.Errorf("pgvector not ready: %w", err)
+	}
+	return f, nil
+}
+
+// close removes the container
+func (f *postgresFixture) close() {
+	if f.resource != nil {
+		_ = f.pool.Purge(f.resource)
+	}
+}
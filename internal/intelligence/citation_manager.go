@@ -51,6 +51,7 @@ type Citation struct {
 	Relevance     float64                `json:"relevance"`
 	UsageCount    int                    `json:"usage_count"`
 	Context       string                 `json:"context"` // Quoted or relevant portion
+	Provenance    *types.Provenance      `json:"provenance,omitempty"`
 	Metadata      map[string]interface{} `json:"metadata"`
 	FormattedText string                 `json:"formatted_text"`
 }
@@ -111,6 +112,13 @@ func DefaultCitationConfig() *CitationConfig {
 				Prefix:    "References:\n",
 				Suffix:    "",
 			},
+			"provenance": {
+				Template:  "[{id}] {type} from {repository} ({timestamp}), source: {source}",
+				Fields:    []string{"id", "type", "repository", "timestamp", "source"},
+				Separator: "\n",
+				Prefix:    "Sources:\n",
+				Suffix:    "",
+			},
 			"inline": {
 				Template:  "[{id}]",
 				Fields:    []string{"id"},
@@ -255,6 +263,7 @@ func (cm *CitationManager) createCitation(result *types.SearchResult, index int)
 		Relevance:  result.Score,
 		UsageCount: 0,
 		Context:    citationContext,
+		Provenance: chunk.Metadata.Provenance,
 		Metadata:   make(map[string]interface{}),
 	}
 
@@ -385,6 +394,17 @@ func (cm *CitationManager) replacePlaceholders(template string, citation *Citati
 		"{confidence}": fmt.Sprintf("%.2f", citation.Confidence),
 		"{relevance}":  fmt.Sprintf("%.2f", citation.Relevance),
 		"{context}":    citation.Context,
+		"{source}":     "unknown",
+		"{author}":     "",
+		"{commit}":     "",
+		"{source_url}": "",
+	}
+
+	if p := citation.Provenance; p != nil {
+		replacements["{source}"] = describeProvenance(p)
+		replacements["{author}"] = p.Author
+		replacements["{commit}"] = p.CommitSHA
+		replacements["{source_url}"] = p.SourceURL
 	}
 
 	for placeholder, value := range replacements {
@@ -394,6 +414,31 @@ func (cm *CitationManager) replacePlaceholders(template string, citation *Citati
 	return text
 }
 
+// describeProvenance summarizes provenance for citation text, e.g. "git by alice at 1a2b3c4"
+func describeProvenance(p *types.Provenance) string {
+	parts := make([]string, 0, 4)
+	if p.SourceSystem != "" {
+		parts = append(parts, p.SourceSystem)
+	}
+	if p.Author != "" {
+		parts = append(parts, "by "+p.Author)
+	}
+	if p.CommitSHA != "" {
+		commit := p.CommitSHA
+		if len(commit) > 7 {
+			commit = commit[:7]
+		}
+		parts = append(parts, "at "+commit)
+	}
+	if p.SourceURL != "" {
+		parts = append(parts, p.SourceURL)
+	}
+	if len(parts) == 0 {
+		return "unknown"
+	}
+	return strings.Join(parts, " ")
+}
+
 func (cm *CitationManager) generateResponseID(query string) string {
 	hash := sha256.Sum256([]byte(query + time.Now().Format(time.RFC3339)))
 	return "resp_" + hex.EncodeToString(hash[:8])
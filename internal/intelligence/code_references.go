@@ -0,0 +1,333 @@
+package intelligence
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"lerian-mcp-memory/pkg/types"
+)
+
+// maxGitSymbolLookups bounds the git grep calls made for one snapshot
+const maxGitSymbolLookups = 500
+
+// Reference kinds
+const (
+	ReferenceKindFile   = "file"
+	ReferenceKindSymbol = "symbol"
+)
+
+// Reference states
+const (
+	ReferenceStatePresent = "present"
+	ReferenceStateMissing = "missing"
+	ReferenceStateRenamed = "renamed"
+)
+
+var (
+	// filePathPattern finds source file paths such as internal/mcp/server.go or main.go:42
+	filePathPattern = regexp.MustCompile("(?:^|[\\s\"'(\\[`])((?:[\\w.-]+/)*[\\w-][\\w.-]*\\.(?:go|py|js|jsx|ts|tsx|rs|java|kt|rb|php|c|h|cc|cpp|hpp|cs|swift|scala|sql|proto|sh|yaml|yml|toml|json|md))(?::\\d+)?\\b")
+
+	// inlineCodePattern finds `inline code` spans
+	inlineCodePattern = regexp.MustCompile("`([^`\\n]+)`")
+
+	// symbolPattern accepts identifiers like HandleRequest, ms.handleStore() or types.ChunkType
+	symbolPattern = regexp.MustCompile(`^(?:[A-Za-z_]\w*\.)*([A-Za-z_]\w*)(\(\))?$`)
+
+	// funcDeclarationPattern finds Go function declarations quoted in memories
+	funcDeclarationPattern = regexp.MustCompile(`\bfunc\s+(?:\([^)]*\)\s*)?([A-Za-z_]\w*)\s*\(`)
+)
+
+// CodeReference is a file or symbol a memory mentions
+type CodeReference struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// CodeReferenceStatus is the state of a reference in the current codebase
+type CodeReferenceStatus struct {
+	CodeReference
+	State     string `json:"state"`
+	RenamedTo string `json:"renamed_to,omitempty"`
+}
+
+// StaleReferenceResult reports which references of a memory no longer resolve
+type StaleReferenceResult struct {
+	ChunkID    string                `json:"chunk_id"`
+	References int                   `json:"references"`
+	Broken     []CodeReferenceStatus `json:"broken,omitempty"`
+	// Staleness is the share of references that no longer resolve (0.0-1.0)
+	Staleness float64 `json:"staleness"`
+	IsStale   bool    `json:"is_stale"`
+}
+
+// CodebaseSnapshot is the file and symbol state of a repository that memories
+// are checked against. It is built from a caller-provided manifest or from git.
+type CodebaseSnapshot struct {
+	files     map[string]bool
+	basenames map[string][]string
+	renames   map[string]string
+	renamedBy map[string][]string
+
+	symbols      map[string]bool
+	symbolLookup func(string) bool
+	lookupMu     sync.Mutex
+	lookupCache  map[string]bool
+}
+
+// NewCodebaseSnapshot builds a snapshot from a file manifest. renames maps
+// old paths to new ones; symbols, when non-nil, lists the defined symbols.
+// Without symbols only file references are checked.
+func NewCodebaseSnapshot(files []string, renames map[string]string, symbols []string) *CodebaseSnapshot {
+	s := &CodebaseSnapshot{
+		files:     make(map[string]bool, len(files)),
+		basenames: make(map[string][]string),
+		renames:   make(map[string]string, len(renames)),
+		renamedBy: make(map[string][]string),
+	}
+	for _, file := range files {
+		file = normalizeReferencePath(file)
+		if file == "" || s.files[file] {
+			continue
+		}
+		s.files[file] = true
+		base := path.Base(file)
+		s.basenames[base] = append(s.basenames[base], file)
+	}
+	for from, to := range renames {
+		from = normalizeReferencePath(from)
+		if from == "" {
+			continue
+		}
+		s.renames[from] = normalizeReferencePath(to)
+		base := path.Base(from)
+		s.renamedBy[base] = append(s.renamedBy[base], from)
+	}
+	if symbols != nil {
+		s.symbols = make(map[string]bool, len(symbols))
+		for _, symbol := range symbols {
+			s.symbols[symbol] = true
+		}
+	}
+	return s
+}
+
+// LoadGitSnapshot builds a snapshot from the git work tree at dir: tracked
+// files from ls-files, renames from history, and symbols looked up on demand
+// with git grep
+func LoadGitSnapshot(ctx context.Context, dir string) (*CodebaseSnapshot, error) {
+	out, err := runGit(ctx, dir, "ls-files")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files in %s: %w", dir, err)
+	}
+	files := strings.Split(strings.TrimSpace(string(out)), "\n")
+
+	// Newest renames come first, so the first rename seen for a path wins
+	renames := make(map[string]string)
+	if out, err := runGit(ctx, dir, "log", "-M", "--diff-filter=R", "--name-status", "--format="); err == nil {
+		scanner := bufio.NewScanner(bytes.NewReader(out))
+		for scanner.Scan() {
+			fields := strings.Split(scanner.Text(), "\t")
+			if len(fields) != 3 || !strings.HasPrefix(fields[0], "R") {
+				continue
+			}
+			if _, seen := renames[fields[1]]; !seen {
+				renames[fields[1]] = fields[2]
+			}
+		}
+	}
+
+	s := NewCodebaseSnapshot(files, renames, nil)
+	s.lookupCache = make(map[string]bool)
+	s.symbolLookup = func(symbol string) bool {
+		_, err := runGit(ctx, dir, "grep", "-q", "-I", "-w", "-F", "-e", symbol)
+		return err == nil
+	}
+	return s, nil
+}
+
+// runGit runs a read-only git subcommand in dir
+func runGit(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...) //nolint:gosec // Fixed read-only git subcommands
+	return cmd.Output()
+}
+
+// ChecksSymbols reports whether symbol references can be verified
+func (s *CodebaseSnapshot) ChecksSymbols() bool {
+	return s.symbols != nil || s.symbolLookup != nil
+}
+
+// FileCount returns the number of files in the snapshot
+func (s *CodebaseSnapshot) FileCount() int {
+	return len(s.files)
+}
+
+// ResolveFile reports whether a referenced path exists, was renamed, or is gone.
+// References may be relative to any directory, so a suffix match on path
+// segments counts as present.
+func (s *CodebaseSnapshot) ResolveFile(ref string) CodeReferenceStatus {
+	ref = normalizeReferencePath(ref)
+	status := CodeReferenceStatus{CodeReference: CodeReference{Kind: ReferenceKindFile, Value: ref}}
+
+	base := path.Base(ref)
+	for _, file := range s.basenames[base] {
+		if pathsMatch(file, ref) {
+			status.State = ReferenceStatePresent
+			return status
+		}
+	}
+	for _, from := range s.renamedBy[base] {
+		if pathsMatch(from, ref) {
+			status.State = ReferenceStateRenamed
+			status.RenamedTo = s.renames[from]
+			return status
+		}
+	}
+	status.State = ReferenceStateMissing
+	return status
+}
+
+// ResolveSymbol reports whether a referenced symbol is still defined or used
+func (s *CodebaseSnapshot) ResolveSymbol(symbol string) CodeReferenceStatus {
+	status := CodeReferenceStatus{CodeReference: CodeReference{Kind: ReferenceKindSymbol, Value: symbol}, State: ReferenceStatePresent}
+	if s.symbols != nil {
+		if !s.symbols[symbol] {
+			status.State = ReferenceStateMissing
+		}
+		return status
+	}
+	if s.symbolLookup == nil {
+		return status
+	}
+
+	s.lookupMu.Lock()
+	defer s.lookupMu.Unlock()
+	found, cached := s.lookupCache[symbol]
+	if !cached {
+		if len(s.lookupCache) >= maxGitSymbolLookups {
+			return status
+		}
+		found = s.symbolLookup(symbol)
+		s.lookupCache[symbol] = found
+	}
+	if !found {
+		status.State = ReferenceStateMissing
+	}
+	return status
+}
+
+// Check resolves every reference of a memory against the snapshot
+func (s *CodebaseSnapshot) Check(chunk *types.ConversationChunk) StaleReferenceResult {
+	result := StaleReferenceResult{ChunkID: chunk.ID}
+	for _, ref := range ExtractCodeReferences(chunk) {
+		var status CodeReferenceStatus
+		if ref.Kind == ReferenceKindFile {
+			status = s.ResolveFile(ref.Value)
+		} else {
+			if !s.ChecksSymbols() {
+				continue
+			}
+			status = s.ResolveSymbol(ref.Value)
+		}
+		result.References++
+		if status.State != ReferenceStatePresent {
+			result.Broken = append(result.Broken, status)
+		}
+	}
+	if result.References > 0 {
+		result.Staleness = float64(len(result.Broken)) / float64(result.References)
+	}
+	result.IsStale = len(result.Broken) > 0
+	return result
+}
+
+// ApplyStaleness lowers a memory's quality by the share of its code
+// references that no longer resolve
+func ApplyStaleness(quality *types.QualityMetrics, result *StaleReferenceResult) {
+	if result == nil || result.Staleness == 0 {
+		return
+	}
+	quality.FreshnessScore *= 1 - result.Staleness
+	if result.Staleness > quality.RelevanceDecay {
+		quality.RelevanceDecay = result.Staleness
+	}
+	quality.CalculateOverallQuality()
+}
+
+// ExtractCodeReferences collects the files and symbols a memory mentions:
+// files it recorded as modified, file paths in its content, and identifiers
+// quoted as inline code or in function declarations
+func ExtractCodeReferences(chunk *types.ConversationChunk) []CodeReference {
+	seen := make(map[CodeReference]bool)
+	refs := make([]CodeReference, 0)
+	add := func(kind, value string) {
+		ref := CodeReference{Kind: kind, Value: value}
+		if value == "" || seen[ref] {
+			return
+		}
+		seen[ref] = true
+		refs = append(refs, ref)
+	}
+
+	for _, file := range chunk.Metadata.FilesModified {
+		add(ReferenceKindFile, normalizeReferencePath(file))
+	}
+	text := chunk.Content + "\n" + chunk.Summary
+	for _, m := range filePathPattern.FindAllStringSubmatch(text, -1) {
+		add(ReferenceKindFile, normalizeReferencePath(m[1]))
+	}
+
+	symbols := make([]string, 0)
+	for _, m := range inlineCodePattern.FindAllStringSubmatch(text, -1) {
+		if symbol, ok := codeSymbol(m[1]); ok {
+			symbols = append(symbols, symbol)
+		}
+	}
+	for _, m := range funcDeclarationPattern.FindAllStringSubmatch(text, -1) {
+		symbols = append(symbols, m[1])
+	}
+	sort.Strings(symbols)
+	for _, symbol := range symbols {
+		add(ReferenceKindSymbol, symbol)
+	}
+	return refs
+}
+
+// codeSymbol returns the identifier quoted in inline code when it looks like
+// a program symbol rather than a plain word
+func codeSymbol(code string) (string, bool) {
+	m := symbolPattern.FindStringSubmatch(strings.TrimSpace(code))
+	if m == nil || len(m[1]) < 3 || filePathPattern.MatchString(" "+code) {
+		return "", false
+	}
+	symbol := m[1]
+	dotted := strings.Contains(code, ".")
+	called := m[2] != ""
+	mixedCase := strings.ContainsAny(symbol[1:], "ABCDEFGHIJKLMNOPQRSTUVWXYZ") || strings.Contains(symbol, "_")
+	if !dotted && !called && !mixedCase {
+		return "", false
+	}
+	return symbol, true
+}
+
+// normalizeReferencePath cleans a referenced path for comparison
+func normalizeReferencePath(p string) string {
+	p = strings.TrimSpace(strings.ReplaceAll(p, "\\", "/"))
+	if p == "" {
+		return ""
+	}
+	return strings.TrimPrefix(path.Clean(p), "./")
+}
+
+// pathsMatch reports whether two paths name the same file, allowing either
+// to be relative to a parent directory of the other
+func pathsMatch(a, b string) bool {
+	return a == b || strings.HasSuffix(a, "/"+b) || strings.HasSuffix(b, "/"+a)
+}
@@ -0,0 +1,150 @@
+package intelligence
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"lerian-mcp-memory/pkg/types"
+)
+
+func TestExtractCodeReferences(t *testing.T) {
+	chunk := &types.ConversationChunk{
+		Content: "Fixed the retry loop in internal/retry/retry.go:71 by calling `retrier.DoWithData()`. " +
+			"See `Config` and `RetryIf`; the `main` branch and ./cmd/server/main.go are unchanged. " +
+			"Docs at https://example.com/docs and func handleStore(ctx context.Context) were touched.",
+		Metadata: types.ChunkMetadata{FilesModified: []string{"internal/retry/retry.go", "README.md"}},
+	}
+
+	got := make(map[CodeReference]bool)
+	for _, ref := range ExtractCodeReferences(chunk) {
+		got[ref] = true
+	}
+	for _, want := range []CodeReference{
+		{ReferenceKindFile, "internal/retry/retry.go"},
+		{ReferenceKindFile, "README.md"},
+		{ReferenceKindFile, "cmd/server/main.go"},
+		{ReferenceKindSymbol, "DoWithData"},
+		{ReferenceKindSymbol, "RetryIf"},
+		{ReferenceKindSymbol, "handleStore"},
+	} {
+		if !got[want] {
+			t.Errorf("missing reference %+v in %+v", want, got)
+		}
+	}
+	for _, unwanted := range []CodeReference{
+		{ReferenceKindSymbol, "main"},
+		{ReferenceKindSymbol, "Config"},
+	} {
+		if got[unwanted] {
+			t.Errorf("unexpected reference %+v", unwanted)
+		}
+	}
+	if len(got) != 6 {
+		t.Errorf("got %d references, want 6: %+v", len(got), got)
+	}
+}
+
+func TestCodebaseSnapshotCheck(t *testing.T) {
+	snapshot := NewCodebaseSnapshot(
+		[]string{"internal/retry/retry.go", "cmd/server/main.go"},
+		map[string]string{"internal/old/store.go": "internal/storage/store.go"},
+		[]string{"DoWithData"},
+	)
+
+	tests := []struct {
+		ref   string
+		state string
+	}{
+		{"internal/retry/retry.go", ReferenceStatePresent},
+		{"retry/retry.go", ReferenceStatePresent},
+		{"/home/dev/project/cmd/server/main.go", ReferenceStatePresent},
+		{"main.go", ReferenceStatePresent},
+		{"internal/old/store.go", ReferenceStateRenamed},
+		{"internal/gone.go", ReferenceStateMissing},
+	}
+	for _, tt := range tests {
+		if status := snapshot.ResolveFile(tt.ref); status.State != tt.state {
+			t.Errorf("ResolveFile(%q) = %s, want %s", tt.ref, status.State, tt.state)
+		}
+	}
+	if status := snapshot.ResolveFile("old/store.go"); status.RenamedTo != "internal/storage/store.go" {
+		t.Errorf("RenamedTo = %q", status.RenamedTo)
+	}
+
+	chunk := &types.ConversationChunk{
+		ID:       "c1",
+		Content:  "Moved `Retrier.DoWithData()` callers; `legacyFetch()` lives in internal/old/store.go",
+		Metadata: types.ChunkMetadata{FilesModified: []string{"internal/retry/retry.go"}},
+	}
+	result := snapshot.Check(chunk)
+	if result.References != 4 || len(result.Broken) != 2 || !result.IsStale {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if result.Staleness != 0.5 {
+		t.Errorf("Staleness = %v, want 0.5", result.Staleness)
+	}
+
+	// Without a symbol list only files are checked
+	filesOnly := NewCodebaseSnapshot([]string{"internal/retry/retry.go", "internal/old/store.go"}, nil, nil)
+	if result := filesOnly.Check(chunk); result.References != 2 || result.IsStale {
+		t.Errorf("files-only check = %+v", result)
+	}
+}
+
+func TestApplyStaleness(t *testing.T) {
+	quality := &types.QualityMetrics{Completeness: 1, Clarity: 1, FreshnessScore: 1, UsageScore: 1}
+	quality.CalculateOverallQuality()
+	before := quality.OverallQuality
+
+	ApplyStaleness(quality, &StaleReferenceResult{Staleness: 0.5, IsStale: true})
+	if quality.FreshnessScore != 0.5 || quality.RelevanceDecay != 0.5 {
+		t.Errorf("unexpected metrics %+v", quality)
+	}
+	if quality.OverallQuality >= before {
+		t.Errorf("overall quality %v not lowered from %v", quality.OverallQuality, before)
+	}
+}
+
+func TestLoadGitSnapshot(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q")
+	write("store.go", "package x\n\nfunc SaveChunk() {}\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	git("mv", "store.go", "storage.go")
+	git("commit", "-q", "-m", "rename")
+
+	snapshot, err := LoadGitSnapshot(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("LoadGitSnapshot: %v", err)
+	}
+	if status := snapshot.ResolveFile("store.go"); status.State != ReferenceStateRenamed || status.RenamedTo != "storage.go" {
+		t.Errorf("ResolveFile(store.go) = %+v", status)
+	}
+	if status := snapshot.ResolveSymbol("SaveChunk"); status.State != ReferenceStatePresent {
+		t.Errorf("SaveChunk = %s, want present", status.State)
+	}
+	if status := snapshot.ResolveSymbol("LoadChunk"); status.State != ReferenceStateMissing {
+		t.Errorf("LoadChunk = %s, want missing", status.State)
+	}
+}
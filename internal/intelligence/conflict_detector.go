@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"lerian-mcp-memory/internal/llm"
 	"lerian-mcp-memory/pkg/types"
 )
 
@@ -103,6 +104,9 @@ type ConflictDetector struct {
 	enableTemporalAnalysis bool
 	enablePatternAnalysis  bool
 
+	// Optional LLM that confirms heuristic conflicts
+	verifier llm.Provider
+
 	// Keyword sets for different conflict domains
 	architecturalKeywords []string
 	technicalKeywords     []string
@@ -199,6 +203,10 @@ func (cd *ConflictDetector) DetectConflicts(ctx context.Context, chunks []types.
 		return filteredConflicts[i].Confidence > filteredConflicts[j].Confidence
 	})
 
+	if cd.verifier != nil {
+		filteredConflicts = cd.verifyConflicts(ctx, filteredConflicts)
+	}
+
 	result.Conflicts = filteredConflicts
 	result.ConflictsFound = len(filteredConflicts)
 	result.ProcessingTime = time.Since(startTime).String()
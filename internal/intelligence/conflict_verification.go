@@ -0,0 +1,89 @@
+package intelligence
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"lerian-mcp-memory/internal/llm"
+	"lerian-mcp-memory/internal/logging"
+)
+
+const (
+	// maxLLMVerifications bounds LLM calls per detection run; conflicts beyond it are kept unverified
+	maxLLMVerifications = 20
+
+	// maxVerificationExcerpt bounds how much of each chunk is sent to the LLM
+	maxVerificationExcerpt = 1500
+)
+
+// SetVerifier sets an LLM provider that confirms heuristic conflicts. Conflicts
+// the LLM rejects are dropped; errors keep the conflict unverified.
+func (cd *ConflictDetector) SetVerifier(provider llm.Provider) {
+	cd.verifier = provider
+}
+
+// verifyConflicts asks the verifier whether each conflict is a real contradiction
+func (cd *ConflictDetector) verifyConflicts(ctx context.Context, conflicts []Conflict) []Conflict {
+	verified := make([]Conflict, 0, len(conflicts))
+	for i := range conflicts {
+		conflict := conflicts[i]
+		if i >= maxLLMVerifications {
+			verified = append(verified, conflict)
+			continue
+		}
+
+		confirmed, reason, err := cd.verifyConflict(ctx, &conflict)
+		if err != nil {
+			logging.Warn("LLM conflict verification failed", "conflict_id", conflict.ID, "provider", cd.verifier.Name(), "error", err)
+			verified = append(verified, conflict)
+			continue
+		}
+		if !confirmed {
+			continue
+		}
+
+		if conflict.Context == nil {
+			conflict.Context = make(map[string]any)
+		}
+		conflict.Context["llm_verified"] = true
+		conflict.Context["llm_provider"] = cd.verifier.Name()
+		conflict.Context["llm_reason"] = reason
+		verified = append(verified, conflict)
+	}
+	return verified
+}
+
+// verifyConflict returns whether the LLM considers the two chunks contradictory and why
+func (cd *ConflictDetector) verifyConflict(ctx context.Context, conflict *Conflict) (confirmed bool, reason string, err error) {
+	resp, err := cd.verifier.Complete(ctx, llm.Request{
+		System: "You check whether two engineering notes contradict each other. Answer with YES or NO on the first line, then one sentence explaining why.",
+		Prompt: fmt.Sprintf("Suspected %s conflict: %s\n\nNote A (%s):\n%s\n\nNote B (%s):\n%s\n\nDo these notes contradict each other?",
+			conflict.Type, conflict.Title,
+			conflict.PrimaryChunk.Timestamp.Format("2006-01-02"), excerpt(conflict.PrimaryChunk.Content),
+			conflict.ConflictChunk.Timestamp.Format("2006-01-02"), excerpt(conflict.ConflictChunk.Content)),
+		MaxTokens: 100,
+	})
+	if err != nil {
+		return false, "", err
+	}
+
+	answer, reason, _ := strings.Cut(strings.TrimSpace(resp.Text), "\n")
+	answer = strings.ToUpper(strings.TrimSpace(answer))
+	switch {
+	case strings.HasPrefix(answer, "YES"):
+		return true, strings.TrimSpace(reason), nil
+	case strings.HasPrefix(answer, "NO"):
+		return false, strings.TrimSpace(reason), nil
+	default:
+		return false, "", fmt.Errorf("unexpected verification answer: %q", resp.Text)
+	}
+}
+
+// excerpt truncates content for prompts
+func excerpt(content string) string {
+	if len(content) <= maxVerificationExcerpt {
+		return content
+	}
+	return content[:maxVerificationExcerpt] + "..."
+}
@@ -0,0 +1,64 @@
+package intelligence
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"lerian-mcp-memory/internal/llm"
+	"lerian-mcp-memory/pkg/types"
+)
+
+// scriptedProvider answers verification prompts by matching note content
+type scriptedProvider struct {
+	answers map[string]string
+}
+
+func (p *scriptedProvider) Name() string  { return "scripted" }
+func (p *scriptedProvider) Model() string { return "scripted-1" }
+
+func (p *scriptedProvider) Complete(_ context.Context, req llm.Request) (*llm.Response, error) {
+	for needle, answer := range p.answers {
+		if strings.Contains(req.Prompt, needle) {
+			return &llm.Response{Text: answer}, nil
+		}
+	}
+	return nil, errors.New("provider unavailable")
+}
+
+func TestConflictDetector_VerifyConflicts(t *testing.T) {
+	conflict := func(id, content string) Conflict {
+		return Conflict{
+			ID:            id,
+			Type:          ConflictTypeArchitectural,
+			PrimaryChunk:  types.ConversationChunk{Content: content},
+			ConflictChunk: types.ConversationChunk{Content: "other note"},
+		}
+	}
+
+	detector := NewConflictDetector()
+	detector.SetVerifier(&scriptedProvider{answers: map[string]string{
+		"use postgres": "YES\nOne note picks postgres, the other mysql.",
+		"use redis":    "no\nBoth notes agree on redis.",
+	}})
+
+	verified := detector.verifyConflicts(context.Background(), []Conflict{
+		conflict("confirmed", "we use postgres"),
+		conflict("rejected", "we use redis"),
+		conflict("unverified", "provider fails on this one"),
+	})
+
+	if len(verified) != 2 {
+		t.Fatalf("Expected 2 conflicts after verification, got %d", len(verified))
+	}
+	if verified[0].ID != "confirmed" || verified[0].Context["llm_verified"] != true {
+		t.Errorf("Expected confirmed conflict to be marked verified, got %+v", verified[0])
+	}
+	if verified[0].Context["llm_reason"] != "One note picks postgres, the other mysql." {
+		t.Errorf("Unexpected verification reason: %v", verified[0].Context["llm_reason"])
+	}
+	if verified[1].ID != "unverified" || verified[1].Context["llm_verified"] != nil {
+		t.Errorf("Expected conflict to be kept unverified when the provider fails, got %+v", verified[1])
+	}
+}
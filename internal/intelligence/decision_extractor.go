@@ -0,0 +1,199 @@
+package intelligence
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxDecisionAlternatives bounds the alternatives recorded per decision
+const maxDecisionAlternatives = 5
+
+// ExtractedDecision is a decision recognized in free-form conversation text
+type ExtractedDecision struct {
+	// Decision is a normalized statement of what was decided
+	Decision string `json:"decision"`
+	// Chosen is the option picked, when the statement compares options
+	Chosen string `json:"chosen,omitempty"`
+	// Alternatives are the options that were rejected
+	Alternatives []string `json:"alternatives,omitempty"`
+	// Rationale is the reason given for the decision, if any
+	Rationale string `json:"rationale,omitempty"`
+	// Statement is the sentence the decision was recognized in
+	Statement string `json:"statement"`
+	// Confidence grows with how much structure was recognized
+	Confidence float64 `json:"confidence"`
+}
+
+// Decision phrasings. Rationale clauses are matched separately so every
+// phrasing accepts the same connectives.
+var (
+	// "we chose X over Y", "went with X instead of Y", "picked X rather than Y"
+	comparativeDecisionPattern = regexp.MustCompile(`(?i)\b(?:chose|choose|picked|selected|opted for|went with|going with|go with|settled on|prefer(?:red)?)\s+(.+?)\s+(?:over|instead of|rather than|in favou?r of)\s+(.+)$`)
+
+	// "we decided to X", "the team agreed on X", "we settled on X"
+	decidedPattern = regexp.MustCompile(`(?i)\b(?:we|i|team|they|everyone)\s+(?:have\s+|had\s+|finally\s+|all\s+)?(?:decided|agreed|resolved|settled)\s+(?:to|on|that|upon)\s+(.+)$`)
+
+	// "we chose X", "we'll go with X", "opted for X"
+	choicePattern = regexp.MustCompile(`(?i)\b(?:we|i|team)\s*(?:'ll|will|are|'re)?\s+(?:chose|picked|selected|opted for|went with|go with|going with|settled on)\s+(.+)$`)
+
+	// "Decision: X"
+	labeledDecisionPattern = regexp.MustCompile(`(?i)^(?:final\s+|the\s+)?decision\s*[:\-]\s*(.+)$`)
+
+	// rationaleClausePattern splits "X because Y" into decision and rationale
+	rationaleClausePattern = regexp.MustCompile(`(?i)^(.+?)[,;]?\s+(?:because|since|due to|as it|as they|so that|given that)\s+(.+)$`)
+
+	// rationaleSentencePattern recognizes a following sentence that explains the decision
+	rationaleSentencePattern = regexp.MustCompile(`(?i)^(?:because|this is because|the reason (?:is|was)|rationale\s*:|reasoning\s*:)\s*(.+)$`)
+
+	// alternativeSeparatorPattern splits "A, B or C" into options
+	alternativeSeparatorPattern = regexp.MustCompile(`(?i)\s*(?:,\s*(?:and|or)?\s*|\s+(?:and|or|nor)\s+)\s*`)
+
+	// sentenceBoundaryPattern ends a sentence at terminal punctuation or a line break
+	sentenceBoundaryPattern = regexp.MustCompile(`[.!?]+(?:\s+|$)|\n+`)
+
+	// adoptionPattern finds the compared options inside a decided clause,
+	// as in "decided to use X instead of Y"
+	adoptionPattern = regexp.MustCompile(`(?i)^(?:use|adopt|keep|switch to|move to|migrate to|go with)?\s*(.+?)\s+(?:over|instead of|rather than|in favou?r of)\s+(.+)$`)
+
+	// undecidedPattern rejects sentences that report the absence of a decision
+	undecidedPattern = regexp.MustCompile(`(?i)\b(?:undecided|tbd|to be decided|yet to decide|still deciding)\b`)
+)
+
+// ExtractDecisions recognizes decision-like statements in content such as
+// "we decided to…" or "chose X over Y because…", returning at most
+// maxExtractedDecisions structured decisions in order of appearance.
+func ExtractDecisions(content string) []ExtractedDecision {
+	sentences := splitSentences(content)
+	decisions := make([]ExtractedDecision, 0)
+	seen := make(map[string]bool)
+
+	for i, sentence := range sentences {
+		decision, ok := parseDecisionSentence(sentence)
+		if !ok {
+			continue
+		}
+		if decision.Rationale == "" && i+1 < len(sentences) {
+			if m := rationaleSentencePattern.FindStringSubmatch(sentences[i+1]); m != nil {
+				decision.Rationale = cleanClause(m[1])
+			}
+		}
+		if decision.Rationale != "" {
+			decision.Confidence += 0.2
+		}
+
+		key := strings.ToLower(decision.Decision)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		decisions = append(decisions, decision)
+		if len(decisions) == maxExtractedDecisions {
+			break
+		}
+	}
+	return decisions
+}
+
+// parseDecisionSentence matches one sentence against the decision phrasings
+func parseDecisionSentence(sentence string) (ExtractedDecision, bool) {
+	if strings.HasSuffix(sentence, "?") || undecidedPattern.MatchString(sentence) {
+		return ExtractedDecision{}, false
+	}
+
+	decision := ExtractedDecision{Statement: sentence}
+
+	if m := comparativeDecisionPattern.FindStringSubmatch(sentence); m != nil {
+		alternatives, rationale := splitRationale(m[2])
+		decision.Chosen = cleanClause(m[1])
+		decision.Alternatives = splitAlternatives(alternatives)
+		decision.Rationale = rationale
+		if decision.Chosen == "" || len(decision.Alternatives) == 0 {
+			return ExtractedDecision{}, false
+		}
+		decision.Decision = "Chose " + decision.Chosen + " over " + strings.Join(decision.Alternatives, ", ")
+		decision.Confidence = 0.7
+		return decision, true
+	}
+
+	for _, pattern := range []*regexp.Regexp{decidedPattern, choicePattern, labeledDecisionPattern} {
+		m := pattern.FindStringSubmatch(sentence)
+		if m == nil {
+			continue
+		}
+		what, rationale := splitRationale(m[1])
+		if what == "" {
+			return ExtractedDecision{}, false
+		}
+		decision.Decision = capitalize(what)
+		decision.Rationale = rationale
+		decision.Confidence = 0.6
+		if options := adoptionPattern.FindStringSubmatch(what); options != nil {
+			decision.Chosen = cleanClause(options[1])
+			decision.Alternatives = splitAlternatives(options[2])
+			decision.Confidence = 0.7
+		} else if pattern == choicePattern {
+			decision.Chosen = what
+		}
+		return decision, true
+	}
+
+	return ExtractedDecision{}, false
+}
+
+// splitRationale separates a trailing "because …" clause from a decision clause
+func splitRationale(clause string) (what, rationale string) {
+	if m := rationaleClausePattern.FindStringSubmatch(clause); m != nil {
+		return cleanClause(m[1]), cleanClause(m[2])
+	}
+	return cleanClause(clause), ""
+}
+
+// splitAlternatives turns "A, B or C" into a list of options
+func splitAlternatives(clause string) []string {
+	alternatives := make([]string, 0)
+	for _, option := range alternativeSeparatorPattern.Split(clause, -1) {
+		if option = cleanClause(option); option != "" && len(alternatives) < maxDecisionAlternatives {
+			alternatives = append(alternatives, option)
+		}
+	}
+	return alternatives
+}
+
+// splitSentences breaks content into trimmed, non-empty sentences, keeping
+// a trailing question mark so questions can be recognized
+func splitSentences(content string) []string {
+	sentences := make([]string, 0)
+	last := 0
+	for _, loc := range sentenceBoundaryPattern.FindAllStringIndex(content, -1) {
+		sentence := content[last:loc[1]]
+		last = loc[1]
+		sentence = strings.TrimSpace(sentence)
+		question := strings.HasSuffix(sentence, "?")
+		if sentence = strings.TrimRight(sentence, "!.?"); question {
+			sentence += "?"
+		}
+		if sentence != "" {
+			sentences = append(sentences, strings.TrimLeft(sentence, "-*• "))
+		}
+	}
+	if tail := strings.TrimSpace(content[last:]); tail != "" {
+		sentences = append(sentences, strings.TrimLeft(tail, "-*• "))
+	}
+	return sentences
+}
+
+// cleanClause trims whitespace and trailing punctuation from a clause
+func cleanClause(clause string) string {
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(clause), ".,;:!"))
+}
+
+// capitalize upper-cases the first letter of s
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
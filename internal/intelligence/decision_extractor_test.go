@@ -0,0 +1,103 @@
+package intelligence
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractDecisions(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		decision     string
+		chosen       string
+		alternatives []string
+		rationale    string
+	}{
+		{
+			name:         "comparative with rationale",
+			content:      "After the load test we chose Postgres over MySQL and MongoDB because we need transactional DDL.",
+			decision:     "Chose Postgres over MySQL, MongoDB",
+			chosen:       "Postgres",
+			alternatives: []string{"MySQL", "MongoDB"},
+			rationale:    "we need transactional DDL",
+		},
+		{
+			name:      "decided to",
+			content:   "We decided to cache sessions in Redis since lookups dominate latency.",
+			decision:  "Cache sessions in Redis",
+			rationale: "lookups dominate latency",
+		},
+		{
+			name:         "decided to use instead of",
+			content:      "The team agreed to use gRPC instead of REST for internal calls.",
+			decision:     "Use gRPC instead of REST for internal calls",
+			chosen:       "gRPC",
+			alternatives: []string{"REST for internal calls"},
+		},
+		{
+			name:      "rationale in following sentence",
+			content:   "We'll go with feature flags. The reason is that rollbacks must not need a deploy.",
+			decision:  "Feature flags",
+			chosen:    "feature flags",
+			rationale: "that rollbacks must not need a deploy",
+		},
+		{
+			name:     "labeled decision",
+			content:  "Notes from sync\nDecision: retire the legacy importer",
+			decision: "Retire the legacy importer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decisions := ExtractDecisions(tt.content)
+			if len(decisions) != 1 {
+				t.Fatalf("got %d decisions, want 1: %+v", len(decisions), decisions)
+			}
+			d := decisions[0]
+			if d.Decision != tt.decision {
+				t.Errorf("Decision = %q, want %q", d.Decision, tt.decision)
+			}
+			if d.Chosen != tt.chosen {
+				t.Errorf("Chosen = %q, want %q", d.Chosen, tt.chosen)
+			}
+			if len(tt.alternatives) > 0 && !reflect.DeepEqual(d.Alternatives, tt.alternatives) {
+				t.Errorf("Alternatives = %q, want %q", d.Alternatives, tt.alternatives)
+			}
+			if d.Rationale != tt.rationale {
+				t.Errorf("Rationale = %q, want %q", d.Rationale, tt.rationale)
+			}
+			if d.Statement == "" || d.Confidence <= 0 {
+				t.Errorf("missing statement or confidence: %+v", d)
+			}
+		})
+	}
+}
+
+func TestExtractDecisionsIgnoresNonDecisions(t *testing.T) {
+	for _, content := range []string{
+		"Should we choose Postgres over MySQL?",
+		"We are still deciding on the queue; options are Kafka or NATS.",
+		"Decision: TBD",
+		"Fixed the flaky test by resetting the clock between runs.",
+	} {
+		if decisions := ExtractDecisions(content); len(decisions) != 0 {
+			t.Errorf("ExtractDecisions(%q) = %+v, want none", content, decisions)
+		}
+	}
+}
+
+func TestExtractDecisionsDeduplicatesAndLimits(t *testing.T) {
+	content := "We decided to use Go. We decided to use Go. " +
+		"We decided to add tracing. We decided to drop Python 2. " +
+		"We decided to pin Node. We decided to vendor deps. We decided to ship weekly."
+
+	decisions := ExtractDecisions(content)
+	if len(decisions) != maxExtractedDecisions {
+		t.Fatalf("got %d decisions, want %d", len(decisions), maxExtractedDecisions)
+	}
+	if decisions[0].Decision != "Use Go" || decisions[1].Decision != "Add tracing" {
+		t.Errorf("unexpected order or duplicates: %+v", decisions[:2])
+	}
+}
@@ -313,6 +313,11 @@ func (fm *FreshnessManager) GetStaleMemories(ctx context.Context, repository str
 	return staleChunks, nil
 }
 
+// Summarize aggregates freshness results by repository and type and rates overall health
+func (fm *FreshnessManager) Summarize(results []ChunkFreshnessResult) FreshnessSummary {
+	return fm.generateFreshnessSummary(results)
+}
+
 // Private helper methods
 
 func (fm *FreshnessManager) determineDecayRate(chunk *types.ConversationChunk) float64 {
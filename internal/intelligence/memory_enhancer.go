@@ -0,0 +1,124 @@
+package intelligence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"lerian-mcp-memory/internal/llm"
+	"lerian-mcp-memory/pkg/types"
+)
+
+const (
+	// maxSuggestedTags bounds the tags an enrichment may add
+	maxSuggestedTags = 5
+
+	// maxExtractedDecisions bounds the decisions kept per chunk
+	maxExtractedDecisions = 5
+
+	// maxEnrichmentContent bounds how much chunk content is sent to the LLM
+	maxEnrichmentContent = 6000
+)
+
+// tagPattern accepts lowercase kebab-case tags
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,39}$`)
+
+// Enrichment is the AI-generated additions for a chunk
+type Enrichment struct {
+	Summary   string   `json:"summary"`
+	Decisions []string `json:"decisions"`
+	Tags      []string `json:"tags"`
+}
+
+// LLMMemoryEnhancer adapts an LLM provider to the chunk store pipeline,
+// producing better summaries, extracted decisions and suggested tags
+type LLMMemoryEnhancer struct {
+	provider llm.Provider
+}
+
+// NewLLMMemoryEnhancer creates an enhancer backed by provider
+func NewLLMMemoryEnhancer(provider llm.Provider) *LLMMemoryEnhancer {
+	return &LLMMemoryEnhancer{provider: provider}
+}
+
+// Enhance asks the LLM for an enrichment and applies it to the chunk
+func (e *LLMMemoryEnhancer) Enhance(ctx context.Context, chunk *types.ConversationChunk) error {
+	content := chunk.Content
+	if len(content) > maxEnrichmentContent {
+		content = content[:maxEnrichmentContent] + "..."
+	}
+
+	resp, err := e.provider.Complete(ctx, llm.Request{
+		System: "You enrich engineering memory notes. Reply with a JSON object only: " +
+			`{"summary": "one or two sentences", "decisions": ["decisions made, if any"], "tags": ["up to 5 lowercase kebab-case topic tags"]}`,
+		Prompt:    fmt.Sprintf("Type: %s\nRepository: %s\n\n%s", chunk.Type, chunk.Metadata.Repository, content),
+		MaxTokens: 400,
+	})
+	if err != nil {
+		return fmt.Errorf("enrichment request failed: %w", err)
+	}
+
+	enrichment, err := ParseEnrichment(resp.Text)
+	if err != nil {
+		return err
+	}
+	ApplyEnrichment(chunk, enrichment, e.provider.Name())
+	return nil
+}
+
+// ParseEnrichment decodes an LLM reply, tolerating markdown code fences
+func ParseEnrichment(text string) (*Enrichment, error) {
+	text = strings.TrimSpace(text)
+	if start, end := strings.Index(text, "{"), strings.LastIndex(text, "}"); start >= 0 && end > start {
+		text = text[start : end+1]
+	}
+
+	var enrichment Enrichment
+	if err := json.Unmarshal([]byte(text), &enrichment); err != nil {
+		return nil, fmt.Errorf("invalid enrichment response: %w", err)
+	}
+	return &enrichment, nil
+}
+
+// ApplyEnrichment replaces the summary when one was produced, records extracted
+// decisions and merges valid suggested tags into the chunk's tags
+func ApplyEnrichment(chunk *types.ConversationChunk, enrichment *Enrichment, source string) {
+	if summary := strings.TrimSpace(enrichment.Summary); summary != "" {
+		chunk.Summary = summary
+	}
+
+	if chunk.Metadata.ExtendedMetadata == nil {
+		chunk.Metadata.ExtendedMetadata = make(map[string]interface{})
+	}
+	chunk.Metadata.ExtendedMetadata[types.EMKeyEnrichedBy] = source
+
+	decisions := make([]string, 0, len(enrichment.Decisions))
+	for _, d := range enrichment.Decisions {
+		if d = strings.TrimSpace(d); d != "" && len(decisions) < maxExtractedDecisions {
+			decisions = append(decisions, d)
+		}
+	}
+	if len(decisions) > 0 {
+		chunk.Metadata.ExtendedMetadata[types.EMKeyExtractedDecisions] = decisions
+	}
+
+	existing := make(map[string]bool, len(chunk.Metadata.Tags))
+	for _, tag := range chunk.Metadata.Tags {
+		existing[tag] = true
+	}
+	suggested := make([]string, 0, maxSuggestedTags)
+	for _, tag := range enrichment.Tags {
+		tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), " ", "-"))
+		if !tagPattern.MatchString(tag) || existing[tag] || len(suggested) == maxSuggestedTags {
+			continue
+		}
+		existing[tag] = true
+		suggested = append(suggested, tag)
+	}
+	if len(suggested) > 0 {
+		chunk.Metadata.ExtendedMetadata[types.EMKeyAutoTags] = suggested
+		chunk.Metadata.Tags = append(chunk.Metadata.Tags, suggested...)
+	}
+}
@@ -0,0 +1,53 @@
+package intelligence
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"lerian-mcp-memory/pkg/types"
+)
+
+func TestLLMMemoryEnhancer_Enhance(t *testing.T) {
+	provider := &scriptedProvider{answers: map[string]string{
+		"connection pool": "```json\n" + `{"summary": "Raised the postgres pool size to fix exhaustion.", ` +
+			`"decisions": ["Use a pool size of 50", " "], "tags": ["Postgres", "connection pool", "database", "not a valid tag!"]}` + "\n```",
+	}}
+	chunk := &types.ConversationChunk{
+		Type:    types.ChunkTypeSolution,
+		Content: "The connection pool was exhausted, so we raised it to 50.",
+		Summary: "extractive summary",
+		Metadata: types.ChunkMetadata{
+			Tags: []string{"database"},
+		},
+	}
+
+	if err := NewLLMMemoryEnhancer(provider).Enhance(context.Background(), chunk); err != nil {
+		t.Fatalf("Enhance failed: %v", err)
+	}
+
+	if chunk.Summary != "Raised the postgres pool size to fix exhaustion." {
+		t.Errorf("Expected AI summary, got %q", chunk.Summary)
+	}
+	if want := []string{"database", "postgres", "connection-pool"}; !reflect.DeepEqual(chunk.Metadata.Tags, want) {
+		t.Errorf("Expected tags %v, got %v", want, chunk.Metadata.Tags)
+	}
+	if want := []string{"Use a pool size of 50"}; !reflect.DeepEqual(chunk.Metadata.ExtendedMetadata[types.EMKeyExtractedDecisions], want) {
+		t.Errorf("Expected decisions %v, got %v", want, chunk.Metadata.ExtendedMetadata[types.EMKeyExtractedDecisions])
+	}
+	if chunk.Metadata.ExtendedMetadata[types.EMKeyEnrichedBy] != "scripted" {
+		t.Errorf("Expected enrichment source to be recorded")
+	}
+}
+
+func TestLLMMemoryEnhancer_InvalidResponse(t *testing.T) {
+	provider := &scriptedProvider{answers: map[string]string{"": "I cannot help with that"}}
+	chunk := &types.ConversationChunk{Content: "anything", Summary: "kept"}
+
+	if err := NewLLMMemoryEnhancer(provider).Enhance(context.Background(), chunk); err == nil {
+		t.Fatal("Expected an error for a non-JSON response")
+	}
+	if chunk.Summary != "kept" || chunk.Metadata.ExtendedMetadata != nil {
+		t.Errorf("Expected chunk to be unchanged, got %+v", chunk)
+	}
+}
@@ -0,0 +1,483 @@
+package intelligence
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"lerian-mcp-memory/pkg/types"
+)
+
+// Detectors that mine patterns, recorded in a detected pattern's context
+const (
+	DetectorErrorFix  = "error_fix"
+	DetectorToolChain = "tool_chain"
+)
+
+// maxSignatureLength bounds the error line kept as an error signature
+const maxSignatureLength = 80
+
+var (
+	// errorLinePattern finds the line of a problem that names the error
+	errorLinePattern = regexp.MustCompile(`(?im)^.*\b(error|exception|fatal|panic|failed|failure)\b.*$`)
+	// volatilePattern matches the parts of an error line that change between
+	// occurrences: quoted values, hex IDs, paths and numbers
+	volatilePattern = regexp.MustCompile(`"[^"]*"|'[^']*'|0x[0-9a-f]+|\b[0-9a-f]{8,}\b|(?:/[\w.-]+)+|\d+`)
+)
+
+// ToolChain is the tools used, in order, on the way to an outcome: the tools
+// a chunk records or a sequence tracked by the workflow analyzer
+type ToolChain struct {
+	SessionID string
+	ChunkIDs  []string
+	Tools     []string
+	Success   bool
+	Timestamp time.Time
+}
+
+// DetectionOptions bound pattern mining
+type DetectionOptions struct {
+	// MinFrequency is how often a sequence must recur to be a pattern
+	MinFrequency int
+	// MinSimilarity is the keyword overlap an error→fix pair needs to join a cluster
+	MinSimilarity float64
+	// MinChainLength and MaxChainLength bound the tool chains mined
+	MinChainLength int
+	MaxChainLength int
+	// MaxExamples is how many occurrences a pattern keeps as examples
+	MaxExamples int
+}
+
+// DefaultDetectionOptions returns the options DetectPatterns uses for unset fields
+func DefaultDetectionOptions() DetectionOptions {
+	return DetectionOptions{
+		MinFrequency:   2,
+		MinSimilarity:  0.3,
+		MinChainLength: 2,
+		MaxChainLength: 5,
+		MaxExamples:    3,
+	}
+}
+
+// withDefaults fills unset options from DefaultDetectionOptions
+func (o DetectionOptions) withDefaults() DetectionOptions {
+	defaults := DefaultDetectionOptions()
+	if o.MinFrequency < 2 {
+		o.MinFrequency = defaults.MinFrequency
+	}
+	if o.MinSimilarity <= 0 || o.MinSimilarity > 1 {
+		o.MinSimilarity = defaults.MinSimilarity
+	}
+	if o.MinChainLength < 2 {
+		o.MinChainLength = defaults.MinChainLength
+	}
+	if o.MaxChainLength < o.MinChainLength {
+		o.MaxChainLength = max(defaults.MaxChainLength, o.MinChainLength)
+	}
+	if o.MaxExamples <= 0 {
+		o.MaxExamples = defaults.MaxExamples
+	}
+	return o
+}
+
+// errorFix is a problem chunk and the chunk that fixed it
+type errorFix struct {
+	problem   *types.ConversationChunk
+	fix       *types.ConversationChunk
+	signature string
+	keywords  []string
+}
+
+// errorFixCluster is a group of error→fix pairs about the same error
+type errorFixCluster struct {
+	signature string
+	keywords  map[string]int
+	pairs     []errorFix
+}
+
+// DetectPatterns mines a repository's chunks and tool chains for recurring
+// sequences: problems followed in their session by the solution or code
+// change that fixed them, clustered by error signature or keyword overlap,
+// and tool chains recurring across chains. Sequences recurring at least
+// MinFrequency times become named patterns with their frequency, success
+// rate and examples. Patterns get IDs derived from what they match, so
+// detecting again updates the stored pattern instead of adding another.
+func (pe *PatternEngine) DetectPatterns(ctx context.Context, repository string, chunks []types.ConversationChunk, chains []ToolChain, options DetectionOptions) ([]Pattern, error) {
+	options = options.withDefaults()
+	now := time.Now().UTC()
+
+	detected := pe.detectErrorFixPatterns(repository, chunks, options, now)
+	detected = append(detected, detectToolChainPatterns(repository, chains, options, now)...)
+	sortPatterns(detected)
+
+	if pe.storage == nil {
+		return detected, nil
+	}
+	for i := range detected {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		pattern := &detected[i]
+		if stored, err := pe.storage.GetPattern(ctx, pattern.ID); err == nil && stored != nil {
+			pattern.CreatedAt = stored.CreatedAt
+			if err := pe.storage.UpdatePattern(ctx, pattern); err != nil {
+				return nil, fmt.Errorf("failed to update pattern %s: %w", pattern.ID, err)
+			}
+			continue
+		}
+		if err := pe.storage.StorePattern(ctx, pattern); err != nil {
+			return nil, fmt.Errorf("failed to store pattern %s: %w", pattern.ID, err)
+		}
+	}
+	return detected, nil
+}
+
+// detectErrorFixPatterns pairs each problem with the fix that followed it in
+// its session and turns recurring clusters of pairs into patterns
+func (pe *PatternEngine) detectErrorFixPatterns(repository string, chunks []types.ConversationChunk, options DetectionOptions, now time.Time) []Pattern {
+	var clusters []*errorFixCluster
+	for _, pair := range errorFixPairs(chunks) {
+		cluster := closestCluster(clusters, &pair, options.MinSimilarity)
+		if cluster == nil {
+			cluster = &errorFixCluster{signature: pair.signature, keywords: make(map[string]int)}
+			clusters = append(clusters, cluster)
+		}
+		cluster.pairs = append(cluster.pairs, pair)
+		for _, keyword := range pair.keywords {
+			cluster.keywords[keyword]++
+		}
+	}
+
+	var patterns []Pattern
+	for _, cluster := range clusters {
+		if len(cluster.pairs) < options.MinFrequency {
+			continue
+		}
+		keywords := topKeywords(cluster.keywords, 5)
+		key := cluster.signature
+		if key == "" {
+			key = strings.Join(keywords, " ")
+		}
+		name := "Fix for " + key
+		if cluster.signature == "" {
+			name = "Fix for " + strings.Join(keywords[:min(3, len(keywords))], ", ") + " problems"
+		}
+
+		successes := 0
+		var triggers, outcomes []string
+		var examples []PatternExample
+		lastSeen := time.Time{}
+		for i := range cluster.pairs {
+			pair := &cluster.pairs[i]
+			outcome := chunkOutcome(pair.fix)
+			if outcome == OutcomeSuccess {
+				successes++
+			}
+			triggers = append(triggers, chunkHeadline(pair.problem))
+			outcomes = append(outcomes, chunkHeadline(pair.fix))
+			if len(examples) < options.MaxExamples {
+				examples = append(examples, PatternExample{
+					ID:         pair.problem.ID + ":" + pair.fix.ID,
+					ChunkIDs:   []string{pair.problem.ID, pair.fix.ID},
+					Outcome:    outcome,
+					Confidence: 1.0,
+					Timestamp:  pair.fix.Timestamp,
+				})
+			}
+			if pair.fix.Timestamp.After(lastSeen) {
+				lastSeen = pair.fix.Timestamp
+			}
+		}
+
+		frequency := len(cluster.pairs)
+		patterns = append(patterns, Pattern{
+			ID:          detectedPatternID(repository, DetectorErrorFix, key),
+			Type:        PatternTypeErrorResolution,
+			Name:        name,
+			Description: fmt.Sprintf("%d problems about %s were fixed the same way; look at how earlier occurrences were resolved first", frequency, strings.Join(keywords, ", ")),
+			Confidence:  frequencyConfidence(frequency),
+			Frequency:   frequency,
+			SuccessRate: float64(successes) / float64(frequency),
+			Keywords:    keywords,
+			Triggers:    limitStrings(unique(triggers), options.MaxExamples),
+			Outcomes:    limitStrings(unique(outcomes), options.MaxExamples),
+			Steps: []PatternStep{
+				{Order: 1, Action: "error", Description: "The problem is reported", Confidence: 1.0},
+				{Order: 2, Action: "fix", Description: "A solution or code change resolves it", Confidence: float64(successes) / float64(frequency)},
+			},
+			Context:   map[string]any{"repository": repository, "detector": DetectorErrorFix, "signature": cluster.signature},
+			Examples:  examples,
+			CreatedAt: now,
+			UpdatedAt: now,
+			LastUsed:  lastSeen,
+		})
+	}
+	return patterns
+}
+
+// errorFixPairs pairs each problem chunk with the first solution or code
+// change after it in the same session, before the next problem
+func errorFixPairs(chunks []types.ConversationChunk) []errorFix {
+	sessions := make(map[string][]*types.ConversationChunk)
+	var order []string
+	for i := range chunks {
+		chunk := &chunks[i]
+		if chunk.IsDeleted() {
+			continue
+		}
+		if _, ok := sessions[chunk.SessionID]; !ok {
+			order = append(order, chunk.SessionID)
+		}
+		sessions[chunk.SessionID] = append(sessions[chunk.SessionID], chunk)
+	}
+
+	var pairs []errorFix
+	for _, sessionID := range order {
+		session := sessions[sessionID]
+		sort.SliceStable(session, func(i, j int) bool { return session[i].Timestamp.Before(session[j].Timestamp) })
+
+		var problem *types.ConversationChunk
+		for _, chunk := range session {
+			switch chunk.Type {
+			case types.ChunkTypeProblem:
+				problem = chunk
+			case types.ChunkTypeSolution, types.ChunkTypeCodeChange:
+				if problem == nil {
+					continue
+				}
+				pairs = append(pairs, errorFix{
+					problem:   problem,
+					fix:       chunk,
+					signature: errorSignature(problem.Content),
+					keywords:  contentKeywords(problem.Content, 8),
+				})
+				problem = nil
+			}
+		}
+	}
+	return pairs
+}
+
+// closestCluster returns the cluster pair belongs to: the one with its error
+// signature, or else the one whose keywords overlap its own the most, if
+// they overlap by at least minSimilarity
+func closestCluster(clusters []*errorFixCluster, pair *errorFix, minSimilarity float64) *errorFixCluster {
+	var best *errorFixCluster
+	bestSimilarity := minSimilarity
+	for _, cluster := range clusters {
+		if pair.signature != "" || cluster.signature != "" {
+			if pair.signature == cluster.signature {
+				return cluster
+			}
+			continue
+		}
+		if similarity := calculateOverlap(topKeywords(cluster.keywords, 8), pair.keywords); similarity >= bestSimilarity {
+			best, bestSimilarity = cluster, similarity
+		}
+	}
+	return best
+}
+
+// detectToolChainPatterns counts the runs of consecutive tools that recur
+// across chains and keeps the longest: a run is dropped when a longer one
+// containing it recurs as often
+func detectToolChainPatterns(repository string, chains []ToolChain, options DetectionOptions, now time.Time) []Pattern {
+	type occurrence struct {
+		chain *ToolChain
+	}
+	runs := make(map[string][]occurrence)
+	for i := range chains {
+		chain := &chains[i]
+		tools := normalizeTools(chain.Tools)
+		seen := make(map[string]bool)
+		for length := options.MinChainLength; length <= min(options.MaxChainLength, len(tools)); length++ {
+			for start := 0; start+length <= len(tools); start++ {
+				key := strings.Join(tools[start:start+length], " → ")
+				if !seen[key] {
+					seen[key] = true
+					runs[key] = append(runs[key], occurrence{chain: chain})
+				}
+			}
+		}
+	}
+
+	frequent := make(map[string]int)
+	for key, occurrences := range runs {
+		if len(occurrences) >= options.MinFrequency {
+			frequent[key] = len(occurrences)
+		}
+	}
+
+	var patterns []Pattern
+	for key, frequency := range frequent {
+		if subsumed(key, frequency, frequent) {
+			continue
+		}
+		tools := strings.Split(key, " → ")
+		successes := 0
+		var examples []PatternExample
+		lastSeen := time.Time{}
+		for _, occurrence := range runs[key] {
+			outcome := OutcomeFailure
+			if occurrence.chain.Success {
+				outcome = OutcomeSuccess
+				successes++
+			}
+			if len(examples) < options.MaxExamples {
+				examples = append(examples, PatternExample{
+					ID:         occurrence.chain.SessionID + ":" + strings.Join(occurrence.chain.ChunkIDs, ","),
+					ChunkIDs:   occurrence.chain.ChunkIDs,
+					Outcome:    outcome,
+					Confidence: 1.0,
+					Timestamp:  occurrence.chain.Timestamp,
+				})
+			}
+			if occurrence.chain.Timestamp.After(lastSeen) {
+				lastSeen = occurrence.chain.Timestamp
+			}
+		}
+
+		steps := make([]PatternStep, len(tools))
+		for i, tool := range tools {
+			steps[i] = PatternStep{Order: i + 1, Action: tool, Description: "Use " + tool, Confidence: 1.0}
+		}
+		patterns = append(patterns, Pattern{
+			ID:          detectedPatternID(repository, DetectorToolChain, key),
+			Type:        PatternTypeWorkflow,
+			Name:        "Tool chain: " + key,
+			Description: fmt.Sprintf("The tools %s were used in this order %d times", key, frequency),
+			Confidence:  frequencyConfidence(frequency),
+			Frequency:   frequency,
+			SuccessRate: float64(successes) / float64(frequency),
+			Keywords:    unique(tools),
+			Triggers:    []string{tools[0]},
+			Outcomes:    []string{tools[len(tools)-1]},
+			Steps:       steps,
+			Context:     map[string]any{"repository": repository, "detector": DetectorToolChain},
+			Examples:    examples,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+			LastUsed:    lastSeen,
+		})
+	}
+	return patterns
+}
+
+// subsumed reports whether a longer frequent run contains key and recurs as often
+func subsumed(key string, frequency int, frequent map[string]int) bool {
+	for other, otherFrequency := range frequent {
+		if len(other) > len(key) && otherFrequency >= frequency && strings.Contains(" → "+other+" → ", " → "+key+" → ") {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeTools lowercases tool names and collapses repeated uses of a tool
+func normalizeTools(tools []string) []string {
+	normalized := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		tool = strings.ToLower(strings.TrimSpace(tool))
+		if tool == "" || (len(normalized) > 0 && normalized[len(normalized)-1] == tool) {
+			continue
+		}
+		normalized = append(normalized, tool)
+	}
+	return normalized
+}
+
+// errorSignature returns the error line of a problem with the parts that
+// change between occurrences blanked out, or "" when it names no error
+func errorSignature(content string) string {
+	line := errorLinePattern.FindString(content)
+	if line == "" {
+		return ""
+	}
+	line = volatilePattern.ReplaceAllString(strings.ToLower(line), "_")
+	line = strings.Join(strings.Fields(line), " ")
+	if len(line) > maxSignatureLength {
+		line = strings.TrimSpace(line[:maxSignatureLength])
+	}
+	return line
+}
+
+// contentKeywords returns the most frequent words of content, longest
+// first among equals
+func contentKeywords(content string, limit int) []string {
+	counts := make(map[string]int)
+	for _, word := range strings.FieldsFunc(strings.ToLower(content), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}) {
+		if len(word) > 3 && !isStopWord(word) {
+			counts[word]++
+		}
+	}
+	return topKeywords(counts, limit)
+}
+
+// topKeywords returns the limit most counted keywords
+func topKeywords(counts map[string]int, limit int) []string {
+	keywords := make([]string, 0, len(counts))
+	for keyword := range counts {
+		keywords = append(keywords, keyword)
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		if counts[keywords[i]] != counts[keywords[j]] {
+			return counts[keywords[i]] > counts[keywords[j]]
+		}
+		return keywords[i] < keywords[j]
+	})
+	return limitStrings(keywords, limit)
+}
+
+// chunkOutcome maps a chunk's outcome to a pattern outcome
+func chunkOutcome(chunk *types.ConversationChunk) PatternOutcome {
+	switch chunk.Metadata.Outcome {
+	case types.OutcomeSuccess:
+		return OutcomeSuccess
+	case types.OutcomeFailed:
+		return OutcomeFailure
+	case types.OutcomeInProgress:
+		return OutcomePartial
+	case types.OutcomeAbandoned:
+		return OutcomeInterrupted
+	}
+	return OutcomeUnknown
+}
+
+// chunkHeadline returns a chunk's summary, or the first line of its content
+func chunkHeadline(chunk *types.ConversationChunk) string {
+	if chunk.Summary != "" {
+		return chunk.Summary
+	}
+	headline, _, _ := strings.Cut(strings.TrimSpace(chunk.Content), "\n")
+	if len(headline) > 120 {
+		headline = headline[:120]
+	}
+	return headline
+}
+
+// frequencyConfidence grows with how often a pattern recurred
+func frequencyConfidence(frequency int) float64 {
+	return float64(frequency) / float64(frequency+1)
+}
+
+// detectedPatternID derives a pattern's ID from what it matches
+func detectedPatternID(repository, detector, key string) string {
+	sum := sha256.Sum256([]byte(repository + "\x00" + detector + "\x00" + key))
+	return "pattern_" + hex.EncodeToString(sum[:8])
+}
+
+// limitStrings returns at most limit strings
+func limitStrings(values []string, limit int) []string {
+	if len(values) > limit {
+		return values[:limit]
+	}
+	return values
+}
@@ -0,0 +1,170 @@
+package intelligence
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/pkg/types"
+)
+
+func newDetectionChunk(t *testing.T, sessionID, content string, chunkType types.ChunkType, at time.Time) types.ConversationChunk {
+	t.Helper()
+	chunk, err := types.NewConversationChunk(sessionID, content, chunkType, &types.ChunkMetadata{
+		Repository: "acme/api",
+		Outcome:    types.OutcomeSuccess,
+		Difficulty: types.DifficultySimple,
+	})
+	if err != nil {
+		t.Fatalf("failed to create chunk: %v", err)
+	}
+	chunk.Timestamp = at
+	return *chunk
+}
+
+func TestDetectErrorFixPatterns(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	var chunks []types.ConversationChunk
+	for i, session := range []string{"s1", "s2", "s3"} {
+		at := start.Add(time.Duration(i) * 10 * time.Minute)
+		chunks = append(chunks,
+			newDetectionChunk(t, session, "Running migrations\nerror: dial tcp 10.0.0."+string(rune('1'+i))+":5432: connection refused", types.ChunkTypeProblem, at),
+			newDetectionChunk(t, session, "Start the database container before migrating", types.ChunkTypeSolution, at.Add(time.Minute)),
+		)
+	}
+	// A fix with no problem before it in its session pairs with nothing
+	chunks = append(chunks, newDetectionChunk(t, "s4", "Bumped the linter", types.ChunkTypeCodeChange, start))
+
+	storage := NewMockPatternStorage()
+	engine := NewPatternEngine(storage)
+	patterns, err := engine.DetectPatterns(context.Background(), "acme/api", chunks, nil, DetectionOptions{})
+	if err != nil {
+		t.Fatalf("DetectPatterns failed: %v", err)
+	}
+	if len(patterns) != 1 {
+		t.Fatalf("expected 1 pattern, got %d", len(patterns))
+	}
+
+	pattern := patterns[0]
+	if pattern.Type != PatternTypeErrorResolution || pattern.Frequency != 3 {
+		t.Errorf("expected an error_resolution pattern seen 3 times, got %s seen %d times", pattern.Type, pattern.Frequency)
+	}
+	if pattern.SuccessRate != 1.0 {
+		t.Errorf("expected success rate 1.0, got %f", pattern.SuccessRate)
+	}
+	if len(pattern.Examples) != 3 || len(pattern.Examples[0].ChunkIDs) != 2 {
+		t.Errorf("expected 3 examples of a problem and its fix, got %+v", pattern.Examples)
+	}
+	if pattern.Context["repository"] != "acme/api" || pattern.Context["detector"] != DetectorErrorFix {
+		t.Errorf("unexpected context %v", pattern.Context)
+	}
+	if _, err := storage.GetPattern(context.Background(), pattern.ID); err != nil {
+		t.Errorf("expected the pattern to be persisted: %v", err)
+	}
+
+	// Detecting again updates the same pattern
+	again, err := engine.DetectPatterns(context.Background(), "acme/api", chunks, nil, DetectionOptions{})
+	if err != nil {
+		t.Fatalf("DetectPatterns failed: %v", err)
+	}
+	if again[0].ID != pattern.ID || len(storage.patterns) != 1 {
+		t.Errorf("expected detection to update pattern %s, got %s and %d stored", pattern.ID, again[0].ID, len(storage.patterns))
+	}
+}
+
+func TestDetectErrorFixPatternsClustersByKeywords(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	chunks := []types.ConversationChunk{
+		newDetectionChunk(t, "s1", "Checkout webhook retries pile up in the payment queue", types.ChunkTypeProblem, start),
+		newDetectionChunk(t, "s1", "Made the webhook handler idempotent", types.ChunkTypeSolution, start.Add(time.Minute)),
+		newDetectionChunk(t, "s2", "Payment webhook retries flood the checkout queue again", types.ChunkTypeProblem, start.Add(2*time.Minute)),
+		newDetectionChunk(t, "s2", "Deduplicated webhook deliveries by event id", types.ChunkTypeCodeChange, start.Add(3*time.Minute)),
+		newDetectionChunk(t, "s3", "Dark mode toggle ignores the system theme", types.ChunkTypeProblem, start.Add(4*time.Minute)),
+		newDetectionChunk(t, "s3", "Read prefers-color-scheme on load", types.ChunkTypeSolution, start.Add(5*time.Minute)),
+	}
+
+	patterns, err := NewPatternEngine(nil).DetectPatterns(context.Background(), "acme/api", chunks, nil, DetectionOptions{})
+	if err != nil {
+		t.Fatalf("DetectPatterns failed: %v", err)
+	}
+	if len(patterns) != 1 || patterns[0].Frequency != 2 {
+		t.Fatalf("expected the two webhook problems to form one pattern, got %+v", patterns)
+	}
+}
+
+func TestDetectToolChainPatterns(t *testing.T) {
+	now := time.Now()
+	chains := []ToolChain{
+		{SessionID: "s1", Tools: []string{"grep", "read", "edit", "test"}, Success: true, Timestamp: now},
+		{SessionID: "s2", Tools: []string{"grep", "read", "read", "edit", "test"}, Success: true, Timestamp: now},
+		{SessionID: "s3", Tools: []string{"Grep", "read", "edit"}, Success: false, Timestamp: now},
+		{SessionID: "s4", Tools: []string{"deploy", "rollback"}, Success: false, Timestamp: now},
+	}
+
+	patterns, err := NewPatternEngine(nil).DetectPatterns(context.Background(), "acme/api", nil, chains, DetectionOptions{})
+	if err != nil {
+		t.Fatalf("DetectPatterns failed: %v", err)
+	}
+
+	names := make(map[string]Pattern)
+	for _, pattern := range patterns {
+		names[pattern.Name] = pattern
+	}
+	if len(patterns) != 2 {
+		t.Fatalf("expected 2 closed tool chains, got %v", names)
+	}
+	chain, ok := names["Tool chain: grep → read → edit"]
+	if !ok || chain.Frequency != 3 || chain.Type != PatternTypeWorkflow || len(chain.Steps) != 3 {
+		t.Errorf("expected grep → read → edit seen 3 times, got %+v", chain)
+	}
+	if chain.SuccessRate < 0.66 || chain.SuccessRate > 0.67 {
+		t.Errorf("expected success rate 2/3, got %f", chain.SuccessRate)
+	}
+	if _, ok := names["Tool chain: grep → read → edit → test"]; !ok {
+		t.Errorf("expected the longer chain seen twice, got %v", names)
+	}
+	if _, ok := names["Tool chain: read → edit"]; ok {
+		t.Error("expected a chain contained in an equally frequent longer one to be dropped")
+	}
+}
+
+func TestFilePatternStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "patterns.json")
+	store := NewFilePatternStore(path)
+
+	pattern := &Pattern{ID: "pattern_1", Type: PatternTypeWorkflow, Name: "Tool chain: grep → edit", Frequency: 2, Keywords: []string{"grep", "edit"}}
+	if err := store.StorePattern(ctx, pattern); err != nil {
+		t.Fatalf("StorePattern failed: %v", err)
+	}
+	if err := store.StorePattern(ctx, &Pattern{ID: "pattern_2", Type: PatternTypeErrorResolution, Name: "Fix for timeouts", Frequency: 5}); err != nil {
+		t.Fatalf("StorePattern failed: %v", err)
+	}
+	if err := store.UpdatePattern(ctx, &Pattern{ID: "missing"}); err == nil {
+		t.Error("expected updating a missing pattern to fail")
+	}
+
+	reloaded := NewFilePatternStore(path)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	all, err := reloaded.ListPatterns(ctx, nil)
+	if err != nil || len(all) != 2 || all[0].ID != "pattern_2" {
+		t.Fatalf("expected 2 patterns, most frequent first, got %+v (%v)", all, err)
+	}
+	workflow := PatternTypeWorkflow
+	if workflows, _ := reloaded.ListPatterns(ctx, &workflow); len(workflows) != 1 {
+		t.Errorf("expected 1 workflow pattern, got %d", len(workflows))
+	}
+	if found, _ := reloaded.SearchPatterns(ctx, "grep", 5); len(found) != 1 || found[0].ID != "pattern_1" {
+		t.Errorf("expected search to find pattern_1, got %+v", found)
+	}
+
+	if err := reloaded.DeletePattern(ctx, "pattern_1"); err != nil {
+		t.Fatalf("DeletePattern failed: %v", err)
+	}
+	if _, err := reloaded.GetPattern(ctx, "pattern_1"); err == nil {
+		t.Error("expected the deleted pattern to be gone")
+	}
+}
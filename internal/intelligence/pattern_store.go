@@ -0,0 +1,201 @@
+package intelligence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FilePatternStore is a PatternStorage kept in memory and persisted to an
+// optional JSON file, so detected patterns survive restarts
+type FilePatternStore struct {
+	mu       sync.RWMutex
+	path     string
+	patterns map[string]Pattern // id -> pattern
+}
+
+// NewFilePatternStore creates a pattern store persisted at path; an empty
+// path keeps it in memory
+func NewFilePatternStore(path string) *FilePatternStore {
+	return &FilePatternStore{
+		path:     path,
+		patterns: make(map[string]Pattern),
+	}
+}
+
+// Load reads the patterns from disk. A missing file is not an error.
+func (s *FilePatternStore) Load() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read patterns: %w", err)
+	}
+
+	var patterns []Pattern
+	if err := json.Unmarshal(data, &patterns); err != nil {
+		return fmt.Errorf("failed to parse patterns: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range patterns {
+		s.patterns[patterns[i].ID] = patterns[i]
+	}
+	return nil
+}
+
+// StorePattern stores a pattern, replacing the one with the same ID
+func (s *FilePatternStore) StorePattern(_ context.Context, pattern *Pattern) error {
+	if pattern == nil || pattern.ID == "" {
+		return errors.New("pattern id is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.patterns[pattern.ID] = *pattern
+	return s.persistLocked()
+}
+
+// GetPattern returns the pattern with the given ID
+func (s *FilePatternStore) GetPattern(_ context.Context, id string) (*Pattern, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pattern, ok := s.patterns[id]
+	if !ok {
+		return nil, fmt.Errorf("pattern not found: %s", id)
+	}
+	return &pattern, nil
+}
+
+// ListPatterns returns the patterns of a type, or all of them when
+// patternType is nil, most frequent first
+func (s *FilePatternStore) ListPatterns(_ context.Context, patternType *PatternType) ([]Pattern, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	patterns := make([]Pattern, 0, len(s.patterns))
+	for id := range s.patterns {
+		if patternType == nil || s.patterns[id].Type == *patternType {
+			patterns = append(patterns, s.patterns[id])
+		}
+	}
+	sortPatterns(patterns)
+	return patterns, nil
+}
+
+// UpdatePattern replaces a stored pattern
+func (s *FilePatternStore) UpdatePattern(_ context.Context, pattern *Pattern) error {
+	if pattern == nil {
+		return errors.New("pattern is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.patterns[pattern.ID]; !ok {
+		return fmt.Errorf("pattern not found: %s", pattern.ID)
+	}
+	s.patterns[pattern.ID] = *pattern
+	return s.persistLocked()
+}
+
+// DeletePattern removes a pattern
+func (s *FilePatternStore) DeletePattern(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.patterns[id]; !ok {
+		return fmt.Errorf("pattern not found: %s", id)
+	}
+	delete(s.patterns, id)
+	return s.persistLocked()
+}
+
+// SearchPatterns returns the patterns whose name, description or keywords
+// contain any word of query, best matches first
+func (s *FilePatternStore) SearchPatterns(_ context.Context, query string, limit int) ([]Pattern, error) {
+	words := strings.Fields(strings.ToLower(query))
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	type match struct {
+		pattern Pattern
+		score   int
+	}
+	var matches []match
+	for id := range s.patterns {
+		pattern := s.patterns[id]
+		text := strings.ToLower(pattern.Name + " " + pattern.Description + " " + strings.Join(pattern.Keywords, " "))
+		score := 0
+		for _, word := range words {
+			if strings.Contains(text, word) {
+				score++
+			}
+		}
+		if score > 0 {
+			matches = append(matches, match{pattern: pattern, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].pattern.ID < matches[j].pattern.ID
+	})
+
+	patterns := make([]Pattern, 0, min(limit, len(matches)))
+	for i := 0; i < len(matches) && (limit <= 0 || i < limit); i++ {
+		patterns = append(patterns, matches[i].pattern)
+	}
+	return patterns, nil
+}
+
+// persistLocked writes the patterns to disk; callers must hold the write lock
+func (s *FilePatternStore) persistLocked() error {
+	if s.path == "" {
+		return nil
+	}
+
+	patterns := make([]Pattern, 0, len(s.patterns))
+	for id := range s.patterns {
+		patterns = append(patterns, s.patterns[id])
+	}
+	sort.Slice(patterns, func(i, j int) bool { return patterns[i].ID < patterns[j].ID })
+
+	data, err := json.MarshalIndent(patterns, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode patterns: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o750); err != nil {
+		return fmt.Errorf("failed to create patterns folder: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write patterns: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// sortPatterns orders patterns most frequent first, then by name
+func sortPatterns(patterns []Pattern) {
+	sort.SliceStable(patterns, func(i, j int) bool {
+		if patterns[i].Frequency != patterns[j].Frequency {
+			return patterns[i].Frequency > patterns[j].Frequency
+		}
+		return patterns[i].Name < patterns[j].Name
+	})
+}
@@ -0,0 +1,108 @@
+package intelligence
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"lerian-mcp-memory/pkg/types"
+)
+
+const (
+	// qualityIdealLength is the content length from which a memory counts
+	// as fully detailed
+	qualityIdealLength = 400
+
+	// qualityMinLength is the content length below which a memory says
+	// too little to be useful on its own
+	qualityMinLength = 40
+
+	// qualityHalfLifeDays is the age at which the recency of a memory halves
+	qualityHalfLifeDays = 90.0
+
+	// qualityCitationScale is the number of citations that takes the usage
+	// score to about two thirds
+	qualityCitationScale = 3.0
+)
+
+var (
+	// specificPattern matches what makes a memory specific: file paths,
+	// identifiers, versions, numbers with units and error messages
+	specificPattern = regexp.MustCompile(`[\w-]+/[\w./-]+|\b[\w-]+\.(go|ts|js|py|rs|java|rb|sql|yaml|yml|json|toml|md)\b|\b[a-z]+[A-Z]\w*\b|\b[a-z]+_[a-z_]+\b|\bv?\d+\.\d+(\.\d+)?\b|\b\d+(ms|s|m|h|mb|gb|kb|%)\b|(?i)\b(error|exception|panic)\b`)
+
+	// vaguePattern matches filler that makes a memory vague
+	vaguePattern = regexp.MustCompile(`(?i)\b(something|somehow|stuff|things?|maybe|probably|etc|some issue|not sure|tbd|todo|fixed it|works now)\b`)
+
+	// codePattern matches inline or fenced code
+	codePattern = regexp.MustCompile("```|`[^`\n]+`|(?m)^(\t|    )\\S")
+)
+
+// AnalyzeQuality scores how useful a memory is likely to be, from 0 to 1 per
+// signal:
+//
+//   - Completeness: content length, a summary and a recorded outcome
+//   - Clarity: specificity (paths, identifiers, versions, error messages
+//     against vague filler) and the presence of code or modified files
+//   - FreshnessScore and RelevanceDecay: recency, halving every 90 days
+//   - UsageScore: how many other memories cite this one
+//
+// OverallQuality combines them with CalculateOverallQuality.
+func AnalyzeQuality(chunk *types.ConversationChunk, citations int, now time.Time) *types.QualityMetrics {
+	quality := &types.QualityMetrics{
+		Completeness: qualityCompleteness(chunk),
+		Clarity:      qualityClarity(chunk),
+		Citations:    citations,
+		UsageScore:   1 - math.Exp(-float64(citations)/qualityCitationScale),
+	}
+
+	quality.FreshnessScore = 1
+	if !chunk.Timestamp.IsZero() {
+		// Whole days, so a score is stable within a day
+		ageDays := math.Max(0, math.Floor(now.Sub(chunk.Timestamp).Hours()/24))
+		quality.FreshnessScore = math.Pow(0.5, ageDays/qualityHalfLifeDays)
+	}
+	quality.RelevanceDecay = 1 - quality.FreshnessScore
+
+	quality.CalculateOverallQuality()
+	return quality
+}
+
+// qualityCompleteness scores length, summary and outcome
+func qualityCompleteness(chunk *types.ConversationChunk) float64 {
+	length := len(strings.TrimSpace(chunk.Content))
+	score := 0.6 * math.Min(1, float64(length)/qualityIdealLength)
+	if length < qualityMinLength {
+		score *= 0.5
+	}
+	if chunk.Summary != "" {
+		score += 0.15
+	}
+	switch chunk.Metadata.Outcome {
+	case types.OutcomeSuccess, types.OutcomeFailed:
+		score += 0.25
+	case types.OutcomeAbandoned:
+		score += 0.1
+	}
+	return math.Min(1, score)
+}
+
+// qualityClarity scores specificity and code
+func qualityClarity(chunk *types.ConversationChunk) float64 {
+	words := len(strings.Fields(chunk.Content))
+	if words == 0 {
+		return 0
+	}
+
+	specific := len(specificPattern.FindAllString(chunk.Content, -1))
+	vague := len(vaguePattern.FindAllString(chunk.Content, -1))
+	// A memory with one specific detail every ten words is fully specific
+	specificity := math.Min(1, float64(specific)*10/float64(words))
+	specificity = math.Max(0, specificity-float64(vague)*0.1)
+
+	score := 0.7 * specificity
+	if codePattern.MatchString(chunk.Content) || len(chunk.Metadata.FilesModified) > 0 {
+		score += 0.3
+	}
+	return math.Min(1, score)
+}
@@ -0,0 +1,47 @@
+package intelligence
+
+import (
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/pkg/types"
+)
+
+func TestAnalyzeQuality(t *testing.T) {
+	now := time.Now()
+	specific := &types.ConversationChunk{
+		Content: "Checkout timed out after 30s because the payments client in internal/payments/client.go reused a closed connection.\n" +
+			"Fixed by creating the client per request:\n\n    client := payments.NewClient(cfg)\n\nVerified with go test ./internal/payments and pgx v5.5.1.",
+		Summary:   "Payments client reused closed connections",
+		Timestamp: now,
+		Metadata:  types.ChunkMetadata{Outcome: types.OutcomeSuccess},
+	}
+	vague := &types.ConversationChunk{
+		Content:   "fixed it somehow, maybe some stuff",
+		Timestamp: now.AddDate(-1, 0, 0),
+		Metadata:  types.ChunkMetadata{Outcome: types.OutcomeInProgress},
+	}
+
+	good := AnalyzeQuality(specific, 4, now)
+	poor := AnalyzeQuality(vague, 0, now)
+	if good.OverallQuality <= 0.7 {
+		t.Errorf("expected a specific, recent, cited memory to score high, got %f (%+v)", good.OverallQuality, good)
+	}
+	if poor.OverallQuality >= 0.3 {
+		t.Errorf("expected a short, vague, old memory to score low, got %f (%+v)", poor.OverallQuality, poor)
+	}
+	if good.Citations != 4 || good.UsageScore <= poor.UsageScore {
+		t.Errorf("expected citations to raise the usage score, got %f and %f", good.UsageScore, poor.UsageScore)
+	}
+	if poor.FreshnessScore >= 0.1 || poor.RelevanceDecay <= 0.9 {
+		t.Errorf("expected a year-old memory to have decayed, got freshness %f", poor.FreshnessScore)
+	}
+	if good.LastCalculated == nil {
+		t.Error("expected the score to record when it was calculated")
+	}
+
+	again := AnalyzeQuality(specific, 4, now.Add(time.Hour))
+	if again.OverallQuality != good.OverallQuality {
+		t.Errorf("expected the score to hold within a day, got %f and %f", good.OverallQuality, again.OverallQuality)
+	}
+}
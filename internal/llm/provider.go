@@ -0,0 +1,191 @@
+// Package llm provides a provider-neutral text completion interface with
+// OpenAI, Anthropic, Gemini and Ollama implementations for intelligence features.
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"lerian-mcp-memory/internal/config"
+)
+
+// Feature identifies an intelligence feature that can use an LLM
+type Feature string
+
+const (
+	// FeatureSummarization generates summaries of memories
+	FeatureSummarization Feature = "summarization"
+	// FeatureConflictVerification confirms candidate conflicts found by heuristics
+	FeatureConflictVerification Feature = "conflict_verification"
+	// FeatureInsights writes narrative insights about a repository
+	FeatureInsights Feature = "insights"
+)
+
+// maxErrorBody bounds how much of an error response is kept in error messages
+const maxErrorBody = 512
+
+// ErrNoProvider is returned when a feature has no LLM provider configured
+var ErrNoProvider = errors.New("no LLM provider configured")
+
+// Request is a single-turn completion request
+type Request struct {
+	System      string
+	Prompt      string
+	MaxTokens   int
+	Temperature float64
+}
+
+// Response is the text produced by a provider
+type Response struct {
+	Text         string `json:"text"`
+	Model        string `json:"model"`
+	InputTokens  int    `json:"input_tokens"`
+	OutputTokens int    `json:"output_tokens"`
+}
+
+// Provider generates text completions
+type Provider interface {
+	// Name returns the provider identifier, e.g. "anthropic"
+	Name() string
+
+	// Model returns the model used for completions
+	Model() string
+
+	// Complete generates a completion for the request
+	Complete(ctx context.Context, req Request) (*Response, error)
+}
+
+// NewProvider creates the named provider from its settings
+func NewProvider(name string, cfg config.LLMProviderConfig, timeout time.Duration) (Provider, error) {
+	client := &http.Client{Timeout: timeout}
+	switch name {
+	case config.LLMProviderOpenAI:
+		return NewOpenAIProvider(cfg, client), nil
+	case config.LLMProviderAnthropic:
+		return NewAnthropicProvider(cfg, client), nil
+	case config.LLMProviderGemini:
+		return NewGeminiProvider(cfg, client), nil
+	case config.LLMProviderOllama:
+		return NewOllamaProvider(cfg, client), nil
+	default:
+		return nil, fmt.Errorf("unknown LLM provider: %s", name)
+	}
+}
+
+// Router selects the provider configured for each feature
+type Router struct {
+	providers map[Feature]Provider
+	maxTokens int
+}
+
+// NewRouter creates a router from configuration. Providers are shared between
+// features that select the same one; features set to "none" have no provider.
+func NewRouter(cfg *config.Config) (*Router, error) {
+	r := &Router{providers: make(map[Feature]Provider), maxTokens: cfg.LLM.MaxTokens}
+	timeout := time.Duration(cfg.LLM.RequestTimeout) * time.Second
+
+	selected := map[Feature]string{
+		FeatureSummarization:        cfg.LLM.SummarizationProvider,
+		FeatureConflictVerification: cfg.LLM.ConflictVerificationProvider,
+		FeatureInsights:             cfg.LLM.InsightsProvider,
+	}
+	byName := make(map[string]Provider)
+	for feature, name := range selected {
+		if name == "" || name == config.LLMProviderNone {
+			continue
+		}
+		provider, ok := byName[name]
+		if !ok {
+			var err error
+			if provider, err = NewProvider(name, cfg.LLMProvider(name), timeout); err != nil {
+				return nil, fmt.Errorf("failed to create %s provider: %w", feature, err)
+			}
+			byName[name] = provider
+		}
+		r.providers[feature] = provider
+	}
+	return r, nil
+}
+
+// Set assigns a provider to a feature; a nil provider disables the feature
+func (r *Router) Set(feature Feature, provider Provider) {
+	if provider == nil {
+		delete(r.providers, feature)
+		return
+	}
+	r.providers[feature] = provider
+}
+
+// For returns the provider of a feature, or nil when none is configured.
+// It is safe to call on a nil router.
+func (r *Router) For(feature Feature) Provider {
+	if r == nil {
+		return nil
+	}
+	return r.providers[feature]
+}
+
+// Complete runs a request with the feature's provider, applying the default token limit
+func (r *Router) Complete(ctx context.Context, feature Feature, req Request) (*Response, error) {
+	provider := r.For(feature)
+	if provider == nil {
+		return nil, fmt.Errorf("%w for %s", ErrNoProvider, feature)
+	}
+	if req.MaxTokens <= 0 {
+		req.MaxTokens = r.maxTokens
+	}
+	return provider.Complete(ctx, req)
+}
+
+// Status reports the provider and model selected per feature
+func (r *Router) Status() map[string]interface{} {
+	status := make(map[string]interface{})
+	for _, feature := range []Feature{FeatureSummarization, FeatureConflictVerification, FeatureInsights} {
+		provider := r.For(feature)
+		if provider == nil {
+			status[string(feature)] = config.LLMProviderNone
+			continue
+		}
+		status[string(feature)] = map[string]string{"provider": provider.Name(), "model": provider.Model()}
+	}
+	return status
+}
+
+// postJSON sends body as JSON and decodes a successful JSON response into out
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
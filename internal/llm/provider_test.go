@@ -0,0 +1,117 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"lerian-mcp-memory/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer serves a canned JSON response and records the last request
+func newTestServer(t *testing.T, response string) (*httptest.Server, *http.Request, map[string]interface{}) {
+	t.Helper()
+	var lastRequest http.Request
+	body := make(map[string]interface{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastRequest = *r
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server, &lastRequest, body
+}
+
+var testRequest = Request{System: "be brief", Prompt: "summarize", MaxTokens: 50}
+
+func TestAnthropicProvider(t *testing.T) {
+	server, req, body := newTestServer(t, `{"model":"claude-test","content":[{"type":"text","text":" Short summary. "}],"usage":{"input_tokens":12,"output_tokens":3}}`)
+	provider := NewAnthropicProvider(config.LLMProviderConfig{APIKey: "key", Model: "claude-test", BaseURL: server.URL}, server.Client())
+
+	resp, err := provider.Complete(context.Background(), testRequest)
+	require.NoError(t, err)
+	assert.Equal(t, &Response{Text: "Short summary.", Model: "claude-test", InputTokens: 12, OutputTokens: 3}, resp)
+
+	assert.Equal(t, "/v1/messages", req.URL.Path)
+	assert.Equal(t, "key", req.Header.Get("x-api-key"))
+	assert.Equal(t, anthropicVersion, req.Header.Get("anthropic-version"))
+	assert.Equal(t, "be brief", body["system"])
+	assert.InDelta(t, 50, body["max_tokens"], 0)
+}
+
+func TestGeminiProvider(t *testing.T) {
+	server, req, body := newTestServer(t, `{"candidates":[{"content":{"parts":[{"text":"Gemini summary."}]}}],"usageMetadata":{"promptTokenCount":9,"candidatesTokenCount":2}}`)
+	provider := NewGeminiProvider(config.LLMProviderConfig{APIKey: "key", Model: "gemini-test", BaseURL: server.URL}, server.Client())
+
+	resp, err := provider.Complete(context.Background(), testRequest)
+	require.NoError(t, err)
+	assert.Equal(t, &Response{Text: "Gemini summary.", Model: "gemini-test", InputTokens: 9, OutputTokens: 2}, resp)
+
+	assert.Equal(t, "/v1beta/models/gemini-test:generateContent", req.URL.Path)
+	assert.Equal(t, "key", req.Header.Get("x-goog-api-key"))
+	assert.Contains(t, body, "systemInstruction")
+}
+
+func TestOllamaProvider(t *testing.T) {
+	server, req, body := newTestServer(t, `{"model":"llama-test","response":"Local summary.","prompt_eval_count":7,"eval_count":2}`)
+	provider := NewOllamaProvider(config.LLMProviderConfig{Model: "llama-test", BaseURL: server.URL + "/"}, server.Client())
+
+	resp, err := provider.Complete(context.Background(), testRequest)
+	require.NoError(t, err)
+	assert.Equal(t, "Local summary.", resp.Text)
+	assert.Equal(t, "/api/generate", req.URL.Path)
+	assert.Equal(t, false, body["stream"])
+}
+
+func TestOpenAIProvider(t *testing.T) {
+	server, req, _ := newTestServer(t, `{"model":"gpt-test","choices":[{"message":{"role":"assistant","content":"OpenAI summary."}}],"usage":{"prompt_tokens":5,"completion_tokens":2}}`)
+	provider := NewOpenAIProvider(config.LLMProviderConfig{APIKey: "key", Model: "gpt-test", BaseURL: server.URL + "/v1"}, server.Client())
+
+	resp, err := provider.Complete(context.Background(), testRequest)
+	require.NoError(t, err)
+	assert.Equal(t, &Response{Text: "OpenAI summary.", Model: "gpt-test", InputTokens: 5, OutputTokens: 2}, resp)
+	assert.Equal(t, "/v1/chat/completions", req.URL.Path)
+}
+
+func TestProviderErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, `{"error":"overloaded"}`, http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	provider := NewAnthropicProvider(config.LLMProviderConfig{BaseURL: server.URL}, server.Client())
+	_, err := provider.Complete(context.Background(), testRequest)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "503")
+	assert.Contains(t, err.Error(), "overloaded")
+}
+
+func TestRouterPerFeatureSelection(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.LLM.SummarizationProvider = config.LLMProviderOllama
+	cfg.LLM.ConflictVerificationProvider = config.LLMProviderAnthropic
+	cfg.LLM.Anthropic.APIKey = "key"
+
+	router, err := NewRouter(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, config.LLMProviderOllama, router.For(FeatureSummarization).Name())
+	assert.Equal(t, config.LLMProviderAnthropic, router.For(FeatureConflictVerification).Name())
+	assert.Nil(t, router.For(FeatureInsights))
+	assert.Equal(t, config.LLMProviderNone, router.Status()[string(FeatureInsights)])
+
+	_, err = router.Complete(context.Background(), FeatureInsights, testRequest)
+	assert.ErrorIs(t, err, ErrNoProvider)
+
+	var nilRouter *Router
+	assert.Nil(t, nilRouter.For(FeatureSummarization))
+
+	cfg.LLM.InsightsProvider = "unknown"
+	_, err = NewRouter(cfg)
+	assert.Error(t, err)
+}
@@ -0,0 +1,296 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"lerian-mcp-memory/internal/config"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	anthropicDefaultBaseURL = "https://api.anthropic.com"
+	anthropicVersion        = "2023-06-01"
+	geminiDefaultBaseURL    = "https://generativelanguage.googleapis.com"
+)
+
+// OpenAIProvider completes text with the OpenAI chat completions API
+type OpenAIProvider struct {
+	client *openai.Client
+	model  string
+}
+
+// NewOpenAIProvider creates an OpenAI provider. BaseURL allows OpenAI-compatible endpoints.
+func NewOpenAIProvider(cfg config.LLMProviderConfig, httpClient *http.Client) *OpenAIProvider {
+	clientConfig := openai.DefaultConfig(cfg.APIKey)
+	if cfg.BaseURL != "" {
+		clientConfig.BaseURL = cfg.BaseURL
+	}
+	clientConfig.HTTPClient = httpClient
+	return &OpenAIProvider{client: openai.NewClientWithConfig(clientConfig), model: cfg.Model}
+}
+
+// Name returns the provider identifier
+func (p *OpenAIProvider) Name() string { return config.LLMProviderOpenAI }
+
+// Model returns the completion model
+func (p *OpenAIProvider) Model() string { return p.model }
+
+// Complete generates a chat completion
+func (p *OpenAIProvider) Complete(ctx context.Context, req Request) (*Response, error) {
+	messages := make([]openai.ChatCompletionMessage, 0, 2)
+	if req.System != "" {
+		messages = append(messages, openai.ChatCompletionMessage{Role: openai.ChatMessageRoleSystem, Content: req.System})
+	}
+	messages = append(messages, openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: req.Prompt})
+
+	resp, err := p.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model:       p.model,
+		Messages:    messages,
+		MaxTokens:   req.MaxTokens,
+		Temperature: float32(req.Temperature),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("openai completion failed: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, errors.New("openai completion returned no choices")
+	}
+
+	return &Response{
+		Text:         strings.TrimSpace(resp.Choices[0].Message.Content),
+		Model:        resp.Model,
+		InputTokens:  resp.Usage.PromptTokens,
+		OutputTokens: resp.Usage.CompletionTokens,
+	}, nil
+}
+
+// AnthropicProvider completes text with the Anthropic Messages API
+type AnthropicProvider struct {
+	client  *http.Client
+	apiKey  string
+	model   string
+	baseURL string
+}
+
+// NewAnthropicProvider creates an Anthropic provider
+func NewAnthropicProvider(cfg config.LLMProviderConfig, client *http.Client) *AnthropicProvider {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = anthropicDefaultBaseURL
+	}
+	return &AnthropicProvider{client: client, apiKey: cfg.APIKey, model: cfg.Model, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// Name returns the provider identifier
+func (p *AnthropicProvider) Name() string { return config.LLMProviderAnthropic }
+
+// Model returns the completion model
+func (p *AnthropicProvider) Model() string { return p.model }
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature float64            `json:"temperature"`
+}
+
+type anthropicResponse struct {
+	Model   string `json:"model"`
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// Complete generates a message completion
+func (p *AnthropicProvider) Complete(ctx context.Context, req Request) (*Response, error) {
+	var resp anthropicResponse
+	err := postJSON(ctx, p.client, p.baseURL+"/v1/messages", map[string]string{
+		"x-api-key":         p.apiKey,
+		"anthropic-version": anthropicVersion,
+	}, anthropicRequest{
+		Model:       p.model,
+		System:      req.System,
+		Messages:    []anthropicMessage{{Role: "user", Content: req.Prompt}},
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+	}, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic completion failed: %w", err)
+	}
+
+	var text strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return nil, errors.New("anthropic completion returned no text")
+	}
+
+	return &Response{
+		Text:         strings.TrimSpace(text.String()),
+		Model:        resp.Model,
+		InputTokens:  resp.Usage.InputTokens,
+		OutputTokens: resp.Usage.OutputTokens,
+	}, nil
+}
+
+// GeminiProvider completes text with the Gemini generateContent API
+type GeminiProvider struct {
+	client  *http.Client
+	apiKey  string
+	model   string
+	baseURL string
+}
+
+// NewGeminiProvider creates a Gemini provider
+func NewGeminiProvider(cfg config.LLMProviderConfig, client *http.Client) *GeminiProvider {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = geminiDefaultBaseURL
+	}
+	return &GeminiProvider{client: client, apiKey: cfg.APIKey, model: cfg.Model, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// Name returns the provider identifier
+func (p *GeminiProvider) Name() string { return config.LLMProviderGemini }
+
+// Model returns the completion model
+func (p *GeminiProvider) Model() string { return p.model }
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiRequest struct {
+	SystemInstruction *geminiContent  `json:"systemInstruction,omitempty"`
+	Contents          []geminiContent `json:"contents"`
+	GenerationConfig  struct {
+		MaxOutputTokens int     `json:"maxOutputTokens,omitempty"`
+		Temperature     float64 `json:"temperature"`
+	} `json:"generationConfig"`
+}
+
+type geminiResponse struct {
+	Candidates []struct {
+		Content geminiContent `json:"content"`
+	} `json:"candidates"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+	} `json:"usageMetadata"`
+	ModelVersion string `json:"modelVersion"`
+}
+
+// Complete generates content
+func (p *GeminiProvider) Complete(ctx context.Context, req Request) (*Response, error) {
+	body := geminiRequest{Contents: []geminiContent{{Role: "user", Parts: []geminiPart{{Text: req.Prompt}}}}}
+	if req.System != "" {
+		body.SystemInstruction = &geminiContent{Parts: []geminiPart{{Text: req.System}}}
+	}
+	body.GenerationConfig.MaxOutputTokens = req.MaxTokens
+	body.GenerationConfig.Temperature = req.Temperature
+
+	endpoint := fmt.Sprintf("%s/v1beta/models/%s:generateContent", p.baseURL, url.PathEscape(p.model))
+	var resp geminiResponse
+	if err := postJSON(ctx, p.client, endpoint, map[string]string{"x-goog-api-key": p.apiKey}, body, &resp); err != nil {
+		return nil, fmt.Errorf("gemini completion failed: %w", err)
+	}
+	if len(resp.Candidates) == 0 {
+		return nil, errors.New("gemini completion returned no candidates")
+	}
+
+	var text strings.Builder
+	for _, part := range resp.Candidates[0].Content.Parts {
+		text.WriteString(part.Text)
+	}
+
+	model := resp.ModelVersion
+	if model == "" {
+		model = p.model
+	}
+	return &Response{
+		Text:         strings.TrimSpace(text.String()),
+		Model:        model,
+		InputTokens:  resp.UsageMetadata.PromptTokenCount,
+		OutputTokens: resp.UsageMetadata.CandidatesTokenCount,
+	}, nil
+}
+
+// OllamaProvider completes text with a local Ollama server
+type OllamaProvider struct {
+	client  *http.Client
+	model   string
+	baseURL string
+}
+
+// NewOllamaProvider creates an Ollama provider
+func NewOllamaProvider(cfg config.LLMProviderConfig, client *http.Client) *OllamaProvider {
+	return &OllamaProvider{client: client, model: cfg.Model, baseURL: strings.TrimSuffix(cfg.BaseURL, "/")}
+}
+
+// Name returns the provider identifier
+func (p *OllamaProvider) Name() string { return config.LLMProviderOllama }
+
+// Model returns the completion model
+func (p *OllamaProvider) Model() string { return p.model }
+
+type ollamaRequest struct {
+	Model   string `json:"model"`
+	System  string `json:"system,omitempty"`
+	Prompt  string `json:"prompt"`
+	Stream  bool   `json:"stream"`
+	Options struct {
+		NumPredict  int     `json:"num_predict,omitempty"`
+		Temperature float64 `json:"temperature"`
+	} `json:"options"`
+}
+
+type ollamaResponse struct {
+	Model           string `json:"model"`
+	Response        string `json:"response"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+}
+
+// Complete generates a completion
+func (p *OllamaProvider) Complete(ctx context.Context, req Request) (*Response, error) {
+	body := ollamaRequest{Model: p.model, System: req.System, Prompt: req.Prompt}
+	body.Options.NumPredict = req.MaxTokens
+	body.Options.Temperature = req.Temperature
+
+	var resp ollamaResponse
+	if err := postJSON(ctx, p.client, p.baseURL+"/api/generate", nil, body, &resp); err != nil {
+		return nil, fmt.Errorf("ollama completion failed: %w", err)
+	}
+
+	return &Response{
+		Text:         strings.TrimSpace(resp.Response),
+		Model:        resp.Model,
+		InputTokens:  resp.PromptEvalCount,
+		OutputTokens: resp.EvalCount,
+	}, nil
+}
@@ -0,0 +1,169 @@
+// Package locking provides advisory chunk locks and per-chunk write serialization
+package locking
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultTTL is the lease length used when no TTL is requested
+	DefaultTTL = 5 * time.Minute
+
+	// MaxTTL bounds lease length so abandoned locks always expire
+	MaxTTL = time.Hour
+
+	// writeStripes is the number of mutexes shared by all chunks for check-and-swap
+	writeStripes = 64
+)
+
+var (
+	// ErrLocked is returned when a chunk is locked by another holder
+	ErrLocked = errors.New("chunk is locked")
+
+	// ErrLockNotHeld is returned when a token does not match the live lock
+	ErrLockNotHeld = errors.New("lock not held")
+)
+
+// Lock is an advisory lease on a chunk
+type Lock struct {
+	ChunkID    string    `json:"chunk_id"`
+	Token      string    `json:"token"`
+	Owner      string    `json:"owner"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Manager tracks advisory locks in memory. Locks are leases: they expire after
+// their TTL so a crashed editor never blocks a chunk permanently.
+type Manager struct {
+	mu      sync.Mutex
+	locks   map[string]*Lock
+	stripes [writeStripes]sync.Mutex
+	now     func() time.Time
+}
+
+// NewManager creates an empty lock manager
+func NewManager() *Manager {
+	return &Manager{
+		locks: make(map[string]*Lock),
+		now:   time.Now,
+	}
+}
+
+// Acquire takes the lock on a chunk for owner. Re-acquiring a live lock with the
+// same owner extends the lease and keeps the token.
+func (m *Manager) Acquire(chunkID, owner string, ttl time.Duration) (*Lock, error) {
+	if chunkID == "" {
+		return nil, errors.New("chunk ID is required")
+	}
+	if owner == "" {
+		return nil, errors.New("lock owner is required")
+	}
+	ttl = clampTTL(ttl)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if current := m.liveLocked(chunkID, now); current != nil {
+		if current.Owner != owner {
+			return nil, fmt.Errorf("%w by %s until %s", ErrLocked, current.Owner, current.ExpiresAt.Format(time.RFC3339))
+		}
+		current.ExpiresAt = now.Add(ttl)
+		lock := *current
+		return &lock, nil
+	}
+
+	lock := &Lock{
+		ChunkID:    chunkID,
+		Token:      uuid.New().String(),
+		Owner:      owner,
+		AcquiredAt: now,
+		ExpiresAt:  now.Add(ttl),
+	}
+	m.locks[chunkID] = lock
+
+	result := *lock
+	return &result, nil
+}
+
+// Release drops the lock if token matches the live lock
+func (m *Manager) Release(chunkID, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current := m.liveLocked(chunkID, m.now())
+	if current == nil || current.Token != token {
+		return ErrLockNotHeld
+	}
+	delete(m.locks, chunkID)
+	return nil
+}
+
+// Get returns the live lock on a chunk, if any
+func (m *Manager) Get(chunkID string) (*Lock, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current := m.liveLocked(chunkID, m.now())
+	if current == nil {
+		return nil, false
+	}
+	lock := *current
+	return &lock, true
+}
+
+// CheckWrite reports whether a writer presenting token may modify the chunk.
+// Unlocked chunks accept any writer; locked chunks only the lock holder.
+func (m *Manager) CheckWrite(chunkID, token string) error {
+	lock, ok := m.Get(chunkID)
+	if !ok || lock.Token == token {
+		return nil
+	}
+	return fmt.Errorf("%w by %s until %s", ErrLocked, lock.Owner, lock.ExpiresAt.Format(time.RFC3339))
+}
+
+// Serialize runs fn while holding the write mutex for chunkID, making a
+// read-compare-write sequence atomic with respect to other serialized writers
+func (m *Manager) Serialize(chunkID string, fn func() error) error {
+	stripe := &m.stripes[stripeFor(chunkID)]
+	stripe.Lock()
+	defer stripe.Unlock()
+	return fn()
+}
+
+// liveLocked returns the unexpired lock for chunkID, pruning it if expired.
+// Callers must hold m.mu.
+func (m *Manager) liveLocked(chunkID string, now time.Time) *Lock {
+	current, ok := m.locks[chunkID]
+	if !ok {
+		return nil
+	}
+	if !now.Before(current.ExpiresAt) {
+		delete(m.locks, chunkID)
+		return nil
+	}
+	return current
+}
+
+func clampTTL(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return DefaultTTL
+	}
+	if ttl > MaxTTL {
+		return MaxTTL
+	}
+	return ttl
+}
+
+func stripeFor(chunkID string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(chunkID))
+	return h.Sum32() % writeStripes
+}
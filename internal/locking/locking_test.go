@@ -0,0 +1,85 @@
+package locking
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquireRelease(t *testing.T) {
+	m := NewManager()
+
+	lock, err := m.Acquire("chunk-1", "consolidation", time.Minute)
+	require.NoError(t, err)
+	assert.NotEmpty(t, lock.Token)
+
+	_, err = m.Acquire("chunk-1", "editor", time.Minute)
+	assert.ErrorIs(t, err, ErrLocked)
+	assert.ErrorIs(t, m.CheckWrite("chunk-1", ""), ErrLocked)
+	assert.NoError(t, m.CheckWrite("chunk-1", lock.Token))
+	assert.NoError(t, m.CheckWrite("chunk-2", ""), "unlocked chunks accept any writer")
+
+	renewed, err := m.Acquire("chunk-1", "consolidation", time.Minute)
+	require.NoError(t, err, "the holder can extend its lease")
+	assert.Equal(t, lock.Token, renewed.Token)
+
+	assert.ErrorIs(t, m.Release("chunk-1", "wrong-token"), ErrLockNotHeld)
+	require.NoError(t, m.Release("chunk-1", lock.Token))
+
+	_, ok := m.Get("chunk-1")
+	assert.False(t, ok)
+}
+
+func TestLockExpires(t *testing.T) {
+	m := NewManager()
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	_, err := m.Acquire("chunk-1", "consolidation", time.Minute)
+	require.NoError(t, err)
+
+	now = now.Add(time.Minute)
+	_, ok := m.Get("chunk-1")
+	assert.False(t, ok, "lease must lapse at its expiry")
+
+	_, err = m.Acquire("chunk-1", "editor", 0)
+	require.NoError(t, err)
+	lock, _ := m.Get("chunk-1")
+	assert.Equal(t, now.Add(DefaultTTL), lock.ExpiresAt)
+}
+
+func TestSerializeIsExclusivePerChunk(t *testing.T) {
+	m := NewManager()
+	version := 0
+	conflicts := 0
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			expected := 0
+			err := m.Serialize("chunk-1", func() error {
+				if version != expected {
+					return errors.New("conflict")
+				}
+				version++
+				return nil
+			})
+			if err != nil {
+				mu.Lock()
+				conflicts++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, version, "exactly one compare-and-swap may win")
+	assert.Equal(t, 19, conflicts)
+}
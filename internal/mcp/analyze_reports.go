@@ -0,0 +1,497 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"lerian-mcp-memory/internal/intelligence"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/pkg/types"
+)
+
+const (
+	// defaultReportChunks is the number of memories analyzed by a report
+	defaultReportChunks = 200
+
+	// maxReportChunks bounds the memories a caller may ask a report to analyze
+	maxReportChunks = 1000
+
+	// defaultReportItems is the number of findings listed in a report
+	defaultReportItems = 20
+
+	// defaultQualityThreshold marks memories below this overall quality as low quality
+	defaultQualityThreshold = 0.5
+
+	// minFilesForGap is how often a file must change before missing decisions count as a gap
+	minFilesForGap = 3
+)
+
+// reportChunks returns the memories a report analyzes, most recent first
+func (ms *MemoryServer) reportChunks(ctx context.Context, repository string, limit int) ([]types.ConversationChunk, error) {
+	var chunks []types.ConversationChunk
+	var err error
+	if repository == GlobalRepository {
+		chunks, err = ms.getChunksForTimeframe(ctx, "", types.TimeframeAll)
+	} else {
+		chunks, err = ms.container.GetVectorStore().ListByRepository(ctx, repository, limit, 0)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memories for analysis: %w", err)
+	}
+
+	sort.SliceStable(chunks, func(i, j int) bool { return chunks[i].Timestamp.After(chunks[j].Timestamp) })
+	if len(chunks) > limit {
+		chunks = chunks[:limit]
+	}
+	return chunks, nil
+}
+
+// reportLimits reads the analyzed-memory and listed-finding limits from options
+func reportLimits(options map[string]interface{}) (chunkLimit, itemLimit int) {
+	chunkLimit, itemLimit = defaultReportChunks, defaultReportItems
+	if l, ok := options["max_chunks"].(float64); ok && l > 0 {
+		chunkLimit = min(int(l), maxReportChunks)
+	}
+	if l, ok := options["limit"].(float64); ok && l > 0 {
+		itemLimit = int(l)
+	}
+	return chunkLimit, itemLimit
+}
+
+// handleQualityReport scores every memory with the confidence engine and lists the weakest ones
+func (ms *MemoryServer) handleQualityReport(ctx context.Context, options map[string]interface{}) (interface{}, error) {
+	repository, _ := options["repository"].(string)
+	chunkLimit, itemLimit := reportLimits(options)
+	threshold := defaultQualityThreshold
+	if th, ok := options["quality_threshold"].(float64); ok && th > 0 && th <= 1 {
+		threshold = th
+	}
+
+	// With a description of the current codebase, memories referring to
+	// deleted or renamed code score lower
+	var snapshot *intelligence.CodebaseSnapshot
+	if hasCodebaseOptions(options) {
+		var err error
+		if snapshot, err = codebaseSnapshot(ctx, options); err != nil {
+			return nil, err
+		}
+	}
+
+	chunks, err := ms.reportChunks(ctx, repository, chunkLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	engine := intelligence.NewConfidenceEngine(ms.container.GetVectorStore())
+	var totals types.QualityMetrics
+	distribution := map[string]int{"high": 0, "medium": 0, "low": 0}
+	byType := make(map[string][]float64)
+	lowQuality := make([]map[string]interface{}, 0)
+	scored, staleCode := 0, 0
+
+	for i := range chunks {
+		chunk := &chunks[i]
+		metrics, err := engine.CalculateQualityMetrics(ctx, chunk)
+		if err != nil {
+			logging.Warn("quality_report: failed to score chunk", "chunk_id", chunk.ID, "error", err)
+			continue
+		}
+		var staleness *intelligence.StaleReferenceResult
+		if snapshot != nil {
+			if result := snapshot.Check(chunk); result.IsStale {
+				staleness = &result
+				staleCode++
+				intelligence.ApplyStaleness(metrics, staleness)
+			}
+		}
+		scored++
+		totals.Completeness += metrics.Completeness
+		totals.Clarity += metrics.Clarity
+		totals.FreshnessScore += metrics.FreshnessScore
+		totals.UsageScore += metrics.UsageScore
+		totals.OverallQuality += metrics.OverallQuality
+		byType[string(chunk.Type)] = append(byType[string(chunk.Type)], metrics.OverallQuality)
+
+		switch {
+		case metrics.OverallQuality >= 0.7:
+			distribution["high"]++
+		case metrics.OverallQuality >= 0.4:
+			distribution["medium"]++
+		default:
+			distribution["low"]++
+		}
+
+		if metrics.OverallQuality < threshold {
+			item := map[string]interface{}{
+				"chunk_id":        chunk.ID,
+				"type":            string(chunk.Type),
+				"summary":         chunk.Summary,
+				"timestamp":       chunk.Timestamp.Format(time.RFC3339),
+				"overall_quality": metrics.OverallQuality,
+				"completeness":    metrics.Completeness,
+				"clarity":         metrics.Clarity,
+				"freshness":       metrics.FreshnessScore,
+			}
+			if staleness != nil {
+				item["broken_references"] = staleness.Broken
+			}
+			lowQuality = append(lowQuality, item)
+		}
+	}
+
+	sort.SliceStable(lowQuality, func(i, j int) bool {
+		return lowQuality[i]["overall_quality"].(float64) < lowQuality[j]["overall_quality"].(float64)
+	})
+	lowQualityCount := len(lowQuality)
+	if len(lowQuality) > itemLimit {
+		lowQuality = lowQuality[:itemLimit]
+	}
+
+	averages := map[string]float64{}
+	if scored > 0 {
+		n := float64(scored)
+		averages = map[string]float64{
+			"overall_quality": totals.OverallQuality / n,
+			"completeness":    totals.Completeness / n,
+			"clarity":         totals.Clarity / n,
+			"freshness":       totals.FreshnessScore / n,
+			"usage":           totals.UsageScore / n,
+		}
+	}
+	typeAverages := make(map[string]float64, len(byType))
+	for chunkType, scores := range byType {
+		sum := 0.0
+		for _, s := range scores {
+			sum += s
+		}
+		typeAverages[chunkType] = sum / float64(len(scores))
+	}
+
+	summary := map[string]interface{}{
+		"memories_analyzed": scored,
+		"averages":          averages,
+		"distribution":      distribution,
+		"by_type":           typeAverages,
+		"low_quality_count": lowQualityCount,
+		"threshold":         threshold,
+	}
+	if snapshot != nil {
+		summary["stale_code_count"] = staleCode
+	}
+
+	return map[string]interface{}{
+		"status":       "success",
+		"operation":    "quality_report",
+		"repository":   repository,
+		"summary":      summary,
+		"low_quality":  lowQuality,
+		"generated_at": time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// handleConflictScan runs the conflict detector and groups findings by severity and type
+func (ms *MemoryServer) handleConflictScan(ctx context.Context, options map[string]interface{}) (interface{}, error) {
+	repository, _ := options["repository"].(string)
+	chunkLimit, itemLimit := reportLimits(options)
+
+	chunks, err := ms.reportChunks(ctx, repository, chunkLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := ms.newConflictDetector().DetectConflicts(ctx, chunks)
+	if err != nil {
+		return nil, fmt.Errorf("conflict scan failed: %w", err)
+	}
+
+	severityRank := map[intelligence.ConflictSeverity]int{
+		intelligence.SeverityCritical: 0,
+		intelligence.SeverityHigh:     1,
+		intelligence.SeverityMedium:   2,
+		intelligence.SeverityLow:      3,
+		intelligence.SeverityInfo:     4,
+	}
+	conflicts := result.Conflicts
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		if severityRank[conflicts[i].Severity] != severityRank[conflicts[j].Severity] {
+			return severityRank[conflicts[i].Severity] < severityRank[conflicts[j].Severity]
+		}
+		return conflicts[i].Confidence > conflicts[j].Confidence
+	})
+
+	bySeverity := make(map[string]int)
+	byType := make(map[string]int)
+	for i := range conflicts {
+		bySeverity[string(conflicts[i].Severity)]++
+		byType[string(conflicts[i].Type)]++
+	}
+
+	items := make([]map[string]interface{}, 0, min(len(conflicts), itemLimit))
+	for i := range conflicts {
+		if i >= itemLimit {
+			break
+		}
+		c := &conflicts[i]
+		items = append(items, map[string]interface{}{
+			"id":                c.ID,
+			"type":              string(c.Type),
+			"severity":          string(c.Severity),
+			"title":             c.Title,
+			"description":       c.Description,
+			"confidence":        c.Confidence,
+			"primary_chunk_id":  c.PrimaryChunk.ID,
+			"conflict_chunk_id": c.ConflictChunk.ID,
+		})
+	}
+
+	return map[string]interface{}{
+		"status":     "success",
+		"operation":  "conflict_scan",
+		"repository": repository,
+		"summary": map[string]interface{}{
+			"memories_analyzed": len(chunks),
+			"conflicts_found":   len(conflicts),
+			"by_severity":       bySeverity,
+			"by_type":           byType,
+		},
+		"conflicts":    items,
+		"generated_at": time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// handleStaleReport checks memory freshness and lists stale memories with suggested actions
+func (ms *MemoryServer) handleStaleReport(ctx context.Context, options map[string]interface{}) (interface{}, error) {
+	repository, _ := options["repository"].(string)
+	chunkLimit, itemLimit := reportLimits(options)
+	minDays := 0
+	if days, ok := options["threshold_days"].(float64); ok && days > 0 {
+		minDays = int(days)
+	}
+
+	chunks, err := ms.reportChunks(ctx, repository, chunkLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	manager := intelligence.NewFreshnessManager(ms.container.GetVectorStore())
+	results := make([]intelligence.ChunkFreshnessResult, 0, len(chunks))
+	stale := make([]map[string]interface{}, 0)
+	freshCount := 0
+
+	for i := range chunks {
+		chunk := &chunks[i]
+		status, err := manager.CheckFreshness(ctx, chunk)
+		if err != nil {
+			continue
+		}
+		results = append(results, intelligence.ChunkFreshnessResult{
+			ChunkID:         chunk.ID,
+			Type:            chunk.Type,
+			Repository:      chunk.Metadata.Repository,
+			FreshnessStatus: *status,
+		})
+		if status.IsFresh {
+			freshCount++
+		}
+		if !status.IsStale || status.DaysOld < minDays {
+			continue
+		}
+		stale = append(stale, map[string]interface{}{
+			"chunk_id":          chunk.ID,
+			"type":              string(chunk.Type),
+			"summary":           chunk.Summary,
+			"days_old":          status.DaysOld,
+			"freshness_score":   status.FreshnessScore,
+			"alerts":            status.Alerts,
+			"suggested_actions": status.SuggestedActions,
+		})
+	}
+
+	sort.SliceStable(stale, func(i, j int) bool {
+		return stale[i]["freshness_score"].(float64) < stale[j]["freshness_score"].(float64)
+	})
+	staleCount := len(stale)
+	if len(stale) > itemLimit {
+		stale = stale[:itemLimit]
+	}
+
+	freshness := manager.Summarize(results)
+
+	return map[string]interface{}{
+		"status":     "success",
+		"operation":  "stale_report",
+		"repository": repository,
+		"summary": map[string]interface{}{
+			"memories_analyzed": len(results),
+			"fresh_count":       freshCount,
+			"stale_count":       staleCount,
+			"threshold_days":    minDays,
+			"overall_health":    freshness.OverallHealth,
+			"by_type":           freshness.ByType,
+		},
+		"stale":        stale,
+		"generated_at": time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// handleKnowledgeGaps looks for what the memory does not cover: unresolved problems,
+// open questions, frequently changed files without decisions and missing memory types
+func (ms *MemoryServer) handleKnowledgeGaps(ctx context.Context, options map[string]interface{}) (interface{}, error) {
+	repository, _ := options["repository"].(string)
+	chunkLimit, itemLimit := reportLimits(options)
+
+	chunks, err := ms.reportChunks(ctx, repository, chunkLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	coverage := make(map[string]int)
+	for i := range chunks {
+		coverage[string(chunks[i].Type)]++
+	}
+
+	gaps := findKnowledgeGaps(chunks, coverage)
+	gapCount := len(gaps)
+	if len(gaps) > itemLimit {
+		gaps = gaps[:itemLimit]
+	}
+
+	return map[string]interface{}{
+		"status":     "success",
+		"operation":  "knowledge_gaps",
+		"repository": repository,
+		"summary": map[string]interface{}{
+			"memories_analyzed": len(chunks),
+			"gaps_found":        gapCount,
+			"coverage":          coverage,
+		},
+		"gaps":         gaps,
+		"generated_at": time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// findKnowledgeGaps derives gaps from chunk types, outcomes, tags and touched files, most severe first
+func findKnowledgeGaps(chunks []types.ConversationChunk, coverage map[string]int) []map[string]interface{} {
+	gaps := make([]map[string]interface{}, 0)
+	addGap := func(kind, severity, description, suggestion string, chunkIDs []string) {
+		gaps = append(gaps, map[string]interface{}{
+			"type":        kind,
+			"severity":    severity,
+			"description": description,
+			"suggestion":  suggestion,
+			"chunk_ids":   chunkIDs,
+		})
+	}
+
+	// Problems are resolved by a successful outcome or a solution sharing their session, tags or files
+	solved := make(map[string]bool)
+	for i := range chunks {
+		if chunks[i].Type != types.ChunkTypeSolution {
+			continue
+		}
+		solved["session:"+chunks[i].SessionID] = true
+		for _, tag := range chunks[i].Metadata.Tags {
+			solved["tag:"+tag] = true
+		}
+		for _, file := range chunks[i].Metadata.FilesModified {
+			solved["file:"+file] = true
+		}
+	}
+
+	var unresolved, openQuestions, untagged []string
+	fileChanges := make(map[string][]string)
+	decidedFiles := make(map[string]bool)
+	for i := range chunks {
+		chunk := &chunks[i]
+		if len(chunk.Metadata.Tags) == 0 {
+			untagged = append(untagged, chunk.ID)
+		}
+		switch chunk.Type {
+		case types.ChunkTypeProblem:
+			if chunk.Metadata.Outcome != types.OutcomeSuccess && !problemSolved(chunk, solved) {
+				unresolved = append(unresolved, chunk.ID)
+			}
+		case types.ChunkTypeQuestion:
+			if chunk.Metadata.Outcome != types.OutcomeSuccess {
+				openQuestions = append(openQuestions, chunk.ID)
+			}
+		case types.ChunkTypeCodeChange:
+			for _, file := range chunk.Metadata.FilesModified {
+				fileChanges[file] = append(fileChanges[file], chunk.ID)
+			}
+		case types.ChunkTypeArchitectureDecision:
+			for _, file := range chunk.Metadata.FilesModified {
+				decidedFiles[file] = true
+			}
+		}
+	}
+
+	if len(unresolved) > 0 {
+		addGap("unresolved_problems", "high",
+			fmt.Sprintf("%d problem(s) have no recorded solution", len(unresolved)),
+			"Store the solution once found, or mark the problem as abandoned", unresolved)
+	}
+
+	files := make([]string, 0, len(fileChanges))
+	for file, ids := range fileChanges {
+		if len(ids) >= minFilesForGap && !decidedFiles[file] {
+			files = append(files, file)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if len(fileChanges[files[i]]) != len(fileChanges[files[j]]) {
+			return len(fileChanges[files[i]]) > len(fileChanges[files[j]])
+		}
+		return files[i] < files[j]
+	})
+	for _, file := range files {
+		addGap("undocumented_hotspot", "medium",
+			fmt.Sprintf("%s changed %d times with no architecture decision recorded", file, len(fileChanges[file])),
+			"Record the design rationale for this file as an architecture decision", fileChanges[file])
+	}
+
+	if len(openQuestions) > 0 {
+		addGap("open_questions", "medium",
+			fmt.Sprintf("%d question(s) have no recorded answer", len(openQuestions)),
+			"Store the answer as a solution or discussion", openQuestions)
+	}
+
+	if len(chunks) > 0 {
+		if coverage[string(types.ChunkTypeArchitectureDecision)] == 0 {
+			addGap("missing_decisions", "medium", "No architecture decisions are recorded",
+				"Capture key design choices with memory_create store_decision", []string{})
+		}
+		if coverage[string(types.ChunkTypeSessionSummary)] == 0 {
+			addGap("missing_session_summaries", "low", "No session summaries are recorded",
+				"End sessions with a session summary to make context handoff easier", []string{})
+		}
+	}
+
+	if len(untagged) > 0 {
+		addGap("untagged_memories", "low",
+			fmt.Sprintf("%d memories have no tags", len(untagged)),
+			"Tag memories so they can be found by topic", untagged)
+	}
+
+	return gaps
+}
+
+// problemSolved reports whether a solution shares the problem's session, tags or files
+func problemSolved(chunk *types.ConversationChunk, solved map[string]bool) bool {
+	if solved["session:"+chunk.SessionID] {
+		return true
+	}
+	for _, tag := range chunk.Metadata.Tags {
+		if solved["tag:"+tag] {
+			return true
+		}
+	}
+	for _, file := range chunk.Metadata.FilesModified {
+		if solved["file:"+file] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newReportChunk(t *testing.T, sessionID, content string, chunkType types.ChunkType, metadata types.ChunkMetadata) *types.ConversationChunk {
+	t.Helper()
+	if metadata.Repository == "" {
+		metadata.Repository = "github.com/acme/api"
+	}
+	if metadata.Outcome == "" {
+		metadata.Outcome = types.OutcomeInProgress
+	}
+	if metadata.Difficulty == "" {
+		metadata.Difficulty = types.DifficultySimple
+	}
+	chunk, err := types.NewConversationChunk(sessionID, content, chunkType, &metadata)
+	require.NoError(t, err)
+	chunk.Embeddings = []float64{0.1, 0.2, 0.3}
+	return chunk
+}
+
+func analyze(t *testing.T, ms *MemoryServer, operation string, options map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	if options["repository"] == nil {
+		options["repository"] = "github.com/acme/api"
+	}
+	result, err := ms.handleMemoryAnalyze(context.Background(), map[string]interface{}{
+		"operation": operation,
+		"options":   options,
+	})
+	require.NoError(t, err)
+	report, ok := result.(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "success", report["status"])
+	assert.Equal(t, operation, report["operation"])
+	return report
+}
+
+func TestAnalyzeKnowledgeGaps(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+
+	problem := newReportChunk(t, "s1", "Login fails under load", types.ChunkTypeProblem, types.ChunkMetadata{Tags: []string{"auth"}})
+	solvedProblem := newReportChunk(t, "s2", "Cache misses on startup", types.ChunkTypeProblem, types.ChunkMetadata{Tags: []string{"cache"}})
+	solution := newReportChunk(t, "s3", "Warm the cache before serving", types.ChunkTypeSolution, types.ChunkMetadata{Tags: []string{"cache"}, Outcome: types.OutcomeSuccess})
+	for _, c := range []*types.ConversationChunk{problem, solvedProblem, solution} {
+		require.NoError(t, store.Store(ctx, c))
+	}
+	for i := 0; i < minFilesForGap; i++ {
+		change := newReportChunk(t, "s4", "Tweak handler", types.ChunkTypeCodeChange, types.ChunkMetadata{FilesModified: []string{"handler.go"}, Tags: []string{"api"}})
+		require.NoError(t, store.Store(ctx, change))
+	}
+
+	report := analyze(t, newCompositeTestServer(t, store), "knowledge_gaps", map[string]interface{}{})
+
+	gaps, ok := report["gaps"].([]map[string]interface{})
+	require.True(t, ok)
+	byType := make(map[string]map[string]interface{})
+	for _, gap := range gaps {
+		byType[gap["type"].(string)] = gap
+	}
+
+	require.Contains(t, byType, "unresolved_problems")
+	assert.Equal(t, []string{problem.ID}, byType["unresolved_problems"]["chunk_ids"])
+	require.Contains(t, byType, "undocumented_hotspot")
+	assert.Len(t, byType["undocumented_hotspot"]["chunk_ids"], minFilesForGap)
+	assert.Contains(t, byType, "missing_decisions")
+	assert.NotContains(t, byType, "untagged_memories")
+	assert.Equal(t, "high", gaps[0]["severity"])
+}
+
+func TestAnalyzeStaleReport(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+
+	old := newReportChunk(t, "s1", "Use library v1 for parsing", types.ChunkTypeDiscussion, types.ChunkMetadata{})
+	old.Timestamp = time.Now().AddDate(-3, 0, 0)
+	fresh := newReportChunk(t, "s1", "Parser benchmarks look fine", types.ChunkTypeDiscussion, types.ChunkMetadata{})
+	require.NoError(t, store.Store(ctx, old))
+	require.NoError(t, store.Store(ctx, fresh))
+
+	report := analyze(t, newCompositeTestServer(t, store), "stale_report", map[string]interface{}{})
+
+	summary := report["summary"].(map[string]interface{})
+	assert.Equal(t, 2, summary["memories_analyzed"])
+	assert.Equal(t, 1, summary["stale_count"])
+	stale := report["stale"].([]map[string]interface{})
+	require.Len(t, stale, 1)
+	assert.Equal(t, old.ID, stale[0]["chunk_id"])
+}
+
+func TestAnalyzeQualityReportAndConflictScan(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	require.NoError(t, store.Store(ctx, newReportChunk(t, "s1", "ok", types.ChunkTypeDiscussion, types.ChunkMetadata{})))
+	require.NoError(t, store.Store(ctx, newReportChunk(t, "s1", "We decided to use PostgreSQL for persistence because it supports transactions and JSON columns.", types.ChunkTypeArchitectureDecision, types.ChunkMetadata{Tags: []string{"database"}})))
+
+	ms := newCompositeTestServer(t, store)
+
+	quality := analyze(t, ms, "quality_report", map[string]interface{}{"quality_threshold": 1.0})
+	summary := quality["summary"].(map[string]interface{})
+	assert.Equal(t, 2, summary["memories_analyzed"])
+	assert.Len(t, quality["low_quality"], 2)
+
+	conflicts := analyze(t, ms, "conflict_scan", map[string]interface{}{})
+	assert.Equal(t, 2, conflicts["summary"].(map[string]interface{})["memories_analyzed"])
+}
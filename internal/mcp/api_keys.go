@@ -0,0 +1,294 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"lerian-mcp-memory/internal/auth"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/security"
+	"lerian-mcp-memory/internal/tenancy"
+
+	"github.com/fredcamaral/gomcp-sdk"
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+// ForbiddenCode is the JSON-RPC error code of tool calls the caller's API key does not allow
+const ForbiddenCode = -32003
+
+// errNotKeyAdmin is returned when a caller that may not manage API keys calls an auth_* tool
+var errNotKeyAdmin = fmt.Errorf("%w: managing API keys needs a local client, a token with the admin scope, or a key allowing every tool and project", tenancy.ErrCrossTenant)
+
+// APIKeyMiddleware rejects tools/call requests authenticated with an API key
+// that is no longer usable or does not list the tool. The key is looked up
+// again on every call, so revoking it also stops connections opened with
+// it. Requests without a key, such as those over stdio, are not affected.
+func APIKeyMiddleware(keys *auth.Store) Middleware {
+	return ForMethods(func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+			caller := auth.KeyFrom(ctx)
+			if caller == nil {
+				return next(ctx, req)
+			}
+
+			tool := RequestTarget(req)
+			key, err := keys.Get(caller.ID)
+			if err != nil {
+				logging.Warn("MCP request with unusable API key", "key_id", caller.ID, "tool", tool, "error", err)
+				return ErrorResponse(req, ForbiddenCode, "API key not usable", map[string]interface{}{
+					"code":   "KEY_NOT_USABLE",
+					"key_id": caller.ID,
+					"reason": err.Error(),
+				})
+			}
+			if !key.Allows(tool) {
+				logging.Warn("MCP request for a tool its API key does not allow", "key_id", key.ID, "tool", tool)
+				return ErrorResponse(req, ForbiddenCode, "Tool not allowed for this API key", map[string]interface{}{
+					"code":   "TOOL_NOT_ALLOWED",
+					"key_id": key.ID,
+					"tool":   tool,
+				})
+			}
+			return next(auth.WithKey(ctx, key), req)
+		}
+	}, "tools/call")
+}
+
+// registerAPIKeyTools registers the API key admin tools when API keys are
+// enabled. Only key admins may call them; see keyAdminTenant.
+func (ms *MemoryServer) registerAPIKeyTools() {
+	if ms.container.GetAPIKeys() == nil {
+		return
+	}
+
+	keyID := map[string]interface{}{
+		"type":        "string",
+		"description": "ID of the key, as returned by auth_create_key or auth_list_keys",
+	}
+	ms.addTool(mcp.NewTool(
+		"auth_create_key",
+		"Admin tool: issue an API key for the HTTP transports, limited to the listed tools. The key is returned once and only its hash is stored; clients send it as 'X-API-Key' or 'Authorization: Bearer'. Callable over stdio, with a token granting the admin scope, or with a key allowing every tool and project; a token held to a tenant can only grant the tenant's projects.",
+		mcp.ObjectSchema("API key parameters", map[string]interface{}{
+			"name": map[string]interface{}{
+				"type":        "string",
+				"description": "Who or what the key is for - e.g. 'ci-bot'",
+			},
+			"allowed_tools": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Tools the key may call - e.g. ['memory_read', 'memory_create'], or ['*'] for all tools",
+			},
+			"projects": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Repositories the key's tenant owns when tenancy is enforced - e.g. ['github.com/acme/api'], or ['*'] for all of them",
+			},
+			"ttl_hours": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"description": "Hours until the key expires; omit or 0 for a key that does not expire",
+			},
+		}, []string{"name", "allowed_tools"}),
+	), mcp.ToolHandlerFunc(ms.handleCreateAPIKey))
+
+	ms.addTool(mcp.NewTool(
+		"auth_revoke_key",
+		"Admin tool: revoke an API key. Requests with it are refused from then on, including on connections opened with it. A token held to a tenant can only revoke keys of the tenant's projects.",
+		mcp.ObjectSchema("API key revocation parameters", map[string]interface{}{
+			"key_id": keyID,
+		}, []string{"key_id"}),
+	), mcp.ToolHandlerFunc(ms.handleRevokeAPIKey))
+
+	ms.addTool(mcp.NewTool(
+		"auth_rotate_key",
+		"Admin tool: replace an API key with a new one with the same name, tools, projects and lifetime. The old key keeps working for the grace period so clients can switch over. A token held to a tenant can only rotate keys of the tenant's projects.",
+		mcp.ObjectSchema("API key rotation parameters", map[string]interface{}{
+			"key_id": keyID,
+			"grace_hours": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"maximum":     auth.MaxRotationGrace.Hours(),
+				"default":     0,
+				"description": "Hours the old key keeps working; 0 revokes it at once",
+			},
+		}, []string{"key_id"}),
+	), mcp.ToolHandlerFunc(ms.handleRotateAPIKey))
+
+	ms.addTool(mcp.NewTool(
+		"auth_list_keys",
+		"Admin tool: list API keys with their allowed tools, expiry and revocation. Secrets are never shown. A token held to a tenant only sees keys of the tenant's projects.",
+		mcp.ObjectSchema("API key listing parameters", map[string]interface{}{}, nil),
+	), mcp.ToolHandlerFunc(ms.handleListAPIKeys))
+}
+
+// handleCreateAPIKey issues an API key
+func (ms *MemoryServer) handleCreateAPIKey(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	keys := ms.container.GetAPIKeys()
+	if keys == nil {
+		return nil, errors.New("API keys are disabled (set MCP_MEMORY_API_KEYS_ENABLED=true)")
+	}
+
+	owner, err := ms.keyAdminTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	name, _ := args["name"].(string)
+	tools := stringList(args["allowed_tools"])
+	projects := stringList(args["projects"])
+	if err := checkKeyProjects(owner, projects); err != nil {
+		return nil, err
+	}
+	var ttl time.Duration
+	if hours, ok := args["ttl_hours"].(float64); ok {
+		ttl = time.Duration(hours * float64(time.Hour))
+	}
+
+	secret, key, err := keys.Create(name, tools, projects, ttl)
+	if err != nil {
+		return nil, err
+	}
+	logging.Info("API key created", "key_id", key.ID, "name", key.Name, "allowed_tools", key.AllowedTools, "projects", key.Projects)
+	return map[string]interface{}{
+		"status":  "success",
+		"key":     key,
+		"api_key": secret,
+		"message": "Store the API key now: it cannot be shown again",
+	}, nil
+}
+
+// handleRevokeAPIKey revokes an API key
+func (ms *MemoryServer) handleRevokeAPIKey(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	keys := ms.container.GetAPIKeys()
+	if keys == nil {
+		return nil, errors.New("API keys are disabled (set MCP_MEMORY_API_KEYS_ENABLED=true)")
+	}
+
+	id, _ := args["key_id"].(string)
+	if err := ms.checkManagedKey(ctx, keys, id); err != nil {
+		return nil, err
+	}
+	key, err := keys.Revoke(id)
+	if err != nil {
+		return nil, err
+	}
+	logging.Info("API key revoked", "key_id", key.ID, "name", key.Name)
+	return map[string]interface{}{"status": "success", "key": key}, nil
+}
+
+// handleRotateAPIKey replaces an API key with a new one
+func (ms *MemoryServer) handleRotateAPIKey(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	keys := ms.container.GetAPIKeys()
+	if keys == nil {
+		return nil, errors.New("API keys are disabled (set MCP_MEMORY_API_KEYS_ENABLED=true)")
+	}
+
+	id, _ := args["key_id"].(string)
+	if err := ms.checkManagedKey(ctx, keys, id); err != nil {
+		return nil, err
+	}
+	var grace time.Duration
+	if hours, ok := args["grace_hours"].(float64); ok {
+		grace = time.Duration(hours * float64(time.Hour))
+	}
+	secret, key, err := keys.Rotate(id, grace)
+	if err != nil {
+		return nil, err
+	}
+	logging.Info("API key rotated", "key_id", id, "replacement_id", key.ID, "grace", grace)
+	return map[string]interface{}{
+		"status":     "success",
+		"rotated_id": id,
+		"key":        key,
+		"api_key":    secret,
+		"message":    "Store the API key now: it cannot be shown again",
+	}, nil
+}
+
+// handleListAPIKeys lists API keys
+func (ms *MemoryServer) handleListAPIKeys(ctx context.Context, _ map[string]interface{}) (interface{}, error) {
+	keys := ms.container.GetAPIKeys()
+	if keys == nil {
+		return nil, errors.New("API keys are disabled (set MCP_MEMORY_API_KEYS_ENABLED=true)")
+	}
+
+	owner, err := ms.keyAdminTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*auth.Key, 0)
+	for _, key := range keys.List() {
+		if checkKeyProjects(owner, key.Projects) == nil {
+			list = append(list, key)
+		}
+	}
+	return map[string]interface{}{"status": "success", "keys": list, "total": len(list)}, nil
+}
+
+// keyAdminTenant checks that the caller may manage API keys and returns the
+// tenant whose projects bound the keys it manages, nil for every key. Local
+// callers may, as may callers whose token grants the admin scope, bounded by
+// their tenant, and callers whose key allows every tool and every project it
+// lists, if any. Since a key admin may call every tool, keys are
+// never bounded by their tools.
+func (ms *MemoryServer) keyAdminTenant(ctx context.Context) (*tenancy.Tenant, error) {
+	tenant := tenancy.FromContext(ctx)
+	if key := auth.KeyFrom(ctx); key != nil {
+		if tenant == nil && len(key.Projects) > 0 {
+			tenant = tenancy.FromKey(key)
+		}
+		if !key.Allows(auth.AllTools) || (tenant != nil && !tenant.AllowsAll()) {
+			return nil, errNotKeyAdmin
+		}
+		return nil, nil
+	}
+	if claims := security.TokenClaimsFrom(ctx); claims != nil {
+		adminScope := ms.container.Config.Security.OAuth.AdminScope
+		if adminScope == "" || !claims.HasScope(adminScope) {
+			return nil, errNotKeyAdmin
+		}
+		return tenant, nil
+	}
+	return nil, nil
+}
+
+// checkManagedKey fails unless the caller may manage the key with ID id
+func (ms *MemoryServer) checkManagedKey(ctx context.Context, keys *auth.Store, id string) error {
+	owner, err := ms.keyAdminTenant(ctx)
+	if err != nil || owner == nil {
+		return err
+	}
+	// Listed rather than fetched, so revoked and expired keys are found too
+	for _, key := range keys.List() {
+		if key.ID == id {
+			return checkKeyProjects(owner, key.Projects)
+		}
+	}
+	return auth.ErrKeyNotFound
+}
+
+// checkKeyProjects fails unless owner, nil for every project, owns all of a key's projects
+func checkKeyProjects(owner *tenancy.Tenant, projects []string) error {
+	if owner == nil {
+		return nil
+	}
+	for _, project := range projects {
+		if !owner.Allows(project) {
+			return fmt.Errorf("%w: project %s of the key", tenancy.ErrCrossTenant, project)
+		}
+	}
+	return nil
+}
+
+// stringList returns the non-empty strings of a JSON array argument
+func stringList(value interface{}) []string {
+	list, _ := value.([]interface{})
+	var items []string
+	for _, item := range list {
+		if s, ok := item.(string); ok && s != "" {
+			items = append(items, s)
+		}
+	}
+	return items
+}
@@ -0,0 +1,156 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/auth"
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/di"
+	"lerian-mcp-memory/internal/security"
+	"lerian-mcp-memory/internal/tenancy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	keys := auth.NewStore("")
+	echoSecret, _, err := keys.Create("echo-only", []string{"echo"}, nil, 0)
+	require.NoError(t, err)
+	otherSecret, _, err := keys.Create("read-only", []string{"memory_read"}, nil, 0)
+	require.NoError(t, err)
+
+	ms := newMiddlewareTestServer()
+	ms.Use(APIKeyMiddleware(keys))
+
+	resp := ms.HandleRequest(context.Background(), toolCallRequest("echo", nil))
+	assert.Nil(t, resp.Error, "requests without a key, such as over stdio, are not restricted")
+
+	echoKey, err := keys.Authenticate(echoSecret)
+	require.NoError(t, err)
+	resp = ms.HandleRequest(auth.WithKey(context.Background(), echoKey), toolCallRequest("echo", nil))
+	assert.Nil(t, resp.Error)
+
+	otherKey, err := keys.Authenticate(otherSecret)
+	require.NoError(t, err)
+	resp = ms.HandleRequest(auth.WithKey(context.Background(), otherKey), toolCallRequest("echo", nil))
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, ForbiddenCode, resp.Error.Code)
+	assert.Equal(t, "TOOL_NOT_ALLOWED", resp.Error.Data.(map[string]interface{})["code"])
+
+	_, err = keys.Revoke(echoKey.ID)
+	require.NoError(t, err)
+	resp = ms.HandleRequest(auth.WithKey(context.Background(), echoKey), toolCallRequest("echo", nil))
+	require.NotNil(t, resp.Error, "a revoked key stops working on connections opened with it")
+	assert.Equal(t, "KEY_NOT_USABLE", resp.Error.Data.(map[string]interface{})["code"])
+}
+
+func TestAPIKeyTools(t *testing.T) {
+	ctx := context.Background()
+	keys := auth.NewStore("")
+	ms := &MemoryServer{container: &di.Container{APIKeys: keys}}
+
+	result, err := ms.handleCreateAPIKey(ctx, map[string]interface{}{
+		"name":          "ci-bot",
+		"allowed_tools": []interface{}{"memory_read", "memory_create"},
+		"ttl_hours":     float64(24),
+	})
+	require.NoError(t, err)
+	created := result.(map[string]interface{})
+	secret := created["api_key"].(string)
+	key := created["key"].(*auth.Key)
+	assert.NotNil(t, key.ExpiresAt)
+
+	_, err = keys.Authenticate(secret)
+	require.NoError(t, err)
+
+	// Only admin keys may manage keys
+	caller, err := keys.Authenticate(secret)
+	require.NoError(t, err)
+	_, err = ms.handleCreateAPIKey(auth.WithKey(ctx, caller), map[string]interface{}{
+		"name":          "escalation",
+		"allowed_tools": []interface{}{"*"},
+	})
+	assert.ErrorIs(t, err, errNotKeyAdmin)
+	_, err = ms.handleCreateAPIKey(auth.WithKey(ctx, &auth.Key{AllowedTools: []string{auth.AllTools}, Projects: []string{"acme/api"}}), map[string]interface{}{
+		"name":          "other-tenant",
+		"allowed_tools": []interface{}{"memory_read"},
+		"projects":      []interface{}{"rival/app"},
+	})
+	assert.ErrorIs(t, err, errNotKeyAdmin, "a key held to some projects is not an admin")
+
+	result, err = ms.handleRotateAPIKey(ctx, map[string]interface{}{"key_id": key.ID})
+	require.NoError(t, err)
+	rotated := result.(map[string]interface{})
+	_, err = keys.Authenticate(secret)
+	assert.ErrorIs(t, err, auth.ErrRevokedKey, "rotating without grace revokes the old key")
+
+	replacement := rotated["key"].(*auth.Key)
+	_, err = ms.handleRevokeAPIKey(ctx, map[string]interface{}{"key_id": replacement.ID})
+	require.NoError(t, err)
+	_, err = keys.Authenticate(rotated["api_key"].(string))
+	assert.ErrorIs(t, err, auth.ErrRevokedKey)
+
+	result, err = ms.handleListAPIKeys(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.(map[string]interface{})["total"])
+
+	_, err = (&MemoryServer{container: &di.Container{}}).handleCreateAPIKey(ctx, map[string]interface{}{"name": "x"})
+	assert.ErrorContains(t, err, "API keys are disabled")
+}
+
+func TestAPIKeyToolsNeedAnAdmin(t *testing.T) {
+	ctx := context.Background()
+	keys := auth.NewStore("")
+	cfg := config.DefaultConfig()
+	ms := &MemoryServer{container: &di.Container{APIKeys: keys, Config: cfg}}
+
+	_, admin, err := keys.Create("admin", []string{auth.AllTools}, []string{tenancy.AllProjects}, 0)
+	require.NoError(t, err)
+	_, own, err := keys.Create("acme-bot", []string{"memory_read"}, []string{"acme/api"}, 0)
+	require.NoError(t, err)
+	_, lister, err := keys.Create("key-lister", []string{"auth_list_keys", "auth_rotate_key"}, nil, 0)
+	require.NoError(t, err)
+
+	// A key listing the auth tools cannot take over an admin key
+	_, err = ms.handleRotateAPIKey(auth.WithKey(ctx, lister), map[string]interface{}{"key_id": admin.ID})
+	assert.ErrorIs(t, err, errNotKeyAdmin)
+	_, err = ms.handleListAPIKeys(auth.WithKey(ctx, lister), nil)
+	assert.ErrorIs(t, err, errNotKeyAdmin)
+	_, err = ms.handleListAPIKeys(auth.WithKey(ctx, admin), nil)
+	require.NoError(t, err)
+
+	// A token needs the admin scope
+	writer := &security.TokenClaims{Subject: "dev", Scopes: []string{"memory:read", "memory:write"}}
+	_, err = ms.handleCreateAPIKey(security.WithTokenClaims(ctx, writer), map[string]interface{}{
+		"name":          "escalation",
+		"allowed_tools": []interface{}{"*"},
+		"projects":      []interface{}{"*"},
+	})
+	assert.ErrorIs(t, err, errNotKeyAdmin)
+
+	// and is bounded by its tenant
+	claims := &security.TokenClaims{Subject: "acme-admin", Scopes: []string{"memory:admin"}, Projects: []string{"acme/api"}}
+	tenantAdmin := tenancy.WithTenant(security.WithTokenClaims(ctx, claims), tenancy.FromClaims(claims))
+	result, err := ms.handleListAPIKeys(tenantAdmin, nil)
+	require.NoError(t, err)
+	listed := result.(map[string]interface{})["keys"].([]*auth.Key)
+	ids := make([]string, 0, len(listed))
+	for _, key := range listed {
+		ids = append(ids, key.ID)
+	}
+	assert.ElementsMatch(t, []string{own.ID, lister.ID}, ids, "keys of other projects are left out")
+	_, err = ms.handleRotateAPIKey(tenantAdmin, map[string]interface{}{"key_id": admin.ID})
+	assert.ErrorIs(t, err, tenancy.ErrCrossTenant)
+	_, err = ms.handleRevokeAPIKey(tenantAdmin, map[string]interface{}{"key_id": admin.ID})
+	assert.ErrorIs(t, err, tenancy.ErrCrossTenant)
+	_, err = ms.handleCreateAPIKey(tenantAdmin, map[string]interface{}{
+		"name":          "wider",
+		"allowed_tools": []interface{}{"memory_read"},
+		"projects":      []interface{}{"rival/app"},
+	})
+	assert.ErrorIs(t, err, tenancy.ErrCrossTenant)
+	_, err = ms.handleRevokeAPIKey(tenantAdmin, map[string]interface{}{"key_id": own.ID})
+	require.NoError(t, err)
+}
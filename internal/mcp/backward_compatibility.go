@@ -96,7 +96,7 @@ func (ms *MemoryServer) registerBackwardCompatibilityLayer() {
 
 // registerCompatibilityWrapper creates a wrapper tool that routes to consolidated tools
 func (ms *MemoryServer) registerCompatibilityWrapper(originalName, description, consolidatedTool, operation, scope string) {
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		originalName,
 		fmt.Sprintf("[LEGACY] %s - Use %s with operation='%s' instead", description, consolidatedTool, operation),
 		mcp.ObjectSchema("Legacy tool parameters (will be passed as options)", map[string]interface{}{
@@ -136,7 +136,7 @@ func (ms *MemoryServer) registerCompatibilityWrapper(originalName, description,
 // registerBulkOperationCompatibility handles the special case of memory_bulk_operation
 // which routes to different consolidated tools based on the operation parameter
 func (ms *MemoryServer) registerBulkOperationCompatibility() {
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_bulk_operation",
 		"[LEGACY] Execute bulk operations - Use memory_create, memory_update, or memory_delete instead",
 		mcp.ObjectSchema("Bulk operation parameters", map[string]interface{}{
@@ -0,0 +1,113 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+const (
+	// defaultBatchConcurrency is how many batched requests run at once without configuration
+	defaultBatchConcurrency = 8
+	// defaultMaxBatchSize is the most requests a batch may hold without configuration
+	defaultMaxBatchSize = 100
+)
+
+// IsBatch reports whether a JSON-RPC message is a batch, i.e. a JSON array
+func IsBatch(data []byte) bool {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// HandleBatch serves a JSON-RPC 2.0 batch, running its requests concurrently
+// through the middleware chain, and returns what to send back: the responses
+// in request order, a single error response when the batch itself is
+// invalid, or nil when the batch held only notifications and client
+// responses. Client responses to server-to-client requests are delivered to
+// the connection in ctx, as HandleClientMessage does.
+func (ms *MemoryServer) HandleBatch(ctx context.Context, data []byte) interface{} {
+	var messages []json.RawMessage
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return &protocol.JSONRPCResponse{
+			JSONRPC: "2.0",
+			Error:   protocol.NewJSONRPCError(protocol.ParseError, "Parse error", err.Error()),
+		}
+	}
+	if len(messages) == 0 {
+		return &protocol.JSONRPCResponse{
+			JSONRPC: "2.0",
+			Error:   protocol.NewJSONRPCError(protocol.InvalidRequest, "Invalid request", "batch is empty"),
+		}
+	}
+
+	concurrency, maxSize := ms.batchLimits()
+	if len(messages) > maxSize {
+		return &protocol.JSONRPCResponse{
+			JSONRPC: "2.0",
+			Error:   protocol.NewJSONRPCError(protocol.InvalidRequest, "Invalid request", fmt.Sprintf("batch of %d requests exceeds the limit of %d", len(messages), maxSize)),
+		}
+	}
+
+	responses := make([]*protocol.JSONRPCResponse, len(messages))
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, message := range messages {
+		if ms.HandleClientMessage(connectionIDFrom(ctx), message) {
+			continue
+		}
+
+		var req protocol.JSONRPCRequest
+		if err := json.Unmarshal(message, &req); err != nil || req.Method == "" {
+			responses[i] = &protocol.JSONRPCResponse{
+				JSONRPC: "2.0",
+				Error:   protocol.NewJSONRPCError(protocol.InvalidRequest, "Invalid request", string(message)),
+			}
+			continue
+		}
+
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(i int, req *protocol.JSONRPCRequest) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			resp := ms.HandleRequest(ctx, req)
+			// Notifications get no response, even inside a batch
+			if req.ID != nil {
+				responses[i] = resp
+			}
+		}(i, &req)
+	}
+	wg.Wait()
+
+	batch := make([]*protocol.JSONRPCResponse, 0, len(responses))
+	for _, resp := range responses {
+		if resp != nil {
+			batch = append(batch, resp)
+		}
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	return batch
+}
+
+// batchLimits returns how many batched requests run at once and how many a batch may hold
+func (ms *MemoryServer) batchLimits() (concurrency, maxSize int) {
+	concurrency, maxSize = defaultBatchConcurrency, defaultMaxBatchSize
+	if ms.container == nil || ms.container.Config == nil {
+		return concurrency, maxSize
+	}
+	if ms.container.Config.Server.BatchConcurrency > 0 {
+		concurrency = ms.container.Config.Server.BatchConcurrency
+	}
+	if ms.container.Config.Server.MaxBatchSize > 0 {
+		maxSize = ms.container.Config.Server.MaxBatchSize
+	}
+	return concurrency, maxSize
+}
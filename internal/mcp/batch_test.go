@@ -0,0 +1,105 @@
+package mcp
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/di"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsBatch(t *testing.T) {
+	assert.True(t, IsBatch([]byte(` [{"jsonrpc":"2.0"}]`)))
+	assert.True(t, IsBatch([]byte("\n[]")))
+	assert.False(t, IsBatch([]byte(`{"jsonrpc":"2.0"}`)))
+	assert.False(t, IsBatch(nil))
+}
+
+func TestHandleBatch(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	batch := `[
+		{"jsonrpc": "2.0", "id": "a", "method": "tools/call", "params": {"name": "echo", "arguments": {}}},
+		{"jsonrpc": "2.0", "method": "notifications/initialized"},
+		{"jsonrpc": "2.0", "id": 2, "method": "tools/list"},
+		{"foo": "bar"}
+	]`
+
+	result := ms.HandleBatch(context.Background(), []byte(batch))
+
+	responses, ok := result.([]*protocol.JSONRPCResponse)
+	require.True(t, ok, "a batch gets a batch response")
+	require.Len(t, responses, 3, "notifications get no response")
+	assert.Equal(t, "a", responses[0].ID)
+	assert.Nil(t, responses[0].Error)
+	assert.EqualValues(t, 2, responses[1].ID)
+	assert.Nil(t, responses[1].Error)
+	require.NotNil(t, responses[2].Error)
+	assert.Equal(t, protocol.InvalidRequest, responses[2].Error.Code)
+}
+
+func TestHandleBatch_InvalidBatches(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	ms.container = &di.Container{Config: config.DefaultConfig()}
+	ms.container.Config.Server.MaxBatchSize = 2
+
+	for name, tc := range map[string]struct {
+		batch string
+		code  int
+	}{
+		"malformed": {batch: `[{"jsonrpc": "2.0"`, code: protocol.ParseError},
+		"empty":     {batch: `[]`, code: protocol.InvalidRequest},
+		"too large": {batch: `[{"jsonrpc": "2.0", "id": 1, "method": "ping"}, {"jsonrpc": "2.0", "id": 2, "method": "ping"}, {"jsonrpc": "2.0", "id": 3, "method": "ping"}]`, code: protocol.InvalidRequest},
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp, ok := ms.HandleBatch(context.Background(), []byte(tc.batch)).(*protocol.JSONRPCResponse)
+			require.True(t, ok, "an invalid batch gets a single error response")
+			require.NotNil(t, resp.Error)
+			assert.Equal(t, tc.code, resp.Error.Code)
+		})
+	}
+
+	assert.Nil(t, ms.HandleBatch(context.Background(), []byte(`[{"jsonrpc": "2.0", "method": "notifications/initialized"}]`)),
+		"a batch of notifications gets no response")
+}
+
+func TestHandleBatch_ConcurrencyLimit(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	ms.container = &di.Container{Config: config.DefaultConfig()}
+	ms.container.Config.Server.BatchConcurrency = 2
+
+	var inFlight, peak atomic.Int32
+	ms.Use(func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				seen := peak.Load()
+				if current <= seen || peak.CompareAndSwap(seen, current) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			return next(ctx, req)
+		}
+	})
+
+	batch := `[` +
+		`{"jsonrpc": "2.0", "id": 1, "method": "tools/list"},` +
+		`{"jsonrpc": "2.0", "id": 2, "method": "tools/list"},` +
+		`{"jsonrpc": "2.0", "id": 3, "method": "tools/list"},` +
+		`{"jsonrpc": "2.0", "id": 4, "method": "tools/list"},` +
+		`{"jsonrpc": "2.0", "id": 5, "method": "tools/list"}]`
+	responses, ok := ms.HandleBatch(context.Background(), []byte(batch)).([]*protocol.JSONRPCResponse)
+	require.True(t, ok)
+	require.Len(t, responses, 5)
+	for i, resp := range responses {
+		assert.EqualValues(t, i+1, resp.ID, "responses keep request order")
+	}
+	assert.Equal(t, int32(2), peak.Load())
+}
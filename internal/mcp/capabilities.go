@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/embeddings"
+	"lerian-mcp-memory/internal/llm"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+// Server modes reported in capabilities
+const (
+	ModeFull = "full"
+	ModeLite = "lite"
+)
+
+// FeatureCapabilities describes which memory features the running configuration supports.
+// Lite mode has no embedding provider, so vector-dependent features are reported as disabled.
+type FeatureCapabilities struct {
+	Mode           string          `json:"mode"`
+	EmbeddingModel string          `json:"embedding_model"`
+	Features       map[string]bool `json:"features"`
+	LLM            map[string]any  `json:"llm,omitempty"`
+	Notes          []string        `json:"notes,omitempty"`
+}
+
+// protocolCapabilities returns the optional MCP capabilities the server
+// configuration turned on; servers built without one serve them all
+func (ms *MemoryServer) protocolCapabilities() config.CapabilitiesConfig {
+	if ms.container == nil || ms.container.Config == nil {
+		return config.CapabilitiesConfig{Resources: true, Prompts: true}
+	}
+	return ms.container.Config.Server.Capabilities
+}
+
+// capabilityDisabled reports whether method belongs to a capability, resources
+// or prompts, that the server configuration turned off
+func (ms *MemoryServer) capabilityDisabled(method string) bool {
+	capabilities := ms.protocolCapabilities()
+	return (!capabilities.Resources && strings.HasPrefix(method, "resources/")) ||
+		(!capabilities.Prompts && strings.HasPrefix(method, "prompts/"))
+}
+
+// featureCapabilities reports the features available with the configured embedding service
+func (ms *MemoryServer) featureCapabilities() FeatureCapabilities {
+	service := ms.container.GetEmbeddingService()
+	semantic := embeddings.IsEnabled(service)
+
+	caps := FeatureCapabilities{
+		Mode:           ModeFull,
+		EmbeddingModel: service.GetModel(),
+		Features: map[string]bool{
+			"keyword_search":        true,
+			"semantic_search":       semantic,
+			"similarity_detection":  semantic,
+			"semantic_chain_links":  semantic,
+			"task_management":       true,
+			"relationships":         true,
+			"digests":               true,
+			"composite_operations":  true,
+			"progressive_relevance": semantic,
+			"llm_summarization":     ms.llmRouter().For(llm.FeatureSummarization) != nil,
+			"llm_conflict_verify":   ms.llmRouter().For(llm.FeatureConflictVerification) != nil,
+			"llm_insights":          ms.llmRouter().For(llm.FeatureInsights) != nil,
+			"ai_enrichment":         ms.aiEnrichmentEnabled(),
+		},
+	}
+	if router := ms.llmRouter(); router != nil {
+		caps.LLM = router.Status()
+	}
+
+	if !semantic {
+		caps.Mode = ModeLite
+		caps.Notes = []string{
+			"Running without an embedding provider: search uses BM25 keyword ranking",
+			"Relevance scores are normalized keyword scores, not cosine similarity",
+		}
+	}
+	return caps
+}
+
+// handleCapabilitiesResource serves memory://capabilities
+func (ms *MemoryServer) handleCapabilitiesResource() ([]protocol.Content, error) {
+	resultJSON, err := json.Marshal(ms.featureCapabilities())
+	if err != nil {
+		return nil, err
+	}
+	return []protocol.Content{protocol.NewContent(string(resultJSON))}, nil
+}
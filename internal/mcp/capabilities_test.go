@@ -0,0 +1,44 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/di"
+	"lerian-mcp-memory/internal/embeddings"
+	"lerian-mcp-memory/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeatureCapabilitiesLiteMode(t *testing.T) {
+	ms := &MemoryServer{container: &di.Container{
+		VectorStore:      storage.NewKeywordStore(""),
+		EmbeddingService: embeddings.NewDisabledEmbeddingService(),
+	}}
+
+	caps := ms.featureCapabilities()
+	assert.Equal(t, ModeLite, caps.Mode)
+	assert.True(t, caps.Features["keyword_search"])
+	assert.False(t, caps.Features["semantic_search"])
+
+	result, err := ms.handleHealth(context.Background(), nil)
+	require.NoError(t, err)
+	health := result.(map[string]interface{})
+	assert.Equal(t, "healthy", health["status"])
+	services := health["services"].(map[string]interface{})
+	assert.Equal(t, "disabled", services["embedding_service"].(map[string]interface{})["status"])
+}
+
+func TestFeatureCapabilitiesFullMode(t *testing.T) {
+	ms := &MemoryServer{container: &di.Container{
+		VectorStore:      storage.NewSimpleMockVectorStore(),
+		EmbeddingService: staticEmbeddingService{},
+	}}
+
+	caps := ms.featureCapabilities()
+	assert.Equal(t, ModeFull, caps.Mode)
+	assert.True(t, caps.Features["semantic_search"])
+	assert.Empty(t, caps.Notes)
+}
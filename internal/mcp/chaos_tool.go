@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"lerian-mcp-memory/internal/chaos"
+	"lerian-mcp-memory/internal/logging"
+
+	"github.com/fredcamaral/gomcp-sdk"
+)
+
+// registerChaosTool registers system_chaos when fault injection is enabled.
+// The tool is never exposed otherwise, so production servers cannot be
+// degraded through it.
+func (ms *MemoryServer) registerChaosTool() {
+	if ms.container.GetFaultInjector() == nil {
+		return
+	}
+
+	logging.Warn("Fault injection enabled: registering system_chaos admin tool")
+	ms.addTool(mcp.NewTool(
+		"system_chaos",
+		"Admin tool for resilience testing: inject errors and latency into the vector store or embedding service at runtime, then watch retries, circuit breakers and search fallbacks react. Operations: status (faults and counters), set (configure a target), clear (remove faults), enable/disable (pause injection without forgetting faults). Faults affect every tenant, so only callers owning every project may use it.",
+		mcp.ObjectSchema("Fault injection parameters", map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{OperationStatus, "set", "clear", "enable", "disable"},
+				"description": "Fault injection operation",
+			},
+			"target": map[string]interface{}{
+				"type":        "string",
+				"enum":        chaos.Targets(),
+				"description": "Dependency to inject faults into (required for set; clear without target clears all)",
+			},
+			"error_rate": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"maximum":     1,
+				"description": "Probability from 0 to 1 that a call fails (set)",
+			},
+			"latency_ms": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     0,
+				"description": "Latency added to every call in milliseconds (set)",
+			},
+		}, []string{"operation"}),
+	), mcp.ToolHandlerFunc(ms.handleChaos))
+}
+
+// handleChaos changes or reports the injected faults
+func (ms *MemoryServer) handleChaos(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: system_chaos called", "args", args)
+
+	injector := ms.container.GetFaultInjector()
+	if injector == nil {
+		return nil, errors.New("fault injection is disabled (set MCP_MEMORY_CHAOS_ENABLED=true)")
+	}
+	// Faults degrade the server for every tenant
+	if err := checkOperator(ctx, "system_chaos"); err != nil {
+		return nil, err
+	}
+
+	operation, _ := args["operation"].(string)
+	target, _ := args["target"].(string)
+
+	switch operation {
+	case OperationStatus:
+	case "set":
+		if target == "" {
+			return nil, errors.New("target is required for set")
+		}
+		var fault chaos.Fault
+		if rate, ok := args["error_rate"].(float64); ok {
+			fault.ErrorRate = rate
+		}
+		if latency, ok := args["latency_ms"].(float64); ok {
+			fault.LatencyMs = int(latency)
+		}
+		if err := injector.SetFault(target, fault); err != nil {
+			return nil, err
+		}
+		logging.Warn("Fault injection updated", "target", target, "error_rate", fault.ErrorRate, "latency_ms", fault.LatencyMs)
+	case "clear":
+		if err := injector.ClearFault(target); err != nil {
+			return nil, err
+		}
+	case "enable", "disable":
+		injector.SetActive(operation == "enable")
+		logging.Warn("Fault injection toggled", "active", operation == "enable")
+	default:
+		return nil, fmt.Errorf("invalid operation: %s (must be status, set, clear, enable or disable)", operation)
+	}
+
+	return map[string]interface{}{
+		"status":    "success",
+		"operation": operation,
+		"chaos":     injector.Status(),
+	}, nil
+}
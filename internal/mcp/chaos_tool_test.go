@@ -0,0 +1,52 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"lerian-mcp-memory/internal/chaos"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/tenancy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleChaos(t *testing.T) {
+	ctx := context.Background()
+	ms := newCompositeTestServer(t, storage.NewSimpleMockVectorStore())
+
+	_, err := ms.handleChaos(ctx, map[string]interface{}{"operation": "status"})
+	require.Error(t, err, "tool must refuse to work without fault injection enabled")
+
+	injector := chaos.NewInjector()
+	ms.container.FaultInjector = injector
+
+	result, err := ms.handleChaos(ctx, map[string]interface{}{
+		"operation":  "set",
+		"target":     chaos.TargetEmbeddings,
+		"error_rate": 1.0,
+		"latency_ms": float64(5),
+	})
+	require.NoError(t, err)
+	status := result.(map[string]interface{})["chaos"].(chaos.Status)
+	assert.Equal(t, chaos.Fault{ErrorRate: 1, LatencyMs: 5}, status.Targets[0].Fault)
+	assert.True(t, errors.Is(injector.Inject(ctx, chaos.TargetEmbeddings, "GenerateEmbedding"), chaos.ErrInjectedFault))
+
+	_, err = ms.handleChaos(ctx, map[string]interface{}{"operation": "disable"})
+	require.NoError(t, err)
+	assert.NoError(t, injector.Inject(ctx, chaos.TargetEmbeddings, "GenerateEmbedding"))
+
+	_, err = ms.handleChaos(ctx, map[string]interface{}{"operation": "set", "target": "database", "error_rate": 0.5})
+	assert.Error(t, err)
+	_, err = ms.handleChaos(ctx, map[string]interface{}{"operation": "explode"})
+	assert.Error(t, err)
+
+	tenant := tenancy.WithTenant(ctx, &tenancy.Tenant{ID: "api_key:acme", Projects: []string{"github.com/acme/api"}})
+	_, err = ms.handleChaos(tenant, map[string]interface{}{"operation": "set", "target": chaos.TargetEmbeddings, "error_rate": 1.0})
+	assert.ErrorIs(t, err, tenancy.ErrCrossTenant, "faults reach every tenant")
+	operator := tenancy.WithTenant(ctx, &tenancy.Tenant{ID: "api_key:ops", Projects: []string{tenancy.AllProjects}})
+	_, err = ms.handleChaos(operator, map[string]interface{}{"operation": "status"})
+	assert.NoError(t, err)
+}
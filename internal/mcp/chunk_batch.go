@@ -0,0 +1,91 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/pkg/types"
+)
+
+// OperationGetChunks fetches several chunks by ID in one call
+const OperationGetChunks = "get_chunks"
+
+// maxChunkBatchSize caps the number of IDs accepted by get_chunks
+const maxChunkBatchSize = 100
+
+// handleGetChunksBatch resolves a list of chunk IDs with a single store round trip.
+// Chunks outside the requested repository are reported as missing so that batch
+// lookups cannot be used to probe other tenants, and so are trashed chunks.
+func (ms *MemoryServer) handleGetChunksBatch(ctx context.Context, options map[string]interface{}, repository string) (interface{}, error) {
+	rawIDs, ok := options["chunk_ids"].([]interface{})
+	if !ok || len(rawIDs) == 0 {
+		return nil, errors.New("chunk_ids parameter is required for get_chunks and must be a non-empty array. Example: {\"chunk_ids\": [\"id1\", \"id2\"], \"repository\": \"github.com/user/repo\"}")
+	}
+	if len(rawIDs) > maxChunkBatchSize {
+		return nil, fmt.Errorf("get_chunks accepts at most %d chunk IDs, got %d", maxChunkBatchSize, len(rawIDs))
+	}
+
+	ids := make([]string, 0, len(rawIDs))
+	for _, raw := range rawIDs {
+		id, ok := raw.(string)
+		if !ok || id == "" {
+			return nil, errors.New("chunk_ids must contain non-empty strings")
+		}
+		ids = append(ids, id)
+	}
+
+	includeEmbeddings, _ := options["include_embeddings"].(bool)
+
+	chunks, err := ms.container.GetVectorStore().GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunks: %w", err)
+	}
+
+	found := make(map[string]bool, len(chunks))
+	visible := make([]types.ConversationChunk, 0, len(chunks))
+	for i := range chunks {
+		chunk := chunks[i]
+		if !chunkVisibleIn(&chunk, repository) || chunk.IsDeleted() {
+			continue
+		}
+		if !includeEmbeddings {
+			chunk.Embeddings = nil
+		}
+		found[chunk.ID] = true
+		visible = append(visible, chunk)
+	}
+
+	missing := make([]string, 0)
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+			found[id] = true // report duplicates once
+		}
+	}
+
+	retrieved := make([]string, 0, len(visible))
+	for i := range visible {
+		retrieved = append(retrieved, visible[i].ID)
+	}
+	ms.recordRetrieved(options, repository, retrieved...)
+
+	logging.Info("Batch chunk retrieval completed", "repository", repository, "requested", len(ids), "found", len(visible))
+
+	return map[string]interface{}{
+		"status":     "success",
+		"repository": repository,
+		"chunks":     visible,
+		"found":      len(visible),
+		"missing":    missing,
+	}, nil
+}
+
+// chunkVisibleIn reports whether a chunk may be returned for a repository-scoped read
+func chunkVisibleIn(chunk *types.ConversationChunk, repository string) bool {
+	if repository == GlobalRepository {
+		return true
+	}
+	return chunk.Metadata.Repository == repository || chunk.Metadata.Repository == GlobalMemoryRepository
+}
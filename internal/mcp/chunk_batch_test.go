@@ -0,0 +1,76 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetChunksBatch(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+
+	first := newTaskChunk(t, "github.com/acme/api", types.TaskStatusTodo)
+	second := newTaskChunk(t, "github.com/acme/api", types.TaskStatusInProgress)
+	foreign := newTaskChunk(t, "github.com/other/repo", types.TaskStatusTodo)
+	trashed := newTaskChunk(t, "github.com/acme/api", types.TaskStatusTodo)
+	deletedAt := time.Now()
+	trashed.Metadata.DeletedAt = &deletedAt
+	for _, c := range []*types.ConversationChunk{first, second, foreign, trashed} {
+		require.NoError(t, store.Store(ctx, c))
+	}
+
+	ms := newCompositeTestServer(t, store)
+	result, err := ms.handleMemoryRead(ctx, map[string]interface{}{
+		"operation": OperationGetChunks,
+		"options": map[string]interface{}{
+			"repository": "github.com/acme/api",
+			"chunk_ids":  []interface{}{second.ID, "unknown", foreign.ID, first.ID, trashed.ID},
+		},
+	})
+	require.NoError(t, err)
+
+	response := result.(map[string]interface{})
+	assert.Equal(t, 2, response["found"])
+	assert.Equal(t, []string{"unknown", foreign.ID, trashed.ID}, response["missing"], "chunks from other repositories and trashed chunks must be reported as missing")
+
+	chunks := response["chunks"].([]types.ConversationChunk)
+	require.Len(t, chunks, 2)
+	assert.Equal(t, second.ID, chunks[0].ID)
+	assert.Equal(t, first.ID, chunks[1].ID)
+	assert.Nil(t, chunks[0].Embeddings, "embeddings are omitted unless requested")
+}
+
+func TestGetChunksBatchValidation(t *testing.T) {
+	ms := newCompositeTestServer(t, storage.NewSimpleMockVectorStore())
+
+	tooMany := make([]interface{}, maxChunkBatchSize+1)
+	for i := range tooMany {
+		tooMany[i] = "id"
+	}
+
+	for name, ids := range map[string]interface{}{
+		"missing":    nil,
+		"empty":      []interface{}{},
+		"non-string": []interface{}{42},
+		"too many":   tooMany,
+	} {
+		t.Run(name, func(t *testing.T) {
+			options := map[string]interface{}{"repository": "github.com/acme/api"}
+			if ids != nil {
+				options["chunk_ids"] = ids
+			}
+			_, err := ms.handleMemoryRead(context.Background(), map[string]interface{}{
+				"operation": OperationGetChunks,
+				"options":   options,
+			})
+			assert.Error(t, err)
+		})
+	}
+}
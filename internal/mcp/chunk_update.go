@@ -0,0 +1,205 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"lerian-mcp-memory/internal/audit"
+	"lerian-mcp-memory/internal/locking"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/pkg/types"
+)
+
+// Versioned update and advisory lock operations for memory_update
+const (
+	OperationUpdateContent = "update_content"
+	OperationAcquireLock   = "acquire_lock"
+	OperationReleaseLock   = "release_lock"
+)
+
+// VersionConflictError reports a version-check-and-swap that lost a race
+type VersionConflictError struct {
+	ChunkID  string
+	Expected int64
+	Current  int64
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("version conflict on chunk %s: expected version %d, current version is %d", e.ChunkID, e.Expected, e.Current)
+}
+
+// errChunkUnchanged lets an updateChunk modify func skip the write when the
+// reread chunk no longer needs the change
+var errChunkUnchanged = errors.New("chunk unchanged")
+
+// updateChunk rereads a chunk and stores modify's changes to it with its
+// version bumped, all under the chunk's write mutex, so read-modify-write
+// paths never overwrite each other or a concurrent update_content. Writers
+// without the token of a live advisory lock on the chunk are refused with
+// locking.ErrLocked.
+func (ms *MemoryServer) updateChunk(ctx context.Context, chunkID, lockToken string, modify func(chunk *types.ConversationChunk) error) (*types.ConversationChunk, error) {
+	store := ms.container.GetVectorStore()
+
+	var updated *types.ConversationChunk
+	err := ms.chunkLocks.Serialize(chunkID, func() error {
+		chunk, err := store.GetByID(ctx, chunkID)
+		if err != nil {
+			return fmt.Errorf("failed to get chunk %s: %w", chunkID, err)
+		}
+		if err := ms.chunkLocks.CheckWrite(chunkID, lockToken); err != nil {
+			return err
+		}
+		if err := modify(chunk); err != nil {
+			return err
+		}
+
+		chunk.Metadata.Version++
+		if err := store.Update(ctx, chunk); err != nil {
+			return fmt.Errorf("failed to update chunk %s: %w", chunkID, err)
+		}
+		updated = chunk
+		return nil
+	})
+	return updated, err
+}
+
+// updateChunkContent replaces a chunk's content if its version still matches
+// expectedVersion and no other editor holds its lock. The stored version is
+// incremented on success.
+func (ms *MemoryServer) updateChunkContent(ctx context.Context, chunkID, repository, content, summary, lockToken string, expectedVersion int64) (*types.ConversationChunk, error) {
+	return ms.updateChunk(ctx, chunkID, lockToken, func(chunk *types.ConversationChunk) error {
+		if chunk.Metadata.Repository != repository {
+			return fmt.Errorf("chunk %s not found in repository %s", chunkID, repository)
+		}
+		if chunk.IsDeleted() {
+			return fmt.Errorf("chunk %s is in the trash; restore it before editing", chunkID)
+		}
+		if chunk.Metadata.Version != expectedVersion {
+			return &VersionConflictError{ChunkID: chunkID, Expected: expectedVersion, Current: chunk.Metadata.Version}
+		}
+
+		embeddings, err := ms.container.GetEmbeddingService().GenerateEmbedding(ctx, content)
+		if err != nil {
+			return fmt.Errorf("failed to generate embeddings: %w", err)
+		}
+
+		chunk.Content = content
+		if summary != "" {
+			chunk.Summary = summary
+		}
+		chunk.Embeddings = embeddings
+		return nil
+	})
+}
+
+// handleUpdateContent edits a chunk's content with optimistic concurrency
+func (ms *MemoryServer) handleUpdateContent(ctx context.Context, options map[string]interface{}) (interface{}, error) {
+	repository, _ := options["repository"].(string)
+	chunkID, _ := options["chunk_id"].(string)
+	content, _ := options["content"].(string)
+	if repository == "" || chunkID == "" || content == "" {
+		return nil, errors.New("update_content requires repository, chunk_id, content and expected_version. Example: {\"operation\": \"update_content\", \"options\": {\"repository\": \"github.com/user/repo\", \"chunk_id\": \"uuid\", \"content\": \"new text\", \"expected_version\": 0}}")
+	}
+	expected, ok := options["expected_version"].(float64)
+	if !ok || expected < 0 {
+		return nil, errors.New("expected_version is required for update_content and must be the non-negative version last read; fetch the chunk first to learn its current version")
+	}
+	summary, _ := options["summary"].(string)
+	lockToken, _ := options["lock_token"].(string)
+
+	chunk, err := ms.updateChunkContent(ctx, chunkID, repository, content, summary, lockToken, int64(expected))
+	var conflict *VersionConflictError
+	switch {
+	case errors.As(err, &conflict):
+		return map[string]interface{}{
+			"status":           "conflict",
+			"error":            conflict.Error(),
+			"chunk_id":         chunkID,
+			"expected_version": conflict.Expected,
+			"current_version":  conflict.Current,
+		}, nil
+	case errors.Is(err, locking.ErrLocked):
+		return map[string]interface{}{
+			"status":   "locked",
+			"error":    err.Error(),
+			"chunk_id": chunkID,
+		}, nil
+	case err != nil:
+		return nil, err
+	}
+
+	ms.container.AuditLogger.LogEvent(ctx, audit.EventTypeMemoryUpdate, "update_content", "chunk", chunkID, map[string]interface{}{
+		"repository": repository,
+		"version":    chunk.Metadata.Version,
+	})
+	logging.Info("Chunk content updated", "chunk_id", chunkID, "version", chunk.Metadata.Version)
+
+	return map[string]interface{}{
+		"status":     "success",
+		"chunk_id":   chunkID,
+		"repository": repository,
+		"version":    chunk.Metadata.Version,
+	}, nil
+}
+
+// handleAcquireLock takes or extends an advisory lock on a chunk
+func (ms *MemoryServer) handleAcquireLock(ctx context.Context, options map[string]interface{}) (interface{}, error) {
+	repository, _ := options["repository"].(string)
+	chunkID, _ := options["chunk_id"].(string)
+	owner, _ := options["owner"].(string)
+	if repository == "" || chunkID == "" || owner == "" {
+		return nil, errors.New("acquire_lock requires repository, chunk_id and owner. Example: {\"operation\": \"acquire_lock\", \"options\": {\"repository\": \"github.com/user/repo\", \"chunk_id\": \"uuid\", \"owner\": \"consolidation-job\", \"ttl_seconds\": 300}}")
+	}
+
+	chunk, err := ms.container.GetVectorStore().GetByID(ctx, chunkID)
+	if err != nil || chunk.Metadata.Repository != repository {
+		return nil, fmt.Errorf("chunk %s not found in repository %s", chunkID, repository)
+	}
+
+	var ttl time.Duration
+	if seconds, ok := options["ttl_seconds"].(float64); ok {
+		ttl = time.Duration(seconds) * time.Second
+	}
+
+	lock, err := ms.chunkLocks.Acquire(chunkID, owner, ttl)
+	if errors.Is(err, locking.ErrLocked) {
+		return map[string]interface{}{
+			"status":   "locked",
+			"error":    err.Error(),
+			"chunk_id": chunkID,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"status":     "success",
+		"chunk_id":   chunkID,
+		"lock_token": lock.Token,
+		"owner":      lock.Owner,
+		"expires_at": lock.ExpiresAt.Format(time.RFC3339),
+		"version":    chunk.Metadata.Version,
+	}, nil
+}
+
+// handleReleaseLock drops an advisory lock held by the caller
+func (ms *MemoryServer) handleReleaseLock(_ context.Context, options map[string]interface{}) (interface{}, error) {
+	chunkID, _ := options["chunk_id"].(string)
+	lockToken, _ := options["lock_token"].(string)
+	if chunkID == "" || lockToken == "" {
+		return nil, errors.New("release_lock requires chunk_id and lock_token. Example: {\"operation\": \"release_lock\", \"options\": {\"repository\": \"github.com/user/repo\", \"chunk_id\": \"uuid\", \"lock_token\": \"token\"}}")
+	}
+
+	if err := ms.chunkLocks.Release(chunkID, lockToken); err != nil {
+		return nil, fmt.Errorf("failed to release lock on chunk %s: %w", chunkID, err)
+	}
+
+	return map[string]interface{}{
+		"status":   "success",
+		"chunk_id": chunkID,
+		"released": true,
+	}, nil
+}
@@ -0,0 +1,153 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"lerian-mcp-memory/internal/locking"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/tags"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateContentVersionCheck(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	chunk := newTaskChunk(t, "github.com/acme/api", types.TaskStatusTodo)
+	require.NoError(t, store.Store(ctx, chunk))
+
+	ms := newCompositeTestServer(t, store)
+	update := func(content string, expected float64) map[string]interface{} {
+		result, err := ms.handleMemoryUpdate(ctx, map[string]interface{}{
+			"operation": OperationUpdateContent,
+			"options": map[string]interface{}{
+				"repository":       "github.com/acme/api",
+				"chunk_id":         chunk.ID,
+				"content":          content,
+				"expected_version": expected,
+			},
+		})
+		require.NoError(t, err)
+		return result.(map[string]interface{})
+	}
+
+	first := update("first edit", 0)
+	assert.Equal(t, "success", first["status"])
+	assert.Equal(t, int64(1), first["version"])
+
+	stale := update("concurrent edit", 0)
+	assert.Equal(t, "conflict", stale["status"])
+	assert.Equal(t, int64(1), stale["current_version"])
+
+	stored, err := store.GetByID(ctx, chunk.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "first edit", stored.Content, "a stale edit must not overwrite")
+}
+
+func TestUpdateContentRespectsLock(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	chunk := newTaskChunk(t, "github.com/acme/api", types.TaskStatusTodo)
+	require.NoError(t, store.Store(ctx, chunk))
+
+	ms := newCompositeTestServer(t, store)
+	acquired, err := ms.handleMemoryUpdate(ctx, map[string]interface{}{
+		"operation": OperationAcquireLock,
+		"options": map[string]interface{}{
+			"repository": "github.com/acme/api",
+			"chunk_id":   chunk.ID,
+			"owner":      "consolidation",
+		},
+	})
+	require.NoError(t, err)
+	token := acquired.(map[string]interface{})["lock_token"].(string)
+
+	options := map[string]interface{}{
+		"repository":       "github.com/acme/api",
+		"chunk_id":         chunk.ID,
+		"content":          "edited",
+		"expected_version": float64(0),
+	}
+	blocked, err := ms.handleMemoryUpdate(ctx, map[string]interface{}{"operation": OperationUpdateContent, "options": options})
+	require.NoError(t, err)
+	assert.Equal(t, "locked", blocked.(map[string]interface{})["status"])
+
+	options["lock_token"] = token
+	allowed, err := ms.handleMemoryUpdate(ctx, map[string]interface{}{"operation": OperationUpdateContent, "options": options})
+	require.NoError(t, err)
+	assert.Equal(t, "success", allowed.(map[string]interface{})["status"])
+
+	_, err = ms.handleMemoryUpdate(ctx, map[string]interface{}{
+		"operation": OperationReleaseLock,
+		"options":   map[string]interface{}{"chunk_id": chunk.ID, "lock_token": token},
+	})
+	require.NoError(t, err)
+}
+
+func TestTagRewriteRacesUpdateContent(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewLocalVectorStore("")
+	ms := newCompositeTestServer(t, store)
+	ms.container.Tags = tags.NewStore("")
+	chunk := newReportChunk(t, "s1", "Pods restart on OOM", types.ChunkTypeProblem, types.ChunkMetadata{Tags: []string{"k8s"}})
+	require.NoError(t, store.Store(ctx, chunk))
+	_, err := ms.handleTagManage(ctx, map[string]interface{}{"operation": "create", "tag": "k8s"})
+	require.NoError(t, err)
+
+	update := func(expected float64) map[string]interface{} {
+		result, err := ms.handleMemoryUpdate(ctx, map[string]interface{}{
+			"operation": OperationUpdateContent,
+			"options": map[string]interface{}{
+				"repository":       "github.com/acme/api",
+				"chunk_id":         chunk.ID,
+				"content":          "Pods restart on OOM; raised the memory limit",
+				"expected_version": expected,
+			},
+		})
+		require.NoError(t, err)
+		return result.(map[string]interface{})
+	}
+
+	var edit map[string]interface{}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		edit = update(0)
+	}()
+	go func() {
+		defer wg.Done()
+		_, err := ms.handleTagManage(ctx, map[string]interface{}{"operation": "rename", "tag": "k8s", "new_name": "kubernetes", "confirm": "k8s"})
+		assert.NoError(t, err)
+	}()
+	wg.Wait()
+
+	// Whichever write lands first, the other sees its version bump: a stale
+	// edit is refused rather than overwriting the renamed tags
+	if edit["status"] == "conflict" {
+		assert.Equal(t, int64(1), edit["current_version"])
+		edit = update(1)
+	}
+	assert.Equal(t, "success", edit["status"])
+
+	stored, err := store.GetByID(ctx, chunk.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Pods restart on OOM; raised the memory limit", stored.Content)
+	assert.Equal(t, []string{"kubernetes"}, stored.Metadata.Tags, "the tag rename must survive the content edit")
+	assert.Equal(t, int64(2), stored.Metadata.Version)
+
+	lock, err := ms.chunkLocks.Acquire(chunk.ID, "consolidation", 0)
+	require.NoError(t, err)
+	_, err = ms.handleTagManage(ctx, map[string]interface{}{"operation": "rename", "tag": "kubernetes", "new_name": "k8s", "confirm": "kubernetes"})
+	require.ErrorIs(t, err, locking.ErrLocked, "an advisory lock blocks tag rewrites")
+	require.NoError(t, ms.chunkLocks.Release(chunk.ID, lock.Token))
+
+	stored, err = store.GetByID(ctx, chunk.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kubernetes"}, stored.Metadata.Tags)
+	assert.Equal(t, int64(2), stored.Metadata.Version)
+}
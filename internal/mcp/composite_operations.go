@@ -0,0 +1,366 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"lerian-mcp-memory/internal/audit"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/saga"
+	"lerian-mcp-memory/pkg/types"
+
+	mcp "github.com/fredcamaral/gomcp-sdk"
+)
+
+// Composite operation names exposed by the memory_composite tool
+const (
+	OperationCompleteTaskWithOutcome = "complete_task_with_outcome"
+	OperationResolveProblem          = "resolve_problem"
+	OperationStoreDecisionWithLinks  = "store_decision_with_links"
+)
+
+// registerCompositeTool registers the memory_composite tool for multi-step operations
+func (ms *MemoryServer) registerCompositeTool() {
+	ms.addTool(mcp.NewTool(
+		"memory_composite",
+		"Run common multi-step memory operations as a single all-or-nothing call. If any step fails, the steps already applied are undone (saga compensation). CRITICAL: 'options' parameter MUST be a JSON object (not a JSON string). REQUIRED fields: repository and session_id for ALL operations; complete_task_with_outcome requires task_id+content; resolve_problem requires problem_chunk_id+content; store_decision_with_links requires decision+rationale.",
+		mcp.ObjectSchema("Composite operation parameters", map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{OperationCompleteTaskWithOutcome, OperationResolveProblem, OperationStoreDecisionWithLinks},
+				"description": "complete_task_with_outcome: complete a task, store its outcome memory and link them; resolve_problem: store a solution, link it to the problem and mark the problem resolved; store_decision_with_links: store a decision and link it to related chunks",
+			},
+			"options": map[string]interface{}{
+				"type":                 "object",
+				"description":          "Operation-specific parameters",
+				"additionalProperties": true,
+				"properties": map[string]interface{}{
+					"repository": map[string]interface{}{
+						"type":        "string",
+						"description": "Repository URL (required) - e.g. 'github.com/user/repo'",
+					},
+					"session_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Session identifier (required)",
+					},
+					"task_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Task chunk ID (required for complete_task_with_outcome)",
+					},
+					"problem_chunk_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Problem chunk ID (required for resolve_problem)",
+					},
+					"content": map[string]interface{}{
+						"type":        "string",
+						"description": "Outcome or solution content to store (required for complete_task_with_outcome and resolve_problem)",
+					},
+					"decision": map[string]interface{}{
+						"type":        "string",
+						"description": "Decision text (required for store_decision_with_links)",
+					},
+					"rationale": map[string]interface{}{
+						"type":        "string",
+						"description": "Decision rationale (required for store_decision_with_links)",
+					},
+					"related_chunk_ids": map[string]interface{}{
+						"type":        "array",
+						"description": "Chunks to link to the new decision (store_decision_with_links)",
+						"items":       map[string]interface{}{"type": "string"},
+					},
+					"tags": map[string]interface{}{
+						"type":        "array",
+						"description": "Tags for the stored memory",
+						"items":       map[string]interface{}{"type": "string"},
+					},
+				},
+			},
+		}, []string{"operation", "options"}),
+	), mcp.ToolHandlerFunc(ms.handleMemoryComposite))
+}
+
+// compositeOutput builds the response of a composite operation once its saga has committed
+type compositeOutput func() map[string]interface{}
+
+// handleMemoryComposite routes composite operations to their saga builders
+func (ms *MemoryServer) handleMemoryComposite(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	operation, ok := args["operation"].(string)
+	if !ok {
+		return nil, errors.New("operation parameter is required. Example: {\"operation\": \"complete_task_with_outcome\", \"options\": {\"task_id\": \"task-123\", \"content\": \"Shipped the migration\", \"session_id\": \"session-123\", \"repository\": \"github.com/user/repo\"}}")
+	}
+
+	options, ok := args["options"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("options parameter is required and must be a JSON object")
+	}
+
+	repository, ok := options["repository"].(string)
+	if !ok || repository == "" {
+		return nil, errors.New("repository parameter is required for composite operations. Example: {\"repository\": \"github.com/user/repo\"}")
+	}
+
+	sessionID, ok := options["session_id"].(string)
+	if !ok || sessionID == "" {
+		return nil, errors.New("session_id parameter is required for composite operations")
+	}
+
+	logging.Info("MCP TOOL: memory_composite called", "operation", operation, "repository", repository)
+
+	var (
+		s      *saga.Saga
+		output compositeOutput
+		err    error
+	)
+
+	switch operation {
+	case OperationCompleteTaskWithOutcome:
+		s, output, err = ms.buildCompleteTaskWithOutcome(ctx, repository, sessionID, options)
+	case OperationResolveProblem:
+		s, output, err = ms.buildResolveProblem(ctx, repository, sessionID, options)
+	case OperationStoreDecisionWithLinks:
+		s, output, err = ms.buildStoreDecisionWithLinks(ctx, repository, sessionID, options)
+	default:
+		validOps := []string{OperationCompleteTaskWithOutcome, OperationResolveProblem, OperationStoreDecisionWithLinks}
+		return nil, fmt.Errorf("unsupported composite operation '%s'. Valid operations: %s", operation, strings.Join(validOps, ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.Execute(ctx)
+	ms.container.GetAuditLogger().LogEvent(ctx, audit.EventTypeMemoryUpdate, operation, "composite", sessionID, map[string]interface{}{
+		"repository": repository,
+		"status":     string(result.Status),
+	})
+	if err != nil {
+		logging.Error("memory_composite failed", "operation", operation, "status", result.Status, "error", err)
+		return nil, fmt.Errorf("composite operation %s failed and was rolled back (status: %s): %w", operation, result.Status, err)
+	}
+
+	response := output()
+	response["operation"] = operation
+	response["status"] = string(result.Status)
+	response["steps"] = result.Steps
+	logging.Info("memory_composite completed", "operation", operation)
+	return response, nil
+}
+
+// buildCompleteTaskWithOutcome completes a task, stores its outcome memory and links them
+func (ms *MemoryServer) buildCompleteTaskWithOutcome(ctx context.Context, repository, sessionID string, options map[string]interface{}) (*saga.Saga, compositeOutput, error) {
+	taskID, ok := options["task_id"].(string)
+	if !ok || taskID == "" {
+		return nil, nil, errors.New("task_id parameter is required for complete_task_with_outcome")
+	}
+	content, ok := options["content"].(string)
+	if !ok || content == "" {
+		return nil, nil, errors.New("content parameter is required for complete_task_with_outcome")
+	}
+
+	task, err := ms.validateTaskChunk(ctx, taskID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outcome := types.OutcomeSuccess
+	if o, ok := options["outcome"].(string); ok && o != "" {
+		outcome = types.Outcome(o)
+	}
+
+	outcomeChunk, err := ms.newCompositeChunk(ctx, sessionID, content, types.ChunkTypeTaskProgress, repository, outcome, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	outcomeChunk.Metadata.ExtendedMetadata = map[string]interface{}{"parent_task_id": taskID}
+
+	original := *task
+	var completed types.ConversationChunk
+	completedStatus := types.TaskStatusCompleted
+	progress := 100
+
+	var relationshipID string
+	store := ms.container.GetVectorStore()
+
+	s := saga.New(OperationCompleteTaskWithOutcome).
+		AddStep("complete_task",
+			func(ctx context.Context) error {
+				updated, err := ms.updateChunk(ctx, taskID, "", func(chunk *types.ConversationChunk) error {
+					chunk.Metadata.TaskStatus = &completedStatus
+					chunk.Metadata.TaskProgress = &progress
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+				completed = *updated
+				return nil
+			},
+			func(ctx context.Context) error {
+				_, err := ms.updateChunk(ctx, taskID, "", func(chunk *types.ConversationChunk) error {
+					chunk.Metadata.TaskStatus = original.Metadata.TaskStatus
+					chunk.Metadata.TaskProgress = original.Metadata.TaskProgress
+					return nil
+				})
+				return err
+			}).
+		AddStep("store_outcome",
+			func(ctx context.Context) error { return store.StoreChunk(ctx, outcomeChunk) },
+			func(ctx context.Context) error { return store.Delete(ctx, outcomeChunk.ID) }).
+		AddStep("link_task_outcome",
+			func(ctx context.Context) error {
+				rel, err := store.StoreRelationship(ctx, taskID, outcomeChunk.ID, types.RelationLedTo, 1.0, types.ConfidenceExplicit)
+				if err != nil {
+					return err
+				}
+				relationshipID = rel.ID
+				return nil
+			},
+			func(ctx context.Context) error { return store.DeleteRelationship(ctx, relationshipID) }).
+		AddStep("notify",
+			func(context.Context) error {
+				ms.notifyTaskTransition(&completed, currentTaskStatus(&original))
+				return nil
+			}, nil)
+
+	output := func() map[string]interface{} {
+		return map[string]interface{}{
+			"task_id":          taskID,
+			"outcome_chunk_id": outcomeChunk.ID,
+			"relationship_id":  relationshipID,
+		}
+	}
+	return s, output, nil
+}
+
+// buildResolveProblem stores a solution, links it to the problem and marks the problem resolved
+func (ms *MemoryServer) buildResolveProblem(ctx context.Context, repository, sessionID string, options map[string]interface{}) (*saga.Saga, compositeOutput, error) {
+	problemID, ok := options["problem_chunk_id"].(string)
+	if !ok || problemID == "" {
+		return nil, nil, errors.New("problem_chunk_id parameter is required for resolve_problem")
+	}
+	content, ok := options["content"].(string)
+	if !ok || content == "" {
+		return nil, nil, errors.New("content parameter is required for resolve_problem")
+	}
+
+	store := ms.container.GetVectorStore()
+	problem, err := store.GetByID(ctx, problemID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get problem chunk: %w", err)
+	}
+
+	solution, err := ms.newCompositeChunk(ctx, sessionID, content, types.ChunkTypeSolution, repository, types.OutcomeSuccess, options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	originalOutcome := problem.Metadata.Outcome
+
+	var relationshipID string
+
+	s := saga.New(OperationResolveProblem).
+		AddStep("store_solution",
+			func(ctx context.Context) error { return store.StoreChunk(ctx, solution) },
+			func(ctx context.Context) error { return store.Delete(ctx, solution.ID) }).
+		AddStep("link_problem_solution",
+			func(ctx context.Context) error {
+				rel, err := store.StoreRelationship(ctx, problemID, solution.ID, types.RelationSolvedBy, 1.0, types.ConfidenceExplicit)
+				if err != nil {
+					return err
+				}
+				relationshipID = rel.ID
+				return nil
+			},
+			func(ctx context.Context) error { return store.DeleteRelationship(ctx, relationshipID) }).
+		AddStep("mark_problem_resolved",
+			func(ctx context.Context) error { return ms.setChunkOutcome(ctx, problemID, types.OutcomeSuccess) },
+			func(ctx context.Context) error { return ms.setChunkOutcome(ctx, problemID, originalOutcome) })
+
+	output := func() map[string]interface{} {
+		return map[string]interface{}{
+			"problem_chunk_id":  problemID,
+			"solution_chunk_id": solution.ID,
+			"relationship_id":   relationshipID,
+		}
+	}
+	return s, output, nil
+}
+
+// setChunkOutcome records the outcome of a chunk
+func (ms *MemoryServer) setChunkOutcome(ctx context.Context, chunkID string, outcome types.Outcome) error {
+	_, err := ms.updateChunk(ctx, chunkID, "", func(chunk *types.ConversationChunk) error {
+		chunk.Metadata.Outcome = outcome
+		return nil
+	})
+	return err
+}
+
+// buildStoreDecisionWithLinks stores a decision and links it to each related chunk
+func (ms *MemoryServer) buildStoreDecisionWithLinks(ctx context.Context, repository, sessionID string, options map[string]interface{}) (*saga.Saga, compositeOutput, error) {
+	decision, ok := options["decision"].(string)
+	if !ok || decision == "" {
+		return nil, nil, errors.New("decision parameter is required for store_decision_with_links")
+	}
+	rationale, ok := options["rationale"].(string)
+	if !ok || rationale == "" {
+		return nil, nil, errors.New("rationale parameter is required for store_decision_with_links")
+	}
+
+	content := fmt.Sprintf("ARCHITECTURAL DECISION: %s\n\nRATIONALE: %s", decision, rationale)
+	decisionChunk, err := ms.newCompositeChunk(ctx, sessionID, content, types.ChunkTypeArchitectureDecision, repository, types.OutcomeSuccess, options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store := ms.container.GetVectorStore()
+	s := saga.New(OperationStoreDecisionWithLinks).
+		AddStep("store_decision",
+			func(ctx context.Context) error { return store.StoreChunk(ctx, decisionChunk) },
+			func(ctx context.Context) error { return store.Delete(ctx, decisionChunk.ID) })
+
+	relatedIDs := extractStringArray(options["related_chunk_ids"])
+	relationshipIDs := make([]string, len(relatedIDs))
+	for i, relatedID := range relatedIDs {
+		s.AddStep("link_"+relatedID,
+			func(ctx context.Context) error {
+				rel, err := store.StoreRelationship(ctx, decisionChunk.ID, relatedID, types.RelationRelatedTo, 1.0, types.ConfidenceExplicit)
+				if err != nil {
+					return err
+				}
+				relationshipIDs[i] = rel.ID
+				return nil
+			},
+			func(ctx context.Context) error { return store.DeleteRelationship(ctx, relationshipIDs[i]) })
+	}
+
+	output := func() map[string]interface{} {
+		return map[string]interface{}{
+			"decision_chunk_id": decisionChunk.ID,
+			"relationship_ids":  relationshipIDs,
+		}
+	}
+	return s, output, nil
+}
+
+// newCompositeChunk creates a chunk with embeddings ready to be stored by a saga step
+func (ms *MemoryServer) newCompositeChunk(ctx context.Context, sessionID, content string, chunkType types.ChunkType, repository string, outcome types.Outcome, options map[string]interface{}) (*types.ConversationChunk, error) {
+	metadata := types.ChunkMetadata{
+		Repository: repository,
+		Outcome:    outcome,
+		Difficulty: types.DifficultyModerate,
+		Tags:       extractStringArray(options["tags"]),
+	}
+
+	chunk, err := types.NewConversationChunk(sessionID, content, chunkType, &metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create chunk: %w", err)
+	}
+
+	embeddings, err := ms.container.GetEmbeddingService().GenerateEmbedding(ctx, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate embeddings: %w", err)
+	}
+	chunk.Embeddings = embeddings
+	return chunk, nil
+}
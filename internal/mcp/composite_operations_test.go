@@ -0,0 +1,140 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"lerian-mcp-memory/internal/audit"
+	"lerian-mcp-memory/internal/di"
+	"lerian-mcp-memory/internal/locking"
+	"lerian-mcp-memory/internal/monitoring"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticEmbeddingService returns a fixed embedding for any text
+type staticEmbeddingService struct{}
+
+func (staticEmbeddingService) GenerateEmbedding(context.Context, string) ([]float64, error) {
+	return []float64{0.1, 0.2, 0.3}, nil
+}
+
+func (staticEmbeddingService) GenerateBatchEmbeddings(_ context.Context, texts []string) ([][]float64, error) {
+	result := make([][]float64, len(texts))
+	for i := range texts {
+		result[i] = []float64{0.1, 0.2, 0.3}
+	}
+	return result, nil
+}
+
+func (staticEmbeddingService) GetDimension() int                 { return 3 }
+func (staticEmbeddingService) GetModel() string                  { return "static" }
+func (staticEmbeddingService) HealthCheck(context.Context) error { return nil }
+
+// failingLinkStore fails relationship creation to exercise saga compensation
+type failingLinkStore struct {
+	storage.VectorStore
+}
+
+func (f *failingLinkStore) StoreRelationship(context.Context, string, string, types.RelationType, float64, types.ConfidenceSource) (*types.MemoryRelationship, error) {
+	return nil, errors.New("relationship store unavailable")
+}
+
+func newCompositeTestServer(t *testing.T, store storage.VectorStore) *MemoryServer {
+	t.Helper()
+	auditLogger, err := audit.NewLogger(t.TempDir())
+	require.NoError(t, err)
+	return &MemoryServer{container: &di.Container{
+		VectorStore:      store,
+		EmbeddingService: staticEmbeddingService{},
+		AuditLogger:      auditLogger,
+	}, chunkLocks: locking.NewManager(), healthScores: monitoring.NewHealthScores()}
+}
+
+func TestCompleteTaskWithOutcome(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	task := newTaskChunk(t, "github.com/acme/api", types.TaskStatusInProgress)
+	require.NoError(t, store.Store(ctx, task))
+
+	ms := newCompositeTestServer(t, store)
+	result, err := ms.handleMemoryComposite(ctx, map[string]interface{}{
+		"operation": OperationCompleteTaskWithOutcome,
+		"options": map[string]interface{}{
+			"repository": "github.com/acme/api",
+			"session_id": "session-1",
+			"task_id":    task.ID,
+			"content":    "Migration shipped",
+		},
+	})
+	require.NoError(t, err)
+
+	response := result.(map[string]interface{})
+	assert.Equal(t, "committed", response["status"])
+
+	updated, err := store.GetByID(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.TaskStatusCompleted, *updated.Metadata.TaskStatus)
+
+	outcome, err := store.GetByID(ctx, response["outcome_chunk_id"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, "Migration shipped", outcome.Content)
+
+	rel, err := store.GetRelationshipByID(ctx, response["relationship_id"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, types.RelationLedTo, rel.RelationType)
+}
+
+func TestCompleteTaskWithOutcomeRollsBack(t *testing.T) {
+	ctx := context.Background()
+	base := storage.NewSimpleMockVectorStore()
+	task := newTaskChunk(t, "github.com/acme/api", types.TaskStatusInProgress)
+	require.NoError(t, base.Store(ctx, task))
+
+	ms := newCompositeTestServer(t, &failingLinkStore{VectorStore: base})
+	_, err := ms.handleMemoryComposite(ctx, map[string]interface{}{
+		"operation": OperationCompleteTaskWithOutcome,
+		"options": map[string]interface{}{
+			"repository": "github.com/acme/api",
+			"session_id": "session-1",
+			"task_id":    task.ID,
+			"content":    "Migration shipped",
+		},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rolled back")
+
+	restored, err := base.GetByID(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.TaskStatusInProgress, *restored.Metadata.TaskStatus)
+
+	chunks, err := base.GetAllChunks(ctx)
+	require.NoError(t, err)
+	assert.Len(t, chunks, 1, "outcome chunk must be removed by compensation")
+}
+
+func TestMemoryCompositeValidation(t *testing.T) {
+	ms := newCompositeTestServer(t, storage.NewSimpleMockVectorStore())
+
+	tests := []struct {
+		name string
+		args map[string]interface{}
+	}{
+		{"missing operation", map[string]interface{}{"options": map[string]interface{}{}}},
+		{"missing repository", map[string]interface{}{"operation": OperationResolveProblem, "options": map[string]interface{}{"session_id": "s"}}},
+		{"missing session", map[string]interface{}{"operation": OperationResolveProblem, "options": map[string]interface{}{"repository": "r"}}},
+		{"unknown operation", map[string]interface{}{"operation": "teleport", "options": map[string]interface{}{"repository": "r", "session_id": "s"}}},
+		{"missing decision", map[string]interface{}{"operation": OperationStoreDecisionWithLinks, "options": map[string]interface{}{"repository": "r", "session_id": "s"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ms.handleMemoryComposite(context.Background(), tt.args)
+			assert.Error(t, err)
+		})
+	}
+}
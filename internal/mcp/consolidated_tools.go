@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/pkg/types"
 	"strings"
 
 	mcp "github.com/fredcamaral/gomcp-sdk"
@@ -15,15 +16,16 @@ import (
 // registerConsolidatedTools registers the 9 consolidated MCP tools
 func (ms *MemoryServer) registerConsolidatedTools() {
 	// 1. memory_create - All creation operations
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"memory_create",
-		"Handle all memory creation operations. CRITICAL: 'options' parameter MUST be a JSON object (not a JSON string). REQUIRED fields: repository is mandatory for ALL operations for multi-tenant isolation; store_chunk/store_decision require session_id+repository; create_thread requires name+description+chunk_ids+repository; create_relationship requires source_chunk_id+target_chunk_id+relation_type+repository. Use repository='global' for cross-project architecture decisions.",
+		"Handle all memory creation operations. CRITICAL: 'options' parameter MUST be a JSON object (not a JSON string). REQUIRED fields: repository is mandatory for ALL operations for multi-tenant isolation; store_chunk/store_decision require session_id+repository; create_thread requires name+description+chunk_ids+repository; create_relationship requires source_chunk_id+target_chunk_id+relation_type+repository; define_relation_type requires name+description+repository. Use repository='global' for cross-project architecture decisions.",
 		mcp.ObjectSchema("Memory creation parameters", map[string]interface{}{
 			"operation": map[string]interface{}{
 				"type": "string",
 				"enum": []string{
 					OperationStoreChunk, OperationStoreDecision, "create_thread", "create_alias",
 					"create_relationship", "auto_detect_relationships", "import_context", "bulk_import",
+					OperationDefineRelationType,
 				},
 				"description": "Type of creation operation to perform",
 			},
@@ -35,7 +37,7 @@ func (ms *MemoryServer) registerConsolidatedTools() {
 			},
 			"options": map[string]interface{}{
 				"type":                 "object",
-				"description":          "Operation-specific parameters. REQUIRED fields: repository is mandatory for ALL operations; store_chunk/store_decision require session_id+repository; create_thread requires name+description+chunk_ids+repository; create_relationship requires source_chunk_id+target_chunk_id+relation_type+repository",
+				"description":          "Operation-specific parameters. REQUIRED fields: repository is mandatory for ALL operations; store_chunk/store_decision require session_id+repository; create_thread requires name+description+chunk_ids+repository; create_relationship requires source_chunk_id+target_chunk_id+relation_type+repository; define_relation_type requires name+description+repository",
 				"additionalProperties": true,
 				"properties": map[string]interface{}{
 					"repository": map[string]interface{}{
@@ -44,7 +46,7 @@ func (ms *MemoryServer) registerConsolidatedTools() {
 					},
 					"session_id": map[string]interface{}{
 						"type":        "string",
-						"description": "Session ID (required for store_chunk, store_decision, import_context)",
+						"description": "Session ID (required for store_chunk, store_decision, import_context). Stored chunks, and both chunks of create_relationship when set, join the session's working set (memory://session/{session_id}/working-set)",
 					},
 					"content": map[string]interface{}{
 						"type":        "string",
@@ -60,11 +62,11 @@ func (ms *MemoryServer) registerConsolidatedTools() {
 					},
 					"name": map[string]interface{}{
 						"type":        "string",
-						"description": "Thread name (required for create_thread)",
+						"description": "Thread name (required for create_thread) or snake_case relation type name (required for define_relation_type)",
 					},
 					"description": map[string]interface{}{
 						"type":        "string",
-						"description": "Thread description (required for create_thread)",
+						"description": "Thread description (required for create_thread) or relation type description (required for define_relation_type)",
 					},
 					"chunk_ids": map[string]interface{}{
 						"type":        "array",
@@ -81,28 +83,49 @@ func (ms *MemoryServer) registerConsolidatedTools() {
 					},
 					"relation_type": map[string]interface{}{
 						"type":        "string",
-						"description": "Relationship type (required for create_relationship)",
+						"description": "Relationship type (required for create_relationship). Use memory_read list_relation_types for the valid options",
+					},
+					"directionality": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"directed", "symmetric"},
+						"default":     "directed",
+						"description": "Relation type directionality (define_relation_type)",
+					},
+					"inverse": map[string]interface{}{
+						"type":        "string",
+						"description": "Inverse relation type name for directed types (define_relation_type, optional)",
 					},
 					"data": map[string]interface{}{
 						"type":        "string",
 						"description": "Data to import (required for import_context)",
 					},
+					"provenance": map[string]interface{}{
+						"type":        "object",
+						"description": "Where the content came from, set by capturing clients such as CLIs, git hooks and importers. Defaults to {\"source_system\": \"mcp\"} for store_chunk",
+						"properties":  provenanceSchemaProperties(),
+					},
+					"memory_class": map[string]interface{}{
+						"type":        "string",
+						"enum":        memoryClassNames(),
+						"description": "Memory class for store_chunk: episodic (session logs, consolidated then trashed after their retention), semantic (distilled facts) or procedural (how-tos). Defaults by chunk type: decisions and analyses are semantic, solutions procedural, the rest episodic",
+					},
 				},
 			},
 		}, []string{"operation", "options"}),
 	), mcp.ToolHandlerFunc(ms.handleMemoryCreate))
 
 	// 2. memory_read - All read/query operations
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"memory_read",
-		"Handle all memory read operations. CRITICAL: 'options' parameter MUST be a JSON object (not a JSON string). REQUIRED fields: repository parameter is mandatory for ALL operations for multi-tenant isolation; search requires query+repository; get_context requires repository; find_similar requires problem+repository; get_relationships requires chunk_id+repository; search_multi_repo requires query+session_id+repository.",
+		"Handle all memory read operations. CRITICAL: 'options' parameter MUST be a JSON object (not a JSON string). REQUIRED fields: repository parameter is mandatory for ALL operations for multi-tenant isolation; search requires query+repository; get_context requires repository; find_similar requires problem+repository; get_relationships requires chunk_id+repository; search_multi_repo requires query+session_id+repository; search_federated requires query+repository and searches repositories too; get_chunks requires chunk_ids+repository; list_relation_types requires repository.",
 		mcp.ObjectSchema("Memory read parameters", map[string]interface{}{
 			"operation": map[string]interface{}{
 				"type": "string",
 				"enum": []string{
 					"search", "get_context", "find_similar", "get_patterns", "get_relationships",
 					"traverse_graph", "get_threads", "search_explained", "search_multi_repo",
-					"resolve_alias", "list_aliases", "get_bulk_progress",
+					"resolve_alias", "list_aliases", "get_bulk_progress", OperationGetChunks,
+					OperationListRelationTypes, OperationSearchFederated,
 				},
 				"description": "Type of read operation to perform",
 			},
@@ -114,12 +137,12 @@ func (ms *MemoryServer) registerConsolidatedTools() {
 			},
 			"options": map[string]interface{}{
 				"type":                 "object",
-				"description":          "Operation-specific parameters. REQUIRED fields: repository is mandatory for ALL operations for multi-tenant isolation; search requires query+repository; get_context requires repository; find_similar requires problem+repository; get_relationships requires chunk_id+repository; search_multi_repo requires query+session_id+repository",
+				"description":          "Operation-specific parameters. REQUIRED fields: repository is mandatory for ALL operations for multi-tenant isolation; search requires query+repository; get_context requires repository; find_similar requires problem+repository; get_relationships requires chunk_id+repository; search_multi_repo requires query+session_id+repository; search_federated requires query+repository; get_chunks requires chunk_ids+repository",
 				"additionalProperties": true,
 				"properties": map[string]interface{}{
 					"query": map[string]interface{}{
 						"type":        "string",
-						"description": "Search query (required for search, search_multi_repo)",
+						"description": "Search query (required for search, search_multi_repo, search_federated)",
 					},
 					"repository": map[string]interface{}{
 						"type":        "string",
@@ -139,7 +162,22 @@ func (ms *MemoryServer) registerConsolidatedTools() {
 					},
 					"session_id": map[string]interface{}{
 						"type":        "string",
-						"description": "Session ID (required for search_multi_repo)",
+						"description": "Session ID (required for search_multi_repo). When set, search, find_similar, search_federated and get_chunks add their results to the session's working set (memory://session/{session_id}/working-set)",
+					},
+					"repositories": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Additional repositories searched with repository by search_federated (up to 20 in total); repositories disabled in configuration are skipped",
+					},
+					"per_project_limit": map[string]interface{}{
+						"type":        "integer",
+						"default":     5,
+						"description": "Most results any one repository contributes to search_federated (1-20)",
+					},
+					"diversity_decay": map[string]interface{}{
+						"type":        "number",
+						"default":     0.85,
+						"description": "Factor (0-1] applied to each further result from the same repository when search_federated reranks; lower values mix repositories more",
 					},
 					"alias_name": map[string]interface{}{
 						"type":        "string",
@@ -149,21 +187,78 @@ func (ms *MemoryServer) registerConsolidatedTools() {
 						"type":        "string",
 						"description": "Operation ID (required for get_bulk_progress)",
 					},
+					"chunk_ids": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Chunk IDs to fetch in one call, up to 100 (required for get_chunks)",
+					},
+					"include_embeddings": map[string]interface{}{
+						"type":        "boolean",
+						"default":     false,
+						"description": "Include embedding vectors in get_chunks results",
+					},
+					"highlight": map[string]interface{}{
+						"type":        "boolean",
+						"default":     true,
+						"description": "Attach a highlight to each search result: the best-matching passage with matched terms wrapped in ** and their byte spans",
+					},
+					"context_sentences": map[string]interface{}{
+						"type":        "integer",
+						"default":     1,
+						"description": "Sentences of context kept on each side of the best-matching passage in search highlights (0-5)",
+					},
+					"expand_relationships": map[string]interface{}{
+						"type":        "boolean",
+						"default":     false,
+						"description": "Also return chunks of the repository one relationship away from the search results (e.g. the decision a bug fix references), listed separately in expanded_results with the linking path",
+					},
+					"expansion_min_confidence": map[string]interface{}{
+						"type":        "number",
+						"default":     defaultExpansionMinConfidence,
+						"description": "Minimum relationship confidence followed by expand_relationships (0-1)",
+					},
+					"expansion_limit": map[string]interface{}{
+						"type":        "integer",
+						"default":     defaultExpansionLimit,
+						"description": "Most expanded results returned by expand_relationships (1-20)",
+					},
+					"mode": map[string]interface{}{
+						"type":        "string",
+						"enum":        searchModeNames(),
+						"description": "Ranking for search and find_similar: vector similarity, BM25 keyword score (finds exact identifiers such as error codes), or hybrid, which fuses both rankings with reciprocal rank fusion. Default: the server's configured mode, normally vector",
+					},
+					"provenance": map[string]interface{}{
+						"type":        "object",
+						"description": "Restrict search results to chunks whose provenance matches every given field (source_system, commit_sha, author, capture_tool)",
+						"properties":  provenanceSchemaProperties(),
+					},
+					"classes": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string", "enum": memoryClassNames()},
+						"description": "Restrict search results to memory classes. Semantic and procedural memories rank above episodic ones, and episodic memories already consolidated rank lowest",
+					},
+					"include_archived": map[string]interface{}{
+						"type":        "boolean",
+						"default":     false,
+						"description": "Also search memories a decay policy archived (search). Archived memories are kept but left out of searches by default",
+					},
 				},
 			},
 		}, []string{"operation", "options"}),
 	), mcp.ToolHandlerFunc(ms.handleMemoryRead))
 
 	// 3. memory_update - All update operations
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"memory_update",
-		"Handle all memory update operations including thread updates, relationship updates, refreshing memories, and conflict resolution. CRITICAL: 'options' parameter MUST be a JSON object (not a JSON string). REQUIRED fields: repository is mandatory for ALL operations for multi-tenant isolation.",
+		"Handle all memory update operations including thread updates, relationship updates, refreshing memories, conflict resolution and recording whether stored solutions worked (verify_solution). CRITICAL: 'options' parameter MUST be a JSON object (not a JSON string). REQUIRED fields: repository is mandatory for ALL operations for multi-tenant isolation.",
 		mcp.ObjectSchema("Memory update parameters", map[string]interface{}{
 			"operation": map[string]interface{}{
 				"type": "string",
 				"enum": []string{
 					"update_thread", "update_relationship", "mark_refreshed",
 					"resolve_conflicts", "bulk_update", "decay_management",
+					OperationUpdateContent, OperationAcquireLock, OperationReleaseLock,
+					OperationVerifySolution,
 				},
 				"description": "Type of update operation to perform",
 			},
@@ -175,7 +270,7 @@ func (ms *MemoryServer) registerConsolidatedTools() {
 			},
 			"options": map[string]interface{}{
 				"type":                 "object",
-				"description":          "Operation-specific parameters. REQUIRED fields: repository is mandatory for ALL operations; update_thread requires thread_id+repository; update_relationship requires relationship_id+repository; mark_refreshed requires chunk_id+validation_notes+repository; decay_management requires repository+session_id+action",
+				"description":          "Operation-specific parameters. REQUIRED fields: repository is mandatory for ALL operations; update_thread requires thread_id+repository; update_relationship requires relationship_id+repository; mark_refreshed requires chunk_id+validation_notes+repository; decay_management requires repository+session_id+action; update_content requires chunk_id+content+expected_version+repository; acquire_lock requires chunk_id+owner+repository; release_lock requires chunk_id+lock_token; verify_solution requires chunk_id+status+repository",
 				"additionalProperties": true,
 				"properties": map[string]interface{}{
 					"repository": map[string]interface{}{
@@ -192,7 +287,46 @@ func (ms *MemoryServer) registerConsolidatedTools() {
 					},
 					"chunk_id": map[string]interface{}{
 						"type":        "string",
-						"description": "Chunk ID (required for mark_refreshed)",
+						"description": "Chunk ID (required for mark_refreshed, update_content, acquire_lock, release_lock and verify_solution)",
+					},
+					"content": map[string]interface{}{
+						"type":        "string",
+						"description": "New chunk content (required for update_content)",
+					},
+					"summary": map[string]interface{}{
+						"type":        "string",
+						"description": "Replacement summary for update_content (optional, the existing summary is kept otherwise)",
+					},
+					"expected_version": map[string]interface{}{
+						"type":        "integer",
+						"description": "Version of the chunk the edit is based on (required for update_content). A mismatch is rejected with status 'conflict' and the current version",
+					},
+					"lock_token": map[string]interface{}{
+						"type":        "string",
+						"description": "Token returned by acquire_lock (required for release_lock, and for update_content and verify_solution while the chunk is locked)",
+					},
+					"status": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{types.VerificationVerified, types.VerificationFailed},
+						"description": "Verdict on the solution (required for verify_solution). Verified solutions rank higher in search and failed ones lower",
+					},
+					"evidence": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "For verify_solution: links to what showed the verdict, such as CI runs, commits or tickets",
+					},
+					"note": map[string]interface{}{
+						"type":        "string",
+						"description": "For verify_solution: how the solution was checked",
+					},
+					"owner": map[string]interface{}{
+						"type":        "string",
+						"description": "Lock holder name, e.g. 'consolidation-job' (required for acquire_lock)",
+					},
+					"ttl_seconds": map[string]interface{}{
+						"type":        "integer",
+						"default":     300,
+						"description": "Lock lease length in seconds for acquire_lock (max 3600)",
 					},
 					"validation_notes": map[string]interface{}{
 						"type":        "string",
@@ -221,7 +355,7 @@ func (ms *MemoryServer) registerConsolidatedTools() {
 	), mcp.ToolHandlerFunc(ms.handleMemoryUpdate))
 
 	// 4. memory_delete - All deletion operations
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"memory_delete",
 		"Handle all memory deletion operations including bulk deletions and filtered deletions. CRITICAL: 'options' parameter MUST be a JSON object (not a JSON string). REQUIRED fields: repository parameter is mandatory for ALL operations to prevent cross-tenant data deletion.",
 		mcp.ObjectSchema("Memory delete parameters", map[string]interface{}{
@@ -246,6 +380,11 @@ func (ms *MemoryServer) registerConsolidatedTools() {
 						"description": "Array of IDs to delete (required for bulk_delete)",
 						"items":       map[string]interface{}{"type": "string"},
 					},
+					"permanent": map[string]interface{}{
+						"type":        "boolean",
+						"default":     false,
+						"description": "Skip the trash and delete immediately. By default deleted memories move to the trash and can be restored with memory_restore until the retention period expires",
+					},
 					"repository": map[string]interface{}{
 						"type":        "string",
 						"description": "Repository URL (REQUIRED for ALL delete operations for security and multi-tenant isolation) - must include full URL like 'github.com/user/repo', 'gitlab.com/user/repo', etc.",
@@ -256,15 +395,17 @@ func (ms *MemoryServer) registerConsolidatedTools() {
 	), mcp.ToolHandlerFunc(ms.handleMemoryDelete))
 
 	// 5. memory_analyze - All analysis operations
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"memory_analyze",
-		"Handle memory analysis operations. CRITICAL: 'options' parameter MUST be a JSON object (not a JSON string). REQUIRED fields: repository is mandatory for ALL operations for multi-tenant isolation; health_dashboard requires repository+session_id; cross_repo_patterns requires session_id+repository; find_similar_repositories requires repository+session_id.",
+		"Handle memory analysis operations. CRITICAL: 'options' parameter MUST be a JSON object (not a JSON string). REQUIRED fields: repository is mandatory for ALL operations for multi-tenant isolation; health_dashboard requires repository+session_id; cross_repo_patterns requires session_id+repository; find_similar_repositories requires repository+session_id. On-demand reports: quality_report (memory quality scores and weakest memories), conflict_scan (contradictions grouped by severity), stale_report (outdated memories with suggested actions), knowledge_gaps (unresolved problems, open questions, undocumented hotspots) - each requires only repository. stale_knowledge flags memories mentioning files or symbols that were deleted or renamed; it requires repository plus a files manifest or repo_path, which quality_report also accepts to lower the score of such memories. verification_coverage reports how many solutions were verified or failed (per repository for 'global') and lists the oldest awaiting a verdict. health_score rates knowledge hygiene from 0 to 100 (recent activity, staleness, conflicts, untagged memories, verified solutions), for every repository with 'global'.",
 		mcp.ObjectSchema("Memory analysis parameters", map[string]interface{}{
 			"operation": map[string]interface{}{
 				"type": "string",
 				"enum": []string{
 					"cross_repo_patterns", "find_similar_repositories", "cross_repo_insights",
 					"detect_conflicts", "health_dashboard", "check_freshness", "detect_threads",
+					"quality_report", "conflict_scan", "stale_report", "knowledge_gaps", OperationStaleKnowledge,
+					OperationVerificationCoverage, OperationHealthScore,
 				},
 				"description": "Type of analysis operation to perform",
 			},
@@ -287,19 +428,62 @@ func (ms *MemoryServer) registerConsolidatedTools() {
 						"type":        "string",
 						"description": "Session ID (required for health_dashboard, cross_repo_patterns, find_similar_repositories)",
 					},
+					"max_chunks": map[string]interface{}{
+						"type":        "integer",
+						"default":     defaultReportChunks,
+						"description": "Number of most recent memories analyzed by report operations (max 1000)",
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"default":     defaultReportItems,
+						"description": "Maximum findings listed by report operations",
+					},
+					"quality_threshold": map[string]interface{}{
+						"type":        "number",
+						"default":     defaultQualityThreshold,
+						"description": "For quality_report: memories with overall quality below this (0-1) are listed",
+					},
+					"threshold_days": map[string]interface{}{
+						"type":        "integer",
+						"description": "For stale_report: only list stale memories at least this many days old",
+					},
+					"files": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "For stale_knowledge and quality_report: manifest of file paths currently in the repository",
+					},
+					"symbols": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "With files: symbols currently defined. Symbol references are only checked when given",
+					},
+					"renames": map[string]interface{}{
+						"type":                 "object",
+						"additionalProperties": map[string]interface{}{"type": "string"},
+						"description":          "With files: map of old path to new path for renamed files",
+					},
+					"repo_path": map[string]interface{}{
+						"type":        "string",
+						"description": "Instead of files: local git work tree to read files, renames and symbols from. Must lie inside the client's roots when it shares any",
+					},
+					"flag": map[string]interface{}{
+						"type":        "boolean",
+						"default":     false,
+						"description": "For stale_knowledge: record broken references in each memory's metadata (stale_code_references) and clear them once they resolve",
+					},
 				},
 			},
 		}, []string{"operation", "options"}),
 	), mcp.ToolHandlerFunc(ms.handleMemoryAnalyze))
 
 	// 6. memory_intelligence - AI-powered operations
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"memory_intelligence",
-		"Handle AI-powered operations. CRITICAL: 'options' parameter MUST be a JSON object (not a JSON string). REQUIRED fields: repository is mandatory for ALL operations for multi-tenant isolation; suggest_related requires current_context+session_id+repository; auto_insights requires repository+session_id; pattern_prediction requires context+repository+session_id.",
+		"Handle AI-powered operations. CRITICAL: 'options' parameter MUST be a JSON object (not a JSON string). REQUIRED fields: repository is mandatory for ALL operations for multi-tenant isolation; suggest_related requires current_context+session_id+repository; auto_insights requires repository+session_id; pattern_prediction requires context+repository+session_id; extract_decisions requires repository (optional chunk_id, limit) and promotes decision statements such as 'we decided to...' into linked architecture_decision chunks; consolidate_memories requires repository+session_id or chunk_ids and promotes episodic memories into one semantic (or procedural) memory linked to them.",
 		mcp.ObjectSchema("Memory intelligence parameters", map[string]interface{}{
 			"operation": map[string]interface{}{
 				"type":        "string",
-				"enum":        []string{"suggest_related", "auto_insights", "pattern_prediction"},
+				"enum":        []string{"suggest_related", "auto_insights", "pattern_prediction", OperationExtractDecisions, OperationConsolidateMemories},
 				"description": "Type of intelligence operation to perform",
 			},
 			"scope": map[string]interface{}{
@@ -310,7 +494,7 @@ func (ms *MemoryServer) registerConsolidatedTools() {
 			},
 			"options": map[string]interface{}{
 				"type":                 "object",
-				"description":          "Operation-specific parameters. REQUIRED fields: repository is mandatory for ALL operations for multi-tenant isolation; suggest_related requires current_context+session_id+repository; auto_insights requires repository+session_id; pattern_prediction requires context+repository+session_id",
+				"description":          "Operation-specific parameters. REQUIRED fields: repository is mandatory for ALL operations for multi-tenant isolation; suggest_related requires current_context+session_id+repository; auto_insights requires repository+session_id; pattern_prediction requires context+repository+session_id; extract_decisions requires repository; consolidate_memories requires repository+session_id or chunk_ids",
 				"additionalProperties": true,
 				"properties": map[string]interface{}{
 					"repository": map[string]interface{}{
@@ -323,25 +507,53 @@ func (ms *MemoryServer) registerConsolidatedTools() {
 					},
 					"session_id": map[string]interface{}{
 						"type":        "string",
-						"description": "Session ID (required for suggest_related, auto_insights, pattern_prediction)",
+						"description": "Session ID (required for suggest_related, auto_insights, pattern_prediction; consolidate_memories takes it or chunk_ids)",
 					},
 					"context": map[string]interface{}{
 						"type":        "string",
 						"description": "Context for prediction (required for pattern_prediction)",
 					},
+					"chunk_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Chunk to extract decisions from (extract_decisions); without it the most recent unscanned chunks of the repository are scanned",
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"default":     100,
+						"description": "Recent chunks scanned by extract_decisions (max 500)",
+					},
+					"chunk_ids": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Episodic chunks to consolidate (consolidate_memories); without them the session's episodic chunks are consolidated",
+					},
+					"memory_class": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{string(types.MemoryClassSemantic), string(types.MemoryClassProcedural)},
+						"default":     string(types.MemoryClassSemantic),
+						"description": "Class of the consolidated memory (consolidate_memories)",
+					},
+					"content": map[string]interface{}{
+						"type":        "string",
+						"description": "Distilled content of the consolidated memory (consolidate_memories); defaults to a list of what each source was about",
+					},
+					"summary": map[string]interface{}{
+						"type":        "string",
+						"description": "Summary of the consolidated memory (consolidate_memories)",
+					},
 				},
 			},
 		}, []string{"operation", "options"}),
 	), mcp.ToolHandlerFunc(ms.handleMemoryIntelligence))
 
 	// 7. memory_transfer - Data transfer operations
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"memory_transfer",
-		"Handle data transfer operations with pagination support. CRITICAL: 'options' parameter MUST be a JSON object (not a JSON string). REQUIRED fields: repository is mandatory for ALL operations for multi-tenant isolation; export_project requires repository+session_id (optional: limit, offset, format, include_vectors); import_context requires data+repository+session_id; continuity requires repository.",
+		"Handle data transfer operations with pagination support. CRITICAL: 'options' parameter MUST be a JSON object (not a JSON string). REQUIRED fields: repository is mandatory for ALL operations for multi-tenant isolation; export_project requires repository+session_id (optional: limit, offset, format, include_vectors); import_context requires data+repository+session_id; continuity requires repository; export_site requires repository (optional: title) and renders the project's decisions, patterns and verified solutions as a static HTML site with search and relationship graphs, e.g. for GitHub Pages.",
 		mcp.ObjectSchema("Memory transfer parameters", map[string]interface{}{
 			"operation": map[string]interface{}{
 				"type":        "string",
-				"enum":        []string{"export_project", "bulk_export", "continuity", "import_context"},
+				"enum":        []string{"export_project", "bulk_export", "continuity", "import_context", "export_site"},
 				"description": "Type of transfer operation to perform",
 			},
 			"scope": map[string]interface{}{
@@ -382,7 +594,7 @@ func (ms *MemoryServer) registerConsolidatedTools() {
 					},
 					"format": map[string]interface{}{
 						"type":        "string",
-						"description": "Export format for export_project: 'json' (default), 'markdown', or 'archive'",
+						"description": "Export format for export_project: 'json' (default), 'markdown', or 'archive'. JSON and archive exports carry the page's relationships and the repository's custom relation types and import back with import_context source 'archive'",
 						"enum":        []string{"json", "markdown", "archive"},
 						"default":     "json",
 					},
@@ -391,13 +603,22 @@ func (ms *MemoryServer) registerConsolidatedTools() {
 						"description": "Include embedding vectors in export_project output (default: false) - Warning: significantly increases response size",
 						"default":     false,
 					},
+					"title": map[string]interface{}{
+						"type":        "string",
+						"description": "Site title for export_site (default: the repository)",
+					},
+					"skip_invalid": map[string]interface{}{
+						"type":        "boolean",
+						"description": "For import_context with source 'archive': import what passes the referential integrity checks (task dependencies, parents, relationship endpoints and relation types) and report the rest, instead of rejecting the whole archive (default: false)",
+						"default":     false,
+					},
 				},
 			},
 		}, []string{"operation", "options"}),
 	), mcp.ToolHandlerFunc(ms.handleMemoryTransfer))
 
 	// 8. memory_tasks - Task and workflow management operations
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"memory_tasks",
 		"Handle task management and workflow tracking operations. CRITICAL: 'options' parameter MUST be a JSON object (not a JSON string). DECISION GUIDE for session_id: OMIT session_id for cross-session task continuity (RECOMMENDED - allows access to todos from previous conversations). INCLUDE session_id only when you need session-specific task isolation. BEHAVIORAL DIFFERENCE: Without session_id = repository-wide todos visible across all LLM sessions; With session_id = session-isolated todos.",
 		mcp.ObjectSchema("Memory tasks parameters", map[string]interface{}{
@@ -406,6 +627,7 @@ func (ms *MemoryServer) registerConsolidatedTools() {
 				"enum": []string{
 					"todo_write", "todo_read", "todo_update", "session_create", "session_end",
 					"session_list", "workflow_analyze", "task_completion_stats",
+					OperationSessionHandoff, OperationSessionResume,
 				},
 				"description": "Type of task operation to perform",
 			},
@@ -417,7 +639,7 @@ func (ms *MemoryServer) registerConsolidatedTools() {
 			},
 			"options": map[string]interface{}{
 				"type":                 "object",
-				"description":          "Operation-specific parameters. REQUIRED: repository for all operations. TODO OPERATIONS DECISION: For todo_write/todo_read/todo_update, OMIT session_id for cross-session continuity (recommended), INCLUDE session_id for session isolation. SESSION OPERATIONS: session_create, session_end, workflow_analyze require session_id.",
+				"description":          "Operation-specific parameters. REQUIRED: repository for all operations. TODO OPERATIONS DECISION: For todo_write/todo_read/todo_update, OMIT session_id for cross-session continuity (recommended), INCLUDE session_id for session isolation. SESSION OPERATIONS: session_create, session_end, workflow_analyze require session_id. HANDOFF: session_handoff requires session_id and packages the session's working set, open todos and tasks and key decisions into a stored handoff; session_resume requires handoff_id+session_id and rehydrates the new session from it.",
 				"additionalProperties": true,
 				"properties": map[string]interface{}{
 					"todos": map[string]interface{}{
@@ -436,19 +658,39 @@ func (ms *MemoryServer) registerConsolidatedTools() {
 						"type":        "string",
 						"description": "Tool name (required for todo_update)",
 					},
+					"handoff_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Handoff to resume, as returned by session_handoff (required for session_resume)",
+					},
+					"notes": map[string]interface{}{
+						"type":        "string",
+						"description": "For session_handoff: what the next session needs to know that the memories do not say",
+					},
+					"from": map[string]interface{}{
+						"type":        "string",
+						"description": "For session_handoff: the agent or person handing off",
+					},
+					"to": map[string]interface{}{
+						"type":        "string",
+						"description": "For session_handoff: the agent or person expected to resume",
+					},
+					"by": map[string]interface{}{
+						"type":        "string",
+						"description": "For session_resume: the agent or person resuming",
+					},
 				},
 			},
 		}, []string{"operation", "options"}),
 	), mcp.ToolHandlerFunc(ms.handleMemoryTasks))
 
 	// 9. memory_system - System operations
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"memory_system",
-		"Handle system-level memory operations including health checks, status reports, and citation management. CRITICAL: 'options' parameter MUST be a JSON object (not a JSON string). REQUIRED fields: repository parameter for status operations; health checks are global by default.",
+		"Handle system-level memory operations including health checks, status reports, citation management, vector quantization reports and tenant usage reports. CRITICAL: 'options' parameter MUST be a JSON object (not a JSON string). REQUIRED fields: repository parameter for status operations; health checks are global by default.",
 		mcp.ObjectSchema("Memory system parameters", map[string]interface{}{
 			"operation": map[string]interface{}{
 				"type":        "string",
-				"enum":        []string{OperationHealth, OperationStatus, "generate_citations", "create_inline_citation", "get_documentation"},
+				"enum":        []string{OperationHealth, OperationStatus, "generate_citations", "create_inline_citation", "get_documentation", OperationGenerateDigest, OperationScheduleDigest, OperationQuantizationReport, OperationUsageReport, OperationScheduleUsageReport},
 				"description": "Type of system operation to perform",
 			},
 			"scope": map[string]interface{}{
@@ -459,7 +701,7 @@ func (ms *MemoryServer) registerConsolidatedTools() {
 			},
 			"options": map[string]interface{}{
 				"type":                 "object",
-				"description":          "Operation-specific parameters. REQUIRED fields: status requires repository; generate_citations requires query+chunk_ids+repository; create_inline_citation requires text+response_id; health checks are global by default",
+				"description":          "Operation-specific parameters. REQUIRED fields: status requires repository; generate_citations requires query+chunk_ids+repository; create_inline_citation requires text+response_id; generate_digest requires repository; schedule_digest requires repository+targets; quantization_report takes sample_size and k; usage_report takes tenant, month and format; schedule_usage_report requires targets; health checks are global by default. Schedules added with schedule_digest and schedule_usage_report are kept in memory until the server restarts; list lasting ones in the schedules file (MCP_MEMORY_DIGEST_SCHEDULES_FILE)",
 				"additionalProperties": true,
 				"properties": map[string]interface{}{
 					"repository": map[string]interface{}{
@@ -483,10 +725,137 @@ func (ms *MemoryServer) registerConsolidatedTools() {
 						"type":        "string",
 						"description": "Response ID (required for create_inline_citation)",
 					},
+					"period": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"daily", "weekly"},
+						"description": "Digest period (generate_digest, schedule_digest). Default: daily",
+					},
+					"format": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"markdown", "html", "csv", "json"},
+						"description": "Digest rendering format (generate_digest, schedule_digest; markdown or html, default markdown) or usage report format (usage_report, default json; schedule_usage_report, default csv)",
+					},
+					"summarize": map[string]interface{}{
+						"type":        "boolean",
+						"default":     false,
+						"description": "For generate_digest: ask the client's model, through MCP sampling, for a short prose summary of the digest. Clients without sampling get the digest with summary_error set",
+					},
+					"targets": map[string]interface{}{
+						"type":        "array",
+						"description": "Delivery targets (required for schedule_digest and schedule_usage_report), e.g. [{\"type\": \"slack\", \"url\": \"https://hooks.slack.com/...\"}, {\"type\": \"email\", \"to\": [\"team@example.com\"]}]",
+						"items":       map[string]interface{}{"type": "object"},
+					},
+					"sample_size": map[string]interface{}{
+						"type":        "number",
+						"default":     defaultQuantizationSamples,
+						"description": "For quantization_report: stored vectors searched for exactly and through the quantized index",
+					},
+					"k": map[string]interface{}{
+						"type":        "number",
+						"default":     defaultQuantizationK,
+						"description": "For quantization_report: neighbours compared per sampled vector",
+					},
+					"tenant": map[string]interface{}{
+						"type":        "string",
+						"description": "For usage_report and schedule_usage_report: tenant to report. Defaults to the caller's tenant; operators may leave it empty to report every tenant",
+					},
+					"month": map[string]interface{}{
+						"type":        "string",
+						"description": "For usage_report: month to report, like '2026-09'. Default: the current month",
+					},
+					"day": map[string]interface{}{
+						"type":        "number",
+						"description": "For schedule_usage_report: day of month (1-28) to deliver the previous month's report. Default: 1",
+					},
+					"hour": map[string]interface{}{
+						"type":        "number",
+						"description": "For schedule_digest and schedule_usage_report: UTC hour of day (0-23) to deliver. Default: 0",
+					},
 				},
 			},
 		}, []string{"operation", "options"}),
 	), mcp.ToolHandlerFunc(ms.handleMemorySystem))
+
+	// 10. memory_composite - Multi-step operations with rollback
+	ms.registerCompositeTool()
+
+	// 11./12. memory_trash_list and memory_restore - Trash management
+	ms.registerTrashTools()
+
+	// 13. memory_pack_context - Token-budgeted context for AI consumers
+	ms.registerPackContextTool()
+
+	// 14. system_tool_stats - Per-tool usage and latency
+	ms.registerToolStatsTool()
+
+	// 15. system_snapshot - Point-in-time snapshots and restore
+	ms.registerSnapshotTool()
+
+	// 16. system_scoring_profiles - Per-repository search ranking profiles
+	ms.registerScoringProfilesTool()
+
+	// 17. memory_timeline - Activity bucketed by day or week
+	ms.registerTimelineTool()
+
+	// 18. system_people - People behind memories and their contributions
+	ms.registerPeopleTool()
+
+	// 19. system_notification_subscriptions - Who hears about which project events
+	ms.registerNotificationSubscriptionsTool()
+
+	// 20. system_page_sync - Notion and Confluence page import
+	ms.registerPageSyncTool()
+
+	// 21. system_slack_sync - Slack channel history import
+	ms.registerSlackSyncTool()
+
+	// 22. memory_reflect - End-of-session reflection stored as a semantic memory
+	ms.registerReflectTool()
+
+	// 23. memory_coordinate - Locks, task claims and scratchpads shared by agents
+	ms.registerCoordinationTool()
+
+	// 24. continue_result - Pages of what truncated results left out
+	ms.registerContinueResultTool()
+
+	// 25. memory_graph_query - Typed traversal of the relationship graph
+	ms.registerGraphQueryTool()
+
+	// 26./27. project_manage and project_list - Project lifecycle and stats
+	ms.registerProjectTools()
+
+	// 28. memory_quality_report - Quality scores and memories to prune
+	ms.registerQualityReportTool()
+
+	// 29./30. tag_manage and tag_list - Tag registry, hierarchy and usage
+	ms.registerTagTools()
+
+	// 31./32. memory_decay_policies and memory_decay_preview - Decay and archival policies
+	ms.registerDecayPolicyTools()
+
+	// 33. memory_dedupe - Merge near-duplicate memories
+	ms.registerDedupeTool()
+
+	// system_chaos - Fault injection, only when enabled
+	ms.registerChaosTool()
+
+	// auth_* - API key administration, only when API keys are enabled
+	ms.registerAPIKeyTools()
+}
+
+// provenanceSchemaProperties describes the provenance object accepted by tools
+func provenanceSchemaProperties() map[string]interface{} {
+	field := func(description string) map[string]interface{} {
+		return map[string]interface{}{"type": "string", "description": description}
+	}
+	return map[string]interface{}{
+		"source_system":        field("Originating system, e.g. 'git', 'cli', 'import', 'jira'"),
+		"source_url":           field("URL of the original item, e.g. a commit or issue link"),
+		"commit_sha":           field("Git commit SHA the content relates to"),
+		"author":               field("Author of the original content"),
+		"capture_tool":         field("Tool that captured the content, e.g. 'post-commit-hook'"),
+		"capture_tool_version": field("Version of the capture tool"),
+	}
 }
 
 // Consolidated tool handlers
@@ -531,8 +900,10 @@ func (ms *MemoryServer) handleMemoryCreate(ctx context.Context, args map[string]
 		return ms.handleImportContext(ctx, options)
 	case "bulk_import":
 		return ms.handleBulkImport(ctx, options)
+	case OperationDefineRelationType:
+		return ms.handleDefineRelationType(ctx, options)
 	default:
-		validOps := []string{"store_chunk", "store_decision", "create_thread", "create_alias", "create_relationship", "auto_detect_relationships", "import_context", "bulk_import"}
+		validOps := []string{"store_chunk", "store_decision", "create_thread", "create_alias", "create_relationship", "auto_detect_relationships", "import_context", "bulk_import", OperationDefineRelationType}
 		return nil, fmt.Errorf("unsupported create operation '%s'. Valid operations: %s. Example: {\"operation\": \"store_chunk\", \"options\": {\"repository\": \"github.com/user/repo\", \"content\": \"Fixed authentication bug\", \"session_id\": \"session-123\"}}", operation, strings.Join(validOps, ", "))
 	}
 }
@@ -608,6 +979,12 @@ func (ms *MemoryServer) routeReadOperation(ctx context.Context, operation string
 		return ms.handleSecureListAliases(ctx, options, repository)
 	case "get_bulk_progress":
 		return ms.handleGetBulkProgress(ctx, options)
+	case OperationListRelationTypes:
+		return ms.handleListRelationTypes(ctx, repository)
+	case OperationGetChunks:
+		return ms.handleGetChunksBatch(ctx, options, repository)
+	case OperationSearchFederated:
+		return ms.handleFederatedSearch(ctx, options, repository)
 	default:
 		return ms.buildUnsupportedOperationError(operation)
 	}
@@ -615,7 +992,7 @@ func (ms *MemoryServer) routeReadOperation(ctx context.Context, operation string
 
 // buildUnsupportedOperationError builds error message for unsupported operations
 func (ms *MemoryServer) buildUnsupportedOperationError(operation string) (interface{}, error) {
-	validOps := []string{"search", "get_context", "find_similar", "get_patterns", "get_relationships", "traverse_graph", "get_threads", "search_explained", "search_multi_repo", "resolve_alias", "list_aliases", "get_bulk_progress"}
+	validOps := []string{"search", "get_context", "find_similar", "get_patterns", "get_relationships", "traverse_graph", "get_threads", "search_explained", "search_multi_repo", "resolve_alias", "list_aliases", "get_bulk_progress", OperationGetChunks, OperationListRelationTypes, OperationSearchFederated}
 	return nil, fmt.Errorf("unsupported read operation '%s'. Valid operations: %s. Example: {\"operation\": \"search\", \"options\": {\"repository\": \"github.com/user/repo\", \"query\": \"authentication issues\"}}", operation, strings.Join(validOps, ", "))
 }
 
@@ -650,6 +1027,14 @@ func (ms *MemoryServer) handleMemoryUpdate(ctx context.Context, args map[string]
 		return ms.handleBulkOperation(ctx, bulkOptions)
 	case "decay_management":
 		return ms.handleMemoryDecayManagement(ctx, options)
+	case OperationUpdateContent:
+		return ms.handleUpdateContent(ctx, options)
+	case OperationAcquireLock:
+		return ms.handleAcquireLock(ctx, options)
+	case OperationReleaseLock:
+		return ms.handleReleaseLock(ctx, options)
+	case OperationVerifySolution:
+		return ms.handleVerifySolution(ctx, options)
 	default:
 		return nil, fmt.Errorf("unsupported update operation: %s", operation)
 	}
@@ -725,8 +1110,22 @@ func (ms *MemoryServer) handleMemoryAnalyze(ctx context.Context, args map[string
 		return ms.handleCheckFreshness(ctx, options)
 	case "detect_threads":
 		return ms.handleDetectThreads(ctx, options)
+	case "quality_report":
+		return ms.handleQualityReport(ctx, options)
+	case "conflict_scan":
+		return ms.handleConflictScan(ctx, options)
+	case "stale_report":
+		return ms.handleStaleReport(ctx, options)
+	case "knowledge_gaps":
+		return ms.handleKnowledgeGaps(ctx, options)
+	case OperationStaleKnowledge:
+		return ms.handleStaleKnowledge(ctx, options)
+	case OperationVerificationCoverage:
+		return ms.handleVerificationCoverage(ctx, options)
+	case OperationHealthScore:
+		return ms.handleHealthScore(ctx, options)
 	default:
-		validOps := []string{"cross_repo_patterns", "find_similar_repositories", "cross_repo_insights", "detect_conflicts", "health_dashboard", "check_freshness", "detect_threads"}
+		validOps := []string{"cross_repo_patterns", "find_similar_repositories", "cross_repo_insights", "detect_conflicts", "health_dashboard", "check_freshness", "detect_threads", "quality_report", "conflict_scan", "stale_report", "knowledge_gaps", OperationStaleKnowledge, OperationVerificationCoverage, OperationHealthScore}
 		return nil, fmt.Errorf("unsupported analyze operation '%s'. Valid operations: %s. Example: {\"operation\": \"health_dashboard\", \"options\": {\"repository\": \"github.com/user/repo\", \"session_id\": \"session-123\"}}", operation, strings.Join(validOps, ", "))
 	}
 }
@@ -761,8 +1160,12 @@ func (ms *MemoryServer) handleMemoryIntelligence(ctx context.Context, args map[s
 		return ms.handleAutoInsights(ctx, options)
 	case "pattern_prediction":
 		return ms.handlePatternPrediction(ctx, options)
+	case OperationExtractDecisions:
+		return ms.handleExtractDecisions(ctx, options)
+	case OperationConsolidateMemories:
+		return ms.handleConsolidateMemories(ctx, options)
 	default:
-		validOps := []string{"suggest_related", "auto_insights", "pattern_prediction"}
+		validOps := []string{"suggest_related", "auto_insights", "pattern_prediction", OperationExtractDecisions, OperationConsolidateMemories}
 		return nil, fmt.Errorf("unsupported intelligence operation '%s'. Valid operations: %s. Example: {\"operation\": \"auto_insights\", \"options\": {\"repository\": \"github.com/user/repo\", \"session_id\": \"session-123\"}}", operation, strings.Join(validOps, ", "))
 	}
 }
@@ -796,6 +1199,10 @@ func (ms *MemoryServer) handleMemoryTasks(ctx context.Context, args map[string]i
 		return ms.handleWorkflowAnalyze(ctx, options)
 	case "task_completion_stats":
 		return ms.handleTaskCompletionStats(ctx, options)
+	case OperationSessionHandoff:
+		return ms.handleSessionHandoff(ctx, options)
+	case OperationSessionResume:
+		return ms.handleSessionResume(ctx, options)
 	default:
 		return nil, fmt.Errorf("unsupported tasks operation: %s", operation)
 	}
@@ -833,8 +1240,10 @@ func (ms *MemoryServer) handleMemoryTransfer(ctx context.Context, args map[strin
 		return ms.handleMemoryContinuity(ctx, options)
 	case "import_context":
 		return ms.handleImportContext(ctx, options)
+	case "export_site":
+		return ms.handleExportSite(ctx, options)
 	default:
-		validOps := []string{"export_project", "bulk_export", "continuity", "import_context"}
+		validOps := []string{"export_project", "bulk_export", "continuity", "import_context", "export_site"}
 		return nil, fmt.Errorf("unsupported transfer operation '%s'. Valid operations: %s. Example: {\"operation\": \"export_project\", \"options\": {\"repository\": \"github.com/user/repo\", \"session_id\": \"session-123\"}}", operation, strings.Join(validOps, ", "))
 	}
 }
@@ -882,6 +1291,16 @@ func (ms *MemoryServer) routeSystemOperation(ctx context.Context, operation stri
 		return ms.handleInlineCitationOperation(ctx, options, repository, hasRepo)
 	case "get_documentation":
 		return ms.handleDocumentationOperation(ctx, options)
+	case OperationGenerateDigest:
+		return ms.handleGenerateDigest(ctx, options)
+	case OperationScheduleDigest:
+		return ms.handleScheduleDigest(ctx, options)
+	case OperationQuantizationReport:
+		return ms.handleQuantizationReport(ctx, options)
+	case OperationUsageReport:
+		return ms.handleUsageReport(ctx, options)
+	case OperationScheduleUsageReport:
+		return ms.handleScheduleUsageReport(ctx, options)
 	default:
 		return ms.buildSystemOperationError(operation)
 	}
@@ -934,6 +1353,6 @@ func (ms *MemoryServer) handleDocumentationOperation(ctx context.Context, option
 
 // buildSystemOperationError builds error message for unsupported system operations
 func (ms *MemoryServer) buildSystemOperationError(operation string) (interface{}, error) {
-	validOps := []string{"health", "status", "generate_citations", "create_inline_citation", "get_documentation"}
+	validOps := []string{"health", "status", "generate_citations", "create_inline_citation", "get_documentation", OperationGenerateDigest, OperationScheduleDigest, OperationQuantizationReport, OperationUsageReport, OperationScheduleUsageReport}
 	return nil, fmt.Errorf("unsupported system operation '%s'. Valid operations: %s. Example: {\"operation\": \"health\"} or {\"operation\": \"status\", \"options\": {\"repository\": \"github.com/user/repo\"}}", operation, strings.Join(validOps, ", "))
 }
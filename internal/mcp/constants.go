@@ -18,6 +18,17 @@ const (
 	OperationHealth        = "health"
 	OperationStatus        = "status"
 
+	// Digest operation names
+	OperationGenerateDigest = "generate_digest"
+	OperationScheduleDigest = "schedule_digest"
+
+	// OperationQuantizationReport reports the storage and recall of vector quantization
+	OperationQuantizationReport = "quantization_report"
+
+	// Usage report operation names
+	OperationUsageReport         = "usage_report"
+	OperationScheduleUsageReport = "schedule_usage_report"
+
 	// Common filter values
 	FilterValueAll = "all"
 )
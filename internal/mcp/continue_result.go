@@ -0,0 +1,222 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"lerian-mcp-memory/internal/auth"
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/security"
+
+	"github.com/fredcamaral/gomcp-sdk"
+)
+
+// continueResultTool pages through what truncated tool results left out
+const continueResultTool = "continue_result"
+
+// cursorField is the field of a truncated result, or of a continue_result
+// page, holding the cursor of what follows
+const cursorField = "_cursor"
+
+const (
+	// defaultResultCacheTTL is how long cached results live without configuration
+	defaultResultCacheTTL = 15 * time.Minute
+	// maxCachedResults bounds the truncated results cached; those closest to expiring go first
+	maxCachedResults = 64
+	// continuationPageOverhead is room a continue_result page keeps for its own fields
+	continuationPageOverhead = 512
+)
+
+// errUnknownContinuation is returned for a cursor that expired or was never issued to the client
+var errUnknownContinuation = errors.New("cursor is unknown or expired; call the original tool again")
+
+// resultSection is an elided part of a truncated result: the middle items
+// of the list at path, or the middle of a text result
+type resultSection struct {
+	path  string
+	items []interface{}
+	text  string
+}
+
+// cachedResult holds the elided sections of a truncated result for the
+// client that received it
+type cachedResult struct {
+	tool     string
+	owner    string
+	sections []resultSection
+	expires  time.Time
+}
+
+// resultOwner returns who a truncated result is cached for: the API key or
+// the bearer token subject that authenticated the request, else the client.
+// Over HTTP the client is its address, which everyone behind the same proxy
+// or NAT shares, so it only stands in for requests without credentials.
+func resultOwner(ctx context.Context) string {
+	if key := auth.KeyFrom(ctx); key != nil {
+		return "api_key:" + key.ID
+	}
+	if claims := security.TokenClaimsFrom(ctx); claims != nil && claims.Subject != "" {
+		return "oauth:" + claims.Subject
+	}
+	return clientIDFrom(ctx)
+}
+
+// resultCache keeps what truncated results left out until it expires
+type resultCache struct {
+	mu      sync.Mutex
+	entries map[string]*cachedResult
+}
+
+// put caches result under id for ttl, dropping expired results and, past
+// maxCachedResults, the ones closest to expiring
+func (rc *resultCache) put(id string, result *cachedResult, now time.Time, ttl time.Duration) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.entries == nil {
+		rc.entries = make(map[string]*cachedResult)
+	}
+	for key, existing := range rc.entries {
+		if now.After(existing.expires) {
+			delete(rc.entries, key)
+		}
+	}
+	for len(rc.entries) >= maxCachedResults {
+		oldest := ""
+		for key, existing := range rc.entries {
+			if oldest == "" || existing.expires.Before(rc.entries[oldest].expires) {
+				oldest = key
+			}
+		}
+		delete(rc.entries, oldest)
+	}
+
+	result.expires = now.Add(ttl)
+	rc.entries[id] = result
+}
+
+// get returns the result cached under id if it has not expired and belongs to owner
+func (rc *resultCache) get(id, owner string, now time.Time) (*cachedResult, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	result, ok := rc.entries[id]
+	if !ok || result.owner != owner || now.After(result.expires) {
+		return nil, false
+	}
+	return result, true
+}
+
+// resultCacheTTL returns how long truncated results stay cached
+func (ms *MemoryServer) resultCacheTTL() time.Duration {
+	if ms.container == nil || ms.container.Config == nil || ms.container.Config.Server.ResultCacheTTL <= 0 {
+		return defaultResultCacheTTL
+	}
+	return time.Duration(ms.container.Config.Server.ResultCacheTTL) * time.Second
+}
+
+// resultCursor is the cursor of the cached result id from an offset into one of its sections
+func resultCursor(id string, section, offset int) string {
+	return encodeCursor(id + ":" + strconv.Itoa(section) + ":" + strconv.Itoa(offset))
+}
+
+// parseResultCursor returns the cached result ID, section and offset of a cursor
+func parseResultCursor(cursor string) (id string, section, offset int, err error) {
+	key, err := decodeCursor(cursor)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	parts := strings.Split(key, ":")
+	if len(parts) != 3 || parts[0] == "" {
+		return "", 0, 0, errInvalidCursor
+	}
+	section, sectionErr := strconv.Atoi(parts[1])
+	offset, offsetErr := strconv.Atoi(parts[2])
+	if sectionErr != nil || offsetErr != nil || section < 0 || offset < 0 {
+		return "", 0, 0, errInvalidCursor
+	}
+	return parts[0], section, offset, nil
+}
+
+// registerContinueResultTool registers continue_result
+func (ms *MemoryServer) registerContinueResultTool() {
+	ms.addTool(mcp.NewTool(
+		continueResultTool,
+		"Fetch the next page of a truncated tool result. Results larger than the server's response limit keep the start and end of their biggest lists (or text), describe the cut under 'truncated' and return a '_cursor'; pass it here, then each page's '_cursor', until a page comes without one. A list's own cursor under 'truncated.lists' starts at that list. Cursors expire after 15 minutes by default.",
+		mcp.ObjectSchema("Continuation parameters", map[string]interface{}{
+			"cursor": map[string]interface{}{
+				"type":        "string",
+				"description": "The _cursor of a truncated result or of a previous page",
+			},
+		}, []string{"cursor"}),
+	), mcp.ToolHandlerFunc(ms.handleContinueResult))
+}
+
+// handleContinueResult returns the page of elided items or text at the
+// cursor. Pages run through each elided list in turn.
+func (ms *MemoryServer) handleContinueResult(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	cursor, _ := args["cursor"].(string)
+	if cursor == "" {
+		return nil, errors.New("cursor parameter is required")
+	}
+	id, index, offset, err := parseResultCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	cached, ok := ms.results.get(id, resultOwner(ctx), time.Now())
+	if !ok {
+		return nil, errUnknownContinuation
+	}
+	if index >= len(cached.sections) {
+		return nil, errInvalidCursor
+	}
+	section := cached.sections[index]
+
+	budget := ms.responseLimit(continueResultTool)
+	if budget <= 0 {
+		budget = config.DefaultMaxResponseBytes
+	}
+	budget -= continuationPageOverhead
+
+	page := map[string]interface{}{"tool": cached.tool, "offset": offset}
+	var next, length int
+	if section.items != nil {
+		if offset > len(section.items) {
+			return nil, errInvalidCursor
+		}
+		next, length = offset, len(section.items)
+		used := 0
+		for next < length {
+			encoded, _ := json.Marshal(section.items[next])
+			if next > offset && used+len(encoded)+1 > budget {
+				break
+			}
+			used += len(encoded) + 1
+			next++
+		}
+		page["path"] = section.path
+		page["items"] = section.items[offset:next]
+	} else {
+		if offset > len(section.text) || (offset < len(section.text) && !utf8.RuneStart(section.text[offset])) {
+			return nil, errInvalidCursor
+		}
+		next, length = runeBoundary(section.text, offset+budget), len(section.text)
+		if next <= offset && offset < length {
+			next = length
+		}
+		page["text"] = section.text[offset:next]
+	}
+	page["remaining"] = length - next
+
+	switch {
+	case next < length:
+		page[cursorField] = resultCursor(id, index, next)
+	case index+1 < len(cached.sections):
+		page[cursorField] = resultCursor(id, index+1, 0)
+	}
+	return page, nil
+}
@@ -0,0 +1,134 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/auth"
+	"lerian-mcp-memory/internal/security"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resultPage is a continue_result page
+type resultPage struct {
+	Path   string        `json:"path"`
+	Items  []interface{} `json:"items"`
+	Text   string        `json:"text"`
+	Cursor string        `json:"_cursor"`
+}
+
+// continueResult follows cursor through every page of a truncated result
+func continueResult(t *testing.T, ms *MemoryServer, cursor string) []resultPage {
+	t.Helper()
+	var pages []resultPage
+	for cursor != "" {
+		require.Less(t, len(pages), 100, "continuation must end")
+		var page resultPage
+		require.NoError(t, json.Unmarshal([]byte(callToolText(t, ms, continueResultTool, map[string]interface{}{"cursor": cursor})), &page))
+		pages = append(pages, page)
+		cursor = page.Cursor
+	}
+	return pages
+}
+
+func TestContinueResult_RunsThroughEveryCutList(t *testing.T) {
+	tasks := make([]string, 300)
+	notes := make([]string, 200)
+	for i := range tasks {
+		tasks[i] = fmt.Sprintf("task-%03d %s", i, strings.Repeat("t", 60))
+	}
+	for i := range notes {
+		notes[i] = fmt.Sprintf("note-%03d %s", i, strings.Repeat("n", 60))
+	}
+	ms := newResponseLimitServer(4*1024, map[string]interface{}{"tasks": tasks, "notes": notes})
+
+	var response struct {
+		Truncated responseTruncation `json:"truncated"`
+		Cursor    string             `json:"_cursor"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(callToolText(t, ms, "list", nil)), &response))
+	require.Len(t, response.Truncated.Lists, 2)
+	assert.Equal(t, []string{"tasks", "notes"}, []string{response.Truncated.Lists[0].Path, response.Truncated.Lists[1].Path}, "the biggest list is cut first")
+
+	elided := map[string]int{}
+	var order []string
+	for _, page := range continueResult(t, ms, response.Cursor) {
+		if len(order) == 0 || order[len(order)-1] != page.Path {
+			order = append(order, page.Path)
+		}
+		elided[page.Path] += len(page.Items)
+	}
+	assert.Equal(t, []string{"tasks", "notes"}, order, "_cursor pages through each cut list in turn")
+	assert.Equal(t, response.Truncated.Lists[0].Elided, elided["tasks"])
+	assert.Equal(t, response.Truncated.Lists[1].Elided, elided["notes"])
+
+	notesOnly := continueResult(t, ms, response.Truncated.Lists[1].Cursor)
+	require.NotEmpty(t, notesOnly)
+	for _, page := range notesOnly {
+		assert.Equal(t, "notes", page.Path, "a list's cursor starts at that list")
+	}
+}
+
+func TestContinueResult_RejectsBadCursors(t *testing.T) {
+	ms := newResponseLimitServer(2*1024, nil)
+	for _, cursor := range []string{"", "%%%", encodeCursor("no-offsets"), resultCursor("unknown", 0, 0)} {
+		resp := ms.HandleRequest(context.Background(), toolCallRequest(continueResultTool, map[string]interface{}{"cursor": cursor}))
+		require.Nil(t, resp.Error)
+		assert.True(t, resp.Result.(*ToolResult).IsError, "cursor %q is rejected", cursor)
+	}
+}
+
+func TestContinueResult_OwnedByCredentialsBehindASharedAddress(t *testing.T) {
+	tasks := make([]string, 300)
+	for i := range tasks {
+		tasks[i] = fmt.Sprintf("task-%03d %s", i, strings.Repeat("t", 60))
+	}
+	ms := newResponseLimitServer(4*1024, map[string]interface{}{"tasks": tasks})
+	proxy := WithClientID(context.Background(), "10.0.0.1")
+	alice := auth.WithKey(proxy, &auth.Key{ID: "alice"})
+
+	resp := ms.HandleRequest(alice, toolCallRequest("list", nil))
+	require.Nil(t, resp.Error)
+	var response struct {
+		Cursor string `json:"_cursor"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(resp.Result.(*protocol.ToolCallResult).Content[0].Text), &response))
+	require.NotEmpty(t, response.Cursor)
+
+	continueAs := func(ctx context.Context) bool {
+		resp := ms.HandleRequest(ctx, toolCallRequest(continueResultTool, map[string]interface{}{"cursor": response.Cursor}))
+		require.Nil(t, resp.Error)
+		result, ok := resp.Result.(*protocol.ToolCallResult)
+		return ok && !result.IsError
+	}
+	assert.False(t, continueAs(proxy), "clients without credentials behind the same address cannot continue")
+	assert.False(t, continueAs(auth.WithKey(proxy, &auth.Key{ID: "bob"})), "other keys cannot continue")
+	assert.False(t, continueAs(security.WithTokenClaims(proxy, &security.TokenClaims{Subject: "alice"})), "a token is not the key")
+	assert.True(t, continueAs(auth.WithKey(WithClientID(context.Background(), "10.0.0.2"), &auth.Key{ID: "alice"})), "the key continues from any address")
+}
+
+func TestResultCache_OwnerExpiryAndEviction(t *testing.T) {
+	var cache resultCache
+	now := time.Now()
+	cache.put("a", &cachedResult{owner: "alice"}, now, time.Minute)
+	_, ok := cache.get("a", "bob", now)
+	assert.False(t, ok, "other clients cannot read a cached result")
+	_, ok = cache.get("a", "alice", now.Add(time.Minute+time.Second))
+	assert.False(t, ok, "cached results expire")
+	_, ok = cache.get("a", "alice", now)
+	assert.True(t, ok)
+
+	for i := 0; i < maxCachedResults+10; i++ {
+		cache.put(fmt.Sprintf("r%d", i), &cachedResult{owner: "alice"}, now.Add(time.Duration(i)*time.Second), time.Hour)
+	}
+	assert.Len(t, cache.entries, maxCachedResults)
+	_, ok = cache.get("r0", "alice", now)
+	assert.False(t, ok, "the results closest to expiring are evicted first")
+}
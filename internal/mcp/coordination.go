@@ -0,0 +1,286 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"lerian-mcp-memory/internal/audit"
+	"lerian-mcp-memory/internal/coordination"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/fredcamaral/gomcp-sdk"
+)
+
+// registerCoordinationTool registers memory_coordinate
+func (ms *MemoryServer) registerCoordinationTool() {
+	ms.addTool(mcp.NewTool(
+		"memory_coordinate",
+		"Coordinate several agents working on the same repository. Named locks and task claims are leases held by one owner until released or expired (default 15 minutes, at most 24 hours); scratchpads are shared notes any agent can read and write, with optional version checks. Operations: acquire_lock, release_lock, list_locks, claim_task (also assigns the task and moves it to in_progress), release_task, list_claims, read_scratchpad, write_scratchpad, list_scratchpads, delete_scratchpad.",
+		mcp.ObjectSchema("Coordination parameters", map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type": "string",
+				"enum": []string{
+					"acquire_lock", "release_lock", "list_locks",
+					"claim_task", "release_task", "list_claims",
+					"read_scratchpad", "write_scratchpad", "list_scratchpads", "delete_scratchpad",
+				},
+				"description": "Coordination operation",
+			},
+			"repository": map[string]interface{}{
+				"type":        "string",
+				"description": "Repository the agents share (required) - e.g. 'github.com/user/repo'",
+			},
+			"name": map[string]interface{}{
+				"type":        "string",
+				"description": "Lock or scratchpad name (acquire_lock, release_lock, read_scratchpad, write_scratchpad, delete_scratchpad)",
+			},
+			"task_id": map[string]interface{}{
+				"type":        "string",
+				"description": "Task to claim or release (claim_task, release_task)",
+			},
+			"owner": map[string]interface{}{
+				"type":        "string",
+				"description": "Agent taking the lock or claim, or writing the scratchpad - e.g. 'agent-frontend'",
+			},
+			"token": map[string]interface{}{
+				"type":        "string",
+				"description": "Token returned when the lock or claim was taken (release_lock, release_task)",
+			},
+			"ttl_seconds": map[string]interface{}{
+				"type":        "number",
+				"default":     900,
+				"description": "Lease length; re-acquiring with the same owner extends it (acquire_lock, claim_task)",
+			},
+			"status": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"todo", "in_progress", "completed", "blocked", "cancelled", "on_hold"},
+				"description": "Task status to set when releasing the claim (release_task)",
+			},
+			"content": map[string]interface{}{
+				"type":        "string",
+				"description": "Scratchpad content (write_scratchpad)",
+			},
+			"append": map[string]interface{}{
+				"type":        "boolean",
+				"default":     false,
+				"description": "Add content as a new line instead of replacing the scratchpad (write_scratchpad)",
+			},
+			"expected_version": map[string]interface{}{
+				"type":        "number",
+				"description": "Only write if the scratchpad is still at this version; 0 only creates it (write_scratchpad)",
+			},
+		}, []string{"operation", "repository"}),
+	), mcp.ToolHandlerFunc(ms.handleCoordinate))
+}
+
+// handleCoordinate manages locks, task claims and scratchpads shared by agents
+func (ms *MemoryServer) handleCoordinate(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: memory_coordinate called", "args", args)
+
+	store := ms.container.GetCoordination()
+	if store == nil {
+		return nil, errors.New("coordination is not available")
+	}
+
+	operation, _ := args["operation"].(string)
+	repository, _ := args["repository"].(string)
+	if repository == "" || repository == GlobalRepository {
+		return nil, errors.New("repository is required. Example: {\"operation\": \"acquire_lock\", \"repository\": \"github.com/user/repo\", \"name\": \"db-migrations\", \"owner\": \"agent-backend\"}")
+	}
+	// Locks, claims and scratchpads are kept per repository, outside the
+	// vector store, so the tenant is checked here
+	if err := checkProjectTenant(ctx, repository); err != nil {
+		return nil, err
+	}
+	name, _ := args["name"].(string)
+	owner, _ := args["owner"].(string)
+	token, _ := args["token"].(string)
+	var ttl time.Duration
+	if seconds, ok := args["ttl_seconds"].(float64); ok {
+		ttl = time.Duration(seconds) * time.Second
+	}
+
+	result := map[string]interface{}{
+		"status":     "success",
+		"operation":  operation,
+		"repository": repository,
+	}
+
+	switch operation {
+	case "acquire_lock":
+		lock, err := store.AcquireLock(repository, name, owner, ttl)
+		if errors.Is(err, coordination.ErrHeld) {
+			return heldResult(result, err), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		result["lock"] = lock
+
+	case "release_lock":
+		if err := store.ReleaseLock(repository, name, token); err != nil {
+			return nil, fmt.Errorf("failed to release lock %s: %w", name, err)
+		}
+		result["released"] = true
+
+	case "list_locks":
+		locks := store.Locks(repository)
+		result["locks"] = locks
+		result["count"] = len(locks)
+
+	case "claim_task":
+		return ms.claimTask(ctx, store, result, args, ttl)
+
+	case "release_task":
+		return ms.releaseTask(ctx, store, result, args)
+
+	case "list_claims":
+		claims := store.Claims(repository)
+		result["claims"] = claims
+		result["count"] = len(claims)
+
+	case "read_scratchpad":
+		pad, ok := store.Scratchpad(repository, name)
+		if !ok {
+			return nil, fmt.Errorf("scratchpad %q not found for %s", name, repository)
+		}
+		result["scratchpad"] = pad
+
+	case "write_scratchpad":
+		content, _ := args["content"].(string)
+		appendLine, _ := args["append"].(bool)
+		expectedVersion := -1
+		if version, ok := args["expected_version"].(float64); ok {
+			expectedVersion = int(version)
+		}
+		pad, err := store.WriteScratchpad(repository, name, content, owner, appendLine, expectedVersion)
+		if errors.Is(err, coordination.ErrVersionConflict) {
+			current, _ := store.Scratchpad(repository, name)
+			result["status"] = "conflict"
+			result["error"] = err.Error()
+			result["scratchpad"] = current
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		result["scratchpad"] = pad
+
+	case "list_scratchpads":
+		pads := store.Scratchpads(repository)
+		result["scratchpads"] = pads
+		result["count"] = len(pads)
+
+	case "delete_scratchpad":
+		if err := store.DeleteScratchpad(repository, name); err != nil {
+			return nil, err
+		}
+		result["deleted"] = true
+
+	default:
+		return nil, fmt.Errorf("unknown coordination operation %q", operation)
+	}
+
+	return result, nil
+}
+
+// claimTask claims a task for an agent and records the agent as its assignee
+func (ms *MemoryServer) claimTask(ctx context.Context, store *coordination.Store, result, args map[string]interface{}, ttl time.Duration) (interface{}, error) {
+	repository, _ := args["repository"].(string)
+	taskID, _ := args["task_id"].(string)
+	owner, _ := args["owner"].(string)
+	if taskID == "" || owner == "" {
+		return nil, errors.New("claim_task requires task_id and owner. Example: {\"operation\": \"claim_task\", \"repository\": \"github.com/user/repo\", \"task_id\": \"uuid\", \"owner\": \"agent-backend\"}")
+	}
+
+	task, err := ms.validateTaskChunk(ctx, taskID)
+	if err != nil || task.Metadata.Repository != repository {
+		return nil, fmt.Errorf("task %s not found in repository %s", taskID, repository)
+	}
+	switch currentTaskStatus(task) {
+	case types.TaskStatusCompleted, types.TaskStatusCancelled:
+		return nil, fmt.Errorf("task %s is %s and cannot be claimed", taskID, currentTaskStatus(task))
+	}
+
+	claim, err := store.ClaimTask(repository, taskID, owner, ttl)
+	if errors.Is(err, coordination.ErrHeld) {
+		return heldResult(result, err), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{"assignee": owner}
+	if status := currentTaskStatus(task); status == "" || status == types.TaskStatusTodo {
+		updates["status"] = string(types.TaskStatusInProgress)
+	}
+	if err := ms.updateClaimedTask(ctx, task, updates); err != nil {
+		_ = store.ReleaseTask(repository, taskID, claim.Token)
+		return nil, err
+	}
+
+	result["claim"] = claim
+	result["task_id"] = taskID
+	return result, nil
+}
+
+// releaseTask drops an agent's claim on a task, optionally setting its status
+func (ms *MemoryServer) releaseTask(ctx context.Context, store *coordination.Store, result, args map[string]interface{}) (interface{}, error) {
+	repository, _ := args["repository"].(string)
+	taskID, _ := args["task_id"].(string)
+	token, _ := args["token"].(string)
+	status, _ := args["status"].(string)
+	if taskID == "" || token == "" {
+		return nil, errors.New("release_task requires task_id and token. Example: {\"operation\": \"release_task\", \"repository\": \"github.com/user/repo\", \"task_id\": \"uuid\", \"token\": \"token\", \"status\": \"completed\"}")
+	}
+
+	if err := store.ReleaseTask(repository, taskID, token); err != nil {
+		return nil, fmt.Errorf("failed to release task %s: %w", taskID, err)
+	}
+	if status != "" {
+		task, err := ms.validateTaskChunk(ctx, taskID)
+		if err != nil {
+			return nil, err
+		}
+		if err := ms.updateClaimedTask(ctx, task, map[string]interface{}{"status": status}); err != nil {
+			return nil, err
+		}
+		result["task_status"] = status
+	}
+
+	result["task_id"] = taskID
+	result["released"] = true
+	return result, nil
+}
+
+// updateClaimedTask applies task field updates made by claiming or releasing a task
+func (ms *MemoryServer) updateClaimedTask(ctx context.Context, task *types.ConversationChunk, params map[string]interface{}) error {
+	previousStatus := currentTaskStatus(task)
+	var updates map[string]interface{}
+	updated, err := ms.updateChunk(ctx, task.ID, "", func(chunk *types.ConversationChunk) error {
+		updates = ms.applyTaskUpdates(chunk, params)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update task: %w", err)
+	}
+	ms.notifyTaskTransition(updated, previousStatus)
+
+	if auditLogger := ms.container.GetAuditLogger(); auditLogger != nil {
+		auditLogger.LogEvent(ctx, audit.EventTypeMemoryUpdate, "update_task", "task", task.ID, map[string]interface{}{
+			"task_id": task.ID,
+			"updates": updates,
+		})
+	}
+	return nil
+}
+
+// heldResult reports a lock or claim held by another agent without failing the call
+func heldResult(result map[string]interface{}, err error) map[string]interface{} {
+	result["status"] = "held"
+	result["error"] = err.Error()
+	return result
+}
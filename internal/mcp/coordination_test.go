@@ -0,0 +1,127 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/coordination"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/tenancy"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoordinate_ClaimTask(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	ms := newCompositeTestServer(t, store)
+	ms.container.Coordination = coordination.NewStore("")
+	repo := "github.com/acme/api"
+
+	task := newTaskChunk(t, repo, types.TaskStatusTodo)
+	require.NoError(t, store.Store(ctx, task))
+	done := newTaskChunk(t, repo, types.TaskStatusCompleted)
+	require.NoError(t, store.Store(ctx, done))
+
+	result, err := ms.handleCoordinate(ctx, map[string]interface{}{
+		"operation": "claim_task", "repository": repo, "task_id": task.ID, "owner": "agent-a",
+	})
+	require.NoError(t, err)
+	claim := result.(map[string]interface{})["claim"].(*coordination.Lease)
+
+	claimed, err := store.GetByID(ctx, task.ID)
+	require.NoError(t, err)
+	require.NotNil(t, claimed.Metadata.TaskAssignee)
+	assert.Equal(t, "agent-a", *claimed.Metadata.TaskAssignee)
+	assert.Equal(t, types.TaskStatusInProgress, *claimed.Metadata.TaskStatus)
+
+	result, err = ms.handleCoordinate(ctx, map[string]interface{}{
+		"operation": "claim_task", "repository": repo, "task_id": task.ID, "owner": "agent-b",
+	})
+	require.NoError(t, err, "a held task is reported, not an error")
+	assert.Equal(t, "held", result.(map[string]interface{})["status"])
+
+	_, err = ms.handleCoordinate(ctx, map[string]interface{}{
+		"operation": "claim_task", "repository": repo, "task_id": done.ID, "owner": "agent-b",
+	})
+	assert.Error(t, err, "finished tasks cannot be claimed")
+	_, err = ms.handleCoordinate(ctx, map[string]interface{}{
+		"operation": "claim_task", "repository": "github.com/acme/web", "task_id": task.ID, "owner": "agent-b",
+	})
+	assert.Error(t, err, "tasks are claimed in their own repository")
+
+	_, err = ms.handleCoordinate(ctx, map[string]interface{}{
+		"operation": "release_task", "repository": repo, "task_id": task.ID, "token": claim.Token, "status": "completed",
+	})
+	require.NoError(t, err)
+	released, err := store.GetByID(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.TaskStatusCompleted, *released.Metadata.TaskStatus)
+	assert.Empty(t, ms.container.Coordination.Claims(repo))
+}
+
+func TestCoordinate_LocksAndScratchpads(t *testing.T) {
+	ctx := context.Background()
+	ms := newCompositeTestServer(t, storage.NewSimpleMockVectorStore())
+	ms.container.Coordination = coordination.NewStore("")
+	repo := "github.com/acme/api"
+
+	result, err := ms.handleCoordinate(ctx, map[string]interface{}{
+		"operation": "acquire_lock", "repository": repo, "name": "db-migrations", "owner": "agent-a", "ttl_seconds": 60.0,
+	})
+	require.NoError(t, err)
+	lock := result.(map[string]interface{})["lock"].(*coordination.Lease)
+
+	result, err = ms.handleCoordinate(ctx, map[string]interface{}{
+		"operation": "acquire_lock", "repository": repo, "name": "db-migrations", "owner": "agent-b",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "held", result.(map[string]interface{})["status"])
+
+	_, err = ms.handleCoordinate(ctx, map[string]interface{}{
+		"operation": "release_lock", "repository": repo, "name": "db-migrations", "token": lock.Token,
+	})
+	require.NoError(t, err)
+
+	_, err = ms.handleCoordinate(ctx, map[string]interface{}{
+		"operation": "write_scratchpad", "repository": repo, "name": "plan", "content": "agent-a: auth", "owner": "agent-a",
+	})
+	require.NoError(t, err)
+	result, err = ms.handleCoordinate(ctx, map[string]interface{}{
+		"operation": "write_scratchpad", "repository": repo, "name": "plan", "content": "agent-b: billing", "owner": "agent-b", "expected_version": 0.0,
+	})
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	assert.Equal(t, "conflict", response["status"])
+	assert.Equal(t, "agent-a: auth", response["scratchpad"].(coordination.Scratchpad).Content, "a conflict returns the current scratchpad")
+
+	_, err = ms.handleCoordinate(ctx, map[string]interface{}{
+		"operation": "write_scratchpad", "repository": repo, "name": "plan", "content": "agent-b: billing", "owner": "agent-b", "append": true, "expected_version": 1.0,
+	})
+	require.NoError(t, err)
+	result, err = ms.handleCoordinate(ctx, map[string]interface{}{"operation": "read_scratchpad", "repository": repo, "name": "plan"})
+	require.NoError(t, err)
+	assert.Equal(t, "agent-a: auth\nagent-b: billing", result.(map[string]interface{})["scratchpad"].(coordination.Scratchpad).Content)
+
+	_, err = ms.handleCoordinate(ctx, map[string]interface{}{"operation": "list_locks"})
+	assert.Error(t, err, "repository is required")
+}
+
+func TestCoordinate_HeldToTheTenant(t *testing.T) {
+	ms := newCompositeTestServer(t, storage.NewSimpleMockVectorStore())
+	ms.container.Coordination = coordination.NewStore("")
+	rival := tenancy.WithTenant(context.Background(), &tenancy.Tenant{ID: "api_key:rival", Projects: []string{"github.com/rival/app"}})
+
+	for _, operation := range []string{"acquire_lock", "list_locks", "list_claims", "read_scratchpad", "write_scratchpad"} {
+		_, err := ms.handleCoordinate(rival, map[string]interface{}{
+			"operation": operation, "repository": "github.com/acme/api", "name": "notes", "owner": "agent-a",
+		})
+		assert.ErrorIs(t, err, tenancy.ErrCrossTenant, operation)
+	}
+	_, err := ms.handleCoordinate(rival, map[string]interface{}{
+		"operation": "acquire_lock", "repository": "github.com/rival/app", "name": "deploy", "owner": "agent-a",
+	})
+	assert.NoError(t, err)
+}
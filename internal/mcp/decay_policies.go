@@ -0,0 +1,378 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"lerian-mcp-memory/internal/audit"
+	"lerian-mcp-memory/internal/decay"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/tenancy"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/fredcamaral/gomcp-sdk"
+)
+
+// decayPolicyArchiver marks memories archived by a decay policy
+const decayPolicyArchiver = "decay_policy"
+
+// decayReport is what a decay policy did, or would do, to one repository
+type decayReport struct {
+	Repository string       `json:"repository"`
+	Policy     decay.Policy `json:"policy"`
+	// DefaultPolicy marks a repository decayed by the default policy
+	DefaultPolicy bool              `json:"default_policy,omitempty"`
+	Evaluated     int               `json:"evaluated"`
+	Candidates    []decay.Candidate `json:"candidates"`
+	Archived      int               `json:"archived"`
+	Trashed       int               `json:"trashed"`
+	Failed        int               `json:"failed,omitempty"`
+	DryRun        bool              `json:"dry_run"`
+}
+
+// registerDecayPolicyTools registers memory_decay_policies and memory_decay_preview
+func (ms *MemoryServer) registerDecayPolicyTools() {
+	ms.addTool(mcp.NewTool(
+		"memory_decay_policies",
+		"Manage per-repository decay policies, run daily with the automatic cleanup. A memory's relevance halves every half_life_days since it was stored or last accessed; below threshold it is archived (kept and restorable with memory_restore, but left out of searches unless include_archived is set) or deleted (moved to the trash), unless it was accessed min_access_count times or its type is protected. A policy for repository '*' applies to repositories without their own, and only callers owning every project may set it; other tenants manage and run the policies of their own projects. Operations: list, get, set (create or change; unset fields keep their current or default value), delete, run (apply now; dry_run only reports).",
+		mcp.ObjectSchema("Decay policy parameters", map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"list", "get", "set", "delete", "run"},
+				"description": "Decay policy operation",
+			},
+			"repository": map[string]interface{}{
+				"type":        "string",
+				"description": "Repository the policy belongs to, or '*' for the default policy (get, set, delete). For run, the repository to decay; every repository with a policy by default",
+			},
+			"policy": map[string]interface{}{
+				"type":        "object",
+				"description": "Policy settings (set). Example: {\"half_life_days\": 60, \"threshold\": 0.25, \"min_access_count\": 3, \"action\": \"archive\", \"protected_types\": [\"architecture_decision\"]}",
+			},
+			"dry_run": map[string]interface{}{
+				"type":        "boolean",
+				"default":     false,
+				"description": "Report what the run would archive or delete without changing anything (run)",
+			},
+		}, []string{"operation"}),
+	), mcp.ToolHandlerFunc(ms.handleDecayPolicies))
+
+	ms.addTool(mcp.NewTool(
+		"memory_decay_preview",
+		"Show what the next decay run would archive or delete in a repository under its decay policy, least relevant first, with each memory's relevance, idle days and access count. Without a policy it shows what the default policy would do. Nothing is changed.",
+		mcp.ObjectSchema("Decay preview parameters", map[string]interface{}{
+			"repository": map[string]interface{}{
+				"type":        "string",
+				"description": "Repository URL (required) - e.g. 'github.com/user/repo'",
+			},
+			"limit": map[string]interface{}{
+				"type":        "number",
+				"default":     defaultReportItems,
+				"description": "Memories to list, least relevant first",
+			},
+		}, []string{"repository"}),
+	), mcp.ToolHandlerFunc(ms.handleDecayPreviewTool))
+}
+
+// handleDecayPolicies manages and runs decay policies
+func (ms *MemoryServer) handleDecayPolicies(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: memory_decay_policies called", "args", args)
+
+	policies := ms.container.GetDecayPolicies()
+	if policies == nil {
+		return nil, errors.New("decay policies are not available")
+	}
+
+	operation, _ := args["operation"].(string)
+	repository, _ := args["repository"].(string)
+	if repository == "" && operation != "list" && operation != "run" {
+		return nil, NewToolError(ToolErrorMissingParameter, "repository parameter is required").
+			WithParameter("repository").
+			WithExample(`{"operation": "set", "repository": "github.com/acme/api", "policy": {"half_life_days": 60, "action": "archive"}}`)
+	}
+	// Only operators own the default policy's repository, '*'
+	if repository != "" {
+		if err := checkProjectTenant(ctx, repository); err != nil {
+			return nil, err
+		}
+	}
+
+	switch operation {
+	case "list":
+		tenant := tenancy.FromContext(ctx)
+		list := make([]decay.Policy, 0)
+		for _, policy := range policies.List() {
+			if tenant == nil || tenant.Allows(policy.Repository) {
+				list = append(list, policy)
+			}
+		}
+		return map[string]interface{}{
+			"status":    "success",
+			"operation": operation,
+			"policies":  list,
+			"count":     len(list),
+		}, nil
+
+	case "get":
+		policy, ok := policies.For(repository)
+		if !ok {
+			return nil, NewToolError(ToolErrorNotFound, fmt.Sprintf("no decay policy applies to %s", repository)).
+				WithParameter("repository").
+				WithSuggestion("Set one with operation set, or set a default policy for repository '*'")
+		}
+		_, own := policies.Get(repository)
+		return map[string]interface{}{
+			"status":         "success",
+			"operation":      operation,
+			"policy":         policy,
+			"default_policy": !own,
+		}, nil
+
+	case "set":
+		options, _ := args["policy"].(map[string]interface{})
+		policy, ok := policies.Get(repository)
+		if !ok {
+			policy = decay.DefaultPolicy(repository)
+		}
+		// Round-trip the options through JSON onto the current policy, so
+		// fields left out keep their value
+		raw, err := json.Marshal(options)
+		if err != nil {
+			return nil, fmt.Errorf("invalid decay policy: %w", err)
+		}
+		if err := json.Unmarshal(raw, &policy); err != nil {
+			return nil, fmt.Errorf("invalid decay policy: %w", err)
+		}
+		policy.Repository = repository
+		policy.Tenant = tenancy.FromContext(ctx)
+		saved, err := policies.Set(policy)
+		if err != nil {
+			return nil, NewToolError(ToolErrorInvalidParameter, err.Error()).WithParameter("policy")
+		}
+		ms.logDecayChange(ctx, "set_decay_policy", repository, map[string]interface{}{"policy": saved})
+		return map[string]interface{}{
+			"status":    "success",
+			"operation": operation,
+			"policy":    saved,
+		}, nil
+
+	case "delete":
+		if err := policies.Delete(repository); err != nil {
+			return nil, NewToolError(ToolErrorNotFound, err.Error()).WithParameter("repository")
+		}
+		ms.logDecayChange(ctx, "delete_decay_policy", repository, nil)
+		return map[string]interface{}{
+			"status":     "success",
+			"operation":  operation,
+			"repository": repository,
+		}, nil
+
+	case "run":
+		dryRun, _ := args["dry_run"].(bool)
+		reports, err := ms.runDecayPolicies(ctx, repository, time.Now(), dryRun)
+		if err != nil {
+			return nil, err
+		}
+		archived, trashed := 0, 0
+		for i := range reports {
+			archived += reports[i].Archived
+			trashed += reports[i].Trashed
+		}
+		return map[string]interface{}{
+			"status":       "success",
+			"operation":    operation,
+			"dry_run":      dryRun,
+			"repositories": reports,
+			"archived":     archived,
+			"trashed":      trashed,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown operation %q: use list, get, set, delete or run", operation)
+	}
+}
+
+// handleDecayPreviewTool shows what the next decay run would do to a repository
+func (ms *MemoryServer) handleDecayPreviewTool(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: memory_decay_preview called", "args", args)
+
+	repository, _ := args["repository"].(string)
+	if repository == "" {
+		return nil, errors.New("repository parameter is required. Example: {\"repository\": \"github.com/user/repo\"}")
+	}
+	if err := checkProjectTenant(ctx, repository); err != nil {
+		return nil, err
+	}
+	limit := defaultReportItems
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+
+	policy, ok := decay.Policy{}, false
+	if policies := ms.container.GetDecayPolicies(); policies != nil {
+		policy, ok = policies.For(repository)
+	}
+	if !ok {
+		policy = decay.DefaultPolicy(repository)
+	}
+	chunks, err := ms.projectChunks(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	report := evaluateDecay(&policy, chunks, time.Now())
+	report.DryRun = true
+
+	total := len(report.Candidates)
+	if len(report.Candidates) > limit {
+		report.Candidates = report.Candidates[:limit]
+	}
+	response := map[string]interface{}{
+		"status":       "success",
+		"repository":   repository,
+		"policy":       policy,
+		"evaluated":    report.Evaluated,
+		"candidates":   report.Candidates,
+		"total":        total,
+		"generated_at": time.Now().Format(time.RFC3339),
+	}
+	switch {
+	case !ok:
+		response["policy_set"] = false
+		response["note"] = "No decay policy applies to this repository, so the daily run leaves it alone. Shown is what the default policy would do; set one with memory_decay_policies"
+	case policy.Action == decay.ActionArchive:
+		response["would_archive"] = total
+	default:
+		response["would_delete"] = total
+	}
+	return response, nil
+}
+
+// runDecayPolicies applies decay policies at time now to one repository, or
+// to every repository a policy applies to when repository is empty. A caller
+// held to a tenant only decays the tenant's repositories, and a policy set by
+// a tenant is applied on its behalf, so the scheduled run, which is held to
+// none, cannot reach beyond the tenant's projects. A dry run only reports
+// what would be archived or deleted.
+func (ms *MemoryServer) runDecayPolicies(ctx context.Context, repository string, now time.Time, dryRun bool) ([]decayReport, error) {
+	policies := ms.container.GetDecayPolicies()
+	if policies == nil {
+		return nil, nil
+	}
+
+	var (
+		chunks []types.ConversationChunk
+		err    error
+	)
+	if repository != "" {
+		chunks, err = ms.projectChunks(ctx, repository)
+	} else {
+		chunks, err = ms.container.GetVectorStore().GetAllChunks(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan memories for decay: %w", err)
+	}
+
+	byRepository := make(map[string][]types.ConversationChunk)
+	for i := range chunks {
+		byRepository[chunks[i].Metadata.Repository] = append(byRepository[chunks[i].Metadata.Repository], chunks[i])
+	}
+	repositories := make([]string, 0, len(byRepository))
+	for repo := range byRepository {
+		repositories = append(repositories, repo)
+	}
+	sort.Strings(repositories)
+
+	caller := tenancy.FromContext(ctx)
+	reports := make([]decayReport, 0)
+	for _, repo := range repositories {
+		policy, ok := policies.For(repo)
+		if !ok || (caller != nil && !caller.Allows(repo)) {
+			continue
+		}
+		decayCtx := ctx
+		if policy.Tenant != nil {
+			if !policy.Tenant.Allows(repo) {
+				continue
+			}
+			decayCtx = tenancy.WithTenant(ctx, policy.Tenant)
+		}
+		report := evaluateDecay(&policy, byRepository[repo], now)
+		_, own := policies.Get(repo)
+		report.DefaultPolicy = !own
+		report.DryRun = dryRun
+		if !dryRun {
+			ms.applyDecay(decayCtx, &report, byRepository[repo], now)
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// evaluateDecay reports the memories a policy would archive or delete
+func evaluateDecay(policy *decay.Policy, chunks []types.ConversationChunk, now time.Time) decayReport {
+	evaluated := 0
+	for i := range chunks {
+		if !chunks[i].IsDeleted() && !chunks[i].IsArchived() {
+			evaluated++
+		}
+	}
+	return decayReport{
+		Repository: policy.Repository,
+		Policy:     *policy,
+		Evaluated:  evaluated,
+		Candidates: policy.Evaluate(chunks, now),
+	}
+}
+
+// applyDecay archives or trashes the candidates of a report
+func (ms *MemoryServer) applyDecay(ctx context.Context, report *decayReport, chunks []types.ConversationChunk, now time.Time) {
+	byID := make(map[string]*types.ConversationChunk, len(chunks))
+	for i := range chunks {
+		byID[chunks[i].ID] = &chunks[i]
+	}
+
+	for _, candidate := range report.Candidates {
+		chunk := byID[candidate.ChunkID]
+		var err error
+		switch candidate.Action {
+		case decay.ActionDelete:
+			if err = ms.trashChunk(ctx, chunk, now); err == nil {
+				report.Trashed++
+			}
+		default:
+			if _, err = ms.updateChunk(ctx, chunk.ID, "", func(current *types.ConversationChunk) error {
+				if current.Metadata.ExtendedMetadata == nil {
+					current.Metadata.ExtendedMetadata = make(map[string]interface{})
+				}
+				current.Metadata.ExtendedMetadata[types.EMKeyArchivedAt] = now.UTC().Format(time.RFC3339)
+				current.Metadata.ExtendedMetadata[types.EMKeyArchivedBy] = decayPolicyArchiver
+				return nil
+			}); err == nil {
+				report.Archived++
+			}
+		}
+		if err != nil {
+			logging.Warn("Failed to decay memory", "chunk_id", chunk.ID, "action", candidate.Action, "error", err)
+			report.Failed++
+		}
+	}
+
+	if report.Archived+report.Trashed > 0 {
+		ms.logDecayChange(ctx, "run_decay_policy", report.Repository, map[string]interface{}{
+			"archived": report.Archived,
+			"trashed":  report.Trashed,
+			"failed":   report.Failed,
+		})
+	}
+}
+
+// logDecayChange records a decay policy change or run in the audit log
+func (ms *MemoryServer) logDecayChange(ctx context.Context, action, repository string, details map[string]interface{}) {
+	if auditLogger := ms.container.GetAuditLogger(); auditLogger != nil {
+		auditLogger.LogEvent(ctx, audit.EventTypeMemoryUpdate, action, "repository", repository, details)
+	}
+}
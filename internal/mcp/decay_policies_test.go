@@ -0,0 +1,170 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/decay"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/tenancy"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecayPoliciesPreviewRunAndRestore(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewArchiveTierVectorStore(storage.NewLocalVectorStore(""))
+	ms := newCompositeTestServer(t, store)
+	ms.container.DecayPolicies = decay.NewPolicyStore("")
+
+	stale := newReportChunk(t, "s1", "Old deploy notes", types.ChunkTypeDiscussion, types.ChunkMetadata{})
+	stale.Timestamp = time.Now().AddDate(0, 0, -200)
+	fresh := newReportChunk(t, "s1", "New deploy notes", types.ChunkTypeDiscussion, types.ChunkMetadata{})
+	decision := newReportChunk(t, "s1", "Deploy with blue-green", types.ChunkTypeArchitectureDecision, types.ChunkMetadata{})
+	decision.Timestamp = time.Now().AddDate(0, 0, -400)
+	for _, chunk := range []*types.ConversationChunk{stale, fresh, decision} {
+		require.NoError(t, store.Store(ctx, chunk))
+	}
+
+	result, err := ms.handleDecayPreviewTool(ctx, map[string]interface{}{"repository": "github.com/acme/api"})
+	require.NoError(t, err)
+	preview := result.(map[string]interface{})
+	assert.Equal(t, false, preview["policy_set"])
+	assert.Equal(t, 1, preview["total"])
+
+	reports, err := ms.runDecayPolicies(ctx, "", time.Now(), false)
+	require.NoError(t, err)
+	assert.Empty(t, reports, "repositories without a policy are left alone")
+
+	result, err = ms.handleDecayPolicies(ctx, map[string]interface{}{
+		"operation":  "set",
+		"repository": "github.com/acme/api",
+		"policy":     map[string]interface{}{"half_life_days": float64(60)},
+	})
+	require.NoError(t, err)
+	policy := result.(map[string]interface{})["policy"].(*decay.Policy)
+	assert.InDelta(t, 60, policy.HalfLifeDays, 0)
+	assert.Equal(t, decay.ActionArchive, policy.Action, "unset fields keep their default")
+
+	result, err = ms.handleDecayPolicies(ctx, map[string]interface{}{"operation": "run", "dry_run": true})
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.(map[string]interface{})["archived"])
+	unchanged, err := store.GetByID(ctx, stale.ID)
+	require.NoError(t, err)
+	assert.False(t, unchanged.IsArchived(), "a dry run changes nothing")
+
+	result, err = ms.handleDecayPolicies(ctx, map[string]interface{}{"operation": "run", "repository": "github.com/acme/api"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.(map[string]interface{})["archived"])
+	archived, err := store.GetByID(ctx, stale.ID)
+	require.NoError(t, err)
+	assert.True(t, archived.IsArchived())
+	assert.Equal(t, decayPolicyArchiver, archived.Metadata.ExtendedMetadata[types.EMKeyArchivedBy])
+
+	query := types.NewMemoryQuery("deploy notes")
+	query.MinRelevanceScore = 0
+	query.Recency = types.RecencyAllTime
+	results, err := store.Search(ctx, query, []float64{0.1, 0.2, 0.3})
+	require.NoError(t, err)
+	found := make([]string, 0, len(results.Results))
+	for i := range results.Results {
+		found = append(found, results.Results[i].Chunk.ID)
+	}
+	assert.Contains(t, found, fresh.ID)
+	assert.NotContains(t, found, stale.ID, "archived memories are left out of searches")
+	query.IncludeArchived = true
+	results, err = store.Search(ctx, query, []float64{0.1, 0.2, 0.3})
+	require.NoError(t, err)
+	assert.Len(t, results.Results, 3)
+
+	_, err = ms.handleRestore(ctx, map[string]interface{}{"repository": "github.com/acme/api", "ids": []interface{}{stale.ID}})
+	require.NoError(t, err)
+	restored, err := store.GetByID(ctx, stale.ID)
+	require.NoError(t, err)
+	assert.False(t, restored.IsArchived())
+}
+
+func TestDecayPoliciesDeleteActionAndErrors(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewLocalVectorStore("")
+	ms := newCompositeTestServer(t, store)
+	ms.container.DecayPolicies = decay.NewPolicyStore("")
+
+	stale := newReportChunk(t, "s1", "Old scratch notes", types.ChunkTypeDiscussion, types.ChunkMetadata{})
+	stale.Timestamp = time.Now().AddDate(0, 0, -400)
+	require.NoError(t, store.Store(ctx, stale))
+
+	_, err := ms.handleDecayPolicies(ctx, map[string]interface{}{
+		"operation":  "set",
+		"repository": decay.DefaultPolicyRepository,
+		"policy":     map[string]interface{}{"action": decay.ActionDelete},
+	})
+	require.NoError(t, err)
+
+	result, err := ms.handleDecayPolicies(ctx, map[string]interface{}{"operation": "get", "repository": "github.com/acme/api"})
+	require.NoError(t, err)
+	assert.Equal(t, true, result.(map[string]interface{})["default_policy"])
+
+	result, err = ms.handleDecayPolicies(ctx, map[string]interface{}{"operation": "run"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.(map[string]interface{})["trashed"])
+	trashed, err := store.GetByID(ctx, stale.ID)
+	require.NoError(t, err)
+	assert.True(t, trashed.IsDeleted())
+
+	_, err = ms.handleDecayPolicies(ctx, map[string]interface{}{
+		"operation":  "set",
+		"repository": "github.com/acme/api",
+		"policy":     map[string]interface{}{"threshold": float64(2)},
+	})
+	assert.Equal(t, ToolErrorInvalidParameter, asToolError(err).Code)
+	_, err = ms.handleDecayPolicies(ctx, map[string]interface{}{"operation": "delete", "repository": "github.com/acme/api"})
+	assert.Equal(t, ToolErrorNotFound, asToolError(err).Code)
+	_, err = ms.handleDecayPolicies(ctx, map[string]interface{}{"operation": "get"})
+	assert.Equal(t, ToolErrorMissingParameter, asToolError(err).Code)
+}
+
+func TestDecayPoliciesHeldToTheTenant(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewLocalVectorStore("")
+	ms := newCompositeTestServer(t, store)
+	ms.container.DecayPolicies = decay.NewPolicyStore("")
+	acme := tenancy.WithTenant(ctx, &tenancy.Tenant{ID: "api_key:acme", Projects: []string{"github.com/acme/api"}})
+	rival := tenancy.WithTenant(ctx, &tenancy.Tenant{ID: "api_key:rival", Projects: []string{"github.com/rival/app"}})
+
+	ours := newReportChunk(t, "s1", "Old acme notes", types.ChunkTypeDiscussion, types.ChunkMetadata{})
+	ours.Timestamp = time.Now().AddDate(0, 0, -400)
+	theirs := newReportChunk(t, "s2", "Old rival notes", types.ChunkTypeDiscussion, types.ChunkMetadata{Repository: "github.com/rival/app"})
+	theirs.Timestamp = time.Now().AddDate(0, 0, -400)
+	for _, chunk := range []*types.ConversationChunk{ours, theirs} {
+		require.NoError(t, store.Store(ctx, chunk))
+	}
+
+	deletion := map[string]interface{}{"action": decay.ActionDelete}
+	for _, repository := range []string{decay.DefaultPolicyRepository, "github.com/acme/api"} {
+		_, err := ms.handleDecayPolicies(rival, map[string]interface{}{"operation": "set", "repository": repository, "policy": deletion})
+		assert.ErrorIs(t, err, tenancy.ErrCrossTenant, repository)
+	}
+	_, err := ms.handleDecayPolicies(rival, map[string]interface{}{"operation": "run", "repository": "github.com/acme/api"})
+	assert.ErrorIs(t, err, tenancy.ErrCrossTenant)
+
+	result, err := ms.handleDecayPolicies(rival, map[string]interface{}{"operation": "set", "repository": "github.com/rival/app", "policy": deletion})
+	require.NoError(t, err)
+	assert.Equal(t, "api_key:rival", result.(map[string]interface{})["policy"].(*decay.Policy).Tenant.ID)
+	result, err = ms.handleDecayPolicies(acme, map[string]interface{}{"operation": "list"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.(map[string]interface{})["count"], "other tenants' policies are hidden")
+
+	// The scheduled run, held to no tenant, applies each policy on its tenant's behalf
+	reports, err := ms.runDecayPolicies(ctx, "", time.Now(), false)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, "github.com/rival/app", reports[0].Repository)
+	assert.Equal(t, 1, reports[0].Trashed)
+	kept, err := store.GetByID(ctx, ours.ID)
+	require.NoError(t, err)
+	assert.False(t, kept.IsDeleted(), "repositories without a policy are left alone")
+}
@@ -0,0 +1,187 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"lerian-mcp-memory/internal/digest"
+	"lerian-mcp-memory/internal/intelligence"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/pkg/types"
+)
+
+const (
+	// OperationExtractDecisions promotes decisions found in stored chunks
+	OperationExtractDecisions = "extract_decisions"
+
+	// decisionTypeExtracted marks decision chunks promoted from conversation
+	decisionTypeExtracted = "extracted"
+
+	// maxDecisionExtractionScan caps the chunks scanned by one extract_decisions call
+	maxDecisionExtractionScan = 500
+)
+
+// decisionExtractionEnabled reports whether stored chunks are scanned for decisions
+func (ms *MemoryServer) decisionExtractionEnabled() bool {
+	return ms.container.Config != nil && ms.container.Config.Intelligence.DecisionExtraction
+}
+
+// promoteDecisions extracts decision-like statements from a stored chunk and
+// stores each as an architecture_decision chunk linked back to its source.
+// Chunks that are decision records themselves, or were already processed,
+// are skipped, so running it twice on a chunk is harmless.
+func (ms *MemoryServer) promoteDecisions(ctx context.Context, source *types.ConversationChunk) ([]*types.ConversationChunk, error) {
+	if source.Metadata.ExtendedMetadata != nil {
+		if _, ok := source.Metadata.ExtendedMetadata["decision_type"]; ok {
+			return nil, nil
+		}
+		if _, ok := source.Metadata.ExtendedMetadata[types.EMKeyDecisionRecords]; ok {
+			return nil, nil
+		}
+	}
+
+	extracted := intelligence.ExtractDecisions(source.Content)
+	if len(extracted) == 0 {
+		return nil, nil
+	}
+
+	vectorStore := ms.container.GetVectorStore()
+	records := make([]*types.ConversationChunk, 0, len(extracted))
+	recordIDs := make([]string, 0, len(extracted))
+	for i := range extracted {
+		record, err := ms.buildDecisionRecord(ctx, source, &extracted[i])
+		if err != nil {
+			return records, err
+		}
+		if err := vectorStore.Store(ctx, record); err != nil {
+			return records, fmt.Errorf("failed to store extracted decision: %w", err)
+		}
+		if _, err := vectorStore.StoreRelationship(ctx, record.ID, source.ID, types.RelationLearnedFrom, extracted[i].Confidence, types.ConfidenceAuto); err != nil {
+			logging.Warn("Failed to link extracted decision to its source", "decision_id", record.ID, "source_id", source.ID, "error", err)
+		}
+		records = append(records, record)
+		recordIDs = append(recordIDs, record.ID)
+		ms.publishEvent(chunkEvent(digest.EventDecision, record, map[string]interface{}{
+			"source_id":  source.ID,
+			"confidence": extracted[i].Confidence,
+		}))
+	}
+
+	if _, err := ms.updateChunk(ctx, source.ID, "", func(chunk *types.ConversationChunk) error {
+		if chunk.Metadata.ExtendedMetadata == nil {
+			chunk.Metadata.ExtendedMetadata = make(map[string]interface{})
+		}
+		chunk.Metadata.ExtendedMetadata[types.EMKeyDecisionRecords] = recordIDs
+		return nil
+	}); err != nil {
+		return records, fmt.Errorf("failed to record extracted decisions on source chunk: %w", err)
+	}
+
+	logging.Info("Promoted extracted decisions", "source_id", source.ID, "count", len(records))
+	return records, nil
+}
+
+// buildDecisionRecord turns an extracted decision into a decision chunk in
+// the same session and repository as its source
+func (ms *MemoryServer) buildDecisionRecord(ctx context.Context, source *types.ConversationChunk, decision *intelligence.ExtractedDecision) (*types.ConversationChunk, error) {
+	content := "ARCHITECTURAL DECISION: " + decision.Decision
+	if decision.Rationale != "" {
+		content += "\n\nRATIONALE: " + decision.Rationale
+	}
+	if len(decision.Alternatives) > 0 {
+		content += "\n\nALTERNATIVES CONSIDERED: " + strings.Join(decision.Alternatives, ", ")
+	}
+	content += "\n\nSOURCE: " + decision.Statement
+
+	metadata := types.ChunkMetadata{
+		Repository: source.Metadata.Repository,
+		Branch:     source.Metadata.Branch,
+		Outcome:    types.OutcomeSuccess,
+		Difficulty: types.DifficultyModerate,
+		Tags:       []string{"architecture", "decision", "auto-extracted"},
+		ExtendedMetadata: map[string]interface{}{
+			"decision_type":            decisionTypeExtracted,
+			"decision_text":            decision.Decision,
+			"rationale_text":           decision.Rationale,
+			"decision_alternatives":    decision.Alternatives,
+			"decision_chosen":          decision.Chosen,
+			types.EMKeyDecisionSource:  source.ID,
+			types.EMKeyConfidenceScore: decision.Confidence,
+		},
+	}
+
+	record, err := types.NewConversationChunk(source.SessionID, content, types.ChunkTypeArchitectureDecision, &metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create decision chunk: %w", err)
+	}
+	record.Summary = decision.Decision
+
+	embeddings, err := ms.container.GetEmbeddingService().GenerateEmbedding(ctx, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate embeddings for extracted decision: %w", err)
+	}
+	record.Embeddings = embeddings
+	return record, nil
+}
+
+// handleExtractDecisions promotes decisions from one chunk, or from the most
+// recent chunks of a repository that have not been scanned yet
+func (ms *MemoryServer) handleExtractDecisions(ctx context.Context, options map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: memory_intelligence extract_decisions called", "options", options)
+
+	repository, _ := options["repository"].(string)
+	vectorStore := ms.container.GetVectorStore()
+
+	var sources []types.ConversationChunk
+	if chunkID, ok := options["chunk_id"].(string); ok && chunkID != "" {
+		chunk, err := vectorStore.GetByID(ctx, chunkID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get chunk: %w", err)
+		}
+		if !chunkVisibleIn(chunk, repository) {
+			return nil, errors.New("chunk not found in repository")
+		}
+		if chunk.IsDeleted() {
+			return nil, fmt.Errorf("chunk %s is in the trash; restore it before extracting decisions", chunkID)
+		}
+		sources = []types.ConversationChunk{*chunk}
+	} else {
+		limit := 100
+		if l, ok := options["limit"].(float64); ok && l > 0 {
+			limit = min(int(l), maxDecisionExtractionScan)
+		}
+		chunks, err := ms.liveChunks(ctx, repository, limit)
+		if err != nil {
+			return nil, err
+		}
+		sources = chunks
+	}
+
+	decisions := make([]map[string]interface{}, 0)
+	for i := range sources {
+		records, err := ms.promoteDecisions(ctx, &sources[i])
+		for _, record := range records {
+			decisions = append(decisions, map[string]interface{}{
+				"chunk_id":        record.ID,
+				"source_chunk_id": sources[i].ID,
+				"decision":        record.Summary,
+				"rationale":       record.Metadata.ExtendedMetadata["rationale_text"],
+				"alternatives":    record.Metadata.ExtendedMetadata["decision_alternatives"],
+			})
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return map[string]interface{}{
+		"status":     "success",
+		"operation":  OperationExtractDecisions,
+		"repository": repository,
+		"scanned":    len(sources),
+		"decisions":  decisions,
+		"total":      len(decisions),
+	}, nil
+}
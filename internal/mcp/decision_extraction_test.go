@@ -0,0 +1,83 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractDecisionsPromotesLinkedRecords(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	source := newReportChunk(t, "session-1", "Long discussion about storage. We chose Postgres over MySQL because we need transactional DDL.", types.ChunkTypeDiscussion, types.ChunkMetadata{})
+	plain := newReportChunk(t, "session-1", "Fixed the flaky login test", types.ChunkTypeSolution, types.ChunkMetadata{})
+	trashed := newReportChunk(t, "session-1", "We chose Redis over Memcached because we need persistence.", types.ChunkTypeDiscussion, types.ChunkMetadata{})
+	require.NoError(t, store.Store(ctx, source))
+	require.NoError(t, store.Store(ctx, plain))
+	require.NoError(t, store.Store(ctx, trashed))
+
+	ms := newCompositeTestServer(t, store)
+	require.NoError(t, ms.trashChunk(ctx, trashed, time.Now()))
+	result, err := ms.handleExtractDecisions(ctx, map[string]interface{}{"repository": "github.com/acme/api"})
+	require.NoError(t, err)
+
+	response := result.(map[string]interface{})
+	assert.Equal(t, 2, response["scanned"])
+	require.Equal(t, 1, response["total"])
+	decision := response["decisions"].([]map[string]interface{})[0]
+	assert.Equal(t, source.ID, decision["source_chunk_id"])
+	assert.Equal(t, "Chose Postgres over MySQL", decision["decision"])
+	assert.Equal(t, "we need transactional DDL", decision["rationale"])
+
+	record, err := store.GetByID(ctx, decision["chunk_id"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, types.ChunkTypeArchitectureDecision, record.Type)
+	assert.Equal(t, "github.com/acme/api", record.Metadata.Repository)
+	assert.Equal(t, source.ID, record.Metadata.ExtendedMetadata[types.EMKeyDecisionSource])
+	assert.Equal(t, []string{"MySQL"}, record.Metadata.ExtendedMetadata["decision_alternatives"])
+	assert.NotEmpty(t, record.Embeddings)
+
+	relationships, err := store.GetRelationships(ctx, &types.RelationshipQuery{ChunkID: record.ID})
+	require.NoError(t, err)
+	require.Len(t, relationships, 1)
+	assert.Equal(t, source.ID, relationships[0].Relationship.TargetChunkID)
+	assert.Equal(t, types.RelationLearnedFrom, relationships[0].Relationship.RelationType)
+
+	updated, err := store.GetByID(ctx, source.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{record.ID}, updated.Metadata.ExtendedMetadata[types.EMKeyDecisionRecords])
+
+	// Scanning again promotes nothing new
+	result, err = ms.handleExtractDecisions(ctx, map[string]interface{}{"repository": "github.com/acme/api", "chunk_id": source.ID})
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.(map[string]interface{})["total"])
+
+	_, err = ms.handleExtractDecisions(ctx, map[string]interface{}{"repository": "github.com/acme/api", "chunk_id": trashed.ID})
+	assert.ErrorContains(t, err, "in the trash")
+}
+
+func TestExtractDecisionsChunkScope(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	other := newReportChunk(t, "session-1", "We decided to use Kafka.", types.ChunkTypeDiscussion, types.ChunkMetadata{Repository: "github.com/acme/web"})
+	require.NoError(t, store.Store(ctx, other))
+
+	ms := newCompositeTestServer(t, store)
+	_, err := ms.handleExtractDecisions(ctx, map[string]interface{}{"repository": "github.com/acme/api", "chunk_id": other.ID})
+	assert.ErrorContains(t, err, "not found in repository")
+}
+
+func TestDecisionExtractionEnabled(t *testing.T) {
+	ms := newCompositeTestServer(t, storage.NewSimpleMockVectorStore())
+	assert.False(t, ms.decisionExtractionEnabled())
+
+	ms.container.Config = config.DefaultConfig()
+	assert.True(t, ms.decisionExtractionEnabled())
+}
@@ -0,0 +1,230 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"lerian-mcp-memory/internal/audit"
+	"lerian-mcp-memory/internal/dedup"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/fredcamaral/gomcp-sdk"
+)
+
+// registerDedupeTool registers memory_dedupe
+func (ms *MemoryServer) registerDedupeTool() {
+	ms.addTool(mcp.NewTool(
+		"memory_dedupe",
+		"Find and merge near-duplicate memories in a repository: memories of the same session and type whose embeddings are more similar than threshold, as bulk imports from chat logs tend to produce. The earliest memory of each group is kept; the tags, files, tools, related memories, relationships and access counts of its duplicates are merged into it, with a merge history, and the duplicates are moved to the trash, where memory_restore can bring them back. dry_run only reports the groups.",
+		mcp.ObjectSchema("Deduplication parameters", map[string]interface{}{
+			"repository": map[string]interface{}{
+				"type":        "string",
+				"description": "Repository URL (required) - e.g. 'github.com/user/repo'",
+			},
+			"session_id": map[string]interface{}{
+				"type":        "string",
+				"description": "Only deduplicate the memories of this session",
+			},
+			"threshold": map[string]interface{}{
+				"type":        "number",
+				"default":     dedup.DefaultThreshold,
+				"minimum":     0.5,
+				"maximum":     1,
+				"description": "Similarity above which memories are duplicates",
+			},
+			"dry_run": map[string]interface{}{
+				"type":        "boolean",
+				"default":     false,
+				"description": "Report the duplicate groups without merging anything",
+			},
+			"limit": map[string]interface{}{
+				"type":        "number",
+				"default":     defaultReportItems,
+				"description": "Duplicate groups to list",
+			},
+		}, []string{"repository"}),
+	), mcp.ToolHandlerFunc(ms.handleDedupe))
+}
+
+// handleDedupe finds and, unless a dry run, merges near-duplicate memories
+func (ms *MemoryServer) handleDedupe(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: memory_dedupe called", "args", args)
+
+	repository, _ := args["repository"].(string)
+	if repository == "" {
+		return nil, errors.New("repository parameter is required. Example: {\"repository\": \"github.com/user/repo\", \"dry_run\": true}")
+	}
+	threshold := dedup.DefaultThreshold
+	if t, ok := args["threshold"].(float64); ok {
+		if t < 0.5 || t > 1 {
+			return nil, NewToolError(ToolErrorInvalidParameter, fmt.Sprintf("threshold must be between 0.5 and 1, got %g", t)).
+				WithParameter("threshold").
+				WithExample(`{"repository": "github.com/acme/api", "threshold": 0.95}`)
+		}
+		threshold = t
+	}
+	limit := defaultReportItems
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+	sessionID, _ := args["session_id"].(string)
+	dryRun, _ := args["dry_run"].(bool)
+
+	chunks, err := ms.projectChunks(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	if sessionID != "" {
+		// Stored chunks carry the repository-scoped form of their session
+		scopedSessionID := ms.createRepositoryScopedSessionID(repository, sessionID)
+		inSession := chunks[:0]
+		for i := range chunks {
+			if chunks[i].SessionID == scopedSessionID {
+				inSession = append(inSession, chunks[i])
+			}
+		}
+		chunks = inSession
+	}
+
+	groups := dedup.Find(chunks, threshold)
+	duplicates := 0
+	for i := range groups {
+		duplicates += len(groups[i].Duplicates)
+	}
+
+	response := map[string]interface{}{
+		"status":           "success",
+		"repository":       repository,
+		"threshold":        threshold,
+		"dry_run":          dryRun,
+		"groups_found":     len(groups),
+		"duplicates_found": duplicates,
+	}
+	if sessionID != "" {
+		response["session_id"] = sessionID
+	}
+	if !dryRun {
+		merged, relinked, failed := ms.mergeDuplicates(ctx, groups, chunks, time.Now())
+		response["merged"] = merged
+		response["relationships_moved"] = relinked
+		if failed > 0 {
+			response["failed"] = failed
+		}
+		if merged > 0 {
+			if auditLogger := ms.container.GetAuditLogger(); auditLogger != nil {
+				auditLogger.LogEvent(ctx, audit.EventTypeMemoryUpdate, "memory_dedupe", "repository", repository, map[string]interface{}{
+					"groups":              len(groups),
+					"merged":              merged,
+					"relationships_moved": relinked,
+					"failed":              failed,
+				})
+			}
+		}
+	}
+
+	if len(groups) > limit {
+		groups = groups[:limit]
+	}
+	response["groups"] = groups
+	return response, nil
+}
+
+// mergeDuplicates merges each group's duplicates into its canonical memory,
+// moves their relationships over and trashes them. It returns how many
+// duplicates were merged, how many relationships moved, and how many
+// duplicates failed to merge.
+func (ms *MemoryServer) mergeDuplicates(ctx context.Context, groups []dedup.Group, chunks []types.ConversationChunk, now time.Time) (merged, relinked, failed int) {
+	byID := make(map[string]*types.ConversationChunk, len(chunks))
+	for i := range chunks {
+		byID[chunks[i].ID] = &chunks[i]
+	}
+
+	for i := range groups {
+		group := &groups[i]
+		canonical, err := ms.updateChunk(ctx, group.CanonicalID, "", func(chunk *types.ConversationChunk) error {
+			for _, duplicate := range group.Duplicates {
+				dedup.Merge(chunk, byID[duplicate.ChunkID], duplicate.Similarity, now)
+			}
+			return nil
+		})
+		if err != nil {
+			logging.Warn("Failed to merge duplicates", "canonical_id", group.CanonicalID, "error", err)
+			failed += len(group.Duplicates)
+			continue
+		}
+
+		for _, duplicate := range group.Duplicates {
+			chunk := byID[duplicate.ChunkID]
+			relinked += ms.moveRelationships(ctx, chunk.ID, canonical.ID)
+			if err := ms.trashChunk(ctx, chunk, now); err != nil {
+				logging.Warn("Failed to trash merged duplicate", "chunk_id", chunk.ID, "error", err)
+				failed++
+				continue
+			}
+			merged++
+		}
+	}
+	return merged, relinked, failed
+}
+
+// moveRelationships points the relationships of a merged duplicate at its
+// canonical memory. Relationships between the two, and ones the canonical
+// already has, are dropped. It returns how many relationships moved.
+func (ms *MemoryServer) moveRelationships(ctx context.Context, duplicateID, canonicalID string) int {
+	store := ms.container.GetVectorStore()
+	query := types.NewRelationshipQuery(duplicateID)
+	query.MinConfidence = 0
+	query.IncludeChunks = false
+	relationships, err := store.GetRelationships(ctx, query)
+	if err != nil {
+		logging.Warn("Failed to list relationships of merged duplicate", "chunk_id", duplicateID, "error", err)
+		return 0
+	}
+
+	existing := make(map[string]bool)
+	canonicalQuery := types.NewRelationshipQuery(canonicalID)
+	canonicalQuery.MinConfidence = 0
+	canonicalQuery.IncludeChunks = false
+	if current, err := store.GetRelationships(ctx, canonicalQuery); err == nil {
+		for i := range current {
+			rel := &current[i].Relationship
+			existing[relationshipKey(rel.SourceChunkID, rel.TargetChunkID, rel.RelationType)] = true
+		}
+	}
+
+	moved := 0
+	for i := range relationships {
+		rel := &relationships[i].Relationship
+		source, target := rel.SourceChunkID, rel.TargetChunkID
+		switch duplicateID {
+		case source:
+			source = canonicalID
+		case target:
+			target = canonicalID
+		default:
+			continue
+		}
+
+		key := relationshipKey(source, target, rel.RelationType)
+		if source != target && !existing[key] {
+			if _, err := store.StoreRelationship(ctx, source, target, rel.RelationType, rel.Confidence, rel.ConfidenceSource); err != nil {
+				logging.Warn("Failed to move relationship of merged duplicate", "relationship_id", rel.ID, "error", err)
+				continue
+			}
+			existing[key] = true
+			moved++
+		}
+		if err := store.DeleteRelationship(ctx, rel.ID); err != nil {
+			logging.Warn("Failed to delete relationship of merged duplicate", "relationship_id", rel.ID, "error", err)
+		}
+	}
+	return moved
+}
+
+// relationshipKey identifies a relationship by its ends and type
+func relationshipKey(source, target string, relationType types.RelationType) string {
+	return source + "|" + target + "|" + string(relationType)
+}
@@ -0,0 +1,116 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"lerian-mcp-memory/internal/chunking"
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/relationships"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// topicEmbeddingService embeds texts mentioning OOM close together and
+// every other text apart from them
+type topicEmbeddingService struct {
+	staticEmbeddingService
+}
+
+func (topicEmbeddingService) GenerateEmbedding(_ context.Context, text string) ([]float64, error) {
+	if strings.Contains(text, "OOM") {
+		return []float64{0.1, 0.2, 0.3}, nil
+	}
+	return []float64{0.9, -0.2, 0.1}, nil
+}
+
+func TestDedupeMergesNearDuplicates(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewLocalVectorStore("")
+	ms := newCompositeTestServer(t, store)
+	ms.container.EmbeddingService = topicEmbeddingService{}
+	ms.container.ChunkingService = chunking.NewService(&config.DefaultConfig().Chunking, topicEmbeddingService{})
+	ms.container.RelationshipManager = relationships.NewManager()
+
+	storeChunk := func(sessionID, content string, tags ...string) *types.ConversationChunk {
+		result, err := ms.handleStoreChunk(ctx, map[string]interface{}{
+			"session_id": sessionID,
+			"repository": "github.com/acme/api",
+			"content":    content,
+			"tags":       tagsArgument(tags),
+		})
+		require.NoError(t, err)
+		chunk, err := store.GetByID(ctx, result.(map[string]interface{})["chunk_id"].(string))
+		require.NoError(t, err)
+		return chunk
+	}
+	original := storeChunk("import-1", "Pods restart on OOM", "k8s")
+	copied := storeChunk("import-1", "Pods restart on OOM.", "memory")
+	distinct := storeChunk("import-1", "Raised the memory limit")
+	otherSession := storeChunk("import-2", "Pods restart on OOM")
+	require.Equal(t, original.Type, copied.Type)
+	_, err := store.StoreRelationship(ctx, distinct.ID, copied.ID, types.RelationLedTo, 0.8, types.ConfidenceExplicit)
+	require.NoError(t, err)
+	_, err = store.StoreRelationship(ctx, original.ID, copied.ID, types.RelationRelatedTo, 0.8, types.ConfidenceExplicit)
+	require.NoError(t, err)
+
+	result, err := ms.handleDedupe(ctx, map[string]interface{}{"repository": "github.com/acme/api", "dry_run": true})
+	require.NoError(t, err)
+	preview := result.(map[string]interface{})
+	assert.Equal(t, 1, preview["groups_found"])
+	assert.Equal(t, 1, preview["duplicates_found"])
+	assert.Nil(t, preview["merged"])
+	untouched, err := store.GetByID(ctx, copied.ID)
+	require.NoError(t, err)
+	assert.False(t, untouched.IsDeleted(), "a dry run merges nothing")
+
+	result, err = ms.handleDedupe(ctx, map[string]interface{}{"repository": "github.com/acme/api", "session_id": "import-1"})
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	assert.Equal(t, 1, response["merged"])
+	assert.Equal(t, 1, response["relationships_moved"])
+
+	canonical, err := store.GetByID(ctx, original.ID)
+	require.NoError(t, err)
+	assert.Subset(t, canonical.Metadata.Tags, []string{"k8s", "memory"})
+	history := canonical.MergeHistory()
+	require.Len(t, history, 1)
+	assert.Equal(t, copied.ID, history[0].ChunkID)
+
+	duplicate, err := store.GetByID(ctx, copied.ID)
+	require.NoError(t, err)
+	assert.True(t, duplicate.IsDeleted())
+	assert.Equal(t, original.ID, duplicate.MergedInto())
+
+	query := types.NewRelationshipQuery(distinct.ID)
+	query.MinConfidence = 0
+	relationships, err := store.GetRelationships(ctx, query)
+	require.NoError(t, err)
+	require.Len(t, relationships, 1)
+	assert.Equal(t, original.ID, relationships[0].Relationship.TargetChunkID)
+	query = types.NewRelationshipQuery(copied.ID)
+	query.MinConfidence = 0
+	relationships, err = store.GetRelationships(ctx, query)
+	require.NoError(t, err)
+	assert.Empty(t, relationships, "relationships between the merged memories are dropped")
+
+	other, err := store.GetByID(ctx, otherSession.ID)
+	require.NoError(t, err)
+	assert.False(t, other.IsDeleted(), "memories of other sessions are not duplicates")
+
+	_, err = ms.handleDedupe(ctx, map[string]interface{}{"repository": "github.com/acme/api", "threshold": float64(2)})
+	assert.Equal(t, ToolErrorInvalidParameter, asToolError(err).Code)
+}
+
+// tagsArgument passes tags the way MCP clients send them
+func tagsArgument(tags []string) []interface{} {
+	argument := make([]interface{}, len(tags))
+	for i, tag := range tags {
+		argument[i] = tag
+	}
+	return argument
+}
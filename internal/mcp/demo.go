@@ -0,0 +1,224 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+// demoSessionID is the session the seeded demo memories belong to
+const demoSessionID = "demo-seed"
+
+// demoWriteOperations are the memory_create operations open to demo clients.
+// They only add memories, which the next reset removes.
+var demoWriteOperations = map[string]bool{
+	OperationStoreChunk:    true,
+	OperationStoreDecision: true,
+	"create_thread":        true,
+	"create_alias":         true,
+	"create_relationship":  true,
+}
+
+// demoAnalysisTools analyze memories without changing the seeded ones
+var demoAnalysisTools = map[string]bool{
+	"memory_analyze":      true,
+	"memory_intelligence": true,
+}
+
+// demoAllows reports whether demo clients may call a tool operation: every
+// read-only tool except the admin ones, the analysis tools and the
+// memory_create operations that only add memories
+func demoAllows(tool, operation string) bool {
+	switch {
+	case tool == "memory_create":
+		return demoWriteOperations[operation]
+	case demoAnalysisTools[tool]:
+		return true
+	case tool == "auth_list_keys":
+		return false
+	}
+	annotations, ok := builtinToolAnnotations[tool]
+	return ok && annotations.ReadOnlyHint != nil && *annotations.ReadOnlyHint
+}
+
+// DemoMiddleware holds clients of a public demo to the tools that read
+// memories or add new ones. Anything that would change or delete the seeded
+// dataset, reach another service or administer the server is refused.
+func DemoMiddleware() Middleware {
+	return ForMethods(func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+			tool, operation := RequestTarget(req), toolOperation(req)
+			if demoAllows(tool, operation) {
+				return next(ctx, req)
+			}
+			return ErrorResponse(req, ForbiddenCode, "Not available in the demo", map[string]interface{}{
+				"code":      "DEMO_RESTRICTED",
+				"tool":      tool,
+				"operation": operation,
+				"hint":      "The demo serves read tools, memory analysis and memory_create store_chunk/store_decision; run your own server for the rest",
+			})
+		}
+	}, "tools/call")
+}
+
+// demoMemory is a memory of the seeded demo dataset. SolvesIndex links a
+// solution to the problem at that index of demoSeed; -1 links nothing.
+type demoMemory struct {
+	Repository  string
+	Type        types.ChunkType
+	Content     string
+	Summary     string
+	Tags        []string
+	Outcome     types.Outcome
+	Difficulty  types.Difficulty
+	AgeDays     int
+	SolvesIndex int
+}
+
+// demoSeed is the dataset a demo server serves. The credentials in it are
+// visibly fake so nobody mistakes them for leaked ones.
+var demoSeed = []demoMemory{
+	{
+		Repository: "github.com/demo/payments-api", Type: types.ChunkTypeProblem,
+		Content: "Checkout fails with ERR_4021 when the payment provider times out after 10s. Customers are charged but the order stays pending.",
+		Summary: "ERR_4021 on payment provider timeouts", Tags: []string{"payments", "timeout"},
+		Outcome: types.OutcomeSuccess, Difficulty: types.DifficultyModerate, AgeDays: 20, SolvesIndex: -1,
+	},
+	{
+		Repository: "github.com/demo/payments-api", Type: types.ChunkTypeSolution,
+		Content: "Made charges idempotent with an Idempotency-Key per order and a reconciliation job that settles pending orders from provider webhooks. ERR_4021 orders now complete within a minute.",
+		Summary: "Idempotent charges and webhook reconciliation fix ERR_4021", Tags: []string{"payments", "idempotency"},
+		Outcome: types.OutcomeSuccess, Difficulty: types.DifficultyModerate, AgeDays: 19, SolvesIndex: 0,
+	},
+	{
+		Repository: "github.com/demo/payments-api", Type: types.ChunkTypeArchitectureDecision,
+		Content: "Decided to use PostgreSQL advisory locks instead of Redis locks for order settlement, because settlement already runs in a database transaction and we drop a dependency.",
+		Summary: "Advisory locks for order settlement", Tags: []string{"architecture", "postgres"},
+		Outcome: types.OutcomeSuccess, Difficulty: types.DifficultyModerate, AgeDays: 40, SolvesIndex: -1,
+	},
+	{
+		Repository: "github.com/demo/payments-api", Type: types.ChunkTypeCodeChange,
+		Content: "Moved the provider client config to environment variables. Local development uses PAYMENTS_API_KEY=demo-key-NOT-A-REAL-SECRET against the sandbox at https://sandbox.payments.example.com.",
+		Summary: "Provider client configured from the environment", Tags: []string{"config"},
+		Outcome: types.OutcomeSuccess, Difficulty: types.DifficultySimple, AgeDays: 12, SolvesIndex: -1,
+	},
+	{
+		Repository: "github.com/demo/payments-api", Type: types.ChunkTypeProblem,
+		Content: "Refund webhooks arrive before the refund request returns, so the handler cannot find the refund and drops the event.",
+		Summary: "Refund webhooks race the refund request", Tags: []string{"payments", "webhooks"},
+		Outcome: types.OutcomeInProgress, Difficulty: types.DifficultyComplex, AgeDays: 3, SolvesIndex: -1,
+	},
+	{
+		Repository: "github.com/demo/web-app", Type: types.ChunkTypeProblem,
+		Content: "The dashboard takes 6 seconds to load for accounts with many projects because every project card fetches its own stats.",
+		Summary: "Slow dashboard from per-card stats requests", Tags: []string{"performance", "frontend"},
+		Outcome: types.OutcomeSuccess, Difficulty: types.DifficultyModerate, AgeDays: 9, SolvesIndex: -1,
+	},
+	{
+		Repository: "github.com/demo/web-app", Type: types.ChunkTypeSolution,
+		Content: "Added a batched /api/projects/stats endpoint and load the stats once per page. The dashboard now loads in under a second.",
+		Summary: "Batched project stats endpoint", Tags: []string{"performance", "api"},
+		Outcome: types.OutcomeSuccess, Difficulty: types.DifficultySimple, AgeDays: 8, SolvesIndex: 5,
+	},
+	{
+		Repository: "github.com/demo/web-app", Type: types.ChunkTypeArchitectureDecision,
+		Content: "Chose server-side sessions over JWTs in local storage, so sessions can be revoked and tokens never reach JavaScript. The demo login is demo@example.com / password-is-demo.",
+		Summary: "Server-side sessions for the web app", Tags: []string{"architecture", "auth"},
+		Outcome: types.OutcomeSuccess, Difficulty: types.DifficultyModerate, AgeDays: 60, SolvesIndex: -1,
+	},
+	{
+		Repository: "github.com/demo/web-app", Type: types.ChunkTypeDiscussion,
+		Content: "Discussed moving the design system to CSS variables so themes can switch without a rebuild. Agreed to try it on the settings page first.",
+		Summary: "Design system theming with CSS variables", Tags: []string{"frontend", "design-system"},
+		Outcome: types.OutcomeInProgress, Difficulty: types.DifficultySimple, AgeDays: 1, SolvesIndex: -1,
+	},
+}
+
+// seedDemo stores the demo dataset and links its solutions to their problems
+func (ms *MemoryServer) seedDemo(ctx context.Context) (int, error) {
+	store := ms.container.GetVectorStore()
+	now := time.Now()
+	ids := make([]string, len(demoSeed))
+	for i := range demoSeed {
+		memory := &demoSeed[i]
+		chunk, err := types.NewConversationChunk(demoSessionID, memory.Content, memory.Type, &types.ChunkMetadata{
+			Repository: memory.Repository,
+			Tags:       append([]string(nil), memory.Tags...),
+			Outcome:    memory.Outcome,
+			Difficulty: memory.Difficulty,
+		})
+		if err != nil {
+			return i, fmt.Errorf("invalid demo memory %d: %w", i, err)
+		}
+		chunk.Summary = memory.Summary
+		chunk.Timestamp = now.AddDate(0, 0, -memory.AgeDays)
+		if err := ms.processAndStoreChunk(ctx, chunk); err != nil {
+			return i, fmt.Errorf("failed to seed demo memory %d: %w", i, err)
+		}
+		ids[i] = chunk.ID
+
+		if memory.SolvesIndex >= 0 {
+			if _, err := store.StoreRelationship(ctx, ids[memory.SolvesIndex], chunk.ID, types.RelationSolvedBy, 1.0, types.ConfidenceExplicit); err != nil {
+				logging.Warn("Failed to link demo solution", "chunk_id", chunk.ID, "error", err)
+			}
+		}
+	}
+	return len(demoSeed), nil
+}
+
+// resetDemo deletes every memory and relationship and seeds the demo dataset again
+func (ms *MemoryServer) resetDemo(ctx context.Context) (int, error) {
+	store := ms.container.GetVectorStore()
+	chunks, err := store.GetAllChunks(storage.WithDeleted(ctx))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list demo memories: %w", err)
+	}
+
+	ids := make([]string, 0, len(chunks))
+	for i := range chunks {
+		ids = append(ids, chunks[i].ID)
+		query := types.NewRelationshipQuery(chunks[i].ID)
+		query.MinConfidence = 0
+		query.MaxDepth = 1
+		query.IncludeChunks = false
+		query.Limit = 0
+		relationships, err := store.GetRelationships(ctx, query)
+		if err != nil {
+			continue
+		}
+		for j := range relationships {
+			_ = store.DeleteRelationship(ctx, relationships[j].Relationship.ID)
+		}
+	}
+	if len(ids) > 0 {
+		if _, err := store.BatchDelete(ctx, ids); err != nil {
+			return 0, fmt.Errorf("failed to clear demo memories: %w", err)
+		}
+	}
+	return ms.seedDemo(ctx)
+}
+
+// runDemoResets restores the demo dataset every interval until ctx ends
+func (ms *MemoryServer) runDemoResets(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := ms.resetDemo(ctx); err != nil {
+				logging.Error("Demo reset failed", "error", err)
+				continue
+			}
+			logging.Info("Reset demo dataset")
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/fredcamaral/gomcp-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDemoMiddleware(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	for _, name := range []string{"memory_read", "memory_create", "memory_delete", "auth_list_keys"} {
+		ms.addTool(mcp.NewTool(name, name, mcp.ObjectSchema(name, map[string]interface{}{}, nil)),
+			mcp.ToolHandlerFunc(func(context.Context, map[string]interface{}) (interface{}, error) { return "ok", nil }))
+	}
+	ms.Use(DemoMiddleware())
+
+	tests := []struct {
+		tool      string
+		operation string
+		allowed   bool
+	}{
+		{"memory_read", "search", true},
+		{"memory_create", OperationStoreChunk, true},
+		{"memory_create", "bulk_import", false},
+		{"memory_delete", "bulk_delete", false},
+		{"auth_list_keys", "", false},
+		{"echo", "", false},
+	}
+	for _, tt := range tests {
+		resp := ms.HandleRequest(context.Background(), toolCallRequest(tt.tool, map[string]interface{}{"operation": tt.operation}))
+		if tt.allowed {
+			assert.Nil(t, resp.Error, "%s %s", tt.tool, tt.operation)
+			continue
+		}
+		require.NotNil(t, resp.Error, "%s %s", tt.tool, tt.operation)
+		assert.Equal(t, ForbiddenCode, resp.Error.Code)
+		assert.Equal(t, "DEMO_RESTRICTED", resp.Error.Data.(map[string]interface{})["code"])
+	}
+}
+
+func TestResetDemo(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewLocalVectorStore("")
+	require.NoError(t, store.Initialize(ctx))
+	ms := newCompositeTestServer(t, store)
+
+	seeded, err := ms.resetDemo(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, len(demoSeed), seeded)
+
+	// A visitor's memory is gone after the next reset
+	visitor := newReportChunk(t, "visitor", "Trying out the demo", types.ChunkTypeDiscussion, types.ChunkMetadata{Repository: "github.com/demo/web-app"})
+	require.NoError(t, store.Store(ctx, visitor))
+
+	_, err = ms.resetDemo(ctx)
+	require.NoError(t, err)
+	chunks, err := store.GetAllChunks(ctx)
+	require.NoError(t, err)
+	assert.Len(t, chunks, len(demoSeed))
+	for i := range chunks {
+		assert.NotEqual(t, visitor.ID, chunks[i].ID)
+	}
+
+	// Each solution is linked to its problem once, however many resets ran
+	for i := range chunks {
+		if chunks[i].Type != types.ChunkTypeSolution {
+			continue
+		}
+		query := types.NewRelationshipQuery(chunks[i].ID)
+		relationships, err := store.GetRelationships(ctx, query)
+		require.NoError(t, err)
+		require.Len(t, relationships, 1, chunks[i].Summary)
+		assert.Equal(t, types.RelationSolvedBy, relationships[0].Relationship.RelationType)
+	}
+}
@@ -0,0 +1,189 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/digest"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/tenancy"
+
+	"github.com/fredcamaral/gomcp-sdk/sampling"
+)
+
+const (
+	// digestSummaryPrompt instructs the client's model when summarizing a digest
+	digestSummaryPrompt = "Summarize this project memory digest for the team in at most five sentences. Lead with what changed and call out blockers and stale work."
+	// digestSummaryMaxTokens bounds the digest summary
+	digestSummaryMaxTokens = 400
+)
+
+// initDigests creates the digest generator and scheduler from configuration
+func (ms *MemoryServer) initDigests(cfg *config.Config) {
+	ms.digestGenerator = digest.NewGenerator(ms.container.GetVectorStore())
+	ms.digestGenerator.SetStaleTaskAge(time.Duration(cfg.Digest.StaleTaskDays) * 24 * time.Hour)
+
+	dispatcher := digest.NewDispatcher(digest.SMTPConfig{
+		Host:     cfg.Digest.SMTPHost,
+		Port:     cfg.Digest.SMTPPort,
+		Username: cfg.Digest.SMTPUsername,
+		Password: cfg.Digest.SMTPPassword,
+		From:     cfg.Digest.SMTPFrom,
+	})
+	ms.digestScheduler = digest.NewScheduler(ms.digestGenerator, dispatcher)
+
+	// Subscribers hear about project events through the same channels
+	if subscriptions := ms.container.GetSubscriptions(); subscriptions != nil {
+		ms.eventNotifier = digest.NewNotifier(subscriptions, dispatcher)
+		ms.digestScheduler.SetNotifier(ms.eventNotifier)
+	}
+
+	ms.initUsageReports()
+}
+
+// startDigestScheduler loads configured schedules and starts the scheduler loop
+func (ms *MemoryServer) startDigestScheduler(ctx context.Context) {
+	cfg := ms.container.Config
+	if ms.digestScheduler == nil || cfg == nil || !cfg.Digest.Enabled {
+		return
+	}
+
+	if cfg.Digest.SchedulesFile != "" {
+		schedules, err := digest.LoadSchedules(cfg.Digest.SchedulesFile)
+		if err != nil {
+			logging.Error("Failed to load digest schedules", "file", cfg.Digest.SchedulesFile, "error", err)
+		}
+		for i := range schedules {
+			if err := ms.digestScheduler.AddSchedule(schedules[i], time.Now()); err != nil {
+				logging.Warn("Skipping invalid digest schedule", "project", schedules[i].Project, "error", err)
+			}
+		}
+		if ms.usageReporter != nil {
+			usageSchedules, err := digest.LoadUsageSchedules(cfg.Digest.SchedulesFile)
+			if err != nil {
+				logging.Error("Failed to load usage report schedules", "file", cfg.Digest.SchedulesFile, "error", err)
+			}
+			for i := range usageSchedules {
+				if err := ms.digestScheduler.AddUsageSchedule(usageSchedules[i], time.Now()); err != nil {
+					logging.Warn("Skipping invalid usage report schedule", "tenant", usageSchedules[i].Tenant, "error", err)
+				}
+			}
+		}
+	}
+
+	go ms.digestScheduler.Run(ctx)
+	logging.Info("Digest scheduler started", "schedules", len(ms.digestScheduler.Schedules()))
+}
+
+// handleGenerateDigest generates a digest on demand and returns it rendered
+func (ms *MemoryServer) handleGenerateDigest(ctx context.Context, options map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: memory_system generate_digest called", "options", options)
+
+	repository, ok := options["repository"].(string)
+	if !ok || repository == "" {
+		return nil, errors.New("repository parameter is required for generate_digest. Example: {\"repository\": \"github.com/user/repo\", \"period\": \"weekly\", \"format\": \"markdown\"}")
+	}
+
+	period := digest.PeriodDaily
+	if p, ok := options["period"].(string); ok && p != "" {
+		period = digest.Period(p)
+	}
+
+	format := digest.FormatMarkdown
+	if f, ok := options["format"].(string); ok && f != "" {
+		format = digest.Format(f)
+	}
+
+	result, err := ms.digestGenerator.Generate(ctx, repository, period, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate digest: %w", err)
+	}
+
+	rendered, err := digest.Render(result, format)
+	if err != nil {
+		return nil, err
+	}
+
+	response := map[string]interface{}{
+		"digest":   result,
+		"format":   format,
+		"rendered": rendered,
+	}
+
+	// The summary is a bonus; a client that cannot sample still gets the digest
+	if summarize, _ := options["summarize"].(bool); summarize {
+		summary, err := ms.summarizeDigest(ctx, rendered)
+		if err != nil {
+			logging.Warn("Digest summary unavailable", "repository", repository, "error", err)
+			response["summary_error"] = err.Error()
+		} else {
+			response["summary"] = summary.Content.Text
+			response["summary_model"] = summary.Model
+		}
+	}
+
+	logging.Info("generate_digest completed", "repository", repository, "period", period)
+	return response, nil
+}
+
+// summarizeDigest asks the client's model to summarize a rendered digest
+func (ms *MemoryServer) summarizeDigest(ctx context.Context, rendered string) (*sampling.CreateMessageResponse, error) {
+	messages := []sampling.SamplingMessage{{
+		Role:    "user",
+		Content: sampling.SamplingMessageContent{Type: "text", Text: rendered},
+	}}
+	return ms.RequestSampling(ctx, messages, SamplingOptions{
+		SystemPrompt: digestSummaryPrompt,
+		MaxTokens:    digestSummaryMaxTokens,
+	})
+}
+
+// handleScheduleDigest adds or replaces a per-project digest schedule. The
+// project must be one of the caller's tenant's, and the digests are
+// generated on the tenant's behalf.
+func (ms *MemoryServer) handleScheduleDigest(ctx context.Context, options map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: memory_system schedule_digest called", "options", options)
+
+	repository, ok := options["repository"].(string)
+	if !ok || repository == "" {
+		return nil, errors.New("repository parameter is required for schedule_digest. Example: {\"repository\": \"github.com/user/repo\", \"period\": \"weekly\", \"hour\": 9, \"targets\": [{\"type\": \"slack\", \"url\": \"https://hooks.slack.com/...\"}]}")
+	}
+	if err := checkProjectTenant(ctx, repository); err != nil {
+		return nil, err
+	}
+
+	// Round-trip the options through JSON to reuse the schedule's field names and types
+	options["project"] = repository
+	if _, ok := options["period"]; !ok {
+		options["period"] = string(digest.PeriodDaily)
+	}
+	raw, err := json.Marshal(options)
+	if err != nil {
+		return nil, fmt.Errorf("invalid digest schedule: %w", err)
+	}
+
+	var schedule digest.Schedule
+	if err := json.Unmarshal(raw, &schedule); err != nil {
+		return nil, fmt.Errorf("invalid digest schedule: %w", err)
+	}
+	schedule.Tenant = tenancy.FromContext(ctx)
+
+	now := time.Now()
+	if err := ms.digestScheduler.AddSchedule(schedule, now); err != nil {
+		return nil, err
+	}
+
+	enabled := ms.container.Config != nil && ms.container.Config.Digest.Enabled
+	return map[string]interface{}{
+		"repository":        repository,
+		"period":            schedule.Period,
+		"next_run":          schedule.NextRun(now).Format(time.RFC3339),
+		"targets":           len(schedule.Targets),
+		"scheduler_enabled": enabled,
+		"note":              "The schedule lasts until the server restarts; add it to MCP_MEMORY_DIGEST_SCHEDULES_FILE to keep it",
+	}, nil
+}
@@ -0,0 +1,555 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"lerian-mcp-memory/internal/highlight"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/tenancy"
+	"lerian-mcp-memory/pkg/types"
+)
+
+const (
+	// defaultEditorContextLimit is the number of memories returned by default
+	defaultEditorContextLimit = 5
+	// maxEditorContextLimit bounds the memories returned, and those kept per position
+	maxEditorContextLimit = 20
+
+	// editorIndexTTL is how long a repository's memories are ranked from memory
+	// before they are reloaded in the background
+	editorIndexTTL = 30 * time.Second
+	// maxEditorIndexChunks bounds the memories kept per repository, most recent first
+	maxEditorIndexChunks = 2000
+	// maxEditorContextEntries bounds the editor positions whose context is kept
+	maxEditorContextEntries = 1000
+
+	// editorRefineTimeout bounds background reloads and semantic refinement
+	editorRefineTimeout = 15 * time.Second
+
+	// Keyword scores of the ways a memory matches an editor position
+	editorFileWeight      = 3.0 // the memory modified the file
+	editorFileNameWeight  = 1.0 // it modified or mentions a file of the same name
+	editorSymbolWeight    = 2.0 // its content mentions the symbol, per mention up to three
+	editorSummaryWeight   = 1.0 // its summary mentions the symbol
+	editorPathTermWeight  = 0.5 // its content mentions a term of the path
+	editorSemanticWeight  = 2.0 // scales the similarity of semantic matches
+	maxEditorSymbolCounts = 3
+)
+
+// EditorContextQuery is an editor position to find memories for
+type EditorContextQuery struct {
+	Repository string
+	FilePath   string
+	Symbol     string
+	Limit      int
+}
+
+// EditorContextItem is a memory relevant to an editor position
+type EditorContextItem struct {
+	ChunkID   string    `json:"chunk_id"`
+	Type      string    `json:"type"`
+	Summary   string    `json:"summary,omitempty"`
+	Snippet   string    `json:"snippet"`
+	Files     []string  `json:"files,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Score     float64   `json:"score"`
+	MatchedOn []string  `json:"matched_on"`
+}
+
+// EditorContext is the context returned for an editor position. Refined
+// reports whether semantic matches were merged into the keyword matches.
+type EditorContext struct {
+	Repository string              `json:"repository"`
+	FilePath   string              `json:"file_path,omitempty"`
+	Symbol     string              `json:"symbol,omitempty"`
+	Items      []EditorContextItem `json:"items"`
+	Refined    bool                `json:"refined"`
+	Cached     bool                `json:"cached"`
+}
+
+// editorIndexKey identifies the memories of a repository as seen by a
+// tenant; tenant is empty for requests not held to one
+type editorIndexKey struct {
+	tenant, repository string
+}
+
+// editorContextKey identifies an editor position
+type editorContextKey struct {
+	editorIndexKey
+	filePath, symbol string
+}
+
+// editorIndex holds a repository's memories as loaded at one time, with the
+// lowercased words of each memory's content
+type editorIndex struct {
+	chunks   []types.ConversationChunk
+	words    []map[string]bool
+	loadedAt time.Time
+}
+
+// editorContextEntry is the context ranked for a position from one index.
+// tried is set once semantic refinement ran, whether or not it succeeded.
+type editorContextEntry struct {
+	items   []EditorContextItem
+	refined bool
+	tried   bool
+	index   *editorIndex
+}
+
+// editorContextCache keeps each repository's memories, and the context of
+// recent editor positions, so lookups need not reach storage. Both are kept
+// per tenant, since each tenant reads memories through its own projects.
+// Contexts are kept while the index they were ranked from is current.
+type editorContextCache struct {
+	mu        sync.Mutex
+	indexes   map[editorIndexKey]*editorIndex
+	reloading map[editorIndexKey]bool
+	entries   map[editorContextKey]*editorContextEntry
+	refining  map[editorContextKey]bool
+}
+
+// index returns the repository's index, if loaded, and whether it is due for
+// a reload no one started yet; the caller then reloads it
+func (c *editorContextCache) index(key editorIndexKey, now time.Time) (index *editorIndex, reload bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	index = c.indexes[key]
+	if index == nil || now.Sub(index.loadedAt) <= editorIndexTTL || c.reloading[key] {
+		return index, false
+	}
+	if c.reloading == nil {
+		c.reloading = make(map[editorIndexKey]bool)
+	}
+	c.reloading[key] = true
+	return index, true
+}
+
+// setIndex makes index the current index of key
+func (c *editorContextCache) setIndex(key editorIndexKey, index *editorIndex) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.indexes == nil {
+		c.indexes = make(map[editorIndexKey]*editorIndex)
+	}
+	if index != nil {
+		c.indexes[key] = index
+	}
+	delete(c.reloading, key)
+}
+
+// entry returns the context kept for key if it was ranked from the current index
+func (c *editorContextCache) entry(key editorContextKey) *editorContextEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := c.entries[key]
+	if entry == nil || entry.index != c.indexes[key.editorIndexKey] {
+		return nil
+	}
+	return entry
+}
+
+// setEntry keeps the context of key unless its index was replaced meanwhile
+func (c *editorContextCache) setEntry(key editorContextKey, entry *editorContextEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry.index != c.indexes[key.editorIndexKey] {
+		return
+	}
+	if c.entries == nil {
+		c.entries = make(map[editorContextKey]*editorContextEntry)
+	}
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= maxEditorContextEntries {
+		// Drop contexts of replaced indexes first, everything if none were
+		for k, e := range c.entries {
+			if e.index != c.indexes[k.editorIndexKey] {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxEditorContextEntries {
+			c.entries = make(map[editorContextKey]*editorContextEntry)
+		}
+	}
+	c.entries[key] = entry
+}
+
+// startRefining reports whether the caller should refine key, marking it so
+func (c *editorContextCache) startRefining(key editorContextKey) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.refining[key] {
+		return false
+	}
+	if c.refining == nil {
+		c.refining = make(map[editorContextKey]bool)
+	}
+	c.refining[key] = true
+	return true
+}
+
+// doneRefining clears the refinement mark of key
+func (c *editorContextCache) doneRefining(key editorContextKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.refining, key)
+}
+
+// EditorContext returns the memories most relevant to an editor position: a
+// file and the symbol at the cursor. It is built for editor plugins polling
+// as the cursor moves, so it answers from memory: each repository's memories
+// are loaded once and reloaded in the background, and memories are ranked by
+// keyword matches on the file and symbol. Semantic matches are searched in
+// the background and merged into the context returned for later lookups of
+// the same position.
+func (ms *MemoryServer) EditorContext(ctx context.Context, query EditorContextQuery) (*EditorContext, error) {
+	repository := strings.TrimSpace(query.Repository)
+	filePath := normalizeEditorPath(query.FilePath)
+	symbol := strings.TrimSpace(query.Symbol)
+	if repository == "" {
+		return nil, errors.New("repository is required")
+	}
+	if filePath == "" && symbol == "" {
+		return nil, errors.New("file_path or symbol is required")
+	}
+	if err := checkProjectTenant(ctx, repository); err != nil {
+		return nil, err
+	}
+	limit := defaultEditorContextLimit
+	if query.Limit > 0 {
+		limit = min(query.Limit, maxEditorContextLimit)
+	}
+
+	tenant := tenancy.FromContext(ctx)
+	indexKey := editorIndexKey{repository: repository}
+	if tenant != nil {
+		indexKey.tenant = tenant.ID
+	}
+	index, err := ms.editorIndex(ctx, indexKey)
+	if err != nil {
+		return nil, err
+	}
+
+	key := editorContextKey{editorIndexKey: indexKey, filePath: filePath, symbol: symbol}
+	result := &EditorContext{Repository: repository, FilePath: filePath, Symbol: symbol}
+	entry := ms.editorContext.entry(key)
+	if entry != nil {
+		result.Cached = true
+	} else {
+		entry = &editorContextEntry{
+			items: rankEditorContext(index, filePath, symbol),
+			index: index,
+		}
+		ms.editorContext.setEntry(key, entry)
+	}
+	if !entry.tried {
+		ms.refineEditorContext(tenant, key, entry)
+	}
+
+	result.Refined = entry.refined
+	result.Items = entry.items[:min(limit, len(entry.items))]
+	return result, nil
+}
+
+// editorIndex returns the repository's memories as the request's tenant
+// sees them, loading them on first use and reloading them in the background
+// once they are older than editorIndexTTL
+func (ms *MemoryServer) editorIndex(ctx context.Context, key editorIndexKey) (*editorIndex, error) {
+	index, reload := ms.editorContext.index(key, time.Now())
+	if index == nil {
+		loaded, err := ms.loadEditorIndex(ctx, key.repository)
+		if err != nil {
+			return nil, err
+		}
+		ms.editorContext.setIndex(key, loaded)
+		return loaded, nil
+	}
+
+	if reload {
+		// Shutdown waits for reloads in progress; the reload reads on
+		// behalf of the same tenant
+		tenant := tenancy.FromContext(ctx)
+		ms.drain.background.add()
+		go func() {
+			defer ms.drain.background.done()
+			ctx, cancel := context.WithTimeout(tenancy.WithTenant(context.Background(), tenant), editorRefineTimeout)
+			defer cancel()
+			loaded, err := ms.loadEditorIndex(ctx, key.repository)
+			if err != nil {
+				logging.Warn("Failed to reload editor context memories", "repository", key.repository, "error", err)
+			}
+			ms.editorContext.setIndex(key, loaded)
+		}()
+	}
+	return index, nil
+}
+
+// loadEditorIndex reads the repository's live memories
+func (ms *MemoryServer) loadEditorIndex(ctx context.Context, repository string) (*editorIndex, error) {
+	chunks, err := ms.liveChunks(ctx, repository, maxEditorIndexChunks)
+	if err != nil {
+		return nil, err
+	}
+	words := make([]map[string]bool, len(chunks))
+	for i := range chunks {
+		words[i] = make(map[string]bool)
+		for _, word := range strings.FieldsFunc(strings.ToLower(chunks[i].Content), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+			words[i][word] = true
+		}
+	}
+	return &editorIndex{chunks: chunks, words: words, loadedAt: time.Now()}, nil
+}
+
+// refineEditorContext searches memories semantically similar to the editor
+// position in the background, on behalf of tenant, and keeps the merged
+// context for later lookups
+func (ms *MemoryServer) refineEditorContext(tenant *tenancy.Tenant, key editorContextKey, entry *editorContextEntry) {
+	if ms.container == nil || ms.container.GetEmbeddingService() == nil || ms.container.GetVectorStore() == nil {
+		return
+	}
+	text := strings.TrimSpace(key.symbol + " " + editorPathTerms(key.filePath))
+	if text == "" || !ms.editorContext.startRefining(key) {
+		return
+	}
+
+	// Shutdown waits for refinements in progress
+	ms.drain.background.add()
+	go func() {
+		defer ms.drain.background.done()
+		defer ms.editorContext.doneRefining(key)
+		ctx, cancel := context.WithTimeout(tenancy.WithTenant(context.Background(), tenant), editorRefineTimeout)
+		defer cancel()
+
+		// Keyword matches stand when the search fails, without retrying it
+		// on every lookup
+		refined := &editorContextEntry{items: entry.items, tried: true, index: entry.index}
+		defer ms.editorContext.setEntry(key, refined)
+
+		results, err := ms.searchEditorContext(ctx, key.repository, text)
+		if err != nil {
+			logging.Warn("Failed to refine editor context", "repository", key.repository, "error", err)
+			return
+		}
+		refined.items = mergeSemanticContext(entry.items, results, key.symbol)
+		refined.refined = true
+	}()
+}
+
+// searchEditorContext searches the repository's memories similar to text
+func (ms *MemoryServer) searchEditorContext(ctx context.Context, repository, text string) ([]types.SearchResult, error) {
+	embeddings, err := ms.container.GetEmbeddingService().GenerateEmbedding(ctx, text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate embeddings: %w", err)
+	}
+	memQuery := types.MemoryQuery{Query: text, Limit: maxEditorContextLimit}
+	if repository != GlobalRepository {
+		memQuery.Repository = &repository
+	}
+	results, err := ms.container.GetVectorStore().Search(ctx, &memQuery, embeddings)
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+	return results.Results, nil
+}
+
+// rankEditorContext scores memories on keyword matches with the file and
+// symbol, returning the best maxEditorContextLimit, most recent first on ties
+func rankEditorContext(index *editorIndex, filePath, symbol string) []EditorContextItem {
+	fileName := path.Base(filePath)
+	if filePath == "" {
+		fileName = ""
+	}
+	terms := strings.Fields(editorPathTerms(filePath))
+
+	items := make([]EditorContextItem, 0)
+	for i := range index.chunks {
+		chunk := &index.chunks[i]
+		score := 0.0
+		var matched []string
+
+		fileScore := 0.0
+		for _, file := range chunk.Metadata.FilesModified {
+			file = normalizeEditorPath(file)
+			switch {
+			case sameEditorPath(file, filePath):
+				fileScore = max(fileScore, editorFileWeight)
+			case fileName != "" && path.Base(file) == fileName:
+				fileScore = max(fileScore, editorFileNameWeight)
+			}
+		}
+		if fileScore == 0 && fileName != "" && strings.Contains(chunk.Content, fileName) {
+			fileScore = editorFileNameWeight
+		}
+		if fileScore > 0 {
+			score += fileScore
+			matched = append(matched, "file")
+		}
+
+		if symbol != "" {
+			mentions := countIdentifier(chunk.Content, symbol, maxEditorSymbolCounts)
+			summary := containsIdentifier(chunk.Summary, symbol)
+			if mentions > 0 || summary {
+				score += editorSymbolWeight * float64(mentions)
+				if summary {
+					score += editorSummaryWeight
+				}
+				matched = append(matched, "symbol")
+			}
+		}
+
+		if len(terms) > 0 {
+			termScore := 0.0
+			for _, term := range terms {
+				if index.words[i][term] {
+					termScore += editorPathTermWeight
+				}
+			}
+			if termScore > 0 {
+				score += termScore
+				matched = append(matched, "keyword")
+			}
+		}
+
+		if score > 0 {
+			items = append(items, editorContextItem(chunk, score, matched, symbol+" "+fileName))
+		}
+	}
+
+	sortEditorContext(items)
+	if len(items) > maxEditorContextLimit {
+		items = items[:maxEditorContextLimit]
+	}
+	return items
+}
+
+// mergeSemanticContext adds the similarity of semantic matches to the
+// keyword matches, taking in memories only the semantic search found
+func mergeSemanticContext(items []EditorContextItem, results []types.SearchResult, symbol string) []EditorContextItem {
+	merged := make([]EditorContextItem, len(items))
+	copy(merged, items)
+	positions := make(map[string]int, len(merged))
+	for i := range merged {
+		positions[merged[i].ChunkID] = i
+	}
+
+	for i := range results {
+		chunk := &results[i].Chunk
+		if chunk.IsDeleted() || results[i].Score <= 0 {
+			continue
+		}
+		score := editorSemanticWeight * results[i].Score
+		if pos, ok := positions[chunk.ID]; ok {
+			merged[pos].Score += score
+			merged[pos].MatchedOn = append(append([]string(nil), merged[pos].MatchedOn...), "semantic")
+			continue
+		}
+		positions[chunk.ID] = len(merged)
+		merged = append(merged, editorContextItem(chunk, score, []string{"semantic"}, symbol))
+	}
+
+	sortEditorContext(merged)
+	if len(merged) > maxEditorContextLimit {
+		merged = merged[:maxEditorContextLimit]
+	}
+	return merged
+}
+
+// editorContextItem describes a matched memory
+func editorContextItem(chunk *types.ConversationChunk, score float64, matched []string, query string) EditorContextItem {
+	item := EditorContextItem{
+		ChunkID:   chunk.ID,
+		Type:      string(chunk.Type),
+		Summary:   chunk.Summary,
+		Files:     chunk.Metadata.FilesModified,
+		Timestamp: chunk.Timestamp,
+		Score:     score,
+		MatchedOn: matched,
+	}
+	if h := highlight.Compute(chunk.Content, query, 0); h != nil {
+		item.Snippet = h.Snippet
+	}
+	return item
+}
+
+// sortEditorContext orders items by score, most recent first on ties
+func sortEditorContext(items []EditorContextItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Score != items[j].Score {
+			return items[i].Score > items[j].Score
+		}
+		return items[i].Timestamp.After(items[j].Timestamp)
+	})
+}
+
+// normalizeEditorPath cleans a file path and makes its separators slashes
+func normalizeEditorPath(filePath string) string {
+	filePath = strings.TrimSpace(strings.ReplaceAll(filePath, "\\", "/"))
+	if filePath == "" {
+		return ""
+	}
+	return path.Clean(filePath)
+}
+
+// sameEditorPath reports whether two paths name the same file, allowing one
+// to be absolute or rooted elsewhere and the other relative to the repository
+func sameEditorPath(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	return a == b || strings.HasSuffix(a, "/"+b) || strings.HasSuffix(b, "/"+a)
+}
+
+// editorPathTerms returns the name of a file without its extension and the
+// name of its directory, lowercased, as search terms matched against words
+func editorPathTerms(filePath string) string {
+	if filePath == "" {
+		return ""
+	}
+	name := path.Base(filePath)
+	terms := []string{strings.ToLower(strings.TrimSuffix(name, path.Ext(name)))}
+	if dir := path.Base(path.Dir(filePath)); dir != "." && dir != "/" {
+		terms = append(terms, strings.ToLower(dir))
+	}
+	return strings.Join(terms, " ")
+}
+
+// containsIdentifier reports whether text mentions the identifier as a whole word
+func containsIdentifier(text, identifier string) bool {
+	return countIdentifier(text, identifier, 1) > 0
+}
+
+// countIdentifier counts, up to limit, the mentions of identifier in text
+// not adjoining other identifier characters
+func countIdentifier(text, identifier string, limit int) int {
+	if identifier == "" {
+		return 0
+	}
+	count := 0
+	for offset := 0; count < limit; {
+		i := strings.Index(text[offset:], identifier)
+		if i < 0 {
+			break
+		}
+		start := offset + i
+		end := start + len(identifier)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if (start == 0 || !isIdentifierRune(before)) && (end == len(text) || !isIdentifierRune(after)) {
+			count++
+		}
+		offset = end
+	}
+	return count
+}
+
+// isIdentifierRune reports whether r can be part of an identifier
+func isIdentifierRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/tenancy"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditorContext(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewKeywordStore("")
+	chunk := func(content string, metadata types.ChunkMetadata) *types.ConversationChunk {
+		c := newReportChunk(t, "session-1", content, types.ChunkTypeSolution, metadata)
+		require.NoError(t, store.Store(ctx, c))
+		return c
+	}
+	edited := chunk("Call RefreshToken before the access token expires", types.ChunkMetadata{FilesModified: []string{"internal/auth/token.go"}})
+	mentioned := chunk("RefreshToken retries twice; RefreshToken logs failures", types.ChunkMetadata{})
+	related := chunk("Auth middleware checks the bearer token", types.ChunkMetadata{})
+	chunk("Deploy with docker compose", types.ChunkMetadata{})
+	chunk("RefreshToken in the billing service", types.ChunkMetadata{Repository: "github.com/acme/billing", FilesModified: []string{"internal/auth/token.go"}})
+
+	ms := newCompositeTestServer(t, store)
+	query := EditorContextQuery{Repository: "github.com/acme/api", FilePath: "/home/dev/api/internal/auth/token.go", Symbol: "RefreshToken"}
+	result, err := ms.EditorContext(ctx, query)
+	require.NoError(t, err)
+	assert.False(t, result.Cached)
+	assert.False(t, result.Refined)
+	ids := make([]string, 0, len(result.Items))
+	for _, item := range result.Items {
+		ids = append(ids, item.ChunkID)
+	}
+	assert.Equal(t, []string{edited.ID, mentioned.ID, related.ID}, ids)
+	assert.Equal(t, []string{"file", "symbol", "keyword"}, result.Items[0].MatchedOn)
+	assert.Equal(t, []string{"symbol"}, result.Items[1].MatchedOn)
+	assert.Contains(t, result.Items[0].Snippet, "RefreshToken")
+
+	// Semantic matches are merged in the background for later lookups
+	require.NoError(t, ms.drain.background.wait(ctx))
+	query.Limit = 1
+	result, err = ms.EditorContext(ctx, query)
+	require.NoError(t, err)
+	assert.True(t, result.Cached)
+	assert.True(t, result.Refined)
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, edited.ID, result.Items[0].ChunkID)
+	assert.Contains(t, result.Items[0].MatchedOn, "semantic")
+
+	// New memories show once the index is reloaded in the background
+	added := chunk("RefreshToken RefreshToken RefreshToken", types.ChunkMetadata{FilesModified: []string{"internal/auth/token.go"}})
+	result, err = ms.EditorContext(ctx, query)
+	require.NoError(t, err)
+	assert.Equal(t, edited.ID, result.Items[0].ChunkID)
+	ms.editorContext.mu.Lock()
+	ms.editorContext.indexes[editorIndexKey{repository: "github.com/acme/api"}].loadedAt = time.Now().Add(-2 * editorIndexTTL)
+	ms.editorContext.mu.Unlock()
+	_, err = ms.EditorContext(ctx, query)
+	require.NoError(t, err)
+	require.NoError(t, ms.drain.background.wait(ctx))
+	result, err = ms.EditorContext(ctx, query)
+	require.NoError(t, err)
+	assert.False(t, result.Cached)
+	assert.Equal(t, added.ID, result.Items[0].ChunkID)
+
+	_, err = ms.EditorContext(ctx, EditorContextQuery{FilePath: "main.go"})
+	assert.Error(t, err)
+	_, err = ms.EditorContext(ctx, EditorContextQuery{Repository: "github.com/acme/api"})
+	assert.Error(t, err)
+}
+
+func TestEditorContextHeldToTheTenant(t *testing.T) {
+	store := storage.NewKeywordStore("")
+	require.NoError(t, store.Store(context.Background(), newReportChunk(t, "session-1", "RefreshToken rotates keys", types.ChunkTypeSolution, types.ChunkMetadata{})))
+	ms := newCompositeTestServer(t, store)
+	acme := tenancy.WithTenant(context.Background(), &tenancy.Tenant{ID: "api_key:acme", Projects: []string{"github.com/acme/api"}})
+	rival := tenancy.WithTenant(context.Background(), &tenancy.Tenant{ID: "api_key:rival", Projects: []string{"github.com/rival/app"}})
+	query := EditorContextQuery{Repository: "github.com/acme/api", Symbol: "RefreshToken"}
+
+	_, err := ms.EditorContext(rival, query)
+	assert.ErrorIs(t, err, tenancy.ErrCrossTenant)
+
+	result, err := ms.EditorContext(acme, query)
+	require.NoError(t, err)
+	assert.Len(t, result.Items, 1)
+	require.NoError(t, ms.drain.background.wait(context.Background()))
+	ms.editorContext.mu.Lock()
+	_, shared := ms.editorContext.indexes[editorIndexKey{repository: "github.com/acme/api"}]
+	_, owned := ms.editorContext.indexes[editorIndexKey{tenant: "api_key:acme", repository: "github.com/acme/api"}]
+	ms.editorContext.mu.Unlock()
+	assert.False(t, shared, "a tenant's index is not served to other callers")
+	assert.True(t, owned)
+}
+
+func TestCountIdentifier(t *testing.T) {
+	assert.Equal(t, 2, countIdentifier("Parse(x) calls Parse", "Parse", 3))
+	assert.Equal(t, 0, countIdentifier("ParseAll and MustParse", "Parse", 3))
+	assert.Equal(t, 1, countIdentifier("Parse Parse Parse", "Parse", 1))
+	assert.True(t, sameEditorPath("/home/dev/api/internal/auth/token.go", "internal/auth/token.go"))
+	assert.False(t, sameEditorPath("internal/auth/mytoken.go", "token.go"))
+}
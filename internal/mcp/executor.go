@@ -38,7 +38,7 @@ func (executor *MCPToolExecutor) ExecuteTool(ctx context.Context, toolName strin
 	logging.Info("MCP EXECUTOR: Sending request to server", "method", req.Method, "tool_name", toolName)
 
 	// Handle the request using our MCP server
-	response := executor.server.mcpServer.HandleRequest(ctx, req)
+	response := executor.server.HandleRequest(ctx, req)
 
 	logging.Info("MCP EXECUTOR: Received response", "has_error", response.Error != nil, "tool_name", toolName)
 
@@ -61,7 +61,7 @@ func (executor *MCPToolExecutor) ListAvailableTools() []string {
 	}
 
 	// Handle the request using our MCP server
-	response := executor.server.mcpServer.HandleRequest(context.Background(), req)
+	response := executor.server.HandleRequest(context.Background(), req)
 
 	if response.Error != nil {
 		return []string{}
@@ -69,7 +69,7 @@ func (executor *MCPToolExecutor) ListAvailableTools() []string {
 
 	// Extract tool names from response
 	if result, ok := response.Result.(map[string]interface{}); ok {
-		if toolsArray, ok := result["tools"].([]protocol.Tool); ok {
+		if toolsArray, ok := result["tools"].([]AnnotatedTool); ok {
 			toolNames := make([]string, len(toolsArray))
 			for i, tool := range toolsArray {
 				toolNames[i] = tool.Name
@@ -0,0 +1,273 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"lerian-mcp-memory/internal/highlight"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/pkg/types"
+)
+
+const (
+	// OperationSearchFederated searches several repositories at once
+	OperationSearchFederated = "search_federated"
+
+	// maxFederatedRepositories bounds how many repositories one search may span
+	maxFederatedRepositories = 20
+
+	defaultFederatedLimit = 10
+	maxFederatedLimit     = 50
+
+	defaultProjectQuota = 5
+	maxProjectQuota     = 20
+
+	// defaultDiversityDecay discounts each further hit from the same
+	// repository, so one busy project cannot crowd out the others
+	defaultDiversityDecay = 0.85
+)
+
+// federatedSearchConfig holds the parameters of a federated search
+type federatedSearchConfig struct {
+	Query          string
+	Repositories   []string
+	Excluded       []string
+	Limit          int
+	ProjectQuota   int
+	DiversityDecay float64
+	MinRelevance   float64
+	Types          []types.ChunkType
+	Highlight      bool
+}
+
+// federatedProject is the outcome of the search in one repository
+type federatedProject struct {
+	Repository string `json:"repository"`
+	Matched    int    `json:"matched"`
+	Returned   int    `json:"returned"`
+	Error      string `json:"error,omitempty"`
+
+	results []types.SearchResult
+}
+
+// federatedHit is a merged search result labeled with its source repository
+type federatedHit struct {
+	Source         string                  `json:"source"`
+	SourceRank     int                     `json:"source_rank"`
+	Score          float64                 `json:"score"`
+	FederatedScore float64                 `json:"federated_score"`
+	Chunk          types.ConversationChunk `json:"chunk"`
+	Highlight      *types.SearchHighlight  `json:"highlight,omitempty"`
+}
+
+// handleFederatedSearch searches the caller's repository together with the
+// other repositories it lists, then merges and reranks the hits. Each
+// repository is searched in isolation and contributes at most its quota, and
+// repositories disabled in configuration are never searched.
+func (ms *MemoryServer) handleFederatedSearch(ctx context.Context, params map[string]interface{}, repository string) (interface{}, error) {
+	logging.Info("MCP TOOL: memory_read search_federated called", "params", params, "repository", repository)
+
+	cfg, err := ms.parseFederatedSearchParams(params, repository)
+	if err != nil {
+		return nil, err
+	}
+
+	embeddings, err := ms.container.GetEmbeddingService().GenerateEmbedding(ctx, cfg.Query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate embeddings: %w", err)
+	}
+
+	start := time.Now()
+	projects := ms.searchProjects(ctx, cfg, embeddings)
+	hits := mergeFederatedResults(projects, cfg)
+
+	retrieved := make(map[string][]string, len(projects))
+	for i := range hits {
+		retrieved[hits[i].Source] = append(retrieved[hits[i].Source], hits[i].Chunk.ID)
+	}
+	for repo, ids := range retrieved {
+		ms.recordRetrieved(params, repo, ids...)
+	}
+
+	failed := 0
+	for i := range projects {
+		if projects[i].Error != "" {
+			failed++
+		}
+	}
+	if failed == len(projects) {
+		return nil, fmt.Errorf("federated search failed in every repository: %s", projects[0].Error)
+	}
+
+	return map[string]interface{}{
+		"status":       "success",
+		"operation":    OperationSearchFederated,
+		"query":        cfg.Query,
+		"repositories": cfg.Repositories,
+		"excluded":     cfg.Excluded,
+		"projects":     projects,
+		"results":      hits,
+		"total":        len(hits),
+		"query_time":   time.Since(start).Milliseconds(),
+		"partial":      failed > 0,
+	}, nil
+}
+
+// parseFederatedSearchParams validates the query and resolves the repositories to search
+func (ms *MemoryServer) parseFederatedSearchParams(params map[string]interface{}, repository string) (*federatedSearchConfig, error) {
+	query, ok := params["query"].(string)
+	if !ok || query == "" {
+		return nil, errors.New("query parameter is required for search_federated. Example: {\"query\": \"retry backoff\", \"repository\": \"github.com/user/api\", \"repositories\": [\"github.com/user/web\"]}")
+	}
+
+	cfg := &federatedSearchConfig{
+		Query:          query,
+		Limit:          defaultFederatedLimit,
+		ProjectQuota:   defaultProjectQuota,
+		DiversityDecay: defaultDiversityDecay,
+		MinRelevance:   0.5,
+		Highlight:      true,
+	}
+
+	requested := []string{repository}
+	if repos, ok := params["repositories"].([]interface{}); ok {
+		for _, r := range repos {
+			if repo, ok := r.(string); ok && repo != "" {
+				requested = append(requested, repo)
+			}
+		}
+	}
+
+	seen := make(map[string]bool, len(requested))
+	for _, repo := range requested {
+		if seen[repo] {
+			continue
+		}
+		seen[repo] = true
+		if ms.container.Config != nil && !ms.container.Config.IsRepositoryEnabled(repo) {
+			cfg.Excluded = append(cfg.Excluded, repo)
+			continue
+		}
+		cfg.Repositories = append(cfg.Repositories, repo)
+	}
+	if len(cfg.Repositories) == 0 {
+		return nil, errors.New("none of the requested repositories are enabled for search")
+	}
+	if len(cfg.Repositories) > maxFederatedRepositories {
+		return nil, fmt.Errorf("search_federated spans at most %d repositories, got %d", maxFederatedRepositories, len(cfg.Repositories))
+	}
+
+	if limit, ok := params["limit"].(float64); ok && limit > 0 {
+		cfg.Limit = min(int(limit), maxFederatedLimit)
+	}
+	if quota, ok := params["per_project_limit"].(float64); ok && quota > 0 {
+		cfg.ProjectQuota = min(int(quota), maxProjectQuota)
+	}
+	if decay, ok := params["diversity_decay"].(float64); ok && decay > 0 && decay <= 1 {
+		cfg.DiversityDecay = decay
+	}
+	if minRelevance, ok := params["min_relevance"].(float64); ok && minRelevance > 0 {
+		cfg.MinRelevance = minRelevance
+	}
+	if typesInterface, ok := params["types"].([]interface{}); ok {
+		for _, t := range typesInterface {
+			if typeStr, ok := t.(string); ok {
+				cfg.Types = append(cfg.Types, types.ChunkType(typeStr))
+			}
+		}
+	}
+	if withHighlights, ok := params["highlight"].(bool); ok {
+		cfg.Highlight = withHighlights
+	}
+
+	return cfg, nil
+}
+
+// searchProjects runs the repository-scoped searches concurrently. A failing
+// repository is reported in its project entry instead of failing the search.
+func (ms *MemoryServer) searchProjects(ctx context.Context, cfg *federatedSearchConfig, embeddings []float64) []federatedProject {
+	vectorStore := ms.container.GetVectorStore()
+	projects := make([]federatedProject, len(cfg.Repositories))
+
+	var wg sync.WaitGroup
+	for i, repo := range cfg.Repositories {
+		wg.Add(1)
+		go func(i int, repo string) {
+			defer wg.Done()
+			project := federatedProject{Repository: repo}
+
+			query := &types.MemoryQuery{
+				Query:             cfg.Query,
+				Repository:        &repo,
+				Types:             cfg.Types,
+				Limit:             cfg.ProjectQuota,
+				MinRelevanceScore: cfg.MinRelevance,
+			}
+			results, err := vectorStore.Search(ctx, query, embeddings)
+			if err != nil {
+				logging.Warn("Federated search failed for repository", "repository", repo, "error", err)
+				project.Error = err.Error()
+			} else {
+				project.Matched = results.Total
+				project.results = results.Results
+				if len(project.results) > cfg.ProjectQuota {
+					project.results = project.results[:cfg.ProjectQuota]
+				}
+			}
+			projects[i] = project
+		}(i, repo)
+	}
+	wg.Wait()
+
+	return projects
+}
+
+// mergeFederatedResults reranks the hits of all projects. A hit's federated
+// score is its relevance discounted by the diversity decay for every better
+// hit from the same repository, then the best hits overall are kept.
+func mergeFederatedResults(projects []federatedProject, cfg *federatedSearchConfig) []federatedHit {
+	var hits []federatedHit
+	for i := range projects {
+		results := projects[i].results
+		sort.SliceStable(results, func(a, b int) bool {
+			return results[a].Score > results[b].Score
+		})
+		for rank := range results {
+			hit := federatedHit{
+				Source:         projects[i].Repository,
+				SourceRank:     rank + 1,
+				Score:          results[rank].Score,
+				FederatedScore: results[rank].Score * math.Pow(cfg.DiversityDecay, float64(rank)),
+				Chunk:          results[rank].Chunk,
+			}
+			if cfg.Highlight {
+				hit.Highlight = highlight.Compute(hit.Chunk.Content, cfg.Query, highlight.DefaultContextSentences)
+			}
+			hits = append(hits, hit)
+		}
+	}
+
+	sort.SliceStable(hits, func(a, b int) bool {
+		if hits[a].FederatedScore != hits[b].FederatedScore {
+			return hits[a].FederatedScore > hits[b].FederatedScore
+		}
+		return hits[a].Source < hits[b].Source
+	})
+	if len(hits) > cfg.Limit {
+		hits = hits[:cfg.Limit]
+	}
+
+	returned := make(map[string]int, len(projects))
+	for i := range hits {
+		returned[hits[i].Source]++
+	}
+	for i := range projects {
+		projects[i].Returned = returned[projects[i].Repository]
+	}
+	return hits
+}
@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFederatedTestServer(t *testing.T) *MemoryServer {
+	t.Helper()
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+
+	chunks := map[string]int{
+		"github.com/acme/api":     4,
+		"github.com/acme/web":     1,
+		"github.com/acme/secrets": 2,
+	}
+	for repo, n := range chunks {
+		for i := 0; i < n; i++ {
+			chunk := newReportChunk(t, "session-1", "Retry with exponential backoff on timeouts", types.ChunkTypeSolution, types.ChunkMetadata{Repository: repo})
+			require.NoError(t, store.Store(ctx, chunk))
+		}
+	}
+
+	ms := newCompositeTestServer(t, store)
+	ms.container.Config = &config.Config{}
+	ms.container.Config.SetRepoConfig("github.com/acme/secrets", config.RepoConfig{Enabled: false})
+	return ms
+}
+
+func TestFederatedSearchMergesRepositoriesWithQuotas(t *testing.T) {
+	ms := newFederatedTestServer(t)
+
+	result, err := ms.handleFederatedSearch(context.Background(), map[string]interface{}{
+		"query":             "backoff",
+		"repositories":      []interface{}{"github.com/acme/web", "github.com/acme/secrets", "github.com/acme/api"},
+		"per_project_limit": float64(3),
+	}, "github.com/acme/api")
+	require.NoError(t, err)
+
+	response := result.(map[string]interface{})
+	assert.Equal(t, []string{"github.com/acme/api", "github.com/acme/web"}, response["repositories"])
+	assert.Equal(t, []string{"github.com/acme/secrets"}, response["excluded"])
+
+	hits := response["results"].([]federatedHit)
+	require.Len(t, hits, 4)
+	// The web hit outranks the second api hit thanks to the diversity decay
+	assert.Equal(t, "github.com/acme/api", hits[0].Source)
+	assert.Equal(t, "github.com/acme/web", hits[1].Source)
+	for i, hit := range hits {
+		assert.NotEqual(t, "github.com/acme/secrets", hit.Source)
+		assert.Equal(t, hit.Source, hit.Chunk.Metadata.Repository)
+		assert.NotNil(t, hit.Highlight)
+		if i > 0 {
+			assert.LessOrEqual(t, hit.FederatedScore, hits[i-1].FederatedScore)
+		}
+	}
+
+	projects := response["projects"].([]federatedProject)
+	require.Len(t, projects, 2)
+	assert.Equal(t, 3, projects[0].Returned)
+	assert.Equal(t, 1, projects[1].Returned)
+}
+
+func TestFederatedSearchLimit(t *testing.T) {
+	ms := newFederatedTestServer(t)
+
+	result, err := ms.handleFederatedSearch(context.Background(), map[string]interface{}{
+		"query":        "backoff",
+		"repositories": []interface{}{"github.com/acme/web"},
+		"limit":        float64(2),
+	}, "github.com/acme/api")
+	require.NoError(t, err)
+
+	response := result.(map[string]interface{})
+	assert.Equal(t, 2, response["total"])
+	assert.Equal(t, false, response["partial"])
+}
+
+func TestFederatedSearchValidation(t *testing.T) {
+	ms := newFederatedTestServer(t)
+	ctx := context.Background()
+
+	_, err := ms.handleFederatedSearch(ctx, map[string]interface{}{}, "github.com/acme/api")
+	assert.ErrorContains(t, err, "query parameter is required")
+
+	_, err = ms.handleFederatedSearch(ctx, map[string]interface{}{"query": "backoff"}, "github.com/acme/secrets")
+	assert.ErrorContains(t, err, "none of the requested repositories")
+
+	repos := make([]interface{}, 0, maxFederatedRepositories)
+	for i := 0; i < maxFederatedRepositories; i++ {
+		repos = append(repos, "github.com/acme/repo-"+string(rune('a'+i)))
+	}
+	_, err = ms.handleFederatedSearch(ctx, map[string]interface{}{"query": "backoff", "repositories": repos}, "github.com/acme/api")
+	assert.ErrorContains(t, err, "at most")
+}
@@ -0,0 +1,115 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/replay"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+// updateGolden rewrites the recorded sessions that no longer match with the
+// current server's responses:
+//
+//	go test ./internal/mcp -run TestGoldenSessions -update
+var updateGolden = flag.Bool("update", false, "rewrite golden sessions with the replayed responses")
+
+// goldenSessions holds sessions recorded with cmd/mcp-record against a server
+// using fake embeddings and an in-memory local store
+const goldenSessions = "testdata/sessions"
+
+// newGoldenServer starts a server configured as the sessions were recorded
+func newGoldenServer(t *testing.T) *MemoryServer {
+	t.Helper()
+	t.Setenv("MCP_MEMORY_USE_CONSOLIDATED_TOOLS", "true")
+	t.Setenv("MCP_MEMORY_USE_BACKWARD_COMPATIBILITY", "false")
+	t.Setenv("MCP_MEMORY_AUDIT_DIRECTORY", t.TempDir())
+
+	cfg := config.DefaultConfig()
+	cfg.Embedding.Provider = config.EmbeddingProviderFake
+	cfg.Storage.Provider = config.StorageProviderLocal
+	cfg.Storage.LocalPath = ""
+
+	ms, err := NewMemoryServer(cfg)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, ms.Start(ctx))
+	t.Cleanup(func() {
+		cancel()
+		_ = ms.Close()
+	})
+	return ms
+}
+
+// goldenHandler serves one recorded message through the server's middleware chain
+func goldenHandler(ms *MemoryServer) replay.Handler {
+	return func(raw json.RawMessage) (json.RawMessage, error) {
+		var req protocol.JSONRPCRequest
+		if err := json.Unmarshal(raw, &req); err != nil {
+			return nil, err
+		}
+		resp := ms.HandleRequest(context.Background(), &req)
+		if resp == nil || req.ID == nil {
+			return nil, replay.ErrNoResponse
+		}
+		return json.Marshal(resp)
+	}
+}
+
+// TestGoldenSessions replays every recorded session against a fresh server
+// and fails on any response that departs from its recording
+func TestGoldenSessions(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join(goldenSessions, "*.jsonl"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths, "no recorded sessions in %s", goldenSessions)
+
+	for _, path := range paths {
+		t.Run(strings.TrimSuffix(filepath.Base(path), ".jsonl"), func(t *testing.T) {
+			exchanges, err := replay.LoadSession(path)
+			require.NoError(t, err)
+
+			differ := replay.NewDiffer(replay.DefaultVolatileFields...)
+			results, err := replay.Replay(exchanges, goldenHandler(newGoldenServer(t)), differ)
+			require.NoError(t, err)
+
+			if *updateGolden {
+				if !hasDifferences(results) {
+					return
+				}
+				for i := range results {
+					exchanges[i].Request = differ.Prepare(exchanges[i].Request)
+					exchanges[i].Response = results[i].Response
+				}
+				var buf bytes.Buffer
+				require.NoError(t, replay.WriteSession(&buf, exchanges))
+				require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+				return
+			}
+
+			for _, result := range results {
+				for _, diff := range result.Differences {
+					t.Errorf("exchange %d (%s) %s", result.Index+1, result.Method, diff)
+				}
+			}
+		})
+	}
+}
+
+// hasDifferences reports whether any replayed response departed from its recording
+func hasDifferences(results []replay.Result) bool {
+	for i := range results {
+		if len(results[i].Differences) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"lerian-mcp-memory/internal/graph"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/fredcamaral/gomcp-sdk"
+)
+
+// registerGraphQueryTool registers memory_graph_query
+func (ms *MemoryServer) registerGraphQueryTool() {
+	ms.addTool(mcp.NewTool(
+		"memory_graph_query",
+		"Query the knowledge graph of memory relationships. Starting from a chunk, follows relationships of the given types in one direction or both, breadth-first (bfs) or depth-first (dfs), up to a depth. Returns the reached nodes and edges ready for visualization, and the path to each node scored by the product of its relationships' confidences, best first.",
+		mcp.ObjectSchema("Graph query parameters", map[string]interface{}{
+			"start_chunk_id": map[string]interface{}{
+				"type":        "string",
+				"description": "Chunk ID to start from (required)",
+			},
+			"relation_types": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Only follow relationships of these types, e.g. ['led_to', 'solved_by']. Use memory_read list_relation_types for the valid options. Default: all",
+			},
+			"direction": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{string(graph.DirectionOutgoing), string(graph.DirectionIncoming), string(graph.DirectionBoth)},
+				"default":     string(graph.DirectionOutgoing),
+				"description": "Follow relationships from source to target (outgoing), back from target to source (incoming), or both",
+			},
+			"strategy": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{string(graph.StrategyBFS), string(graph.StrategyDFS)},
+				"default":     string(graph.StrategyBFS),
+				"description": "bfs visits level by level and reaches each node by its best shortest path; dfs follows the most confident relationships as deep as it can first",
+			},
+			"max_depth": map[string]interface{}{
+				"type":        "integer",
+				"default":     graph.DefaultMaxDepth,
+				"description": fmt.Sprintf("Relationships to follow from the start at most (1-%d)", graph.MaxDepth),
+			},
+			"min_confidence": map[string]interface{}{
+				"type":        "number",
+				"default":     graph.DefaultMinConfidence,
+				"description": "Ignore relationships less confident than this (0-1)",
+			},
+			"repository": map[string]interface{}{
+				"type":        "string",
+				"description": "Only visit memories of this repository",
+			},
+			"max_nodes": map[string]interface{}{
+				"type":        "integer",
+				"default":     graph.DefaultMaxNodes,
+				"description": fmt.Sprintf("Stop after reaching this many nodes (max %d); the result is marked truncated", graph.MaxNodes),
+			},
+			"max_paths": map[string]interface{}{
+				"type":        "integer",
+				"default":     graph.DefaultMaxPaths,
+				"description": "Number of best scoring paths to return",
+			},
+		}, []string{"start_chunk_id"}),
+	), mcp.ToolHandlerFunc(ms.handleGraphQuery))
+}
+
+// handleGraphQuery traverses the relationship graph from a chunk
+func (ms *MemoryServer) handleGraphQuery(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: memory_graph_query called", "args", args)
+
+	query, err := graphQuery(args)
+	if err != nil {
+		return nil, err
+	}
+	result, err := graph.Traverse(ctx, ms.container.GetVectorStore(), query)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"status":    "success",
+		"query":     result.Query,
+		"nodes":     result.Nodes,
+		"edges":     result.Edges,
+		"paths":     result.Paths,
+		"truncated": result.Truncated,
+		"summary": map[string]interface{}{
+			"total_nodes": len(result.Nodes),
+			"total_edges": len(result.Edges),
+		},
+	}, nil
+}
+
+// graphQuery reads a graph query from tool arguments
+func graphQuery(args map[string]interface{}) (graph.Query, error) {
+	query := graph.Query{MinConfidence: graph.DefaultMinConfidence}
+	query.Start, _ = args["start_chunk_id"].(string)
+	if query.Start == "" {
+		return query, errors.New("start_chunk_id parameter is required. Example: {\"start_chunk_id\": \"chunk-id\", \"relation_types\": [\"led_to\", \"solved_by\"], \"direction\": \"both\", \"max_depth\": 3}")
+	}
+
+	if rawTypes, ok := args["relation_types"].([]interface{}); ok {
+		for _, raw := range rawTypes {
+			name, _ := raw.(string)
+			relationType := types.RelationType(name)
+			if !relationType.WellFormed() {
+				return query, fmt.Errorf("invalid relation type %q. Use memory_read list_relation_types for the valid options", name)
+			}
+			query.RelationTypes = append(query.RelationTypes, relationType)
+		}
+	}
+	if direction, ok := args["direction"].(string); ok {
+		query.Direction = graph.Direction(direction)
+	}
+	if strategy, ok := args["strategy"].(string); ok {
+		query.Strategy = graph.Strategy(strategy)
+	}
+	if depth, ok := args["max_depth"].(float64); ok {
+		query.MaxDepth = int(depth)
+	}
+	if confidence, ok := args["min_confidence"].(float64); ok {
+		query.MinConfidence = confidence
+	}
+	query.Repository, _ = args["repository"].(string)
+	if nodes, ok := args["max_nodes"].(float64); ok {
+		query.MaxNodes = int(nodes)
+	}
+	if paths, ok := args["max_paths"].(float64); ok {
+		query.MaxPaths = int(paths)
+	}
+	return query, query.Normalize()
+}
@@ -0,0 +1,57 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/graph"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleGraphQuery(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewLocalVectorStore("")
+	problem := newReportChunk(t, "s1", "Checkout times out", types.ChunkTypeProblem, types.ChunkMetadata{})
+	solution := newReportChunk(t, "s1", "Raise the provider timeout", types.ChunkTypeSolution, types.ChunkMetadata{})
+	review := newReportChunk(t, "s1", "Incident review", types.ChunkTypeDiscussion, types.ChunkMetadata{})
+	for _, chunk := range []*types.ConversationChunk{problem, solution, review} {
+		require.NoError(t, store.Store(ctx, chunk))
+	}
+	_, err := store.StoreRelationship(ctx, problem.ID, solution.ID, types.RelationSolvedBy, 0.9, types.ConfidenceExplicit)
+	require.NoError(t, err)
+	_, err = store.StoreRelationship(ctx, review.ID, problem.ID, types.RelationReferences, 0.8, types.ConfidenceExplicit)
+	require.NoError(t, err)
+	ms := newCompositeTestServer(t, store)
+
+	_, err = ms.handleGraphQuery(ctx, map[string]interface{}{})
+	assert.ErrorContains(t, err, "start_chunk_id parameter is required")
+	_, err = ms.handleGraphQuery(ctx, map[string]interface{}{"start_chunk_id": problem.ID, "relation_types": []interface{}{"Solved By"}})
+	assert.ErrorContains(t, err, "invalid relation type")
+	_, err = ms.handleGraphQuery(ctx, map[string]interface{}{"start_chunk_id": problem.ID, "strategy": "random"})
+	assert.ErrorContains(t, err, "invalid strategy")
+
+	result, err := ms.handleGraphQuery(ctx, map[string]interface{}{
+		"start_chunk_id": problem.ID,
+		"direction":      "both",
+		"strategy":       "dfs",
+		"relation_types": []interface{}{"solved_by", "references"},
+		"max_depth":      float64(3),
+	})
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	nodes := response["nodes"].([]graph.Node)
+	require.Len(t, nodes, 3)
+	assert.Equal(t, problem.ID, nodes[0].ID)
+	assert.Equal(t, solution.ID, nodes[1].ID, "the most confident relationship is followed first")
+	assert.Len(t, response["edges"], 2)
+	assert.Equal(t, graph.StrategyDFS, response["query"].(graph.Query).Strategy)
+
+	paths := response["paths"].([]graph.Path)
+	require.Len(t, paths, 2)
+	assert.Equal(t, []types.RelationType{types.RelationSolvedBy}, paths[0].RelationTypes)
+	assert.InDelta(t, 0.9, paths[0].Score, 1e-9)
+}
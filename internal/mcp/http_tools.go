@@ -0,0 +1,53 @@
+package mcp
+
+import (
+	"time"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/httptools"
+	"lerian-mcp-memory/internal/logging"
+
+	"github.com/fredcamaral/gomcp-sdk"
+)
+
+// httpToolRegistrar registers the tools of an HTTP tools file on the server
+type httpToolRegistrar struct {
+	ms *MemoryServer
+}
+
+// HasTool reports whether a tool of that name is registered
+func (r httpToolRegistrar) HasTool(name string) bool {
+	r.ms.toolsMu.RLock()
+	defer r.ms.toolsMu.RUnlock()
+	return r.ms.toolNames[name]
+}
+
+// AddHTTPTool registers an HTTP tool. Tools that only read are annotated
+// read-only, and all of them as reaching outside the server.
+func (r httpToolRegistrar) AddHTTPTool(tool *httptools.Tool) {
+	annotations := openWorld(toolHints(tool.Spec.ReadOnly(), false, tool.Spec.ReadOnly()))
+	annotations.Title = tool.Spec.Name
+	r.ms.RegisterTool(
+		mcp.NewTool(tool.Spec.Name, tool.Spec.Description, tool.Spec.Schema()),
+		mcp.ToolHandlerFunc(tool.Call),
+		annotations,
+	)
+}
+
+// RemoveHTTPTool withdraws an HTTP tool
+func (r httpToolRegistrar) RemoveHTTPTool(name string) {
+	r.ms.RemoveTool(name)
+}
+
+// initHTTPTools registers the tools of the configured HTTP tools file
+func (ms *MemoryServer) initHTTPTools(cfg *config.Config) {
+	if cfg.HTTPTools.File == "" {
+		return
+	}
+
+	ms.httpTools = httptools.NewRegistry(cfg.HTTPTools.File, httpToolRegistrar{ms: ms})
+	ms.httpTools.SetInterval(time.Duration(cfg.HTTPTools.ReloadSeconds) * time.Second)
+	if err := ms.httpTools.Reload(); err != nil {
+		logging.Error("Failed to load http tools", "file", cfg.HTTPTools.File, "error", err)
+	}
+}
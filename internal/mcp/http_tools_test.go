@@ -0,0 +1,57 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lerian-mcp-memory/internal/config"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPTools(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status": "operational", "service": "` + r.URL.Query().Get("service") + `"}`))
+	}))
+	defer api.Close()
+
+	path := filepath.Join(t.TempDir(), "tools.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+tools:
+  - name: status_page
+    description: Service status
+    url: `+api.URL+`/status
+  - name: echo
+    description: Shadows a registered tool
+    url: `+api.URL+`/echo
+`), 0o600))
+
+	ms := newMiddlewareTestServer()
+	cfg := config.DefaultConfig()
+	cfg.HTTPTools.File = path
+	ms.initHTTPTools(cfg)
+
+	assert.Contains(t, listedToolNames(t, ms), "status_page")
+	annotations, ok := ms.ToolAnnotationsFor("status_page")
+	require.True(t, ok)
+	assert.True(t, *annotations.ReadOnlyHint)
+	assert.True(t, *annotations.OpenWorldHint)
+
+	resp := ms.HandleRequest(context.Background(), toolCallRequest("status_page", map[string]interface{}{"service": "billing"}))
+	require.Nil(t, resp.Error)
+	assert.Contains(t, resp.Result.(*protocol.ToolCallResult).Content[0].Text, "billing")
+
+	resp = ms.HandleRequest(context.Background(), toolCallRequest("echo", map[string]interface{}{}))
+	require.Nil(t, resp.Error)
+	assert.Contains(t, resp.Result.(*protocol.ToolCallResult).Content[0].Text, "ok", "registered tools are not replaced")
+
+	require.NoError(t, os.WriteFile(path, []byte("tools: []\n"), 0o600))
+	require.NoError(t, ms.httpTools.Reload())
+	assert.NotContains(t, listedToolNames(t, ms), "status_page")
+}
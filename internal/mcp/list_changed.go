@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"log"
+
+	"github.com/fredcamaral/gomcp-sdk/notifications"
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+// RegisterTool adds or replaces a tool, optionally with annotations that
+// describe its behavior to clients; without them a built-in tool keeps its
+// default annotations. Once the server has started, connected clients are
+// told the tool list changed.
+func (ms *MemoryServer) RegisterTool(tool protocol.Tool, handler protocol.ToolHandler, annotations ...ToolAnnotations) {
+	ms.addTool(tool, handler)
+	ms.setToolAnnotations(tool.Name, annotations...)
+	if ms.started.Load() {
+		ms.NotifyToolsChanged()
+	}
+}
+
+// RemoveTool withdraws a tool so it is no longer listed or callable, and
+// reports whether it was registered. Once the server has started, connected
+// clients are told the tool list changed.
+func (ms *MemoryServer) RemoveTool(name string) bool {
+	ms.toolsMu.Lock()
+	registered := ms.toolNames[name]
+	if registered {
+		delete(ms.toolNames, name)
+		if ms.removedTools == nil {
+			ms.removedTools = make(map[string]bool)
+		}
+		ms.removedTools[name] = true
+	}
+	ms.toolsMu.Unlock()
+
+	if registered && ms.started.Load() {
+		ms.NotifyToolsChanged()
+	}
+	return registered
+}
+
+// NotifyToolsChanged sends notifications/tools/list_changed to connected clients
+func (ms *MemoryServer) NotifyToolsChanged() {
+	ms.broadcastNotification(notifications.NotificationToolsListChanged, &notifications.ListChangedParams{})
+}
+
+// NotifyResourcesChanged sends notifications/resources/list_changed to connected clients
+func (ms *MemoryServer) NotifyResourcesChanged() {
+	ms.broadcastNotification(notifications.NotificationResourcesListChanged, &notifications.ListChangedParams{})
+}
+
+// AddNotificationSender adds a connection that receives notifications sent
+// to every client. Transports with one long-lived client, such as stdio,
+// register it here; SSE sessions and WebSocket clients are reached through
+// the notifier and the WebSocket hub.
+func (ms *MemoryServer) AddNotificationSender(sender NotificationSender) {
+	ms.toolsMu.Lock()
+	defer ms.toolsMu.Unlock()
+	ms.broadcastSenders = append(ms.broadcastSenders, sender)
+}
+
+// broadcastNotification delivers a notification to every connected client
+func (ms *MemoryServer) broadcastNotification(method string, params interface{}) {
+	if ms.notifier != nil {
+		if err := ms.notifier.Broadcast(method, params); err != nil {
+			log.Printf("Warning: Failed to broadcast %s: %v", method, err)
+		}
+	}
+
+	if ms.wsHub != nil {
+		ms.wsHub.BroadcastNotification(method, params)
+	}
+
+	ms.toolsMu.RLock()
+	senders := ms.broadcastSenders
+	ms.toolsMu.RUnlock()
+	for _, sender := range senders {
+		if err := sender(method, params); err != nil {
+			log.Printf("Warning: Failed to send %s: %v", method, err)
+		}
+	}
+}
+
+// trackTool records a registered tool, reinstating it if it was removed
+func (ms *MemoryServer) trackTool(name string) {
+	ms.toolsMu.Lock()
+	defer ms.toolsMu.Unlock()
+	if ms.toolNames == nil {
+		ms.toolNames = make(map[string]bool)
+	}
+	ms.toolNames[name] = true
+	delete(ms.removedTools, name)
+}
+
+// isToolRemoved reports whether a tool was withdrawn with RemoveTool
+func (ms *MemoryServer) isToolRemoved(name string) bool {
+	ms.toolsMu.RLock()
+	defer ms.toolsMu.RUnlock()
+	return ms.removedTools[name]
+}
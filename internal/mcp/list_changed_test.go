@@ -0,0 +1,111 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/di"
+
+	mcp "github.com/fredcamaral/gomcp-sdk"
+	"github.com/fredcamaral/gomcp-sdk/notifications"
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listedToolNames(t *testing.T, ms *MemoryServer) []string {
+	t.Helper()
+	resp := ms.HandleRequest(context.Background(), &protocol.JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list"})
+	require.Nil(t, resp.Error)
+	tools := resp.Result.(map[string]interface{})["tools"].([]AnnotatedTool)
+	names := make([]string, 0, len(tools))
+	for i := range tools {
+		names = append(names, tools[i].Name)
+	}
+	return names
+}
+
+func TestRegisterAndRemoveToolNotify(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	var methods []string
+	ms.AddNotificationSender(func(method string, _ interface{}) error {
+		methods = append(methods, method)
+		return nil
+	})
+
+	tool := mcp.NewTool("late", "Late", mcp.ObjectSchema("Late", map[string]interface{}{}, nil))
+	handler := mcp.ToolHandlerFunc(func(context.Context, map[string]interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+
+	// Registration before startup is silent
+	ms.RegisterTool(tool, handler)
+	assert.Empty(t, methods)
+
+	ms.started.Store(true)
+	assert.True(t, ms.RemoveTool("late"))
+	assert.Equal(t, []string{notifications.NotificationToolsListChanged}, methods)
+	assert.NotContains(t, listedToolNames(t, ms), "late")
+	resp := ms.HandleRequest(context.Background(), toolCallRequest("late", nil))
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, protocol.MethodNotFound, resp.Error.Code)
+
+	// Removing an unknown tool changes nothing
+	assert.False(t, ms.RemoveTool("late"))
+	assert.Len(t, methods, 1)
+
+	ms.RegisterTool(tool, handler)
+	assert.Len(t, methods, 2)
+	assert.Contains(t, listedToolNames(t, ms), "late")
+	resp = ms.HandleRequest(context.Background(), toolCallRequest("late", nil))
+	assert.Nil(t, resp.Error)
+
+	ms.NotifyResourcesChanged()
+	assert.Equal(t, notifications.NotificationResourcesListChanged, methods[2])
+}
+
+func TestInitializeAdvertisesListChanged(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	resp := ms.HandleRequest(context.Background(), &protocol.JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "initialize",
+		Params: map[string]interface{}{
+			"protocolVersion": protocol.Version,
+			"clientInfo":      map[string]interface{}{"name": "test", "version": "1.0.0"},
+		},
+	})
+	require.Nil(t, resp.Error)
+	result := resp.Result.(protocol.InitializeResult)
+	assert.True(t, result.Capabilities.Tools.ListChanged)
+	assert.True(t, result.Capabilities.Resources.ListChanged)
+	assert.True(t, result.Capabilities.Resources.Subscribe)
+}
+
+func TestDisabledCapabilities(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	cfg := config.DefaultConfig()
+	cfg.Server.Capabilities = config.CapabilitiesConfig{Resources: true, Prompts: false}
+	ms.container = &di.Container{Config: cfg}
+
+	resp := ms.HandleRequest(context.Background(), &protocol.JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "initialize",
+		Params: map[string]interface{}{
+			"protocolVersion": protocol.Version,
+			"clientInfo":      map[string]interface{}{"name": "test", "version": "1.0.0"},
+		},
+	})
+	require.Nil(t, resp.Error)
+	result := resp.Result.(protocol.InitializeResult)
+	assert.Nil(t, result.Capabilities.Prompts, "disabled capabilities are not advertised")
+	assert.NotNil(t, result.Capabilities.Resources)
+
+	resp = ms.HandleRequest(context.Background(), &protocol.JSONRPCRequest{JSONRPC: "2.0", ID: 2, Method: "prompts/list"})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, protocol.MethodNotFound, resp.Error.Code)
+	resp = ms.HandleRequest(context.Background(), &protocol.JSONRPCRequest{JSONRPC: "2.0", ID: 3, Method: "resources/list"})
+	assert.Nil(t, resp.Error)
+}
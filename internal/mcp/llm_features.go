@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"lerian-mcp-memory/internal/intelligence"
+	"lerian-mcp-memory/internal/llm"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/pkg/types"
+)
+
+// maxNarrativeChunks bounds the memories described to the LLM for narrative insights
+const maxNarrativeChunks = 30
+
+// llmRouter returns the container's LLM router, or nil when none is configured
+func (ms *MemoryServer) llmRouter() *llm.Router {
+	if ms.container == nil {
+		return nil
+	}
+	return ms.container.GetLLMRouter()
+}
+
+// aiEnrichmentEnabled reports whether new chunks are enriched by the summarization LLM
+func (ms *MemoryServer) aiEnrichmentEnabled() bool {
+	cfg := ms.container.Config
+	return cfg != nil && cfg.LLM.EnrichmentEnabled && ms.llmRouter().For(llm.FeatureSummarization) != nil
+}
+
+// newConflictDetector creates a conflict detector with LLM verification when configured
+func (ms *MemoryServer) newConflictDetector() *intelligence.ConflictDetector {
+	detector := intelligence.NewConflictDetector()
+	detector.SetVerifier(ms.llmRouter().For(llm.FeatureConflictVerification))
+	return detector
+}
+
+// addNarrativeInsights asks the insights LLM for a short narrative of recent activity
+func (ms *MemoryServer) addNarrativeInsights(ctx context.Context, chunks []types.ConversationChunk, insights map[string]interface{}) {
+	provider := ms.llmRouter().For(llm.FeatureInsights)
+	if provider == nil || len(chunks) == 0 {
+		return
+	}
+
+	var notes strings.Builder
+	for i := range chunks {
+		if i >= maxNarrativeChunks {
+			break
+		}
+		chunk := &chunks[i]
+		summary := chunk.Summary
+		if summary == "" {
+			summary = chunk.Content
+		}
+		fmt.Fprintf(&notes, "- [%s, %s, %s] %s\n", chunk.Timestamp.Format("2006-01-02"), chunk.Type, chunk.Metadata.Outcome, summary)
+	}
+
+	resp, err := provider.Complete(ctx, llm.Request{
+		System:    "You analyze an engineering team's memory log. Write three to five short bullet points on trends, recurring problems and suggested next steps. Reply with the bullets only.",
+		Prompt:    fmt.Sprintf("Repository: %v\nTimeframe: %v\n\nMemories:\n%s", insights["repository"], insights["timeframe"], notes.String()),
+		MaxTokens: 400,
+	})
+	if err != nil {
+		logging.Warn("LLM insight generation failed", "provider", provider.Name(), "error", err)
+		return
+	}
+
+	insights["narrative"] = resp.Text
+	insights["narrative_provider"] = provider.Name()
+	insights["narrative_model"] = resp.Model
+}
@@ -0,0 +1,304 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/pkg/types"
+)
+
+const (
+	// OperationConsolidateMemories promotes episodic memories into one
+	// semantic or procedural memory (memory_intelligence)
+	OperationConsolidateMemories = "consolidate_memories"
+
+	// consolidationCaptureTool marks the provenance of consolidated memories
+	consolidationCaptureTool = "consolidation"
+
+	// maxConsolidationSources caps the memories merged by one consolidation
+	maxConsolidationSources = 200
+
+	// consolidationLineLength caps each source's line in extractive content
+	consolidationLineLength = 200
+)
+
+// memoryClassConfig returns the configured retention of each memory class
+func (ms *MemoryServer) memoryClassConfig() config.MemoryClassConfig {
+	if cfg := ms.container.Config; cfg != nil {
+		return cfg.Storage.MemoryClasses
+	}
+	return config.DefaultConfig().Storage.MemoryClasses
+}
+
+// memoryClassNames lists the memory classes for tool schemas
+func memoryClassNames() []string {
+	names := make([]string, 0, len(types.MemoryClasses))
+	for _, class := range types.MemoryClasses {
+		names = append(names, string(class))
+	}
+	return names
+}
+
+// memoryClassesFromParams parses the classes a search is restricted to
+func memoryClassesFromParams(params map[string]interface{}) ([]types.MemoryClass, error) {
+	raw, ok := params["classes"].([]interface{})
+	if !ok {
+		return nil, nil
+	}
+	classes := make([]types.MemoryClass, 0, len(raw))
+	for _, r := range raw {
+		class := types.MemoryClass(fmt.Sprint(r))
+		if !class.Valid() {
+			return nil, fmt.Errorf("invalid memory class %q: use episodic, semantic or procedural", class)
+		}
+		classes = append(classes, class)
+	}
+	return classes, nil
+}
+
+// handleConsolidateMemories promotes a session's episodic memories, or the
+// given ones, into one semantic (or procedural) memory. The new memory links
+// back to its sources, which stay searchable but rank below it.
+func (ms *MemoryServer) handleConsolidateMemories(ctx context.Context, options map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: memory_intelligence consolidate_memories called", "options", options)
+
+	repository, _ := options["repository"].(string)
+	sessionID, _ := options["session_id"].(string)
+	rawIDs, _ := options["chunk_ids"].([]interface{})
+	if repository == GlobalRepository || (sessionID == "" && len(rawIDs) == 0) {
+		return nil, errors.New("consolidate_memories requires a repository and session_id or chunk_ids. Example: {\"operation\": \"consolidate_memories\", \"options\": {\"repository\": \"github.com/user/repo\", \"session_id\": \"auth-fix-session\"}}")
+	}
+
+	class := types.MemoryClassSemantic
+	if c, ok := options["memory_class"].(string); ok && c != "" {
+		class = types.MemoryClass(c)
+	}
+	if class != types.MemoryClassSemantic && class != types.MemoryClassProcedural {
+		return nil, fmt.Errorf("memories consolidate into the semantic or procedural class, not %q", class)
+	}
+	content, _ := options["content"].(string)
+	summary, _ := options["summary"].(string)
+
+	store := ms.container.GetVectorStore()
+	var candidates []types.ConversationChunk
+	if len(rawIDs) > 0 {
+		if len(rawIDs) > maxConsolidationSources {
+			return nil, fmt.Errorf("consolidate_memories merges at most %d chunks", maxConsolidationSources)
+		}
+		for _, raw := range rawIDs {
+			id, _ := raw.(string)
+			chunk, err := store.GetByID(ctx, id)
+			if err != nil || chunk.Metadata.Repository != repository {
+				return nil, fmt.Errorf("chunk %s not found in repository %s", id, repository)
+			}
+			candidates = append(candidates, *chunk)
+		}
+	} else {
+		if !strings.Contains(sessionID, "::") {
+			sessionID = ms.createRepositoryScopedSessionID(repository, sessionID)
+		}
+		chunks, err := store.ListBySession(ctx, sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list session memories: %w", err)
+		}
+		candidates = chunks
+	}
+
+	sources := make([]*types.ConversationChunk, 0, len(candidates))
+	for i := range candidates {
+		chunk := &candidates[i]
+		if chunk.Metadata.Repository != repository || chunk.IsDeleted() || chunk.IsPassage() ||
+			chunk.Class() != types.MemoryClassEpisodic || chunk.IsPromoted() {
+			continue
+		}
+		sources = append(sources, chunk)
+	}
+	if len(sources) == 0 {
+		return nil, errors.New("no episodic memories left to consolidate; memories already consolidated, in the trash or of another class are skipped")
+	}
+	if len(sources) > maxConsolidationSources {
+		sources = sources[len(sources)-maxConsolidationSources:]
+	}
+
+	record, err := ms.consolidateMemories(ctx, sources, class, content, summary)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceIDs := make([]string, 0, len(sources))
+	for _, source := range sources {
+		sourceIDs = append(sourceIDs, source.ID)
+	}
+	return map[string]interface{}{
+		"status":       "success",
+		"operation":    OperationConsolidateMemories,
+		"repository":   repository,
+		"chunk_id":     record.ID,
+		"memory_class": record.Class(),
+		"summary":      record.Summary,
+		"consolidated": sourceIDs,
+		"skipped":      len(candidates) - len(sources),
+	}, nil
+}
+
+// consolidateMemories stores one memory of class distilled from episodic
+// sources of a single repository, links it to each with learned_from and
+// marks the sources promoted to it. Without content, the new memory lists
+// what each source was about.
+func (ms *MemoryServer) consolidateMemories(ctx context.Context, sources []*types.ConversationChunk, class types.MemoryClass, content, summary string) (*types.ConversationChunk, error) {
+	sort.SliceStable(sources, func(i, j int) bool { return sources[i].Timestamp.Before(sources[j].Timestamp) })
+	first := sources[0]
+
+	metadata := types.ChunkMetadata{
+		Repository:  first.Metadata.Repository,
+		Branch:      first.Metadata.Branch,
+		Outcome:     types.OutcomeSuccess,
+		Difficulty:  types.DifficultySimple,
+		MemoryClass: class,
+		Provenance:  &types.Provenance{SourceSystem: types.SourceSystemMCP, CaptureTool: consolidationCaptureTool},
+	}
+	tags := map[string]bool{"consolidated": true}
+	files := make(map[string]bool)
+	sourceIDs := make([]string, 0, len(sources))
+	lines := make([]string, 0, len(sources))
+	for _, source := range sources {
+		sourceIDs = append(sourceIDs, source.ID)
+		for _, tag := range source.Metadata.Tags {
+			tags[tag] = true
+		}
+		for _, file := range source.Metadata.FilesModified {
+			files[file] = true
+		}
+		lines = append(lines, "- "+consolidationLine(source))
+	}
+	metadata.Tags = sortedKeys(tags)
+	metadata.FilesModified = sortedKeys(files)
+	metadata.ExtendedMetadata = map[string]interface{}{"consolidated_from": sourceIDs}
+
+	if content == "" {
+		content = fmt.Sprintf("CONSOLIDATED FROM %d SESSION MEMORIES:\n\n%s", len(sources), strings.Join(lines, "\n"))
+	}
+	if summary == "" {
+		summary = fmt.Sprintf("Consolidated %d memories: %s", len(sources), consolidationLine(first))
+	}
+
+	chunkType := types.ChunkTypeSessionSummary
+	if class == types.MemoryClassProcedural {
+		chunkType = types.ChunkTypeSolution
+	}
+	record, err := types.NewConversationChunk(first.SessionID, content, chunkType, &metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consolidated memory: %w", err)
+	}
+	record.Summary = summary
+	record.Embeddings, err = ms.container.GetEmbeddingService().GenerateEmbedding(ctx, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate embeddings for consolidated memory: %w", err)
+	}
+
+	store := ms.container.GetVectorStore()
+	if err := store.Store(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to store consolidated memory: %w", err)
+	}
+	for _, source := range sources {
+		if _, err := store.StoreRelationship(ctx, record.ID, source.ID, types.RelationLearnedFrom, 1.0, types.ConfidenceDerived); err != nil {
+			logging.Warn("Failed to link consolidated memory to its source", "chunk_id", record.ID, "source_id", source.ID, "error", err)
+		}
+		if _, err := ms.updateChunk(ctx, source.ID, "", func(chunk *types.ConversationChunk) error {
+			chunk.Metadata.PromotedTo = record.ID
+			return nil
+		}); err != nil {
+			return record, fmt.Errorf("failed to mark %s consolidated: %w", source.ID, err)
+		}
+		source.Metadata.PromotedTo = record.ID
+	}
+
+	logging.Info("Consolidated episodic memories", "chunk_id", record.ID, "class", class, "sources", len(sources))
+	return record, nil
+}
+
+// consolidationLine describes a source memory in one line
+func consolidationLine(chunk *types.ConversationChunk) string {
+	line := chunk.Summary
+	if line == "" {
+		line = chunk.Content
+	}
+	line = strings.Join(strings.Fields(line), " ")
+	if len(line) > consolidationLineLength {
+		line = strings.TrimSpace(line[:consolidationLineLength]) + "..."
+	}
+	return line
+}
+
+// sortedKeys returns a set's members in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// expireMemories moves memories past their class's retention to the trash.
+// With promotion on expiry, each session's expiring episodic memories are
+// consolidated into a semantic memory first; a session whose consolidation
+// fails keeps its memories until the next run. It returns how many memories
+// were trashed and how many consolidated memories were stored.
+func (ms *MemoryServer) expireMemories(ctx context.Context, now time.Time) (trashed, promoted int, err error) {
+	cfg := ms.memoryClassConfig()
+	retention := map[types.MemoryClass]int{
+		types.MemoryClassEpisodic:   cfg.EpisodicRetentionDays,
+		types.MemoryClassSemantic:   cfg.SemanticRetentionDays,
+		types.MemoryClassProcedural: cfg.ProceduralRetentionDays,
+	}
+
+	chunks, err := ms.container.GetVectorStore().GetAllChunks(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to scan memories for expiry: %w", err)
+	}
+
+	expiring := make([]*types.ConversationChunk, 0)
+	sessions := make(map[string][]*types.ConversationChunk)
+	for i := range chunks {
+		chunk := &chunks[i]
+		days := retention[chunk.Class()]
+		if chunk.IsDeleted() || chunk.IsPassage() || days == 0 || chunk.Timestamp.After(now.AddDate(0, 0, -days)) {
+			continue
+		}
+		if cfg.PromoteOnExpiry && chunk.Class() == types.MemoryClassEpisodic && !chunk.IsPromoted() {
+			key := chunk.Metadata.Repository + "\x00" + chunk.SessionID
+			sessions[key] = append(sessions[key], chunk)
+			continue
+		}
+		expiring = append(expiring, chunk)
+	}
+
+	keys := make([]string, 0, len(sessions))
+	for key := range sessions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, err := ms.consolidateMemories(ctx, sessions[key], types.MemoryClassSemantic, "", ""); err != nil {
+			logging.Warn("Failed to consolidate expiring session memories", "session_id", sessions[key][0].SessionID, "error", err)
+			continue
+		}
+		promoted++
+		expiring = append(expiring, sessions[key]...)
+	}
+
+	for _, chunk := range expiring {
+		if err := ms.trashChunk(ctx, chunk, now); err != nil {
+			return trashed, promoted, fmt.Errorf("failed to trash expired memory %s: %w", chunk.ID, err)
+		}
+		trashed++
+	}
+	return trashed, promoted, nil
+}
@@ -0,0 +1,136 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const classTestSession = "github.com/acme/api::session-1"
+
+func TestConsolidateMemories(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewKeywordStore("")
+	problem := newReportChunk(t, classTestSession, "Login fails after the token rotation", types.ChunkTypeProblem, types.ChunkMetadata{Tags: []string{"auth"}})
+	fix := newReportChunk(t, classTestSession, "Rotated keys are now cached for five minutes", types.ChunkTypeCodeChange, types.ChunkMetadata{FilesModified: []string{"internal/auth/keys.go"}})
+	decision := newReportChunk(t, classTestSession, "We cache signing keys", types.ChunkTypeArchitectureDecision, types.ChunkMetadata{})
+	other := newReportChunk(t, "github.com/acme/api::session-2", "Unrelated discussion", types.ChunkTypeDiscussion, types.ChunkMetadata{})
+	for _, c := range []*types.ConversationChunk{problem, fix, decision, other} {
+		require.NoError(t, store.Store(ctx, c))
+	}
+
+	ms := newCompositeTestServer(t, store)
+	consolidate := func(options map[string]interface{}) (map[string]interface{}, error) {
+		options["repository"] = "github.com/acme/api"
+		result, err := ms.handleMemoryIntelligence(ctx, map[string]interface{}{
+			"operation": OperationConsolidateMemories,
+			"options":   options,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return result.(map[string]interface{}), nil
+	}
+
+	result, err := consolidate(map[string]interface{}{"session_id": "session-1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{problem.ID, fix.ID}, result["consolidated"])
+	assert.Equal(t, 1, result["skipped"], "the decision is semantic already")
+
+	record, err := store.GetByID(ctx, result["chunk_id"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, types.MemoryClassSemantic, record.Class())
+	assert.Equal(t, classTestSession, record.SessionID)
+	assert.Contains(t, record.Content, "Login fails after the token rotation")
+	assert.Equal(t, []string{"auth", "consolidated"}, record.Metadata.Tags)
+	assert.Equal(t, []string{"internal/auth/keys.go"}, record.Metadata.FilesModified)
+
+	source, err := store.GetByID(ctx, problem.ID)
+	require.NoError(t, err)
+	assert.Equal(t, record.ID, source.Metadata.PromotedTo)
+	links, err := store.GetRelationships(ctx, &types.RelationshipQuery{ChunkID: record.ID, Direction: "outgoing"})
+	require.NoError(t, err)
+	assert.Len(t, links, 2)
+
+	_, err = consolidate(map[string]interface{}{"session_id": "session-1"})
+	assert.Error(t, err, "consolidated memories are not consolidated twice")
+
+	result, err = consolidate(map[string]interface{}{
+		"chunk_ids":    []interface{}{other.ID},
+		"memory_class": "procedural",
+		"content":      "Run make lint before pushing",
+	})
+	require.NoError(t, err)
+	record, err = store.GetByID(ctx, result["chunk_id"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, types.ChunkTypeSolution, record.Type)
+	assert.Equal(t, "Run make lint before pushing", record.Content)
+
+	_, err = consolidate(map[string]interface{}{"session_id": "session-2", "memory_class": "episodic"})
+	assert.Error(t, err)
+}
+
+func TestExpireMemories(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewKeywordStore("")
+	now := time.Now()
+	old := func(session, content string, chunkType types.ChunkType) *types.ConversationChunk {
+		c := newReportChunk(t, session, content, chunkType, types.ChunkMetadata{})
+		c.Timestamp = now.AddDate(0, 0, -40)
+		require.NoError(t, store.Store(ctx, c))
+		return c
+	}
+	log1 := old(classTestSession, "Tried bumping the pool size", types.ChunkTypeDiscussion)
+	log2 := old(classTestSession, "Pool size fixed the timeouts", types.ChunkTypeProblem)
+	fact := old(classTestSession, "The pool holds 20 connections", types.ChunkTypeAnalysis)
+	recent := newReportChunk(t, classTestSession, "Monitoring the pool", types.ChunkTypeDiscussion, types.ChunkMetadata{})
+	require.NoError(t, store.Store(ctx, recent))
+
+	ms := newCompositeTestServer(t, store)
+	ms.container.Config = config.DefaultConfig()
+	ms.container.Config.Storage.MemoryClasses.EpisodicRetentionDays = 30
+
+	trashed, promoted, err := ms.expireMemories(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, trashed)
+	assert.Equal(t, 1, promoted)
+
+	for _, c := range []*types.ConversationChunk{log1, log2} {
+		got, err := store.GetByID(ctx, c.ID)
+		require.NoError(t, err)
+		assert.True(t, got.IsDeleted(), "expired episodic memories move to the trash")
+		require.True(t, got.IsPromoted(), "and were consolidated first")
+		consolidated, err := store.GetByID(ctx, got.Metadata.PromotedTo)
+		require.NoError(t, err)
+		assert.False(t, consolidated.IsDeleted())
+	}
+	for _, c := range []*types.ConversationChunk{fact, recent} {
+		got, err := store.GetByID(ctx, c.ID)
+		require.NoError(t, err)
+		assert.False(t, got.IsDeleted(), "semantic and recent memories are kept")
+	}
+
+	// Without promotion expired memories are only trashed
+	ms.container.Config.Storage.MemoryClasses.PromoteOnExpiry = false
+	ms.container.Config.Storage.MemoryClasses.SemanticRetentionDays = 30
+	trashed, promoted, err = ms.expireMemories(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, trashed)
+	assert.Zero(t, promoted)
+}
+
+func TestSearchRejectsUnknownMemoryClass(t *testing.T) {
+	ms := newCompositeTestServer(t, storage.NewKeywordStore(""))
+	_, err := ms.handleSecureSearch(context.Background(), map[string]interface{}{
+		"query":   "pool size",
+		"classes": []interface{}{"semantic", "long_term"},
+	}, "github.com/acme/api")
+	assert.ErrorContains(t, err, "long_term")
+}
@@ -0,0 +1,193 @@
+package mcp
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"lerian-mcp-memory/internal/intelligence"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/monitoring"
+	"lerian-mcp-memory/internal/tenancy"
+	"lerian-mcp-memory/pkg/types"
+)
+
+// OperationHealthScore scores the knowledge hygiene of repositories (memory_analyze)
+const OperationHealthScore = "health_score"
+
+const (
+	// healthActivityWeeks is the number of recent weeks activity coverage looks at
+	healthActivityWeeks = 4
+
+	// healthScoreInterval is how often the health scores behind the metrics
+	// and the admin dashboard are recomputed
+	healthScoreInterval = time.Hour
+)
+
+// GetHealthScores returns the latest memory health score of each repository
+func (ms *MemoryServer) GetHealthScores() *monitoring.HealthScores {
+	return ms.healthScores
+}
+
+// handleHealthScore scores one repository, or every repository for 'global'.
+// The scores also update the health gauges served on /metrics.
+func (ms *MemoryServer) handleHealthScore(ctx context.Context, options map[string]interface{}) (interface{}, error) {
+	repository, _ := options["repository"].(string)
+
+	var scores []monitoring.RepositoryHealth
+	if repository == GlobalRepository {
+		var err error
+		if scores, err = ms.refreshHealthScores(ctx); err != nil {
+			return nil, err
+		}
+		scores = tenantHealth(ctx, scores)
+	} else {
+		chunks, err := ms.reportChunks(ctx, repository, maxReportChunks)
+		if err != nil {
+			return nil, err
+		}
+		score := ms.repositoryHealth(ctx, repository, chunks, time.Now())
+		ms.healthScores.Set(score)
+		scores = []monitoring.RepositoryHealth{score}
+	}
+
+	return map[string]interface{}{
+		"status":       "success",
+		"operation":    OperationHealthScore,
+		"repository":   repository,
+		"repositories": scores,
+		"generated_at": time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// MemoryHealth returns the health score of every repository the request's
+// tenant owns, recomputing them when the last computation is older than
+// healthScoreInterval
+func (ms *MemoryServer) MemoryHealth(ctx context.Context) ([]monitoring.RepositoryHealth, error) {
+	if time.Since(ms.healthScores.ComputedAt()) < healthScoreInterval {
+		return tenantHealth(ctx, ms.healthScores.Scores()), nil
+	}
+	scores, err := ms.refreshHealthScores(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tenantHealth(ctx, scores), nil
+}
+
+// refreshHealthScores recomputes the health score of every repository. The
+// scores back the metrics of every tenant, so they are computed outside the
+// request's tenant and callers pass them through tenantHealth.
+func (ms *MemoryServer) refreshHealthScores(ctx context.Context) ([]monitoring.RepositoryHealth, error) {
+	ctx = tenancy.WithTenant(ctx, nil)
+	chunks, err := ms.liveChunks(ctx, GlobalRepository, math.MaxInt)
+	if err != nil {
+		return nil, err
+	}
+
+	// liveChunks sorts newest first, which each repository's share keeps
+	byRepository := make(map[string][]types.ConversationChunk)
+	for i := range chunks {
+		repository := chunks[i].Metadata.Repository
+		if repository == "" {
+			continue
+		}
+		byRepository[repository] = append(byRepository[repository], chunks[i])
+	}
+
+	now := time.Now()
+	scores := make([]monitoring.RepositoryHealth, 0, len(byRepository))
+	for repository, repoChunks := range byRepository {
+		scores = append(scores, ms.repositoryHealth(ctx, repository, repoChunks, now))
+	}
+	ms.healthScores.Replace(scores, now)
+	return ms.healthScores.Scores(), nil
+}
+
+// tenantHealth keeps the scores of the repositories the request's tenant owns
+func tenantHealth(ctx context.Context, scores []monitoring.RepositoryHealth) []monitoring.RepositoryHealth {
+	tenant := tenancy.FromContext(ctx)
+	if tenant == nil {
+		return scores
+	}
+	return monitoring.FilterHealth(scores, tenant.Allows)
+}
+
+// runHealthScoring keeps the health scores current for the metrics endpoint
+func (ms *MemoryServer) runHealthScoring(ctx context.Context) {
+	ticker := time.NewTicker(healthScoreInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := ms.refreshHealthScores(ctx); err != nil {
+				logging.Error("Memory health scoring failed", "error", err)
+			}
+		}
+	}
+}
+
+// repositoryHealth scores a repository from its memories, newest first.
+// Activity coverage looks at every memory; the ratios at the most recent
+// maxReportChunks and the conflict scan, which compares memories pairwise,
+// at the most recent defaultReportChunks.
+func (ms *MemoryServer) repositoryHealth(ctx context.Context, repository string, chunks []types.ConversationChunk, now time.Time) monitoring.RepositoryHealth {
+	var components monitoring.HealthComponents
+
+	activeWeeks := make(map[int]bool)
+	for i := range chunks {
+		week := int(now.Sub(chunks[i].Timestamp) / (7 * 24 * time.Hour))
+		if week >= healthActivityWeeks {
+			break
+		}
+		if week >= 0 {
+			activeWeeks[week] = true
+		}
+	}
+	components.ActivityCoverage = float64(len(activeWeeks)) / healthActivityWeeks
+
+	if len(chunks) > maxReportChunks {
+		chunks = chunks[:maxReportChunks]
+	}
+
+	manager := intelligence.NewFreshnessManager(ms.container.GetVectorStore())
+	var coverage verificationCoverage
+	stale, untagged := 0, 0
+	for i := range chunks {
+		chunk := &chunks[i]
+		if status, err := manager.CheckFreshness(ctx, chunk); err == nil && status.IsStale {
+			stale++
+		}
+		if len(chunk.Metadata.Tags) == 0 {
+			untagged++
+		}
+		if chunk.Type == types.ChunkTypeSolution {
+			coverage.add(chunk)
+		}
+	}
+	if len(chunks) > 0 {
+		components.StaleRatio = float64(stale) / float64(len(chunks))
+		components.UntaggedRatio = float64(untagged) / float64(len(chunks))
+	}
+	components.Solutions = coverage.Solutions
+	if coverage.Solutions > 0 {
+		components.VerifiedSolutionRatio = float64(coverage.Verified) / float64(coverage.Solutions)
+	}
+
+	recent := chunks[:min(len(chunks), defaultReportChunks)]
+	if result, err := ms.newConflictDetector().DetectConflicts(ctx, recent); err == nil {
+		components.Conflicts = len(result.Conflicts)
+	} else {
+		logging.Warn("health_score: conflict scan failed", "repository", repository, "error", err)
+	}
+
+	return monitoring.RepositoryHealth{
+		Repository: repository,
+		Memories:   len(chunks),
+		Score:      monitoring.HealthScore(len(chunks), components),
+		Components: components,
+		ComputedAt: now,
+	}
+}
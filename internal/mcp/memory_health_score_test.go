@@ -0,0 +1,76 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/monitoring"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/tenancy"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeHealthScore(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	chunk := func(content string, chunkType types.ChunkType, repository string, age time.Duration, tags ...string) *types.ConversationChunk {
+		c := newReportChunk(t, "s1", content, chunkType, types.ChunkMetadata{Repository: repository, Tags: tags})
+		c.Timestamp = time.Now().Add(-age)
+		require.NoError(t, store.Store(ctx, c))
+		return c
+	}
+	chunk("Planning the billing rollout", types.ChunkTypeDiscussion, "github.com/acme/api", 0, "billing")
+	verified := chunk("Retry the token refresh once", types.ChunkTypeSolution, "github.com/acme/api", time.Hour)
+	verified.RecordVerification(types.VerificationRecord{Status: types.VerificationVerified})
+	require.NoError(t, store.Update(ctx, verified))
+	chunk("Raise the pool size to 50", types.ChunkTypeSolution, "github.com/acme/api", 15*24*time.Hour)
+	chunk("Notes on the legacy importer", types.ChunkTypeDiscussion, "github.com/acme/api", 1000*24*time.Hour, "importer")
+	chunk("Landing page copy review", types.ChunkTypeDiscussion, "github.com/acme/web", 0, "copy")
+
+	ms := newCompositeTestServer(t, store)
+	report := analyze(t, ms, OperationHealthScore, map[string]interface{}{})
+	scores := report["repositories"].([]monitoring.RepositoryHealth)
+	require.Len(t, scores, 1)
+	api := scores[0]
+	assert.Equal(t, 4, api.Memories)
+	assert.InDelta(t, 0.5, api.Components.ActivityCoverage, 1e-9, "weeks 0 and 2 of the last 4 saw new memories")
+	assert.InDelta(t, 0.25, api.Components.StaleRatio, 1e-9)
+	assert.InDelta(t, 0.5, api.Components.UntaggedRatio, 1e-9)
+	assert.Equal(t, 2, api.Components.Solutions)
+	assert.InDelta(t, 0.5, api.Components.VerifiedSolutionRatio, 1e-9)
+	assert.Equal(t, monitoring.HealthScore(api.Memories, api.Components), api.Score)
+
+	report = analyze(t, ms, OperationHealthScore, map[string]interface{}{"repository": GlobalRepository})
+	scores = report["repositories"].([]monitoring.RepositoryHealth)
+	require.Len(t, scores, 2)
+	assert.Equal(t, "github.com/acme/api", scores[0].Repository, "the least healthy repository comes first")
+	assert.Len(t, ms.GetHealthScores().Scores(), 2, "scores feed the health gauges")
+
+	cached, err := ms.MemoryHealth(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, scores, cached)
+}
+
+func TestMemoryHealth_HeldToTheTenant(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	for _, repository := range []string{"github.com/acme/api", "github.com/rival/app"} {
+		require.NoError(t, store.Store(ctx, newReportChunk(t, "s1", "Notes on "+repository, types.ChunkTypeDiscussion, types.ChunkMetadata{Repository: repository})))
+	}
+	ms := newCompositeTestServer(t, storage.NewTenantIsolatedVectorStore(store))
+
+	tenant := tenancy.WithTenant(ctx, &tenancy.Tenant{ID: "api_key:acme", Projects: []string{"github.com/acme/api"}})
+	scores, err := ms.MemoryHealth(tenant)
+	require.NoError(t, err)
+	require.Len(t, scores, 1)
+	assert.Equal(t, "github.com/acme/api", scores[0].Repository)
+	assert.Len(t, ms.GetHealthScores().Scores(), 2, "a tenant's request scores every repository for the metrics")
+
+	scores, err = ms.MemoryHealth(ctx)
+	require.NoError(t, err)
+	assert.Len(t, scores, 2)
+}
@@ -0,0 +1,252 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"runtime/debug"
+	"time"
+
+	"lerian-mcp-memory/internal/logging"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+// RequestHandler handles a single MCP JSON-RPC request. The method name is
+// req.Method and its parameters are req.Params.
+type RequestHandler func(ctx context.Context, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse
+
+// Middleware wraps request handling. A middleware calls next to continue the
+// chain, or returns its own response (see ErrorResponse) to short-circuit it.
+type Middleware func(next RequestHandler) RequestHandler
+
+// Use appends middlewares to the request chain. The first middleware
+// registered is the outermost, so it sees every request first and every
+// response last. Use applies to all transports served through HandleRequest.
+func (ms *MemoryServer) Use(middlewares ...Middleware) {
+	ms.middlewareMu.Lock()
+	defer ms.middlewareMu.Unlock()
+
+	ms.middlewares = append(ms.middlewares, middlewares...)
+	ms.requestHandler = nil
+}
+
+// HandleRequest dispatches a JSON-RPC request through the middleware chain to
+// the underlying MCP server. Transports should call this rather than the MCP
+// server directly so middlewares are applied. Requests arriving after
+// Shutdown began are refused with ShuttingDownCode.
+func (ms *MemoryServer) HandleRequest(ctx context.Context, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+	if !ms.drain.enter() {
+		return shuttingDownResponse(req)
+	}
+	defer ms.drain.requests.done()
+	return ms.chain()(ctx, req)
+}
+
+// chain returns the composed request handler, building it on first use after a change
+func (ms *MemoryServer) chain() RequestHandler {
+	ms.middlewareMu.RLock()
+	handler := ms.requestHandler
+	ms.middlewareMu.RUnlock()
+	if handler != nil {
+		return handler
+	}
+
+	ms.middlewareMu.Lock()
+	defer ms.middlewareMu.Unlock()
+	if ms.requestHandler == nil {
+		handler = ms.dispatch
+		for i := len(ms.middlewares) - 1; i >= 0; i-- {
+			handler = ms.middlewares[i](handler)
+		}
+		ms.requestHandler = handler
+	}
+	return ms.requestHandler
+}
+
+// dispatch hands a request at the end of the middleware chain to the MCP server
+func (ms *MemoryServer) dispatch(ctx context.Context, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+	ctx = ms.withSession(ctx, req.Method)
+	if ms.capabilityDisabled(req.Method) {
+		return ErrorResponse(req, protocol.MethodNotFound, "Method not found", map[string]interface{}{"method": req.Method})
+	}
+	var typedResult *toolResultSlot
+	switch req.Method {
+	case "tools/call":
+		if ms.isToolRemoved(RequestTarget(req)) {
+			return ErrorResponse(req, protocol.MethodNotFound, "Tool not found", nil)
+		}
+		ctx = ms.withResourcePolicy(ms.withClientRoots(ctx))
+		ctx, typedResult = withToolResultSlot(ctx)
+	case "resources/read":
+		ctx = ms.withResourcePolicy(ms.withClientRoots(ctx))
+	case "prompts/get":
+		var invalid *protocol.JSONRPCResponse
+		if req, invalid = ms.preparePromptArguments(req); invalid != nil {
+			return invalid
+		}
+	case "resources/subscribe", "resources/unsubscribe":
+		return ms.handleResourceSubscription(ctx, req)
+	case notificationRootsListChanged:
+		return ms.handleRootsListChanged(ctx, req)
+	}
+
+	resp := ms.mcpServer.HandleRequest(ctx, req)
+	if resp == nil || resp.Error != nil {
+		return resp
+	}
+	if typedResult != nil {
+		switch {
+		case typedResult.result != nil:
+			resp.Result = typedResult.result
+		case typedResult.err != nil:
+			resp.Result = toolErrorResult(typedResult.err)
+		}
+	}
+
+	switch req.Method {
+	case "tools/list":
+		result, ok := resp.Result.(map[string]interface{})
+		if !ok {
+			return resp
+		}
+		tools, ok := result["tools"].([]protocol.Tool)
+		if !ok {
+			return resp
+		}
+		listed := make([]protocol.Tool, 0, len(tools))
+		for i := range tools {
+			if !ms.isToolRemoved(tools[i].Name) {
+				listed = append(listed, tools[i])
+			}
+		}
+		result["tools"] = listed
+		return ms.annotateToolList(ms.paginateListResponse(req, resp))
+
+	case "resources/list", "prompts/list":
+		return ms.paginateListResponse(req, resp)
+
+	case "initialize":
+		ms.recordClientCapabilities(connectionIDFrom(ctx), req)
+		result, ok := resp.Result.(protocol.InitializeResult)
+		if !ok {
+			return resp
+		}
+		result.Capabilities.Tools = &protocol.ToolCapability{ListChanged: true}
+		resources := protocol.ResourceCapability{}
+		if result.Capabilities.Resources != nil {
+			resources = *result.Capabilities.Resources
+		}
+		resources.ListChanged = true
+		resources.Subscribe = true
+		result.Capabilities.Resources = &resources
+		capabilities := ms.protocolCapabilities()
+		if !capabilities.Resources {
+			result.Capabilities.Resources = nil
+		}
+		if !capabilities.Prompts {
+			result.Capabilities.Prompts = nil
+		}
+		resp.Result = result
+	}
+	return resp
+}
+
+// ErrorResponse builds a JSON-RPC error response to req, for middlewares that reject a request
+func ErrorResponse(req *protocol.JSONRPCRequest, code int, message string, data interface{}) *protocol.JSONRPCResponse {
+	return &protocol.JSONRPCResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Error:   protocol.NewJSONRPCError(code, message, data),
+	}
+}
+
+// ForMethods applies a middleware only to the given methods, e.g.
+// ForMethods(auth, "tools/call", "resources/read", "prompts/get"). Other
+// methods skip it.
+func ForMethods(middleware Middleware, methods ...string) Middleware {
+	selected := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		selected[method] = true
+	}
+
+	return func(next RequestHandler) RequestHandler {
+		wrapped := middleware(next)
+		return func(ctx context.Context, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+			if selected[req.Method] {
+				return wrapped(ctx, req)
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// RequestTarget returns what a request acts on: the tool name for
+// tools/call, the URI for resources/read and the prompt name for
+// prompts/get. It returns "" for other methods.
+func RequestTarget(req *protocol.JSONRPCRequest) string {
+	var key string
+	switch req.Method {
+	case "tools/call", "prompts/get":
+		key = "name"
+	case "resources/read":
+		key = "uri"
+	default:
+		return ""
+	}
+
+	target, _ := requestParams(req)[key].(string)
+	return target
+}
+
+// requestParams returns the parameters of a request as a map, or nil when
+// they are not an object
+func requestParams(req *protocol.JSONRPCRequest) map[string]interface{} {
+	params, ok := req.Params.(map[string]interface{})
+	if !ok {
+		// Requests built in-process carry typed params; normalize them
+		data, err := json.Marshal(req.Params)
+		if err != nil || json.Unmarshal(data, &params) != nil {
+			return nil
+		}
+	}
+	return params
+}
+
+// RecoveryMiddleware turns a panic further down the chain into an
+// InternalError response instead of taking down the transport. The panic is
+// logged with its stack and kept out of the response.
+func RecoveryMiddleware() Middleware {
+	return func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *protocol.JSONRPCRequest) (resp *protocol.JSONRPCResponse) {
+			defer func() {
+				if r := recover(); r != nil {
+					logging.Error("MCP request panicked", "method", req.Method, "target", RequestTarget(req), "panic", r, "stack", string(debug.Stack()))
+					resp = ErrorResponse(req, protocol.InternalError, "Internal error", nil)
+				}
+			}()
+			return next(ctx, req)
+		}
+	}
+}
+
+// LoggingMiddleware logs each request with its duration and outcome
+func LoggingMiddleware() Middleware {
+	return func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+			start := time.Now()
+			resp := next(ctx, req)
+
+			fields := []interface{}{"method", req.Method, "duration", time.Since(start)}
+			if target := RequestTarget(req); target != "" {
+				fields = append(fields, "target", target)
+			}
+			if resp != nil && resp.Error != nil {
+				fields = append(fields, "code", resp.Error.Code, "error", resp.Error.Message)
+				logging.Warn("MCP request failed", fields...)
+			} else {
+				logging.Info("MCP request handled", fields...)
+			}
+			return resp
+		}
+	}
+}
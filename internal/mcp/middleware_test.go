@@ -0,0 +1,122 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	mcp "github.com/fredcamaral/gomcp-sdk"
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMiddlewareTestServer() *MemoryServer {
+	ms := &MemoryServer{mcpServer: mcp.NewServer("test", "1.0.0")}
+	ms.addTool(mcp.NewTool("echo", "Echo", mcp.ObjectSchema("Echo", map[string]interface{}{}, nil)),
+		mcp.ToolHandlerFunc(func(_ context.Context, params map[string]interface{}) (interface{}, error) {
+			if params["panic"] == true {
+				panic("boom")
+			}
+			return "ok", nil
+		}))
+	return ms
+}
+
+func toolCallRequest(name string, args map[string]interface{}) *protocol.JSONRPCRequest {
+	return &protocol.JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params:  map[string]interface{}{"name": name, "arguments": args},
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	ms := newMiddlewareTestServer()
+
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next RequestHandler) RequestHandler {
+			return func(ctx context.Context, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+				calls = append(calls, name+":"+req.Method+":"+RequestTarget(req))
+				resp := next(ctx, req)
+				calls = append(calls, name+":done")
+				return resp
+			}
+		}
+	}
+	ms.Use(record("outer"), record("inner"))
+
+	resp := ms.HandleRequest(context.Background(), toolCallRequest("echo", nil))
+	require.Nil(t, resp.Error)
+	assert.Equal(t, []string{"outer:tools/call:echo", "inner:tools/call:echo", "inner:done", "outer:done"}, calls)
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	ms := newMiddlewareTestServer()
+
+	deny := func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+			return ErrorResponse(req, protocol.InvalidRequest, "unauthorized", map[string]interface{}{"target": RequestTarget(req)})
+		}
+	}
+	ms.Use(ForMethods(deny, "tools/call", "resources/read", "prompts/get"))
+
+	resp := ms.HandleRequest(context.Background(), toolCallRequest("echo", nil))
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, protocol.InvalidRequest, resp.Error.Code)
+	assert.Equal(t, "unauthorized", resp.Error.Message)
+	assert.Equal(t, 1, resp.ID)
+
+	// Methods outside the filter are not affected
+	resp = ms.HandleRequest(context.Background(), &protocol.JSONRPCRequest{JSONRPC: "2.0", ID: 2, Method: "tools/list"})
+	assert.Nil(t, resp.Error)
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	ms.Use(RecoveryMiddleware(), LoggingMiddleware())
+
+	resp := ms.HandleRequest(context.Background(), toolCallRequest("echo", map[string]interface{}{"panic": true}))
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, protocol.InternalError, resp.Error.Code)
+	assert.Equal(t, "Internal error", resp.Error.Message)
+	assert.Nil(t, resp.Error.Data, "the panic stays out of the response")
+
+	resp = ms.HandleRequest(context.Background(), toolCallRequest("echo", nil))
+	assert.Nil(t, resp.Error)
+}
+
+func TestMiddlewareAddedAfterFirstRequest(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	require.Nil(t, ms.HandleRequest(context.Background(), toolCallRequest("echo", nil)).Error)
+
+	ms.Use(func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+			return ErrorResponse(req, protocol.InternalError, "rate limited", nil)
+		}
+	})
+	resp := ms.HandleRequest(context.Background(), toolCallRequest("echo", nil))
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, "rate limited", resp.Error.Message)
+}
+
+func TestRequestTarget(t *testing.T) {
+	assert.Equal(t, "memory://stats", RequestTarget(&protocol.JSONRPCRequest{Method: "resources/read", Params: map[string]interface{}{"uri": "memory://stats"}}))
+	assert.Equal(t, "review", RequestTarget(&protocol.JSONRPCRequest{Method: "prompts/get", Params: map[string]interface{}{"name": "review"}}))
+	assert.Equal(t, "memory_read", RequestTarget(&protocol.JSONRPCRequest{Method: "tools/call", Params: protocol.ToolCallRequest{Name: "memory_read"}}))
+	assert.Empty(t, RequestTarget(&protocol.JSONRPCRequest{Method: "tools/list"}))
+}
+
+func TestExecutorUsesMiddleware(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	ms.Use(func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+			return ErrorResponse(req, protocol.InvalidRequest, "blocked", nil)
+		}
+	})
+
+	_, err := NewMCPToolExecutor(ms).ExecuteTool(context.Background(), "echo", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "blocked")
+}
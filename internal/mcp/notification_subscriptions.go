@@ -0,0 +1,302 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"lerian-mcp-memory/internal/audit"
+	"lerian-mcp-memory/internal/digest"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/tenancy"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/fredcamaral/gomcp-sdk"
+)
+
+const (
+	// eventDeliveryTimeout bounds sending one event to its subscribers
+	eventDeliveryTimeout = 30 * time.Second
+	// maxEventTitleRunes shortens event titles taken from memory content
+	maxEventTitleRunes = 100
+)
+
+// registerNotificationSubscriptionsTool registers system_notification_subscriptions
+func (ms *MemoryServer) registerNotificationSubscriptionsTool() {
+	ms.addTool(mcp.NewTool(
+		"system_notification_subscriptions",
+		"Manage per-person notification subscriptions: which projects and event types (digest, task_status, decision, verification) reach a person, through which channels (webhook, slack, email), and whether each event is sent immediately or batched into a daily or weekly digest. Subscribers are people from system_people. A caller held to a tenant sees and manages only its own subscriptions, which must name the tenant's projects and only receive their events. Operations: list, get, upsert (create or replace), delete, test (send a sample event to the subscription's channels).",
+		mcp.ObjectSchema("Notification subscription parameters", map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"list", "get", "upsert", "delete", "test"},
+				"description": "Subscription operation",
+			},
+			"subscription_id": map[string]interface{}{
+				"type":        "string",
+				"description": "Subscription to read, replace, delete or test (get, upsert, delete, test)",
+			},
+			"person_id": map[string]interface{}{
+				"type":        "string",
+				"description": "Subscriber (upsert), or whose subscriptions to list (list)",
+			},
+			"identity": map[string]interface{}{
+				"type":        "string",
+				"description": "Name, alias or email of the subscriber, instead of person_id",
+			},
+			"subscription": map[string]interface{}{
+				"type":        "object",
+				"description": "Subscription settings (upsert). Example: {\"projects\": [\"github.com/acme/api\"], \"event_types\": [\"decision\", \"task_status\"], \"channels\": [{\"type\": \"slack\", \"url\": \"https://hooks.slack.com/...\"}], \"mode\": \"digest\", \"period\": \"daily\", \"hour\": 9}. Omit event_types to cover all; omitting projects covers all of them, for callers owning every project only; mode defaults to immediate",
+			},
+		}, []string{"operation"}),
+	), mcp.ToolHandlerFunc(ms.handleNotificationSubscriptions))
+}
+
+// handleNotificationSubscriptions manages notification subscriptions
+func (ms *MemoryServer) handleNotificationSubscriptions(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: system_notification_subscriptions called", "args", args)
+
+	subscriptions := ms.container.GetSubscriptions()
+	if subscriptions == nil {
+		return nil, errors.New("notification subscriptions are not available")
+	}
+
+	operation, _ := args["operation"].(string)
+	subscriptionID, _ := args["subscription_id"].(string)
+
+	switch operation {
+	case "list":
+		personIDs, err := ms.subscriberIDs(args, false)
+		if err != nil {
+			return nil, err
+		}
+		list := make([]digest.Subscription, 0)
+		for _, subscription := range subscriptions.List(personIDs...) {
+			if ownsSubscription(ctx, &subscription) {
+				list = append(list, subscription)
+			}
+		}
+		return map[string]interface{}{
+			"status":        "success",
+			"operation":     operation,
+			"subscriptions": list,
+			"count":         len(list),
+		}, nil
+
+	case "get":
+		subscription, ok := subscriptions.Get(subscriptionID)
+		if !ok || !ownsSubscription(ctx, &subscription) {
+			return nil, fmt.Errorf("subscription %q not found", subscriptionID)
+		}
+		response := map[string]interface{}{
+			"status":       "success",
+			"operation":    operation,
+			"subscription": subscription,
+		}
+		if ms.eventNotifier != nil && subscription.Mode == digest.DeliveryDigest {
+			response["pending_events"] = ms.eventNotifier.Pending(subscription.ID)
+		}
+		return response, nil
+
+	case "upsert":
+		options, ok := args["subscription"].(map[string]interface{})
+		if !ok {
+			return nil, errors.New("subscription is required for upsert. Example: {\"operation\": \"upsert\", \"identity\": \"jane@example.com\", \"subscription\": {\"event_types\": [\"decision\"], \"channels\": [{\"type\": \"email\", \"to\": [\"jane@example.com\"]}]}}")
+		}
+		personIDs, err := ms.subscriberIDs(args, true)
+		if err != nil {
+			return nil, err
+		}
+		// Round-trip the options through JSON to reuse the subscription's field names and types
+		raw, err := json.Marshal(options)
+		if err != nil {
+			return nil, fmt.Errorf("invalid subscription: %w", err)
+		}
+		var subscription digest.Subscription
+		if err := json.Unmarshal(raw, &subscription); err != nil {
+			return nil, fmt.Errorf("invalid subscription: %w", err)
+		}
+		subscription.ID = subscriptionID
+		subscription.PersonID = personIDs[0]
+		subscription.Tenant = tenancy.FromContext(ctx)
+		if subscriptionID != "" {
+			if previous, ok := subscriptions.Get(subscriptionID); ok && !ownsSubscription(ctx, &previous) {
+				return nil, fmt.Errorf("subscription %q not found", subscriptionID)
+			}
+		}
+		if err := checkSubscriptionProjects(ctx, subscription.Projects); err != nil {
+			return nil, err
+		}
+
+		saved, err := subscriptions.Upsert(subscription)
+		if err != nil {
+			return nil, err
+		}
+		ms.logSubscriptionChange(ctx, operation, saved)
+		return map[string]interface{}{
+			"status":       "success",
+			"operation":    operation,
+			"subscription": saved,
+		}, nil
+
+	case "delete":
+		subscription, ok := subscriptions.Get(subscriptionID)
+		if !ok || !ownsSubscription(ctx, &subscription) {
+			return nil, fmt.Errorf("subscription %q not found", subscriptionID)
+		}
+		if err := subscriptions.Delete(subscriptionID); err != nil {
+			return nil, err
+		}
+		ms.logSubscriptionChange(ctx, operation, &subscription)
+		return map[string]interface{}{
+			"status":          "success",
+			"operation":       operation,
+			"subscription_id": subscriptionID,
+		}, nil
+
+	case "test":
+		subscription, ok := subscriptions.Get(subscriptionID)
+		if !ok || !ownsSubscription(ctx, &subscription) {
+			return nil, fmt.Errorf("subscription %q not found", subscriptionID)
+		}
+		if ms.eventNotifier == nil {
+			return nil, errors.New("notification delivery is not available")
+		}
+		project := GlobalRepository
+		if len(subscription.Projects) > 0 {
+			project = subscription.Projects[0]
+		}
+		eventType := digest.EventDecision
+		if len(subscription.EventTypes) > 0 {
+			eventType = subscription.EventTypes[0]
+		}
+		event := &digest.Event{Type: eventType, Project: project, Title: "Test notification from the memory server"}
+		if err := ms.eventNotifier.Send(ctx, &subscription, event); err != nil {
+			return nil, fmt.Errorf("test notification failed: %w", err)
+		}
+		return map[string]interface{}{
+			"status":          "success",
+			"operation":       operation,
+			"subscription_id": subscriptionID,
+			"channels":        len(subscription.Channels),
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown operation %q: use list, get, upsert, delete or test", operation)
+	}
+}
+
+// ownsSubscription reports whether the caller may see and manage a
+// subscription: operators see every one, other tenants their own
+func ownsSubscription(ctx context.Context, subscription *digest.Subscription) bool {
+	tenant := tenancy.FromContext(ctx)
+	if tenant == nil || tenant.AllowsAll() {
+		return true
+	}
+	return subscription.Tenant != nil && subscription.Tenant.ID == tenant.ID
+}
+
+// checkSubscriptionProjects fails unless the caller's tenant owns every
+// project a subscription covers. Only operators may cover every project.
+func checkSubscriptionProjects(ctx context.Context, projects []string) error {
+	if len(projects) == 0 || slices.Contains(projects, tenancy.AllProjects) {
+		return checkOperator(ctx, "a subscription without projects")
+	}
+	for _, project := range projects {
+		if err := checkProjectTenant(ctx, project); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// subscriberIDs resolves the person_id or identity argument to a person and
+// the IDs merged into them. Without either, required reports an error and
+// otherwise no IDs are returned.
+func (ms *MemoryServer) subscriberIDs(args map[string]interface{}, required bool) ([]string, error) {
+	personID, _ := args["person_id"].(string)
+	identity, _ := args["identity"].(string)
+	if personID == "" && identity == "" {
+		if required {
+			return nil, errors.New("person_id or identity is required to name the subscriber. Example: {\"identity\": \"jane@example.com\"}")
+		}
+		return nil, nil
+	}
+
+	directory := ms.container.GetPeople()
+	if directory == nil {
+		if personID == "" {
+			return nil, errors.New("the people directory is not available; use person_id")
+		}
+		return []string{personID}, nil
+	}
+	if personID == "" {
+		person, ok := directory.Resolve(identity)
+		if !ok {
+			return nil, fmt.Errorf("no person matches %q; add them with system_people first", identity)
+		}
+		personID = person.ID
+	}
+	if _, ok := directory.Get(personID); !ok {
+		return nil, fmt.Errorf("person %q not found", personID)
+	}
+	return directory.MergedIDs(directory.Canonical(personID)), nil
+}
+
+// logSubscriptionChange records a subscription change in the audit log
+func (ms *MemoryServer) logSubscriptionChange(ctx context.Context, operation string, subscription *digest.Subscription) {
+	if auditLogger := ms.container.GetAuditLogger(); auditLogger != nil {
+		auditLogger.LogEvent(audit.WithPersonID(ctx, subscription.PersonID), audit.EventTypeMemoryUpdate, operation+"_notification_subscription", "notification_subscription", subscription.ID, map[string]interface{}{
+			"projects":    subscription.Projects,
+			"event_types": subscription.EventTypes,
+			"mode":        subscription.Mode,
+			"channels":    len(subscription.Channels),
+		})
+	}
+}
+
+// publishEvent sends a project event to its subscribers in the background,
+// so slow channels do not hold up the tool call that caused it
+func (ms *MemoryServer) publishEvent(event *digest.Event) {
+	if ms.eventNotifier == nil {
+		return
+	}
+	// Shutdown waits for deliveries in progress
+	ms.drain.background.add()
+	go func() {
+		defer ms.drain.background.done()
+		ctx, cancel := context.WithTimeout(context.Background(), eventDeliveryTimeout)
+		defer cancel()
+		if err := ms.eventNotifier.Notify(ctx, event); err != nil {
+			logging.Warn("Failed to notify subscribers", "type", event.Type, "project", event.Project, "error", err)
+		}
+	}()
+}
+
+// chunkEvent builds the event of a change to a chunk
+func chunkEvent(eventType digest.EventType, chunk *types.ConversationChunk, details map[string]interface{}) *digest.Event {
+	return &digest.Event{
+		Type:       eventType,
+		Project:    chunk.Metadata.Repository,
+		Title:      eventTitle(chunk),
+		ChunkID:    chunk.ID,
+		Details:    details,
+		OccurredAt: time.Now().UTC(),
+	}
+}
+
+// eventTitle names a chunk in a notification: its summary, else its first line
+func eventTitle(chunk *types.ConversationChunk) string {
+	title := chunk.Summary
+	if title == "" {
+		title, _, _ = strings.Cut(strings.TrimSpace(chunk.Content), "\n")
+	}
+	if runes := []rune(title); len(runes) > maxEventTitleRunes {
+		title = string(runes[:maxEventTitleRunes-1]) + "…"
+	}
+	return title
+}
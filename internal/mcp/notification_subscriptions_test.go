@@ -0,0 +1,155 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/digest"
+	"lerian-mcp-memory/internal/people"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/tenancy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eventRecorder records the messages sent to subscribers
+type eventRecorder struct {
+	mu       sync.Mutex
+	messages []*digest.Message
+}
+
+func (r *eventRecorder) Deliver(_ context.Context, _ digest.Target, msg *digest.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, msg)
+	return nil
+}
+
+func (r *eventRecorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.messages)
+}
+
+func TestNotificationSubscriptions_ManageAndNotify(t *testing.T) {
+	ctx := context.Background()
+	ms := newCompositeTestServer(t, storage.NewSimpleMockVectorStore())
+	ms.container.People = people.NewStore("")
+	ms.container.Subscriptions = digest.NewSubscriptionStore("")
+	recorder := &eventRecorder{}
+	ms.eventNotifier = digest.NewNotifier(ms.container.Subscriptions, recorder)
+	repo := "github.com/acme/api"
+
+	_, err := ms.handleNotificationSubscriptions(ctx, map[string]interface{}{
+		"operation":    "upsert",
+		"identity":     "jane@example.com",
+		"subscription": map[string]interface{}{"channels": []interface{}{map[string]interface{}{"type": "webhook", "url": "https://example.com/hook"}}},
+	})
+	require.Error(t, err, "subscribers must be known people")
+
+	jane, err := ms.container.People.Upsert(people.Person{DisplayName: "Jane Doe"}, "jane@example.com")
+	require.NoError(t, err)
+
+	upsert := func(options map[string]interface{}) digest.Subscription {
+		result, err := ms.handleNotificationSubscriptions(ctx, map[string]interface{}{
+			"operation":    "upsert",
+			"identity":     "jane@example.com",
+			"subscription": options,
+		})
+		require.NoError(t, err)
+		return *result.(map[string]interface{})["subscription"].(*digest.Subscription)
+	}
+	immediate := upsert(map[string]interface{}{
+		"projects":    []interface{}{repo},
+		"event_types": []interface{}{"task_status"},
+		"channels":    []interface{}{map[string]interface{}{"type": "webhook", "url": "https://example.com/hook"}},
+	})
+	assert.Equal(t, jane.ID, immediate.PersonID)
+	assert.Equal(t, digest.DeliveryImmediate, immediate.Mode)
+	batched := upsert(map[string]interface{}{
+		"event_types": []interface{}{"task_status"},
+		"channels":    []interface{}{map[string]interface{}{"type": "slack", "url": "https://hooks.slack.com/x"}},
+		"mode":        "digest",
+		"period":      "daily",
+		"hour":        9,
+	})
+
+	result, err := ms.handleNotificationSubscriptions(ctx, map[string]interface{}{"operation": "list", "person_id": jane.ID})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.(map[string]interface{})["count"])
+
+	// Creating a task moves it to todo, which reaches both subscriptions
+	_, err = ms.handleCreateTask(ctx, map[string]interface{}{
+		"title":       "Rotate keys",
+		"description": "Rotate the signing keys",
+		"session_id":  "session-1",
+		"repository":  repo,
+	})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return recorder.count() == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, digest.EventTaskStatus, recorder.messages[0].Events[0].Type)
+	require.Eventually(t, func() bool { return ms.eventNotifier.Pending(batched.ID) == 1 }, time.Second, 10*time.Millisecond)
+
+	result, err = ms.handleNotificationSubscriptions(ctx, map[string]interface{}{"operation": "get", "subscription_id": batched.ID})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.(map[string]interface{})["pending_events"])
+
+	_, err = ms.handleNotificationSubscriptions(ctx, map[string]interface{}{"operation": "test", "subscription_id": batched.ID})
+	require.NoError(t, err)
+	assert.Equal(t, 2, recorder.count(), "test sends right away, even in digest mode")
+
+	_, err = ms.handleNotificationSubscriptions(ctx, map[string]interface{}{"operation": "delete", "subscription_id": immediate.ID})
+	require.NoError(t, err)
+	_, err = ms.handleNotificationSubscriptions(ctx, map[string]interface{}{"operation": "get", "subscription_id": immediate.ID})
+	assert.Error(t, err)
+	_, err = ms.handleNotificationSubscriptions(ctx, map[string]interface{}{"operation": "mute"})
+	assert.Error(t, err)
+}
+
+func TestNotificationSubscriptions_HeldToTheTenant(t *testing.T) {
+	ms := newCompositeTestServer(t, storage.NewSimpleMockVectorStore())
+	ms.container.People = people.NewStore("")
+	ms.container.Subscriptions = digest.NewSubscriptionStore("")
+	_, err := ms.container.People.Upsert(people.Person{DisplayName: "Jane Doe"}, "jane@example.com")
+	require.NoError(t, err)
+	acme := tenancy.WithTenant(context.Background(), &tenancy.Tenant{ID: "api_key:acme", Projects: []string{"github.com/acme/api"}})
+	rival := tenancy.WithTenant(context.Background(), &tenancy.Tenant{ID: "api_key:rival", Projects: []string{"github.com/rival/app"}})
+	webhook := []interface{}{map[string]interface{}{"type": "webhook", "url": "https://example.com/hook"}}
+
+	upsert := func(ctx context.Context, projects ...interface{}) (interface{}, error) {
+		options := map[string]interface{}{"channels": webhook}
+		if len(projects) > 0 {
+			options["projects"] = projects
+		}
+		return ms.handleNotificationSubscriptions(ctx, map[string]interface{}{
+			"operation":    "upsert",
+			"identity":     "jane@example.com",
+			"subscription": options,
+		})
+	}
+	_, err = upsert(rival, "github.com/acme/api")
+	assert.ErrorIs(t, err, tenancy.ErrCrossTenant, "another tenant's project")
+	_, err = upsert(rival)
+	assert.ErrorIs(t, err, tenancy.ErrCrossTenant, "every project")
+	_, err = upsert(rival, "*")
+	assert.ErrorIs(t, err, tenancy.ErrCrossTenant)
+
+	result, err := upsert(acme, "github.com/acme/api")
+	require.NoError(t, err)
+	owned := result.(map[string]interface{})["subscription"].(*digest.Subscription)
+	assert.Equal(t, "api_key:acme", owned.Tenant.ID)
+
+	result, err = ms.handleNotificationSubscriptions(rival, map[string]interface{}{"operation": "list"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.(map[string]interface{})["count"], "other tenants' subscriptions are hidden")
+	_, err = ms.handleNotificationSubscriptions(rival, map[string]interface{}{"operation": "delete", "subscription_id": owned.ID})
+	assert.Error(t, err)
+	result, err = ms.handleNotificationSubscriptions(acme, map[string]interface{}{"operation": "list"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.(map[string]interface{})["count"])
+	_, err = ms.handleScheduleDigest(rival, map[string]interface{}{"repository": "github.com/acme/api", "targets": webhook})
+	assert.ErrorIs(t, err, tenancy.ErrCrossTenant, "digests of another tenant's project")
+}
@@ -0,0 +1,55 @@
+package mcp
+
+import (
+	"context"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/security"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+// ToolAnnotator tells read-only tools from the others
+type ToolAnnotator interface {
+	ToolAnnotationsFor(name string) (ToolAnnotations, bool)
+}
+
+// ToolScope is the OAuth scope a tool needs: the configured one, else the
+// read scope for read-only tools and the write scope for the others
+func ToolScope(cfg *config.OAuthConfig, tools ToolAnnotator, tool string) string {
+	if scope, ok := cfg.ToolScopes[tool]; ok {
+		return scope
+	}
+	if annotations, ok := tools.ToolAnnotationsFor(tool); ok && annotations.ReadOnlyHint != nil && *annotations.ReadOnlyHint {
+		return cfg.ReadScope
+	}
+	return cfg.WriteScope
+}
+
+// OAuthScopeMiddleware rejects tools/call requests whose bearer token lacks
+// the scope of the tool. The HTTP endpoints check scopes before a request
+// reaches the server, but WebSocket connections call tools after the
+// upgrade, so every call is checked again here against the token the
+// connection was opened with. Requests without a token are not affected.
+func OAuthScopeMiddleware(cfg *config.OAuthConfig, tools ToolAnnotator) Middleware {
+	return ForMethods(func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+			claims := security.TokenClaimsFrom(ctx)
+			if claims == nil {
+				return next(ctx, req)
+			}
+
+			tool := RequestTarget(req)
+			if scope := ToolScope(cfg, tools, tool); !claims.HasScope(scope) {
+				logging.Warn("MCP request for a tool its bearer token has no scope for", "subject", claims.Subject, "tool", tool, "scope", scope)
+				return ErrorResponse(req, ForbiddenCode, "Insufficient scope for this tool", map[string]interface{}{
+					"code":  "INSUFFICIENT_SCOPE",
+					"tool":  tool,
+					"scope": scope,
+				})
+			}
+			return next(ctx, req)
+		}
+	}, "tools/call")
+}
@@ -0,0 +1,50 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/security"
+
+	"github.com/fredcamaral/gomcp-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuthScopeMiddleware(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	for _, name := range []string{"memory_read", "memory_create", "auth_list_keys"} {
+		ms.addTool(mcp.NewTool(name, name, mcp.ObjectSchema(name, map[string]interface{}{}, nil)),
+			mcp.ToolHandlerFunc(func(context.Context, map[string]interface{}) (interface{}, error) { return "ok", nil }))
+	}
+	cfg := &config.OAuthConfig{
+		ReadScope:  "memory:read",
+		WriteScope: "memory:write",
+		ToolScopes: map[string]string{"auth_list_keys": "memory:admin"},
+	}
+	ms.Use(OAuthScopeMiddleware(cfg, ms))
+	reader := security.WithTokenClaims(context.Background(), &security.TokenClaims{Subject: "jane", Scopes: []string{"memory:read", "memory:write"}})
+
+	tests := []struct {
+		ctx     context.Context
+		tool    string
+		allowed bool
+	}{
+		{reader, "memory_read", true},
+		{reader, "memory_create", true},
+		{reader, "auth_list_keys", false},
+		{security.WithTokenClaims(context.Background(), &security.TokenClaims{Scopes: []string{"memory:read"}}), "memory_create", false},
+		{context.Background(), "auth_list_keys", true},
+	}
+	for _, tt := range tests {
+		resp := ms.HandleRequest(tt.ctx, toolCallRequest(tt.tool, nil))
+		if tt.allowed {
+			assert.Nil(t, resp.Error, tt.tool)
+			continue
+		}
+		require.NotNil(t, resp.Error, tt.tool)
+		assert.Equal(t, ForbiddenCode, resp.Error.Code)
+		assert.Equal(t, "INSUFFICIENT_SCOPE", resp.Error.Data.(map[string]interface{})["code"])
+	}
+}
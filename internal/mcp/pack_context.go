@@ -0,0 +1,169 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/packing"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/fredcamaral/gomcp-sdk"
+)
+
+const (
+	// defaultPackCandidates is the number of memories considered for packing
+	defaultPackCandidates = 50
+
+	// maxPackCandidates bounds the candidates a caller may request
+	maxPackCandidates = 200
+
+	// packScanFactor is how many more recent memories than candidates are
+	// read, so a type filter still leaves enough to fill the budget
+	packScanFactor = 4
+)
+
+// registerPackContextTool registers memory_pack_context
+func (ms *MemoryServer) registerPackContextTool() {
+	ms.addTool(mcp.NewTool(
+		"memory_pack_context",
+		"Pack the most useful memories of a repository into a ready-to-insert context block that fits a token budget for the given model. Memories are deduplicated, weighted by relevance, recency and priority, and included in full or as summaries when space is short. Session summaries come first.",
+		mcp.ObjectSchema("Context packing parameters", map[string]interface{}{
+			"repository": map[string]interface{}{
+				"type":        "string",
+				"description": "Repository URL (required) - e.g. 'github.com/user/repo'",
+			},
+			"query": map[string]interface{}{
+				"type":        "string",
+				"description": "What the context is for. When omitted, the most recent memories are packed",
+			},
+			"token_budget": map[string]interface{}{
+				"type":        "integer",
+				"default":     packing.DefaultBudget,
+				"description": "Maximum tokens for the packed context, capped at the model's context window",
+			},
+			"model": map[string]interface{}{
+				"type":        "string",
+				"description": "Target model name used for token estimation, e.g. 'claude-3-5-sonnet', 'gpt-4o', 'gemini-1.5-pro'",
+			},
+			"max_candidates": map[string]interface{}{
+				"type":        "integer",
+				"default":     defaultPackCandidates,
+				"description": "Number of memories considered before packing (max 200)",
+			},
+			"recency_half_life_days": map[string]interface{}{
+				"type":        "number",
+				"default":     14,
+				"description": "Age in days at which a memory's recency boost halves",
+			},
+			"types": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Only pack memories of these chunk types",
+			},
+		}, []string{"repository"}),
+	), mcp.ToolHandlerFunc(ms.handlePackContext))
+}
+
+// handlePackContext selects candidate memories and packs them into a context block
+func (ms *MemoryServer) handlePackContext(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: memory_pack_context called", "args", args)
+
+	repository, ok := args["repository"].(string)
+	if !ok || repository == "" {
+		return nil, errors.New("repository parameter is required. Example: {\"repository\": \"github.com/user/repo\", \"query\": \"authentication\", \"token_budget\": 4000, \"model\": \"claude-3-5-sonnet\"}")
+	}
+
+	limit := defaultPackCandidates
+	if l, ok := args["max_candidates"].(float64); ok && l > 0 {
+		limit = min(int(l), maxPackCandidates)
+	}
+
+	var chunkTypes []types.ChunkType
+	if rawTypes, ok := args["types"].([]interface{}); ok {
+		for _, raw := range rawTypes {
+			if t, ok := raw.(string); ok {
+				chunkTypes = append(chunkTypes, types.ChunkType(t))
+			}
+		}
+	}
+
+	query, _ := args["query"].(string)
+	candidates, err := ms.packCandidates(ctx, repository, query, chunkTypes, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := packing.Options{Now: time.Now()}
+	opts.Model, _ = args["model"].(string)
+	if budget, ok := args["token_budget"].(float64); ok {
+		opts.Budget = int(budget)
+	}
+	if days, ok := args["recency_half_life_days"].(float64); ok && days > 0 {
+		opts.RecencyHalfLife = time.Duration(days * float64(24*time.Hour))
+	}
+
+	result := packing.Pack(candidates, opts)
+
+	return map[string]interface{}{
+		"status":             "success",
+		"repository":         repository,
+		"query":              query,
+		"model":              result.Model,
+		"context":            result.Context,
+		"token_budget":       result.Budget,
+		"tokens_used":        result.TokensUsed,
+		"items":              result.Items,
+		"candidates":         len(candidates),
+		"duplicates_removed": result.Duplicates,
+		"dropped":            result.Dropped,
+	}, nil
+}
+
+// packCandidates returns memories to pack: search hits for a query, otherwise
+// the repository's most recent memories outside the trash with equal relevance
+func (ms *MemoryServer) packCandidates(ctx context.Context, repository, query string, chunkTypes []types.ChunkType, limit int) ([]types.SearchResult, error) {
+	if query != "" {
+		memQuery := types.MemoryQuery{
+			Query: query,
+			Types: chunkTypes,
+			Limit: limit,
+		}
+		if repository != GlobalRepository {
+			memQuery.Repository = &repository
+		}
+
+		embeddings, err := ms.container.GetEmbeddingService().GenerateEmbedding(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate embeddings: %w", err)
+		}
+		results, err := ms.container.GetVectorStore().Search(ctx, &memQuery, embeddings)
+		if err != nil {
+			return nil, fmt.Errorf("search failed: %w", err)
+		}
+		return results.Results, nil
+	}
+
+	chunks, err := ms.liveChunks(ctx, repository, limit*packScanFactor)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make(map[types.ChunkType]bool, len(chunkTypes))
+	for _, t := range chunkTypes {
+		allowed[t] = true
+	}
+	results := make([]types.SearchResult, 0, min(len(chunks), limit))
+	for i := range chunks {
+		if len(allowed) > 0 && !allowed[chunks[i].Type] {
+			continue
+		}
+		results = append(results, types.SearchResult{Chunk: chunks[i], Score: 1.0})
+		if len(results) == limit {
+			break
+		}
+	}
+	return results, nil
+}
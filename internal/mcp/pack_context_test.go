@@ -0,0 +1,68 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/packing"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackContext(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	task := newTaskChunk(t, "github.com/acme/api", types.TaskStatusTodo)
+	other := newTaskChunk(t, "github.com/acme/other", types.TaskStatusTodo)
+	trashed := newTaskChunk(t, "github.com/acme/api", types.TaskStatusTodo)
+	for _, c := range []*types.ConversationChunk{task, other, trashed} {
+		require.NoError(t, store.Store(ctx, c))
+	}
+
+	ms := newCompositeTestServer(t, store)
+	require.NoError(t, ms.trashChunk(ctx, trashed, time.Now()))
+	result, err := ms.handlePackContext(ctx, map[string]interface{}{
+		"repository":   "github.com/acme/api",
+		"model":        "claude-3-5-sonnet",
+		"token_budget": float64(500),
+	})
+	require.NoError(t, err)
+
+	response := result.(map[string]interface{})
+	items := response["items"].([]packing.Item)
+	require.Len(t, items, 1)
+	assert.Equal(t, task.ID, items[0].ID)
+	assert.Contains(t, response["context"], "TASK: test")
+	assert.Equal(t, 500, response["token_budget"])
+	assert.LessOrEqual(t, response["tokens_used"], 500)
+
+	_, err = ms.handlePackContext(ctx, map[string]interface{}{})
+	assert.Error(t, err)
+}
+
+func TestPackContextFillsCandidatesPastFilteredTypes(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	task := newTaskChunk(t, "github.com/acme/api", types.TaskStatusTodo)
+	task.Timestamp = time.Now().Add(-time.Hour)
+	discussion := newReportChunk(t, "session-1", "We talked about caching", types.ChunkTypeDiscussion, types.ChunkMetadata{})
+	for _, c := range []*types.ConversationChunk{task, discussion} {
+		require.NoError(t, store.Store(ctx, c))
+	}
+
+	ms := newCompositeTestServer(t, store)
+	result, err := ms.handlePackContext(ctx, map[string]interface{}{
+		"repository":     "github.com/acme/api",
+		"max_candidates": float64(1),
+		"types":          []interface{}{string(types.ChunkTypeTask)},
+	})
+	require.NoError(t, err)
+
+	items := result.(map[string]interface{})["items"].([]packing.Item)
+	require.Len(t, items, 1, "an older task still fills the candidate left by a newer discussion")
+	assert.Equal(t, task.ID, items[0].ID)
+}
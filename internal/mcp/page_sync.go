@@ -0,0 +1,192 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/pagesync"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/fredcamaral/gomcp-sdk"
+)
+
+// pageSyncCaptureTool names the importer in the provenance of page sections
+const pageSyncCaptureTool = "page_sync"
+
+// pageIngester stores the sections of synced pages as memories
+type pageIngester struct {
+	ms *MemoryServer
+}
+
+// IngestPage stores each section of a page as a memory, then trashes the
+// memories imported from its previous version
+func (p pageIngester) IngestPage(ctx context.Context, spec *pagesync.SourceSpec, page *pagesync.Page, previous []string) ([]string, error) {
+	sections := pagesync.SplitSections(page.Markdown, pagesync.DefaultMaxSectionRunes)
+	sessionID := p.ms.createRepositoryScopedSessionID(spec.Repository, spec.Type+"-"+page.ID)
+
+	chunkIDs := make([]string, 0, len(sections))
+	for i, section := range sections {
+		metadata := types.ChunkMetadata{
+			Repository: spec.Repository,
+			Tags:       []string{"imported", spec.Type, "source:" + spec.Type},
+			Provenance: &types.Provenance{
+				SourceSystem: spec.Type,
+				SourceURL:    page.URL,
+				Author:       page.Author,
+				CaptureTool:  pageSyncCaptureTool,
+			},
+		}
+
+		// Lead with the page title so sections stay findable on their own
+		content := "# " + page.Title + "\n\n" + section.Text
+		if section.Heading != "" && section.Heading != page.Title {
+			content = "# " + page.Title + " / " + section.Heading + "\n\n" + section.Text
+		}
+
+		chunk, err := p.ms.container.GetChunkingService().CreateChunk(ctx, sessionID, content, &metadata)
+		if err != nil {
+			return chunkIDs, fmt.Errorf("failed to create chunk for section %d: %w", i+1, err)
+		}
+		chunk.Type = types.ChunkTypeAnalysis
+
+		// Chunking rebuilds extended metadata, so page references are added after it
+		if chunk.Metadata.ExtendedMetadata == nil {
+			chunk.Metadata.ExtendedMetadata = make(map[string]interface{})
+		}
+		chunk.Metadata.ExtendedMetadata["page_source"] = spec.Name
+		chunk.Metadata.ExtendedMetadata["page_id"] = page.ID
+		chunk.Metadata.ExtendedMetadata["page_title"] = page.Title
+		chunk.Metadata.ExtendedMetadata["page_section"] = i + 1
+		if page.Space != "" {
+			chunk.Metadata.ExtendedMetadata["page_space"] = page.Space
+		}
+		p.ms.setPersonReference(&chunk.Metadata, types.EMKeyAuthorPersonID, page.Author)
+
+		if err := p.ms.processAndStoreChunk(ctx, chunk); err != nil {
+			return chunkIDs, fmt.Errorf("failed to store section %d: %w", i+1, err)
+		}
+		chunkIDs = append(chunkIDs, chunk.ID)
+	}
+
+	// The previous version is only replaced once the new one is stored
+	store := p.ms.container.GetVectorStore()
+	now := time.Now()
+	for _, id := range previous {
+		chunk, err := store.GetByID(ctx, id)
+		if err != nil || chunk.IsDeleted() {
+			continue
+		}
+		if err := p.ms.trashChunk(ctx, chunk, now); err != nil {
+			logging.Warn("Failed to trash previous page section", "page", page.ID, "chunk_id", id, "error", err)
+		}
+	}
+	return chunkIDs, nil
+}
+
+// initPageSync creates the page syncer and registers the configured sources
+func (ms *MemoryServer) initPageSync(cfg *config.Config) {
+	if !cfg.PageSync.Enabled {
+		return
+	}
+
+	ms.pageSyncer = pagesync.NewSyncer(cfg.PageSync.StatePath, pageIngester{ms: ms})
+	if err := ms.pageSyncer.Load(); err != nil {
+		logging.Error("Failed to load page sync state", "path", cfg.PageSync.StatePath, "error", err)
+	}
+	if cfg.PageSync.SourcesFile == "" {
+		return
+	}
+
+	specs, err := pagesync.LoadSources(cfg.PageSync.SourcesFile)
+	if err != nil {
+		logging.Error("Failed to load page sync sources", "file", cfg.PageSync.SourcesFile, "error", err)
+		return
+	}
+	credentials := &pagesync.Credentials{
+		NotionToken:       cfg.PageSync.NotionToken,
+		ConfluenceBaseURL: cfg.PageSync.ConfluenceBaseURL,
+		ConfluenceEmail:   cfg.PageSync.ConfluenceEmail,
+		ConfluenceToken:   cfg.PageSync.ConfluenceToken,
+	}
+	for i := range specs {
+		source, err := pagesync.NewSource(&specs[i], credentials)
+		if err != nil {
+			logging.Warn("Skipping page sync source", "source", specs[i].Name, "error", err)
+			continue
+		}
+		if err := ms.pageSyncer.AddSource(&specs[i], source); err != nil {
+			logging.Warn("Skipping page sync source", "source", specs[i].Name, "error", err)
+		}
+	}
+}
+
+// registerPageSyncTool registers system_page_sync
+func (ms *MemoryServer) registerPageSyncTool() {
+	ms.addTool(mcp.NewTool(
+		"system_page_sync",
+		"Inspect and trigger the import of Notion and Confluence pages. Pages are converted to Markdown, split into sections at headings and stored as memories with provenance pointing back at the page; pages edited upstream are re-imported and their previous sections moved to trash. Sources are configured by the operator and synced periodically. Operations: list (sources and sync progress), sync (sync one source now; full re-imports every page). Only callers owning every project may use it.",
+		mcp.ObjectSchema("Page sync parameters", map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"list", "sync"},
+				"description": "Page sync operation",
+			},
+			"source": map[string]interface{}{
+				"type":        "string",
+				"description": "Name of the source to sync (sync)",
+			},
+			"full": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Re-import every page instead of those edited since the last sync (sync)",
+				"default":     false,
+			},
+		}, []string{"operation"}),
+	), mcp.ToolHandlerFunc(ms.handlePageSync))
+}
+
+// handlePageSync lists page sync sources or syncs one on demand
+func (ms *MemoryServer) handlePageSync(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: system_page_sync called", "args", args)
+
+	if ms.pageSyncer == nil {
+		return nil, errors.New("page sync is disabled: set MCP_MEMORY_PAGE_SYNC_ENABLED=true and MCP_MEMORY_PAGE_SYNC_SOURCES_FILE")
+	}
+	// Sources are configured by the operator and write into their projects
+	// whoever triggers them
+	if err := checkOperator(ctx, "system_page_sync"); err != nil {
+		return nil, err
+	}
+
+	operation, _ := args["operation"].(string)
+	switch operation {
+	case "list":
+		sources := ms.pageSyncer.Sources()
+		return map[string]interface{}{
+			"status":    "success",
+			"operation": operation,
+			"sources":   sources,
+			"count":     len(sources),
+		}, nil
+	case "sync":
+		source, _ := args["source"].(string)
+		if source == "" {
+			return nil, errors.New("source is required for sync. Example: {\"operation\": \"sync\", \"source\": \"engineering-wiki\"}")
+		}
+		full, _ := args["full"].(bool)
+		result, err := ms.pageSyncer.Sync(ctx, source, full)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"status":    "success",
+			"operation": operation,
+			"result":    result,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown operation %q: use list or sync", operation)
+	}
+}
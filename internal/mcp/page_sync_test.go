@@ -0,0 +1,121 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/chunking"
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/pagesync"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/tenancy"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticPageSource serves the same pages on every sync
+type staticPageSource struct {
+	pages []pagesync.Page
+}
+
+func (s *staticPageSource) Pages(context.Context, time.Time) ([]pagesync.Page, error) {
+	return s.pages, nil
+}
+
+func newPageSyncTestServer(t *testing.T, store storage.VectorStore) *MemoryServer {
+	t.Helper()
+	ms := newCompositeTestServer(t, store)
+	ms.container.ChunkingService = chunking.NewService(&config.DefaultConfig().Chunking, staticEmbeddingService{})
+	ms.pageSyncer = pagesync.NewSyncer("", pageIngester{ms: ms})
+	return ms
+}
+
+func TestPageIngester_StoresSectionsWithProvenance(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	ms := newPageSyncTestServer(t, store)
+
+	spec := &pagesync.SourceSpec{Name: "wiki", Type: pagesync.SourceConfluence, Repository: "github.com/acme/api", Spaces: []string{"ENG"}}
+	page := &pagesync.Page{
+		ID:        "42",
+		Title:     "Deploys",
+		URL:       "https://acme.atlassian.net/wiki/spaces/ENG/pages/42",
+		Space:     "ENG",
+		Author:    "Bruno <bruno@acme.io>",
+		Markdown:  "## Steps\n\n" + strings.Repeat("Roll out one region at a time. ", 20) + "\n\n## Rollback\n\n" + strings.Repeat("Revert the release tag. ", 20),
+		UpdatedAt: time.Now().Add(-time.Hour),
+	}
+
+	ids, err := pageIngester{ms: ms}.IngestPage(ctx, spec, page, nil)
+	require.NoError(t, err)
+	require.Len(t, ids, 2)
+
+	chunk, err := store.GetByID(ctx, ids[1])
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(chunk.Content, "# Deploys / Rollback\n\n"))
+	assert.Equal(t, types.ChunkTypeAnalysis, chunk.Type)
+	assert.Equal(t, "github.com/acme/api", chunk.Metadata.Repository)
+	assert.Contains(t, chunk.Metadata.Tags, "source:confluence")
+	require.NotNil(t, chunk.Metadata.Provenance)
+	assert.Equal(t, "confluence", chunk.Metadata.Provenance.SourceSystem)
+	assert.Equal(t, page.URL, chunk.Metadata.Provenance.SourceURL)
+	assert.Equal(t, page.Author, chunk.Metadata.Provenance.Author)
+	assert.Equal(t, pageSyncCaptureTool, chunk.Metadata.Provenance.CaptureTool)
+	assert.Equal(t, "42", chunk.Metadata.ExtendedMetadata["page_id"])
+	assert.Equal(t, "ENG", chunk.Metadata.ExtendedMetadata["page_space"])
+
+	// Importing a new version trashes the sections of the previous one
+	page.Markdown = "Single section now"
+	updated, err := pageIngester{ms: ms}.IngestPage(ctx, spec, page, ids)
+	require.NoError(t, err)
+	require.Len(t, updated, 1)
+	for _, id := range ids {
+		old, err := store.GetByID(ctx, id)
+		require.NoError(t, err)
+		assert.True(t, old.IsDeleted())
+	}
+	current, err := store.GetByID(ctx, updated[0])
+	require.NoError(t, err)
+	assert.False(t, current.IsDeleted())
+}
+
+func TestHandlePageSync(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	ms := newPageSyncTestServer(t, store)
+	source := &staticPageSource{pages: []pagesync.Page{
+		{ID: "p1", Title: "Onboarding", URL: "https://notion.so/p1", Markdown: "Clone the repo and run make setup.", UpdatedAt: time.Now().Add(-time.Hour)},
+	}}
+	require.NoError(t, ms.pageSyncer.AddSource(&pagesync.SourceSpec{Name: "notes", Type: pagesync.SourceNotion, Repository: "github.com/acme/api"}, source))
+
+	result, err := ms.handlePageSync(ctx, map[string]interface{}{"operation": "sync", "source": "notes"})
+	require.NoError(t, err)
+	synced := result.(map[string]interface{})["result"].(*pagesync.Result)
+	assert.Equal(t, 1, synced.Imported)
+	assert.Equal(t, 1, synced.Chunks)
+
+	result, err = ms.handlePageSync(ctx, map[string]interface{}{"operation": "sync", "source": "notes"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.(map[string]interface{})["result"].(*pagesync.Result).Unchanged)
+
+	result, err = ms.handlePageSync(ctx, map[string]interface{}{"operation": "list"})
+	require.NoError(t, err)
+	sources := result.(map[string]interface{})["sources"].([]pagesync.SourceStatus)
+	require.Len(t, sources, 1)
+	assert.Equal(t, 1, sources[0].Pages)
+
+	_, err = ms.handlePageSync(ctx, map[string]interface{}{"operation": "sync"})
+	assert.ErrorContains(t, err, "source is required")
+
+	tenant := tenancy.WithTenant(ctx, &tenancy.Tenant{ID: "api_key:acme", Projects: []string{"github.com/acme/api"}})
+	_, err = ms.handlePageSync(tenant, map[string]interface{}{"operation": "sync", "source": "notes"})
+	assert.ErrorIs(t, err, tenancy.ErrCrossTenant)
+
+	ms.pageSyncer = nil
+	_, err = ms.handlePageSync(ctx, map[string]interface{}{"operation": "list"})
+	assert.ErrorContains(t, err, "page sync is disabled")
+}
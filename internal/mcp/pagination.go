@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"errors"
+	"sort"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+// defaultListPageSize is how many items a list page holds without configuration
+const defaultListPageSize = 100
+
+// errInvalidCursor is returned for a cursor this server did not issue
+var errInvalidCursor = errors.New("invalid cursor")
+
+// paginateListResponse cuts the result of tools/list, resources/list or
+// prompts/list down to the page after the request's cursor, sorted by name
+// or URI, and sets nextCursor when more items follow. The cursor is the key
+// of the last item listed, so pages stay consistent when items are added or
+// removed between requests.
+func (ms *MemoryServer) paginateListResponse(req *protocol.JSONRPCRequest, resp *protocol.JSONRPCResponse) *protocol.JSONRPCResponse {
+	result, ok := resp.Result.(map[string]interface{})
+	if !ok {
+		return resp
+	}
+	cursor, _ := requestParams(req)["cursor"].(string)
+	size := ms.listPageSize()
+
+	var next string
+	var err error
+	switch req.Method {
+	case "tools/list":
+		tools, ok := result["tools"].([]protocol.Tool)
+		if !ok {
+			return resp
+		}
+		result["tools"], next, err = paginate(tools, func(tool *protocol.Tool) string { return tool.Name }, cursor, size)
+	case "resources/list":
+		resources, ok := result["resources"].([]protocol.Resource)
+		if !ok {
+			return resp
+		}
+		result["resources"], next, err = paginate(resources, func(resource *protocol.Resource) string { return resource.URI }, cursor, size)
+	case "prompts/list":
+		prompts, ok := result["prompts"].([]protocol.Prompt)
+		if !ok {
+			return resp
+		}
+		result["prompts"], next, err = paginate(prompts, func(prompt *protocol.Prompt) string { return prompt.Name }, cursor, size)
+	default:
+		return resp
+	}
+	if err != nil {
+		return ErrorResponse(req, protocol.InvalidParams, "Invalid params", err.Error())
+	}
+
+	if next != "" {
+		result["nextCursor"] = next
+	}
+	return resp
+}
+
+// paginate sorts items by key and returns the page of up to size items
+// following cursor, with the cursor of the next page or "" on the last page.
+// A size of 0 returns every item.
+func paginate[T any](items []T, key func(*T) string, cursor string, size int) ([]T, string, error) {
+	sorted := append([]T(nil), items...)
+	sort.Slice(sorted, func(i, j int) bool { return key(&sorted[i]) < key(&sorted[j]) })
+
+	start := 0
+	if cursor != "" {
+		after, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		start = sort.Search(len(sorted), func(i int) bool { return key(&sorted[i]) > after })
+	}
+	if size <= 0 || len(sorted)-start <= size {
+		return sorted[start:], "", nil
+	}
+
+	page := sorted[start : start+size]
+	return page, encodeCursor(key(&page[len(page)-1])), nil
+}
+
+// encodeCursor makes the opaque cursor for the page after key
+func encodeCursor(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+// decodeCursor returns the key a cursor continues after
+func decodeCursor(cursor string) (string, error) {
+	key, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(key) == 0 {
+		return "", errInvalidCursor
+	}
+	return string(key), nil
+}
+
+// listPageSize returns how many items a list page holds; 0 lists everything
+func (ms *MemoryServer) listPageSize() int {
+	if ms.container == nil || ms.container.Config == nil {
+		return defaultListPageSize
+	}
+	return ms.container.Config.Server.ListPageSize
+}
@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/di"
+
+	"github.com/fredcamaral/gomcp-sdk"
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listRequest(method, cursor string) *protocol.JSONRPCRequest {
+	params := map[string]interface{}{}
+	if cursor != "" {
+		params["cursor"] = cursor
+	}
+	return &protocol.JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: method, Params: params}
+}
+
+func TestListPagination_Tools(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	ms.container = &di.Container{Config: config.DefaultConfig()}
+	ms.container.Config.Server.ListPageSize = 2
+	for _, name := range []string{"delta", "alpha", "charlie", "bravo"} {
+		ms.addTool(mcp.NewTool(name, name, mcp.ObjectSchema(name, map[string]interface{}{}, nil)),
+			mcp.ToolHandlerFunc(func(context.Context, map[string]interface{}) (interface{}, error) { return "ok", nil }))
+	}
+	ms.RemoveTool("charlie")
+
+	var names []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 5, "pagination must end")
+		resp := ms.HandleRequest(context.Background(), listRequest("tools/list", cursor))
+		require.Nil(t, resp.Error)
+		result := resp.Result.(map[string]interface{})
+		tools := result["tools"].([]AnnotatedTool)
+		assert.LessOrEqual(t, len(tools), 2)
+		for i := range tools {
+			names = append(names, tools[i].Name)
+		}
+		next, ok := result["nextCursor"].(string)
+		if !ok {
+			break
+		}
+		cursor = next
+	}
+	assert.Equal(t, []string{"alpha", "bravo", "delta", "echo"}, names, "pages are sorted by name and skip removed tools")
+}
+
+func TestListPagination_ResourcesAndPrompts(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	ms.container = &di.Container{Config: config.DefaultConfig()}
+	ms.container.Config.Server.ListPageSize = 3
+	for i := 0; i < 5; i++ {
+		ms.mcpServer.AddResource(mcp.NewResource(fmt.Sprintf("memory://test/%d", i), "test", "test", "text/plain"),
+			mcp.ResourceHandlerFunc(func(context.Context, string) ([]protocol.Content, error) { return nil, nil }))
+		ms.mcpServer.AddPrompt(mcp.NewPrompt(fmt.Sprintf("prompt-%d", i), "test", nil),
+			mcp.PromptHandlerFunc(func(context.Context, map[string]interface{}) ([]protocol.Content, error) { return nil, nil }))
+	}
+
+	first := ms.HandleRequest(context.Background(), listRequest("resources/list", "")).Result.(map[string]interface{})
+	resources := first["resources"].([]protocol.Resource)
+	require.Len(t, resources, 3)
+	assert.Equal(t, "memory://test/0", resources[0].URI)
+	second := ms.HandleRequest(context.Background(), listRequest("resources/list", first["nextCursor"].(string))).Result.(map[string]interface{})
+	resources = second["resources"].([]protocol.Resource)
+	require.Len(t, resources, 2)
+	assert.Equal(t, "memory://test/3", resources[0].URI)
+	assert.NotContains(t, second, "nextCursor", "the last page has no cursor")
+
+	prompts := ms.HandleRequest(context.Background(), listRequest("prompts/list", "")).Result.(map[string]interface{})
+	assert.Len(t, prompts["prompts"], 3)
+	assert.NotEmpty(t, prompts["nextCursor"])
+
+	// A page size of 0 lists everything at once
+	ms.container.Config.Server.ListPageSize = 0
+	all := ms.HandleRequest(context.Background(), listRequest("prompts/list", "")).Result.(map[string]interface{})
+	assert.Len(t, all["prompts"], 5)
+	assert.NotContains(t, all, "nextCursor")
+}
+
+func TestListPagination_InvalidCursor(t *testing.T) {
+	ms := newMiddlewareTestServer()
+
+	resp := ms.HandleRequest(context.Background(), listRequest("tools/list", "not base64!"))
+
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, protocol.InvalidParams, resp.Error.Code)
+}
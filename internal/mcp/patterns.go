@@ -0,0 +1,145 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"lerian-mcp-memory/internal/intelligence"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/pkg/types"
+)
+
+// maxPatternChunks bounds how many memories a pattern detection run mines
+const maxPatternChunks = 2000
+
+// patternDetection is the result of mining a repository for patterns
+type patternDetection struct {
+	patterns       []intelligence.Pattern
+	chunksAnalyzed int
+	chainsAnalyzed int
+}
+
+// handleGetPatterns mines a repository's memories within a timeframe for
+// recurring error→fix pairs and tool chains, persists them as named
+// patterns and returns them, most frequent first
+func (ms *MemoryServer) handleGetPatterns(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: memory_get_patterns called", "params", params)
+
+	repository, ok := params["repository"].(string)
+	if !ok || repository == "" {
+		logging.Error("memory_get_patterns failed: missing repository parameter")
+		return nil, errors.New("repository is required")
+	}
+
+	timeframe := types.TimeframeMonth
+	if tf, ok := params["timeframe"].(string); ok && tf != "" {
+		timeframe = tf
+	}
+
+	options := intelligence.DefaultDetectionOptions()
+	if minFrequency, ok := params["min_frequency"].(float64); ok {
+		if minFrequency < 2 {
+			return nil, NewToolError(ToolErrorInvalidParameter, "min_frequency must be at least 2").
+				WithParameter("min_frequency").
+				WithSuggestion("A pattern is a sequence seen at least twice")
+		}
+		options.MinFrequency = int(minFrequency)
+	}
+
+	var patternType intelligence.PatternType
+	if value, ok := params["pattern_type"].(string); ok && value != "" {
+		patternType = intelligence.PatternType(value)
+		if patternType != intelligence.PatternTypeErrorResolution && patternType != intelligence.PatternTypeWorkflow {
+			return nil, NewToolError(ToolErrorInvalidParameter, fmt.Sprintf("invalid pattern_type %q", value)).
+				WithParameter("pattern_type").
+				WithSuggestion("Use error_resolution or workflow")
+		}
+	}
+
+	detection, err := ms.detectPatterns(ctx, repository, ms.calculateTimeframeBoundaries(timeframe), options)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns := make([]intelligence.Pattern, 0, len(detection.patterns))
+	for i := range detection.patterns {
+		if patternType == "" || detection.patterns[i].Type == patternType {
+			patterns = append(patterns, detection.patterns[i])
+		}
+	}
+
+	return map[string]interface{}{
+		"repository":            repository,
+		"timeframe":             timeframe,
+		"patterns":              patterns,
+		"total_patterns":        len(patterns),
+		"total_chunks_analyzed": detection.chunksAnalyzed,
+		"tool_chains_analyzed":  detection.chainsAnalyzed,
+	}, nil
+}
+
+// detectPatterns runs pattern detection over a repository's live memories
+// since a time, and over the tool sequences tracked for it. The global
+// repository mines every project.
+func (ms *MemoryServer) detectPatterns(ctx context.Context, repository string, since time.Time, options intelligence.DetectionOptions) (*patternDetection, error) {
+	scope := repository
+	if scope == GlobalMemoryRepository {
+		scope = GlobalRepository
+	}
+
+	all, err := ms.liveChunks(ctx, scope, maxPatternChunks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunks: %w", err)
+	}
+	chunks := make([]types.ConversationChunk, 0, len(all))
+	var chains []intelligence.ToolChain
+	for i := range all {
+		chunk := &all[i]
+		if chunk.Timestamp.Before(since) {
+			continue
+		}
+		chunks = append(chunks, *chunk)
+		if len(chunk.Metadata.ToolsUsed) >= 2 {
+			chains = append(chains, intelligence.ToolChain{
+				SessionID: chunk.SessionID,
+				ChunkIDs:  []string{chunk.ID},
+				Tools:     chunk.Metadata.ToolsUsed,
+				Success:   chunk.Metadata.Outcome == types.OutcomeSuccess,
+				Timestamp: chunk.Timestamp,
+			})
+		}
+	}
+
+	if analyzer := ms.container.GetPatternAnalyzer(); analyzer != nil {
+		for _, sequence := range analyzer.GetSequences() {
+			if scope != GlobalRepository && sequence.Repository != repository {
+				continue
+			}
+			if sequence.EndTime.Before(since) || len(sequence.Tools) < 2 {
+				continue
+			}
+			tools := make([]string, len(sequence.Tools))
+			for i := range sequence.Tools {
+				tools[i] = sequence.Tools[i].Tool
+			}
+			chains = append(chains, intelligence.ToolChain{
+				SessionID: sequence.SessionID,
+				Tools:     tools,
+				Success:   sequence.Outcome == types.OutcomeSuccess,
+				Timestamp: sequence.EndTime,
+			})
+		}
+	}
+
+	engine := ms.container.GetPatternEngine()
+	if engine == nil {
+		engine = intelligence.NewPatternEngine(nil)
+	}
+	patterns, err := engine.DetectPatterns(ctx, repository, chunks, chains, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect patterns: %w", err)
+	}
+	return &patternDetection{patterns: patterns, chunksAnalyzed: len(chunks), chainsAnalyzed: len(chains)}, nil
+}
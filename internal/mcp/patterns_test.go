@@ -0,0 +1,57 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/intelligence"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/workflow"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPatternsDetectsAndPersists(t *testing.T) {
+	ctx := context.Background()
+	ms := newCompositeTestServer(t, storage.NewLocalVectorStore(""))
+	patternStore := intelligence.NewFilePatternStore("")
+	ms.container.PatternEngine = intelligence.NewPatternEngine(patternStore)
+	ms.container.PatternAnalyzer = workflow.NewPatternAnalyzer()
+	store := ms.container.GetVectorStore()
+
+	for _, session := range []string{"s1", "s2"} {
+		require.NoError(t, store.Store(ctx, newReportChunk(t, session, "go test fails\npanic: runtime error: invalid memory address", types.ChunkTypeProblem, types.ChunkMetadata{})))
+		require.NoError(t, store.Store(ctx, newReportChunk(t, session, "Initialize the client before use", types.ChunkTypeSolution, types.ChunkMetadata{
+			Outcome:   types.OutcomeSuccess,
+			ToolsUsed: []string{"grep", "edit", "test"},
+		})))
+	}
+	require.NoError(t, store.Store(ctx, newReportChunk(t, "s3", "Other project problem\npanic: runtime error: invalid memory address", types.ChunkTypeProblem, types.ChunkMetadata{Repository: "github.com/acme/web"})))
+
+	result, err := ms.handleGetPatterns(ctx, map[string]interface{}{"repository": "github.com/acme/api"})
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	patterns := response["patterns"].([]intelligence.Pattern)
+	require.Len(t, patterns, 2)
+	assert.Equal(t, 4, response["total_chunks_analyzed"])
+	assert.Equal(t, 2, response["tool_chains_analyzed"])
+
+	stored, err := patternStore.ListPatterns(ctx, nil)
+	require.NoError(t, err)
+	assert.Len(t, stored, 2, "detected patterns are persisted")
+
+	result, err = ms.handleGetPatterns(ctx, map[string]interface{}{"repository": "github.com/acme/api", "pattern_type": "workflow"})
+	require.NoError(t, err)
+	patterns = result.(map[string]interface{})["patterns"].([]intelligence.Pattern)
+	require.Len(t, patterns, 1)
+	assert.Equal(t, "Tool chain: grep → edit → test", patterns[0].Name)
+
+	_, err = ms.handleGetPatterns(ctx, map[string]interface{}{"repository": "github.com/acme/api", "min_frequency": float64(1)})
+	assert.Equal(t, ToolErrorInvalidParameter, asToolError(err).Code)
+
+	result, err = ms.handleGetPatterns(ctx, map[string]interface{}{"repository": "github.com/acme/api", "min_frequency": float64(3)})
+	require.NoError(t, err)
+	assert.Empty(t, result.(map[string]interface{})["patterns"])
+}
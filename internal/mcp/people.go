@@ -0,0 +1,349 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"lerian-mcp-memory/internal/audit"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/people"
+	"lerian-mcp-memory/internal/tenancy"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/fredcamaral/gomcp-sdk"
+)
+
+// maxPeopleChunks bounds the memories scanned for contributions and merges
+const maxPeopleChunks = 10000
+
+// registerPeopleTool registers system_people
+func (ms *MemoryServer) registerPeopleTool() {
+	ms.addTool(mcp.NewTool(
+		"system_people",
+		"Manage the people behind memories. Chunk authors (provenance.author), task assignees and creators are resolved to people by name, alias or email, and audit events name the person who acted. Operations: list, get, upsert (create or update a person), resolve (find the person behind a name or email), merge (fold duplicate identities into one person and rewrite their references), contributions (per-person counts of authored memories and tasks). The directory is shared by every tenant, so only callers owning every project may upsert or merge, and list shows other tenants only the people their projects' memories refer to.",
+		mcp.ObjectSchema("People parameters", map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"list", "get", "upsert", "resolve", "merge", "contributions"},
+				"description": "People operation",
+			},
+			"person_id": map[string]interface{}{
+				"type":        "string",
+				"description": "Person to read or update (get, upsert), merge into (merge), or report on (contributions)",
+			},
+			"identity": map[string]interface{}{
+				"type":        "string",
+				"description": "Name, alias, email or \"Name <email>\" to look up (resolve), or to report on instead of person_id (contributions)",
+			},
+			"display_name": map[string]interface{}{
+				"type":        "string",
+				"description": "Name shown for the person (upsert)",
+			},
+			"aliases": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Other names the person appears under, such as usernames (upsert)",
+			},
+			"email": map[string]interface{}{
+				"type":        "string",
+				"description": "Email address; only its hash is stored (upsert)",
+			},
+			"merge_ids": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Duplicate people to merge into person_id (merge)",
+			},
+			"include_merged": map[string]interface{}{
+				"type":        "boolean",
+				"default":     false,
+				"description": "Also list people merged into others (list)",
+			},
+			"repository": map[string]interface{}{
+				"type":        "string",
+				"description": "Repository to report on; omit or use '_global' for all (contributions)",
+			},
+		}, []string{"operation"}),
+	), mcp.ToolHandlerFunc(ms.handlePeople))
+}
+
+// handlePeople manages the people directory and reports contributions
+func (ms *MemoryServer) handlePeople(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: system_people called", "args", args)
+
+	directory := ms.container.GetPeople()
+	if directory == nil {
+		return nil, errors.New("the people directory is not available")
+	}
+
+	operation, _ := args["operation"].(string)
+	personID, _ := args["person_id"].(string)
+
+	// The directory is shared by every tenant, and merges rewrite references
+	// in every tenant's memories
+	if operation == "upsert" || operation == "merge" {
+		if err := checkOperator(ctx, "system_people "+operation); err != nil {
+			return nil, err
+		}
+	}
+
+	switch operation {
+	case "list":
+		includeMerged, _ := args["include_merged"].(bool)
+		list := directory.List(includeMerged)
+		if tenant := tenancy.FromContext(ctx); tenant != nil && !tenant.AllowsAll() {
+			seen, err := ms.peopleSeenBy(ctx, directory, tenant)
+			if err != nil {
+				return nil, err
+			}
+			list = slices.DeleteFunc(list, func(person people.Person) bool {
+				return !seen[person.ID] && !seen[directory.Canonical(person.ID)]
+			})
+		}
+		return map[string]interface{}{
+			"status":    "success",
+			"operation": operation,
+			"people":    list,
+			"count":     len(list),
+		}, nil
+
+	case "get":
+		person, ok := directory.Get(personID)
+		if !ok {
+			return nil, fmt.Errorf("person %q not found. Example: {\"operation\": \"get\", \"person_id\": \"person_...\"}", personID)
+		}
+		return map[string]interface{}{
+			"status":    "success",
+			"operation": operation,
+			"person":    person,
+		}, nil
+
+	case "upsert":
+		displayName, _ := args["display_name"].(string)
+		email, _ := args["email"].(string)
+		saved, err := directory.Upsert(people.Person{
+			ID:          personID,
+			DisplayName: displayName,
+			Aliases:     extractStringArray(args["aliases"]),
+		}, email)
+		if err != nil {
+			return nil, err
+		}
+		ms.logPeopleChange(ctx, operation, saved, nil)
+		return map[string]interface{}{
+			"status":    "success",
+			"operation": operation,
+			"person":    saved,
+		}, nil
+
+	case "resolve":
+		identity, _ := args["identity"].(string)
+		if identity == "" {
+			return nil, errors.New("identity is required for resolve. Example: {\"operation\": \"resolve\", \"identity\": \"Jane Doe <jane@example.com>\"}")
+		}
+		person, found := directory.Resolve(identity)
+		response := map[string]interface{}{
+			"status":    "success",
+			"operation": operation,
+			"identity":  identity,
+			"found":     found,
+		}
+		if found {
+			response["person"] = person
+		}
+		return response, nil
+
+	case "merge":
+		mergeIDs := extractStringArray(args["merge_ids"])
+		if personID == "" || len(mergeIDs) == 0 {
+			return nil, errors.New("person_id and merge_ids are required for merge. Example: {\"operation\": \"merge\", \"person_id\": \"person_a\", \"merge_ids\": [\"person_b\"]}")
+		}
+		merged, err := directory.Merge(mergeIDs, personID)
+		if err != nil {
+			return nil, err
+		}
+		rewritten, err := ms.canonicalizePeopleReferences(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("merged people but failed to rewrite their references: %w", err)
+		}
+		ms.logPeopleChange(ctx, operation, merged, map[string]interface{}{
+			"merged_ids":       mergeIDs,
+			"chunks_rewritten": rewritten,
+		})
+		return map[string]interface{}{
+			"status":           "success",
+			"operation":        operation,
+			"person":           merged,
+			"merged_ids":       mergeIDs,
+			"chunks_rewritten": rewritten,
+		}, nil
+
+	case "contributions":
+		return ms.peopleContributions(ctx, directory, personID, args)
+
+	default:
+		return nil, fmt.Errorf("unknown operation %q: use list, get, upsert, resolve, merge or contributions", operation)
+	}
+}
+
+// peopleContributions reports what people contributed, for one person or everyone
+func (ms *MemoryServer) peopleContributions(ctx context.Context, directory *people.Store, personID string, args map[string]interface{}) (interface{}, error) {
+	if identity, _ := args["identity"].(string); identity != "" && personID == "" {
+		person, ok := directory.Resolve(identity)
+		if !ok {
+			return nil, fmt.Errorf("no person matches %q", identity)
+		}
+		personID = person.ID
+	}
+	repository, _ := args["repository"].(string)
+	if repository == "" {
+		repository = GlobalRepository
+	}
+
+	chunks, err := ms.liveChunks(ctx, repository, maxPeopleChunks)
+	if err != nil {
+		return nil, err
+	}
+	contributions := directory.Contributions(chunks)
+
+	if personID == "" {
+		return map[string]interface{}{
+			"status":        "success",
+			"operation":     "contributions",
+			"repository":    repository,
+			"contributions": contributions,
+			"people":        len(contributions),
+		}, nil
+	}
+
+	person, ok := directory.Get(personID)
+	if !ok {
+		return nil, fmt.Errorf("person %q not found", personID)
+	}
+	personID = directory.Canonical(personID)
+	contribution := people.Contribution{PersonID: personID, DisplayName: person.DisplayName}
+	for i := range contributions {
+		if contributions[i].PersonID == personID {
+			contribution = contributions[i]
+		}
+	}
+
+	response := map[string]interface{}{
+		"status":       "success",
+		"operation":    "contributions",
+		"repository":   repository,
+		"contribution": contribution,
+	}
+	if auditLogger := ms.container.GetAuditLogger(); auditLogger != nil {
+		events := 0
+		for _, id := range directory.MergedIDs(personID) {
+			found, err := auditLogger.Search(ctx, &audit.SearchCriteria{PersonID: id})
+			if err != nil {
+				return nil, fmt.Errorf("failed to search the audit log: %w", err)
+			}
+			events += len(found)
+		}
+		response["audit_events"] = events
+	}
+	return response, nil
+}
+
+// peopleSeenBy returns the people, and those they were merged into, that
+// the memories of the tenant's projects refer to
+func (ms *MemoryServer) peopleSeenBy(ctx context.Context, directory *people.Store, tenant *tenancy.Tenant) (map[string]bool, error) {
+	chunks, err := ms.liveChunks(ctx, GlobalRepository, maxPeopleChunks)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for i := range chunks {
+		if !tenant.Allows(chunks[i].Metadata.Repository) {
+			continue
+		}
+		for _, key := range people.ReferenceKeys {
+			if id := people.Reference(&chunks[i], key); id != "" {
+				seen[id] = true
+				seen[directory.Canonical(id)] = true
+			}
+		}
+	}
+	return seen, nil
+}
+
+// canonicalizePeopleReferences points the references of stored chunks to
+// merged people at the surviving person and returns how many chunks changed
+func (ms *MemoryServer) canonicalizePeopleReferences(ctx context.Context) (int, error) {
+	directory := ms.container.GetPeople()
+	chunks, err := ms.liveChunks(ctx, GlobalRepository, maxPeopleChunks)
+	if err != nil {
+		return 0, err
+	}
+
+	rewritten := 0
+	for i := range chunks {
+		_, err := ms.updateChunk(ctx, chunks[i].ID, "", func(chunk *types.ConversationChunk) error {
+			if !directory.CanonicalizeReferences(chunk) {
+				return errChunkUnchanged
+			}
+			return nil
+		})
+		switch {
+		case errors.Is(err, errChunkUnchanged):
+			continue
+		case err != nil:
+			return rewritten, err
+		}
+		rewritten++
+	}
+	return rewritten, nil
+}
+
+// logPeopleChange records a change to the people directory in the audit log
+func (ms *MemoryServer) logPeopleChange(ctx context.Context, operation string, person *people.Person, details map[string]interface{}) {
+	if auditLogger := ms.container.GetAuditLogger(); auditLogger != nil {
+		if details == nil {
+			details = map[string]interface{}{"display_name": person.DisplayName}
+		}
+		auditLogger.LogEvent(ctx, audit.EventTypeMemoryUpdate, operation+"_person", "person", person.ID, details)
+	}
+}
+
+// resolvePerson returns the ID of the person behind an identity, registering
+// a new person for identities not seen before. It returns "" without a
+// directory or when the directory cannot be updated.
+func (ms *MemoryServer) resolvePerson(identity string) string {
+	directory := ms.container.GetPeople()
+	if directory == nil || identity == "" {
+		return ""
+	}
+	person, err := directory.Ensure(identity)
+	if err != nil {
+		logging.Warn("Failed to resolve person", "identity", identity, "error", err)
+		return ""
+	}
+	return person.ID
+}
+
+// setPersonReference records the person behind identity under key and
+// returns their ID, or "" when there is none
+func (ms *MemoryServer) setPersonReference(metadata *types.ChunkMetadata, key, identity string) string {
+	personID := ms.resolvePerson(identity)
+	if personID == "" {
+		return ""
+	}
+	if metadata.ExtendedMetadata == nil {
+		metadata.ExtendedMetadata = make(map[string]interface{})
+	}
+	metadata.ExtendedMetadata[key] = personID
+	return personID
+}
+
+// attributeAuthor links a chunk to the person in its provenance author and
+// returns a context whose audit events name them
+func (ms *MemoryServer) attributeAuthor(ctx context.Context, metadata *types.ChunkMetadata) context.Context {
+	if metadata.Provenance == nil {
+		return ctx
+	}
+	return audit.WithPersonID(ctx, ms.setPersonReference(metadata, types.EMKeyAuthorPersonID, metadata.Provenance.Author))
+}
@@ -0,0 +1,162 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/people"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/tenancy"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeople_TasksMergeAndContributions(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	ms := newCompositeTestServer(t, store)
+	ms.container.People = people.NewStore("")
+	repo := "github.com/acme/api"
+
+	createTask := func(title, assignee string) string {
+		result, err := ms.handleCreateTask(ctx, map[string]interface{}{
+			"title":       title,
+			"description": title,
+			"session_id":  "session-1",
+			"repository":  repo,
+			"assignee":    assignee,
+			"creator":     "Bob <bob@example.com>",
+		})
+		require.NoError(t, err)
+		return result.(map[string]interface{})["task_id"].(string)
+	}
+	first := createTask("Rotate keys", "Jane Doe <jane@example.com>")
+	second := createTask("Fix login", "jdoe")
+
+	jane, ok := ms.container.People.Resolve("jane@example.com")
+	require.True(t, ok)
+	jdoe, ok := ms.container.People.Resolve("jdoe")
+	require.True(t, ok)
+	require.NotEqual(t, jane.ID, jdoe.ID, "unrelated identities start as different people")
+
+	chunk, err := store.GetByID(ctx, first)
+	require.NoError(t, err)
+	assert.Equal(t, jane.ID, people.Reference(chunk, types.EMKeyTaskAssigneePersonID))
+	bob := people.Reference(chunk, types.EMKeyTaskCreatorPersonID)
+	assert.NotEmpty(t, bob)
+
+	result, err := ms.handlePeople(ctx, map[string]interface{}{
+		"operation": "merge",
+		"person_id": jane.ID,
+		"merge_ids": []interface{}{jdoe.ID},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.(map[string]interface{})["chunks_rewritten"])
+
+	chunk, err = store.GetByID(ctx, second)
+	require.NoError(t, err)
+	assert.Equal(t, jane.ID, people.Reference(chunk, types.EMKeyTaskAssigneePersonID))
+
+	result, err = ms.handlePeople(ctx, map[string]interface{}{
+		"operation": "contributions",
+		"identity":  "jdoe",
+	})
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	contribution := response["contribution"].(people.Contribution)
+	assert.Equal(t, jane.ID, contribution.PersonID)
+	assert.Equal(t, 2, contribution.TasksAssigned)
+	assert.Equal(t, []string{repo}, contribution.Repositories)
+	assert.Contains(t, response, "audit_events")
+
+	result, err = ms.handlePeople(ctx, map[string]interface{}{"operation": "contributions"})
+	require.NoError(t, err)
+	all := result.(map[string]interface{})["contributions"].([]people.Contribution)
+	require.Len(t, all, 2)
+	for i := range all {
+		if all[i].PersonID == bob {
+			assert.Equal(t, 2, all[i].TasksCreated, "Bob created both tasks")
+			assert.Zero(t, all[i].TasksAssigned)
+		}
+	}
+
+	// Clearing the assignee drops the reference
+	_, err = ms.handleUpdateTask(ctx, map[string]interface{}{"task_id": second, "session_id": "session-1", "assignee": ""})
+	require.NoError(t, err)
+	chunk, err = store.GetByID(ctx, second)
+	require.NoError(t, err)
+	assert.Empty(t, people.Reference(chunk, types.EMKeyTaskAssigneePersonID))
+}
+
+func TestPeople_Operations(t *testing.T) {
+	ctx := context.Background()
+	ms := newCompositeTestServer(t, storage.NewSimpleMockVectorStore())
+	ms.container.People = people.NewStore("")
+
+	result, err := ms.handlePeople(ctx, map[string]interface{}{
+		"operation":    "upsert",
+		"display_name": "Jane Doe",
+		"aliases":      []interface{}{"jdoe"},
+		"email":        "jane@example.com",
+	})
+	require.NoError(t, err)
+	jane := result.(map[string]interface{})["person"].(*people.Person)
+	assert.Equal(t, people.HashEmail("jane@example.com"), jane.EmailHash)
+
+	result, err = ms.handlePeople(ctx, map[string]interface{}{"operation": "resolve", "identity": "JDOE"})
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	assert.Equal(t, true, response["found"])
+	assert.Equal(t, jane.ID, response["person"].(people.Person).ID)
+
+	result, err = ms.handlePeople(ctx, map[string]interface{}{"operation": "list"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.(map[string]interface{})["count"])
+
+	_, err = ms.handlePeople(ctx, map[string]interface{}{"operation": "merge", "person_id": jane.ID})
+	assert.Error(t, err)
+	_, err = ms.handlePeople(ctx, map[string]interface{}{"operation": "get", "person_id": "person_missing"})
+	assert.Error(t, err)
+	_, err = ms.handlePeople(ctx, map[string]interface{}{"operation": "rename"})
+	assert.Error(t, err)
+}
+
+func TestPeople_HeldToTheTenant(t *testing.T) {
+	ctx := context.Background()
+	ms := newCompositeTestServer(t, storage.NewSimpleMockVectorStore())
+	ms.container.People = people.NewStore("")
+	tenant := tenancy.WithTenant(ctx, &tenancy.Tenant{ID: "api_key:acme", Projects: []string{"github.com/acme/api"}})
+
+	for _, operation := range []string{"upsert", "merge"} {
+		_, err := ms.handlePeople(tenant, map[string]interface{}{
+			"operation":    operation,
+			"display_name": "Mallory",
+			"person_id":    "person_a",
+			"merge_ids":    []interface{}{"person_b"},
+		})
+		assert.ErrorIs(t, err, tenancy.ErrCrossTenant, operation)
+	}
+
+	for _, repo := range []string{"github.com/acme/api", "github.com/rival/app"} {
+		_, err := ms.handleCreateTask(ctx, map[string]interface{}{
+			"title":       "Rotate keys",
+			"description": "Rotate keys",
+			"session_id":  "session-1",
+			"repository":  repo,
+			"assignee":    "Dev of " + repo,
+		})
+		require.NoError(t, err)
+	}
+
+	result, err := ms.handlePeople(ctx, map[string]interface{}{"operation": "list"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.(map[string]interface{})["count"])
+
+	result, err = ms.handlePeople(tenant, map[string]interface{}{"operation": "list"})
+	require.NoError(t, err)
+	listed := result.(map[string]interface{})["people"].([]people.Person)
+	require.Len(t, listed, 1, "people of other tenants' projects stay hidden")
+	assert.Equal(t, "Dev of github.com/acme/api", listed[0].DisplayName)
+}
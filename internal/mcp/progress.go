@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"context"
+
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/progress"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+// NotificationSender delivers a server-to-client notification on the
+// connection a request arrived on
+type NotificationSender func(method string, params interface{}) error
+
+type notificationSenderKey struct{}
+
+// WithNotificationSender returns a context that lets handlers of requests
+// served with it notify the client. Transports that can push messages to the
+// client (stdio, WebSocket, SSE sessions) attach one before HandleRequest.
+func WithNotificationSender(ctx context.Context, sender NotificationSender) context.Context {
+	return context.WithValue(ctx, notificationSenderKey{}, sender)
+}
+
+// notificationSenderFrom returns the sender attached to ctx, or nil
+func notificationSenderFrom(ctx context.Context) NotificationSender {
+	sender, _ := ctx.Value(notificationSenderKey{}).(NotificationSender)
+	return sender
+}
+
+// ProgressMiddleware attaches a progress.Reporter to tools/call requests that
+// carry params._meta.progressToken, so tool handlers can report progress with
+// progress.FromContext(ctx). Reports become notifications/progress messages
+// for that token. Requests without a token, or served by a transport that
+// cannot notify the client, get a reporter that discards reports.
+func ProgressMiddleware() Middleware {
+	return ForMethods(func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+			sender := notificationSenderFrom(ctx)
+			token := progressToken(req)
+			if sender == nil || token == nil {
+				return next(ctx, req)
+			}
+
+			reporter := progress.NewTokenReporter(token, func(n *progress.Notification) error {
+				return sender(progress.MethodProgress, n)
+			})
+			resp := next(progress.WithReporter(ctx, reporter), req)
+			if err := reporter.Err(); err != nil {
+				logging.Warn("Failed to deliver progress notifications", "target", RequestTarget(req), "error", err)
+			}
+			return resp
+		}
+	}, "tools/call")
+}
+
+// progressToken returns the progress token a request asks to be notified
+// with, or nil when it did not ask. Tokens are strings or numbers.
+func progressToken(req *protocol.JSONRPCRequest) interface{} {
+	meta, _ := requestParams(req)["_meta"].(map[string]interface{})
+	switch token := meta["progressToken"].(type) {
+	case string, float64:
+		return token
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,53 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/progress"
+
+	mcp "github.com/fredcamaral/gomcp-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressMiddleware(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	ms.addTool(mcp.NewTool("slow", "Slow", mcp.ObjectSchema("Slow", map[string]interface{}{}, nil)),
+		mcp.ToolHandlerFunc(func(ctx context.Context, _ map[string]interface{}) (interface{}, error) {
+			reporter := progress.FromContext(ctx)
+			reporter.Report(1, 2, "Halfway")
+			reporter.Report(2, 2, "Done")
+			return "ok", nil
+		}))
+	ms.Use(ProgressMiddleware())
+
+	type sent struct {
+		method string
+		params *progress.Notification
+	}
+	var notifications []sent
+	ctx := WithNotificationSender(context.Background(), func(method string, params interface{}) error {
+		notifications = append(notifications, sent{method, params.(*progress.Notification)})
+		return nil
+	})
+
+	// No token, no notifications
+	resp := ms.HandleRequest(ctx, toolCallRequest("slow", nil))
+	require.Nil(t, resp.Error)
+	assert.Empty(t, notifications)
+
+	req := toolCallRequest("slow", nil)
+	req.Params.(map[string]interface{})["_meta"] = map[string]interface{}{"progressToken": "import-1"}
+	resp = ms.HandleRequest(ctx, req)
+	require.Nil(t, resp.Error)
+	require.Len(t, notifications, 2)
+	assert.Equal(t, progress.MethodProgress, notifications[0].method)
+	assert.Equal(t, &progress.Notification{ProgressToken: "import-1", Progress: 1, Total: 2, Percentage: 50, Message: "Halfway"}, notifications[0].params)
+	assert.Equal(t, 100.0, notifications[1].params.Percentage)
+
+	// A transport that cannot notify still serves the request
+	resp = ms.HandleRequest(context.Background(), req)
+	assert.Nil(t, resp.Error)
+	assert.Len(t, notifications, 2)
+}
@@ -0,0 +1,313 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/pkg/types"
+)
+
+// projectBundleVersion is the format of project exports. Version 2 added
+// the relationships and custom relation types of the exported chunks.
+const projectBundleVersion = 2
+
+// maxIntegrityViolations bounds the violations quoted in an import error
+const maxIntegrityViolations = 10
+
+// projectGraph is what a project export carries besides its chunks. Tasks
+// travel as task chunks; their dependencies, blocked chunks and parents are
+// checked like relationships when imported.
+type projectGraph struct {
+	Relationships []types.MemoryRelationship     `json:"relationships"`
+	RelationTypes []types.RelationTypeDefinition `json:"relation_types"`
+}
+
+// exportProjectGraph returns the relationships and custom relation types to
+// export with page, a page of all, the project's chunks in listing order.
+// Each relationship goes out with the page holding the later of its two
+// chunks, so importing the pages in order never meets a missing chunk.
+// Relationships to chunks outside the project go out with their project
+// chunk and need the other chunk to exist where the bundle is imported.
+func (ms *MemoryServer) exportProjectGraph(ctx context.Context, repository string, page, all []types.ConversationChunk) (*projectGraph, error) {
+	position := make(map[string]int, len(all))
+	for i := range all {
+		position[all[i].ID] = i
+	}
+	for i := range page {
+		if _, ok := position[page[i].ID]; !ok {
+			position[page[i].ID] = len(all) + i // Listed after the full listing was taken
+		}
+	}
+	last := func(relationship *types.MemoryRelationship) string {
+		source, sourceListed := position[relationship.SourceChunkID]
+		target, targetListed := position[relationship.TargetChunkID]
+		if !targetListed || (sourceListed && source > target) {
+			return relationship.SourceChunkID
+		}
+		return relationship.TargetChunkID
+	}
+
+	inPage := make(map[string]bool, len(page))
+	for i := range page {
+		inPage[page[i].ID] = true
+	}
+	graph := &projectGraph{Relationships: []types.MemoryRelationship{}, RelationTypes: []types.RelationTypeDefinition{}}
+	seen := make(map[string]bool)
+	for i := range page {
+		query := types.NewRelationshipQuery(page[i].ID)
+		query.MinConfidence = 0
+		query.IncludeChunks = false
+		query.Limit = 0
+		results, err := ms.container.GetVectorStore().GetRelationships(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export relationships of chunk %s: %w", page[i].ID, err)
+		}
+		for j := range results {
+			relationship := results[j].Relationship
+			if seen[relationship.ID] || !inPage[last(&relationship)] {
+				continue
+			}
+			seen[relationship.ID] = true
+			graph.Relationships = append(graph.Relationships, relationship)
+		}
+	}
+	sort.Slice(graph.Relationships, func(i, j int) bool { return graph.Relationships[i].ID < graph.Relationships[j].ID })
+
+	definitions := ms.relationTaxonomy().List(repository)
+	for i := range definitions {
+		if !definitions[i].BuiltIn {
+			graph.RelationTypes = append(graph.RelationTypes, definitions[i])
+		}
+	}
+	return graph, nil
+}
+
+// countTasks returns how many of chunks are tasks
+func countTasks(chunks []types.ConversationChunk) int {
+	tasks := 0
+	for i := range chunks {
+		if chunks[i].Type == types.ChunkTypeTask {
+			tasks++
+		}
+	}
+	return tasks
+}
+
+// parseProjectGraph reads the relationships and relation types of an
+// archive; bundles from before version 2 have none
+func parseProjectGraph(archive map[string]interface{}) (*projectGraph, error) {
+	graph := &projectGraph{}
+	for key, target := range map[string]interface{}{"relationships": &graph.Relationships, "relation_types": &graph.RelationTypes} {
+		value, ok := archive[key]
+		if !ok || value == nil {
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive %s: %w", key, err)
+		}
+		if err := json.Unmarshal(encoded, target); err != nil {
+			return nil, fmt.Errorf("failed to read archive %s: %w", key, err)
+		}
+	}
+	return graph, nil
+}
+
+// checkProjectIntegrity returns what in an imported bundle refers to
+// something that neither the bundle nor the store holds: a duplicated
+// chunk, a task dependency, blocked chunk or parent that does not exist,
+// a relationship to a missing chunk, or a relation type the repository
+// does not know and the bundle does not define. With repair, dangling task
+// references are dropped and the relationships at fault are left out of
+// graph instead, and the violations are still reported.
+func (ms *MemoryServer) checkProjectIntegrity(ctx context.Context, repository string, chunks []types.ConversationChunk, graph *projectGraph, repair bool) []string {
+	var violations []string
+	bundled := make(map[string]bool, len(chunks))
+	for i := range chunks {
+		if bundled[chunks[i].ID] {
+			violations = append(violations, fmt.Sprintf("chunk %s appears more than once", chunks[i].ID))
+		}
+		bundled[chunks[i].ID] = true
+	}
+
+	known := make(map[string]bool)
+	exists := func(id string) bool {
+		if bundled[id] {
+			return true
+		}
+		if found, checked := known[id]; checked {
+			return found
+		}
+		chunk, err := ms.container.GetVectorStore().GetByID(ctx, id)
+		known[id] = err == nil && chunk != nil
+		return known[id]
+	}
+	resolved := func(chunkID, field string, ids []string) []string {
+		kept := ids[:0:0]
+		for _, id := range ids {
+			if exists(id) {
+				kept = append(kept, id)
+			} else {
+				violations = append(violations, fmt.Sprintf("task %s: %s refers to missing chunk %s", chunkID, field, id))
+			}
+		}
+		return kept
+	}
+
+	for i := range chunks {
+		metadata := &chunks[i].Metadata
+		dependencies := resolved(chunks[i].ID, "task_dependencies", metadata.TaskDependencies)
+		blocks := resolved(chunks[i].ID, "task_blocks", metadata.TaskBlocks)
+		parentMissing := metadata.ParentChunkID != "" && !exists(metadata.ParentChunkID)
+		if parentMissing {
+			violations = append(violations, fmt.Sprintf("chunk %s: parent_chunk_id refers to missing chunk %s", chunks[i].ID, metadata.ParentChunkID))
+		}
+		if repair {
+			if len(metadata.TaskDependencies) > 0 {
+				metadata.TaskDependencies = dependencies
+			}
+			if len(metadata.TaskBlocks) > 0 {
+				metadata.TaskBlocks = blocks
+			}
+			if parentMissing {
+				metadata.ParentChunkID = ""
+			}
+		}
+	}
+
+	defined := make(map[types.RelationType]bool, len(graph.RelationTypes))
+	for i := range graph.RelationTypes {
+		defined[graph.RelationTypes[i].Name] = true
+	}
+	taxonomy := ms.relationTaxonomy()
+	kept := graph.Relationships[:0:0]
+	for i := range graph.Relationships {
+		relationship := &graph.Relationships[i]
+		var problems []string
+		for _, id := range []string{relationship.SourceChunkID, relationship.TargetChunkID} {
+			if !exists(id) {
+				problems = append(problems, fmt.Sprintf("relationship %s refers to missing chunk %s", relationship.ID, id))
+			}
+		}
+		if !defined[relationship.RelationType] && taxonomy.Validate(repository, relationship.RelationType) != nil {
+			problems = append(problems, fmt.Sprintf("relationship %s has unknown relation type %q", relationship.ID, relationship.RelationType))
+		}
+		violations = append(violations, problems...)
+		if len(problems) == 0 {
+			kept = append(kept, *relationship)
+		}
+	}
+	if repair {
+		graph.Relationships = kept
+	}
+	return violations
+}
+
+// integrityError describes failed integrity checks, quoting the first violations
+func integrityError(violations []string) error {
+	quoted := violations
+	if len(quoted) > maxIntegrityViolations {
+		quoted = quoted[:maxIntegrityViolations]
+	}
+	return fmt.Errorf("import failed %d referential integrity checks, nothing was imported (set skip_invalid to import the rest): %s", len(violations), strings.Join(quoted, "; "))
+}
+
+// importProjectGraph defines the bundle's relation types for repository and
+// stores the relationships among stored chunks, skipping those the
+// repository already has. It returns how many of each it stored.
+func (ms *MemoryServer) importProjectGraph(ctx context.Context, repository string, graph *projectGraph, stored map[string]bool) (relationTypes, relationships int) {
+	if ms.container.GetRelationTaxonomy() != nil {
+		for i := range graph.RelationTypes {
+			definition := graph.RelationTypes[i]
+			definition.BuiltIn, definition.Project, definition.CreatedAt = false, "", nil
+			if _, err := ms.container.GetRelationTaxonomy().Define(repository, definition); err != nil {
+				logging.Warn("Failed to import relation type", "repository", repository, "relation_type", definition.Name, "error", err)
+				continue
+			}
+			relationTypes++
+		}
+	}
+
+	store := ms.container.GetVectorStore()
+	for i := range graph.Relationships {
+		relationship := &graph.Relationships[i]
+		if !stored[relationship.SourceChunkID] && !stored[relationship.TargetChunkID] {
+			continue // Both chunks predate the import and keep their relationships
+		}
+		if ms.hasRelationship(ctx, relationship) {
+			continue
+		}
+		source := relationship.ConfidenceSource
+		if source == "" {
+			source = types.ConfidenceExplicit
+		}
+		if _, err := store.StoreRelationship(ctx, relationship.SourceChunkID, relationship.TargetChunkID, relationship.RelationType, relationship.Confidence, source); err != nil {
+			logging.Warn("Failed to import relationship", "relationship", relationship.ID, "error", err)
+			continue
+		}
+		relationships++
+	}
+	return relationTypes, relationships
+}
+
+// hasRelationship reports whether the store already links the chunks of relationship with its type
+func (ms *MemoryServer) hasRelationship(ctx context.Context, relationship *types.MemoryRelationship) bool {
+	query := types.NewRelationshipQuery(relationship.SourceChunkID)
+	query.Direction = "outgoing"
+	query.RelationTypes = []types.RelationType{relationship.RelationType}
+	query.MinConfidence = 0
+	query.IncludeChunks = false
+	existing, err := ms.container.GetVectorStore().GetRelationships(ctx, query)
+	if err != nil {
+		return false
+	}
+	for i := range existing {
+		found := &existing[i].Relationship
+		if found.SourceChunkID == relationship.SourceChunkID && found.TargetChunkID == relationship.TargetChunkID && found.RelationType == relationship.RelationType {
+			return true
+		}
+	}
+	return false
+}
+
+// importProjectArchive imports an exported project: its chunks, custom
+// relation types and relationships, after checking that everything they
+// refer to is in the archive or already stored
+func (ms *MemoryServer) importProjectArchive(ctx context.Context, params *importParams) (interface{}, error) {
+	chunks, graph, err := ms.importArchiveData(ctx, params.data, params.repository, params.metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := ms.checkProjectIntegrity(ctx, params.repository, chunks, graph, params.skipInvalid)
+	if len(violations) > 0 && !params.skipInvalid {
+		return nil, integrityError(violations)
+	}
+
+	stored := ms.storeImportedChunks(ctx, chunks, params)
+	relationTypes, relationships := ms.importProjectGraph(ctx, params.repository, graph, stored)
+
+	response := ms.buildImportResponse(params, chunks, len(stored))
+	response["tasks_stored"] = countStoredTasks(chunks, stored)
+	response["relation_types_imported"] = relationTypes
+	response["relationships_imported"] = relationships
+	if len(violations) > 0 {
+		response["integrity_violations"] = violations
+	}
+	return response, nil
+}
+
+// countStoredTasks returns how many of chunks are tasks that were stored
+func countStoredTasks(chunks []types.ConversationChunk, stored map[string]bool) int {
+	tasks := 0
+	for i := range chunks {
+		if chunks[i].Type == types.ChunkTypeTask && stored[chunks[i].ID] {
+			tasks++
+		}
+	}
+	return tasks
+}
@@ -0,0 +1,167 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/relationships"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bundleRepository = "github.com/acme/api"
+
+// newBundleTestServer returns a server over an empty store with a relation taxonomy
+func newBundleTestServer(t *testing.T) (*MemoryServer, storage.VectorStore) {
+	t.Helper()
+	store := storage.NewKeywordStore("")
+	ms := newCompositeTestServer(t, store)
+	ms.container.RelationTaxonomy = relationships.NewTaxonomy("")
+	return ms, store
+}
+
+// exportBundle exports a page of the project as JSON and returns the bundle
+func exportBundle(t *testing.T, ms *MemoryServer, limit, offset int) (string, map[string]interface{}) {
+	t.Helper()
+	result, err := ms.handleExportProject(context.Background(), map[string]interface{}{
+		"repository": bundleRepository,
+		"session_id": "session-1",
+		"limit":      float64(limit),
+		"offset":     float64(offset),
+	})
+	require.NoError(t, err)
+	data := result.(map[string]interface{})["data"].(string)
+	var bundle map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(data), &bundle))
+	return data, bundle
+}
+
+// importBundle imports an exported bundle
+func importBundle(ms *MemoryServer, data string, skipInvalid bool) (map[string]interface{}, error) {
+	result, err := ms.handleImportContext(context.Background(), map[string]interface{}{
+		"source":       "archive",
+		"data":         data,
+		"repository":   bundleRepository,
+		"session_id":   "session-2",
+		"skip_invalid": skipInvalid,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(map[string]interface{}), nil
+}
+
+// seedProject stores a task depending on another, both linked with a custom
+// relation type. The dependency is listed, and so exported, first.
+func seedProject(t *testing.T, ms *MemoryServer, store storage.VectorStore) (first, second *types.ConversationChunk) {
+	t.Helper()
+	ctx := context.Background()
+	first = newTaskChunk(t, bundleRepository, types.TaskStatusTodo)
+	second = newTaskChunk(t, bundleRepository, types.TaskStatusTodo)
+	second.Metadata.TaskDependencies = []string{first.ID}
+	second.Timestamp = first.Timestamp.Add(-time.Minute) // Listings go newest first
+	require.NoError(t, store.Store(ctx, first))
+	require.NoError(t, store.Store(ctx, second))
+
+	_, err := ms.container.RelationTaxonomy.Define(bundleRepository, types.RelationTypeDefinition{
+		Name: "blocks_release", Description: "Issue blocks a release", Directionality: types.DirectionalityDirected,
+	})
+	require.NoError(t, err)
+	_, err = store.StoreRelationship(ctx, first.ID, second.ID, "blocks_release", 0.9, types.ConfidenceExplicit)
+	require.NoError(t, err)
+	return first, second
+}
+
+func TestProjectBundle_RoundTrip(t *testing.T) {
+	source, store := newBundleTestServer(t)
+	first, second := seedProject(t, source, store)
+
+	data, bundle := exportBundle(t, source, 100, 0)
+	assert.EqualValues(t, projectBundleVersion, bundle["format_version"])
+	require.Len(t, bundle["relationships"], 1)
+	require.Len(t, bundle["relation_types"], 1)
+
+	target, targetStore := newBundleTestServer(t)
+	response, err := importBundle(target, data, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, response["chunks_stored"])
+	assert.Equal(t, 2, response["tasks_stored"])
+	assert.Equal(t, 1, response["relation_types_imported"])
+	assert.Equal(t, 1, response["relationships_imported"])
+	assert.NotContains(t, response, "integrity_violations")
+
+	imported, err := targetStore.GetByID(context.Background(), second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{first.ID}, imported.Metadata.TaskDependencies)
+	assert.NoError(t, target.relationTaxonomy().Validate(bundleRepository, "blocks_release"))
+	assert.True(t, target.hasRelationship(context.Background(), &types.MemoryRelationship{
+		SourceChunkID: first.ID, TargetChunkID: second.ID, RelationType: "blocks_release",
+	}))
+
+	// Importing again adds no duplicate relationships
+	response, err = importBundle(target, data, false)
+	require.NoError(t, err)
+	assert.Equal(t, 0, response["relationships_imported"])
+}
+
+func TestProjectBundle_PagesCarryRelationshipsOfTheirLaterChunk(t *testing.T) {
+	source, store := newBundleTestServer(t)
+	seedProject(t, source, store)
+
+	target, _ := newBundleTestServer(t)
+	relationshipsImported := 0
+	for offset := 0; offset < 2; offset++ {
+		data, bundle := exportBundle(t, source, 1, offset)
+		relationshipsImported += len(bundle["relationships"].([]interface{}))
+
+		// Importing the pages in order never meets a missing chunk
+		response, err := importBundle(target, data, false)
+		require.NoError(t, err, "page %d", offset)
+		assert.Equal(t, 1, response["chunks_stored"])
+	}
+	assert.Equal(t, 1, relationshipsImported, "each relationship goes out with exactly one page")
+}
+
+func TestProjectBundle_IntegrityChecks(t *testing.T) {
+	source, store := newBundleTestServer(t)
+	first, second := seedProject(t, source, store)
+
+	// The second page alone refers to a task the target does not have
+	data, _ := exportBundle(t, source, 1, 1)
+	target, targetStore := newBundleTestServer(t)
+	_, err := importBundle(target, data, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "referential integrity")
+	assert.Contains(t, err.Error(), "task_dependencies refers to missing chunk "+first.ID)
+	_, err = targetStore.GetByID(context.Background(), second.ID)
+	assert.Error(t, err, "nothing is imported when a check fails")
+
+	// skip_invalid imports the rest and reports what it left out
+	response, err := importBundle(target, data, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, response["chunks_stored"])
+	assert.Equal(t, 0, response["relationships_imported"])
+	assert.Len(t, response["integrity_violations"], 2, "the dependency and the relationship")
+	imported, err := targetStore.GetByID(context.Background(), second.ID)
+	require.NoError(t, err)
+	assert.Empty(t, imported.Metadata.TaskDependencies, "dangling dependencies are dropped")
+}
+
+func TestProjectBundle_RejectsUnknownRelationTypes(t *testing.T) {
+	ms, _ := newBundleTestServer(t)
+	chunk := newTaskChunk(t, bundleRepository, types.TaskStatusTodo)
+	graph := &projectGraph{Relationships: []types.MemoryRelationship{{
+		ID: "rel-1", SourceChunkID: chunk.ID, TargetChunkID: chunk.ID, RelationType: "not_defined",
+	}}}
+	violations := ms.checkProjectIntegrity(context.Background(), bundleRepository, []types.ConversationChunk{*chunk, *chunk}, graph, false)
+	assert.ElementsMatch(t, []string{
+		"chunk " + chunk.ID + " appears more than once",
+		`relationship rel-1 has unknown relation type "not_defined"`,
+	}, violations)
+	assert.Len(t, graph.Relationships, 1, "without repair the graph is left as is")
+}
@@ -0,0 +1,449 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"lerian-mcp-memory/internal/audit"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/projects"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/tenancy"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/fredcamaral/gomcp-sdk"
+)
+
+// projectPageSize is how many memories are read at a time when a lifecycle
+// operation collects a project's memories
+const projectPageSize = 1000
+
+// Ways project deletion treats the project's memories
+const (
+	cascadeRestrict = "restrict"
+	cascadeTrash    = "trash"
+	cascadePurge    = "purge"
+)
+
+// projectStats sums a project's memories
+type projectStats struct {
+	Memories      int                     `json:"memories"`
+	Trashed       int                     `json:"trashed"`
+	Sessions      int                     `json:"sessions"`
+	ByType        map[types.ChunkType]int `json:"by_type,omitempty"`
+	FirstActivity *time.Time              `json:"first_activity,omitempty"`
+	LastActivity  *time.Time              `json:"last_activity,omitempty"`
+}
+
+// projectListing is one project of project_list. Repositories holding
+// memories that were never registered are listed too, without a project.
+type projectListing struct {
+	ID         string            `json:"id"`
+	Registered bool              `json:"registered"`
+	Status     projects.Status   `json:"status"`
+	Project    *projects.Project `json:"project,omitempty"`
+	Stats      projectStats      `json:"stats"`
+}
+
+// registerProjectTools registers project_manage and project_list
+func (ms *MemoryServer) registerProjectTools() {
+	ms.addTool(mcp.NewTool(
+		"project_manage",
+		"Manage the lifecycle of projects, the repositories memories are filed under. Operations: create (register a project), update (name and description), rename (give a project a new ID and move its memories; writes to the old ID are refused), archive (make a project read-only), unarchive, delete (remove a project; cascade restrict refuses while it has memories, trash moves them to the trash, purge deletes them permanently). rename and delete only preview their effect until confirm repeats the project_id.",
+		mcp.ObjectSchema("Project lifecycle parameters", map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"create", "update", "rename", "archive", "unarchive", "delete"},
+				"description": "Lifecycle operation",
+			},
+			"project_id": map[string]interface{}{
+				"type":        "string",
+				"description": "Project to act on: the repository its memories name, e.g. 'github.com/acme/api'",
+			},
+			"name": map[string]interface{}{
+				"type":        "string",
+				"description": "Display name (create, update)",
+			},
+			"description": map[string]interface{}{
+				"type":        "string",
+				"description": "What the project is (create, update)",
+			},
+			"new_project_id": map[string]interface{}{
+				"type":        "string",
+				"description": "New ID of the project (rename)",
+			},
+			"cascade": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{cascadeRestrict, cascadeTrash, cascadePurge},
+				"default":     cascadeRestrict,
+				"description": "What happens to the project's memories (delete)",
+			},
+			"confirm": map[string]interface{}{
+				"type":        "string",
+				"description": "The project_id again, to carry out a rename or delete instead of previewing it",
+			},
+		}, []string{"operation", "project_id"}),
+	), mcp.ToolHandlerFunc(ms.handleProjectManage))
+
+	ms.addTool(mcp.NewTool(
+		"project_list",
+		"List projects with their lifecycle status and memory stats: live and trashed memories, sessions, memories by type and first and last activity. Repositories that hold memories without being registered are listed as unregistered.",
+		mcp.ObjectSchema("Project list parameters", map[string]interface{}{
+			"include_archived": map[string]interface{}{
+				"type":        "boolean",
+				"default":     true,
+				"description": "List archived projects",
+			},
+			"include_unregistered": map[string]interface{}{
+				"type":        "boolean",
+				"default":     true,
+				"description": "List repositories holding memories that are not registered projects",
+			},
+		}, nil),
+	), mcp.ToolHandlerFunc(ms.handleProjectList))
+}
+
+// handleProjectManage runs a project lifecycle operation
+func (ms *MemoryServer) handleProjectManage(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: project_manage called", "args", args)
+
+	registry := ms.container.GetProjects()
+	if registry == nil {
+		return nil, errors.New("the project registry is not available")
+	}
+
+	operation, _ := args["operation"].(string)
+	projectID, _ := args["project_id"].(string)
+	if projectID == "" {
+		return nil, NewToolError(ToolErrorMissingParameter, "project_id parameter is required").
+			WithParameter("project_id").
+			WithExample(`{"operation": "create", "project_id": "github.com/acme/api", "name": "Acme API"}`)
+	}
+	if err := checkProjectTenant(ctx, projectID); err != nil {
+		return nil, err
+	}
+	name, _ := args["name"].(string)
+	description, _ := args["description"].(string)
+
+	var (
+		project *projects.Project
+		err     error
+	)
+	switch operation {
+	case "create":
+		project, err = registry.Create(projects.Project{ID: projectID, Name: name, Description: description})
+	case "update":
+		project, err = registry.Update(projectID, name, description)
+	case "archive", "unarchive":
+		project, err = registry.SetArchived(projectID, operation == "archive")
+	case "rename":
+		return ms.renameProject(ctx, registry, projectID, args)
+	case "delete":
+		return ms.deleteProject(ctx, registry, projectID, args)
+	default:
+		return nil, fmt.Errorf("unknown operation %q: use create, update, rename, archive, unarchive or delete", operation)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ms.logProjectChange(ctx, operation, project.ID, map[string]interface{}{"status": project.Status})
+	return map[string]interface{}{
+		"status":    "success",
+		"operation": operation,
+		"project":   project,
+	}, nil
+}
+
+// renameProject gives a project a new ID and moves its memories there. Without
+// confirmation it only reports how many memories would move.
+func (ms *MemoryServer) renameProject(ctx context.Context, registry *projects.Store, projectID string, args map[string]interface{}) (interface{}, error) {
+	newID, _ := args["new_project_id"].(string)
+	if newID == "" {
+		return nil, NewToolError(ToolErrorMissingParameter, "new_project_id parameter is required for rename").
+			WithParameter("new_project_id").
+			WithExample(`{"operation": "rename", "project_id": "github.com/acme/api", "new_project_id": "github.com/acme/payments-api", "confirm": "github.com/acme/api"}`)
+	}
+	if err := checkProjectTenant(ctx, newID); err != nil {
+		return nil, err
+	}
+	project, ok := registry.Get(projectID)
+	if !ok {
+		return nil, fmt.Errorf("project %q not found", projectID)
+	}
+
+	chunks, err := ms.projectChunks(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if confirm, _ := args["confirm"].(string); confirm != projectID {
+		return projectPreview("rename", &project, map[string]interface{}{
+			"new_project_id":   newID,
+			"memories_to_move": len(chunks),
+		}), nil
+	}
+
+	renamed, err := registry.Rename(projectID, newID)
+	if err != nil {
+		return nil, err
+	}
+	lifecycleCtx := projects.WithLifecycle(ctx)
+	moved := 0
+	for i := range chunks {
+		if _, err := ms.updateChunk(lifecycleCtx, chunks[i].ID, "", func(chunk *types.ConversationChunk) error {
+			chunk.Metadata.Repository = renamed.ID
+			return nil
+		}); err != nil {
+			return nil, fmt.Errorf("renamed project %q to %q but moved only %d of %d memories: %w", projectID, renamed.ID, moved, len(chunks), err)
+		}
+		moved++
+	}
+
+	ms.logProjectChange(ctx, "rename", renamed.ID, map[string]interface{}{
+		"former_id":      projectID,
+		"memories_moved": moved,
+	})
+	return map[string]interface{}{
+		"status":         "success",
+		"operation":      "rename",
+		"project":        renamed,
+		"memories_moved": moved,
+	}, nil
+}
+
+// deleteProject removes a project and, by its cascade, trashes or purges its
+// memories. Without confirmation it only reports what would happen to them.
+func (ms *MemoryServer) deleteProject(ctx context.Context, registry *projects.Store, projectID string, args map[string]interface{}) (interface{}, error) {
+	cascade, _ := args["cascade"].(string)
+	if cascade == "" {
+		cascade = cascadeRestrict
+	}
+	if cascade != cascadeRestrict && cascade != cascadeTrash && cascade != cascadePurge {
+		return nil, fmt.Errorf("invalid cascade %q: use restrict, trash or purge", cascade)
+	}
+	project, ok := registry.Get(projectID)
+	if !ok {
+		return nil, fmt.Errorf("project %q not found", projectID)
+	}
+
+	chunks, err := ms.projectChunks(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	live := 0
+	for i := range chunks {
+		if !chunks[i].IsDeleted() {
+			live++
+		}
+	}
+	if cascade == cascadeRestrict && live > 0 {
+		return nil, NewToolError(ToolErrorConflict, fmt.Sprintf("project %q still has %d memories", projectID, live)).
+			WithParameter("cascade").
+			WithSuggestion("Delete with cascade trash to keep them restorable for a while, or purge to delete them permanently")
+	}
+	if confirm, _ := args["confirm"].(string); confirm != projectID {
+		return projectPreview("delete", &project, map[string]interface{}{
+			"cascade":          cascade,
+			"memories":         live,
+			"trashed_memories": len(chunks) - live,
+		}), nil
+	}
+
+	lifecycleCtx := projects.WithLifecycle(ctx)
+	store := ms.container.GetVectorStore()
+	affected := 0
+	switch cascade {
+	case cascadeTrash:
+		now := time.Now()
+		for i := range chunks {
+			if chunks[i].IsDeleted() {
+				continue
+			}
+			if err := ms.trashChunk(lifecycleCtx, &chunks[i], now); err != nil {
+				return nil, fmt.Errorf("trashed %d memories of project %q before failing: %w", affected, projectID, err)
+			}
+			affected++
+		}
+	case cascadePurge:
+		ids := make([]string, len(chunks))
+		for i := range chunks {
+			ids[i] = chunks[i].ID
+		}
+		if len(ids) > 0 {
+			result, err := store.BatchDelete(lifecycleCtx, ids)
+			if err != nil {
+				return nil, fmt.Errorf("failed to purge the memories of project %q: %w", projectID, err)
+			}
+			affected = result.Success
+			if result.Failed > 0 {
+				return nil, fmt.Errorf("purged %d memories of project %q but %d failed", result.Success, projectID, result.Failed)
+			}
+		}
+	}
+
+	deleted, err := registry.Delete(projectID)
+	if err != nil {
+		return nil, err
+	}
+	ms.logProjectChange(ctx, "delete", projectID, map[string]interface{}{
+		"cascade":           cascade,
+		"memories_affected": affected,
+	})
+	return map[string]interface{}{
+		"status":            "success",
+		"operation":         "delete",
+		"project":           deleted,
+		"cascade":           cascade,
+		"memories_affected": affected,
+	}, nil
+}
+
+// projectPreview describes what a rename or delete would do and how to confirm it
+func projectPreview(operation string, project *projects.Project, effect map[string]interface{}) map[string]interface{} {
+	preview := map[string]interface{}{
+		"status":    "confirmation_required",
+		"operation": operation,
+		"project":   project,
+		"message":   fmt.Sprintf("Nothing changed yet. Call %s again with confirm set to %q to carry it out", operation, project.ID),
+	}
+	for key, value := range effect {
+		preview[key] = value
+	}
+	return preview
+}
+
+// projectChunks returns every memory filed under a project, trashed ones included
+func (ms *MemoryServer) projectChunks(ctx context.Context, projectID string) ([]types.ConversationChunk, error) {
+	store := ms.container.GetVectorStore()
+	ctx = storage.WithDeleted(ctx)
+	var chunks []types.ConversationChunk
+	for {
+		page, err := store.ListByRepository(ctx, projectID, projectPageSize, len(chunks))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the memories of project %q: %w", projectID, err)
+		}
+		chunks = append(chunks, page...)
+		if len(page) < projectPageSize {
+			return chunks, nil
+		}
+	}
+}
+
+// handleProjectList lists projects with their memory stats
+func (ms *MemoryServer) handleProjectList(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	registry := ms.container.GetProjects()
+	if registry == nil {
+		return nil, errors.New("the project registry is not available")
+	}
+	includeArchived, ok := args["include_archived"].(bool)
+	if !ok {
+		includeArchived = true
+	}
+	includeUnregistered, ok := args["include_unregistered"].(bool)
+	if !ok {
+		includeUnregistered = true
+	}
+
+	chunks, err := ms.container.GetVectorStore().GetAllChunks(storage.WithDeleted(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memories: %w", err)
+	}
+	stats := projectStatsByRepository(chunks)
+
+	tenant := tenancy.FromContext(ctx)
+	listings := make([]projectListing, 0, len(stats))
+	listed := make(map[string]bool)
+	for _, project := range registry.List(includeArchived) {
+		listed[project.ID] = true
+		if tenant != nil && !tenant.Allows(project.ID) {
+			continue
+		}
+		listings = append(listings, projectListing{
+			ID:         project.ID,
+			Registered: true,
+			Status:     project.Status,
+			Project:    &project,
+			Stats:      stats[project.ID],
+		})
+	}
+	unregistered := 0
+	for repository := range stats {
+		if listed[repository] || projects.IsShared(repository) {
+			continue
+		}
+		if registered, ok := registry.Resolve(repository); ok && (registered.ID != repository || !includeArchived) {
+			continue // Left behind by a rename, or an archived project left out
+		}
+		unregistered++
+		if includeUnregistered {
+			listings = append(listings, projectListing{ID: repository, Status: projects.StatusActive, Stats: stats[repository]})
+		}
+	}
+	sort.Slice(listings, func(i, j int) bool { return listings[i].ID < listings[j].ID })
+
+	return map[string]interface{}{
+		"status":               "success",
+		"projects":             listings,
+		"count":                len(listings),
+		"unregistered":         unregistered,
+		"require_registration": registry.RequiresRegistration(),
+	}, nil
+}
+
+// projectStatsByRepository sums chunks by the repository they are filed under
+func projectStatsByRepository(chunks []types.ConversationChunk) map[string]projectStats {
+	stats := make(map[string]projectStats)
+	sessions := make(map[string]map[string]bool)
+	for i := range chunks {
+		chunk := &chunks[i]
+		repository := chunk.Metadata.Repository
+		if repository == "" {
+			continue
+		}
+		s := stats[repository]
+		if chunk.IsDeleted() {
+			s.Trashed++
+			stats[repository] = s
+			continue
+		}
+
+		s.Memories++
+		if s.ByType == nil {
+			s.ByType = make(map[types.ChunkType]int)
+		}
+		s.ByType[chunk.Type]++
+		if sessions[repository] == nil {
+			sessions[repository] = make(map[string]bool)
+		}
+		if chunk.SessionID != "" && !sessions[repository][chunk.SessionID] {
+			sessions[repository][chunk.SessionID] = true
+			s.Sessions++
+		}
+		if timestamp := chunk.Timestamp; s.FirstActivity == nil || timestamp.Before(*s.FirstActivity) {
+			s.FirstActivity = &timestamp
+		}
+		if timestamp := chunk.Timestamp; s.LastActivity == nil || timestamp.After(*s.LastActivity) {
+			s.LastActivity = &timestamp
+		}
+		stats[repository] = s
+	}
+	return stats
+}
+
+// checkProjectTenant fails when the request's tenant does not own projectID
+func checkProjectTenant(ctx context.Context, projectID string) error {
+	if tenant := tenancy.FromContext(ctx); tenant != nil && !tenant.Allows(projectID) {
+		return fmt.Errorf("%w: project %q", tenancy.ErrCrossTenant, projectID)
+	}
+	return nil
+}
+
+// logProjectChange records a project lifecycle change in the audit log
+func (ms *MemoryServer) logProjectChange(ctx context.Context, operation, projectID string, details map[string]interface{}) {
+	if auditLogger := ms.container.GetAuditLogger(); auditLogger != nil {
+		auditLogger.LogEvent(ctx, audit.EventTypeMemoryUpdate, operation+"_project", "project", projectID, details)
+	}
+}
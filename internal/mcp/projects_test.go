@@ -0,0 +1,125 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/projects"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newProjectTestServer(t *testing.T) (*MemoryServer, *projects.Store) {
+	t.Helper()
+	registry := projects.NewStore("")
+	ms := newCompositeTestServer(t, storage.NewProjectGuardedVectorStore(storage.NewLocalVectorStore(""), registry))
+	ms.container.Projects = registry
+	return ms, registry
+}
+
+func TestProjectRenameMovesMemories(t *testing.T) {
+	ctx := context.Background()
+	ms, registry := newProjectTestServer(t)
+	_, err := ms.handleProjectManage(ctx, map[string]interface{}{"operation": "create", "project_id": "github.com/acme/api", "name": "Acme API"})
+	require.NoError(t, err)
+	store := ms.container.GetVectorStore()
+	for _, content := range []string{"Checkout times out", "Raise the provider timeout"} {
+		require.NoError(t, store.Store(ctx, newReportChunk(t, "s1", content, types.ChunkTypeProblem, types.ChunkMetadata{})))
+	}
+
+	args := map[string]interface{}{"operation": "rename", "project_id": "github.com/acme/api", "new_project_id": "github.com/acme/payments"}
+	result, err := ms.handleProjectManage(ctx, args)
+	require.NoError(t, err)
+	preview := result.(map[string]interface{})
+	assert.Equal(t, "confirmation_required", preview["status"])
+	assert.Equal(t, 2, preview["memories_to_move"])
+	_, ok := registry.Get("github.com/acme/api")
+	assert.True(t, ok, "a preview changes nothing")
+
+	args["confirm"] = "github.com/acme/api"
+	result, err = ms.handleProjectManage(ctx, args)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.(map[string]interface{})["memories_moved"])
+	moved, err := store.ListByRepository(ctx, "github.com/acme/payments", 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, moved, 2)
+
+	late := newReportChunk(t, "s2", "Late note", types.ChunkTypeDiscussion, types.ChunkMetadata{})
+	err = store.Store(ctx, late)
+	assert.ErrorIs(t, err, projects.ErrRenamed)
+	assert.Equal(t, ToolErrorConflict, asToolError(err).Code)
+}
+
+func TestProjectDeleteCascades(t *testing.T) {
+	ctx := context.Background()
+	ms, registry := newProjectTestServer(t)
+	_, err := registry.Create(projects.Project{ID: "github.com/acme/api"})
+	require.NoError(t, err)
+	store := ms.container.GetVectorStore()
+	chunk := newReportChunk(t, "s1", "Checkout times out", types.ChunkTypeProblem, types.ChunkMetadata{})
+	require.NoError(t, store.Store(ctx, chunk))
+
+	_, err = ms.handleProjectManage(ctx, map[string]interface{}{"operation": "archive", "project_id": "github.com/acme/api"})
+	require.NoError(t, err)
+	_, err = ms.handleProjectManage(ctx, map[string]interface{}{"operation": "delete", "project_id": "github.com/acme/api", "confirm": "github.com/acme/api"})
+	assert.ErrorContains(t, err, "still has 1 memories")
+
+	args := map[string]interface{}{"operation": "delete", "project_id": "github.com/acme/api", "cascade": "trash"}
+	result, err := ms.handleProjectManage(ctx, args)
+	require.NoError(t, err)
+	assert.Equal(t, "confirmation_required", result.(map[string]interface{})["status"])
+
+	args["confirm"] = "github.com/acme/api"
+	result, err = ms.handleProjectManage(ctx, args)
+	require.NoError(t, err, "deleting an archived project trashes its memories")
+	assert.Equal(t, 1, result.(map[string]interface{})["memories_affected"])
+	trashed, err := store.GetByID(ctx, chunk.ID)
+	require.NoError(t, err)
+	assert.True(t, trashed.IsDeleted())
+	_, ok := registry.Get("github.com/acme/api")
+	assert.False(t, ok)
+
+	_, err = ms.handleProjectManage(ctx, map[string]interface{}{"operation": "delete", "project_id": "github.com/acme/api", "cascade": "sideways"})
+	assert.ErrorContains(t, err, "invalid cascade")
+}
+
+func TestProjectList(t *testing.T) {
+	ctx := context.Background()
+	ms, registry := newProjectTestServer(t)
+	_, err := registry.Create(projects.Project{ID: "github.com/acme/api", Name: "Acme API"})
+	require.NoError(t, err)
+	_, err = registry.Create(projects.Project{ID: "github.com/acme/legacy"})
+	require.NoError(t, err)
+	_, err = registry.SetArchived("github.com/acme/legacy", true)
+	require.NoError(t, err)
+
+	store := ms.container.GetVectorStore()
+	require.NoError(t, store.Store(ctx, newReportChunk(t, "s1", "Checkout times out", types.ChunkTypeProblem, types.ChunkMetadata{})))
+	require.NoError(t, store.Store(ctx, newReportChunk(t, "s2", "Raise the timeout", types.ChunkTypeSolution, types.ChunkMetadata{})))
+	require.NoError(t, store.Store(ctx, newReportChunk(t, "s3", "Stray note", types.ChunkTypeDiscussion, types.ChunkMetadata{Repository: "github.com/acme/scratch"})))
+
+	result, err := ms.handleProjectList(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	listings := response["projects"].([]projectListing)
+	require.Len(t, listings, 3)
+	assert.Equal(t, 1, response["unregistered"])
+
+	api := listings[0]
+	assert.Equal(t, "github.com/acme/api", api.ID)
+	assert.True(t, api.Registered)
+	assert.Equal(t, 2, api.Stats.Memories)
+	assert.Equal(t, 2, api.Stats.Sessions)
+	assert.Equal(t, map[types.ChunkType]int{types.ChunkTypeProblem: 1, types.ChunkTypeSolution: 1}, api.Stats.ByType)
+	assert.NotNil(t, api.Stats.LastActivity)
+	assert.Equal(t, projects.StatusArchived, listings[1].Status)
+	assert.False(t, listings[2].Registered)
+	assert.Equal(t, 1, listings[2].Stats.Memories)
+
+	result, err = ms.handleProjectList(ctx, map[string]interface{}{"include_archived": false, "include_unregistered": false})
+	require.NoError(t, err)
+	assert.Len(t, result.(map[string]interface{})["projects"], 1)
+}
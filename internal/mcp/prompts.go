@@ -0,0 +1,112 @@
+package mcp
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/fredcamaral/gomcp-sdk/server"
+)
+
+// promptRegistry keeps the arguments prompts declare, so prompts/get
+// requests are checked against them before reaching the handler
+type promptRegistry struct {
+	mu     sync.RWMutex
+	byName map[string]registeredPrompt
+}
+
+// registeredPrompt is a prompt's declared arguments and their defaults
+type registeredPrompt struct {
+	arguments []protocol.PromptArgument
+	defaults  map[string]string
+}
+
+// AddPrompt registers a prompt. Its handler only sees arguments the prompt
+// declares, always has the required ones, and gets defaults for optional
+// arguments the client left out. Defaults may only name declared arguments.
+func (ms *MemoryServer) AddPrompt(prompt protocol.Prompt, defaults map[string]string, handler server.PromptHandler) error {
+	declared := make(map[string]bool, len(prompt.Arguments))
+	for _, argument := range prompt.Arguments {
+		declared[argument.Name] = true
+	}
+	for name := range defaults {
+		if !declared[name] {
+			return fmt.Errorf("prompt %s has a default for undeclared argument %s", prompt.Name, name)
+		}
+	}
+
+	ms.prompts.mu.Lock()
+	if ms.prompts.byName == nil {
+		ms.prompts.byName = make(map[string]registeredPrompt)
+	}
+	ms.prompts.byName[prompt.Name] = registeredPrompt{arguments: prompt.Arguments, defaults: defaults}
+	ms.prompts.mu.Unlock()
+
+	ms.mcpServer.AddPrompt(prompt, handler)
+	return nil
+}
+
+// preparePromptArguments validates the arguments of a prompts/get request
+// against the prompt's declaration and fills in defaults. It returns the
+// request to dispatch, or an invalid params response naming the missing,
+// unknown or non-string arguments.
+func (ms *MemoryServer) preparePromptArguments(req *protocol.JSONRPCRequest) (*protocol.JSONRPCRequest, *protocol.JSONRPCResponse) {
+	params := requestParams(req)
+	name, _ := params["name"].(string)
+	ms.prompts.mu.RLock()
+	prompt, ok := ms.prompts.byName[name]
+	ms.prompts.mu.RUnlock()
+	if !ok {
+		return req, nil // The MCP server answers unknown prompts
+	}
+
+	given, _ := params["arguments"].(map[string]interface{})
+	declared := make(map[string]bool, len(prompt.arguments))
+	arguments := make(map[string]interface{}, len(prompt.arguments))
+	var missing, unknown, invalid []string
+	for _, argument := range prompt.arguments {
+		declared[argument.Name] = true
+		value, present := given[argument.Name]
+		switch {
+		case present:
+			if _, isString := value.(string); !isString {
+				invalid = append(invalid, argument.Name)
+			}
+			arguments[argument.Name] = value
+		case prompt.defaults[argument.Name] != "":
+			arguments[argument.Name] = prompt.defaults[argument.Name]
+		case argument.Required:
+			missing = append(missing, argument.Name)
+		}
+	}
+	for argument := range given {
+		if !declared[argument] {
+			unknown = append(unknown, argument)
+		}
+	}
+	sort.Strings(unknown)
+
+	if len(missing) > 0 || len(unknown) > 0 || len(invalid) > 0 {
+		data := map[string]interface{}{"prompt": name}
+		if len(missing) > 0 {
+			data["missing"] = missing
+		}
+		if len(unknown) > 0 {
+			data["unknown"] = unknown
+		}
+		if len(invalid) > 0 {
+			data["not_strings"] = invalid
+		}
+		return nil, ErrorResponse(req, protocol.InvalidParams, "Invalid prompt arguments", data)
+	}
+
+	prepared := make(map[string]interface{}, len(params))
+	for key, value := range params {
+		prepared[key] = value
+	}
+	prepared["arguments"] = arguments
+	copied := *req
+	copied.Params = prepared
+	return &copied, nil
+}
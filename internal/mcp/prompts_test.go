@@ -0,0 +1,63 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fredcamaral/gomcp-sdk"
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func promptGetRequest(name string, args map[string]interface{}) *protocol.JSONRPCRequest {
+	return &protocol.JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "prompts/get",
+		Params:  map[string]interface{}{"name": name, "arguments": args},
+	}
+}
+
+func TestPromptArguments(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	var received map[string]interface{}
+	prompt := mcp.NewPrompt("review", "Review a file", []protocol.PromptArgument{
+		mcp.NewPromptArgument("file_path", "File to review", true),
+		mcp.NewPromptArgument("focus", "What to focus on", false),
+		mcp.NewPromptArgument("tone", "How to phrase it", false),
+	})
+	require.NoError(t, ms.AddPrompt(prompt, map[string]string{"focus": "correctness"},
+		mcp.PromptHandlerFunc(func(_ context.Context, args map[string]interface{}) ([]protocol.Content, error) {
+			received = args
+			return []protocol.Content{protocol.NewContent("review it")}, nil
+		})))
+
+	resp := ms.HandleRequest(context.Background(), promptGetRequest("review", map[string]interface{}{"file_path": "main.go"}))
+	require.Nil(t, resp.Error)
+	assert.Equal(t, map[string]interface{}{"file_path": "main.go", "focus": "correctness"}, received, "defaults fill in left out arguments")
+
+	tests := []struct {
+		name  string
+		args  map[string]interface{}
+		field string
+		want  []string
+	}{
+		{"missing required", map[string]interface{}{"focus": "style"}, "missing", []string{"file_path"}},
+		{"unknown", map[string]interface{}{"file_path": "main.go", "language": "go"}, "unknown", []string{"language"}},
+		{"not a string", map[string]interface{}{"file_path": 42.0}, "not_strings", []string{"file_path"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = nil
+			resp := ms.HandleRequest(context.Background(), promptGetRequest("review", tt.args))
+			require.NotNil(t, resp.Error)
+			assert.Equal(t, protocol.InvalidParams, resp.Error.Code)
+			assert.Equal(t, tt.want, resp.Error.Data.(map[string]interface{})[tt.field])
+			assert.Nil(t, received, "the handler is not called")
+		})
+	}
+
+	err := ms.AddPrompt(mcp.NewPrompt("broken", "", nil), map[string]string{"x": "y"}, nil)
+	assert.ErrorContains(t, err, "undeclared argument x")
+}
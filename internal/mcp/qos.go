@@ -0,0 +1,260 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/logging"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+// OverloadedCode is the JSON-RPC error code of requests refused because
+// their QoS class has no room, in the server error range used for
+// unavailable services; clients may retry them later
+const OverloadedCode = -32004
+
+// qosPriority orders the classes served first when the server-wide limit is
+// reached
+var qosPriority = []string{config.QoSInteractive, config.QoSAdmin, config.QoSBulk}
+
+// qosToolClasses are the tools classified by name
+var qosToolClasses = map[string]string{
+	"memory_transfer":       config.QoSBulk,
+	"system_snapshot":       config.QoSBulk,
+	"system_page_sync":      config.QoSBulk,
+	"system_slack_sync":     config.QoSBulk,
+	"memory_quality_report": config.QoSBulk,
+	"memory_dedupe":         config.QoSBulk,
+	"memory_system":         config.QoSAdmin,
+	"project_manage":        config.QoSAdmin,
+	"tag_manage":            config.QoSAdmin,
+	"memory_decay_policies": config.QoSAdmin,
+}
+
+// Reasons a request gets no turn
+var (
+	errQoSQueueFull = errors.New("queue is full")
+	errQoSTimeout   = errors.New("timed out waiting for a turn")
+)
+
+// qosClass is one class's running requests and the requests waiting, oldest first
+type qosClass struct {
+	limits   config.QoSClassConfig
+	running  int
+	waiting  []chan struct{}
+	rejected int64
+}
+
+// QoSClassStats is a snapshot of one QoS class
+type QoSClassStats struct {
+	Running     int   `json:"running"`
+	Waiting     int   `json:"waiting"`
+	Rejected    int64 `json:"rejected"`
+	Concurrency int   `json:"concurrency"`
+	QueueSize   int   `json:"queue_size"`
+}
+
+// QoSScheduler gives requests turns by QoS class. Each class runs up to its
+// concurrency at once and queues up to its queue size more; the rest are
+// refused. A freed turn goes to the longest waiting request of the
+// highest-priority class with room.
+type QoSScheduler struct {
+	maxConcurrent int
+	queueTimeout  time.Duration
+	tools         map[string]string
+
+	mu      sync.Mutex
+	running int
+	classes map[string]*qosClass
+}
+
+// NewQoSScheduler creates a scheduler from the QoS configuration. It returns
+// nil when QoS scheduling is disabled.
+func NewQoSScheduler(cfg *config.QoSConfig) *QoSScheduler {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+
+	scheduler := &QoSScheduler{
+		maxConcurrent: cfg.MaxConcurrent,
+		queueTimeout:  time.Duration(cfg.QueueTimeoutSeconds) * time.Second,
+		tools:         cfg.Tools,
+		classes:       make(map[string]*qosClass, len(qosPriority)),
+	}
+	for _, name := range qosPriority {
+		scheduler.classes[name] = &qosClass{limits: cfg.Class(name)}
+	}
+	return scheduler
+}
+
+// Classify returns the QoS class of a call to tool with operation.
+// Configured classes come first; otherwise bulk operations and tools that
+// move many memories are bulk, system and auth tools are admin, and
+// everything else is interactive.
+func (s *QoSScheduler) Classify(tool, operation string) string {
+	if operation != "" {
+		if class, ok := s.tools[tool+":"+operation]; ok {
+			return class
+		}
+	}
+	if class, ok := s.tools[tool]; ok {
+		return class
+	}
+
+	switch {
+	case strings.HasPrefix(operation, "bulk_"), strings.HasPrefix(operation, "import_"), strings.HasPrefix(operation, "export_"):
+		return config.QoSBulk
+	case qosToolClasses[tool] != "":
+		return qosToolClasses[tool]
+	case strings.HasPrefix(tool, "system_"), strings.HasPrefix(tool, "auth_"):
+		return config.QoSAdmin
+	}
+	return config.QoSInteractive
+}
+
+// Acquire waits for a turn in class. It returns errQoSQueueFull when the
+// class queue is full, errQoSTimeout when no turn came in time, or the
+// context's error. Callers release the turn with Release.
+func (s *QoSScheduler) Acquire(ctx context.Context, class string) error {
+	s.mu.Lock()
+	c := s.classes[class]
+	if len(c.waiting) == 0 && s.hasRoomLocked(c) && !s.higherWaitingLocked(class) {
+		s.grantLocked(c)
+		s.mu.Unlock()
+		return nil
+	}
+	if len(c.waiting) >= c.limits.QueueSize {
+		c.rejected++
+		s.mu.Unlock()
+		return errQoSQueueFull
+	}
+	turn := make(chan struct{})
+	c.waiting = append(c.waiting, turn)
+	s.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if s.queueTimeout > 0 {
+		timer := time.NewTimer(s.queueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	var err error
+	select {
+	case <-turn:
+		return nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-timeout:
+		err = errQoSTimeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, waiting := range c.waiting {
+		if waiting == turn {
+			c.waiting = append(c.waiting[:i], c.waiting[i+1:]...)
+			c.rejected++
+			return err
+		}
+	}
+	// The turn was granted while giving up; pass it on
+	s.releaseLocked(c)
+	return err
+}
+
+// Release ends a turn in class and hands freed turns to waiting requests
+func (s *QoSScheduler) Release(class string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.releaseLocked(s.classes[class])
+}
+
+// Stats returns a snapshot of each class
+func (s *QoSScheduler) Stats() map[string]QoSClassStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := make(map[string]QoSClassStats, len(s.classes))
+	for name, c := range s.classes {
+		stats[name] = QoSClassStats{
+			Running:     c.running,
+			Waiting:     len(c.waiting),
+			Rejected:    c.rejected,
+			Concurrency: c.limits.Concurrency,
+			QueueSize:   c.limits.QueueSize,
+		}
+	}
+	return stats
+}
+
+// hasRoomLocked reports whether a request of c could run now
+func (s *QoSScheduler) hasRoomLocked(c *qosClass) bool {
+	return c.running < c.limits.Concurrency && (s.maxConcurrent == 0 || s.running < s.maxConcurrent)
+}
+
+// higherWaitingLocked reports whether a class ahead of class has a request
+// waiting that could use the next server-wide turn
+func (s *QoSScheduler) higherWaitingLocked(class string) bool {
+	for _, name := range qosPriority {
+		if name == class {
+			return false
+		}
+		if c := s.classes[name]; len(c.waiting) > 0 && c.running < c.limits.Concurrency {
+			return true
+		}
+	}
+	return false
+}
+
+// grantLocked counts a turn of c as running
+func (s *QoSScheduler) grantLocked(c *qosClass) {
+	c.running++
+	s.running++
+}
+
+// releaseLocked ends a turn of c and wakes waiting requests, highest
+// priority first, while there is room
+func (s *QoSScheduler) releaseLocked(c *qosClass) {
+	c.running--
+	s.running--
+	for _, name := range qosPriority {
+		next := s.classes[name]
+		for len(next.waiting) > 0 && s.hasRoomLocked(next) {
+			turn := next.waiting[0]
+			next.waiting = next.waiting[1:]
+			s.grantLocked(next)
+			close(turn)
+		}
+	}
+}
+
+// QoSMiddleware runs tool calls and resource reads in their QoS class's turn.
+// Requests that get no turn are refused with an OverloadedCode error whose
+// data names the class.
+func QoSMiddleware(scheduler *QoSScheduler) Middleware {
+	return ForMethods(func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+			class := config.QoSInteractive
+			if req.Method == "tools/call" {
+				class = scheduler.Classify(RequestTarget(req), toolOperation(req))
+			}
+
+			if err := scheduler.Acquire(ctx, class); err != nil {
+				logging.Warn("MCP request refused by QoS scheduling", "method", req.Method, "target", RequestTarget(req), "class", class, "reason", err)
+				return ErrorResponse(req, OverloadedCode, "Server busy", map[string]interface{}{
+					"code":   "OVERLOADED",
+					"class":  class,
+					"reason": err.Error(),
+				})
+			}
+			defer scheduler.Release(class)
+			return next(ctx, req)
+		}
+	}, "tools/call", "resources/read")
+}
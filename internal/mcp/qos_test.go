@@ -0,0 +1,152 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/config"
+
+	mcp "github.com/fredcamaral/gomcp-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestQoSScheduler(maxConcurrent int, limits config.QoSClassConfig) *QoSScheduler {
+	return NewQoSScheduler(&config.QoSConfig{
+		Enabled:       true,
+		MaxConcurrent: maxConcurrent,
+		Interactive:   limits,
+		Bulk:          limits,
+		Admin:         limits,
+		Tools:         map[string]string{"memory_analyze": config.QoSBulk, "memory_transfer:export_site": config.QoSAdmin},
+	})
+}
+
+// acquireAsync waits for a turn in the background, reporting the outcome
+func acquireAsync(ctx context.Context, scheduler *QoSScheduler, class string) <-chan error {
+	done := make(chan error, 1)
+	go func() { done <- scheduler.Acquire(ctx, class) }()
+	return done
+}
+
+// waitQueued waits until class has waiting requests
+func waitQueued(t *testing.T, scheduler *QoSScheduler, class string, waiting int) {
+	t.Helper()
+	require.Eventually(t, func() bool { return scheduler.Stats()[class].Waiting == waiting }, time.Second, time.Millisecond)
+}
+
+func TestQoSClassify(t *testing.T) {
+	scheduler := newTestQoSScheduler(0, config.QoSClassConfig{Concurrency: 1})
+	for _, tc := range []struct {
+		tool, operation, class string
+	}{
+		{"memory_read", "search", config.QoSInteractive},
+		{"memory_create", "bulk_import", config.QoSBulk},
+		{"memory_delete", "bulk_delete", config.QoSBulk},
+		{"memory_transfer", "export_project", config.QoSBulk},
+		{"memory_transfer", "export_site", config.QoSAdmin},
+		{"memory_analyze", "health_score", config.QoSBulk},
+		{"system_page_sync", "list_sources", config.QoSBulk},
+		{"system_tool_stats", "", config.QoSAdmin},
+		{"auth_create_key", "", config.QoSAdmin},
+		{"memory_system", "health", config.QoSAdmin},
+		{"memory_graph_query", "", config.QoSInteractive},
+	} {
+		assert.Equal(t, tc.class, scheduler.Classify(tc.tool, tc.operation), tc.tool+":"+tc.operation)
+	}
+}
+
+func TestQoSSchedulerLimitsEachClass(t *testing.T) {
+	ctx := context.Background()
+	scheduler := newTestQoSScheduler(0, config.QoSClassConfig{Concurrency: 1, QueueSize: 1})
+
+	require.NoError(t, scheduler.Acquire(ctx, config.QoSBulk))
+	queued := acquireAsync(ctx, scheduler, config.QoSBulk)
+	waitQueued(t, scheduler, config.QoSBulk, 1)
+	assert.ErrorIs(t, scheduler.Acquire(ctx, config.QoSBulk), errQoSQueueFull)
+
+	// A busy bulk class leaves interactive calls alone
+	require.NoError(t, scheduler.Acquire(ctx, config.QoSInteractive))
+	scheduler.Release(config.QoSInteractive)
+
+	scheduler.Release(config.QoSBulk)
+	require.NoError(t, <-queued)
+	stats := scheduler.Stats()[config.QoSBulk]
+	assert.Equal(t, 1, stats.Running)
+	assert.Equal(t, int64(1), stats.Rejected)
+	scheduler.Release(config.QoSBulk)
+	assert.Equal(t, 0, scheduler.Stats()[config.QoSBulk].Running)
+}
+
+func TestQoSSchedulerServesInteractiveFirst(t *testing.T) {
+	ctx := context.Background()
+	scheduler := newTestQoSScheduler(1, config.QoSClassConfig{Concurrency: 2, QueueSize: 2})
+
+	require.NoError(t, scheduler.Acquire(ctx, config.QoSBulk))
+	bulk := acquireAsync(ctx, scheduler, config.QoSBulk)
+	waitQueued(t, scheduler, config.QoSBulk, 1)
+	interactive := acquireAsync(ctx, scheduler, config.QoSInteractive)
+	waitQueued(t, scheduler, config.QoSInteractive, 1)
+
+	scheduler.Release(config.QoSBulk)
+	require.NoError(t, <-interactive, "the freed turn goes to the interactive request though bulk waited longer")
+	select {
+	case <-bulk:
+		t.Fatal("bulk request ran past the server-wide limit")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	scheduler.Release(config.QoSInteractive)
+	require.NoError(t, <-bulk)
+	scheduler.Release(config.QoSBulk)
+}
+
+func TestQoSSchedulerGivesUp(t *testing.T) {
+	scheduler := newTestQoSScheduler(0, config.QoSClassConfig{Concurrency: 1, QueueSize: 4})
+	scheduler.queueTimeout = 10 * time.Millisecond
+	require.NoError(t, scheduler.Acquire(context.Background(), config.QoSAdmin))
+
+	assert.ErrorIs(t, scheduler.Acquire(context.Background(), config.QoSAdmin), errQoSTimeout)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, scheduler.Acquire(ctx, config.QoSAdmin), context.Canceled)
+
+	stats := scheduler.Stats()[config.QoSAdmin]
+	assert.Equal(t, 0, stats.Waiting, "requests that gave up leave the queue")
+	assert.Equal(t, int64(2), stats.Rejected)
+}
+
+func TestQoSMiddleware(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	scheduler := newTestQoSScheduler(0, config.QoSClassConfig{Concurrency: 1})
+	ms.Use(QoSMiddleware(scheduler))
+
+	started, unblock := make(chan struct{}), make(chan struct{})
+	ms.addTool(mcp.NewTool("memory_analyze", "Analyze", mcp.ObjectSchema("Analyze", map[string]interface{}{}, nil)),
+		mcp.ToolHandlerFunc(func(context.Context, map[string]interface{}) (interface{}, error) {
+			close(started)
+			<-unblock
+			return "done", nil
+		}))
+
+	finished := make(chan struct{})
+	go func() {
+		ms.HandleRequest(context.Background(), toolCallRequest("memory_analyze", nil))
+		close(finished)
+	}()
+	<-started
+
+	resp := ms.HandleRequest(context.Background(), toolCallRequest("memory_analyze", nil))
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, OverloadedCode, resp.Error.Code)
+	assert.NotEqual(t, ForbiddenCode, resp.Error.Code, "clients retry overloads, not refusals")
+	assert.Equal(t, config.QoSBulk, resp.Error.Data.(map[string]interface{})["class"])
+
+	resp = ms.HandleRequest(context.Background(), toolCallRequest("echo", nil))
+	assert.Nil(t, resp.Error, "interactive calls run while bulk work is busy")
+
+	close(unblock)
+	<-finished
+	assert.Equal(t, 0, scheduler.Stats()[config.QoSBulk].Running)
+}
@@ -0,0 +1,215 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"lerian-mcp-memory/internal/intelligence"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/pkg/types"
+
+	mcp "github.com/fredcamaral/gomcp-sdk"
+)
+
+// Reasons a memory scored low, listed with it in the quality report
+const (
+	qualityReasonShort   = "short"
+	qualityReasonVague   = "vague"
+	qualityReasonOld     = "old"
+	qualityReasonUncited = "never_cited"
+)
+
+// qualityReasonThreshold marks a quality signal below it as a reason the
+// memory scored low
+const qualityReasonThreshold = 0.4
+
+// registerQualityReportTool registers memory_quality_report
+func (ms *MemoryServer) registerQualityReportTool() {
+	ms.addTool(mcp.NewTool(
+		"memory_quality_report",
+		"Score every memory of a repository for quality and list the weakest ones as candidates to prune. A memory's score combines its length and recorded outcome, its specificity (paths, identifiers, versions and errors rather than vague wording) and code, its recency, and how many other memories cite it. Scores are saved on the memories and search ranks higher-quality memories first; pass dry_run to only report. Prune with memory_delete bulk_delete.",
+		mcp.ObjectSchema("Quality report parameters", map[string]interface{}{
+			"repository": map[string]interface{}{
+				"type":        "string",
+				"description": "Repository URL (required) - e.g. 'github.com/user/repo'. Use 'global' to score every repository",
+			},
+			"threshold": map[string]interface{}{
+				"type":        "number",
+				"default":     defaultQualityThreshold,
+				"description": "Memories whose overall quality (0-1) is below this are listed",
+			},
+			"max_chunks": map[string]interface{}{
+				"type":        "number",
+				"default":     defaultReportChunks,
+				"description": "Most recent memories to score",
+			},
+			"limit": map[string]interface{}{
+				"type":        "number",
+				"default":     defaultReportItems,
+				"description": "Low-quality memories to list, weakest first",
+			},
+			"dry_run": map[string]interface{}{
+				"type":        "boolean",
+				"default":     false,
+				"description": "Report without saving the scores on the memories",
+			},
+		}, []string{"repository"}),
+	), mcp.ToolHandlerFunc(ms.handleQualityReportTool))
+}
+
+// handleQualityReportTool scores a repository's memories with
+// intelligence.AnalyzeQuality, saves the scores and lists the weakest
+func (ms *MemoryServer) handleQualityReportTool(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: memory_quality_report called", "args", args)
+
+	repository, _ := args["repository"].(string)
+	if repository == "" {
+		return nil, errors.New("repository parameter is required. Example: {\"repository\": \"github.com/user/repo\", \"threshold\": 0.4}")
+	}
+	threshold := defaultQualityThreshold
+	if th, ok := args["threshold"].(float64); ok {
+		if th <= 0 || th > 1 {
+			return nil, NewToolError(ToolErrorInvalidParameter, "threshold must be between 0 and 1").WithParameter("threshold")
+		}
+		threshold = th
+	}
+	chunkLimit, itemLimit := reportLimits(args)
+	dryRun, _ := args["dry_run"].(bool)
+
+	chunks, err := ms.reportChunks(ctx, repository, chunkLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var total float64
+	scored, saved, failed := 0, 0, 0
+	distribution := map[string]int{"high": 0, "medium": 0, "low": 0}
+	lowQuality := make([]map[string]interface{}, 0)
+	for i := range chunks {
+		chunk := &chunks[i]
+		if chunk.IsPassage() {
+			continue
+		}
+		quality := intelligence.AnalyzeQuality(chunk, ms.citationCount(ctx, chunk.ID), now)
+		scored++
+		total += quality.OverallQuality
+
+		switch {
+		case quality.OverallQuality >= 0.7:
+			distribution["high"]++
+		case quality.OverallQuality >= 0.4:
+			distribution["medium"]++
+		default:
+			distribution["low"]++
+		}
+
+		if quality.OverallQuality < threshold {
+			lowQuality = append(lowQuality, map[string]interface{}{
+				"chunk_id":        chunk.ID,
+				"type":            string(chunk.Type),
+				"summary":         chunk.Summary,
+				"timestamp":       chunk.Timestamp.Format(time.RFC3339),
+				"overall_quality": quality.OverallQuality,
+				"completeness":    quality.Completeness,
+				"clarity":         quality.Clarity,
+				"freshness":       quality.FreshnessScore,
+				"citations":       quality.Citations,
+				"reasons":         qualityReasons(quality),
+			})
+		}
+
+		if dryRun {
+			continue
+		}
+		if _, err := ms.updateChunk(ctx, chunk.ID, "", func(current *types.ConversationChunk) error {
+			current.Metadata.Quality = quality
+			return nil
+		}); err != nil {
+			logging.Warn("memory_quality_report: failed to save quality score", "chunk_id", chunk.ID, "error", err)
+			failed++
+			continue
+		}
+		saved++
+	}
+
+	sort.SliceStable(lowQuality, func(i, j int) bool {
+		return lowQuality[i]["overall_quality"].(float64) < lowQuality[j]["overall_quality"].(float64)
+	})
+	lowQualityCount := len(lowQuality)
+	pruneIDs := make([]string, 0, min(itemLimit, lowQualityCount))
+	if len(lowQuality) > itemLimit {
+		lowQuality = lowQuality[:itemLimit]
+	}
+	for _, item := range lowQuality {
+		pruneIDs = append(pruneIDs, item["chunk_id"].(string))
+	}
+
+	average := 0.0
+	if scored > 0 {
+		average = total / float64(scored)
+	}
+	response := map[string]interface{}{
+		"status":     "success",
+		"repository": repository,
+		"summary": map[string]interface{}{
+			"memories_scored":   scored,
+			"average_quality":   average,
+			"distribution":      distribution,
+			"low_quality_count": lowQualityCount,
+			"threshold":         threshold,
+			"scores_saved":      saved,
+			"dry_run":           dryRun,
+		},
+		"low_quality":  lowQuality,
+		"generated_at": now.Format(time.RFC3339),
+	}
+	if failed > 0 {
+		response["summary"].(map[string]interface{})["scores_failed"] = failed
+	}
+	if len(pruneIDs) > 0 {
+		response["prune"] = map[string]interface{}{
+			"tool":      "memory_delete",
+			"operation": "bulk_delete",
+			"options":   map[string]interface{}{"repository": repository, "ids": pruneIDs},
+		}
+	}
+	return response, nil
+}
+
+// citationCount returns how many relationships point at a chunk
+func (ms *MemoryServer) citationCount(ctx context.Context, chunkID string) int {
+	relationships, err := ms.container.GetVectorStore().GetRelationships(ctx, &types.RelationshipQuery{
+		ChunkID:   chunkID,
+		Direction: "incoming",
+		SortBy:    "confidence",
+		SortOrder: "desc",
+		MaxDepth:  1,
+		Limit:     100,
+	})
+	if err != nil {
+		logging.Warn("memory_quality_report: failed to count citations", "chunk_id", chunkID, "error", err)
+		return 0
+	}
+	return len(relationships)
+}
+
+// qualityReasons names the signals that pulled a memory's quality down
+func qualityReasons(quality *types.QualityMetrics) []string {
+	reasons := make([]string, 0, 4)
+	if quality.Completeness < qualityReasonThreshold {
+		reasons = append(reasons, qualityReasonShort)
+	}
+	if quality.Clarity < qualityReasonThreshold {
+		reasons = append(reasons, qualityReasonVague)
+	}
+	if quality.FreshnessScore < qualityReasonThreshold {
+		reasons = append(reasons, qualityReasonOld)
+	}
+	if quality.Citations == 0 {
+		reasons = append(reasons, qualityReasonUncited)
+	}
+	return reasons
+}
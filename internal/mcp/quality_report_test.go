@@ -0,0 +1,58 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQualityReportTool(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewLocalVectorStore("")
+	ms := newCompositeTestServer(t, store)
+
+	detailed := newReportChunk(t, "s1", "Checkout timed out after 30s: internal/payments/client.go reused a closed connection. "+
+		"Fixed with `payments.NewClient(cfg)` per request, verified with go test ./internal/payments on pgx v5.5.1.", types.ChunkTypeSolution,
+		types.ChunkMetadata{Outcome: types.OutcomeSuccess})
+	vague := newReportChunk(t, "s1", "fixed it somehow", types.ChunkTypeDiscussion, types.ChunkMetadata{})
+	citing := newReportChunk(t, "s2", "Same timeout in staging, see the payments fix", types.ChunkTypeProblem, types.ChunkMetadata{})
+	for _, chunk := range []*types.ConversationChunk{detailed, vague, citing} {
+		require.NoError(t, store.Store(ctx, chunk))
+	}
+	_, err := store.StoreRelationship(ctx, citing.ID, detailed.ID, types.RelationReferences, 0.9, types.ConfidenceExplicit)
+	require.NoError(t, err)
+
+	result, err := ms.handleQualityReportTool(ctx, map[string]interface{}{"repository": "github.com/acme/api", "dry_run": true})
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	summary := response["summary"].(map[string]interface{})
+	assert.Equal(t, 3, summary["memories_scored"])
+	assert.Equal(t, 0, summary["scores_saved"])
+	lowQuality := response["low_quality"].([]map[string]interface{})
+	require.NotEmpty(t, lowQuality)
+	assert.Equal(t, vague.ID, lowQuality[0]["chunk_id"])
+	assert.Contains(t, lowQuality[0]["reasons"], qualityReasonShort)
+	assert.Contains(t, response["prune"].(map[string]interface{})["options"].(map[string]interface{})["ids"], vague.ID)
+	unsaved, err := store.GetByID(ctx, vague.ID)
+	require.NoError(t, err)
+	assert.Nil(t, unsaved.Metadata.Quality, "a dry run saves nothing")
+
+	_, err = ms.handleQualityReportTool(ctx, map[string]interface{}{"repository": "github.com/acme/api"})
+	require.NoError(t, err)
+	scored, err := store.GetByID(ctx, detailed.ID)
+	require.NoError(t, err)
+	require.NotNil(t, scored.Metadata.Quality)
+	assert.Equal(t, 1, scored.Metadata.Quality.Citations)
+	poor, err := store.GetByID(ctx, vague.ID)
+	require.NoError(t, err)
+	require.NotNil(t, poor.Metadata.Quality)
+	assert.Greater(t, scored.Metadata.Quality.OverallQuality, poor.Metadata.Quality.OverallQuality)
+
+	_, err = ms.handleQualityReportTool(ctx, map[string]interface{}{"repository": "github.com/acme/api", "threshold": float64(2)})
+	assert.Equal(t, ToolErrorInvalidParameter, asToolError(err).Code)
+}
@@ -0,0 +1,60 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"lerian-mcp-memory/internal/logging"
+)
+
+const (
+	// defaultQuantizationSamples is how many stored vectors a quantization report searches for
+	defaultQuantizationSamples = 20
+
+	// maxQuantizationSamples bounds the searches of one quantization report
+	maxQuantizationSamples = 200
+
+	// defaultQuantizationK is how many neighbours a quantization report compares
+	defaultQuantizationK = 10
+
+	// maxQuantizationK bounds the neighbours a quantization report compares
+	maxQuantizationK = 100
+)
+
+// handleQuantizationReport reports how much vector quantization saves the
+// memory collection and how much recall quantized search gives up for it
+func (ms *MemoryServer) handleQuantizationReport(ctx context.Context, options map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: memory_system quantization_report called", "options", options)
+
+	qdrantStore := ms.container.GetQdrant()
+	if qdrantStore == nil {
+		return nil, errors.New("quantization reports need the Qdrant storage provider")
+	}
+
+	sampleSize := boundedOption(options, "sample_size", defaultQuantizationSamples, maxQuantizationSamples)
+	k := boundedOption(options, "k", defaultQuantizationK, maxQuantizationK)
+	report, err := qdrantStore.QuantizationReport(ctx, sampleSize, k)
+	if err != nil {
+		return nil, fmt.Errorf("quantization report failed: %w", err)
+	}
+
+	return map[string]interface{}{
+		"status":     "success",
+		"operation":  OperationQuantizationReport,
+		"report":     report,
+		"configured": ms.container.Config.Qdrant.Quantization.ForCollection(report.Collection).Mode,
+	}, nil
+}
+
+// boundedOption reads a positive number option, falling back to def and capped at limit
+func boundedOption(options map[string]interface{}, key string, def, limit int) int {
+	value := def
+	if number, ok := options[key].(float64); ok && number >= 1 {
+		value = int(number)
+	}
+	if value > limit {
+		value = limit
+	}
+	return value
+}
@@ -0,0 +1,28 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuantizationReportNeedsQdrant(t *testing.T) {
+	ms := newCompositeTestServer(t, storage.NewKeywordStore(""))
+
+	_, err := ms.handleMemorySystem(context.Background(), map[string]interface{}{
+		"operation": OperationQuantizationReport,
+		"options":   map[string]interface{}{},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Qdrant storage provider")
+}
+
+func TestBoundedOption(t *testing.T) {
+	options := map[string]interface{}{"k": 500.0, "sample_size": 0.0}
+	assert.Equal(t, maxQuantizationK, boundedOption(options, "k", defaultQuantizationK, maxQuantizationK))
+	assert.Equal(t, defaultQuantizationSamples, boundedOption(options, "sample_size", defaultQuantizationSamples, maxQuantizationSamples))
+}
@@ -0,0 +1,230 @@
+package mcp
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/logging"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+const (
+	// RateLimitedCode is the JSON-RPC error code of throttled requests, in the
+	// server error range used for rate limiting across protocols
+	RateLimitedCode = -32001
+
+	// anonymousClient names clients whose transport identifies neither them nor their connection
+	anonymousClient = "anonymous"
+	// maxRateBuckets bounds the buckets kept before idle ones are dropped
+	maxRateBuckets = 10000
+)
+
+type clientIDKey struct{}
+
+// WithClientID returns a context naming the client that sends requests served
+// with it, such as its remote address. Rate limits are kept per client;
+// requests without one are counted against their connection instead.
+func WithClientID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, clientIDKey{}, id)
+}
+
+// clientIDFrom returns the client identity of ctx: its client ID, else its
+// connection ID, else a name shared by all anonymous clients
+func clientIDFrom(ctx context.Context) string {
+	if id, _ := ctx.Value(clientIDKey{}).(string); id != "" {
+		return id
+	}
+	if id := connectionIDFrom(ctx); id != "" {
+		return id
+	}
+	return anonymousClient
+}
+
+// RateLimit allows PerMinute calls a minute on average, and up to Burst at once
+type RateLimit struct {
+	PerMinute int `json:"per_minute"`
+	Burst     int `json:"burst"`
+}
+
+// tokenBucket holds the calls a client may still make under one limit
+type tokenBucket struct {
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+}
+
+// refill adds the tokens earned since the bucket was last used
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed.Minutes()*float64(b.limit.PerMinute))
+	}
+	b.last = now
+}
+
+// wait returns how long until the bucket holds a whole token
+func (b *tokenBucket) wait() time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / float64(b.limit.PerMinute) * float64(time.Minute))
+}
+
+// RateDecision is the outcome of a rate limit check. When a call is not
+// allowed, Scope names the limit it hit and RetryAfter when to try again.
+type RateDecision struct {
+	Allowed    bool
+	Scope      string
+	Limit      RateLimit
+	RetryAfter time.Duration
+}
+
+// RateLimiter throttles tool calls with token buckets kept per client: one
+// across all tools, and one for each limited tool or tool operation. A call
+// must fit in every bucket it falls under and only then takes from them, so
+// rejected calls do not use up the client's budget.
+type RateLimiter struct {
+	client  RateLimit
+	clients map[string]RateLimit // client -> limit replacing the default
+	tools   map[string]RateLimit // tool or tool:operation -> limit
+	now     func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket // client + scope -> bucket
+}
+
+// NewRateLimiter creates a limiter from the rate limit configuration. It
+// returns nil when rate limiting is disabled.
+func NewRateLimiter(cfg *config.RateLimitConfig) *RateLimiter {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+
+	limiter := &RateLimiter{
+		client:  RateLimit{PerMinute: cfg.RequestsPerMinute, Burst: cfg.Burst},
+		clients: make(map[string]RateLimit, len(cfg.Clients)),
+		tools:   make(map[string]RateLimit, len(cfg.Tools)),
+		now:     time.Now,
+		buckets: make(map[string]*tokenBucket),
+	}
+	if limiter.client.Burst == 0 {
+		limiter.client.Burst = limiter.client.PerMinute
+	}
+	for client, perMinute := range cfg.Clients {
+		limiter.clients[client] = RateLimit{PerMinute: perMinute, Burst: perMinute}
+	}
+	for tool, perMinute := range cfg.Tools {
+		limiter.tools[tool] = RateLimit{PerMinute: perMinute, Burst: perMinute}
+	}
+	return limiter
+}
+
+// Allow checks a call to tool (with its operation, if any) by client and,
+// when it fits within every limit it falls under, counts it
+func (rl *RateLimiter) Allow(client, tool, operation string) RateDecision {
+	scopes := make(map[string]RateLimit, 3)
+	if limit, ok := rl.clients[client]; ok {
+		scopes["client"] = limit
+	} else if rl.client.PerMinute > 0 {
+		scopes["client"] = rl.client
+	}
+	if limit, ok := rl.tools[tool]; ok {
+		scopes[tool] = limit
+	}
+	if operation != "" {
+		if limit, ok := rl.tools[tool+":"+operation]; ok {
+			scopes[tool+":"+operation] = limit
+		}
+	}
+	if len(scopes) == 0 {
+		return RateDecision{Allowed: true}
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	if len(rl.buckets) > maxRateBuckets {
+		rl.pruneLocked(now)
+	}
+
+	names := make([]string, 0, len(scopes))
+	for scope := range scopes {
+		names = append(names, scope)
+	}
+	sort.Strings(names)
+
+	buckets := make([]*tokenBucket, 0, len(names))
+	decision := RateDecision{Allowed: true}
+	for _, scope := range names {
+		key := client + "\x00" + scope
+		bucket, ok := rl.buckets[key]
+		if !ok || bucket.limit != scopes[scope] {
+			bucket = &tokenBucket{limit: scopes[scope], tokens: float64(scopes[scope].Burst), last: now}
+			rl.buckets[key] = bucket
+		}
+		bucket.refill(now)
+		if wait := bucket.wait(); wait > decision.RetryAfter {
+			decision = RateDecision{Scope: scope, Limit: bucket.limit, RetryAfter: wait}
+		}
+		buckets = append(buckets, bucket)
+	}
+	if decision.RetryAfter > 0 {
+		return decision
+	}
+
+	for _, bucket := range buckets {
+		bucket.tokens--
+	}
+	return decision
+}
+
+// pruneLocked drops buckets that have refilled completely, since a new bucket
+// would be the same; callers must hold the lock
+func (rl *RateLimiter) pruneLocked(now time.Time) {
+	for key, bucket := range rl.buckets {
+		bucket.refill(now)
+		if bucket.tokens >= float64(bucket.limit.Burst) {
+			delete(rl.buckets, key)
+		}
+	}
+}
+
+// RateLimitMiddleware rejects tools/call requests over the client's limits
+// with a RateLimitedCode error whose data says which limit was hit and how
+// many seconds to wait before retrying.
+func RateLimitMiddleware(limiter *RateLimiter) Middleware {
+	return ForMethods(func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+			tool := RequestTarget(req)
+			client := clientIDFrom(ctx)
+			decision := limiter.Allow(client, tool, toolOperation(req))
+			if decision.Allowed {
+				return next(ctx, req)
+			}
+
+			retryAfter := int(math.Ceil(decision.RetryAfter.Seconds()))
+			logging.Warn("MCP request rate limited", "client", client, "tool", tool, "scope", decision.Scope, "retry_after", retryAfter)
+			return ErrorResponse(req, RateLimitedCode, "Rate limit exceeded", map[string]interface{}{
+				"code":           "RATE_LIMITED",
+				"scope":          decision.Scope,
+				"limit":          decision.Limit.PerMinute,
+				"window":         "minute",
+				"retry_after":    retryAfter,
+				"retry_after_ms": decision.RetryAfter.Milliseconds(),
+			})
+		}
+	}, "tools/call")
+}
+
+// toolOperation returns the operation argument of a tools/call request, or ""
+func toolOperation(req *protocol.JSONRPCRequest) string {
+	arguments, _ := requestParams(req)["arguments"].(map[string]interface{})
+	operation, _ := arguments["operation"].(string)
+	return strings.TrimSpace(operation)
+}
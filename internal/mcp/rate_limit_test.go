@@ -0,0 +1,84 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/config"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRateLimiter(cfg *config.RateLimitConfig) (*RateLimiter, *time.Time) {
+	cfg.Enabled = true
+	limiter := NewRateLimiter(cfg)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+	return limiter, &now
+}
+
+func TestRateLimiter_ToolAndClientLimits(t *testing.T) {
+	limiter, now := newTestRateLimiter(&config.RateLimitConfig{
+		RequestsPerMinute: 3,
+		Tools:             map[string]int{"memory_read:search": 2},
+		Clients:           map[string]int{"ci": 100},
+	})
+
+	assert.True(t, limiter.Allow("a", "memory_read", "search").Allowed)
+	assert.True(t, limiter.Allow("a", "memory_read", "search").Allowed)
+	decision := limiter.Allow("a", "memory_read", "search")
+	require.False(t, decision.Allowed, "the search limit is spent")
+	assert.Equal(t, "memory_read:search", decision.Scope)
+	assert.Equal(t, 30*time.Second, decision.RetryAfter)
+
+	// Other operations only count against the client limit, which the
+	// rejected search did not use up
+	assert.True(t, limiter.Allow("a", "memory_read", "get_context").Allowed)
+	decision = limiter.Allow("a", "memory_read", "get_context")
+	require.False(t, decision.Allowed)
+	assert.Equal(t, "client", decision.Scope)
+
+	// Clients are limited separately, and some have their own limit
+	assert.True(t, limiter.Allow("b", "memory_read", "search").Allowed)
+	for i := 0; i < 10; i++ {
+		assert.True(t, limiter.Allow("ci", "memory_create", "store_chunk").Allowed)
+	}
+
+	*now = now.Add(30 * time.Second)
+	assert.True(t, limiter.Allow("a", "memory_read", "search").Allowed, "a token was earned back")
+	assert.False(t, limiter.Allow("a", "memory_read", "search").Allowed)
+}
+
+func TestRateLimiter_Disabled(t *testing.T) {
+	assert.Nil(t, NewRateLimiter(&config.RateLimitConfig{RequestsPerMinute: 1}))
+
+	limiter, _ := newTestRateLimiter(&config.RateLimitConfig{})
+	for i := 0; i < 5; i++ {
+		assert.True(t, limiter.Allow("a", "memory_read", "search").Allowed, "no limits apply")
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	limiter, _ := newTestRateLimiter(&config.RateLimitConfig{Tools: map[string]int{"echo": 1}})
+	ms.Use(RateLimitMiddleware(limiter))
+
+	ctx := WithClientID(context.Background(), "10.0.0.1")
+	resp := ms.HandleRequest(ctx, toolCallRequest("echo", nil))
+	require.Nil(t, resp.Error)
+
+	resp = ms.HandleRequest(ctx, toolCallRequest("echo", nil))
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, RateLimitedCode, resp.Error.Code)
+	data := resp.Error.Data.(map[string]interface{})
+	assert.Equal(t, "echo", data["scope"])
+	assert.Equal(t, 60, data["retry_after"])
+
+	resp = ms.HandleRequest(WithConnectionID(context.Background(), "session-2"), toolCallRequest("echo", nil))
+	assert.Nil(t, resp.Error, "other clients have their own bucket")
+	resp = ms.HandleRequest(ctx, &protocol.JSONRPCRequest{JSONRPC: "2.0", ID: 2, Method: "tools/list"})
+	assert.Nil(t, resp.Error, "only tool calls are limited")
+}
@@ -0,0 +1,262 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"lerian-mcp-memory/internal/llm"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/fredcamaral/gomcp-sdk"
+	"github.com/fredcamaral/gomcp-sdk/sampling"
+)
+
+const (
+	// reflectionTag marks reflections, which later reflections skip
+	reflectionTag = "reflection"
+
+	// reflectionCaptureTool marks the provenance of reflections
+	reflectionCaptureTool = "memory_reflect"
+
+	// maxReflectionSources bounds the memories of a session described to the LLM
+	maxReflectionSources = 100
+
+	// reflectionMaxTokens bounds the reflection the LLM writes
+	reflectionMaxTokens = 1200
+
+	// reflectionPrompt asks for a reflection as JSON
+	reflectionPrompt = `You review the memory log of one coding session and write its reflection for future sessions.
+Reply with a single JSON object and nothing else:
+{"summary": "one sentence on the session", "attempted": [], "worked": [], "failed": [], "lessons": []}
+"attempted" lists what the session set out to do, "worked" what succeeded, "failed" what failed or was abandoned and why, and "lessons" reusable advice for similar work. Each entry is one short sentence grounded in the log; leave a list empty rather than guess.`
+)
+
+// Reflection is the structured outcome of a session
+type Reflection struct {
+	Summary   string   `json:"summary"`
+	Attempted []string `json:"attempted"`
+	Worked    []string `json:"worked"`
+	Failed    []string `json:"failed"`
+	Lessons   []string `json:"lessons"`
+}
+
+// registerReflectTool registers memory_reflect
+func (ms *MemoryServer) registerReflectTool() {
+	ms.addTool(mcp.NewTool(
+		"memory_reflect",
+		"Reflect on a finished session: an LLM reads the session's memories and writes what was attempted, what worked, what failed and the lessons learned. The reflection is stored as a high-priority semantic memory linked to the session's memories, so later sessions find the lessons first. Uses the server's summarization LLM, or the client's model through MCP sampling when none is configured.",
+		mcp.ObjectSchema("Reflection parameters", map[string]interface{}{
+			"repository": map[string]interface{}{
+				"type":        "string",
+				"description": "Repository URL (required) - e.g. 'github.com/user/repo'",
+			},
+			"session_id": map[string]interface{}{
+				"type":        "string",
+				"description": "Session to reflect on (required)",
+			},
+			"notes": map[string]interface{}{
+				"type":        "string",
+				"description": "Context the memories lack, such as the session's goal or how it ended",
+			},
+		}, []string{"repository", "session_id"}),
+	), mcp.ToolHandlerFunc(ms.handleReflect))
+}
+
+// handleReflect writes and stores the reflection of a session
+func (ms *MemoryServer) handleReflect(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: memory_reflect called", "args", args)
+
+	repository, _ := args["repository"].(string)
+	sessionID, _ := args["session_id"].(string)
+	if repository == "" || repository == GlobalRepository || sessionID == "" {
+		return nil, errors.New("repository and session_id parameters are required. Example: {\"repository\": \"github.com/user/repo\", \"session_id\": \"auth-fix-session\"}")
+	}
+	notes, _ := args["notes"].(string)
+	if !strings.Contains(sessionID, "::") {
+		sessionID = ms.createRepositoryScopedSessionID(repository, sessionID)
+	}
+
+	chunks, err := ms.container.GetVectorStore().ListBySession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session memories: %w", err)
+	}
+	sources := make([]*types.ConversationChunk, 0, len(chunks))
+	for i := range chunks {
+		chunk := &chunks[i]
+		if chunk.Metadata.Repository != repository || chunk.IsDeleted() || chunk.IsPassage() || isReflection(chunk) {
+			continue
+		}
+		sources = append(sources, chunk)
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("session %s has no memories to reflect on", sessionID)
+	}
+	sort.SliceStable(sources, func(i, j int) bool { return sources[i].Timestamp.Before(sources[j].Timestamp) })
+	if len(sources) > maxReflectionSources {
+		sources = sources[len(sources)-maxReflectionSources:]
+	}
+
+	reflection, model, err := ms.reflect(ctx, sources, notes)
+	if err != nil {
+		return nil, err
+	}
+	record, err := ms.storeReflection(ctx, sources, reflection, model)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"status":       "success",
+		"repository":   repository,
+		"session_id":   sessionID,
+		"chunk_id":     record.ID,
+		"memory_class": record.Class(),
+		"reflection":   reflection,
+		"model":        model,
+		"sources":      len(sources),
+	}, nil
+}
+
+// reflect asks the summarization LLM, or else the client's model, for the
+// reflection of a session's memories, returning it with the model that wrote it
+func (ms *MemoryServer) reflect(ctx context.Context, sources []*types.ConversationChunk, notes string) (*Reflection, string, error) {
+	var memories strings.Builder
+	if notes != "" {
+		fmt.Fprintf(&memories, "Notes: %s\n\n", notes)
+	}
+	fmt.Fprintf(&memories, "Session %s in %s:\n", sources[0].SessionID, sources[0].Metadata.Repository)
+	for _, source := range sources {
+		fmt.Fprintf(&memories, "- [%s, %s, %s] %s\n", source.Timestamp.Format("2006-01-02 15:04"), source.Type, source.Metadata.Outcome, consolidationLine(source))
+	}
+
+	var text, model string
+	if provider := ms.llmRouter().For(llm.FeatureSummarization); provider != nil {
+		resp, err := provider.Complete(ctx, llm.Request{System: reflectionPrompt, Prompt: memories.String(), MaxTokens: reflectionMaxTokens})
+		if err != nil {
+			return nil, "", fmt.Errorf("reflection failed: %w", err)
+		}
+		text, model = resp.Text, resp.Model
+	} else {
+		resp, err := ms.RequestSampling(ctx, []sampling.SamplingMessage{{
+			Role:    "user",
+			Content: sampling.SamplingMessageContent{Type: "text", Text: memories.String()},
+		}}, SamplingOptions{SystemPrompt: reflectionPrompt, MaxTokens: reflectionMaxTokens})
+		if err != nil {
+			return nil, "", fmt.Errorf("memory_reflect needs an LLM: configure MCP_MEMORY_LLM_SUMMARIZATION_PROVIDER or call it from a client that supports sampling (%w)", err)
+		}
+		text, model = resp.Content.Text, resp.Model
+	}
+
+	reflection, err := parseReflection(text)
+	if err != nil {
+		return nil, "", err
+	}
+	return reflection, model, nil
+}
+
+// parseReflection reads the JSON reflection from an LLM's reply, which may
+// wrap it in prose or a code fence
+func parseReflection(text string) (*Reflection, error) {
+	start, end := strings.Index(text, "{"), strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil, errors.New("the LLM did not reply with a reflection")
+	}
+	var reflection Reflection
+	if err := json.Unmarshal([]byte(text[start:end+1]), &reflection); err != nil {
+		return nil, fmt.Errorf("the LLM replied with an invalid reflection: %w", err)
+	}
+	reflection.Summary = strings.TrimSpace(reflection.Summary)
+	if reflection.Summary == "" && len(reflection.Attempted)+len(reflection.Worked)+len(reflection.Failed)+len(reflection.Lessons) == 0 {
+		return nil, errors.New("the LLM replied with an empty reflection")
+	}
+	return &reflection, nil
+}
+
+// storeReflection stores a reflection as a high-priority semantic memory of
+// the session and links it to the memories it was drawn from
+func (ms *MemoryServer) storeReflection(ctx context.Context, sources []*types.ConversationChunk, reflection *Reflection, model string) (*types.ConversationChunk, error) {
+	first := sources[0]
+	priority := types.PriorityHigh
+	sourceIDs := make([]string, 0, len(sources))
+	files := make(map[string]bool)
+	for _, source := range sources {
+		sourceIDs = append(sourceIDs, source.ID)
+		for _, file := range source.Metadata.FilesModified {
+			files[file] = true
+		}
+	}
+	metadata := types.ChunkMetadata{
+		Repository:    first.Metadata.Repository,
+		Branch:        first.Metadata.Branch,
+		Outcome:       types.OutcomeSuccess,
+		Difficulty:    types.DifficultySimple,
+		Tags:          []string{reflectionTag},
+		FilesModified: sortedKeys(files),
+		TaskPriority:  &priority,
+		MemoryClass:   types.MemoryClassSemantic,
+		Provenance:    &types.Provenance{SourceSystem: types.SourceSystemMCP, CaptureTool: reflectionCaptureTool},
+		ExtendedMetadata: map[string]interface{}{
+			"reflection":     reflection,
+			"reflected_from": sourceIDs,
+			"model":          model,
+		},
+	}
+
+	content := renderReflection(reflection)
+	record, err := types.NewConversationChunk(first.SessionID, content, types.ChunkTypeSessionSummary, &metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reflection: %w", err)
+	}
+	record.Summary = "Reflection: " + reflection.Summary
+	record.Embeddings, err = ms.container.GetEmbeddingService().GenerateEmbedding(ctx, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate embeddings for reflection: %w", err)
+	}
+
+	store := ms.container.GetVectorStore()
+	if err := store.Store(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to store reflection: %w", err)
+	}
+	for _, source := range sources {
+		if _, err := store.StoreRelationship(ctx, record.ID, source.ID, types.RelationLearnedFrom, 1.0, types.ConfidenceDerived); err != nil {
+			logging.Warn("Failed to link reflection to its source", "chunk_id", record.ID, "source_id", source.ID, "error", err)
+		}
+	}
+
+	logging.Info("Stored session reflection", "chunk_id", record.ID, "session_id", first.SessionID, "sources", len(sources))
+	return record, nil
+}
+
+// renderReflection formats a reflection as the content of its memory
+func renderReflection(reflection *Reflection) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "SESSION REFLECTION: %s\n", reflection.Summary)
+	for _, section := range []struct {
+		title   string
+		entries []string
+	}{
+		{"What was attempted", reflection.Attempted},
+		{"What worked", reflection.Worked},
+		{"What failed", reflection.Failed},
+		{"Lessons", reflection.Lessons},
+	} {
+		if len(section.entries) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s\n", section.title)
+		for _, entry := range section.entries {
+			fmt.Fprintf(&b, "- %s\n", entry)
+		}
+	}
+	return b.String()
+}
+
+// isReflection reports whether a chunk is a session reflection
+func isReflection(chunk *types.ConversationChunk) bool {
+	return chunk.Metadata.Provenance != nil && chunk.Metadata.Provenance.CaptureTool == reflectionCaptureTool
+}
@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/llm"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedProvider answers every completion with the same text, keeping the last request
+type scriptedProvider struct {
+	text string
+	last llm.Request
+}
+
+func (p *scriptedProvider) Name() string  { return "scripted" }
+func (p *scriptedProvider) Model() string { return "scripted-1" }
+
+func (p *scriptedProvider) Complete(_ context.Context, req llm.Request) (*llm.Response, error) {
+	p.last = req
+	return &llm.Response{Text: p.text, Model: p.Model()}, nil
+}
+
+func TestReflect(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewKeywordStore("")
+	problem := newReportChunk(t, classTestSession, "Login fails after the token rotation", types.ChunkTypeProblem, types.ChunkMetadata{})
+	fix := newReportChunk(t, classTestSession, "Cached rotated keys for five minutes", types.ChunkTypeCodeChange, types.ChunkMetadata{FilesModified: []string{"internal/auth/keys.go"}})
+	for _, c := range []*types.ConversationChunk{problem, fix} {
+		require.NoError(t, store.Store(ctx, c))
+	}
+
+	ms := newCompositeTestServer(t, store)
+	router, err := llm.NewRouter(config.DefaultConfig())
+	require.NoError(t, err)
+	provider := &scriptedProvider{text: "```json\n" + `{"summary": "Fixed login after key rotation", "attempted": ["Fix login failures"], "worked": ["Caching rotated keys"], "failed": [], "lessons": ["Cache signing keys across rotations"]}` + "\n```"}
+	router.Set(llm.FeatureSummarization, provider)
+	ms.container.LLM = router
+
+	result, err := ms.handleReflect(ctx, map[string]interface{}{
+		"repository": "github.com/acme/api",
+		"session_id": "session-1",
+		"notes":      "Goal: unblock the release",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, provider.last.Prompt, "Goal: unblock the release")
+	assert.Contains(t, provider.last.Prompt, "Login fails after the token rotation")
+
+	response := result.(map[string]interface{})
+	assert.Equal(t, 2, response["sources"])
+	assert.Equal(t, "scripted-1", response["model"])
+	record, err := store.GetByID(ctx, response["chunk_id"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, types.MemoryClassSemantic, record.Class())
+	require.NotNil(t, record.Metadata.TaskPriority)
+	assert.Equal(t, types.PriorityHigh, *record.Metadata.TaskPriority)
+	assert.Equal(t, classTestSession, record.SessionID)
+	assert.Contains(t, record.Content, "## Lessons\n- Cache signing keys across rotations")
+	assert.NotContains(t, record.Content, "What failed", "empty sections are left out")
+	assert.Equal(t, []string{"internal/auth/keys.go"}, record.Metadata.FilesModified)
+	links, err := store.GetRelationships(ctx, &types.RelationshipQuery{ChunkID: record.ID, Direction: "outgoing"})
+	require.NoError(t, err)
+	assert.Len(t, links, 2)
+
+	// A second reflection does not read the first
+	result, err = ms.handleReflect(ctx, map[string]interface{}{"repository": "github.com/acme/api", "session_id": "session-1"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.(map[string]interface{})["sources"])
+
+	provider.text = "I could not find anything to reflect on."
+	_, err = ms.handleReflect(ctx, map[string]interface{}{"repository": "github.com/acme/api", "session_id": "session-1"})
+	assert.Error(t, err)
+
+	_, err = ms.handleReflect(ctx, map[string]interface{}{"repository": "github.com/acme/api", "session_id": "session-9"})
+	assert.ErrorContains(t, err, "no memories")
+}
+
+func TestReflectWithoutLLM(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewKeywordStore("")
+	require.NoError(t, store.Store(ctx, newReportChunk(t, classTestSession, "Tried bumping the pool size", types.ChunkTypeDiscussion, types.ChunkMetadata{})))
+
+	ms := newCompositeTestServer(t, store)
+	_, err := ms.handleReflect(ctx, map[string]interface{}{"repository": "github.com/acme/api", "session_id": "session-1"})
+	assert.ErrorIs(t, err, ErrSamplingUnavailable, "without a provider the client's model is asked")
+}
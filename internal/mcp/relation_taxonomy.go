@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"lerian-mcp-memory/internal/audit"
+	"lerian-mcp-memory/internal/relationships"
+	"lerian-mcp-memory/pkg/types"
+)
+
+// Relation taxonomy operations
+const (
+	OperationListRelationTypes  = "list_relation_types"
+	OperationDefineRelationType = "define_relation_type"
+)
+
+// builtinTaxonomy serves built-in relation types when no managed taxonomy is configured
+var builtinTaxonomy = relationships.NewTaxonomy("")
+
+// relationTaxonomy returns the configured taxonomy, falling back to built-in types only
+func (ms *MemoryServer) relationTaxonomy() *relationships.Taxonomy {
+	if taxonomy := ms.container.GetRelationTaxonomy(); taxonomy != nil {
+		return taxonomy
+	}
+	return builtinTaxonomy
+}
+
+// handleListRelationTypes lists the relation types valid for a repository
+func (ms *MemoryServer) handleListRelationTypes(ctx context.Context, repository string) (interface{}, error) {
+	if err := checkProjectTenant(ctx, repository); err != nil {
+		return nil, err
+	}
+	definitions := ms.relationTaxonomy().List(repository)
+
+	custom := 0
+	for i := range definitions {
+		if !definitions[i].BuiltIn {
+			custom++
+		}
+	}
+
+	return map[string]interface{}{
+		"status":         "success",
+		"repository":     repository,
+		"relation_types": definitions,
+		"built_in_count": len(definitions) - custom,
+		"custom_count":   custom,
+	}, nil
+}
+
+// handleDefineRelationType adds or replaces a custom relation type for a repository
+func (ms *MemoryServer) handleDefineRelationType(ctx context.Context, options map[string]interface{}) (interface{}, error) {
+	repository, _ := options["repository"].(string)
+	name, _ := options["name"].(string)
+	description, _ := options["description"].(string)
+	if repository == "" || name == "" || description == "" {
+		return nil, errors.New("define_relation_type requires repository, name and description. Example: {\"operation\": \"define_relation_type\", \"options\": {\"repository\": \"github.com/user/repo\", \"name\": \"blocks_release\", \"description\": \"Issue blocks a release\", \"directionality\": \"directed\"}}")
+	}
+	if err := checkProjectTenant(ctx, repository); err != nil {
+		return nil, err
+	}
+	directionality, _ := options["directionality"].(string)
+	inverse, _ := options["inverse"].(string)
+
+	definition, err := ms.relationTaxonomy().Define(repository, types.RelationTypeDefinition{
+		Name:           types.RelationType(name),
+		Description:    description,
+		Directionality: types.Directionality(directionality),
+		Inverse:        types.RelationType(inverse),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ms.container.AuditLogger.LogEvent(ctx, audit.EventTypeMemoryUpdate, "define_relation_type", "relation_type", name, map[string]interface{}{
+		"repository":     repository,
+		"directionality": string(definition.Directionality),
+	})
+
+	return map[string]interface{}{
+		"status":        "success",
+		"repository":    repository,
+		"relation_type": definition,
+		"defined_at":    definition.CreatedAt.Format(time.RFC3339),
+	}, nil
+}
@@ -0,0 +1,85 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/relationships"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/tenancy"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomRelationTypes(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	source := newTaskChunk(t, "github.com/acme/api", types.TaskStatusTodo)
+	target := newTaskChunk(t, "github.com/acme/api", types.TaskStatusTodo)
+	for _, c := range []*types.ConversationChunk{source, target} {
+		require.NoError(t, store.Store(ctx, c))
+	}
+
+	ms := newCompositeTestServer(t, store)
+	ms.container.RelationTaxonomy = relationships.NewTaxonomy("")
+
+	_, err := ms.handleMemoryCreate(ctx, map[string]interface{}{
+		"operation": OperationDefineRelationType,
+		"options": map[string]interface{}{
+			"repository":  "github.com/acme/api",
+			"name":        "blocks_release",
+			"description": "Issue blocks a release",
+		},
+	})
+	require.NoError(t, err)
+
+	listed, err := ms.handleMemoryRead(ctx, map[string]interface{}{
+		"operation": OperationListRelationTypes,
+		"options":   map[string]interface{}{"repository": "github.com/acme/api"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, listed.(map[string]interface{})["custom_count"])
+
+	link := func(repository string) error {
+		_, err := ms.handleMemoryCreate(ctx, map[string]interface{}{
+			"operation": "create_relationship",
+			"options": map[string]interface{}{
+				"repository":      repository,
+				"source_chunk_id": source.ID,
+				"target_chunk_id": target.ID,
+				"relation_type":   "blocks_release",
+			},
+		})
+		return err
+	}
+	assert.NoError(t, link("github.com/acme/api"))
+	assert.Error(t, link("github.com/acme/other"), "custom types are only valid in the defining repository")
+}
+
+func TestRelationTypes_HeldToTheTenant(t *testing.T) {
+	ms := newCompositeTestServer(t, storage.NewSimpleMockVectorStore())
+	ms.container.RelationTaxonomy = relationships.NewTaxonomy("")
+	rival := tenancy.WithTenant(context.Background(), &tenancy.Tenant{ID: "api_key:rival", Projects: []string{"github.com/rival/app"}})
+	define := func(repository string) error {
+		_, err := ms.handleDefineRelationType(rival, map[string]interface{}{
+			"repository":  repository,
+			"name":        "blocks_release",
+			"description": "Issue blocks a release",
+		})
+		return err
+	}
+
+	assert.ErrorIs(t, define("github.com/acme/api"), tenancy.ErrCrossTenant)
+	owned, err := ms.handleListRelationTypes(context.Background(), "github.com/acme/api")
+	require.NoError(t, err)
+	assert.Equal(t, 0, owned.(map[string]interface{})["custom_count"], "the other tenant's taxonomy is left alone")
+	_, err = ms.handleListRelationTypes(rival, "github.com/acme/api")
+	assert.ErrorIs(t, err, tenancy.ErrCrossTenant)
+
+	require.NoError(t, define("github.com/rival/app"))
+	listed, err := ms.handleListRelationTypes(rival, "github.com/rival/app")
+	require.NoError(t, err)
+	assert.Equal(t, 1, listed.(map[string]interface{})["custom_count"])
+}
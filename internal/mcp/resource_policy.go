@@ -0,0 +1,36 @@
+package mcp
+
+import (
+	"context"
+
+	"lerian-mcp-memory/internal/security"
+)
+
+type resourcePolicyKey struct{}
+
+// SetResourcePolicy holds tools to the files and databases policy grants.
+// A nil policy lifts the restriction.
+func (ms *MemoryServer) SetResourcePolicy(policy *security.ResourcePolicy) {
+	ms.resourcePolicy = policy
+}
+
+// WithResourcePolicy returns a context whose handlers check the resources
+// they access against policy
+func WithResourcePolicy(ctx context.Context, policy *security.ResourcePolicy) context.Context {
+	return context.WithValue(ctx, resourcePolicyKey{}, policy)
+}
+
+// ResourcePolicyFrom returns the resource policy handlers of the request
+// check resources against, or nil when access is not restricted
+func ResourcePolicyFrom(ctx context.Context) *security.ResourcePolicy {
+	policy, _ := ctx.Value(resourcePolicyKey{}).(*security.ResourcePolicy)
+	return policy
+}
+
+// withResourcePolicy attaches the server's resource policy to a request
+func (ms *MemoryServer) withResourcePolicy(ctx context.Context) context.Context {
+	if ms.resourcePolicy == nil {
+		return ctx
+	}
+	return WithResourcePolicy(ctx, ms.resourcePolicy)
+}
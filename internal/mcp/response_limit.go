@@ -0,0 +1,283 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"lerian-mcp-memory/internal/logging"
+
+	"github.com/fredcamaral/gomcp-sdk"
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/google/uuid"
+)
+
+// minElidedString is the shortest string cut down once lists alone cannot fit a result
+const minElidedString = 256
+
+// responseTruncation tells the client how a result was cut down to its limit
+type responseTruncation struct {
+	LimitBytes    int             `json:"limit_bytes"`
+	OriginalBytes int             `json:"original_bytes"`
+	Lists         []truncatedList `json:"lists,omitempty"`
+	ElidedStrings int             `json:"elided_strings,omitempty"`
+	Hint          string          `json:"hint"`
+}
+
+// truncatedList is a list of a result that lost its middle items. Path
+// locates it in the result, e.g. "results" or "chunks[2].tags", and Cursor
+// continues with its elided items.
+type truncatedList struct {
+	Path     string `json:"path"`
+	Total    int    `json:"total"`
+	KeptHead int    `json:"kept_head"`
+	KeptTail int    `json:"kept_tail"`
+	Elided   int    `json:"elided"`
+	Cursor   string `json:"cursor"`
+}
+
+// responseLimit returns the most bytes a result of tool may hold; 0 is unlimited
+func (ms *MemoryServer) responseLimit(tool string) int {
+	if ms.container == nil || ms.container.Config == nil {
+		return 0
+	}
+	if limit, ok := ms.container.Config.Server.ResponseLimits[tool]; ok {
+		return limit
+	}
+	return ms.container.Config.Server.MaxResponseBytes
+}
+
+// withResponseLimit cuts results of tool larger than its response limit down to size
+func (ms *MemoryServer) withResponseLimit(tool string, handler protocol.ToolHandler) protocol.ToolHandler {
+	return mcp.ToolHandlerFunc(func(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+		result, err := handler.Handle(ctx, params)
+		limit := ms.responseLimit(tool)
+		if err != nil || limit <= 0 {
+			return result, err
+		}
+		return ms.truncateResponse(ctx, tool, result, limit), nil
+	})
+}
+
+// truncateResponse returns result cut down to at most limit bytes of JSON.
+// It keeps the shape of the result and elides the middle of its largest
+// lists, biggest first, recording each in a "truncated" field. The elided
+// items go to the result cache, and the result's "_cursor" pages through
+// them with continue_result. Long strings lose their middle only when lists
+// alone cannot fit the limit. Text results lose their middle, with a cursor
+// for it. Typed content is sent as is.
+func (ms *MemoryServer) truncateResponse(ctx context.Context, tool string, result interface{}, limit int) interface{} {
+	switch value := result.(type) {
+	case nil, *ToolResult, *protocol.ToolCallResult:
+		return result
+	case string:
+		if len(value) <= limit {
+			return value
+		}
+		return ms.truncateText(ctx, tool, value, limit)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil || len(data) <= limit {
+		return result
+	}
+	var document interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		return result
+	}
+	root, ok := document.(map[string]interface{})
+	if !ok {
+		root = map[string]interface{}{"items": document}
+	}
+
+	truncation := &responseTruncation{
+		LimitBytes:    limit,
+		OriginalBytes: len(data),
+		Hint:          fmt.Sprintf("Results were cut down to %d bytes by eliding the middle of the lists below; call %s with %s to page through the elided items.", limit, continueResultTool, cursorField),
+	}
+	cached := &cachedResult{tool: tool, owner: resultOwner(ctx)}
+	id := uuid.New().String()
+	root["truncated"] = truncation
+	root[cursorField] = resultCursor(id, 0, 0)
+	size := func() int {
+		encoded, _ := json.Marshal(root)
+		return len(encoded)
+	}
+
+	done := make(map[string]bool)
+	current := size()
+	for current > limit {
+		list := largestList(root, done)
+		if list == nil {
+			break
+		}
+		done[list.path] = true
+		current = elideListMiddle(list, id, cached, truncation, size, limit)
+	}
+	for current > limit {
+		// The notice itself is never cut
+		delete(root, "truncated")
+		delete(root, cursorField)
+		elided := elideLongestString(root)
+		root["truncated"] = truncation
+		root[cursorField] = resultCursor(id, 0, 0)
+		if !elided {
+			break
+		}
+		truncation.ElidedStrings++
+		current = size()
+	}
+
+	if current > limit {
+		logging.Warn("Tool result exceeds its response limit after truncation", "tool", tool, "bytes", current, "limit", limit)
+	}
+	if len(cached.sections) == 0 {
+		// Only strings were cut; there is nothing to continue with
+		delete(root, cursorField)
+		truncation.Hint = fmt.Sprintf("Results were cut down to %d bytes by eliding the middle of long strings; fetch the items they belong to for their full text.", limit)
+	} else {
+		ms.results.put(id, cached, time.Now(), ms.resultCacheTTL())
+	}
+	return root
+}
+
+// elideListMiddle keeps as many items at the ends of list as the limit
+// allows, adds the rest to cached as a section of the result with id and
+// returns the new result size
+func elideListMiddle(list *resultList, id string, cached *cachedResult, truncation *responseTruncation, size func() int, limit int) int {
+	items := list.items
+	section := len(cached.sections)
+	cached.sections = append(cached.sections, resultSection{path: list.path})
+	truncation.Lists = append(truncation.Lists, truncatedList{Path: list.path, Total: len(items), Cursor: resultCursor(id, section, 0)})
+	notice := &truncation.Lists[len(truncation.Lists)-1]
+
+	keep := func(kept int) int {
+		head, tail := (kept+1)/2, kept/2
+		trimmed := make([]interface{}, 0, kept)
+		trimmed = append(trimmed, items[:head]...)
+		trimmed = append(trimmed, items[len(items)-tail:]...)
+		list.set(trimmed)
+		notice.KeptHead, notice.KeptTail, notice.Elided = head, tail, len(items)-kept
+		return size()
+	}
+
+	// Find the most items that still fit, from none to all but one
+	kept := sort.Search(len(items), func(kept int) bool { return keep(kept) > limit }) - 1
+	if kept < 0 {
+		kept = 0
+	}
+	current := keep(kept)
+	cached.sections[section].items = items[notice.KeptHead : len(items)-notice.KeptTail]
+	return current
+}
+
+// truncateText keeps the start and end of text within limit and caches the middle for continuation
+func (ms *MemoryServer) truncateText(ctx context.Context, tool, text string, limit int) string {
+	id := uuid.New().String()
+	cursor := resultCursor(id, 0, 0)
+
+	noticeFormat := "\n\n[... %d bytes elided; call " + continueResultTool + " with " + cursorField + " %q for them ...]\n\n"
+	budget := limit - len(fmt.Sprintf(noticeFormat, len(text), cursor)) - utf8.UTFMax
+	head := runeBoundary(text, budget/2)
+	tail := runeBoundary(text, len(text)-budget/2)
+	ms.results.put(id, &cachedResult{tool: tool, owner: resultOwner(ctx), sections: []resultSection{{text: text[head:tail]}}}, time.Now(), ms.resultCacheTTL())
+	return text[:head] + fmt.Sprintf(noticeFormat, tail-head, cursor) + text[tail:]
+}
+
+// runeBoundary returns the largest index up to i that starts a rune of text
+func runeBoundary(text string, i int) int {
+	if i >= len(text) {
+		return len(text)
+	}
+	if i < 0 {
+		return 0
+	}
+	for i > 0 && !utf8.RuneStart(text[i]) {
+		i--
+	}
+	return i
+}
+
+// resultList is a list found in a decoded result, with the way to replace it
+type resultList struct {
+	path  string
+	items []interface{}
+	size  int
+	set   func([]interface{})
+}
+
+// largestList returns the list of value with the most bytes of JSON, other
+// than those at the paths in skip, or nil when there is none
+func largestList(value interface{}, skip map[string]bool) *resultList {
+	var largest *resultList
+	var visit func(value interface{}, path string, set func([]interface{}))
+	visit = func(value interface{}, path string, set func([]interface{})) {
+		switch node := value.(type) {
+		case map[string]interface{}:
+			for key, child := range node {
+				if path == "" && key == "truncated" {
+					continue
+				}
+				childPath := key
+				if path != "" {
+					childPath = path + "." + key
+				}
+				visit(child, childPath, func(items []interface{}) { node[key] = items })
+			}
+		case []interface{}:
+			if set != nil && len(node) > 0 && !skip[path] {
+				encoded, _ := json.Marshal(node)
+				if largest == nil || len(encoded) > largest.size {
+					largest = &resultList{path: path, items: node, size: len(encoded), set: set}
+				}
+			}
+			for i, child := range node {
+				visit(child, fmt.Sprintf("%s[%d]", path, i), func(items []interface{}) { node[i] = items })
+			}
+		}
+	}
+	visit(value, "", nil)
+	return largest
+}
+
+// elideLongestString cuts the middle out of the longest string of value and
+// reports whether one was long enough to cut
+func elideLongestString(value interface{}) bool {
+	var longest string
+	var set func(string)
+	var visit func(value interface{})
+	visit = func(value interface{}) {
+		switch node := value.(type) {
+		case map[string]interface{}:
+			for key, child := range node {
+				if text, ok := child.(string); ok && len(text) > len(longest) {
+					longest, set = text, func(cut string) { node[key] = cut }
+				}
+				visit(child)
+			}
+		case []interface{}:
+			for i, child := range node {
+				if text, ok := child.(string); ok && len(text) > len(longest) {
+					longest, set = text, func(cut string) { node[i] = cut }
+				}
+				visit(child)
+			}
+		}
+	}
+	visit(value)
+	if len(longest) < minElidedString {
+		return false
+	}
+
+	quarter := len(longest) / 4
+	head := runeBoundary(longest, quarter)
+	tail := runeBoundary(longest, len(longest)-quarter)
+	set(fmt.Sprintf("%s [... %d bytes elided ...] %s", longest[:head], tail-head, longest[tail:]))
+	return true
+}
@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/di"
+
+	"github.com/fredcamaral/gomcp-sdk"
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newResponseLimitServer serves "list" returning result under a limit of limit bytes
+func newResponseLimitServer(limit int, result interface{}) *MemoryServer {
+	ms := newMiddlewareTestServer()
+	ms.container = &di.Container{Config: config.DefaultConfig()}
+	ms.container.Config.Server.MaxResponseBytes = limit
+	ms.addTool(mcp.NewTool("list", "List", mcp.ObjectSchema("List", map[string]interface{}{}, nil)),
+		mcp.ToolHandlerFunc(func(context.Context, map[string]interface{}) (interface{}, error) { return result, nil }))
+	ms.registerContinueResultTool()
+	return ms
+}
+
+// callToolText calls a tool and returns the text of its result
+func callToolText(t *testing.T, ms *MemoryServer, name string, args map[string]interface{}) string {
+	t.Helper()
+	resp := ms.HandleRequest(context.Background(), toolCallRequest(name, args))
+	require.Nil(t, resp.Error)
+	result, ok := resp.Result.(*protocol.ToolCallResult)
+	require.True(t, ok, "unexpected result %T", resp.Result)
+	require.Len(t, result.Content, 1)
+	require.False(t, result.IsError, result.Content[0].Text)
+	return result.Content[0].Text
+}
+
+func TestResponseLimit_ElidesListMiddle(t *testing.T) {
+	items := make([]map[string]interface{}, 500)
+	for i := range items {
+		items[i] = map[string]interface{}{"id": fmt.Sprintf("chunk-%03d", i), "content": strings.Repeat("x", 100)}
+	}
+	ms := newResponseLimitServer(8*1024, map[string]interface{}{"status": "success", "total": len(items), "results": items})
+
+	text := callToolText(t, ms, "list", nil)
+	assert.LessOrEqual(t, len(text), 8*1024)
+
+	var response struct {
+		Status    string                   `json:"status"`
+		Total     int                      `json:"total"`
+		Results   []map[string]interface{} `json:"results"`
+		Truncated responseTruncation       `json:"truncated"`
+		Cursor    string                   `json:"_cursor"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(text), &response))
+	assert.Equal(t, "success", response.Status, "fields outside the list are kept")
+	assert.Equal(t, 500, response.Total)
+	require.Len(t, response.Truncated.Lists, 1)
+	list := response.Truncated.Lists[0]
+	assert.Equal(t, "results", list.Path)
+	assert.Equal(t, 500, list.Total)
+	assert.Equal(t, len(response.Results), list.KeptHead+list.KeptTail)
+	assert.Equal(t, 500-len(response.Results), list.Elided)
+	assert.Greater(t, len(response.Results), 20, "as many items as fit are kept")
+	assert.Equal(t, "chunk-000", response.Results[0]["id"], "the start of the list is kept")
+	assert.Equal(t, "chunk-499", response.Results[len(response.Results)-1]["id"], "the end of the list is kept")
+	assert.Equal(t, list.Cursor, response.Cursor, "the result's cursor starts at its only cut list")
+
+	// Paging through the cursor returns exactly the elided items
+	var elided []string
+	for _, page := range continueResult(t, ms, response.Cursor) {
+		assert.Equal(t, "results", page.Path)
+		require.NotEmpty(t, page.Items)
+		for _, item := range page.Items {
+			elided = append(elided, item.(map[string]interface{})["id"].(string))
+		}
+	}
+	require.Len(t, elided, list.Elided)
+	assert.Equal(t, fmt.Sprintf("chunk-%03d", list.KeptHead), elided[0])
+	assert.Equal(t, fmt.Sprintf("chunk-%03d", 499-list.KeptTail), elided[len(elided)-1])
+}
+
+func TestResponseLimit_TextAndStrings(t *testing.T) {
+	text := strings.Repeat("é", 10000)
+	ms := newResponseLimitServer(4*1024, text)
+
+	truncated := callToolText(t, ms, "list", nil)
+	assert.LessOrEqual(t, len(truncated), 4*1024)
+	assert.Contains(t, truncated, "bytes elided; call continue_result")
+	start := strings.Index(truncated, "with _cursor \"") + len("with _cursor \"")
+	cursor := truncated[start : start+strings.Index(truncated[start:], "\"")]
+
+	var middle strings.Builder
+	for _, page := range continueResult(t, ms, cursor) {
+		middle.WriteString(page.Text)
+	}
+	head, tail, _ := strings.Cut(truncated, "\n\n[...")
+	_, tail, _ = strings.Cut(tail, "...]\n\n")
+	assert.Equal(t, text, head+middle.String()+tail, "the elided middle completes the text")
+
+	// A single oversized value loses the middle of its strings instead
+	ms = newResponseLimitServer(2*1024, map[string]interface{}{"chunk": map[string]interface{}{"content": strings.Repeat("y", 20000)}})
+	object := callToolText(t, ms, "list", nil)
+	assert.LessOrEqual(t, len(object), 2*1024)
+	assert.Contains(t, object, "bytes elided ...]")
+	assert.Contains(t, object, `"elided_strings":`)
+	assert.NotContains(t, object, cursorField, "cut strings leave nothing to continue with")
+}
+
+func TestResponseLimit_Configuration(t *testing.T) {
+	items := make([]string, 1000)
+	for i := range items {
+		items[i] = strings.Repeat("z", 50)
+	}
+	ms := newResponseLimitServer(2*1024, map[string]interface{}{"items": items})
+	assert.LessOrEqual(t, len(callToolText(t, ms, "list", nil)), 2*1024)
+
+	ms.container.Config.Server.ResponseLimits = map[string]int{"list": 0}
+	assert.NotContains(t, callToolText(t, ms, "list", nil), "truncated", "a per-tool limit of 0 sends results whole")
+
+	ms.container.Config.Server.ResponseLimits = nil
+	ms.container.Config.Server.MaxResponseBytes = 0
+	assert.Greater(t, len(callToolText(t, ms, "list", nil)), 50000)
+}
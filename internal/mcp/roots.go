@@ -0,0 +1,221 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/security"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/fredcamaral/gomcp-sdk/roots"
+)
+
+const (
+	// methodRootsList asks the client for the roots it shares with the server
+	methodRootsList = "roots/list"
+	// notificationRootsListChanged is sent by clients when their roots change
+	notificationRootsListChanged = "notifications/roots/list_changed"
+)
+
+// ErrPathOutsideRoots is returned for paths outside every root the client shares
+var ErrPathOutsideRoots = errors.New("path is outside the client's roots")
+
+// RootsResolver returns the roots of the client a request came from. ok is
+// false when the client does not share roots, and paths are not restricted.
+type RootsResolver func() (clientRoots []roots.Root, ok bool, err error)
+
+type rootsResolverKey struct{}
+
+// WithRootsResolver returns a context whose handlers see the client's roots
+// through ClientRoots
+func WithRootsResolver(ctx context.Context, resolver RootsResolver) context.Context {
+	return context.WithValue(ctx, rootsResolverKey{}, resolver)
+}
+
+// ClientRoots returns the roots the client of the request shares with the
+// server. ok is false when the client declared no roots capability, or the
+// request did not come from a client connection.
+func ClientRoots(ctx context.Context) (clientRoots []roots.Root, ok bool, err error) {
+	resolver, _ := ctx.Value(rootsResolverKey{}).(RootsResolver)
+	if resolver == nil {
+		return nil, false, nil
+	}
+	return resolver()
+}
+
+// ValidatePath resolves a file path against the client's roots. Relative
+// paths resolve against the first root, and the result must lie inside one
+// of the roots. Clients without roots leave paths unrestricted, except by
+// the resource policy: when one is configured, it must grant reading the
+// path.
+func ValidatePath(ctx context.Context, path string) (string, error) {
+	clientRoots, ok, err := ClientRoots(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the client's roots: %w", err)
+	}
+
+	var resolved string
+	if ok {
+		resolved, err = PathWithinRoots(path, clientRoots)
+	} else {
+		resolved, err = filepath.Abs(path)
+	}
+	if err != nil {
+		return "", err
+	}
+	if policy := ResourcePolicyFrom(ctx); policy != nil {
+		if err := policy.CheckPath(resolveSymlinks(resolved), security.AccessRead); err != nil {
+			return "", err
+		}
+	}
+	return resolved, nil
+}
+
+// PathWithinRoots resolves path, following symlinks, and checks it lies
+// inside one of the file roots. Relative paths resolve against the first file
+// root.
+func PathWithinRoots(path string, clientRoots []roots.Root) (string, error) {
+	dirs := make([]string, 0, len(clientRoots))
+	for _, root := range clientRoots {
+		if dir, ok := rootPath(root.URI); ok {
+			dirs = append(dirs, resolveSymlinks(dir))
+		}
+	}
+	if len(dirs) == 0 {
+		return "", fmt.Errorf("%w: the client shares no file roots", ErrPathOutsideRoots)
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dirs[0], path)
+	}
+	resolved := resolveSymlinks(filepath.Clean(path))
+	for _, dir := range dirs {
+		if rel, err := filepath.Rel(dir, resolved); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrPathOutsideRoots, path)
+}
+
+// rootPath returns the directory of a file:// root URI
+func rootPath(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" || u.Path == "" {
+		return "", false
+	}
+	return filepath.Clean(filepath.FromSlash(u.Path)), true
+}
+
+// resolveSymlinks follows symlinks in path as far as it exists, so a link
+// inside a root cannot point outside it
+func resolveSymlinks(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	// Resolve the existing parent of a path that does not exist yet
+	parent := filepath.Dir(path)
+	if parent == path {
+		return path
+	}
+	return filepath.Join(resolveSymlinks(parent), filepath.Base(path))
+}
+
+// rootsCache remembers which connections' clients share roots and the roots
+// they listed. The zero value is ready to use.
+type rootsCache struct {
+	mu        sync.Mutex
+	supported map[string]bool
+	listed    map[string][]roots.Root // by connection ID, until the client reports a change
+}
+
+// setSupported records whether the client of a connection declared roots
+func (rc *rootsCache) setSupported(connectionID string, supported bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.supported == nil {
+		rc.supported = make(map[string]bool)
+	}
+	if supported {
+		rc.supported[connectionID] = true
+	} else {
+		delete(rc.supported, connectionID)
+	}
+	delete(rc.listed, connectionID)
+}
+
+// get returns the cached roots of a connection and whether its client shares roots
+func (rc *rootsCache) get(connectionID string) (clientRoots []roots.Root, cached, supported bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	clientRoots, cached = rc.listed[connectionID]
+	return clientRoots, cached, rc.supported[connectionID]
+}
+
+// store caches the roots a connection's client listed
+func (rc *rootsCache) store(connectionID string, clientRoots []roots.Root) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if !rc.supported[connectionID] {
+		return
+	}
+	if rc.listed == nil {
+		rc.listed = make(map[string][]roots.Root)
+	}
+	rc.listed[connectionID] = clientRoots
+}
+
+// invalidate forgets the roots a connection's client listed
+func (rc *rootsCache) invalidate(connectionID string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	delete(rc.listed, connectionID)
+}
+
+// withClientRoots attaches a resolver for the roots of the request's client,
+// which asks the client with roots/list on first use and after it reports a
+// change
+func (ms *MemoryServer) withClientRoots(ctx context.Context) context.Context {
+	connectionID := connectionIDFrom(ctx)
+	if connectionID == "" {
+		return ctx
+	}
+	return WithRootsResolver(ctx, func() ([]roots.Root, bool, error) {
+		clientRoots, cached, supported := ms.clientRoots.get(connectionID)
+		if !supported || cached {
+			return clientRoots, supported, nil
+		}
+
+		sender := requestSenderFrom(ctx)
+		if sender == nil {
+			return nil, true, errors.New("the client's roots cannot be requested on this connection")
+		}
+		resp, err := ms.requestClient(ctx, sender, methodRootsList, roots.ListRootsRequest{}, 0)
+		if err != nil {
+			return nil, true, err
+		}
+		if resp.Error != nil {
+			return nil, true, fmt.Errorf("client rejected the roots request: %s", resp.Error.Message)
+		}
+		var listed roots.ListRootsResponse
+		if err := json.Unmarshal(resp.Result, &listed); err != nil {
+			return nil, true, fmt.Errorf("invalid roots result: %w", err)
+		}
+		ms.clientRoots.store(connectionID, listed.Roots)
+		logging.Info("Client roots listed", "connection_id", connectionID, "roots", len(listed.Roots))
+		return listed.Roots, true, nil
+	})
+}
+
+// handleRootsListChanged drops the cached roots of the notifying client, so
+// the next tool call lists them again. Notifications get no response.
+func (ms *MemoryServer) handleRootsListChanged(ctx context.Context, _ *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+	ms.clientRoots.invalidate(connectionIDFrom(ctx))
+	return nil
+}
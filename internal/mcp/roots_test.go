@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lerian-mcp-memory/internal/security"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/fredcamaral/gomcp-sdk/roots"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathWithinRoots(t *testing.T) {
+	base, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	project := filepath.Join(base, "project")
+	outside := filepath.Join(base, "outside")
+	require.NoError(t, os.MkdirAll(filepath.Join(project, "src"), 0o750))
+	require.NoError(t, os.MkdirAll(outside, 0o750))
+	require.NoError(t, os.Symlink(outside, filepath.Join(project, "escape")))
+
+	clientRoots := []roots.Root{
+		{URI: "https://example.com/docs", Name: "Docs"},
+		{URI: "file://" + filepath.ToSlash(project), Name: "Project"},
+	}
+
+	path, err := PathWithinRoots(filepath.Join(project, "src"), clientRoots)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(project, "src"), path)
+
+	path, err = PathWithinRoots("src/new_file.go", clientRoots)
+	require.NoError(t, err, "relative paths resolve against the first file root")
+	assert.Equal(t, filepath.Join(project, "src", "new_file.go"), path)
+
+	_, err = PathWithinRoots(outside, clientRoots)
+	assert.ErrorIs(t, err, ErrPathOutsideRoots)
+	_, err = PathWithinRoots(filepath.Join(project, "..", "outside"), clientRoots)
+	assert.ErrorIs(t, err, ErrPathOutsideRoots)
+	_, err = PathWithinRoots(filepath.Join(project, "escape"), clientRoots)
+	assert.ErrorIs(t, err, ErrPathOutsideRoots, "symlinks pointing outside the roots are rejected")
+	_, err = PathWithinRoots(project, clientRoots[:1])
+	assert.ErrorIs(t, err, ErrPathOutsideRoots, "only file roots grant access")
+}
+
+func TestValidatePathWithoutRoots(t *testing.T) {
+	path, err := ValidatePath(context.Background(), "relative/dir")
+	require.NoError(t, err)
+	assert.True(t, filepath.IsAbs(path), "paths are unrestricted for clients without roots")
+}
+
+func TestValidatePathWithResourcePolicy(t *testing.T) {
+	base, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	allowed := filepath.Join(base, "allowed")
+	require.NoError(t, os.MkdirAll(allowed, 0o750))
+	require.NoError(t, os.Symlink(base, filepath.Join(allowed, "escape")))
+
+	policy := &security.ResourcePolicy{Files: []security.FileRule{{Paths: []string{allowed}}}}
+	require.NoError(t, policy.Compile())
+	ms := newMiddlewareTestServer()
+	ms.SetResourcePolicy(policy)
+	ctx := ms.withResourcePolicy(context.Background())
+
+	path, err := ValidatePath(ctx, allowed)
+	require.NoError(t, err)
+	assert.Equal(t, allowed, path)
+	_, err = ValidatePath(ctx, base)
+	assert.ErrorIs(t, err, security.ErrResourceDenied)
+	_, err = ValidatePath(ctx, filepath.Join(allowed, "escape"))
+	assert.ErrorIs(t, err, security.ErrResourceDenied, "symlinks leading outside the policy are rejected")
+	assert.Equal(t, ToolErrorPermissionDenied, asToolError(err).Code)
+}
+
+func TestClientRoots(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	project := t.TempDir()
+
+	listed := 0
+	sender := func(req *protocol.JSONRPCRequest) error {
+		if req.Method != methodRootsList {
+			return nil
+		}
+		listed++
+		id, _ := json.Marshal(req.ID)
+		result, _ := json.Marshal(roots.ListRootsResponse{Roots: []roots.Root{{URI: "file://" + filepath.ToSlash(project), Name: "Project"}}})
+		go ms.HandleClientMessage("client-1", []byte(`{"jsonrpc":"2.0","id":`+string(id)+`,"result":`+string(result)+`}`))
+		return nil
+	}
+	ctx := WithRequestSender(WithConnectionID(context.Background(), "client-1"), sender)
+
+	_, ok, err := ClientRoots(ms.withClientRoots(ctx))
+	require.NoError(t, err)
+	assert.False(t, ok, "clients must declare roots on initialize")
+
+	initializeConnection(t, ms, "client-1", map[string]interface{}{"roots": map[string]interface{}{"listChanged": true}})
+	clientRoots, ok, err := ClientRoots(ms.withClientRoots(ctx))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Len(t, clientRoots, 1)
+	assert.Equal(t, "Project", clientRoots[0].Name)
+
+	_, err = ValidatePath(ms.withClientRoots(ctx), filepath.Dir(project))
+	assert.ErrorIs(t, err, ErrPathOutsideRoots)
+	assert.Equal(t, 1, listed, "roots are cached per connection")
+
+	// A change notification makes the next call list the roots again
+	resp := ms.HandleRequest(ctx, &protocol.JSONRPCRequest{JSONRPC: "2.0", Method: notificationRootsListChanged})
+	assert.Nil(t, resp, "notifications get no response")
+	_, _, err = ClientRoots(ms.withClientRoots(ctx))
+	require.NoError(t, err)
+	assert.Equal(t, 2, listed)
+
+	ms.CloseConnection("client-1")
+	_, ok, _ = ClientRoots(ms.withClientRoots(ctx))
+	assert.False(t, ok)
+}
+
+func TestStaleKnowledgeRespectsClientRoots(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	project := t.TempDir()
+	sender := func(req *protocol.JSONRPCRequest) error {
+		id, _ := json.Marshal(req.ID)
+		result, _ := json.Marshal(roots.ListRootsResponse{Roots: []roots.Root{{URI: "file://" + filepath.ToSlash(project)}}})
+		go ms.HandleClientMessage("client-1", []byte(`{"jsonrpc":"2.0","id":`+string(id)+`,"result":`+string(result)+`}`))
+		return nil
+	}
+	ctx := WithRequestSender(WithConnectionID(context.Background(), "client-1"), sender)
+	initializeConnection(t, ms, "client-1", map[string]interface{}{"roots": map[string]interface{}{}})
+
+	_, err := codebaseSnapshot(ms.withClientRoots(ctx), map[string]interface{}{"repo_path": filepath.Dir(project)})
+	assert.ErrorIs(t, err, ErrPathOutsideRoots)
+}
@@ -0,0 +1,254 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"lerian-mcp-memory/internal/logging"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/fredcamaral/gomcp-sdk/sampling"
+)
+
+const (
+	// methodSamplingCreateMessage asks the client for an LLM completion
+	methodSamplingCreateMessage = "sampling/createMessage"
+
+	defaultSamplingMaxTokens = 1024
+
+	// defaultClientRequestTimeout bounds the wait for a client's response
+	defaultClientRequestTimeout = 2 * time.Minute
+)
+
+var (
+	// ErrSamplingUnavailable is returned when the request's transport cannot
+	// carry server-to-client requests
+	ErrSamplingUnavailable = errors.New("sampling is not available on this connection")
+	// ErrSamplingNotSupported is returned when the client did not declare the
+	// sampling capability on initialize
+	ErrSamplingNotSupported = errors.New("the client does not support sampling")
+)
+
+// RequestSender sends a server-to-client JSON-RPC request on the connection
+// a request arrived on. The client's response comes back through
+// HandleClientMessage.
+type RequestSender func(req *protocol.JSONRPCRequest) error
+
+type requestSenderKey struct{}
+
+// WithRequestSender returns a context that lets handlers of requests served
+// with it send requests, such as sampling/createMessage, to the client
+func WithRequestSender(ctx context.Context, sender RequestSender) context.Context {
+	return context.WithValue(ctx, requestSenderKey{}, sender)
+}
+
+// requestSenderFrom returns the sender attached to ctx, or nil
+func requestSenderFrom(ctx context.Context) RequestSender {
+	sender, _ := ctx.Value(requestSenderKey{}).(RequestSender)
+	return sender
+}
+
+// SamplingOptions tunes a completion requested from the client
+type SamplingOptions struct {
+	SystemPrompt string
+	// MaxTokens defaults to 1024
+	MaxTokens        int
+	Temperature      *float64
+	StopSequences    []string
+	ModelPreferences *sampling.ModelPreferences
+	// IncludeContext is "none", "thisServer" or "allServers"
+	IncludeContext string
+	// Timeout bounds the wait for the client's answer and defaults to two minutes
+	Timeout time.Duration
+}
+
+// RequestSampling asks the client on the request's connection to run an LLM
+// completion with its own model, and waits for the answer. It fails with
+// ErrSamplingUnavailable or ErrSamplingNotSupported when the client cannot
+// be asked, so callers can fall back to working without a model.
+func (ms *MemoryServer) RequestSampling(ctx context.Context, messages []sampling.SamplingMessage, opts SamplingOptions) (*sampling.CreateMessageResponse, error) {
+	sender := requestSenderFrom(ctx)
+	if sender == nil {
+		return nil, ErrSamplingUnavailable
+	}
+	connectionID := connectionIDFrom(ctx)
+	if !ms.clientRequests.supportsSampling(connectionID) {
+		return nil, ErrSamplingNotSupported
+	}
+	if len(messages) == 0 {
+		return nil, errors.New("sampling requires at least one message")
+	}
+
+	params := sampling.CreateMessageRequest{
+		Messages:         messages,
+		ModelPreferences: opts.ModelPreferences,
+		IncludeContext:   opts.IncludeContext,
+		Temperature:      opts.Temperature,
+		MaxTokens:        opts.MaxTokens,
+		StopSequences:    opts.StopSequences,
+	}
+	if params.MaxTokens <= 0 {
+		params.MaxTokens = defaultSamplingMaxTokens
+	}
+	if opts.SystemPrompt != "" {
+		params.SystemPrompt = &opts.SystemPrompt
+	}
+
+	resp, err := ms.requestClient(ctx, sender, methodSamplingCreateMessage, params, opts.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, fmt.Errorf("client rejected the sampling request: %s", resp.Error.Message)
+	}
+	var result sampling.CreateMessageResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("invalid sampling result: %w", err)
+	}
+	return &result, nil
+}
+
+// requestClient sends a request to the client on the request's connection
+// and waits up to timeout, two minutes by default, for its response
+func (ms *MemoryServer) requestClient(ctx context.Context, sender RequestSender, method string, params interface{}, timeout time.Duration) (*clientResponse, error) {
+	if timeout <= 0 {
+		timeout = defaultClientRequestTimeout
+	}
+
+	id, responses := ms.clientRequests.open(connectionIDFrom(ctx))
+	defer ms.clientRequests.close(id)
+
+	if err := sender(&protocol.JSONRPCRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params}); err != nil {
+		return nil, fmt.Errorf("failed to send %s request: %w", method, err)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case resp := <-responses:
+		return resp, nil
+	case <-timer.C:
+		return nil, fmt.Errorf("client did not answer the %s request within %s", method, timeout)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// clientResponse is a client's answer to a server-to-client request
+type clientResponse struct {
+	JSONRPC string                 `json:"jsonrpc"`
+	ID      json.RawMessage        `json:"id"`
+	Method  string                 `json:"method"`
+	Result  json.RawMessage        `json:"result"`
+	Error   *protocol.JSONRPCError `json:"error"`
+}
+
+// HandleClientMessage delivers a client's response to a server-to-client
+// request sent on the same connection, reporting whether data was such a
+// response. Transports offer every incoming message here first and serve
+// the rest as requests.
+func (ms *MemoryServer) HandleClientMessage(connectionID string, data []byte) bool {
+	var resp clientResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return false
+	}
+	if resp.Method != "" || len(resp.ID) == 0 || (resp.Result == nil && resp.Error == nil) {
+		return false
+	}
+
+	var id string
+	if err := json.Unmarshal(resp.ID, &id); err != nil || !ms.clientRequests.deliver(connectionID, id, &resp) {
+		logging.Warn("Dropping response to unknown server request", "id", string(resp.ID), "connection_id", connectionID)
+	}
+	return true
+}
+
+// clientRequests tracks server-to-client requests awaiting a response, and
+// which connections' clients declared sampling. The zero value is ready to use.
+type clientRequests struct {
+	mu       sync.Mutex
+	nextID   uint64
+	pending  map[string]*pendingRequest
+	sampling map[string]bool // by connection ID
+}
+
+// pendingRequest is a request waiting for the client of a connection to answer
+type pendingRequest struct {
+	connectionID string
+	responses    chan *clientResponse
+}
+
+// open registers a new request to a connection and returns its ID and where
+// its response arrives
+func (cr *clientRequests) open(connectionID string) (string, <-chan *clientResponse) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	if cr.pending == nil {
+		cr.pending = make(map[string]*pendingRequest)
+	}
+	cr.nextID++
+	id := fmt.Sprintf("server-%d", cr.nextID)
+	pending := &pendingRequest{connectionID: connectionID, responses: make(chan *clientResponse, 1)}
+	cr.pending[id] = pending
+	return id, pending.responses
+}
+
+// close forgets a request that was answered or given up on
+func (cr *clientRequests) close(id string) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	delete(cr.pending, id)
+}
+
+// deliver hands a response to the request waiting for it on the connection,
+// reporting whether one was
+func (cr *clientRequests) deliver(connectionID, id string, resp *clientResponse) bool {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	pending, ok := cr.pending[id]
+	if !ok || pending.connectionID != connectionID {
+		return false
+	}
+	delete(cr.pending, id)
+	pending.responses <- resp
+	return true
+}
+
+// setSampling records whether the client of a connection declared sampling
+func (cr *clientRequests) setSampling(connectionID string, supported bool) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	if cr.sampling == nil {
+		cr.sampling = make(map[string]bool)
+	}
+	if supported {
+		cr.sampling[connectionID] = true
+	} else {
+		delete(cr.sampling, connectionID)
+	}
+}
+
+// supportsSampling reports whether the client of a connection declared sampling
+func (cr *clientRequests) supportsSampling(connectionID string) bool {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	return cr.sampling[connectionID]
+}
+
+// recordClientCapabilities remembers whether the client initializing a
+// connection can be asked for completions and shares roots
+func (ms *MemoryServer) recordClientCapabilities(connectionID string, req *protocol.JSONRPCRequest) {
+	if connectionID == "" {
+		return
+	}
+	params, _ := req.Params.(map[string]interface{})
+	capabilities, _ := params["capabilities"].(map[string]interface{})
+	_, supportsSampling := capabilities["sampling"]
+	ms.clientRequests.setSampling(connectionID, supportsSampling)
+	_, sharesRoots := capabilities["roots"]
+	ms.clientRoots.setSupported(connectionID, sharesRoots)
+}
@@ -0,0 +1,91 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/fredcamaral/gomcp-sdk/sampling"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// initializeConnection initializes a connection as a client declaring capabilities
+func initializeConnection(t *testing.T, ms *MemoryServer, connectionID string, capabilities map[string]interface{}) {
+	t.Helper()
+	resp := ms.HandleRequest(WithConnectionID(context.Background(), connectionID), &protocol.JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "initialize",
+		Params: map[string]interface{}{
+			"protocolVersion": protocol.Version,
+			"capabilities":    capabilities,
+			"clientInfo":      map[string]interface{}{"name": "test", "version": "1.0.0"},
+		},
+	})
+	require.Nil(t, resp.Error)
+}
+
+func TestRequestSampling(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	messages := []sampling.SamplingMessage{{Role: "user", Content: sampling.SamplingMessageContent{Type: "text", Text: "Summarize"}}}
+
+	_, err := ms.RequestSampling(context.Background(), messages, SamplingOptions{})
+	assert.ErrorIs(t, err, ErrSamplingUnavailable)
+
+	// The client answers on its own connection, as a transport would deliver it
+	var sent *protocol.JSONRPCRequest
+	answer := func(connectionID, result string) RequestSender {
+		return func(req *protocol.JSONRPCRequest) error {
+			sent = req
+			id, _ := json.Marshal(req.ID)
+			go ms.HandleClientMessage(connectionID, []byte(`{"jsonrpc":"2.0","id":`+string(id)+`,"result":`+result+`}`))
+			return nil
+		}
+	}
+	ctx := WithConnectionID(context.Background(), "client-1")
+
+	_, err = ms.RequestSampling(WithRequestSender(ctx, answer("client-1", "{}")), messages, SamplingOptions{})
+	assert.ErrorIs(t, err, ErrSamplingNotSupported, "the client must declare sampling on initialize")
+
+	initializeConnection(t, ms, "client-1", map[string]interface{}{"sampling": map[string]interface{}{}})
+	result, err := ms.RequestSampling(WithRequestSender(ctx, answer("client-1", `{"role":"assistant","content":{"type":"text","text":"All quiet"},"model":"client-model"}`)), messages, SamplingOptions{SystemPrompt: "Be brief"})
+	require.NoError(t, err)
+	assert.Equal(t, "All quiet", result.Content.Text)
+	assert.Equal(t, "client-model", result.Model)
+	assert.Equal(t, methodSamplingCreateMessage, sent.Method)
+	params := sent.Params.(sampling.CreateMessageRequest)
+	assert.Equal(t, defaultSamplingMaxTokens, params.MaxTokens)
+	assert.Equal(t, "Be brief", *params.SystemPrompt)
+
+	// Responses from another connection do not answer the request
+	_, err = ms.RequestSampling(WithRequestSender(ctx, answer("client-2", "{}")), messages, SamplingOptions{Timeout: 50 * time.Millisecond})
+	assert.ErrorContains(t, err, "did not answer")
+
+	reject := func(req *protocol.JSONRPCRequest) error {
+		id, _ := json.Marshal(req.ID)
+		go ms.HandleClientMessage("client-1", []byte(`{"jsonrpc":"2.0","id":`+string(id)+`,"error":{"code":-1,"message":"User rejected sampling request"}}`))
+		return nil
+	}
+	_, err = ms.RequestSampling(WithRequestSender(ctx, reject), messages, SamplingOptions{})
+	assert.ErrorContains(t, err, "User rejected")
+
+	failing := func(*protocol.JSONRPCRequest) error { return errors.New("connection closed") }
+	_, err = ms.RequestSampling(WithRequestSender(ctx, failing), messages, SamplingOptions{})
+	assert.ErrorContains(t, err, "connection closed")
+
+	ms.CloseConnection("client-1")
+	_, err = ms.RequestSampling(WithRequestSender(ctx, answer("client-1", "{}")), messages, SamplingOptions{})
+	assert.ErrorIs(t, err, ErrSamplingNotSupported)
+}
+
+func TestHandleClientMessageIgnoresRequests(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	assert.False(t, ms.HandleClientMessage("client-1", []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)))
+	assert.False(t, ms.HandleClientMessage("client-1", []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)))
+	assert.False(t, ms.HandleClientMessage("client-1", []byte(`not json`)))
+	assert.True(t, ms.HandleClientMessage("client-1", []byte(`{"jsonrpc":"2.0","id":"server-99","result":{}}`)), "stray responses are consumed")
+}
@@ -0,0 +1,467 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"lerian-mcp-memory/internal/audit"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/scoring"
+	"lerian-mcp-memory/internal/tenancy"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/fredcamaral/gomcp-sdk"
+)
+
+// relevanceRanking names plain relevance ranking in evaluation reports
+const relevanceRanking = "relevance"
+
+// registerScoringProfilesTool registers system_scoring_profiles
+func (ms *MemoryServer) registerScoringProfilesTool() {
+	ms.addTool(mcp.NewTool(
+		"system_scoring_profiles",
+		"Manage per-repository scoring profiles that re-rank memory_read search results: weights for recency, priors per chunk type, tag boosts and a penalty for archived content. The active profile of a repository is applied to its searches. Operations: list, get, upsert (create or replace; the first profile becomes active), activate, delete, evaluate (A/B report replaying recent searches from the query log under two profiles).",
+		mcp.ObjectSchema("Scoring profile parameters", map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"list", "get", "upsert", "activate", "delete", "evaluate"},
+				"description": "Scoring profile operation",
+			},
+			"repository": map[string]interface{}{
+				"type":        "string",
+				"description": "Repository the profiles belong to",
+			},
+			"name": map[string]interface{}{
+				"type":        "string",
+				"description": "Profile name (get, activate, delete)",
+			},
+			"profile": map[string]interface{}{
+				"type":        "object",
+				"description": "Profile to create or replace (upsert). Example: {\"name\": \"fresh-first\", \"recency_weight\": 0.3, \"recency_half_life_days\": 14, \"type_priors\": {\"solution\": 1.2}, \"tag_boosts\": {\"security\": 0.1}, \"archived_penalty\": 0.5}",
+			},
+			"activate": map[string]interface{}{
+				"type":        "boolean",
+				"default":     false,
+				"description": "Make the profile active after saving it (upsert)",
+			},
+			"profile_a": map[string]interface{}{
+				"type":        "string",
+				"description": "First profile to compare; defaults to the active profile (evaluate)",
+			},
+			"profile_b": map[string]interface{}{
+				"type":        "string",
+				"description": "Second profile to compare; omit for plain relevance ranking (evaluate)",
+			},
+			"queries": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Queries to evaluate instead of the query log (evaluate)",
+			},
+			"days": map[string]interface{}{
+				"type":        "number",
+				"default":     7,
+				"description": "How far back to read the query log (evaluate)",
+			},
+			"max_queries": map[string]interface{}{
+				"type":        "number",
+				"default":     20,
+				"description": "Most distinct queries to replay (evaluate)",
+			},
+			"k": map[string]interface{}{
+				"type":        "number",
+				"default":     5,
+				"description": "Number of top results compared per query (evaluate)",
+			},
+		}, []string{"operation", "repository"}),
+	), mcp.ToolHandlerFunc(ms.handleScoringProfiles))
+}
+
+// handleScoringProfiles manages and evaluates scoring profiles
+func (ms *MemoryServer) handleScoringProfiles(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: system_scoring_profiles called", "args", args)
+
+	profiles := ms.container.GetScoringProfiles()
+	if profiles == nil {
+		return nil, errors.New("scoring profiles are not available")
+	}
+
+	operation, _ := args["operation"].(string)
+	repository, _ := args["repository"].(string)
+	if repository == "" {
+		return nil, errors.New("repository is required. Example: {\"operation\": \"list\", \"repository\": \"github.com/user/repo\"}")
+	}
+	if err := checkProjectTenant(ctx, repository); err != nil {
+		return nil, err
+	}
+	name, _ := args["name"].(string)
+
+	switch operation {
+	case "list":
+		list := profiles.List(repository)
+		active := ""
+		for i := range list {
+			if list[i].Active {
+				active = list[i].Name
+			}
+		}
+		return map[string]interface{}{
+			"status":     "success",
+			"operation":  operation,
+			"repository": repository,
+			"profiles":   list,
+			"active":     active,
+			"count":      len(list),
+		}, nil
+
+	case "get":
+		profile, ok := profiles.Get(repository, name)
+		if !ok {
+			return nil, fmt.Errorf("scoring profile %q not found for %s", name, repository)
+		}
+		return map[string]interface{}{
+			"status":    "success",
+			"operation": operation,
+			"profile":   profile,
+		}, nil
+
+	case "upsert":
+		options, ok := args["profile"].(map[string]interface{})
+		if !ok {
+			return nil, errors.New("profile is required for upsert. Example: {\"operation\": \"upsert\", \"repository\": \"github.com/user/repo\", \"profile\": {\"name\": \"fresh-first\", \"recency_weight\": 0.3}}")
+		}
+		// Round-trip the options through JSON to reuse the profile's field names and types
+		raw, err := json.Marshal(options)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scoring profile: %w", err)
+		}
+		var profile scoring.Profile
+		if err := json.Unmarshal(raw, &profile); err != nil {
+			return nil, fmt.Errorf("invalid scoring profile: %w", err)
+		}
+		activate, _ := args["activate"].(bool)
+
+		saved, err := profiles.Upsert(repository, profile, activate)
+		if err != nil {
+			return nil, err
+		}
+		ms.logScoringProfileChange(ctx, operation, saved)
+		return map[string]interface{}{
+			"status":    "success",
+			"operation": operation,
+			"profile":   saved,
+		}, nil
+
+	case "activate":
+		activated, err := profiles.Activate(repository, name)
+		if err != nil {
+			return nil, err
+		}
+		ms.logScoringProfileChange(ctx, operation, activated)
+		return map[string]interface{}{
+			"status":    "success",
+			"operation": operation,
+			"profile":   activated,
+		}, nil
+
+	case "delete":
+		profile, ok := profiles.Get(repository, name)
+		if !ok {
+			return nil, fmt.Errorf("scoring profile %q not found for %s", name, repository)
+		}
+		if err := profiles.Delete(repository, name); err != nil {
+			return nil, err
+		}
+		ms.logScoringProfileChange(ctx, operation, &profile)
+		return map[string]interface{}{
+			"status":     "success",
+			"operation":  operation,
+			"repository": repository,
+			"name":       name,
+			"was_active": profile.Active,
+		}, nil
+
+	case "evaluate":
+		return ms.evaluateScoringProfiles(ctx, repository, args)
+
+	default:
+		return nil, fmt.Errorf("unknown operation %q: use list, get, upsert, activate, delete or evaluate", operation)
+	}
+}
+
+// logScoringProfileChange records a profile change in the audit log
+func (ms *MemoryServer) logScoringProfileChange(ctx context.Context, operation string, profile *scoring.Profile) {
+	if auditLogger := ms.container.GetAuditLogger(); auditLogger != nil {
+		auditLogger.LogEvent(ctx, audit.EventTypeMemoryUpdate, operation+"_scoring_profile", "scoring_profile", profile.Name, map[string]interface{}{
+			"repository": profile.Repository,
+			"active":     profile.Active,
+		})
+	}
+}
+
+// applyScoringProfile re-ranks results with the active profile of the
+// query's repository, returning the profile's name or "" when none applies
+func (ms *MemoryServer) applyScoringProfile(query *types.MemoryQuery, results *types.SearchResults) string {
+	profiles := ms.container.GetScoringProfiles()
+	if profiles == nil || query.Repository == nil || len(results.Results) == 0 {
+		return ""
+	}
+	profile, ok := profiles.ActiveProfile(*query.Repository)
+	if !ok {
+		return ""
+	}
+	profile.Rank(results.Results, time.Now())
+	return profile.Name
+}
+
+// ScoringEvaluation compares two rankings over replayed queries
+type ScoringEvaluation struct {
+	Repository string `json:"repository"`
+	ProfileA   string `json:"profile_a"`
+	ProfileB   string `json:"profile_b"`
+	K          int    `json:"k"`
+	// Source is "query_log" or "queries"
+	Source  string `json:"source"`
+	Queries int    `json:"queries"`
+	// Top1Agreement is the fraction of queries whose top result is the same
+	Top1Agreement float64 `json:"top1_agreement"`
+	// MeanOverlap is the mean fraction of top-k results both rankings share
+	MeanOverlap float64           `json:"mean_overlap"`
+	A           RankingSummary    `json:"a"`
+	B           RankingSummary    `json:"b"`
+	PerQuery    []QueryEvaluation `json:"per_query"`
+}
+
+// RankingSummary describes the top-k results one ranking returned, averaged over queries
+type RankingSummary struct {
+	// MeanRelevance is the mean unadjusted relevance score of the top k
+	MeanRelevance float64 `json:"mean_relevance"`
+	MeanAgeDays   float64 `json:"mean_age_days"`
+	// ArchivedResults counts archived chunks placed in the top k
+	ArchivedResults int `json:"archived_results"`
+}
+
+// QueryEvaluation compares the rankings of one query
+type QueryEvaluation struct {
+	Query   string   `json:"query"`
+	Results int      `json:"results"`
+	TopA    []string `json:"top_a"`
+	TopB    []string `json:"top_b"`
+	Overlap float64  `json:"overlap"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// evaluateScoringProfiles replays queries through memory_read search under
+// two profiles and compares their top results
+func (ms *MemoryServer) evaluateScoringProfiles(ctx context.Context, repository string, args map[string]interface{}) (interface{}, error) {
+	nameA, _ := args["profile_a"].(string)
+	nameB, _ := args["profile_b"].(string)
+	if nameA == "" {
+		active, ok := ms.container.GetScoringProfiles().ActiveProfile(repository)
+		if !ok {
+			return nil, fmt.Errorf("profile_a is required: %s has no active scoring profile", repository)
+		}
+		nameA = active.Name
+	}
+	profileA, err := ms.evaluationProfile(repository, nameA)
+	if err != nil {
+		return nil, err
+	}
+	profileB, err := ms.evaluationProfile(repository, nameB)
+	if err != nil {
+		return nil, err
+	}
+
+	k := 5
+	if value, ok := args["k"].(float64); ok && value >= 1 {
+		k = int(value)
+	}
+	maxQueries := 20
+	if value, ok := args["max_queries"].(float64); ok && value >= 1 {
+		maxQueries = int(value)
+	}
+
+	evaluation := &ScoringEvaluation{Repository: repository, ProfileA: profileA.Name, ProfileB: profileB.Name, K: k, Source: "queries"}
+	var queries []string
+	if raw, ok := args["queries"].([]interface{}); ok {
+		for _, value := range raw {
+			if query, ok := value.(string); ok && query != "" {
+				queries = append(queries, query)
+			}
+		}
+	} else {
+		days := 7
+		if value, ok := args["days"].(float64); ok && value >= 1 {
+			days = int(value)
+		}
+		evaluation.Source = "query_log"
+		if queries, err = ms.loggedQueries(ctx, repository, days); err != nil {
+			return nil, err
+		}
+	}
+	if len(queries) > maxQueries {
+		queries = queries[:maxQueries]
+	}
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("no queries to evaluate for %s: search the repository first or pass queries", repository)
+	}
+
+	now := time.Now()
+	for _, query := range queries {
+		queryEvaluation := QueryEvaluation{Query: query}
+		memQuery, err := ms.secureSearchQuery(map[string]interface{}{"query": query}, repository)
+		var results *types.SearchResults
+		if err == nil {
+			results, _, err = ms.secureSearch(ctx, memQuery)
+		}
+		if err != nil {
+			queryEvaluation.Error = err.Error()
+			evaluation.PerQuery = append(evaluation.PerQuery, queryEvaluation)
+			continue
+		}
+
+		queryEvaluation.Results = len(results.Results)
+		topA := rankForEvaluation(profileA, results.Results, k, now, &evaluation.A)
+		topB := rankForEvaluation(profileB, results.Results, k, now, &evaluation.B)
+		for i := range topA {
+			queryEvaluation.TopA = append(queryEvaluation.TopA, topA[i].Chunk.ID)
+		}
+		for i := range topB {
+			queryEvaluation.TopB = append(queryEvaluation.TopB, topB[i].Chunk.ID)
+		}
+		queryEvaluation.Overlap = topOverlap(queryEvaluation.TopA, queryEvaluation.TopB)
+
+		evaluation.Queries++
+		evaluation.MeanOverlap += queryEvaluation.Overlap
+		if len(topA) == 0 || topA[0].Chunk.ID == topB[0].Chunk.ID {
+			evaluation.Top1Agreement++
+		}
+		evaluation.PerQuery = append(evaluation.PerQuery, queryEvaluation)
+	}
+
+	if evaluation.Queries > 0 {
+		n := float64(evaluation.Queries)
+		evaluation.Top1Agreement /= n
+		evaluation.MeanOverlap /= n
+		evaluation.A.MeanRelevance /= n
+		evaluation.A.MeanAgeDays /= n
+		evaluation.B.MeanRelevance /= n
+		evaluation.B.MeanAgeDays /= n
+	}
+
+	logging.Info("Scoring profiles evaluated", "repository", repository, "profile_a", profileA.Name, "profile_b", profileB.Name, "queries", evaluation.Queries, "mean_overlap", evaluation.MeanOverlap)
+	return map[string]interface{}{
+		"status":     "success",
+		"operation":  "evaluate",
+		"evaluation": evaluation,
+	}, nil
+}
+
+// evaluationProfile looks up a profile to evaluate; an empty name is plain relevance ranking
+func (ms *MemoryServer) evaluationProfile(repository, name string) (scoring.Profile, error) {
+	if name == "" || name == relevanceRanking {
+		return scoring.Profile{Name: relevanceRanking}, nil
+	}
+	profile, ok := ms.container.GetScoringProfiles().Get(repository, name)
+	if !ok {
+		return scoring.Profile{}, fmt.Errorf("scoring profile %q not found for %s", name, repository)
+	}
+	return profile, nil
+}
+
+// loggedQueries returns the distinct queries searched in a repository, most
+// recent first. Queries of repositories the request's tenant does not own
+// are never returned.
+func (ms *MemoryServer) loggedQueries(ctx context.Context, repository string, days int) ([]string, error) {
+	auditLogger := ms.container.GetAuditLogger()
+	if auditLogger == nil {
+		return nil, errors.New("the query log is not available: pass queries to evaluate")
+	}
+	succeeded := true
+	events, err := auditLogger.Search(ctx, &audit.SearchCriteria{
+		StartTime:  time.Now().AddDate(0, 0, -days),
+		EventTypes: []audit.EventType{audit.EventTypeMemorySearch},
+		Success:    &succeeded,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the query log: %w", err)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Timestamp.After(events[j].Timestamp) })
+	tenant := tenancy.FromContext(ctx)
+	seen := make(map[string]bool)
+	queries := make([]string, 0)
+	for i := range events {
+		query, _ := events[i].Details["query"].(string)
+		logged := loggedRepository(events[i].Details["repository"])
+		if query == "" || seen[query] || logged != repository || (tenant != nil && !tenant.Allows(logged)) {
+			continue
+		}
+		seen[query] = true
+		queries = append(queries, query)
+	}
+	return queries, nil
+}
+
+// loggedRepository reads the repository of a search audit event, logged as a string pointer
+func loggedRepository(value interface{}) string {
+	switch repository := value.(type) {
+	case string:
+		return repository
+	case *string:
+		if repository != nil {
+			return *repository
+		}
+	}
+	return ""
+}
+
+// rankForEvaluation ranks a copy of results with profile and adds its top k to summary
+func rankForEvaluation(profile scoring.Profile, results []types.SearchResult, k int, now time.Time, summary *RankingSummary) []types.SearchResult {
+	ranked := make([]types.SearchResult, len(results))
+	copy(ranked, results)
+	relevance := make(map[string]float64, len(ranked))
+	for i := range ranked {
+		relevance[ranked[i].Chunk.ID] = ranked[i].Score
+	}
+	profile.Rank(ranked, now)
+	if len(ranked) > k {
+		ranked = ranked[:k]
+	}
+	if len(ranked) == 0 {
+		return ranked
+	}
+
+	var relevanceSum, ageSum float64
+	for i := range ranked {
+		relevanceSum += relevance[ranked[i].Chunk.ID]
+		ageSum += now.Sub(ranked[i].Chunk.Timestamp).Hours() / 24
+		if scoring.IsArchived(&ranked[i].Chunk) {
+			summary.ArchivedResults++
+		}
+	}
+	summary.MeanRelevance += relevanceSum / float64(len(ranked))
+	summary.MeanAgeDays += ageSum / float64(len(ranked))
+	return ranked
+}
+
+// topOverlap returns the fraction of the top results two rankings share
+func topOverlap(a, b []string) float64 {
+	if len(a) == 0 {
+		return 1
+	}
+	inA := make(map[string]bool, len(a))
+	for _, id := range a {
+		inA[id] = true
+	}
+	shared := 0
+	for _, id := range b {
+		if inA[id] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a))
+}
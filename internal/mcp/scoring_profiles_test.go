@@ -0,0 +1,170 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/relationships"
+	"lerian-mcp-memory/internal/scoring"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/tenancy"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedResultsStore answers every search with the same results
+type fixedResultsStore struct {
+	storage.VectorStore
+	results []types.SearchResult
+}
+
+func (s *fixedResultsStore) Search(context.Context, *types.MemoryQuery, []float64) (*types.SearchResults, error) {
+	results := make([]types.SearchResult, len(s.results))
+	copy(results, s.results)
+	return &types.SearchResults{Results: results, Total: len(results)}, nil
+}
+
+func newScoringTestServer(t *testing.T) *MemoryServer {
+	t.Helper()
+	now := time.Now()
+	store := &fixedResultsStore{VectorStore: storage.NewSimpleMockVectorStore(), results: []types.SearchResult{
+		{Score: 0.9, Chunk: types.ConversationChunk{ID: "stale", Type: types.ChunkTypeSolution, Timestamp: now.AddDate(-1, 0, 0)}},
+		{Score: 0.7, Chunk: types.ConversationChunk{ID: "fresh", Type: types.ChunkTypeSolution, Timestamp: now}},
+	}}
+	ms := newCompositeTestServer(t, store)
+	ms.container.Config = config.DefaultConfig()
+	ms.container.ScoringProfiles = scoring.NewStore("")
+	ms.container.RelationshipManager = relationships.NewManager()
+	return ms
+}
+
+func TestScoringProfiles_AppliedToSearch(t *testing.T) {
+	ctx := context.Background()
+	ms := newScoringTestServer(t)
+	repo := "github.com/acme/api"
+
+	_, err := ms.handleScoringProfiles(ctx, map[string]interface{}{
+		"operation":  "upsert",
+		"repository": repo,
+		"profile":    map[string]interface{}{"name": "fresh-first", "recency_weight": 0.5, "recency_half_life_days": 7.0},
+	})
+	require.NoError(t, err)
+
+	result, err := ms.handleMemoryRead(ctx, searchArgs("deploy", repo))
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	assert.Equal(t, "fresh-first", response["scoring_profile"])
+	assert.Equal(t, "fresh", response["results"].([]types.SearchResult)[0].Chunk.ID)
+
+	// Other repositories keep relevance ranking
+	result, err = ms.handleMemoryRead(ctx, searchArgs("deploy", "github.com/acme/web"))
+	require.NoError(t, err)
+	response = result.(map[string]interface{})
+	assert.NotContains(t, response, "scoring_profile")
+	assert.Equal(t, "stale", response["results"].([]types.SearchResult)[0].Chunk.ID)
+}
+
+// searchArgs builds the memory_read arguments of a search
+func searchArgs(query, repository string) map[string]interface{} {
+	return map[string]interface{}{
+		"operation": "search",
+		"options":   map[string]interface{}{"query": query, "repository": repository},
+	}
+}
+
+func TestScoringProfiles_EvaluateTheQueryLog(t *testing.T) {
+	ctx := context.Background()
+	ms := newScoringTestServer(t)
+	repo := "github.com/acme/api"
+
+	_, err := ms.handleScoringProfiles(ctx, map[string]interface{}{
+		"operation":  "upsert",
+		"repository": repo,
+		"profile":    map[string]interface{}{"name": "fresh-first", "recency_weight": 0.5},
+	})
+	require.NoError(t, err)
+	for _, query := range []string{"deploy", "rollback", "deploy"} {
+		_, err = ms.handleMemoryRead(ctx, searchArgs(query, repo))
+		require.NoError(t, err)
+	}
+	_, err = ms.handleMemoryRead(ctx, searchArgs("billing", "github.com/acme/web"))
+	require.NoError(t, err)
+
+	result, err := ms.handleScoringProfiles(ctx, map[string]interface{}{
+		"operation":  "evaluate",
+		"repository": repo,
+	})
+	require.NoError(t, err)
+	evaluation := result.(map[string]interface{})["evaluation"].(*ScoringEvaluation)
+	assert.Equal(t, "query_log", evaluation.Source)
+	assert.Equal(t, 2, evaluation.Queries, "memory_read searches of the repository fill the query log once per query")
+	assert.Equal(t, []string{"fresh"}, evaluation.PerQuery[0].TopA[:1])
+}
+
+func TestScoringProfiles_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	ms := newScoringTestServer(t)
+	repo := "github.com/acme/api"
+
+	_, err := ms.handleScoringProfiles(ctx, map[string]interface{}{
+		"operation":  "evaluate",
+		"repository": repo,
+	})
+	assert.Error(t, err, "no active profile to evaluate")
+
+	_, err = ms.handleScoringProfiles(ctx, map[string]interface{}{
+		"operation":  "upsert",
+		"repository": repo,
+		"profile":    map[string]interface{}{"name": "fresh-first", "recency_weight": 0.5},
+	})
+	require.NoError(t, err)
+
+	result, err := ms.handleScoringProfiles(ctx, map[string]interface{}{
+		"operation":  "evaluate",
+		"repository": repo,
+		"queries":    []interface{}{"deploy", "rollback"},
+		"k":          1.0,
+	})
+	require.NoError(t, err)
+	evaluation := result.(map[string]interface{})["evaluation"].(*ScoringEvaluation)
+	assert.Equal(t, "fresh-first", evaluation.ProfileA)
+	assert.Equal(t, relevanceRanking, evaluation.ProfileB)
+	assert.Equal(t, 2, evaluation.Queries)
+	assert.Zero(t, evaluation.Top1Agreement)
+	assert.Zero(t, evaluation.MeanOverlap)
+	assert.Equal(t, []string{"fresh"}, evaluation.PerQuery[0].TopA)
+	assert.Equal(t, []string{"stale"}, evaluation.PerQuery[0].TopB)
+	assert.Less(t, evaluation.A.MeanAgeDays, evaluation.B.MeanAgeDays)
+	// Relevance as memory_read ranks it before a profile applies
+	assert.InDelta(t, 0.77, evaluation.A.MeanRelevance, 1e-9)
+	assert.InDelta(t, 0.99, evaluation.B.MeanRelevance, 1e-9)
+}
+
+func TestScoringProfiles_HeldToTheTenant(t *testing.T) {
+	ms := newScoringTestServer(t)
+	rival := tenancy.WithTenant(context.Background(), &tenancy.Tenant{ID: "api_key:rival", Projects: []string{"github.com/rival/app"}})
+
+	for _, operation := range []string{"list", "get", "upsert", "activate", "evaluate"} {
+		_, err := ms.handleScoringProfiles(rival, map[string]interface{}{
+			"operation":  operation,
+			"repository": "github.com/acme/api",
+			"name":       "fresh-first",
+			"profile":    map[string]interface{}{"name": "fresh-first"},
+		})
+		assert.ErrorIs(t, err, tenancy.ErrCrossTenant, operation)
+	}
+	_, err := ms.handleScoringProfiles(rival, map[string]interface{}{"operation": "list", "repository": "github.com/rival/app"})
+	assert.NoError(t, err)
+}
+
+func TestLoggedRepository(t *testing.T) {
+	repo := "github.com/acme/api"
+	assert.Equal(t, repo, loggedRepository(repo))
+	assert.Equal(t, repo, loggedRepository(&repo))
+	assert.Empty(t, loggedRepository((*string)(nil)))
+	assert.Empty(t, loggedRepository(nil))
+}
@@ -0,0 +1,163 @@
+package mcp
+
+import (
+	"context"
+	"sort"
+
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/pkg/types"
+)
+
+const (
+	defaultExpansionMinConfidence = 0.8
+	defaultExpansionLimit         = 5
+	maxExpansionLimit             = 20
+
+	// expansionRelationsPerSeed bounds the relationships followed from one result
+	expansionRelationsPerSeed = 10
+)
+
+// searchExpansionConfig controls one-hop relationship expansion of search results
+type searchExpansionConfig struct {
+	MinConfidence float64
+	Limit         int
+}
+
+// expansionHit is a chunk reached from a search result through a relationship
+type expansionHit struct {
+	// Expansion is always true, marking the hit as not matched by the query itself
+	Expansion bool                    `json:"expansion"`
+	Score     float64                 `json:"score"`
+	Chunk     types.ConversationChunk `json:"chunk"`
+	Path      expansionPath           `json:"path"`
+}
+
+// expansionPath links an expansion hit to the search result it was reached from
+type expansionPath struct {
+	FromChunkID  string             `json:"from_chunk_id"`
+	RelationType types.RelationType `json:"relation_type"`
+	// Direction is "outgoing" when the result points at the hit and "incoming" when the hit points at the result
+	Direction  string  `json:"direction"`
+	Confidence float64 `json:"confidence"`
+}
+
+// parseSearchExpansion reads the expansion options of a search; ok is false
+// unless expand_relationships is set
+func parseSearchExpansion(params map[string]interface{}) (cfg searchExpansionConfig, ok bool) {
+	if expand, _ := params["expand_relationships"].(bool); !expand {
+		return cfg, false
+	}
+
+	cfg = searchExpansionConfig{MinConfidence: defaultExpansionMinConfidence, Limit: defaultExpansionLimit}
+	if confidence, ok := params["expansion_min_confidence"].(float64); ok && confidence > 0 && confidence <= 1 {
+		cfg.MinConfidence = confidence
+	}
+	if limit, ok := params["expansion_limit"].(float64); ok && limit >= 1 {
+		cfg.Limit = int(limit)
+	}
+	if cfg.Limit > maxExpansionLimit {
+		cfg.Limit = maxExpansionLimit
+	}
+	return cfg, true
+}
+
+// expandSearchResults follows relationships of at least the configured
+// confidence one hop out from each result. Chunks already in the results are
+// skipped, a chunk reached from several results keeps its best path,
+// trashed hits are dropped, and so are hits outside repository unless
+// repository is "" or global.
+// Each hit scores the result's score times the relationship's confidence.
+func (ms *MemoryServer) expandSearchResults(ctx context.Context, results []types.SearchResult, repository string, cfg searchExpansionConfig) []expansionHit {
+	store := ms.container.GetVectorStore()
+
+	seen := make(map[string]bool, len(results))
+	for i := range results {
+		seen[results[i].Chunk.ID] = true
+	}
+
+	best := make(map[string]*expansionHit)
+	for i := range results {
+		seed := &results[i]
+		query := types.NewRelationshipQuery(seed.Chunk.ID)
+		query.MinConfidence = cfg.MinConfidence
+		query.MaxDepth = 1
+		query.Limit = expansionRelationsPerSeed
+		related, err := store.GetRelationships(ctx, query)
+		if err != nil {
+			logging.Warn("Failed to expand search result", "chunk_id", seed.Chunk.ID, "error", err)
+			continue
+		}
+
+		for j := range related {
+			rel := &related[j].Relationship
+			hit := expansionHit{
+				Expansion: true,
+				Score:     seed.Score * rel.Confidence,
+				Path: expansionPath{
+					FromChunkID:  seed.Chunk.ID,
+					RelationType: rel.RelationType,
+					Direction:    "outgoing",
+					Confidence:   rel.Confidence,
+				},
+			}
+			neighborID, neighbor := rel.TargetChunkID, related[j].TargetChunk
+			if rel.TargetChunkID == seed.Chunk.ID {
+				neighborID, neighbor = rel.SourceChunkID, related[j].SourceChunk
+				hit.Path.Direction = "incoming"
+			}
+			if seen[neighborID] {
+				continue
+			}
+			if neighbor != nil {
+				hit.Chunk = *neighbor
+			}
+			hit.Chunk.ID = neighborID
+			if current, ok := best[neighborID]; !ok || hit.Score > current.Score {
+				best[neighborID] = &hit
+			}
+		}
+	}
+
+	// Relationship queries may not carry the chunks; fetch the missing ones
+	missing := make([]string, 0)
+	for id, hit := range best {
+		if hit.Chunk.Content == "" {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		chunks, err := store.GetByIDs(ctx, missing)
+		if err != nil {
+			logging.Warn("Failed to fetch expanded chunks", "count", len(missing), "error", err)
+		}
+		for i := range chunks {
+			if hit, ok := best[chunks[i].ID]; ok {
+				hit.Chunk = chunks[i]
+			}
+		}
+	}
+
+	hits := make([]expansionHit, 0, len(best))
+	for _, hit := range best {
+		if hit.Chunk.Content == "" {
+			continue // dangling relationship
+		}
+		if hit.Chunk.IsDeleted() {
+			continue
+		}
+		if repository != "" && repository != GlobalRepository && hit.Chunk.Metadata.Repository != repository {
+			continue
+		}
+		hits = append(hits, *hit)
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Chunk.ID < hits[j].Chunk.ID
+	})
+	if len(hits) > cfg.Limit {
+		hits = hits[:cfg.Limit]
+	}
+	return hits
+}
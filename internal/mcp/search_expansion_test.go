@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandSearchResults(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewKeywordStore("")
+	ms := newCompositeTestServer(t, store)
+	chunk := func(content string, chunkType types.ChunkType, repository string) *types.ConversationChunk {
+		c := newReportChunk(t, "session-1", content, chunkType, types.ChunkMetadata{Repository: repository})
+		require.NoError(t, store.Store(ctx, c))
+		return c
+	}
+	fix := chunk("Fixed the token refresh race", types.ChunkTypeSolution, "github.com/acme/api")
+	other := chunk("Tokens expire after an hour", types.ChunkTypeDiscussion, "github.com/acme/api")
+	decision := chunk("Use short-lived tokens with refresh", types.ChunkTypeArchitectureDecision, "github.com/acme/api")
+	problem := chunk("Users logged out at random", types.ChunkTypeProblem, "github.com/acme/api")
+	weak := chunk("Maybe related chatter", types.ChunkTypeDiscussion, "github.com/acme/api")
+	foreign := chunk("Web client token handling", types.ChunkTypeSolution, "github.com/acme/web")
+	trashed := chunk("Rotate refresh tokens nightly", types.ChunkTypeSolution, "github.com/acme/api")
+	require.NoError(t, ms.trashChunk(ctx, trashed, time.Now()))
+
+	link := func(from, to *types.ConversationChunk, relation types.RelationType, confidence float64) {
+		_, err := store.StoreRelationship(ctx, from.ID, to.ID, relation, confidence, types.ConfidenceExplicit)
+		require.NoError(t, err)
+	}
+	link(fix, decision, types.RelationReferences, 0.9)
+	link(other, decision, types.RelationReferences, 0.95)
+	link(problem, fix, types.RelationSolvedBy, 0.85)
+	link(fix, weak, types.RelationRelatedTo, 0.4)
+	link(fix, foreign, types.RelationRelatedTo, 0.9)
+	link(fix, other, types.RelationRelatedTo, 0.9)
+	link(fix, trashed, types.RelationRelatedTo, 0.95)
+	results := []types.SearchResult{{Chunk: *fix, Score: 0.9}, {Chunk: *other, Score: 0.5}}
+
+	cfg, ok := parseSearchExpansion(map[string]interface{}{"expand_relationships": true})
+	require.True(t, ok)
+	hits := ms.expandSearchResults(ctx, results, "github.com/acme/api", cfg)
+
+	// The decision is reached from both results and keeps the better path;
+	// results, weak links, trashed chunks and other repositories are left out
+	require.Len(t, hits, 2)
+	assert.Equal(t, decision.ID, hits[0].Chunk.ID)
+	assert.True(t, hits[0].Expansion)
+	assert.InDelta(t, 0.81, hits[0].Score, 1e-9)
+	assert.Equal(t, expansionPath{FromChunkID: fix.ID, RelationType: types.RelationReferences, Direction: "outgoing", Confidence: 0.9}, hits[0].Path)
+	assert.Equal(t, problem.ID, hits[1].Chunk.ID)
+	assert.Equal(t, "incoming", hits[1].Path.Direction)
+	assert.Equal(t, "Users logged out at random", hits[1].Chunk.Content)
+
+	cfg.Limit = 1
+	assert.Len(t, ms.expandSearchResults(ctx, results, "github.com/acme/api", cfg), 1)
+	cfg.Limit = defaultExpansionLimit
+	assert.Len(t, ms.expandSearchResults(ctx, results, GlobalRepository, cfg), 3, "global searches expand across repositories")
+}
+
+func TestParseSearchExpansion(t *testing.T) {
+	_, ok := parseSearchExpansion(map[string]interface{}{})
+	assert.False(t, ok)
+
+	cfg, ok := parseSearchExpansion(map[string]interface{}{
+		"expand_relationships":     true,
+		"expansion_min_confidence": 0.6,
+		"expansion_limit":          float64(100),
+	})
+	require.True(t, ok)
+	assert.InDelta(t, 0.6, cfg.MinConfidence, 1e-9)
+	assert.Equal(t, maxExpansionLimit, cfg.Limit)
+}
@@ -0,0 +1,38 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecureSearchHighlights(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	chunk := newTaskChunk(t, "github.com/acme/api", types.TaskStatusTodo)
+	chunk.Content = "Deployed on Monday. The postgres pool was exhausted. We raised the limit."
+	require.NoError(t, store.Store(ctx, chunk))
+
+	ms := newCompositeTestServer(t, store)
+	search := func(options map[string]interface{}) []types.SearchResult {
+		options["repository"] = "github.com/acme/api"
+		options["query"] = "postgres pool"
+		result, err := ms.handleMemoryRead(ctx, map[string]interface{}{"operation": "search", "options": options})
+		require.NoError(t, err)
+		results := result.(map[string]interface{})["results"].([]types.SearchResult)
+		require.Len(t, results, 1)
+		return results
+	}
+
+	highlighted := search(map[string]interface{}{"context_sentences": float64(0)})[0].Highlight
+	require.NotNil(t, highlighted)
+	assert.Equal(t, "…The **postgres** **pool** was exhausted.…", highlighted.Highlighted)
+	assert.Equal(t, []string{"postgres", "pool"}, highlighted.MatchedTerms)
+
+	assert.Nil(t, search(map[string]interface{}{"highlight": false})[0].Highlight)
+}
@@ -0,0 +1,44 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"lerian-mcp-memory/pkg/types"
+)
+
+// searchModeNames lists the search modes for tool schemas
+func searchModeNames() []string {
+	return []string{string(types.SearchModeVector), string(types.SearchModeKeyword), string(types.SearchModeHybrid)}
+}
+
+// searchModeFromParams returns the search mode a search asks for, or the
+// configured default. Keyword and hybrid modes need the keyword index,
+// except in lite mode, where every search is ranked by keyword.
+func (ms *MemoryServer) searchModeFromParams(params map[string]interface{}) (types.SearchMode, error) {
+	mode := types.SearchModeVector
+	cfg := ms.container.Config
+	if cfg != nil && cfg.Search.DefaultMode != "" {
+		mode = types.SearchMode(cfg.Search.DefaultMode)
+	}
+	if requested, ok := params["mode"].(string); ok && requested != "" {
+		mode = types.SearchMode(requested)
+	}
+
+	if !mode.Valid() {
+		return "", fmt.Errorf("invalid search mode %q (valid: vector, keyword, hybrid)", mode)
+	}
+	if mode != types.SearchModeVector && ms.container.HybridSearch == nil && (cfg == nil || !cfg.IsLiteMode()) {
+		return "", fmt.Errorf("%s search requires the keyword index (MCP_MEMORY_SEARCH_KEYWORD_INDEX=true)", mode)
+	}
+	return mode, nil
+}
+
+// searchEmbeddings generates the query vector of a search, which keyword
+// searches do without
+func (ms *MemoryServer) searchEmbeddings(ctx context.Context, query string, mode types.SearchMode) ([]float64, error) {
+	if mode == types.SearchModeKeyword {
+		return nil, nil
+	}
+	return ms.container.GetEmbeddingService().GenerateEmbedding(ctx, query)
+}
@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchModes(t *testing.T) {
+	ctx := context.Background()
+	local := storage.NewLocalVectorStore("")
+	require.NoError(t, local.Initialize(ctx))
+
+	ms := newCompositeTestServer(t, local)
+	_, err := ms.handleSecureSearch(ctx, map[string]interface{}{"query": "ERR_4021", "mode": "keyword"}, "github.com/acme/api")
+	assert.ErrorContains(t, err, "requires the keyword index")
+
+	hybrid := storage.NewHybridSearchStore(local, 0)
+	require.NoError(t, hybrid.Initialize(ctx))
+	ms.container.VectorStore = hybrid
+	ms.container.HybridSearch = hybrid
+
+	for _, content := range []string{"Payment failed with ERR_4021 at checkout", "Checkout flow redesign"} {
+		chunk, err := types.NewConversationChunk("session-1", content, types.ChunkTypeProblem, &types.ChunkMetadata{
+			Repository: "github.com/acme/api",
+			Outcome:    types.OutcomeSuccess,
+			Difficulty: types.DifficultySimple,
+		})
+		require.NoError(t, err)
+		chunk.Embeddings = []float64{0.1, 0.2, 0.3}
+		require.NoError(t, hybrid.Store(ctx, chunk))
+	}
+
+	result, err := ms.handleSecureSearch(ctx, map[string]interface{}{"query": "ERR_4021", "mode": "keyword"}, "github.com/acme/api")
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	assert.Equal(t, types.SearchModeKeyword, response["search_mode"])
+	results := response["results"].([]types.SearchResult)
+	require.Len(t, results, 1)
+	assert.Contains(t, results[0].Chunk.Content, "ERR_4021")
+
+	result, err = ms.handleSecureSearch(ctx, map[string]interface{}{"query": "ERR_4021", "mode": "hybrid"}, "github.com/acme/api")
+	require.NoError(t, err)
+	results = result.(map[string]interface{})["results"].([]types.SearchResult)
+	require.NotEmpty(t, results)
+	assert.Contains(t, results[0].Chunk.Content, "ERR_4021", "the exact identifier match ranks first")
+
+	_, err = ms.handleSecureSearch(ctx, map[string]interface{}{"query": "ERR_4021", "mode": "fuzzy"}, "github.com/acme/api")
+	assert.ErrorContains(t, err, "invalid search mode")
+
+	// Searches that name no mode use the configured default
+	ms.container.Config = &config.Config{Search: config.SearchConfig{DefaultMode: config.SearchModeHybrid}}
+	mode, err := ms.searchModeFromParams(map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, types.SearchModeHybrid, mode)
+}
@@ -0,0 +1,222 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/pkg/types"
+)
+
+// SearchPlanOutcome reports how progressive search arrived at its results
+type SearchPlanOutcome struct {
+	// SatisfiedStep names the step that satisfied the query, or "" when none did
+	SatisfiedStep string `json:"satisfied_step,omitempty"`
+	// ResultStep names the step whose results were returned
+	ResultStep     string            `json:"result_step,omitempty"`
+	EarlyExitScore float64           `json:"early_exit_score,omitempty"`
+	Steps          []SearchStepTrace `json:"steps"`
+}
+
+// SearchStepTrace records one step of a search plan
+type SearchStepTrace struct {
+	Name     string  `json:"name"`
+	Results  int     `json:"results"`
+	TopScore float64 `json:"top_score,omitempty"`
+	Duration string  `json:"duration,omitempty"`
+	// Repository is the related repository that produced the results
+	Repository string `json:"repository,omitempty"`
+	Skipped    string `json:"skipped,omitempty"`
+}
+
+// executeProgressiveSearch runs the configured search plan. Each step relaxes
+// the original query; the first step with results (scoring at least the early
+// exit score, when one is set) satisfies the query. If none does, the best
+// results any step found are returned. Scoped searches never leave the
+// query's repository: steps searching related repositories or dropping the
+// repository are skipped.
+func (ms *MemoryServer) executeProgressiveSearch(ctx context.Context, query *types.MemoryQuery, embeddings []float64, scoped bool) (*types.SearchResults, *SearchPlanOutcome, error) {
+	var searchConfig config.SearchConfig
+	if ms.container.Config != nil {
+		searchConfig = ms.container.Config.Search
+	}
+
+	// If progressive search is disabled, just do a single search
+	plan := []config.SearchStep{{Name: "strict"}}
+	if searchConfig.EnableProgressiveSearch {
+		plan = searchConfig.SearchPlan()
+	}
+
+	outcome := &SearchPlanOutcome{EarlyExitScore: searchConfig.EarlyExitScore, Steps: make([]SearchStepTrace, 0, len(plan))}
+	var best, last *types.SearchResults
+	var bestScore float64
+	tried := make(map[string]bool)
+
+	for i := range plan {
+		step := &plan[i]
+		trace := SearchStepTrace{Name: step.Name}
+		if scoped && query.Repository != nil && (step.RelatedRepositories || step.DropRepository) {
+			trace.Skipped = "leaves the repository"
+			outcome.Steps = append(outcome.Steps, trace)
+			continue
+		}
+		queries := ms.searchStepQueries(query, step, &searchConfig)
+		if len(queries) == 0 {
+			trace.Skipped = "query has no repository"
+			outcome.Steps = append(outcome.Steps, trace)
+			continue
+		}
+
+		// Skip steps that would repeat a search already run
+		fresh := queries[:0]
+		for _, q := range queries {
+			if key := searchQueryKey(q); !tried[key] {
+				tried[key] = true
+				fresh = append(fresh, q)
+			}
+		}
+		if len(fresh) == 0 {
+			trace.Skipped = "same as an earlier step"
+			outcome.Steps = append(outcome.Steps, trace)
+			continue
+		}
+
+		logging.Info("Progressive search step", "step", step.Name, "searches", len(fresh), "min_relevance", fresh[0].MinRelevanceScore)
+		start := time.Now()
+		results, repository, err := ms.runSearchStep(ctx, step, fresh, embeddings, searchConfig.StepBudgetMs)
+		trace.Duration = time.Since(start).String()
+		switch {
+		case errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil:
+			logging.Warn("Progressive search step ran out of time", "step", step.Name, "duration", trace.Duration)
+			trace.Skipped = "time budget exceeded"
+			outcome.Steps = append(outcome.Steps, trace)
+			continue
+		case err != nil:
+			return nil, nil, fmt.Errorf("search step %s failed: %w", step.Name, err)
+		}
+
+		last = results
+		trace.Results = len(results.Results)
+		trace.TopScore = topSearchScore(results)
+		trace.Repository = repository
+		outcome.Steps = append(outcome.Steps, trace)
+
+		if trace.Results == 0 {
+			continue
+		}
+		if best == nil || trace.TopScore > bestScore {
+			best, bestScore = results, trace.TopScore
+			outcome.ResultStep = step.Name
+		}
+		if searchConfig.EarlyExitScore <= 0 || trace.TopScore >= searchConfig.EarlyExitScore {
+			logging.Info("Progressive search satisfied", "step", step.Name, "results", trace.Results, "top_score", trace.TopScore)
+			outcome.SatisfiedStep = step.Name
+			return results, outcome, nil
+		}
+	}
+
+	if best != nil {
+		return best, outcome, nil
+	}
+	if last == nil {
+		last = &types.SearchResults{Results: []types.SearchResult{}}
+	}
+	return last, outcome, nil
+}
+
+// searchStepQueries relaxes query as step describes. Related repository steps
+// yield one query per related repository, and none without a repository.
+func (ms *MemoryServer) searchStepQueries(query *types.MemoryQuery, step *config.SearchStep, searchConfig *config.SearchConfig) []*types.MemoryQuery {
+	relaxed := *query
+	if step.MinRelevance > 0 {
+		relaxed.MinRelevanceScore = step.MinRelevance
+	}
+	if step.DropTypes {
+		relaxed.Types = nil
+	}
+	if step.DropRepository {
+		relaxed.Repository = nil
+	}
+	if !step.RelatedRepositories {
+		return []*types.MemoryQuery{&relaxed}
+	}
+
+	if query.Repository == nil {
+		return nil
+	}
+	relatedRepos := ms.generateRelatedRepositories(*query.Repository)
+	// Limit to configured max related repos
+	if len(relatedRepos) > searchConfig.MaxRelatedRepos {
+		relatedRepos = relatedRepos[:searchConfig.MaxRelatedRepos]
+	}
+	queries := make([]*types.MemoryQuery, 0, len(relatedRepos))
+	for i := range relatedRepos {
+		relatedQuery := relaxed
+		relatedQuery.Repository = &relatedRepos[i]
+		queries = append(queries, &relatedQuery)
+	}
+	return queries
+}
+
+// runSearchStep runs a step's searches within its time budget. A step with
+// several searches (related repositories) stops at the first with results,
+// and a failed search moves on to the next one.
+func (ms *MemoryServer) runSearchStep(ctx context.Context, step *config.SearchStep, queries []*types.MemoryQuery, embeddings []float64, defaultBudgetMs int) (*types.SearchResults, string, error) {
+	budgetMs := step.TimeBudgetMs
+	if budgetMs == 0 {
+		budgetMs = defaultBudgetMs
+	}
+	if budgetMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(budgetMs)*time.Millisecond)
+		defer cancel()
+	}
+
+	store := ms.container.GetVectorStore()
+	if !step.RelatedRepositories {
+		results, err := store.Search(ctx, queries[0], embeddings)
+		return results, "", err
+	}
+
+	var lastErr error
+	for _, query := range queries {
+		results, err := store.Search(ctx, query, embeddings)
+		if err != nil {
+			lastErr = err
+			if ctx.Err() != nil {
+				return nil, "", err
+			}
+			continue // Try next related repo
+		}
+		if len(results.Results) > 0 {
+			return results, *query.Repository, nil
+		}
+	}
+	if lastErr != nil {
+		logging.Warn("Related repository searches failed", "step", step.Name, "error", lastErr)
+	}
+	return &types.SearchResults{Results: []types.SearchResult{}}, "", nil
+}
+
+// searchQueryKey identifies the parts of a query that plan steps relax
+func searchQueryKey(query *types.MemoryQuery) string {
+	repository := "*"
+	if query.Repository != nil {
+		repository = *query.Repository
+	}
+	return fmt.Sprintf("%g|%s|%v", query.MinRelevanceScore, repository, query.Types)
+}
+
+// topSearchScore returns the best score in results
+func topSearchScore(results *types.SearchResults) float64 {
+	var top float64
+	for i := range results.Results {
+		if results.Results[i].Score > top {
+			top = results.Results[i].Score
+		}
+	}
+	return top
+}
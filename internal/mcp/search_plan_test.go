@@ -0,0 +1,170 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// plannedSearchStore answers searches with canned scores, recording each query
+type plannedSearchStore struct {
+	storage.VectorStore
+	answer  func(query *types.MemoryQuery) []float64
+	queries []types.MemoryQuery
+	delay   time.Duration
+}
+
+func (s *plannedSearchStore) Search(ctx context.Context, query *types.MemoryQuery, _ []float64) (*types.SearchResults, error) {
+	s.queries = append(s.queries, *query)
+	if s.delay > 0 {
+		select {
+		case <-time.After(s.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	results := &types.SearchResults{}
+	for _, score := range s.answer(query) {
+		results.Results = append(results.Results, types.SearchResult{Score: score})
+	}
+	results.Total = len(results.Results)
+	return results, nil
+}
+
+func newPlannedSearchServer(t *testing.T, store *plannedSearchStore) *MemoryServer {
+	store.VectorStore = storage.NewSimpleMockVectorStore()
+	ms := newCompositeTestServer(t, store)
+	ms.container.Config = config.DefaultConfig()
+	return ms
+}
+
+func TestProgressiveSearch_DefaultPlan(t *testing.T) {
+	repo := "libs/commons-go"
+	store := &plannedSearchStore{answer: func(query *types.MemoryQuery) []float64 {
+		// Only a search across all repositories finds anything
+		if query.Repository == nil {
+			return []float64{0.4}
+		}
+		return nil
+	}}
+	ms := newPlannedSearchServer(t, store)
+
+	query := types.NewMemoryQuery("retry strategy")
+	query.Repository = &repo
+	results, outcome, err := ms.executeProgressiveSearch(context.Background(), query, nil, false)
+	require.NoError(t, err)
+	assert.Len(t, results.Results, 1)
+	assert.Equal(t, "any_repository", outcome.SatisfiedStep)
+
+	names := make([]string, len(outcome.Steps))
+	for i, step := range outcome.Steps {
+		names[i] = step.Name
+	}
+	assert.Equal(t, []string{"strict", "relaxed", "related_repositories", "any_repository"}, names)
+
+	// Without a repository, repository steps add nothing and are skipped
+	store.queries = nil
+	_, outcome, err = ms.executeProgressiveSearch(context.Background(), types.NewMemoryQuery("retry strategy"), nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, "strict", outcome.SatisfiedStep)
+	assert.Len(t, store.queries, 1)
+}
+
+func TestProgressiveSearch_EarlyExitScore(t *testing.T) {
+	store := &plannedSearchStore{answer: func(query *types.MemoryQuery) []float64 {
+		if query.Types == nil {
+			return []float64{0.7, 0.5}
+		}
+		return []float64{0.55}
+	}}
+	ms := newPlannedSearchServer(t, store)
+	ms.container.Config.Search.Plan = []config.SearchStep{
+		{Name: "typed"},
+		{Name: "untyped", DropTypes: true},
+		{Name: "broad", MinRelevance: 0.1, DropTypes: true},
+	}
+
+	query := types.NewMemoryQuery("deploy")
+	query.Types = []types.ChunkType{types.ChunkTypeSolution}
+
+	// The typed step has results but scores below the threshold
+	ms.container.Config.Search.EarlyExitScore = 0.6
+	results, outcome, err := ms.executeProgressiveSearch(context.Background(), query, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, "untyped", outcome.SatisfiedStep)
+	assert.Len(t, results.Results, 2)
+	assert.Len(t, outcome.Steps, 2)
+
+	// Nothing reaches the threshold: the best results are returned unsatisfied
+	ms.container.Config.Search.EarlyExitScore = 0.9
+	results, outcome, err = ms.executeProgressiveSearch(context.Background(), query, nil, false)
+	require.NoError(t, err)
+	assert.Empty(t, outcome.SatisfiedStep)
+	assert.Equal(t, "untyped", outcome.ResultStep)
+	assert.InDelta(t, 0.7, outcome.Steps[1].TopScore, 1e-9)
+	assert.Len(t, results.Results, 2)
+}
+
+func TestProgressiveSearch_StepBudget(t *testing.T) {
+	store := &plannedSearchStore{
+		answer: func(*types.MemoryQuery) []float64 { return []float64{0.8} },
+		delay:  50 * time.Millisecond,
+	}
+	ms := newPlannedSearchServer(t, store)
+	ms.container.Config.Search.Plan = []config.SearchStep{
+		{Name: "fast", TimeBudgetMs: 1},
+		{Name: "patient", MinRelevance: 0.2},
+	}
+
+	_, outcome, err := ms.executeProgressiveSearch(context.Background(), types.NewMemoryQuery("deploy"), nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, "time budget exceeded", outcome.Steps[0].Skipped)
+	assert.Equal(t, "patient", outcome.SatisfiedStep)
+}
+
+func TestSecureSearch_RunsThePlanWithinTheRepository(t *testing.T) {
+	store := &plannedSearchStore{answer: func(query *types.MemoryQuery) []float64 {
+		switch {
+		case query.Repository == nil || *query.Repository != "libs/commons-go":
+			return []float64{0.9}
+		case query.MinRelevanceScore < 0.5:
+			return []float64{0.4}
+		}
+		return nil
+	}}
+	ms := newPlannedSearchServer(t, store)
+
+	result, err := ms.handleMemoryRead(context.Background(), map[string]interface{}{
+		"operation": "search",
+		"options":   map[string]interface{}{"query": "retry strategy", "repository": "libs/commons-go"},
+	})
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	assert.Equal(t, "relaxed", response["satisfied_step"])
+
+	// Without a satisfying step, repository steps are skipped rather than run
+	store.answer = func(*types.MemoryQuery) []float64 { return nil }
+	store.queries = nil
+	result, err = ms.handleMemoryRead(context.Background(), map[string]interface{}{
+		"operation": "search",
+		"options":   map[string]interface{}{"query": "retry strategy", "repository": "libs/commons-go"},
+	})
+	require.NoError(t, err)
+	outcome := result.(map[string]interface{})["search_plan"].(*SearchPlanOutcome)
+	for _, step := range outcome.Steps {
+		if step.Name == "related_repositories" || step.Name == "any_repository" || step.Name == "broadest" {
+			assert.Equal(t, "leaves the repository", step.Skipped, step.Name)
+		}
+	}
+	for _, query := range store.queries {
+		require.NotNil(t, query.Repository)
+		assert.Equal(t, "libs/commons-go", *query.Repository)
+	}
+}
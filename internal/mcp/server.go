@@ -12,10 +12,22 @@ import (
 	"lerian-mcp-memory/internal/config"
 	contextdetector "lerian-mcp-memory/internal/context"
 	"lerian-mcp-memory/internal/di"
+	"lerian-mcp-memory/internal/digest"
+	"lerian-mcp-memory/internal/embeddings"
+	"lerian-mcp-memory/internal/highlight"
+	"lerian-mcp-memory/internal/httptools"
 	"lerian-mcp-memory/internal/intelligence"
+	"lerian-mcp-memory/internal/locking"
 	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/monitoring"
+	"lerian-mcp-memory/internal/pagesync"
 	"lerian-mcp-memory/internal/relationships"
+	"lerian-mcp-memory/internal/scoring"
+	"lerian-mcp-memory/internal/security"
+	"lerian-mcp-memory/internal/slacksync"
 	"lerian-mcp-memory/internal/threading"
+	"lerian-mcp-memory/internal/usage"
+	"lerian-mcp-memory/internal/websocket"
 	"lerian-mcp-memory/internal/workflow"
 	"lerian-mcp-memory/pkg/types"
 	"log"
@@ -26,9 +38,12 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	mcp "github.com/fredcamaral/gomcp-sdk"
+	"github.com/fredcamaral/gomcp-sdk/notifications"
 	"github.com/fredcamaral/gomcp-sdk/protocol"
 	"github.com/fredcamaral/gomcp-sdk/server"
 	"github.com/google/uuid"
@@ -56,6 +71,85 @@ type MemoryServer struct {
 
 	// Workflow tracking
 	todoTracker *workflow.TodoTracker
+
+	// Live update delivery
+	notifier *notifications.Notifier
+	wsHub    *websocket.Hub
+
+	// Scheduled digests and events sent to notification subscribers
+	digestGenerator *digest.Generator
+	digestScheduler *digest.Scheduler
+	eventNotifier   *digest.Notifier
+
+	// Monthly usage reports of each tenant, when usage metering is enabled
+	usageReporter *usage.Reporter
+
+	// Periodic import of Notion and Confluence pages
+	pageSyncer *pagesync.Syncer
+
+	// Periodic import of Slack channel history
+	slackSyncer *slacksync.Syncer
+
+	// External HTTP APIs exposed as tools, reloaded when their file changes
+	httpTools *httptools.Registry
+
+	// Files and databases tools may access, when a resource policy is configured
+	resourcePolicy *security.ResourcePolicy
+
+	// Advisory chunk locks and versioned update serialization
+	chunkLocks *locking.Manager
+
+	// Per-tool invocation counts and latency
+	toolMetrics *monitoring.ToolMetrics
+
+	// Turns of tool calls and resource reads by QoS class, when QoS scheduling is enabled
+	qos *QoSScheduler
+
+	// Latest memory health score of each repository, served as gauges
+	healthScores *monitoring.HealthScores
+
+	// Chunks touched per session, served as memory://session/{id}/working-set
+	workingSet *workingSetTracker
+
+	// Resource subscriptions (resources/subscribe)
+	subscriptions resourceSubscriptions
+
+	// Arguments declared by prompts, checked on prompts/get
+	prompts promptRegistry
+
+	// Server-to-client requests awaiting a response (sampling/createMessage, roots/list)
+	clientRequests clientRequests
+
+	// Roots shared by each connection's client
+	clientRoots rootsCache
+
+	// Per-client state kept between requests
+	sessions SessionManager
+
+	// Repository memories and ranked context served to editor plugins
+	editorContext editorContextCache
+
+	// Elided parts of truncated tool results, paged through with continue_result
+	results resultCache
+
+	// Request middleware chain applied by HandleRequest
+	middlewareMu   sync.RWMutex
+	middlewares    []Middleware
+	requestHandler RequestHandler
+
+	// Tools registered and removed at runtime, annotations given at runtime,
+	// and connections that receive list change notifications sent to every client
+	toolsMu          sync.RWMutex
+	toolNames        map[string]bool
+	removedTools     map[string]bool
+	toolAnnotations  map[string]ToolAnnotations
+	broadcastSenders []NotificationSender
+	started          atomic.Bool
+
+	// Request draining and transport shutdown hooks for Shutdown, and the
+	// cancellation of the background loops Start runs
+	drain          drainState
+	stopBackground context.CancelFunc
 }
 
 // NewMemoryServer creates a new memory MCP server
@@ -80,13 +174,89 @@ func NewMemoryServer(cfg *config.Config) (*MemoryServer, error) {
 	// Initialize workflow tracking
 	memServer.todoTracker = workflow.NewTodoTracker()
 
+	// Initialize chunk locking for versioned updates
+	memServer.chunkLocks = locking.NewManager()
+
+	// Initialize digest generation and scheduling
+	memServer.initDigests(cfg)
+
+	// Initialize Notion and Confluence page import
+	memServer.initPageSync(cfg)
+
+	// Initialize Slack channel history import
+	memServer.initSlackSync(cfg)
+
+	// Initialize per-tool usage metrics
+	memServer.toolMetrics = monitoring.NewToolMetrics()
+	memServer.healthScores = monitoring.NewHealthScores()
+
+	// Initialize per-session working sets
+	memServer.workingSet = newWorkingSetTracker()
+
+	// Initialize resource update notifications
+	memServer.notifier = notifications.NewNotifier(getEnvInt("MCP_MEMORY_NOTIFICATION_QUEUE_SIZE", 100))
+
 	// Create MCP server
-	serverName := getEnv("SERVICE_NAME", "claude-memory")
-	serverVersion := getEnv("SERVICE_VERSION", "VERSION_PLACEHOLDER")
-	mcpServer := mcp.NewServer(serverName, serverVersion)
+	mcpServer := mcp.NewServer(cfg.Server.Name, cfg.Server.Version)
 	memServer.mcpServer = mcpServer
 	memServer.registerTools()
-	memServer.registerResources()
+	if cfg.Server.Capabilities.Resources {
+		memServer.registerResources()
+	}
+	memServer.initHTTPTools(cfg)
+
+	// Hold tools to the files and databases the resource policy grants
+	if cfg.Security.ResourcePolicyFile != "" {
+		policy, err := security.LoadResourcePolicy(cfg.Security.ResourcePolicyFile)
+		if err != nil {
+			return nil, err
+		}
+		memServer.SetResourcePolicy(policy)
+	}
+
+	// Keep subscribers of recent activity up to date as chunks are stored
+	if feed := container.GetChangeFeed(); feed != nil && cfg.Server.Capabilities.Resources {
+		memServer.AddResourceWatcher(recentActivityWatcher{feed: feed})
+	}
+
+	// Keep a panicking handler from taking down the transport,
+	// and let tool handlers report progress to clients that asked for it
+	memServer.Use(RecoveryMiddleware(), ProgressMiddleware())
+
+	// Hold clients authenticated with API keys to the tools their keys allow
+	if keys := container.GetAPIKeys(); keys != nil {
+		memServer.Use(APIKeyMiddleware(keys))
+	}
+
+	// Hold clients authenticated with bearer tokens to the tools their scopes allow
+	if cfg.Security.OAuth.Enabled {
+		memServer.Use(OAuthScopeMiddleware(&cfg.Security.OAuth, memServer))
+	}
+
+	// Hold authenticated clients to their tenant's projects
+	if cfg.Security.Tenancy.Enabled {
+		memServer.Use(TenancyMiddleware())
+	}
+
+	// Hold public demo clients to reading memories and adding new ones
+	if cfg.Demo.Enabled {
+		memServer.Use(DemoMiddleware())
+	}
+
+	// Keep clients from hammering expensive tools
+	if limiter := NewRateLimiter(&cfg.RateLimit); limiter != nil {
+		memServer.Use(RateLimitMiddleware(limiter))
+	}
+
+	// Keep background bulk work from starving interactive calls
+	if memServer.qos = NewQoSScheduler(&cfg.QoS); memServer.qos != nil {
+		memServer.Use(QoSMiddleware(memServer.qos))
+	}
+
+	// Count each tenant's calls, sessions and projects for its usage reports
+	if meter := container.GetUsageMeter(); meter != nil {
+		memServer.Use(UsageMiddleware(meter))
+	}
 
 	return memServer, nil
 }
@@ -103,9 +273,62 @@ func (ms *MemoryServer) Start(ctx context.Context) error {
 		log.Printf("Warning: Service health check failed: %v", err)
 	}
 
+	// Background loops run until Shutdown
+	ctx, ms.stopBackground = context.WithCancel(ctx)
+
+	// Start resource update notification delivery
+	ms.notifier.Start(ctx)
+
 	// Start automatic decay management for old chunks
 	go ms.runPeriodicDecay(ctx)
 
+	// Start scheduled digest delivery
+	ms.startDigestScheduler(ctx)
+
+	// Start batched delivery to digest-mode notification subscriptions
+	if ms.eventNotifier != nil {
+		go ms.eventNotifier.Run(ctx)
+	}
+
+	// Start periodic re-sync of imported pages
+	if ms.pageSyncer != nil {
+		go ms.pageSyncer.Run(ctx)
+	}
+
+	// Start periodic sync of Slack channels
+	if ms.slackSyncer != nil {
+		go ms.slackSyncer.Run(ctx)
+	}
+
+	// Start reloading HTTP tools when their file changes
+	if ms.httpTools != nil {
+		go ms.httpTools.Run(ctx)
+	}
+
+	// Start hard purge of trashed memories past their retention
+	go ms.runTrashPurger(ctx)
+
+	// Start periodic flushing of metered usage
+	if meter := ms.container.GetUsageMeter(); meter != nil {
+		go meter.Run(ctx, time.Duration(ms.container.Config.Usage.FlushSeconds)*time.Second)
+	}
+
+	// Start periodic memory health scoring
+	go ms.runHealthScoring(ctx)
+
+	// Seed the demo dataset and restore it on an interval
+	if demo := ms.container.Config.Demo; demo.Enabled {
+		seeded, err := ms.resetDemo(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to seed demo dataset: %w", err)
+		}
+		log.Printf("Demo mode: seeded %d memories, reset every %d minutes", seeded, demo.ResetMinutes)
+		if demo.ResetMinutes > 0 {
+			go ms.runDemoResets(ctx, time.Duration(demo.ResetMinutes)*time.Minute)
+		}
+	}
+
+	ms.started.Store(true)
 	log.Printf("Claude Memory MCP Server started successfully")
 	return nil
 }
@@ -122,13 +345,13 @@ func (ms *MemoryServer) GetContainer() *di.Container {
 
 // SetWebSocketHub sets the WebSocket hub for broadcasting memory updates
 func (ms *MemoryServer) SetWebSocketHub(hub interface{}) {
-	// Store hub in a type-safe way - we'll access it when needed
-	// For now, just log that it's been set
-	if hub != nil {
-		log.Printf("WebSocket hub configured for memory updates")
-		// In a more complete implementation, we'd store this and use it
-		// when memory operations occur to broadcast changes
+	wsHub, ok := hub.(*websocket.Hub)
+	if !ok || wsHub == nil {
+		return
 	}
+
+	ms.wsHub = wsHub
+	log.Printf("WebSocket hub configured for memory updates")
 }
 
 // registerTools registers all MCP tools
@@ -138,7 +361,7 @@ func (ms *MemoryServer) registerTools() {
 	useCompatibility := getEnvBool("MCP_MEMORY_USE_BACKWARD_COMPATIBILITY", false)
 
 	if useConsolidated {
-		log.Printf("Registering 9 consolidated MCP tools")
+		log.Printf("Registering 14 consolidated MCP tools")
 		ms.registerConsolidatedTools()
 
 		// Optionally add backward compatibility layer for legacy tool names
@@ -156,7 +379,7 @@ func (ms *MemoryServer) registerTools() {
 func (ms *MemoryServer) registerLegacyTools() {
 	// Register all original MCP tools with proper schemas
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_store_chunk",
 		"Store important conversation moments (bug fixes, solutions, decisions, learnings) for future reference. Automatically categorizes and links related memories.",
 		mcp.ObjectSchema("Store memory chunk parameters", map[string]interface{}{
@@ -168,10 +391,15 @@ func (ms *MemoryServer) registerLegacyTools() {
 			"tools_used":     mcp.ArraySchema("List of tools that were used", map[string]interface{}{"type": "string"}),
 			"tags":           mcp.ArraySchema("Additional tags for categorization (e.g., 'bug-fix', 'performance', 'architecture')", map[string]interface{}{"type": "string"}),
 			"client_type":    mcp.StringParam("Client type (e.g., 'claude-cli', 'chatgpt', 'vscode', 'web', 'api')", false),
+			"memory_class": map[string]interface{}{
+				"type":        "string",
+				"enum":        memoryClassNames(),
+				"description": "Memory class; defaults by chunk type (decisions and analyses are semantic, solutions procedural, the rest episodic)",
+			},
 		}, []string{"content", "session_id"}),
 	), mcp.ToolHandlerFunc(ms.handleStoreChunk))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_search",
 		"Search past memories using natural language. Finds similar problems, solutions, and decisions. Use before solving to check if issue was encountered before.",
 		mcp.ObjectSchema("Search memory parameters", map[string]interface{}{
@@ -184,6 +412,8 @@ func (ms *MemoryServer) registerLegacyTools() {
 				"default":     "recent",
 			},
 			"types": mcp.ArraySchema("Filter by chunk types", map[string]interface{}{"type": "string"}),
+			"classes": mcp.ArraySchema("Filter by memory classes: episodic (session logs), semantic (distilled facts) or procedural (how-tos)",
+				map[string]interface{}{"type": "string", "enum": memoryClassNames()}),
 			"limit": map[string]interface{}{
 				"type":        "integer",
 				"description": "Maximum number of results",
@@ -198,10 +428,31 @@ func (ms *MemoryServer) registerLegacyTools() {
 				"minimum":     0,
 				"maximum":     1,
 			},
+			"mode": map[string]interface{}{
+				"type":        "string",
+				"enum":        searchModeNames(),
+				"description": "Ranking: vector similarity, BM25 keyword score (finds exact identifiers such as error codes), or hybrid, which fuses both rankings. Default: the server's configured mode, normally vector",
+			},
+			"expand_relationships": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Also return chunks one relationship away from the results (e.g. the decision a bug fix references), listed separately in expanded_results with the linking path",
+				"default":     false,
+			},
+			"expansion_min_confidence": map[string]interface{}{
+				"type":        "number",
+				"description": "Minimum relationship confidence followed when expanding (0-1)",
+				"default":     defaultExpansionMinConfidence,
+			},
+			"expansion_limit": map[string]interface{}{
+				"type":        "integer",
+				"description": "Maximum number of expanded results",
+				"default":     defaultExpansionLimit,
+				"maximum":     maxExpansionLimit,
+			},
 		}, []string{"query"}),
 	), mcp.ToolHandlerFunc(ms.handleSearch))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_get_context",
 		"Get project overview and recent activity. Use at session start or when switching projects to understand context, patterns, and ongoing work.",
 		mcp.ObjectSchema("Get context parameters", map[string]interface{}{
@@ -216,7 +467,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		}, []string{"repository"}),
 	), mcp.ToolHandlerFunc(ms.handleGetContext))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_find_similar",
 		"Find similar problems and their solutions from past experiences. Use when facing errors or complex challenges to learn from previous solutions.",
 		mcp.ObjectSchema("Find similar parameters", map[string]interface{}{
@@ -232,7 +483,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		}, []string{"problem"}),
 	), mcp.ToolHandlerFunc(ms.handleFindSimilar))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_store_decision",
 		"Explicitly store architectural/design decisions with rationale and alternatives. Use after making significant technical choices to preserve context.",
 		mcp.ObjectSchema("Store decision parameters", map[string]interface{}{
@@ -245,9 +496,9 @@ func (ms *MemoryServer) registerLegacyTools() {
 		}, []string{"decision", "rationale", "session_id"}),
 	), mcp.ToolHandlerFunc(ms.handleStoreDecision))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_get_patterns",
-		"Identify recurring patterns, common issues, and trends. Use for retrospectives, identifying refactoring needs, or understanding project challenges.",
+		"Detect recurring patterns: problems fixed the same way again and again (error→fix pairs clustered by error signature) and tool chains used repeatedly. Patterns are saved with their frequency, success rate and example chunks. Use for retrospectives, identifying refactoring needs, or understanding project challenges.",
 		mcp.ObjectSchema("Get patterns parameters", map[string]interface{}{
 			"repository": mcp.StringParam("Official repository name to analyze (e.g., 'github.com/lerianstudio/midaz'). Use '_global' for global patterns", true),
 			"timeframe": map[string]interface{}{
@@ -256,17 +507,28 @@ func (ms *MemoryServer) registerLegacyTools() {
 				"description": "Time period to analyze",
 				"default":     types.TimeframeMonth,
 			},
+			"min_frequency": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     2,
+				"default":     2,
+				"description": "How many times a sequence must recur to be a pattern",
+			},
+			"pattern_type": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{string(intelligence.PatternTypeErrorResolution), string(intelligence.PatternTypeWorkflow)},
+				"description": "Only return error→fix (error_resolution) or tool chain (workflow) patterns",
+			},
 		}, []string{"repository"}),
 	), mcp.ToolHandlerFunc(ms.handleGetPatterns))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_health",
 		"Check the health status of the memory system",
 		mcp.ObjectSchema("Health check parameters", map[string]interface{}{}, []string{}),
 	), mcp.ToolHandlerFunc(ms.handleHealth))
 
 	// Phase 3.2: Advanced MCP Tools
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_suggest_related",
 		"Get AI-powered suggestions for related context based on current work",
 		mcp.ObjectSchema("Suggest related parameters", map[string]interface{}{
@@ -284,7 +546,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		}, []string{"current_context", "session_id"}),
 	), mcp.ToolHandlerFunc(ms.handleSuggestRelated))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_export_project",
 		"Export all memory data for a project in various formats",
 		mcp.ObjectSchema("Export project parameters", map[string]interface{}{
@@ -304,7 +566,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		}, []string{"repository", "session_id"}),
 	), mcp.ToolHandlerFunc(ms.handleExportProject))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_import_context",
 		"Import conversation context from external source",
 		mcp.ObjectSchema("Import context parameters", map[string]interface{}{
@@ -314,7 +576,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 				"enum":        []string{types.SourceConversation, "file", "archive"},
 				"default":     types.SourceConversation,
 			},
-			"data":       mcp.StringParam("Data to import (conversation text, file content, or base64 archive)", true),
+			"data":       mcp.StringParam("Data to import (conversation text, file content, or an export_project archive, base64 or JSON)", true),
 			"repository": mcp.StringParam("Official repository name for imported data (e.g., 'github.com/lerianstudio/midaz'). Use '_global' for global memories", true),
 			"metadata": mcp.ObjectSchema("Import metadata", map[string]interface{}{
 				"source_system": mcp.StringParam("Name of the source system", false),
@@ -327,12 +589,13 @@ func (ms *MemoryServer) registerLegacyTools() {
 				"enum":        []string{"auto", "paragraph", "fixed_size", "conversation_turns"},
 				"default":     "auto",
 			},
-			"session_id": mcp.StringParam("Session identifier", true),
+			"skip_invalid": mcp.BooleanParam("For archives: import what passes the referential integrity checks and report the rest instead of rejecting the archive", false),
+			"session_id":   mcp.StringParam("Session identifier", true),
 		}, []string{"source", "data", "repository", "session_id"}),
 	), mcp.ToolHandlerFunc(ms.handleImportContext))
 
 	// Quick Memory Actions - Convenience tools for common workflow queries
-	ms.mcpServer.AddTool(
+	ms.addTool(
 		mcp.NewTool("mcp__memory__memory_status",
 			"Get comprehensive status overview of memory system for a repository",
 			mcp.ObjectSchema("Memory status parameters", map[string]interface{}{
@@ -340,7 +603,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 			}, []string{"repository"}),
 		), mcp.ToolHandlerFunc(ms.handleMemoryStatus))
 
-	ms.mcpServer.AddTool(
+	ms.addTool(
 		mcp.NewTool("mcp__memory__memory_conflicts",
 			"Detect contradictory decisions or patterns across memories",
 			mcp.ObjectSchema("Memory conflicts parameters", map[string]interface{}{
@@ -349,7 +612,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 			}, []string{}),
 		), mcp.ToolHandlerFunc(ms.handleMemoryConflicts))
 
-	ms.mcpServer.AddTool(
+	ms.addTool(
 		mcp.NewTool("mcp__memory__memory_resolve_conflicts",
 			"Get detailed resolution strategies and recommendations for specific conflicts. Use after detecting conflicts to get actionable next steps.",
 			mcp.ObjectSchema("Memory conflict resolution parameters", map[string]interface{}{
@@ -374,7 +637,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 			}, []string{"conflict_ids"}),
 		), mcp.ToolHandlerFunc(ms.handleMemoryResolveConflicts))
 
-	ms.mcpServer.AddTool(
+	ms.addTool(
 		mcp.NewTool("mcp__memory__memory_continuity",
 			"Show what was left incomplete from previous sessions for resuming work",
 			mcp.ObjectSchema("Memory continuity parameters", map[string]interface{}{
@@ -385,7 +648,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		), mcp.ToolHandlerFunc(ms.handleMemoryContinuity))
 
 	// Memory Threading Tools
-	ms.mcpServer.AddTool(
+	ms.addTool(
 		mcp.NewTool("mcp__memory__memory_create_thread",
 			"Create a memory thread from related chunks to group coherent conversations",
 			mcp.ObjectSchema("Memory thread creation parameters", map[string]interface{}{
@@ -398,7 +661,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 			}, []string{"chunk_ids"}),
 		), mcp.ToolHandlerFunc(ms.handleCreateThread))
 
-	ms.mcpServer.AddTool(
+	ms.addTool(
 		mcp.NewTool("mcp__memory__memory_get_threads",
 			"Retrieve memory threads with optional filtering",
 			mcp.ObjectSchema("Memory thread retrieval parameters", map[string]interface{}{
@@ -410,7 +673,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 			}, []string{}),
 		), mcp.ToolHandlerFunc(ms.handleGetThreads))
 
-	ms.mcpServer.AddTool(
+	ms.addTool(
 		mcp.NewTool("mcp__memory__memory_detect_threads",
 			"Automatically detect and create memory threads from existing chunks",
 			mcp.ObjectSchema("Memory thread detection parameters", map[string]interface{}{
@@ -426,7 +689,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 			}, []string{"repository"}),
 		), mcp.ToolHandlerFunc(ms.handleDetectThreads))
 
-	ms.mcpServer.AddTool(
+	ms.addTool(
 		mcp.NewTool("mcp__memory__memory_update_thread",
 			"Update memory thread properties like status, title, or add/remove chunks",
 			mcp.ObjectSchema("Memory thread update parameters", map[string]interface{}{
@@ -443,7 +706,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		), mcp.ToolHandlerFunc(ms.handleUpdateThread))
 
 	// Cross-Project Pattern Detection Tools
-	ms.mcpServer.AddTool(
+	ms.addTool(
 		mcp.NewTool("mcp__memory__memory_analyze_cross_repo_patterns",
 			"Analyze patterns that appear across multiple repositories to identify shared solutions, common problems, and best practices",
 			mcp.ObjectSchema("Cross-repository pattern analysis parameters", map[string]interface{}{
@@ -467,7 +730,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 			}, []string{"session_id"}),
 		), mcp.ToolHandlerFunc(ms.handleAnalyzeCrossRepoPatterns))
 
-	ms.mcpServer.AddTool(
+	ms.addTool(
 		mcp.NewTool("mcp__memory__memory_find_similar_repositories",
 			"Find repositories with similar technology stacks, patterns, or problem domains for knowledge transfer and best practice sharing",
 			mcp.ObjectSchema("Similar repository discovery parameters", map[string]interface{}{
@@ -490,7 +753,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 			}, []string{"repository", "session_id"}),
 		), mcp.ToolHandlerFunc(ms.handleFindSimilarRepositories))
 
-	ms.mcpServer.AddTool(
+	ms.addTool(
 		mcp.NewTool("mcp__memory__memory_get_cross_repo_insights",
 			"Get comprehensive insights across all repositories including technology distribution, success rates, and common patterns",
 			mcp.ObjectSchema("Cross-repository insights parameters", map[string]interface{}{
@@ -501,7 +764,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 			}, []string{"session_id"}),
 		), mcp.ToolHandlerFunc(ms.handleGetCrossRepoInsights))
 
-	ms.mcpServer.AddTool(
+	ms.addTool(
 		mcp.NewTool("mcp__memory__memory_search_multi_repo",
 			"Search for patterns, solutions, or insights across multiple repositories with advanced filtering and ranking",
 			mcp.ObjectSchema("Multi-repository search parameters", map[string]interface{}{
@@ -538,7 +801,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		), mcp.ToolHandlerFunc(ms.handleSearchMultiRepo))
 
 	// Memory Health Dashboard Tool
-	ms.mcpServer.AddTool(
+	ms.addTool(
 		mcp.NewTool("mcp__memory__memory_health_dashboard",
 			"Get comprehensive memory system health overview including completion rates, outdated chunks, effectiveness scores, and system performance metrics",
 			mcp.ObjectSchema("Memory health dashboard parameters", map[string]interface{}{
@@ -556,7 +819,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		), mcp.ToolHandlerFunc(ms.handleMemoryHealthDashboard))
 
 	// Memory decay management tool
-	ms.mcpServer.AddTool(
+	ms.addTool(
 		mcp.NewTool("mcp__memory__memory_decay_management",
 			"Manage memory decay process with intelligent LLM-based summarization and archival",
 			mcp.ObjectSchema("Memory decay management parameters", map[string]interface{}{
@@ -604,7 +867,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 
 	// Relationship management tools
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_link",
 		"Create a relationship between two memory chunks. Use to explicitly connect related problems, solutions, decisions, or learnings.",
 		mcp.ObjectSchema("Link memory parameters", map[string]interface{}{
@@ -629,7 +892,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		}, []string{"source_chunk_id", "target_chunk_id", "relation_type"}),
 	), mcp.ToolHandlerFunc(ms.handleMemoryLink))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_get_relationships",
 		"Get relationships for a memory chunk. Use to understand how memories connect and find related context.",
 		mcp.ObjectSchema("Get relationships parameters", map[string]interface{}{
@@ -670,7 +933,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		}, []string{"chunk_id"}),
 	), mcp.ToolHandlerFunc(ms.handleGetRelationships))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_traverse_graph",
 		"Traverse the knowledge graph to discover connected memories and reasoning chains. Use to understand how decisions and solutions relate.",
 		mcp.ObjectSchema("Graph traversal parameters", map[string]interface{}{
@@ -693,7 +956,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		}, []string{"start_chunk_id"}),
 	), mcp.ToolHandlerFunc(ms.handleTraverseGraph))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_auto_detect_relationships",
 		"Automatically detect relationships for a memory chunk based on content, timing, and patterns. Use after storing important memories.",
 		mcp.ObjectSchema("Auto-detect relationships parameters", map[string]interface{}{
@@ -718,7 +981,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		}, []string{"chunk_id", "session_id"}),
 	), mcp.ToolHandlerFunc(ms.handleAutoDetectRelationships))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_update_relationship",
 		"Update the confidence score and metadata of an existing relationship. Use when you learn more about how memories relate.",
 		mcp.ObjectSchema("Update relationship parameters", map[string]interface{}{
@@ -739,7 +1002,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		}, []string{"relationship_id"}),
 	), mcp.ToolHandlerFunc(ms.handleUpdateRelationship))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_search_explained",
 		"Search memories with detailed explanations of relevance, ranking factors, and citations. Use when you need to understand why results were returned.",
 		mcp.ObjectSchema("Explained search parameters", map[string]interface{}{
@@ -778,7 +1041,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		}, []string{"query"}),
 	), mcp.ToolHandlerFunc(ms.handleSearchExplained))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_check_freshness",
 		"Check the freshness and staleness of memories. Use to identify outdated content that needs refreshing or archiving.",
 		mcp.ObjectSchema("Freshness check parameters", map[string]interface{}{
@@ -798,7 +1061,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		}, []string{"repository"}),
 	), mcp.ToolHandlerFunc(ms.handleCheckFreshness))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_mark_refreshed",
 		"Mark a memory as recently refreshed/validated. Use after updating or verifying that content is still current.",
 		mcp.ObjectSchema("Mark refreshed parameters", map[string]interface{}{
@@ -813,7 +1076,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 	), mcp.ToolHandlerFunc(ms.handleMarkRefreshed))
 
 	// Citation management tools
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_generate_citations",
 		"Generate formatted citations for search results or specific memory chunks. Use when you need to provide proper attribution for information used in responses.",
 		mcp.ObjectSchema("Generate citations parameters", map[string]interface{}{
@@ -826,7 +1089,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 			"citation_style": map[string]interface{}{
 				"type":        "string",
 				"description": "Citation format style",
-				"enum":        []string{"simple", "apa", "mla", "chicago"},
+				"enum":        []string{"simple", "apa", "mla", "chicago", "provenance"},
 				"default":     "simple",
 			},
 			"group_sources": map[string]interface{}{
@@ -842,7 +1105,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		}, []string{"query", "chunk_ids"}),
 	), mcp.ToolHandlerFunc(ms.handleGenerateCitations))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_create_inline_citation",
 		"Create inline citation references for specific text portions. Use to add citation markers within AI responses.",
 		mcp.ObjectSchema("Create inline citation parameters", map[string]interface{}{
@@ -858,7 +1121,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 	), mcp.ToolHandlerFunc(ms.handleCreateInlineCitation))
 
 	// Bulk operations tools
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_bulk_operation",
 		"Execute bulk operations on multiple memories efficiently with progress tracking and error handling.",
 		mcp.ObjectSchema("Bulk operation parameters", map[string]interface{}{
@@ -911,7 +1174,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		}, []string{"operation"}),
 	), mcp.ToolHandlerFunc(ms.handleBulkOperation))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_bulk_import",
 		"Import memories from various formats (JSON, markdown, CSV) with flexible chunking strategies.",
 		mcp.ObjectSchema("Bulk import parameters", map[string]interface{}{
@@ -952,7 +1215,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		}, []string{"data"}),
 	), mcp.ToolHandlerFunc(ms.handleBulkImport))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_bulk_export",
 		"Export memories to various formats with filtering, compression, and formatting options.",
 		mcp.ObjectSchema("Bulk export parameters", map[string]interface{}{
@@ -1029,7 +1292,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		}, []string{}),
 	), mcp.ToolHandlerFunc(ms.handleBulkExport))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_create_alias",
 		"Create memory aliases for flexible referencing using tags, shortcuts, queries, or collections.",
 		mcp.ObjectSchema("Create alias parameters", map[string]interface{}{
@@ -1087,7 +1350,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		}, []string{"name", "type", "target"}),
 	), mcp.ToolHandlerFunc(ms.handleCreateAlias))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_resolve_alias",
 		"Resolve an alias reference to get the matching memory chunks.",
 		mcp.ObjectSchema("Resolve alias parameters", map[string]interface{}{
@@ -1095,7 +1358,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		}, []string{"alias_name"}),
 	), mcp.ToolHandlerFunc(ms.handleResolveAlias))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_list_aliases",
 		"List memory aliases with optional filtering and sorting.",
 		mcp.ObjectSchema("List aliases parameters", map[string]interface{}{
@@ -1123,7 +1386,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		}, []string{}),
 	), mcp.ToolHandlerFunc(ms.handleListAliases))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_get_bulk_progress",
 		"Get the progress status of a bulk operation.",
 		mcp.ObjectSchema("Get bulk progress parameters", map[string]interface{}{
@@ -1132,7 +1395,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 	), mcp.ToolHandlerFunc(ms.handleGetBulkProgress))
 
 	// Task-oriented Memory Tools
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_create_task",
 		"Create task-oriented memory chunks for tracking work items, TODOs, and project tasks separately from general memories.",
 		mcp.ObjectSchema("Create task parameters", map[string]interface{}{
@@ -1153,6 +1416,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 				"default":     "todo",
 			},
 			"assignee": mcp.StringParam("Person assigned to the task (optional)", false),
+			"creator":  mcp.StringParam("Person who created the task, e.g. 'Jane Doe <jane@example.com>' (optional)", false),
 			"due_date": mcp.StringParam("Due date in ISO 8601 format (optional)", false),
 			"estimate": map[string]interface{}{
 				"type":        "integer",
@@ -1164,7 +1428,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		}, []string{"title", "description", "session_id"}),
 	), mcp.ToolHandlerFunc(ms.handleCreateTask))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_get_task_status",
 		"Retrieve task status, progress, and details for specific tasks or filtered task lists.",
 		mcp.ObjectSchema("Get task status parameters", map[string]interface{}{
@@ -1194,7 +1458,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		}, []string{}),
 	), mcp.ToolHandlerFunc(ms.handleGetTaskStatus))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_update_task",
 		"Update task status, progress, or other task properties. Use for marking progress, changing status, or updating details.",
 		mcp.ObjectSchema("Update task parameters", map[string]interface{}{
@@ -1231,7 +1495,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		}, []string{"task_id", "session_id"}),
 	), mcp.ToolHandlerFunc(ms.handleUpdateTask))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_list_tasks",
 		"List and filter tasks with various criteria. Useful for dashboards, reports, and task management views.",
 		mcp.ObjectSchema("List tasks parameters", map[string]interface{}{
@@ -1272,7 +1536,7 @@ func (ms *MemoryServer) registerLegacyTools() {
 		}, []string{}),
 	), mcp.ToolHandlerFunc(ms.handleListTasks))
 
-	ms.mcpServer.AddTool(mcp.NewTool(
+	ms.addTool(mcp.NewTool(
 		"mcp__memory__memory_complete_task",
 		"Mark a task as completed with outcome summary and automatically update related dependencies.",
 		mcp.ObjectSchema("Complete task parameters", map[string]interface{}{
@@ -1317,7 +1581,7 @@ func (ms *MemoryServer) registerResources() {
 		{
 			uri:         "memory://patterns/{repository}",
 			name:        "Common Patterns",
-			description: "Identified patterns in project history",
+			description: "Recurring error→fix pairs and tool chains detected in project history",
 			mimeType:    "application/json",
 		},
 		{
@@ -1332,11 +1596,30 @@ func (ms *MemoryServer) registerResources() {
 			description: "Cross-project insights and patterns",
 			mimeType:    "application/json",
 		},
+		{
+			uri:         "memory://capabilities",
+			name:        "Server Capabilities",
+			description: "Features available in the running configuration (full or lite mode)",
+			mimeType:    "application/json",
+		},
+		{
+			uri:         "memory://session/{session_id}/working-set",
+			name:        "Session Working Set",
+			description: "Chunks stored, retrieved or linked in a session, most recent first, to re-establish context after a prompt reset",
+			mimeType:    "application/json",
+		},
+		{
+			uri:         "tasks://board/{project}",
+			name:        "Task Board",
+			description: "Kanban board of project tasks grouped by status",
+			mimeType:    "application/json",
+		},
 	}
 
 	for _, res := range resources {
 		resource := mcp.NewResource(res.uri, res.name, res.description, res.mimeType)
 		ms.mcpServer.AddResource(resource, mcp.ResourceHandlerFunc(ms.handleResourceRead))
+		ms.addResourceTemplate(res.uri)
 	}
 }
 
@@ -1348,13 +1631,24 @@ func (ms *MemoryServer) validateStoreChunkParams(params map[string]interface{})
 	content, ok = params["content"].(string)
 	if !ok || content == "" {
 		logging.Error("memory_store_chunk failed: missing content parameter")
-		return "", "", errors.New("content parameter is required and must be non-empty string. Example: {\"content\": \"Fixed authentication bug by updating JWT validation\", \"session_id\": \"auth-fix-session\"}")
+		return "", "", NewToolError(ToolErrorMissingParameter, "content parameter is required and must be non-empty string").
+			WithParameter("content").
+			WithExample(`{"content": "Fixed authentication bug by updating JWT validation", "session_id": "auth-fix-session"}`)
 	}
 
 	sessionID, ok = params["session_id"].(string)
 	if !ok || sessionID == "" {
 		logging.Error("memory_store_chunk failed: missing session_id parameter")
-		return "", "", errors.New("session_id parameter is required and must be non-empty string. Use descriptive session IDs. Example: {\"session_id\": \"bug-fix-2024\", \"content\": \"Solution details\"}")
+		return "", "", NewToolError(ToolErrorMissingParameter, "session_id parameter is required and must be non-empty string").
+			WithParameter("session_id").
+			WithSuggestion("Use descriptive session IDs").
+			WithExample(`{"session_id": "bug-fix-2024", "content": "Solution details"}`)
+	}
+
+	if class, ok := params["memory_class"].(string); ok && class != "" && !types.MemoryClass(class).Valid() {
+		return "", "", NewToolError(ToolErrorInvalidParameter, fmt.Sprintf("invalid memory_class %q", class)).
+			WithParameter("memory_class").
+			WithSuggestion("Use episodic, semantic or procedural")
 	}
 
 	return content, sessionID, nil
@@ -1451,6 +1745,7 @@ func (ms *MemoryServer) handleStoreChunk(ctx context.Context, params map[string]
 
 	// Build metadata from parameters
 	metadata := ms.buildMetadataFromParams(params)
+	tagsResolved, tagSuggestions := ms.reconcileTags(&metadata)
 
 	// Create repository-scoped session ID for multi-tenant isolation
 	repositoryScopedSessionID := ms.createRepositoryScopedSessionID(metadata.Repository, sessionID)
@@ -1460,6 +1755,7 @@ func (ms *MemoryServer) handleStoreChunk(ctx context.Context, params map[string]
 	if err := ms.addContextMetadata(&metadata, params); err != nil {
 		logging.Warn("Failed to add context metadata", "error", err)
 	}
+	ctx = ms.attributeAuthor(ctx, &metadata)
 
 	// Create and store chunk with repository-scoped session ID
 	logging.Info("Creating conversation chunk", "session_id", repositoryScopedSessionID)
@@ -1473,6 +1769,10 @@ func (ms *MemoryServer) handleStoreChunk(ctx context.Context, params map[string]
 	// Process parent-child relationship if specified
 	ms.processParentChildRelationship(ctx, chunk, &metadata)
 
+	// Score the new memory so search can rank it; memory_quality_report
+	// rescores it later as it ages and gets cited
+	chunk.Metadata.Quality = intelligence.AnalyzeQuality(chunk, 0, time.Now())
+
 	logging.Info("Storing chunk in vector store", "chunk_id", chunk.ID)
 	startTime := time.Now()
 
@@ -1490,13 +1790,39 @@ func (ms *MemoryServer) handleStoreChunk(ctx context.Context, params map[string]
 	// Auto-detect relationships with recent chunks
 	ms.autoDetectRelationships(ctx, chunk)
 
+	ms.recordWorkingSet(sessionID, chunk.Metadata.Repository, workingSetStored, chunk.ID)
+
+	response := map[string]interface{}{
+		"chunk_id":     chunk.ID,
+		"type":         string(chunk.Type),
+		"memory_class": chunk.Class(),
+		"summary":      chunk.Summary,
+		"stored_at":    chunk.Timestamp.Format(time.RFC3339),
+	}
+
+	// With write batching the chunk is durable but searchable only after the next flush
+	if ms.container.GetIngestionQueue() != nil {
+		response["queued"] = true
+	}
+	addTagReconciliation(response, tagsResolved, tagSuggestions)
+
+	// Promote decision statements into linked decision records
+	if ms.decisionExtractionEnabled() {
+		records, err := ms.promoteDecisions(ctx, chunk)
+		if err != nil {
+			logging.Warn("Decision extraction failed", "chunk_id", chunk.ID, "error", err)
+		}
+		if len(records) > 0 {
+			decisionIDs := make([]string, 0, len(records))
+			for _, record := range records {
+				decisionIDs = append(decisionIDs, record.ID)
+			}
+			response["extracted_decisions"] = decisionIDs
+		}
+	}
+
 	logging.Info("memory_store_chunk completed successfully", "chunk_id", chunk.ID, "session_id", sessionID)
-	return map[string]interface{}{
-		"chunk_id":  chunk.ID,
-		"type":      string(chunk.Type),
-		"summary":   chunk.Summary,
-		"stored_at": chunk.Timestamp.Format(time.RFC3339),
-	}, nil
+	return response, nil
 }
 
 // validateSearchParams validates required parameters for search
@@ -1506,6 +1832,9 @@ func (ms *MemoryServer) validateSearchParams(params map[string]interface{}) (str
 		logging.Error("memory_search failed: missing query parameter")
 		return "", errors.New("query parameter is required and must be non-empty string. Use specific search terms. Example: {\"query\": \"authentication bug fix\", \"repository\": \"github.com/user/repo\"}")
 	}
+	if _, err := memoryClassesFromParams(params); err != nil {
+		return "", err
+	}
 	return query, nil
 }
 
@@ -1537,6 +1866,10 @@ func (ms *MemoryServer) buildMemoryQueryFromParams(query string, params map[stri
 		}
 	}
 
+	// Checked by validateSearchParams
+	memQuery.Classes, _ = memoryClassesFromParams(params)
+	memQuery.IncludeArchived, _ = params["include_archived"].(bool)
+
 	return memQuery
 }
 
@@ -1600,14 +1933,15 @@ func (ms *MemoryServer) formatSearchResults(ctx context.Context, query string, r
 // buildResultMap builds a result map for a single search result
 func (ms *MemoryServer) buildResultMap(result *types.SearchResult, relMgr *relationships.Manager) map[string]interface{} {
 	resultMap := map[string]interface{}{
-		"chunk_id":   result.Chunk.ID,
-		"score":      result.Score,
-		"type":       string(result.Chunk.Type),
-		"summary":    result.Chunk.Summary,
-		"repository": result.Chunk.Metadata.Repository,
-		"timestamp":  result.Chunk.Timestamp.Format(time.RFC3339),
-		"tags":       result.Chunk.Metadata.Tags,
-		"outcome":    string(result.Chunk.Metadata.Outcome),
+		"chunk_id":     result.Chunk.ID,
+		"score":        result.Score,
+		"type":         string(result.Chunk.Type),
+		"memory_class": string(result.Chunk.Class()),
+		"summary":      result.Chunk.Summary,
+		"repository":   result.Chunk.Metadata.Repository,
+		"timestamp":    result.Chunk.Timestamp.Format(time.RFC3339),
+		"tags":         result.Chunk.Metadata.Tags,
+		"outcome":      string(result.Chunk.Metadata.Outcome),
 	}
 
 	// Add relationship information
@@ -1654,10 +1988,14 @@ func (ms *MemoryServer) handleSearch(ctx context.Context, params map[string]inte
 
 	// Build memory query from parameters
 	memQuery := ms.buildMemoryQueryFromParams(query, params)
+	memQuery.Mode, err = ms.searchModeFromParams(params)
+	if err != nil {
+		return nil, err
+	}
 
 	// Generate embeddings for query
-	logging.Info("Generating embeddings for search query", "query", query)
-	embeddings, err := ms.container.GetEmbeddingService().GenerateEmbedding(ctx, query)
+	logging.Info("Generating embeddings for search query", "query", query, "mode", memQuery.Mode)
+	embeddings, err := ms.searchEmbeddings(ctx, query, memQuery.Mode)
 	if err != nil {
 		logging.Error("Failed to generate embeddings", "error", err, "query", query)
 		return nil, fmt.Errorf("failed to generate query embeddings: %w", err)
@@ -1666,95 +2004,43 @@ func (ms *MemoryServer) handleSearch(ctx context.Context, params map[string]inte
 
 	// Execute progressive search with relaxation strategy
 	searchStart := time.Now()
-	results, err := ms.executeProgressiveSearch(ctx, memQuery, embeddings)
+	results, outcome, err := ms.executeProgressiveSearch(ctx, memQuery, embeddings, false)
 	if err != nil {
 		logging.Error("Progressive search failed", "error", err, "query", query)
 		ms.logSearchAudit(ctx, query, memQuery, nil, searchStart, err)
 		return nil, fmt.Errorf("search failed: %w", err)
 	}
-	logging.Info("Progressive search completed", "total_results", results.Total, "query_time", results.QueryTime)
+	logging.Info("Progressive search completed", "total_results", results.Total, "query_time", results.QueryTime, "satisfied_step", outcome.SatisfiedStep)
+
+	// Favor verified solutions over failed ones, distilled memories over
+	// session logs and high-quality memories over low-quality ones, then
+	// re-rank with the repository's scoring profile, if it has one
+	scoring.RankByOutcome(results.Results)
+	scoring.RankByClass(results.Results)
+	scoring.RankByQuality(results.Results)
+	profileName := ms.applyScoringProfile(memQuery, results)
 
 	// Log successful search audit event
 	ms.logSearchAudit(ctx, query, memQuery, results, searchStart, nil)
 
 	// Format results for response
 	response := ms.formatSearchResults(ctx, query, results)
-
-	logging.Info("memory_search completed successfully", "total_results", results.Total, "query", query)
-	return response, nil
-}
-
-// executeProgressiveSearch implements a fallback strategy for searches
-// Tries progressively looser search criteria if initial search returns no results
-func (ms *MemoryServer) executeProgressiveSearch(ctx context.Context, query *types.MemoryQuery, embeddings []float64) (*types.SearchResults, error) {
-	searchConfig := ms.container.Config.Search
-
-	// If progressive search is disabled, just do a single search
-	if !searchConfig.EnableProgressiveSearch {
-		return ms.container.GetVectorStore().Search(ctx, query, embeddings)
+	response["satisfied_step"] = outcome.SatisfiedStep
+	response["search_plan"] = outcome
+	response["search_mode"] = memQuery.Mode
+	if profileName != "" {
+		response["scoring_profile"] = profileName
 	}
-
-	// Step 1: Try original query (strict search)
-	logging.Info("Progressive search: Step 1 - Strict search", "repo", query.Repository, "min_relevance", query.MinRelevanceScore)
-	results, err := ms.container.GetVectorStore().Search(ctx, query, embeddings)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(results.Results) > 0 {
-		logging.Info("Progressive search: Strict search succeeded", "results", len(results.Results))
-		return results, nil
-	}
-
-	// Step 2: Relax relevance score (loose search)
-	relaxedQuery := *query // Copy the query
-	relaxedQuery.MinRelevanceScore = searchConfig.RelaxedMinRelevance
-	logging.Info("Progressive search: Step 2 - Relaxed relevance", "min_relevance", relaxedQuery.MinRelevanceScore)
-	results, err = ms.container.GetVectorStore().Search(ctx, &relaxedQuery, embeddings)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(results.Results) > 0 {
-		logging.Info("Progressive search: Relaxed search succeeded", "results", len(results.Results))
-		return results, nil
-	}
-
-	// Step 3: Try related repositories if original repo specified
-	if query.Repository != nil && searchConfig.EnableRepositoryFallback {
-		results, err := ms.searchRelatedRepositories(ctx, &relaxedQuery, embeddings, *query.Repository, searchConfig)
-		if err == nil && len(results.Results) > 0 {
-			return results, nil
-		}
-
-		// Step 3b: Complete repository fallback (remove filter)
-		repoFallbackQuery := relaxedQuery
-		repoFallbackQuery.Repository = nil
-		logging.Info("Progressive search: Step 3b - Complete repository fallback", "original_repo", *query.Repository)
-		results, err = ms.container.GetVectorStore().Search(ctx, &repoFallbackQuery, embeddings)
-		if err != nil {
-			return nil, err
-		}
-
-		if len(results.Results) > 0 {
-			logging.Info("Progressive search: Complete repository fallback succeeded", "results", len(results.Results))
-			return results, nil
+	if expansion, ok := parseSearchExpansion(params); ok {
+		repository := ""
+		if memQuery.Repository != nil {
+			repository = *memQuery.Repository
 		}
+		response["expanded_results"] = ms.expandSearchResults(ctx, results.Results, repository, expansion)
 	}
 
-	// Step 4: Broadest search - remove type filters too
-	broadQuery := *query // Copy the query
-	broadQuery.MinRelevanceScore = searchConfig.BroadestMinRelevance
-	broadQuery.Repository = nil
-	broadQuery.Types = nil
-	logging.Info("Progressive search: Step 4 - Broadest search", "min_relevance", broadQuery.MinRelevanceScore)
-	results, err = ms.container.GetVectorStore().Search(ctx, &broadQuery, embeddings)
-	if err != nil {
-		return nil, err
-	}
-
-	logging.Info("Progressive search completed", "final_results", len(results.Results), "strategy", "broadest")
-	return results, nil
+	logging.Info("memory_search completed successfully", "total_results", results.Total, "query", query)
+	return response, nil
 }
 
 // generateRelatedRepositories creates variations of a repository name for fallback searches
@@ -2258,6 +2544,11 @@ func (ms *MemoryServer) handleStoreDecision(ctx context.Context, params map[stri
 	if err != nil {
 		return nil, err
 	}
+	ms.recordWorkingSet(sessionID, chunk.Metadata.Repository, workingSetStored, chunk.ID)
+
+	event := chunkEvent(digest.EventDecision, chunk, map[string]interface{}{"rationale": rationale})
+	event.Title = decision
+	ms.publishEvent(event)
 
 	return map[string]interface{}{
 		"chunk_id":  chunk.ID,
@@ -2374,41 +2665,6 @@ func (ms *MemoryServer) createDecisionChunk(ctx context.Context, sessionID, cont
 	return chunk, nil
 }
 
-func (ms *MemoryServer) handleGetPatterns(ctx context.Context, params map[string]interface{}) (interface{}, error) {
-	logging.Info("MCP TOOL: memory_get_patterns called", "params", params)
-
-	repository, ok := params["repository"].(string)
-	if !ok || repository == "" {
-		logging.Error("memory_get_patterns failed: missing repository parameter")
-		return nil, errors.New("repository is required")
-	}
-
-	timeframe := types.TimeframeMonth
-	if tf, ok := params["timeframe"].(string); ok {
-		timeframe = tf
-	}
-
-	// Get chunks based on timeframe
-	var chunks []types.ConversationChunk
-	var err error
-
-	// For now, get recent chunks - in a full implementation,
-	// this would have proper time filtering
-	chunks, err = ms.container.GetVectorStore().ListByRepository(ctx, repository, 100, 0)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get chunks: %w", err)
-	}
-
-	patterns := ms.analyzePatterns(chunks)
-
-	return map[string]interface{}{
-		"repository":            repository,
-		"timeframe":             timeframe,
-		"patterns":              patterns,
-		"total_chunks_analyzed": len(chunks),
-	}, nil
-}
-
 func (ms *MemoryServer) handleHealth(ctx context.Context, _ map[string]interface{}) (interface{}, error) {
 	logging.Info("MCP TOOL: memory_health called")
 
@@ -2436,7 +2692,11 @@ func (ms *MemoryServer) handleHealth(ctx context.Context, _ map[string]interface
 
 	// Check embedding service
 	logging.Info("Checking embedding service health")
-	if err := ms.container.GetEmbeddingService().HealthCheck(ctx); err != nil {
+	if !embeddings.IsEnabled(ms.container.GetEmbeddingService()) {
+		health["services"].(map[string]interface{})["embedding_service"] = map[string]interface{}{
+			"status": "disabled",
+		}
+	} else if err := ms.container.GetEmbeddingService().HealthCheck(ctx); err != nil {
 		logging.Error("Embedding service health check failed", "error", err)
 		health["services"].(map[string]interface{})["embedding_service"] = map[string]interface{}{
 			"status": "unhealthy",
@@ -2450,6 +2710,18 @@ func (ms *MemoryServer) handleHealth(ctx context.Context, _ map[string]interface
 		}
 	}
 
+	health["capabilities"] = ms.featureCapabilities()
+
+	if queue := ms.container.GetIngestionQueue(); queue != nil {
+		health["ingestion"] = queue.Stats()
+	}
+	if router := ms.container.GetReplicaRouter(); router != nil {
+		health["read_replicas"] = router.Stats()
+	}
+	if ms.httpTools != nil {
+		health["http_tools"] = ms.httpTools.Status()
+	}
+
 	// Get statistics
 	if stats, err := ms.container.GetVectorStore().GetStats(ctx); err == nil {
 		health["stats"] = stats
@@ -2465,6 +2737,13 @@ func (ms *MemoryServer) handleHealth(ctx context.Context, _ map[string]interface
 // Resource handler
 
 func (ms *MemoryServer) handleResourceRead(ctx context.Context, uri string) ([]protocol.Content, error) {
+	if strings.HasPrefix(uri, taskBoardURIPrefix) {
+		return ms.handleTaskBoardResource(ctx, uri)
+	}
+	if strings.HasPrefix(uri, workingSetURIPrefix) {
+		return ms.handleWorkingSetResource(ctx, uri)
+	}
+
 	parts := strings.Split(uri, "/")
 	if len(parts) < 3 {
 		return nil, fmt.Errorf("invalid resource URI: %s", uri)
@@ -2481,6 +2760,8 @@ func (ms *MemoryServer) handleResourceRead(ctx context.Context, uri string) ([]p
 		return ms.handleDecisionsResource(ctx, parts)
 	case GlobalRepository:
 		return ms.handleGlobalResource(parts)
+	case "capabilities":
+		return ms.handleCapabilitiesResource()
 	default:
 		return nil, fmt.Errorf("unknown resource type: %s", resourceType)
 	}
@@ -2491,7 +2772,7 @@ func (ms *MemoryServer) handleRecentResource(ctx context.Context, parts []string
 	if len(parts) < 4 {
 		return nil, errors.New("repository required for recent resource")
 	}
-	repository := parts[3]
+	repository := strings.Join(parts[3:], "/")
 	chunks, err := ms.container.GetVectorStore().ListByRepository(ctx, repository, 20, 0)
 	if err != nil {
 		return nil, err
@@ -2506,14 +2787,13 @@ func (ms *MemoryServer) handlePatternsResource(ctx context.Context, parts []stri
 		return nil, errors.New("repository required for patterns resource")
 	}
 	repository := parts[3]
-	chunks, err := ms.container.GetVectorStore().ListByRepository(ctx, repository, 100, 0)
+	detection, err := ms.detectPatterns(ctx, repository, time.Time{}, intelligence.DefaultDetectionOptions())
 	if err != nil {
 		return nil, err
 	}
-	patterns := ms.analyzePatterns(chunks)
 	result := map[string]interface{}{
 		"repository": repository,
-		"patterns":   patterns,
+		"patterns":   detection.patterns,
 	}
 	resultJSON, _ := json.Marshal(result)
 	return []protocol.Content{protocol.NewContent(string(resultJSON))}, nil
@@ -2894,6 +3174,9 @@ func (ms *MemoryServer) handleAutoInsights(ctx context.Context, params map[strin
 	recommendations := ms.generateInsightRecommendations(chunks)
 	insights["recommendations"] = recommendations
 
+	// Add an LLM-written narrative when an insights provider is configured
+	ms.addNarrativeInsights(ctx, chunks, insights)
+
 	logging.Info("Auto insights generated successfully", "insights_count", len(insights), "recommendations", len(recommendations))
 
 	return insights, nil
@@ -3465,21 +3748,22 @@ func (ms *MemoryServer) validateExportProjectParams(params map[string]interface{
 	}, nil
 }
 
-// getRepositoryDataForExport retrieves repository chunks with pagination info
-func (ms *MemoryServer) getRepositoryDataForExport(ctx context.Context, repository string, limit, offset int) ([]types.ConversationChunk, int, error) {
-	chunks, err := ms.container.GetVectorStore().ListByRepository(ctx, repository, limit, offset)
+// getRepositoryDataForExport retrieves a page of repository chunks, and all
+// of them for pagination info
+func (ms *MemoryServer) getRepositoryDataForExport(ctx context.Context, repository string, limit, offset int) (chunks, all []types.ConversationChunk, totalCount int, err error) {
+	chunks, err = ms.container.GetVectorStore().ListByRepository(ctx, repository, limit, offset)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to retrieve repository data: %w", err)
+		return nil, nil, 0, fmt.Errorf("failed to retrieve repository data: %w", err)
 	}
 
 	// Get total count for pagination info
-	totalChunks, err := ms.container.GetVectorStore().ListByRepository(ctx, repository, 10000, 0)
-	totalCount := len(totalChunks)
+	all, err = ms.container.GetVectorStore().ListByRepository(ctx, repository, 10000, 0)
+	totalCount = len(all)
 	if err != nil {
-		totalCount = -1 // Unknown if we can't fetch
+		all, totalCount = nil, -1 // Unknown if we can't fetch
 	}
 
-	return chunks, totalCount, nil
+	return chunks, all, totalCount, nil
 }
 
 // createPaginationInfo creates pagination metadata
@@ -3493,8 +3777,9 @@ func (ms *MemoryServer) createPaginationInfo(limit, offset, chunksInPage, totalC
 }
 
 // exportToJSON exports chunks as JSON format
-func (ms *MemoryServer) exportToJSON(chunks []types.ConversationChunk, exportParams *exportParams, totalCount int) (interface{}, error) {
+func (ms *MemoryServer) exportToJSON(chunks []types.ConversationChunk, graph *projectGraph, exportParams *exportParams, totalCount int) (interface{}, error) {
 	exportData := map[string]interface{}{
+		"format_version":  projectBundleVersion,
 		"repository":      exportParams.repository,
 		"export_date":     time.Now().Format(time.RFC3339),
 		"total_chunks":    totalCount,
@@ -3502,6 +3787,8 @@ func (ms *MemoryServer) exportToJSON(chunks []types.ConversationChunk, exportPar
 		"include_vectors": exportParams.includeVectors,
 		"pagination":      ms.createPaginationInfo(exportParams.limit, exportParams.offset, len(chunks), totalCount),
 		"chunks":          chunks,
+		"relationships":   graph.Relationships,
+		"relation_types":  graph.RelationTypes,
 	}
 
 	// Remove vector data if not requested
@@ -3517,14 +3804,17 @@ func (ms *MemoryServer) exportToJSON(chunks []types.ConversationChunk, exportPar
 	}
 
 	return map[string]interface{}{
-		"format":       "json",
-		"data":         string(exportJSON),
-		"size_bytes":   len(exportJSON),
-		"chunks":       len(chunks),
-		"total_chunks": totalCount,
-		"repository":   exportParams.repository,
-		"session_id":   exportParams.sessionID,
-		"pagination":   ms.createPaginationInfo(exportParams.limit, exportParams.offset, len(chunks), totalCount),
+		"format":         "json",
+		"data":           string(exportJSON),
+		"size_bytes":     len(exportJSON),
+		"chunks":         len(chunks),
+		"tasks":          countTasks(chunks),
+		"relationships":  len(graph.Relationships),
+		"relation_types": len(graph.RelationTypes),
+		"total_chunks":   totalCount,
+		"repository":     exportParams.repository,
+		"session_id":     exportParams.sessionID,
+		"pagination":     ms.createPaginationInfo(exportParams.limit, exportParams.offset, len(chunks), totalCount),
 	}, nil
 }
 
@@ -3565,7 +3855,7 @@ func (ms *MemoryServer) exportToMarkdown(chunks []types.ConversationChunk, expor
 }
 
 // exportToArchive exports chunks as compressed archive format
-func (ms *MemoryServer) exportToArchive(chunks []types.ConversationChunk, exportParams *exportParams) (interface{}, error) {
+func (ms *MemoryServer) exportToArchive(chunks []types.ConversationChunk, graph *projectGraph, exportParams *exportParams) (interface{}, error) {
 	// Use backup manager to create compressed archive
 	if ms.container.GetBackupManager() == nil {
 		return nil, errors.New("backup manager not available")
@@ -3573,9 +3863,12 @@ func (ms *MemoryServer) exportToArchive(chunks []types.ConversationChunk, export
 
 	// Create a filtered backup for this repository only
 	backupData := map[string]interface{}{
-		"repository":  exportParams.repository,
-		"export_date": time.Now().Format(time.RFC3339),
-		"chunks":      chunks,
+		"format_version": projectBundleVersion,
+		"repository":     exportParams.repository,
+		"export_date":    time.Now().Format(time.RFC3339),
+		"chunks":         chunks,
+		"relationships":  graph.Relationships,
+		"relation_types": graph.RelationTypes,
 		"metadata": map[string]interface{}{
 			"export_type": "project_export",
 			"session_id":  exportParams.sessionID,
@@ -3591,13 +3884,16 @@ func (ms *MemoryServer) exportToArchive(chunks []types.ConversationChunk, export
 	archiveB64 := base64.StdEncoding.EncodeToString(archiveJSON)
 
 	return map[string]interface{}{
-		"format":     "archive",
-		"data":       archiveB64,
-		"size_bytes": len(archiveJSON),
-		"chunks":     len(chunks),
-		"repository": exportParams.repository,
-		"session_id": exportParams.sessionID,
-		"encoding":   "base64",
+		"format":         "archive",
+		"data":           archiveB64,
+		"size_bytes":     len(archiveJSON),
+		"chunks":         len(chunks),
+		"tasks":          countTasks(chunks),
+		"relationships":  len(graph.Relationships),
+		"relation_types": len(graph.RelationTypes),
+		"repository":     exportParams.repository,
+		"session_id":     exportParams.sessionID,
+		"encoding":       "base64",
 	}, nil
 }
 
@@ -3612,19 +3908,24 @@ func (ms *MemoryServer) handleExportProject(ctx context.Context, params map[stri
 	}
 
 	// Get repository data with pagination
-	chunks, totalCount, err := ms.getRepositoryDataForExport(ctx, exportParams.repository, exportParams.limit, exportParams.offset)
+	chunks, all, totalCount, err := ms.getRepositoryDataForExport(ctx, exportParams.repository, exportParams.limit, exportParams.offset)
 	if err != nil {
 		return nil, err
 	}
 
 	// Export in the requested format
 	switch exportParams.format {
-	case "json":
-		return ms.exportToJSON(chunks, exportParams, totalCount)
+	case "json", "archive":
+		graph, err := ms.exportProjectGraph(ctx, exportParams.repository, chunks, all)
+		if err != nil {
+			return nil, err
+		}
+		if exportParams.format == "archive" {
+			return ms.exportToArchive(chunks, graph, exportParams)
+		}
+		return ms.exportToJSON(chunks, graph, exportParams, totalCount)
 	case "markdown":
 		return ms.exportToMarkdown(chunks, exportParams, totalCount)
-	case "archive":
-		return ms.exportToArchive(chunks, exportParams)
 	default:
 		return nil, fmt.Errorf("unsupported format: %s", exportParams.format)
 	}
@@ -3636,15 +3937,18 @@ func (ms *MemoryServer) handleImportContext(ctx context.Context, params map[stri
 	if err != nil {
 		return nil, err
 	}
+	if importParams.source == "archive" {
+		return ms.importProjectArchive(ctx, importParams)
+	}
 
 	importedChunks, err := ms.importChunksBySource(ctx, importParams)
 	if err != nil {
 		return nil, err
 	}
 
-	storedCount := ms.storeImportedChunks(ctx, importedChunks, importParams)
+	stored := ms.storeImportedChunks(ctx, importedChunks, importParams)
 
-	return ms.buildImportResponse(importParams, importedChunks, storedCount), nil
+	return ms.buildImportResponse(importParams, importedChunks, len(stored)), nil
 }
 
 // importParams holds parsed import parameters
@@ -3655,6 +3959,7 @@ type importParams struct {
 	sessionID        string
 	chunkingStrategy string
 	metadata         map[string]interface{}
+	skipInvalid      bool // Import an archive despite failed integrity checks, leaving out what fails
 }
 
 // parseImportParams extracts and validates import parameters
@@ -3689,6 +3994,8 @@ func (ms *MemoryServer) parseImportParams(params map[string]interface{}) (*impor
 		metadata = meta
 	}
 
+	skipInvalid, _ := params["skip_invalid"].(bool)
+
 	return &importParams{
 		source:           source,
 		data:             data,
@@ -3696,6 +4003,7 @@ func (ms *MemoryServer) parseImportParams(params map[string]interface{}) (*impor
 		sessionID:        sessionID,
 		chunkingStrategy: chunkingStrategy,
 		metadata:         metadata,
+		skipInvalid:      skipInvalid,
 	}, nil
 }
 
@@ -3706,19 +4014,17 @@ func (ms *MemoryServer) importChunksBySource(ctx context.Context, params *import
 		return ms.importConversationText(ctx, params.data, params.repository, params.chunkingStrategy, params.metadata)
 	case "file":
 		return ms.importFileContent(ctx, params.data, params.repository, params.chunkingStrategy, params.metadata)
-	case "archive":
-		return ms.importArchiveData(ctx, params.data, params.repository, params.metadata)
 	default:
 		return nil, fmt.Errorf("unsupported source type: %s", params.source)
 	}
 }
 
-// storeImportedChunks stores imported chunks with embeddings
-func (ms *MemoryServer) storeImportedChunks(ctx context.Context, chunks []types.ConversationChunk, params *importParams) int {
+// storeImportedChunks stores imported chunks with embeddings and returns the IDs stored
+func (ms *MemoryServer) storeImportedChunks(ctx context.Context, chunks []types.ConversationChunk, params *importParams) map[string]bool {
 	repositoryScopedSessionID := ms.createRepositoryScopedSessionID(params.repository, params.sessionID)
 	logging.Info("Created repository-scoped session for import", "original_session", params.sessionID, "scoped_session", repositoryScopedSessionID, "repository", params.repository)
 
-	storedCount := 0
+	stored := make(map[string]bool, len(chunks))
 	for i := range chunks {
 		chunk := &chunks[i]
 		chunk.SessionID = repositoryScopedSessionID
@@ -3728,10 +4034,10 @@ func (ms *MemoryServer) storeImportedChunks(ctx context.Context, chunks []types.
 			continue
 		}
 
-		storedCount++
+		stored[chunk.ID] = true
 	}
 
-	return storedCount
+	return stored
 }
 
 // processAndStoreChunk generates embedding and stores a single chunk
@@ -3765,6 +4071,21 @@ func (ms *MemoryServer) buildImportResponse(params *importParams, chunks []types
 
 // Helper methods for import functionality
 
+// importProvenance builds provenance for import_context chunks from the import metadata
+func importProvenance(metadata map[string]interface{}) *types.Provenance {
+	provenance := types.ProvenanceFromMap(metadata)
+	if provenance == nil {
+		provenance = &types.Provenance{}
+	}
+	if provenance.SourceSystem == "" {
+		provenance.SourceSystem = types.SourceSystemImport
+	}
+	if provenance.CaptureTool == "" {
+		provenance.CaptureTool = "import_context"
+	}
+	return provenance
+}
+
 func (ms *MemoryServer) importConversationText(ctx context.Context, data, repository, _ string, metadata map[string]interface{}) ([]types.ConversationChunk, error) {
 	// Create conversation chunks using the chunking service
 	chunkMetadata := types.ChunkMetadata{
@@ -3781,10 +4102,12 @@ func (ms *MemoryServer) importConversationText(ctx context.Context, data, reposi
 		}
 	}
 
-	// Add source system as a tag since no dedicated field exists
+	// Keep the source tag for tag-based search alongside structured provenance
 	if sourceSystem, exists := metadata["source_system"].(string); exists {
 		chunkMetadata.Tags = append(chunkMetadata.Tags, "source:"+sourceSystem)
 	}
+	chunkMetadata.Provenance = importProvenance(metadata)
+	ms.setPersonReference(&chunkMetadata, types.EMKeyAuthorPersonID, chunkMetadata.Provenance.Author)
 
 	chunkData, err := ms.container.GetChunkingService().CreateChunk(ctx, "import", data, &chunkMetadata)
 	if err != nil {
@@ -3810,10 +4133,12 @@ func (ms *MemoryServer) importFileContent(ctx context.Context, data, repository,
 		}
 	}
 
-	// Add source system as a tag since no dedicated field exists
+	// Keep the source tag for tag-based search alongside structured provenance
 	if sourceSystem, exists := metadata["source_system"].(string); exists {
 		chunkMetadata.Tags = append(chunkMetadata.Tags, "source:"+sourceSystem)
 	}
+	chunkMetadata.Provenance = importProvenance(metadata)
+	ms.setPersonReference(&chunkMetadata, types.EMKeyAuthorPersonID, chunkMetadata.Provenance.Author)
 
 	chunkData, err := ms.container.GetChunkingService().CreateChunk(ctx, "import", data, &chunkMetadata)
 	if err != nil {
@@ -3826,33 +4151,41 @@ func (ms *MemoryServer) importFileContent(ctx context.Context, data, repository,
 	return []types.ConversationChunk{*chunkData}, nil
 }
 
-func (ms *MemoryServer) importArchiveData(_ context.Context, data, repository string, metadata map[string]interface{}) ([]types.ConversationChunk, error) {
-	// Decode base64 archive data
+func (ms *MemoryServer) importArchiveData(_ context.Context, data, repository string, metadata map[string]interface{}) ([]types.ConversationChunk, *projectGraph, error) {
+	// Decode base64 archive data; the data of a JSON export is taken as is
 	archiveData, err := base64.StdEncoding.DecodeString(data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode archive data: %w", err)
+		if !strings.HasPrefix(strings.TrimSpace(data), "{") {
+			return nil, nil, fmt.Errorf("failed to decode archive data: %w", err)
+		}
+		archiveData = []byte(data)
 	}
 
 	// Parse JSON archive
 	var archiveContent map[string]interface{}
 	if err := json.Unmarshal(archiveData, &archiveContent); err != nil {
-		return nil, fmt.Errorf("failed to parse archive JSON: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse archive JSON: %w", err)
 	}
 
 	// Extract chunks from archive
 	chunksData, exists := archiveContent["chunks"]
 	if !exists {
-		return nil, errors.New("no chunks found in archive")
+		return nil, nil, errors.New("no chunks found in archive")
 	}
 
 	chunksJSON, err := json.Marshal(chunksData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal chunks data: %w", err)
+		return nil, nil, fmt.Errorf("failed to marshal chunks data: %w", err)
 	}
 
 	var chunks []types.ConversationChunk
 	if err := json.Unmarshal(chunksJSON, &chunks); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal chunks: %w", err)
+		return nil, nil, fmt.Errorf("failed to unmarshal chunks: %w", err)
+	}
+
+	graph, err := parseProjectGraph(archiveContent)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Update repository and add import metadata
@@ -3866,7 +4199,7 @@ func (ms *MemoryServer) importArchiveData(_ context.Context, data, repository st
 		}
 	}
 
-	return chunks, nil
+	return chunks, graph, nil
 }
 
 // GetServer returns the underlying MCP server
@@ -3958,13 +4291,11 @@ func (ms *MemoryServer) handleMemoryLink(ctx context.Context, params map[string]
 		return nil, errors.New("relation_type is required")
 	}
 
+	// Custom relation types are scoped to the repository that defined them
+	repository, _ := params["repository"].(string)
 	relationType := types.RelationType(relationTypeStr)
-	if !relationType.Valid() {
-		validTypes := make([]string, 0, len(types.AllValidRelationTypes()))
-		for _, vt := range types.AllValidRelationTypes() {
-			validTypes = append(validTypes, string(vt))
-		}
-		return nil, fmt.Errorf("invalid relation type: %s. Valid types are: %v", relationTypeStr, validTypes)
+	if err := ms.relationTaxonomy().Validate(repository, relationType); err != nil {
+		return nil, err
 	}
 
 	confidence := 0.8 // default
@@ -3981,6 +4312,13 @@ func (ms *MemoryServer) handleMemoryLink(ctx context.Context, params map[string]
 		return nil, fmt.Errorf("failed to create relationship: %w", err)
 	}
 
+	// The store mirrors built-in symmetric types itself; custom ones are mirrored here
+	if definition, _ := ms.relationTaxonomy().Lookup(repository, relationType); !definition.BuiltIn && definition.Directionality == types.DirectionalitySymmetric {
+		if _, err := storage.StoreRelationship(ctx, targetChunkID, sourceChunkID, relationType, confidence, types.ConfidenceExplicit); err != nil {
+			return nil, fmt.Errorf("failed to create inverse relationship: %w", err)
+		}
+	}
+
 	// Log the action
 	ms.container.AuditLogger.LogEvent(ctx, audit.EventTypeRelationshipAdd, "memory_link", "relationship", relationship.ID, map[string]interface{}{
 		"source_chunk_id": sourceChunkID,
@@ -3990,6 +4328,10 @@ func (ms *MemoryServer) handleMemoryLink(ctx context.Context, params map[string]
 		"relationship_id": relationship.ID,
 	})
 
+	if sessionID, ok := params["session_id"].(string); ok {
+		ms.recordWorkingSet(sessionID, repository, workingSetLinked, sourceChunkID, targetChunkID)
+	}
+
 	return map[string]interface{}{
 		"relationship_id":   relationship.ID,
 		"source_chunk_id":   relationship.SourceChunkID,
@@ -4649,13 +4991,6 @@ func (ms *MemoryServer) logExplainedSearch(ctx context.Context, query string, re
 }
 
 // Helper functions for environment variables
-func getEnv(key, defaultValue string) string {
-	if val := os.Getenv(key); val != "" {
-		return val
-	}
-	return defaultValue
-}
-
 func getEnvInt(key string, defaultValue int) int {
 	if val := os.Getenv(key); val != "" {
 		if i, err := strconv.Atoi(val); err == nil {
@@ -4724,6 +5059,19 @@ func (ms *MemoryServer) buildMetadataFromParams(params map[string]interface{}) t
 		}
 	}
 
+	// Provenance supplied by the capturing client (CLI, git hook, ...), else the MCP tool call itself
+	if provenance, ok := params["provenance"].(map[string]interface{}); ok {
+		metadata.Provenance = types.ProvenanceFromMap(provenance)
+	}
+	if metadata.Provenance == nil {
+		metadata.Provenance = &types.Provenance{SourceSystem: types.SourceSystemMCP}
+	}
+
+	// Without a memory class the chunk type's default class applies
+	if class, ok := params["memory_class"].(string); ok {
+		metadata.MemoryClass = types.MemoryClass(class)
+	}
+
 	return metadata
 }
 
@@ -4849,7 +5197,7 @@ func (ms *MemoryServer) handleMemoryConflicts(ctx context.Context, params map[st
 	}
 
 	// Use the new conflict detection system
-	conflictDetector := intelligence.NewConflictDetector()
+	conflictDetector := ms.newConflictDetector()
 	conflictResolver := intelligence.NewConflictResolver()
 
 	// Detect conflicts using the enhanced system
@@ -4982,7 +5330,7 @@ func (ms *MemoryServer) getRequestedConflicts(ctx context.Context, conflictIDs [
 		return nil, 0, fmt.Errorf("failed to get chunks for conflict analysis: %w", err)
 	}
 
-	conflictDetector := intelligence.NewConflictDetector()
+	conflictDetector := ms.newConflictDetector()
 	conflictResult, err := conflictDetector.DetectConflicts(ctx, chunks)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to detect conflicts: %w", err)
@@ -8014,32 +8362,6 @@ func (ms *MemoryServer) generateDecayRecommendations(total, old, stale, forSumma
 	return recommendations
 }
 
-// searchRelatedRepositories searches related repositories for the given query
-func (ms *MemoryServer) searchRelatedRepositories(ctx context.Context, relaxedQuery *types.MemoryQuery, embeddings []float64, originalRepo string, searchConfig config.SearchConfig) (*types.SearchResults, error) {
-	relatedRepos := ms.generateRelatedRepositories(originalRepo)
-	// Limit to configured max related repos
-	if len(relatedRepos) > searchConfig.MaxRelatedRepos {
-		relatedRepos = relatedRepos[:searchConfig.MaxRelatedRepos]
-	}
-
-	for _, relatedRepo := range relatedRepos {
-		relatedQuery := *relaxedQuery
-		relatedQuery.Repository = &relatedRepo
-		logging.Info("Progressive search: Step 3 - Related repo search", "original_repo", originalRepo, "trying_repo", relatedRepo)
-		results, err := ms.container.GetVectorStore().Search(ctx, &relatedQuery, embeddings)
-		if err != nil {
-			continue // Try next related repo
-		}
-
-		if len(results.Results) > 0 {
-			logging.Info("Progressive search: Related repo search succeeded", "results", len(results.Results), "repo", relatedRepo)
-			return results, nil
-		}
-	}
-
-	return nil, errors.New("no results found in related repositories")
-}
-
 // filterChunksBySession filters chunks by the given session ID (supports both regular and composite session IDs)
 func (ms *MemoryServer) filterChunksBySession(chunks []types.ConversationChunk, sessionID string) []types.ConversationChunk {
 	var filtered []types.ConversationChunk
@@ -8128,21 +8450,34 @@ func (ms *MemoryServer) runPeriodicDecay(ctx context.Context) {
 		case <-ticker.C:
 			logging.Info("Running automatic memory decay cleanup")
 
-			// Clean up chunks older than retention period (90 days by default)
-			retentionDays := 90
-			deletedCount, err := ms.container.GetVectorStore().Cleanup(ctx, retentionDays)
+			// Trash memories past their class's retention, consolidating
+			// expiring session logs into semantic memories first
+			trashed, promoted, err := ms.expireMemories(ctx, time.Now())
 			if err != nil {
 				logging.Error("Failed to run automatic cleanup", "error", err)
 				continue
 			}
 
-			if deletedCount > 0 {
-				logging.Info("Automatic cleanup completed", "deleted_chunks", deletedCount, "retention_days", retentionDays)
+			if trashed > 0 {
+				logging.Info("Automatic cleanup completed", "trashed_chunks", trashed, "consolidated_sessions", promoted)
 
 				// Store cleanup result as memory chunk for tracking
-				content := fmt.Sprintf("Automatic memory cleanup completed. Deleted %d old chunks (retention: %d days)", deletedCount, retentionDays)
+				content := fmt.Sprintf("Automatic memory cleanup completed. Moved %d expired chunks to the trash and consolidated %d sessions into semantic memories", trashed, promoted)
 				ms.storeCleanupResult(ctx, content)
 			}
+
+			// Archive or delete what decayed under the repositories' policies
+			reports, err := ms.runDecayPolicies(ctx, "", time.Now(), false)
+			if err != nil {
+				logging.Error("Failed to apply decay policies", "error", err)
+				continue
+			}
+			for i := range reports {
+				if reports[i].Archived+reports[i].Trashed+reports[i].Failed > 0 {
+					logging.Info("Decay policy applied", "repository", reports[i].Repository,
+						"archived", reports[i].Archived, "trashed", reports[i].Trashed, "failed", reports[i].Failed)
+				}
+			}
 		}
 	}
 }
@@ -9005,6 +9340,9 @@ func (ms *MemoryServer) handleBulkImport(ctx context.Context, params map[string]
 
 	// Parse metadata if provided
 	ms.parseImportMetadata(params, &options)
+	if provenance, ok := params["provenance"].(map[string]interface{}); ok {
+		options.Provenance = types.ProvenanceFromMap(provenance)
+	}
 
 	// Import data
 	result, err := ms.bulkImporter.Import(ctx, data, &options)
@@ -9290,6 +9628,7 @@ func (ms *MemoryServer) handleSecureBulkDelete(ctx context.Context, params map[s
 
 	// Convert to string slice and validate ownership
 	validIds := []string{}
+	validChunks := make(map[string]*types.ConversationChunk)
 	rejectedIds := []string{}
 
 	vectorStore := ms.container.GetVectorStore()
@@ -9320,12 +9659,22 @@ func (ms *MemoryServer) handleSecureBulkDelete(ctx context.Context, params map[s
 		}
 
 		validIds = append(validIds, id)
+		validChunks[id] = chunk
 	}
 
+	// Deleted chunks go to the trash unless a permanent delete is requested
+	permanent, _ := params["permanent"].(bool)
+
 	// Only delete chunks that belong to the specified repository
 	deletedCount := 0
+	now := time.Now()
 	for _, id := range validIds {
-		err := vectorStore.Delete(ctx, id)
+		var err error
+		if permanent {
+			err = vectorStore.Delete(ctx, id)
+		} else {
+			err = ms.trashChunk(ctx, validChunks[id], now)
+		}
 		if err != nil {
 			logging.Error("Failed to delete chunk", "id", id, "error", err)
 			rejectedIds = append(rejectedIds, id)
@@ -9341,6 +9690,10 @@ func (ms *MemoryServer) handleSecureBulkDelete(ctx context.Context, params map[s
 		"verified_count":  len(validIds),
 		"deleted_count":   deletedCount,
 		"rejected_count":  len(rejectedIds),
+		"permanent":       permanent,
+	}
+	if !permanent {
+		result["trash_retention_days"] = int(ms.trashRetention().Hours() / 24)
 	}
 
 	if len(rejectedIds) > 0 {
@@ -9357,10 +9710,86 @@ func (ms *MemoryServer) handleSecureBulkDelete(ctx context.Context, params map[s
 	return result, nil
 }
 
-// handleSecureSearch performs repository-scoped search without progressive fallback
+// handleSecureSearch performs repository-scoped search, running the search
+// plan without the steps that would leave the repository
 func (ms *MemoryServer) handleSecureSearch(ctx context.Context, params map[string]interface{}, repository string) (interface{}, error) {
 	logging.Info("MCP TOOL: memory_secure_search called", "params", params, "repository", repository)
 
+	memQuery, err := ms.secureSearchQuery(params, repository)
+	if err != nil {
+		return nil, err
+	}
+	query := memQuery.Query
+
+	// Perform SECURE search (no plan step that breaks repository isolation)
+	searchStart := time.Now()
+	results, outcome, err := ms.secureSearch(ctx, memQuery)
+	if err != nil {
+		ms.logSearchAudit(ctx, query, memQuery, nil, searchStart, err)
+		return nil, err
+	}
+	profileName := ms.applyScoringProfile(memQuery, results)
+	ms.logSearchAudit(ctx, query, memQuery, results, searchStart, nil)
+
+	// Attach matched-term snippets unless explicitly disabled
+	if withHighlights, ok := params["highlight"].(bool); !ok || withHighlights {
+		contextSentences := highlight.DefaultContextSentences
+		if n, ok := params["context_sentences"].(float64); ok {
+			contextSentences = int(n)
+		}
+		for i := range results.Results {
+			results.Results[i].Highlight = highlight.Compute(results.Results[i].Chunk.Content, query, contextSentences)
+		}
+	}
+
+	retrieved := make([]string, 0, len(results.Results))
+	for i := range results.Results {
+		retrieved = append(retrieved, results.Results[i].Chunk.ID)
+	}
+
+	// Build response
+	response := map[string]interface{}{
+		"status":         "success",
+		"repository":     repository,
+		"query":          query,
+		"total":          results.Total,
+		"results":        results.Results,
+		"query_time":     results.QueryTime.Milliseconds(),
+		"search_mode":    memQuery.Mode,
+		"satisfied_step": outcome.SatisfiedStep,
+		"search_plan":    outcome,
+		"security_note":  "Repository-scoped search with no cross-tenant fallback",
+	}
+	if profileName != "" {
+		response["scoring_profile"] = profileName
+	}
+
+	// Follow high-confidence relationships one hop when asked, within the repository
+	if expansion, ok := parseSearchExpansion(params); ok {
+		expanded := ms.expandSearchResults(ctx, results.Results, repository, expansion)
+		for i := range expanded {
+			retrieved = append(retrieved, expanded[i].Chunk.ID)
+		}
+		response["expanded_results"] = expanded
+	}
+	ms.recordRetrieved(params, repository, retrieved...)
+
+	if repository == GlobalRepository {
+		response["scope"] = GlobalRepository
+		response["security_note"] = "Global search across all repositories for architecture decisions"
+	}
+
+	logging.Info("Secure search completed",
+		"repository", repository,
+		"query", query,
+		"results_count", results.Total,
+		"query_time_ms", results.QueryTime.Milliseconds())
+
+	return response, nil
+}
+
+// secureSearchQuery builds the query of a repository-scoped search from its parameters
+func (ms *MemoryServer) secureSearchQuery(params map[string]interface{}, repository string) (*types.MemoryQuery, error) {
 	// Parse search query
 	query, ok := params["query"].(string)
 	if !ok || query == "" {
@@ -9405,43 +9834,53 @@ func (ms *MemoryServer) handleSecureSearch(ctx context.Context, params map[strin
 		memQuery.Recency = types.Recency(recency)
 	}
 
-	// Generate embeddings for the query
-	embeddingService := ms.container.GetEmbeddingService()
-	embeddings, err := embeddingService.GenerateEmbedding(ctx, query)
+	// Parse memory class filter
+	classes, err := memoryClassesFromParams(params)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate embeddings: %w", err)
+		return nil, err
 	}
+	memQuery.Classes = classes
 
-	// Perform SECURE search (no progressive fallback that breaks repository isolation)
-	vectorStore := ms.container.GetVectorStore()
-	results, err := vectorStore.Search(ctx, &memQuery, embeddings)
-	if err != nil {
-		return nil, fmt.Errorf("search failed: %w", err)
-	}
+	// Archived memories are searched only on request
+	memQuery.IncludeArchived, _ = params["include_archived"].(bool)
 
-	// Build response
-	response := map[string]interface{}{
-		"status":        "success",
-		"repository":    repository,
-		"query":         query,
-		"total":         results.Total,
-		"results":       results.Results,
-		"query_time":    results.QueryTime.Milliseconds(),
-		"security_note": "Repository-scoped search with no cross-tenant fallback",
+	// Parse provenance filter
+	if provenance, ok := params["provenance"].(map[string]interface{}); ok {
+		if p := types.ProvenanceFromMap(provenance); p != nil {
+			memQuery.Provenance = &types.ProvenanceFilter{
+				SourceSystem: p.SourceSystem,
+				CommitSHA:    p.CommitSHA,
+				Author:       p.Author,
+				CaptureTool:  p.CaptureTool,
+			}
+		}
 	}
 
-	if repository == GlobalRepository {
-		response["scope"] = GlobalRepository
-		response["security_note"] = "Global search across all repositories for architecture decisions"
+	// Parse search mode
+	memQuery.Mode, err = ms.searchModeFromParams(params)
+	if err != nil {
+		return nil, err
 	}
 
-	logging.Info("Secure search completed",
-		"repository", repository,
-		"query", query,
-		"results_count", results.Total,
-		"query_time_ms", results.QueryTime.Milliseconds())
+	return &memQuery, nil
+}
 
-	return response, nil
+// secureSearch runs a query as memory_read search does: the search plan
+// within the repository, then outcome, class and quality ranking. The
+// repository's scoring profile is left to the caller.
+func (ms *MemoryServer) secureSearch(ctx context.Context, memQuery *types.MemoryQuery) (*types.SearchResults, *SearchPlanOutcome, error) {
+	embeddings, err := ms.searchEmbeddings(ctx, memQuery.Query, memQuery.Mode)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate embeddings: %w", err)
+	}
+	results, outcome, err := ms.executeProgressiveSearch(ctx, memQuery, embeddings, true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("search failed: %w", err)
+	}
+	scoring.RankByOutcome(results.Results)
+	scoring.RankByClass(results.Results)
+	scoring.RankByQuality(results.Results)
+	return results, outcome, nil
 }
 
 // handleSecureFindSimilar performs repository-scoped similarity search
@@ -9468,6 +9907,12 @@ func (ms *MemoryServer) handleSecureFindSimilar(ctx context.Context, params map[
 	if chunkType, ok := params["chunk_type"].(string); ok {
 		searchParams["types"] = []string{chunkType}
 	}
+	if sessionID, ok := params["session_id"]; ok {
+		searchParams["session_id"] = sessionID
+	}
+	if mode, ok := params["mode"]; ok {
+		searchParams["mode"] = mode
+	}
 
 	return ms.handleSecureSearch(ctx, searchParams, repository)
 }
@@ -9486,7 +9931,7 @@ func (ms *MemoryServer) handleSecureSearchExplained(ctx context.Context, params
 	if resultMap, ok := result.(map[string]interface{}); ok {
 		resultMap["explanation"] = map[string]interface{}{
 			"search_strategy":   "Repository-scoped search with strict isolation",
-			"fallback_disabled": "Progressive search fallback disabled for security",
+			"fallback_disabled": "Search plan steps leaving the repository are skipped for security",
 			"repository_scope":  repository,
 		}
 		if repository == GlobalRepository {
@@ -9980,15 +10425,24 @@ func (ms *MemoryServer) handleCreateTask(ctx context.Context, params map[string]
 
 	// Build task metadata
 	metadata := ms.buildTaskMetadata(taskConfig, params)
+	tagsResolved, tagSuggestions := ms.reconcileTags(&metadata)
+	if creator, ok := params["creator"].(string); ok && creator != "" {
+		ctx = audit.WithPersonID(ctx, ms.setPersonReference(&metadata, types.EMKeyTaskCreatorPersonID, creator))
+	}
 
 	// Create and store task chunk
 	chunk, err := ms.createAndStoreTaskChunk(ctx, taskConfig, &metadata)
 	if err != nil {
 		return nil, err
 	}
+	ms.notifyTaskTransition(chunk, "")
 
 	// Log and return response
-	return ms.finalizeTaskCreation(ctx, chunk, taskConfig)
+	response, err := ms.finalizeTaskCreation(ctx, chunk, taskConfig)
+	if err == nil {
+		addTagReconciliation(response, tagsResolved, tagSuggestions)
+	}
+	return response, err
 }
 
 // createTaskConfig holds configuration for task creation
@@ -10062,6 +10516,7 @@ func (ms *MemoryServer) buildTaskMetadata(taskConfig *createTaskConfig, params m
 func (ms *MemoryServer) setTaskSpecificFields(metadata *types.ChunkMetadata, params map[string]interface{}) {
 	if assignee, ok := params["assignee"].(string); ok && assignee != "" {
 		metadata.TaskAssignee = &assignee
+		ms.setPersonReference(metadata, types.EMKeyTaskAssigneePersonID, assignee)
 	}
 
 	if dueDate, ok := params["due_date"].(string); ok && dueDate != "" {
@@ -10118,7 +10573,7 @@ func (ms *MemoryServer) createAndStoreTaskChunk(ctx context.Context, taskConfig
 }
 
 // finalizeTaskCreation logs audit event and returns response
-func (ms *MemoryServer) finalizeTaskCreation(ctx context.Context, chunk *types.ConversationChunk, taskConfig *createTaskConfig) (interface{}, error) {
+func (ms *MemoryServer) finalizeTaskCreation(ctx context.Context, chunk *types.ConversationChunk, taskConfig *createTaskConfig) (map[string]interface{}, error) {
 	// Audit log
 	ms.container.GetAuditLogger().LogEvent(ctx, audit.EventTypeMemoryStore, "create_task", "task", chunk.ID, map[string]interface{}{
 		"task_id":    chunk.ID,
@@ -10311,6 +10766,9 @@ func (ms *MemoryServer) applyTaskUpdates(updatedChunk *types.ConversationChunk,
 	if assignee, ok := params["assignee"].(string); ok {
 		updatedChunk.Metadata.TaskAssignee = &assignee
 		updates["task_assignee"] = assignee
+		if ms.setPersonReference(&updatedChunk.Metadata, types.EMKeyTaskAssigneePersonID, assignee) == "" && updatedChunk.Metadata.ExtendedMetadata != nil {
+			delete(updatedChunk.Metadata.ExtendedMetadata, types.EMKeyTaskAssigneePersonID)
+		}
 	}
 
 	// Due date update
@@ -10376,17 +10834,21 @@ func (ms *MemoryServer) handleUpdateTask(ctx context.Context, params map[string]
 		return nil, err
 	}
 
-	// Create updated chunk by modifying the existing one
-	updatedChunk := *chunk
-	updates := ms.applyTaskUpdates(&updatedChunk, params)
+	previousStatus := currentTaskStatus(chunk)
 
 	// Create progress note chunk if provided
 	ms.createProgressNoteChunk(ctx, params, chunk, taskID, sessionID)
 
-	// Apply updates by updating the full chunk
-	if err := ms.container.GetVectorStore().Update(ctx, &updatedChunk); err != nil {
+	// Apply updates to the stored task under its chunk lock
+	var updates map[string]interface{}
+	updatedChunk, err := ms.updateChunk(ctx, taskID, "", func(current *types.ConversationChunk) error {
+		updates = ms.applyTaskUpdates(current, params)
+		return nil
+	})
+	if err != nil {
 		return nil, fmt.Errorf("failed to update task: %w", err)
 	}
+	ms.notifyTaskTransition(updatedChunk, previousStatus)
 
 	// Audit log
 	ms.container.GetAuditLogger().LogEvent(ctx, audit.EventTypeMemoryUpdate, "update_task", "task", taskID, map[string]interface{}{
@@ -10573,11 +11035,9 @@ func (ms *MemoryServer) handleCompleteTask(ctx context.Context, params map[strin
 	}
 
 	// Update task to completed status
-	updatedChunk := *chunk
+	previousStatus := currentTaskStatus(chunk)
 	completedStatus := types.TaskStatusCompleted
 	progress := 100
-	updatedChunk.Metadata.TaskStatus = &completedStatus
-	updatedChunk.Metadata.TaskProgress = &progress
 
 	updates := map[string]interface{}{
 		"task_status":   string(types.TaskStatusCompleted),
@@ -10585,16 +11045,26 @@ func (ms *MemoryServer) handleCompleteTask(ctx context.Context, params map[strin
 	}
 
 	// Add actual time if provided
+	var timeSpent *int
 	if actualTime, ok := params["actual_time"].(float64); ok {
-		timeSpent := int(actualTime)
-		updatedChunk.Metadata.TimeSpent = &timeSpent
-		updates["time_spent"] = timeSpent
+		spent := int(actualTime)
+		timeSpent = &spent
+		updates["time_spent"] = spent
 	}
 
-	// Apply updates
-	if err := ms.container.GetVectorStore().Update(ctx, &updatedChunk); err != nil {
+	// Apply updates to the stored task under its chunk lock
+	updatedChunk, err := ms.updateChunk(ctx, taskID, "", func(current *types.ConversationChunk) error {
+		current.Metadata.TaskStatus = &completedStatus
+		current.Metadata.TaskProgress = &progress
+		if timeSpent != nil {
+			current.Metadata.TimeSpent = timeSpent
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, fmt.Errorf("failed to complete task: %w", err)
 	}
+	ms.notifyTaskTransition(updatedChunk, previousStatus)
 
 	// Create completion notes chunk if provided
 	if completionNotes, ok := params["completion_notes"].(string); ok && completionNotes != "" {
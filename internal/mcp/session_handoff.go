@@ -0,0 +1,426 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"lerian-mcp-memory/internal/audit"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/workflow"
+	"lerian-mcp-memory/pkg/types"
+)
+
+// Session handoff operations of the memory_tasks tool
+const (
+	OperationSessionHandoff = "session_handoff"
+	OperationSessionResume  = "session_resume"
+)
+
+const (
+	// handoffTag marks handoff artifacts among session summaries
+	handoffTag = "handoff"
+
+	// handoffCaptureTool marks the provenance of handoff artifacts
+	handoffCaptureTool = "session_handoff"
+
+	// maxHandoffChunks bounds the working set a handoff carries
+	maxHandoffChunks = 25
+
+	// workingSetHandedOff marks chunks a resumed session inherited
+	workingSetHandedOff = "handed_off"
+)
+
+// SessionHandoff is what one session leaves for the next: the memories it
+// was working with, the work still open and the decisions it made
+type SessionHandoff struct {
+	Repository string              `json:"repository"`
+	SessionID  string              `json:"session_id"`
+	From       string              `json:"from,omitempty"`
+	To         string              `json:"to,omitempty"`
+	Notes      string              `json:"notes,omitempty"`
+	WorkingSet []HandoffItem       `json:"working_set"`
+	OpenTodos  []workflow.TodoItem `json:"open_todos"`
+	OpenTasks  []HandoffItem       `json:"open_tasks"`
+	Decisions  []HandoffItem       `json:"decisions"`
+	CreatedAt  time.Time           `json:"created_at"`
+	Resumes    []HandoffResume     `json:"resumes,omitempty"`
+}
+
+// HandoffItem is a memory referenced by a handoff
+type HandoffItem struct {
+	ChunkID string          `json:"chunk_id"`
+	Type    types.ChunkType `json:"type"`
+	Summary string          `json:"summary"`
+	Actions []string        `json:"actions,omitempty"`
+	Status  string          `json:"status,omitempty"`
+}
+
+// HandoffResume records a session that picked a handoff up
+type HandoffResume struct {
+	SessionID string    `json:"session_id"`
+	By        string    `json:"by,omitempty"`
+	ResumedAt time.Time `json:"resumed_at"`
+}
+
+// handleSessionHandoff packages a session's working set, open todos and
+// tasks and key decisions into a handoff artifact, stored as a session
+// summary memory so another agent or a person can resume from it
+func (ms *MemoryServer) handleSessionHandoff(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	repository, _ := args["repository"].(string)
+	sessionID, _ := args["session_id"].(string)
+	if repository == "" || repository == GlobalRepository || sessionID == "" {
+		return nil, NewToolError(ToolErrorMissingParameter, "repository and session_id parameters are required for session_handoff").
+			WithExample(`{"operation": "session_handoff", "options": {"repository": "github.com/user/repo", "session_id": "auth-fix-session", "to": "reviewer-agent", "notes": "Token refresh still flaky"}}`)
+	}
+	sessionID = ms.validateAndNormalizeSessionID(sessionID)
+	from, _ := args["from"].(string)
+	to, _ := args["to"].(string)
+	notes, _ := args["notes"].(string)
+
+	handoff, err := ms.buildHandoff(ctx, repository, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	handoff.From, handoff.To, handoff.Notes = from, to, strings.TrimSpace(notes)
+	if len(handoff.WorkingSet)+len(handoff.OpenTodos)+len(handoff.OpenTasks)+len(handoff.Decisions) == 0 && handoff.Notes == "" {
+		return nil, NewToolError(ToolErrorNotFound, fmt.Sprintf("session %s has nothing to hand off in %s", sessionID, repository)).
+			WithParameter("session_id").
+			WithSuggestion("Hand off a session that stored or retrieved memories, or add notes")
+	}
+
+	record, err := ms.storeHandoff(ctx, handoff)
+	if err != nil {
+		return nil, err
+	}
+	ms.logHandoffEvent(ctx, "session_handoff", record.ID, map[string]interface{}{
+		"repository": repository,
+		"session_id": sessionID,
+		"to":         to,
+		"chunks":     len(handoff.WorkingSet),
+		"open_todos": len(handoff.OpenTodos),
+	})
+
+	return map[string]interface{}{
+		"status":     "success",
+		"handoff_id": record.ID,
+		"handoff":    handoff,
+		"resume": map[string]interface{}{
+			"operation": OperationSessionResume,
+			"options":   map[string]interface{}{"repository": repository, "handoff_id": record.ID, "session_id": "<new session id>"},
+		},
+	}, nil
+}
+
+// buildHandoff gathers a session's working set, open todos and tasks and
+// decisions. Sessions whose working set was forgotten, as after a restart,
+// fall back to the memories stored in them.
+func (ms *MemoryServer) buildHandoff(ctx context.Context, repository, sessionID string) (*SessionHandoff, error) {
+	store := ms.container.GetVectorStore()
+	handoff := &SessionHandoff{
+		Repository: repository,
+		SessionID:  sessionID,
+		WorkingSet: []HandoffItem{},
+		OpenTodos:  []workflow.TodoItem{},
+		OpenTasks:  []HandoffItem{},
+		Decisions:  []HandoffItem{},
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	actions := make(map[string][]string)
+	var ids []string
+	for _, entry := range ms.workingSet.snapshot(sessionID) {
+		if entry.Repository != "" && entry.Repository != repository {
+			continue
+		}
+		actions[entry.ChunkID] = entry.Actions
+		ids = append(ids, entry.ChunkID)
+	}
+	chunks, err := store.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the working set: %w", err)
+	}
+
+	// The memories stored in the session carry its decisions and tasks even
+	// when nothing retrieved them
+	sessionChunks, err := store.ListBySession(ctx, ms.createRepositoryScopedSessionID(repository, sessionID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session memories: %w", err)
+	}
+	sort.SliceStable(sessionChunks, func(i, j int) bool { return sessionChunks[i].Timestamp.After(sessionChunks[j].Timestamp) })
+	seen := make(map[string]bool, len(chunks))
+	for i := range chunks {
+		seen[chunks[i].ID] = true
+	}
+	for i := range sessionChunks {
+		if !seen[sessionChunks[i].ID] {
+			seen[sessionChunks[i].ID] = true
+			chunks = append(chunks, sessionChunks[i])
+		}
+	}
+
+	for i := range chunks {
+		chunk := &chunks[i]
+		if chunk.Metadata.Repository != repository || chunk.IsDeleted() || chunk.IsPassage() || isHandoff(chunk) {
+			continue
+		}
+		item := HandoffItem{ChunkID: chunk.ID, Type: chunk.Type, Summary: handoffSummary(chunk), Actions: actions[chunk.ID]}
+		switch {
+		case chunk.Type == types.ChunkTypeArchitectureDecision:
+			handoff.Decisions = append(handoff.Decisions, item)
+		case chunk.Type == types.ChunkTypeTask && chunk.Metadata.TaskStatus != nil &&
+			*chunk.Metadata.TaskStatus != types.TaskStatusCompleted && *chunk.Metadata.TaskStatus != types.TaskStatusCancelled:
+			item.Status = string(*chunk.Metadata.TaskStatus)
+			handoff.OpenTasks = append(handoff.OpenTasks, item)
+		case len(handoff.WorkingSet) < maxHandoffChunks:
+			handoff.WorkingSet = append(handoff.WorkingSet, item)
+		}
+	}
+
+	if ms.todoTracker != nil {
+		if session, ok := ms.todoTracker.GetActiveSession(sessionID, repository); ok {
+			for _, todo := range session.Todos {
+				if todo.Status != "completed" && todo.Status != "cancelled" {
+					handoff.OpenTodos = append(handoff.OpenTodos, todo)
+				}
+			}
+		}
+	}
+	return handoff, nil
+}
+
+// storeHandoff stores a handoff as a session summary memory of the session
+// it was taken from
+func (ms *MemoryServer) storeHandoff(ctx context.Context, handoff *SessionHandoff) (*types.ConversationChunk, error) {
+	metadata := types.ChunkMetadata{
+		Repository:       handoff.Repository,
+		Outcome:          types.OutcomeInProgress,
+		Difficulty:       types.DifficultySimple,
+		Tags:             []string{handoffTag},
+		Provenance:       &types.Provenance{SourceSystem: types.SourceSystemMCP, CaptureTool: handoffCaptureTool, Author: handoff.From},
+		ExtendedMetadata: map[string]interface{}{handoffTag: handoff},
+	}
+
+	content := renderHandoff(handoff)
+	record, err := types.NewConversationChunk(ms.createRepositoryScopedSessionID(handoff.Repository, handoff.SessionID), content, types.ChunkTypeSessionSummary, &metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create handoff: %w", err)
+	}
+	record.Summary = fmt.Sprintf("Handoff of session %s", handoff.SessionID)
+	if handoff.To != "" {
+		record.Summary += " to " + handoff.To
+	}
+	record.Embeddings, err = ms.container.GetEmbeddingService().GenerateEmbedding(ctx, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate embeddings for handoff: %w", err)
+	}
+	if err := ms.container.GetVectorStore().Store(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to store handoff: %w", err)
+	}
+
+	logging.Info("Stored session handoff", "chunk_id", record.ID, "session_id", handoff.SessionID, "to", handoff.To)
+	return record, nil
+}
+
+// handleSessionResume rehydrates a new session from a handoff: the handed
+// off memories join its working set, the open todos its todo list, and the
+// memories themselves come back in the response so one call restores the context
+func (ms *MemoryServer) handleSessionResume(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	repository, _ := args["repository"].(string)
+	handoffID, _ := args["handoff_id"].(string)
+	sessionID, _ := args["session_id"].(string)
+	if repository == "" || handoffID == "" || sessionID == "" {
+		return nil, NewToolError(ToolErrorMissingParameter, "repository, handoff_id and session_id parameters are required for session_resume").
+			WithExample(`{"operation": "session_resume", "options": {"repository": "github.com/user/repo", "handoff_id": "<handoff_id from session_handoff>", "session_id": "auth-fix-continued"}}`)
+	}
+	sessionID = ms.validateAndNormalizeSessionID(sessionID)
+	by, _ := args["by"].(string)
+
+	store := ms.container.GetVectorStore()
+	record, err := store.GetByID(ctx, handoffID)
+	if err != nil || record == nil || !isHandoff(record) || record.Metadata.Repository != repository || record.IsDeleted() {
+		return nil, NewToolError(ToolErrorNotFound, fmt.Sprintf("handoff %s not found in %s", handoffID, repository)).
+			WithParameter("handoff_id").
+			WithSuggestion("Use the handoff_id returned by session_handoff, or search for session summaries tagged handoff")
+	}
+	handoff, err := decodeHandoff(record)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]HandoffItem, 0, len(handoff.WorkingSet)+len(handoff.OpenTasks)+len(handoff.Decisions))
+	items = append(append(append(items, handoff.Decisions...), handoff.OpenTasks...), handoff.WorkingSet...)
+	ids := make([]string, len(items))
+	for i := range items {
+		ids[i] = items[i].ChunkID
+	}
+	chunks, err := store.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load handed off memories: %w", err)
+	}
+	memories := make([]map[string]interface{}, 0, len(chunks))
+	restored := make([]string, 0, len(chunks))
+	for i := range chunks {
+		if chunks[i].IsDeleted() {
+			continue
+		}
+		restored = append(restored, chunks[i].ID)
+		memories = append(memories, map[string]interface{}{
+			"chunk_id":  chunks[i].ID,
+			"type":      string(chunks[i].Type),
+			"summary":   chunks[i].Summary,
+			"content":   chunks[i].Content,
+			"timestamp": chunks[i].Timestamp.Format(time.RFC3339),
+		})
+	}
+	ms.recordWorkingSet(sessionID, repository, workingSetHandedOff, restored...)
+
+	if ms.todoTracker != nil && len(handoff.OpenTodos) > 0 {
+		todos := handoff.OpenTodos
+		if session, ok := ms.todoTracker.GetActiveSession(sessionID, repository); ok {
+			todos = mergeTodos(session.Todos, handoff.OpenTodos)
+		}
+		if err := ms.todoTracker.ProcessTodoWrite(ctx, sessionID, repository, todos); err != nil {
+			return nil, fmt.Errorf("failed to restore todos: %w", err)
+		}
+	}
+
+	resume := HandoffResume{SessionID: sessionID, By: by, ResumedAt: time.Now().UTC()}
+	if _, err := ms.updateChunk(ctx, handoffID, "", func(chunk *types.ConversationChunk) error {
+		current, err := decodeHandoff(chunk)
+		if err != nil {
+			return err
+		}
+		current.Resumes = append(current.Resumes, resume)
+		chunk.Metadata.ExtendedMetadata[handoffTag] = current
+		return nil
+	}); err != nil {
+		logging.Warn("Failed to record handoff resume", "handoff_id", handoffID, "error", err)
+	}
+	ms.logHandoffEvent(ctx, "session_resume", handoffID, map[string]interface{}{
+		"repository":     repository,
+		"session_id":     sessionID,
+		"from_session":   handoff.SessionID,
+		"chunks":         len(restored),
+		"restored_todos": len(handoff.OpenTodos),
+	})
+
+	return map[string]interface{}{
+		"status":       "success",
+		"handoff_id":   handoffID,
+		"session_id":   sessionID,
+		"from_session": handoff.SessionID,
+		"notes":        handoff.Notes,
+		"open_todos":   handoff.OpenTodos,
+		"open_tasks":   handoff.OpenTasks,
+		"decisions":    handoff.Decisions,
+		"memories":     memories,
+		"missing":      len(ids) - len(restored),
+		"working_set":  WorkingSetURI(sessionID),
+	}, nil
+}
+
+// decodeHandoff reads the handoff stored in a handoff memory, whether it
+// round-tripped through storage as JSON or not
+func decodeHandoff(record *types.ConversationChunk) (*SessionHandoff, error) {
+	raw, ok := record.Metadata.ExtendedMetadata[handoffTag]
+	if !ok {
+		return nil, fmt.Errorf("memory %s does not hold a handoff", record.ID)
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read handoff %s: %w", record.ID, err)
+	}
+	var handoff SessionHandoff
+	if err := json.Unmarshal(data, &handoff); err != nil {
+		return nil, fmt.Errorf("failed to read handoff %s: %w", record.ID, err)
+	}
+	return &handoff, nil
+}
+
+// mergeTodos adds the handed off todos a session does not have yet
+func mergeTodos(current, handedOff []workflow.TodoItem) []workflow.TodoItem {
+	merged := append([]workflow.TodoItem(nil), current...)
+	have := make(map[string]bool, len(current))
+	for _, todo := range current {
+		have[todo.ID] = true
+	}
+	for _, todo := range handedOff {
+		if todo.ID == "" || !have[todo.ID] {
+			merged = append(merged, todo)
+		}
+	}
+	return merged
+}
+
+// renderHandoff formats a handoff as the content of its memory
+func renderHandoff(handoff *SessionHandoff) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "SESSION HANDOFF: %s in %s\n", handoff.SessionID, handoff.Repository)
+	if handoff.From != "" || handoff.To != "" {
+		fmt.Fprintf(&b, "From %s to %s\n", valueOr(handoff.From, "unknown"), valueOr(handoff.To, "anyone"))
+	}
+	if handoff.Notes != "" {
+		fmt.Fprintf(&b, "\n%s\n", handoff.Notes)
+	}
+	if len(handoff.OpenTodos) > 0 {
+		b.WriteString("\n## Open todos\n")
+		for _, todo := range handoff.OpenTodos {
+			fmt.Fprintf(&b, "- [%s] %s\n", todo.Status, todo.Content)
+		}
+	}
+	for _, section := range []struct {
+		title string
+		items []HandoffItem
+	}{
+		{"Open tasks", handoff.OpenTasks},
+		{"Key decisions", handoff.Decisions},
+		{"Working set", handoff.WorkingSet},
+	} {
+		if len(section.items) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s\n", section.title)
+		for _, item := range section.items {
+			fmt.Fprintf(&b, "- (%s) %s [%s]\n", item.Type, item.Summary, item.ChunkID)
+		}
+	}
+	return b.String()
+}
+
+// handoffSummary returns a chunk's summary, or the start of its content
+func handoffSummary(chunk *types.ConversationChunk) string {
+	if chunk.Summary != "" {
+		return chunk.Summary
+	}
+	summary, _, _ := strings.Cut(strings.TrimSpace(chunk.Content), "\n")
+	if len(summary) > 120 {
+		summary = summary[:120] + "..."
+	}
+	return summary
+}
+
+// valueOr returns value, or fallback when value is empty
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// isHandoff reports whether a chunk is a session handoff
+func isHandoff(chunk *types.ConversationChunk) bool {
+	return chunk.Metadata.Provenance != nil && chunk.Metadata.Provenance.CaptureTool == handoffCaptureTool
+}
+
+// logHandoffEvent records a handoff or resume in the audit log
+func (ms *MemoryServer) logHandoffEvent(ctx context.Context, action, handoffID string, details map[string]interface{}) {
+	auditLogger := ms.container.GetAuditLogger()
+	if auditLogger == nil {
+		return
+	}
+	auditLogger.LogEvent(ctx, audit.EventTypeMemoryUpdate, action, "handoff", handoffID, details)
+}
@@ -0,0 +1,91 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/workflow"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionHandoffAndResume(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	ms := newCompositeTestServer(t, store)
+	ms.workingSet = newWorkingSetTracker()
+	ms.todoTracker = workflow.NewTodoTracker()
+
+	session := "github.com/acme/api::auth-fix"
+	decision := newReportChunk(t, session, "Rotate refresh tokens on every use", types.ChunkTypeArchitectureDecision, types.ChunkMetadata{})
+	problem := newReportChunk(t, session, "Refresh tokens are replayable", types.ChunkTypeProblem, types.ChunkMetadata{})
+	openTask := newTaskChunk(t, "github.com/acme/api", types.TaskStatusInProgress)
+	openTask.SessionID = session
+	doneTask := newTaskChunk(t, "github.com/acme/api", types.TaskStatusCompleted)
+	doneTask.SessionID = session
+	retrieved := newReportChunk(t, "older-session", "Token storage uses Redis", types.ChunkTypeDiscussion, types.ChunkMetadata{})
+	for _, chunk := range []*types.ConversationChunk{decision, problem, openTask, doneTask, retrieved} {
+		require.NoError(t, store.Store(ctx, chunk))
+	}
+	ms.recordWorkingSet("auth-fix", "github.com/acme/api", workingSetRetrieved, retrieved.ID)
+	require.NoError(t, ms.todoTracker.ProcessTodoWrite(ctx, "auth-fix", "github.com/acme/api", []workflow.TodoItem{
+		{ID: "1", Content: "Add replay test", Status: "pending"},
+		{ID: "2", Content: "Write the decision", Status: "completed"},
+	}))
+
+	result, err := ms.handleMemoryTasks(ctx, map[string]interface{}{
+		"operation": OperationSessionHandoff,
+		"options":   map[string]interface{}{"repository": "github.com/acme/api", "session_id": "auth-fix", "to": "reviewer", "notes": "Replay test still missing"},
+	})
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	handoff := response["handoff"].(*SessionHandoff)
+	handoffID := response["handoff_id"].(string)
+	assert.Len(t, handoff.Decisions, 1)
+	require.Len(t, handoff.OpenTasks, 1)
+	assert.Equal(t, openTask.ID, handoff.OpenTasks[0].ChunkID)
+	require.Len(t, handoff.OpenTodos, 1)
+	assert.Equal(t, "Add replay test", handoff.OpenTodos[0].Content)
+	workingSet := map[string]HandoffItem{}
+	for _, item := range handoff.WorkingSet {
+		workingSet[item.ChunkID] = item
+	}
+	assert.Len(t, workingSet, 3, "the retrieved chunk, the session's problem and its completed task")
+	assert.Equal(t, []string{workingSetRetrieved}, workingSet[retrieved.ID].Actions)
+
+	stored, err := store.GetByID(ctx, handoffID)
+	require.NoError(t, err)
+	assert.Equal(t, types.ChunkTypeSessionSummary, stored.Type)
+	assert.Contains(t, stored.Content, "Replay test still missing")
+
+	result, err = ms.handleMemoryTasks(ctx, map[string]interface{}{
+		"operation": OperationSessionResume,
+		"options":   map[string]interface{}{"repository": "github.com/acme/api", "handoff_id": handoffID, "session_id": "auth-fix-2", "by": "reviewer"},
+	})
+	require.NoError(t, err)
+	resumed := result.(map[string]interface{})
+	assert.Len(t, resumed["memories"], 5)
+	assert.Equal(t, "Replay test still missing", resumed["notes"])
+
+	entries := ms.workingSet.snapshot("auth-fix-2")
+	assert.Len(t, entries, 5)
+	todos, ok := ms.todoTracker.GetActiveSession("auth-fix-2", "github.com/acme/api")
+	require.True(t, ok)
+	require.Len(t, todos.Todos, 1)
+	assert.Equal(t, "1", todos.Todos[0].ID)
+
+	stored, err = store.GetByID(ctx, handoffID)
+	require.NoError(t, err)
+	recorded, err := decodeHandoff(stored)
+	require.NoError(t, err)
+	require.Len(t, recorded.Resumes, 1)
+	assert.Equal(t, "auth-fix-2", recorded.Resumes[0].SessionID)
+
+	_, err = ms.handleSessionResume(ctx, map[string]interface{}{"repository": "github.com/acme/web", "handoff_id": handoffID, "session_id": "x"})
+	assert.Equal(t, ToolErrorNotFound, asToolError(err).Code)
+	_, err = ms.handleSessionHandoff(ctx, map[string]interface{}{"repository": "github.com/acme/api", "session_id": "empty"})
+	assert.Equal(t, ToolErrorNotFound, asToolError(err).Code)
+}
@@ -0,0 +1,291 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// sessionIdleTimeout ends sessions of connectionless transports, such as
+	// plain HTTP, that sent no request for this long
+	sessionIdleTimeout = 30 * time.Minute
+
+	// maxSessions caps tracked sessions; the least recently active session
+	// makes room for a new one
+	maxSessions = 10000
+)
+
+type sessionIDKey struct{}
+
+type sessionKey struct{}
+
+// WithSessionID returns a context naming the session requests served with
+// it belong to. Transports without connections, such as plain HTTP, attach
+// the session ID the client sends back; connection-based transports need not,
+// as their connection ID names their session.
+func WithSessionID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, sessionIDKey{}, id)
+}
+
+// sessionIDFrom returns the session ID attached to ctx, or ""
+func sessionIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(sessionIDKey{}).(string)
+	return id
+}
+
+// SessionFrom returns the session of the request being served, if the
+// client initialized one
+func SessionFrom(ctx context.Context) (*Session, bool) {
+	session, ok := ctx.Value(sessionKey{}).(*Session)
+	return session, ok
+}
+
+// Session is the state the server keeps for one client between requests.
+// Tool handlers keep per-session values in it, such as conversation history
+// or a working directory, and release resources with OnClose; everything is
+// dropped when the client disconnects or the session idles out.
+type Session struct {
+	id        string
+	createdAt time.Time
+	expires   bool // ended by idling out rather than by a disconnect
+
+	mu       sync.Mutex
+	lastSeen time.Time
+	values   map[string]interface{}
+	cleanups []func()
+	closed   bool
+}
+
+// ID returns the session ID
+func (s *Session) ID() string {
+	return s.id
+}
+
+// CreatedAt returns when the client initialized the session
+func (s *Session) CreatedAt() time.Time {
+	return s.createdAt
+}
+
+// Get returns a session value
+func (s *Session) Get(key string) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.values[key]
+	return value, ok
+}
+
+// Set stores a session value
+func (s *Session) Set(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.values == nil {
+		s.values = make(map[string]interface{})
+	}
+	s.values[key] = value
+}
+
+// Update replaces a session value with update's result, atomically with
+// respect to other calls on the session. update gets nil when the value is
+// not set and must not call the session.
+func (s *Session) Update(key string, update func(value interface{}) interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.values == nil {
+		s.values = make(map[string]interface{})
+	}
+	s.values[key] = update(s.values[key])
+}
+
+// Delete removes a session value
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+}
+
+// OnClose registers fn to run when the session ends. fn runs at once if the
+// session already ended.
+func (s *Session) OnClose(fn func()) {
+	s.mu.Lock()
+	if !s.closed {
+		s.cleanups = append(s.cleanups, fn)
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+	fn()
+}
+
+// touch records activity on the session
+func (s *Session) touch(now time.Time) {
+	s.mu.Lock()
+	s.lastSeen = now
+	s.mu.Unlock()
+}
+
+// idleSince returns when the session was last active
+func (s *Session) idleSince() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastSeen
+}
+
+// close drops the session's values and runs its cleanups
+func (s *Session) close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	cleanups := s.cleanups
+	s.cleanups, s.values = nil, nil
+	s.mu.Unlock()
+
+	for _, cleanup := range cleanups {
+		cleanup()
+	}
+}
+
+// SessionManager tracks client sessions. Sessions of connection-based
+// transports (stdio, WebSocket, SSE) are named by their connection ID and
+// end with CloseConnection; sessions opened with Open for connectionless
+// transports end with Close or after idling out. The zero value is ready
+// to use.
+type SessionManager struct {
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+// Open starts a session that ends with Close or after idling out. An empty
+// id gets a generated one; opening an existing session returns it.
+func (m *SessionManager) Open(id string) *Session {
+	if id == "" {
+		id = uuid.New().String()
+	}
+	return m.open(id, true)
+}
+
+// open returns the session named id, starting it if needed
+func (m *SessionManager) open(id string, expires bool) *Session {
+	now := time.Now()
+	m.mu.Lock()
+	if session, ok := m.sessions[id]; ok {
+		m.mu.Unlock()
+		session.touch(now)
+		return session
+	}
+
+	if m.sessions == nil {
+		m.sessions = make(map[string]*Session)
+	}
+	ended := m.expireIdle(now)
+	if len(m.sessions) >= maxSessions {
+		ended = append(ended, m.evictIdlest())
+	}
+	session := &Session{id: id, createdAt: now, lastSeen: now, expires: expires}
+	m.sessions[id] = session
+	m.mu.Unlock()
+
+	for _, s := range ended {
+		s.close()
+	}
+	return session
+}
+
+// Get returns an active session and records activity on it
+func (m *SessionManager) Get(id string) (*Session, bool) {
+	now := time.Now()
+	m.mu.Lock()
+	session, ok := m.sessions[id]
+	if ok && session.expires && now.Sub(session.idleSince()) > sessionIdleTimeout {
+		delete(m.sessions, id)
+		m.mu.Unlock()
+		session.close()
+		return nil, false
+	}
+	m.mu.Unlock()
+
+	if ok {
+		session.touch(now)
+	}
+	return session, ok
+}
+
+// Close ends a session, reporting whether it existed
+func (m *SessionManager) Close(id string) bool {
+	m.mu.Lock()
+	session, ok := m.sessions[id]
+	delete(m.sessions, id)
+	m.mu.Unlock()
+
+	if ok {
+		session.close()
+	}
+	return ok
+}
+
+// Len returns the number of active sessions
+func (m *SessionManager) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sessions)
+}
+
+// expireIdle removes expiring sessions idle past the timeout and returns
+// them for closing. Callers hold the mutex.
+func (m *SessionManager) expireIdle(now time.Time) []*Session {
+	var ended []*Session
+	for id, session := range m.sessions {
+		if session.expires && now.Sub(session.idleSince()) > sessionIdleTimeout {
+			delete(m.sessions, id)
+			ended = append(ended, session)
+		}
+	}
+	return ended
+}
+
+// evictIdlest removes the least recently active session and returns it for
+// closing. Callers hold the mutex.
+func (m *SessionManager) evictIdlest() *Session {
+	var idlest *Session
+	for _, session := range m.sessions {
+		if idlest == nil || session.idleSince().Before(idlest.idleSince()) {
+			idlest = session
+		}
+	}
+	delete(m.sessions, idlest.id)
+	return idlest
+}
+
+// Sessions returns the server's client sessions
+func (ms *MemoryServer) Sessions() *SessionManager {
+	return &ms.sessions
+}
+
+// withSession attaches the request's session to ctx. initialize starts the
+// session of a connection; other requests get the session they name, if it
+// is still active.
+func (ms *MemoryServer) withSession(ctx context.Context, method string) context.Context {
+	id, expires := sessionIDFrom(ctx), true
+	if id == "" {
+		id, expires = connectionIDFrom(ctx), false
+	}
+	if id == "" {
+		return ctx
+	}
+
+	var session *Session
+	if method == "initialize" {
+		session = ms.sessions.open(id, expires)
+	} else {
+		var ok bool
+		if session, ok = ms.sessions.Get(id); !ok {
+			return ctx
+		}
+	}
+	return context.WithValue(ctx, sessionKey{}, session)
+}
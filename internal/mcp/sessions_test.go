@@ -0,0 +1,110 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fredcamaral/gomcp-sdk"
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// registerHistoryTool adds a tool that appends its message to the session's
+// history and returns the history
+func registerHistoryTool(ms *MemoryServer) {
+	ms.addTool(mcp.NewTool("remember", "Remember", mcp.ObjectSchema("Remember", map[string]interface{}{}, nil)),
+		mcp.ToolHandlerFunc(func(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+			session, ok := SessionFrom(ctx)
+			if !ok {
+				return "no session", nil
+			}
+			var history []string
+			session.Update("history", func(value interface{}) interface{} {
+				history, _ = value.([]string)
+				history = append(history, params["message"].(string))
+				return history
+			})
+			return history, nil
+		}))
+}
+
+// resultText returns the text of a tool call response
+func resultText(t *testing.T, resp *protocol.JSONRPCResponse) string {
+	t.Helper()
+	result, ok := resp.Result.(*protocol.ToolCallResult)
+	require.True(t, ok)
+	require.NotEmpty(t, result.Content)
+	return result.Content[0].Text
+}
+
+func TestSessionsIsolateConnections(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	registerHistoryTool(ms)
+	remember := func(ctx context.Context, message string) string {
+		resp := ms.HandleRequest(ctx, toolCallRequest("remember", map[string]interface{}{"message": message}))
+		require.Nil(t, resp.Error)
+		return resultText(t, resp)
+	}
+
+	initializeConnection(t, ms, "conn-a", nil)
+	initializeConnection(t, ms, "conn-b", nil)
+	connA := WithConnectionID(context.Background(), "conn-a")
+	connB := WithConnectionID(context.Background(), "conn-b")
+
+	remember(connA, "first")
+	assert.Equal(t, `["first","second"]`, remember(connA, "second"))
+	assert.Equal(t, `["other"]`, remember(connB, "other"), "connections keep separate state")
+	assert.Equal(t, "no session", remember(context.Background(), "anonymous"), "requests without a session share nothing")
+	assert.Equal(t, 2, ms.Sessions().Len())
+
+	// Disconnecting runs cleanups and drops the state
+	session, ok := ms.Sessions().Get("conn-a")
+	require.True(t, ok)
+	cleaned := false
+	session.OnClose(func() { cleaned = true })
+	ms.CloseConnection("conn-a")
+	assert.True(t, cleaned)
+	_, ok = session.Get("history")
+	assert.False(t, ok)
+	assert.Equal(t, "no session", remember(connA, "after disconnect"), "a closed connection must initialize again")
+
+	initializeConnection(t, ms, "conn-a", nil)
+	assert.Equal(t, `["fresh"]`, remember(connA, "fresh"))
+}
+
+func TestSessionManager_OpenedSessionsIdleOut(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	registerHistoryTool(ms)
+
+	session := ms.Sessions().Open("")
+	require.NotEmpty(t, session.ID())
+	assert.Same(t, session, ms.Sessions().Open(session.ID()), "opening an existing session returns it")
+
+	ctx := WithSessionID(context.Background(), session.ID())
+	resp := ms.HandleRequest(ctx, toolCallRequest("remember", map[string]interface{}{"message": "over http"}))
+	require.Nil(t, resp.Error)
+	assert.Equal(t, `["over http"]`, resultText(t, resp))
+
+	cleaned := false
+	session.OnClose(func() { cleaned = true })
+	session.touch(time.Now().Add(-sessionIdleTimeout - time.Minute))
+	_, ok := ms.Sessions().Get(session.ID())
+	assert.False(t, ok)
+	assert.True(t, cleaned)
+
+	// Connection sessions end with their connection, not by idling out
+	initializeConnection(t, ms, "conn-a", nil)
+	bound, ok := ms.Sessions().Get("conn-a")
+	require.True(t, ok)
+	bound.touch(time.Now().Add(-sessionIdleTimeout - time.Minute))
+	_, ok = ms.Sessions().Get("conn-a")
+	assert.True(t, ok)
+
+	assert.True(t, ms.Sessions().Close("conn-a"))
+	assert.False(t, ms.Sessions().Close("conn-a"))
+	ran := false
+	bound.OnClose(func() { ran = true })
+	assert.True(t, ran, "cleanups registered after the end run at once")
+}
@@ -0,0 +1,212 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/fredcamaral/gomcp-sdk/notifications"
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+const (
+	// ShuttingDownCode is the JSON-RPC error code of requests refused while
+	// the server shuts down, in the server error range used for unavailable
+	// services; clients may retry them elsewhere or after a restart
+	ShuttingDownCode = -32002
+
+	// flushTransportType identifies the notifier handler that sees the flush marker
+	flushTransportType = "shutdown-flush"
+	// flushClientID is the notifier client the flush marker is queued for
+	flushClientID = "shutdown-flush"
+	// flushMarkerMethod names the flush marker; it never reaches a client
+	flushMarkerMethod = "$/shutdown/flush"
+)
+
+// ErrShuttingDown is returned by Shutdown when the server already shuts down
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// errNotFlushMarker makes the flush handler pass on ordinary notifications
+var errNotFlushMarker = errors.New("not the flush marker")
+
+// ShutdownHook closes a transport or other resource when the server shuts
+// down, within ctx's deadline
+type ShutdownHook func(ctx context.Context) error
+
+// tracker counts running work so shutdown can wait for it
+type tracker struct {
+	mu      sync.Mutex
+	running int
+	idle    chan struct{}
+}
+
+func (t *tracker) add() {
+	t.mu.Lock()
+	t.running++
+	t.mu.Unlock()
+}
+
+func (t *tracker) done() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.running--
+	if t.running == 0 && t.idle != nil {
+		close(t.idle)
+		t.idle = nil
+	}
+}
+
+// wait returns once nothing runs, or ctx's error when it is done first
+func (t *tracker) wait(ctx context.Context) error {
+	t.mu.Lock()
+	if t.running == 0 {
+		t.mu.Unlock()
+		return nil
+	}
+	if t.idle == nil {
+		t.idle = make(chan struct{})
+	}
+	idle := t.idle
+	t.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// drainState refuses requests once shutdown begins and tracks the work
+// shutdown waits for
+type drainState struct {
+	mu       sync.Mutex
+	draining bool
+	hooks    []ShutdownHook
+
+	requests   tracker // requests being served
+	background tracker // work requests left running, such as event deliveries
+}
+
+// enter admits a request, reporting false once shutdown began. Admitted
+// requests call requests.done when served.
+func (d *drainState) enter() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return false
+	}
+	d.requests.add()
+	return true
+}
+
+// begin starts draining, reporting false if it already started
+func (d *drainState) begin() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return false
+	}
+	d.draining = true
+	return true
+}
+
+// AddShutdownHook adds a hook Shutdown runs once requests drained and
+// notifications were flushed. Transports register hooks that close their
+// connections; hooks run in the order they were added.
+func (ms *MemoryServer) AddShutdownHook(hook ShutdownHook) {
+	ms.drain.mu.Lock()
+	defer ms.drain.mu.Unlock()
+	ms.drain.hooks = append(ms.drain.hooks, hook)
+}
+
+// Shutdown stops the server gracefully. New requests are refused with
+// ShuttingDownCode while requests in flight, and the bulk operations and
+// event deliveries they started, run to completion. Queued notifications are
+// then delivered, the shutdown hooks close the transports and the storage is
+// closed, flushing queued writes. If ctx is done first, Shutdown goes on
+// closing and returns the context's error among the others.
+func (ms *MemoryServer) Shutdown(ctx context.Context) error {
+	if !ms.drain.begin() {
+		return ErrShuttingDown
+	}
+
+	var errs []error
+	if err := ms.drain.requests.wait(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("requests still in flight: %w", err))
+	}
+	if ms.bulkManager != nil {
+		if err := ms.bulkManager.Wait(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("bulk operations still running: %w", err))
+		}
+	}
+	if err := ms.drain.background.wait(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("event deliveries still running: %w", err))
+	}
+
+	// Stop background loops such as decay and page sync
+	if ms.stopBackground != nil {
+		ms.stopBackground()
+	}
+
+	if ms.notifier != nil && ms.started.Load() {
+		if err := ms.flushNotifications(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("notifications still queued: %w", err))
+		}
+		ms.notifier.Stop()
+	}
+
+	ms.drain.mu.Lock()
+	hooks := ms.drain.hooks
+	ms.drain.mu.Unlock()
+	for _, hook := range hooks {
+		if err := hook(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if ms.container != nil {
+		if err := ms.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// flushNotifications waits until the notifier delivered what it queued. The
+// notifier delivers in order, so a marker queued last arrives after the rest.
+func (ms *MemoryServer) flushNotifications(ctx context.Context) error {
+	flushed := make(chan struct{})
+	var once sync.Once
+	ms.notifier.RegisterHandler(flushTransportType, func(clientID string, notification *notifications.Notification) error {
+		if clientID != flushClientID {
+			return errNotFlushMarker
+		}
+		if notification.Method == flushMarkerMethod {
+			once.Do(func() { close(flushed) })
+		}
+		return nil
+	})
+	ms.notifier.RegisterClient(flushClientID, false, false)
+	defer ms.notifier.UnregisterClient(flushClientID)
+
+	if err := ms.notifier.SendToClient(flushClientID, flushMarkerMethod, struct{}{}); err != nil {
+		return err
+	}
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// shuttingDownResponse refuses a request that arrived during shutdown.
+// Notifications get no response.
+func shuttingDownResponse(req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+	if req.ID == nil {
+		return nil
+	}
+	return ErrorResponse(req, ShuttingDownCode, "Server is shutting down", nil)
+}
@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fredcamaral/gomcp-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdownDrainsRequests(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	ms.addTool(mcp.NewTool("slow", "Slow", mcp.ObjectSchema("Slow", map[string]interface{}{}, nil)),
+		mcp.ToolHandlerFunc(func(context.Context, map[string]interface{}) (interface{}, error) {
+			close(started)
+			<-release
+			return "done", nil
+		}))
+
+	served := make(chan string, 1)
+	go func() {
+		resp := ms.HandleRequest(context.Background(), toolCallRequest("slow", nil))
+		served <- resultText(t, resp)
+	}()
+	<-started
+
+	var hooks []string
+	ms.AddShutdownHook(func(context.Context) error {
+		hooks = append(hooks, "first")
+		return nil
+	})
+	ms.AddShutdownHook(func(context.Context) error {
+		hooks = append(hooks, "second")
+		return nil
+	})
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- ms.Shutdown(context.Background()) }()
+
+	// Requests arriving while draining are refused; notifications are dropped
+	require.Eventually(t, func() bool {
+		resp := ms.HandleRequest(context.Background(), toolCallRequest("echo", nil))
+		return resp.Error != nil && resp.Error.Code == ShuttingDownCode
+	}, time.Second, time.Millisecond)
+	notification := toolCallRequest("echo", nil)
+	notification.ID = nil
+	assert.Nil(t, ms.HandleRequest(context.Background(), notification))
+
+	select {
+	case err := <-stopped:
+		t.Fatalf("Shutdown returned with a request in flight: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	assert.Empty(t, hooks, "transports closed before requests drained")
+
+	close(release)
+	assert.Equal(t, "done", <-served)
+	require.NoError(t, <-stopped)
+	assert.Equal(t, []string{"first", "second"}, hooks)
+
+	assert.ErrorIs(t, ms.Shutdown(context.Background()), ErrShuttingDown)
+}
+
+func TestShutdownHonoursDeadline(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	release := make(chan struct{})
+	defer close(release)
+	ms.drain.background.add()
+	go func() {
+		<-release
+		ms.drain.background.done()
+	}()
+
+	closed := false
+	ms.AddShutdownHook(func(context.Context) error {
+		closed = true
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := ms.Shutdown(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, closed, "transports are closed once the deadline passed")
+}
@@ -0,0 +1,55 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/sitegen"
+)
+
+// handleExportSite renders a project's decisions, patterns and verified
+// solutions as a static site under the configured output directory
+func (ms *MemoryServer) handleExportSite(ctx context.Context, options map[string]interface{}) (interface{}, error) {
+	repository, _ := options["repository"].(string)
+	if repository == "" {
+		return nil, errors.New("repository is required for export_site. Example: {\"operation\": \"export_site\", \"options\": {\"repository\": \"github.com/user/repo\"}}")
+	}
+	title, _ := options["title"].(string)
+
+	site, err := sitegen.NewBuilder(ms.container.GetVectorStore()).Build(ctx, repository, title, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect knowledge base: %w", err)
+	}
+
+	outputDir := config.DefaultConfig().Site.OutputDir
+	if ms.container.Config != nil && ms.container.Config.Site.OutputDir != "" {
+		outputDir = ms.container.Config.Site.OutputDir
+	}
+	// Each project gets its own directory; the name never escapes the output directory
+	dir := filepath.Join(outputDir, strings.TrimSuffix(sitegen.PageFile(repository), ".html"))
+	if err := sitegen.Write(site, dir); err != nil {
+		return nil, fmt.Errorf("failed to write site: %w", err)
+	}
+
+	counts := make(map[string]int, len(sitegen.Kinds))
+	for _, kind := range sitegen.Kinds {
+		counts[string(kind)] = len(site.Section(kind))
+	}
+	logging.Info("Exported knowledge base site", "repository", repository, "dir", dir, "entries", len(site.Entries))
+	return map[string]interface{}{
+		"status":        "success",
+		"operation":     "export_site",
+		"repository":    repository,
+		"path":          dir,
+		"index":         filepath.Join(dir, "index.html"),
+		"entries":       counts,
+		"relationships": len(site.Edges),
+		"generated_at":  site.GeneratedAt,
+	}, nil
+}
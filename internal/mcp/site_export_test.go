@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleMemoryTransfer_ExportSite(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	require.NoError(t, store.Store(ctx, &types.ConversationChunk{
+		ID:         "decision-1",
+		SessionID:  "session-1",
+		Type:       types.ChunkTypeArchitectureDecision,
+		Content:    "Adopt event sourcing for billing",
+		Metadata:   types.ChunkMetadata{Repository: "github.com/acme/api"},
+		Embeddings: []float64{0.1, 0.2},
+	}))
+
+	ms := newCompositeTestServer(t, store)
+	ms.container.Config = config.DefaultConfig()
+	ms.container.Config.Site.OutputDir = t.TempDir()
+
+	result, err := ms.handleMemoryTransfer(ctx, map[string]interface{}{
+		"operation": "export_site",
+		"options":   map[string]interface{}{"repository": "github.com/acme/api", "title": "Acme API"},
+	})
+	require.NoError(t, err)
+
+	response := result.(map[string]interface{})
+	dir := response["path"].(string)
+	assert.Equal(t, filepath.Join(ms.container.Config.Site.OutputDir, "github-com-acme-api"), dir)
+	assert.Equal(t, 1, response["entries"].(map[string]int)["decision"])
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(index), "Adopt event sourcing for billing")
+	assert.FileExists(t, filepath.Join(dir, "entries", "decision-1.html"))
+
+	_, err = ms.handleMemoryTransfer(ctx, map[string]interface{}{
+		"operation": "export_site",
+		"options":   map[string]interface{}{},
+	})
+	assert.ErrorContains(t, err, "repository parameter is required")
+}
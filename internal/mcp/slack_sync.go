@@ -0,0 +1,185 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/slacksync"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/fredcamaral/gomcp-sdk"
+)
+
+// slackSyncCaptureTool names the importer in the provenance of Slack conversations
+const slackSyncCaptureTool = "slack_sync"
+
+// slackIngester stores synced Slack conversations as memories
+type slackIngester struct {
+	ms *MemoryServer
+}
+
+// IngestConversation stores a conversation as memories, one per part, then
+// trashes the memories imported from its previous version
+func (s slackIngester) IngestConversation(ctx context.Context, spec *slacksync.ChannelSpec, conversation *slacksync.Conversation, previous []string) ([]string, error) {
+	parts := conversation.Parts(slacksync.MaxPartRunes)
+	sessionID := s.ms.createRepositoryScopedSessionID(spec.Repository, "slack-"+spec.ID)
+	channel := "#" + spec.DisplayName()
+	usefulness := conversation.UsefulnessHint()
+
+	chunkIDs := make([]string, 0, len(parts))
+	for i, part := range parts {
+		metadata := types.ChunkMetadata{
+			Repository: spec.Repository,
+			Tags:       []string{"imported", "conversation", "slack", "source:slack"},
+			Provenance: &types.Provenance{
+				SourceSystem: "slack",
+				SourceURL:    conversation.URL,
+				Author:       conversation.Author(),
+				CaptureTool:  slackSyncCaptureTool,
+			},
+		}
+
+		title := "# Slack " + channel
+		if conversation.ThreadTS != "" {
+			title += " thread"
+		}
+		content := title + " (" + conversation.Start().UTC().Format("2006-01-02") + ")\n\n" + part
+
+		chunk, err := s.ms.container.GetChunkingService().CreateChunk(ctx, sessionID, content, &metadata)
+		if err != nil {
+			return chunkIDs, fmt.Errorf("failed to create chunk for part %d: %w", i+1, err)
+		}
+		chunk.Type = types.ChunkTypeDiscussion
+
+		// Chunking rebuilds extended metadata, so conversation references are added after it
+		if chunk.Metadata.ExtendedMetadata == nil {
+			chunk.Metadata.ExtendedMetadata = make(map[string]interface{})
+		}
+		chunk.Metadata.ExtendedMetadata["slack_channel"] = spec.ID
+		chunk.Metadata.ExtendedMetadata["slack_conversation"] = conversation.Key()
+		if conversation.ThreadTS != "" {
+			chunk.Metadata.ExtendedMetadata["slack_thread_ts"] = conversation.ThreadTS
+		}
+		chunk.Metadata.ExtendedMetadata["slack_participants"] = conversation.Participants()
+		if reactions := conversation.Reactions(); len(reactions) > 0 {
+			chunk.Metadata.ExtendedMetadata["slack_reactions"] = reactions
+		}
+		chunk.Metadata.ExtendedMetadata[types.EMKeyUsefulnessHint] = usefulness
+		s.ms.setPersonReference(&chunk.Metadata, types.EMKeyAuthorPersonID, conversation.Author())
+
+		if err := s.ms.processAndStoreChunk(ctx, chunk); err != nil {
+			return chunkIDs, fmt.Errorf("failed to store part %d: %w", i+1, err)
+		}
+		chunkIDs = append(chunkIDs, chunk.ID)
+	}
+
+	// The previous version of a thread is only replaced once the new one is stored
+	store := s.ms.container.GetVectorStore()
+	now := time.Now()
+	for _, id := range previous {
+		chunk, err := store.GetByID(ctx, id)
+		if err != nil || chunk.IsDeleted() {
+			continue
+		}
+		if err := s.ms.trashChunk(ctx, chunk, now); err != nil {
+			logging.Warn("Failed to trash previous slack thread", "thread", conversation.ThreadTS, "chunk_id", id, "error", err)
+		}
+	}
+	return chunkIDs, nil
+}
+
+// initSlackSync creates the Slack syncer and registers the configured channels
+func (ms *MemoryServer) initSlackSync(cfg *config.Config) {
+	if !cfg.SlackSync.Enabled {
+		return
+	}
+
+	ms.slackSyncer = slacksync.NewSyncer(cfg.SlackSync.StatePath, slackIngester{ms: ms})
+	if err := ms.slackSyncer.Load(); err != nil {
+		logging.Error("Failed to load slack sync state", "path", cfg.SlackSync.StatePath, "error", err)
+	}
+	if cfg.SlackSync.ChannelsFile == "" {
+		return
+	}
+	if cfg.SlackSync.Token == "" {
+		logging.Error("Slack sync requires MCP_MEMORY_SLACK_TOKEN; no channels will be synced")
+		return
+	}
+
+	specs, err := slacksync.LoadChannels(cfg.SlackSync.ChannelsFile)
+	if err != nil {
+		logging.Error("Failed to load slack channels", "file", cfg.SlackSync.ChannelsFile, "error", err)
+		return
+	}
+	client := slacksync.NewClient("", cfg.SlackSync.Token, nil)
+	for i := range specs {
+		if err := ms.slackSyncer.AddChannel(&specs[i], client); err != nil {
+			logging.Warn("Skipping slack channel", "channel", specs[i].ID, "error", err)
+		}
+	}
+}
+
+// registerSlackSyncTool registers system_slack_sync
+func (ms *MemoryServer) registerSlackSyncTool() {
+	ms.addTool(mcp.NewTool(
+		"system_slack_sync",
+		"Inspect and trigger the import of Slack channel history. Each thread is stored as one conversation memory and other messages are grouped by when they were sent; authors are linked to people, and reactions are kept as a usefulness hint. Channels are configured by the operator and synced incrementally and periodically; threads that receive new replies within a week are re-imported and their previous version moved to trash. Operations: list (channels and sync progress), sync (sync one channel now). Only callers owning every project may use it.",
+		mcp.ObjectSchema("Slack sync parameters", map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"list", "sync"},
+				"description": "Slack sync operation",
+			},
+			"channel": map[string]interface{}{
+				"type":        "string",
+				"description": "ID of the channel to sync (sync)",
+			},
+		}, []string{"operation"}),
+	), mcp.ToolHandlerFunc(ms.handleSlackSync))
+}
+
+// handleSlackSync lists synced Slack channels or syncs one on demand
+func (ms *MemoryServer) handleSlackSync(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: system_slack_sync called", "args", args)
+
+	if ms.slackSyncer == nil {
+		return nil, errors.New("slack sync is disabled: set MCP_MEMORY_SLACK_SYNC_ENABLED=true, MCP_MEMORY_SLACK_TOKEN and MCP_MEMORY_SLACK_CHANNELS_FILE")
+	}
+	// Sources are configured by the operator and write into their projects
+	// whoever triggers them
+	if err := checkOperator(ctx, "system_slack_sync"); err != nil {
+		return nil, err
+	}
+
+	operation, _ := args["operation"].(string)
+	switch operation {
+	case "list":
+		channels := ms.slackSyncer.Channels()
+		return map[string]interface{}{
+			"status":    "success",
+			"operation": operation,
+			"channels":  channels,
+			"count":     len(channels),
+		}, nil
+	case "sync":
+		channel, _ := args["channel"].(string)
+		if channel == "" {
+			return nil, errors.New("channel is required for sync. Example: {\"operation\": \"sync\", \"channel\": \"C0123ABCD\"}")
+		}
+		result, err := ms.slackSyncer.Sync(ctx, channel)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"status":    "success",
+			"operation": operation,
+			"result":    result,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown operation %q: use list or sync", operation)
+	}
+}
@@ -0,0 +1,132 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/chunking"
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/slacksync"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/tenancy"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticSlackSource serves the same channel history on every sync
+type staticSlackSource struct {
+	messages []slacksync.Message
+}
+
+func (s *staticSlackSource) History(context.Context, string, time.Time) ([]slacksync.Message, error) {
+	return s.messages, nil
+}
+
+func (s *staticSlackSource) Replies(context.Context, string, string) ([]slacksync.Message, error) {
+	return nil, nil
+}
+
+func (s *staticSlackSource) Permalink(_ context.Context, channel, ts string) string {
+	return "https://acme.slack.com/archives/" + channel + "/p" + strings.ReplaceAll(ts, ".", "")
+}
+
+func newSlackSyncTestServer(t *testing.T, store storage.VectorStore) *MemoryServer {
+	t.Helper()
+	ms := newCompositeTestServer(t, store)
+	ms.container.ChunkingService = chunking.NewService(&config.DefaultConfig().Chunking, staticEmbeddingService{})
+	ms.slackSyncer = slacksync.NewSyncer("", slackIngester{ms: ms})
+	return ms
+}
+
+func slackMessage(at time.Time, user, text string) slacksync.Message {
+	return slacksync.Message{TS: slacksync.FormatTS(at), User: user, Text: text, Time: at}
+}
+
+func TestSlackIngester_StoresConversationWithHints(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	ms := newSlackSyncTestServer(t, store)
+
+	start := time.Now().Add(-time.Hour).UTC()
+	question := slackMessage(start, "Ana <ana@acme.io>", "Why do deploys to eu-west hang?")
+	question.ReplyCount = 1
+	answer := slackMessage(start.Add(time.Minute), "Bruno <bruno@acme.io>", "The migration job holds a lock; run it first.")
+	answer.Reactions = map[string]int{"white_check_mark": 1}
+	spec := &slacksync.ChannelSpec{ID: "C01", Name: "deploys", Repository: "github.com/acme/api"}
+	conversation := &slacksync.Conversation{
+		ThreadTS: question.TS,
+		Messages: []slacksync.Message{question, answer},
+		URL:      "https://acme.slack.com/archives/C01/p1",
+	}
+
+	ids, err := slackIngester{ms: ms}.IngestConversation(ctx, spec, conversation, nil)
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+
+	chunk, err := store.GetByID(ctx, ids[0])
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(chunk.Content, "# Slack #deploys thread ("))
+	assert.Contains(t, chunk.Content, "**Bruno** (")
+	assert.Equal(t, types.ChunkTypeDiscussion, chunk.Type)
+	assert.Contains(t, chunk.Metadata.Tags, "source:slack")
+	require.NotNil(t, chunk.Metadata.Provenance)
+	assert.Equal(t, "slack", chunk.Metadata.Provenance.SourceSystem)
+	assert.Equal(t, conversation.URL, chunk.Metadata.Provenance.SourceURL)
+	assert.Equal(t, "Ana <ana@acme.io>", chunk.Metadata.Provenance.Author)
+	assert.Equal(t, "C01", chunk.Metadata.ExtendedMetadata["slack_channel"])
+	assert.Equal(t, question.TS, chunk.Metadata.ExtendedMetadata["slack_thread_ts"])
+	assert.InDelta(t, 0.5, chunk.Metadata.ExtendedMetadata[types.EMKeyUsefulnessHint], 0.001)
+
+	// Re-importing the thread trashes its previous version
+	updated, err := slackIngester{ms: ms}.IngestConversation(ctx, spec, conversation, ids)
+	require.NoError(t, err)
+	old, err := store.GetByID(ctx, ids[0])
+	require.NoError(t, err)
+	assert.True(t, old.IsDeleted())
+	current, err := store.GetByID(ctx, updated[0])
+	require.NoError(t, err)
+	assert.False(t, current.IsDeleted())
+}
+
+func TestHandleSlackSync(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	ms := newSlackSyncTestServer(t, store)
+	now := time.Now().UTC()
+	source := &staticSlackSource{messages: []slacksync.Message{
+		slackMessage(now.Add(-2*time.Hour), "Ana", "Release train leaves at 3pm"),
+		slackMessage(now.Add(-2*time.Hour+time.Minute), "Bruno", "I'll tag it"),
+	}}
+	require.NoError(t, ms.slackSyncer.AddChannel(&slacksync.ChannelSpec{ID: "C01", Repository: "github.com/acme/api"}, source))
+
+	result, err := ms.handleSlackSync(ctx, map[string]interface{}{"operation": "sync", "channel": "C01"})
+	require.NoError(t, err)
+	synced := result.(map[string]interface{})["result"].(*slacksync.Result)
+	assert.Equal(t, 1, synced.Imported)
+	assert.Equal(t, 1, synced.Chunks)
+
+	result, err = ms.handleSlackSync(ctx, map[string]interface{}{"operation": "sync", "channel": "C01"})
+	require.NoError(t, err)
+	assert.Zero(t, result.(map[string]interface{})["result"].(*slacksync.Result).Imported, "synced messages are not imported again")
+
+	result, err = ms.handleSlackSync(ctx, map[string]interface{}{"operation": "list"})
+	require.NoError(t, err)
+	channels := result.(map[string]interface{})["channels"].([]slacksync.ChannelStatus)
+	require.Len(t, channels, 1)
+	assert.Equal(t, 1, channels[0].Conversations)
+
+	_, err = ms.handleSlackSync(ctx, map[string]interface{}{"operation": "sync"})
+	assert.ErrorContains(t, err, "channel is required")
+
+	tenant := tenancy.WithTenant(ctx, &tenancy.Tenant{ID: "api_key:acme", Projects: []string{"github.com/acme/api"}})
+	_, err = ms.handleSlackSync(tenant, map[string]interface{}{"operation": "sync", "channel": "C01"})
+	assert.ErrorIs(t, err, tenancy.ErrCrossTenant)
+
+	ms.slackSyncer = nil
+	_, err = ms.handleSlackSync(ctx, map[string]interface{}{"operation": "list"})
+	assert.ErrorContains(t, err, "slack sync is disabled")
+}
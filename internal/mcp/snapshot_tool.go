@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"lerian-mcp-memory/internal/logging"
+
+	"github.com/fredcamaral/gomcp-sdk"
+)
+
+// registerSnapshotTool registers system_snapshot
+func (ms *MemoryServer) registerSnapshotTool() {
+	ms.addTool(mcp.NewTool(
+		"system_snapshot",
+		"Point-in-time snapshots of the whole memory state (every chunk and relationship). Operations: create (take a snapshot; old ones are pruned by the retention policy), list (snapshots with sizes, newest first), restore (return memory to a snapshot, deleting anything created since; a safety snapshot of the current state is taken first), delete (remove a snapshot). Queued writes are flushed first so snapshots are consistent. Only callers not held to a tenant, or owning every project, may use it.",
+		mcp.ObjectSchema("Snapshot parameters", map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"create", "list", "restore", "delete"},
+				"description": "Snapshot operation",
+			},
+			"label": map[string]interface{}{
+				"type":        "string",
+				"description": "Note stored with the snapshot, e.g. 'before bulk import' (create)",
+			},
+			"snapshot_id": map[string]interface{}{
+				"type":        "string",
+				"description": "Snapshot to restore or delete, as returned by create or list",
+			},
+			"safety_snapshot": map[string]interface{}{
+				"type":        "boolean",
+				"default":     true,
+				"description": "Snapshot the current state before restoring so the restore can be undone (restore)",
+			},
+		}, []string{"operation"}),
+	), mcp.ToolHandlerFunc(ms.handleSnapshot))
+}
+
+// handleSnapshot creates, lists, restores or deletes snapshots
+func (ms *MemoryServer) handleSnapshot(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: system_snapshot called", "args", args)
+
+	manager := ms.container.GetSnapshotManager()
+	if manager == nil {
+		return nil, errors.New("snapshots are not available")
+	}
+	if err := checkOperator(ctx, "system_snapshot"); err != nil {
+		return nil, err
+	}
+
+	operation, _ := args["operation"].(string)
+	snapshotID, _ := args["snapshot_id"].(string)
+
+	switch operation {
+	case "create":
+		label, _ := args["label"].(string)
+		snapshot, pruned, err := manager.Create(ctx, label)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create snapshot: %w", err)
+		}
+		logging.Info("Snapshot created", "snapshot_id", snapshot.ID, "chunks", snapshot.ChunkCount, "size_bytes", snapshot.Size, "pruned", len(pruned))
+		return map[string]interface{}{
+			"status":    "success",
+			"operation": operation,
+			"snapshot":  snapshot,
+			"pruned":    pruned,
+			"retention": manager.Retention(),
+		}, nil
+
+	case "list":
+		snapshots, err := manager.List()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list snapshots: %w", err)
+		}
+		var totalSize int64
+		for i := range snapshots {
+			totalSize += snapshots[i].Size
+		}
+		return map[string]interface{}{
+			"status":           "success",
+			"operation":        operation,
+			"snapshots":        snapshots,
+			"count":            len(snapshots),
+			"total_size_bytes": totalSize,
+			"retention":        manager.Retention(),
+		}, nil
+
+	case "restore":
+		if snapshotID == "" {
+			return nil, errors.New("snapshot_id is required for restore")
+		}
+		safety := true
+		if value, ok := args["safety_snapshot"].(bool); ok {
+			safety = value
+		}
+		result, err := manager.Restore(ctx, snapshotID, safety)
+		if err != nil {
+			if result != nil && result.SafetySnapshotID != "" {
+				return nil, fmt.Errorf("failed to restore snapshot %s (restore %s to undo partial changes): %w", snapshotID, result.SafetySnapshotID, err)
+			}
+			return nil, fmt.Errorf("failed to restore snapshot %s: %w", snapshotID, err)
+		}
+		logging.Warn("Memory restored from snapshot", "snapshot_id", snapshotID, "chunks_restored", result.ChunksRestored, "chunks_deleted", result.ChunksDeleted)
+		return map[string]interface{}{
+			"status":    "success",
+			"operation": operation,
+			"restore":   result,
+		}, nil
+
+	case "delete":
+		if snapshotID == "" {
+			return nil, errors.New("snapshot_id is required for delete")
+		}
+		if err := manager.Delete(snapshotID); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"status":      "success",
+			"operation":   operation,
+			"snapshot_id": snapshotID,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("invalid operation: %s (must be create, list, restore or delete)", operation)
+	}
+}
@@ -0,0 +1,59 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/persistence"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/tenancy"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleSnapshot(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewKeywordStore("")
+	chunk := newReportChunk(t, "session-1", "Use exponential backoff for retries", types.ChunkTypeSolution, types.ChunkMetadata{})
+	require.NoError(t, store.Store(ctx, chunk))
+
+	ms := newCompositeTestServer(t, store)
+	ms.container.SnapshotManager = persistence.NewSnapshotManager(store, t.TempDir())
+
+	result, err := ms.handleSnapshot(ctx, map[string]interface{}{"operation": "create", "label": "before cleanup"})
+	require.NoError(t, err)
+	snapshot := result.(map[string]interface{})["snapshot"].(*persistence.SnapshotMetadata)
+	assert.Equal(t, 1, snapshot.ChunkCount)
+
+	require.NoError(t, store.Delete(ctx, chunk.ID))
+
+	result, err = ms.handleSnapshot(ctx, map[string]interface{}{"operation": "list"})
+	require.NoError(t, err)
+	listing := result.(map[string]interface{})
+	assert.Equal(t, 1, listing["count"])
+	assert.Equal(t, snapshot.Size, listing["total_size_bytes"])
+
+	result, err = ms.handleSnapshot(ctx, map[string]interface{}{"operation": "restore", "snapshot_id": snapshot.ID, "safety_snapshot": false})
+	require.NoError(t, err)
+	restore := result.(map[string]interface{})["restore"].(*persistence.RestoreResult)
+	assert.Equal(t, 1, restore.ChunksRestored)
+	assert.Empty(t, restore.SafetySnapshotID)
+	_, err = store.GetByID(ctx, chunk.ID)
+	assert.NoError(t, err)
+
+	_, err = ms.handleSnapshot(ctx, map[string]interface{}{"operation": "restore"})
+	assert.ErrorContains(t, err, "snapshot_id is required")
+	_, err = ms.handleSnapshot(ctx, map[string]interface{}{"operation": "delete", "snapshot_id": snapshot.ID})
+	assert.NoError(t, err)
+	_, err = ms.handleSnapshot(ctx, map[string]interface{}{"operation": "rollback"})
+	assert.ErrorContains(t, err, "invalid operation")
+
+	tenant := tenancy.WithTenant(ctx, &tenancy.Tenant{ID: "api_key:k1", Projects: []string{"acme/api"}})
+	_, err = ms.handleSnapshot(tenant, map[string]interface{}{"operation": "list"})
+	assert.ErrorIs(t, err, tenancy.ErrCrossTenant, "snapshots span every tenant")
+	operator := tenancy.WithTenant(ctx, &tenancy.Tenant{ID: "api_key:ops", Projects: []string{tenancy.AllProjects}})
+	_, err = ms.handleSnapshot(operator, map[string]interface{}{"operation": "list"})
+	assert.NoError(t, err)
+}
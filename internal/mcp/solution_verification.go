@@ -0,0 +1,192 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"lerian-mcp-memory/internal/audit"
+	"lerian-mcp-memory/internal/digest"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/pkg/types"
+)
+
+// Solution verification operations
+const (
+	// OperationVerifySolution records whether a stored solution worked (memory_update)
+	OperationVerifySolution = "verify_solution"
+	// OperationVerificationCoverage reports how many solutions were verified (memory_analyze)
+	OperationVerificationCoverage = "verification_coverage"
+)
+
+// handleVerifySolution marks a stored solution verified or failed, with
+// links to the evidence. The verdict is kept in the chunk's verification
+// history and sets its outcome, which search uses to rank it.
+func (ms *MemoryServer) handleVerifySolution(ctx context.Context, options map[string]interface{}) (interface{}, error) {
+	repository, _ := options["repository"].(string)
+	chunkID, _ := options["chunk_id"].(string)
+	status, _ := options["status"].(string)
+	if repository == "" || chunkID == "" || status == "" {
+		return nil, errors.New("verify_solution requires repository, chunk_id and status (verified or failed). Example: {\"operation\": \"verify_solution\", \"options\": {\"repository\": \"github.com/user/repo\", \"chunk_id\": \"uuid\", \"status\": \"verified\", \"evidence\": [\"https://ci.example.com/runs/42\"]}}")
+	}
+
+	record := types.VerificationRecord{Status: status, At: time.Now().UTC()}
+	record.Note, _ = options["note"].(string)
+	if rawEvidence, ok := options["evidence"].([]interface{}); ok {
+		for _, e := range rawEvidence {
+			link, _ := e.(string)
+			record.Evidence = append(record.Evidence, link)
+		}
+	}
+	if err := record.Validate(); err != nil {
+		return nil, err
+	}
+	lockToken, _ := options["lock_token"].(string)
+
+	verified, err := ms.updateChunk(ctx, chunkID, lockToken, func(chunk *types.ConversationChunk) error {
+		if chunk.Metadata.Repository != repository {
+			return fmt.Errorf("chunk %s not found in repository %s", chunkID, repository)
+		}
+		if chunk.IsDeleted() {
+			return fmt.Errorf("chunk %s is in the trash; restore it before verifying", chunkID)
+		}
+		chunk.RecordVerification(record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ms.container.AuditLogger.LogEvent(ctx, audit.EventTypeMemoryUpdate, OperationVerifySolution, "chunk", chunkID, map[string]interface{}{
+		"repository": repository,
+		"status":     status,
+		"evidence":   record.Evidence,
+	})
+	logging.Info("Solution verification recorded", "chunk_id", chunkID, "status", status)
+	ms.publishEvent(chunkEvent(digest.EventVerification, verified, map[string]interface{}{
+		"status":   status,
+		"evidence": record.Evidence,
+	}))
+
+	return map[string]interface{}{
+		"status":               "success",
+		"chunk_id":             chunkID,
+		"repository":           repository,
+		"verification_status":  status,
+		"outcome":              verified.Metadata.Outcome,
+		"verification_history": verified.VerificationHistory(),
+	}, nil
+}
+
+// verificationCoverage counts a set of solutions by verification status
+type verificationCoverage struct {
+	Solutions  int `json:"solutions"`
+	Verified   int `json:"verified"`
+	Failed     int `json:"failed"`
+	Unverified int `json:"unverified"`
+	// Coverage is the fraction of solutions with a verdict
+	Coverage float64 `json:"coverage"`
+	// VerifiedRate is the fraction of verdicts that confirmed the solution
+	VerifiedRate float64 `json:"verified_rate"`
+}
+
+// add counts one solution
+func (vc *verificationCoverage) add(chunk *types.ConversationChunk) {
+	vc.Solutions++
+	switch chunk.VerificationStatus() {
+	case types.VerificationVerified:
+		vc.Verified++
+	case types.VerificationFailed:
+		vc.Failed++
+	default:
+		vc.Unverified++
+	}
+	vc.Coverage = float64(vc.Verified+vc.Failed) / float64(vc.Solutions)
+	if judged := vc.Verified + vc.Failed; judged > 0 {
+		vc.VerifiedRate = float64(vc.Verified) / float64(judged)
+	}
+}
+
+// handleVerificationCoverage reports how many of a repository's solutions
+// were verified or found to fail, and lists the oldest ones still awaiting a
+// verdict. The global repository is broken down per repository.
+func (ms *MemoryServer) handleVerificationCoverage(ctx context.Context, options map[string]interface{}) (interface{}, error) {
+	repository, _ := options["repository"].(string)
+	chunkLimit, itemLimit := reportLimits(options)
+
+	chunks, err := ms.liveChunks(ctx, repository, chunkLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	var total verificationCoverage
+	byRepository := make(map[string]*verificationCoverage)
+	awaiting := make([]*types.ConversationChunk, 0)
+	for i := range chunks {
+		chunk := &chunks[i]
+		if chunk.Type != types.ChunkTypeSolution {
+			continue
+		}
+		total.add(chunk)
+		if repository == GlobalRepository {
+			repoCoverage, ok := byRepository[chunk.Metadata.Repository]
+			if !ok {
+				repoCoverage = &verificationCoverage{}
+				byRepository[chunk.Metadata.Repository] = repoCoverage
+			}
+			repoCoverage.add(chunk)
+		}
+		if chunk.VerificationStatus() == "" {
+			awaiting = append(awaiting, chunk)
+		}
+	}
+
+	sort.SliceStable(awaiting, func(i, j int) bool { return awaiting[i].Timestamp.Before(awaiting[j].Timestamp) })
+	if len(awaiting) > itemLimit {
+		awaiting = awaiting[:itemLimit]
+	}
+	unverified := make([]map[string]interface{}, 0, len(awaiting))
+	for _, chunk := range awaiting {
+		unverified = append(unverified, map[string]interface{}{
+			"chunk_id":  chunk.ID,
+			"summary":   chunk.Summary,
+			"outcome":   chunk.Metadata.Outcome,
+			"timestamp": chunk.Timestamp.Format(time.RFC3339),
+		})
+	}
+
+	result := map[string]interface{}{
+		"status":                "success",
+		"operation":             OperationVerificationCoverage,
+		"repository":            repository,
+		"memories_analyzed":     len(chunks),
+		"coverage":              total,
+		"awaiting_verification": unverified,
+		"generated_at":          time.Now().Format(time.RFC3339),
+	}
+	if repository == GlobalRepository {
+		result["by_repository"] = byRepository
+	}
+	return result, nil
+}
+
+// liveChunks returns up to limit of a repository's memories outside the
+// trash, newest first. The global repository reads every repository's
+// memories rather than a broad search, so none is left out of a breakdown.
+func (ms *MemoryServer) liveChunks(ctx context.Context, repository string, limit int) ([]types.ConversationChunk, error) {
+	if repository != GlobalRepository {
+		return ms.reportChunks(ctx, repository, limit)
+	}
+
+	chunks, err := ms.container.GetVectorStore().GetAllChunks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memories for analysis: %w", err)
+	}
+	sort.SliceStable(chunks, func(i, j int) bool { return chunks[i].Timestamp.After(chunks[j].Timestamp) })
+	if len(chunks) > limit {
+		chunks = chunks[:limit]
+	}
+	return chunks, nil
+}
@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func verifySolution(ms *MemoryServer, options map[string]interface{}) (interface{}, error) {
+	if options["repository"] == nil {
+		options["repository"] = "github.com/acme/api"
+	}
+	return ms.handleMemoryUpdate(context.Background(), map[string]interface{}{
+		"operation": OperationVerifySolution,
+		"options":   options,
+	})
+}
+
+func TestVerifySolution(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	solution := newReportChunk(t, "s1", "Retry the token refresh once", types.ChunkTypeSolution, types.ChunkMetadata{})
+	require.NoError(t, store.Store(ctx, solution))
+	ms := newCompositeTestServer(t, store)
+
+	_, err := verifySolution(ms, map[string]interface{}{"chunk_id": solution.ID, "status": "maybe"})
+	assert.Error(t, err)
+	_, err = verifySolution(ms, map[string]interface{}{"chunk_id": solution.ID, "status": types.VerificationVerified, "repository": "github.com/acme/web"})
+	assert.Error(t, err, "chunks of other repositories cannot be verified")
+
+	result, err := verifySolution(ms, map[string]interface{}{
+		"chunk_id": solution.ID,
+		"status":   types.VerificationVerified,
+		"evidence": []interface{}{"https://ci.example.com/runs/42"},
+		"note":     "Green for a week",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, types.OutcomeSuccess, result.(map[string]interface{})["outcome"])
+
+	// A later regression is recorded on top of the earlier verdict
+	_, err = verifySolution(ms, map[string]interface{}{"chunk_id": solution.ID, "status": types.VerificationFailed})
+	require.NoError(t, err)
+
+	stored, err := store.GetByID(ctx, solution.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.VerificationFailed, stored.VerificationStatus())
+	assert.Equal(t, types.OutcomeFailed, stored.Metadata.Outcome)
+	history := stored.VerificationHistory()
+	require.Len(t, history, 2)
+	assert.Equal(t, []string{"https://ci.example.com/runs/42"}, history[0].Evidence)
+	assert.Equal(t, "Green for a week", history[0].Note)
+	assert.Equal(t, types.VerificationFailed, history[1].Status)
+}
+
+func TestAnalyzeVerificationCoverage(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	chunk := func(content, repository, status string) *types.ConversationChunk {
+		c := newReportChunk(t, "s1", content, types.ChunkTypeSolution, types.ChunkMetadata{Repository: repository})
+		if status != "" {
+			c.RecordVerification(types.VerificationRecord{Status: status})
+		}
+		require.NoError(t, store.Store(ctx, c))
+		return c
+	}
+	chunk("Verified fix", "github.com/acme/api", types.VerificationVerified)
+	chunk("Failed fix", "github.com/acme/api", types.VerificationFailed)
+	pending := chunk("Untested fix", "github.com/acme/api", "")
+	chunk("Web fix", "github.com/acme/web", types.VerificationVerified)
+	discussion := newReportChunk(t, "s1", "Not a solution", types.ChunkTypeDiscussion, types.ChunkMetadata{})
+	require.NoError(t, store.Store(ctx, discussion))
+
+	ms := newCompositeTestServer(t, store)
+	report := analyze(t, ms, OperationVerificationCoverage, map[string]interface{}{})
+	coverage := report["coverage"].(verificationCoverage)
+	assert.Equal(t, verificationCoverage{Solutions: 3, Verified: 1, Failed: 1, Unverified: 1, Coverage: 2.0 / 3, VerifiedRate: 0.5}, coverage)
+	awaiting := report["awaiting_verification"].([]map[string]interface{})
+	require.Len(t, awaiting, 1)
+	assert.Equal(t, pending.ID, awaiting[0]["chunk_id"])
+	assert.NotContains(t, report, "by_repository")
+
+	report = analyze(t, ms, OperationVerificationCoverage, map[string]interface{}{"repository": GlobalRepository})
+	byRepository := report["by_repository"].(map[string]*verificationCoverage)
+	require.Len(t, byRepository, 2)
+	assert.Equal(t, 1, byRepository["github.com/acme/web"].Verified)
+	assert.Equal(t, 4, report["coverage"].(verificationCoverage).Solutions)
+}
@@ -0,0 +1,205 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"lerian-mcp-memory/internal/intelligence"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/pkg/types"
+)
+
+// OperationStaleKnowledge checks memories against the current codebase
+const OperationStaleKnowledge = "stale_knowledge"
+
+// hasCodebaseOptions reports whether options describe the current codebase
+func hasCodebaseOptions(options map[string]interface{}) bool {
+	_, hasFiles := options["files"].([]interface{})
+	repoPath, _ := options["repo_path"].(string)
+	return hasFiles || repoPath != ""
+}
+
+// codebaseSnapshot builds the codebase state memories are checked against,
+// from a files manifest (with optional symbols and renames) or a git work tree
+// inside the client's roots
+func codebaseSnapshot(ctx context.Context, options map[string]interface{}) (*intelligence.CodebaseSnapshot, error) {
+	if rawFiles, ok := options["files"].([]interface{}); ok {
+		files := make([]string, 0, len(rawFiles))
+		for _, f := range rawFiles {
+			if file, ok := f.(string); ok {
+				files = append(files, file)
+			}
+		}
+
+		var symbols []string
+		if rawSymbols, ok := options["symbols"].([]interface{}); ok {
+			symbols = make([]string, 0, len(rawSymbols))
+			for _, s := range rawSymbols {
+				if symbol, ok := s.(string); ok {
+					symbols = append(symbols, symbol)
+				}
+			}
+		}
+
+		renames := make(map[string]string)
+		if rawRenames, ok := options["renames"].(map[string]interface{}); ok {
+			for from, to := range rawRenames {
+				if toPath, ok := to.(string); ok {
+					renames[from] = toPath
+				}
+			}
+		}
+		return intelligence.NewCodebaseSnapshot(files, renames, symbols), nil
+	}
+
+	repoPath, _ := options["repo_path"].(string)
+	if repoPath == "" {
+		return nil, errors.New("a files manifest or repo_path is required to check memories against the codebase")
+	}
+	repoPath, err := ValidatePath(ctx, repoPath)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(repoPath); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("repo_path %q is not a directory", repoPath)
+	}
+	return intelligence.LoadGitSnapshot(ctx, repoPath)
+}
+
+// handleStaleKnowledge flags memories whose referenced files or symbols were
+// deleted or renamed in the current codebase. With flag set, the broken
+// references are recorded on each memory and cleared once they resolve again.
+func (ms *MemoryServer) handleStaleKnowledge(ctx context.Context, options map[string]interface{}) (interface{}, error) {
+	repository, _ := options["repository"].(string)
+	chunkLimit, itemLimit := reportLimits(options)
+	flag, _ := options["flag"].(bool)
+
+	snapshot, err := codebaseSnapshot(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks, err := ms.reportChunks(ctx, repository, chunkLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	stale := make([]map[string]interface{}, 0)
+	withReferences, flagged, cleared := 0, 0, 0
+	byState := map[string]int{intelligence.ReferenceStateMissing: 0, intelligence.ReferenceStateRenamed: 0}
+
+	for i := range chunks {
+		chunk := &chunks[i]
+		result := snapshot.Check(chunk)
+		if result.References == 0 {
+			continue
+		}
+		withReferences++
+
+		if flag {
+			if markStaleReferences(chunk, &result) {
+				if _, err := ms.updateChunk(ctx, chunk.ID, "", func(current *types.ConversationChunk) error {
+					markStaleReferences(current, &result)
+					return nil
+				}); err != nil {
+					logging.Warn("stale_knowledge: failed to flag memory", "chunk_id", chunk.ID, "error", err)
+				} else if result.IsStale {
+					flagged++
+				} else {
+					cleared++
+				}
+			}
+		}
+
+		if !result.IsStale {
+			continue
+		}
+		for _, ref := range result.Broken {
+			byState[ref.State]++
+		}
+		stale = append(stale, map[string]interface{}{
+			"chunk_id":          chunk.ID,
+			"type":              string(chunk.Type),
+			"summary":           chunk.Summary,
+			"timestamp":         chunk.Timestamp.Format(time.RFC3339),
+			"references":        result.References,
+			"broken_references": result.Broken,
+			"staleness":         result.Staleness,
+			"suggested_action":  staleSuggestion(&result),
+		})
+	}
+
+	sort.SliceStable(stale, func(i, j int) bool {
+		return stale[i]["staleness"].(float64) > stale[j]["staleness"].(float64)
+	})
+	staleCount := len(stale)
+	if len(stale) > itemLimit {
+		stale = stale[:itemLimit]
+	}
+
+	return map[string]interface{}{
+		"status":     "success",
+		"operation":  OperationStaleKnowledge,
+		"repository": repository,
+		"summary": map[string]interface{}{
+			"memories_analyzed":  len(chunks),
+			"memories_with_refs": withReferences,
+			"stale_count":        staleCount,
+			"broken_by_state":    byState,
+			"codebase_files":     snapshot.FileCount(),
+			"symbols_checked":    snapshot.ChecksSymbols(),
+			"flagged":            flagged,
+			"cleared":            cleared,
+		},
+		"stale":        stale,
+		"generated_at": time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// markStaleReferences records a check result in a memory's metadata,
+// reporting whether the metadata changed
+func markStaleReferences(chunk *types.ConversationChunk, result *intelligence.StaleReferenceResult) bool {
+	_, wasFlagged := chunk.Metadata.ExtendedMetadata[types.EMKeyStaleReferences]
+	if !result.IsStale && !wasFlagged {
+		return false
+	}
+
+	if chunk.Metadata.ExtendedMetadata == nil {
+		chunk.Metadata.ExtendedMetadata = make(map[string]interface{})
+	}
+	if !result.IsStale {
+		delete(chunk.Metadata.ExtendedMetadata, types.EMKeyStaleReferences)
+		delete(chunk.Metadata.ExtendedMetadata, types.EMKeyStaleCheckedAt)
+		return true
+	}
+
+	broken := make([]string, 0, len(result.Broken))
+	for _, ref := range result.Broken {
+		broken = append(broken, ref.Value)
+	}
+	chunk.Metadata.ExtendedMetadata[types.EMKeyStaleReferences] = broken
+	chunk.Metadata.ExtendedMetadata[types.EMKeyStaleCheckedAt] = time.Now().UTC().Format(time.RFC3339)
+	return true
+}
+
+// staleSuggestion proposes what to do with a memory whose references broke
+func staleSuggestion(result *intelligence.StaleReferenceResult) string {
+	renamed := 0
+	for _, ref := range result.Broken {
+		if ref.State == intelligence.ReferenceStateRenamed {
+			renamed++
+		}
+	}
+	switch {
+	case renamed == len(result.Broken):
+		return "Update the memory to use the renamed paths"
+	case result.Staleness >= 1:
+		return "Verify the memory still applies; everything it references is gone"
+	default:
+		return "Verify the memory against the current code and update the missing references"
+	}
+}
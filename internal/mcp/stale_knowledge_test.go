@@ -0,0 +1,83 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeStaleKnowledge(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+
+	current := newReportChunk(t, "s1", "Tuned timeouts in internal/api/server.go", types.ChunkTypeCodeChange, types.ChunkMetadata{})
+	moved := newReportChunk(t, "s1", "The importer lives in internal/legacy/importer.go", types.ChunkTypeDiscussion, types.ChunkMetadata{})
+	deleted := newReportChunk(t, "s1", "Fixed a race", types.ChunkTypeSolution, types.ChunkMetadata{FilesModified: []string{"internal/cache/lru.go"}})
+	noRefs := newReportChunk(t, "s1", "Discussed release cadence", types.ChunkTypeDiscussion, types.ChunkMetadata{})
+	for _, c := range []*types.ConversationChunk{current, moved, deleted, noRefs} {
+		require.NoError(t, store.Store(ctx, c))
+	}
+
+	ms := newCompositeTestServer(t, store)
+	options := map[string]interface{}{
+		"files":   []interface{}{"internal/api/server.go", "internal/importer/importer.go"},
+		"renames": map[string]interface{}{"internal/legacy/importer.go": "internal/importer/importer.go"},
+		"flag":    true,
+	}
+	report := analyze(t, ms, OperationStaleKnowledge, options)
+
+	summary := report["summary"].(map[string]interface{})
+	assert.Equal(t, 3, summary["memories_with_refs"])
+	assert.Equal(t, 2, summary["stale_count"])
+	assert.Equal(t, 2, summary["flagged"])
+	assert.Equal(t, false, summary["symbols_checked"])
+
+	stale := report["stale"].([]map[string]interface{})
+	require.Len(t, stale, 2)
+	ids := []string{stale[0]["chunk_id"].(string), stale[1]["chunk_id"].(string)}
+	assert.ElementsMatch(t, []string{moved.ID, deleted.ID}, ids)
+
+	flagged, err := store.GetByID(ctx, deleted.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"internal/cache/lru.go"}, flagged.Metadata.ExtendedMetadata[types.EMKeyStaleReferences])
+
+	// Once the file is back, flagging clears the mark
+	options["files"] = []interface{}{"internal/api/server.go", "internal/importer/importer.go", "internal/cache/lru.go"}
+	report = analyze(t, ms, OperationStaleKnowledge, options)
+	assert.Equal(t, 1, report["summary"].(map[string]interface{})["cleared"])
+	restored, err := store.GetByID(ctx, deleted.ID)
+	require.NoError(t, err)
+	assert.NotContains(t, restored.Metadata.ExtendedMetadata, types.EMKeyStaleReferences)
+}
+
+func TestAnalyzeStaleKnowledgeRequiresCodebase(t *testing.T) {
+	ms := newCompositeTestServer(t, storage.NewSimpleMockVectorStore())
+	_, err := ms.handleMemoryAnalyze(context.Background(), map[string]interface{}{
+		"operation": OperationStaleKnowledge,
+		"options":   map[string]interface{}{"repository": "github.com/acme/api"},
+	})
+	assert.ErrorContains(t, err, "files manifest or repo_path")
+}
+
+func TestQualityReportPenalizesStaleReferences(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	chunk := newReportChunk(t, "s1", "Refactored the cache eviction in internal/cache/lru.go", types.ChunkTypeCodeChange, types.ChunkMetadata{})
+	require.NoError(t, store.Store(ctx, chunk))
+	ms := newCompositeTestServer(t, store)
+
+	baseline := analyze(t, ms, "quality_report", map[string]interface{}{"quality_threshold": 1.0})
+	baseScore := baseline["low_quality"].([]map[string]interface{})[0]["overall_quality"].(float64)
+	assert.NotContains(t, baseline["summary"], "stale_code_count")
+
+	report := analyze(t, ms, "quality_report", map[string]interface{}{"quality_threshold": 1.0, "files": []interface{}{"internal/api/server.go"}})
+	assert.Equal(t, 1, report["summary"].(map[string]interface{})["stale_code_count"])
+	item := report["low_quality"].([]map[string]interface{})[0]
+	assert.Less(t, item["overall_quality"].(float64), baseScore)
+	assert.NotEmpty(t, item["broken_references"])
+}
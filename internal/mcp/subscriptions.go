@@ -0,0 +1,234 @@
+package mcp
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+
+	"lerian-mcp-memory/internal/storage"
+
+	"github.com/fredcamaral/gomcp-sdk/notifications"
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+// recentURIPrefix is the URI prefix of the recent activity resource
+const recentURIPrefix = "memory://recent/"
+
+type connectionIDKey struct{}
+
+// WithConnectionID returns a context identifying the client connection
+// requests served with it arrive on. Transports attach one, along with a
+// notification sender, so resources/subscribe can remember who to notify.
+func WithConnectionID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, connectionIDKey{}, id)
+}
+
+// connectionIDFrom returns the connection ID attached to ctx, or ""
+func connectionIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(connectionIDKey{}).(string)
+	return id
+}
+
+// ResourceWatcher follows the data behind resources so subscribers hear
+// about changes made outside the handlers that already report them.
+type ResourceWatcher interface {
+	// Matches reports whether the watcher can follow uri
+	Matches(uri string) bool
+	// Watch starts following uri, calling changed whenever it changes, until
+	// stop is called. Watch is called when uri gets its first subscriber and
+	// stop once the last one leaves; changed must not be called from Watch.
+	Watch(uri string, changed func()) (stop func())
+}
+
+// resourceSubscriptions tracks which connections subscribed to which
+// resource URIs. The zero value is ready to use.
+type resourceSubscriptions struct {
+	mu        sync.Mutex
+	templates []string
+	watchers  []ResourceWatcher
+	byURI     map[string]*uriSubscription
+}
+
+// uriSubscription holds the subscribers of one URI and the watches following it
+type uriSubscription struct {
+	senders map[string]NotificationSender // by connection ID
+	stops   []func()
+}
+
+// AddResourceWatcher follows the data behind matching resources while they
+// have subscribers
+func (ms *MemoryServer) AddResourceWatcher(watcher ResourceWatcher) {
+	ms.subscriptions.mu.Lock()
+	defer ms.subscriptions.mu.Unlock()
+	ms.subscriptions.watchers = append(ms.subscriptions.watchers, watcher)
+}
+
+// ResourceUpdated sends notifications/resources/updated for uri to the
+// connections subscribed to it. A connection that cannot be reached loses
+// all its subscriptions.
+func (ms *MemoryServer) ResourceUpdated(uri string) {
+	ms.subscriptions.mu.Lock()
+	sub := ms.subscriptions.byURI[uri]
+	senders := make(map[string]NotificationSender)
+	if sub != nil {
+		for id, sender := range sub.senders {
+			senders[id] = sender
+		}
+	}
+	ms.subscriptions.mu.Unlock()
+
+	params := &notifications.ResourceChangedParams{URI: uri}
+	for id, sender := range senders {
+		if err := sender(notificationResourceUpdated, params); err != nil {
+			log.Printf("Warning: Failed to notify %s of update to %s, dropping its subscriptions: %v", id, uri, err)
+			ms.CloseConnection(id)
+		}
+	}
+}
+
+// CloseConnection drops the session, subscriptions and client capabilities
+// of a connection that went away
+func (ms *MemoryServer) CloseConnection(id string) {
+	ms.sessions.Close(id)
+	ms.clientRequests.setSampling(id, false)
+	ms.clientRoots.setSupported(id, false)
+
+	ms.subscriptions.mu.Lock()
+	uris := make([]string, 0)
+	for uri, sub := range ms.subscriptions.byURI {
+		if _, ok := sub.senders[id]; ok {
+			uris = append(uris, uri)
+		}
+	}
+	ms.subscriptions.mu.Unlock()
+
+	for _, uri := range uris {
+		ms.unsubscribe(uri, id)
+	}
+}
+
+// subscribe sends updates to uri to a connection, starting the watchers
+// that match uri when it gets its first subscriber
+func (ms *MemoryServer) subscribe(uri, connectionID string, sender NotificationSender) {
+	s := &ms.subscriptions
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.byURI == nil {
+		s.byURI = make(map[string]*uriSubscription)
+	}
+	sub, ok := s.byURI[uri]
+	if !ok {
+		sub = &uriSubscription{senders: make(map[string]NotificationSender)}
+		for _, watcher := range s.watchers {
+			if watcher.Matches(uri) {
+				sub.stops = append(sub.stops, watcher.Watch(uri, func() { ms.ResourceUpdated(uri) }))
+			}
+		}
+		s.byURI[uri] = sub
+	}
+	sub.senders[connectionID] = sender
+}
+
+// unsubscribe stops sending updates to uri to a connection, stopping the
+// watchers of uri once nobody is subscribed
+func (ms *MemoryServer) unsubscribe(uri, connectionID string) {
+	s := &ms.subscriptions
+	s.mu.Lock()
+	sub, ok := s.byURI[uri]
+	if !ok {
+		s.mu.Unlock()
+		return
+	}
+	delete(sub.senders, connectionID)
+	var stops []func()
+	if len(sub.senders) == 0 {
+		delete(s.byURI, uri)
+		stops = sub.stops
+	}
+	s.mu.Unlock()
+
+	for _, stop := range stops {
+		stop()
+	}
+}
+
+// addResourceTemplate records a registered resource URI, possibly holding
+// one {placeholder}, as subscribable
+func (ms *MemoryServer) addResourceTemplate(template string) {
+	ms.subscriptions.mu.Lock()
+	defer ms.subscriptions.mu.Unlock()
+	ms.subscriptions.templates = append(ms.subscriptions.templates, template)
+}
+
+// isKnownResource reports whether uri names a registered resource
+func (ms *MemoryServer) isKnownResource(uri string) bool {
+	ms.subscriptions.mu.Lock()
+	defer ms.subscriptions.mu.Unlock()
+	for _, template := range ms.subscriptions.templates {
+		if matchesResourceTemplate(template, uri) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesResourceTemplate reports whether uri fills in the placeholder of
+// template with a non-empty value, or equals it when it has none
+func matchesResourceTemplate(template, uri string) bool {
+	open := strings.Index(template, "{")
+	closing := strings.LastIndex(template, "}")
+	if open < 0 || closing < open {
+		return template == uri
+	}
+	prefix, suffix := template[:open], template[closing+1:]
+	return len(uri) > len(prefix)+len(suffix) && strings.HasPrefix(uri, prefix) && strings.HasSuffix(uri, suffix)
+}
+
+// handleResourceSubscription serves resources/subscribe and resources/unsubscribe
+func (ms *MemoryServer) handleResourceSubscription(ctx context.Context, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+	params, _ := req.Params.(map[string]interface{})
+	uri, _ := params["uri"].(string)
+	if uri == "" {
+		return ErrorResponse(req, protocol.InvalidParams, "uri is required", nil)
+	}
+
+	connectionID := connectionIDFrom(ctx)
+	sender := notificationSenderFrom(ctx)
+	if connectionID == "" || sender == nil {
+		return ErrorResponse(req, protocol.InvalidRequest, "Subscriptions are not supported on this transport", nil)
+	}
+
+	if req.Method == "resources/unsubscribe" {
+		ms.unsubscribe(uri, connectionID)
+	} else {
+		if !ms.isKnownResource(uri) {
+			return ErrorResponse(req, protocol.InvalidParams, "Resource not found", map[string]interface{}{"uri": uri})
+		}
+		ms.subscribe(uri, connectionID, sender)
+	}
+	return &protocol.JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: map[string]interface{}{}}
+}
+
+// recentActivityWatcher updates memory://recent/{repository} as chunks of
+// the repository are stored, updated or deleted. Subscribers are notified
+// off the write path so storing a chunk never waits on a slow client.
+type recentActivityWatcher struct {
+	feed *storage.ChangeFeed
+}
+
+// Matches implements ResourceWatcher
+func (w recentActivityWatcher) Matches(uri string) bool {
+	return matchesResourceTemplate(recentURIPrefix+"{repository}", uri)
+}
+
+// Watch implements ResourceWatcher
+func (w recentActivityWatcher) Watch(uri string, changed func()) func() {
+	repository := strings.TrimPrefix(uri, recentURIPrefix)
+	return w.feed.Listen(func(change storage.ChunkChange) {
+		if change.Repository == repository {
+			go changed()
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/fredcamaral/gomcp-sdk/notifications"
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func subscriptionRequest(method, uri string) *protocol.JSONRPCRequest {
+	return &protocol.JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: method, Params: map[string]interface{}{"uri": uri}}
+}
+
+func TestResourceSubscriptionLifecycle(t *testing.T) {
+	feed := storage.NewChangeFeed(storage.NewKeywordStore(""))
+	ms := newMiddlewareTestServer()
+	ms.registerResources()
+	ms.AddResourceWatcher(recentActivityWatcher{feed: feed})
+
+	updates := make(chan string, 10)
+	sender := NotificationSender(func(method string, params interface{}) error {
+		assert.Equal(t, notificationResourceUpdated, method)
+		updates <- params.(*notifications.ResourceChangedParams).URI
+		return nil
+	})
+	ctx := WithConnectionID(WithNotificationSender(context.Background(), sender), "client-1")
+	uri := "memory://recent/github.com/acme/api"
+
+	resp := ms.HandleRequest(context.Background(), subscriptionRequest("resources/subscribe", uri))
+	require.NotNil(t, resp.Error, "transports without notifications cannot subscribe")
+	resp = ms.HandleRequest(ctx, subscriptionRequest("resources/subscribe", "memory://unknown/thing"))
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, protocol.InvalidParams, resp.Error.Code)
+
+	resp = ms.HandleRequest(ctx, subscriptionRequest("resources/subscribe", uri))
+	require.Nil(t, resp.Error)
+
+	store := func(repository string) {
+		chunk := newReportChunk(t, "session-1", "Stored "+repository, types.ChunkTypeDiscussion, types.ChunkMetadata{Repository: repository})
+		require.NoError(t, feed.Store(context.Background(), chunk))
+	}
+	store("github.com/acme/web")
+	store("github.com/acme/api")
+	select {
+	case got := <-updates:
+		assert.Equal(t, uri, got)
+	case <-time.After(time.Second):
+		t.Fatal("expected resources/updated notification")
+	}
+
+	resp = ms.HandleRequest(ctx, subscriptionRequest("resources/unsubscribe", uri))
+	require.Nil(t, resp.Error)
+	store("github.com/acme/api")
+	select {
+	case got := <-updates:
+		t.Fatalf("unexpected update after unsubscribe: %s", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestResourceUpdatedDropsUnreachableConnections(t *testing.T) {
+	ms := &MemoryServer{}
+	uri := WorkingSetURI("session-1")
+	var calls int
+	ms.subscribe(uri, "gone", func(string, interface{}) error {
+		calls++
+		return errors.New("connection closed")
+	})
+	ms.subscribe(TaskBoardURI("acme"), "gone", func(string, interface{}) error { return nil })
+
+	ms.ResourceUpdated(uri)
+	ms.ResourceUpdated(uri)
+	assert.Equal(t, 1, calls)
+	assert.Empty(t, ms.subscriptions.byURI, "every subscription of the connection is dropped")
+}
+
+func TestMatchesResourceTemplate(t *testing.T) {
+	assert.True(t, matchesResourceTemplate("memory://recent/{repository}", "memory://recent/github.com/acme/api"))
+	assert.False(t, matchesResourceTemplate("memory://recent/{repository}", "memory://recent/"))
+	assert.True(t, matchesResourceTemplate("memory://session/{session_id}/working-set", "memory://session/s1/working-set"))
+	assert.False(t, matchesResourceTemplate("memory://session/{session_id}/working-set", "memory://session/s1"))
+	assert.True(t, matchesResourceTemplate("memory://capabilities", "memory://capabilities"))
+	assert.False(t, matchesResourceTemplate("memory://capabilities", "memory://capabilities/x"))
+}
@@ -0,0 +1,498 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"lerian-mcp-memory/internal/audit"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/tags"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/fredcamaral/gomcp-sdk"
+)
+
+// tagListing is one tag of tag_list. Tags used on memories without being
+// registered are listed too, without a registration.
+type tagListing struct {
+	Name        string   `json:"name"`
+	Parent      string   `json:"parent,omitempty"`
+	Registered  bool     `json:"registered"`
+	Description string   `json:"description,omitempty"`
+	Aliases     []string `json:"aliases,omitempty"`
+	Usage       int      `json:"usage"`
+	// SubtreeUsage counts the memories using the tag or any of its subtopics
+	SubtreeUsage int `json:"subtree_usage"`
+	Subtopics    int `json:"subtopics,omitempty"`
+}
+
+// registerTagTools registers tag_manage and tag_list
+func (ms *MemoryServer) registerTagTools() {
+	ms.addTool(mcp.NewTool(
+		"tag_manage",
+		"Manage the registry of tags memories and tasks are labelled with. Tags may form a hierarchy by naming an area and a subtopic, as in 'infra/kubernetes'; creating a subtopic registers its area. Operations: create, update (description), rename (give a tag and its subtopics a new name and rewrite every memory using them), merge (fold the tags in sources, registered or merely used on memories, into tag and rewrite every memory using them), delete (remove a tag from the registry and from every memory). Former names are kept as aliases: memories stored with them later are filed under the current tag. rename, merge and delete only preview how many memories they rewrite until confirm repeats the tag. The registry is shared by every tenant, so only callers owning every project may change it.",
+		mcp.ObjectSchema("Tag management parameters", map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"create", "update", "rename", "merge", "delete"},
+				"description": "Operation to run",
+			},
+			"tag": map[string]interface{}{
+				"type":        "string",
+				"description": "Tag to act on, e.g. 'performance' or 'infra/kubernetes'. For merge, the tag the sources are folded into",
+			},
+			"description": map[string]interface{}{
+				"type":        "string",
+				"description": "What the tag is for (create, update)",
+			},
+			"new_name": map[string]interface{}{
+				"type":        "string",
+				"description": "New name of the tag (rename)",
+			},
+			"sources": mcp.ArraySchema("Tags folded into tag (merge)", map[string]interface{}{"type": "string"}),
+			"confirm": map[string]interface{}{
+				"type":        "string",
+				"description": "The tag again, to carry out a rename, merge or delete instead of previewing it",
+			},
+		}, []string{"operation", "tag"}),
+	), mcp.ToolHandlerFunc(ms.handleTagManage))
+
+	ms.addTool(mcp.NewTool(
+		"tag_list",
+		"List tags with how many memories use them, registered tags with their description, area and aliases, and tags used on memories without being registered. A tag's subtree_usage adds the memories of its subtopics.",
+		mcp.ObjectSchema("Tag list parameters", map[string]interface{}{
+			"repository": map[string]interface{}{
+				"type":        "string",
+				"description": "Count usage in one repository only - e.g. 'github.com/user/repo'. Every repository by default",
+			},
+			"area": map[string]interface{}{
+				"type":        "string",
+				"description": "List only this tag and its subtopics",
+			},
+			"include_unregistered": map[string]interface{}{
+				"type":        "boolean",
+				"default":     true,
+				"description": "List tags used on memories that are not registered",
+			},
+		}, nil),
+	), mcp.ToolHandlerFunc(ms.handleTagList))
+}
+
+// handleTagManage runs a tag registry operation
+func (ms *MemoryServer) handleTagManage(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: tag_manage called", "args", args)
+
+	registry := ms.container.GetTags()
+	if registry == nil {
+		return nil, errors.New("the tag registry is not available")
+	}
+	// The registry, and the rewrites of renames, merges and deletes, are
+	// shared by every tenant
+	if err := checkOperator(ctx, "tag_manage"); err != nil {
+		return nil, err
+	}
+
+	operation, _ := args["operation"].(string)
+	name, _ := args["tag"].(string)
+	name = tags.Normalize(name)
+	if name == "" {
+		return nil, NewToolError(ToolErrorMissingParameter, "tag parameter is required").
+			WithParameter("tag").
+			WithExample(`{"operation": "create", "tag": "infra/kubernetes", "description": "Cluster setup and operations"}`)
+	}
+	description, _ := args["description"].(string)
+
+	var (
+		tag *tags.Tag
+		err error
+	)
+	switch operation {
+	case "create":
+		tag, err = registry.Create(tags.Tag{Name: name, Description: description})
+	case "update":
+		tag, err = registry.Update(name, description)
+	case "rename":
+		return ms.renameTag(ctx, registry, name, args)
+	case "merge":
+		return ms.mergeTags(ctx, registry, name, args)
+	case "delete":
+		return ms.deleteTag(ctx, registry, name, args)
+	default:
+		return nil, fmt.Errorf("unknown operation %q: use create, update, rename, merge or delete", operation)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ms.logTagChange(ctx, operation, tag.Name, nil)
+	return map[string]interface{}{
+		"status":    "success",
+		"operation": operation,
+		"tag":       tag,
+	}, nil
+}
+
+// renameTag renames a tag and its subtopics and rewrites the memories using
+// them. Without confirmation it only reports how many memories would change.
+func (ms *MemoryServer) renameTag(ctx context.Context, registry *tags.Store, name string, args map[string]interface{}) (interface{}, error) {
+	newName, _ := args["new_name"].(string)
+	newName = tags.Normalize(newName)
+	if newName == "" {
+		return nil, NewToolError(ToolErrorMissingParameter, "new_name parameter is required for rename").
+			WithParameter("new_name").
+			WithExample(`{"operation": "rename", "tag": "k8s", "new_name": "infra/kubernetes", "confirm": "k8s"}`)
+	}
+	if _, ok := registry.Get(name); !ok {
+		return nil, NewToolError(ToolErrorNotFound, fmt.Sprintf("tag %q not found", name)).
+			WithParameter("tag").
+			WithSuggestion("List registered tags with tag_list; to fold an unregistered tag into a registered one, use merge")
+	}
+
+	// The registry settles the subtopics' new names; preview with the same mapping
+	renames := map[string]string{name: newName}
+	for _, subtopic := range registry.Subtopics(name) {
+		renames[subtopic] = newName + strings.TrimPrefix(subtopic, name)
+	}
+	if confirm, _ := args["confirm"].(string); confirm != name {
+		affected, err := ms.rewriteTags(ctx, renameTagsRewrite(renames), true)
+		if err != nil {
+			return nil, err
+		}
+		return tagPreview("rename", name, map[string]interface{}{
+			"renames":             renames,
+			"memories_to_rewrite": affected,
+		}), nil
+	}
+
+	renames, err := registry.Rename(name, newName)
+	if err != nil {
+		return nil, err
+	}
+	return ms.finishTagRewrite(ctx, registry, "rename", newName, renames)
+}
+
+// mergeTags folds tags into one and rewrites the memories using them.
+// Without confirmation it only reports how many memories would change.
+func (ms *MemoryServer) mergeTags(ctx context.Context, registry *tags.Store, target string, args map[string]interface{}) (interface{}, error) {
+	sources := extractStringArray(args["sources"])
+	if len(sources) == 0 {
+		return nil, NewToolError(ToolErrorMissingParameter, "sources parameter is required for merge").
+			WithParameter("sources").
+			WithExample(`{"operation": "merge", "tag": "kubernetes", "sources": ["k8s", "kubernets"], "confirm": "kubernetes"}`)
+	}
+	if _, ok := registry.Get(target); !ok {
+		return nil, NewToolError(ToolErrorNotFound, fmt.Sprintf("tag %q not found", target)).
+			WithParameter("tag").
+			WithSuggestion("Create the tag to merge into with tag_manage create first")
+	}
+
+	renames := make(map[string]string, len(sources))
+	merged := make(map[string]bool, len(sources))
+	for _, source := range sources {
+		renames[tags.Normalize(source)] = target
+		merged[tags.Normalize(source)] = true
+	}
+	if confirm, _ := args["confirm"].(string); confirm != target {
+		affected, err := ms.rewriteTags(ctx, renameTagsRewrite(renames), true)
+		if err != nil {
+			return nil, err
+		}
+		return tagPreview("merge", target, map[string]interface{}{
+			"sources":             sortedKeys(merged),
+			"memories_to_rewrite": affected,
+		}), nil
+	}
+
+	if _, err := registry.Merge(sources, target); err != nil {
+		return nil, err
+	}
+	return ms.finishTagRewrite(ctx, registry, "merge", target, renames)
+}
+
+// finishTagRewrite rewrites the memories and scoring profiles using renamed
+// or merged tags, once the registry has changed
+func (ms *MemoryServer) finishTagRewrite(ctx context.Context, registry *tags.Store, operation, name string, renames map[string]string) (interface{}, error) {
+	rewritten, err := ms.rewriteTags(ctx, renameTagsRewrite(renames), false)
+	if err != nil {
+		return nil, fmt.Errorf("tag %s done but memories were only partly rewritten: %w", operation, err)
+	}
+	profiles := 0
+	if scoringProfiles := ms.container.GetScoringProfiles(); scoringProfiles != nil {
+		if profiles, err = scoringProfiles.RenameTags(renames); err != nil {
+			logging.Warn("tag_manage: failed to rename tag boosts", "operation", operation, "error", err)
+		}
+	}
+
+	tag, _ := registry.Get(name)
+	ms.logTagChange(ctx, operation, name, map[string]interface{}{
+		"renames":            renames,
+		"memories_rewritten": rewritten,
+	})
+	return map[string]interface{}{
+		"status":             "success",
+		"operation":          operation,
+		"tag":                tag,
+		"renames":            renames,
+		"memories_rewritten": rewritten,
+		"profiles_updated":   profiles,
+	}, nil
+}
+
+// deleteTag removes a tag from the registry and from the memories using it.
+// Without confirmation it only reports how many memories would change.
+func (ms *MemoryServer) deleteTag(ctx context.Context, registry *tags.Store, name string, args map[string]interface{}) (interface{}, error) {
+	if _, ok := registry.Get(name); !ok {
+		return nil, NewToolError(ToolErrorNotFound, fmt.Sprintf("tag %q not found", name)).WithParameter("tag")
+	}
+	if subtopics := registry.Subtopics(name); len(subtopics) > 0 {
+		return nil, NewToolError(ToolErrorConflict, fmt.Sprintf("tag %q has subtopics %s", name, strings.Join(subtopics, ", "))).
+			WithParameter("tag").
+			WithSuggestion("Delete, rename or merge its subtopics first")
+	}
+
+	drop := func(chunkTags []string) ([]string, bool) {
+		kept := slices.DeleteFunc(slices.Clone(chunkTags), func(tag string) bool { return tags.Normalize(tag) == name })
+		return kept, len(kept) != len(chunkTags)
+	}
+	if confirm, _ := args["confirm"].(string); confirm != name {
+		affected, err := ms.rewriteTags(ctx, drop, true)
+		if err != nil {
+			return nil, err
+		}
+		return tagPreview("delete", name, map[string]interface{}{"memories_to_rewrite": affected}), nil
+	}
+
+	deleted, err := registry.Delete(name)
+	if err != nil {
+		return nil, err
+	}
+	rewritten, err := ms.rewriteTags(ctx, drop, false)
+	if err != nil {
+		return nil, fmt.Errorf("tag deleted but memories were only partly rewritten: %w", err)
+	}
+	ms.logTagChange(ctx, "delete", name, map[string]interface{}{"memories_rewritten": rewritten})
+	return map[string]interface{}{
+		"status":             "success",
+		"operation":          "delete",
+		"tag":                deleted,
+		"memories_rewritten": rewritten,
+	}, nil
+}
+
+// renameTagsRewrite returns a rewrite of chunk tags that replaces renamed
+// names with their new ones, keeping each tag once
+func renameTagsRewrite(renames map[string]string) func([]string) ([]string, bool) {
+	return func(chunkTags []string) ([]string, bool) {
+		rewritten := make([]string, 0, len(chunkTags))
+		changed := false
+		for _, tag := range chunkTags {
+			if to, ok := renames[tags.Normalize(tag)]; ok {
+				tag, changed = to, true
+			}
+			if !slices.Contains(rewritten, tag) {
+				rewritten = append(rewritten, tag)
+			}
+		}
+		return rewritten, changed
+	}
+}
+
+// rewriteTags applies rewrite to the tags of every memory, trashed ones
+// included so they come back with current tags, and returns how many
+// memories it changed. A dry run only counts them.
+func (ms *MemoryServer) rewriteTags(ctx context.Context, rewrite func([]string) ([]string, bool), dryRun bool) (int, error) {
+	store := ms.container.GetVectorStore()
+	chunks, err := store.GetAllChunks(storage.WithDeleted(ctx))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list memories: %w", err)
+	}
+
+	rewritten := 0
+	for i := range chunks {
+		if _, changed := rewrite(chunks[i].Metadata.Tags); !changed {
+			continue
+		}
+		if dryRun {
+			rewritten++
+			continue
+		}
+		_, err := ms.updateChunk(ctx, chunks[i].ID, "", func(chunk *types.ConversationChunk) error {
+			chunkTags, changed := rewrite(chunk.Metadata.Tags)
+			if !changed {
+				return errChunkUnchanged
+			}
+			chunk.Metadata.Tags = chunkTags
+			return nil
+		})
+		switch {
+		case errors.Is(err, errChunkUnchanged):
+			continue
+		case err != nil:
+			return rewritten, fmt.Errorf("rewrote %d memories, then failed on %s: %w", rewritten, chunks[i].ID, err)
+		}
+		rewritten++
+	}
+	return rewritten, nil
+}
+
+// tagPreview describes what a rename, merge or delete would do and how to confirm it
+func tagPreview(operation, name string, effect map[string]interface{}) map[string]interface{} {
+	preview := map[string]interface{}{
+		"status":    "confirmation_required",
+		"operation": operation,
+		"tag":       name,
+		"message":   fmt.Sprintf("Nothing changed yet. Call %s again with confirm set to %q to carry it out", operation, name),
+	}
+	for key, value := range effect {
+		preview[key] = value
+	}
+	return preview
+}
+
+// handleTagList lists tags with their usage
+func (ms *MemoryServer) handleTagList(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	registry := ms.container.GetTags()
+	if registry == nil {
+		return nil, errors.New("the tag registry is not available")
+	}
+	repository, _ := args["repository"].(string)
+	area, _ := args["area"].(string)
+	area = tags.Normalize(area)
+	includeUnregistered, ok := args["include_unregistered"].(bool)
+	if !ok {
+		includeUnregistered = true
+	}
+
+	var (
+		chunks []types.ConversationChunk
+		err    error
+	)
+	if repository == "" || repository == GlobalRepository {
+		chunks, err = ms.container.GetVectorStore().GetAllChunks(ctx)
+	} else {
+		chunks, err = ms.projectChunks(ctx, normalizeRepository(repository))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memories: %w", err)
+	}
+
+	// Memories carrying an alias count toward the tag it stands for
+	usage := make(map[string]int)
+	for i := range chunks {
+		if chunks[i].IsDeleted() {
+			continue
+		}
+		seen := make(map[string]bool, len(chunks[i].Metadata.Tags))
+		for _, tag := range chunks[i].Metadata.Tags {
+			name := registry.Canonical(tag)
+			if !seen[name] {
+				seen[name] = true
+				usage[name]++
+			}
+		}
+	}
+
+	listings := make(map[string]*tagListing)
+	for _, tag := range registry.List() {
+		listings[tag.Name] = &tagListing{
+			Name:        tag.Name,
+			Parent:      tag.Parent(),
+			Registered:  true,
+			Description: tag.Description,
+			Aliases:     tag.Aliases,
+			Usage:       usage[tag.Name],
+		}
+	}
+	unregistered := 0
+	for name, count := range usage {
+		if _, ok := listings[name]; ok {
+			continue
+		}
+		unregistered++
+		if includeUnregistered {
+			listings[name] = &tagListing{Name: name, Parent: tags.Parent(name), Usage: count}
+		}
+	}
+	for name, listing := range listings {
+		for other, count := range usage {
+			if tags.IsWithin(other, name) {
+				listing.SubtreeUsage += count
+			}
+		}
+		for other := range listings {
+			if other != name && tags.IsWithin(other, name) {
+				listing.Subtopics++
+			}
+		}
+	}
+
+	result := make([]tagListing, 0, len(listings))
+	for name, listing := range listings {
+		if area == "" || tags.IsWithin(name, area) {
+			result = append(result, *listing)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return map[string]interface{}{
+		"status":       "success",
+		"tags":         result,
+		"count":        len(result),
+		"unregistered": unregistered,
+	}, nil
+}
+
+// reconcileTags files the tags of a new memory or task under the registered
+// tags they stand for, former names and differently written ones included,
+// and suggests registered tags for unknown ones that look like typos. It
+// returns the tags it replaced and the suggestions, both keyed by the tag as
+// given.
+func (ms *MemoryServer) reconcileTags(metadata *types.ChunkMetadata) (resolved map[string]string, suggestions map[string][]string) {
+	registry := ms.container.GetTags()
+	if registry == nil || len(metadata.Tags) == 0 {
+		return nil, nil
+	}
+
+	reconciled := make([]string, 0, len(metadata.Tags))
+	for _, tag := range metadata.Tags {
+		canonical := registry.Canonical(tag)
+		if canonical != tag {
+			if resolved == nil {
+				resolved = make(map[string]string)
+			}
+			resolved[tag] = canonical
+		} else if candidates := registry.Suggest(tag); len(candidates) > 0 {
+			if suggestions == nil {
+				suggestions = make(map[string][]string)
+			}
+			suggestions[tag] = candidates
+		}
+		if !slices.Contains(reconciled, canonical) {
+			reconciled = append(reconciled, canonical)
+		}
+	}
+	metadata.Tags = reconciled
+	return resolved, suggestions
+}
+
+// addTagReconciliation reports what reconcileTags did in a store response
+func addTagReconciliation(response map[string]interface{}, resolved map[string]string, suggestions map[string][]string) {
+	if len(resolved) > 0 {
+		response["tags_resolved"] = resolved
+	}
+	if len(suggestions) > 0 {
+		response["tag_suggestions"] = suggestions
+	}
+}
+
+// logTagChange records a tag registry operation in the audit log
+func (ms *MemoryServer) logTagChange(ctx context.Context, operation, name string, details map[string]interface{}) {
+	if auditLogger := ms.container.GetAuditLogger(); auditLogger != nil {
+		auditLogger.LogEvent(ctx, audit.EventTypeMemoryUpdate, operation+"_tag", "tag", name, details)
+	}
+}
@@ -0,0 +1,119 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/scoring"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/tags"
+	"lerian-mcp-memory/internal/tenancy"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagManageRenameMergeAndList(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewLocalVectorStore("")
+	ms := newCompositeTestServer(t, store)
+	ms.container.Tags = tags.NewStore("")
+	ms.container.ScoringProfiles = scoring.NewStore("")
+	_, err := ms.container.ScoringProfiles.Upsert("github.com/acme/api", scoring.Profile{Name: "ops", TagBoosts: map[string]float64{"k8s": 0.2}}, false)
+	require.NoError(t, err)
+
+	tagged := newReportChunk(t, "s1", "Pods restart on OOM", types.ChunkTypeProblem, types.ChunkMetadata{Tags: []string{"k8s", "memory"}})
+	typo := newReportChunk(t, "s1", "Raised the pod memory limit", types.ChunkTypeSolution, types.ChunkMetadata{Tags: []string{"kubernets"}})
+	other := newReportChunk(t, "s2", "Cache warmup", types.ChunkTypeDiscussion, types.ChunkMetadata{Tags: []string{"performance"}})
+	for _, chunk := range []*types.ConversationChunk{tagged, typo, other} {
+		require.NoError(t, store.Store(ctx, chunk))
+	}
+
+	_, err = ms.handleTagManage(ctx, map[string]interface{}{"operation": "create", "tag": "k8s"})
+	require.NoError(t, err)
+	_, err = ms.handleTagManage(ctx, map[string]interface{}{"operation": "create", "tag": "infra/kubernetes", "description": "Cluster operations"})
+	require.NoError(t, err)
+
+	preview, err := ms.handleTagManage(ctx, map[string]interface{}{"operation": "merge", "tag": "infra/kubernetes", "sources": []interface{}{"k8s", "kubernets"}})
+	require.NoError(t, err)
+	assert.Equal(t, "confirmation_required", preview.(map[string]interface{})["status"])
+	assert.Equal(t, 2, preview.(map[string]interface{})["memories_to_rewrite"])
+	unchanged, err := store.GetByID(ctx, tagged.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"k8s", "memory"}, unchanged.Metadata.Tags, "a preview rewrites nothing")
+
+	result, err := ms.handleTagManage(ctx, map[string]interface{}{"operation": "merge", "tag": "infra/kubernetes", "sources": []interface{}{"k8s", "kubernets"}, "confirm": "infra/kubernetes"})
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	assert.Equal(t, 2, response["memories_rewritten"])
+	assert.Equal(t, 1, response["profiles_updated"])
+	merged, err := store.GetByID(ctx, tagged.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"infra/kubernetes", "memory"}, merged.Metadata.Tags)
+	profile, _ := ms.container.ScoringProfiles.Get("github.com/acme/api", "ops")
+	assert.Equal(t, map[string]float64{"infra/kubernetes": 0.2}, profile.TagBoosts)
+
+	_, err = ms.handleTagManage(ctx, map[string]interface{}{"operation": "rename", "tag": "infra", "new_name": "platform", "confirm": "infra"})
+	require.NoError(t, err)
+	renamed, err := store.GetByID(ctx, typo.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"platform/kubernetes"}, renamed.Metadata.Tags)
+
+	result, err = ms.handleTagList(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	listings := map[string]tagListing{}
+	for _, listing := range result.(map[string]interface{})["tags"].([]tagListing) {
+		listings[listing.Name] = listing
+	}
+	assert.Equal(t, 2, listings["platform/kubernetes"].Usage)
+	assert.Equal(t, "platform", listings["platform/kubernetes"].Parent)
+	assert.Equal(t, 0, listings["platform"].Usage)
+	assert.Equal(t, 2, listings["platform"].SubtreeUsage)
+	assert.Equal(t, 1, listings["platform"].Subtopics)
+	assert.False(t, listings["memory"].Registered)
+	assert.Equal(t, 2, result.(map[string]interface{})["unregistered"])
+
+	result, err = ms.handleTagList(ctx, map[string]interface{}{"area": "platform", "include_unregistered": false})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.(map[string]interface{})["count"])
+
+	_, err = ms.handleTagManage(ctx, map[string]interface{}{"operation": "delete", "tag": "platform", "confirm": "platform"})
+	assert.Equal(t, ToolErrorConflict, asToolError(err).Code)
+	_, err = ms.handleTagManage(ctx, map[string]interface{}{"operation": "rename", "tag": "missing", "new_name": "x"})
+	assert.Equal(t, ToolErrorNotFound, asToolError(err).Code)
+}
+
+func TestTagManageIsLimitedToOperators(t *testing.T) {
+	ms := newCompositeTestServer(t, storage.NewLocalVectorStore(""))
+	ms.container.Tags = tags.NewStore("")
+	tenant := tenancy.WithTenant(context.Background(), &tenancy.Tenant{ID: "api_key:acme", Projects: []string{"github.com/acme/api"}})
+	operator := tenancy.WithTenant(context.Background(), &tenancy.Tenant{ID: "api_key:ops", Projects: []string{tenancy.AllProjects}})
+
+	for _, operation := range []string{"create", "update", "rename", "merge", "delete"} {
+		_, err := ms.handleTagManage(tenant, map[string]interface{}{"operation": operation, "tag": "k8s", "new_name": "kubernetes", "sources": []interface{}{"kube"}})
+		assert.ErrorIs(t, err, tenancy.ErrCrossTenant, operation)
+	}
+	_, ok := ms.container.Tags.Get("k8s")
+	assert.False(t, ok)
+
+	_, err := ms.handleTagManage(operator, map[string]interface{}{"operation": "create", "tag": "k8s"})
+	require.NoError(t, err)
+}
+
+func TestReconcileTagsAtIngest(t *testing.T) {
+	ms := newCompositeTestServer(t, storage.NewSimpleMockVectorStore())
+	ms.container.Tags = tags.NewStore("")
+	_, err := ms.container.Tags.Create(tags.Tag{Name: "performance"})
+	require.NoError(t, err)
+	_, err = ms.container.Tags.Create(tags.Tag{Name: "k8s"})
+	require.NoError(t, err)
+	_, err = ms.container.Tags.Rename("k8s", "infra/kubernetes")
+	require.NoError(t, err)
+
+	metadata := types.ChunkMetadata{Tags: []string{"K8s", "perfomance", "infra/kubernetes", "release"}}
+	resolved, suggestions := ms.reconcileTags(&metadata)
+	assert.Equal(t, map[string]string{"K8s": "infra/kubernetes"}, resolved)
+	assert.Equal(t, map[string][]string{"perfomance": {"performance"}}, suggestions)
+	assert.Equal(t, []string{"infra/kubernetes", "perfomance", "release"}, metadata.Tags, "typos are only suggested, aliases are filed under their tag")
+}
@@ -0,0 +1,149 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"lerian-mcp-memory/internal/digest"
+	"lerian-mcp-memory/internal/websocket"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/fredcamaral/gomcp-sdk/notifications"
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+const (
+	// taskBoardURIPrefix is the scheme and path prefix of the task board resource
+	taskBoardURIPrefix = "tasks://board/"
+
+	// notificationResourceUpdated is the MCP method sent when a resource changes
+	notificationResourceUpdated = "notifications/resources/updated"
+
+	// taskBoardLimit caps how many tasks, most recent first, a single board shows
+	taskBoardLimit = 500
+)
+
+// taskBoardColumns defines the Kanban column order of the task board
+var taskBoardColumns = []types.TaskStatus{
+	types.TaskStatusTodo,
+	types.TaskStatusInProgress,
+	types.TaskStatusBlocked,
+	types.TaskStatusOnHold,
+	types.TaskStatusCompleted,
+	types.TaskStatusCancelled,
+}
+
+// TaskBoardURI returns the resource URI of the task board for a project
+func TaskBoardURI(project string) string {
+	return taskBoardURIPrefix + project
+}
+
+// handleTaskBoardResource handles tasks://board/{project} resource requests
+func (ms *MemoryServer) handleTaskBoardResource(ctx context.Context, uri string) ([]protocol.Content, error) {
+	project := strings.TrimPrefix(uri, taskBoardURIPrefix)
+	if project == "" || project == uri {
+		return nil, errors.New("project required for task board resource")
+	}
+
+	// Every memory of the project is paged through, so the cap applies to
+	// tasks rather than to the memories listed before filtering
+	chunks, err := ms.projectChunks(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+
+	board := ms.buildTaskBoard(project, chunks)
+	boardJSON, _ := json.Marshal(board)
+	return []protocol.Content{protocol.NewContent(string(boardJSON))}, nil
+}
+
+// buildTaskBoard groups task chunks into Kanban columns keyed by task status
+func (ms *MemoryServer) buildTaskBoard(project string, chunks []types.ConversationChunk) map[string]interface{} {
+	columns := make(map[string][]interface{}, len(taskBoardColumns))
+	for _, status := range taskBoardColumns {
+		columns[string(status)] = []interface{}{}
+	}
+
+	tasks := make([]types.ConversationChunk, 0, len(chunks))
+	for i := range chunks {
+		if chunks[i].Type == types.ChunkTypeTask && !chunks[i].IsDeleted() {
+			tasks = append(tasks, chunks[i])
+		}
+	}
+	ms.sortTaskChunks(tasks, "desc")
+	truncated := len(tasks) > taskBoardLimit
+	if truncated {
+		tasks = tasks[:taskBoardLimit]
+	}
+	chunks = tasks
+
+	total := 0
+	for i := range chunks {
+
+		status := types.TaskStatusTodo
+		if chunks[i].Metadata.TaskStatus != nil {
+			status = *chunks[i].Metadata.TaskStatus
+		}
+
+		columns[string(status)] = append(columns[string(status)], ms.formatTaskResponse(&chunks[i]))
+		total++
+	}
+
+	order := make([]string, 0, len(taskBoardColumns))
+	for _, status := range taskBoardColumns {
+		order = append(order, string(status))
+	}
+
+	return map[string]interface{}{
+		"project":      project,
+		"uri":          TaskBoardURI(project),
+		"columns":      columns,
+		"column_order": order,
+		"total":        total,
+		"truncated":    truncated,
+		"generated_at": time.Now().Format(time.RFC3339),
+	}
+}
+
+// GetNotifier returns the notifier used to push resource updates to clients
+func (ms *MemoryServer) GetNotifier() *notifications.Notifier {
+	return ms.notifier
+}
+
+// notifyTaskTransition pushes a resources/updated notification to subscribers of the
+// task's board and broadcasts the transition to WebSocket clients when the status changed
+func (ms *MemoryServer) notifyTaskTransition(chunk *types.ConversationChunk, previous types.TaskStatus) {
+	if chunk.Metadata.TaskStatus == nil || *chunk.Metadata.TaskStatus == previous {
+		return
+	}
+
+	current := *chunk.Metadata.TaskStatus
+	uri := TaskBoardURI(chunk.Metadata.Repository)
+
+	ms.ResourceUpdated(uri)
+
+	if ms.wsHub != nil {
+		event := websocket.NewMemoryEvent("task", "updated", chunk.ID, chunk.Metadata.Repository, chunk.SessionID, map[string]interface{}{
+			"uri":         uri,
+			"from_status": string(previous),
+			"to_status":   string(current),
+		})
+		ms.wsHub.BroadcastMemoryEvent(&event)
+	}
+
+	ms.publishEvent(chunkEvent(digest.EventTaskStatus, chunk, map[string]interface{}{
+		"from_status": string(previous),
+		"to_status":   string(current),
+	}))
+}
+
+// currentTaskStatus returns the task status of a chunk, or an empty status if unset
+func currentTaskStatus(chunk *types.ConversationChunk) types.TaskStatus {
+	if chunk.Metadata.TaskStatus == nil {
+		return ""
+	}
+	return *chunk.Metadata.TaskStatus
+}
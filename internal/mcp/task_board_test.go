@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/di"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/fredcamaral/gomcp-sdk/notifications"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTaskChunk(t *testing.T, repository string, status types.TaskStatus) *types.ConversationChunk {
+	t.Helper()
+	chunk, err := types.NewConversationChunk("session-1", "TASK: test", types.ChunkTypeTask, &types.ChunkMetadata{
+		Repository: repository,
+		Outcome:    types.OutcomeInProgress,
+		Difficulty: types.DifficultySimple,
+		TaskStatus: &status,
+	})
+	require.NoError(t, err)
+	chunk.Embeddings = []float64{0.1, 0.2, 0.3}
+	return chunk
+}
+
+func TestTaskBoardResource(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	ms := &MemoryServer{container: &di.Container{VectorStore: store}}
+
+	require.NoError(t, store.Store(ctx, newTaskChunk(t, "github.com/acme/api", types.TaskStatusTodo)))
+	require.NoError(t, store.Store(ctx, newTaskChunk(t, "github.com/acme/api", types.TaskStatusInProgress)))
+	require.NoError(t, store.Store(ctx, newTaskChunk(t, "github.com/acme/api", types.TaskStatusCompleted)))
+	require.NoError(t, store.Store(ctx, newTaskChunk(t, "github.com/acme/other", types.TaskStatusTodo)))
+	trashed := newTaskChunk(t, "github.com/acme/api", types.TaskStatusTodo)
+	deletedAt := time.Now()
+	trashed.Metadata.DeletedAt = &deletedAt
+	require.NoError(t, store.Store(ctx, trashed))
+
+	contents, err := ms.handleResourceRead(ctx, TaskBoardURI("github.com/acme/api"))
+	require.NoError(t, err)
+	require.Len(t, contents, 1)
+
+	var board struct {
+		Project string                              `json:"project"`
+		Total   int                                 `json:"total"`
+		Columns map[string][]map[string]interface{} `json:"columns"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(contents[0].Text), &board))
+
+	assert.Equal(t, "github.com/acme/api", board.Project)
+	assert.Equal(t, 3, board.Total)
+	assert.Len(t, board.Columns[string(types.TaskStatusTodo)], 1)
+	assert.Len(t, board.Columns[string(types.TaskStatusInProgress)], 1)
+	assert.Len(t, board.Columns[string(types.TaskStatusCompleted)], 1)
+	assert.Empty(t, board.Columns[string(types.TaskStatusBlocked)])
+
+	_, err = ms.handleResourceRead(ctx, "tasks://board/")
+	assert.Error(t, err)
+}
+
+func TestTaskBoardResourceFindsTasksAmongManyMemories(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewLocalVectorStore("")
+	ms := &MemoryServer{container: &di.Container{VectorStore: store}}
+
+	task := newTaskChunk(t, "github.com/acme/api", types.TaskStatusTodo)
+	task.Timestamp = time.Now().Add(-time.Hour)
+	require.NoError(t, store.Store(ctx, task))
+	for i := 0; i < taskBoardLimit+1; i++ {
+		note := newReportChunk(t, "session-1", fmt.Sprintf("Note %d", i), types.ChunkTypeDiscussion, types.ChunkMetadata{})
+		require.NoError(t, store.Store(ctx, note))
+	}
+
+	contents, err := ms.handleResourceRead(ctx, TaskBoardURI("github.com/acme/api"))
+	require.NoError(t, err)
+	var board struct {
+		Total     int  `json:"total"`
+		Truncated bool `json:"truncated"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(contents[0].Text), &board))
+	assert.Equal(t, 1, board.Total, "newer memories of other types do not crowd tasks out")
+	assert.False(t, board.Truncated)
+}
+
+func TestNotifyTaskTransition(t *testing.T) {
+	type delivery struct {
+		method string
+		params interface{}
+	}
+	var delivered []delivery
+	ms := &MemoryServer{}
+	ms.subscribe(TaskBoardURI("acme"), "client-1", func(method string, params interface{}) error {
+		delivered = append(delivered, delivery{method, params})
+		return nil
+	})
+	chunk := newTaskChunk(t, "acme", types.TaskStatusInProgress)
+
+	// Unchanged status must not notify
+	ms.notifyTaskTransition(chunk, types.TaskStatusInProgress)
+	assert.Empty(t, delivered)
+
+	ms.notifyTaskTransition(chunk, types.TaskStatusTodo)
+	require.Len(t, delivered, 1)
+	assert.Equal(t, notificationResourceUpdated, delivered[0].method)
+	assert.Equal(t, &notifications.ResourceChangedParams{URI: "tasks://board/acme"}, delivered[0].params)
+
+	// Boards nobody subscribed to are not announced
+	ms.notifyTaskTransition(newTaskChunk(t, "other", types.TaskStatusCompleted), types.TaskStatusTodo)
+	assert.Len(t, delivered, 1)
+}
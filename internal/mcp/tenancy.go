@@ -0,0 +1,41 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"lerian-mcp-memory/internal/auth"
+	"lerian-mcp-memory/internal/security"
+	"lerian-mcp-memory/internal/tenancy"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+// TenancyMiddleware derives the tenant of requests authenticated with an API
+// key or a bearer token and attaches it to the context, so the storage layer
+// holds the request to the tenant's projects. Register it after
+// APIKeyMiddleware so tool calls see the key's current projects. Requests
+// without credentials, such as those over stdio, are not held to a tenant.
+func TenancyMiddleware() Middleware {
+	return func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+			if key := auth.KeyFrom(ctx); key != nil {
+				return next(tenancy.WithTenant(ctx, tenancy.FromKey(key)), req)
+			}
+			if claims := security.TokenClaimsFrom(ctx); claims != nil {
+				return next(tenancy.WithTenant(ctx, tenancy.FromClaims(claims)), req)
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// checkOperator fails unless the request is an operator's: one not held to a
+// tenant, or whose tenant owns every project. Tools that reach every tenant's
+// data, named by what, are limited to operators.
+func checkOperator(ctx context.Context, what string) error {
+	if tenant := tenancy.FromContext(ctx); tenant != nil && !tenant.AllowsAll() {
+		return fmt.Errorf("%w: %s spans every tenant's projects", tenancy.ErrCrossTenant, what)
+	}
+	return nil
+}
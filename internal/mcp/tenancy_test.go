@@ -0,0 +1,35 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/auth"
+	"lerian-mcp-memory/internal/security"
+	"lerian-mcp-memory/internal/tenancy"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenancyMiddleware(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	var seen *tenancy.Tenant
+	ms.Use(TenancyMiddleware(), func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+			seen = tenancy.FromContext(ctx)
+			return next(ctx, req)
+		}
+	})
+
+	ms.HandleRequest(context.Background(), toolCallRequest("echo", nil))
+	assert.Nil(t, seen, "requests without credentials are not held to a tenant")
+
+	key := &auth.Key{ID: "k1", Projects: []string{"acme/api"}}
+	ms.HandleRequest(auth.WithKey(context.Background(), key), toolCallRequest("echo", nil))
+	assert.Equal(t, &tenancy.Tenant{ID: "api_key:k1", Projects: []string{"acme/api"}}, seen)
+
+	claims := &security.TokenClaims{Subject: "agent-7", Projects: []string{"acme/web"}}
+	ms.HandleRequest(security.WithTokenClaims(context.Background(), claims), toolCallRequest("echo", nil))
+	assert.Equal(t, &tenancy.Tenant{ID: "oauth:agent-7", Projects: []string{"acme/web"}}, seen)
+}
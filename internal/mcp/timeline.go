@@ -0,0 +1,191 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/timeline"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/fredcamaral/gomcp-sdk"
+)
+
+const (
+	// maxTimelineChunks bounds the memories a timeline reads per repository
+	maxTimelineChunks = 5000
+
+	// defaultTimelineEntries is the page size of a bucket drill-down
+	defaultTimelineEntries = 50
+	// maxTimelineEntries bounds the page size a caller may request
+	maxTimelineEntries = 200
+)
+
+// registerTimelineTool registers memory_timeline
+func (ms *MemoryServer) registerTimelineTool() {
+	ms.addTool(mcp.NewTool(
+		"memory_timeline",
+		"Browse a repository's memory activity over time. Returns memories bucketed by day or week with counts per type, active sessions and highlights, including empty buckets. Pass a bucket start date to drill down into the memories of one day or week.",
+		mcp.ObjectSchema("Timeline parameters", map[string]interface{}{
+			"repository": map[string]interface{}{
+				"type":        "string",
+				"description": "Repository URL (required) - e.g. 'github.com/user/repo', or 'global' for every repository",
+			},
+			"granularity": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{string(timeline.GranularityDay), string(timeline.GranularityWeek)},
+				"default":     string(timeline.GranularityDay),
+				"description": "Bucket length. Weeks start on Monday; all buckets are UTC",
+			},
+			"from": map[string]interface{}{
+				"type":        "string",
+				"description": "Start of the window as a date (2006-01-02) or RFC3339 time. Defaults to 14 days or 8 weeks before 'to'",
+			},
+			"to": map[string]interface{}{
+				"type":        "string",
+				"description": "End of the window as a date (2006-01-02) or RFC3339 time, inclusive. Defaults to now",
+			},
+			"bucket": map[string]interface{}{
+				"type":        "string",
+				"description": "Drill down: a date in the day or week to list the memories of, e.g. a bucket's start",
+			},
+			"types": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Only count memories of these chunk types",
+			},
+			"session_id": map[string]interface{}{
+				"type":        "string",
+				"description": "Only count memories of this session",
+			},
+			"limit": map[string]interface{}{
+				"type":        "integer",
+				"default":     defaultTimelineEntries,
+				"description": "Drill down: number of memories to return (max 200)",
+			},
+			"offset": map[string]interface{}{
+				"type":        "integer",
+				"default":     0,
+				"description": "Drill down: number of memories to skip",
+			},
+		}, []string{"repository"}),
+	), mcp.ToolHandlerFunc(ms.handleTimeline))
+}
+
+// Timeline serves memory_timeline for callers outside MCP, such as the HTTP
+// timeline endpoint
+func (ms *MemoryServer) Timeline(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	return ms.handleTimeline(ctx, args)
+}
+
+// handleTimeline returns a repository's activity bucketed by day or week,
+// or the memories of one bucket when drilling down
+func (ms *MemoryServer) handleTimeline(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: memory_timeline called", "args", args)
+
+	repository, ok := args["repository"].(string)
+	if !ok || repository == "" {
+		return nil, errors.New("repository parameter is required. Example: {\"repository\": \"github.com/user/repo\", \"granularity\": \"week\", \"from\": \"2024-01-01\"}")
+	}
+
+	query, err := timelineQuery(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := query.Normalize(time.Now()); err != nil {
+		return nil, err
+	}
+
+	chunks, err := ms.liveChunks(ctx, repository, maxTimelineChunks)
+	if err != nil {
+		return nil, err
+	}
+
+	if bucket, _ := args["bucket"].(string); bucket != "" {
+		start, err := parseTimelineTime(bucket)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bucket: %w", err)
+		}
+		limit := defaultTimelineEntries
+		if l, ok := args["limit"].(float64); ok && l > 0 {
+			limit = min(int(l), maxTimelineEntries)
+		}
+		offset := 0
+		if o, ok := args["offset"].(float64); ok && o > 0 {
+			offset = int(o)
+		}
+
+		start = query.Granularity.Start(start)
+		entries, total := timeline.Entries(chunks, query, start, offset, limit)
+		return map[string]interface{}{
+			"status":      "success",
+			"repository":  repository,
+			"granularity": query.Granularity,
+			"bucket": map[string]interface{}{
+				"start": start,
+				"end":   query.Granularity.Next(start),
+			},
+			"memories": entries,
+			"total":    total,
+			"offset":   offset,
+			"has_more": offset+len(entries) < total,
+		}, nil
+	}
+
+	tl := timeline.Build(chunks, query)
+	return map[string]interface{}{
+		"status":      "success",
+		"repository":  repository,
+		"granularity": tl.Granularity,
+		"from":        tl.From,
+		"to":          tl.To,
+		"total":       tl.Total,
+		"buckets":     tl.Buckets,
+	}, nil
+}
+
+// timelineQuery reads a timeline query from tool arguments
+func timelineQuery(args map[string]interface{}) (*timeline.Query, error) {
+	query := &timeline.Query{}
+	if g, ok := args["granularity"].(string); ok {
+		query.Granularity = timeline.Granularity(g)
+	}
+	if from, _ := args["from"].(string); from != "" {
+		t, err := parseTimelineTime(from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from: %w", err)
+		}
+		query.From = t
+	}
+	if to, _ := args["to"].(string); to != "" {
+		t, err := parseTimelineTime(to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to: %w", err)
+		}
+		query.To = t
+	}
+	if rawTypes, ok := args["types"].([]interface{}); ok {
+		for _, raw := range rawTypes {
+			if t, ok := raw.(string); ok {
+				query.Types = append(query.Types, types.ChunkType(t))
+			}
+		}
+	}
+	query.SessionID, _ = args["session_id"].(string)
+	return query, nil
+}
+
+// parseTimelineTime parses a date or RFC3339 time. Windows extend to whole
+// buckets, so a date as the end of a window covers that day.
+func parseTimelineTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected a date (2006-01-02) or RFC3339 time, got %q", value)
+	}
+	return t, nil
+}
@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/timeline"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleTimeline(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	monday := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	for i, at := range []time.Time{monday.Add(9 * time.Hour), monday.Add(10 * time.Hour), monday.AddDate(0, 0, 8)} {
+		chunk := newReportChunk(t, "s1", "Timeline memory "+string(rune('a'+i)), types.ChunkTypeSolution, types.ChunkMetadata{})
+		chunk.Timestamp = at
+		require.NoError(t, store.Store(ctx, chunk))
+	}
+	other := newReportChunk(t, "s2", "Another repository", types.ChunkTypeProblem, types.ChunkMetadata{Repository: "github.com/acme/web"})
+	other.Timestamp = monday
+	require.NoError(t, store.Store(ctx, other))
+	ms := newCompositeTestServer(t, store)
+
+	_, err := ms.handleTimeline(ctx, map[string]interface{}{})
+	assert.Error(t, err, "repository is required")
+	_, err = ms.handleTimeline(ctx, map[string]interface{}{"repository": "github.com/acme/api", "from": "last sprint"})
+	assert.ErrorContains(t, err, "invalid from")
+
+	result, err := ms.handleTimeline(ctx, map[string]interface{}{
+		"repository":  "github.com/acme/api",
+		"granularity": "week",
+		"from":        "2024-03-11",
+		"to":          "2024-03-24",
+	})
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	assert.Equal(t, 3, response["total"])
+	buckets := response["buckets"].([]*timeline.Bucket)
+	require.Len(t, buckets, 2)
+	assert.Equal(t, 2, buckets[0].Count)
+	assert.Equal(t, 1, buckets[1].Count)
+
+	// Drill into the first week
+	result, err = ms.handleTimeline(ctx, map[string]interface{}{
+		"repository":  "github.com/acme/api",
+		"granularity": "week",
+		"bucket":      "2024-03-13",
+		"limit":       float64(1),
+	})
+	require.NoError(t, err)
+	response = result.(map[string]interface{})
+	assert.Equal(t, 2, response["total"])
+	assert.Equal(t, true, response["has_more"])
+	memories := response["memories"].([]timeline.Entry)
+	require.Len(t, memories, 1)
+	assert.Equal(t, monday.Add(10*time.Hour), memories[0].Timestamp)
+
+	result, err = ms.handleTimeline(ctx, map[string]interface{}{"repository": GlobalRepository, "from": "2024-03-11", "to": "2024-03-11"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.(map[string]interface{})["total"], "the global timeline covers every repository")
+}
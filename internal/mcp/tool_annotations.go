@@ -0,0 +1,181 @@
+package mcp
+
+import (
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+// ToolAnnotations are the MCP hints that describe how a tool behaves, so
+// clients can tell read-only tools such as memory_read from destructive ones
+// such as memory_delete, e.g. to skip confirmation prompts. Hints are
+// advisory; a nil hint takes the spec default (not read-only, destructive,
+// not idempotent, open world).
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    *bool  `json:"readOnlyHint,omitempty"`
+	DestructiveHint *bool  `json:"destructiveHint,omitempty"`
+	IdempotentHint  *bool  `json:"idempotentHint,omitempty"`
+	OpenWorldHint   *bool  `json:"openWorldHint,omitempty"`
+}
+
+// AnnotatedTool is a tool as listed by tools/list
+type AnnotatedTool struct {
+	protocol.Tool
+	Annotations *ToolAnnotations `json:"annotations,omitempty"`
+}
+
+// toolHints describes a tool that only touches this server's memory.
+// Read-only tools are neither destructive nor stateful, so they are idempotent.
+func toolHints(readOnly, destructive, idempotent bool) ToolAnnotations {
+	if readOnly {
+		destructive, idempotent = false, true
+	}
+	return ToolAnnotations{
+		ReadOnlyHint:    &readOnly,
+		DestructiveHint: &destructive,
+		IdempotentHint:  &idempotent,
+		OpenWorldHint:   boolPtr(false),
+	}
+}
+
+// openWorld marks a tool that also reaches services outside the server
+func openWorld(annotations ToolAnnotations) ToolAnnotations {
+	annotations.OpenWorldHint = boolPtr(true)
+	return annotations
+}
+
+// boolPtr returns a pointer to b
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// builtinToolAnnotations describe the tools this server registers. A tool
+// with several operations gets the hints of its most impactful operation:
+// memory_update is destructive because updates overwrite content, while
+// memory_create only adds memories.
+var builtinToolAnnotations = map[string]ToolAnnotations{
+	// Consolidated tools
+	"memory_create":                     toolHints(false, false, false),
+	"memory_read":                       toolHints(true, false, true),
+	"memory_update":                     toolHints(false, true, false),
+	"memory_delete":                     toolHints(false, true, true),
+	"memory_analyze":                    toolHints(false, false, false), // detect_threads creates threads
+	"memory_intelligence":               toolHints(false, false, false), // extract_decisions stores decisions
+	"memory_transfer":                   toolHints(false, false, false),
+	"memory_tasks":                      toolHints(false, false, false),
+	"memory_system":                     toolHints(false, false, false),
+	"memory_composite":                  toolHints(false, false, false),
+	"memory_trash_list":                 toolHints(true, false, true),
+	"memory_restore":                    toolHints(false, false, true),
+	"memory_pack_context":               toolHints(true, false, true),
+	"memory_timeline":                   toolHints(true, false, true),
+	"memory_graph_query":                toolHints(true, false, true),
+	"project_manage":                    toolHints(false, true, false), // delete can purge memories
+	"project_list":                      toolHints(true, false, true),
+	"memory_quality_report":             toolHints(false, false, true), // saves scores on memories
+	"tag_manage":                        toolHints(false, true, false), // delete strips the tag from memories
+	"tag_list":                          toolHints(true, false, true),
+	"memory_decay_policies":             toolHints(false, true, false), // run archives or deletes memories
+	"memory_decay_preview":              toolHints(true, false, true),
+	"memory_dedupe":                     toolHints(false, true, false),             // trashes the duplicates it merges
+	"memory_reflect":                    openWorld(toolHints(false, false, false)), // asks an LLM
+	"memory_coordinate":                 toolHints(false, true, false),             // delete_scratchpad removes notes
+	"system_tool_stats":                 toolHints(true, false, true),
+	"continue_result":                   toolHints(true, false, true),
+	"system_snapshot":                   toolHints(false, true, false),
+	"system_scoring_profiles":           toolHints(false, true, false),
+	"system_people":                     toolHints(false, true, false), // merge folds people together
+	"system_notification_subscriptions": openWorld(toolHints(false, true, false)),
+	"system_page_sync":                  openWorld(toolHints(false, true, false)), // replaces earlier imports
+	"system_slack_sync":                 openWorld(toolHints(false, true, false)),
+	"system_chaos":                      toolHints(false, true, true),
+	"auth_create_key":                   toolHints(false, false, false),
+	"auth_revoke_key":                   toolHints(false, true, true),
+	"auth_rotate_key":                   toolHints(false, true, false),
+	"auth_list_keys":                    toolHints(true, false, true),
+
+	// Legacy tools
+	"mcp__memory__memory_search":                    toolHints(true, false, true),
+	"mcp__memory__memory_search_explained":          toolHints(true, false, true),
+	"mcp__memory__memory_get_context":               toolHints(true, false, true),
+	"mcp__memory__memory_find_similar":              toolHints(true, false, true),
+	"mcp__memory__memory_get_patterns":              toolHints(true, false, true),
+	"mcp__memory__memory_suggest_related":           toolHints(true, false, true),
+	"mcp__memory__memory_get_relationships":         toolHints(true, false, true),
+	"mcp__memory__memory_traverse_graph":            toolHints(true, false, true),
+	"mcp__memory__memory_check_freshness":           toolHints(true, false, true),
+	"mcp__memory__memory_resolve_alias":             toolHints(true, false, true),
+	"mcp__memory__memory_list_aliases":              toolHints(true, false, true),
+	"mcp__memory__memory_get_bulk_progress":         toolHints(true, false, true),
+	"mcp__memory__memory_get_task_status":           toolHints(true, false, true),
+	"mcp__memory__memory_list_tasks":                toolHints(true, false, true),
+	"mcp__memory__memory_health":                    toolHints(true, false, true),
+	"mcp__memory__memory_export_project":            toolHints(true, false, true),
+	"mcp__memory__memory_bulk_export":               toolHints(true, false, true),
+	"mcp__memory__memory_generate_citations":        toolHints(true, false, true),
+	"mcp__memory__memory_create_inline_citation":    toolHints(true, false, true),
+	"mcp__memory__memory_store_chunk":               toolHints(false, false, false),
+	"mcp__memory__memory_store_decision":            toolHints(false, false, false),
+	"mcp__memory__memory_create_alias":              toolHints(false, false, false),
+	"mcp__memory__memory_create_task":               toolHints(false, false, false),
+	"mcp__memory__memory_link":                      toolHints(false, false, false),
+	"mcp__memory__memory_import_context":            toolHints(false, false, false),
+	"mcp__memory__memory_bulk_import":               toolHints(false, false, false),
+	"mcp__memory__memory_auto_detect_relationships": toolHints(false, false, false),
+	"mcp__memory__memory_mark_refreshed":            toolHints(false, false, true),
+	"mcp__memory__memory_complete_task":             toolHints(false, false, true),
+	"mcp__memory__memory_update_task":               toolHints(false, true, false),
+	"mcp__memory__memory_update_relationship":       toolHints(false, true, false),
+	"mcp__memory__memory_bulk_operation":            toolHints(false, true, false), // may delete
+}
+
+// setToolAnnotations records the annotations a tool was registered with;
+// none restores the built-in annotations
+func (ms *MemoryServer) setToolAnnotations(name string, annotations ...ToolAnnotations) {
+	ms.toolsMu.Lock()
+	defer ms.toolsMu.Unlock()
+	if len(annotations) == 0 {
+		delete(ms.toolAnnotations, name)
+		return
+	}
+	if ms.toolAnnotations == nil {
+		ms.toolAnnotations = make(map[string]ToolAnnotations)
+	}
+	ms.toolAnnotations[name] = annotations[0]
+}
+
+// ToolAnnotationsFor returns the annotations of a tool, if it has any
+func (ms *MemoryServer) ToolAnnotationsFor(name string) (ToolAnnotations, bool) {
+	ms.toolsMu.RLock()
+	annotations, ok := ms.toolAnnotations[name]
+	ms.toolsMu.RUnlock()
+	if ok {
+		return annotations, true
+	}
+	annotations, ok = builtinToolAnnotations[name]
+	return annotations, ok
+}
+
+// annotateToolList adds annotations to the tools of a tools/list response
+func (ms *MemoryServer) annotateToolList(resp *protocol.JSONRPCResponse) *protocol.JSONRPCResponse {
+	if resp == nil || resp.Error != nil {
+		return resp
+	}
+	result, ok := resp.Result.(map[string]interface{})
+	if !ok {
+		return resp
+	}
+	tools, ok := result["tools"].([]protocol.Tool)
+	if !ok {
+		return resp
+	}
+
+	annotated := make([]AnnotatedTool, len(tools))
+	for i := range tools {
+		annotated[i] = AnnotatedTool{Tool: tools[i]}
+		if annotations, ok := ms.ToolAnnotationsFor(tools[i].Name); ok {
+			annotated[i].Annotations = &annotations
+		}
+	}
+	result["tools"] = annotated
+	return resp
+}
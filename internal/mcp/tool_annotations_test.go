@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/di"
+
+	"github.com/fredcamaral/gomcp-sdk"
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolsListIncludesAnnotations(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	noop := mcp.ToolHandlerFunc(func(context.Context, map[string]interface{}) (interface{}, error) { return "ok", nil })
+	ms.addTool(mcp.NewTool("memory_read", "Read", mcp.ObjectSchema("Read", map[string]interface{}{}, nil)), noop)
+	ms.addTool(mcp.NewTool("memory_delete", "Delete", mcp.ObjectSchema("Delete", map[string]interface{}{}, nil)), noop)
+	ms.RegisterTool(mcp.NewTool("plugin_lookup", "Lookup", mcp.ObjectSchema("Lookup", map[string]interface{}{}, nil)), noop,
+		ToolAnnotations{Title: "Plugin lookup", ReadOnlyHint: boolPtr(true)})
+
+	resp := ms.HandleRequest(context.Background(), &protocol.JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list"})
+	require.Nil(t, resp.Error)
+	data, err := json.Marshal(resp.Result)
+	require.NoError(t, err)
+
+	var listed struct {
+		Tools []struct {
+			Name        string                 `json:"name"`
+			InputSchema map[string]interface{} `json:"inputSchema"`
+			Annotations map[string]interface{} `json:"annotations"`
+		} `json:"tools"`
+	}
+	require.NoError(t, json.Unmarshal(data, &listed))
+	byName := make(map[string]map[string]interface{})
+	for _, tool := range listed.Tools {
+		assert.NotNil(t, tool.InputSchema, "tools keep their schema")
+		byName[tool.Name] = tool.Annotations
+	}
+
+	assert.Equal(t, true, byName["memory_read"]["readOnlyHint"])
+	assert.Equal(t, false, byName["memory_read"]["destructiveHint"])
+	assert.Equal(t, false, byName["memory_delete"]["readOnlyHint"])
+	assert.Equal(t, true, byName["memory_delete"]["destructiveHint"])
+	assert.Equal(t, true, byName["memory_delete"]["idempotentHint"])
+	assert.Equal(t, map[string]interface{}{"title": "Plugin lookup", "readOnlyHint": true}, byName["plugin_lookup"])
+	assert.Nil(t, byName["echo"], "tools without annotations list none")
+
+	// Registering again without annotations drops them
+	ms.RegisterTool(mcp.NewTool("plugin_lookup", "Lookup", mcp.ObjectSchema("Lookup", map[string]interface{}{}, nil)), noop)
+	_, ok := ms.ToolAnnotationsFor("plugin_lookup")
+	assert.False(t, ok)
+}
+
+func TestBuiltinToolAnnotationsCoverConsolidatedTools(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	ms.container = &di.Container{Config: config.DefaultConfig()}
+	ms.registerConsolidatedTools()
+	for _, name := range listedToolNames(t, ms) {
+		if name == "echo" {
+			continue
+		}
+		annotations, ok := ms.ToolAnnotationsFor(name)
+		require.True(t, ok, "%s has no annotations", name)
+		assert.NotNil(t, annotations.ReadOnlyHint, name)
+		assert.NotNil(t, annotations.DestructiveHint, name)
+	}
+}
+
+func TestConsolidatedToolManifestListsEveryPage(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Server.ListPageSize = 5
+	tools, err := ConsolidatedToolManifest(cfg)
+	require.NoError(t, err)
+	require.Greater(t, len(tools), 5)
+
+	byName := make(map[string]AnnotatedTool, len(tools))
+	for i := range tools {
+		byName[tools[i].Name] = tools[i]
+	}
+	assert.Len(t, byName, len(tools), "pages do not overlap")
+	require.Contains(t, byName, "memory_read")
+	assert.NotNil(t, byName["memory_read"].InputSchema)
+	require.NotNil(t, byName["memory_read"].Annotations)
+	assert.True(t, *byName["memory_read"].Annotations.ReadOnlyHint)
+}
@@ -0,0 +1,139 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	mcp "github.com/fredcamaral/gomcp-sdk"
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+// Content block types of a tool result
+const (
+	ContentTypeText     = "text"
+	ContentTypeImage    = "image"
+	ContentTypeAudio    = "audio"
+	ContentTypeResource = "resource"
+)
+
+// ToolContent is one content block of a tool result. Text blocks carry Text,
+// image and audio blocks carry base64 Data with its MimeType, and resource
+// blocks embed a Resource.
+type ToolContent struct {
+	Type     string            `json:"type"`
+	Text     string            `json:"text,omitempty"`
+	Data     string            `json:"data,omitempty"`
+	MimeType string            `json:"mimeType,omitempty"`
+	Resource *EmbeddedResource `json:"resource,omitempty"`
+}
+
+// EmbeddedResource is a resource returned inline with a tool result: Text
+// for textual contents or base64 Blob for binary ones
+type EmbeddedResource struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+// ToolResult is a tool call result made of typed content blocks. Handlers
+// return it instead of a plain value to send images, audio or embedded
+// resources, e.g.
+//
+//	return NewToolResult(TextContent("Memories per week"), ImageContent(png, "image/png")), nil
+type ToolResult struct {
+	Content           []ToolContent `json:"content"`
+	StructuredContent interface{}   `json:"structuredContent,omitempty"`
+	IsError           bool          `json:"isError,omitempty"`
+}
+
+// NewToolResult builds a tool result from content blocks
+func NewToolResult(content ...ToolContent) *ToolResult {
+	return &ToolResult{Content: content}
+}
+
+// TextContent is a text block
+func TextContent(text string) ToolContent {
+	return ToolContent{Type: ContentTypeText, Text: text}
+}
+
+// ImageContent is an image block, e.g. a PNG chart with mimeType "image/png"
+func ImageContent(data []byte, mimeType string) ToolContent {
+	return ToolContent{Type: ContentTypeImage, Data: base64.StdEncoding.EncodeToString(data), MimeType: mimeType}
+}
+
+// AudioContent is an audio block, e.g. a recording with mimeType "audio/wav"
+func AudioContent(data []byte, mimeType string) ToolContent {
+	return ToolContent{Type: ContentTypeAudio, Data: base64.StdEncoding.EncodeToString(data), MimeType: mimeType}
+}
+
+// TextResourceContent embeds a textual resource
+func TextResourceContent(uri, mimeType, text string) ToolContent {
+	return ToolContent{Type: ContentTypeResource, Resource: &EmbeddedResource{URI: uri, MimeType: mimeType, Text: text}}
+}
+
+// BlobResourceContent embeds a binary resource
+func BlobResourceContent(uri, mimeType string, data []byte) ToolContent {
+	return ToolContent{Type: ContentTypeResource, Resource: &EmbeddedResource{URI: uri, MimeType: mimeType, Blob: base64.StdEncoding.EncodeToString(data)}}
+}
+
+// textFallback renders the result with text content only, describing the
+// blocks text cannot hold
+func (r *ToolResult) textFallback() *protocol.ToolCallResult {
+	result := &protocol.ToolCallResult{Content: make([]protocol.Content, 0, len(r.Content)), IsError: r.IsError}
+	for _, block := range r.Content {
+		text := block.Text
+		switch block.Type {
+		case ContentTypeImage, ContentTypeAudio:
+			text = fmt.Sprintf("[%s: %s]", block.Type, block.MimeType)
+		case ContentTypeResource:
+			if block.Resource != nil {
+				text = fmt.Sprintf("[resource: %s]", block.Resource.URI)
+			}
+		}
+		result.Content = append(result.Content, protocol.NewContent(text))
+	}
+	return result
+}
+
+// toolResultSlot holds the typed result of the tool call in a request, or
+// the error it failed with
+type toolResultSlot struct {
+	result *ToolResult
+	err    error
+}
+
+// toolResultSlotKey is the context key of a tools/call request's result slot
+type toolResultSlotKey struct{}
+
+// withToolResultSlot gives a tools/call request a slot for a typed result
+func withToolResultSlot(ctx context.Context) (context.Context, *toolResultSlot) {
+	slot := &toolResultSlot{}
+	return context.WithValue(ctx, toolResultSlotKey{}, slot), slot
+}
+
+// withTypedContent lets handler return a *ToolResult. The MCP server only
+// knows text content, so it gets a text rendering of the result while the
+// result itself waits in the request's slot for dispatch to send instead.
+// Errors wait there too, for dispatch to send as typed error content.
+func withTypedContent(handler protocol.ToolHandler) protocol.ToolHandler {
+	return mcp.ToolHandlerFunc(func(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+		result, err := handler.Handle(ctx, params)
+		slot, hasSlot := ctx.Value(toolResultSlotKey{}).(*toolResultSlot)
+		if err != nil {
+			if hasSlot {
+				slot.err = err
+			}
+			return result, err
+		}
+		rich, ok := result.(*ToolResult)
+		if !ok {
+			return result, nil
+		}
+		if hasSlot {
+			slot.result = rich
+		}
+		return rich.textFallback(), nil
+	})
+}
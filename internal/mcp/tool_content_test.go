@@ -0,0 +1,66 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/fredcamaral/gomcp-sdk"
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pngHeader stands in for a generated chart
+var pngHeader = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+
+func TestToolCallReturnsTypedContent(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	ms.addTool(mcp.NewTool("chart", "Chart", mcp.ObjectSchema("Chart", map[string]interface{}{}, nil)),
+		mcp.ToolHandlerFunc(func(context.Context, map[string]interface{}) (interface{}, error) {
+			return NewToolResult(
+				TextContent("Memories per week"),
+				ImageContent(pngHeader, "image/png"),
+				AudioContent([]byte("RIFF"), "audio/wav"),
+				TextResourceContent("memory://chunk/1", "text/markdown", "# Decision"),
+				BlobResourceContent("memory://export/1", "application/zip", []byte("PK")),
+			), nil
+		}))
+
+	resp := ms.HandleRequest(context.Background(), toolCallRequest("chart", nil))
+	require.Nil(t, resp.Error)
+	data, err := json.Marshal(resp.Result)
+	require.NoError(t, err)
+
+	var result ToolResult
+	require.NoError(t, json.Unmarshal(data, &result))
+	require.Len(t, result.Content, 5)
+	assert.Equal(t, TextContent("Memories per week"), result.Content[0])
+
+	image := result.Content[1]
+	assert.Equal(t, ContentTypeImage, image.Type)
+	assert.Equal(t, "image/png", image.MimeType)
+	decoded, err := base64.StdEncoding.DecodeString(image.Data)
+	require.NoError(t, err)
+	assert.Equal(t, pngHeader, decoded)
+
+	assert.Equal(t, ContentTypeAudio, result.Content[2].Type)
+	assert.Equal(t, "# Decision", result.Content[3].Resource.Text)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("PK")), result.Content[4].Resource.Blob)
+	assert.NotContains(t, string(data), `"text":""`, "empty fields are left out")
+
+	// Plain results are still sent as text
+	resp = ms.HandleRequest(context.Background(), toolCallRequest("echo", nil))
+	require.Nil(t, resp.Error)
+	assert.Equal(t, "ok", resp.Result.(*protocol.ToolCallResult).Content[0].Text)
+}
+
+func TestToolResultTextFallback(t *testing.T) {
+	result := NewToolResult(TextContent("chart"), ImageContent(pngHeader, "image/png"), TextResourceContent("memory://chunk/1", "", "body"))
+	fallback := result.textFallback()
+	require.Len(t, fallback.Content, 3)
+	assert.Equal(t, "chart", fallback.Content[0].Text)
+	assert.Equal(t, "[image: image/png]", fallback.Content[1].Text)
+	assert.Equal(t, "[resource: memory://chunk/1]", fallback.Content[2].Text)
+}
@@ -0,0 +1,215 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+
+	"lerian-mcp-memory/internal/embeddings"
+	"lerian-mcp-memory/internal/locking"
+	"lerian-mcp-memory/internal/projects"
+	"lerian-mcp-memory/internal/security"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/tenancy"
+)
+
+// ToolErrorCode classifies why a tool call failed
+type ToolErrorCode string
+
+// Tool error codes
+const (
+	ToolErrorMissingParameter ToolErrorCode = "missing_parameter"
+	ToolErrorInvalidParameter ToolErrorCode = "invalid_parameter"
+	ToolErrorNotFound         ToolErrorCode = "not_found"
+	ToolErrorConflict         ToolErrorCode = "conflict"
+	ToolErrorPermissionDenied ToolErrorCode = "permission_denied"
+	ToolErrorUnsupported      ToolErrorCode = "unsupported"
+	ToolErrorUnavailable      ToolErrorCode = "unavailable"
+	ToolErrorTimeout          ToolErrorCode = "timeout"
+	ToolErrorInternal         ToolErrorCode = "internal_error"
+)
+
+// defaultSuggestions tell a client what to do about an error that came
+// without a suggestion of its own
+var defaultSuggestions = map[ToolErrorCode]string{
+	ToolErrorMissingParameter: "Add the missing parameter and call the tool again",
+	ToolErrorInvalidParameter: "Correct the parameter and call the tool again",
+	ToolErrorNotFound:         "Check the ID, or search for the memory first",
+	ToolErrorConflict:         "Read the current state and retry the change",
+	ToolErrorPermissionDenied: "Stay within the repositories and paths this client may access",
+	ToolErrorUnsupported:      "This server is not configured for the operation; use another operation",
+	ToolErrorUnavailable:      "Retry later",
+	ToolErrorTimeout:          "Retry with a narrower request, e.g. a smaller limit",
+	ToolErrorInternal:         "Retry later; report the error if it persists",
+}
+
+// ToolError is a tool failure a client can act on: what went wrong, which
+// parameter caused it, what to do instead and an example of correct
+// arguments. Failed tool calls send it as isError content, e.g.
+//
+//	return nil, NewToolError(ToolErrorMissingParameter, "content parameter is required").
+//		WithParameter("content").
+//		WithExample(`{"content": "Fixed the login bug", "session_id": "auth-fix"}`)
+type ToolError struct {
+	Code       ToolErrorCode   `json:"code"`
+	Message    string          `json:"message"`
+	Parameter  string          `json:"parameter,omitempty"`
+	Suggestion string          `json:"suggestion,omitempty"`
+	Example    json.RawMessage `json:"example,omitempty"`
+	Retryable  bool            `json:"retryable"`
+}
+
+// NewToolError creates a tool error
+func NewToolError(code ToolErrorCode, message string) *ToolError {
+	return &ToolError{
+		Code:      code,
+		Message:   message,
+		Retryable: code == ToolErrorUnavailable || code == ToolErrorTimeout,
+	}
+}
+
+// WithParameter names the parameter that caused the error
+func (e *ToolError) WithParameter(parameter string) *ToolError {
+	e.Parameter = parameter
+	return e
+}
+
+// WithSuggestion tells the client how to recover
+func (e *ToolError) WithSuggestion(suggestion string) *ToolError {
+	e.Suggestion = suggestion
+	return e
+}
+
+// WithExample adds example arguments, as JSON, that would have worked
+func (e *ToolError) WithExample(example string) *ToolError {
+	e.Example = json.RawMessage(example)
+	return e
+}
+
+// Error renders the error as the sentence clients without structured error
+// support read
+func (e *ToolError) Error() string {
+	sentences := []string{strings.TrimSuffix(e.Message, ".") + "."}
+	if e.Suggestion != "" {
+		sentences = append(sentences, strings.TrimSuffix(e.Suggestion, ".")+".")
+	}
+	if len(e.Example) > 0 {
+		sentences = append(sentences, "Example: "+string(e.Example))
+	}
+	return strings.Join(sentences, " ")
+}
+
+var (
+	requiredParameterPattern = regexp.MustCompile(`^(\w+) (?:parameter )?(?:is|are) required`)
+	invalidParameterPattern  = regexp.MustCompile(`^(?:invalid|unknown|unsupported) (\w+)(?: parameter)?:? ["'\d]`)
+	operationPattern         = regexp.MustCompile(`^(?:unsupported|unknown|invalid) (?:\w+ )?operation\b`)
+	examplePattern           = regexp.MustCompile(`Example: [{\[]`)
+)
+
+// asToolError returns err as a tool error. Errors that are not one yet are
+// classified by their sentinel or their wording, and the recovery hints and
+// examples tool handlers write into their messages are lifted out.
+func asToolError(err error) *ToolError {
+	var typed *ToolError
+	if errors.As(err, &typed) {
+		toolErr := *typed
+		if toolErr.Suggestion == "" {
+			toolErr.Suggestion = defaultSuggestions[toolErr.Code]
+		}
+		return &toolErr
+	}
+
+	message, suggestion, example := splitErrorMessage(err.Error())
+	toolErr := NewToolError(classifyToolError(err, message), message)
+	switch {
+	case operationPattern.MatchString(message):
+		toolErr.Parameter = "operation"
+	case toolErr.Code == ToolErrorMissingParameter:
+		if match := requiredParameterPattern.FindStringSubmatch(message); match != nil {
+			toolErr.Parameter = match[1]
+		}
+	case toolErr.Code == ToolErrorInvalidParameter:
+		if match := invalidParameterPattern.FindStringSubmatch(message); match != nil {
+			toolErr.Parameter = match[1]
+		}
+	}
+
+	toolErr.Suggestion = suggestion
+	if toolErr.Suggestion == "" {
+		toolErr.Suggestion = defaultSuggestions[toolErr.Code]
+	}
+	toolErr.Example = example
+	return toolErr
+}
+
+// classifyToolError picks the code of an error that is not a tool error
+func classifyToolError(err error, message string) ToolErrorCode {
+	var conflict *VersionConflictError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ToolErrorTimeout
+	case errors.Is(err, ErrShuttingDown), errors.Is(err, storage.ErrIngestionQueueFull), errors.Is(err, context.Canceled):
+		return ToolErrorUnavailable
+	case errors.Is(err, ErrPathOutsideRoots), errors.Is(err, security.ErrResourceDenied), errors.Is(err, tenancy.ErrCrossTenant):
+		return ToolErrorPermissionDenied
+	case errors.Is(err, embeddings.ErrEmbeddingsDisabled), errors.Is(err, ErrSamplingUnavailable), errors.Is(err, ErrSamplingNotSupported):
+		return ToolErrorUnsupported
+	case errors.Is(err, locking.ErrLocked), errors.As(err, &conflict), errors.Is(err, projects.ErrArchived), errors.Is(err, projects.ErrRenamed):
+		return ToolErrorConflict
+	case errors.Is(err, projects.ErrUnregistered):
+		return ToolErrorNotFound
+	}
+
+	lower := strings.ToLower(message)
+	switch {
+	case requiredParameterPattern.MatchString(message), strings.Contains(lower, " requires "):
+		return ToolErrorMissingParameter
+	case strings.Contains(lower, "not found"):
+		return ToolErrorNotFound
+	case strings.HasPrefix(lower, "invalid "), strings.HasPrefix(lower, "unknown "), strings.HasPrefix(lower, "unsupported "),
+		strings.Contains(lower, "must be"), strings.Contains(lower, "must not"):
+		return ToolErrorInvalidParameter
+	}
+	return ToolErrorInternal
+}
+
+// splitErrorMessage splits a handler's error text, e.g. "content parameter
+// is required. Use X. Example: {...}", into the message, the recovery hint
+// and the example arguments
+func splitErrorMessage(text string) (message, suggestion string, example json.RawMessage) {
+	if loc := examplePattern.FindStringIndex(text); loc != nil {
+		var raw json.RawMessage
+		if err := json.NewDecoder(strings.NewReader(text[loc[1]-1:])).Decode(&raw); err == nil {
+			var compacted bytes.Buffer
+			if json.Compact(&compacted, raw) == nil {
+				example = compacted.Bytes()
+				text = text[:loc[0]]
+			}
+		}
+	}
+
+	text = strings.TrimSpace(text)
+	if i := strings.Index(text, ". "); i >= 0 {
+		message, suggestion = text[:i], strings.TrimSpace(text[i+2:])
+	} else {
+		message = text
+	}
+	return strings.TrimSuffix(message, "."), strings.TrimSuffix(suggestion, "."), example
+}
+
+// toolErrorResult renders a failed tool call as isError content: the error
+// sentence first for clients that only read text, then its details as JSON,
+// also sent as structured content
+func toolErrorResult(err error) *ToolResult {
+	details := map[string]interface{}{"error": asToolError(err)}
+	result := NewToolResult(TextContent(err.Error()))
+	if data, marshalErr := json.Marshal(details); marshalErr == nil {
+		result.Content = append(result.Content, TextContent(string(data)))
+	}
+	result.StructuredContent = details
+	result.IsError = true
+	return result
+}
@@ -0,0 +1,132 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"lerian-mcp-memory/internal/locking"
+
+	mcp "github.com/fredcamaral/gomcp-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsToolError(t *testing.T) {
+	for _, tc := range []struct {
+		err        error
+		code       ToolErrorCode
+		parameter  string
+		message    string
+		suggestion string
+		example    string
+	}{
+		{
+			err:        errors.New(`chunk_ids parameter is required for get_chunks and must be a non-empty array. Example: {"chunk_ids": ["id1", "id2"], "repository": "github.com/user/repo"}`),
+			code:       ToolErrorMissingParameter,
+			parameter:  "chunk_ids",
+			message:    "chunk_ids parameter is required for get_chunks and must be a non-empty array",
+			suggestion: defaultSuggestions[ToolErrorMissingParameter],
+			example:    `{"chunk_ids":["id1","id2"],"repository":"github.com/user/repo"}`,
+		},
+		{
+			err:        errors.New("unsupported read operation 'teleport'. Valid operations: search, get_context"),
+			code:       ToolErrorInvalidParameter,
+			parameter:  "operation",
+			message:    "unsupported read operation 'teleport'",
+			suggestion: "Valid operations: search, get_context",
+		},
+		{
+			err:        fmt.Errorf("invalid relation type %q. Use memory_read list_relation_types for the valid options", "Solved By"),
+			code:       ToolErrorInvalidParameter,
+			message:    `invalid relation type "Solved By"`,
+			suggestion: "Use memory_read list_relation_types for the valid options",
+		},
+		{
+			err:        fmt.Errorf("invalid strategy %q: use bfs or dfs", "random"),
+			code:       ToolErrorInvalidParameter,
+			parameter:  "strategy",
+			message:    `invalid strategy "random": use bfs or dfs`,
+			suggestion: defaultSuggestions[ToolErrorInvalidParameter],
+		},
+		{
+			err:        errors.New("chunk not found: 42"),
+			code:       ToolErrorNotFound,
+			message:    "chunk not found: 42",
+			suggestion: defaultSuggestions[ToolErrorNotFound],
+		},
+		{
+			err:        fmt.Errorf("update failed: %w", locking.ErrLocked),
+			code:       ToolErrorConflict,
+			message:    "update failed: chunk is locked",
+			suggestion: defaultSuggestions[ToolErrorConflict],
+		},
+		{
+			err:        fmt.Errorf("search: %w", context.DeadlineExceeded),
+			code:       ToolErrorTimeout,
+			message:    "search: context deadline exceeded",
+			suggestion: defaultSuggestions[ToolErrorTimeout],
+		},
+		{
+			err:        errors.New("qdrant: connection refused"),
+			code:       ToolErrorInternal,
+			message:    "qdrant: connection refused",
+			suggestion: defaultSuggestions[ToolErrorInternal],
+		},
+	} {
+		toolErr := asToolError(tc.err)
+		assert.Equal(t, tc.code, toolErr.Code, tc.err.Error())
+		assert.Equal(t, tc.parameter, toolErr.Parameter, tc.err.Error())
+		assert.Equal(t, tc.message, toolErr.Message, tc.err.Error())
+		assert.Equal(t, tc.suggestion, toolErr.Suggestion, tc.err.Error())
+		assert.Equal(t, tc.example, string(toolErr.Example), tc.err.Error())
+		assert.Equal(t, tc.code == ToolErrorTimeout, toolErr.Retryable, tc.err.Error())
+	}
+}
+
+func TestToolErrorKeepsItsSentence(t *testing.T) {
+	ms := &MemoryServer{}
+	_, _, err := ms.validateStoreChunkParams(map[string]interface{}{"content": "Fixed it"})
+	assert.EqualError(t, err, `session_id parameter is required and must be non-empty string. Use descriptive session IDs. Example: {"session_id": "bug-fix-2024", "content": "Solution details"}`)
+
+	toolErr := asToolError(fmt.Errorf("store_chunk: %w", err))
+	assert.Equal(t, ToolErrorMissingParameter, toolErr.Code)
+	assert.Equal(t, "session_id", toolErr.Parameter)
+	assert.Equal(t, "Use descriptive session IDs", toolErr.Suggestion)
+	assert.JSONEq(t, `{"session_id": "bug-fix-2024", "content": "Solution details"}`, string(toolErr.Example))
+}
+
+func TestToolCallReturnsTypedError(t *testing.T) {
+	ms := newMiddlewareTestServer()
+	ms.addTool(mcp.NewTool("fail", "Fail", mcp.ObjectSchema("Fail", map[string]interface{}{}, nil)),
+		mcp.ToolHandlerFunc(func(context.Context, map[string]interface{}) (interface{}, error) {
+			return nil, NewToolError(ToolErrorInvalidParameter, "limit must be between 1 and 100").
+				WithParameter("limit").
+				WithExample(`{"limit": 10}`)
+		}))
+
+	resp := ms.HandleRequest(context.Background(), toolCallRequest("fail", nil))
+	require.Nil(t, resp.Error, "tool failures are results, not JSON-RPC errors")
+	data, err := json.Marshal(resp.Result)
+	require.NoError(t, err)
+
+	var result struct {
+		Content           []ToolContent `json:"content"`
+		StructuredContent struct {
+			Error ToolError `json:"error"`
+		} `json:"structuredContent"`
+		IsError bool `json:"isError"`
+	}
+	require.NoError(t, json.Unmarshal(data, &result))
+	assert.True(t, result.IsError)
+	require.Len(t, result.Content, 2)
+	assert.Equal(t, `limit must be between 1 and 100. Example: {"limit": 10}`, result.Content[0].Text)
+	assert.JSONEq(t, `{"error":{"code":"invalid_parameter","message":"limit must be between 1 and 100","parameter":"limit","suggestion":"Correct the parameter and call the tool again","example":{"limit":10},"retryable":false}}`, result.Content[1].Text)
+
+	details := result.StructuredContent.Error
+	assert.Equal(t, ToolErrorInvalidParameter, details.Code)
+	assert.Equal(t, "limit", details.Parameter)
+	assert.JSONEq(t, `{"limit": 10}`, string(details.Example))
+}
@@ -0,0 +1,56 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/di"
+
+	"github.com/fredcamaral/gomcp-sdk"
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+// ToolManifest returns the tools the server lists, with their schemas and
+// annotations, in tools/list order across every page
+func (ms *MemoryServer) ToolManifest(ctx context.Context) ([]AnnotatedTool, error) {
+	var tools []AnnotatedTool
+	cursor := ""
+	for {
+		params := map[string]interface{}{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		resp := ms.HandleRequest(ctx, &protocol.JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list", Params: params})
+		if resp.Error != nil {
+			return nil, fmt.Errorf("failed to list tools: %s", resp.Error.Message)
+		}
+		result, ok := resp.Result.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected tools/list result %T", resp.Result)
+		}
+		page, ok := result["tools"].([]AnnotatedTool)
+		if !ok {
+			return nil, fmt.Errorf("unexpected tools/list tools %T", result["tools"])
+		}
+		tools = append(tools, page...)
+
+		next, _ := result["nextCursor"].(string)
+		if next == "" {
+			return tools, nil
+		}
+		cursor = next
+	}
+}
+
+// ConsolidatedToolManifest returns the manifest of the consolidated tools
+// a server with cfg registers by default. It only builds the tool registry,
+// so it needs no storage or network and suits code generation.
+func ConsolidatedToolManifest(cfg *config.Config) ([]AnnotatedTool, error) {
+	ms := &MemoryServer{
+		mcpServer: mcp.NewServer("manifest", "1.0.0"),
+		container: &di.Container{Config: cfg},
+	}
+	ms.registerConsolidatedTools()
+	return ms.ToolManifest(context.Background())
+}
@@ -0,0 +1,109 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/monitoring"
+
+	"github.com/fredcamaral/gomcp-sdk"
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+// addTool registers a tool, recording its invocations in the tool metrics
+// and cutting results larger than its response limit down to size.
+// Handlers may return a *ToolResult to send typed content.
+func (ms *MemoryServer) addTool(tool protocol.Tool, handler protocol.ToolHandler) {
+	ms.trackTool(tool.Name)
+	handler = withTypedContent(ms.withResponseLimit(tool.Name, handler))
+	if ms.toolMetrics == nil {
+		ms.mcpServer.AddTool(tool, handler)
+		return
+	}
+
+	metrics := ms.toolMetrics
+	ms.mcpServer.AddTool(tool, mcp.ToolHandlerFunc(func(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+		start := time.Now()
+		result, err := handler.Handle(ctx, params)
+		metrics.Record(tool.Name, time.Since(start), err)
+		return result, err
+	}))
+}
+
+// GetToolMetrics returns the per-tool usage metrics
+func (ms *MemoryServer) GetToolMetrics() *monitoring.ToolMetrics {
+	return ms.toolMetrics
+}
+
+// registerToolStatsTool registers system_tool_stats
+func (ms *MemoryServer) registerToolStatsTool() {
+	ms.addTool(mcp.NewTool(
+		"system_tool_stats",
+		"Report per-tool usage since the server started: invocation counts, error rates and latency percentiles (p50/p95/p99), busiest tools first, and the running, waiting and refused requests of each QoS class (interactive, bulk, admin). Use it to see which tools are hot or failing.",
+		mcp.ObjectSchema("Tool statistics parameters", map[string]interface{}{
+			"tool": map[string]interface{}{
+				"type":        "string",
+				"description": "Only report this tool, e.g. 'memory_read'",
+			},
+			"errors_only": map[string]interface{}{
+				"type":        "boolean",
+				"default":     false,
+				"description": "Only report tools that returned at least one error",
+			},
+		}, []string{}),
+	), mcp.ToolHandlerFunc(ms.handleToolStats))
+}
+
+// handleToolStats returns the tool usage snapshot
+func (ms *MemoryServer) handleToolStats(_ context.Context, args map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: system_tool_stats called", "args", args)
+
+	if ms.toolMetrics == nil {
+		return map[string]interface{}{
+			"status":  "success",
+			"enabled": false,
+			"tools":   []monitoring.ToolStats{},
+		}, nil
+	}
+
+	toolName, _ := args["tool"].(string)
+	errorsOnly, _ := args["errors_only"].(bool)
+
+	stats := ms.toolMetrics.Stats()
+	tools := make([]monitoring.ToolStats, 0, len(stats))
+	var totalCalls, totalErrors int64
+	for i := range stats {
+		totalCalls += stats[i].Calls
+		totalErrors += stats[i].Errors
+		if toolName != "" && stats[i].Tool != toolName {
+			continue
+		}
+		if errorsOnly && stats[i].Errors == 0 {
+			continue
+		}
+		tools = append(tools, stats[i])
+	}
+
+	errorRate := 0.0
+	if totalCalls > 0 {
+		errorRate = float64(totalErrors) / float64(totalCalls)
+	}
+
+	response := map[string]interface{}{
+		"status":  "success",
+		"enabled": true,
+		"since":   ms.toolMetrics.Since().Format(time.RFC3339),
+		"summary": map[string]interface{}{
+			"total_calls":  totalCalls,
+			"total_errors": totalErrors,
+			"error_rate":   errorRate,
+			"tools_used":   len(stats),
+		},
+		"tools": tools,
+	}
+	if ms.qos != nil {
+		response["qos"] = ms.qos.Stats()
+	}
+	return response, nil
+}
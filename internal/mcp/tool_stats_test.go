@@ -0,0 +1,64 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"lerian-mcp-memory/internal/monitoring"
+
+	mcp "github.com/fredcamaral/gomcp-sdk"
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/fredcamaral/gomcp-sdk/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func callTool(t *testing.T, srv *server.Server, name string, args map[string]interface{}) *protocol.JSONRPCResponse {
+	t.Helper()
+	return srv.HandleRequest(context.Background(), &protocol.JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params:  map[string]interface{}{"name": name, "arguments": args},
+	})
+}
+
+func TestAddToolRecordsMetrics(t *testing.T) {
+	ms := &MemoryServer{
+		mcpServer:   mcp.NewServer("test", "1.0.0"),
+		toolMetrics: monitoring.NewToolMetrics(),
+	}
+	ms.addTool(mcp.NewTool("ok_tool", "", mcp.ObjectSchema("", map[string]interface{}{}, []string{})),
+		mcp.ToolHandlerFunc(func(context.Context, map[string]interface{}) (interface{}, error) {
+			return map[string]interface{}{"status": "success"}, nil
+		}))
+	ms.addTool(mcp.NewTool("failing_tool", "", mcp.ObjectSchema("", map[string]interface{}{}, []string{})),
+		mcp.ToolHandlerFunc(func(context.Context, map[string]interface{}) (interface{}, error) {
+			return nil, errors.New("boom")
+		}))
+	ms.registerToolStatsTool()
+
+	callTool(t, ms.mcpServer, "ok_tool", nil)
+	callTool(t, ms.mcpServer, "ok_tool", nil)
+	callTool(t, ms.mcpServer, "failing_tool", nil)
+
+	result, err := ms.handleToolStats(context.Background(), map[string]interface{}{})
+	require.NoError(t, err)
+	report := result.(map[string]interface{})
+	summary := report["summary"].(map[string]interface{})
+	assert.Equal(t, int64(3), summary["total_calls"])
+	assert.Equal(t, int64(1), summary["total_errors"])
+
+	tools := report["tools"].([]monitoring.ToolStats)
+	require.Len(t, tools, 2)
+	assert.Equal(t, "ok_tool", tools[0].Tool)
+	assert.Equal(t, int64(2), tools[0].Calls)
+
+	result, err = ms.handleToolStats(context.Background(), map[string]interface{}{"errors_only": true})
+	require.NoError(t, err)
+	tools = result.(map[string]interface{})["tools"].([]monitoring.ToolStats)
+	require.Len(t, tools, 1)
+	assert.Equal(t, "failing_tool", tools[0].Tool)
+	assert.Equal(t, "boom", tools[0].LastError)
+}
@@ -0,0 +1,247 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"lerian-mcp-memory/internal/audit"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/fredcamaral/gomcp-sdk"
+)
+
+const (
+	// defaultTrashRetentionDays applies when no configuration is available
+	defaultTrashRetentionDays = 30
+
+	// trashPurgeInterval is how often expired trash is hard-deleted
+	trashPurgeInterval = time.Hour
+
+	// trashScanLimit bounds the chunks scanned per repository when listing trash
+	trashScanLimit = 10000
+)
+
+// registerTrashTools registers memory_trash_list and memory_restore
+func (ms *MemoryServer) registerTrashTools() {
+	ms.addTool(mcp.NewTool(
+		"memory_trash_list",
+		"List memories in the trash for a repository. Deleted memories stay restorable until the trash retention period expires, after which they are purged permanently.",
+		mcp.ObjectSchema("Trash list parameters", map[string]interface{}{
+			"repository": map[string]interface{}{
+				"type":        "string",
+				"description": "Repository URL (required) - e.g. 'github.com/user/repo'",
+			},
+			"limit": map[string]interface{}{
+				"type":        "integer",
+				"default":     50,
+				"description": "Maximum number of trashed memories to return",
+			},
+		}, []string{"repository"}),
+	), mcp.ToolHandlerFunc(ms.handleTrashList))
+
+	ms.addTool(mcp.NewTool(
+		"memory_restore",
+		"Restore memories from the trash, or from the archive a decay policy moved them to, so they appear in search again.",
+		mcp.ObjectSchema("Restore parameters", map[string]interface{}{
+			"repository": map[string]interface{}{
+				"type":        "string",
+				"description": "Repository URL (required) - e.g. 'github.com/user/repo'",
+			},
+			"ids": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "IDs of trashed or archived memories to restore (required)",
+			},
+		}, []string{"repository", "ids"}),
+	), mcp.ToolHandlerFunc(ms.handleRestore))
+}
+
+// trashRetention returns how long trashed chunks are kept before purging
+func (ms *MemoryServer) trashRetention() time.Duration {
+	days := defaultTrashRetentionDays
+	if cfg := ms.container.Config; cfg != nil {
+		days = cfg.Storage.TrashRetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// trashChunk moves a chunk to the trash
+func (ms *MemoryServer) trashChunk(ctx context.Context, chunk *types.ConversationChunk, now time.Time) error {
+	deletedAt := now.UTC()
+	trashed, err := ms.updateChunk(ctx, chunk.ID, "", func(current *types.ConversationChunk) error {
+		current.Metadata.DeletedAt = &deletedAt
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	*chunk = *trashed
+	return nil
+}
+
+// handleTrashList lists trashed chunks of a repository, most recently deleted first
+func (ms *MemoryServer) handleTrashList(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	repository, ok := args["repository"].(string)
+	if !ok || repository == "" {
+		return nil, errors.New("repository parameter is required. Example: {\"repository\": \"github.com/user/repo\"}")
+	}
+
+	limit := 50
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+
+	chunks, err := ms.container.GetVectorStore().ListByRepository(storage.WithDeleted(ctx), repository, trashScanLimit, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repository chunks: %w", err)
+	}
+
+	trashed := make([]types.ConversationChunk, 0)
+	for i := range chunks {
+		if chunks[i].IsDeleted() {
+			trashed = append(trashed, chunks[i])
+		}
+	}
+	sort.Slice(trashed, func(i, j int) bool {
+		return trashed[i].Metadata.DeletedAt.After(*trashed[j].Metadata.DeletedAt)
+	})
+
+	total := len(trashed)
+	if len(trashed) > limit {
+		trashed = trashed[:limit]
+	}
+
+	retention := ms.trashRetention()
+	items := make([]map[string]interface{}, 0, len(trashed))
+	for i := range trashed {
+		chunk := &trashed[i]
+		items = append(items, map[string]interface{}{
+			"chunk_id":   chunk.ID,
+			"type":       chunk.Type,
+			"summary":    chunk.Summary,
+			"deleted_at": chunk.Metadata.DeletedAt.Format(time.RFC3339),
+			"purge_at":   chunk.Metadata.DeletedAt.Add(retention).Format(time.RFC3339),
+		})
+	}
+
+	return map[string]interface{}{
+		"repository":     repository,
+		"total":          total,
+		"items":          items,
+		"retention_days": int(retention.Hours() / 24),
+	}, nil
+}
+
+// handleRestore clears the deletion or archive mark of chunks owned by the repository
+func (ms *MemoryServer) handleRestore(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	repository, ok := args["repository"].(string)
+	if !ok || repository == "" {
+		return nil, errors.New("repository parameter is required. Example: {\"repository\": \"github.com/user/repo\", \"ids\": [\"chunk-id\"]}")
+	}
+
+	rawIDs, ok := args["ids"].([]interface{})
+	if !ok || len(rawIDs) == 0 {
+		return nil, errors.New("ids parameter is required and must be a non-empty array of chunk IDs")
+	}
+
+	restored := make([]string, 0, len(rawIDs))
+	rejected := make([]string, 0)
+
+	for _, raw := range rawIDs {
+		id, ok := raw.(string)
+		if !ok || id == "" {
+			rejected = append(rejected, fmt.Sprintf("invalid_id_%v", raw))
+			continue
+		}
+
+		_, err := ms.updateChunk(ctx, id, "", func(chunk *types.ConversationChunk) error {
+			if chunk.Metadata.Repository != repository || (!chunk.IsDeleted() && !chunk.IsArchived()) {
+				return errChunkUnchanged
+			}
+			chunk.Metadata.DeletedAt = nil
+			delete(chunk.Metadata.ExtendedMetadata, types.EMKeyArchivedAt)
+			delete(chunk.Metadata.ExtendedMetadata, types.EMKeyArchivedBy)
+			return nil
+		})
+		if err != nil {
+			if !errors.Is(err, errChunkUnchanged) {
+				logging.Error("Failed to restore chunk", "id", id, "error", err)
+			}
+			rejected = append(rejected, id)
+			continue
+		}
+		restored = append(restored, id)
+	}
+
+	ms.container.AuditLogger.LogEvent(ctx, audit.EventTypeMemoryUpdate, "memory_restore", "repository", repository, map[string]interface{}{
+		"restored": restored,
+		"rejected": rejected,
+	})
+
+	result := map[string]interface{}{
+		"status":         "success",
+		"repository":     repository,
+		"restored_count": len(restored),
+		"restored_ids":   restored,
+		"rejected_count": len(rejected),
+	}
+	if len(rejected) > 0 {
+		result["rejected_ids"] = rejected
+		result["note"] = "Rejected IDs were not found, neither trashed nor archived, or belong to another repository"
+	}
+	return result, nil
+}
+
+// purgeTrash permanently deletes chunks whose trash retention expired before now
+func (ms *MemoryServer) purgeTrash(ctx context.Context, now time.Time) (int, error) {
+	store := ms.container.GetVectorStore()
+	chunks, err := store.GetAllChunks(storage.WithDeleted(ctx))
+	if err != nil {
+		return 0, fmt.Errorf("failed to scan chunks for trash purge: %w", err)
+	}
+
+	cutoff := now.Add(-ms.trashRetention())
+	expired := make([]string, 0)
+	for i := range chunks {
+		if chunks[i].IsDeleted() && !chunks[i].Metadata.DeletedAt.After(cutoff) {
+			expired = append(expired, chunks[i].ID)
+		}
+	}
+
+	if len(expired) == 0 {
+		return 0, nil
+	}
+
+	result, err := store.BatchDelete(ctx, expired)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge trash: %w", err)
+	}
+	return result.Success, nil
+}
+
+// runTrashPurger hard-deletes expired trash on a fixed interval until ctx is cancelled
+func (ms *MemoryServer) runTrashPurger(ctx context.Context) {
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			purged, err := ms.purgeTrash(ctx, now)
+			if err != nil {
+				logging.Error("Trash purge failed", "error", err)
+				continue
+			}
+			if purged > 0 {
+				logging.Info("Purged expired trash", "deleted_chunks", purged)
+			}
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoftDeleteAndRestore(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	kept := newTaskChunk(t, "github.com/acme/api", types.TaskStatusTodo)
+	deleted := newTaskChunk(t, "github.com/acme/api", types.TaskStatusTodo)
+	for _, c := range []*types.ConversationChunk{kept, deleted} {
+		require.NoError(t, store.Store(ctx, c))
+	}
+
+	ms := newCompositeTestServer(t, store)
+	result, err := ms.handleMemoryDelete(ctx, map[string]interface{}{
+		"operation": "bulk_delete",
+		"options": map[string]interface{}{
+			"repository": "github.com/acme/api",
+			"ids":        []interface{}{deleted.ID},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.(map[string]interface{})["deleted_count"])
+	assert.Equal(t, false, result.(map[string]interface{})["permanent"])
+
+	trashed, err := store.GetByID(ctx, deleted.ID)
+	require.NoError(t, err, "soft-deleted chunks stay in the store")
+	assert.True(t, trashed.IsDeleted())
+
+	query := types.NewMemoryQuery("TASK")
+	query.MinRelevanceScore = 0
+	results, err := store.Search(ctx, query, kept.Embeddings)
+	require.NoError(t, err)
+	for _, r := range results.Results {
+		assert.NotEqual(t, deleted.ID, r.Chunk.ID, "trashed chunks must be excluded from search")
+	}
+
+	recent, err := ms.handleRecentResource(ctx, strings.Split("memory://recent/github.com/acme/api", "/"))
+	require.NoError(t, err)
+	require.Len(t, recent, 1)
+	assert.Contains(t, recent[0].Text, kept.ID)
+	assert.NotContains(t, recent[0].Text, deleted.ID, "trashed chunks must be excluded from the recent resource")
+
+	listed, err := ms.handleTrashList(ctx, map[string]interface{}{"repository": "github.com/acme/api"})
+	require.NoError(t, err)
+	items := listed.(map[string]interface{})["items"].([]map[string]interface{})
+	require.Len(t, items, 1)
+	assert.Equal(t, deleted.ID, items[0]["chunk_id"])
+
+	restoredResult, err := ms.handleRestore(ctx, map[string]interface{}{
+		"repository": "github.com/other/repo",
+		"ids":        []interface{}{deleted.ID},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, restoredResult.(map[string]interface{})["restored_count"], "restore is repository scoped")
+
+	restoredResult, err = ms.handleRestore(ctx, map[string]interface{}{
+		"repository": "github.com/acme/api",
+		"ids":        []interface{}{deleted.ID},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, restoredResult.(map[string]interface{})["restored_count"])
+
+	restored, err := store.GetByID(ctx, deleted.ID)
+	require.NoError(t, err)
+	assert.False(t, restored.IsDeleted())
+}
+
+func TestPermanentDeleteSkipsTrash(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	chunk := newTaskChunk(t, "github.com/acme/api", types.TaskStatusTodo)
+	require.NoError(t, store.Store(ctx, chunk))
+
+	ms := newCompositeTestServer(t, store)
+	_, err := ms.handleMemoryDelete(ctx, map[string]interface{}{
+		"operation": "bulk_delete",
+		"options": map[string]interface{}{
+			"repository": "github.com/acme/api",
+			"ids":        []interface{}{chunk.ID},
+			"permanent":  true,
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = store.GetByID(ctx, chunk.ID)
+	assert.Error(t, err)
+}
+
+func TestPurgeTrash(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	now := time.Now()
+
+	expired := newTaskChunk(t, "github.com/acme/api", types.TaskStatusTodo)
+	expiredAt := now.AddDate(0, 0, -(defaultTrashRetentionDays + 1))
+	expired.Metadata.DeletedAt = &expiredAt
+	recent := newTaskChunk(t, "github.com/acme/api", types.TaskStatusTodo)
+	recentAt := now.Add(-time.Hour)
+	recent.Metadata.DeletedAt = &recentAt
+	live := newTaskChunk(t, "github.com/acme/api", types.TaskStatusTodo)
+	for _, c := range []*types.ConversationChunk{expired, recent, live} {
+		require.NoError(t, store.Store(ctx, c))
+	}
+
+	ms := newCompositeTestServer(t, store)
+	purged, err := ms.purgeTrash(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	_, err = store.GetByID(ctx, expired.ID)
+	assert.Error(t, err)
+	for _, id := range []string{recent.ID, live.ID} {
+		_, err = store.GetByID(ctx, id)
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,239 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	mcp "github.com/fredcamaral/gomcp-sdk"
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+// RegisterTypedTool registers a tool whose arguments and result are Go types.
+// The input schema is derived from TIn's struct tags (see SchemaFor), the
+// arguments are decoded into TIn before the handler runs, and the handler's
+// TOut is returned to the client as JSON text.
+//
+//	type searchArgs struct {
+//		Query string `json:"query" description:"Text to search for"`
+//		Limit int    `json:"limit,omitempty" description:"Maximum results" default:"10"`
+//	}
+//	RegisterTypedTool(ms, "search", "Search memories", func(ctx context.Context, in searchArgs) (searchResult, error) { ... })
+func RegisterTypedTool[TIn, TOut any](ms *MemoryServer, name, description string, handler func(ctx context.Context, in TIn) (TOut, error)) {
+	ms.addTool(mcp.NewTool(name, description, SchemaFor[TIn]()), typedToolHandler(name, handler))
+}
+
+// typedToolHandler adapts a typed handler to the map-based tool handler
+func typedToolHandler[TIn, TOut any](name string, handler func(ctx context.Context, in TIn) (TOut, error)) protocol.ToolHandler {
+	required := requiredFields(reflect.TypeOf((*TIn)(nil)).Elem())
+
+	return mcp.ToolHandlerFunc(func(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+		for _, field := range required {
+			if _, ok := params[field]; !ok {
+				return nil, fmt.Errorf("%s: missing required argument %q", name, field)
+			}
+		}
+
+		var in TIn
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to encode arguments: %w", name, err)
+		}
+		if err := json.Unmarshal(data, &in); err != nil {
+			return nil, fmt.Errorf("%s: invalid arguments: %w", name, err)
+		}
+
+		out, err := handler(ctx, in)
+		if err != nil {
+			return nil, err
+		}
+		if rich, ok := any(out).(*ToolResult); ok {
+			return rich, nil
+		}
+		return typedToolResult(out)
+	})
+}
+
+// typedToolResult converts a handler result into a tool call result
+func typedToolResult(out interface{}) (*protocol.ToolCallResult, error) {
+	switch v := out.(type) {
+	case *protocol.ToolCallResult:
+		return v, nil
+	case string:
+		return protocol.NewToolCallResult(protocol.NewContent(v)), nil
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode tool result: %w", err)
+	}
+	return protocol.NewToolCallResult(protocol.NewContent(string(data))), nil
+}
+
+// SchemaFor derives a JSON schema from T, which must be a struct. Fields are
+// named by their json tag; a field is required unless it is a pointer or
+// tagged omitempty. Optional tags refine a property:
+//
+//	description:"..."  property description
+//	enum:"a,b,c"       allowed values
+//	default:"..."      default value, parsed as the field's type
+func SchemaFor[T any]() map[string]interface{} {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("typed tool arguments must be a struct, got %s", t))
+	}
+	return typeSchema(t, map[reflect.Type]bool{})
+}
+
+// timeType is encoded as an RFC 3339 string
+var timeType = reflect.TypeOf(time.Time{})
+
+// typeSchema returns the schema for t; seen breaks recursive types
+func typeSchema(t reflect.Type, seen map[reflect.Type]bool) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), seen)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem(), seen)}
+	case reflect.Struct:
+		if seen[t] {
+			return map[string]interface{}{"type": "object"}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+		return structSchema(t, seen)
+	default:
+		// interface{} and anything else accept any JSON value
+		return map[string]interface{}{}
+	}
+}
+
+// structSchema builds an object schema from a struct's exported fields
+func structSchema(t reflect.Type, seen map[reflect.Type]bool) map[string]interface{} {
+	properties := make(map[string]interface{})
+	for _, f := range jsonFields(t) {
+		property := typeSchema(f.field.Type, seen)
+		if description := f.field.Tag.Get("description"); description != "" {
+			property["description"] = description
+		}
+		if enum := f.field.Tag.Get("enum"); enum != "" {
+			property["enum"] = strings.Split(enum, ",")
+		}
+		if def, ok := f.field.Tag.Lookup("default"); ok {
+			property["default"] = parseSchemaDefault(f.field.Type, def)
+		}
+		properties[f.name] = property
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if required := requiredFields(t); len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// requiredFields lists the JSON names of required fields of a struct type
+func requiredFields(t reflect.Type) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	required := make([]string, 0)
+	for _, f := range jsonFields(t) {
+		if !f.omitEmpty && f.field.Type.Kind() != reflect.Ptr {
+			required = append(required, f.name)
+		}
+	}
+	return required
+}
+
+// jsonField is a struct field as encoding/json sees it
+type jsonField struct {
+	name      string
+	omitEmpty bool
+	field     reflect.StructField
+}
+
+// jsonFields lists the fields encoding/json encodes for a struct type,
+// promoting the fields of untagged embedded structs
+func jsonFields(t reflect.Type) []jsonField {
+	fields := make([]jsonField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, jsonFields(embedded)...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, jsonField{
+			name:      name,
+			omitEmpty: strings.Contains(","+options+",", ",omitempty,"),
+			field:     field,
+		})
+	}
+	return fields
+}
+
+// parseSchemaDefault converts a default tag to the field's JSON type
+func parseSchemaDefault(t reflect.Type, value string) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return value
+}
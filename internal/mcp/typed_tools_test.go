@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	mcp "github.com/fredcamaral/gomcp-sdk"
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type typedPaging struct {
+	Limit int `json:"limit,omitempty" description:"Maximum results" default:"10"`
+}
+
+type typedSearchArgs struct {
+	typedPaging
+	Query      string            `json:"query" description:"Text to search for"`
+	Mode       string            `json:"mode,omitempty" enum:"fast,thorough"`
+	Tags       []string          `json:"tags,omitempty"`
+	Since      *time.Time        `json:"since"`
+	Exact      bool              `json:"exact,omitempty" default:"true"`
+	Filters    map[string]string `json:"filters,omitempty"`
+	Threshold  float64           `json:"threshold,omitempty"`
+	Deprecated string            `json:"-"`
+}
+
+type typedSearchResult struct {
+	Query string `json:"query"`
+	Limit int    `json:"limit"`
+	Tags  int    `json:"tags"`
+}
+
+func TestSchemaFor(t *testing.T) {
+	schema := SchemaFor[typedSearchArgs]()
+
+	assert.Equal(t, "object", schema["type"])
+	assert.Equal(t, []string{"query"}, schema["required"])
+
+	properties := schema["properties"].(map[string]interface{})
+	assert.Len(t, properties, 8)
+	assert.Equal(t, map[string]interface{}{"type": "integer", "description": "Maximum results", "default": int64(10)}, properties["limit"])
+	assert.Equal(t, map[string]interface{}{"type": "string", "description": "Text to search for"}, properties["query"])
+	assert.Equal(t, []string{"fast", "thorough"}, properties["mode"].(map[string]interface{})["enum"])
+	assert.Equal(t, map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}}, properties["tags"])
+	assert.Equal(t, "date-time", properties["since"].(map[string]interface{})["format"])
+	assert.Equal(t, true, properties["exact"].(map[string]interface{})["default"])
+	assert.Equal(t, "object", properties["filters"].(map[string]interface{})["type"])
+	assert.Equal(t, "number", properties["threshold"].(map[string]interface{})["type"])
+	assert.NotContains(t, properties, "Deprecated")
+
+	assert.Panics(t, func() { SchemaFor[string]() })
+}
+
+func TestRegisterTypedTool(t *testing.T) {
+	ms := &MemoryServer{mcpServer: mcp.NewServer("test", "1.0.0")}
+	RegisterTypedTool(ms, "typed_search", "Search", func(_ context.Context, in typedSearchArgs) (typedSearchResult, error) {
+		if in.Query == "fail" {
+			return typedSearchResult{}, errors.New("search failed")
+		}
+		return typedSearchResult{Query: in.Query, Limit: in.Limit, Tags: len(in.Tags)}, nil
+	})
+
+	resp := callTool(t, ms.mcpServer, "typed_search", map[string]interface{}{"query": "retry", "limit": 5, "tags": []string{"go", "http"}})
+	require.Nil(t, resp.Error)
+	result := resp.Result.(*protocol.ToolCallResult)
+	require.False(t, result.IsError)
+	var out typedSearchResult
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &out))
+	assert.Equal(t, typedSearchResult{Query: "retry", Limit: 5, Tags: 2}, out)
+
+	resp = callTool(t, ms.mcpServer, "typed_search", map[string]interface{}{"limit": 5})
+	result = resp.Result.(*protocol.ToolCallResult)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, `missing required argument "query"`)
+
+	resp = callTool(t, ms.mcpServer, "typed_search", map[string]interface{}{"query": "retry", "limit": "many"})
+	result = resp.Result.(*protocol.ToolCallResult)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "invalid arguments")
+
+	resp = callTool(t, ms.mcpServer, "typed_search", map[string]interface{}{"query": "fail"})
+	result = resp.Result.(*protocol.ToolCallResult)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "search failed")
+}
@@ -0,0 +1,172 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"lerian-mcp-memory/internal/digest"
+	"lerian-mcp-memory/internal/embeddings"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/tenancy"
+	"lerian-mcp-memory/internal/usage"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+// errUsageMeteringDisabled is returned by usage report operations when metering is off
+var errUsageMeteringDisabled = errors.New("usage reports require usage metering (set MCP_MEMORY_USAGE_METERING=true)")
+
+// UsageMiddleware counts each tool call for the tenant making it, with the
+// session and repository it names. Register it after TenancyMiddleware and
+// the rate limiter, so calls are counted for their tenant once served.
+func UsageMiddleware(meter *usage.Meter) Middleware {
+	return ForMethods(func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+			sessionID, repository := usageArguments(req)
+			meter.RecordCall(ctx, sessionID, repository)
+			return next(ctx, req)
+		}
+	}, "tools/call")
+}
+
+// usageArguments returns the session_id and repository arguments of a tool
+// call, given directly or in its options
+func usageArguments(req *protocol.JSONRPCRequest) (sessionID, repository string) {
+	arguments, _ := requestParams(req)["arguments"].(map[string]interface{})
+	options, _ := arguments["options"].(map[string]interface{})
+	for _, values := range []map[string]interface{}{arguments, options} {
+		if sessionID == "" {
+			sessionID, _ = values["session_id"].(string)
+		}
+		if repository == "" {
+			repository, _ = values["repository"].(string)
+		}
+	}
+	return sessionID, repository
+}
+
+// initUsageReports creates the usage reporter when metering is enabled and
+// lets the digest scheduler deliver its reports
+func (ms *MemoryServer) initUsageReports() {
+	meter := ms.container.GetUsageMeter()
+	if meter == nil {
+		return
+	}
+	dimension := 0
+	if service := ms.container.GetEmbeddingService(); embeddings.IsEnabled(service) {
+		dimension = service.GetDimension()
+	}
+	ms.usageReporter = usage.NewReporter(meter, ms.container.GetVectorStore(), dimension)
+	if ms.digestScheduler != nil {
+		ms.digestScheduler.SetUsageReporter(ms.usageReporter)
+	}
+}
+
+// usageReportTenant returns the tenant whose usage a caller asks for, which
+// must be its own unless it is an operator: a caller not held to a tenant,
+// or one owning every project. An empty result asks for every tenant.
+func usageReportTenant(ctx context.Context, options map[string]interface{}) (string, error) {
+	requested, _ := options["tenant"].(string)
+	caller := tenancy.FromContext(ctx)
+	if caller == nil || caller.AllowsAll() {
+		return requested, nil
+	}
+	if requested != "" && requested != caller.ID {
+		return "", fmt.Errorf("%w: usage of tenant %q", tenancy.ErrCrossTenant, requested)
+	}
+	return caller.ID, nil
+}
+
+// handleUsageReport reports what a tenant, or every tenant, used in a month
+func (ms *MemoryServer) handleUsageReport(ctx context.Context, options map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: memory_system usage_report called", "options", options)
+	if ms.usageReporter == nil {
+		return nil, errUsageMeteringDisabled
+	}
+
+	tenant, err := usageReportTenant(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	month, _ := options["month"].(string)
+	if month == "" {
+		month = usage.MonthOf(time.Now())
+	}
+	format, _ := options["format"].(string)
+	if format == "" {
+		format = usage.FormatJSON
+	}
+
+	var reports []usage.Report
+	if tenant == "" {
+		reports, err = ms.usageReporter.GenerateAll(ctx, month)
+	} else {
+		var report *usage.Report
+		if report, err = ms.usageReporter.Generate(ctx, tenant, month); err == nil {
+			reports = []usage.Report{*report}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate usage report: %w", err)
+	}
+
+	response := map[string]interface{}{
+		"month":   month,
+		"format":  format,
+		"tenants": len(reports),
+	}
+	if format == usage.FormatJSON {
+		response["reports"] = reports
+		return response, nil
+	}
+	rendered, err := usage.Render(reports, format)
+	if err != nil {
+		return nil, err
+	}
+	response["rendered"] = rendered
+	return response, nil
+}
+
+// handleScheduleUsageReport adds or replaces the monthly usage report delivered for a tenant, or for every tenant
+func (ms *MemoryServer) handleScheduleUsageReport(ctx context.Context, options map[string]interface{}) (interface{}, error) {
+	logging.Info("MCP TOOL: memory_system schedule_usage_report called", "options", options)
+	if ms.usageReporter == nil || ms.digestScheduler == nil {
+		return nil, errUsageMeteringDisabled
+	}
+
+	tenant, err := usageReportTenant(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Round-trip the options through JSON to reuse the schedule's field names and types
+	options["tenant"] = tenant
+	raw, err := json.Marshal(options)
+	if err != nil {
+		return nil, fmt.Errorf("invalid usage report schedule: %w", err)
+	}
+	var schedule digest.UsageSchedule
+	if err := json.Unmarshal(raw, &schedule); err != nil {
+		return nil, fmt.Errorf("invalid usage report schedule: %w", err)
+	}
+
+	now := time.Now()
+	if err := ms.digestScheduler.AddUsageSchedule(schedule, now); err != nil {
+		return nil, err
+	}
+
+	enabled := ms.container.Config != nil && ms.container.Config.Digest.Enabled
+	if tenant == "" {
+		tenant = "all tenants"
+	}
+	return map[string]interface{}{
+		"tenant":            tenant,
+		"next_run":          schedule.NextRun(now).Format(time.RFC3339),
+		"targets":           len(schedule.Targets),
+		"scheduler_enabled": enabled,
+		"note":              "The schedule lasts until the server restarts; add it to MCP_MEMORY_DIGEST_SCHEDULES_FILE to keep it",
+	}, nil
+}
@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/auth"
+	"lerian-mcp-memory/internal/digest"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/tenancy"
+	"lerian-mcp-memory/internal/usage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageMiddleware(t *testing.T) {
+	meter := usage.NewMeter("")
+	ms := newMiddlewareTestServer()
+	ms.Use(TenancyMiddleware(), UsageMiddleware(meter))
+
+	key := &auth.Key{ID: "k1", Projects: []string{"acme/api"}}
+	ctx := auth.WithKey(context.Background(), key)
+	ms.HandleRequest(ctx, toolCallRequest("echo", map[string]interface{}{"session_id": "s1"}))
+	ms.HandleRequest(ctx, toolCallRequest("echo", map[string]interface{}{"options": map[string]interface{}{"session_id": "s2", "repository": "acme/web"}}))
+	ms.HandleRequest(context.Background(), toolCallRequest("echo", nil))
+
+	month := usage.MonthOf(time.Now())
+	keyed := meter.Usage("api_key:k1", month)
+	assert.EqualValues(t, 2, keyed.APICalls)
+	assert.Equal(t, []string{"s1", "s2"}, keyed.Sessions)
+	assert.Equal(t, []string{"acme/api", "acme/web"}, keyed.Projects)
+	assert.EqualValues(t, 1, meter.Usage(usage.LocalTenant, month).APICalls)
+}
+
+func TestUsageReportOperations(t *testing.T) {
+	ms := newCompositeTestServer(t, storage.NewKeywordStore(""))
+	_, err := ms.handleUsageReport(context.Background(), map[string]interface{}{})
+	assert.ErrorIs(t, err, errUsageMeteringDisabled)
+
+	meter := usage.NewMeter("")
+	ms.container.UsageMeter = meter
+	ms.digestScheduler = digest.NewScheduler(digest.NewGenerator(ms.container.GetVectorStore()), nil)
+	ms.initUsageReports()
+	require.NotNil(t, ms.usageReporter)
+
+	acme := tenancy.WithTenant(context.Background(), &tenancy.Tenant{ID: "acme", Projects: []string{"github.com/acme/api"}})
+	globex := tenancy.WithTenant(context.Background(), &tenancy.Tenant{ID: "globex", Projects: []string{"github.com/globex/api"}})
+	meter.RecordCall(acme, "s1", "")
+	meter.RecordCall(globex, "s2", "")
+
+	// Tenants see their own usage by default and nobody else's
+	result, err := ms.handleUsageReport(acme, map[string]interface{}{})
+	require.NoError(t, err)
+	reports := result.(map[string]interface{})["reports"].([]usage.Report)
+	require.Len(t, reports, 1)
+	assert.Equal(t, "acme", reports[0].Tenant)
+
+	_, err = ms.handleUsageReport(acme, map[string]interface{}{"tenant": "globex"})
+	assert.ErrorIs(t, err, tenancy.ErrCrossTenant)
+
+	// Operators report every tenant
+	result, err = ms.handleUsageReport(context.Background(), map[string]interface{}{"format": "csv"})
+	require.NoError(t, err)
+	response := result.(map[string]interface{})
+	assert.Equal(t, 2, response["tenants"])
+	assert.Contains(t, response["rendered"], ",globex,")
+
+	result, err = ms.handleScheduleUsageReport(acme, map[string]interface{}{
+		"day":     float64(2),
+		"targets": []interface{}{map[string]interface{}{"type": "webhook", "url": "http://example.com"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "acme", result.(map[string]interface{})["tenant"])
+	schedules := ms.digestScheduler.UsageSchedules()
+	require.Len(t, schedules, 1)
+	assert.Equal(t, "acme", schedules[0].Tenant)
+}
@@ -0,0 +1,256 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+const (
+	// workingSetURIPrefix and workingSetURISuffix frame the session ID in the
+	// memory://session/{id}/working-set resource URI
+	workingSetURIPrefix = "memory://session/"
+	workingSetURISuffix = "/working-set"
+
+	// maxWorkingSetChunks caps the chunks remembered per session; the least
+	// recently touched chunk is forgotten first
+	maxWorkingSetChunks = 100
+
+	// maxWorkingSetSessions caps the sessions tracked at once; the least
+	// recently active session is forgotten first
+	maxWorkingSetSessions = 1000
+)
+
+// How a chunk entered a session's working set
+const (
+	workingSetStored    = "stored"
+	workingSetRetrieved = "retrieved"
+	workingSetLinked    = "linked"
+)
+
+// WorkingSetURI returns the resource URI of a session's working set
+func WorkingSetURI(sessionID string) string {
+	return workingSetURIPrefix + sessionID + workingSetURISuffix
+}
+
+// workingSetEntry is one chunk touched during a session
+type workingSetEntry struct {
+	ChunkID      string    `json:"chunk_id"`
+	Repository   string    `json:"repository,omitempty"`
+	Actions      []string  `json:"actions"`
+	Touches      int       `json:"touches"`
+	FirstTouched time.Time `json:"first_touched"`
+	LastTouched  time.Time `json:"last_touched"`
+}
+
+// sessionWorkingSet holds the chunks touched in one session
+type sessionWorkingSet struct {
+	entries    map[string]*workingSetEntry
+	lastActive time.Time
+}
+
+// workingSetTracker remembers, per session, which chunks were stored,
+// retrieved or linked. It lives in memory only: a working set is a cheap
+// way back into recent context, not a durable record.
+type workingSetTracker struct {
+	mutex    sync.Mutex
+	sessions map[string]*sessionWorkingSet
+}
+
+// newWorkingSetTracker creates an empty tracker
+func newWorkingSetTracker() *workingSetTracker {
+	return &workingSetTracker{sessions: make(map[string]*sessionWorkingSet)}
+}
+
+// touch records that chunkIDs were used in a session through action
+func (w *workingSetTracker) touch(sessionID, repository, action string, chunkIDs ...string) {
+	if w == nil || sessionID == "" || len(chunkIDs) == 0 {
+		return
+	}
+
+	now := time.Now()
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	session, ok := w.sessions[sessionID]
+	if !ok {
+		if len(w.sessions) >= maxWorkingSetSessions {
+			w.evictIdleSession()
+		}
+		session = &sessionWorkingSet{entries: make(map[string]*workingSetEntry)}
+		w.sessions[sessionID] = session
+	}
+	session.lastActive = now
+
+	for _, id := range chunkIDs {
+		if id == "" {
+			continue
+		}
+		entry, ok := session.entries[id]
+		if !ok {
+			if len(session.entries) >= maxWorkingSetChunks {
+				session.evictStalest()
+			}
+			entry = &workingSetEntry{ChunkID: id, Repository: repository, FirstTouched: now}
+			session.entries[id] = entry
+		}
+		if !containsString(entry.Actions, action) {
+			entry.Actions = append(entry.Actions, action)
+		}
+		entry.Touches++
+		entry.LastTouched = now
+	}
+}
+
+// snapshot returns a session's entries, most recently touched first
+func (w *workingSetTracker) snapshot(sessionID string) []workingSetEntry {
+	entries := make([]workingSetEntry, 0)
+	if w == nil {
+		return entries
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	session, ok := w.sessions[sessionID]
+	if !ok {
+		return entries
+	}
+	for _, entry := range session.entries {
+		copied := *entry
+		copied.Actions = append([]string(nil), entry.Actions...)
+		entries = append(entries, copied)
+	}
+	sort.Slice(entries, func(a, b int) bool {
+		return entries[a].LastTouched.After(entries[b].LastTouched)
+	})
+	return entries
+}
+
+// evictIdleSession forgets the least recently active session. Callers hold the mutex.
+func (w *workingSetTracker) evictIdleSession() {
+	var oldestID string
+	var oldest time.Time
+	for id, session := range w.sessions {
+		if oldestID == "" || session.lastActive.Before(oldest) {
+			oldestID, oldest = id, session.lastActive
+		}
+	}
+	delete(w.sessions, oldestID)
+}
+
+// evictStalest forgets the least recently touched chunk of the session
+func (s *sessionWorkingSet) evictStalest() {
+	var oldestID string
+	var oldest time.Time
+	for id, entry := range s.entries {
+		if oldestID == "" || entry.LastTouched.Before(oldest) {
+			oldestID, oldest = id, entry.LastTouched
+		}
+	}
+	delete(s.entries, oldestID)
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// recordWorkingSet adds chunks to the session's working set and tells
+// subscribed clients the working-set resource changed
+func (ms *MemoryServer) recordWorkingSet(sessionID, repository, action string, chunkIDs ...string) {
+	if ms.workingSet == nil || sessionID == "" || len(chunkIDs) == 0 {
+		return
+	}
+
+	sessionID = ms.validateAndNormalizeSessionID(sessionID)
+	ms.workingSet.touch(sessionID, repository, action, chunkIDs...)
+
+	ms.ResourceUpdated(WorkingSetURI(sessionID))
+}
+
+// recordRetrieved adds retrieved chunks to the working set of the session
+// named in params, if any. Reads without a session_id are not tracked.
+func (ms *MemoryServer) recordRetrieved(params map[string]interface{}, repository string, chunkIDs ...string) {
+	if sessionID, ok := params["session_id"].(string); ok {
+		ms.recordWorkingSet(sessionID, repository, workingSetRetrieved, chunkIDs...)
+	}
+}
+
+// handleWorkingSetResource handles memory://session/{id}/working-set
+// resource requests. Each entry carries the chunk itself, so one read is
+// enough to re-establish the session's context.
+func (ms *MemoryServer) handleWorkingSetResource(ctx context.Context, uri string) ([]protocol.Content, error) {
+	sessionID := strings.TrimSuffix(strings.TrimPrefix(uri, workingSetURIPrefix), workingSetURISuffix)
+	if sessionID == "" || !strings.HasSuffix(uri, workingSetURISuffix) || strings.Contains(sessionID, "/") {
+		return nil, errors.New("session ID required for working set resource: memory://session/{id}/working-set")
+	}
+	sessionID = ms.validateAndNormalizeSessionID(sessionID)
+
+	entries := ms.workingSet.snapshot(sessionID)
+	ids := make([]string, 0, len(entries))
+	for i := range entries {
+		ids = append(ids, entries[i].ChunkID)
+	}
+
+	chunks := make(map[string]map[string]interface{}, len(ids))
+	if len(ids) > 0 {
+		found, err := ms.container.GetVectorStore().GetByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for i := range found {
+			if found[i].IsDeleted() {
+				continue
+			}
+			chunks[found[i].ID] = map[string]interface{}{
+				"type":      string(found[i].Type),
+				"summary":   found[i].Summary,
+				"content":   found[i].Content,
+				"timestamp": found[i].Timestamp.Format(time.RFC3339),
+			}
+		}
+	}
+
+	items := make([]map[string]interface{}, 0, len(entries))
+	missing := make([]string, 0)
+	for i := range entries {
+		chunk, ok := chunks[entries[i].ChunkID]
+		if !ok {
+			// Deleted or trashed since it was touched
+			missing = append(missing, entries[i].ChunkID)
+			continue
+		}
+		items = append(items, map[string]interface{}{
+			"chunk_id":      entries[i].ChunkID,
+			"repository":    entries[i].Repository,
+			"actions":       entries[i].Actions,
+			"touches":       entries[i].Touches,
+			"first_touched": entries[i].FirstTouched.Format(time.RFC3339),
+			"last_touched":  entries[i].LastTouched.Format(time.RFC3339),
+			"chunk":         chunk,
+		})
+	}
+
+	result := map[string]interface{}{
+		"session_id":   sessionID,
+		"uri":          WorkingSetURI(sessionID),
+		"chunks":       items,
+		"missing":      missing,
+		"total":        len(items),
+		"generated_at": time.Now().Format(time.RFC3339),
+	}
+	resultJSON, _ := json.Marshal(result)
+	return []protocol.Content{protocol.NewContent(string(resultJSON))}, nil
+}
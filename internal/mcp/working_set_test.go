@@ -0,0 +1,110 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkingSetTracker(t *testing.T) {
+	tracker := newWorkingSetTracker()
+	tracker.touch("s1", "github.com/acme/api", workingSetStored, "a")
+	tracker.touch("s1", "github.com/acme/api", workingSetRetrieved, "b", "a")
+	tracker.touch("s2", "github.com/acme/api", workingSetRetrieved, "c")
+
+	entries := tracker.snapshot("s1")
+	require.Len(t, entries, 2)
+	ids := map[string]workingSetEntry{}
+	for _, entry := range entries {
+		ids[entry.ChunkID] = entry
+	}
+	assert.Equal(t, []string{workingSetStored, workingSetRetrieved}, ids["a"].Actions)
+	assert.Equal(t, 2, ids["a"].Touches)
+	assert.Equal(t, []string{workingSetRetrieved}, ids["b"].Actions)
+	assert.Empty(t, tracker.snapshot("unknown"))
+
+	for i := 0; i < maxWorkingSetChunks+10; i++ {
+		tracker.touch("s3", "", workingSetRetrieved, fmt.Sprintf("chunk-%d", i))
+	}
+	assert.Len(t, tracker.snapshot("s3"), maxWorkingSetChunks)
+
+	var nilTracker *workingSetTracker
+	nilTracker.touch("s1", "", workingSetStored, "a")
+	assert.Empty(t, nilTracker.snapshot("s1"))
+}
+
+func TestWorkingSetResource(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+	first := newReportChunk(t, "session-1", "Retry with exponential backoff", types.ChunkTypeSolution, types.ChunkMetadata{})
+	second := newReportChunk(t, "session-1", "Cache invalidation on deploy", types.ChunkTypeSolution, types.ChunkMetadata{})
+	unrelated := newReportChunk(t, "session-1", "Unrelated backoff note", types.ChunkTypeSolution, types.ChunkMetadata{Repository: "github.com/acme/web"})
+	for _, chunk := range []*types.ConversationChunk{first, second, unrelated} {
+		require.NoError(t, store.Store(ctx, chunk))
+	}
+
+	ms := newCompositeTestServer(t, store)
+	ms.workingSet = newWorkingSetTracker()
+
+	_, err := ms.handleSecureSearch(ctx, map[string]interface{}{"query": "backoff", "session_id": "work-1"}, "github.com/acme/api")
+	require.NoError(t, err)
+	_, err = ms.handleSecureSearch(ctx, map[string]interface{}{"query": "cache"}, "github.com/acme/api")
+	require.NoError(t, err)
+	_, err = ms.handleMemoryLink(ctx, map[string]interface{}{
+		"source_chunk_id": first.ID,
+		"target_chunk_id": second.ID,
+		"relation_type":   string(types.RelationRelatedTo),
+		"repository":      "github.com/acme/api",
+		"session_id":      "work-1",
+	})
+	require.NoError(t, err)
+
+	contents, err := ms.handleResourceRead(ctx, WorkingSetURI("work-1"))
+	require.NoError(t, err)
+	require.Len(t, contents, 1)
+
+	var workingSet struct {
+		SessionID string `json:"session_id"`
+		Total     int    `json:"total"`
+		Chunks    []struct {
+			ChunkID string   `json:"chunk_id"`
+			Actions []string `json:"actions"`
+			Chunk   struct {
+				Content string `json:"content"`
+			} `json:"chunk"`
+		} `json:"chunks"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(contents[0].Text), &workingSet))
+
+	assert.Equal(t, "work-1", workingSet.SessionID)
+	require.Equal(t, 2, workingSet.Total)
+	actions := map[string][]string{}
+	for _, entry := range workingSet.Chunks {
+		actions[entry.ChunkID] = entry.Actions
+		assert.NotEmpty(t, entry.Chunk.Content)
+	}
+	assert.Equal(t, []string{workingSetRetrieved, workingSetLinked}, actions[first.ID])
+	assert.Equal(t, []string{workingSetLinked}, actions[second.ID])
+
+	require.NoError(t, ms.trashChunk(ctx, second, time.Now()))
+	contents, err = ms.handleResourceRead(ctx, WorkingSetURI("work-1"))
+	require.NoError(t, err)
+	var trimmed struct {
+		Total   int      `json:"total"`
+		Missing []string `json:"missing"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(contents[0].Text), &trimmed))
+	assert.Equal(t, 1, trimmed.Total)
+	assert.Equal(t, []string{second.ID}, trimmed.Missing, "trashed chunks leave the working set")
+
+	_, err = ms.handleResourceRead(ctx, "memory://session//working-set")
+	assert.Error(t, err)
+}
@@ -0,0 +1,184 @@
+package monitoring
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Weights of the health components in the composite score. The verified
+// solution ratio only counts for repositories holding solutions; without
+// any, the remaining weights are scaled back up to a total of 1.
+const (
+	healthWeightActivity  = 0.25
+	healthWeightFreshness = 0.25
+	healthWeightConflicts = 0.15
+	healthWeightTagging   = 0.15
+	healthWeightVerified  = 0.20
+)
+
+// healthConflictDensity is the number of conflicts per memory at which the
+// conflict component of the score drops to zero
+const healthConflictDensity = 0.1
+
+// HealthComponents are the knowledge hygiene measures of a repository
+type HealthComponents struct {
+	// ActivityCoverage is the fraction of recent weeks with at least one new memory
+	ActivityCoverage float64 `json:"activity_coverage"`
+	// StaleRatio is the fraction of memories the freshness manager finds stale
+	StaleRatio float64 `json:"stale_ratio"`
+	// Conflicts is the number of conflicts found among the most recent memories
+	Conflicts int `json:"conflicts"`
+	// UntaggedRatio is the fraction of memories without tags
+	UntaggedRatio float64 `json:"untagged_ratio"`
+	// Solutions is the number of solutions, which VerifiedSolutionRatio is a fraction of
+	Solutions int `json:"solutions"`
+	// VerifiedSolutionRatio is the fraction of solutions verified to work
+	VerifiedSolutionRatio float64 `json:"verified_solution_ratio"`
+}
+
+// RepositoryHealth is the composite health score of a repository's memories
+type RepositoryHealth struct {
+	Repository string           `json:"repository"`
+	Memories   int              `json:"memories"`
+	Score      float64          `json:"score"`
+	Components HealthComponents `json:"components"`
+	ComputedAt time.Time        `json:"computed_at"`
+}
+
+// HealthScore combines the components into a score from 0 (neglected) to
+// 100 (healthy). Conflicts count relative to the repository's size, so a
+// handful in a large repository weighs less than in a small one.
+func HealthScore(memories int, c HealthComponents) float64 {
+	if memories == 0 {
+		return 0
+	}
+
+	conflictScore := math.Max(0, 1-float64(c.Conflicts)/(float64(memories)*healthConflictDensity))
+	score := healthWeightActivity*c.ActivityCoverage +
+		healthWeightFreshness*(1-c.StaleRatio) +
+		healthWeightConflicts*conflictScore +
+		healthWeightTagging*(1-c.UntaggedRatio)
+	weights := healthWeightActivity + healthWeightFreshness + healthWeightConflicts + healthWeightTagging
+	if c.Solutions > 0 {
+		score += healthWeightVerified * c.VerifiedSolutionRatio
+		weights += healthWeightVerified
+	}
+	return math.Round(score/weights*1000) / 10
+}
+
+// HealthScores holds the latest health score of each repository
+type HealthScores struct {
+	mutex      sync.RWMutex
+	scores     map[string]RepositoryHealth
+	computedAt time.Time
+}
+
+// NewHealthScores creates an empty health score registry
+func NewHealthScores() *HealthScores {
+	return &HealthScores{scores: make(map[string]RepositoryHealth)}
+}
+
+// Replace swaps in the scores of a full recomputation; repositories missing
+// from it are dropped
+func (h *HealthScores) Replace(scores []RepositoryHealth, computedAt time.Time) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.scores = make(map[string]RepositoryHealth, len(scores))
+	for _, score := range scores {
+		h.scores[score.Repository] = score
+	}
+	h.computedAt = computedAt
+}
+
+// Set records the score of one repository
+func (h *HealthScores) Set(score RepositoryHealth) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.scores[score.Repository] = score
+}
+
+// ComputedAt returns when the last full recomputation ran, or the zero time
+func (h *HealthScores) ComputedAt() time.Time {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return h.computedAt
+}
+
+// Scores returns the latest scores, lowest first
+func (h *HealthScores) Scores() []RepositoryHealth {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	scores := make([]RepositoryHealth, 0, len(h.scores))
+	for _, score := range h.scores {
+		scores = append(scores, score)
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score < scores[j].Score
+		}
+		return scores[i].Repository < scores[j].Repository
+	})
+	return scores
+}
+
+// WritePrometheus writes the scores as gauges in the Prometheus text exposition format
+func (h *HealthScores) WritePrometheus(w io.Writer) error {
+	return WriteHealthPrometheus(w, h.Scores())
+}
+
+// FilterHealth returns the scores of the repositories allows accepts, in order
+func FilterHealth(scores []RepositoryHealth, allows func(repository string) bool) []RepositoryHealth {
+	filtered := make([]RepositoryHealth, 0, len(scores))
+	for _, score := range scores {
+		if allows(score.Repository) {
+			filtered = append(filtered, score)
+		}
+	}
+	return filtered
+}
+
+// WriteHealthPrometheus writes scores as gauges in the Prometheus text exposition format
+func WriteHealthPrometheus(w io.Writer, scores []RepositoryHealth) error {
+	scores = slices.Clone(scores)
+	sort.Slice(scores, func(i, j int) bool { return scores[i].Repository < scores[j].Repository })
+
+	gauges := []struct {
+		name  string
+		help  string
+		value func(RepositoryHealth) float64
+	}{
+		{"mcp_memory_health_score", "Composite memory health score of a repository, from 0 to 100.",
+			func(s RepositoryHealth) float64 { return s.Score }},
+		{"mcp_memory_health_memories", "Memories of a repository analyzed by the health score.",
+			func(s RepositoryHealth) float64 { return float64(s.Memories) }},
+		{"mcp_memory_health_activity_coverage", "Fraction of recent weeks with new memories.",
+			func(s RepositoryHealth) float64 { return s.Components.ActivityCoverage }},
+		{"mcp_memory_health_stale_ratio", "Fraction of memories that are stale.",
+			func(s RepositoryHealth) float64 { return s.Components.StaleRatio }},
+		{"mcp_memory_health_conflicts", "Conflicts found among recent memories.",
+			func(s RepositoryHealth) float64 { return float64(s.Components.Conflicts) }},
+		{"mcp_memory_health_untagged_ratio", "Fraction of memories without tags.",
+			func(s RepositoryHealth) float64 { return s.Components.UntaggedRatio }},
+		{"mcp_memory_health_verified_solution_ratio", "Fraction of solutions verified to work.",
+			func(s RepositoryHealth) float64 { return s.Components.VerifiedSolutionRatio }},
+	}
+
+	var b strings.Builder
+	for _, gauge := range gauges {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", gauge.name, gauge.help, gauge.name)
+		for _, score := range scores {
+			fmt.Fprintf(&b, "%s{repository=%q} %g\n", gauge.name, score.Repository, gauge.value(score))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,173 @@
+package monitoring
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// toolLatencySamples is the number of recent latencies kept per tool for percentiles
+const toolLatencySamples = 1024
+
+// toolLatencyBuckets are the Prometheus histogram upper bounds in seconds
+var toolLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// ToolStats is a snapshot of the usage of one MCP tool
+type ToolStats struct {
+	Tool         string    `json:"tool"`
+	Calls        int64     `json:"calls"`
+	Errors       int64     `json:"errors"`
+	ErrorRate    float64   `json:"error_rate"`
+	MeanMs       float64   `json:"mean_ms"`
+	P50Ms        float64   `json:"p50_ms"`
+	P95Ms        float64   `json:"p95_ms"`
+	P99Ms        float64   `json:"p99_ms"`
+	MaxMs        float64   `json:"max_ms"`
+	LastCalledAt time.Time `json:"last_called_at"`
+	LastError    string    `json:"last_error,omitempty"`
+}
+
+// toolSeries accumulates counters, a latency histogram and recent samples for a tool
+type toolSeries struct {
+	calls      int64
+	errors     int64
+	sumSeconds float64
+	maxSeconds float64
+	buckets    []int64
+	samples    []float64
+	next       int
+	lastCalled time.Time
+	lastError  string
+}
+
+// ToolMetrics tracks invocation counts, errors and latency per MCP tool
+type ToolMetrics struct {
+	mutex     sync.RWMutex
+	tools     map[string]*toolSeries
+	startedAt time.Time
+}
+
+// NewToolMetrics creates an empty tool metrics collector
+func NewToolMetrics() *ToolMetrics {
+	return &ToolMetrics{
+		tools:     make(map[string]*toolSeries),
+		startedAt: time.Now(),
+	}
+}
+
+// Record registers one tool call with its duration and error, if any
+func (m *ToolMetrics) Record(tool string, duration time.Duration, err error) {
+	seconds := duration.Seconds()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	series, ok := m.tools[tool]
+	if !ok {
+		series = &toolSeries{buckets: make([]int64, len(toolLatencyBuckets))}
+		m.tools[tool] = series
+	}
+
+	series.calls++
+	series.sumSeconds += seconds
+	series.maxSeconds = max(series.maxSeconds, seconds)
+	series.lastCalled = time.Now()
+	if err != nil {
+		series.errors++
+		series.lastError = err.Error()
+	}
+	for i, bound := range toolLatencyBuckets {
+		if seconds <= bound {
+			series.buckets[i]++
+		}
+	}
+
+	if len(series.samples) < toolLatencySamples {
+		series.samples = append(series.samples, seconds)
+	} else {
+		series.samples[series.next] = seconds
+		series.next = (series.next + 1) % toolLatencySamples
+	}
+}
+
+// Stats returns a snapshot per tool, busiest first. Percentiles cover the
+// most recent calls only.
+func (m *ToolMetrics) Stats() []ToolStats {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	stats := make([]ToolStats, 0, len(m.tools))
+	for name, series := range m.tools {
+		sorted := append([]float64(nil), series.samples...)
+		sort.Float64s(sorted)
+
+		s := ToolStats{
+			Tool:         name,
+			Calls:        series.calls,
+			Errors:       series.errors,
+			MaxMs:        series.maxSeconds * 1000,
+			P50Ms:        percentile(sorted, 0.50) * 1000,
+			P95Ms:        percentile(sorted, 0.95) * 1000,
+			P99Ms:        percentile(sorted, 0.99) * 1000,
+			LastCalledAt: series.lastCalled,
+			LastError:    series.lastError,
+		}
+		if series.calls > 0 {
+			s.ErrorRate = float64(series.errors) / float64(series.calls)
+			s.MeanMs = series.sumSeconds / float64(series.calls) * 1000
+		}
+		stats = append(stats, s)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Calls != stats[j].Calls {
+			return stats[i].Calls > stats[j].Calls
+		}
+		return stats[i].Tool < stats[j].Tool
+	})
+	return stats
+}
+
+// Since returns when collection started
+func (m *ToolMetrics) Since() time.Time {
+	return m.startedAt
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition format
+func (m *ToolMetrics) WritePrometheus(w io.Writer) error {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	names := make([]string, 0, len(m.tools))
+	for name := range m.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("# HELP mcp_tool_calls_total Total MCP tool invocations.\n# TYPE mcp_tool_calls_total counter\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "mcp_tool_calls_total{tool=%q} %d\n", name, m.tools[name].calls)
+	}
+	b.WriteString("# HELP mcp_tool_errors_total Total MCP tool invocations that returned an error.\n# TYPE mcp_tool_errors_total counter\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "mcp_tool_errors_total{tool=%q} %d\n", name, m.tools[name].errors)
+	}
+	b.WriteString("# HELP mcp_tool_duration_seconds MCP tool invocation latency.\n# TYPE mcp_tool_duration_seconds histogram\n")
+	for _, name := range names {
+		series := m.tools[name]
+		for i, bound := range toolLatencyBuckets {
+			fmt.Fprintf(&b, "mcp_tool_duration_seconds_bucket{tool=%q,le=%q} %d\n", name, strconv.FormatFloat(bound, 'g', -1, 64), series.buckets[i])
+		}
+		fmt.Fprintf(&b, "mcp_tool_duration_seconds_bucket{tool=%q,le=\"+Inf\"} %d\n", name, series.calls)
+		fmt.Fprintf(&b, "mcp_tool_duration_seconds_sum{tool=%q} %g\n", name, series.sumSeconds)
+		fmt.Fprintf(&b, "mcp_tool_duration_seconds_count{tool=%q} %d\n", name, series.calls)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,110 @@
+package monitoring
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolMetricsStats(t *testing.T) {
+	m := NewToolMetrics()
+	for i := 1; i <= 10; i++ {
+		m.Record("memory_read", time.Duration(i)*time.Millisecond, nil)
+	}
+	m.Record("memory_create", 20*time.Millisecond, errors.New("store failed"))
+	m.Record("memory_create", 40*time.Millisecond, nil)
+
+	stats := m.Stats()
+	require.Len(t, stats, 2)
+
+	read := stats[0]
+	assert.Equal(t, "memory_read", read.Tool)
+	assert.Equal(t, int64(10), read.Calls)
+	assert.Zero(t, read.Errors)
+	assert.InDelta(t, 5.5, read.MeanMs, 0.01)
+	assert.InDelta(t, 5.5, read.P50Ms, 0.01)
+	assert.InDelta(t, 10, read.MaxMs, 0.01)
+	assert.Greater(t, read.P99Ms, read.P95Ms-0.01)
+
+	create := stats[1]
+	assert.Equal(t, int64(2), create.Calls)
+	assert.Equal(t, int64(1), create.Errors)
+	assert.InDelta(t, 0.5, create.ErrorRate, 0.001)
+	assert.Equal(t, "store failed", create.LastError)
+}
+
+func TestToolMetricsSampleWindow(t *testing.T) {
+	m := NewToolMetrics()
+	for i := 0; i < toolLatencySamples; i++ {
+		m.Record("memory_read", time.Second, nil)
+	}
+	for i := 0; i < toolLatencySamples; i++ {
+		m.Record("memory_read", time.Millisecond, nil)
+	}
+
+	stats := m.Stats()
+	require.Len(t, stats, 1)
+	assert.Equal(t, int64(2*toolLatencySamples), stats[0].Calls)
+	assert.InDelta(t, 1, stats[0].P99Ms, 0.01, "percentiles cover only recent calls")
+	assert.InDelta(t, 1000, stats[0].MaxMs, 0.01)
+}
+
+func TestToolMetricsWritePrometheus(t *testing.T) {
+	m := NewToolMetrics()
+	m.Record("memory_read", 30*time.Millisecond, nil)
+	m.Record("memory_read", 3*time.Second, errors.New("timeout"))
+
+	var buf bytes.Buffer
+	require.NoError(t, m.WritePrometheus(&buf))
+	out := buf.String()
+
+	assert.Contains(t, out, "# TYPE mcp_tool_calls_total counter")
+	assert.Contains(t, out, `mcp_tool_calls_total{tool="memory_read"} 2`)
+	assert.Contains(t, out, `mcp_tool_errors_total{tool="memory_read"} 1`)
+	assert.Contains(t, out, `mcp_tool_duration_seconds_bucket{tool="memory_read",le="0.05"} 1`)
+	assert.Contains(t, out, `mcp_tool_duration_seconds_bucket{tool="memory_read",le="5"} 2`)
+	assert.Contains(t, out, `mcp_tool_duration_seconds_bucket{tool="memory_read",le="+Inf"} 2`)
+	assert.Contains(t, out, `mcp_tool_duration_seconds_count{tool="memory_read"} 2`)
+}
+
+func TestHealthScore(t *testing.T) {
+	healthy := HealthComponents{ActivityCoverage: 1, Solutions: 4, VerifiedSolutionRatio: 1}
+	assert.InDelta(t, 100, HealthScore(10, healthy), 1e-9)
+	assert.Zero(t, HealthScore(0, healthy), "a repository without memories scores zero")
+
+	// Without solutions the verified ratio does not count
+	noSolutions := HealthComponents{ActivityCoverage: 0.5, StaleRatio: 0.5, UntaggedRatio: 0.5}
+	assert.InDelta(t, 59.4, HealthScore(10, noSolutions), 1e-9)
+
+	// One conflict per ten memories zeroes the conflict component
+	conflicted := healthy
+	conflicted.Conflicts = 1
+	assert.InDelta(t, 85, HealthScore(10, conflicted), 1e-9)
+	assert.Greater(t, HealthScore(100, conflicted), HealthScore(10, conflicted))
+}
+
+func TestHealthScoresWritePrometheus(t *testing.T) {
+	scores := NewHealthScores()
+	scores.Replace([]RepositoryHealth{
+		{Repository: "github.com/acme/web", Memories: 3, Score: 90},
+		{Repository: "github.com/acme/api", Memories: 12, Score: 41.5, Components: HealthComponents{Conflicts: 2, StaleRatio: 0.25}},
+	}, time.Now())
+	assert.Equal(t, "github.com/acme/api", scores.Scores()[0].Repository, "lowest score first")
+
+	var buf bytes.Buffer
+	require.NoError(t, scores.WritePrometheus(&buf))
+	out := buf.String()
+
+	assert.Contains(t, out, "# TYPE mcp_memory_health_score gauge")
+	assert.Contains(t, out, `mcp_memory_health_score{repository="github.com/acme/api"} 41.5`)
+	assert.Contains(t, out, `mcp_memory_health_memories{repository="github.com/acme/web"} 3`)
+	assert.Contains(t, out, `mcp_memory_health_conflicts{repository="github.com/acme/api"} 2`)
+	assert.Contains(t, out, `mcp_memory_health_stale_ratio{repository="github.com/acme/api"} 0.25`)
+
+	scores.Replace(nil, time.Now())
+	assert.Empty(t, scores.Scores(), "a recomputation drops repositories it no longer sees")
+}
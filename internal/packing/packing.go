@@ -0,0 +1,287 @@
+// Package packing selects and orders memories into a context block that fits
+// the token budget of a target model
+package packing
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"lerian-mcp-memory/pkg/types"
+)
+
+const (
+	// DefaultBudget is the token budget used when the caller does not set one
+	DefaultBudget = 4000
+
+	// MinBudget is the smallest budget worth packing
+	MinBudget = 100
+
+	// DefaultRecencyHalfLife is the age at which a memory's recency boost halves
+	DefaultRecencyHalfLife = 14 * 24 * time.Hour
+
+	// DefaultModel is assumed when no model is given
+	DefaultModel = "default"
+)
+
+// Mode is how a chunk was included in the packed context
+type Mode string
+
+const (
+	// ModeFull includes the chunk content verbatim
+	ModeFull Mode = "full"
+	// ModeSummary includes only the chunk summary because the content did not fit
+	ModeSummary Mode = "summary"
+)
+
+// ModelProfile describes the tokenization and context window of a model family
+type ModelProfile struct {
+	Name          string  `json:"name"`
+	ContextWindow int     `json:"context_window"`
+	CharsPerToken float64 `json:"chars_per_token"`
+}
+
+// modelProfiles are matched by prefix against the lowercased model name; the longest match wins
+var modelProfiles = []ModelProfile{
+	{Name: "claude", ContextWindow: 200000, CharsPerToken: 3.5},
+	{Name: "gpt-4o", ContextWindow: 128000, CharsPerToken: 4.0},
+	{Name: "gpt-4-turbo", ContextWindow: 128000, CharsPerToken: 4.0},
+	{Name: "gpt-4", ContextWindow: 8192, CharsPerToken: 4.0},
+	{Name: "gpt-3.5", ContextWindow: 16385, CharsPerToken: 4.0},
+	{Name: "o1", ContextWindow: 128000, CharsPerToken: 4.0},
+	{Name: "gemini", ContextWindow: 1000000, CharsPerToken: 4.0},
+	{Name: "llama", ContextWindow: 8192, CharsPerToken: 3.8},
+	{Name: "mistral", ContextWindow: 32000, CharsPerToken: 3.8},
+}
+
+// defaultProfile is used for unknown models
+var defaultProfile = ModelProfile{Name: DefaultModel, ContextWindow: 8192, CharsPerToken: 4.0}
+
+// ProfileFor returns the profile whose name is the longest prefix of model
+func ProfileFor(model string) ModelProfile {
+	model = strings.ToLower(strings.TrimSpace(model))
+	best, bestLen := defaultProfile, 0
+	for _, p := range modelProfiles {
+		if strings.HasPrefix(model, p.Name) && len(p.Name) > bestLen {
+			best, bestLen = p, len(p.Name)
+		}
+	}
+	return best
+}
+
+// EstimateTokens approximates the token count of text for a model profile.
+// It is a character-ratio heuristic and errs on the high side for short texts.
+func (p ModelProfile) EstimateTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	return int(math.Ceil(float64(utf8.RuneCountInString(text)) / p.CharsPerToken))
+}
+
+// Options controls packing
+type Options struct {
+	Model           string
+	Budget          int
+	RecencyHalfLife time.Duration
+	Now             time.Time
+	Title           string
+}
+
+// Item is a chunk included in the packed context
+type Item struct {
+	ID        string          `json:"id"`
+	Type      types.ChunkType `json:"type"`
+	Mode      Mode            `json:"mode"`
+	Tokens    int             `json:"tokens"`
+	Score     float64         `json:"score"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// Result is a ready-to-insert context block and its accounting
+type Result struct {
+	Context    string `json:"context"`
+	Model      string `json:"model"`
+	Budget     int    `json:"token_budget"`
+	TokensUsed int    `json:"tokens_used"`
+	Items      []Item `json:"items"`
+	Duplicates int    `json:"duplicates_removed"`
+	Dropped    int    `json:"dropped"`
+}
+
+// candidate is a deduplicated chunk with its packing score
+type candidate struct {
+	chunk *types.ConversationChunk
+	score float64
+}
+
+// Pack selects chunks from results to fit the budget. Chunks are deduplicated
+// by content, ranked by relevance weighted by recency and priority, and
+// included in full when they fit or as their summary otherwise. Session
+// summaries are placed first, followed by the remaining chunks by rank.
+func Pack(results []types.SearchResult, opts Options) *Result {
+	profile := ProfileFor(opts.Model)
+	model := opts.Model
+	if model == "" {
+		model = DefaultModel
+	}
+	budget := opts.Budget
+	if budget <= 0 {
+		budget = DefaultBudget
+	}
+	budget = min(max(budget, MinBudget), profile.ContextWindow)
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	if opts.RecencyHalfLife <= 0 {
+		opts.RecencyHalfLife = DefaultRecencyHalfLife
+	}
+	if opts.Title == "" {
+		opts.Title = "Relevant project memory"
+	}
+
+	candidates, duplicates := dedupe(results, opts)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	header := "## " + opts.Title + "\n"
+	used := profile.EstimateTokens(header)
+	entries := make([]string, 0, len(candidates))
+	items := make([]Item, 0, len(candidates))
+	dropped := 0
+
+	for _, c := range candidates {
+		mode := ModeFull
+		entry := renderEntry(c.chunk, mode)
+		tokens := profile.EstimateTokens(entry)
+		if used+tokens > budget && c.chunk.Summary != "" {
+			mode = ModeSummary
+			entry = renderEntry(c.chunk, mode)
+			tokens = profile.EstimateTokens(entry)
+		}
+		if used+tokens > budget {
+			dropped++
+			continue
+		}
+		used += tokens
+		entries = append(entries, entry)
+		items = append(items, Item{
+			ID:        c.chunk.ID,
+			Type:      c.chunk.Type,
+			Mode:      mode,
+			Tokens:    tokens,
+			Score:     c.score,
+			Timestamp: c.chunk.Timestamp,
+		})
+	}
+
+	orderSummariesFirst(entries, items)
+
+	var b strings.Builder
+	if len(entries) == 0 {
+		used = 0
+	} else {
+		b.WriteString(header)
+		for _, e := range entries {
+			b.WriteString("\n")
+			b.WriteString(e)
+		}
+	}
+
+	return &Result{
+		Context:    b.String(),
+		Model:      model,
+		Budget:     budget,
+		TokensUsed: used,
+		Items:      items,
+		Duplicates: duplicates,
+		Dropped:    dropped,
+	}
+}
+
+// dedupe removes deleted chunks and chunks with identical normalized content,
+// keeping the best-scoring copy
+func dedupe(results []types.SearchResult, opts Options) ([]candidate, int) {
+	byHash := make(map[[sha256.Size]byte]int)
+	candidates := make([]candidate, 0, len(results))
+	duplicates := 0
+
+	for i := range results {
+		chunk := &results[i].Chunk
+		if chunk.IsDeleted() {
+			continue
+		}
+		c := candidate{chunk: chunk, score: weight(results[i].Score, chunk, opts)}
+		key := sha256.Sum256([]byte(strings.Join(strings.Fields(strings.ToLower(chunk.Content)), " ")))
+		if idx, ok := byHash[key]; ok {
+			duplicates++
+			if c.score > candidates[idx].score {
+				candidates[idx] = c
+			}
+			continue
+		}
+		byHash[key] = len(candidates)
+		candidates = append(candidates, c)
+	}
+	return candidates, duplicates
+}
+
+// weight combines relevance with recency decay and type/priority boosts
+func weight(relevance float64, chunk *types.ConversationChunk, opts Options) float64 {
+	age := opts.Now.Sub(chunk.Timestamp)
+	if age < 0 {
+		age = 0
+	}
+	recency := 0.5 + 0.5*math.Pow(0.5, float64(age)/float64(opts.RecencyHalfLife))
+
+	priority := 1.0
+	switch chunk.Type {
+	case types.ChunkTypeArchitectureDecision:
+		priority = 1.3
+	case types.ChunkTypeSessionSummary, types.ChunkTypeSolution:
+		priority = 1.2
+	}
+	if chunk.Metadata.TaskPriority != nil {
+		switch *chunk.Metadata.TaskPriority {
+		case types.PriorityHigh:
+			priority *= 1.2
+		case types.PriorityLow:
+			priority *= 0.8
+		}
+	}
+	return relevance * recency * priority
+}
+
+// renderEntry formats a chunk as a markdown section
+func renderEntry(chunk *types.ConversationChunk, mode Mode) string {
+	body := chunk.Content
+	if mode == ModeSummary {
+		body = chunk.Summary
+	}
+	return fmt.Sprintf("### %s · %s · %s\n%s\n", chunk.Type, chunk.Timestamp.Format("2006-01-02"), chunk.ID, strings.TrimSpace(body))
+}
+
+// orderSummariesFirst moves session summaries ahead of other entries,
+// preserving rank order within each group
+func orderSummariesFirst(entries []string, items []Item) {
+	idx := make([]int, len(items))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		return items[idx[a]].Type == types.ChunkTypeSessionSummary && items[idx[b]].Type != types.ChunkTypeSessionSummary
+	})
+
+	sortedEntries := make([]string, len(entries))
+	sortedItems := make([]Item, len(items))
+	for i, j := range idx {
+		sortedEntries[i] = entries[j]
+		sortedItems[i] = items[j]
+	}
+	copy(entries, sortedEntries)
+	copy(items, sortedItems)
+}
@@ -0,0 +1,88 @@
+package packing
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func result(id string, chunkType types.ChunkType, content, summary string, age time.Duration, score float64) types.SearchResult {
+	return types.SearchResult{
+		Chunk: types.ConversationChunk{
+			ID:        id,
+			Type:      chunkType,
+			Content:   content,
+			Summary:   summary,
+			Timestamp: now.Add(-age),
+		},
+		Score: score,
+	}
+}
+
+func itemIDs(items []Item) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return ids
+}
+
+func TestProfileFor(t *testing.T) {
+	assert.Equal(t, "claude", ProfileFor("claude-3-5-sonnet").Name)
+	assert.Equal(t, "gpt-4o", ProfileFor("GPT-4o-mini").Name)
+	assert.Equal(t, "gpt-4", ProfileFor("gpt-4-0613").Name)
+	assert.Equal(t, DefaultModel, ProfileFor("unknown-model").Name)
+	assert.Equal(t, 3, ProfileFor("gpt-4").EstimateTokens("hello world"))
+}
+
+func TestPackOrdersSummariesFirstAndDeduplicates(t *testing.T) {
+	results := []types.SearchResult{
+		result("solution", types.ChunkTypeSolution, "Raised the postgres pool size to 50.", "", time.Hour, 0.9),
+		result("copy", types.ChunkTypeSolution, "raised the  Postgres pool size to 50.", "", 30*24*time.Hour, 0.9),
+		result("summary", types.ChunkTypeSessionSummary, "Session fixed pool exhaustion.", "", time.Hour, 0.5),
+		result("old", types.ChunkTypeDiscussion, "Talked about caching.", "", 90*24*time.Hour, 0.9),
+	}
+
+	packed := Pack(results, Options{Model: "gpt-4o", Now: now})
+
+	assert.Equal(t, []string{"summary", "solution", "old"}, itemIDs(packed.Items))
+	assert.Equal(t, 1, packed.Duplicates)
+	assert.Equal(t, 0, packed.Dropped)
+	assert.True(t, strings.HasPrefix(packed.Context, "## Relevant project memory\n"))
+	assert.Less(t, strings.Index(packed.Context, "Session fixed"), strings.Index(packed.Context, "Raised the postgres"))
+
+	total := ProfileFor("gpt-4o").EstimateTokens("## Relevant project memory\n")
+	for _, item := range packed.Items {
+		total += item.Tokens
+	}
+	assert.Equal(t, total, packed.TokensUsed)
+}
+
+func TestPackFallsBackToSummariesWithinBudget(t *testing.T) {
+	long := strings.Repeat("connection pool details ", 60)
+	results := []types.SearchResult{
+		result("big", types.ChunkTypeSolution, long, "Pool size raised to 50.", time.Hour, 0.9),
+		result("huge", types.ChunkTypeProblem, long+long, "", time.Hour, 0.8),
+	}
+
+	packed := Pack(results, Options{Budget: 150, Now: now})
+	require.Len(t, packed.Items, 1)
+	assert.Equal(t, ModeSummary, packed.Items[0].Mode)
+	assert.Contains(t, packed.Context, "Pool size raised to 50.")
+	assert.Equal(t, 1, packed.Dropped)
+	assert.LessOrEqual(t, packed.TokensUsed, 150)
+}
+
+func TestPackClampsBudgetToContextWindow(t *testing.T) {
+	packed := Pack(nil, Options{Model: "gpt-4", Budget: 1000000})
+	assert.Equal(t, 8192, packed.Budget)
+	assert.Empty(t, packed.Context)
+	assert.Zero(t, packed.TokensUsed)
+}
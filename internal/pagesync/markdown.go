@@ -0,0 +1,340 @@
+package pagesync
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	// DefaultMaxSectionRunes bounds the length of one imported section
+	DefaultMaxSectionRunes = 4000
+	// minSectionRunes is the length below which a section joins the next one
+	minSectionRunes = 300
+)
+
+// blankLines matches runs of blank lines left by block elements
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+// HTMLToMarkdown converts Confluence storage format (XHTML with ac: macros)
+// to Markdown. Headings, paragraphs, emphasis, links, lists, tables, quotes
+// and code blocks are kept; other markup is reduced to its text.
+func HTMLToMarkdown(source string) string {
+	nodes, err := html.ParseFragment(strings.NewReader(source), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return strings.TrimSpace(source)
+	}
+
+	var b strings.Builder
+	for _, node := range nodes {
+		renderNode(&b, node, 0)
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(b.String(), "\n\n"))
+}
+
+// renderNode writes a node and its children as Markdown. depth is the list nesting level.
+func renderNode(b *strings.Builder, node *html.Node, depth int) {
+	switch node.Type {
+	case html.TextNode:
+		b.WriteString(collapseSpace(node.Data))
+		return
+	case html.CommentNode:
+		// Code macro bodies are CDATA sections, which HTML parses as comments
+		if text, ok := strings.CutPrefix(node.Data, "[CDATA["); ok {
+			b.WriteString(strings.TrimSuffix(text, "]]"))
+		}
+		return
+	case html.ElementNode:
+	default:
+		renderChildren(b, node, depth)
+		return
+	}
+
+	switch node.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level := int(node.Data[1] - '0')
+		b.WriteString("\n\n" + strings.Repeat("#", level) + " " + strings.TrimSpace(innerMarkdown(node, depth)) + "\n\n")
+	case "p", "div":
+		b.WriteString("\n\n")
+		renderChildren(b, node, depth)
+		b.WriteString("\n\n")
+	case "br":
+		b.WriteString("\n")
+	case "hr":
+		b.WriteString("\n\n---\n\n")
+	case "strong", "b":
+		wrapInline(b, node, depth, "**")
+	case "em", "i":
+		wrapInline(b, node, depth, "*")
+	case "s", "del":
+		wrapInline(b, node, depth, "~~")
+	case "code":
+		wrapInline(b, node, depth, "`")
+	case "a":
+		text := strings.TrimSpace(innerMarkdown(node, depth))
+		if href := attribute(node, "href"); href != "" {
+			b.WriteString("[" + text + "](" + href + ")")
+		} else {
+			b.WriteString(text)
+		}
+	case "ul", "ol":
+		b.WriteString("\n")
+		if depth == 0 {
+			b.WriteString("\n")
+		}
+		renderList(b, node, depth)
+		b.WriteString("\n")
+	case "blockquote":
+		quoted := strings.TrimSpace(innerMarkdown(node, depth))
+		b.WriteString("\n\n> " + strings.ReplaceAll(quoted, "\n", "\n> ") + "\n\n")
+	case "pre":
+		b.WriteString("\n\n```\n" + strings.Trim(textContent(node), "\n") + "\n```\n\n")
+	case "table":
+		renderTable(b, node)
+	case "ac:structured-macro":
+		renderMacro(b, node, depth)
+	case "img", "ac:image", "script", "style":
+	default:
+		renderChildren(b, node, depth)
+	}
+}
+
+// renderChildren writes the children of a node
+func renderChildren(b *strings.Builder, node *html.Node, depth int) {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		renderNode(b, child, depth)
+	}
+}
+
+// innerMarkdown returns the Markdown of a node's children
+func innerMarkdown(node *html.Node, depth int) string {
+	var b strings.Builder
+	renderChildren(&b, node, depth)
+	return b.String()
+}
+
+// wrapInline writes a node's children between inline markers
+func wrapInline(b *strings.Builder, node *html.Node, depth int, marker string) {
+	text := innerMarkdown(node, depth)
+	if strings.TrimSpace(text) == "" {
+		b.WriteString(text)
+		return
+	}
+	b.WriteString(marker + strings.TrimSpace(text) + marker)
+}
+
+// renderList writes the items of a list, indenting nested lists
+func renderList(b *strings.Builder, list *html.Node, depth int) {
+	for item := list.FirstChild; item != nil; item = item.NextSibling {
+		if item.Type != html.ElementNode || item.Data != "li" {
+			continue
+		}
+		bullet := "- "
+		if list.Data == "ol" {
+			bullet = "1. "
+		}
+		text := strings.TrimSpace(blankLines.ReplaceAllString(innerMarkdown(item, depth+1), "\n\n"))
+		b.WriteString(strings.Repeat("  ", depth) + bullet + strings.ReplaceAll(text, "\n\n", "\n") + "\n")
+	}
+}
+
+// renderTable writes a table as a Markdown table, using the first row as header
+func renderTable(b *strings.Builder, table *html.Node) {
+	var rows [][]string
+	var collect func(node *html.Node)
+	collect = func(node *html.Node) {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			if child.Data != "tr" {
+				collect(child)
+				continue
+			}
+			var row []string
+			for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+				if cell.Type == html.ElementNode && (cell.Data == "td" || cell.Data == "th") {
+					text := strings.TrimSpace(blankLines.ReplaceAllString(innerMarkdown(cell, 0), "\n\n"))
+					row = append(row, strings.ReplaceAll(strings.ReplaceAll(text, "\n", " "), "|", `\|`))
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+	collect(table)
+	if len(rows) == 0 {
+		return
+	}
+
+	b.WriteString("\n\n")
+	for i, row := range rows {
+		b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		if i == 0 {
+			b.WriteString(strings.Repeat("| --- ", len(row)) + "|\n")
+		}
+	}
+	b.WriteString("\n")
+}
+
+// renderMacro writes the Confluence macros that carry content: code blocks
+// and panels. Other macros are dropped.
+func renderMacro(b *strings.Builder, macro *html.Node, depth int) {
+	name := attribute(macro, "ac:name")
+	switch name {
+	case "code", "noformat":
+		language := ""
+		body := ""
+		for child := macro.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			switch child.Data {
+			case "ac:parameter":
+				if attribute(child, "ac:name") == "language" {
+					language = textContent(child)
+				}
+			case "ac:plain-text-body":
+				body = innerMarkdown(child, depth)
+			}
+		}
+		b.WriteString("\n\n```" + language + "\n" + strings.Trim(body, "\n") + "\n```\n\n")
+	case "info", "note", "tip", "warning", "panel", "expand":
+		for child := macro.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == html.ElementNode && child.Data == "ac:rich-text-body" {
+				quoted := strings.TrimSpace(blankLines.ReplaceAllString(innerMarkdown(child, depth), "\n\n"))
+				b.WriteString("\n\n> " + strings.ReplaceAll(quoted, "\n", "\n> ") + "\n\n")
+			}
+		}
+	}
+}
+
+// attribute returns the value of a node's attribute, or ""
+func attribute(node *html.Node, key string) string {
+	for _, attr := range node.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// textContent returns the raw text under a node, keeping whitespace
+func textContent(node *html.Node) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			b.WriteString(n.Data)
+		case html.CommentNode:
+			if text, ok := strings.CutPrefix(n.Data, "[CDATA["); ok {
+				b.WriteString(strings.TrimSuffix(text, "]]"))
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(node)
+	return b.String()
+}
+
+// collapseSpace reduces runs of whitespace in text to single spaces, as HTML renders them
+func collapseSpace(text string) string {
+	collapsed := strings.Join(strings.Fields(text), " ")
+	if collapsed == "" {
+		if text != "" {
+			return " "
+		}
+		return ""
+	}
+	if strings.TrimLeft(text, " \t\n\r") != text {
+		collapsed = " " + collapsed
+	}
+	if strings.TrimRight(text, " \t\n\r") != text {
+		collapsed += " "
+	}
+	return collapsed
+}
+
+// Section is a part of a page that is imported as one memory
+type Section struct {
+	// Heading is the heading the section starts under, or "" before the first
+	Heading string
+	Text    string
+}
+
+// SplitSections splits Markdown into sections of at most maxRunes runes.
+// Pages are cut at headings, consecutive short sections are kept together,
+// and sections that are still too long are cut between paragraphs.
+func SplitSections(markdown string, maxRunes int) []Section {
+	if maxRunes <= 0 {
+		maxRunes = DefaultMaxSectionRunes
+	}
+
+	var sections []Section
+	var current Section
+	flush := func() {
+		if strings.TrimSpace(current.Text) != "" {
+			sections = append(sections, splitLong(Section{Heading: current.Heading, Text: strings.TrimSpace(current.Text)}, maxRunes)...)
+		}
+		current = Section{}
+	}
+
+	inCode := false
+	for _, line := range strings.Split(markdown, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+		}
+		if !inCode && strings.HasPrefix(line, "#") {
+			// Short sections are kept together with the next one
+			if runeCount(strings.TrimSpace(current.Text)) >= minSectionRunes {
+				flush()
+			}
+			if current.Heading == "" {
+				current.Heading = strings.TrimSpace(strings.TrimLeft(line, "#"))
+			}
+		}
+		current.Text += line + "\n"
+	}
+	flush()
+	return sections
+}
+
+// splitLong cuts a section that is too long between paragraphs, and cuts
+// paragraphs that are still too long
+func splitLong(section Section, maxRunes int) []Section {
+	if runeCount(section.Text) <= maxRunes {
+		return []Section{section}
+	}
+
+	var parts []Section
+	var current strings.Builder
+	add := func() {
+		if text := strings.TrimSpace(current.String()); text != "" {
+			parts = append(parts, Section{Heading: section.Heading, Text: text})
+		}
+		current.Reset()
+	}
+	for _, paragraph := range strings.Split(section.Text, "\n\n") {
+		if runeCount(current.String())+runeCount(paragraph)+2 > maxRunes {
+			add()
+		}
+		runes := []rune(paragraph)
+		for len(runes) > maxRunes {
+			current.WriteString(string(runes[:maxRunes]))
+			add()
+			runes = runes[maxRunes:]
+		}
+		current.WriteString(string(runes) + "\n\n")
+	}
+	add()
+	return parts
+}
+
+// runeCount returns the number of runes in s
+func runeCount(s string) int {
+	return len([]rune(s))
+}
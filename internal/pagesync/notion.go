@@ -0,0 +1,436 @@
+package pagesync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// notionAPIURL is the public Notion API
+	notionAPIURL = "https://api.notion.com"
+	// notionVersion is the Notion API version the client speaks
+	notionVersion = "2022-06-28"
+	// notionPageSize is the most results Notion returns per request
+	notionPageSize = 100
+	// maxNotionDepth bounds how deep nested blocks are rendered
+	maxNotionDepth = 3
+)
+
+// NotionSource lists pages through the Notion API
+type NotionSource struct {
+	baseURL   string
+	token     string
+	databases []string
+	client    *http.Client
+	users     map[string]string // user id -> "Name <email>"
+}
+
+// NewNotionSource creates a Notion source. Without databases it syncs every
+// page shared with the integration the token belongs to.
+func NewNotionSource(baseURL, token string, databases []string, client *http.Client) *NotionSource {
+	if baseURL == "" {
+		baseURL = notionAPIURL
+	}
+	return &NotionSource{
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		token:     token,
+		databases: databases,
+		client:    client,
+		users:     make(map[string]string),
+	}
+}
+
+// notionPage is a page object of the Notion API
+type notionPage struct {
+	ID             string    `json:"id"`
+	URL            string    `json:"url"`
+	LastEditedTime time.Time `json:"last_edited_time"`
+	LastEditedBy   struct {
+		ID string `json:"id"`
+	} `json:"last_edited_by"`
+	Archived   bool                      `json:"archived"`
+	Properties map[string]notionProperty `json:"properties"`
+}
+
+// notionProperty is a page property; only titles are read
+type notionProperty struct {
+	Type  string           `json:"type"`
+	Title []notionRichText `json:"title"`
+}
+
+// notionRichText is a run of formatted text
+type notionRichText struct {
+	PlainText   string `json:"plain_text"`
+	Href        string `json:"href"`
+	Annotations struct {
+		Bold          bool `json:"bold"`
+		Italic        bool `json:"italic"`
+		Strikethrough bool `json:"strikethrough"`
+		Code          bool `json:"code"`
+	} `json:"annotations"`
+}
+
+// notionList is a paginated list response
+type notionList struct {
+	Results    []json.RawMessage `json:"results"`
+	HasMore    bool              `json:"has_more"`
+	NextCursor string            `json:"next_cursor"`
+}
+
+// Pages returns the pages edited at or after since
+func (n *NotionSource) Pages(ctx context.Context, since time.Time) ([]Page, error) {
+	var listed []notionPage
+	if len(n.databases) == 0 {
+		found, err := n.searchPages(ctx, since)
+		if err != nil {
+			return nil, err
+		}
+		listed = found
+	}
+	for _, database := range n.databases {
+		found, err := n.queryDatabase(ctx, database, since)
+		if err != nil {
+			return nil, err
+		}
+		listed = append(listed, found...)
+	}
+
+	pages := make([]Page, 0, len(listed))
+	for i := range listed {
+		if listed[i].Archived {
+			continue
+		}
+		markdown, err := n.renderChildren(ctx, listed[i].ID, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read notion page %s: %w", listed[i].ID, err)
+		}
+		pages = append(pages, Page{
+			ID:        listed[i].ID,
+			Title:     listed[i].title(),
+			URL:       listed[i].URL,
+			Author:    n.userName(ctx, listed[i].LastEditedBy.ID),
+			Markdown:  strings.TrimSpace(markdown),
+			UpdatedAt: listed[i].LastEditedTime,
+		})
+	}
+	return pages, nil
+}
+
+// title returns the text of the page's title property
+func (p *notionPage) title() string {
+	for _, property := range p.Properties {
+		if property.Type == "title" {
+			return plainText(property.Title)
+		}
+	}
+	return "Untitled"
+}
+
+// searchPages lists the pages shared with the integration, most recently
+// edited first, stopping at the first one edited before since
+func (n *NotionSource) searchPages(ctx context.Context, since time.Time) ([]notionPage, error) {
+	var pages []notionPage
+	cursor := ""
+	for {
+		body := map[string]interface{}{
+			"filter":    map[string]interface{}{"property": "object", "value": "page"},
+			"sort":      map[string]interface{}{"direction": "descending", "timestamp": "last_edited_time"},
+			"page_size": notionPageSize,
+		}
+		if cursor != "" {
+			body["start_cursor"] = cursor
+		}
+		var list notionList
+		if err := n.do(ctx, http.MethodPost, "/v1/search", body, &list); err != nil {
+			return nil, err
+		}
+		for _, raw := range list.Results {
+			var page notionPage
+			if err := json.Unmarshal(raw, &page); err != nil {
+				return nil, fmt.Errorf("failed to parse notion page: %w", err)
+			}
+			if page.LastEditedTime.Before(since) {
+				return pages, nil
+			}
+			pages = append(pages, page)
+		}
+		if !list.HasMore {
+			return pages, nil
+		}
+		cursor = list.NextCursor
+	}
+}
+
+// queryDatabase lists the pages of a database edited at or after since
+func (n *NotionSource) queryDatabase(ctx context.Context, database string, since time.Time) ([]notionPage, error) {
+	var pages []notionPage
+	cursor := ""
+	for {
+		body := map[string]interface{}{"page_size": notionPageSize}
+		if !since.IsZero() {
+			body["filter"] = map[string]interface{}{
+				"timestamp":        "last_edited_time",
+				"last_edited_time": map[string]interface{}{"on_or_after": since.UTC().Format(time.RFC3339)},
+			}
+		}
+		if cursor != "" {
+			body["start_cursor"] = cursor
+		}
+		var list notionList
+		if err := n.do(ctx, http.MethodPost, "/v1/databases/"+database+"/query", body, &list); err != nil {
+			return nil, err
+		}
+		for _, raw := range list.Results {
+			var page notionPage
+			if err := json.Unmarshal(raw, &page); err != nil {
+				return nil, fmt.Errorf("failed to parse notion page: %w", err)
+			}
+			pages = append(pages, page)
+		}
+		if !list.HasMore {
+			return pages, nil
+		}
+		cursor = list.NextCursor
+	}
+}
+
+// notionBlock is a content block; its content sits under a key named after its type
+type notionBlock struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	HasChildren bool   `json:"has_children"`
+	content     notionBlockContent
+}
+
+// notionBlockContent holds the fields of the block types that are rendered
+type notionBlockContent struct {
+	RichText []notionRichText `json:"rich_text"`
+	Checked  bool             `json:"checked"`
+	Language string           `json:"language"`
+	Title    string           `json:"title"`
+	URL      string           `json:"url"`
+}
+
+// renderChildren renders the child blocks of a page or block as Markdown
+func (n *NotionSource) renderChildren(ctx context.Context, parentID string, depth int) (string, error) {
+	var b strings.Builder
+	cursor := ""
+	for {
+		path := fmt.Sprintf("/v1/blocks/%s/children?page_size=%d", parentID, notionPageSize)
+		if cursor != "" {
+			path += "&start_cursor=" + cursor
+		}
+		var list notionList
+		if err := n.do(ctx, http.MethodGet, path, nil, &list); err != nil {
+			return "", err
+		}
+		for _, raw := range list.Results {
+			block, err := parseNotionBlock(raw)
+			if err != nil {
+				return "", err
+			}
+			if err := n.renderBlock(ctx, &b, block, depth); err != nil {
+				return "", err
+			}
+		}
+		if !list.HasMore {
+			return b.String(), nil
+		}
+		cursor = list.NextCursor
+	}
+}
+
+// parseNotionBlock decodes a block and the content under its type key
+func parseNotionBlock(raw json.RawMessage) (*notionBlock, error) {
+	var block notionBlock
+	if err := json.Unmarshal(raw, &block); err != nil {
+		return nil, fmt.Errorf("failed to parse notion block: %w", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("failed to parse notion block: %w", err)
+	}
+	if content, ok := fields[block.Type]; ok {
+		if err := json.Unmarshal(content, &block.content); err != nil {
+			return nil, fmt.Errorf("failed to parse notion %s block: %w", block.Type, err)
+		}
+	}
+	return &block, nil
+}
+
+// renderBlock writes one block, and its children, as Markdown
+func (n *NotionSource) renderBlock(ctx context.Context, b *strings.Builder, block *notionBlock, depth int) error {
+	indent := strings.Repeat("  ", depth)
+	text := richTextMarkdown(block.content.RichText)
+	nested := false
+
+	switch block.Type {
+	case "heading_1", "heading_2", "heading_3":
+		level := int(block.Type[len(block.Type)-1] - '0')
+		fmt.Fprintf(b, "%s %s\n\n", strings.Repeat("#", level), text)
+	case "paragraph":
+		if text != "" {
+			fmt.Fprintf(b, "%s%s\n\n", indent, text)
+		}
+	case "bulleted_list_item", "toggle":
+		fmt.Fprintf(b, "%s- %s\n", indent, text)
+		nested = true
+	case "numbered_list_item":
+		fmt.Fprintf(b, "%s1. %s\n", indent, text)
+		nested = true
+	case "to_do":
+		check := " "
+		if block.content.Checked {
+			check = "x"
+		}
+		fmt.Fprintf(b, "%s- [%s] %s\n", indent, check, text)
+		nested = true
+	case "quote", "callout":
+		fmt.Fprintf(b, "%s> %s\n\n", indent, text)
+	case "code":
+		fmt.Fprintf(b, "```%s\n%s\n```\n\n", block.content.Language, plainText(block.content.RichText))
+	case "divider":
+		b.WriteString("---\n\n")
+	case "bookmark", "embed", "link_preview":
+		if block.content.URL != "" {
+			fmt.Fprintf(b, "%s<%s>\n\n", indent, block.content.URL)
+		}
+	case "child_page":
+		// Subpages are pages of their own; only mention them
+		fmt.Fprintf(b, "%sSubpage: %s\n\n", indent, block.content.Title)
+		return nil
+	default:
+		if text != "" {
+			fmt.Fprintf(b, "%s%s\n\n", indent, text)
+		}
+	}
+
+	if !block.HasChildren || depth+1 >= maxNotionDepth {
+		return nil
+	}
+	childDepth := depth
+	if nested {
+		childDepth = depth + 1
+	}
+	children, err := n.renderChildren(ctx, block.ID, childDepth)
+	if err != nil {
+		return err
+	}
+	b.WriteString(children)
+	if nested && depth == 0 {
+		b.WriteString("\n")
+	}
+	return nil
+}
+
+// richTextMarkdown renders formatted text runs as Markdown
+func richTextMarkdown(runs []notionRichText) string {
+	var b strings.Builder
+	for i := range runs {
+		text := runs[i].PlainText
+		if text == "" {
+			continue
+		}
+		switch {
+		case runs[i].Annotations.Code:
+			text = "`" + text + "`"
+		case runs[i].Annotations.Bold && runs[i].Annotations.Italic:
+			text = "***" + text + "***"
+		case runs[i].Annotations.Bold:
+			text = "**" + text + "**"
+		case runs[i].Annotations.Italic:
+			text = "*" + text + "*"
+		}
+		if runs[i].Annotations.Strikethrough {
+			text = "~~" + text + "~~"
+		}
+		if runs[i].Href != "" {
+			text = "[" + text + "](" + runs[i].Href + ")"
+		}
+		b.WriteString(text)
+	}
+	return b.String()
+}
+
+// plainText joins text runs without formatting
+func plainText(runs []notionRichText) string {
+	var b strings.Builder
+	for i := range runs {
+		b.WriteString(runs[i].PlainText)
+	}
+	return b.String()
+}
+
+// userName returns a Notion user as "Name <email>", remembering the answer.
+// Users that cannot be read are left anonymous.
+func (n *NotionSource) userName(ctx context.Context, id string) string {
+	if id == "" {
+		return ""
+	}
+	if name, ok := n.users[id]; ok {
+		return name
+	}
+
+	var user struct {
+		Name   string `json:"name"`
+		Person struct {
+			Email string `json:"email"`
+		} `json:"person"`
+	}
+	name := ""
+	if err := n.do(ctx, http.MethodGet, "/v1/users/"+id, nil, &user); err == nil {
+		name = user.Name
+		if user.Person.Email != "" {
+			name = fmt.Sprintf("%s <%s>", user.Name, user.Person.Email)
+		}
+	}
+	n.users[id] = name
+	return name
+}
+
+// do sends an API request and decodes the JSON response into out
+func (n *NotionSource) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode notion request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, n.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create notion request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+n.token)
+	req.Header.Set("Notion-Version", notionVersion)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return doJSON(n.client, req, "notion", out)
+}
+
+// doJSON sends a request and decodes a successful JSON response into out
+func doJSON(client *http.Client, req *http.Request, service string, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", service, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned status %d: %s", service, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", service, err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// Package pagesync imports pages from Notion and Confluence as memories and
+// keeps them in sync. Pages are converted to Markdown, split into sections
+// and imported again whenever they change upstream.
+package pagesync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Source types
+const (
+	SourceNotion     = "notion"
+	SourceConfluence = "confluence"
+)
+
+const (
+	// defaultIntervalMinutes is how often sources are re-synced unless configured
+	defaultIntervalMinutes = 60
+	// minIntervalMinutes keeps sources from being polled too often
+	minIntervalMinutes = 5
+	// requestTimeout bounds each API call to a source
+	requestTimeout = 30 * time.Second
+)
+
+// Page is one page of a knowledge base, converted to Markdown
+type Page struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+	// Space is the Confluence space key or the Notion database the page belongs to
+	Space string `json:"space,omitempty"`
+	// Author is the last editor, as "Name <email>" when the email is known
+	Author    string    `json:"author,omitempty"`
+	Markdown  string    `json:"-"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Source lists the pages of a knowledge base
+type Source interface {
+	// Pages returns the pages edited at or after since, with their content.
+	// A zero since returns every page.
+	Pages(ctx context.Context, since time.Time) ([]Page, error)
+}
+
+// SourceSpec configures one synced Notion workspace or Confluence space set
+type SourceSpec struct {
+	Name       string `yaml:"name" json:"name"`
+	Type       string `yaml:"type" json:"type"`
+	Repository string `yaml:"repository" json:"repository"`
+	// Spaces are Confluence space keys or Notion database IDs. Notion sources
+	// without any sync every page shared with the integration.
+	Spaces          []string `yaml:"spaces,omitempty" json:"spaces,omitempty"`
+	IntervalMinutes int      `yaml:"interval_minutes" json:"interval_minutes"`
+}
+
+// Validate checks the spec for missing or invalid fields
+func (s *SourceSpec) Validate() error {
+	if s.Name == "" {
+		return errors.New("page sync source name is required")
+	}
+	if s.Repository == "" {
+		return fmt.Errorf("page sync source %q requires a repository", s.Name)
+	}
+	switch s.Type {
+	case SourceNotion:
+	case SourceConfluence:
+		if len(s.Spaces) == 0 {
+			return fmt.Errorf("confluence source %q requires at least one space key", s.Name)
+		}
+	default:
+		return fmt.Errorf("page sync source %q has invalid type %q (valid: notion, confluence)", s.Name, s.Type)
+	}
+	if s.IntervalMinutes != 0 && s.IntervalMinutes < minIntervalMinutes {
+		return fmt.Errorf("page sync source %q interval must be at least %d minutes", s.Name, minIntervalMinutes)
+	}
+	return nil
+}
+
+// Interval returns how often the source is re-synced
+func (s *SourceSpec) Interval() time.Duration {
+	if s.IntervalMinutes == 0 {
+		return defaultIntervalMinutes * time.Minute
+	}
+	return time.Duration(s.IntervalMinutes) * time.Minute
+}
+
+// sourcesFile is the YAML layout of a page sync sources file
+type sourcesFile struct {
+	Sources []SourceSpec `yaml:"sources"`
+}
+
+// LoadSources reads source specs from a YAML file
+func LoadSources(path string) ([]SourceSpec, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // Path comes from operator configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page sync sources: %w", err)
+	}
+
+	var file sourcesFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse page sync sources: %w", err)
+	}
+
+	names := make(map[string]bool, len(file.Sources))
+	for i := range file.Sources {
+		if err := file.Sources[i].Validate(); err != nil {
+			return nil, err
+		}
+		if names[file.Sources[i].Name] {
+			return nil, fmt.Errorf("duplicate page sync source %q", file.Sources[i].Name)
+		}
+		names[file.Sources[i].Name] = true
+	}
+	return file.Sources, nil
+}
+
+// Credentials hold the API settings shared by all sources of a type
+type Credentials struct {
+	NotionToken   string
+	NotionBaseURL string // defaults to the public Notion API
+
+	ConfluenceBaseURL string // e.g. https://acme.atlassian.net/wiki
+	ConfluenceEmail   string // with ConfluenceToken for Cloud basic auth
+	ConfluenceToken   string // API token, or a personal access token without an email
+}
+
+// NewSource creates the client for a source spec
+func NewSource(spec *SourceSpec, credentials *Credentials) (Source, error) {
+	client := &http.Client{Timeout: requestTimeout}
+	switch spec.Type {
+	case SourceNotion:
+		if credentials.NotionToken == "" {
+			return nil, fmt.Errorf("notion source %q requires MCP_MEMORY_NOTION_TOKEN", spec.Name)
+		}
+		return NewNotionSource(credentials.NotionBaseURL, credentials.NotionToken, spec.Spaces, client), nil
+	case SourceConfluence:
+		if credentials.ConfluenceBaseURL == "" || credentials.ConfluenceToken == "" {
+			return nil, fmt.Errorf("confluence source %q requires MCP_MEMORY_CONFLUENCE_BASE_URL and MCP_MEMORY_CONFLUENCE_TOKEN", spec.Name)
+		}
+		return NewConfluenceSource(credentials.ConfluenceBaseURL, credentials.ConfluenceEmail, credentials.ConfluenceToken, spec.Spaces, client), nil
+	default:
+		return nil, fmt.Errorf("unsupported page sync source type %q", spec.Type)
+	}
+}
@@ -0,0 +1,380 @@
+package pagesync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTMLToMarkdown(t *testing.T) {
+	source := `<h1>Runbook</h1>
+<p>Restart the <strong>api</strong> with <code>make restart</code>, see <a href="https://wiki/x">docs</a>.</p>
+<ul><li>first</li><li>second<ul><li>nested</li></ul></li></ul>
+<table><tbody><tr><th>Env</th><th>Host</th></tr><tr><td>prod</td><td>a|b</td></tr></tbody></table>
+<ac:structured-macro ac:name="code"><ac:parameter ac:name="language">bash</ac:parameter><ac:plain-text-body><![CDATA[kubectl rollout restart deploy/api]]></ac:plain-text-body></ac:structured-macro>
+<ac:structured-macro ac:name="warning"><ac:rich-text-body><p>Page the on-call first</p></ac:rich-text-body></ac:structured-macro>`
+
+	markdown := HTMLToMarkdown(source)
+
+	assert.Contains(t, markdown, "# Runbook")
+	assert.Contains(t, markdown, "Restart the **api** with `make restart`, see [docs](https://wiki/x).")
+	assert.Contains(t, markdown, "- first\n- second\n  - nested")
+	assert.Contains(t, markdown, "| Env | Host |\n| --- | --- |\n| prod | a\\|b |")
+	assert.Contains(t, markdown, "```bash\nkubectl rollout restart deploy/api\n```")
+	assert.Contains(t, markdown, "> Page the on-call first")
+	assert.NotContains(t, markdown, "\n\n\n")
+}
+
+func TestSplitSections(t *testing.T) {
+	long := strings.Repeat("word ", 80)
+	markdown := "intro\n\n# Short\n\ntiny\n\n# Deploy\n\n" + long + "\n\n```\n# not a heading\n```\n\n# Rollback\n\n" + long
+
+	sections := SplitSections(markdown, DefaultMaxSectionRunes)
+
+	require.Len(t, sections, 2)
+	assert.Equal(t, "Short", sections[0].Heading, "short sections join the next one")
+	assert.Contains(t, sections[0].Text, "intro")
+	assert.Contains(t, sections[0].Text, "# not a heading")
+	assert.Equal(t, "Rollback", sections[1].Heading)
+}
+
+func TestSplitSections_LongSection(t *testing.T) {
+	paragraph := strings.Repeat("a", 60)
+	markdown := "# Notes\n\n" + strings.Repeat(paragraph+"\n\n", 5) + strings.Repeat("b", 250)
+
+	sections := SplitSections(markdown, 100)
+
+	require.Greater(t, len(sections), 3)
+	for _, section := range sections {
+		assert.Equal(t, "Notes", section.Heading)
+		assert.LessOrEqual(t, len([]rune(section.Text)), 100)
+	}
+}
+
+func TestSourceSpec_Validate(t *testing.T) {
+	valid := SourceSpec{Name: "wiki", Type: SourceConfluence, Repository: "github.com/acme/api", Spaces: []string{"ENG"}}
+	require.NoError(t, valid.Validate())
+	assert.Equal(t, time.Hour, valid.Interval())
+
+	noSpaces := valid
+	noSpaces.Spaces = nil
+	assert.Error(t, noSpaces.Validate())
+
+	badType := valid
+	badType.Type = "sharepoint"
+	assert.Error(t, badType.Validate())
+
+	tooOften := valid
+	tooOften.IntervalMinutes = 1
+	assert.Error(t, tooOften.Validate())
+
+	notion := SourceSpec{Name: "notes", Type: SourceNotion, Repository: "github.com/acme/api", IntervalMinutes: 30}
+	require.NoError(t, notion.Validate())
+	assert.Equal(t, 30*time.Minute, notion.Interval())
+}
+
+func TestLoadSources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sources.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`sources:
+  - name: wiki
+    type: confluence
+    repository: github.com/acme/api
+    spaces: [ENG, OPS]
+  - name: notes
+    type: notion
+    repository: github.com/acme/api
+    interval_minutes: 15
+`), 0o600))
+
+	specs, err := LoadSources(path)
+	require.NoError(t, err)
+	require.Len(t, specs, 2)
+	assert.Equal(t, []string{"ENG", "OPS"}, specs[0].Spaces)
+	assert.Equal(t, 15, specs[1].IntervalMinutes)
+
+	require.NoError(t, os.WriteFile(path, []byte(`sources:
+  - {name: wiki, type: notion, repository: r}
+  - {name: wiki, type: notion, repository: r}
+`), 0o600))
+	_, err = LoadSources(path)
+	assert.ErrorContains(t, err, "duplicate")
+}
+
+func TestNotionSource_Pages(t *testing.T) {
+	edited := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	var searches int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, notionVersion, r.Header.Get("Notion-Version"))
+		switch {
+		case r.URL.Path == "/v1/search":
+			searches++
+			writeJSON(t, w, map[string]interface{}{"results": []interface{}{
+				notionPageJSON("page-1", "Runbook", edited),
+				notionPageJSON("page-old", "Old", edited.Add(-48*time.Hour)),
+			}})
+		case r.URL.Path == "/v1/blocks/page-1/children":
+			writeJSON(t, w, map[string]interface{}{"results": []interface{}{
+				map[string]interface{}{"id": "b1", "type": "heading_2", "heading_2": richText("Restart")},
+				map[string]interface{}{"id": "b2", "type": "bulleted_list_item", "has_children": true, "bulleted_list_item": richText("drain")},
+				map[string]interface{}{"id": "b3", "type": "code", "code": map[string]interface{}{"language": "bash", "rich_text": []interface{}{map[string]interface{}{"plain_text": "make restart"}}}},
+			}})
+		case r.URL.Path == "/v1/blocks/b2/children":
+			writeJSON(t, w, map[string]interface{}{"results": []interface{}{
+				map[string]interface{}{"id": "b4", "type": "bulleted_list_item", "bulleted_list_item": richText("wait")},
+			}})
+		case r.URL.Path == "/v1/users/user-1":
+			writeJSON(t, w, map[string]interface{}{"name": "Ana", "person": map[string]interface{}{"email": "ana@acme.io"}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	source := NewNotionSource(server.URL, "secret", nil, server.Client())
+	pages, err := source.Pages(context.Background(), edited.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, pages, 1, "pages edited before since are not listed")
+
+	page := pages[0]
+	assert.Equal(t, "page-1", page.ID)
+	assert.Equal(t, "Runbook", page.Title)
+	assert.Equal(t, "https://notion.so/page-1", page.URL)
+	assert.Equal(t, "Ana <ana@acme.io>", page.Author)
+	assert.True(t, page.UpdatedAt.Equal(edited))
+	assert.Equal(t, "## Restart\n\n- drain\n  - wait\n\n```bash\nmake restart\n```", page.Markdown)
+	assert.Equal(t, 1, searches)
+}
+
+func TestConfluenceSource_Pages(t *testing.T) {
+	edited := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, token, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "bot@acme.io", user)
+		assert.Equal(t, "secret", token)
+		require.Equal(t, "/rest/api/content/search", r.URL.Path)
+		queries = append(queries, r.URL.Query().Get("cql"))
+
+		result := map[string]interface{}{"size": 1, "_links": map[string]interface{}{"base": "https://acme.atlassian.net/wiki"}}
+		page := map[string]interface{}{
+			"id":      "42",
+			"title":   "Deploys",
+			"space":   map[string]interface{}{"key": "ENG"},
+			"version": map[string]interface{}{"when": edited, "by": map[string]interface{}{"displayName": "Bruno"}},
+			"body":    map[string]interface{}{"storage": map[string]interface{}{"value": "<h2>Steps</h2><p>Run it</p>"}},
+			"_links":  map[string]interface{}{"webui": "/spaces/ENG/pages/42"},
+		}
+		if r.URL.Query().Get("start") == "0" {
+			result["_links"].(map[string]interface{})["next"] = "/rest/api/content/search?start=1"
+		} else {
+			page["id"] = "43"
+		}
+		result["results"] = []interface{}{page}
+		writeJSON(t, w, result)
+	}))
+	defer server.Close()
+
+	source := NewConfluenceSource(server.URL+"/", "bot@acme.io", "secret", []string{"ENG"}, server.Client())
+	pages, err := source.Pages(context.Background(), edited)
+	require.NoError(t, err)
+	require.Len(t, pages, 2, "results are paginated")
+
+	assert.Equal(t, "42", pages[0].ID)
+	assert.Equal(t, "ENG", pages[0].Space)
+	assert.Equal(t, "Bruno", pages[0].Author)
+	assert.Equal(t, "https://acme.atlassian.net/wiki/spaces/ENG/pages/42", pages[0].URL)
+	assert.Equal(t, "## Steps\n\nRun it", pages[0].Markdown)
+	require.Len(t, queries, 2)
+	assert.Equal(t, `type = page AND space in ("ENG") AND lastmodified >= "2026-03-01 10:00" ORDER BY lastmodified ASC`, queries[0])
+}
+
+func TestNewSource_RequiresCredentials(t *testing.T) {
+	notion := &SourceSpec{Name: "notes", Type: SourceNotion, Repository: "r"}
+	_, err := NewSource(notion, &Credentials{})
+	assert.ErrorContains(t, err, "MCP_MEMORY_NOTION_TOKEN")
+
+	confluence := &SourceSpec{Name: "wiki", Type: SourceConfluence, Repository: "r", Spaces: []string{"ENG"}}
+	_, err = NewSource(confluence, &Credentials{ConfluenceBaseURL: "https://wiki"})
+	assert.ErrorContains(t, err, "MCP_MEMORY_CONFLUENCE_TOKEN")
+
+	source, err := NewSource(confluence, &Credentials{ConfluenceBaseURL: "https://wiki", ConfluenceToken: "pat"})
+	require.NoError(t, err)
+	assert.IsType(t, &ConfluenceSource{}, source)
+}
+
+// fakeSource serves a fixed list of pages, filtered by since
+type fakeSource struct {
+	pages  []Page
+	err    error
+	since  []time.Time
+	called int
+}
+
+func (f *fakeSource) Pages(_ context.Context, since time.Time) ([]Page, error) {
+	f.called++
+	f.since = append(f.since, since)
+	if f.err != nil {
+		return nil, f.err
+	}
+	var pages []Page
+	for _, page := range f.pages {
+		if !page.UpdatedAt.Before(since) {
+			pages = append(pages, page)
+		}
+	}
+	return pages, nil
+}
+
+// fakeIngester records ingested pages and returns one chunk ID per version
+type fakeIngester struct {
+	ingested []string
+	previous map[string][]string
+	fail     map[string]bool
+}
+
+func (f *fakeIngester) IngestPage(_ context.Context, _ *SourceSpec, page *Page, previous []string) ([]string, error) {
+	if f.fail[page.ID] {
+		return nil, errors.New("embedding unavailable")
+	}
+	f.ingested = append(f.ingested, page.ID)
+	if f.previous == nil {
+		f.previous = make(map[string][]string)
+	}
+	f.previous[page.ID] = previous
+	return []string{page.ID + "@" + page.UpdatedAt.Format(time.RFC3339)}, nil
+}
+
+func newTestSyncer(t *testing.T, path string, ingester Ingester, source Source, now *time.Time) *Syncer {
+	t.Helper()
+	syncer := NewSyncer(path, ingester)
+	syncer.now = func() time.Time { return *now }
+	require.NoError(t, syncer.Load())
+	require.NoError(t, syncer.AddSource(&SourceSpec{Name: "wiki", Type: SourceNotion, Repository: "github.com/acme/api"}, source))
+	return syncer
+}
+
+func TestSyncer_IncrementalSync(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "state.json")
+	source := &fakeSource{pages: []Page{
+		{ID: "a", Title: "A", UpdatedAt: now.Add(-72 * time.Hour)},
+		{ID: "b", Title: "B", UpdatedAt: now.Add(-2 * time.Hour)},
+	}}
+	ingester := &fakeIngester{}
+	syncer := newTestSyncer(t, path, ingester, source, &now)
+
+	result, err := syncer.Sync(ctx, "wiki", false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Imported)
+	assert.Equal(t, 2, result.Chunks)
+	assert.True(t, source.since[0].IsZero(), "the first sync lists every page")
+
+	// b is edited; a restarted syncer only re-imports it
+	now = now.Add(time.Hour)
+	source.pages[1].UpdatedAt = now.Add(-2 * time.Minute)
+	restarted := newTestSyncer(t, path, ingester, source, &now)
+
+	result, err = restarted.Sync(ctx, "wiki", false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Listed)
+	assert.Equal(t, 1, result.Updated)
+	assert.Equal(t, 0, result.Imported)
+	assert.Equal(t, now.Add(-time.Hour-syncOverlap), source.since[1])
+	assert.Equal(t, []string{"a", "b", "b"}, ingester.ingested)
+	assert.Equal(t, []string{"b@2026-03-02T10:00:00Z"}, ingester.previous["b"], "the previous version is replaced")
+
+	// Pages listed again by the overlap are skipped when unchanged
+	now = now.Add(time.Minute)
+	result, err = restarted.Sync(ctx, "wiki", false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Unchanged)
+	assert.Len(t, ingester.ingested, 3)
+
+	// A full sync re-imports everything
+	result, err = restarted.Sync(ctx, "wiki", true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Updated)
+
+	statuses := restarted.Sources()
+	require.Len(t, statuses, 1)
+	assert.Equal(t, 2, statuses[0].Pages)
+	require.NotNil(t, statuses[0].LastSync)
+	assert.Equal(t, now.Add(time.Hour), statuses[0].NextSync)
+}
+
+func TestSyncer_FailuresKeepTheWindow(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	source := &fakeSource{pages: []Page{
+		{ID: "a", Title: "A", UpdatedAt: now.Add(-time.Hour)},
+		{ID: "b", Title: "B", UpdatedAt: now.Add(-time.Hour)},
+	}}
+	ingester := &fakeIngester{fail: map[string]bool{"b": true}}
+	syncer := newTestSyncer(t, "", ingester, source, &now)
+
+	result, err := syncer.Sync(ctx, "wiki", false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Imported)
+	assert.Equal(t, 1, result.Failed)
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0], "embedding unavailable")
+	assert.Equal(t, "1 pages failed to import", syncer.Sources()[0].LastError)
+
+	// The failed page is retried; the imported one is skipped
+	now = now.Add(time.Hour)
+	ingester.fail = nil
+	result, err = syncer.Sync(ctx, "wiki", false)
+	require.NoError(t, err)
+	assert.True(t, source.since[1].IsZero(), "the window does not advance past failed pages")
+	assert.Equal(t, 1, result.Imported)
+	assert.Equal(t, 1, result.Unchanged)
+	assert.Empty(t, syncer.Sources()[0].LastError)
+
+	// A source that cannot be listed is not synced again until its interval passes
+	source.err = errors.New("unauthorized")
+	now = now.Add(2 * time.Hour)
+	_, err = syncer.Sync(ctx, "wiki", false)
+	require.Error(t, err)
+	status := syncer.Sources()[0]
+	assert.Equal(t, "unauthorized", status.LastError)
+	assert.Empty(t, syncer.SyncDue(ctx, now.Add(time.Minute)))
+	assert.Equal(t, 3, source.called)
+
+	_, err = syncer.Sync(ctx, "missing", false)
+	assert.ErrorContains(t, err, "not found")
+}
+
+func notionPageJSON(id, title string, edited time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"id":               id,
+		"url":              "https://notion.so/" + id,
+		"last_edited_time": edited,
+		"last_edited_by":   map[string]interface{}{"id": "user-1"},
+		"properties": map[string]interface{}{
+			"Name": map[string]interface{}{"type": "title", "title": []interface{}{map[string]interface{}{"plain_text": title}}},
+		},
+	}
+}
+
+func richText(text string) map[string]interface{} {
+	return map[string]interface{}{"rich_text": []interface{}{map[string]interface{}{"plain_text": text}}}
+}
+
+func writeJSON(t *testing.T, w http.ResponseWriter, body interface{}) {
+	t.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	require.NoError(t, json.NewEncoder(w).Encode(body))
+}
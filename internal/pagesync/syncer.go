@@ -0,0 +1,324 @@
+package pagesync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"lerian-mcp-memory/internal/logging"
+)
+
+// syncOverlap re-lists pages edited shortly before the last sync, so edits
+// racing a sync are not missed; unchanged pages are skipped
+const syncOverlap = 5 * time.Minute
+
+// Ingester stores the sections of a page as memories, replacing the
+// memories imported from its previous version, and returns their IDs
+type Ingester interface {
+	IngestPage(ctx context.Context, spec *SourceSpec, page *Page, previous []string) ([]string, error)
+}
+
+// PageState records which version of a page was imported
+type PageState struct {
+	Title     string    `json:"title"`
+	URL       string    `json:"url,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+	SyncedAt  time.Time `json:"synced_at"`
+	ChunkIDs  []string  `json:"chunk_ids"`
+}
+
+// SourceState is the sync progress of one source
+type SourceState struct {
+	LastSync time.Time `json:"last_sync"`
+	// SyncedThrough is when the last sync that imported every page started;
+	// the next incremental sync lists pages edited since then
+	SyncedThrough time.Time            `json:"synced_through"`
+	LastError     string               `json:"last_error,omitempty"`
+	Pages         map[string]PageState `json:"pages"`
+}
+
+// SourceStatus reports a source and its sync progress
+type SourceStatus struct {
+	SourceSpec
+	LastSync  *time.Time `json:"last_sync,omitempty"`
+	NextSync  time.Time  `json:"next_sync"`
+	LastError string     `json:"last_error,omitempty"`
+	Pages     int        `json:"pages"`
+}
+
+// Result reports one sync of a source
+type Result struct {
+	Source     string    `json:"source"`
+	Full       bool      `json:"full"`
+	Listed     int       `json:"pages_listed"`
+	Imported   int       `json:"pages_imported"`
+	Updated    int       `json:"pages_updated"`
+	Unchanged  int       `json:"pages_unchanged"`
+	Failed     int       `json:"pages_failed"`
+	Chunks     int       `json:"chunks_stored"`
+	Errors     []string  `json:"errors,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// syncSource is a configured source and its client
+type syncSource struct {
+	spec   SourceSpec
+	source Source
+}
+
+// Syncer imports pages from its sources and re-imports those that changed.
+// Sync progress is persisted to an optional JSON file so restarts resume
+// incrementally. Pages deleted upstream are not detected and their
+// memories are kept.
+type Syncer struct {
+	syncMu sync.Mutex // one sync at a time
+
+	mu       sync.RWMutex
+	path     string
+	ingester Ingester
+	sources  map[string]*syncSource
+	state    map[string]*SourceState
+	now      func() time.Time
+	interval time.Duration
+}
+
+// NewSyncer creates a syncer persisting its progress at path; an empty path keeps it in memory
+func NewSyncer(path string, ingester Ingester) *Syncer {
+	return &Syncer{
+		path:     path,
+		ingester: ingester,
+		sources:  make(map[string]*syncSource),
+		state:    make(map[string]*SourceState),
+		now:      time.Now,
+		interval: time.Minute,
+	}
+}
+
+// Load reads sync progress from disk. A missing file is not an error.
+func (s *Syncer) Load() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read page sync state: %w", err)
+	}
+
+	state := make(map[string]*SourceState)
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse page sync state: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = state
+	return nil
+}
+
+// AddSource registers a source to sync
+func (s *Syncer) AddSource(spec *SourceSpec, source Source) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.sources[spec.Name]; exists {
+		return fmt.Errorf("page sync source %q already exists", spec.Name)
+	}
+	s.sources[spec.Name] = &syncSource{spec: *spec, source: source}
+	return nil
+}
+
+// Sources returns the configured sources and their progress, by name
+func (s *Syncer) Sources() []SourceStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]SourceStatus, 0, len(s.sources))
+	for name, source := range s.sources {
+		status := SourceStatus{SourceSpec: source.spec, NextSync: s.now().UTC()}
+		if state, ok := s.state[name]; ok {
+			status.LastError = state.LastError
+			status.Pages = len(state.Pages)
+			if !state.LastSync.IsZero() {
+				lastSync := state.LastSync
+				status.LastSync = &lastSync
+				status.NextSync = lastSync.Add(source.spec.Interval())
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Sync imports the pages of a source edited since its last sync, or every
+// page when full is set, and re-imports those whose version changed
+func (s *Syncer) Sync(ctx context.Context, name string, full bool) (*Result, error) {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	s.mu.RLock()
+	source, ok := s.sources[name]
+	var since time.Time
+	known := make(map[string]PageState)
+	if state, exists := s.state[name]; exists {
+		if !full && !state.SyncedThrough.IsZero() {
+			since = state.SyncedThrough.Add(-syncOverlap)
+		}
+		for id, page := range state.Pages {
+			known[id] = page
+		}
+	}
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("page sync source %q not found", name)
+	}
+
+	result := &Result{Source: name, Full: full, StartedAt: s.now().UTC()}
+	pages, err := source.source.Pages(ctx, since)
+	if err != nil {
+		if recordErr := s.recordSync(name, nil, result.StartedAt, false, err.Error()); recordErr != nil {
+			logging.Warn("Failed to record page sync", "source", name, "error", recordErr)
+		}
+		return nil, fmt.Errorf("failed to list pages of %s: %w", name, err)
+	}
+	result.Listed = len(pages)
+
+	synced := make(map[string]PageState)
+	for i := range pages {
+		page := &pages[i]
+		previous, seen := known[page.ID]
+		if seen && !full && !page.UpdatedAt.After(previous.UpdatedAt) {
+			result.Unchanged++
+			continue
+		}
+
+		chunkIDs, err := s.ingester.IngestPage(ctx, &source.spec, page, previous.ChunkIDs)
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("%s (%s): %v", page.Title, page.ID, err))
+			continue
+		}
+		synced[page.ID] = PageState{
+			Title:     page.Title,
+			URL:       page.URL,
+			UpdatedAt: page.UpdatedAt,
+			SyncedAt:  s.now().UTC(),
+			ChunkIDs:  chunkIDs,
+		}
+		result.Chunks += len(chunkIDs)
+		if seen {
+			result.Updated++
+		} else {
+			result.Imported++
+		}
+	}
+
+	// Failed pages are listed again next time, since the window does not advance
+	lastError := ""
+	if result.Failed > 0 {
+		lastError = fmt.Sprintf("%d pages failed to import", result.Failed)
+	}
+	if err := s.recordSync(name, synced, result.StartedAt, result.Failed == 0, lastError); err != nil {
+		return result, err
+	}
+
+	result.FinishedAt = s.now().UTC()
+	logging.Info("Page sync finished", "source", name, "listed", result.Listed, "imported", result.Imported, "updated", result.Updated, "failed", result.Failed)
+	return result, nil
+}
+
+// SyncDue syncs every source whose interval has passed since its last sync
+func (s *Syncer) SyncDue(ctx context.Context, now time.Time) []Result {
+	var results []Result
+	for _, status := range s.Sources() {
+		if status.NextSync.After(now) {
+			continue
+		}
+		result, err := s.Sync(ctx, status.Name, false)
+		if err != nil {
+			logging.Error("Page sync failed", "source", status.Name, "error", err)
+			continue
+		}
+		results = append(results, *result)
+	}
+	return results
+}
+
+// Run syncs sources as they fall due until the context is cancelled
+func (s *Syncer) Run(ctx context.Context) {
+	s.SyncDue(ctx, s.now())
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logging.Info("Stopping page sync due to context cancellation")
+			return
+		case now := <-ticker.C:
+			s.SyncDue(ctx, now)
+		}
+	}
+}
+
+// recordSync stores the outcome of a sync that started at startedAt. Unless
+// complete, the next sync covers the same window again.
+func (s *Syncer) recordSync(name string, synced map[string]PageState, startedAt time.Time, complete bool, lastError string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.state[name]
+	if !ok {
+		state = &SourceState{Pages: make(map[string]PageState)}
+		s.state[name] = state
+	}
+	if state.Pages == nil {
+		state.Pages = make(map[string]PageState)
+	}
+	for id, page := range synced {
+		state.Pages[id] = page
+	}
+	state.LastSync = startedAt
+	if complete {
+		state.SyncedThrough = startedAt
+	}
+	state.LastError = lastError
+	return s.persistLocked()
+}
+
+// persistLocked writes the sync progress to disk; callers must hold the write lock
+func (s *Syncer) persistLocked() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode page sync state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o750); err != nil {
+		return fmt.Errorf("failed to create page sync state directory: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write page sync state: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
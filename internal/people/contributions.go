@@ -0,0 +1,146 @@
+package people
+
+import (
+	"sort"
+	"time"
+
+	"lerian-mcp-memory/pkg/types"
+)
+
+// ReferenceKeys are the extended metadata keys through which chunks refer to people
+var ReferenceKeys = []string{
+	types.EMKeyAuthorPersonID,
+	types.EMKeyTaskAssigneePersonID,
+	types.EMKeyTaskCreatorPersonID,
+}
+
+// Reference returns the person a chunk refers to under key, if any
+func Reference(chunk *types.ConversationChunk, key string) string {
+	if chunk.Metadata.ExtendedMetadata == nil {
+		return ""
+	}
+	id, _ := chunk.Metadata.ExtendedMetadata[key].(string)
+	return id
+}
+
+// CanonicalizeReferences points a chunk's references to merged people at
+// the person they were merged into, and reports whether any changed
+func (s *Store) CanonicalizeReferences(chunk *types.ConversationChunk) bool {
+	changed := false
+	for _, key := range ReferenceKeys {
+		id := Reference(chunk, key)
+		if id == "" {
+			continue
+		}
+		if canonical := s.Canonical(id); canonical != id {
+			chunk.Metadata.ExtendedMetadata[key] = canonical
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Contribution sums what one person did across stored memories
+type Contribution struct {
+	PersonID    string `json:"person_id"`
+	DisplayName string `json:"display_name"`
+
+	ChunksAuthored        int                      `json:"chunks_authored"`
+	AuthoredByType        map[types.ChunkType]int  `json:"authored_by_type,omitempty"`
+	TasksAssigned         int                      `json:"tasks_assigned"`
+	TasksAssignedByStatus map[types.TaskStatus]int `json:"tasks_assigned_by_status,omitempty"`
+	TasksCreated          int                      `json:"tasks_created"`
+	Repositories          []string                 `json:"repositories,omitempty"`
+
+	FirstActivity time.Time `json:"first_activity"`
+	LastActivity  time.Time `json:"last_activity"`
+}
+
+// Total is the number of memories the person authored, created or was assigned
+func (c *Contribution) Total() int {
+	return c.ChunksAuthored + c.TasksAssigned + c.TasksCreated
+}
+
+// Contributions tallies chunks by the people they refer to. References to
+// merged people count toward the person they were merged into. People
+// without contributions are left out; the result is sorted by Total,
+// highest first.
+func (s *Store) Contributions(chunks []types.ConversationChunk) []Contribution {
+	byPerson := make(map[string]*Contribution)
+	repositories := make(map[string]map[string]bool)
+
+	contribution := func(id string) *Contribution {
+		id = s.Canonical(id)
+		c, ok := byPerson[id]
+		if !ok {
+			c = &Contribution{PersonID: id}
+			if person, found := s.Get(id); found {
+				c.DisplayName = person.DisplayName
+			}
+			byPerson[id] = c
+			repositories[id] = make(map[string]bool)
+		}
+		return c
+	}
+
+	for i := range chunks {
+		chunk := &chunks[i]
+		counted := make(map[string]bool, len(ReferenceKeys))
+		for _, key := range ReferenceKeys {
+			id := Reference(chunk, key)
+			if id == "" {
+				continue
+			}
+			c := contribution(id)
+			switch key {
+			case types.EMKeyAuthorPersonID:
+				c.ChunksAuthored++
+				if c.AuthoredByType == nil {
+					c.AuthoredByType = make(map[types.ChunkType]int)
+				}
+				c.AuthoredByType[chunk.Type]++
+			case types.EMKeyTaskAssigneePersonID:
+				c.TasksAssigned++
+				if chunk.Metadata.TaskStatus != nil {
+					if c.TasksAssignedByStatus == nil {
+						c.TasksAssignedByStatus = make(map[types.TaskStatus]int)
+					}
+					c.TasksAssignedByStatus[*chunk.Metadata.TaskStatus]++
+				}
+			case types.EMKeyTaskCreatorPersonID:
+				c.TasksCreated++
+			}
+
+			// A person who both created and is assigned a task was active once
+			if counted[c.PersonID] {
+				continue
+			}
+			counted[c.PersonID] = true
+			if chunk.Metadata.Repository != "" {
+				repositories[c.PersonID][chunk.Metadata.Repository] = true
+			}
+			if c.FirstActivity.IsZero() || chunk.Timestamp.Before(c.FirstActivity) {
+				c.FirstActivity = chunk.Timestamp
+			}
+			if chunk.Timestamp.After(c.LastActivity) {
+				c.LastActivity = chunk.Timestamp
+			}
+		}
+	}
+
+	result := make([]Contribution, 0, len(byPerson))
+	for id, c := range byPerson {
+		for repository := range repositories[id] {
+			c.Repositories = append(c.Repositories, repository)
+		}
+		sort.Strings(c.Repositories)
+		result = append(result, *c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Total() != result[j].Total() {
+			return result[i].Total() > result[j].Total()
+		}
+		return result[i].PersonID < result[j].PersonID
+	})
+	return result
+}
@@ -0,0 +1,487 @@
+// Package people keeps a directory of the people behind memories: who wrote
+// a chunk, who created or is assigned a task, and who acted in an audit
+// event. Identities captured from different sources ("Jane Doe",
+// "jdoe", "Jane Doe <jane@example.com>") resolve to one person, and
+// duplicates can be merged.
+package people
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// idPrefix marks person IDs so they are told apart from free-text identities
+const idPrefix = "person_"
+
+// Person is one identity in the directory. Emails are kept only as hashes.
+type Person struct {
+	ID          string   `json:"id"`
+	DisplayName string   `json:"display_name"`
+	Aliases     []string `json:"aliases,omitempty"`
+	EmailHash   string   `json:"email_hash,omitempty"`
+	// MergedInto names the person this duplicate was merged into
+	MergedInto string `json:"merged_into,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Merged reports whether the person was merged into another
+func (p *Person) Merged() bool {
+	return p.MergedInto != ""
+}
+
+// HashEmail returns the SHA-256 of a normalized email address
+func HashEmail(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseIdentity splits a free-text identity, such as a git author
+// "Jane Doe <jane@example.com>", into a name and an email. Either may be empty.
+func ParseIdentity(identity string) (name, email string) {
+	identity = strings.TrimSpace(identity)
+	if address, err := mail.ParseAddress(identity); err == nil {
+		return strings.TrimSpace(address.Name), address.Address
+	}
+	if strings.Contains(identity, "@") && !strings.ContainsAny(identity, " <>") {
+		return "", identity
+	}
+	return identity, ""
+}
+
+// normalizeName folds a name or alias for matching
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// Store is the people directory. It is persisted to an optional JSON file so
+// it survives restarts.
+type Store struct {
+	mu     sync.RWMutex
+	path   string
+	people map[string]Person // id -> person
+}
+
+// NewStore creates a directory persisted at path; an empty path keeps it in memory
+func NewStore(path string) *Store {
+	return &Store{
+		path:   path,
+		people: make(map[string]Person),
+	}
+}
+
+// Load reads the directory from disk. A missing file is not an error.
+func (s *Store) Load() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read people directory: %w", err)
+	}
+
+	var people []Person
+	if err := json.Unmarshal(data, &people); err != nil {
+		return fmt.Errorf("failed to parse people directory: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range people {
+		s.people[people[i].ID] = people[i]
+	}
+	return nil
+}
+
+// Upsert creates a person, or updates the one with the same ID. email, when
+// set, is stored as its hash. A name, alias or email already used by another
+// person is rejected; merge the two instead.
+func (s *Store) Upsert(person Person, email string) (*Person, error) {
+	person.DisplayName = strings.TrimSpace(person.DisplayName)
+	if person.DisplayName == "" {
+		return nil, errors.New("display_name is required for a person")
+	}
+	person.Aliases = cleanAliases(person.DisplayName, person.Aliases)
+	if email != "" {
+		if _, err := mail.ParseAddress(email); err != nil {
+			return nil, fmt.Errorf("invalid email %q", email)
+		}
+		person.EmailHash = HashEmail(email)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	if person.ID == "" {
+		person.ID = idPrefix + uuid.New().String()
+		person.CreatedAt = now
+	} else {
+		previous, ok := s.people[person.ID]
+		if !ok {
+			return nil, fmt.Errorf("person %q not found", person.ID)
+		}
+		if previous.Merged() {
+			return nil, fmt.Errorf("person %q was merged into %q; update that person instead", person.ID, previous.MergedInto)
+		}
+		person.CreatedAt = previous.CreatedAt
+		if person.EmailHash == "" {
+			person.EmailHash = previous.EmailHash
+		}
+	}
+	person.MergedInto = ""
+	person.UpdatedAt = now
+
+	if other := s.conflictLocked(&person); other != nil {
+		return nil, fmt.Errorf("%q already identifies %s (%s); merge the two people instead", other.name, other.person.DisplayName, other.person.ID)
+	}
+
+	snapshot := s.snapshotLocked()
+	s.people[person.ID] = person
+	if err := s.persistLocked(); err != nil {
+		s.people = snapshot
+		return nil, err
+	}
+	return &person, nil
+}
+
+// Get returns a person by ID, including people merged into another
+func (s *Store) Get(id string) (Person, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	person, ok := s.people[id]
+	return person, ok
+}
+
+// Canonical returns the ID a person's references should use: the person it
+// was merged into, if any, else the ID itself
+func (s *Store) Canonical(id string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.canonicalLocked(id)
+}
+
+// Resolve finds the person behind an identity: a person ID, a display name,
+// an alias, an email, or a "Name <email>" address. Merged people resolve to
+// the person they were merged into.
+func (s *Store) Resolve(identity string) (Person, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.resolveLocked(identity)
+}
+
+// Ensure resolves an identity and registers a new person when none matches.
+// Names and emails learned from the identity are added to a matched person.
+func (s *Store) Ensure(identity string) (*Person, error) {
+	name, email := ParseIdentity(identity)
+	if name == "" && email == "" {
+		return nil, errors.New("identity is empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := s.snapshotLocked()
+	now := time.Now().UTC()
+	person, found := s.resolveLocked(identity)
+	switch {
+	case !found:
+		person = Person{ID: idPrefix + uuid.New().String(), DisplayName: name, CreatedAt: now, UpdatedAt: now}
+		if name == "" {
+			// Never show an address that is only kept hashed
+			person.DisplayName = strings.SplitN(email, "@", 2)[0]
+		}
+		if email != "" {
+			person.EmailHash = HashEmail(email)
+		}
+	case !s.learnLocked(&person, name, email):
+		return &person, nil
+	default:
+		person.UpdatedAt = now
+	}
+
+	s.people[person.ID] = person
+	if err := s.persistLocked(); err != nil {
+		s.people = snapshot
+		return nil, err
+	}
+	return &person, nil
+}
+
+// List returns the directory sorted by display name. Merged people are left
+// out unless includeMerged is set.
+func (s *Store) List(includeMerged bool) []Person {
+	s.mu.RLock()
+	people := make([]Person, 0, len(s.people))
+	for id := range s.people {
+		if includeMerged || s.people[id].MergedInto == "" {
+			people = append(people, s.people[id])
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(people, func(i, j int) bool {
+		if people[i].DisplayName != people[j].DisplayName {
+			return people[i].DisplayName < people[j].DisplayName
+		}
+		return people[i].ID < people[j].ID
+	})
+	return people
+}
+
+// Merge folds duplicate identities into one person. The sources keep their
+// records, marked as merged, so references to them still resolve; their
+// names become aliases of the target. It returns the updated target.
+func (s *Store) Merge(sourceIDs []string, targetID string) (*Person, error) {
+	if len(sourceIDs) == 0 {
+		return nil, errors.New("at least one person to merge is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target, ok := s.people[targetID]
+	if !ok {
+		return nil, fmt.Errorf("person %q not found", targetID)
+	}
+	if target.Merged() {
+		return nil, fmt.Errorf("person %q was merged into %q; merge into that person instead", targetID, target.MergedInto)
+	}
+
+	snapshot := s.snapshotLocked()
+	now := time.Now().UTC()
+	for _, sourceID := range sourceIDs {
+		source, ok := s.people[sourceID]
+		if !ok {
+			s.people = snapshot
+			return nil, fmt.Errorf("person %q not found", sourceID)
+		}
+		if sourceID == targetID || source.MergedInto == targetID {
+			continue
+		}
+		if source.Merged() {
+			s.people = snapshot
+			return nil, fmt.Errorf("person %q was already merged into %q", sourceID, source.MergedInto)
+		}
+
+		target.Aliases = append(target.Aliases, source.DisplayName)
+		target.Aliases = append(target.Aliases, source.Aliases...)
+		if target.EmailHash == "" {
+			target.EmailHash = source.EmailHash
+		}
+		source.MergedInto = targetID
+		source.UpdatedAt = now
+		s.people[sourceID] = source
+
+		// Keep merge chains one level deep
+		for id, person := range s.people {
+			if person.MergedInto == sourceID {
+				person.MergedInto = targetID
+				s.people[id] = person
+			}
+		}
+	}
+	target.Aliases = cleanAliases(target.DisplayName, target.Aliases)
+	target.UpdatedAt = now
+	s.people[targetID] = target
+
+	if err := s.persistLocked(); err != nil {
+		s.people = snapshot
+		return nil, err
+	}
+	return &target, nil
+}
+
+// MergedIDs returns a person's ID followed by the IDs merged into it
+func (s *Store) MergedIDs(id string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := []string{id}
+	for other, person := range s.people {
+		if person.MergedInto == id {
+			ids = append(ids, other)
+		}
+	}
+	sort.Strings(ids[1:])
+	return ids
+}
+
+// canonicalLocked follows merges from id; callers must hold the lock
+func (s *Store) canonicalLocked(id string) string {
+	for range len(s.people) {
+		person, ok := s.people[id]
+		if !ok || !person.Merged() {
+			break
+		}
+		id = person.MergedInto
+	}
+	return id
+}
+
+// resolveLocked finds the active person behind an identity; callers must hold the lock
+func (s *Store) resolveLocked(identity string) (Person, bool) {
+	identity = strings.TrimSpace(identity)
+	if person, ok := s.people[identity]; ok {
+		person, ok = s.people[s.canonicalLocked(person.ID)]
+		return person, ok
+	}
+
+	name, email := ParseIdentity(identity)
+	emailHash := ""
+	if email != "" {
+		emailHash = HashEmail(email)
+	}
+	key := normalizeName(name)
+
+	// An email is stronger evidence than a name, which people may share
+	var byName *Person
+	for id := range s.people {
+		person := s.people[id]
+		if emailHash != "" && person.EmailHash == emailHash {
+			canonical, ok := s.people[s.canonicalLocked(id)]
+			return canonical, ok
+		}
+		if key != "" && byName == nil && person.hasName(key) {
+			byName = &person
+		}
+	}
+	if byName == nil {
+		return Person{}, false
+	}
+	canonical, ok := s.people[s.canonicalLocked(byName.ID)]
+	return canonical, ok
+}
+
+// learnLocked adds a name or email seen for a person when no one else
+// claims it, and reports whether the person changed; callers must hold the
+// write lock
+func (s *Store) learnLocked(person *Person, name, email string) bool {
+	changed := false
+	if key := normalizeName(name); key != "" && !person.hasName(key) {
+		if _, taken := s.resolveLocked(name); !taken {
+			person.Aliases = cleanAliases(person.DisplayName, append(person.Aliases, name))
+			changed = true
+		}
+	}
+	if email != "" && person.EmailHash == "" {
+		if _, taken := s.resolveLocked("<" + email + ">"); !taken {
+			person.EmailHash = HashEmail(email)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// identityConflict names the person already using an identity
+type identityConflict struct {
+	name   string
+	person Person
+}
+
+// conflictLocked returns another active person already identified by one of
+// person's names or its email; callers must hold the lock
+func (s *Store) conflictLocked(person *Person) *identityConflict {
+	names := append([]string{person.DisplayName}, person.Aliases...)
+	for id := range s.people {
+		other := s.people[id]
+		if id == person.ID || other.Merged() {
+			continue
+		}
+		for _, name := range names {
+			if other.hasName(normalizeName(name)) {
+				return &identityConflict{name: name, person: other}
+			}
+		}
+		if person.EmailHash != "" && other.EmailHash == person.EmailHash {
+			return &identityConflict{name: "email", person: other}
+		}
+	}
+	return nil
+}
+
+// hasName reports whether a normalized name is the person's display name or an alias
+func (p *Person) hasName(key string) bool {
+	if normalizeName(p.DisplayName) == key {
+		return true
+	}
+	for _, alias := range p.Aliases {
+		if normalizeName(alias) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanAliases trims and de-duplicates aliases, dropping the display name
+func cleanAliases(displayName string, aliases []string) []string {
+	seen := map[string]bool{normalizeName(displayName): true}
+	cleaned := make([]string, 0, len(aliases))
+	for _, alias := range aliases {
+		alias = strings.TrimSpace(alias)
+		key := normalizeName(alias)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		cleaned = append(cleaned, alias)
+	}
+	if len(cleaned) == 0 {
+		return nil
+	}
+	return cleaned
+}
+
+// snapshotLocked copies the directory so a failed write can be undone; callers must hold the lock
+func (s *Store) snapshotLocked() map[string]Person {
+	snapshot := make(map[string]Person, len(s.people))
+	for id := range s.people {
+		snapshot[id] = s.people[id]
+	}
+	return snapshot
+}
+
+// persistLocked writes the directory to disk; callers must hold the write lock
+func (s *Store) persistLocked() error {
+	if s.path == "" {
+		return nil
+	}
+
+	people := make([]Person, 0, len(s.people))
+	for id := range s.people {
+		people = append(people, s.people[id])
+	}
+	sort.Slice(people, func(i, j int) bool { return people[i].ID < people[j].ID })
+
+	data, err := json.MarshalIndent(people, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode people directory: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o750); err != nil {
+		return fmt.Errorf("failed to create people directory folder: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write people directory: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,176 @@
+package people
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIdentity(t *testing.T) {
+	for identity, want := range map[string][2]string{
+		"Jane Doe <jane@example.com>": {"Jane Doe", "jane@example.com"},
+		"jane@example.com":            {"", "jane@example.com"},
+		"  jdoe ":                     {"jdoe", ""},
+		"Jane Doe":                    {"Jane Doe", ""},
+	} {
+		name, email := ParseIdentity(identity)
+		assert.Equal(t, want, [2]string{name, email}, identity)
+	}
+}
+
+func TestStoreEnsureResolvesIdentities(t *testing.T) {
+	store := NewStore("")
+
+	jane, err := store.Ensure("Jane Doe <Jane@Example.com>")
+	require.NoError(t, err)
+	assert.Equal(t, "Jane Doe", jane.DisplayName)
+	assert.Equal(t, HashEmail("jane@example.com"), jane.EmailHash)
+
+	// The same email under another name is the same person, who learns the name
+	again, err := store.Ensure("J. Doe <jane@example.com>")
+	require.NoError(t, err)
+	assert.Equal(t, jane.ID, again.ID)
+	assert.Equal(t, []string{"J. Doe"}, again.Aliases)
+
+	for _, identity := range []string{jane.ID, "jane doe", "j. doe", "jane@example.com"} {
+		person, ok := store.Resolve(identity)
+		require.True(t, ok, identity)
+		assert.Equal(t, jane.ID, person.ID, identity)
+	}
+
+	// An email alone never becomes the visible name
+	bob, err := store.Ensure("bob@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "bob", bob.DisplayName)
+	assert.NotEqual(t, jane.ID, bob.ID)
+
+	_, err = store.Ensure("  ")
+	assert.Error(t, err)
+}
+
+func TestStoreUpsertRejectsTakenIdentities(t *testing.T) {
+	store := NewStore("")
+
+	jane, err := store.Upsert(Person{DisplayName: "Jane Doe", Aliases: []string{"jdoe", "JDoe", "Jane Doe"}}, "jane@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jdoe"}, jane.Aliases)
+
+	_, err = store.Upsert(Person{DisplayName: "Someone", Aliases: []string{"JDOE"}}, "")
+	assert.ErrorContains(t, err, "merge the two people")
+	_, err = store.Upsert(Person{DisplayName: "Someone"}, "jane@example.com")
+	assert.ErrorContains(t, err, "merge the two people")
+
+	jane.DisplayName = "Jane D."
+	updated, err := store.Upsert(*jane, "")
+	require.NoError(t, err)
+	assert.Equal(t, jane.CreatedAt, updated.CreatedAt)
+	assert.Equal(t, HashEmail("jane@example.com"), updated.EmailHash, "email is kept when not given")
+
+	_, err = store.Upsert(Person{ID: "person_missing", DisplayName: "X"}, "")
+	assert.Error(t, err)
+	_, err = store.Upsert(Person{DisplayName: "X"}, "not an email")
+	assert.Error(t, err)
+}
+
+func TestStoreMerge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "people.json")
+	store := NewStore(path)
+
+	jane, err := store.Upsert(Person{DisplayName: "Jane Doe"}, "")
+	require.NoError(t, err)
+	jdoe, err := store.Ensure("jdoe <jd@work.example>")
+	require.NoError(t, err)
+	janie, err := store.Ensure("Janie")
+	require.NoError(t, err)
+
+	// Merge janie into jdoe, then jdoe into jane: chains collapse to jane
+	_, err = store.Merge([]string{janie.ID}, jdoe.ID)
+	require.NoError(t, err)
+	merged, err := store.Merge([]string{jdoe.ID}, jane.ID)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"jdoe", "Janie"}, merged.Aliases)
+	assert.Equal(t, HashEmail("jd@work.example"), merged.EmailHash)
+	assert.Equal(t, jane.ID, store.Canonical(janie.ID))
+	record, ok := store.Get(janie.ID)
+	require.True(t, ok)
+	assert.Equal(t, jane.ID, record.MergedInto)
+	assert.Equal(t, jane.ID, store.MergedIDs(jane.ID)[0])
+	assert.Len(t, store.MergedIDs(jane.ID), 3)
+
+	person, ok := store.Resolve("janie")
+	require.True(t, ok)
+	assert.Equal(t, jane.ID, person.ID)
+	assert.Len(t, store.List(false), 1)
+	assert.Len(t, store.List(true), 3)
+
+	_, err = store.Merge([]string{jane.ID}, jdoe.ID)
+	assert.ErrorContains(t, err, "merge into that person instead")
+	_, err = store.Merge(nil, jane.ID)
+	assert.Error(t, err)
+
+	reloaded := NewStore(path)
+	require.NoError(t, reloaded.Load())
+	assert.Equal(t, jane.ID, reloaded.Canonical(jdoe.ID))
+	assert.Len(t, reloaded.List(true), 3)
+}
+
+func TestStoreContributions(t *testing.T) {
+	store := NewStore("")
+	jane, err := store.Ensure("Jane Doe")
+	require.NoError(t, err)
+	jdoe, err := store.Ensure("jdoe")
+	require.NoError(t, err)
+	bob, err := store.Ensure("Bob")
+	require.NoError(t, err)
+
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	todo := types.TaskStatusTodo
+	chunk := func(id string, chunkType types.ChunkType, age time.Duration, refs map[string]interface{}) types.ConversationChunk {
+		return types.ConversationChunk{
+			ID:        id,
+			Type:      chunkType,
+			Timestamp: now.Add(-age),
+			Metadata:  types.ChunkMetadata{Repository: "github.com/acme/api", ExtendedMetadata: refs},
+		}
+	}
+	chunks := []types.ConversationChunk{
+		chunk("a", types.ChunkTypeSolution, time.Hour, map[string]interface{}{types.EMKeyAuthorPersonID: jane.ID}),
+		chunk("b", types.ChunkTypeProblem, 48*time.Hour, map[string]interface{}{types.EMKeyAuthorPersonID: jdoe.ID}),
+		chunk("c", types.ChunkTypeTask, 24*time.Hour, map[string]interface{}{
+			types.EMKeyTaskAssigneePersonID: jane.ID,
+			types.EMKeyTaskCreatorPersonID:  bob.ID,
+		}),
+		chunk("d", types.ChunkTypeSolution, 0, nil),
+	}
+	chunks[2].Metadata.TaskStatus = &todo
+
+	_, err = store.Merge([]string{jdoe.ID}, jane.ID)
+	require.NoError(t, err)
+	assert.True(t, store.CanonicalizeReferences(&chunks[1]))
+	assert.Equal(t, jane.ID, Reference(&chunks[1], types.EMKeyAuthorPersonID))
+	assert.False(t, store.CanonicalizeReferences(&chunks[0]))
+	chunks[1].Metadata.ExtendedMetadata[types.EMKeyAuthorPersonID] = jdoe.ID
+
+	contributions := store.Contributions(chunks)
+	require.Len(t, contributions, 2)
+
+	top := contributions[0]
+	assert.Equal(t, jane.ID, top.PersonID)
+	assert.Equal(t, "Jane Doe", top.DisplayName)
+	assert.Equal(t, 2, top.ChunksAuthored, "references to merged people count toward the target")
+	assert.Equal(t, map[types.ChunkType]int{types.ChunkTypeSolution: 1, types.ChunkTypeProblem: 1}, top.AuthoredByType)
+	assert.Equal(t, 1, top.TasksAssigned)
+	assert.Equal(t, map[types.TaskStatus]int{types.TaskStatusTodo: 1}, top.TasksAssignedByStatus)
+	assert.Equal(t, now.Add(-48*time.Hour), top.FirstActivity)
+	assert.Equal(t, now.Add(-time.Hour), top.LastActivity)
+	assert.Equal(t, []string{"github.com/acme/api"}, top.Repositories)
+
+	assert.Equal(t, bob.ID, contributions[1].PersonID)
+	assert.Equal(t, 1, contributions[1].TasksCreated)
+}
@@ -16,6 +16,7 @@ import (
 	"strings"
 	"time"
 
+	"lerian-mcp-memory/internal/storage"
 	"lerian-mcp-memory/pkg/types"
 )
 
@@ -94,7 +95,7 @@ func (bm *BackupManager) prepareBackupFile(repository string) (string, error) {
 
 // getChunksForBackup retrieves and filters chunks for backup
 func (bm *BackupManager) getChunksForBackup(ctx context.Context, repository string) ([]types.ConversationChunk, error) {
-	chunks, err := bm.storage.GetAllChunks(ctx)
+	chunks, err := bm.storage.GetAllChunks(storage.WithDeleted(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve chunks: %w", err)
 	}
@@ -431,7 +432,7 @@ func (bm *BackupManager) MigrateData(ctx context.Context, fromVersion, toVersion
 	}
 
 	// Get all chunks
-	chunks, err := bm.storage.GetAllChunks(ctx)
+	chunks, err := bm.storage.GetAllChunks(storage.WithDeleted(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to retrieve chunks for migration: %w", err)
 	}
@@ -485,7 +486,7 @@ func (bm *BackupManager) migrateChunk(chunk *types.ConversationChunk, fromVersio
 
 // VerifyIntegrity checks data integrity
 func (bm *BackupManager) VerifyIntegrity(ctx context.Context) error {
-	chunks, err := bm.storage.GetAllChunks(ctx)
+	chunks, err := bm.storage.GetAllChunks(storage.WithDeleted(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to retrieve chunks: %w", err)
 	}
@@ -515,7 +516,7 @@ func (bm *BackupManager) VerifyIntegrity(ctx context.Context) error {
 // CompressData implements data compression for storage efficiency
 func (bm *BackupManager) CompressData(ctx context.Context) error {
 	// Get all chunks
-	chunks, err := bm.storage.GetAllChunks(ctx)
+	chunks, err := bm.storage.GetAllChunks(storage.WithDeleted(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to retrieve chunks: %w", err)
 	}
@@ -0,0 +1,573 @@
+package persistence
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/google/uuid"
+)
+
+const (
+	snapshotFileSuffix     = ".json.gz"
+	snapshotMetadataSuffix = ".meta.json"
+
+	// snapshotBatchSize is the number of chunks stored or deleted per call during a restore
+	snapshotBatchSize = 100
+
+	// maxRelationshipsPerChunk bounds the outgoing relationships captured per chunk
+	maxRelationshipsPerChunk = 10000
+)
+
+// snapshotIDPattern matches generated snapshot IDs, which double as file names
+var snapshotIDPattern = regexp.MustCompile(`^snap_\d{8}_\d{6}_[0-9a-f]{8}$`)
+
+// ErrSnapshotNotFound is returned when no snapshot has the requested ID
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// SnapshotStorage is the storage a snapshot captures and restores
+type SnapshotStorage interface {
+	GetAllChunks(ctx context.Context) ([]types.ConversationChunk, error)
+	BatchStore(ctx context.Context, chunks []*types.ConversationChunk) (*storage.BatchResult, error)
+	BatchDelete(ctx context.Context, ids []string) (*storage.BatchResult, error)
+	GetRelationships(ctx context.Context, query *types.RelationshipQuery) ([]types.RelationshipResult, error)
+	StoreRelationship(ctx context.Context, sourceID, targetID string, relationType types.RelationType, confidence float64, source types.ConfidenceSource) (*types.MemoryRelationship, error)
+	DeleteRelationship(ctx context.Context, relationshipID string) error
+}
+
+// WriteQueue is a write path that buffers changes, such as the ingestion
+// queue. Quiesce drains it and holds further queued writes back while fn runs.
+type WriteQueue interface {
+	Quiesce(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// SnapshotRetention limits how many snapshots are kept. Zero disables a limit.
+type SnapshotRetention struct {
+	MaxCount   int `json:"max_count"`
+	MaxAgeDays int `json:"max_age_days"`
+}
+
+// SnapshotMetadata describes a stored snapshot
+type SnapshotMetadata struct {
+	ID                string    `json:"id"`
+	Label             string    `json:"label,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	ChunkCount        int       `json:"chunk_count"`
+	RelationshipCount int       `json:"relationship_count"`
+	Size              int64     `json:"size_bytes"`
+	Checksum          string    `json:"checksum"`
+}
+
+// RestoreResult summarizes a point-in-time restore
+type RestoreResult struct {
+	SnapshotID            string `json:"snapshot_id"`
+	SafetySnapshotID      string `json:"safety_snapshot_id,omitempty"`
+	ChunksRestored        int    `json:"chunks_restored"`
+	ChunksDeleted         int    `json:"chunks_deleted"`
+	RelationshipsRestored int    `json:"relationships_restored"`
+	RelationshipsDeleted  int    `json:"relationships_deleted"`
+}
+
+// snapshotData is the content of a snapshot file
+type snapshotData struct {
+	ID            string                     `json:"id"`
+	CreatedAt     time.Time                  `json:"created_at"`
+	Chunks        []types.ConversationChunk  `json:"chunks"`
+	Relationships []types.MemoryRelationship `json:"relationships"`
+}
+
+// SnapshotManager captures the whole memory state (chunks and their
+// relationships) and restores it to that point in time. When a write queue
+// is set, it is drained first and held back while the store is read or
+// rewritten, so a snapshot never contains half of a queued batch.
+type SnapshotManager struct {
+	storage   SnapshotStorage
+	queue     WriteQueue
+	dir       string
+	retention SnapshotRetention
+
+	// mutex serializes snapshot operations
+	mutex sync.Mutex
+}
+
+// NewSnapshotManager creates a snapshot manager writing to dir
+func NewSnapshotManager(storage SnapshotStorage, dir string) *SnapshotManager {
+	return &SnapshotManager{
+		storage: storage,
+		dir:     dir,
+		retention: SnapshotRetention{
+			MaxCount:   getEnvInt("MCP_MEMORY_SNAPSHOT_RETENTION_COUNT", 10),
+			MaxAgeDays: getEnvInt("MCP_MEMORY_SNAPSHOT_RETENTION_DAYS", 30),
+		},
+	}
+}
+
+// SetWriteQueue coordinates snapshots with a buffered write path
+func (sm *SnapshotManager) SetWriteQueue(queue WriteQueue) {
+	sm.queue = queue
+}
+
+// SetRetention replaces the retention policy
+func (sm *SnapshotManager) SetRetention(retention SnapshotRetention) {
+	sm.retention = retention
+}
+
+// Retention returns the retention policy
+func (sm *SnapshotManager) Retention() SnapshotRetention {
+	return sm.retention
+}
+
+// Create takes a snapshot, then applies the retention policy. It returns the
+// new snapshot and the IDs of snapshots removed by retention.
+func (sm *SnapshotManager) Create(ctx context.Context, label string) (*SnapshotMetadata, []string, error) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	var metadata *SnapshotMetadata
+	err := sm.withConsistentStore(ctx, func(ctx context.Context, store SnapshotStorage) error {
+		var err error
+		metadata, err = sm.create(ctx, store, label)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	removed, err := sm.applyRetention(metadata.ID)
+	if err != nil {
+		return metadata, removed, fmt.Errorf("snapshot %s created, but retention failed: %w", metadata.ID, err)
+	}
+	return metadata, removed, nil
+}
+
+// List returns stored snapshots, newest first
+func (sm *SnapshotManager) List() ([]SnapshotMetadata, error) {
+	entries, err := os.ReadDir(sm.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []SnapshotMetadata{}, nil
+		}
+		return nil, fmt.Errorf("failed to read snapshot directory: %w", err)
+	}
+
+	snapshots := make([]SnapshotMetadata, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), snapshotFileSuffix+snapshotMetadataSuffix) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(sm.dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var metadata SnapshotMetadata
+		if err := json.Unmarshal(data, &metadata); err != nil {
+			continue
+		}
+		snapshots = append(snapshots, metadata)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+	return snapshots, nil
+}
+
+// Delete removes a snapshot
+func (sm *SnapshotManager) Delete(id string) error {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	if _, err := sm.readMetadata(id); err != nil {
+		return err
+	}
+	return sm.remove(id)
+}
+
+// Restore returns the store to the state captured by a snapshot: chunks and
+// relationships created since are deleted, and changed or deleted ones are
+// put back. Unless safety is false, the current state is snapshotted first
+// so the restore itself can be undone.
+func (sm *SnapshotManager) Restore(ctx context.Context, id string, safety bool) (*RestoreResult, error) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	metadata, err := sm.readMetadata(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := sm.readSnapshot(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RestoreResult{SnapshotID: id}
+	err = sm.withConsistentStore(ctx, func(ctx context.Context, store SnapshotStorage) error {
+		if safety {
+			safetySnapshot, err := sm.create(ctx, store, "before restore of "+id)
+			if err != nil {
+				return fmt.Errorf("failed to create safety snapshot: %w", err)
+			}
+			result.SafetySnapshotID = safetySnapshot.ID
+		}
+		return sm.restore(ctx, store, data, result)
+	})
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// withConsistentStore runs fn against the manager's store with queued writes
+// drained and held back. The store is always the manager's own, so fn goes
+// through every layer above the queue, such as tenant isolation and indexes.
+func (sm *SnapshotManager) withConsistentStore(ctx context.Context, fn func(ctx context.Context, store SnapshotStorage) error) error {
+	if sm.queue == nil {
+		return fn(ctx, sm.storage)
+	}
+	return sm.queue.Quiesce(ctx, func(ctx context.Context) error {
+		return fn(ctx, sm.storage)
+	})
+}
+
+// create captures the store into a new snapshot file
+func (sm *SnapshotManager) create(ctx context.Context, store SnapshotStorage, label string) (*SnapshotMetadata, error) {
+	if err := os.MkdirAll(sm.dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	chunks, err := store.GetAllChunks(storage.WithDeleted(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chunks: %w", err)
+	}
+	relationships, err := outgoingRelationships(ctx, store, chunks)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	data := &snapshotData{
+		ID:            fmt.Sprintf("snap_%s_%s", now.Format("20060102_150405"), uuid.New().String()[:8]),
+		CreatedAt:     now,
+		Chunks:        chunks,
+		Relationships: relationships,
+	}
+
+	path := sm.snapshotPath(data.ID)
+	size, checksum, err := writeSnapshotFile(path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &SnapshotMetadata{
+		ID:                data.ID,
+		Label:             label,
+		CreatedAt:         now,
+		ChunkCount:        len(chunks),
+		RelationshipCount: len(relationships),
+		Size:              size,
+		Checksum:          checksum,
+	}
+	encoded, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal snapshot metadata: %w", err)
+	}
+	if err := os.WriteFile(path+snapshotMetadataSuffix, encoded, 0o600); err != nil {
+		_ = os.Remove(path)
+		return nil, fmt.Errorf("failed to write snapshot metadata: %w", err)
+	}
+	return metadata, nil
+}
+
+// restore rewrites the store to match a snapshot
+func (sm *SnapshotManager) restore(ctx context.Context, store SnapshotStorage, data *snapshotData, result *RestoreResult) error {
+	current, err := store.GetAllChunks(storage.WithDeleted(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to read chunks: %w", err)
+	}
+
+	wanted := make(map[string]bool, len(data.Chunks))
+	for i := range data.Chunks {
+		wanted[data.Chunks[i].ID] = true
+	}
+
+	// Delete chunks created after the snapshot, with their relationships
+	extra := make([]string, 0)
+	for i := range current {
+		if !wanted[current[i].ID] {
+			extra = append(extra, current[i].ID)
+		}
+	}
+	for _, id := range extra {
+		deleted, err := deleteRelationships(ctx, store, id, "both", nil)
+		result.RelationshipsDeleted += deleted
+		if err != nil {
+			return err
+		}
+	}
+	for start := 0; start < len(extra); start += snapshotBatchSize {
+		batch := extra[start:min(start+snapshotBatchSize, len(extra))]
+		if _, err := store.BatchDelete(ctx, batch); err != nil {
+			return fmt.Errorf("failed to delete chunks created after the snapshot: %w", err)
+		}
+		result.ChunksDeleted += len(batch)
+	}
+
+	// Put back every snapshot chunk; upserts overwrite later changes
+	for start := 0; start < len(data.Chunks); start += snapshotBatchSize {
+		end := min(start+snapshotBatchSize, len(data.Chunks))
+		batch := make([]*types.ConversationChunk, 0, end-start)
+		for i := start; i < end; i++ {
+			batch = append(batch, &data.Chunks[i])
+		}
+		if _, err := store.BatchStore(ctx, batch); err != nil {
+			return fmt.Errorf("failed to restore chunks: %w", err)
+		}
+		result.ChunksRestored += len(batch)
+	}
+
+	return sm.restoreRelationships(ctx, store, data, result)
+}
+
+// restoreRelationships makes each chunk's outgoing relationships match the
+// snapshot. Relationships are matched by target and type, since the store
+// assigns new IDs to recreated ones.
+func (sm *SnapshotManager) restoreRelationships(ctx context.Context, store SnapshotStorage, data *snapshotData, result *RestoreResult) error {
+	bySource := make(map[string][]types.MemoryRelationship)
+	for i := range data.Relationships {
+		rel := data.Relationships[i]
+		bySource[rel.SourceChunkID] = append(bySource[rel.SourceChunkID], rel)
+	}
+
+	for i := range data.Chunks {
+		chunkID := data.Chunks[i].ID
+		keep := make(map[string]bool)
+		for _, rel := range bySource[chunkID] {
+			keep[relationshipKey(&rel)] = true
+		}
+
+		existing := make(map[string]bool)
+		deleted, err := deleteRelationships(ctx, store, chunkID, "outgoing", func(rel *types.MemoryRelationship) bool {
+			key := relationshipKey(rel)
+			if keep[key] && !existing[key] {
+				existing[key] = true
+				return false
+			}
+			return true
+		})
+		result.RelationshipsDeleted += deleted
+		if err != nil {
+			return err
+		}
+
+		for _, rel := range bySource[chunkID] {
+			if existing[relationshipKey(&rel)] {
+				continue
+			}
+			if _, err := store.StoreRelationship(ctx, rel.SourceChunkID, rel.TargetChunkID, rel.RelationType, rel.Confidence, rel.ConfidenceSource); err != nil {
+				return fmt.Errorf("failed to restore relationship %s: %w", rel.ID, err)
+			}
+			existing[relationshipKey(&rel)] = true
+			result.RelationshipsRestored++
+		}
+	}
+	return nil
+}
+
+// applyRetention removes snapshots beyond the retention policy, never the one just created
+func (sm *SnapshotManager) applyRetention(keepID string) ([]string, error) {
+	snapshots, err := sm.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var cutoff time.Time
+	if sm.retention.MaxAgeDays > 0 {
+		cutoff = time.Now().AddDate(0, 0, -sm.retention.MaxAgeDays)
+	}
+
+	removed := make([]string, 0)
+	kept := 0
+	for i := range snapshots {
+		snapshot := &snapshots[i]
+		if snapshot.ID != keepID {
+			tooMany := sm.retention.MaxCount > 0 && kept >= sm.retention.MaxCount
+			tooOld := !cutoff.IsZero() && snapshot.CreatedAt.Before(cutoff)
+			if tooMany || tooOld {
+				if err := sm.remove(snapshot.ID); err != nil {
+					return removed, err
+				}
+				removed = append(removed, snapshot.ID)
+				continue
+			}
+		}
+		kept++
+	}
+	return removed, nil
+}
+
+// snapshotPath returns the file holding a snapshot
+func (sm *SnapshotManager) snapshotPath(id string) string {
+	return filepath.Join(sm.dir, id+snapshotFileSuffix)
+}
+
+// readMetadata loads a snapshot's metadata, rejecting IDs that are not snapshot IDs
+func (sm *SnapshotManager) readMetadata(id string) (*SnapshotMetadata, error) {
+	if !snapshotIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("%w: %q", ErrSnapshotNotFound, id)
+	}
+	data, err := os.ReadFile(sm.snapshotPath(id) + snapshotMetadataSuffix)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrSnapshotNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot metadata: %w", err)
+	}
+	var metadata SnapshotMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot metadata: %w", err)
+	}
+	return &metadata, nil
+}
+
+// readSnapshot loads a snapshot file after verifying its checksum
+func (sm *SnapshotManager) readSnapshot(metadata *SnapshotMetadata) (*snapshotData, error) {
+	raw, err := os.ReadFile(sm.snapshotPath(metadata.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	sum := sha256.Sum256(raw)
+	if hex.EncodeToString(sum[:]) != metadata.Checksum {
+		return nil, fmt.Errorf("snapshot %s is corrupted: checksum mismatch", metadata.ID)
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	var data snapshotData
+	if err := json.NewDecoder(reader).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	return &data, nil
+}
+
+// remove deletes a snapshot and its metadata
+func (sm *SnapshotManager) remove(id string) error {
+	path := sm.snapshotPath(id)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove snapshot %s: %w", id, err)
+	}
+	if err := os.Remove(path + snapshotMetadataSuffix); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove snapshot metadata %s: %w", id, err)
+	}
+	return nil
+}
+
+// writeSnapshotFile writes data gzipped to path, returning its size and SHA-256
+func writeSnapshotFile(path string, data *snapshotData) (int64, string, error) {
+	tmpPath := path + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+
+	hash := sha256.New()
+	gzWriter := gzip.NewWriter(io.MultiWriter(file, hash))
+	encodeErr := json.NewEncoder(gzWriter).Encode(data)
+	if err := gzWriter.Close(); encodeErr == nil {
+		encodeErr = err
+	}
+	if encodeErr == nil {
+		encodeErr = file.Sync()
+	}
+	if err := file.Close(); encodeErr == nil {
+		encodeErr = err
+	}
+	if encodeErr != nil {
+		_ = os.Remove(tmpPath)
+		return 0, "", fmt.Errorf("failed to write snapshot file: %w", encodeErr)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return 0, "", fmt.Errorf("failed to write snapshot file: %w", err)
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to stat snapshot file: %w", err)
+	}
+	return stat.Size(), hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// outgoingRelationships collects the outgoing relationships of every chunk,
+// which covers each relationship exactly once
+func outgoingRelationships(ctx context.Context, store SnapshotStorage, chunks []types.ConversationChunk) ([]types.MemoryRelationship, error) {
+	relationships := make([]types.MemoryRelationship, 0)
+	for i := range chunks {
+		results, err := store.GetRelationships(ctx, relationshipQuery(chunks[i].ID, "outgoing"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read relationships of chunk %s: %w", chunks[i].ID, err)
+		}
+		for j := range results {
+			relationships = append(relationships, results[j].Relationship)
+		}
+	}
+	return relationships, nil
+}
+
+// deleteRelationships deletes a chunk's relationships in direction that
+// match, or all of them when match is nil, returning how many were deleted
+func deleteRelationships(ctx context.Context, store SnapshotStorage, chunkID, direction string, match func(*types.MemoryRelationship) bool) (int, error) {
+	results, err := store.GetRelationships(ctx, relationshipQuery(chunkID, direction))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read relationships of chunk %s: %w", chunkID, err)
+	}
+
+	deleted := 0
+	for i := range results {
+		rel := &results[i].Relationship
+		if match != nil && !match(rel) {
+			continue
+		}
+		if err := store.DeleteRelationship(ctx, rel.ID); err != nil {
+			return deleted, fmt.Errorf("failed to delete relationship %s: %w", rel.ID, err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// relationshipQuery selects every relationship of a chunk in a direction
+func relationshipQuery(chunkID, direction string) *types.RelationshipQuery {
+	return &types.RelationshipQuery{
+		ChunkID:   chunkID,
+		Direction: direction,
+		MaxDepth:  1,
+		SortBy:    "created_at",
+		SortOrder: "asc",
+		Limit:     maxRelationshipsPerChunk,
+	}
+}
+
+// relationshipKey identifies a relationship by what it connects
+func relationshipKey(rel *types.MemoryRelationship) string {
+	return rel.TargetChunkID + "|" + string(rel.RelationType)
+}
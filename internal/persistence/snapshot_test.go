@@ -0,0 +1,178 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSnapshotChunk(id, content string) *types.ConversationChunk {
+	return &types.ConversationChunk{
+		ID:        id,
+		SessionID: "session",
+		Type:      types.ChunkTypeSolution,
+		Content:   content,
+		Timestamp: time.Now(),
+		Metadata: types.ChunkMetadata{
+			Repository: "github.com/acme/api",
+			Outcome:    types.OutcomeSuccess,
+			Difficulty: types.DifficultySimple,
+		},
+	}
+}
+
+func TestSnapshotManager_CreateAndRestore(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewKeywordStore("")
+	require.NoError(t, store.Store(ctx, newSnapshotChunk("a", "original a")))
+	require.NoError(t, store.Store(ctx, newSnapshotChunk("b", "original b")))
+	_, err := store.StoreRelationship(ctx, "a", "b", types.RelationLedTo, 0.9, types.ConfidenceExplicit)
+	require.NoError(t, err)
+
+	manager := NewSnapshotManager(store, t.TempDir())
+	snapshot, pruned, err := manager.Create(ctx, "baseline")
+	require.NoError(t, err)
+	assert.Empty(t, pruned)
+	assert.Equal(t, "baseline", snapshot.Label)
+	assert.Equal(t, 2, snapshot.ChunkCount)
+	assert.Equal(t, 1, snapshot.RelationshipCount)
+	assert.Positive(t, snapshot.Size)
+
+	// Change everything the snapshot covers
+	edited := newSnapshotChunk("a", "edited a")
+	require.NoError(t, store.Update(ctx, edited))
+	require.NoError(t, store.Delete(ctx, "b"))
+	require.NoError(t, store.Store(ctx, newSnapshotChunk("c", "created later")))
+	_, err = store.StoreRelationship(ctx, "a", "c", types.RelationDependsOn, 0.8, types.ConfidenceExplicit)
+	require.NoError(t, err)
+	rels, err := store.GetRelationships(ctx, relationshipQuery("a", "outgoing"))
+	require.NoError(t, err)
+	for i := range rels {
+		if rels[i].Relationship.TargetChunkID == "b" {
+			require.NoError(t, store.DeleteRelationship(ctx, rels[i].Relationship.ID))
+		}
+	}
+
+	result, err := manager.Restore(ctx, snapshot.ID, true)
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.SafetySnapshotID)
+	assert.Equal(t, 2, result.ChunksRestored)
+	assert.Equal(t, 1, result.ChunksDeleted)
+	assert.Equal(t, 1, result.RelationshipsRestored)
+	assert.Equal(t, 1, result.RelationshipsDeleted)
+
+	chunks, err := store.GetAllChunks(ctx)
+	require.NoError(t, err)
+	require.Len(t, chunks, 2)
+	restored, err := store.GetByID(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "original a", restored.Content)
+
+	rels, err = store.GetRelationships(ctx, relationshipQuery("a", "outgoing"))
+	require.NoError(t, err)
+	require.Len(t, rels, 1)
+	assert.Equal(t, "b", rels[0].Relationship.TargetChunkID)
+	assert.Equal(t, types.RelationLedTo, rels[0].Relationship.RelationType)
+
+	// The safety snapshot undoes the restore
+	_, err = manager.Restore(ctx, result.SafetySnapshotID, false)
+	require.NoError(t, err)
+	restored, err = store.GetByID(ctx, "c")
+	require.NoError(t, err)
+	assert.Equal(t, "created later", restored.Content)
+}
+
+func TestSnapshotManager_Retention(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewKeywordStore("")
+	require.NoError(t, store.Store(ctx, newSnapshotChunk("a", "content")))
+
+	manager := NewSnapshotManager(store, t.TempDir())
+	manager.SetRetention(SnapshotRetention{MaxCount: 2})
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		snapshot, _, err := manager.Create(ctx, "")
+		require.NoError(t, err)
+		ids = append(ids, snapshot.ID)
+		time.Sleep(time.Millisecond)
+	}
+
+	snapshots, err := manager.List()
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, ids[2], snapshots[0].ID)
+	assert.Equal(t, ids[1], snapshots[1].ID)
+
+	require.NoError(t, manager.Delete(ids[1]))
+	assert.ErrorIs(t, manager.Delete(ids[1]), ErrSnapshotNotFound)
+	_, err = manager.Restore(ctx, "../../etc/passwd", false)
+	assert.ErrorIs(t, err, ErrSnapshotNotFound)
+}
+
+func TestSnapshotManager_DetectsCorruption(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewKeywordStore("")
+	require.NoError(t, store.Store(ctx, newSnapshotChunk("a", "content")))
+
+	dir := t.TempDir()
+	manager := NewSnapshotManager(store, dir)
+	snapshot, _, err := manager.Create(ctx, "")
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, snapshot.ID+snapshotFileSuffix)
+	require.NoError(t, os.WriteFile(path, []byte("not a snapshot"), 0o600))
+	_, err = manager.Restore(ctx, snapshot.ID, false)
+	assert.ErrorContains(t, err, "checksum mismatch")
+}
+
+// recordingQueue records that snapshot work ran inside Quiesce
+type recordingQueue struct {
+	quiesced int
+	inside   bool
+}
+
+func (q *recordingQueue) Quiesce(ctx context.Context, fn func(ctx context.Context) error) error {
+	q.quiesced++
+	q.inside = true
+	defer func() { q.inside = false }()
+	return fn(ctx)
+}
+
+// quiescedStore fails reads made outside its queue's Quiesce
+type quiescedStore struct {
+	*storage.KeywordStore
+	queue *recordingQueue
+}
+
+func (s *quiescedStore) GetAllChunks(ctx context.Context) ([]types.ConversationChunk, error) {
+	if !s.queue.inside {
+		return nil, errors.New("read outside Quiesce")
+	}
+	return s.KeywordStore.GetAllChunks(ctx)
+}
+
+func TestSnapshotManager_UsesWriteQueue(t *testing.T) {
+	ctx := context.Background()
+	queue := &recordingQueue{}
+	store := &quiescedStore{KeywordStore: storage.NewKeywordStore(""), queue: queue}
+	require.NoError(t, store.Store(ctx, newSnapshotChunk("a", "content")))
+
+	// The queue is only a barrier: the manager's own store is read inside it
+	manager := NewSnapshotManager(store, t.TempDir())
+	manager.SetWriteQueue(queue)
+
+	snapshot, _, err := manager.Create(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, snapshot.ChunkCount)
+	assert.Equal(t, 1, queue.quiesced)
+}
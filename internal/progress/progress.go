@@ -0,0 +1,131 @@
+// Package progress lets long-running operations report how far along they are.
+// The reporter travels in the context, so code that reports progress does not
+// depend on the transport that delivers it to the client.
+package progress
+
+import (
+	"context"
+	"math"
+	"sync"
+)
+
+// MethodProgress is the MCP notification method for progress updates
+const MethodProgress = "notifications/progress"
+
+// minPercentageStep coalesces reports that barely move the percentage, so a
+// loop reporting every item does not flood the client
+const minPercentageStep = 1.0
+
+// Notification is the params of a notifications/progress message
+type Notification struct {
+	ProgressToken interface{} `json:"progressToken"`
+	Progress      float64     `json:"progress"`
+	Total         float64     `json:"total,omitempty"`
+	Percentage    float64     `json:"percentage,omitempty"`
+	Message       string      `json:"message,omitempty"`
+}
+
+// Reporter receives progress updates for one operation
+type Reporter interface {
+	// Report records that current of total units are done. A total of zero
+	// or less means the total is not known yet.
+	Report(current, total float64, message string)
+}
+
+type contextKey struct{}
+
+// WithReporter returns a context that carries r
+func WithReporter(ctx context.Context, r Reporter) context.Context {
+	return context.WithValue(ctx, contextKey{}, r)
+}
+
+// FromContext returns the reporter carried by ctx, or one that discards
+// reports when the caller did not ask for progress. It never returns nil.
+func FromContext(ctx context.Context) Reporter {
+	if r, ok := ctx.Value(contextKey{}).(Reporter); ok && r != nil {
+		return r
+	}
+	return noopReporter{}
+}
+
+// noopReporter discards reports
+type noopReporter struct{}
+
+func (noopReporter) Report(float64, float64, string) {}
+
+// Sender delivers a progress notification to the client
+type Sender func(n *Notification) error
+
+// TokenReporter reports progress as notifications for one progress token.
+// Progress only moves forward: reports behind the last one sent are dropped,
+// as are reports that change neither the message nor the percentage by a
+// whole point. It is safe for concurrent use.
+type TokenReporter struct {
+	token interface{}
+	send  Sender
+
+	mutex      sync.Mutex
+	sent       bool
+	last       float64
+	percentage float64
+	message    string
+	err        error
+}
+
+// NewTokenReporter creates a reporter that sends notifications for token
+func NewTokenReporter(token interface{}, send Sender) *TokenReporter {
+	return &TokenReporter{token: token, send: send}
+}
+
+// Report sends a notification unless it would not tell the client anything new
+func (r *TokenReporter) Report(current, total float64, message string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var percentage float64
+	if total > 0 {
+		percentage = math.Round(math.Min(math.Max(current/total, 0), 1)*1000) / 10
+	}
+
+	if r.sent {
+		if current < r.last {
+			return
+		}
+		done := total > 0 && current >= total
+		if message == r.message && !done && math.Abs(percentage-r.percentage) < minPercentageStep {
+			return
+		}
+		if message == r.message && current == r.last {
+			return
+		}
+	}
+
+	notification := &Notification{
+		ProgressToken: r.token,
+		Progress:      current,
+		Message:       message,
+	}
+	if total > 0 {
+		notification.Total = total
+		notification.Percentage = percentage
+	}
+	if err := r.send(notification); err != nil {
+		// Progress is advisory; keep the first failure for the caller to inspect
+		if r.err == nil {
+			r.err = err
+		}
+		return
+	}
+
+	r.sent = true
+	r.last = current
+	r.percentage = percentage
+	r.message = message
+}
+
+// Err returns the first error from sending a notification, if any
+func (r *TokenReporter) Err() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.err
+}
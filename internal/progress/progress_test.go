@@ -0,0 +1,72 @@
+package progress
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromContext(t *testing.T) {
+	// Without a reporter, reports are discarded
+	FromContext(context.Background()).Report(1, 2, "ignored")
+
+	var sent []*Notification
+	reporter := NewTokenReporter("tok", func(n *Notification) error {
+		sent = append(sent, n)
+		return nil
+	})
+	FromContext(WithReporter(context.Background(), reporter)).Report(1, 4, "working")
+
+	require.Len(t, sent, 1)
+	assert.Equal(t, &Notification{ProgressToken: "tok", Progress: 1, Total: 4, Percentage: 25, Message: "working"}, sent[0])
+}
+
+func TestTokenReporter_Coalesces(t *testing.T) {
+	var sent []*Notification
+	reporter := NewTokenReporter(7.0, func(n *Notification) error {
+		sent = append(sent, n)
+		return nil
+	})
+
+	for i := 0; i <= 1000; i++ {
+		reporter.Report(float64(i), 1000, "Processing")
+	}
+	// One notification per whole percentage point, plus the start
+	assert.Len(t, sent, 101)
+	assert.Equal(t, 100.0, sent[len(sent)-1].Percentage)
+
+	// Progress never goes backwards, and repeats are dropped
+	reporter.Report(500, 1000, "Processing")
+	reporter.Report(1000, 1000, "Processing")
+	assert.Len(t, sent, 101)
+
+	// A new message is always worth sending
+	reporter.Report(1000, 1000, "Done")
+	require.Len(t, sent, 102)
+	assert.Equal(t, "Done", sent[101].Message)
+
+	// Unknown totals report no percentage
+	unknown := NewTokenReporter("tok", func(n *Notification) error {
+		sent = append(sent, n)
+		return nil
+	})
+	unknown.Report(3, 0, "Scanning")
+	assert.Zero(t, sent[len(sent)-1].Total)
+	assert.Zero(t, sent[len(sent)-1].Percentage)
+}
+
+func TestTokenReporter_KeepsFirstError(t *testing.T) {
+	calls := 0
+	reporter := NewTokenReporter("tok", func(*Notification) error {
+		calls++
+		return errors.New("connection closed")
+	})
+
+	reporter.Report(1, 2, "half")
+	reporter.Report(1, 2, "half")
+	assert.Equal(t, 2, calls, "failed reports are retried on the next call")
+	assert.EqualError(t, reporter.Err(), "connection closed")
+}
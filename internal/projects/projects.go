@@ -0,0 +1,399 @@
+// Package projects keeps the registry of projects, the repositories memories
+// are filed under. Projects are created, renamed, archived and deleted
+// explicitly; the storage layer checks writes against the registry, so an
+// archived project takes no new memories and, when registration is
+// required, a repository nobody created takes none at all.
+package projects
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// maxIDLength bounds project IDs, which are repository names such as github.com/acme/api
+const maxIDLength = 256
+
+// SharedRepositories hold memories that belong to no single project. They
+// need no registration and cannot be registered.
+var SharedRepositories = []string{"global", "_global"}
+
+// Status is where a project is in its lifecycle
+type Status string
+
+// Project statuses
+const (
+	StatusActive   Status = "active"
+	StatusArchived Status = "archived"
+)
+
+// Errors returned for writes the registry refuses
+var (
+	ErrArchived     = errors.New("project is archived")
+	ErrRenamed      = errors.New("project was renamed")
+	ErrUnregistered = errors.New("project is not registered")
+)
+
+// Project is a registered project. Its ID is the repository its memories
+// name; the IDs it had before being renamed are kept so writes still using
+// them are refused with the new one instead of starting a stray project.
+type Project struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Status      Status   `json:"status"`
+	FormerIDs   []string `json:"former_ids,omitempty"`
+
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+}
+
+// Archived reports whether the project is archived
+func (p *Project) Archived() bool {
+	return p.Status == StatusArchived
+}
+
+// IsShared reports whether repository holds memories of no single project
+func IsShared(repository string) bool {
+	return slices.Contains(SharedRepositories, repository)
+}
+
+// ValidateID checks that id can name a project
+func ValidateID(id string) error {
+	switch {
+	case id == "":
+		return errors.New("project id is required")
+	case len(id) > maxIDLength:
+		return fmt.Errorf("project id must not exceed %d characters", maxIDLength)
+	case IsShared(id), id == "*":
+		return fmt.Errorf("project id %q is reserved", id)
+	case strings.IndexFunc(id, unicode.IsSpace) >= 0:
+		return fmt.Errorf("project id %q must not contain spaces", id)
+	}
+	return nil
+}
+
+type lifecycleKey struct{}
+
+// WithLifecycle returns a context for the writes a lifecycle operation makes
+// itself, such as moving the memories of a renamed project or trashing those
+// of a deleted one, which the registry lets through
+func WithLifecycle(ctx context.Context) context.Context {
+	return context.WithValue(ctx, lifecycleKey{}, true)
+}
+
+// InLifecycle reports whether ctx belongs to a lifecycle operation
+func InLifecycle(ctx context.Context) bool {
+	inLifecycle, _ := ctx.Value(lifecycleKey{}).(bool)
+	return inLifecycle
+}
+
+// Store is the project registry. It is persisted to an optional JSON file so
+// it survives restarts.
+type Store struct {
+	mu                  sync.RWMutex
+	path                string
+	requireRegistration bool
+	projects            map[string]Project // id -> project
+}
+
+// NewStore creates a registry persisted at path; an empty path keeps it in memory
+func NewStore(path string) *Store {
+	return &Store{
+		path:     path,
+		projects: make(map[string]Project),
+	}
+}
+
+// SetRequireRegistration makes writes to repositories that are not
+// registered projects fail. Without it they pass, as they did before
+// projects were registered.
+func (s *Store) SetRequireRegistration(require bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requireRegistration = require
+}
+
+// RequiresRegistration reports whether writes need a registered project
+func (s *Store) RequiresRegistration() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.requireRegistration
+}
+
+// Load reads the registry from disk. A missing file is not an error.
+func (s *Store) Load() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read project registry: %w", err)
+	}
+
+	var projects []Project
+	if err := json.Unmarshal(data, &projects); err != nil {
+		return fmt.Errorf("failed to parse project registry: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range projects {
+		s.projects[projects[i].ID] = projects[i]
+	}
+	return nil
+}
+
+// Create registers a project. Its ID must not name another project, now or
+// before a rename.
+func (s *Store) Create(project Project) (*Project, error) {
+	project.ID = strings.TrimSpace(project.ID)
+	if err := ValidateID(project.ID); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.takenLocked(project.ID, ""); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	project.Status = StatusActive
+	project.FormerIDs = nil
+	project.CreatedAt = now
+	project.UpdatedAt = now
+	project.ArchivedAt = nil
+
+	return s.saveLocked(project, "")
+}
+
+// Update changes a project's name and description
+func (s *Store) Update(id, name, description string) (*Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, ok := s.projects[id]
+	if !ok {
+		return nil, fmt.Errorf("project %q not found", id)
+	}
+	project.Name = name
+	project.Description = description
+	project.UpdatedAt = time.Now().UTC()
+	return s.saveLocked(project, "")
+}
+
+// Get returns a project by its current ID
+func (s *Store) Get(id string) (Project, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	project, ok := s.projects[id]
+	return project, ok
+}
+
+// Resolve returns the project an ID names, now or before a rename
+func (s *Store) Resolve(id string) (Project, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.resolveLocked(id)
+}
+
+// List returns the registered projects sorted by ID. Archived projects are
+// left out unless includeArchived is set.
+func (s *Store) List(includeArchived bool) []Project {
+	s.mu.RLock()
+	projects := make([]Project, 0, len(s.projects))
+	for id := range s.projects {
+		if includeArchived || s.projects[id].Status != StatusArchived {
+			projects = append(projects, s.projects[id])
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(projects, func(i, j int) bool { return projects[i].ID < projects[j].ID })
+	return projects
+}
+
+// Rename gives a project a new ID and keeps the old one as a former ID.
+// Callers move the project's memories to the new ID.
+func (s *Store) Rename(id, newID string) (*Project, error) {
+	newID = strings.TrimSpace(newID)
+	if err := ValidateID(newID); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, ok := s.projects[id]
+	if !ok {
+		return nil, fmt.Errorf("project %q not found", id)
+	}
+	if project.Archived() {
+		return nil, fmt.Errorf("%w: %s. Unarchive it before renaming it", ErrArchived, id)
+	}
+	if newID == id {
+		return nil, fmt.Errorf("project %q already has that id", id)
+	}
+	if err := s.takenLocked(newID, id); err != nil {
+		return nil, err
+	}
+
+	project.FormerIDs = append(slices.DeleteFunc(project.FormerIDs, func(former string) bool { return former == newID }), id)
+	project.ID = newID
+	project.UpdatedAt = time.Now().UTC()
+	return s.saveLocked(project, id)
+}
+
+// SetArchived archives a project, making it read-only, or brings it back
+func (s *Store) SetArchived(id string, archived bool) (*Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, ok := s.projects[id]
+	if !ok {
+		return nil, fmt.Errorf("project %q not found", id)
+	}
+	if project.Archived() == archived {
+		return &project, nil
+	}
+
+	now := time.Now().UTC()
+	project.Status, project.ArchivedAt = StatusActive, nil
+	if archived {
+		project.Status, project.ArchivedAt = StatusArchived, &now
+	}
+	project.UpdatedAt = now
+	return s.saveLocked(project, "")
+}
+
+// Delete removes a project from the registry. Callers deal with its memories first.
+func (s *Store) Delete(id string) (*Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, ok := s.projects[id]
+	if !ok {
+		return nil, fmt.Errorf("project %q not found", id)
+	}
+	delete(s.projects, id)
+	if err := s.persistLocked(); err != nil {
+		s.projects[id] = project
+		return nil, err
+	}
+	return &project, nil
+}
+
+// CheckWrite fails writes to repository that the registry does not allow:
+// writes to archived projects, to the former ID of a renamed project and,
+// when registration is required, to repositories that are not projects.
+// Shared repositories always take writes.
+func (s *Store) CheckWrite(repository string) error {
+	if repository == "" || IsShared(repository) {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	project, ok := s.resolveLocked(repository)
+	switch {
+	case !ok && s.requireRegistration:
+		return fmt.Errorf("%w: %s. Create it with project_manage first", ErrUnregistered, repository)
+	case !ok:
+		return nil
+	case project.ID != repository:
+		return fmt.Errorf("%w: %s is now %s. Use the new project id", ErrRenamed, repository, project.ID)
+	case project.Archived():
+		return fmt.Errorf("%w: %s. Unarchive it with project_manage before changing its memories", ErrArchived, repository)
+	}
+	return nil
+}
+
+// resolveLocked finds the project an ID names; callers must hold the lock
+func (s *Store) resolveLocked(id string) (Project, bool) {
+	if project, ok := s.projects[id]; ok {
+		return project, true
+	}
+	for key := range s.projects {
+		if slices.Contains(s.projects[key].FormerIDs, id) {
+			return s.projects[key], true
+		}
+	}
+	return Project{}, false
+}
+
+// takenLocked fails when id names a project other than owner, now or before
+// a rename; callers must hold the lock
+func (s *Store) takenLocked(id, owner string) error {
+	project, ok := s.resolveLocked(id)
+	switch {
+	case !ok || project.ID == owner:
+		return nil
+	case project.ID == id:
+		return fmt.Errorf("project %q already exists", id)
+	default:
+		return fmt.Errorf("%q is a former id of project %q", id, project.ID)
+	}
+}
+
+// saveLocked stores project, replacing the entry under previousID when it was
+// renamed, and persists the registry; callers must hold the write lock
+func (s *Store) saveLocked(project Project, previousID string) (*Project, error) {
+	snapshot := make(map[string]Project, len(s.projects))
+	for id := range s.projects {
+		snapshot[id] = s.projects[id]
+	}
+
+	if previousID != "" {
+		delete(s.projects, previousID)
+	}
+	s.projects[project.ID] = project
+	if err := s.persistLocked(); err != nil {
+		s.projects = snapshot
+		return nil, err
+	}
+	return &project, nil
+}
+
+// persistLocked writes the registry to disk; callers must hold the write lock
+func (s *Store) persistLocked() error {
+	if s.path == "" {
+		return nil
+	}
+
+	projects := make([]Project, 0, len(s.projects))
+	for id := range s.projects {
+		projects = append(projects, s.projects[id])
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].ID < projects[j].ID })
+
+	data, err := json.MarshalIndent(projects, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode project registry: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o750); err != nil {
+		return fmt.Errorf("failed to create project registry folder: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write project registry: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,92 @@
+package projects
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreLifecycle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "projects.json")
+	store := NewStore(path)
+
+	api, err := store.Create(Project{ID: " github.com/acme/api ", Name: "Acme API"})
+	require.NoError(t, err)
+	assert.Equal(t, "github.com/acme/api", api.ID)
+	assert.Equal(t, StatusActive, api.Status)
+
+	_, err = store.Create(Project{ID: "github.com/acme/api"})
+	assert.ErrorContains(t, err, "already exists")
+	for _, id := range []string{"", "global", "_global", "*", "acme api"} {
+		_, err = store.Create(Project{ID: id})
+		assert.Error(t, err, id)
+	}
+
+	renamed, err := store.Rename(api.ID, "github.com/acme/payments")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"github.com/acme/api"}, renamed.FormerIDs)
+	_, ok := store.Get("github.com/acme/api")
+	assert.False(t, ok)
+	resolved, ok := store.Resolve("github.com/acme/api")
+	require.True(t, ok)
+	assert.Equal(t, renamed.ID, resolved.ID)
+
+	_, err = store.Create(Project{ID: "github.com/acme/api"})
+	assert.ErrorContains(t, err, "former id")
+
+	archived, err := store.SetArchived(renamed.ID, true)
+	require.NoError(t, err)
+	assert.True(t, archived.Archived())
+	require.NotNil(t, archived.ArchivedAt)
+	_, err = store.Rename(renamed.ID, "github.com/acme/other")
+	assert.ErrorIs(t, err, ErrArchived)
+	assert.Empty(t, store.List(false))
+	assert.Len(t, store.List(true), 1)
+
+	reloaded := NewStore(path)
+	require.NoError(t, reloaded.Load())
+	project, ok := reloaded.Get("github.com/acme/payments")
+	require.True(t, ok)
+	assert.Equal(t, StatusArchived, project.Status)
+	assert.Equal(t, "Acme API", project.Name)
+
+	_, err = reloaded.Delete(project.ID)
+	require.NoError(t, err)
+	_, ok = reloaded.Resolve("github.com/acme/api")
+	assert.False(t, ok, "a deleted project frees its former ids")
+	_, err = reloaded.Delete(project.ID)
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestStoreCheckWrite(t *testing.T) {
+	store := NewStore("")
+	_, err := store.Create(Project{ID: "github.com/acme/api"})
+	require.NoError(t, err)
+	_, err = store.Create(Project{ID: "github.com/acme/old"})
+	require.NoError(t, err)
+	_, err = store.Rename("github.com/acme/old", "github.com/acme/new")
+	require.NoError(t, err)
+	_, err = store.SetArchived("github.com/acme/api", true)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, store.CheckWrite("github.com/acme/api"), ErrArchived)
+	err = store.CheckWrite("github.com/acme/old")
+	assert.ErrorIs(t, err, ErrRenamed)
+	assert.ErrorContains(t, err, "github.com/acme/new")
+	assert.NoError(t, store.CheckWrite("github.com/acme/new"))
+	assert.NoError(t, store.CheckWrite("github.com/acme/unknown"), "unregistered repositories take writes by default")
+	assert.NoError(t, store.CheckWrite(""))
+
+	store.SetRequireRegistration(true)
+	assert.ErrorIs(t, store.CheckWrite("github.com/acme/unknown"), ErrUnregistered)
+	assert.NoError(t, store.CheckWrite("global"))
+	assert.NoError(t, store.CheckWrite("github.com/acme/new"))
+}
+
+func TestLifecycleContext(t *testing.T) {
+	assert.False(t, InLifecycle(context.Background()))
+	assert.True(t, InLifecycle(WithLifecycle(context.Background())))
+}
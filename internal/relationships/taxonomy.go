@@ -0,0 +1,180 @@
+package relationships
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"lerian-mcp-memory/pkg/types"
+)
+
+// Taxonomy manages the relation types available to each project: the built-in
+// types plus custom types a project defines. Custom types are persisted to an
+// optional JSON file so they survive restarts.
+type Taxonomy struct {
+	mu     sync.RWMutex
+	path   string
+	custom map[string]map[types.RelationType]types.RelationTypeDefinition // project -> name -> definition
+}
+
+// NewTaxonomy creates a taxonomy persisted at path; an empty path keeps it in memory
+func NewTaxonomy(path string) *Taxonomy {
+	return &Taxonomy{
+		path:   path,
+		custom: make(map[string]map[types.RelationType]types.RelationTypeDefinition),
+	}
+}
+
+// Load reads custom definitions from disk. A missing file is not an error.
+func (t *Taxonomy) Load() error {
+	if t.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(t.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read relation taxonomy: %w", err)
+	}
+
+	var definitions []types.RelationTypeDefinition
+	if err := json.Unmarshal(data, &definitions); err != nil {
+		return fmt.Errorf("failed to parse relation taxonomy: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := range definitions {
+		t.putLocked(&definitions[i])
+	}
+	return nil
+}
+
+// Define adds or replaces a custom relation type for a project
+func (t *Taxonomy) Define(project string, definition types.RelationTypeDefinition) (*types.RelationTypeDefinition, error) {
+	if project == "" {
+		return nil, errors.New("project is required to define a relation type")
+	}
+	if definition.Directionality == "" {
+		definition.Directionality = types.DirectionalityDirected
+	}
+	if err := definition.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	definition.Project = project
+	definition.BuiltIn = false
+	definition.CreatedAt = &now
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if definition.Inverse != "" && !definition.Inverse.Valid() && definition.Inverse != definition.Name {
+		if _, ok := t.custom[project][definition.Inverse]; !ok {
+			return nil, fmt.Errorf("inverse relation type %q is not defined for %s", definition.Inverse, project)
+		}
+	}
+
+	previous, existed := t.custom[project][definition.Name]
+	t.putLocked(&definition)
+	if err := t.persistLocked(); err != nil {
+		if existed {
+			t.custom[project][definition.Name] = previous
+		} else {
+			delete(t.custom[project], definition.Name)
+		}
+		return nil, err
+	}
+
+	result := definition
+	return &result, nil
+}
+
+// List returns the built-in types followed by the project's custom types sorted by name
+func (t *Taxonomy) List(project string) []types.RelationTypeDefinition {
+	definitions := types.BuiltInRelationTypes()
+
+	t.mu.RLock()
+	custom := make([]types.RelationTypeDefinition, 0, len(t.custom[project]))
+	for name := range t.custom[project] {
+		custom = append(custom, t.custom[project][name])
+	}
+	t.mu.RUnlock()
+
+	sort.Slice(custom, func(i, j int) bool { return custom[i].Name < custom[j].Name })
+	return append(definitions, custom...)
+}
+
+// Lookup returns the definition of a relation type as seen by a project
+func (t *Taxonomy) Lookup(project string, name types.RelationType) (types.RelationTypeDefinition, bool) {
+	if name.Valid() {
+		for _, definition := range types.BuiltInRelationTypes() {
+			if definition.Name == name {
+				return definition, true
+			}
+		}
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	definition, ok := t.custom[project][name]
+	return definition, ok
+}
+
+// Validate checks that a relation type may be used in a project
+func (t *Taxonomy) Validate(project string, name types.RelationType) error {
+	if _, ok := t.Lookup(project, name); ok {
+		return nil
+	}
+
+	valid := make([]string, 0)
+	for _, definition := range t.List(project) {
+		valid = append(valid, string(definition.Name))
+	}
+	return fmt.Errorf("invalid relation type: %s. Valid types for %s are: %v", name, project, valid)
+}
+
+// putLocked stores a definition; callers must hold the write lock
+func (t *Taxonomy) putLocked(definition *types.RelationTypeDefinition) {
+	if t.custom[definition.Project] == nil {
+		t.custom[definition.Project] = make(map[types.RelationType]types.RelationTypeDefinition)
+	}
+	t.custom[definition.Project][definition.Name] = *definition
+}
+
+// persistLocked writes all custom definitions to disk; callers must hold the write lock
+func (t *Taxonomy) persistLocked() error {
+	if t.path == "" {
+		return nil
+	}
+
+	definitions := make([]types.RelationTypeDefinition, 0)
+	for project := range t.custom {
+		for name := range t.custom[project] {
+			definitions = append(definitions, t.custom[project][name])
+		}
+	}
+
+	data, err := json.MarshalIndent(definitions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode relation taxonomy: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o750); err != nil {
+		return fmt.Errorf("failed to create relation taxonomy directory: %w", err)
+	}
+
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write relation taxonomy: %w", err)
+	}
+	return os.Rename(tmp, t.path)
+}
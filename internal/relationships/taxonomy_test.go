@@ -0,0 +1,68 @@
+package relationships
+
+import (
+	"path/filepath"
+	"testing"
+
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaxonomyDefineAndValidate(t *testing.T) {
+	taxonomy := NewTaxonomy("")
+
+	definition, err := taxonomy.Define("github.com/acme/api", types.RelationTypeDefinition{
+		Name:        "blocks_release",
+		Description: "Issue blocks a release",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, types.DirectionalityDirected, definition.Directionality, "directed is the default")
+
+	assert.NoError(t, taxonomy.Validate("github.com/acme/api", "blocks_release"))
+	assert.NoError(t, taxonomy.Validate("github.com/acme/api", types.RelationLedTo))
+	assert.Error(t, taxonomy.Validate("github.com/acme/other", "blocks_release"), "custom types are project scoped")
+
+	definitions := taxonomy.List("github.com/acme/api")
+	assert.Len(t, definitions, len(types.AllValidRelationTypes())+1)
+	assert.Equal(t, types.RelationType("blocks_release"), definitions[len(definitions)-1].Name)
+}
+
+func TestTaxonomyRejectsInvalidDefinitions(t *testing.T) {
+	taxonomy := NewTaxonomy("")
+
+	for name, definition := range map[string]types.RelationTypeDefinition{
+		"built-in name":     {Name: types.RelationLedTo, Description: "clash"},
+		"malformed name":    {Name: "Blocks Release", Description: "bad"},
+		"no description":    {Name: "blocks_release"},
+		"bad direction":     {Name: "blocks_release", Description: "x", Directionality: "sideways"},
+		"symmetric inverse": {Name: "pairs_with", Description: "x", Directionality: types.DirectionalitySymmetric, Inverse: "pairs_with"},
+		"unknown inverse":   {Name: "blocks_release", Description: "x", Inverse: "blocked_by_release"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := taxonomy.Define("github.com/acme/api", definition)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestTaxonomyPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "taxonomy.json")
+
+	taxonomy := NewTaxonomy(path)
+	_, err := taxonomy.Define("github.com/acme/api", types.RelationTypeDefinition{
+		Name:           "pairs_with",
+		Description:    "Chunks describe paired components",
+		Directionality: types.DirectionalitySymmetric,
+	})
+	require.NoError(t, err)
+
+	reloaded := NewTaxonomy(path)
+	require.NoError(t, reloaded.Load())
+
+	definition, ok := reloaded.Lookup("github.com/acme/api", "pairs_with")
+	require.True(t, ok)
+	assert.Equal(t, types.DirectionalitySymmetric, definition.Directionality)
+	assert.False(t, definition.BuiltIn)
+}
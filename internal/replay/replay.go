@@ -0,0 +1,438 @@
+// Package replay records MCP sessions as JSON-RPC exchanges and replays them
+// against a server, diffing each response structurally with the recorded one.
+// Generated identifiers and timestamps differ between runs, so the differ
+// pairs recorded UUIDs with replayed ones (and substitutes them in later
+// requests), ignores timestamps and skips an allowlist of volatile fields.
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// maxLineBytes bounds one recorded message
+const maxLineBytes = 16 << 20
+
+// Exchange is one client message of a session and the server's response to
+// it. Notifications have no response.
+type Exchange struct {
+	Request  json.RawMessage `json:"request"`
+	Response json.RawMessage `json:"response,omitempty"`
+	// Ignore lists further volatile fields of this response, as Differ fields
+	Ignore []string `json:"ignore,omitempty"`
+}
+
+// ReadSession reads a session, one exchange per line. Blank lines and lines
+// starting with # are skipped.
+func ReadSession(r io.Reader) ([]Exchange, error) {
+	var exchanges []Exchange
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineBytes)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 || text[0] == '#' {
+			continue
+		}
+		var exchange Exchange
+		if err := json.Unmarshal(text, &exchange); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if len(exchange.Request) == 0 {
+			return nil, fmt.Errorf("line %d: exchange has no request", line)
+		}
+		exchanges = append(exchanges, exchange)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+	return exchanges, nil
+}
+
+// LoadSession reads the session recorded in a file
+func LoadSession(path string) ([]Exchange, error) {
+	f, err := os.Open(path) // #nosec G304 -- path names a session chosen by the caller
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	return ReadSession(f)
+}
+
+// WriteSession writes exchanges one per line
+func WriteSession(w io.Writer, exchanges []Exchange) error {
+	for i := range exchanges {
+		if err := writeExchange(w, &exchanges[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeExchange writes an exchange as one compact line
+func writeExchange(w io.Writer, exchange *Exchange) error {
+	compact := *exchange
+	var err error
+	if compact.Request, err = compactJSON(exchange.Request); err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
+	if len(exchange.Response) > 0 {
+		if compact.Response, err = compactJSON(exchange.Response); err != nil {
+			return fmt.Errorf("invalid response: %w", err)
+		}
+	}
+	data, err := json.Marshal(&compact)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// compactJSON drops the insignificant whitespace of a JSON value
+func compactJSON(data []byte) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// message holds the fields that tell JSON-RPC requests, notifications and
+// responses apart
+type message struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+}
+
+// Recorder writes the exchanges of a live session as the client and the
+// server send their messages. Requests wait for the response with their ID;
+// client notifications are written at once. Messages the server starts
+// itself (notifications, sampling requests) and batches are not recorded.
+type Recorder struct {
+	mutex   sync.Mutex
+	w       io.Writer
+	pending map[string]json.RawMessage
+}
+
+// NewRecorder creates a recorder writing to w
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: w, pending: make(map[string]json.RawMessage)}
+}
+
+// ClientMessage records a message from the client
+func (r *Recorder) ClientMessage(line []byte) error {
+	var msg message
+	if json.Unmarshal(line, &msg) != nil || msg.Method == "" {
+		return nil // Batches, and responses to server requests
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if len(msg.ID) == 0 || string(msg.ID) == "null" {
+		return writeExchange(r.w, &Exchange{Request: append(json.RawMessage(nil), line...)})
+	}
+	r.pending[string(msg.ID)] = append(json.RawMessage(nil), line...)
+	return nil
+}
+
+// ServerMessage records a message from the server
+func (r *Recorder) ServerMessage(line []byte) error {
+	var msg message
+	if json.Unmarshal(line, &msg) != nil || msg.Method != "" || len(msg.ID) == 0 {
+		return nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	request, ok := r.pending[string(msg.ID)]
+	if !ok {
+		return nil
+	}
+	delete(r.pending, string(msg.ID))
+	return writeExchange(r.w, &Exchange{Request: request, Response: append(json.RawMessage(nil), line...)})
+}
+
+// Pending returns the number of requests still waiting for a response
+func (r *Recorder) Pending() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.pending)
+}
+
+// DefaultVolatileFields are fields whose values change between runs of the
+// same session: timings and clocks
+var DefaultVolatileFields = []string{
+	"*_at", "*_time", "*_ms", "*_seconds", "*_ns",
+	"timestamp", "time", "duration", "elapsed", "took", "uptime", "age", "last_seen",
+}
+
+var (
+	uuidPattern      = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+	timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?( ?(Z|[+-]\d{2}:?\d{2}))?( [A-Z]{3,5})?`)
+)
+
+// volatileToken stands in for a masked timestamp
+const volatileToken = "\x00"
+
+// Difference is a place where a replayed response departs from the recording
+type Difference struct {
+	Path     string
+	Recorded string
+	Replayed string
+}
+
+// String describes the difference
+func (d Difference) String() string {
+	return fmt.Sprintf("%s: recorded %s, replayed %s", d.Path, d.Recorded, d.Replayed)
+}
+
+// Differ compares the responses of one replayed session with their
+// recordings. It remembers which replayed UUID each recorded UUID became, so
+// later requests can refer to what earlier ones created, and reports a
+// recorded UUID that turns into two different ones.
+type Differ struct {
+	fields   []string
+	replaced map[string]string
+}
+
+// NewDiffer creates a differ skipping the given fields. A field is a key
+// name matched at any depth; a leading or trailing * matches a suffix or a
+// prefix.
+func NewDiffer(fields ...string) *Differ {
+	return &Differ{fields: fields, replaced: make(map[string]string)}
+}
+
+// Prepare rewrites the recorded UUIDs of a request to their replayed values
+func (d *Differ) Prepare(request json.RawMessage) json.RawMessage {
+	if len(d.replaced) == 0 {
+		return request
+	}
+	return uuidPattern.ReplaceAllFunc(request, func(id []byte) []byte {
+		if replayed, ok := d.replaced[string(id)]; ok {
+			return []byte(replayed)
+		}
+		return id
+	})
+}
+
+// Compare returns the differences between a recorded and a replayed
+// response, skipping the differ's fields and those of ignore
+func (d *Differ) Compare(recorded, replayed json.RawMessage, ignore []string) ([]Difference, error) {
+	var want, got interface{}
+	if err := decode(recorded, &want); err != nil {
+		return nil, fmt.Errorf("invalid recorded response: %w", err)
+	}
+	if err := decode(replayed, &got); err != nil {
+		return nil, fmt.Errorf("invalid replayed response: %w", err)
+	}
+
+	fields := append(append([]string(nil), d.fields...), ignore...)
+	var diffs []Difference
+	d.compare("$", want, got, fields, &diffs)
+	return diffs, nil
+}
+
+// decode parses JSON keeping numbers exact
+func decode(data []byte, v *interface{}) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// compare walks two decoded values side by side
+func (d *Differ) compare(path string, want, got interface{}, fields []string, diffs *[]Difference) {
+	switch w := want.(type) {
+	case map[string]interface{}:
+		g, ok := got.(map[string]interface{})
+		if !ok {
+			*diffs = append(*diffs, mismatch(path, want, got))
+			return
+		}
+		for _, key := range unionKeys(w, g) {
+			if matchesField(key, fields) {
+				continue
+			}
+			child := path + "." + key
+			wv, inWant := w[key]
+			gv, inGot := g[key]
+			switch {
+			case !inGot:
+				*diffs = append(*diffs, Difference{Path: child, Recorded: describe(wv), Replayed: "missing"})
+			case !inWant:
+				*diffs = append(*diffs, Difference{Path: child, Recorded: "missing", Replayed: describe(gv)})
+			default:
+				d.compare(child, wv, gv, fields, diffs)
+			}
+		}
+	case []interface{}:
+		g, ok := got.([]interface{})
+		if !ok {
+			*diffs = append(*diffs, mismatch(path, want, got))
+			return
+		}
+		if len(w) != len(g) {
+			*diffs = append(*diffs, Difference{Path: path, Recorded: fmt.Sprintf("%d items", len(w)), Replayed: fmt.Sprintf("%d items", len(g))})
+			return
+		}
+		for i := range w {
+			d.compare(fmt.Sprintf("%s[%d]", path, i), w[i], g[i], fields, diffs)
+		}
+	case string:
+		g, ok := got.(string)
+		if !ok {
+			*diffs = append(*diffs, mismatch(path, want, got))
+			return
+		}
+		d.compareStrings(path, w, g, fields, diffs)
+	default:
+		if describe(want) != describe(got) {
+			*diffs = append(*diffs, mismatch(path, want, got))
+		}
+	}
+}
+
+// compareStrings compares strings. Tool results carry JSON in text content,
+// which is compared structurally; other strings must match once timestamps
+// are masked and UUIDs paired.
+func (d *Differ) compareStrings(path, want, got string, fields []string, diffs *[]Difference) {
+	if want == got {
+		return
+	}
+
+	if embedded(want) && embedded(got) {
+		var w, g interface{}
+		if decode([]byte(want), &w) == nil && decode([]byte(got), &g) == nil {
+			d.compare(path+"<json>", w, g, fields, diffs)
+			return
+		}
+	}
+
+	wantIDs, gotIDs := uuidPattern.FindAllString(want, -1), uuidPattern.FindAllString(got, -1)
+	maskedWant := timestampPattern.ReplaceAllString(uuidPattern.ReplaceAllString(want, volatileToken), volatileToken)
+	maskedGot := timestampPattern.ReplaceAllString(uuidPattern.ReplaceAllString(got, volatileToken), volatileToken)
+	if maskedWant != maskedGot || len(wantIDs) != len(gotIDs) {
+		*diffs = append(*diffs, Difference{Path: path, Recorded: describe(want), Replayed: describe(got)})
+		return
+	}
+	for i := range wantIDs {
+		if replayed, ok := d.replaced[wantIDs[i]]; ok && replayed != gotIDs[i] {
+			*diffs = append(*diffs, Difference{Path: path, Recorded: wantIDs[i] + " (replayed earlier as " + replayed + ")", Replayed: gotIDs[i]})
+			continue
+		}
+		d.replaced[wantIDs[i]] = gotIDs[i]
+	}
+}
+
+// embedded reports whether a string holds a JSON object or array
+func embedded(s string) bool {
+	s = strings.TrimSpace(s)
+	return len(s) > 1 && (s[0] == '{' && s[len(s)-1] == '}' || s[0] == '[' && s[len(s)-1] == ']')
+}
+
+// matchesField reports whether key is one of the volatile fields
+func matchesField(key string, fields []string) bool {
+	for _, field := range fields {
+		switch {
+		case strings.HasPrefix(field, "*") && strings.HasSuffix(key, field[1:]):
+			return true
+		case strings.HasSuffix(field, "*") && strings.HasPrefix(key, field[:len(field)-1]):
+			return true
+		case key == field:
+			return true
+		}
+	}
+	return false
+}
+
+// unionKeys returns the keys of both objects, sorted
+func unionKeys(a, b map[string]interface{}) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// mismatch reports two values that differ as a whole
+func mismatch(path string, want, got interface{}) Difference {
+	return Difference{Path: path, Recorded: describe(want), Replayed: describe(got)}
+}
+
+// describe renders a decoded value for a difference, shortened
+func describe(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	const limit = 120
+	if len(data) > limit {
+		return string(data[:limit]) + "..."
+	}
+	return string(data)
+}
+
+// ErrNoResponse is returned by a Handler for a message that gets no response
+var ErrNoResponse = errors.New("no response")
+
+// Handler serves one JSON-RPC message and returns the server's response
+type Handler func(request json.RawMessage) (json.RawMessage, error)
+
+// Result is the outcome of replaying one exchange
+type Result struct {
+	Index       int
+	Method      string
+	Response    json.RawMessage
+	Differences []Difference
+}
+
+// Replay sends each exchange's request to handler in order and diffs the
+// responses with the recorded ones. Exchanges without a recorded response
+// are only sent. The returned results hold the replayed responses, so a
+// caller can write them as the new recording.
+func Replay(exchanges []Exchange, handler Handler, differ *Differ) ([]Result, error) {
+	results := make([]Result, 0, len(exchanges))
+	for i := range exchanges {
+		request := differ.Prepare(exchanges[i].Request)
+		var msg message
+		_ = json.Unmarshal(request, &msg)
+
+		response, err := handler(request)
+		if err != nil && !errors.Is(err, ErrNoResponse) {
+			return results, fmt.Errorf("exchange %d (%s): %w", i+1, msg.Method, err)
+		}
+
+		result := Result{Index: i, Method: msg.Method, Response: response}
+		switch {
+		case len(exchanges[i].Response) == 0 && len(response) > 0:
+			result.Differences = []Difference{{Path: "$", Recorded: "no response", Replayed: "a response"}}
+		case len(exchanges[i].Response) > 0 && len(response) == 0:
+			result.Differences = []Difference{{Path: "$", Recorded: "a response", Replayed: "no response"}}
+		case len(response) > 0:
+			diffs, err := differ.Compare(exchanges[i].Response, response, exchanges[i].Ignore)
+			if err != nil {
+				return results, fmt.Errorf("exchange %d (%s): %w", i+1, msg.Method, err)
+			}
+			result.Differences = diffs
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,109 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	recordedID = "9337559c-2a1f-497e-92cd-a86610ac8264"
+	replayedID = "e8fcc2b6-3298-4021-a5a7-e4f7631b32cd"
+)
+
+func TestDifferCompare(t *testing.T) {
+	differ := NewDiffer(DefaultVolatileFields...)
+
+	recorded := `{"id":1,"result":{"content":[{"type":"text","text":"{\"chunk_id\":\"` + recordedID + `\",\"stored_at\":\"2026-10-16T18:56:58Z\",\"query_time\":12,\"total\":1}"}],"note":"stored ` + recordedID + ` at 2026-10-16 18:56:58 +0000 UTC"}}`
+	replayed := `{"id":1,"result":{"content":[{"type":"text","text":"{\"total\":1,\"chunk_id\":\"` + replayedID + `\",\"stored_at\":\"2026-10-17T09:00:00Z\",\"query_time\":3}"}],"note":"stored ` + replayedID + ` at 2026-10-17 09:00:00 +0000 UTC"}}`
+	diffs, err := differ.Compare(json.RawMessage(recorded), json.RawMessage(replayed), nil)
+	require.NoError(t, err)
+	assert.Empty(t, diffs, "UUIDs, timestamps and volatile fields may differ; embedded JSON compares by structure")
+
+	// The pairing carries over to later requests
+	prepared := differ.Prepare(json.RawMessage(`{"params":{"arguments":{"chunk_ids":["` + recordedID + `"]}}}`))
+	assert.Contains(t, string(prepared), replayedID)
+
+	// A recorded UUID must keep replaying as the same one
+	diffs, err = differ.Compare(json.RawMessage(`{"id":"`+recordedID+`"}`), json.RawMessage(`{"id":"00000000-0000-0000-0000-000000000000"}`), nil)
+	require.NoError(t, err)
+	require.Len(t, diffs, 1)
+	assert.Contains(t, diffs[0].String(), "replayed earlier as "+replayedID)
+}
+
+func TestDifferReportsRegressions(t *testing.T) {
+	differ := NewDiffer(DefaultVolatileFields...)
+
+	diffs, err := differ.Compare(
+		json.RawMessage(`{"result":{"tools":[{"name":"memory_read","required":["operation"]}],"total":2,"status":"success"}}`),
+		json.RawMessage(`{"result":{"tools":[{"name":"memory_read","required":["operation","scope"]}],"total":"2","extra":true}}`),
+		[]string{"extra"},
+	)
+	require.NoError(t, err)
+
+	paths := make([]string, 0, len(diffs))
+	for _, diff := range diffs {
+		paths = append(paths, diff.Path)
+	}
+	assert.Equal(t, []string{"$.result.status", "$.result.tools[0].required", "$.result.total"}, paths)
+	assert.Equal(t, "missing", diffs[0].Replayed)
+	assert.Equal(t, "1 items", diffs[1].Recorded)
+}
+
+func TestRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	recorder := NewRecorder(&buf)
+
+	require.NoError(t, recorder.ClientMessage([]byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)))
+	require.NoError(t, recorder.ClientMessage([]byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)))
+	require.NoError(t, recorder.ClientMessage([]byte(`{"jsonrpc":"2.0","id":2,"method":"ping"}`)))
+	require.NoError(t, recorder.ServerMessage([]byte(`{"jsonrpc":"2.0","method":"notifications/progress","params":{}}`)))
+	require.NoError(t, recorder.ServerMessage([]byte(`{"jsonrpc":"2.0","id":1,"result":{"serverInfo":{"name":"memory"}}}`)))
+	assert.Equal(t, 1, recorder.Pending())
+
+	exchanges, err := ReadSession(strings.NewReader("# recorded by hand\n\n" + buf.String()))
+	require.NoError(t, err)
+	require.Len(t, exchanges, 2, "server notifications are not recorded and ping awaits its response")
+	assert.JSONEq(t, `{"jsonrpc":"2.0","method":"notifications/initialized"}`, string(exchanges[0].Request))
+	assert.Empty(t, exchanges[0].Response)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":{"serverInfo":{"name":"memory"}}}`, string(exchanges[1].Response))
+
+	var out bytes.Buffer
+	require.NoError(t, WriteSession(&out, exchanges))
+	assert.Equal(t, buf.String(), out.String())
+}
+
+func TestReplay(t *testing.T) {
+	exchanges := []Exchange{
+		{Request: json.RawMessage(`{"id":1,"method":"create"}`), Response: json.RawMessage(`{"id":1,"result":{"id":"` + recordedID + `"}}`)},
+		{Request: json.RawMessage(`{"method":"notify"}`)},
+		{Request: json.RawMessage(`{"id":2,"method":"get","params":{"id":"` + recordedID + `"}}`), Response: json.RawMessage(`{"id":2,"result":{"found":true}}`)},
+	}
+
+	var requests []string
+	handler := func(request json.RawMessage) (json.RawMessage, error) {
+		requests = append(requests, string(request))
+		switch {
+		case strings.Contains(string(request), `"create"`):
+			return json.RawMessage(`{"id":1,"result":{"id":"` + replayedID + `"}}`), nil
+		case strings.Contains(string(request), replayedID):
+			return json.RawMessage(`{"id":2,"result":{"found":true}}`), nil
+		case strings.Contains(string(request), `"get"`):
+			return json.RawMessage(`{"id":2,"result":{"found":false}}`), nil
+		}
+		return nil, ErrNoResponse
+	}
+
+	results, err := Replay(exchanges, handler, NewDiffer(DefaultVolatileFields...))
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	for _, result := range results {
+		assert.Empty(t, result.Differences, result.Method)
+	}
+	assert.Contains(t, requests[2], replayedID, "later requests refer to the replayed UUID")
+	assert.Equal(t, "get", results[2].Method)
+}
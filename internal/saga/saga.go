@@ -0,0 +1,168 @@
+// Package saga runs multi-step operations that span several stores, undoing
+// completed steps with compensating actions when a later step fails.
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Status represents the final state of a saga
+type Status string
+
+const (
+	// StatusCommitted means every step completed
+	StatusCommitted Status = "committed"
+	// StatusCompensated means a step failed and all completed steps were undone
+	StatusCompensated Status = "compensated"
+	// StatusCompensationFailed means a step failed and at least one compensation also failed
+	StatusCompensationFailed Status = "compensation_failed"
+)
+
+// StepStatus represents the state of a single step after execution
+type StepStatus string
+
+const (
+	StepCompleted          StepStatus = "completed"
+	StepFailed             StepStatus = "failed"
+	StepCompensated        StepStatus = "compensated"
+	StepCompensationFailed StepStatus = "compensation_failed"
+	StepSkipped            StepStatus = "skipped"
+)
+
+// Action is the forward or compensating action of a step
+type Action func(ctx context.Context) error
+
+// Step is a unit of work with an optional compensating action
+type Step struct {
+	Name       string
+	Execute    Action
+	Compensate Action
+}
+
+// StepResult records what happened to a step
+type StepResult struct {
+	Name     string     `json:"name"`
+	Status   StepStatus `json:"status"`
+	Error    string     `json:"error,omitempty"`
+	Duration string     `json:"duration"`
+}
+
+// Result records the outcome of a saga run
+type Result struct {
+	Name   string       `json:"name"`
+	Status Status       `json:"status"`
+	Steps  []StepResult `json:"steps"`
+}
+
+// Error is returned when a saga fails; it carries the failed step and any compensation errors
+type Error struct {
+	Step               string
+	Cause              error
+	CompensationErrors []error
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("step %q failed: %v", e.Step, e.Cause)
+	if len(e.CompensationErrors) > 0 {
+		parts := make([]string, 0, len(e.CompensationErrors))
+		for _, err := range e.CompensationErrors {
+			parts = append(parts, err.Error())
+		}
+		msg += fmt.Sprintf(" (compensation failed: %s)", strings.Join(parts, "; "))
+	}
+	return msg
+}
+
+// Unwrap returns the error of the failed step
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Saga is an ordered list of steps executed as one logical operation
+type Saga struct {
+	name  string
+	steps []Step
+}
+
+// New creates an empty saga
+func New(name string) *Saga {
+	return &Saga{name: name}
+}
+
+// AddStep appends a step; compensate may be nil for steps with nothing to undo
+func (s *Saga) AddStep(name string, execute, compensate Action) *Saga {
+	s.steps = append(s.steps, Step{Name: name, Execute: execute, Compensate: compensate})
+	return s
+}
+
+// Execute runs the steps in order. If a step fails, the completed steps are
+// compensated in reverse order and an *Error describing the failure is returned.
+func (s *Saga) Execute(ctx context.Context) (*Result, error) {
+	result := &Result{
+		Name:   s.name,
+		Status: StatusCommitted,
+		Steps:  make([]StepResult, len(s.steps)),
+	}
+	for i := range s.steps {
+		result.Steps[i] = StepResult{Name: s.steps[i].Name, Status: StepSkipped}
+	}
+
+	for i := range s.steps {
+		step := &s.steps[i]
+		start := time.Now()
+
+		err := ctx.Err()
+		if err == nil {
+			err = step.Execute(ctx)
+		}
+		result.Steps[i].Duration = time.Since(start).String()
+
+		if err != nil {
+			result.Steps[i].Status = StepFailed
+			result.Steps[i].Error = err.Error()
+			return result, s.compensate(ctx, result, i, err)
+		}
+		result.Steps[i].Status = StepCompleted
+	}
+
+	return result, nil
+}
+
+// compensate undoes the steps before failedIndex in reverse order
+func (s *Saga) compensate(ctx context.Context, result *Result, failedIndex int, cause error) error {
+	sagaErr := &Error{Step: s.steps[failedIndex].Name, Cause: cause}
+	result.Status = StatusCompensated
+
+	// Compensations must run even if the caller's context was cancelled
+	compensationCtx := context.WithoutCancel(ctx)
+
+	for i := failedIndex - 1; i >= 0; i-- {
+		step := &s.steps[i]
+		if step.Compensate == nil {
+			result.Steps[i].Status = StepCompensated
+			continue
+		}
+
+		if err := step.Compensate(compensationCtx); err != nil {
+			result.Steps[i].Status = StepCompensationFailed
+			result.Steps[i].Error = err.Error()
+			result.Status = StatusCompensationFailed
+			sagaErr.CompensationErrors = append(sagaErr.CompensationErrors, fmt.Errorf("%s: %w", step.Name, err))
+			continue
+		}
+		result.Steps[i].Status = StepCompensated
+	}
+
+	return sagaErr
+}
+
+// IsCompensationFailure reports whether err is a saga error whose compensation did not fully succeed
+func IsCompensationFailure(err error) bool {
+	var sagaErr *Error
+	return errors.As(err, &sagaErr) && len(sagaErr.CompensationErrors) > 0
+}
@@ -0,0 +1,79 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSagaCommits(t *testing.T) {
+	var calls []string
+	s := New("ok").
+		AddStep("a", func(context.Context) error { calls = append(calls, "a"); return nil }, nil).
+		AddStep("b", func(context.Context) error { calls = append(calls, "b"); return nil }, nil)
+
+	result, err := s.Execute(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, StatusCommitted, result.Status)
+	assert.Equal(t, []string{"a", "b"}, calls)
+	for _, step := range result.Steps {
+		assert.Equal(t, StepCompleted, step.Status)
+	}
+}
+
+func TestSagaCompensatesInReverseOrder(t *testing.T) {
+	var undone []string
+	boom := errors.New("boom")
+
+	s := New("partial").
+		AddStep("a", func(context.Context) error { return nil }, func(context.Context) error { undone = append(undone, "a"); return nil }).
+		AddStep("b", func(context.Context) error { return nil }, func(context.Context) error { undone = append(undone, "b"); return nil }).
+		AddStep("c", func(context.Context) error { return boom }, func(context.Context) error { undone = append(undone, "c"); return nil }).
+		AddStep("d", func(context.Context) error { t.Fatal("step after failure must not run"); return nil }, nil)
+
+	result, err := s.Execute(context.Background())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, boom)
+	assert.False(t, IsCompensationFailure(err))
+
+	assert.Equal(t, StatusCompensated, result.Status)
+	assert.Equal(t, []string{"b", "a"}, undone)
+	assert.Equal(t, StepCompensated, result.Steps[0].Status)
+	assert.Equal(t, StepCompensated, result.Steps[1].Status)
+	assert.Equal(t, StepFailed, result.Steps[2].Status)
+	assert.Equal(t, StepSkipped, result.Steps[3].Status)
+}
+
+func TestSagaReportsCompensationFailure(t *testing.T) {
+	s := New("broken").
+		AddStep("a", func(context.Context) error { return nil }, func(context.Context) error { return errors.New("cannot undo") }).
+		AddStep("b", func(context.Context) error { return errors.New("boom") }, nil)
+
+	result, err := s.Execute(context.Background())
+	require.Error(t, err)
+	assert.True(t, IsCompensationFailure(err))
+	assert.Contains(t, err.Error(), "cannot undo")
+	assert.Equal(t, StatusCompensationFailed, result.Status)
+	assert.Equal(t, StepCompensationFailed, result.Steps[0].Status)
+}
+
+func TestSagaCompensatesAfterCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	compensated := false
+
+	s := New("cancelled").
+		AddStep("a", func(context.Context) error { cancel(); return nil }, func(ctx context.Context) error {
+			compensated = ctx.Err() == nil
+			return nil
+		}).
+		AddStep("b", func(context.Context) error { return nil }, nil)
+
+	result, err := s.Execute(ctx)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.True(t, compensated, "compensation must run with a live context")
+	assert.Equal(t, StatusCompensated, result.Status)
+}
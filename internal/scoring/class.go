@@ -0,0 +1,47 @@
+package scoring
+
+import (
+	"sort"
+
+	"lerian-mcp-memory/pkg/types"
+)
+
+// Class factors multiply the relevance of a result by its memory class.
+// Distilled facts and how-tos outrank the session logs they came from, and
+// episodic memories already consolidated into a semantic one rank lowest,
+// so the consolidated memory answers first.
+const (
+	SemanticFactor         = 1.15
+	ProceduralFactor       = 1.1
+	PromotedEpisodicFactor = 0.6
+)
+
+// ClassFactor returns the relevance multiplier for a chunk's memory class
+func ClassFactor(chunk *types.ConversationChunk) float64 {
+	switch chunk.Class() {
+	case types.MemoryClassSemantic:
+		return SemanticFactor
+	case types.MemoryClassProcedural:
+		return ProceduralFactor
+	}
+	if chunk.IsPromoted() {
+		return PromotedEpisodicFactor
+	}
+	return 1
+}
+
+// RankByClass scales result scores by their class factor and re-sorts them,
+// reporting whether any score changed. Ties keep their original order.
+func RankByClass(results []types.SearchResult) bool {
+	changed := false
+	for i := range results {
+		if factor := ClassFactor(&results[i].Chunk); factor != 1 {
+			results[i].Score *= factor
+			changed = true
+		}
+	}
+	if changed {
+		sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	}
+	return changed
+}
@@ -0,0 +1,47 @@
+package scoring
+
+import (
+	"sort"
+
+	"lerian-mcp-memory/pkg/types"
+)
+
+// Outcome factors multiply the relevance of a result by what is known about
+// whether it worked. Verdicts recorded by verification outweigh the outcome
+// a memory was stored with.
+const (
+	VerifiedFactor           = 1.25
+	VerificationFailedFactor = 0.5
+	FailedOutcomeFactor      = 0.8
+)
+
+// OutcomeFactor returns the relevance multiplier for a chunk's outcome
+func OutcomeFactor(chunk *types.ConversationChunk) float64 {
+	switch chunk.VerificationStatus() {
+	case types.VerificationVerified:
+		return VerifiedFactor
+	case types.VerificationFailed:
+		return VerificationFailedFactor
+	}
+	switch chunk.Metadata.Outcome {
+	case types.OutcomeFailed, types.OutcomeAbandoned:
+		return FailedOutcomeFactor
+	}
+	return 1
+}
+
+// RankByOutcome scales result scores by their outcome factor and re-sorts
+// them, reporting whether any score changed. Ties keep their original order.
+func RankByOutcome(results []types.SearchResult) bool {
+	changed := false
+	for i := range results {
+		if factor := OutcomeFactor(&results[i].Chunk); factor != 1 {
+			results[i].Score *= factor
+			changed = true
+		}
+	}
+	if changed {
+		sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	}
+	return changed
+}
@@ -0,0 +1,82 @@
+package scoring
+
+import (
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRankByOutcome(t *testing.T) {
+	now := time.Now()
+	results := []types.SearchResult{
+		scoredResult("failed-verification", 0.9, types.ChunkTypeSolution, 0, now),
+		scoredResult("abandoned", 0.85, types.ChunkTypeSolution, 0, now),
+		scoredResult("plain", 0.7, types.ChunkTypeSolution, 0, now),
+		scoredResult("verified", 0.6, types.ChunkTypeSolution, 0, now),
+	}
+	results[0].Chunk.RecordVerification(types.VerificationRecord{Status: types.VerificationFailed})
+	results[1].Chunk.Metadata.Outcome = types.OutcomeAbandoned
+	results[3].Chunk.RecordVerification(types.VerificationRecord{Status: types.VerificationVerified})
+
+	assert.True(t, RankByOutcome(results))
+	ids := make([]string, 0, len(results))
+	for i := range results {
+		ids = append(ids, results[i].Chunk.ID)
+	}
+	assert.Equal(t, []string{"verified", "plain", "abandoned", "failed-verification"}, ids)
+	assert.InDelta(t, 0.75, results[0].Score, 1e-9)
+
+	plain := []types.SearchResult{scoredResult("a", 0.5, types.ChunkTypeSolution, 0, now)}
+	assert.False(t, RankByOutcome(plain))
+	assert.InDelta(t, 0.5, plain[0].Score, 1e-9)
+}
+
+func TestRankByClass(t *testing.T) {
+	now := time.Now()
+	results := []types.SearchResult{
+		scoredResult("promoted", 0.9, types.ChunkTypeDiscussion, 0, now),
+		scoredResult("episodic", 0.8, types.ChunkTypeDiscussion, 0, now),
+		scoredResult("procedural", 0.7, types.ChunkTypeSolution, 0, now),
+		scoredResult("semantic", 0.7, types.ChunkTypeDiscussion, 0, now),
+	}
+	results[0].Chunk.Metadata.PromotedTo = "semantic"
+	results[3].Chunk.Metadata.MemoryClass = types.MemoryClassSemantic
+
+	assert.True(t, RankByClass(results))
+	ids := make([]string, 0, len(results))
+	for i := range results {
+		ids = append(ids, results[i].Chunk.ID)
+	}
+	assert.Equal(t, []string{"semantic", "episodic", "procedural", "promoted"}, ids)
+	assert.InDelta(t, 0.54, results[3].Score, 1e-9)
+
+	plain := []types.SearchResult{scoredResult("a", 0.5, types.ChunkTypeProblem, 0, now)}
+	assert.False(t, RankByClass(plain))
+}
+
+func TestRankByQuality(t *testing.T) {
+	now := time.Now()
+	results := []types.SearchResult{
+		scoredResult("poor", 0.8, types.ChunkTypeSolution, 0, now),
+		scoredResult("unscored", 0.75, types.ChunkTypeSolution, 0, now),
+		scoredResult("good", 0.7, types.ChunkTypeSolution, 0, now),
+	}
+	results[0].Chunk.Metadata.Quality = &types.QualityMetrics{OverallQuality: 0.1, LastCalculated: &now}
+	results[2].Chunk.Metadata.Quality = &types.QualityMetrics{OverallQuality: 0.9, LastCalculated: &now}
+
+	assert.True(t, RankByQuality(results))
+	ids := make([]string, 0, len(results))
+	for i := range results {
+		ids = append(ids, results[i].Chunk.ID)
+	}
+	assert.Equal(t, []string{"good", "unscored", "poor"}, ids)
+	assert.InDelta(t, 0.7*1.16, results[0].Score, 1e-9)
+	assert.InDelta(t, 0.75, results[1].Score, 1e-9, "memories never scored keep their score")
+
+	average := []types.SearchResult{scoredResult("a", 0.5, types.ChunkTypeSolution, 0, now)}
+	average[0].Chunk.Metadata.Quality = &types.QualityMetrics{OverallQuality: 0.5, LastCalculated: &now}
+	assert.False(t, RankByQuality(average))
+}
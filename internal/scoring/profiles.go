@@ -0,0 +1,378 @@
+// Package scoring re-ranks search results by verified outcome, memory class
+// and quality, and with per-repository scoring profiles
+package scoring
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"lerian-mcp-memory/pkg/types"
+)
+
+// DefaultRecencyHalfLifeDays is the age at which the recency boost halves
+const DefaultRecencyHalfLifeDays = 30.0
+
+var profileNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,63}$`)
+
+// Profile weights search results at rank time for one repository. The
+// adjusted score of a result is
+//
+//	score*typePrior + recencyWeight*0.5^(ageDays/halfLife) + tagBoosts
+//
+// reduced by ArchivedPenalty (a fraction) when the chunk is archived.
+type Profile struct {
+	Name        string `json:"name"`
+	Repository  string `json:"repository"`
+	Description string `json:"description,omitempty"`
+	// Active marks the profile applied to the repository's searches
+	Active bool `json:"active"`
+
+	RecencyWeight       float64                     `json:"recency_weight"`
+	RecencyHalfLifeDays float64                     `json:"recency_half_life_days"`
+	TypePriors          map[types.ChunkType]float64 `json:"type_priors,omitempty"`
+	TagBoosts           map[string]float64          `json:"tag_boosts,omitempty"`
+	ArchivedPenalty     float64                     `json:"archived_penalty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks a profile's weights
+func (p *Profile) Validate() error {
+	if !profileNamePattern.MatchString(p.Name) {
+		return fmt.Errorf("invalid profile name %q: use lowercase letters, digits, '-' and '_'", p.Name)
+	}
+	if p.RecencyWeight < 0 || p.RecencyWeight > 1 {
+		return fmt.Errorf("recency_weight must be between 0 and 1, got %g", p.RecencyWeight)
+	}
+	if p.RecencyHalfLifeDays < 0 {
+		return fmt.Errorf("recency_half_life_days must not be negative, got %g", p.RecencyHalfLifeDays)
+	}
+	if p.ArchivedPenalty < 0 || p.ArchivedPenalty > 1 {
+		return fmt.Errorf("archived_penalty must be between 0 and 1, got %g", p.ArchivedPenalty)
+	}
+	for chunkType, prior := range p.TypePriors {
+		if !chunkType.Valid() {
+			return fmt.Errorf("invalid chunk type in type_priors: %s", chunkType)
+		}
+		if prior < 0 {
+			return fmt.Errorf("type prior for %s must not be negative, got %g", chunkType, prior)
+		}
+	}
+	for tag, boost := range p.TagBoosts {
+		if tag == "" {
+			return errors.New("tag_boosts keys must not be empty")
+		}
+		if boost < -1 || boost > 1 {
+			return fmt.Errorf("tag boost for %s must be between -1 and 1, got %g", tag, boost)
+		}
+	}
+	return nil
+}
+
+// Score returns the adjusted score of a result at time now
+func (p *Profile) Score(result *types.SearchResult, now time.Time) float64 {
+	chunk := &result.Chunk
+	score := result.Score
+	if prior, ok := p.TypePriors[chunk.Type]; ok {
+		score *= prior
+	}
+
+	if p.RecencyWeight > 0 && !chunk.Timestamp.IsZero() {
+		halfLife := p.RecencyHalfLifeDays
+		if halfLife == 0 {
+			halfLife = DefaultRecencyHalfLifeDays
+		}
+		ageDays := math.Max(0, now.Sub(chunk.Timestamp).Hours()/24)
+		score += p.RecencyWeight * math.Pow(0.5, ageDays/halfLife)
+	}
+
+	for _, tag := range chunk.Metadata.Tags {
+		score += p.TagBoosts[tag]
+	}
+
+	if p.ArchivedPenalty > 0 && IsArchived(chunk) {
+		score *= 1 - p.ArchivedPenalty
+	}
+	return score
+}
+
+// Rank re-scores results in place and sorts them by adjusted score. Ties keep
+// their original order.
+func (p *Profile) Rank(results []types.SearchResult, now time.Time) {
+	for i := range results {
+		results[i].Score = p.Score(&results[i], now)
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+}
+
+// IsArchived reports whether a chunk has been archived
+func IsArchived(chunk *types.ConversationChunk) bool {
+	return chunk.IsArchived()
+}
+
+// Store manages scoring profiles per repository. Profiles are persisted to an
+// optional JSON file so they survive restarts.
+type Store struct {
+	mu       sync.RWMutex
+	path     string
+	profiles map[string]map[string]Profile // repository -> name -> profile
+}
+
+// NewStore creates a profile store persisted at path; an empty path keeps it in memory
+func NewStore(path string) *Store {
+	return &Store{
+		path:     path,
+		profiles: make(map[string]map[string]Profile),
+	}
+}
+
+// Load reads profiles from disk. A missing file is not an error.
+func (s *Store) Load() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read scoring profiles: %w", err)
+	}
+
+	var profiles []Profile
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return fmt.Errorf("failed to parse scoring profiles: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range profiles {
+		s.putLocked(&profiles[i])
+	}
+	return nil
+}
+
+// Upsert creates or replaces a repository's profile. The first profile of a
+// repository becomes active; activate makes this one the active profile.
+func (s *Store) Upsert(repository string, profile Profile, activate bool) (*Profile, error) {
+	if repository == "" {
+		return nil, errors.New("repository is required for a scoring profile")
+	}
+	profile.Repository = repository
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := s.snapshotLocked(repository)
+	now := time.Now().UTC()
+	previous, existed := s.profiles[repository][profile.Name]
+	profile.CreatedAt = now
+	if existed {
+		profile.CreatedAt = previous.CreatedAt
+	}
+	profile.UpdatedAt = now
+	profile.Active = previous.Active || len(s.profiles[repository]) == 0
+	s.putLocked(&profile)
+	if activate {
+		s.activateLocked(repository, profile.Name)
+	}
+
+	if err := s.persistLocked(); err != nil {
+		s.profiles[repository] = snapshot
+		return nil, err
+	}
+
+	result := s.profiles[repository][profile.Name]
+	return &result, nil
+}
+
+// Activate makes a profile the one applied to its repository's searches
+func (s *Store) Activate(repository, name string) (*Profile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.profiles[repository][name]; !ok {
+		return nil, fmt.Errorf("scoring profile %q not found for %s", name, repository)
+	}
+	snapshot := s.snapshotLocked(repository)
+	s.activateLocked(repository, name)
+	if err := s.persistLocked(); err != nil {
+		s.profiles[repository] = snapshot
+		return nil, err
+	}
+
+	result := s.profiles[repository][name]
+	return &result, nil
+}
+
+// Delete removes a profile. Deleting the active profile leaves the repository
+// with plain relevance ranking until another profile is activated.
+func (s *Store) Delete(repository, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.profiles[repository][name]; !ok {
+		return fmt.Errorf("scoring profile %q not found for %s", name, repository)
+	}
+	snapshot := s.snapshotLocked(repository)
+	delete(s.profiles[repository], name)
+	if err := s.persistLocked(); err != nil {
+		s.profiles[repository] = snapshot
+		return err
+	}
+	return nil
+}
+
+// Get returns a repository's profile by name
+func (s *Store) Get(repository, name string) (Profile, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	profile, ok := s.profiles[repository][name]
+	return profile, ok
+}
+
+// ActiveProfile returns the profile applied to a repository's searches
+func (s *Store) ActiveProfile(repository string) (Profile, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for name := range s.profiles[repository] {
+		if s.profiles[repository][name].Active {
+			return s.profiles[repository][name], true
+		}
+	}
+	return Profile{}, false
+}
+
+// List returns a repository's profiles sorted by name
+func (s *Store) List(repository string) []Profile {
+	s.mu.RLock()
+	profiles := make([]Profile, 0, len(s.profiles[repository]))
+	for name := range s.profiles[repository] {
+		profiles = append(profiles, s.profiles[repository][name])
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles
+}
+
+// RenameTags moves tag boosts from old tag names to new ones in every
+// profile, as tags are renamed or merged. A boost already set for the new
+// name is kept. It returns how many profiles changed.
+func (s *Store) RenameTags(renames map[string]string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := make(map[string]map[string]Profile, len(s.profiles))
+	changed := 0
+	for repository := range s.profiles {
+		snapshot[repository] = s.snapshotLocked(repository)
+		for name, profile := range s.profiles[repository] {
+			boosts := make(map[string]float64, len(profile.TagBoosts))
+			renamed := false
+			for tag, boost := range profile.TagBoosts {
+				if to, ok := renames[tag]; ok {
+					renamed = true
+					if _, set := profile.TagBoosts[to]; set {
+						continue
+					}
+					// Tags merged into the same one keep the strongest boost
+					if merged, ok := boosts[to]; ok {
+						boost = max(merged, boost)
+					}
+					tag = to
+				}
+				boosts[tag] = boost
+			}
+			if !renamed {
+				continue
+			}
+			profile.TagBoosts = boosts
+			profile.UpdatedAt = time.Now().UTC()
+			s.profiles[repository][name] = profile
+			changed++
+		}
+	}
+	if changed == 0 {
+		return 0, nil
+	}
+
+	if err := s.persistLocked(); err != nil {
+		s.profiles = snapshot
+		return 0, err
+	}
+	return changed, nil
+}
+
+// putLocked stores a profile; callers must hold the write lock
+func (s *Store) putLocked(profile *Profile) {
+	if s.profiles[profile.Repository] == nil {
+		s.profiles[profile.Repository] = make(map[string]Profile)
+	}
+	s.profiles[profile.Repository][profile.Name] = *profile
+}
+
+// activateLocked marks one profile active and the rest inactive; callers must hold the write lock
+func (s *Store) activateLocked(repository, name string) {
+	for other, profile := range s.profiles[repository] {
+		profile.Active = other == name
+		s.profiles[repository][other] = profile
+	}
+}
+
+// snapshotLocked copies a repository's profiles so a failed write can be undone; callers must hold the lock
+func (s *Store) snapshotLocked(repository string) map[string]Profile {
+	snapshot := make(map[string]Profile, len(s.profiles[repository]))
+	for name := range s.profiles[repository] {
+		snapshot[name] = s.profiles[repository][name]
+	}
+	return snapshot
+}
+
+// persistLocked writes all profiles to disk; callers must hold the write lock
+func (s *Store) persistLocked() error {
+	if s.path == "" {
+		return nil
+	}
+
+	profiles := make([]Profile, 0)
+	for repository := range s.profiles {
+		for name := range s.profiles[repository] {
+			profiles = append(profiles, s.profiles[repository][name])
+		}
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		if profiles[i].Repository != profiles[j].Repository {
+			return profiles[i].Repository < profiles[j].Repository
+		}
+		return profiles[i].Name < profiles[j].Name
+	})
+
+	data, err := json.MarshalIndent(profiles, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode scoring profiles: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o750); err != nil {
+		return fmt.Errorf("failed to create scoring profiles directory: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write scoring profiles: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,129 @@
+package scoring
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scoredResult(id string, score float64, chunkType types.ChunkType, age time.Duration, now time.Time) types.SearchResult {
+	return types.SearchResult{
+		Score: score,
+		Chunk: types.ConversationChunk{
+			ID:        id,
+			Type:      chunkType,
+			Timestamp: now.Add(-age),
+		},
+	}
+}
+
+func TestProfileRank(t *testing.T) {
+	now := time.Now()
+	day := 24 * time.Hour
+
+	results := []types.SearchResult{
+		scoredResult("old-solution", 0.80, types.ChunkTypeSolution, 300*day, now),
+		scoredResult("fresh-discussion", 0.70, types.ChunkTypeDiscussion, 0, now),
+		scoredResult("archived", 0.90, types.ChunkTypeSolution, 300*day, now),
+		scoredResult("tagged", 0.60, types.ChunkTypeSolution, 300*day, now),
+	}
+	results[2].Chunk.Metadata.ExtendedMetadata = map[string]interface{}{types.EMKeyArchivedAt: now.Format(time.RFC3339)}
+	results[3].Chunk.Metadata.Tags = []string{"security"}
+
+	profile := Profile{
+		Name:            "fresh-first",
+		RecencyWeight:   0.2,
+		TypePriors:      map[types.ChunkType]float64{types.ChunkTypeDiscussion: 0.5},
+		TagBoosts:       map[string]float64{"security": 0.25},
+		ArchivedPenalty: 0.5,
+	}
+	require.NoError(t, profile.Validate())
+	profile.Rank(results, now)
+
+	ids := make([]string, len(results))
+	for i := range results {
+		ids[i] = results[i].Chunk.ID
+	}
+	assert.Equal(t, []string{"tagged", "old-solution", "fresh-discussion", "archived"}, ids)
+	// 0.7*0.5 discounted by type, plus the full recency boost for a fresh chunk
+	assert.InDelta(t, 0.55, results[2].Score, 1e-9)
+}
+
+func TestProfileValidate(t *testing.T) {
+	for name, profile := range map[string]Profile{
+		"bad name":         {Name: "Fresh First"},
+		"recency weight":   {Name: "p", RecencyWeight: 2},
+		"negative prior":   {Name: "p", TypePriors: map[types.ChunkType]float64{types.ChunkTypeSolution: -1}},
+		"unknown type":     {Name: "p", TypePriors: map[types.ChunkType]float64{"essay": 1}},
+		"tag boost":        {Name: "p", TagBoosts: map[string]float64{"security": 3}},
+		"archived penalty": {Name: "p", ArchivedPenalty: 1.5},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, profile.Validate())
+		})
+	}
+}
+
+func TestStoreActivationAndPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	repo := "github.com/acme/api"
+
+	store := NewStore(path)
+	first, err := store.Upsert(repo, Profile{Name: "baseline"}, false)
+	require.NoError(t, err)
+	assert.True(t, first.Active, "the first profile becomes active")
+
+	second, err := store.Upsert(repo, Profile{Name: "fresh-first", RecencyWeight: 0.3}, false)
+	require.NoError(t, err)
+	assert.False(t, second.Active)
+
+	_, err = store.Activate(repo, "fresh-first")
+	require.NoError(t, err)
+	active, ok := store.ActiveProfile(repo)
+	require.True(t, ok)
+	assert.Equal(t, "fresh-first", active.Name)
+
+	// Updating keeps the creation time and the active flag
+	updated, err := store.Upsert(repo, Profile{Name: "fresh-first", RecencyWeight: 0.4}, false)
+	require.NoError(t, err)
+	assert.True(t, updated.Active)
+	assert.Equal(t, second.CreatedAt, updated.CreatedAt)
+
+	reloaded := NewStore(path)
+	require.NoError(t, reloaded.Load())
+	profiles := reloaded.List(repo)
+	require.Len(t, profiles, 2)
+	assert.Equal(t, "baseline", profiles[0].Name)
+	assert.InDelta(t, 0.4, profiles[1].RecencyWeight, 1e-9)
+
+	require.NoError(t, reloaded.Delete(repo, "fresh-first"))
+	_, ok = reloaded.ActiveProfile(repo)
+	assert.False(t, ok, "deleting the active profile leaves none active")
+	assert.Error(t, reloaded.Delete(repo, "fresh-first"))
+	_, ok = reloaded.ActiveProfile("github.com/acme/other")
+	assert.False(t, ok)
+}
+
+func TestStoreRenameTags(t *testing.T) {
+	store := NewStore("")
+	_, err := store.Upsert("github.com/acme/api", Profile{Name: "infra", TagBoosts: map[string]float64{"k8s": 0.2, "kubernets": 0.3, "perf": 0.1}}, false)
+	require.NoError(t, err)
+	_, err = store.Upsert("github.com/acme/web", Profile{Name: "kept", TagBoosts: map[string]float64{"k8s": 0.1, "kubernetes": 0.4}}, false)
+	require.NoError(t, err)
+	_, err = store.Upsert("github.com/acme/docs", Profile{Name: "untagged"}, false)
+	require.NoError(t, err)
+
+	changed, err := store.RenameTags(map[string]string{"k8s": "kubernetes", "kubernets": "kubernetes"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, changed)
+
+	infra, _ := store.Get("github.com/acme/api", "infra")
+	assert.Equal(t, map[string]float64{"kubernetes": 0.3, "perf": 0.1}, infra.TagBoosts, "merged tags keep the strongest boost")
+	kept, _ := store.Get("github.com/acme/web", "kept")
+	assert.Equal(t, map[string]float64{"kubernetes": 0.4}, kept.TagBoosts, "a boost already set for the new name wins")
+}
@@ -0,0 +1,42 @@
+package scoring
+
+import (
+	"sort"
+
+	"lerian-mcp-memory/pkg/types"
+)
+
+// Quality factors multiply the relevance of a result by its stored quality
+// score: a memory of average quality (0.5) keeps its score, the best gain
+// up to MaxQualityFactor and the worst lose down to MinQualityFactor.
+// Memories never scored are left alone.
+const (
+	MinQualityFactor = 0.8
+	MaxQualityFactor = 1.2
+)
+
+// QualityFactor returns the relevance multiplier for a chunk's quality
+func QualityFactor(chunk *types.ConversationChunk) float64 {
+	quality := chunk.Metadata.Quality
+	if quality == nil || quality.LastCalculated == nil {
+		return 1
+	}
+	overall := min(1, max(0, quality.OverallQuality))
+	return MinQualityFactor + (MaxQualityFactor-MinQualityFactor)*overall
+}
+
+// RankByQuality scales result scores by their quality factor and re-sorts
+// them, reporting whether any score changed. Ties keep their original order.
+func RankByQuality(results []types.SearchResult) bool {
+	changed := false
+	for i := range results {
+		if factor := QualityFactor(&results[i].Chunk); factor != 1 {
+			results[i].Score *= factor
+			changed = true
+		}
+	}
+	if changed {
+		sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	}
+	return changed
+}
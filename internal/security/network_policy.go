@@ -0,0 +1,247 @@
+package security
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Endpoint groups a network policy can grant
+const (
+	EndpointMCP    = "mcp"    // JSON-RPC over HTTP (/mcp)
+	EndpointSSE    = "sse"    // Server-Sent Events (/sse)
+	EndpointWS     = "ws"     // WebSocket updates (/ws)
+	EndpointHealth = "health" // Health checks (/health)
+	EndpointAdmin  = "admin"  // Operator endpoints such as /metrics
+
+	// EndpointAll grants every endpoint group
+	EndpointAll = "*"
+)
+
+// validEndpoints lists the endpoint groups accepted in a policy
+var validEndpoints = map[string]bool{
+	EndpointMCP: true, EndpointSSE: true, EndpointWS: true, EndpointHealth: true, EndpointAdmin: true, EndpointAll: true,
+}
+
+// ErrNetworkDenied is returned when a client address may not reach an endpoint
+var ErrNetworkDenied = errors.New("access denied by network policy")
+
+// NetworkZone is a named set of address ranges and the endpoints they may reach
+type NetworkZone struct {
+	Name      string   `json:"name" yaml:"name"`
+	CIDRs     []string `json:"cidrs" yaml:"cidrs"`
+	Endpoints []string `json:"endpoints" yaml:"endpoints"`
+
+	prefixes []netip.Prefix
+}
+
+// NetworkPolicy decides which endpoints a client address may reach. Zones are
+// matched in order and the first zone containing the address applies;
+// addresses outside every zone get the default endpoints.
+type NetworkPolicy struct {
+	Zones             []NetworkZone `json:"zones" yaml:"zones"`
+	DefaultEndpoints  []string      `json:"default_endpoints" yaml:"default_endpoints"`
+	TrustProxyHeaders bool          `json:"trust_proxy_headers" yaml:"trust_proxy_headers"`
+}
+
+// LoadNetworkPolicy reads a YAML network policy
+func LoadNetworkPolicy(path string) (*NetworkPolicy, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // Path comes from operator configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read network policy: %w", err)
+	}
+
+	var policy NetworkPolicy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse network policy: %w", err)
+	}
+	if err := policy.Compile(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// NewAllowlistPolicy builds a policy from simple allowlists. When allowlist is
+// set, only those ranges reach the server; when adminAllowlist is set, admin
+// endpoints are reachable only from those ranges.
+func NewAllowlistPolicy(allowlist, adminAllowlist []string, trustProxyHeaders bool) (*NetworkPolicy, error) {
+	clientEndpoints := []string{EndpointMCP, EndpointSSE, EndpointWS, EndpointHealth}
+	if len(adminAllowlist) == 0 {
+		clientEndpoints = []string{EndpointAll}
+	}
+
+	policy := &NetworkPolicy{TrustProxyHeaders: trustProxyHeaders}
+	if len(adminAllowlist) > 0 {
+		policy.Zones = append(policy.Zones, NetworkZone{Name: "admin", CIDRs: adminAllowlist, Endpoints: []string{EndpointAll}})
+	}
+	if len(allowlist) > 0 {
+		policy.Zones = append(policy.Zones, NetworkZone{Name: "allowlist", CIDRs: allowlist, Endpoints: clientEndpoints})
+	} else {
+		policy.DefaultEndpoints = clientEndpoints
+	}
+
+	if err := policy.Compile(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Compile validates the policy and parses its address ranges. Plain IP
+// addresses are accepted as single-host ranges.
+func (p *NetworkPolicy) Compile() error {
+	if err := validateEndpoints("default", p.DefaultEndpoints); err != nil {
+		return err
+	}
+	for i := range p.Zones {
+		zone := &p.Zones[i]
+		if zone.Name == "" {
+			zone.Name = fmt.Sprintf("zone-%d", i+1)
+		}
+		if len(zone.CIDRs) == 0 {
+			return fmt.Errorf("network zone %q has no address ranges", zone.Name)
+		}
+		if err := validateEndpoints(zone.Name, zone.Endpoints); err != nil {
+			return err
+		}
+		zone.prefixes = make([]netip.Prefix, 0, len(zone.CIDRs))
+		for _, cidr := range zone.CIDRs {
+			prefix, err := ParsePrefix(cidr)
+			if err != nil {
+				return fmt.Errorf("network zone %q: %w", zone.Name, err)
+			}
+			zone.prefixes = append(zone.prefixes, prefix)
+		}
+	}
+	return nil
+}
+
+// ParsePrefix parses a CIDR range or a single IP address
+func ParsePrefix(value string) (netip.Prefix, error) {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid CIDR %q", value)
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid IP address %q", value)
+	}
+	return netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()), nil
+}
+
+// validateEndpoints rejects unknown endpoint groups
+func validateEndpoints(owner string, endpoints []string) error {
+	for _, endpoint := range endpoints {
+		if !validEndpoints[endpoint] {
+			return fmt.Errorf("network zone %q: unknown endpoint %q (must be one of mcp, sse, ws, health, admin or *)", owner, endpoint)
+		}
+	}
+	return nil
+}
+
+// Allows reports whether addr may reach the endpoint group
+func (p *NetworkPolicy) Allows(addr netip.Addr, endpoint string) bool {
+	endpoints := p.DefaultEndpoints
+	if zone := p.match(addr); zone != nil {
+		endpoints = zone.Endpoints
+	}
+	return grantsEndpoint(endpoints, endpoint)
+}
+
+// AdminZone reports whether the client of r is in an admin network: a zone
+// granting the admin endpoints in a policy that keeps them from other
+// clients, because another zone or the default endpoints grant endpoints but
+// not the admin ones. A policy letting every client it admits reach the admin
+// endpoints sets no admin network apart.
+func (p *NetworkPolicy) AdminZone(r *http.Request) bool {
+	addr, err := p.ClientAddr(r)
+	if err != nil {
+		return false
+	}
+	zone := p.match(addr)
+	if zone == nil || !grantsEndpoint(zone.Endpoints, EndpointAdmin) {
+		return false
+	}
+	if len(p.DefaultEndpoints) > 0 && !grantsEndpoint(p.DefaultEndpoints, EndpointAdmin) {
+		return true
+	}
+	for i := range p.Zones {
+		if len(p.Zones[i].Endpoints) > 0 && !grantsEndpoint(p.Zones[i].Endpoints, EndpointAdmin) {
+			return true
+		}
+	}
+	return false
+}
+
+// grantsEndpoint reports whether endpoints include the endpoint group
+func grantsEndpoint(endpoints []string, endpoint string) bool {
+	for _, e := range endpoints {
+		if e == EndpointAll || e == endpoint {
+			return true
+		}
+	}
+	return false
+}
+
+// match returns the first zone containing addr
+func (p *NetworkPolicy) match(addr netip.Addr) *NetworkZone {
+	addr = addr.Unmap()
+	for i := range p.Zones {
+		for _, prefix := range p.Zones[i].prefixes {
+			if prefix.Contains(addr) {
+				return &p.Zones[i]
+			}
+		}
+	}
+	return nil
+}
+
+// ClientAddr returns the address of the client that sent r. Proxy headers are
+// used only when trusted; the last X-Forwarded-For entry, across every header
+// line, is the one added by the trusted proxy and cannot be forged by the client.
+func (p *NetworkPolicy) ClientAddr(r *http.Request) (netip.Addr, error) {
+	if p.TrustProxyHeaders {
+		if lines := r.Header.Values("X-Forwarded-For"); len(lines) > 0 {
+			hops := strings.Split(lines[len(lines)-1], ",")
+			if addr, err := netip.ParseAddr(strings.TrimSpace(hops[len(hops)-1])); err == nil {
+				return addr.Unmap(), nil
+			}
+		}
+		if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+			if addr, err := netip.ParseAddr(strings.TrimSpace(realIP)); err == nil {
+				return addr.Unmap(), nil
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("invalid client address %q", r.RemoteAddr)
+	}
+	return addr.Unmap(), nil
+}
+
+// Check returns ErrNetworkDenied when the client of r may not reach the endpoint
+func (p *NetworkPolicy) Check(r *http.Request, endpoint string) error {
+	addr, err := p.ClientAddr(r)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrNetworkDenied, err)
+	}
+	if !p.Allows(addr, endpoint) {
+		return fmt.Errorf("%w: %s may not reach %s", ErrNetworkDenied, addr, endpoint)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadNetworkPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+zones:
+  - name: local
+    cidrs: ["127.0.0.0/8", "::1"]
+    endpoints: ["*"]
+  - name: vpn
+    cidrs: ["10.8.0.0/16"]
+    endpoints: ["mcp", "ws"]
+default_endpoints: ["health"]
+`), 0o600))
+
+	policy, err := LoadNetworkPolicy(path)
+	require.NoError(t, err)
+
+	tests := []struct {
+		addr     string
+		endpoint string
+		allowed  bool
+	}{
+		{"127.0.0.1", EndpointAdmin, true},
+		{"::1", EndpointAdmin, true},
+		{"::ffff:127.0.0.1", EndpointMCP, true},
+		{"10.8.3.4", EndpointMCP, true},
+		{"10.8.3.4", EndpointWS, true},
+		{"10.8.3.4", EndpointAdmin, false},
+		{"10.8.3.4", EndpointHealth, false},
+		{"198.51.100.1", EndpointHealth, true},
+		{"198.51.100.1", EndpointMCP, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr+"/"+tt.endpoint, func(t *testing.T) {
+			assert.Equal(t, tt.allowed, policy.Allows(netip.MustParseAddr(tt.addr), tt.endpoint))
+		})
+	}
+}
+
+func TestNetworkPolicyCompileErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy NetworkPolicy
+		errMsg string
+	}{
+		{"bad cidr", NetworkPolicy{Zones: []NetworkZone{{Name: "x", CIDRs: []string{"10.0.0.0/33"}, Endpoints: []string{EndpointAll}}}}, "invalid CIDR"},
+		{"bad ip", NetworkPolicy{Zones: []NetworkZone{{Name: "x", CIDRs: []string{"not-an-ip"}}}}, "invalid IP address"},
+		{"no ranges", NetworkPolicy{Zones: []NetworkZone{{Name: "x"}}}, "no address ranges"},
+		{"unknown endpoint", NetworkPolicy{Zones: []NetworkZone{{Name: "x", CIDRs: []string{"10.0.0.1"}, Endpoints: []string{"graphql"}}}}, "unknown endpoint"},
+		{"unknown default endpoint", NetworkPolicy{DefaultEndpoints: []string{"api"}}, "unknown endpoint"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Compile()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestNewAllowlistPolicy(t *testing.T) {
+	office := netip.MustParseAddr("192.168.1.20")
+	admin := netip.MustParseAddr("127.0.0.1")
+	outside := netip.MustParseAddr("203.0.113.9")
+
+	t.Run("client allowlist only", func(t *testing.T) {
+		policy, err := NewAllowlistPolicy([]string{"192.168.1.0/24"}, nil, false)
+		require.NoError(t, err)
+		assert.True(t, policy.Allows(office, EndpointMCP))
+		assert.True(t, policy.Allows(office, EndpointAdmin))
+		assert.False(t, policy.Allows(outside, EndpointHealth))
+	})
+
+	t.Run("admin allowlist only", func(t *testing.T) {
+		policy, err := NewAllowlistPolicy(nil, []string{"127.0.0.1"}, false)
+		require.NoError(t, err)
+		assert.True(t, policy.Allows(outside, EndpointMCP))
+		assert.False(t, policy.Allows(outside, EndpointAdmin))
+		assert.True(t, policy.Allows(admin, EndpointAdmin))
+	})
+
+	t.Run("both allowlists", func(t *testing.T) {
+		policy, err := NewAllowlistPolicy([]string{"192.168.1.0/24"}, []string{"127.0.0.1"}, false)
+		require.NoError(t, err)
+		assert.True(t, policy.Allows(office, EndpointWS))
+		assert.False(t, policy.Allows(office, EndpointAdmin))
+		assert.True(t, policy.Allows(admin, EndpointMCP))
+		assert.False(t, policy.Allows(outside, EndpointMCP))
+	})
+}
+
+func TestNetworkPolicyClientAddr(t *testing.T) {
+	req := httptest.NewRequest("GET", "/health", nil)
+	req.RemoteAddr = "10.0.0.5:51234"
+	req.Header.Set("X-Forwarded-For", "127.0.0.1, 203.0.113.9")
+
+	policy, err := NewAllowlistPolicy([]string{"127.0.0.1"}, nil, false)
+	require.NoError(t, err)
+
+	addr, err := policy.ClientAddr(req)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.5", addr.String(), "proxy headers are ignored unless trusted")
+	assert.ErrorIs(t, policy.Check(req, EndpointHealth), ErrNetworkDenied)
+
+	policy.TrustProxyHeaders = true
+	addr, err = policy.ClientAddr(req)
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.9", addr.String(), "the proxy-appended hop is used, not the client-supplied one")
+	assert.ErrorIs(t, policy.Check(req, EndpointHealth), ErrNetworkDenied)
+
+	// A proxy may append its hop as a header line of its own after the client's
+	req.Header.Del("X-Forwarded-For")
+	req.Header.Add("X-Forwarded-For", "127.0.0.1")
+	req.Header.Add("X-Forwarded-For", "198.51.100.7, 203.0.113.9")
+	addr, err = policy.ClientAddr(req)
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.9", addr.String(), "the last hop of the last header line is used")
+	assert.ErrorIs(t, policy.Check(req, EndpointHealth), ErrNetworkDenied)
+}
+
+func TestNetworkPolicyAdminZone(t *testing.T) {
+	request := func(addr string) *http.Request {
+		req := httptest.NewRequest("GET", "/metrics", nil)
+		req.RemoteAddr = addr + ":51234"
+		return req
+	}
+
+	t.Run("admin allowlist", func(t *testing.T) {
+		policy, err := NewAllowlistPolicy([]string{"192.168.1.0/24"}, []string{"127.0.0.1"}, false)
+		require.NoError(t, err)
+		assert.True(t, policy.AdminZone(request("127.0.0.1")))
+		assert.False(t, policy.AdminZone(request("192.168.1.20")))
+		assert.False(t, policy.AdminZone(request("203.0.113.9")))
+	})
+
+	t.Run("client allowlist only", func(t *testing.T) {
+		policy, err := NewAllowlistPolicy([]string{"192.168.1.0/24"}, nil, false)
+		require.NoError(t, err)
+		assert.False(t, policy.AdminZone(request("192.168.1.20")), "a policy admitting every client to admin endpoints sets no admin network apart")
+	})
+
+	t.Run("default endpoints", func(t *testing.T) {
+		policy := &NetworkPolicy{DefaultEndpoints: []string{EndpointAll}}
+		require.NoError(t, policy.Compile())
+		assert.False(t, policy.AdminZone(request("127.0.0.1")), "addresses outside every zone are never an admin network")
+	})
+}
@@ -0,0 +1,500 @@
+package security
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProtectedResourcePath is where the OAuth protected resource metadata of a
+// server is published (RFC 9728)
+const ProtectedResourcePath = "/.well-known/oauth-protected-resource"
+
+const (
+	// maxJWKSSize bounds the key sets and authorization server metadata read
+	maxJWKSSize = 1 << 20
+
+	// minJWKSRefresh is how often an unknown key ID may trigger a key set refetch
+	minJWKSRefresh = time.Minute
+)
+
+// OAuth authorization errors
+var (
+	ErrMissingToken      = errors.New("bearer token required")
+	ErrInvalidToken      = errors.New("invalid bearer token")
+	ErrExpiredToken      = errors.New("bearer token has expired")
+	ErrInsufficientScope = errors.New("bearer token lacks the required scope")
+)
+
+// TokenClaims are the validated claims of a bearer token
+type TokenClaims struct {
+	Subject   string    `json:"sub"`
+	Issuer    string    `json:"iss"`
+	ClientID  string    `json:"client_id,omitempty"`
+	Scopes    []string  `json:"scopes"`
+	Projects  []string  `json:"projects,omitempty"` // repositories of the token's tenant, from the projects claim
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type claimsContextKey struct{}
+
+// WithTokenClaims returns a context carrying the claims of the token that authorized a request
+func WithTokenClaims(ctx context.Context, claims *TokenClaims) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// TokenClaimsFrom returns the claims of the token that authorized the request served with ctx, or nil
+func TokenClaimsFrom(ctx context.Context) *TokenClaims {
+	claims, _ := ctx.Value(claimsContextKey{}).(*TokenClaims)
+	return claims
+}
+
+// HasScope reports whether the token grants scope. The empty scope is always granted.
+func (c *TokenClaims) HasScope(scope string) bool {
+	return scope == "" || slices.Contains(c.Scopes, scope)
+}
+
+// ProtectedResourceMetadata describes this server as an OAuth protected
+// resource, telling clients which authorization servers issue its tokens
+type ProtectedResourceMetadata struct {
+	Resource               string   `json:"resource"`
+	AuthorizationServers   []string `json:"authorization_servers"`
+	ScopesSupported        []string `json:"scopes_supported,omitempty"`
+	BearerMethodsSupported []string `json:"bearer_methods_supported"`
+}
+
+// ProtectedResourceMetadataURL is where the metadata of resource is
+// published: the well-known path inserted between its host and path
+func ProtectedResourceMetadataURL(resource string) (string, error) {
+	parsed, err := url.Parse(resource)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return "", fmt.Errorf("invalid resource URI %q", resource)
+	}
+	return parsed.Scheme + "://" + parsed.Host + ProtectedResourcePath + strings.TrimSuffix(parsed.Path, "/"), nil
+}
+
+// BearerChallenge builds the WWW-Authenticate header of a rejected request.
+// errorCode is empty when no token was sent, as RFC 6750 asks.
+func BearerChallenge(metadataURL, errorCode, description, scope string) string {
+	params := []string{fmt.Sprintf("resource_metadata=%q", metadataURL)}
+	if errorCode != "" {
+		params = append(params, fmt.Sprintf("error=%q", errorCode))
+	}
+	if description != "" {
+		params = append(params, fmt.Sprintf("error_description=%q", description))
+	}
+	if scope != "" {
+		params = append(params, fmt.Sprintf("scope=%q", scope))
+	}
+	return "Bearer " + strings.Join(params, ", ")
+}
+
+// BearerToken returns the token of the request's Authorization header
+func BearerToken(r *http.Request) (string, error) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return "", ErrMissingToken
+	}
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", fmt.Errorf("%w: expected an Authorization: Bearer header", ErrInvalidToken)
+	}
+	return strings.TrimSpace(token), nil
+}
+
+// AccessTokenParam is the query parameter a WebSocket upgrade may carry its
+// token in (RFC 6750), as browsers cannot set headers on one
+const AccessTokenParam = "access_token"
+
+// UpgradeToken returns the token of a WebSocket upgrade request: its
+// Authorization header's bearer token, else its access_token query parameter
+func UpgradeToken(r *http.Request) (string, error) {
+	token, err := BearerToken(r)
+	if errors.Is(err, ErrMissingToken) {
+		if param := strings.TrimSpace(r.URL.Query().Get(AccessTokenParam)); param != "" {
+			return param, nil
+		}
+	}
+	return token, err
+}
+
+// OAuthValidator validates JWT bearer tokens issued by an authorization
+// server. Tokens must be signed with a key of the server's JWKS, issued by
+// it, meant for this resource and unexpired. Keys are cached and refetched
+// when they expire or a token names an unknown key, which follows key
+// rotation without a restart.
+type OAuthValidator struct {
+	issuer   string
+	audience string
+	jwksURL  string
+	cacheTTL time.Duration
+	skew     time.Duration
+	client   *http.Client
+	now      func() time.Time
+
+	mutex     sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// NewOAuthValidator creates a validator for tokens from issuer meant for
+// audience. With an empty jwksURL the key set is discovered from the
+// issuer's authorization server metadata.
+func NewOAuthValidator(issuer, audience, jwksURL string, cacheTTL, skew time.Duration) *OAuthValidator {
+	return &OAuthValidator{
+		issuer:   issuer,
+		audience: audience,
+		jwksURL:  jwksURL,
+		cacheTTL: cacheTTL,
+		skew:     skew,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		now:      time.Now,
+	}
+}
+
+// jwtHeader is the JOSE header of a token
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// jwtClaims are the registered claims a token is checked against
+type jwtClaims struct {
+	Issuer    string          `json:"iss"`
+	Subject   string          `json:"sub"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *float64        `json:"exp"`
+	NotBefore *float64        `json:"nbf"`
+	ClientID  string          `json:"client_id"`
+	Scope     string          `json:"scope"`
+	Scp       json.RawMessage `json:"scp"`
+	Projects  json.RawMessage `json:"projects"`
+}
+
+// Validate verifies a bearer token and returns its claims
+func (v *OAuthValidator) Validate(ctx context.Context, token string) (*TokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: not a JWT", ErrInvalidToken)
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	key, err := v.key(ctx, header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+	if err := verifySignature(header.Algorithm, key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	return v.checkClaims(&claims)
+}
+
+// checkClaims checks the issuer, audience and lifetime of a verified token
+func (v *OAuthValidator) checkClaims(claims *jwtClaims) (*TokenClaims, error) {
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return nil, fmt.Errorf("%w: issued by %q", ErrInvalidToken, claims.Issuer)
+	}
+	if v.audience != "" && !slices.Contains(stringOrList(claims.Audience), v.audience) {
+		return nil, fmt.Errorf("%w: not issued for %s", ErrInvalidToken, v.audience)
+	}
+
+	now := v.now()
+	if claims.ExpiresAt == nil {
+		return nil, fmt.Errorf("%w: no expiry", ErrInvalidToken)
+	}
+	expiresAt := time.Unix(int64(*claims.ExpiresAt), 0)
+	if !now.Before(expiresAt.Add(v.skew)) {
+		return nil, ErrExpiredToken
+	}
+	if claims.NotBefore != nil && now.Add(v.skew).Before(time.Unix(int64(*claims.NotBefore), 0)) {
+		return nil, fmt.Errorf("%w: not valid yet", ErrInvalidToken)
+	}
+
+	scopes := strings.Fields(claims.Scope)
+	if len(scopes) == 0 {
+		scopes = stringOrList(claims.Scp)
+	}
+	return &TokenClaims{
+		Subject:   claims.Subject,
+		Issuer:    claims.Issuer,
+		ClientID:  claims.ClientID,
+		Scopes:    scopes,
+		Projects:  stringOrList(claims.Projects),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// key returns the verification key named kid, fetching the key set when it
+// is stale or does not hold the key
+func (v *OAuthValidator) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	now := v.now()
+	stale := v.keys == nil || now.Sub(v.fetchedAt) >= v.cacheTTL
+	key, ok := v.lookup(kid)
+	if !stale && (ok || now.Sub(v.fetchedAt) < minJWKSRefresh) {
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown signing key %q", ErrInvalidToken, kid)
+		}
+		return key, nil
+	}
+
+	keys, err := v.fetchKeys(ctx)
+	if err != nil {
+		if ok {
+			// Keep serving the cached key while the authorization server is unreachable
+			return key, nil
+		}
+		return nil, err
+	}
+	v.keys, v.fetchedAt = keys, now
+	if key, ok = v.lookup(kid); !ok {
+		return nil, fmt.Errorf("%w: unknown signing key %q", ErrInvalidToken, kid)
+	}
+	return key, nil
+}
+
+// lookup finds a cached key. A token without a key ID matches a key set holding a single key.
+func (v *OAuthValidator) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+// fetchKeys fetches the key set, discovering its URL first when needed
+func (v *OAuthValidator) fetchKeys(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	if v.jwksURL == "" {
+		jwksURL, err := v.discoverJWKSURL(ctx)
+		if err != nil {
+			return nil, err
+		}
+		v.jwksURL = jwksURL
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := v.getJSON(ctx, v.jwksURL, &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for i := range set.Keys {
+		if set.Keys[i].Use != "" && set.Keys[i].Use != "sig" {
+			continue
+		}
+		key, err := set.Keys[i].publicKey()
+		if err != nil {
+			continue // Skip key types this server cannot verify with
+		}
+		keys[set.Keys[i].KeyID] = key
+	}
+	return keys, nil
+}
+
+// discoverJWKSURL reads the issuer's authorization server metadata (RFC
+// 8414), falling back to its OpenID Connect discovery document
+func (v *OAuthValidator) discoverJWKSURL(ctx context.Context) (string, error) {
+	issuer, err := url.Parse(v.issuer)
+	if err != nil || issuer.Host == "" {
+		return "", fmt.Errorf("cannot discover the JWKS of issuer %q", v.issuer)
+	}
+	path := strings.TrimSuffix(issuer.Path, "/")
+	candidates := []string{
+		issuer.Scheme + "://" + issuer.Host + "/.well-known/oauth-authorization-server" + path,
+		strings.TrimSuffix(v.issuer, "/") + "/.well-known/openid-configuration",
+	}
+
+	var lastErr error
+	for _, candidate := range candidates {
+		var metadata struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if lastErr = v.getJSON(ctx, candidate, &metadata); lastErr != nil {
+			continue
+		}
+		if metadata.JWKSURI != "" {
+			return metadata.JWKSURI, nil
+		}
+		lastErr = fmt.Errorf("%s has no jwks_uri", candidate)
+	}
+	return "", fmt.Errorf("failed to discover the JWKS of issuer %s: %w", v.issuer, lastErr)
+}
+
+// getJSON fetches a JSON document
+func (v *OAuthValidator) getJSON(ctx context.Context, target string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, http.NoBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %s", target, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxJWKSSize)).Decode(out)
+}
+
+// jsonWebKey is a public key of a JWKS (RFC 7517)
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+// publicKey decodes an RSA or EC key
+func (k *jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if _, err := key.ECDH(); err != nil {
+			return nil, fmt.Errorf("invalid EC key: %w", err)
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.KeyType)
+	}
+}
+
+// verifySignature checks a token signature. Only asymmetric algorithms are
+// accepted, so a token cannot be signed with the public key as an HMAC secret.
+func verifySignature(algorithm string, key crypto.PublicKey, signed, signature []byte) error {
+	var newHash func() hash.Hash
+	var cryptoHash crypto.Hash
+	switch algorithm[len(algorithm)-min(3, len(algorithm)):] {
+	case "256":
+		newHash, cryptoHash = sha256.New, crypto.SHA256
+	case "384":
+		newHash, cryptoHash = sha512.New384, crypto.SHA384
+	case "512":
+		newHash, cryptoHash = sha512.New, crypto.SHA512
+	default:
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, algorithm)
+	}
+	h := newHash()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	var err error
+	switch {
+	case strings.HasPrefix(algorithm, "RS"), strings.HasPrefix(algorithm, "PS"):
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: %s needs an RSA key", ErrInvalidToken, algorithm)
+		}
+		if algorithm[0] == 'R' {
+			err = rsa.VerifyPKCS1v15(rsaKey, cryptoHash, digest, signature)
+		} else {
+			err = rsa.VerifyPSS(rsaKey, cryptoHash, digest, signature, nil)
+		}
+	case strings.HasPrefix(algorithm, "ES"):
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(signature)%2 != 0 {
+			return fmt.Errorf("%w: %s needs an EC key", ErrInvalidToken, algorithm)
+		}
+		half := len(signature) / 2
+		if !ecdsa.Verify(ecKey, digest, new(big.Int).SetBytes(signature[:half]), new(big.Int).SetBytes(signature[half:])) {
+			err = errors.New("verification failed")
+		}
+	default:
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, algorithm)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: bad signature", ErrInvalidToken)
+	}
+	return nil
+}
+
+// decodeSegment decodes a base64url JSON segment of a token
+func decodeSegment(segment string, out interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("%w: malformed segment", ErrInvalidToken)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("%w: malformed segment", ErrInvalidToken)
+	}
+	return nil
+}
+
+// stringOrList decodes a claim that is either a string or a list of strings
+func stringOrList(raw json.RawMessage) []string {
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return strings.Fields(single)
+	}
+	var list []string
+	_ = json.Unmarshal(raw, &list)
+	return list
+}
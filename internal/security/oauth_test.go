@@ -0,0 +1,230 @@
+package security
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer   = "https://auth.example.com"
+	testResource = "https://memory.example.com/mcp"
+)
+
+// testAuthServer serves the JWKS of an authorization server and signs its tokens
+type testAuthServer struct {
+	*httptest.Server
+	rsaKey  *rsa.PrivateKey
+	ecKey   *ecdsa.PrivateKey
+	keyID   string
+	fetches atomic.Int32
+}
+
+func newTestAuthServer(t *testing.T) *testAuthServer {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	as := &testAuthServer{rsaKey: rsaKey, ecKey: ecKey, keyID: "rsa-1"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/oauth-authorization-server", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"issuer": as.URL, "jwks_uri": as.URL + "/jwks"})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, _ *http.Request) {
+		as.fetches.Add(1)
+		b64 := base64.RawURLEncoding.EncodeToString
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{
+			{"kty": "RSA", "kid": as.keyID, "use": "sig", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32)))},
+			{"kty": "oct", "kid": "hmac-1", "k": "c2VjcmV0"},
+		}})
+	})
+	as.Server = httptest.NewServer(mux)
+	t.Cleanup(as.Close)
+	return as
+}
+
+// sign issues a token signed with the RSA key, or the EC key for ES256
+func (as *testAuthServer) sign(t *testing.T, alg, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	b64 := base64.RawURLEncoding.EncodeToString
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signed := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch alg {
+	case "RS256":
+		signature, err = rsa.SignPKCS1v15(rand.Reader, as.rsaKey, crypto.SHA256, digest[:])
+	case "ES256":
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, as.ecKey, digest[:])
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	default:
+		signature = []byte("forged")
+	}
+	require.NoError(t, err)
+	return signed + "." + b64(signature)
+}
+
+func validClaims(now time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"iss":   testIssuer,
+		"sub":   "agent-7",
+		"aud":   testResource,
+		"exp":   now.Add(time.Hour).Unix(),
+		"scope": "memory:read memory:write",
+	}
+}
+
+func TestOAuthValidatorValidate(t *testing.T) {
+	as := newTestAuthServer(t)
+	now := time.Unix(1700000000, 0)
+	validator := NewOAuthValidator(testIssuer, testResource, as.URL+"/jwks", time.Hour, time.Minute)
+	validator.now = func() time.Time { return now }
+
+	claims, err := validator.Validate(context.Background(), as.sign(t, "RS256", as.keyID, validClaims(now)))
+	require.NoError(t, err)
+	assert.Equal(t, "agent-7", claims.Subject)
+	assert.True(t, claims.HasScope("memory:write"))
+	assert.False(t, claims.HasScope("memory:admin"))
+
+	ecClaims := validClaims(now)
+	delete(ecClaims, "scope")
+	ecClaims["scp"] = []string{"memory:read"}
+	ecClaims["aud"] = []string{"https://other.example.com", testResource}
+	ecClaims["projects"] = []string{"github.com/acme/api", "github.com/acme/web"}
+	claims, err = validator.Validate(context.Background(), as.sign(t, "ES256", "ec-1", ecClaims))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"memory:read"}, claims.Scopes)
+	assert.Equal(t, []string{"github.com/acme/api", "github.com/acme/web"}, claims.Projects)
+	assert.Same(t, claims, TokenClaimsFrom(WithTokenClaims(context.Background(), claims)))
+
+	with := func(key string, value interface{}) map[string]interface{} {
+		claims := validClaims(now)
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"not a JWT", "opaque-token", ErrInvalidToken},
+		{"other issuer", as.sign(t, "RS256", as.keyID, with("iss", "https://evil.example.com")), ErrInvalidToken},
+		{"other audience", as.sign(t, "RS256", as.keyID, with("aud", "https://other.example.com")), ErrInvalidToken},
+		{"expired", as.sign(t, "RS256", as.keyID, with("exp", now.Add(-2*time.Minute).Unix())), ErrExpiredToken},
+		{"no expiry", as.sign(t, "RS256", as.keyID, with("exp", nil)), ErrInvalidToken},
+		{"not valid yet", as.sign(t, "RS256", as.keyID, with("nbf", now.Add(time.Hour).Unix())), ErrInvalidToken},
+		{"symmetric algorithm", as.sign(t, "HS256", "hmac-1", validClaims(now)), ErrInvalidToken},
+		{"no algorithm", as.sign(t, "none", as.keyID, validClaims(now)), ErrInvalidToken},
+		{"key of another type", as.sign(t, "RS256", "ec-1", validClaims(now)), ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validator.Validate(context.Background(), tt.token)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+
+	tampered := as.sign(t, "RS256", as.keyID, validClaims(now))
+	forged := as.sign(t, "RS256", as.keyID, with("scope", "memory:admin"))
+	_, err = validator.Validate(context.Background(), forged[:len(forged)-10]+tampered[len(tampered)-10:])
+	assert.ErrorIs(t, err, ErrInvalidToken, "a signature of other claims must not verify")
+}
+
+func TestOAuthValidatorFollowsKeyRotation(t *testing.T) {
+	as := newTestAuthServer(t)
+	now := time.Unix(1700000000, 0)
+	// The key set is discovered from the issuer's authorization server metadata
+	validator := NewOAuthValidator(as.URL, testResource, "", time.Hour, time.Minute)
+	validator.now = func() time.Time { return now }
+
+	claims := validClaims(now)
+	claims["iss"] = as.URL
+	_, err := validator.Validate(context.Background(), as.sign(t, "RS256", "rsa-1", claims))
+	require.NoError(t, err)
+	_, err = validator.Validate(context.Background(), as.sign(t, "RS256", "rsa-1", claims))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), as.fetches.Load(), "keys are cached")
+
+	as.keyID = "rsa-2"
+	_, err = validator.Validate(context.Background(), as.sign(t, "RS256", "rsa-2", claims))
+	assert.ErrorIs(t, err, ErrInvalidToken, "unknown keys are not refetched right after a fetch")
+
+	now = now.Add(2 * minJWKSRefresh)
+	_, err = validator.Validate(context.Background(), as.sign(t, "RS256", "rsa-2", claims))
+	require.NoError(t, err, "a rotated key is fetched")
+	assert.Equal(t, int32(2), as.fetches.Load())
+}
+
+func TestBearerToken(t *testing.T) {
+	req := httptest.NewRequest("POST", "/mcp", nil)
+	_, err := BearerToken(req)
+	assert.ErrorIs(t, err, ErrMissingToken)
+
+	req.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	_, err = BearerToken(req)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	req.Header.Set("Authorization", "bearer abc.def.ghi")
+	token, err := BearerToken(req)
+	require.NoError(t, err)
+	assert.Equal(t, "abc.def.ghi", token)
+}
+
+func TestUpgradeToken(t *testing.T) {
+	req := httptest.NewRequest("GET", "/ws", nil)
+	_, err := UpgradeToken(req)
+	assert.ErrorIs(t, err, ErrMissingToken)
+
+	req = httptest.NewRequest("GET", "/ws?access_token=abc.def.ghi", nil)
+	token, err := UpgradeToken(req)
+	require.NoError(t, err)
+	assert.Equal(t, "abc.def.ghi", token)
+
+	req.Header.Set("Authorization", "Bearer jkl.mno.pqr")
+	token, err = UpgradeToken(req)
+	require.NoError(t, err)
+	assert.Equal(t, "jkl.mno.pqr", token, "the header takes precedence")
+}
+
+func TestProtectedResourceMetadataURL(t *testing.T) {
+	metadataURL, err := ProtectedResourceMetadataURL(testResource)
+	require.NoError(t, err)
+	assert.Equal(t, "https://memory.example.com/.well-known/oauth-protected-resource/mcp", metadataURL)
+
+	metadataURL, err = ProtectedResourceMetadataURL("https://memory.example.com/")
+	require.NoError(t, err)
+	assert.Equal(t, "https://memory.example.com/.well-known/oauth-protected-resource", metadataURL)
+
+	_, err = ProtectedResourceMetadataURL("memory.example.com")
+	assert.Error(t, err)
+
+	assert.Equal(t, `Bearer resource_metadata="https://m/.well-known/oauth-protected-resource", error="insufficient_scope", error_description="need write", scope="memory:write"`,
+		BearerChallenge("https://m/.well-known/oauth-protected-resource", "insufficient_scope", "need write", "memory:write"))
+}
@@ -0,0 +1,109 @@
+package security
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSigningSecret = "0123456789abcdef0123456789abcdef"
+
+func newTestGuard(now time.Time, maxNonces int) *ReplayGuard {
+	guard := NewReplayGuard(testSigningSecret, 5*time.Minute, maxNonces)
+	guard.now = func() time.Time { return now }
+	return guard
+}
+
+func TestSignRequestMatchesOpenSSL(t *testing.T) {
+	// printf '%s\n%s\n%s' 123 abc '{"a":1}' | openssl dgst -sha256 -hmac secret -hex
+	assert.Equal(t, "50ca4db9fab47d7800496df6995ca13dbe62e0f07b031520396b39a1dd5309f8", SignRequest("secret", "123", "abc", []byte(`{"a":1}`)))
+}
+
+func TestReplayGuardVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"jsonrpc":"2.0","method":"tools/list","id":1}`)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	nonce := "nonce-0000000001"
+	sig := SignRequest(testSigningSecret, ts, nonce, body)
+
+	tests := []struct {
+		name      string
+		timestamp string
+		nonce     string
+		signature string
+		body      []byte
+		want      error
+	}{
+		{"missing headers", "", nonce, sig, body, ErrMissingSignature},
+		{"bad timestamp", "yesterday", nonce, sig, body, ErrInvalidTimestamp},
+		{"too old", strconv.FormatInt(now.Add(-10*time.Minute).Unix(), 10), nonce, sig, body, ErrStaleRequest},
+		{"from the future", strconv.FormatInt(now.Add(10*time.Minute).Unix(), 10), nonce, sig, body, ErrStaleRequest},
+		{"short nonce", ts, "abc", SignRequest(testSigningSecret, ts, "abc", body), body, ErrInvalidNonce},
+		{"tampered body", ts, nonce, sig, []byte(`{"jsonrpc":"2.0","method":"tools/call","id":1}`), ErrInvalidSignature},
+		{"wrong secret", ts, nonce, SignRequest("another-secret", ts, nonce, body), body, ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guard := newTestGuard(now, 10)
+			assert.ErrorIs(t, guard.Verify(tt.timestamp, tt.nonce, tt.signature, tt.body), tt.want)
+			assert.Empty(t, guard.nonces, "rejected requests must not consume nonces")
+		})
+	}
+}
+
+func TestReplayGuardRejectsReplay(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	guard := newTestGuard(now, 10)
+	body := []byte(`{"id":1}`)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	sig := SignRequest(testSigningSecret, ts, "nonce-0000000001", body)
+
+	require.NoError(t, guard.Verify(ts, "nonce-0000000001", sig, body))
+	assert.ErrorIs(t, guard.Verify(ts, "nonce-0000000001", sig, body), ErrReplayedRequest)
+
+	// Once the timestamp leaves the skew window the replay is stale
+	guard.now = func() time.Time { return now.Add(6 * time.Minute) }
+	assert.ErrorIs(t, guard.Verify(ts, "nonce-0000000001", sig, body), ErrStaleRequest)
+}
+
+func TestReplayGuardNonceCacheLimit(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	guard := newTestGuard(now, 2)
+	body := []byte(`{}`)
+	sign := func(at time.Time, nonce string) error {
+		ts := strconv.FormatInt(at.Unix(), 10)
+		return guard.Verify(ts, nonce, SignRequest(testSigningSecret, ts, nonce, body), body)
+	}
+
+	require.NoError(t, sign(now.Add(-4*time.Minute), "nonce-0000000001"))
+	require.NoError(t, sign(now, "nonce-0000000002"))
+	assert.ErrorIs(t, sign(now, "nonce-0000000003"), ErrNonceCacheFull)
+
+	// Expired nonces are pruned to make room
+	guard.now = func() time.Time { return now.Add(2 * time.Minute) }
+	require.NoError(t, sign(now.Add(2*time.Minute), "nonce-0000000003"))
+	assert.Len(t, guard.nonces, 2)
+}
+
+func TestReplayGuardVerifyRequestKeepsBody(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	guard := newTestGuard(now, 10)
+	body := `{"jsonrpc":"2.0","method":"tools/list","id":1}`
+	ts := strconv.FormatInt(now.Unix(), 10)
+
+	req := httptest.NewRequest("POST", "/mcp", bytes.NewBufferString(body))
+	req.Header.Set(HeaderTimestamp, ts)
+	req.Header.Set(HeaderNonce, "nonce-0000000001")
+	req.Header.Set(HeaderSignature, SignRequest(testSigningSecret, ts, "nonce-0000000001", []byte(body)))
+
+	require.NoError(t, guard.VerifyRequest(req))
+	remaining, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(remaining))
+}
@@ -0,0 +1,414 @@
+package security
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Resource access modes a policy can grant
+const (
+	AccessRead  = "read"
+	AccessWrite = "write"
+)
+
+// Resource URI schemes a policy governs
+const (
+	SchemeFile     = "file"
+	SchemeDatabase = "database"
+)
+
+// SchemaAll grants every schema of a database
+const SchemaAll = "*"
+
+// ErrResourceDenied is returned when a policy does not grant access to a resource
+var ErrResourceDenied = errors.New("access denied by resource policy")
+
+// FileRule grants access to the files under a set of directories. Extensions,
+// when set, are the only file extensions allowed; DenyExtensions are refused
+// even when Extensions allows everything.
+type FileRule struct {
+	Name           string   `json:"name" yaml:"name"`
+	Paths          []string `json:"paths" yaml:"paths"`
+	Access         []string `json:"access" yaml:"access"`
+	Extensions     []string `json:"extensions,omitempty" yaml:"extensions,omitempty"`
+	DenyExtensions []string `json:"deny_extensions,omitempty" yaml:"deny_extensions,omitempty"`
+	MaxSizeBytes   int64    `json:"max_size_bytes,omitempty" yaml:"max_size_bytes,omitempty"`
+}
+
+// SchemaGrant grants access to one schema of a database, or all of them
+type SchemaGrant struct {
+	Schema string   `json:"schema" yaml:"schema"`
+	Access []string `json:"access" yaml:"access"`
+}
+
+// DatabaseRule grants access to the schemas of a database, addressed as
+// database://<database>/<schema>
+type DatabaseRule struct {
+	Database string        `json:"database" yaml:"database"`
+	Grants   []SchemaGrant `json:"grants" yaml:"grants"`
+	MaxRows  int           `json:"max_rows,omitempty" yaml:"max_rows,omitempty"`
+}
+
+// ResourcePolicy decides which file:// and database:// resources may be read
+// or written. Anything a rule does not grant is denied: file rules are
+// matched in order and the first rule whose paths contain a file applies,
+// and a database schema gets its own grant, or the database's "*" grant.
+type ResourcePolicy struct {
+	Files     []FileRule     `json:"files" yaml:"files"`
+	Databases []DatabaseRule `json:"databases" yaml:"databases"`
+}
+
+// LoadResourcePolicy reads a YAML resource policy
+func LoadResourcePolicy(path string) (*ResourcePolicy, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // Path comes from operator configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resource policy: %w", err)
+	}
+
+	var policy ResourcePolicy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse resource policy: %w", err)
+	}
+	if err := policy.Compile(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// Compile validates the policy and normalizes its paths and extensions.
+// Rules without access grant reading.
+func (p *ResourcePolicy) Compile() error {
+	for i := range p.Files {
+		rule := &p.Files[i]
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("files-%d", i+1)
+		}
+		if len(rule.Paths) == 0 {
+			return fmt.Errorf("file rule %q has no paths", rule.Name)
+		}
+		for j, path := range rule.Paths {
+			if !filepath.IsAbs(path) {
+				return fmt.Errorf("file rule %q: path %q must be absolute", rule.Name, path)
+			}
+			rule.Paths[j] = filepath.Clean(path)
+		}
+		access, err := compileAccess("file rule "+rule.Name, rule.Access)
+		if err != nil {
+			return err
+		}
+		rule.Access = access
+		if rule.MaxSizeBytes < 0 {
+			return fmt.Errorf("file rule %q: max_size_bytes must not be negative", rule.Name)
+		}
+		rule.Extensions = normalizeExtensions(rule.Extensions)
+		rule.DenyExtensions = normalizeExtensions(rule.DenyExtensions)
+	}
+
+	seen := make(map[string]bool, len(p.Databases))
+	for i := range p.Databases {
+		rule := &p.Databases[i]
+		if rule.Database == "" {
+			return fmt.Errorf("database rule %d has no database", i+1)
+		}
+		if seen[rule.Database] {
+			return fmt.Errorf("database %q has more than one rule", rule.Database)
+		}
+		seen[rule.Database] = true
+		if len(rule.Grants) == 0 {
+			return fmt.Errorf("database %q has no schema grants", rule.Database)
+		}
+		for j := range rule.Grants {
+			grant := &rule.Grants[j]
+			if grant.Schema == "" {
+				return fmt.Errorf("database %q: grant %d has no schema", rule.Database, j+1)
+			}
+			access, err := compileAccess(fmt.Sprintf("database %s schema %s", rule.Database, grant.Schema), grant.Access)
+			if err != nil {
+				return err
+			}
+			grant.Access = access
+		}
+		if rule.MaxRows < 0 {
+			return fmt.Errorf("database %q: max_rows must not be negative", rule.Database)
+		}
+	}
+	return nil
+}
+
+// compileAccess validates access modes, defaulting to read
+func compileAccess(owner string, access []string) ([]string, error) {
+	if len(access) == 0 {
+		return []string{AccessRead}, nil
+	}
+	for _, mode := range access {
+		if mode != AccessRead && mode != AccessWrite {
+			return nil, fmt.Errorf("%s: unknown access %q (must be read or write)", owner, mode)
+		}
+	}
+	return access, nil
+}
+
+// normalizeExtensions lowercases extensions and gives them a leading dot
+func normalizeExtensions(extensions []string) []string {
+	normalized := make([]string, 0, len(extensions))
+	for _, ext := range extensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		normalized = append(normalized, ext)
+	}
+	return normalized
+}
+
+// grants reports whether access lists mode
+func grants(access []string, mode string) bool {
+	for _, granted := range access {
+		if granted == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// fileRule returns the first rule whose paths contain path
+func (p *ResourcePolicy) fileRule(path string) *FileRule {
+	for i := range p.Files {
+		for _, dir := range p.Files[i].Paths {
+			if rel, err := filepath.Rel(dir, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return &p.Files[i]
+			}
+		}
+	}
+	return nil
+}
+
+// CheckPath checks that path lies under a file rule granting access. It
+// suits directories; files are checked with CheckFile. Callers resolve
+// symlinks first, so a link cannot lead outside the granted paths.
+func (p *ResourcePolicy) CheckPath(path, access string) error {
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("%w: path %q is not absolute", ErrResourceDenied, path)
+	}
+	path = filepath.Clean(path)
+	rule := p.fileRule(path)
+	if rule == nil {
+		return fmt.Errorf("%w: %s is outside every allowed path", ErrResourceDenied, path)
+	}
+	if !grants(rule.Access, access) {
+		return fmt.Errorf("%w: %s access to %s is not granted", ErrResourceDenied, access, path)
+	}
+	return nil
+}
+
+// CheckFile checks that a file of size bytes may be accessed: its path, its
+// extension and its size. A negative size is not checked, e.g. for a file
+// about to be written.
+func (p *ResourcePolicy) CheckFile(path string, size int64, access string) error {
+	if err := p.CheckPath(path, access); err != nil {
+		return err
+	}
+	rule := p.fileRule(filepath.Clean(path))
+
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, denied := range rule.DenyExtensions {
+		if ext == denied {
+			return fmt.Errorf("%w: %s files are not allowed", ErrResourceDenied, ext)
+		}
+	}
+	if len(rule.Extensions) > 0 {
+		allowed := false
+		for _, candidate := range rule.Extensions {
+			allowed = allowed || ext == candidate
+		}
+		if !allowed {
+			return fmt.Errorf("%w: only %s files are allowed under %s", ErrResourceDenied, strings.Join(rule.Extensions, ", "), strings.Join(rule.Paths, ", "))
+		}
+	}
+	if rule.MaxSizeBytes > 0 && size > rule.MaxSizeBytes {
+		return fmt.Errorf("%w: %s is %d bytes, over the %d byte limit", ErrResourceDenied, path, size, rule.MaxSizeBytes)
+	}
+	return nil
+}
+
+// CheckSchema checks that a database schema is granted access
+func (p *ResourcePolicy) CheckSchema(database, schema, access string) error {
+	rule := p.databaseRule(database)
+	if rule == nil {
+		return fmt.Errorf("%w: database %q is not allowed", ErrResourceDenied, database)
+	}
+
+	var grant *SchemaGrant
+	for i := range rule.Grants {
+		switch rule.Grants[i].Schema {
+		case schema:
+			grant = &rule.Grants[i]
+		case SchemaAll:
+			if grant == nil {
+				grant = &rule.Grants[i]
+			}
+		}
+	}
+	if grant == nil {
+		return fmt.Errorf("%w: schema %q of database %q is not allowed", ErrResourceDenied, schema, database)
+	}
+	if !grants(grant.Access, access) {
+		return fmt.Errorf("%w: %s access to schema %q of database %q is not granted", ErrResourceDenied, access, schema, database)
+	}
+	return nil
+}
+
+// CheckQuery checks that a SQL query may run against a database schema:
+// queries that write need the write grant, everything else the read grant
+func (p *ResourcePolicy) CheckQuery(database, schema, query string) error {
+	access, err := QueryAccess(query)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrResourceDenied, err)
+	}
+	return p.CheckSchema(database, schema, access)
+}
+
+// MaxRows returns how many rows a query against database may return, or 0
+// for no limit
+func (p *ResourcePolicy) MaxRows(database string) int {
+	if rule := p.databaseRule(database); rule != nil {
+		return rule.MaxRows
+	}
+	return 0
+}
+
+// databaseRule returns the rule of a database
+func (p *ResourcePolicy) databaseRule(database string) *DatabaseRule {
+	for i := range p.Databases {
+		if p.Databases[i].Database == database {
+			return &p.Databases[i]
+		}
+	}
+	return nil
+}
+
+// CheckURI checks access to a file:// or database://<database>/<schema>
+// resource. Other schemes are not governed by the policy.
+func (p *ResourcePolicy) CheckURI(uri, access string) error {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return fmt.Errorf("%w: invalid resource URI %q", ErrResourceDenied, uri)
+	}
+	switch u.Scheme {
+	case SchemeFile:
+		return p.CheckPath(filepath.FromSlash(u.Path), access)
+	case SchemeDatabase:
+		schema := strings.Trim(u.Path, "/")
+		if schema == "" || strings.Contains(schema, "/") {
+			return fmt.Errorf("%w: %q does not name a schema, expected database://<database>/<schema>", ErrResourceDenied, uri)
+		}
+		return p.CheckSchema(u.Host, schema, access)
+	}
+	return nil
+}
+
+// readStatements are the SQL statements that only read
+var readStatements = map[string]bool{
+	"SELECT": true, "WITH": true, "SHOW": true, "EXPLAIN": true, "DESCRIBE": true, "DESC": true, "VALUES": true, "TABLE": true,
+}
+
+// writeKeywords make a statement that starts like a read write anyway, e.g.
+// a WITH ... INSERT, a SELECT ... INTO or a SELECT ... FOR UPDATE
+var writeKeywords = map[string]bool{
+	"INSERT": true, "UPDATE": true, "DELETE": true, "MERGE": true, "UPSERT": true, "INTO": true,
+	"CREATE": true, "ALTER": true, "DROP": true, "TRUNCATE": true, "GRANT": true, "REVOKE": true,
+}
+
+// QueryAccess classifies a SQL query as read or write. Comments, string
+// literals and quoted identifiers are ignored, every statement of a
+// multi-statement query counts, and anything that is not clearly a read is
+// a write.
+func QueryAccess(query string) (string, error) {
+	statements := sqlStatements(query)
+	if len(statements) == 0 {
+		return "", errors.New("empty query")
+	}
+	for _, words := range statements {
+		if !readStatements[words[0]] {
+			return AccessWrite, nil
+		}
+		for _, word := range words[1:] {
+			if writeKeywords[word] {
+				return AccessWrite, nil
+			}
+		}
+	}
+	return AccessRead, nil
+}
+
+// sqlStatements splits a query into statements of uppercased keywords and
+// identifiers, dropping comments, literals and quoted identifiers
+func sqlStatements(query string) [][]string {
+	var (
+		statements [][]string
+		words      []string
+		word       strings.Builder
+	)
+	endWord := func() {
+		if word.Len() > 0 {
+			words = append(words, strings.ToUpper(word.String()))
+			word.Reset()
+		}
+	}
+	endStatement := func() {
+		endWord()
+		if len(words) > 0 {
+			statements = append(statements, words)
+			words = nil
+		}
+	}
+
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			endWord()
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			endWord()
+			i += 2
+			for i+1 < len(runes) && (runes[i] != '*' || runes[i+1] != '/') {
+				i++
+			}
+			i++
+		case r == '\'' || r == '"' || r == '`':
+			endWord()
+			for i++; i < len(runes); i++ {
+				if runes[i] == r {
+					// A doubled quote escapes itself
+					if i+1 < len(runes) && runes[i+1] == r {
+						i++
+						continue
+					}
+					break
+				}
+			}
+		case r == ';':
+			endStatement()
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			word.WriteRune(r)
+		default:
+			endWord()
+		}
+	}
+	endStatement()
+	return statements
+}
@@ -0,0 +1,143 @@
+package security
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadResourcePolicyExample(t *testing.T) {
+	policy, err := LoadResourcePolicy(filepath.Join("..", "..", "configs", "resource-policy.example.yaml"))
+	require.NoError(t, err)
+	require.Len(t, policy.Files, 2)
+	assert.Equal(t, []string{AccessRead}, policy.Files[1].Access, "rules without access grant reading")
+	assert.Equal(t, 1000, policy.MaxRows("analytics"))
+	assert.Equal(t, 0, policy.MaxRows("app"))
+}
+
+func TestResourcePolicyFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+files:
+  - name: workspace
+    paths: ["/srv/workspace/"]
+    access: ["read", "write"]
+    deny_extensions: ["ENV", ".pem"]
+    max_size_bytes: 100
+  - name: docs
+    paths: ["/srv/docs"]
+    extensions: ["md", ".TXT"]
+`), 0o600))
+	policy, err := LoadResourcePolicy(path)
+	require.NoError(t, err)
+
+	tests := []struct {
+		path   string
+		size   int64
+		access string
+		errMsg string
+	}{
+		{"/srv/workspace/main.go", 10, AccessWrite, ""},
+		{"/srv/workspace/big.bin", 101, AccessRead, "over the 100 byte limit"},
+		{"/srv/workspace/new.bin", -1, AccessWrite, ""},
+		{"/srv/workspace/.config/prod.env", 10, AccessRead, ".env files are not allowed"},
+		{"/srv/workspace/../secrets/key", 10, AccessRead, "outside every allowed path"},
+		{"/srv/workspace-other/main.go", 10, AccessRead, "outside every allowed path"},
+		{"/srv/docs/guide/README.MD", 1 << 20, AccessRead, ""},
+		{"/srv/docs/notes.txt", 10, AccessRead, ""},
+		{"/srv/docs/run.sh", 10, AccessRead, "only .md, .txt files are allowed"},
+		{"/srv/docs/notes.md", 10, AccessWrite, "write access to /srv/docs/notes.md is not granted"},
+		{"docs/notes.md", 10, AccessRead, "is not absolute"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			err := policy.CheckFile(tt.path, tt.size, tt.access)
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrResourceDenied)
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+
+	assert.NoError(t, policy.CheckPath("/srv/docs", AccessRead), "directories are checked by path only")
+	assert.NoError(t, policy.CheckURI("file:///srv/docs/guide", AccessRead))
+	assert.ErrorIs(t, policy.CheckURI("file:///etc/passwd", AccessRead), ErrResourceDenied)
+	assert.NoError(t, policy.CheckURI("memory://recent", AccessRead), "other schemes are not governed")
+}
+
+func TestResourcePolicyDatabases(t *testing.T) {
+	policy := &ResourcePolicy{Databases: []DatabaseRule{
+		{Database: "analytics", Grants: []SchemaGrant{
+			{Schema: SchemaAll},
+			{Schema: "scratch", Access: []string{AccessRead, AccessWrite}},
+			{Schema: "audit", Access: []string{AccessWrite}},
+		}},
+	}}
+	require.NoError(t, policy.Compile())
+
+	assert.NoError(t, policy.CheckQuery("analytics", "reporting", "SELECT * FROM revenue"))
+	assert.ErrorContains(t, policy.CheckQuery("analytics", "reporting", "DELETE FROM revenue"), `write access to schema "reporting" of database "analytics" is not granted`)
+	assert.NoError(t, policy.CheckQuery("analytics", "scratch", "CREATE TABLE t (id int)"), "a schema's own grant takes precedence over *")
+	assert.ErrorIs(t, policy.CheckQuery("analytics", "audit", "SELECT 1"), ErrResourceDenied)
+	assert.ErrorContains(t, policy.CheckQuery("billing", "public", "SELECT 1"), `database "billing" is not allowed`)
+	assert.ErrorContains(t, policy.CheckQuery("analytics", "scratch", " -- nothing\n"), "empty query")
+
+	assert.NoError(t, policy.CheckURI("database://analytics/scratch", AccessWrite))
+	assert.ErrorIs(t, policy.CheckURI("database://analytics/reporting", AccessWrite), ErrResourceDenied)
+	assert.ErrorContains(t, policy.CheckURI("database://analytics", AccessRead), "does not name a schema")
+}
+
+func TestQueryAccess(t *testing.T) {
+	tests := []struct {
+		query  string
+		access string
+	}{
+		{"select id, replace(name, 'a', 'b') from users where note = 'DROP TABLE users'", AccessRead},
+		{`SELECT "delete" FROM t -- UPDATE t SET x = 1`, AccessRead},
+		{"/* INSERT */ WITH recent AS (SELECT * FROM events) SELECT count(*) FROM recent", AccessRead},
+		{"EXPLAIN SELECT 1; SHOW search_path;", AccessRead},
+		{"SELECT 'it''s; DELETE FROM t' AS quote", AccessRead},
+		{"WITH moved AS (DELETE FROM queue RETURNING *) SELECT * FROM moved", AccessWrite},
+		{"SELECT * INTO backup FROM users", AccessWrite},
+		{"SELECT * FROM jobs FOR UPDATE SKIP LOCKED", AccessWrite},
+		{"SELECT 1; DROP TABLE users", AccessWrite},
+		{"update users set name = 'x'", AccessWrite},
+		{"VACUUM users", AccessWrite},
+		{"CALL refresh_views()", AccessWrite},
+	}
+	for _, tt := range tests {
+		access, err := QueryAccess(tt.query)
+		require.NoError(t, err, tt.query)
+		assert.Equal(t, tt.access, access, tt.query)
+	}
+}
+
+func TestResourcePolicyCompileErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy ResourcePolicy
+		errMsg string
+	}{
+		{"no paths", ResourcePolicy{Files: []FileRule{{Name: "x"}}}, `file rule "x" has no paths`},
+		{"relative path", ResourcePolicy{Files: []FileRule{{Paths: []string{"docs"}}}}, `path "docs" must be absolute`},
+		{"bad access", ResourcePolicy{Files: []FileRule{{Paths: []string{"/srv"}, Access: []string{"execute"}}}}, `unknown access "execute"`},
+		{"negative size", ResourcePolicy{Files: []FileRule{{Paths: []string{"/srv"}, MaxSizeBytes: -1}}}, "max_size_bytes must not be negative"},
+		{"no database", ResourcePolicy{Databases: []DatabaseRule{{}}}, "has no database"},
+		{"no grants", ResourcePolicy{Databases: []DatabaseRule{{Database: "app"}}}, "has no schema grants"},
+		{"duplicate database", ResourcePolicy{Databases: []DatabaseRule{
+			{Database: "app", Grants: []SchemaGrant{{Schema: "*"}}},
+			{Database: "app", Grants: []SchemaGrant{{Schema: "*"}}},
+		}}, "more than one rule"},
+		{"no schema", ResourcePolicy{Databases: []DatabaseRule{{Database: "app", Grants: []SchemaGrant{{}}}}}, "grant 1 has no schema"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, tt.policy.Compile(), tt.errMsg)
+		})
+	}
+}
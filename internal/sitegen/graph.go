@@ -0,0 +1,121 @@
+package sitegen
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strings"
+)
+
+const (
+	// nodeRadius is the size of an entry in the graphs
+	nodeRadius = 10
+	// graphMargin leaves room for labels around the graph
+	graphMargin = 110
+	// maxLabelRunes bounds the node labels
+	maxLabelRunes = 28
+)
+
+// kindColors fills the nodes of each kind
+var kindColors = map[Kind]string{
+	KindDecision: "#2563eb",
+	KindPattern:  "#9333ea",
+	KindSolution: "#16a34a",
+}
+
+// graphNode is a placed entry
+type graphNode struct {
+	entry *Entry
+	x, y  float64
+}
+
+// GraphSVG draws entries and the edges among them as an SVG image. When
+// center is set it is drawn in the middle with the other entries around it;
+// otherwise every entry sits on a circle. Nodes link to their page at
+// linkPrefix + PageFile(ID). Entries without edges are left out, and an
+// empty string is returned when there are no edges.
+func GraphSVG(site *Site, edges []Edge, center, linkPrefix string) string {
+	if len(edges) == 0 {
+		return ""
+	}
+
+	var ids []string
+	placed := make(map[string]bool)
+	for _, edge := range edges {
+		for _, id := range []string{edge.Source, edge.Target} {
+			if !placed[id] && id != center {
+				placed[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	// The circle grows with the number of nodes so they keep apart
+	radius := math.Max(120, float64(len(ids))*3*nodeRadius/math.Pi)
+	size := 2 * (radius + graphMargin)
+	middle := size / 2
+
+	nodes := make(map[string]*graphNode, len(ids)+1)
+	if entry, ok := site.Entry(center); ok {
+		nodes[center] = &graphNode{entry: entry, x: middle, y: middle}
+	}
+	for i, id := range ids {
+		entry, ok := site.Entry(id)
+		if !ok {
+			continue
+		}
+		angle := 2*math.Pi*float64(i)/float64(len(ids)) - math.Pi/2
+		nodes[id] = &graphNode{entry: entry, x: middle + radius*math.Cos(angle), y: middle + radius*math.Sin(angle)}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %.0f %.0f" class="graph" role="img" aria-label="Relationship graph">`, size, size)
+	b.WriteString(`<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="6" markerHeight="6" orient="auto-start-reverse"><path d="M 0 0 L 10 5 L 0 10 z" fill="#94a3b8"/></marker></defs>`)
+
+	for _, edge := range edges {
+		source, target := nodes[edge.Source], nodes[edge.Target]
+		if source == nil || target == nil {
+			continue
+		}
+		// Stop the arrow at the edge of the target node
+		dx, dy := target.x-source.x, target.y-source.y
+		length := math.Hypot(dx, dy)
+		if length == 0 {
+			continue
+		}
+		x2, y2 := target.x-dx/length*nodeRadius, target.y-dy/length*nodeRadius
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#94a3b8" stroke-width="%.1f" marker-end="url(#arrow)"><title>%s (%.0f%%)</title></line>`,
+			source.x, source.y, x2, y2, 1+2*edge.Confidence, html.EscapeString(string(edge.Relation)), edge.Confidence*100)
+	}
+
+	for _, id := range append([]string{center}, ids...) {
+		node := nodes[id]
+		if node == nil {
+			continue
+		}
+		anchor := "middle"
+		labelX, labelY := node.x, node.y-nodeRadius-6
+		if node.entry.ID != center {
+			switch {
+			case node.x < middle-1:
+				anchor, labelX, labelY = "end", node.x-nodeRadius-4, node.y+4
+			case node.x > middle+1:
+				anchor, labelX, labelY = "start", node.x+nodeRadius+4, node.y+4
+			}
+		}
+		fmt.Fprintf(&b, `<a href="%s"><circle cx="%.1f" cy="%.1f" r="%d" fill="%s"><title>%s</title></circle><text x="%.1f" y="%.1f" text-anchor="%s">%s</text></a>`,
+			html.EscapeString(linkPrefix+PageFile(node.entry.ID)), node.x, node.y, nodeRadius, kindColors[node.entry.Kind],
+			html.EscapeString(node.entry.Title), labelX, labelY, anchor, html.EscapeString(label(node.entry.Title)))
+	}
+
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+// label shortens a title to fit next to a node
+func label(title string) string {
+	if runes := []rune(title); len(runes) > maxLabelRunes {
+		return string(runes[:maxLabelRunes-1]) + "…"
+	}
+	return title
+}
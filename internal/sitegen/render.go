@@ -0,0 +1,309 @@
+package sitegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxIndexedRunes bounds how much of an entry's text the search index holds
+const maxIndexedRunes = 2000
+
+const indexTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{ .Site.Title }} knowledge base</title>
+<link rel="stylesheet" href="style.css">
+</head>
+<body>
+<header>
+<h1>{{ .Site.Title }} knowledge base</h1>
+<p class="meta">{{ len .Site.Entries }} entries · generated {{ date .Site.GeneratedAt }}</p>
+<input type="search" id="search" placeholder="Search decisions, patterns and solutions" autocomplete="off">
+<ul id="results"></ul>
+</header>
+<main>
+{{ if .Graph }}<section>
+<h2>Relationships</h2>
+<p class="legend"><span class="decision">decision</span> <span class="pattern">pattern</span> <span class="solution">solution</span></p>
+{{ .Graph }}
+</section>
+{{ end }}{{ range .Sections }}<section id="{{ .Kind }}">
+<h2>{{ .Kind.Label }} ({{ len .Entries }})</h2>
+<ul class="entries">{{ range .Entries }}
+<li><a href="entries/{{ page .ID }}">{{ .Title }}</a> <span class="meta">{{ date .Timestamp }}</span></li>{{ else }}
+<li class="meta">None yet</li>{{ end }}
+</ul>
+</section>
+{{ end }}</main>
+<script src="search-index.js"></script>
+<script src="search.js"></script>
+</body>
+</html>
+`
+
+const entryTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{ .Entry.Title }} · {{ .Site.Title }}</title>
+<link rel="stylesheet" href="../style.css">
+</head>
+<body>
+<header>
+<p><a href="../index.html">← {{ .Site.Title }} knowledge base</a></p>
+<h1>{{ .Entry.Title }}</h1>
+<p class="meta"><span class="{{ .Entry.Kind }}">{{ .Entry.Kind }}</span> · {{ date .Entry.Timestamp }}{{ if .Entry.Author }} · {{ .Entry.Author }}{{ end }}{{ if .Entry.SourceURL }} · <a href="{{ .Entry.SourceURL }}">source</a>{{ end }}</p>
+{{ if .Entry.Tags }}<p class="tags">{{ range .Entry.Tags }}<span>{{ . }}</span> {{ end }}</p>{{ end }}
+</header>
+<main>
+<div class="content">{{ .Entry.Content }}</div>
+{{ if .Related }}<section>
+<h2>Related</h2>
+<ul class="entries">{{ range .Related }}
+<li>{{ .Relation }} <a href="{{ page .Entry.ID }}">{{ .Entry.Title }}</a> <span class="meta">{{ .Entry.Kind }}</span></li>{{ end }}
+</ul>
+{{ .Graph }}
+</section>{{ end }}
+</main>
+</body>
+</html>
+`
+
+const styleSheet = `body { font-family: system-ui, sans-serif; max-width: 60rem; margin: 0 auto; padding: 1rem 1.5rem; color: #0f172a; line-height: 1.5; }
+a { color: #1d4ed8; text-decoration: none; }
+a:hover { text-decoration: underline; }
+.meta { color: #64748b; font-size: 0.9rem; }
+#search { width: 100%; padding: 0.6rem; font-size: 1rem; border: 1px solid #cbd5e1; border-radius: 6px; box-sizing: border-box; }
+#results { list-style: none; padding: 0; }
+#results li { padding: 0.3rem 0; }
+.entries { padding-left: 1.2rem; }
+.content { white-space: pre-wrap; background: #f8fafc; border: 1px solid #e2e8f0; border-radius: 6px; padding: 1rem; }
+.tags span { background: #e2e8f0; border-radius: 4px; padding: 0 0.4rem; font-size: 0.85rem; }
+.decision, .pattern, .solution { color: #fff; border-radius: 4px; padding: 0 0.4rem; font-size: 0.85rem; }
+.decision { background: #2563eb; }
+.pattern { background: #9333ea; }
+.solution { background: #16a34a; }
+svg.graph { width: 100%; max-height: 48rem; }
+svg.graph text { font-size: 11px; fill: #334155; }
+`
+
+const searchScript = `(function () {
+  var input = document.getElementById("search");
+  var results = document.getElementById("results");
+  var index = window.SEARCH_INDEX || [];
+
+  function score(entry, terms) {
+    var title = entry.title.toLowerCase();
+    var text = (entry.tags.join(" ") + " " + entry.text).toLowerCase();
+    var total = 0;
+    for (var i = 0; i < terms.length; i++) {
+      if (title.indexOf(terms[i]) >= 0) {
+        total += 3;
+      } else if (text.indexOf(terms[i]) >= 0) {
+        total += 1;
+      } else {
+        return 0;
+      }
+    }
+    return total;
+  }
+
+  input.addEventListener("input", function () {
+    var terms = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+    results.innerHTML = "";
+    if (!terms.length) {
+      return;
+    }
+    index
+      .map(function (entry) { return { entry: entry, score: score(entry, terms) }; })
+      .filter(function (hit) { return hit.score > 0; })
+      .sort(function (a, b) { return b.score - a.score; })
+      .slice(0, 20)
+      .forEach(function (hit) {
+        var item = document.createElement("li");
+        var link = document.createElement("a");
+        link.href = hit.entry.url;
+        link.textContent = hit.entry.title;
+        var kind = document.createElement("span");
+        kind.className = hit.entry.kind;
+        kind.textContent = hit.entry.kind;
+        item.appendChild(kind);
+        item.appendChild(document.createTextNode(" "));
+        item.appendChild(link);
+        results.appendChild(item);
+      });
+    if (!results.children.length) {
+      results.innerHTML = '<li class="meta">No matches</li>';
+    }
+  });
+})();
+`
+
+var templateFuncs = htmltemplate.FuncMap{
+	"page": PageFile,
+	"date": func(t interface{ Format(string) string }) string { return t.Format("2006-01-02") },
+}
+
+var (
+	indexTmpl = htmltemplate.Must(htmltemplate.New("site-index").Funcs(templateFuncs).Parse(indexTemplate))
+	entryTmpl = htmltemplate.Must(htmltemplate.New("site-entry").Funcs(templateFuncs).Parse(entryTemplate))
+)
+
+// section is a group of entries on the index page
+type section struct {
+	Kind    Kind
+	Entries []Entry
+}
+
+// related is a neighbor listed on an entry page
+type related struct {
+	Relation string
+	Entry    *Entry
+}
+
+// searchEntry is one record of the embedded search index
+type searchEntry struct {
+	Title string   `json:"title"`
+	Kind  Kind     `json:"kind"`
+	Tags  []string `json:"tags"`
+	Text  string   `json:"text"`
+	URL   string   `json:"url"`
+}
+
+// Files renders the site as files keyed by their path relative to the site root
+func Files(site *Site) (map[string][]byte, error) {
+	files := map[string][]byte{
+		"style.css": []byte(styleSheet),
+		"search.js": []byte(searchScript),
+		// Serve the files as they are from GitHub Pages
+		".nojekyll": {},
+	}
+
+	sections := make([]section, 0, len(Kinds))
+	for _, kind := range Kinds {
+		sections = append(sections, section{Kind: kind, Entries: site.Section(kind)})
+	}
+	graph := GraphSVG(site, site.Edges, "", "entries/")
+	if graph != "" {
+		files["graph.svg"] = []byte(graph)
+	}
+	index, err := render(indexTmpl, map[string]interface{}{
+		"Site":     site,
+		"Sections": sections,
+		"Graph":    htmltemplate.HTML(graph), //nolint:gosec // Built by GraphSVG, which escapes every value
+	})
+	if err != nil {
+		return nil, err
+	}
+	files["index.html"] = index
+
+	searchIndex := make([]searchEntry, 0, len(site.Entries))
+	for i := range site.Entries {
+		entry := &site.Entries[i]
+		page, err := renderEntry(site, entry)
+		if err != nil {
+			return nil, err
+		}
+		files["entries/"+PageFile(entry.ID)] = page
+
+		text := strings.TrimSpace(entry.Summary + "\n" + entry.Content)
+		if runes := []rune(text); len(runes) > maxIndexedRunes {
+			text = string(runes[:maxIndexedRunes])
+		}
+		tags := entry.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		searchIndex = append(searchIndex, searchEntry{Title: entry.Title, Kind: entry.Kind, Tags: tags, Text: text, URL: "entries/" + PageFile(entry.ID)})
+	}
+	data, err := json.Marshal(searchIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode search index: %w", err)
+	}
+	files["search-index.js"] = []byte("window.SEARCH_INDEX = " + string(data) + ";\n")
+	return files, nil
+}
+
+// renderEntry renders the page of one entry with its neighborhood graph
+func renderEntry(site *Site, entry *Entry) ([]byte, error) {
+	edges := site.Neighbors(entry.ID)
+	var neighbors []related
+	for _, edge := range edges {
+		relation, other := string(edge.Relation), edge.Target
+		if edge.Target == entry.ID {
+			relation, other = "← "+relation, edge.Source
+		}
+		if neighbor, ok := site.Entry(other); ok {
+			neighbors = append(neighbors, related{Relation: relation, Entry: neighbor})
+		}
+	}
+	graph := GraphSVG(site, edges, entry.ID, "")
+	return render(entryTmpl, map[string]interface{}{
+		"Site":    site,
+		"Entry":   entry,
+		"Related": neighbors,
+		"Graph":   htmltemplate.HTML(graph), //nolint:gosec // Built by GraphSVG, which escapes every value
+	})
+}
+
+// render executes a page template
+func render(tmpl *htmltemplate.Template, data interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", tmpl.Name(), err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Write renders the site into dir, replacing what an earlier export left
+// there. The site is built next to dir and swapped in once complete.
+func Write(site *Site, dir string) error {
+	files, err := Files(site)
+	if err != nil {
+		return err
+	}
+
+	staging := dir + ".tmp"
+	if err := os.RemoveAll(staging); err != nil {
+		return fmt.Errorf("failed to clear site staging directory: %w", err)
+	}
+	for name, data := range files {
+		path := filepath.Join(staging, filepath.FromSlash(name))
+		// The site is meant to be published, so web servers must be able to read it
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil { //nolint:gosec // Published content
+			return fmt.Errorf("failed to create site directory: %w", err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil { //nolint:gosec // Published content
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove previous site: %w", err)
+	}
+	if err := os.Rename(staging, dir); err != nil {
+		return fmt.Errorf("failed to publish site: %w", err)
+	}
+	return nil
+}
+
+// PageFile returns the file name of an entry's page, keeping only characters safe in URLs and paths
+func PageFile(id string) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '-'
+		}
+	}, id)
+	return safe + ".html"
+}
@@ -0,0 +1,268 @@
+// Package sitegen renders a project's knowledge base — its decisions,
+// patterns and verified solutions — as a static HTML site with a client-side
+// search index and relationship graphs, so it can be published (e.g. to
+// GitHub Pages) for readers without an MCP client.
+package sitegen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+)
+
+const (
+	// maxSiteChunks bounds how many of a project's memories are considered
+	maxSiteChunks = 10000
+	// minRelationConfidence is the weakest relationship drawn in the graphs
+	minRelationConfidence = 0.5
+	// maxTitleLength bounds entry titles
+	maxTitleLength = 120
+)
+
+// Kind is the section of the site an entry belongs to
+type Kind string
+
+const (
+	// KindDecision is an architecture decision
+	KindDecision Kind = "decision"
+	// KindPattern is a recurring pattern, i.e. a memory tagged "pattern"
+	KindPattern Kind = "pattern"
+	// KindSolution is a solution whose latest verification passed
+	KindSolution Kind = "solution"
+)
+
+// Kinds lists the sections in the order they are shown
+var Kinds = []Kind{KindDecision, KindPattern, KindSolution}
+
+// Label returns the section heading for the kind
+func (k Kind) Label() string {
+	switch k {
+	case KindDecision:
+		return "Decisions"
+	case KindPattern:
+		return "Patterns"
+	case KindSolution:
+		return "Verified solutions"
+	default:
+		return string(k)
+	}
+}
+
+// Entry is one page of the site
+type Entry struct {
+	ID        string    `json:"id"`
+	Kind      Kind      `json:"kind"`
+	Title     string    `json:"title"`
+	Summary   string    `json:"summary,omitempty"`
+	Content   string    `json:"content"`
+	Tags      []string  `json:"tags,omitempty"`
+	Author    string    `json:"author,omitempty"`
+	SourceURL string    `json:"source_url,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Edge is a relationship between two entries
+type Edge struct {
+	Source     string             `json:"source"`
+	Target     string             `json:"target"`
+	Relation   types.RelationType `json:"relation"`
+	Confidence float64            `json:"confidence"`
+}
+
+// Site is a project's knowledge base, ready to render
+type Site struct {
+	Project     string    `json:"project"`
+	Title       string    `json:"title"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Entries     []Entry   `json:"entries"`
+	Edges       []Edge    `json:"edges"`
+}
+
+// Section returns the entries of a kind, newest first
+func (s *Site) Section(kind Kind) []Entry {
+	var entries []Entry
+	for i := range s.Entries {
+		if s.Entries[i].Kind == kind {
+			entries = append(entries, s.Entries[i])
+		}
+	}
+	return entries
+}
+
+// Entry returns the entry with the given ID
+func (s *Site) Entry(id string) (*Entry, bool) {
+	for i := range s.Entries {
+		if s.Entries[i].ID == id {
+			return &s.Entries[i], true
+		}
+	}
+	return nil, false
+}
+
+// Neighbors returns the edges touching an entry
+func (s *Site) Neighbors(id string) []Edge {
+	var edges []Edge
+	for _, edge := range s.Edges {
+		if edge.Source == id || edge.Target == id {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+// Builder collects a project's knowledge base from the store
+type Builder struct {
+	store storage.VectorStore
+}
+
+// NewBuilder creates a site builder reading from store
+func NewBuilder(store storage.VectorStore) *Builder {
+	return &Builder{store: store}
+}
+
+// Build collects the decisions, patterns and verified solutions of a project
+// and the relationships among them. Trashed memories are left out.
+func (b *Builder) Build(ctx context.Context, project, title string, now time.Time) (*Site, error) {
+	if project == "" {
+		return nil, errors.New("project is required")
+	}
+	if title == "" {
+		title = project
+	}
+
+	chunks, err := b.store.ListByRepository(ctx, project, maxSiteChunks, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project memories: %w", err)
+	}
+
+	site := &Site{Project: project, Title: title, GeneratedAt: now.UTC(), Entries: []Entry{}, Edges: []Edge{}}
+	for i := range chunks {
+		chunk := &chunks[i]
+		if chunk.IsDeleted() {
+			continue
+		}
+		if kind, ok := classify(chunk); ok {
+			site.Entries = append(site.Entries, newEntry(chunk, kind))
+		}
+	}
+	sort.Slice(site.Entries, func(i, j int) bool {
+		if !site.Entries[i].Timestamp.Equal(site.Entries[j].Timestamp) {
+			return site.Entries[i].Timestamp.After(site.Entries[j].Timestamp)
+		}
+		return site.Entries[i].ID < site.Entries[j].ID
+	})
+
+	edges, err := b.edges(ctx, site.Entries)
+	if err != nil {
+		return nil, err
+	}
+	site.Edges = edges
+	return site, nil
+}
+
+// edges returns the relationships whose ends are both entries of the site
+func (b *Builder) edges(ctx context.Context, entries []Entry) ([]Edge, error) {
+	included := make(map[string]bool, len(entries))
+	for i := range entries {
+		included[entries[i].ID] = true
+	}
+
+	seen := make(map[string]bool)
+	edges := []Edge{}
+	for i := range entries {
+		query := types.NewRelationshipQuery(entries[i].ID)
+		query.MinConfidence = minRelationConfidence
+		query.MaxDepth = 1
+		query.IncludeChunks = false
+		related, err := b.store.GetRelationships(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load relationships of %s: %w", entries[i].ID, err)
+		}
+		for j := range related {
+			rel := &related[j].Relationship
+			if seen[rel.ID] || rel.Confidence < minRelationConfidence || !included[rel.SourceChunkID] || !included[rel.TargetChunkID] {
+				continue
+			}
+			seen[rel.ID] = true
+			edges = append(edges, Edge{
+				Source:     rel.SourceChunkID,
+				Target:     rel.TargetChunkID,
+				Relation:   rel.RelationType,
+				Confidence: rel.Confidence,
+			})
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Source != edges[j].Source {
+			return edges[i].Source < edges[j].Source
+		}
+		return edges[i].Target < edges[j].Target
+	})
+	return edges, nil
+}
+
+// classify returns the section a memory belongs to, if any
+func classify(chunk *types.ConversationChunk) (Kind, bool) {
+	switch {
+	case chunk.Type == types.ChunkTypeArchitectureDecision:
+		return KindDecision, true
+	case hasTag(chunk.Metadata.Tags, "pattern"):
+		return KindPattern, true
+	case chunk.Type == types.ChunkTypeSolution && chunk.VerificationStatus() == types.VerificationVerified:
+		return KindSolution, true
+	default:
+		return "", false
+	}
+}
+
+// hasTag reports whether tags contain tag, ignoring case
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// newEntry converts a memory into a site entry
+func newEntry(chunk *types.ConversationChunk, kind Kind) Entry {
+	entry := Entry{
+		ID:        chunk.ID,
+		Kind:      kind,
+		Title:     chunkTitle(chunk),
+		Summary:   chunk.Summary,
+		Content:   chunk.Content,
+		Tags:      chunk.Metadata.Tags,
+		Timestamp: chunk.Timestamp,
+	}
+	if p := chunk.Metadata.Provenance; p != nil {
+		entry.Author = p.Author
+		entry.SourceURL = p.SourceURL
+	}
+	return entry
+}
+
+// chunkTitle returns a short one-line title for a memory
+func chunkTitle(chunk *types.ConversationChunk) string {
+	title := chunk.Summary
+	if title == "" {
+		title = chunk.Content
+	}
+	title, _, _ = strings.Cut(strings.TrimSpace(title), "\n")
+	title = strings.TrimSpace(strings.TrimLeft(title, "# "))
+
+	if runes := []rune(title); len(runes) > maxTitleLength {
+		title = string(runes[:maxTitleLength]) + "..."
+	}
+	if title == "" {
+		title = chunk.ID
+	}
+	return title
+}
@@ -0,0 +1,147 @@
+package sitegen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const project = "github.com/acme/api"
+
+var siteNow = time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+
+// storeChunk stores a memory of the project
+func storeChunk(t *testing.T, store storage.VectorStore, id string, chunkType types.ChunkType, content string, edit func(*types.ConversationChunk)) {
+	t.Helper()
+	chunk := &types.ConversationChunk{
+		ID:        id,
+		SessionID: "session",
+		Type:      chunkType,
+		Content:   content,
+		Timestamp: siteNow.Add(-time.Duration(len(id)) * time.Hour),
+		Metadata:  types.ChunkMetadata{Repository: project},
+		// The store requires embeddings, though the site does not use them
+		Embeddings: []float64{0.1, 0.2},
+	}
+	if edit != nil {
+		edit(chunk)
+	}
+	require.NoError(t, store.Store(context.Background(), chunk))
+}
+
+func newTestStore(t *testing.T) storage.VectorStore {
+	t.Helper()
+	ctx := context.Background()
+	store := storage.NewSimpleMockVectorStore()
+
+	storeChunk(t, store, "dec-1", types.ChunkTypeArchitectureDecision, "# Use Postgres for jobs\n\nQueues live in <script>alert(1)</script> tables.", func(c *types.ConversationChunk) {
+		c.Metadata.Provenance = &types.Provenance{Author: "Ana", SourceURL: "https://wiki.acme.io/adr/1"}
+		c.Metadata.Tags = []string{"database"}
+	})
+	storeChunk(t, store, "pat-1", types.ChunkTypeAnalysis, "Retry with jittered backoff", func(c *types.ConversationChunk) {
+		c.Metadata.Tags = []string{"pattern", "resilience"}
+	})
+	storeChunk(t, store, "sol-verified", types.ChunkTypeSolution, "Raise the pool size to 40", func(c *types.ConversationChunk) {
+		c.RecordVerification(types.VerificationRecord{Status: types.VerificationVerified, At: siteNow})
+	})
+	storeChunk(t, store, "sol-unverified", types.ChunkTypeSolution, "Maybe restart it", nil)
+	storeChunk(t, store, "dec-trashed", types.ChunkTypeArchitectureDecision, "Use MongoDB", func(c *types.ConversationChunk) {
+		deleted := siteNow
+		c.Metadata.DeletedAt = &deleted
+	})
+	storeChunk(t, store, "other-repo", types.ChunkTypeArchitectureDecision, "Elsewhere", func(c *types.ConversationChunk) {
+		c.Metadata.Repository = "github.com/acme/web"
+	})
+
+	_, err := store.StoreRelationship(ctx, "dec-1", "sol-verified", types.RelationEnables, 0.9, types.ConfidenceExplicit)
+	require.NoError(t, err)
+	_, err = store.StoreRelationship(ctx, "pat-1", "sol-verified", types.RelationImplements, 0.7, types.ConfidenceInferred)
+	require.NoError(t, err)
+	_, err = store.StoreRelationship(ctx, "dec-1", "sol-unverified", types.RelationRelatedTo, 0.9, types.ConfidenceInferred)
+	require.NoError(t, err)
+	_, err = store.StoreRelationship(ctx, "dec-1", "pat-1", types.RelationRelatedTo, 0.2, types.ConfidenceInferred)
+	require.NoError(t, err)
+	return store
+}
+
+func TestBuilder_Build(t *testing.T) {
+	site, err := NewBuilder(newTestStore(t)).Build(context.Background(), project, "", siteNow)
+	require.NoError(t, err)
+
+	assert.Equal(t, project, site.Title)
+	require.Len(t, site.Section(KindDecision), 1, "trashed decisions and other projects are left out")
+	assert.Equal(t, "Use Postgres for jobs", site.Section(KindDecision)[0].Title)
+	assert.Equal(t, "Ana", site.Section(KindDecision)[0].Author)
+	require.Len(t, site.Section(KindPattern), 1)
+	require.Len(t, site.Section(KindSolution), 1, "only verified solutions are published")
+	assert.Equal(t, "sol-verified", site.Section(KindSolution)[0].ID)
+
+	require.Len(t, site.Edges, 2, "edges need both ends on the site and enough confidence")
+	assert.Len(t, site.Neighbors("sol-verified"), 2)
+
+	_, err = NewBuilder(newTestStore(t)).Build(context.Background(), "", "", siteNow)
+	assert.Error(t, err)
+}
+
+func TestFiles(t *testing.T) {
+	site, err := NewBuilder(newTestStore(t)).Build(context.Background(), project, "Acme API", siteNow)
+	require.NoError(t, err)
+
+	files, err := Files(site)
+	require.NoError(t, err)
+
+	index := string(files["index.html"])
+	assert.Contains(t, index, "<h1>Acme API knowledge base</h1>")
+	assert.Contains(t, index, `href="entries/dec-1.html"`)
+	assert.Contains(t, index, "Verified solutions (1)")
+	assert.Contains(t, index, "<svg", "the overview graph is inlined")
+	assert.Contains(t, string(files["graph.svg"]), `href="entries/sol-verified.html"`)
+
+	decision := string(files["entries/dec-1.html"])
+	assert.Contains(t, decision, "&lt;script&gt;", "memory content is escaped")
+	assert.NotContains(t, decision, "<script>alert")
+	assert.Contains(t, decision, `href="https://wiki.acme.io/adr/1"`)
+	assert.Contains(t, decision, `enables <a href="sol-verified.html">`)
+
+	solution := string(files["entries/sol-verified.html"])
+	assert.Contains(t, solution, "← enables")
+
+	searchIndex := string(files["search-index.js"])
+	assert.True(t, strings.HasPrefix(searchIndex, "window.SEARCH_INDEX = ["))
+	assert.Contains(t, searchIndex, "jittered backoff")
+	assert.NotContains(t, searchIndex, "Maybe restart it")
+	assert.Contains(t, files, ".nojekyll")
+}
+
+func TestWrite_ReplacesPreviousSite(t *testing.T) {
+	store := newTestStore(t)
+	dir := filepath.Join(t.TempDir(), "site")
+	site, err := NewBuilder(store).Build(context.Background(), project, "", siteNow)
+	require.NoError(t, err)
+	require.NoError(t, Write(site, dir))
+	assert.FileExists(t, filepath.Join(dir, "entries", "pat-1.html"))
+
+	require.NoError(t, store.Delete(context.Background(), "pat-1"))
+	site, err = NewBuilder(store).Build(context.Background(), project, "", siteNow)
+	require.NoError(t, err)
+	require.NoError(t, Write(site, dir))
+
+	assert.FileExists(t, filepath.Join(dir, "index.html"))
+	assert.NoFileExists(t, filepath.Join(dir, "entries", "pat-1.html"), "pages of removed entries do not linger")
+	_, err = os.Stat(dir + ".tmp")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestPageFile(t *testing.T) {
+	assert.Equal(t, "3f2a-9b_c.html", PageFile("3f2a-9b_c"))
+	assert.Equal(t, "------etc-passwd.html", PageFile("../../etc/passwd"))
+}
@@ -0,0 +1,343 @@
+package slacksync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// slackAPIURL is the Slack Web API
+	slackAPIURL = "https://slack.com/api"
+	// slackPageSize is how many messages are requested at a time
+	slackPageSize = 200
+	// requestTimeout bounds each API call
+	requestTimeout = 30 * time.Second
+	// maxRateLimitRetries is how often a rate limited call is retried
+	maxRateLimitRetries = 3
+	// maxRetryAfter caps how long a rate limited call waits
+	maxRetryAfter = time.Minute
+)
+
+// skippedSubtypes are channel events that carry no conversation
+var skippedSubtypes = map[string]bool{
+	"channel_join": true, "channel_leave": true, "channel_topic": true, "channel_purpose": true,
+	"channel_name": true, "channel_archive": true, "channel_unarchive": true, "pinned_item": true,
+	"unpinned_item": true, "bot_add": true, "bot_remove": true,
+	// Replies also sent to the channel are imported with their thread
+	"thread_broadcast": true,
+}
+
+// Client reads channel history through the Slack Web API
+type Client struct {
+	baseURL string
+	token   string
+	client  *http.Client
+
+	mu        sync.Mutex
+	users     map[string]string // user id -> "Name <email>"
+	workspace string            // workspace URL, for permalinks
+}
+
+// NewClient creates a Slack client for a bot token with the channels:history,
+// groups:history, users:read and users:read.email scopes. An empty baseURL
+// uses the public Slack API.
+func NewClient(baseURL, token string, client *http.Client) *Client {
+	if baseURL == "" {
+		baseURL = slackAPIURL
+	}
+	if client == nil {
+		client = &http.Client{Timeout: requestTimeout}
+	}
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		client:  client,
+		users:   make(map[string]string),
+	}
+}
+
+// slackMessage is a message object of the Web API
+type slackMessage struct {
+	Type        string `json:"type"`
+	Subtype     string `json:"subtype"`
+	User        string `json:"user"`
+	BotID       string `json:"bot_id"`
+	Username    string `json:"username"`
+	Text        string `json:"text"`
+	TS          string `json:"ts"`
+	ThreadTS    string `json:"thread_ts"`
+	ReplyCount  int    `json:"reply_count"`
+	LatestReply string `json:"latest_reply"`
+	Reactions   []struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	} `json:"reactions"`
+}
+
+// slackMessages is a page of history or replies
+type slackMessages struct {
+	Messages         []slackMessage `json:"messages"`
+	HasMore          bool           `json:"has_more"`
+	ResponseMetadata struct {
+		NextCursor string `json:"next_cursor"`
+	} `json:"response_metadata"`
+}
+
+// History returns the channel's messages sent after oldest, oldest first.
+// Thread replies are not included; see Replies.
+func (c *Client) History(ctx context.Context, channel string, oldest time.Time) ([]Message, error) {
+	params := url.Values{}
+	params.Set("channel", channel)
+	if !oldest.IsZero() {
+		params.Set("oldest", FormatTS(oldest))
+	}
+	messages, err := c.messages(ctx, "conversations.history", params)
+	if err != nil {
+		return nil, err
+	}
+	// History lists the newest messages first
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	return messages, nil
+}
+
+// Replies returns a thread: its parent followed by the replies, oldest first
+func (c *Client) Replies(ctx context.Context, channel, threadTS string) ([]Message, error) {
+	params := url.Values{}
+	params.Set("channel", channel)
+	params.Set("ts", threadTS)
+	return c.messages(ctx, "conversations.replies", params)
+}
+
+// messages reads every page of a history or replies call
+func (c *Client) messages(ctx context.Context, method string, params url.Values) ([]Message, error) {
+	var messages []Message
+	params.Set("limit", strconv.Itoa(slackPageSize))
+	for {
+		var page slackMessages
+		if err := c.call(ctx, method, params, &page); err != nil {
+			return nil, err
+		}
+		for i := range page.Messages {
+			raw := &page.Messages[i]
+			if skippedSubtypes[raw.Subtype] || (raw.Text == "" && len(raw.Reactions) == 0) {
+				continue
+			}
+			messages = append(messages, c.message(ctx, raw))
+		}
+		if !page.HasMore || page.ResponseMetadata.NextCursor == "" {
+			return messages, nil
+		}
+		params.Set("cursor", page.ResponseMetadata.NextCursor)
+	}
+}
+
+// message converts a Web API message, resolving its author and mentions
+func (c *Client) message(ctx context.Context, raw *slackMessage) Message {
+	message := Message{
+		TS:          raw.TS,
+		UserID:      raw.User,
+		Text:        c.markdown(ctx, raw.Text),
+		ThreadTS:    raw.ThreadTS,
+		ReplyCount:  raw.ReplyCount,
+		LatestReply: raw.LatestReply,
+		Time:        ParseTS(raw.TS),
+	}
+	switch {
+	case raw.User != "":
+		message.User = c.userName(ctx, raw.User)
+	case raw.Username != "":
+		message.User = raw.Username
+	case raw.BotID != "":
+		message.User = "bot " + raw.BotID
+	}
+	if len(raw.Reactions) > 0 {
+		message.Reactions = make(map[string]int, len(raw.Reactions))
+		for _, reaction := range raw.Reactions {
+			message.Reactions[reaction.Name] += reaction.Count
+		}
+	}
+	return message
+}
+
+// mrkdwnReference matches Slack's <...> references: mentions, channels and links
+var mrkdwnReference = regexp.MustCompile(`<([^<>]+)>`)
+
+// markdown converts Slack mrkdwn to Markdown, naming mentioned users and channels
+func (c *Client) markdown(ctx context.Context, text string) string {
+	converted := mrkdwnReference.ReplaceAllStringFunc(text, func(match string) string {
+		reference := strings.TrimSuffix(strings.TrimPrefix(match, "<"), ">")
+		target, label, hasLabel := strings.Cut(reference, "|")
+		switch {
+		case strings.HasPrefix(target, "@U"), strings.HasPrefix(target, "@W"):
+			if hasLabel {
+				return "@" + label
+			}
+			name, _, _ := strings.Cut(c.userName(ctx, target[1:]), " <")
+			if name == "" {
+				return target
+			}
+			return "@" + name
+		case strings.HasPrefix(target, "#C"):
+			if hasLabel {
+				return "#" + label
+			}
+			return target
+		case strings.HasPrefix(target, "!"):
+			// Special mentions such as <!here> and <!subteam^ID|@team>
+			if hasLabel {
+				return label
+			}
+			return "@" + strings.TrimPrefix(target, "!")
+		case hasLabel:
+			return "[" + label + "](" + target + ")"
+		default:
+			return target
+		}
+	})
+	return html.UnescapeString(converted)
+}
+
+// userName returns a user as "Name <email>", remembering the answer. Users
+// that cannot be read are named by their ID.
+func (c *Client) userName(ctx context.Context, id string) string {
+	c.mu.Lock()
+	name, ok := c.users[id]
+	c.mu.Unlock()
+	if ok {
+		return name
+	}
+
+	var result struct {
+		User struct {
+			Name    string `json:"name"`
+			Profile struct {
+				RealName    string `json:"real_name"`
+				DisplayName string `json:"display_name"`
+				Email       string `json:"email"`
+			} `json:"profile"`
+		} `json:"user"`
+	}
+	name = id
+	params := url.Values{}
+	params.Set("user", id)
+	if err := c.call(ctx, "users.info", params, &result); err == nil {
+		profile := result.User.Profile
+		switch {
+		case profile.RealName != "":
+			name = profile.RealName
+		case profile.DisplayName != "":
+			name = profile.DisplayName
+		case result.User.Name != "":
+			name = result.User.Name
+		}
+		if profile.Email != "" {
+			name = fmt.Sprintf("%s <%s>", name, profile.Email)
+		}
+	}
+
+	c.mu.Lock()
+	c.users[id] = name
+	c.mu.Unlock()
+	return name
+}
+
+// Permalink returns the link to a message, or "" when the workspace URL is unknown
+func (c *Client) Permalink(ctx context.Context, channel, ts string) string {
+	c.mu.Lock()
+	workspace := c.workspace
+	c.mu.Unlock()
+	if workspace == "" {
+		var result struct {
+			URL string `json:"url"`
+		}
+		if err := c.call(ctx, "auth.test", url.Values{}, &result); err != nil || result.URL == "" {
+			return ""
+		}
+		workspace = strings.TrimSuffix(result.URL, "/")
+		c.mu.Lock()
+		c.workspace = workspace
+		c.mu.Unlock()
+	}
+	return fmt.Sprintf("%s/archives/%s/p%s", workspace, channel, strings.ReplaceAll(ts, ".", ""))
+}
+
+// call sends a Web API request and decodes a successful response into out,
+// waiting out rate limits
+func (c *Client) call(ctx context.Context, method string, params url.Values, out interface{}) error {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/"+method+"?"+params.Encode(), nil)
+		if err != nil {
+			return fmt.Errorf("failed to create slack request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+c.token)
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("slack %s failed: %w", method, err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read slack %s response: %w", method, err)
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxRateLimitRetries {
+			wait := maxRetryAfter
+			if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && time.Duration(seconds)*time.Second < maxRetryAfter {
+				wait = time.Duration(seconds) * time.Second
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+			continue
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("slack %s returned status %d", method, resp.StatusCode)
+		}
+
+		var status struct {
+			OK    bool   `json:"ok"`
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(body, &status); err != nil {
+			return fmt.Errorf("failed to decode slack %s response: %w", method, err)
+		}
+		if !status.OK {
+			return fmt.Errorf("slack %s failed: %s", method, status.Error)
+		}
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("failed to decode slack %s response: %w", method, err)
+		}
+		return nil
+	}
+}
+
+// ParseTS converts a Slack timestamp ("1712345678.000200") to a time
+func ParseTS(ts string) time.Time {
+	seconds, fraction, _ := strings.Cut(ts, ".")
+	sec, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	micros, _ := strconv.ParseInt((fraction + "000000")[:6], 10, 64)
+	return time.Unix(sec, micros*1000).UTC()
+}
+
+// FormatTS converts a time to a Slack timestamp
+func FormatTS(t time.Time) string {
+	return fmt.Sprintf("%d.%06d", t.Unix(), t.Nanosecond()/1000)
+}
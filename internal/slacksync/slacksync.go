@@ -0,0 +1,287 @@
+// Package slacksync imports the history of Slack channels as conversation
+// memories. Threads are imported as one conversation each, other messages
+// are grouped by when they were sent, and reactions are kept as a hint of
+// how useful a conversation was. Channels are synced incrementally from a
+// cursor, and threads that receive new replies are imported again.
+package slacksync
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// defaultIntervalMinutes is how often channels are re-synced unless configured
+	defaultIntervalMinutes = 30
+	// minIntervalMinutes keeps channels from being polled too often
+	minIntervalMinutes = 5
+	// defaultHistoryDays is how far back the first sync of a channel reaches
+	defaultHistoryDays = 30
+)
+
+// ChannelSpec configures one synced channel
+type ChannelSpec struct {
+	// ID is the channel ID, e.g. C0123ABCD
+	ID         string `yaml:"id" json:"id"`
+	Name       string `yaml:"name,omitempty" json:"name,omitempty"`
+	Repository string `yaml:"repository" json:"repository"`
+	// HistoryDays is how far back the first sync reaches; 0 uses the default
+	HistoryDays     int `yaml:"history_days,omitempty" json:"history_days,omitempty"`
+	IntervalMinutes int `yaml:"interval_minutes,omitempty" json:"interval_minutes,omitempty"`
+}
+
+// Validate checks the spec for missing or invalid fields
+func (s *ChannelSpec) Validate() error {
+	if s.ID == "" {
+		return errors.New("slack channel id is required")
+	}
+	if s.Repository == "" {
+		return fmt.Errorf("slack channel %s requires a repository", s.ID)
+	}
+	if s.HistoryDays < 0 {
+		return fmt.Errorf("slack channel %s history_days cannot be negative", s.ID)
+	}
+	if s.IntervalMinutes != 0 && s.IntervalMinutes < minIntervalMinutes {
+		return fmt.Errorf("slack channel %s interval must be at least %d minutes", s.ID, minIntervalMinutes)
+	}
+	return nil
+}
+
+// DisplayName returns the channel name, or its ID when no name is configured
+func (s *ChannelSpec) DisplayName() string {
+	if s.Name != "" {
+		return strings.TrimPrefix(s.Name, "#")
+	}
+	return s.ID
+}
+
+// Interval returns how often the channel is re-synced
+func (s *ChannelSpec) Interval() time.Duration {
+	if s.IntervalMinutes == 0 {
+		return defaultIntervalMinutes * time.Minute
+	}
+	return time.Duration(s.IntervalMinutes) * time.Minute
+}
+
+// History returns how far back the first sync reaches
+func (s *ChannelSpec) History() time.Duration {
+	if s.HistoryDays == 0 {
+		return defaultHistoryDays * 24 * time.Hour
+	}
+	return time.Duration(s.HistoryDays) * 24 * time.Hour
+}
+
+// channelsFile is the YAML layout of a Slack channels file
+type channelsFile struct {
+	Channels []ChannelSpec `yaml:"channels"`
+}
+
+// LoadChannels reads channel specs from a YAML file
+func LoadChannels(path string) ([]ChannelSpec, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // Path comes from operator configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read slack channels: %w", err)
+	}
+
+	var file channelsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse slack channels: %w", err)
+	}
+
+	ids := make(map[string]bool, len(file.Channels))
+	for i := range file.Channels {
+		if err := file.Channels[i].Validate(); err != nil {
+			return nil, err
+		}
+		if ids[file.Channels[i].ID] {
+			return nil, fmt.Errorf("duplicate slack channel %s", file.Channels[i].ID)
+		}
+		ids[file.Channels[i].ID] = true
+	}
+	return file.Channels, nil
+}
+
+// Message is a channel message or thread reply, with its author resolved
+type Message struct {
+	TS     string `json:"ts"`
+	UserID string `json:"user_id,omitempty"`
+	// User is the author as "Name <email>" when the email is known
+	User     string `json:"user"`
+	Text     string `json:"text"`
+	ThreadTS string `json:"thread_ts,omitempty"`
+	// ReplyCount and LatestReply are set on thread parents
+	ReplyCount  int            `json:"reply_count,omitempty"`
+	LatestReply string         `json:"latest_reply,omitempty"`
+	Reactions   map[string]int `json:"reactions,omitempty"`
+	Time        time.Time      `json:"time"`
+}
+
+// IsThreadParent reports whether the message started a thread
+func (m *Message) IsThreadParent() bool {
+	return m.ReplyCount > 0 && (m.ThreadTS == "" || m.ThreadTS == m.TS)
+}
+
+// Conversation is a thread, or a run of channel messages, imported as one memory
+type Conversation struct {
+	// ThreadTS is the timestamp of the thread parent, or "" for channel messages
+	ThreadTS string    `json:"thread_ts,omitempty"`
+	Messages []Message `json:"messages"`
+	// URL links to the thread or the first message
+	URL string `json:"url,omitempty"`
+}
+
+// Key identifies the conversation: the thread or its first message
+func (c *Conversation) Key() string {
+	if c.ThreadTS != "" {
+		return c.ThreadTS
+	}
+	if len(c.Messages) == 0 {
+		return ""
+	}
+	return c.Messages[0].TS
+}
+
+// Author returns who started the conversation
+func (c *Conversation) Author() string {
+	if len(c.Messages) == 0 {
+		return ""
+	}
+	return c.Messages[0].User
+}
+
+// Participants returns everyone who wrote in the conversation, in order of first message
+func (c *Conversation) Participants() []string {
+	seen := make(map[string]bool)
+	var participants []string
+	for i := range c.Messages {
+		if user := c.Messages[i].User; user != "" && !seen[user] {
+			seen[user] = true
+			participants = append(participants, user)
+		}
+	}
+	return participants
+}
+
+// Reactions totals the reactions to the conversation's messages by emoji
+func (c *Conversation) Reactions() map[string]int {
+	totals := make(map[string]int)
+	for i := range c.Messages {
+		for emoji, count := range c.Messages[i].Reactions {
+			totals[emoji] += count
+		}
+	}
+	return totals
+}
+
+// positiveReactions and negativeReactions are the emoji read as endorsing or
+// disputing a message. Skin tone variants are folded into the base emoji.
+var (
+	positiveReactions = map[string]bool{
+		"+1": true, "thumbsup": true, "white_check_mark": true, "heavy_check_mark": true,
+		"100": true, "tada": true, "raised_hands": true, "clap": true, "heart": true,
+		"star": true, "pray": true, "rocket": true, "bulb": true,
+	}
+	negativeReactions = map[string]bool{
+		"-1": true, "thumbsdown": true, "x": true, "no_entry": true, "confused": true,
+	}
+)
+
+// UsefulnessHint scores from 0 to 1 how much the conversation was endorsed
+// with reactions: positive reactions raise it with diminishing returns and
+// negative ones cancel them out. One net endorsement scores 0.5.
+func (c *Conversation) UsefulnessHint() float64 {
+	net := 0
+	for emoji, count := range c.Reactions() {
+		base, _, _ := strings.Cut(emoji, "::")
+		switch {
+		case positiveReactions[base]:
+			net += count
+		case negativeReactions[base]:
+			net -= count
+		}
+	}
+	if net <= 0 {
+		return 0
+	}
+	return 1 - 1/float64(1+net)
+}
+
+// Start returns when the conversation began
+func (c *Conversation) Start() time.Time {
+	if len(c.Messages) == 0 {
+		return time.Time{}
+	}
+	return c.Messages[0].Time
+}
+
+// Parts renders the conversation as Markdown, one line per message, split
+// between messages into parts of at most maxRunes runes. A message longer
+// than maxRunes is cut.
+func (c *Conversation) Parts(maxRunes int) []string {
+	var parts []string
+	var current strings.Builder
+	for i := range c.Messages {
+		line := c.Messages[i].markdown()
+		if runes := []rune(line); len(runes) > maxRunes {
+			line = string(runes[:maxRunes])
+		}
+		if current.Len() > 0 && len([]rune(current.String()))+len([]rune(line))+1 > maxRunes {
+			parts = append(parts, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+		current.WriteString(line + "\n")
+	}
+	if text := strings.TrimSpace(current.String()); text != "" {
+		parts = append(parts, text)
+	}
+	return parts
+}
+
+// markdown renders a message as "**Author** (time): text"
+func (m *Message) markdown() string {
+	author := m.User
+	if name, _, found := strings.Cut(author, " <"); found {
+		author = name
+	}
+	if author == "" {
+		author = "unknown"
+	}
+	return fmt.Sprintf("**%s** (%s): %s", author, m.Time.UTC().Format("2006-01-02 15:04"), m.Text)
+}
+
+// GroupMessages turns channel messages into conversations. Thread parents
+// start a conversation of their own, to be filled with the thread's replies;
+// other messages are grouped with those sent within gap of the previous one,
+// up to maxMessages per group.
+func GroupMessages(messages []Message, gap time.Duration, maxMessages int) []Conversation {
+	sorted := append([]Message(nil), messages...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	var conversations []Conversation
+	current := -1 // index of the group messages are added to
+	for i := range sorted {
+		message := sorted[i]
+		if message.IsThreadParent() {
+			conversations = append(conversations, Conversation{ThreadTS: message.TS, Messages: []Message{message}})
+			current = -1
+			continue
+		}
+		if current >= 0 {
+			group := &conversations[current]
+			last := group.Messages[len(group.Messages)-1]
+			if message.Time.Sub(last.Time) <= gap && len(group.Messages) < maxMessages {
+				group.Messages = append(group.Messages, message)
+				continue
+			}
+		}
+		conversations = append(conversations, Conversation{Messages: []Message{message}})
+		current = len(conversations) - 1
+	}
+	return conversations
+}
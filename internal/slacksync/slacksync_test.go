@@ -0,0 +1,325 @@
+package slacksync
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var syncNow = time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+// message builds a channel message sent minutes before syncNow
+func message(minutesAgo int, user, text string) Message {
+	at := syncNow.Add(-time.Duration(minutesAgo) * time.Minute)
+	return Message{TS: FormatTS(at), User: user, Text: text, Time: at}
+}
+
+func TestChannelSpec_Validate(t *testing.T) {
+	valid := ChannelSpec{ID: "C01", Name: "#eng", Repository: "github.com/acme/api"}
+	require.NoError(t, valid.Validate())
+	assert.Equal(t, 30*time.Minute, valid.Interval())
+	assert.Equal(t, 30*24*time.Hour, valid.History())
+	assert.Equal(t, "eng", valid.DisplayName())
+
+	noRepository := valid
+	noRepository.Repository = ""
+	assert.Error(t, noRepository.Validate())
+
+	tooOften := valid
+	tooOften.IntervalMinutes = 1
+	assert.Error(t, tooOften.Validate())
+}
+
+func TestLoadChannels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "channels.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("channels:\n  - id: C01\n    repository: github.com/acme/api\n    history_days: 7\n"), 0o600))
+
+	channels, err := LoadChannels(path)
+	require.NoError(t, err)
+	require.Len(t, channels, 1)
+	assert.Equal(t, 7*24*time.Hour, channels[0].History())
+
+	require.NoError(t, os.WriteFile(path, []byte("channels:\n  - id: C01\n    repository: a\n  - id: C01\n    repository: b\n"), 0o600))
+	_, err = LoadChannels(path)
+	assert.ErrorContains(t, err, "duplicate")
+}
+
+func TestGroupMessages(t *testing.T) {
+	parent := message(200, "Ana", "Deploy is failing")
+	parent.ReplyCount = 2
+	messages := []Message{
+		message(10, "Bruno", "late follow-up"),
+		message(300, "Ana", "morning"),
+		message(290, "Bruno", "morning!"),
+		parent,
+		message(195, "Caio", "unrelated after the thread"),
+	}
+
+	conversations := GroupMessages(messages, 30*time.Minute, 20)
+
+	require.Len(t, conversations, 4)
+	assert.Len(t, conversations[0].Messages, 2, "messages close together are grouped")
+	assert.Equal(t, parent.TS, conversations[1].ThreadTS, "threads are conversations of their own")
+	assert.Equal(t, parent.TS, conversations[1].Key())
+	assert.Equal(t, "unrelated after the thread", conversations[2].Messages[0].Text)
+	assert.Equal(t, "late follow-up", conversations[3].Messages[0].Text, "a long pause starts a new conversation")
+
+	capped := GroupMessages([]Message{message(3, "a", "1"), message(2, "a", "2"), message(1, "a", "3")}, time.Hour, 2)
+	assert.Len(t, capped, 2)
+}
+
+func TestConversation_UsefulnessHint(t *testing.T) {
+	conversation := Conversation{Messages: []Message{
+		{Text: "fix", Reactions: map[string]int{"+1::skin-tone-3": 2, "eyes": 4}},
+		{Text: "nope", Reactions: map[string]int{"-1": 1}},
+	}}
+	assert.InDelta(t, 0.5, conversation.UsefulnessHint(), 0.001, "one net endorsement")
+
+	conversation.Messages[0].Reactions["white_check_mark"] = 2
+	assert.InDelta(t, 0.75, conversation.UsefulnessHint(), 0.001)
+
+	assert.Zero(t, (&Conversation{Messages: []Message{{Reactions: map[string]int{"x": 3}}}}).UsefulnessHint())
+}
+
+func TestConversation_Parts(t *testing.T) {
+	conversation := Conversation{Messages: []Message{
+		message(3, "Ana <ana@acme.io>", strings.Repeat("a", 60)),
+		message(2, "Bruno", strings.Repeat("b", 60)),
+		message(1, "", strings.Repeat("c", 200)),
+	}}
+
+	parts := conversation.Parts(100)
+
+	require.Len(t, parts, 3)
+	assert.True(t, strings.HasPrefix(parts[0], "**Ana** (2026-03-10 11:57): "))
+	assert.True(t, strings.HasPrefix(parts[2], "**unknown**"))
+	for _, part := range parts {
+		assert.LessOrEqual(t, len([]rune(part)), 100)
+	}
+	assert.Equal(t, []string{"Ana <ana@acme.io>", "Bruno"}, conversation.Participants())
+}
+
+func TestTimestamps(t *testing.T) {
+	at := ParseTS("1712345678.000200")
+	assert.Equal(t, int64(1712345678), at.Unix())
+	assert.Equal(t, 200*time.Microsecond, time.Duration(at.Nanosecond()))
+	assert.Equal(t, "1712345678.000200", FormatTS(at))
+	assert.True(t, ParseTS("bogus").IsZero())
+}
+
+func TestClient(t *testing.T) {
+	var historyPages int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer xoxb-test", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/conversations.history":
+			if r.URL.Query().Get("channel") != "C01" {
+				_, _ = w.Write([]byte(`{"ok": false, "error": "channel_not_found"}`))
+				return
+			}
+			historyPages++
+			if r.URL.Query().Get("cursor") == "" {
+				_, _ = w.Write([]byte(`{"ok": true, "has_more": true, "response_metadata": {"next_cursor": "page2"}, "messages": [
+					{"type": "message", "user": "U1", "text": "ask <@U2> about &lt;this&gt; in <#C02|ops>, see <https://x.io|the doc>", "ts": "1700000300.000100",
+					 "reactions": [{"name": "+1", "count": 2}]},
+					{"type": "message", "subtype": "channel_join", "user": "U3", "text": "joined", "ts": "1700000200.000100"}
+				]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"ok": true, "messages": [
+				{"type": "message", "user": "U2", "text": "thread start", "ts": "1700000100.000100", "thread_ts": "1700000100.000100", "reply_count": 1, "latest_reply": "1700000150.000100"}
+			]}`))
+		case "/conversations.replies":
+			assert.Equal(t, "1700000100.000100", r.URL.Query().Get("ts"))
+			_, _ = w.Write([]byte(`{"ok": true, "messages": [
+				{"type": "message", "user": "U2", "text": "thread start", "ts": "1700000100.000100", "thread_ts": "1700000100.000100"},
+				{"type": "message", "bot_id": "B1", "text": "bot reply", "ts": "1700000150.000100", "thread_ts": "1700000100.000100"}
+			]}`))
+		case "/users.info":
+			switch r.URL.Query().Get("user") {
+			case "U1":
+				_, _ = w.Write([]byte(`{"ok": true, "user": {"name": "ana", "profile": {"real_name": "Ana Lima", "email": "ana@acme.io"}}}`))
+			default:
+				_, _ = w.Write([]byte(`{"ok": false, "error": "user_not_found"}`))
+			}
+		case "/auth.test":
+			_, _ = w.Write([]byte(`{"ok": true, "url": "https://acme.slack.com/"}`))
+		default:
+			_, _ = w.Write([]byte(`{"ok": false, "error": "unknown_method"}`))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := NewClient(server.URL, "xoxb-test", server.Client())
+
+	messages, err := client.History(ctx, "C01", time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, historyPages)
+	require.Len(t, messages, 2, "channel events are skipped")
+	assert.Equal(t, "thread start", messages[0].Text, "history is returned oldest first")
+	assert.True(t, messages[0].IsThreadParent())
+	assert.Equal(t, "U2", messages[0].User, "unknown users are named by ID")
+	assert.Equal(t, "Ana Lima <ana@acme.io>", messages[1].User)
+	assert.Equal(t, "ask @U2 about <this> in #ops, see [the doc](https://x.io)", messages[1].Text)
+	assert.Equal(t, 2, messages[1].Reactions["+1"])
+
+	replies, err := client.Replies(ctx, "C01", "1700000100.000100")
+	require.NoError(t, err)
+	require.Len(t, replies, 2)
+	assert.Equal(t, "bot B1", replies[1].User)
+
+	assert.Equal(t, "https://acme.slack.com/archives/C01/p1700000100000100", client.Permalink(ctx, "C01", "1700000100.000100"))
+
+	_, err = client.History(ctx, "C404", time.Time{})
+	assert.ErrorContains(t, err, "channel_not_found")
+}
+
+// fakeSource serves a channel from memory
+type fakeSource struct {
+	history []Message
+	threads map[string][]Message
+	err     error
+	oldest  time.Time
+}
+
+func (f *fakeSource) History(_ context.Context, _ string, oldest time.Time) ([]Message, error) {
+	f.oldest = oldest
+	if f.err != nil {
+		return nil, f.err
+	}
+	var messages []Message
+	for _, message := range f.history {
+		if message.Time.After(oldest) {
+			messages = append(messages, message)
+		}
+	}
+	return messages, nil
+}
+
+func (f *fakeSource) Replies(_ context.Context, _, threadTS string) ([]Message, error) {
+	return f.threads[threadTS], nil
+}
+
+func (f *fakeSource) Permalink(_ context.Context, channel, ts string) string {
+	return "https://acme.slack.com/archives/" + channel + "/p" + strings.ReplaceAll(ts, ".", "")
+}
+
+// recordingIngester remembers the conversations it ingested
+type recordingIngester struct {
+	conversations []Conversation
+	previous      [][]string
+	failOn        string
+	next          int
+}
+
+func (r *recordingIngester) IngestConversation(_ context.Context, _ *ChannelSpec, conversation *Conversation, previous []string) ([]string, error) {
+	if r.failOn != "" && conversation.Messages[0].Text == r.failOn {
+		return nil, errors.New("store unavailable")
+	}
+	r.conversations = append(r.conversations, *conversation)
+	r.previous = append(r.previous, previous)
+	r.next++
+	return []string{"chunk-" + string(rune('a'+r.next-1))}, nil
+}
+
+func newTestSyncer(t *testing.T, source *fakeSource, ingester *recordingIngester) *Syncer {
+	t.Helper()
+	syncer := NewSyncer(filepath.Join(t.TempDir(), "state.json"), ingester)
+	syncer.now = func() time.Time { return syncNow }
+	require.NoError(t, syncer.AddChannel(&ChannelSpec{ID: "C01", Name: "eng", Repository: "github.com/acme/api"}, source))
+	return syncer
+}
+
+func TestSyncer_IncrementalAndThreadUpdates(t *testing.T) {
+	ctx := context.Background()
+	parent := message(120, "Ana", "Why is CI red?")
+	parent.ReplyCount, parent.LatestReply = 1, message(110, "", "").TS
+	reply := message(110, "Bruno", "flaky test, rerun")
+	reply.ThreadTS = parent.TS
+	source := &fakeSource{
+		history: []Message{message(300, "Ana", "standup notes"), parent},
+		threads: map[string][]Message{parent.TS: {parent, reply}},
+	}
+	ingester := &recordingIngester{}
+	syncer := newTestSyncer(t, source, ingester)
+
+	result, err := syncer.Sync(ctx, "C01")
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Imported)
+	assert.Equal(t, syncNow.Add(-30*24*time.Hour), source.oldest, "the first sync reaches back the history window")
+	require.Len(t, ingester.conversations, 2)
+	thread := ingester.conversations[1]
+	assert.Len(t, thread.Messages, 2, "threads are imported with their replies")
+	assert.Equal(t, "https://acme.slack.com/archives/C01/p"+strings.ReplaceAll(parent.TS, ".", ""), thread.URL)
+
+	// Nothing new: nothing is imported again
+	syncer.now = func() time.Time { return syncNow.Add(time.Hour) }
+	result, err = syncer.Sync(ctx, "C01")
+	require.NoError(t, err)
+	assert.Zero(t, result.Imported+result.ThreadsUpdated)
+	assert.Equal(t, syncNow.Add(time.Hour-threadWindow), source.oldest, "later syncs reach back to watched threads")
+
+	// A new message and a new reply to the watched thread
+	late := message(-30, "Caio", "rerun passed")
+	late.ThreadTS = parent.TS
+	updatedParent := parent
+	updatedParent.ReplyCount, updatedParent.LatestReply = 2, late.TS
+	source.history = []Message{message(300, "Ana", "standup notes"), updatedParent, message(-40, "Ana", "release at 3pm")}
+	source.threads[parent.TS] = []Message{updatedParent, reply, late}
+
+	result, err = syncer.Sync(ctx, "C01")
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Imported)
+	assert.Equal(t, 1, result.ThreadsUpdated)
+	require.Len(t, ingester.conversations, 4)
+	assert.Len(t, ingester.conversations[3].Messages, 3)
+	assert.Equal(t, []string{"chunk-b"}, ingester.previous[3], "the previous version of the thread is replaced")
+
+	// Progress survives a restart
+	restarted := NewSyncer(syncer.path, ingester)
+	require.NoError(t, restarted.Load())
+	require.NoError(t, restarted.AddChannel(&ChannelSpec{ID: "C01", Repository: "github.com/acme/api"}, source))
+	channels := restarted.Channels()
+	require.Len(t, channels, 1)
+	assert.Equal(t, 3, channels[0].Conversations)
+	assert.Equal(t, 1, channels[0].Threads)
+	assert.Equal(t, FormatTS(syncNow.Add(40*time.Minute)), channels[0].Cursor)
+}
+
+func TestSyncer_FailureKeepsCursor(t *testing.T) {
+	ctx := context.Background()
+	source := &fakeSource{history: []Message{message(300, "Ana", "first"), message(200, "Ana", "second"), message(100, "Ana", "third")}}
+	ingester := &recordingIngester{failOn: "second"}
+	syncer := newTestSyncer(t, source, ingester)
+
+	result, err := syncer.Sync(ctx, "C01")
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Imported)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, source.history[0].TS, syncer.Channels()[0].Cursor, "the cursor stops before the failed conversation")
+
+	ingester.failOn = ""
+	result, err = syncer.Sync(ctx, "C01")
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Imported, "the failed conversation is retried")
+	assert.Empty(t, syncer.Channels()[0].LastError)
+
+	source.err = errors.New("channel_not_found")
+	_, err = syncer.Sync(ctx, "C01")
+	assert.ErrorContains(t, err, "channel_not_found")
+	assert.Equal(t, "channel_not_found", syncer.Channels()[0].LastError)
+
+	_, err = syncer.Sync(ctx, "C99")
+	assert.ErrorContains(t, err, "not found")
+}
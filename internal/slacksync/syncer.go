@@ -0,0 +1,391 @@
+package slacksync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"lerian-mcp-memory/internal/logging"
+)
+
+const (
+	// groupGap is the longest pause between channel messages of one conversation
+	groupGap = 30 * time.Minute
+	// maxGroupMessages bounds the channel messages grouped into one conversation
+	maxGroupMessages = 20
+	// threadWindow is how long threads are watched for new replies
+	threadWindow = 7 * 24 * time.Hour
+	// MaxPartRunes bounds the text of one imported memory
+	MaxPartRunes = 4000
+)
+
+// Source reads a channel's messages; Client implements it for the Slack API
+type Source interface {
+	History(ctx context.Context, channel string, oldest time.Time) ([]Message, error)
+	Replies(ctx context.Context, channel, threadTS string) ([]Message, error)
+	Permalink(ctx context.Context, channel, ts string) string
+}
+
+// Ingester stores a conversation as memories, replacing the memories
+// imported from its previous version, and returns their IDs
+type Ingester interface {
+	IngestConversation(ctx context.Context, spec *ChannelSpec, conversation *Conversation, previous []string) ([]string, error)
+}
+
+// ThreadState records which version of a thread was imported
+type ThreadState struct {
+	LatestReply string    `json:"latest_reply"`
+	SyncedAt    time.Time `json:"synced_at"`
+	ChunkIDs    []string  `json:"chunk_ids"`
+}
+
+// ChannelState is the sync progress of one channel
+type ChannelState struct {
+	// Cursor is the timestamp of the newest channel message imported
+	Cursor        string    `json:"cursor,omitempty"`
+	LastSync      time.Time `json:"last_sync"`
+	LastError     string    `json:"last_error,omitempty"`
+	Conversations int       `json:"conversations"`
+	// Threads are the recent threads watched for new replies, by parent timestamp
+	Threads map[string]ThreadState `json:"threads"`
+}
+
+// ChannelStatus reports a channel and its sync progress
+type ChannelStatus struct {
+	ChannelSpec
+	LastSync      *time.Time `json:"last_sync,omitempty"`
+	NextSync      time.Time  `json:"next_sync"`
+	LastError     string     `json:"last_error,omitempty"`
+	Cursor        string     `json:"cursor,omitempty"`
+	Conversations int        `json:"conversations"`
+	Threads       int        `json:"threads_watched"`
+}
+
+// Result reports one sync of a channel
+type Result struct {
+	Channel        string    `json:"channel"`
+	Messages       int       `json:"messages_fetched"`
+	Imported       int       `json:"conversations_imported"`
+	ThreadsUpdated int       `json:"threads_updated"`
+	Failed         int       `json:"conversations_failed"`
+	Chunks         int       `json:"chunks_stored"`
+	Errors         []string  `json:"errors,omitempty"`
+	StartedAt      time.Time `json:"started_at"`
+	FinishedAt     time.Time `json:"finished_at"`
+}
+
+// syncChannel is a configured channel and its source
+type syncChannel struct {
+	spec   ChannelSpec
+	source Source
+}
+
+// Syncer imports channel history and re-imports threads that receive new
+// replies. Sync progress is persisted to an optional JSON file so restarts
+// resume from the cursor. Edited and deleted messages are not detected.
+type Syncer struct {
+	syncMu sync.Mutex // one sync at a time
+
+	mu       sync.RWMutex
+	path     string
+	ingester Ingester
+	channels map[string]*syncChannel
+	state    map[string]*ChannelState
+	now      func() time.Time
+	interval time.Duration
+}
+
+// NewSyncer creates a syncer persisting its progress at path; an empty path keeps it in memory
+func NewSyncer(path string, ingester Ingester) *Syncer {
+	return &Syncer{
+		path:     path,
+		ingester: ingester,
+		channels: make(map[string]*syncChannel),
+		state:    make(map[string]*ChannelState),
+		now:      time.Now,
+		interval: time.Minute,
+	}
+}
+
+// Load reads sync progress from disk. A missing file is not an error.
+func (s *Syncer) Load() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read slack sync state: %w", err)
+	}
+
+	state := make(map[string]*ChannelState)
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse slack sync state: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = state
+	return nil
+}
+
+// AddChannel registers a channel to sync
+func (s *Syncer) AddChannel(spec *ChannelSpec, source Source) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.channels[spec.ID]; exists {
+		return fmt.Errorf("slack channel %s already exists", spec.ID)
+	}
+	s.channels[spec.ID] = &syncChannel{spec: *spec, source: source}
+	return nil
+}
+
+// Channels returns the configured channels and their progress, by ID
+func (s *Syncer) Channels() []ChannelStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]ChannelStatus, 0, len(s.channels))
+	for id, channel := range s.channels {
+		status := ChannelStatus{ChannelSpec: channel.spec, NextSync: s.now().UTC()}
+		if state, ok := s.state[id]; ok {
+			status.LastError = state.LastError
+			status.Cursor = state.Cursor
+			status.Conversations = state.Conversations
+			status.Threads = len(state.Threads)
+			if !state.LastSync.IsZero() {
+				lastSync := state.LastSync
+				status.LastSync = &lastSync
+				status.NextSync = lastSync.Add(channel.spec.Interval())
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].ID < statuses[j].ID })
+	return statuses
+}
+
+// Sync imports the channel's messages sent since its cursor, and re-imports
+// recent threads that received replies since they were imported
+func (s *Syncer) Sync(ctx context.Context, id string) (*Result, error) {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	s.mu.RLock()
+	channel, ok := s.channels[id]
+	var cursor string
+	threads := make(map[string]ThreadState)
+	if state, exists := s.state[id]; exists {
+		cursor = state.Cursor
+		for ts, thread := range state.Threads {
+			threads[ts] = thread
+		}
+	}
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("slack channel %s not found", id)
+	}
+
+	result := &Result{Channel: id, StartedAt: s.now().UTC()}
+	watchedSince := result.StartedAt.Add(-threadWindow)
+	oldest := result.StartedAt.Add(-channel.spec.History())
+	if cursor != "" {
+		// Reach back far enough to see new replies to watched threads
+		oldest = ParseTS(cursor)
+		if watchedSince.Before(oldest) {
+			oldest = watchedSince
+		}
+	}
+
+	messages, err := channel.source.History(ctx, id, oldest)
+	if err != nil {
+		if recordErr := s.recordSync(id, nil, cursor, 0, result.StartedAt, err.Error()); recordErr != nil {
+			logging.Warn("Failed to record slack sync", "channel", id, "error", recordErr)
+		}
+		return nil, fmt.Errorf("failed to read history of %s: %w", id, err)
+	}
+	result.Messages = len(messages)
+
+	cursorTime := ParseTS(cursor)
+	var fresh []Message
+	var updated []Conversation
+	for i := range messages {
+		message := &messages[i]
+		if cursor == "" || ParseTS(message.TS).After(cursorTime) {
+			fresh = append(fresh, *message)
+			continue
+		}
+		// Threads imported before with replies since
+		if thread, watched := threads[message.TS]; watched && message.IsThreadParent() && message.LatestReply != thread.LatestReply {
+			updated = append(updated, Conversation{ThreadTS: message.TS, Messages: []Message{*message}})
+		}
+	}
+
+	synced := make(map[string]ThreadState)
+	ingest := func(conversation *Conversation, previous []string) error {
+		if conversation.ThreadTS != "" {
+			replies, err := channel.source.Replies(ctx, id, conversation.ThreadTS)
+			if err != nil {
+				return err
+			}
+			if len(replies) > 0 {
+				conversation.Messages = replies
+			}
+		}
+		conversation.URL = channel.source.Permalink(ctx, id, conversation.Key())
+
+		chunkIDs, err := s.ingester.IngestConversation(ctx, &channel.spec, conversation, previous)
+		if err != nil {
+			return err
+		}
+		result.Chunks += len(chunkIDs)
+		if conversation.ThreadTS != "" {
+			synced[conversation.ThreadTS] = ThreadState{
+				LatestReply: conversation.Messages[len(conversation.Messages)-1].TS,
+				SyncedAt:    s.now().UTC(),
+				ChunkIDs:    chunkIDs,
+			}
+		}
+		return nil
+	}
+
+	// New conversations are imported in order, and the cursor only advances
+	// past those imported, so a failure is retried from there next time
+	newCursor := cursor
+	for _, conversation := range GroupMessages(fresh, groupGap, maxGroupMessages) {
+		conversation := conversation
+		if err := ingest(&conversation, nil); err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("conversation %s: %v", conversation.Key(), err))
+			break
+		}
+		result.Imported++
+		if conversation.ThreadTS != "" {
+			newCursor = conversation.ThreadTS
+		} else {
+			newCursor = conversation.Messages[len(conversation.Messages)-1].TS
+		}
+	}
+
+	for i := range updated {
+		conversation := &updated[i]
+		if err := ingest(conversation, threads[conversation.ThreadTS].ChunkIDs); err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("thread %s: %v", conversation.ThreadTS, err))
+			continue
+		}
+		result.ThreadsUpdated++
+	}
+
+	lastError := ""
+	if result.Failed > 0 {
+		lastError = fmt.Sprintf("%d conversations failed to import", result.Failed)
+	}
+	if err := s.recordSync(id, synced, newCursor, result.Imported, result.StartedAt, lastError); err != nil {
+		return result, err
+	}
+
+	result.FinishedAt = s.now().UTC()
+	logging.Info("Slack sync finished", "channel", id, "messages", result.Messages, "imported", result.Imported, "threads_updated", result.ThreadsUpdated, "failed", result.Failed)
+	return result, nil
+}
+
+// SyncDue syncs every channel whose interval has passed since its last sync
+func (s *Syncer) SyncDue(ctx context.Context, now time.Time) []Result {
+	var results []Result
+	for _, status := range s.Channels() {
+		if status.NextSync.After(now) {
+			continue
+		}
+		result, err := s.Sync(ctx, status.ID)
+		if err != nil {
+			logging.Error("Slack sync failed", "channel", status.ID, "error", err)
+			continue
+		}
+		results = append(results, *result)
+	}
+	return results
+}
+
+// Run syncs channels as they fall due until the context is cancelled
+func (s *Syncer) Run(ctx context.Context) {
+	s.SyncDue(ctx, s.now())
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logging.Info("Stopping slack sync due to context cancellation")
+			return
+		case now := <-ticker.C:
+			s.SyncDue(ctx, now)
+		}
+	}
+}
+
+// recordSync stores the outcome of a sync that started at startedAt and
+// stops watching threads older than the thread window
+func (s *Syncer) recordSync(id string, synced map[string]ThreadState, cursor string, imported int, startedAt time.Time, lastError string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.state[id]
+	if !ok {
+		state = &ChannelState{}
+		s.state[id] = state
+	}
+	if state.Threads == nil {
+		state.Threads = make(map[string]ThreadState)
+	}
+	for ts, thread := range synced {
+		state.Threads[ts] = thread
+	}
+	watchedSince := startedAt.Add(-threadWindow)
+	for ts := range state.Threads {
+		if ParseTS(ts).Before(watchedSince) {
+			delete(state.Threads, ts)
+		}
+	}
+	state.Cursor = cursor
+	state.Conversations += imported
+	state.LastSync = startedAt
+	state.LastError = lastError
+	return s.persistLocked()
+}
+
+// persistLocked writes the sync progress to disk; callers must hold the write lock
+func (s *Syncer) persistLocked() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode slack sync state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o750); err != nil {
+		return fmt.Errorf("failed to create slack sync state directory: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write slack sync state: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+
+	"lerian-mcp-memory/pkg/types"
+)
+
+// archiveOverfetch is how many times the requested results an archive-tier
+// search asks for, so dropping archived ones still leaves enough to return
+const archiveOverfetch = 2
+
+// ArchiveTierVectorStore wraps a VectorStore and keeps archived chunks (see
+// types.ConversationChunk.IsArchived) out of searches unless the query sets
+// IncludeArchived. Archived chunks stay where they are: lookups by ID and
+// listings still return them, so they can be restored, and trashed chunks
+// are left to the IncludeDeleted filter of the wrapped store.
+type ArchiveTierVectorStore struct {
+	VectorStore
+}
+
+// NewArchiveTierVectorStore keeps archived chunks of store out of searches
+func NewArchiveTierVectorStore(store VectorStore) *ArchiveTierVectorStore {
+	return &ArchiveTierVectorStore{VectorStore: store}
+}
+
+// Search searches chunks that are not archived, or every chunk when the
+// query includes the archive
+func (s *ArchiveTierVectorStore) Search(ctx context.Context, query *types.MemoryQuery, embeddings []float64) (*types.SearchResults, error) {
+	if query.IncludeArchived {
+		return s.VectorStore.Search(ctx, query, embeddings)
+	}
+
+	limit := query.Limit
+	if limit > 0 {
+		widened := *query
+		widened.Limit = limit * archiveOverfetch
+		query = &widened
+	}
+	results, err := s.VectorStore.Search(ctx, query, embeddings)
+	if err != nil || results == nil {
+		return results, err
+	}
+
+	kept := results.Results[:0]
+	for i := range results.Results {
+		if !results.Results[i].Chunk.IsArchived() {
+			kept = append(kept, results.Results[i])
+		}
+	}
+	if limit > 0 && len(kept) > limit {
+		kept = kept[:limit]
+	}
+	results.Results = kept
+	results.Total = len(kept)
+	return results, nil
+}
+
+// FindSimilar finds similar chunks that are not archived
+func (s *ArchiveTierVectorStore) FindSimilar(ctx context.Context, content string, chunkType *types.ChunkType, limit int) ([]types.ConversationChunk, error) {
+	chunks, err := s.VectorStore.FindSimilar(ctx, content, chunkType, limit*archiveOverfetch)
+	if err != nil {
+		return nil, err
+	}
+	kept := chunks[:0]
+	for i := range chunks {
+		if !chunks[i].IsArchived() {
+			kept = append(kept, chunks[i])
+		}
+	}
+	if limit > 0 && len(kept) > limit {
+		kept = kept[:limit]
+	}
+	return kept, nil
+}
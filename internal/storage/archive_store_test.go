@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveTierVectorStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewArchiveTierVectorStore(NewKeywordStore(""))
+
+	live := newKeywordChunk(t, "acme/api", "connection pool exhausted under load", types.ChunkTypeProblem)
+	archived := newKeywordChunk(t, "acme/api", "connection pool sizing for the old cluster", types.ChunkTypeSolution)
+	archived.Metadata.ExtendedMetadata = map[string]interface{}{types.EMKeyArchivedAt: time.Now().Format(time.RFC3339)}
+	require.NoError(t, store.Store(ctx, live))
+	require.NoError(t, store.Store(ctx, archived))
+
+	repo := "acme/api"
+	query := &types.MemoryQuery{Query: "connection pool", Repository: &repo, Recency: types.RecencyAllTime, Limit: 1}
+	results, err := store.Search(ctx, query, nil)
+	require.NoError(t, err)
+	require.Len(t, results.Results, 1)
+	assert.Equal(t, live.ID, results.Results[0].Chunk.ID)
+	assert.Equal(t, 1, query.Limit, "the caller's query is left alone")
+
+	query.IncludeArchived = true
+	query.Limit = 10
+	results, err = store.Search(ctx, query, nil)
+	require.NoError(t, err)
+	assert.Len(t, results.Results, 2)
+
+	stored, err := store.GetByID(ctx, archived.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.IsArchived(), "archived chunks are still found by ID")
+}
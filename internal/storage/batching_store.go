@@ -0,0 +1,516 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/pkg/types"
+)
+
+// closeFlushTimeout bounds the final flush when the store is closed
+const closeFlushTimeout = 30 * time.Second
+
+// ErrIngestionQueueFull is returned by Store when too many chunks are waiting to be flushed
+var ErrIngestionQueueFull = errors.New("ingestion queue is full")
+
+// BatchingVectorStore wraps a VectorStore so that Store queues chunks and
+// upserts them in batches, instead of making one round trip per chunk.
+//
+// A queued chunk is appended to a spill file and synced before Store
+// returns, and only removed from it once a batch containing it has been
+// stored. Chunks left in the spill file by a crash are queued again by
+// Initialize, so delivery is at least once; upserts are keyed by chunk ID,
+// which makes a redelivery harmless.
+//
+// Queued chunks are visible to GetByID, GetByIDs and Update immediately,
+// and to searches and listings once flushed.
+type BatchingVectorStore struct {
+	VectorStore
+
+	batchSize     int
+	flushInterval time.Duration
+	maxPending    int
+	spillPath     string
+
+	mutex   sync.Mutex
+	pending map[string]*types.ConversationChunk
+	order   []string // Pending IDs, oldest first
+	spill   *os.File
+	stats   IngestionStats
+
+	// flushMutex serializes flushes with deletes so a batch in flight
+	// cannot bring back a chunk deleted while it was queued
+	flushMutex sync.Mutex
+
+	// paused is held by Quiesce and read-held by flushes, so none runs
+	// while the store is quiesced
+	paused sync.RWMutex
+
+	wake      chan struct{}
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// IngestionStats describes the ingestion queue
+type IngestionStats struct {
+	Pending       int        `json:"pending"`
+	Enqueued      int64      `json:"enqueued"`
+	Flushed       int64      `json:"flushed"`
+	Batches       int64      `json:"batches"`
+	FailedFlushes int64      `json:"failed_flushes"`
+	Rejected      int64      `json:"rejected_chunks"` // Dropped after the backend rejected them, e.g. failed validation
+	LastFlush     *time.Time `json:"last_flush,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+}
+
+// NewBatchingVectorStore wraps store with a write queue and starts its flush loop
+func NewBatchingVectorStore(store VectorStore, cfg *config.IngestionConfig) (*BatchingVectorStore, error) {
+	if dir := filepath.Dir(cfg.SpillPath); dir != "" {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return nil, fmt.Errorf("failed to create ingestion spill directory: %w", err)
+		}
+	}
+	spill, err := os.OpenFile(cfg.SpillPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ingestion spill file: %w", err)
+	}
+
+	bs := &BatchingVectorStore{
+		VectorStore:   store,
+		batchSize:     cfg.BatchSize,
+		flushInterval: time.Duration(cfg.FlushIntervalMs) * time.Millisecond,
+		maxPending:    cfg.MaxPending,
+		spillPath:     cfg.SpillPath,
+		pending:       make(map[string]*types.ConversationChunk),
+		spill:         spill,
+		wake:          make(chan struct{}, 1),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	go bs.run()
+	return bs, nil
+}
+
+// Initialize initializes the wrapped store, then queues any chunks a previous run left in the spill file
+func (bs *BatchingVectorStore) Initialize(ctx context.Context) error {
+	if err := bs.VectorStore.Initialize(ctx); err != nil {
+		return err
+	}
+
+	recovered, err := bs.recoverSpill()
+	if err != nil {
+		return err
+	}
+	if recovered > 0 {
+		logging.Info("Recovered queued chunks from ingestion spill file", "chunks", recovered, "path", bs.spillPath)
+		bs.signal()
+	}
+	return nil
+}
+
+// Store queues a chunk for the next batch. The chunk is durable once Store returns.
+func (bs *BatchingVectorStore) Store(ctx context.Context, chunk *types.ConversationChunk) error {
+	if err := chunk.Validate(); err != nil {
+		return fmt.Errorf("invalid chunk: %w", err)
+	}
+	if len(chunk.Embeddings) == 0 {
+		return errors.New("chunk must have embeddings before storing")
+	}
+
+	queued := *chunk
+	data, err := json.Marshal(&queued)
+	if err != nil {
+		return fmt.Errorf("failed to encode chunk: %w", err)
+	}
+
+	bs.mutex.Lock()
+	if _, ok := bs.pending[queued.ID]; !ok && len(bs.order) >= bs.maxPending {
+		bs.mutex.Unlock()
+		return fmt.Errorf("%w: %d chunks waiting", ErrIngestionQueueFull, bs.maxPending)
+	}
+	if err := bs.appendSpill(data); err != nil {
+		bs.mutex.Unlock()
+		return err
+	}
+	bs.enqueue(&queued)
+	bs.stats.Enqueued++
+	full := len(bs.order) >= bs.batchSize
+	bs.mutex.Unlock()
+
+	if full {
+		bs.signal()
+	}
+	return nil
+}
+
+// StoreChunk is an alias for Store
+func (bs *BatchingVectorStore) StoreChunk(ctx context.Context, chunk *types.ConversationChunk) error {
+	return bs.Store(ctx, chunk)
+}
+
+// GetByID returns a queued chunk, or the stored one when none is queued
+func (bs *BatchingVectorStore) GetByID(ctx context.Context, id string) (*types.ConversationChunk, error) {
+	bs.mutex.Lock()
+	chunk, ok := bs.pending[id]
+	bs.mutex.Unlock()
+	if ok {
+		found := *chunk
+		return &found, nil
+	}
+	return bs.VectorStore.GetByID(ctx, id)
+}
+
+// GetByIDs returns queued chunks and looks up the rest in the wrapped store
+func (bs *BatchingVectorStore) GetByIDs(ctx context.Context, ids []string) ([]types.ConversationChunk, error) {
+	byID := make(map[string]*types.ConversationChunk, len(ids))
+	remaining := make([]string, 0, len(ids))
+
+	bs.mutex.Lock()
+	for _, id := range ids {
+		if chunk, ok := bs.pending[id]; ok {
+			found := *chunk
+			byID[id] = &found
+		} else {
+			remaining = append(remaining, id)
+		}
+	}
+	bs.mutex.Unlock()
+
+	if len(remaining) > 0 {
+		stored, err := bs.VectorStore.GetByIDs(ctx, remaining)
+		if err != nil {
+			return nil, err
+		}
+		for i := range stored {
+			byID[stored[i].ID] = &stored[i]
+		}
+	}
+	return orderChunksByID(ids, byID), nil
+}
+
+// Update replaces a queued chunk in place, or updates the stored one
+func (bs *BatchingVectorStore) Update(ctx context.Context, chunk *types.ConversationChunk) error {
+	bs.mutex.Lock()
+	_, queued := bs.pending[chunk.ID]
+	bs.mutex.Unlock()
+	if queued {
+		return bs.Store(ctx, chunk)
+	}
+	return bs.VectorStore.Update(ctx, chunk)
+}
+
+// Delete drops a queued chunk and deletes it from the wrapped store
+func (bs *BatchingVectorStore) Delete(ctx context.Context, id string) error {
+	bs.flushMutex.Lock()
+	defer bs.flushMutex.Unlock()
+
+	queued, err := bs.dequeue([]string{id})
+	if err != nil {
+		return err
+	}
+	err = bs.VectorStore.Delete(ctx, id)
+	if err != nil && queued {
+		// A chunk that was only ever queued is not in the wrapped store
+		if _, getErr := bs.VectorStore.GetByID(ctx, id); getErr != nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// BatchDelete drops queued chunks and deletes them from the wrapped store
+func (bs *BatchingVectorStore) BatchDelete(ctx context.Context, ids []string) (*BatchResult, error) {
+	bs.flushMutex.Lock()
+	defer bs.flushMutex.Unlock()
+
+	if _, err := bs.dequeue(ids); err != nil {
+		return nil, err
+	}
+	return bs.VectorStore.BatchDelete(ctx, ids)
+}
+
+// Flush stores every chunk queued when it is called, one batch at a time.
+// Chunks stay queued if a batch fails, to be retried by the next flush.
+func (bs *BatchingVectorStore) Flush(ctx context.Context) error {
+	bs.paused.RLock()
+	defer bs.paused.RUnlock()
+	bs.flushMutex.Lock()
+	defer bs.flushMutex.Unlock()
+
+	bs.mutex.Lock()
+	remaining := len(bs.order)
+	bs.mutex.Unlock()
+
+	for remaining > 0 {
+		batch := bs.nextBatch()
+		if len(batch) == 0 {
+			return nil
+		}
+		remaining -= len(batch)
+
+		result, err := bs.VectorStore.BatchStore(ctx, batch)
+		if err != nil {
+			bs.mutex.Lock()
+			bs.stats.FailedFlushes++
+			bs.stats.LastError = err.Error()
+			bs.mutex.Unlock()
+			return fmt.Errorf("failed to flush %d queued chunks: %w", len(batch), err)
+		}
+
+		// Per-chunk failures without an error are permanent (e.g. validation),
+		// so retrying the chunk would fail forever
+		if result != nil && result.Failed > 0 {
+			logging.Warn("Vector store rejected queued chunks", "failed", result.Failed, "errors", result.Errors)
+		}
+		if err := bs.acknowledge(batch, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Quiesce flushes the queue, then runs fn with flushing paused, so no queued
+// write changes the stored state until fn returns. It is only a barrier: fn
+// works through the caller's own store, with every layer above this one, and
+// must not flush. Stores made meanwhile are still accepted and queued, and
+// deletes still apply.
+func (bs *BatchingVectorStore) Quiesce(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := bs.Flush(ctx); err != nil {
+		return err
+	}
+
+	bs.paused.Lock()
+	defer bs.paused.Unlock()
+	return fn(ctx)
+}
+
+// Stats returns a snapshot of the queue's counters
+func (bs *BatchingVectorStore) Stats() IngestionStats {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+
+	stats := bs.stats
+	stats.Pending = len(bs.order)
+	return stats
+}
+
+// Close stops the flush loop, flushes what is queued and closes the wrapped
+// store. Chunks that cannot be flushed stay in the spill file for the next start.
+func (bs *BatchingVectorStore) Close() error {
+	var flushErr error
+	bs.closeOnce.Do(func() {
+		close(bs.stop)
+		<-bs.done
+
+		ctx, cancel := context.WithTimeout(context.Background(), closeFlushTimeout)
+		defer cancel()
+		flushErr = bs.Flush(ctx)
+
+		bs.mutex.Lock()
+		if err := bs.spill.Close(); err != nil && flushErr == nil {
+			flushErr = fmt.Errorf("failed to close ingestion spill file: %w", err)
+		}
+		bs.mutex.Unlock()
+	})
+
+	if err := bs.VectorStore.Close(); err != nil {
+		return err
+	}
+	return flushErr
+}
+
+// run flushes when a batch fills up and every flush interval
+func (bs *BatchingVectorStore) run() {
+	defer close(bs.done)
+
+	ticker := time.NewTicker(bs.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-bs.stop:
+			return
+		case <-ticker.C:
+		case <-bs.wake:
+		}
+		if err := bs.Flush(context.Background()); err != nil {
+			logging.Warn("Ingestion flush failed; chunks stay queued", "error", err)
+		}
+	}
+}
+
+// signal wakes the flush loop without blocking
+func (bs *BatchingVectorStore) signal() {
+	select {
+	case bs.wake <- struct{}{}:
+	default:
+	}
+}
+
+// enqueue adds or replaces a pending chunk; a replaced chunk keeps its place. Callers hold mutex.
+func (bs *BatchingVectorStore) enqueue(chunk *types.ConversationChunk) {
+	if _, ok := bs.pending[chunk.ID]; !ok {
+		bs.order = append(bs.order, chunk.ID)
+	}
+	bs.pending[chunk.ID] = chunk
+}
+
+// nextBatch returns up to batchSize of the oldest pending chunks
+func (bs *BatchingVectorStore) nextBatch() []*types.ConversationChunk {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+
+	n := min(bs.batchSize, len(bs.order))
+	batch := make([]*types.ConversationChunk, 0, n)
+	for _, id := range bs.order[:n] {
+		batch = append(batch, bs.pending[id])
+	}
+	return batch
+}
+
+// acknowledge removes stored chunks from the queue. A chunk replaced while
+// its batch was in flight stays queued so the newer version is stored too.
+func (bs *BatchingVectorStore) acknowledge(batch []*types.ConversationChunk, result *BatchResult) error {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+
+	for _, chunk := range batch {
+		if bs.pending[chunk.ID] == chunk {
+			delete(bs.pending, chunk.ID)
+		}
+	}
+	bs.compactOrder()
+
+	now := time.Now()
+	bs.stats.Batches++
+	bs.stats.Flushed += int64(len(batch))
+	bs.stats.LastFlush = &now
+	if result != nil {
+		bs.stats.Rejected += int64(result.Failed)
+	}
+	return bs.rewriteSpill()
+}
+
+// dequeue drops chunks from the queue, reporting whether any were queued
+func (bs *BatchingVectorStore) dequeue(ids []string) (bool, error) {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+
+	removed := false
+	for _, id := range ids {
+		if _, ok := bs.pending[id]; ok {
+			delete(bs.pending, id)
+			removed = true
+		}
+	}
+	if !removed {
+		return false, nil
+	}
+	bs.compactOrder()
+	return true, bs.rewriteSpill()
+}
+
+// compactOrder drops IDs that are no longer pending. Callers hold mutex.
+func (bs *BatchingVectorStore) compactOrder() {
+	order := bs.order[:0]
+	for _, id := range bs.order {
+		if _, ok := bs.pending[id]; ok {
+			order = append(order, id)
+		}
+	}
+	bs.order = order
+}
+
+// appendSpill durably records a queued chunk. Callers hold mutex.
+func (bs *BatchingVectorStore) appendSpill(data []byte) error {
+	if _, err := bs.spill.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write ingestion spill file: %w", err)
+	}
+	if err := bs.spill.Sync(); err != nil {
+		return fmt.Errorf("failed to sync ingestion spill file: %w", err)
+	}
+	return nil
+}
+
+// rewriteSpill replaces the spill file with the pending chunks. Callers hold mutex.
+func (bs *BatchingVectorStore) rewriteSpill() error {
+	tmpPath := bs.spillPath + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to rewrite ingestion spill file: %w", err)
+	}
+
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+	for _, id := range bs.order {
+		if err := encoder.Encode(bs.pending[id]); err != nil {
+			_ = tmp.Close()
+			return fmt.Errorf("failed to rewrite ingestion spill file: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to rewrite ingestion spill file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to sync ingestion spill file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to rewrite ingestion spill file: %w", err)
+	}
+	if err := os.Rename(tmpPath, bs.spillPath); err != nil {
+		return fmt.Errorf("failed to replace ingestion spill file: %w", err)
+	}
+
+	spill, err := os.OpenFile(bs.spillPath, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to reopen ingestion spill file: %w", err)
+	}
+	_ = bs.spill.Close()
+	bs.spill = spill
+	return nil
+}
+
+// recoverSpill queues the chunks recorded in the spill file. Later lines
+// for the same chunk replace earlier ones.
+func (bs *BatchingVectorStore) recoverSpill() (int, error) {
+	file, err := os.Open(bs.spillPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read ingestion spill file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+
+	before := len(bs.order)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var chunk types.ConversationChunk
+		if err := json.Unmarshal(scanner.Bytes(), &chunk); err != nil {
+			// A torn final line from a crash mid-write was never acknowledged
+			logging.Warn("Skipping unreadable ingestion spill entry", "error", err)
+			continue
+		}
+		bs.enqueue(&chunk)
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read ingestion spill file: %w", err)
+	}
+	return len(bs.order) - before, nil
+}
@@ -0,0 +1,241 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchRecordingStore records batches and can be told to fail them
+type batchRecordingStore struct {
+	VectorStore
+	mutex   sync.Mutex
+	batches []int
+	fail    bool
+}
+
+func newBatchRecordingStore() *batchRecordingStore {
+	return &batchRecordingStore{VectorStore: NewSimpleMockVectorStore()}
+}
+
+func (s *batchRecordingStore) BatchStore(ctx context.Context, chunks []*types.ConversationChunk) (*BatchResult, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.fail {
+		return nil, errors.New("service unavailable")
+	}
+	s.batches = append(s.batches, len(chunks))
+	return s.VectorStore.BatchStore(ctx, chunks)
+}
+
+func (s *batchRecordingStore) GetByID(ctx context.Context, id string) (*types.ConversationChunk, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.VectorStore.GetByID(ctx, id)
+}
+
+func (s *batchRecordingStore) setFail(fail bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.fail = fail
+}
+
+func (s *batchRecordingStore) batchSizes() []int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]int(nil), s.batches...)
+}
+
+func newQueuedChunk(id string) *types.ConversationChunk {
+	return &types.ConversationChunk{
+		ID:         id,
+		SessionID:  "session",
+		Type:       types.ChunkTypeSolution,
+		Content:    "content " + id,
+		Timestamp:  time.Now(),
+		Embeddings: []float64{0.1, 0.2},
+		Metadata: types.ChunkMetadata{
+			Outcome:    types.OutcomeSuccess,
+			Difficulty: types.DifficultySimple,
+		},
+	}
+}
+
+func newTestIngestionConfig(t *testing.T, batchSize int) *config.IngestionConfig {
+	return &config.IngestionConfig{
+		Enabled:         true,
+		BatchSize:       batchSize,
+		FlushIntervalMs: int(time.Hour / time.Millisecond),
+		MaxPending:      100,
+		SpillPath:       filepath.Join(t.TempDir(), "queue.jsonl"),
+	}
+}
+
+func TestBatchingVectorStore_FlushesFullBatches(t *testing.T) {
+	inner := newBatchRecordingStore()
+	store, err := NewBatchingVectorStore(inner, newTestIngestionConfig(t, 3))
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, store.Store(ctx, newQueuedChunk(fmt.Sprintf("chunk-%d", i))))
+	}
+	assert.Eventually(t, func() bool { return len(inner.batchSizes()) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []int{3}, inner.batchSizes())
+
+	// A partial batch waits, but is readable by ID while queued
+	require.NoError(t, store.Store(ctx, newQueuedChunk("chunk-3")))
+	_, err = inner.GetByID(ctx, "chunk-3")
+	assert.Error(t, err)
+	chunk, err := store.GetByID(ctx, "chunk-3")
+	require.NoError(t, err)
+	assert.Equal(t, "content chunk-3", chunk.Content)
+
+	chunks, err := store.GetByIDs(ctx, []string{"chunk-3", "chunk-0", "missing"})
+	require.NoError(t, err)
+	require.Len(t, chunks, 2)
+	assert.Equal(t, "chunk-3", chunks[0].ID)
+	assert.Equal(t, "chunk-0", chunks[1].ID)
+
+	require.NoError(t, store.Flush(ctx))
+	_, err = inner.GetByID(ctx, "chunk-3")
+	assert.NoError(t, err)
+
+	stats := store.Stats()
+	assert.Equal(t, 0, stats.Pending)
+	assert.Equal(t, int64(4), stats.Enqueued)
+	assert.Equal(t, int64(4), stats.Flushed)
+	assert.Equal(t, int64(2), stats.Batches)
+}
+
+func TestBatchingVectorStore_RecoversSpillAfterFailure(t *testing.T) {
+	cfg := newTestIngestionConfig(t, 10)
+	inner := newBatchRecordingStore()
+	inner.setFail(true)
+	store, err := NewBatchingVectorStore(inner, cfg)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, store.Store(ctx, newQueuedChunk("a")))
+	require.NoError(t, store.Store(ctx, newQueuedChunk("b")))
+	assert.Error(t, store.Flush(ctx))
+
+	stats := store.Stats()
+	assert.Equal(t, 2, stats.Pending)
+	assert.Equal(t, int64(1), stats.FailedFlushes)
+	assert.Contains(t, stats.LastError, "service unavailable")
+
+	// Closing cannot flush either; the chunks stay in the spill file
+	assert.Error(t, store.Close())
+
+	recovered := newBatchRecordingStore()
+	store, err = NewBatchingVectorStore(recovered, cfg)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+	require.NoError(t, store.Initialize(ctx))
+	require.NoError(t, store.Flush(ctx))
+
+	for _, id := range []string{"a", "b"} {
+		_, err := recovered.GetByID(ctx, id)
+		assert.NoError(t, err, id)
+	}
+	assert.Equal(t, 0, store.Stats().Pending)
+}
+
+func TestBatchingVectorStore_DeleteDropsQueuedChunk(t *testing.T) {
+	cfg := newTestIngestionConfig(t, 10)
+	inner := newBatchRecordingStore()
+	inner.setFail(true)
+	store, err := NewBatchingVectorStore(inner, cfg)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, store.Store(ctx, newQueuedChunk("keep")))
+	require.NoError(t, store.Store(ctx, newQueuedChunk("drop")))
+	require.NoError(t, store.Delete(ctx, "drop"))
+	_, err = store.GetByID(ctx, "drop")
+	assert.Error(t, err)
+	_ = store.Close()
+
+	store, err = NewBatchingVectorStore(newBatchRecordingStore(), cfg)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+	require.NoError(t, store.Initialize(ctx))
+	assert.Equal(t, 1, store.Stats().Pending)
+	_, err = store.GetByID(ctx, "keep")
+	assert.NoError(t, err)
+}
+
+func TestBatchingVectorStore_UpdateReplacesQueuedChunk(t *testing.T) {
+	inner := newBatchRecordingStore()
+	store, err := NewBatchingVectorStore(inner, newTestIngestionConfig(t, 10))
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+	ctx := context.Background()
+
+	chunk := newQueuedChunk("a")
+	require.NoError(t, store.Store(ctx, chunk))
+	chunk.Content = "edited"
+	require.NoError(t, store.Update(ctx, chunk))
+	assert.Equal(t, 1, store.Stats().Pending)
+
+	require.NoError(t, store.Flush(ctx))
+	stored, err := inner.GetByID(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "edited", stored.Content)
+}
+
+func TestBatchingVectorStore_RejectsWhenFull(t *testing.T) {
+	cfg := newTestIngestionConfig(t, 2)
+	cfg.MaxPending = 2
+	inner := newBatchRecordingStore()
+	inner.setFail(true)
+	store, err := NewBatchingVectorStore(inner, cfg)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+	ctx := context.Background()
+
+	require.NoError(t, store.Store(ctx, newQueuedChunk("a")))
+	require.NoError(t, store.Store(ctx, newQueuedChunk("b")))
+	assert.ErrorIs(t, store.Store(ctx, newQueuedChunk("c")), ErrIngestionQueueFull)
+
+	// Replacing a queued chunk does not grow the queue
+	assert.NoError(t, store.Store(ctx, newQueuedChunk("a")))
+
+	invalid := newQueuedChunk("d")
+	invalid.Embeddings = nil
+	assert.Error(t, store.Store(ctx, invalid))
+}
+
+func TestBatchingVectorStore_QuiesceFlushesFirst(t *testing.T) {
+	inner := newBatchRecordingStore()
+	store, err := NewBatchingVectorStore(inner, newTestIngestionConfig(t, 10))
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+	ctx := context.Background()
+
+	require.NoError(t, store.Store(ctx, newQueuedChunk("a")))
+	err = store.Quiesce(ctx, func(ctx context.Context) error {
+		_, err := inner.GetByID(ctx, "a")
+		assert.NoError(t, err)
+
+		// Writes made while quiesced are queued, not applied
+		require.NoError(t, store.Store(ctx, newQueuedChunk("b")))
+		_, err = inner.GetByID(ctx, "b")
+		assert.Error(t, err)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.Stats().Pending)
+}
@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"context"
+	"sync"
+
+	"lerian-mcp-memory/pkg/types"
+)
+
+// Chunk change actions reported by ChangeFeed
+const (
+	ChunkStored  = "stored"
+	ChunkUpdated = "updated"
+	ChunkDeleted = "deleted"
+)
+
+// ChunkChange describes a chunk written through a ChangeFeed
+type ChunkChange struct {
+	ChunkID    string
+	Repository string
+	SessionID  string
+	Action     string
+}
+
+// ChangeFeed wraps a VectorStore and tells listeners about every chunk
+// stored, updated or deleted through it, so data derived from chunks (live
+// resources, caches) can follow changes without polling. Listeners are
+// called synchronously after the write succeeds and must not block.
+type ChangeFeed struct {
+	VectorStore
+
+	mutex     sync.RWMutex
+	nextID    int
+	listeners map[int]func(ChunkChange)
+}
+
+// NewChangeFeed reports changes made through store
+func NewChangeFeed(store VectorStore) *ChangeFeed {
+	return &ChangeFeed{
+		VectorStore: store,
+		listeners:   make(map[int]func(ChunkChange)),
+	}
+}
+
+// Listen calls listener for each change until the returned function is called
+func (f *ChangeFeed) Listen(listener func(ChunkChange)) (cancel func()) {
+	f.mutex.Lock()
+	id := f.nextID
+	f.nextID++
+	f.listeners[id] = listener
+	f.mutex.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			f.mutex.Lock()
+			delete(f.listeners, id)
+			f.mutex.Unlock()
+		})
+	}
+}
+
+// listening reports whether any listener is registered
+func (f *ChangeFeed) listening() bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return len(f.listeners) > 0
+}
+
+// publish reports a change to the listeners
+func (f *ChangeFeed) publish(chunk *types.ConversationChunk, action string) {
+	change := ChunkChange{ChunkID: chunk.ID, Repository: chunk.Metadata.Repository, SessionID: chunk.SessionID, Action: action}
+
+	f.mutex.RLock()
+	listeners := make([]func(ChunkChange), 0, len(f.listeners))
+	for _, listener := range f.listeners {
+		listeners = append(listeners, listener)
+	}
+	f.mutex.RUnlock()
+
+	for _, listener := range listeners {
+		listener(change)
+	}
+}
+
+// Store stores a chunk and reports it
+func (f *ChangeFeed) Store(ctx context.Context, chunk *types.ConversationChunk) error {
+	if err := f.VectorStore.Store(ctx, chunk); err != nil {
+		return err
+	}
+	f.publish(chunk, ChunkStored)
+	return nil
+}
+
+// StoreChunk stores a chunk and reports it
+func (f *ChangeFeed) StoreChunk(ctx context.Context, chunk *types.ConversationChunk) error {
+	if err := f.VectorStore.StoreChunk(ctx, chunk); err != nil {
+		return err
+	}
+	f.publish(chunk, ChunkStored)
+	return nil
+}
+
+// Update updates a chunk and reports it
+func (f *ChangeFeed) Update(ctx context.Context, chunk *types.ConversationChunk) error {
+	if err := f.VectorStore.Update(ctx, chunk); err != nil {
+		return err
+	}
+	f.publish(chunk, ChunkUpdated)
+	return nil
+}
+
+// BatchStore stores chunks and reports each one stored
+func (f *ChangeFeed) BatchStore(ctx context.Context, chunks []*types.ConversationChunk) (*BatchResult, error) {
+	result, err := f.VectorStore.BatchStore(ctx, chunks)
+	if result == nil || !f.listening() {
+		return result, err
+	}
+
+	if result.Failed == 0 && err == nil {
+		for _, chunk := range chunks {
+			f.publish(chunk, ChunkStored)
+		}
+		return result, err
+	}
+	processed := make(map[string]bool, len(result.ProcessedIDs))
+	for _, id := range result.ProcessedIDs {
+		processed[id] = true
+	}
+	for _, chunk := range chunks {
+		if processed[chunk.ID] {
+			f.publish(chunk, ChunkStored)
+		}
+	}
+	return result, err
+}
+
+// Delete deletes a chunk and reports it. The chunk is read first, while
+// anyone is listening, so the change can name its repository.
+func (f *ChangeFeed) Delete(ctx context.Context, id string) error {
+	deleted := &types.ConversationChunk{ID: id}
+	if f.listening() {
+		if chunk, err := f.VectorStore.GetByID(ctx, id); err == nil {
+			deleted = chunk
+		}
+	}
+	if err := f.VectorStore.Delete(ctx, id); err != nil {
+		return err
+	}
+	f.publish(deleted, ChunkDeleted)
+	return nil
+}
+
+// BatchDelete deletes chunks and reports each one deleted
+func (f *ChangeFeed) BatchDelete(ctx context.Context, ids []string) (*BatchResult, error) {
+	if !f.listening() {
+		return f.VectorStore.BatchDelete(ctx, ids)
+	}
+
+	chunks, _ := f.VectorStore.GetByIDs(ctx, ids)
+	known := make(map[string]*types.ConversationChunk, len(chunks))
+	for i := range chunks {
+		known[chunks[i].ID] = &chunks[i]
+	}
+
+	result, err := f.VectorStore.BatchDelete(ctx, ids)
+	if result == nil {
+		return result, err
+	}
+	deletedIDs := ids
+	if result.Failed > 0 || err != nil {
+		deletedIDs = result.ProcessedIDs
+	}
+	for _, id := range deletedIDs {
+		chunk, ok := known[id]
+		if !ok {
+			chunk = &types.ConversationChunk{ID: id}
+		}
+		f.publish(chunk, ChunkDeleted)
+	}
+	return result, err
+}
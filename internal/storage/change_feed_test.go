@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeFeed_ReportsWrites(t *testing.T) {
+	ctx := context.Background()
+	feed := NewChangeFeed(NewKeywordStore(""))
+
+	var changes []ChunkChange
+	cancel := feed.Listen(func(change ChunkChange) { changes = append(changes, change) })
+
+	chunk := newQueuedChunk("a")
+	chunk.Metadata.Repository = "github.com/acme/api"
+	require.NoError(t, feed.Store(ctx, chunk))
+	require.NoError(t, feed.Update(ctx, chunk))
+	_, err := feed.BatchStore(ctx, []*types.ConversationChunk{newQueuedChunk("b"), newQueuedChunk("c")})
+	require.NoError(t, err)
+	require.NoError(t, feed.Delete(ctx, "a"))
+
+	require.Len(t, changes, 5)
+	assert.Equal(t, ChunkChange{ChunkID: "a", Repository: "github.com/acme/api", SessionID: "session", Action: ChunkStored}, changes[0])
+	assert.Equal(t, ChunkUpdated, changes[1].Action)
+	assert.Equal(t, []string{"b", "c"}, []string{changes[2].ChunkID, changes[3].ChunkID})
+	assert.Equal(t, ChunkChange{ChunkID: "a", Repository: "github.com/acme/api", SessionID: "session", Action: ChunkDeleted}, changes[4], "deletes name the repository of the deleted chunk")
+
+	// Failed writes and cancelled listeners hear nothing
+	assert.Error(t, feed.Delete(ctx, "missing"))
+	cancel()
+	require.NoError(t, feed.Store(ctx, newQueuedChunk("d")))
+	assert.Len(t, changes, 5)
+}
@@ -85,6 +85,19 @@ func (s *CircuitBreakerVectorStore) GetByID(ctx context.Context, id string) (*ty
 	return result, err
 }
 
+// GetByIDs retrieves several chunks by ID
+func (s *CircuitBreakerVectorStore) GetByIDs(ctx context.Context, ids []string) ([]types.ConversationChunk, error) {
+	var result []types.ConversationChunk
+
+	err := s.cb.Execute(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = s.store.GetByIDs(ctx, ids)
+		return err
+	})
+
+	return result, err
+}
+
 // ListByRepository lists chunks by repository
 func (s *CircuitBreakerVectorStore) ListByRepository(ctx context.Context, repository string, limit, offset int) ([]types.ConversationChunk, error) {
 	var result []types.ConversationChunk
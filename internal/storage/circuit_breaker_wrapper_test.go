@@ -40,6 +40,11 @@ func (m *MockVectorStore) GetByID(ctx context.Context, id string) (*types.Conver
 	return args.Get(0).(*types.ConversationChunk), args.Error(1)
 }
 
+func (m *MockVectorStore) GetByIDs(ctx context.Context, ids []string) ([]types.ConversationChunk, error) {
+	args := m.Called(ctx, ids)
+	return args.Get(0).([]types.ConversationChunk), args.Error(1)
+}
+
 func (m *MockVectorStore) ListByRepository(ctx context.Context, repository string, limit, offset int) ([]types.ConversationChunk, error) {
 	args := m.Called(ctx, repository, limit, offset)
 	return args.Get(0).([]types.ConversationChunk), args.Error(1)
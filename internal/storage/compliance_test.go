@@ -0,0 +1,79 @@
+package storage_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/storage/storagetest"
+
+	"github.com/google/uuid"
+)
+
+// complianceDimension is the embedding size of the compliance runs, that of
+// OpenAI's embeddings
+const complianceDimension = 1536
+
+func TestLocalVectorStoreCompliance(t *testing.T) {
+	suite := &storagetest.Suite{
+		NewStore: func(t *testing.T) storage.VectorStore {
+			return storage.NewLocalVectorStore(t.TempDir())
+		},
+		Dimension: complianceDimension,
+	}
+	suite.Run(t)
+}
+
+func TestKeywordStoreCompliance(t *testing.T) {
+	suite := &storagetest.Suite{
+		NewStore: func(t *testing.T) storage.VectorStore {
+			return storage.NewKeywordStore(filepath.Join(t.TempDir(), "keyword.json"))
+		},
+	}
+	suite.Run(t)
+}
+
+// TestQdrantStoreCompliance runs the VectorStore compliance suite against a live Qdrant.
+// Set MCP_MEMORY_TEST_QDRANT_HOST to enable it.
+func TestQdrantStoreCompliance(t *testing.T) {
+	host := os.Getenv("MCP_MEMORY_TEST_QDRANT_HOST")
+	if host == "" {
+		t.Skip("MCP_MEMORY_TEST_QDRANT_HOST not set; skipping Qdrant compliance suite")
+	}
+
+	suite := &storagetest.Suite{
+		NewStore: func(t *testing.T) storage.VectorStore {
+			collection := "compliance_" + uuid.New().String()
+			store := storage.NewQdrantStore(&config.QdrantConfig{Host: host, Port: 6334, Collection: collection})
+			t.Cleanup(func() { _ = store.DeleteCollection(context.Background(), collection) })
+			return store
+		},
+		Dimension: complianceDimension,
+	}
+	suite.Run(t)
+}
+
+// TestPgVectorStoreCompliance runs the VectorStore compliance suite against a
+// live PostgreSQL with pgvector. Set MCP_MEMORY_TEST_POSTGRES_DSN to enable it;
+// the integration tests run it against a container of their own.
+func TestPgVectorStoreCompliance(t *testing.T) {
+	dsn := os.Getenv("MCP_MEMORY_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("MCP_MEMORY_TEST_POSTGRES_DSN not set; skipping pgvector compliance suite")
+	}
+
+	suite := &storagetest.Suite{
+		NewStore: func(t *testing.T) storage.VectorStore {
+			table := "compliance_" + strings.ReplaceAll(uuid.New().String()[:8], "-", "")
+			store := storage.NewPgVectorStore(&config.PgVectorConfig{DSN: dsn, Table: table, Dimension: complianceDimension})
+			t.Cleanup(func() { _ = store.DeleteCollection(context.Background(), table) })
+			return store
+		},
+		Dimension: complianceDimension,
+	}
+	suite.Run(t)
+}
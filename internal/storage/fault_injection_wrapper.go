@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"context"
+	"lerian-mcp-memory/internal/chaos"
+	"lerian-mcp-memory/pkg/types"
+)
+
+// FaultInjectingVectorStore wraps a VectorStore with the chaos injector, so
+// calls can be delayed or failed on demand. It sits beneath the retry and
+// circuit breaker wrappers, which then see injected faults like real outages.
+type FaultInjectingVectorStore struct {
+	store    VectorStore
+	injector *chaos.Injector
+}
+
+// NewFaultInjectingVectorStore creates a fault injecting store
+func NewFaultInjectingVectorStore(store VectorStore, injector *chaos.Injector) *FaultInjectingVectorStore {
+	return &FaultInjectingVectorStore{
+		store:    store,
+		injector: injector,
+	}
+}
+
+// inject applies the vector store fault to an operation
+func (s *FaultInjectingVectorStore) inject(ctx context.Context, operation string) error {
+	return s.injector.Inject(ctx, chaos.TargetVectorStore, operation)
+}
+
+// Initialize initializes the store
+func (s *FaultInjectingVectorStore) Initialize(ctx context.Context) error {
+	if err := s.inject(ctx, "Initialize"); err != nil {
+		return err
+	}
+	return s.store.Initialize(ctx)
+}
+
+// Store stores a chunk
+func (s *FaultInjectingVectorStore) Store(ctx context.Context, chunk *types.ConversationChunk) error {
+	if err := s.inject(ctx, "Store"); err != nil {
+		return err
+	}
+	return s.store.Store(ctx, chunk)
+}
+
+// Search performs a search
+func (s *FaultInjectingVectorStore) Search(ctx context.Context, query *types.MemoryQuery, embeddings []float64) (*types.SearchResults, error) {
+	if err := s.inject(ctx, "Search"); err != nil {
+		return nil, err
+	}
+	return s.store.Search(ctx, query, embeddings)
+}
+
+// GetByID gets a chunk by ID
+func (s *FaultInjectingVectorStore) GetByID(ctx context.Context, id string) (*types.ConversationChunk, error) {
+	if err := s.inject(ctx, "GetByID"); err != nil {
+		return nil, err
+	}
+	return s.store.GetByID(ctx, id)
+}
+
+// GetByIDs retrieves several chunks by ID
+func (s *FaultInjectingVectorStore) GetByIDs(ctx context.Context, ids []string) ([]types.ConversationChunk, error) {
+	if err := s.inject(ctx, "GetByIDs"); err != nil {
+		return nil, err
+	}
+	return s.store.GetByIDs(ctx, ids)
+}
+
+// ListByRepository lists chunks by repository
+func (s *FaultInjectingVectorStore) ListByRepository(ctx context.Context, repository string, limit, offset int) ([]types.ConversationChunk, error) {
+	if err := s.inject(ctx, "ListByRepository"); err != nil {
+		return nil, err
+	}
+	return s.store.ListByRepository(ctx, repository, limit, offset)
+}
+
+// ListBySession lists chunks by session ID
+func (s *FaultInjectingVectorStore) ListBySession(ctx context.Context, sessionID string) ([]types.ConversationChunk, error) {
+	if err := s.inject(ctx, "ListBySession"); err != nil {
+		return nil, err
+	}
+	return s.store.ListBySession(ctx, sessionID)
+}
+
+// Delete deletes a chunk
+func (s *FaultInjectingVectorStore) Delete(ctx context.Context, id string) error {
+	if err := s.inject(ctx, "Delete"); err != nil {
+		return err
+	}
+	return s.store.Delete(ctx, id)
+}
+
+// Update updates a chunk
+func (s *FaultInjectingVectorStore) Update(ctx context.Context, chunk *types.ConversationChunk) error {
+	if err := s.inject(ctx, "Update"); err != nil {
+		return err
+	}
+	return s.store.Update(ctx, chunk)
+}
+
+// HealthCheck checks the store
+func (s *FaultInjectingVectorStore) HealthCheck(ctx context.Context) error {
+	if err := s.inject(ctx, "HealthCheck"); err != nil {
+		return err
+	}
+	return s.store.HealthCheck(ctx)
+}
+
+// GetStats gets store statistics
+func (s *FaultInjectingVectorStore) GetStats(ctx context.Context) (*StoreStats, error) {
+	if err := s.inject(ctx, "GetStats"); err != nil {
+		return nil, err
+	}
+	return s.store.GetStats(ctx)
+}
+
+// Cleanup removes old chunks
+func (s *FaultInjectingVectorStore) Cleanup(ctx context.Context, retentionDays int) (int, error) {
+	if err := s.inject(ctx, "Cleanup"); err != nil {
+		return 0, err
+	}
+	return s.store.Cleanup(ctx, retentionDays)
+}
+
+// Close closes the store; it is never failed so shutdown stays clean
+func (s *FaultInjectingVectorStore) Close() error {
+	return s.store.Close()
+}
+
+// GetAllChunks retrieves all chunks
+func (s *FaultInjectingVectorStore) GetAllChunks(ctx context.Context) ([]types.ConversationChunk, error) {
+	if err := s.inject(ctx, "GetAllChunks"); err != nil {
+		return nil, err
+	}
+	return s.store.GetAllChunks(ctx)
+}
+
+// DeleteCollection deletes a collection
+func (s *FaultInjectingVectorStore) DeleteCollection(ctx context.Context, collection string) error {
+	if err := s.inject(ctx, "DeleteCollection"); err != nil {
+		return err
+	}
+	return s.store.DeleteCollection(ctx, collection)
+}
+
+// ListCollections lists collections
+func (s *FaultInjectingVectorStore) ListCollections(ctx context.Context) ([]string, error) {
+	if err := s.inject(ctx, "ListCollections"); err != nil {
+		return nil, err
+	}
+	return s.store.ListCollections(ctx)
+}
+
+// FindSimilar finds similar chunks
+func (s *FaultInjectingVectorStore) FindSimilar(ctx context.Context, content string, chunkType *types.ChunkType, limit int) ([]types.ConversationChunk, error) {
+	if err := s.inject(ctx, "FindSimilar"); err != nil {
+		return nil, err
+	}
+	return s.store.FindSimilar(ctx, content, chunkType, limit)
+}
+
+// StoreChunk stores a chunk
+func (s *FaultInjectingVectorStore) StoreChunk(ctx context.Context, chunk *types.ConversationChunk) error {
+	if err := s.inject(ctx, "StoreChunk"); err != nil {
+		return err
+	}
+	return s.store.StoreChunk(ctx, chunk)
+}
+
+// BatchStore stores several chunks
+func (s *FaultInjectingVectorStore) BatchStore(ctx context.Context, chunks []*types.ConversationChunk) (*BatchResult, error) {
+	if err := s.inject(ctx, "BatchStore"); err != nil {
+		return nil, err
+	}
+	return s.store.BatchStore(ctx, chunks)
+}
+
+// BatchDelete deletes several chunks
+func (s *FaultInjectingVectorStore) BatchDelete(ctx context.Context, ids []string) (*BatchResult, error) {
+	if err := s.inject(ctx, "BatchDelete"); err != nil {
+		return nil, err
+	}
+	return s.store.BatchDelete(ctx, ids)
+}
+
+// StoreRelationship stores a relationship
+func (s *FaultInjectingVectorStore) StoreRelationship(ctx context.Context, sourceID, targetID string, relationType types.RelationType, confidence float64, source types.ConfidenceSource) (*types.MemoryRelationship, error) {
+	if err := s.inject(ctx, "StoreRelationship"); err != nil {
+		return nil, err
+	}
+	return s.store.StoreRelationship(ctx, sourceID, targetID, relationType, confidence, source)
+}
+
+// GetRelationships finds relationships
+func (s *FaultInjectingVectorStore) GetRelationships(ctx context.Context, query *types.RelationshipQuery) ([]types.RelationshipResult, error) {
+	if err := s.inject(ctx, "GetRelationships"); err != nil {
+		return nil, err
+	}
+	return s.store.GetRelationships(ctx, query)
+}
+
+// TraverseGraph traverses the relationship graph
+func (s *FaultInjectingVectorStore) TraverseGraph(ctx context.Context, startChunkID string, maxDepth int, relationTypes []types.RelationType) (*types.GraphTraversalResult, error) {
+	if err := s.inject(ctx, "TraverseGraph"); err != nil {
+		return nil, err
+	}
+	return s.store.TraverseGraph(ctx, startChunkID, maxDepth, relationTypes)
+}
+
+// UpdateRelationship updates a relationship
+func (s *FaultInjectingVectorStore) UpdateRelationship(ctx context.Context, relationshipID string, confidence float64, factors types.ConfidenceFactors) error {
+	if err := s.inject(ctx, "UpdateRelationship"); err != nil {
+		return err
+	}
+	return s.store.UpdateRelationship(ctx, relationshipID, confidence, factors)
+}
+
+// DeleteRelationship deletes a relationship
+func (s *FaultInjectingVectorStore) DeleteRelationship(ctx context.Context, relationshipID string) error {
+	if err := s.inject(ctx, "DeleteRelationship"); err != nil {
+		return err
+	}
+	return s.store.DeleteRelationship(ctx, relationshipID)
+}
+
+// GetRelationshipByID gets a relationship by ID
+func (s *FaultInjectingVectorStore) GetRelationshipByID(ctx context.Context, relationshipID string) (*types.MemoryRelationship, error) {
+	if err := s.inject(ctx, "GetRelationshipByID"); err != nil {
+		return nil, err
+	}
+	return s.store.GetRelationshipByID(ctx, relationshipID)
+}
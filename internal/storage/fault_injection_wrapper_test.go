@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"lerian-mcp-memory/internal/chaos"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFaultInjectingVectorStore(t *testing.T) {
+	ctx := context.Background()
+	injector := chaos.NewInjector()
+	store := NewFaultInjectingVectorStore(NewSimpleMockVectorStore(), injector)
+
+	require.NoError(t, store.HealthCheck(ctx))
+
+	require.NoError(t, injector.SetFault(chaos.TargetVectorStore, chaos.Fault{ErrorRate: 1}))
+	_, err := store.ListByRepository(ctx, "github.com/acme/api", 10, 0)
+	assert.True(t, errors.Is(err, chaos.ErrInjectedFault))
+	assert.NoError(t, store.Close())
+}
+
+func TestFaultInjectingVectorStoreIsRetried(t *testing.T) {
+	ctx := context.Background()
+	injector := chaos.NewInjector()
+	require.NoError(t, injector.SetFault(chaos.TargetVectorStore, chaos.Fault{ErrorRate: 1}))
+
+	config := defaultRetryConfig()
+	config.InitialDelay = time.Millisecond
+	config.MaxDelay = time.Millisecond
+	store := NewRetryableVectorStore(NewFaultInjectingVectorStore(NewSimpleMockVectorStore(), injector), config)
+
+	_, err := store.GetByID(ctx, "missing")
+	require.Error(t, err)
+	assert.Greater(t, injector.Status().Targets[1].Stats.Calls, int64(1), "injected faults should be retried as transient")
+}
@@ -0,0 +1,496 @@
+// Package hnsw is an in-process approximate nearest neighbor index over
+// cosine similarity, using hierarchical navigable small world graphs. It
+// needs no external service and is saved to and loaded from a single file,
+// so embedded stores can search vectors offline.
+package hnsw
+
+import (
+	"container/heap"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Default graph parameters
+const (
+	DefaultM              = 16
+	DefaultEfConstruction = 200
+	DefaultEfSearch       = 64
+)
+
+// formatVersion identifies the layout Save writes
+const formatVersion = 1
+
+// Result is a vector found by Search
+type Result struct {
+	ID         string
+	Similarity float32 // Cosine similarity to the query, from -1 to 1
+}
+
+// node is a vector in the graph with its neighbors on each of its levels.
+// Removed vectors stay in the graph as tombstones, so searches can still
+// pass through them, until the index is compacted.
+type node struct {
+	ID        string
+	Vector    []float32 // Normalized to unit length
+	Neighbors [][]int32
+	Deleted   bool
+}
+
+// Index is an HNSW graph of vectors keyed by ID. It is safe for concurrent use.
+type Index struct {
+	mu             sync.RWMutex
+	dimension      int
+	m              int
+	efConstruction int
+	levelFactor    float64
+	nodes          []*node
+	ids            map[string]int32
+	entry          int32
+	maxLevel       int
+	deleted        int
+	rng            *rand.Rand
+}
+
+// New creates an empty index. The dimension is fixed by the first vector
+// added; m and efConstruction fall back to the defaults when not positive.
+func New(m, efConstruction int) *Index {
+	if m <= 1 {
+		m = DefaultM
+	}
+	if efConstruction <= 0 {
+		efConstruction = DefaultEfConstruction
+	}
+	return &Index{
+		m:              m,
+		efConstruction: efConstruction,
+		levelFactor:    1 / math.Log(float64(m)),
+		ids:            make(map[string]int32),
+		entry:          -1,
+		rng:            rand.New(rand.NewPCG(uint64(m), uint64(efConstruction))), //nolint:gosec // Level sampling is not security sensitive
+	}
+}
+
+// Len returns the number of vectors in the index
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.ids)
+}
+
+// Dimension returns the size of the index's vectors, 0 while it is empty
+func (idx *Index) Dimension() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.dimension
+}
+
+// Tombstones returns the number of removed vectors still in the graph
+func (idx *Index) Tombstones() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.deleted
+}
+
+// Has reports whether a vector with that ID is in the index
+func (idx *Index) Has(id string) bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	_, ok := idx.ids[id]
+	return ok
+}
+
+// IDs returns the IDs of the vectors in the index
+func (idx *Index) IDs() []string {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	ids := make([]string, 0, len(idx.ids))
+	for id := range idx.ids {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Add inserts a vector, replacing the one stored under the same ID
+func (idx *Index) Add(id string, vector []float64) error {
+	normalized, err := normalize(vector)
+	if err != nil {
+		return fmt.Errorf("vector %s: %w", id, err)
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.dimension == 0 {
+		idx.dimension = len(normalized)
+	}
+	if len(normalized) != idx.dimension {
+		return fmt.Errorf("vector %s has %d dimensions, the index stores %d", id, len(normalized), idx.dimension)
+	}
+	idx.removeLocked(id)
+	idx.insertLocked(id, normalized)
+	return nil
+}
+
+// Remove deletes the vector stored under id, if any
+func (idx *Index) Remove(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.removeLocked(id)
+}
+
+// removeLocked turns a vector into a tombstone; callers hold the write lock
+func (idx *Index) removeLocked(id string) {
+	position, ok := idx.ids[id]
+	if !ok {
+		return
+	}
+	idx.nodes[position].Deleted = true
+	delete(idx.ids, id)
+	idx.deleted++
+}
+
+// insertLocked adds a normalized vector to the graph; callers hold the write lock
+func (idx *Index) insertLocked(id string, vector []float32) {
+	level := int(math.Floor(-math.Log(1-idx.rng.Float64()) * idx.levelFactor))
+	position := int32(len(idx.nodes))
+	n := &node{ID: id, Vector: vector, Neighbors: make([][]int32, level+1)}
+	idx.nodes = append(idx.nodes, n)
+	idx.ids[id] = position
+
+	if idx.entry < 0 {
+		idx.entry, idx.maxLevel = position, level
+		return
+	}
+
+	entry := idx.entry
+	for l := idx.maxLevel; l > level; l-- {
+		entry = idx.searchLayer(vector, []int32{entry}, 1, l)[0].node
+	}
+
+	entries := []int32{entry}
+	for l := min(level, idx.maxLevel); l >= 0; l-- {
+		found := idx.searchLayer(vector, entries, idx.efConstruction, l)
+		neighbors := make([]int32, 0, idx.m)
+		for i := 0; i < len(found) && len(neighbors) < idx.m; i++ {
+			neighbors = append(neighbors, found[i].node)
+		}
+		n.Neighbors[l] = neighbors
+
+		for _, neighbor := range neighbors {
+			other := idx.nodes[neighbor]
+			other.Neighbors[l] = append(other.Neighbors[l], position)
+			if len(other.Neighbors[l]) > idx.maxNeighbors(l) {
+				other.Neighbors[l] = idx.closest(other.Vector, other.Neighbors[l], idx.maxNeighbors(l))
+			}
+		}
+
+		entries = entries[:0]
+		for _, candidate := range found {
+			entries = append(entries, candidate.node)
+		}
+	}
+
+	if level > idx.maxLevel {
+		idx.entry, idx.maxLevel = position, level
+	}
+}
+
+// maxNeighbors is how many links a node keeps on a level
+func (idx *Index) maxNeighbors(level int) int {
+	if level == 0 {
+		return 2 * idx.m
+	}
+	return idx.m
+}
+
+// closest returns the n of candidates closest to vector
+func (idx *Index) closest(vector []float32, candidates []int32, n int) []int32 {
+	sorted := make([]candidate, len(candidates))
+	for i, c := range candidates {
+		sorted[i] = candidate{node: c, distance: distance(vector, idx.nodes[c].Vector)}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].distance < sorted[j].distance })
+	kept := make([]int32, 0, n)
+	for i := 0; i < len(sorted) && i < n; i++ {
+		kept = append(kept, sorted[i].node)
+	}
+	return kept
+}
+
+// Search returns up to k vectors most similar to the query for which accept
+// returns true, most similar first. A nil accept accepts every vector. ef
+// is the candidate list size; it grows until k vectors are accepted or the
+// whole graph has been searched, so restrictive filters still find matches.
+func (idx *Index) Search(query []float64, k, ef int, accept func(id string) bool) ([]Result, error) {
+	if k <= 0 {
+		return []Result{}, nil
+	}
+	normalized, err := normalize(query)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	if idx.entry < 0 || len(idx.ids) == 0 {
+		return []Result{}, nil
+	}
+	if len(normalized) != idx.dimension {
+		return nil, fmt.Errorf("query has %d dimensions, the index stores %d", len(normalized), idx.dimension)
+	}
+
+	entry := idx.entry
+	for l := idx.maxLevel; l > 0; l-- {
+		entry = idx.searchLayer(normalized, []int32{entry}, 1, l)[0].node
+	}
+
+	ef = max(ef, k)
+	for {
+		found := idx.searchLayer(normalized, []int32{entry}, ef, 0)
+		results := make([]Result, 0, k)
+		for _, c := range found {
+			n := idx.nodes[c.node]
+			if n.Deleted || (accept != nil && !accept(n.ID)) {
+				continue
+			}
+			results = append(results, Result{ID: n.ID, Similarity: 1 - c.distance})
+			if len(results) == k {
+				break
+			}
+		}
+		if len(results) == k || ef >= len(idx.nodes) {
+			return results, nil
+		}
+		ef *= 2
+	}
+}
+
+// candidate is a node and its distance to a query
+type candidate struct {
+	node     int32
+	distance float32
+}
+
+// searchLayer returns the ef nodes of a level closest to vector, closest first
+func (idx *Index) searchLayer(vector []float32, entries []int32, ef, level int) []candidate {
+	visited := make(map[int32]bool, ef*4)
+	near := &nearHeap{}
+	far := &farHeap{}
+	for _, entry := range entries {
+		if visited[entry] {
+			continue
+		}
+		visited[entry] = true
+		c := candidate{node: entry, distance: distance(vector, idx.nodes[entry].Vector)}
+		heap.Push(near, c)
+		heap.Push(far, c)
+	}
+	for far.Len() > ef {
+		heap.Pop(far)
+	}
+
+	for near.Len() > 0 {
+		current := heap.Pop(near).(candidate)
+		if far.Len() >= ef && current.distance > (*far)[0].distance {
+			break
+		}
+		neighbors := idx.nodes[current.node].Neighbors
+		if level >= len(neighbors) {
+			continue
+		}
+		for _, neighbor := range neighbors[level] {
+			if visited[neighbor] {
+				continue
+			}
+			visited[neighbor] = true
+			d := distance(vector, idx.nodes[neighbor].Vector)
+			if far.Len() < ef || d < (*far)[0].distance {
+				heap.Push(near, candidate{node: neighbor, distance: d})
+				heap.Push(far, candidate{node: neighbor, distance: d})
+				if far.Len() > ef {
+					heap.Pop(far)
+				}
+			}
+		}
+	}
+
+	results := make([]candidate, far.Len())
+	for i := len(results) - 1; i >= 0; i-- {
+		results[i] = heap.Pop(far).(candidate)
+	}
+	return results
+}
+
+// Compact rebuilds the graph without tombstones
+func (idx *Index) Compact() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.deleted == 0 {
+		return
+	}
+	live := idx.nodes
+	idx.nodes = make([]*node, 0, len(idx.ids))
+	idx.ids = make(map[string]int32, len(idx.ids))
+	idx.entry, idx.maxLevel, idx.deleted = -1, 0, 0
+	for _, n := range live {
+		if !n.Deleted {
+			idx.insertLocked(n.ID, n.Vector)
+		}
+	}
+}
+
+// snapshot is the layout Save writes
+type snapshot struct {
+	Version        int
+	Dimension      int
+	M              int
+	EfConstruction int
+	Entry          int32
+	MaxLevel       int
+	Nodes          []*node
+}
+
+// Save writes the index to w
+func (idx *Index) Save(w io.Writer) error {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return gob.NewEncoder(w).Encode(&snapshot{
+		Version:        formatVersion,
+		Dimension:      idx.dimension,
+		M:              idx.m,
+		EfConstruction: idx.efConstruction,
+		Entry:          idx.entry,
+		MaxLevel:       idx.maxLevel,
+		Nodes:          idx.nodes,
+	})
+}
+
+// Load reads an index written by Save
+func Load(r io.Reader) (*Index, error) {
+	var s snapshot
+	if err := gob.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("failed to decode index: %w", err)
+	}
+	if s.Version != formatVersion {
+		return nil, fmt.Errorf("unsupported index format version %d", s.Version)
+	}
+
+	idx := New(s.M, s.EfConstruction)
+	idx.dimension, idx.entry, idx.maxLevel, idx.nodes = s.Dimension, s.Entry, s.MaxLevel, s.Nodes
+	if idx.entry >= int32(len(idx.nodes)) {
+		return nil, errors.New("index entry point is out of range")
+	}
+	for position, n := range idx.nodes {
+		if len(n.Vector) != idx.dimension {
+			return nil, fmt.Errorf("vector %s has %d dimensions, the index stores %d", n.ID, len(n.Vector), idx.dimension)
+		}
+		for _, neighbors := range n.Neighbors {
+			for _, neighbor := range neighbors {
+				if neighbor < 0 || neighbor >= int32(len(idx.nodes)) {
+					return nil, fmt.Errorf("vector %s links to missing node %d", n.ID, neighbor)
+				}
+			}
+		}
+		if n.Deleted {
+			idx.deleted++
+		} else {
+			idx.ids[n.ID] = int32(position) //nolint:gosec // Node counts fit in int32
+		}
+	}
+	return idx, nil
+}
+
+// SaveFile writes the index to path, replacing the file atomically
+func (idx *Index) SaveFile(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create index directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600) //nolint:gosec // Path comes from operator configuration
+	if err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	if err := idx.Save(file); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadFile reads an index saved with SaveFile
+func LoadFile(path string) (*Index, error) {
+	file, err := os.Open(path) //nolint:gosec // Path comes from operator configuration
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	return Load(file)
+}
+
+// normalize returns vector scaled to unit length as float32
+func normalize(vector []float64) ([]float32, error) {
+	if len(vector) == 0 {
+		return nil, errors.New("vector is empty")
+	}
+	var norm float64
+	for _, v := range vector {
+		norm += v * v
+	}
+	if norm == 0 || math.IsNaN(norm) || math.IsInf(norm, 0) {
+		return nil, errors.New("vector has no direction")
+	}
+	norm = math.Sqrt(norm)
+	normalized := make([]float32, len(vector))
+	for i, v := range vector {
+		normalized[i] = float32(v / norm)
+	}
+	return normalized, nil
+}
+
+// distance is the cosine distance of two unit vectors
+func distance(a, b []float32) float32 {
+	var dot float32
+	for i := range a {
+		dot += a[i] * b[i]
+	}
+	return 1 - dot
+}
+
+// nearHeap pops the closest candidate first
+type nearHeap []candidate
+
+func (h nearHeap) Len() int            { return len(h) }
+func (h nearHeap) Less(i, j int) bool  { return h[i].distance < h[j].distance }
+func (h nearHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *nearHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+func (h *nearHeap) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// farHeap pops the farthest candidate first
+type farHeap []candidate
+
+func (h farHeap) Len() int            { return len(h) }
+func (h farHeap) Less(i, j int) bool  { return h[i].distance > h[j].distance }
+func (h farHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *farHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+func (h *farHeap) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
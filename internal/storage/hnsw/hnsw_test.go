@@ -0,0 +1,169 @@
+package hnsw
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomVectors(n, dimension int) map[string][]float64 {
+	rng := rand.New(rand.NewPCG(7, 11)) //nolint:gosec // Test data
+	vectors := make(map[string][]float64, n)
+	for i := 0; i < n; i++ {
+		vector := make([]float64, dimension)
+		for j := range vector {
+			vector[j] = rng.NormFloat64()
+		}
+		vectors[fmt.Sprintf("v%04d", i)] = vector
+	}
+	return vectors
+}
+
+// exactNearest returns the IDs of the k vectors most similar to query by brute force
+func exactNearest(vectors map[string][]float64, query []float64, k int) []string {
+	q, _ := normalize(query)
+	type scored struct {
+		id         string
+		similarity float32
+	}
+	all := make([]scored, 0, len(vectors))
+	for id, vector := range vectors {
+		v, _ := normalize(vector)
+		all = append(all, scored{id: id, similarity: 1 - distance(q, v)})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].similarity > all[j].similarity })
+	ids := make([]string, 0, k)
+	for i := 0; i < k; i++ {
+		ids = append(ids, all[i].id)
+	}
+	return ids
+}
+
+func TestIndex_Recall(t *testing.T) {
+	vectors := randomVectors(1000, 32)
+	idx := New(0, 0)
+	for id, vector := range vectors {
+		require.NoError(t, idx.Add(id, vector))
+	}
+	require.Equal(t, 1000, idx.Len())
+
+	queries := randomVectors(20, 32)
+	hits, total := 0, 0
+	for _, query := range queries {
+		want := exactNearest(vectors, query, 10)
+		got, err := idx.Search(query, 10, DefaultEfSearch, nil)
+		require.NoError(t, err)
+		require.Len(t, got, 10)
+		for i := 1; i < len(got); i++ {
+			assert.GreaterOrEqual(t, got[i-1].Similarity, got[i].Similarity, "results are most similar first")
+		}
+		for _, result := range got {
+			for _, id := range want {
+				if result.ID == id {
+					hits++
+				}
+			}
+		}
+		total += len(want)
+	}
+	assert.GreaterOrEqual(t, float64(hits)/float64(total), 0.9, "recall@10 against exact search")
+}
+
+func TestIndex_FilterReplaceRemove(t *testing.T) {
+	vectors := randomVectors(300, 8)
+	idx := New(8, 64)
+	for id, vector := range vectors {
+		require.NoError(t, idx.Add(id, vector))
+	}
+
+	query := vectors["v0007"]
+	got, err := idx.Search(query, 5, 10, func(id string) bool { return strings.HasSuffix(id, "3") })
+	require.NoError(t, err)
+	assert.Len(t, got, 5, "restrictive filters widen the search until enough vectors match")
+	for _, result := range got {
+		assert.True(t, strings.HasSuffix(result.ID, "3"))
+	}
+
+	got, err = idx.Search(query, 1, 10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "v0007", got[0].ID)
+	assert.InDelta(t, 1.0, got[0].Similarity, 1e-5)
+
+	// Replacing a vector moves it; removing it hides it from searches
+	require.NoError(t, idx.Add("v0007", vectors["v0100"]))
+	assert.Equal(t, 300, idx.Len())
+	got, err = idx.Search(vectors["v0100"], 2, 10, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"v0007", "v0100"}, []string{got[0].ID, got[1].ID})
+
+	idx.Remove("v0007")
+	assert.False(t, idx.Has("v0007"))
+	assert.Equal(t, 2, idx.Tombstones())
+	got, err = idx.Search(vectors["v0100"], 300, 10, nil)
+	require.NoError(t, err)
+	assert.Len(t, got, 299)
+	for _, result := range got {
+		assert.NotEqual(t, "v0007", result.ID)
+	}
+
+	idx.Compact()
+	assert.Zero(t, idx.Tombstones())
+	assert.Equal(t, 299, idx.Len())
+	got, err = idx.Search(vectors["v0100"], 1, 10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "v0100", got[0].ID)
+}
+
+func TestIndex_Validation(t *testing.T) {
+	idx := New(0, 0)
+	assert.Error(t, idx.Add("empty", nil))
+	assert.Error(t, idx.Add("zero", []float64{0, 0}))
+	require.NoError(t, idx.Add("a", []float64{1, 0}))
+	assert.ErrorContains(t, idx.Add("b", []float64{1, 0, 0}), "the index stores 2")
+
+	_, err := idx.Search([]float64{1, 0, 0}, 1, 10, nil)
+	assert.Error(t, err)
+
+	empty, err := New(0, 0).Search([]float64{1}, 3, 10, nil)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+func TestIndex_SaveLoad(t *testing.T) {
+	vectors := randomVectors(200, 16)
+	idx := New(0, 0)
+	for id, vector := range vectors {
+		require.NoError(t, idx.Add(id, vector))
+	}
+	idx.Remove("v0001")
+
+	var buf bytes.Buffer
+	require.NoError(t, idx.Save(&buf))
+	loaded, err := Load(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, idx.IDs(), loaded.IDs())
+	assert.Equal(t, 1, loaded.Tombstones())
+	assert.Equal(t, 16, loaded.Dimension())
+
+	query := vectors["v0042"]
+	want, err := idx.Search(query, 5, DefaultEfSearch, nil)
+	require.NoError(t, err)
+	got, err := loaded.Search(query, 5, DefaultEfSearch, nil)
+	require.NoError(t, err)
+	assert.Equal(t, want, got, "a loaded index answers like the saved one")
+
+	path := t.TempDir() + "/index.hnsw"
+	require.NoError(t, loaded.SaveFile(path))
+	fromFile, err := LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, idx.IDs(), fromFile.IDs())
+
+	_, err = Load(strings.NewReader("not an index"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,234 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"lerian-mcp-memory/pkg/types"
+)
+
+// DefaultRRFK is the rank constant of reciprocal rank fusion. Larger values
+// flatten the difference between top and lower ranks.
+const DefaultRRFK = 60
+
+// hybridCandidateFactor widens each ranking fused by a hybrid search, so a
+// chunk ranked just past the limit by one of them can still make the cut
+const hybridCandidateFactor = 3
+
+// HybridSearchStore wraps a VectorStore with a KeywordIndex of the chunks
+// written through it. Searches in keyword mode are served by the index, and
+// hybrid searches run the vector search and the index side by side and fuse
+// their rankings with reciprocal rank fusion; vector searches go to the
+// wrapped store untouched. The index is built from the store on Initialize
+// and follows the writes made through the wrapper.
+type HybridSearchStore struct {
+	VectorStore
+
+	index *KeywordIndex
+	rrfK  int
+}
+
+// NewHybridSearchStore indexes store; rrfK of 0 or less uses DefaultRRFK
+func NewHybridSearchStore(store VectorStore, rrfK int) *HybridSearchStore {
+	if rrfK <= 0 {
+		rrfK = DefaultRRFK
+	}
+	return &HybridSearchStore{VectorStore: store, index: NewKeywordIndex(), rrfK: rrfK}
+}
+
+// Index returns the keyword index
+func (s *HybridSearchStore) Index() *KeywordIndex {
+	return s.index
+}
+
+// SetAnalyzers sets the text analyzers of the index; call it before Initialize
+func (s *HybridSearchStore) SetAnalyzers(analyzers *Analyzers) {
+	s.index.SetAnalyzers(analyzers)
+}
+
+// Initialize initializes the wrapped store and builds the index from it
+func (s *HybridSearchStore) Initialize(ctx context.Context) error {
+	if err := s.VectorStore.Initialize(ctx); err != nil {
+		return err
+	}
+	return s.Rebuild(ctx)
+}
+
+// Rebuild re-reads every chunk of the wrapped store into the index. The
+// trash is indexed too, for searches that include it.
+func (s *HybridSearchStore) Rebuild(ctx context.Context) error {
+	chunks, err := s.VectorStore.GetAllChunks(WithDeleted(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to build keyword index: %w", err)
+	}
+	s.index.Reset(chunks)
+	return nil
+}
+
+// Search ranks chunks as the query's mode asks
+func (s *HybridSearchStore) Search(ctx context.Context, query *types.MemoryQuery, embeddings []float64) (*types.SearchResults, error) {
+	switch query.Mode {
+	case types.SearchModeKeyword:
+		start := time.Now()
+		results := s.index.Search(query, false)
+		results.QueryTime = time.Since(start)
+		return results, nil
+	case types.SearchModeHybrid:
+		return s.hybridSearch(ctx, query, embeddings)
+	default:
+		return s.VectorStore.Search(ctx, query, embeddings)
+	}
+}
+
+// hybridSearch fuses the vector ranking, held to the query's minimum
+// relevance, with the keyword ranking of every chunk holding a query term
+func (s *HybridSearchStore) hybridSearch(ctx context.Context, query *types.MemoryQuery, embeddings []float64) (*types.SearchResults, error) {
+	start := time.Now()
+
+	widened := *query
+	if query.Limit > 0 {
+		widened.Limit = query.Limit * hybridCandidateFactor
+	}
+	vector, err := s.VectorStore.Search(ctx, &widened, embeddings)
+	if err != nil {
+		return nil, err
+	}
+	keyword := s.index.Search(&widened, true)
+
+	fused := FuseRankings(s.rrfK, vector.Results, keyword.Results)
+	total := len(fused)
+	if query.Limit > 0 && len(fused) > query.Limit {
+		fused = fused[:query.Limit]
+	}
+	return &types.SearchResults{Results: fused, Total: total, QueryTime: time.Since(start)}, nil
+}
+
+// FuseRankings merges rankings with reciprocal rank fusion: a chunk scores
+// the sum of 1/(k+rank) over the rankings it appears in. Scores are scaled so
+// a chunk ranked first by every ranking scores 1.0. Each chunk keeps the
+// result of the first ranking it appears in.
+func FuseRankings(k int, rankings ...[]types.SearchResult) []types.SearchResult {
+	if len(rankings) == 0 {
+		return []types.SearchResult{}
+	}
+	best := float64(len(rankings)) / float64(k+1)
+
+	fused := make([]types.SearchResult, 0)
+	positions := make(map[string]int)
+	for _, ranking := range rankings {
+		for rank := range ranking {
+			id := ranking[rank].Chunk.ID
+			score := 1 / float64(k+rank+1) / best
+			if i, ok := positions[id]; ok {
+				fused[i].Score += score
+				continue
+			}
+			positions[id] = len(fused)
+			result := ranking[rank]
+			result.Score = score
+			fused = append(fused, result)
+		}
+	}
+
+	// Stable, so ties keep the order of the earlier rankings
+	sort.SliceStable(fused, func(i, j int) bool {
+		return fused[i].Score > fused[j].Score
+	})
+	return fused
+}
+
+// Store stores a chunk and indexes it
+func (s *HybridSearchStore) Store(ctx context.Context, chunk *types.ConversationChunk) error {
+	if err := s.VectorStore.Store(ctx, chunk); err != nil {
+		return err
+	}
+	s.index.Add(chunk)
+	return nil
+}
+
+// StoreChunk is an alias for Store
+func (s *HybridSearchStore) StoreChunk(ctx context.Context, chunk *types.ConversationChunk) error {
+	return s.Store(ctx, chunk)
+}
+
+// Update updates a chunk and re-indexes it
+func (s *HybridSearchStore) Update(ctx context.Context, chunk *types.ConversationChunk) error {
+	if err := s.VectorStore.Update(ctx, chunk); err != nil {
+		return err
+	}
+	s.index.Add(chunk)
+	return nil
+}
+
+// Delete removes a chunk and its index entry
+func (s *HybridSearchStore) Delete(ctx context.Context, id string) error {
+	if err := s.VectorStore.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.index.Remove(id)
+	return nil
+}
+
+// BatchStore stores chunks and indexes those stored
+func (s *HybridSearchStore) BatchStore(ctx context.Context, chunks []*types.ConversationChunk) (*BatchResult, error) {
+	result, err := s.VectorStore.BatchStore(ctx, chunks)
+	if result == nil {
+		return result, err
+	}
+
+	processed := batchProcessedIDs(result, err)
+	for _, chunk := range chunks {
+		if processed == nil || processed[chunk.ID] {
+			s.index.Add(chunk)
+		}
+	}
+	return result, err
+}
+
+// BatchDelete removes chunks and the index entries of those removed
+func (s *HybridSearchStore) BatchDelete(ctx context.Context, ids []string) (*BatchResult, error) {
+	result, err := s.VectorStore.BatchDelete(ctx, ids)
+	if result == nil {
+		return result, err
+	}
+
+	processed := batchProcessedIDs(result, err)
+	for _, id := range ids {
+		if processed == nil || processed[id] {
+			s.index.Remove(id)
+		}
+	}
+	return result, err
+}
+
+// batchProcessedIDs returns the IDs a batch write processed, or nil when it
+// processed every item
+func batchProcessedIDs(result *BatchResult, err error) map[string]bool {
+	if result.Failed == 0 && err == nil {
+		return nil
+	}
+	processed := make(map[string]bool, len(result.ProcessedIDs))
+	for _, id := range result.ProcessedIDs {
+		processed[id] = true
+	}
+	return processed
+}
+
+// Cleanup removes old chunks and rebuilds the index when any were removed
+func (s *HybridSearchStore) Cleanup(ctx context.Context, retentionDays int) (int, error) {
+	removed, err := s.VectorStore.Cleanup(ctx, retentionDays)
+	if err == nil && removed > 0 {
+		err = s.Rebuild(ctx)
+	}
+	return removed, err
+}
+
+// DeleteCollection deletes a collection and rebuilds the index
+func (s *HybridSearchStore) DeleteCollection(ctx context.Context, collection string) error {
+	if err := s.VectorStore.DeleteCollection(ctx, collection); err != nil {
+		return err
+	}
+	return s.Rebuild(ctx)
+}
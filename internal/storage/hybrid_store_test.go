@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeywordIndexSearch(t *testing.T) {
+	index := NewKeywordIndex()
+	failure := newKeywordChunk(t, "repo-a", "Payment failed with ERR_4021 at checkout", types.ChunkTypeProblem)
+	checkout := newKeywordChunk(t, "repo-a", "Checkout page redesign", types.ChunkTypeDiscussion)
+	other := newKeywordChunk(t, "repo-b", "ERR_4021 also seen in billing", types.ChunkTypeProblem)
+	index.Reset([]types.ConversationChunk{*failure, *checkout, *other})
+
+	query := types.NewMemoryQuery("err_4021 checkout")
+	query.MinRelevanceScore = 0
+	results := index.Search(query, false)
+	assert.Equal(t, []string{failure.ID, other.ID, checkout.ID}, resultIDs(results), "chunks holding more and rarer query terms rank first")
+	assert.InDelta(t, 1.0, results.Results[0].Score, 0.0001)
+	assert.Nil(t, results.Results[0].Chunk.Embeddings, "the index keeps chunks without vectors")
+
+	repo := "repo-a"
+	query.Repository = &repo
+	query.Types = []types.ChunkType{types.ChunkTypeProblem}
+	assert.Equal(t, []string{failure.ID}, resultIDs(index.Search(query, false)), "query filters apply")
+
+	// Updates replace the indexed terms and deletes drop them
+	failure.Content = "Payment retried successfully"
+	index.Add(failure)
+	index.Remove(other.ID)
+	query = types.NewMemoryQuery("err_4021")
+	assert.Empty(t, index.Search(query, false).Results)
+	assert.Equal(t, 2, index.Len())
+}
+
+func TestKeywordIndexMinRelevance(t *testing.T) {
+	index := NewKeywordIndex()
+	strong := newKeywordChunk(t, "repo-a", "timeout timeout timeout in the gateway", types.ChunkTypeProblem)
+	weak := newKeywordChunk(t, "repo-a", "gateway configuration reference for the gateway team and its many services", types.ChunkTypeDiscussion)
+	index.Reset([]types.ConversationChunk{*strong, *weak})
+
+	query := types.NewMemoryQuery("gateway timeout")
+	query.MinRelevanceScore = 0.9
+	assert.Equal(t, []string{strong.ID}, resultIDs(index.Search(query, false)))
+	assert.Len(t, index.Search(query, true).Results, 2, "allScores keeps every chunk holding a query term")
+}
+
+func TestFuseRankings(t *testing.T) {
+	result := func(id string) types.SearchResult {
+		return types.SearchResult{Chunk: types.ConversationChunk{ID: id}, Score: 0.9}
+	}
+	vector := []types.SearchResult{result("a"), result("b"), result("c")}
+	keyword := []types.SearchResult{result("c"), result("d")}
+
+	fused := FuseRankings(60, vector, keyword)
+	ids := make([]string, len(fused))
+	for i := range fused {
+		ids[i] = fused[i].Chunk.ID
+	}
+	assert.Equal(t, []string{"c", "a", "b", "d"}, ids, "a chunk in both rankings beats chunks in one")
+	assert.InDelta(t, (1.0/63+1.0/61)/(2.0/61), fused[0].Score, 1e-9)
+	assert.InDelta(t, 1.0, FuseRankings(60, vector, vector)[0].Score, 1e-9, "first in every ranking scores 1.0")
+	assert.Empty(t, FuseRankings(60))
+}
+
+func TestHybridSearchStore(t *testing.T) {
+	ctx := context.Background()
+	inner := NewLocalVectorStore("")
+	require.NoError(t, inner.Initialize(ctx))
+	preexisting := newLocalChunk(t, "repo-a", "Database migration plan", []float64{0.9, 0.1, 0})
+	require.NoError(t, inner.Store(ctx, preexisting))
+
+	store := NewHybridSearchStore(inner, 0)
+	require.NoError(t, store.Initialize(ctx))
+	assert.Equal(t, 1, store.Index().Len(), "the index is built from the wrapped store")
+
+	failure := newLocalChunk(t, "repo-a", "Payment failed with ERR_4021 at checkout", []float64{0, 1, 0})
+	redesign := newLocalChunk(t, "repo-a", "Checkout flow redesign notes", []float64{1, 0, 0})
+	require.NoError(t, store.Store(ctx, failure))
+	result, err := store.BatchStore(ctx, []*types.ConversationChunk{redesign})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Success)
+
+	query := types.NewMemoryQuery("ERR_4021 checkout")
+	query.MinRelevanceScore = 0
+	embedding := []float64{1, 0, 0}
+
+	vector, err := store.Search(ctx, query, embedding)
+	require.NoError(t, err)
+	assert.Equal(t, []string{redesign.ID, preexisting.ID, failure.ID}, resultIDs(vector), "vector mode ranks by similarity alone")
+
+	query.Mode = types.SearchModeKeyword
+	keyword, err := store.Search(ctx, query, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{failure.ID, redesign.ID}, resultIDs(keyword), "keyword mode finds the exact identifier")
+
+	query.Mode = types.SearchModeHybrid
+	query.Limit = 2
+	hybrid, err := store.Search(ctx, query, embedding)
+	require.NoError(t, err)
+	assert.Equal(t, []string{redesign.ID, failure.ID}, resultIDs(hybrid), "hybrid mode lifts keyword matches over vector-only ones")
+	assert.Equal(t, 3, hybrid.Total)
+
+	require.NoError(t, store.Delete(ctx, failure.ID))
+	query.Mode = types.SearchModeKeyword
+	keyword, err = store.Search(ctx, query, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{redesign.ID}, resultIDs(keyword), "deleted chunks leave the index")
+
+	_, err = store.BatchDelete(ctx, []string{redesign.ID})
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.Index().Len())
+}
@@ -7,6 +7,28 @@ import (
 	"lerian-mcp-memory/pkg/types"
 )
 
+// includeDeletedKey marks contexts whose listings include the trash
+type includeDeletedKey struct{}
+
+// WithDeleted returns a context under which ListByRepository, ListBySession
+// and GetAllChunks also return chunks in the trash, as Search does for a
+// MemoryQuery with IncludeDeleted. Only code that manages the trash itself,
+// or copies the whole store, should ask for it.
+func WithDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, includeDeletedKey{}, true)
+}
+
+// IncludesDeleted reports whether ctx asks listings to include the trash
+func IncludesDeleted(ctx context.Context) bool {
+	include, _ := ctx.Value(includeDeletedKey{}).(bool)
+	return include
+}
+
+// listable reports whether a listing made under ctx returns chunk
+func listable(ctx context.Context, chunk *types.ConversationChunk) bool {
+	return !chunk.IsDeleted() || IncludesDeleted(ctx)
+}
+
 // VectorStore defines the interface for vector database operations
 type VectorStore interface {
 	// Initialize the vector store (create collections, etc.)
@@ -21,10 +43,15 @@ type VectorStore interface {
 	// Get a chunk by its ID
 	GetByID(ctx context.Context, id string) (*types.ConversationChunk, error)
 
-	// List chunks by repository with optional filters
+	// Get several chunks by ID in one round trip. Unknown IDs are skipped and
+	// results follow the order of ids.
+	GetByIDs(ctx context.Context, ids []string) ([]types.ConversationChunk, error)
+
+	// List chunks by repository with optional filters. Chunks in the trash
+	// are left out unless ctx comes from WithDeleted.
 	ListByRepository(ctx context.Context, repository string, limit int, offset int) ([]types.ConversationChunk, error)
 
-	// List chunks by session ID
+	// List chunks by session ID, leaving out the trash like ListByRepository
 	ListBySession(ctx context.Context, sessionID string) ([]types.ConversationChunk, error)
 
 	// Delete a chunk by ID
@@ -47,7 +74,8 @@ type VectorStore interface {
 
 	// Additional methods for service compatibility
 
-	// GetAllChunks retrieves all chunks (for backup operations)
+	// GetAllChunks retrieves all chunks (for backup operations), leaving out
+	// the trash like ListByRepository
 	GetAllChunks(ctx context.Context) ([]types.ConversationChunk, error)
 
 	// DeleteCollection deletes an entire collection
@@ -126,3 +154,15 @@ type StorageMetrics struct {
 	LastOperation    *string            `json:"last_operation,omitempty"`
 	ConnectionStatus string             `json:"connection_status"`
 }
+
+// orderChunksByID returns the found chunks in the order of ids, once per ID
+func orderChunksByID(ids []string, byID map[string]*types.ConversationChunk) []types.ConversationChunk {
+	results := make([]types.ConversationChunk, 0, len(byID))
+	for _, id := range ids {
+		if chunk, ok := byID[id]; ok {
+			results = append(results, *chunk)
+			delete(byID, id)
+		}
+	}
+	return results
+}
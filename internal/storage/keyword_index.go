@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"lerian-mcp-memory/pkg/types"
+)
+
+// KeywordIndex is an in-memory inverted index of chunk terms that ranks
+// chunks by BM25, so searches find exact identifiers (error codes, function
+// names) without scanning every chunk. Term statistics are taken over the
+// whole index. Chunks are kept without their vectors, to apply query filters.
+type KeywordIndex struct {
+	mutex       sync.RWMutex
+	postings    map[string]map[string]int // term -> chunk ID -> term frequency
+	docs        map[string]*indexedChunk
+	totalLength int
+	analyzers   *Analyzers
+}
+
+// indexedChunk is a chunk in the index and the distinct terms it holds
+type indexedChunk struct {
+	chunk  types.ConversationChunk
+	terms  []string
+	length int
+}
+
+// NewKeywordIndex creates an empty index
+func NewKeywordIndex() *KeywordIndex {
+	return &KeywordIndex{
+		postings: make(map[string]map[string]int),
+		docs:     make(map[string]*indexedChunk),
+	}
+}
+
+// SetAnalyzers sets the text analyzers chunks and queries are split into
+// terms with; nil uses the standard analyzer. Chunks indexed before keep
+// their terms until the next Reset.
+func (ki *KeywordIndex) SetAnalyzers(analyzers *Analyzers) {
+	ki.mutex.Lock()
+	defer ki.mutex.Unlock()
+	ki.analyzers = analyzers
+}
+
+// Reset replaces the contents of the index with chunks
+func (ki *KeywordIndex) Reset(chunks []types.ConversationChunk) {
+	ki.mutex.Lock()
+	defer ki.mutex.Unlock()
+
+	ki.postings = make(map[string]map[string]int)
+	ki.docs = make(map[string]*indexedChunk, len(chunks))
+	ki.totalLength = 0
+	for i := range chunks {
+		ki.addLocked(&chunks[i])
+	}
+}
+
+// Add indexes a chunk, replacing an earlier version of it
+func (ki *KeywordIndex) Add(chunk *types.ConversationChunk) {
+	ki.mutex.Lock()
+	defer ki.mutex.Unlock()
+
+	ki.removeLocked(chunk.ID)
+	ki.addLocked(chunk)
+}
+
+// Remove drops a chunk from the index
+func (ki *KeywordIndex) Remove(id string) {
+	ki.mutex.Lock()
+	defer ki.mutex.Unlock()
+
+	ki.removeLocked(id)
+}
+
+// Len returns the number of indexed chunks
+func (ki *KeywordIndex) Len() int {
+	ki.mutex.RLock()
+	defer ki.mutex.RUnlock()
+
+	return len(ki.docs)
+}
+
+// addLocked indexes a chunk; callers must hold the write lock
+func (ki *KeywordIndex) addLocked(chunk *types.ConversationChunk) {
+	terms := ki.analyzers.ChunkTerms(chunk)
+	freqs := make(map[string]int, len(terms))
+	for _, term := range terms {
+		freqs[term]++
+	}
+
+	doc := &indexedChunk{chunk: *chunk, terms: make([]string, 0, len(freqs)), length: len(terms)}
+	doc.chunk.Embeddings = nil
+	for term, freq := range freqs {
+		postings, ok := ki.postings[term]
+		if !ok {
+			postings = make(map[string]int)
+			ki.postings[term] = postings
+		}
+		postings[chunk.ID] = freq
+		doc.terms = append(doc.terms, term)
+	}
+	ki.docs[chunk.ID] = doc
+	ki.totalLength += doc.length
+}
+
+// removeLocked drops a chunk; callers must hold the write lock
+func (ki *KeywordIndex) removeLocked(id string) {
+	doc, ok := ki.docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range doc.terms {
+		delete(ki.postings[term], id)
+		if len(ki.postings[term]) == 0 {
+			delete(ki.postings, term)
+		}
+	}
+	ki.totalLength -= doc.length
+	delete(ki.docs, id)
+}
+
+// Search ranks the chunks matching the query filters that hold any query
+// term by BM25 score, best first, normalized so the best match scores 1.0.
+// Unless allScores is set, results scoring under the query's minimum
+// relevance are left out. Total counts the matches before the query limit.
+func (ki *KeywordIndex) Search(query *types.MemoryQuery, allScores bool) *types.SearchResults {
+	ki.mutex.RLock()
+	defer ki.mutex.RUnlock()
+
+	n := float64(len(ki.docs))
+	avgLength := 1.0
+	if n > 0 && ki.totalLength > 0 {
+		avgLength = float64(ki.totalLength) / n
+	}
+
+	scores := make(map[string]float64)
+	for _, term := range ki.analyzers.QueryTerms(query) {
+		postings := ki.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, freq := range postings {
+			if _, seen := scores[id]; !seen && !matchesQueryFilters(&ki.docs[id].chunk, query) {
+				scores[id] = -1 // Filtered out; remembered to skip the check for later terms
+				continue
+			}
+			if scores[id] < 0 {
+				continue
+			}
+			tf := float64(freq)
+			length := float64(ki.docs[id].length)
+			scores[id] += idf * (tf * (bm25K1 + 1)) / (tf + bm25K1*(1-bm25B+bm25B*length/avgLength))
+		}
+	}
+
+	maxScore := 0.0
+	for _, score := range scores {
+		maxScore = math.Max(maxScore, score)
+	}
+
+	results := make([]types.SearchResult, 0, len(scores))
+	for id, score := range scores {
+		if score <= 0 {
+			continue
+		}
+		score /= maxScore
+		if !allScores && score < query.MinRelevanceScore {
+			continue
+		}
+		results = append(results, types.SearchResult{Chunk: ki.docs[id].chunk, Score: score})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if !results[i].Chunk.Timestamp.Equal(results[j].Chunk.Timestamp) {
+			return results[i].Chunk.Timestamp.After(results[j].Chunk.Timestamp)
+		}
+		return results[i].Chunk.ID < results[j].Chunk.ID
+	})
+
+	total := len(results)
+	if query.Limit > 0 && len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+	return &types.SearchResults{Results: results, Total: total}
+}
@@ -0,0 +1,706 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/google/uuid"
+)
+
+// BM25 tuning parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// KeywordStore is a VectorStore that ranks chunks with BM25 keyword scoring instead of
+// vector similarity. It needs no embedding provider or external database, which makes it
+// the storage backend for lite mode. Data is kept in memory and, when a path is given,
+// persisted as a JSON snapshot after every write.
+type KeywordStore struct {
+	mutex         sync.RWMutex
+	path          string
+	chunks        map[string]types.ConversationChunk
+	relationships map[string]*types.MemoryRelationship
+	analyzers     *Analyzers
+}
+
+// keywordSnapshot is the on-disk layout of a KeywordStore
+type keywordSnapshot struct {
+	Chunks        []types.ConversationChunk   `json:"chunks"`
+	Relationships []*types.MemoryRelationship `json:"relationships"`
+}
+
+// NewKeywordStore creates a keyword store; an empty path keeps data in memory only
+func NewKeywordStore(path string) *KeywordStore {
+	return &KeywordStore{
+		path:          path,
+		chunks:        make(map[string]types.ConversationChunk),
+		relationships: make(map[string]*types.MemoryRelationship),
+	}
+}
+
+// SetAnalyzers sets the text analyzers chunks and queries are split into
+// terms with; nil uses the standard analyzer
+func (ks *KeywordStore) SetAnalyzers(analyzers *Analyzers) {
+	ks.mutex.Lock()
+	defer ks.mutex.Unlock()
+	ks.analyzers = analyzers
+}
+
+// Initialize loads the persisted snapshot, if any
+func (ks *KeywordStore) Initialize(ctx context.Context) error {
+	if ks.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(ks.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read keyword store: %w", err)
+	}
+
+	var snapshot keywordSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("failed to parse keyword store: %w", err)
+	}
+
+	ks.mutex.Lock()
+	defer ks.mutex.Unlock()
+	for i := range snapshot.Chunks {
+		ks.chunks[snapshot.Chunks[i].ID] = snapshot.Chunks[i]
+	}
+	for _, rel := range snapshot.Relationships {
+		ks.relationships[rel.ID] = rel
+	}
+	return nil
+}
+
+// persistLocked writes the snapshot to disk; callers must hold the write lock
+func (ks *KeywordStore) persistLocked() error {
+	if ks.path == "" {
+		return nil
+	}
+
+	snapshot := keywordSnapshot{
+		Chunks:        make([]types.ConversationChunk, 0, len(ks.chunks)),
+		Relationships: make([]*types.MemoryRelationship, 0, len(ks.relationships)),
+	}
+	for id := range ks.chunks {
+		snapshot.Chunks = append(snapshot.Chunks, ks.chunks[id])
+	}
+	for _, rel := range ks.relationships {
+		snapshot.Relationships = append(snapshot.Relationships, rel)
+	}
+
+	data, err := json.Marshal(&snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode keyword store: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(ks.path), 0o750); err != nil {
+		return fmt.Errorf("failed to create keyword store directory: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated snapshot
+	tmp := ks.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write keyword store: %w", err)
+	}
+	return os.Rename(tmp, ks.path)
+}
+
+// Store stores a chunk; embeddings are optional
+func (ks *KeywordStore) Store(ctx context.Context, chunk *types.ConversationChunk) error {
+	if chunk.ID == "" {
+		return errors.New("chunk ID is required")
+	}
+	if chunk.Type == "" {
+		return errors.New("chunk type is required")
+	}
+
+	ks.mutex.Lock()
+	defer ks.mutex.Unlock()
+	ks.chunks[chunk.ID] = *chunk
+	return ks.persistLocked()
+}
+
+// Search ranks chunks matching the query filters by BM25 score. The embeddings
+// argument is ignored. Scores are normalized so the best match scores 1.0.
+func (ks *KeywordStore) Search(ctx context.Context, query *types.MemoryQuery, _ []float64) (*types.SearchResults, error) {
+	start := time.Now()
+
+	ks.mutex.RLock()
+	defer ks.mutex.RUnlock()
+
+	candidates := make([]types.ConversationChunk, 0, len(ks.chunks))
+	for id := range ks.chunks {
+		chunk := ks.chunks[id]
+		if matchesQueryFilters(&chunk, query) {
+			candidates = append(candidates, chunk)
+		}
+	}
+
+	scores := bm25Scores(ks.analyzers.QueryTerms(query), candidates, ks.analyzers)
+
+	results := make([]types.SearchResult, 0, len(candidates))
+	for i := range candidates {
+		score := scores[i]
+		if query.Query != "" && score < query.MinRelevanceScore {
+			continue
+		}
+		if query.Query != "" && score == 0 {
+			continue
+		}
+		results = append(results, types.SearchResult{Chunk: candidates[i], Score: score})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Chunk.Timestamp.After(results[j].Chunk.Timestamp)
+	})
+
+	total := len(results)
+	if query.Limit > 0 && len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+
+	return &types.SearchResults{
+		Results:   results,
+		Total:     total,
+		QueryTime: time.Since(start),
+	}, nil
+}
+
+// matchesQueryFilters applies the trash, repository, type and recency filters of a query
+func matchesQueryFilters(chunk *types.ConversationChunk, query *types.MemoryQuery) bool {
+	if chunk.IsDeleted() && !query.IncludeDeleted {
+		return false
+	}
+
+	if query.Repository != nil && *query.Repository != "" && chunk.Metadata.Repository != *query.Repository {
+		return false
+	}
+
+	if !query.Provenance.Matches(chunk.Metadata.Provenance) {
+		return false
+	}
+
+	if len(query.Classes) > 0 && !types.HasMemoryClass(query.Classes, chunk.Class()) {
+		return false
+	}
+
+	if len(query.Types) > 0 {
+		found := false
+		for _, t := range query.Types {
+			if chunk.Type == t {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	switch query.Recency {
+	case types.RecencyRecent:
+		return chunk.Timestamp.After(time.Now().AddDate(0, 0, -7))
+	case types.RecencyLastMonth:
+		return chunk.Timestamp.After(time.Now().AddDate(0, -1, 0))
+	case types.RecencyAllTime:
+		return true
+	}
+	return true
+}
+
+// bm25Scores scores each document against the query terms and normalizes to [0, 1]
+func bm25Scores(queryTerms []string, docs []types.ConversationChunk, analyzers *Analyzers) []float64 {
+	scores := make([]float64, len(docs))
+	if len(queryTerms) == 0 || len(docs) == 0 {
+		for i := range scores {
+			scores[i] = 1.0
+		}
+		return scores
+	}
+
+	termFreqs := make([]map[string]int, len(docs))
+	docFreq := make(map[string]int)
+	totalLength := 0
+
+	for i := range docs {
+		terms := analyzers.ChunkTerms(&docs[i])
+		totalLength += len(terms)
+		freqs := make(map[string]int, len(terms))
+		for _, term := range terms {
+			freqs[term]++
+		}
+		for term := range freqs {
+			docFreq[term]++
+		}
+		termFreqs[i] = freqs
+	}
+
+	n := float64(len(docs))
+	avgLength := float64(totalLength) / n
+	if avgLength == 0 {
+		avgLength = 1
+	}
+
+	maxScore := 0.0
+	for i := range docs {
+		docLength := 0
+		for _, f := range termFreqs[i] {
+			docLength += f
+		}
+
+		score := 0.0
+		for _, term := range queryTerms {
+			tf := float64(termFreqs[i][term])
+			if tf == 0 {
+				continue
+			}
+			df := float64(docFreq[term])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			score += idf * (tf * (bm25K1 + 1)) / (tf + bm25K1*(1-bm25B+bm25B*float64(docLength)/avgLength))
+		}
+
+		scores[i] = score
+		if score > maxScore {
+			maxScore = score
+		}
+	}
+
+	if maxScore > 0 {
+		for i := range scores {
+			scores[i] /= maxScore
+		}
+	}
+	return scores
+}
+
+// GetByID returns a chunk by ID
+func (ks *KeywordStore) GetByID(ctx context.Context, id string) (*types.ConversationChunk, error) {
+	ks.mutex.RLock()
+	defer ks.mutex.RUnlock()
+
+	chunk, exists := ks.chunks[id]
+	if !exists {
+		return nil, errors.New("chunk not found")
+	}
+	return &chunk, nil
+}
+
+// GetByIDs returns the chunks with the given IDs, skipping unknown ones
+func (ks *KeywordStore) GetByIDs(ctx context.Context, ids []string) ([]types.ConversationChunk, error) {
+	ks.mutex.RLock()
+	defer ks.mutex.RUnlock()
+
+	byID := make(map[string]*types.ConversationChunk, len(ids))
+	for _, id := range ids {
+		if chunk, exists := ks.chunks[id]; exists {
+			byID[id] = &chunk
+		}
+	}
+	return orderChunksByID(ids, byID), nil
+}
+
+// sortedChunksLocked returns chunks listable under ctx that match the
+// predicate, newest first; callers must hold a lock
+func (ks *KeywordStore) sortedChunksLocked(ctx context.Context, match func(*types.ConversationChunk) bool) []types.ConversationChunk {
+	results := make([]types.ConversationChunk, 0)
+	for id := range ks.chunks {
+		chunk := ks.chunks[id]
+		if listable(ctx, &chunk) && match(&chunk) {
+			results = append(results, chunk)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Timestamp.After(results[j].Timestamp)
+	})
+	return results
+}
+
+// ListByRepository lists chunks of a repository, newest first
+func (ks *KeywordStore) ListByRepository(ctx context.Context, repository string, limit, offset int) ([]types.ConversationChunk, error) {
+	ks.mutex.RLock()
+	defer ks.mutex.RUnlock()
+
+	results := ks.sortedChunksLocked(ctx, func(c *types.ConversationChunk) bool {
+		return repository == "" || c.Metadata.Repository == repository
+	})
+
+	if offset >= len(results) {
+		return []types.ConversationChunk{}, nil
+	}
+	results = results[offset:]
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// ListBySession lists chunks of a session, newest first
+func (ks *KeywordStore) ListBySession(ctx context.Context, sessionID string) ([]types.ConversationChunk, error) {
+	ks.mutex.RLock()
+	defer ks.mutex.RUnlock()
+
+	return ks.sortedChunksLocked(ctx, func(c *types.ConversationChunk) bool {
+		return c.SessionID == sessionID
+	}), nil
+}
+
+// Delete removes a chunk by ID
+func (ks *KeywordStore) Delete(ctx context.Context, id string) error {
+	ks.mutex.Lock()
+	defer ks.mutex.Unlock()
+
+	if _, exists := ks.chunks[id]; !exists {
+		return errors.New("chunk not found")
+	}
+	delete(ks.chunks, id)
+	return ks.persistLocked()
+}
+
+// Update replaces an existing chunk
+func (ks *KeywordStore) Update(ctx context.Context, chunk *types.ConversationChunk) error {
+	if chunk.ID == "" {
+		return errors.New("chunk ID is required")
+	}
+
+	ks.mutex.Lock()
+	defer ks.mutex.Unlock()
+
+	if _, exists := ks.chunks[chunk.ID]; !exists {
+		return errors.New("chunk not found")
+	}
+	ks.chunks[chunk.ID] = *chunk
+	return ks.persistLocked()
+}
+
+// HealthCheck always succeeds; the store has no external dependencies
+func (ks *KeywordStore) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+// GetStats returns chunk counts and the time range of stored chunks
+func (ks *KeywordStore) GetStats(ctx context.Context) (*StoreStats, error) {
+	ks.mutex.RLock()
+	defer ks.mutex.RUnlock()
+
+	stats := &StoreStats{
+		TotalChunks:  int64(len(ks.chunks)),
+		ChunksByType: make(map[string]int64),
+		ChunksByRepo: make(map[string]int64),
+	}
+
+	var oldest, newest time.Time
+	for id := range ks.chunks {
+		chunk := ks.chunks[id]
+		stats.ChunksByType[string(chunk.Type)]++
+		if chunk.Metadata.Repository != "" {
+			stats.ChunksByRepo[chunk.Metadata.Repository]++
+		}
+		stats.StorageSize += int64(len(chunk.Content))
+		if oldest.IsZero() || chunk.Timestamp.Before(oldest) {
+			oldest = chunk.Timestamp
+		}
+		if chunk.Timestamp.After(newest) {
+			newest = chunk.Timestamp
+		}
+	}
+
+	if !oldest.IsZero() {
+		o, n := oldest.Format(time.RFC3339), newest.Format(time.RFC3339)
+		stats.OldestChunk, stats.NewestChunk = &o, &n
+	}
+	return stats, nil
+}
+
+// Cleanup deletes chunks older than the retention period
+func (ks *KeywordStore) Cleanup(ctx context.Context, retentionDays int) (int, error) {
+	ks.mutex.Lock()
+	defer ks.mutex.Unlock()
+
+	cutoff := time.Now().AddDate(0, 0, -retentionDays)
+	deleted := 0
+	for id := range ks.chunks {
+		if ks.chunks[id].Timestamp.Before(cutoff) {
+			delete(ks.chunks, id)
+			deleted++
+		}
+	}
+
+	if deleted == 0 {
+		return 0, nil
+	}
+	return deleted, ks.persistLocked()
+}
+
+// Close flushes the snapshot to disk
+func (ks *KeywordStore) Close() error {
+	ks.mutex.Lock()
+	defer ks.mutex.Unlock()
+	return ks.persistLocked()
+}
+
+// GetAllChunks returns every stored chunk
+func (ks *KeywordStore) GetAllChunks(ctx context.Context) ([]types.ConversationChunk, error) {
+	ks.mutex.RLock()
+	defer ks.mutex.RUnlock()
+
+	return ks.sortedChunksLocked(ctx, func(*types.ConversationChunk) bool { return true }), nil
+}
+
+// DeleteCollection removes all chunks and relationships
+func (ks *KeywordStore) DeleteCollection(ctx context.Context, collection string) error {
+	ks.mutex.Lock()
+	defer ks.mutex.Unlock()
+
+	ks.chunks = make(map[string]types.ConversationChunk)
+	ks.relationships = make(map[string]*types.MemoryRelationship)
+	return ks.persistLocked()
+}
+
+// ListCollections returns the single collection backing the store
+func (ks *KeywordStore) ListCollections(ctx context.Context) ([]string, error) {
+	return []string{"keyword"}, nil
+}
+
+// FindSimilar ranks chunks of the given type by keyword overlap with the content
+func (ks *KeywordStore) FindSimilar(ctx context.Context, content string, chunkType *types.ChunkType, limit int) ([]types.ConversationChunk, error) {
+	query := types.NewMemoryQuery(content)
+	query.Limit = limit
+	query.MinRelevanceScore = 0
+	if chunkType != nil {
+		query.Types = []types.ChunkType{*chunkType}
+	}
+
+	results, err := ks.Search(ctx, query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make([]types.ConversationChunk, 0, len(results.Results))
+	for i := range results.Results {
+		chunks = append(chunks, results.Results[i].Chunk)
+	}
+	return chunks, nil
+}
+
+// StoreChunk is an alias for Store
+func (ks *KeywordStore) StoreChunk(ctx context.Context, chunk *types.ConversationChunk) error {
+	return ks.Store(ctx, chunk)
+}
+
+// BatchStore stores multiple chunks
+func (ks *KeywordStore) BatchStore(ctx context.Context, chunks []*types.ConversationChunk) (*BatchResult, error) {
+	result := &BatchResult{Errors: []string{}, ProcessedIDs: []string{}}
+	for _, chunk := range chunks {
+		if err := ks.Store(ctx, chunk); err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, err.Error())
+		} else {
+			result.Success++
+		}
+		result.ProcessedIDs = append(result.ProcessedIDs, chunk.ID)
+	}
+	return result, nil
+}
+
+// BatchDelete deletes multiple chunks
+func (ks *KeywordStore) BatchDelete(ctx context.Context, ids []string) (*BatchResult, error) {
+	result := &BatchResult{Errors: []string{}, ProcessedIDs: ids}
+	for _, id := range ids {
+		if err := ks.Delete(ctx, id); err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, err.Error())
+		} else {
+			result.Success++
+		}
+	}
+	return result, nil
+}
+
+// StoreRelationship stores a relationship between two chunks
+func (ks *KeywordStore) StoreRelationship(ctx context.Context, sourceID, targetID string, relationType types.RelationType, confidence float64, source types.ConfidenceSource) (*types.MemoryRelationship, error) {
+	now := time.Now()
+	rel := &types.MemoryRelationship{
+		ID:               uuid.New().String(),
+		SourceChunkID:    sourceID,
+		TargetChunkID:    targetID,
+		RelationType:     relationType,
+		Confidence:       confidence,
+		ConfidenceSource: source,
+		CreatedAt:        now,
+	}
+
+	ks.mutex.Lock()
+	defer ks.mutex.Unlock()
+	ks.relationships[rel.ID] = rel
+	return rel, ks.persistLocked()
+}
+
+// GetRelationships returns relationships of a chunk matching the query filters
+func (ks *KeywordStore) GetRelationships(ctx context.Context, query *types.RelationshipQuery) ([]types.RelationshipResult, error) {
+	ks.mutex.RLock()
+	defer ks.mutex.RUnlock()
+
+	results := make([]types.RelationshipResult, 0)
+	for _, rel := range ks.relationships {
+		if !relationshipMatches(rel, query) {
+			continue
+		}
+
+		result := types.RelationshipResult{Relationship: *rel}
+		if query.IncludeChunks {
+			if chunk, ok := ks.chunks[rel.SourceChunkID]; ok {
+				result.SourceChunk = &chunk
+			}
+			if chunk, ok := ks.chunks[rel.TargetChunkID]; ok {
+				result.TargetChunk = &chunk
+			}
+		}
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Relationship.Confidence > results[j].Relationship.Confidence
+	})
+	if query.Limit > 0 && len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+	return results, nil
+}
+
+// relationshipMatches checks a relationship against the direction, type and confidence filters
+func relationshipMatches(rel *types.MemoryRelationship, query *types.RelationshipQuery) bool {
+	switch query.Direction {
+	case "outgoing":
+		if rel.SourceChunkID != query.ChunkID {
+			return false
+		}
+	case "incoming":
+		if rel.TargetChunkID != query.ChunkID {
+			return false
+		}
+	default:
+		if rel.SourceChunkID != query.ChunkID && rel.TargetChunkID != query.ChunkID {
+			return false
+		}
+	}
+
+	if rel.Confidence < query.MinConfidence {
+		return false
+	}
+
+	if len(query.RelationTypes) == 0 {
+		return true
+	}
+	for _, t := range query.RelationTypes {
+		if rel.RelationType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// TraverseGraph walks outgoing relationships breadth-first up to maxDepth
+func (ks *KeywordStore) TraverseGraph(ctx context.Context, startChunkID string, maxDepth int, relationTypes []types.RelationType) (*types.GraphTraversalResult, error) {
+	ks.mutex.RLock()
+	defer ks.mutex.RUnlock()
+
+	result := &types.GraphTraversalResult{
+		Paths: []types.GraphPath{},
+		Nodes: []types.GraphNode{},
+		Edges: []types.GraphEdge{},
+	}
+
+	depths := map[string]int{startChunkID: 0}
+	order := []string{startChunkID}
+	degrees := make(map[string]int)
+	query := &types.RelationshipQuery{RelationTypes: relationTypes, Direction: "outgoing"}
+
+	for i := 0; i < len(order); i++ {
+		current := order[i]
+		if depths[current] >= maxDepth {
+			continue
+		}
+
+		query.ChunkID = current
+		for _, rel := range ks.relationships {
+			if !relationshipMatches(rel, query) {
+				continue
+			}
+			result.Edges = append(result.Edges, types.GraphEdge{Relationship: *rel, Weight: rel.Confidence})
+			degrees[rel.SourceChunkID]++
+			degrees[rel.TargetChunkID]++
+			if _, seen := depths[rel.TargetChunkID]; !seen {
+				depths[rel.TargetChunkID] = depths[current] + 1
+				order = append(order, rel.TargetChunkID)
+			}
+		}
+	}
+
+	for _, id := range order {
+		node := types.GraphNode{ChunkID: id, Degree: degrees[id]}
+		if chunk, ok := ks.chunks[id]; ok {
+			node.Chunk = &chunk
+		}
+		result.Nodes = append(result.Nodes, node)
+	}
+
+	return result, nil
+}
+
+// UpdateRelationship updates the confidence of a relationship
+func (ks *KeywordStore) UpdateRelationship(ctx context.Context, relationshipID string, confidence float64, factors types.ConfidenceFactors) error {
+	ks.mutex.Lock()
+	defer ks.mutex.Unlock()
+
+	rel, exists := ks.relationships[relationshipID]
+	if !exists {
+		return errors.New("relationship not found")
+	}
+	rel.Confidence = confidence
+	rel.ConfidenceFactors = factors
+	return ks.persistLocked()
+}
+
+// DeleteRelationship removes a relationship
+func (ks *KeywordStore) DeleteRelationship(ctx context.Context, relationshipID string) error {
+	ks.mutex.Lock()
+	defer ks.mutex.Unlock()
+
+	if _, exists := ks.relationships[relationshipID]; !exists {
+		return errors.New("relationship not found")
+	}
+	delete(ks.relationships, relationshipID)
+	return ks.persistLocked()
+}
+
+// GetRelationshipByID returns a relationship by ID
+func (ks *KeywordStore) GetRelationshipByID(ctx context.Context, relationshipID string) (*types.MemoryRelationship, error) {
+	ks.mutex.RLock()
+	defer ks.mutex.RUnlock()
+
+	rel, exists := ks.relationships[relationshipID]
+	if !exists {
+		return nil, errors.New("relationship not found")
+	}
+	return rel, nil
+}
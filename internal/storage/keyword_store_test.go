@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newKeywordChunk(t *testing.T, repo, content string, chunkType types.ChunkType) *types.ConversationChunk {
+	t.Helper()
+	chunk, err := types.NewConversationChunk("session-1", content, chunkType, &types.ChunkMetadata{
+		Repository: repo,
+		Outcome:    types.OutcomeSuccess,
+		Difficulty: types.DifficultySimple,
+	})
+	require.NoError(t, err)
+	return chunk
+}
+
+func TestKeywordStoreSearchRanksByBM25(t *testing.T) {
+	ctx := context.Background()
+	store := NewKeywordStore("")
+
+	best := newKeywordChunk(t, "repo", "postgres connection pool exhausted, raised postgres pool size", types.ChunkTypeSolution)
+	partial := newKeywordChunk(t, "repo", "connection timeout talking to redis", types.ChunkTypeProblem)
+	unrelated := newKeywordChunk(t, "repo", "renamed the frontend build script", types.ChunkTypeDiscussion)
+	for _, c := range []*types.ConversationChunk{best, partial, unrelated} {
+		require.NoError(t, store.Store(ctx, c), "embeddings must not be required")
+	}
+
+	query := types.NewMemoryQuery("postgres connection")
+	query.MinRelevanceScore = 0
+	results, err := store.Search(ctx, query, nil)
+	require.NoError(t, err)
+
+	require.Len(t, results.Results, 2)
+	assert.Equal(t, best.ID, results.Results[0].Chunk.ID)
+	assert.InDelta(t, 1.0, results.Results[0].Score, 1e-9)
+	assert.Equal(t, partial.ID, results.Results[1].Chunk.ID)
+	assert.Less(t, results.Results[1].Score, results.Results[0].Score)
+}
+
+func TestKeywordStoreSearchFilters(t *testing.T) {
+	ctx := context.Background()
+	store := NewKeywordStore("")
+
+	match := newKeywordChunk(t, "repo-a", "cache invalidation bug", types.ChunkTypeProblem)
+	otherRepo := newKeywordChunk(t, "repo-b", "cache invalidation bug", types.ChunkTypeProblem)
+	otherType := newKeywordChunk(t, "repo-a", "cache invalidation fix", types.ChunkTypeSolution)
+	old := newKeywordChunk(t, "repo-a", "cache invalidation bug", types.ChunkTypeProblem)
+	old.Timestamp = time.Now().AddDate(0, -2, 0)
+	for _, c := range []*types.ConversationChunk{match, otherRepo, otherType, old} {
+		require.NoError(t, store.Store(ctx, c))
+	}
+
+	repo := "repo-a"
+	query := types.NewMemoryQuery("cache")
+	query.Repository = &repo
+	query.Types = []types.ChunkType{types.ChunkTypeProblem}
+	query.Recency = types.RecencyLastMonth
+	query.MinRelevanceScore = 0
+
+	results, err := store.Search(ctx, query, nil)
+	require.NoError(t, err)
+	require.Len(t, results.Results, 1)
+	assert.Equal(t, match.ID, results.Results[0].Chunk.ID)
+}
+
+func TestKeywordStoreSearchProvenanceFilter(t *testing.T) {
+	ctx := context.Background()
+	store := NewKeywordStore("")
+
+	fromGit := newKeywordChunk(t, "repo", "cache invalidation bug", types.ChunkTypeProblem)
+	fromGit.Metadata.Provenance = &types.Provenance{SourceSystem: types.SourceSystemGit, CommitSHA: "abc1234"}
+	imported := newKeywordChunk(t, "repo", "cache invalidation bug", types.ChunkTypeProblem)
+	imported.Metadata.Provenance = &types.Provenance{SourceSystem: types.SourceSystemImport}
+	untracked := newKeywordChunk(t, "repo", "cache invalidation bug", types.ChunkTypeProblem)
+	for _, c := range []*types.ConversationChunk{fromGit, imported, untracked} {
+		require.NoError(t, store.Store(ctx, c))
+	}
+
+	query := types.NewMemoryQuery("cache")
+	query.MinRelevanceScore = 0
+	query.Provenance = &types.ProvenanceFilter{SourceSystem: types.SourceSystemGit}
+
+	results, err := store.Search(ctx, query, nil)
+	require.NoError(t, err)
+	require.Len(t, results.Results, 1)
+	assert.Equal(t, fromGit.ID, results.Results[0].Chunk.ID)
+}
+
+func TestKeywordStorePersistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "keyword.json")
+
+	store := NewKeywordStore(path)
+	require.NoError(t, store.Initialize(ctx))
+	a := newKeywordChunk(t, "repo", "first memory", types.ChunkTypeDiscussion)
+	b := newKeywordChunk(t, "repo", "second memory", types.ChunkTypeDiscussion)
+	require.NoError(t, store.Store(ctx, a))
+	require.NoError(t, store.Store(ctx, b))
+	rel, err := store.StoreRelationship(ctx, a.ID, b.ID, types.RelationLedTo, 0.9, types.ConfidenceExplicit)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	reopened := NewKeywordStore(path)
+	require.NoError(t, reopened.Initialize(ctx))
+
+	got, err := reopened.GetByID(ctx, a.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "first memory", got.Content)
+
+	rels, err := reopened.GetRelationships(ctx, types.NewRelationshipQuery(a.ID))
+	require.NoError(t, err)
+	require.Len(t, rels, 1)
+	assert.Equal(t, rel.ID, rels[0].Relationship.ID)
+
+	graph, err := reopened.TraverseGraph(ctx, a.ID, 2, nil)
+	require.NoError(t, err)
+	assert.Len(t, graph.Nodes, 2)
+	assert.Len(t, graph.Edges, 1)
+}
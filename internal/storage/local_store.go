@@ -0,0 +1,313 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/storage/hnsw"
+	"lerian-mcp-memory/pkg/types"
+)
+
+// Files of a LocalVectorStore directory
+const (
+	localChunksFile = "chunks.json"
+	localIndexFile  = "vectors.hnsw"
+)
+
+// LocalVectorStore is an embedded VectorStore for running fully offline. It
+// keeps chunks and relationships like the KeywordStore, in a JSON snapshot,
+// and searches their embeddings with an in-process HNSW index saved beside
+// it. Searches without embeddings fall back to BM25 keyword ranking, so the
+// store also serves lite mode.
+type LocalVectorStore struct {
+	*KeywordStore
+	dir   string
+	index *hnsw.Index
+}
+
+// NewLocalVectorStore creates a local store persisted in dir; an empty dir keeps data in memory only
+func NewLocalVectorStore(dir string) *LocalVectorStore {
+	chunksPath := ""
+	if dir != "" {
+		chunksPath = filepath.Join(dir, localChunksFile)
+	}
+	return &LocalVectorStore{
+		KeywordStore: NewKeywordStore(chunksPath),
+		dir:          dir,
+		index:        hnsw.New(hnsw.DefaultM, hnsw.DefaultEfConstruction),
+	}
+}
+
+// Initialize loads the chunks and the vector index. Chunks are saved on
+// every write but the index only on Close, so an index that is missing,
+// unreadable, older than the chunks or holding other chunks, as after a
+// crash, is rebuilt from the chunks' embeddings.
+func (ls *LocalVectorStore) Initialize(ctx context.Context) error {
+	if err := ls.KeywordStore.Initialize(ctx); err != nil {
+		return err
+	}
+	if ls.dir == "" {
+		return nil
+	}
+
+	index, err := hnsw.LoadFile(ls.indexPath())
+	switch {
+	case err == nil && !ls.indexOutdated() && ls.indexMatchesChunks(index):
+		ls.index = index
+		logging.Info("Loaded local vector index", "vectors", index.Len(), "path", ls.indexPath())
+		return nil
+	case err != nil && !errors.Is(err, os.ErrNotExist):
+		logging.Warn("Rebuilding unreadable local vector index", "path", ls.indexPath(), "error", err)
+	case err == nil:
+		logging.Warn("Rebuilding local vector index that is out of step with the stored chunks", "path", ls.indexPath())
+	}
+	return ls.rebuildIndex()
+}
+
+// indexPath is where the vector index is saved
+func (ls *LocalVectorStore) indexPath() string {
+	return filepath.Join(ls.dir, localIndexFile)
+}
+
+// indexOutdated reports whether chunks were saved after the index
+func (ls *LocalVectorStore) indexOutdated() bool {
+	chunksInfo, err := os.Stat(ls.path)
+	if err != nil {
+		return false // No chunks saved yet
+	}
+	indexInfo, err := os.Stat(ls.indexPath())
+	return err != nil || indexInfo.ModTime().Before(chunksInfo.ModTime())
+}
+
+// indexMatchesChunks reports whether index holds exactly the stored chunks with embeddings
+func (ls *LocalVectorStore) indexMatchesChunks(index *hnsw.Index) bool {
+	ls.mutex.RLock()
+	defer ls.mutex.RUnlock()
+	indexed := 0
+	for id := range ls.chunks {
+		if len(ls.chunks[id].Embeddings) == 0 {
+			continue
+		}
+		if !index.Has(id) {
+			return false
+		}
+		indexed++
+	}
+	return indexed == index.Len()
+}
+
+// rebuildIndex indexes the embeddings of every stored chunk and saves the
+// index. Chunks whose embeddings cannot be indexed stay searchable by keyword.
+func (ls *LocalVectorStore) rebuildIndex() error {
+	index := hnsw.New(hnsw.DefaultM, hnsw.DefaultEfConstruction)
+	ls.mutex.RLock()
+	for id := range ls.chunks {
+		if embeddings := ls.chunks[id].Embeddings; len(embeddings) > 0 {
+			if err := index.Add(id, embeddings); err != nil {
+				logging.Warn("Skipping chunk the local vector index cannot hold", "id", id, "error", err)
+			}
+		}
+	}
+	ls.mutex.RUnlock()
+
+	ls.index = index
+	logging.Info("Rebuilt local vector index", "vectors", index.Len())
+	return ls.saveIndex()
+}
+
+// saveIndex writes the vector index to disk, first dropping tombstones once
+// they make up a fifth of the graph
+func (ls *LocalVectorStore) saveIndex() error {
+	if ls.dir == "" {
+		return nil
+	}
+	if ls.index.Tombstones()*4 > ls.index.Len() {
+		ls.index.Compact()
+	}
+	if err := ls.index.SaveFile(ls.indexPath()); err != nil {
+		return fmt.Errorf("failed to save local vector index: %w", err)
+	}
+	return nil
+}
+
+// indexChunk brings the chunk's vector in the index up to date
+func (ls *LocalVectorStore) indexChunk(chunk *types.ConversationChunk) error {
+	if len(chunk.Embeddings) == 0 {
+		ls.index.Remove(chunk.ID)
+		return nil
+	}
+	return ls.index.Add(chunk.ID, chunk.Embeddings)
+}
+
+// checkDimension rejects embeddings of a different size than those indexed
+func (ls *LocalVectorStore) checkDimension(chunk *types.ConversationChunk) error {
+	if dimension := ls.index.Dimension(); dimension != 0 && len(chunk.Embeddings) != 0 && len(chunk.Embeddings) != dimension {
+		return fmt.Errorf("chunk has %d-dimensional embeddings, the local vector index stores %d", len(chunk.Embeddings), dimension)
+	}
+	return nil
+}
+
+// Store stores a chunk and indexes its embeddings, if it has any
+func (ls *LocalVectorStore) Store(ctx context.Context, chunk *types.ConversationChunk) error {
+	if err := ls.checkDimension(chunk); err != nil {
+		return err
+	}
+	if err := ls.KeywordStore.Store(ctx, chunk); err != nil {
+		return err
+	}
+	return ls.indexChunk(chunk)
+}
+
+// StoreChunk is an alias for Store
+func (ls *LocalVectorStore) StoreChunk(ctx context.Context, chunk *types.ConversationChunk) error {
+	return ls.Store(ctx, chunk)
+}
+
+// Update replaces an existing chunk and its vector
+func (ls *LocalVectorStore) Update(ctx context.Context, chunk *types.ConversationChunk) error {
+	if err := ls.checkDimension(chunk); err != nil {
+		return err
+	}
+	if err := ls.KeywordStore.Update(ctx, chunk); err != nil {
+		return err
+	}
+	return ls.indexChunk(chunk)
+}
+
+// Delete removes a chunk and its vector
+func (ls *LocalVectorStore) Delete(ctx context.Context, id string) error {
+	if err := ls.KeywordStore.Delete(ctx, id); err != nil {
+		return err
+	}
+	ls.index.Remove(id)
+	return nil
+}
+
+// BatchStore stores multiple chunks
+func (ls *LocalVectorStore) BatchStore(ctx context.Context, chunks []*types.ConversationChunk) (*BatchResult, error) {
+	result := &BatchResult{Errors: []string{}, ProcessedIDs: []string{}}
+	for _, chunk := range chunks {
+		if err := ls.Store(ctx, chunk); err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, err.Error())
+		} else {
+			result.Success++
+		}
+		result.ProcessedIDs = append(result.ProcessedIDs, chunk.ID)
+	}
+	return result, nil
+}
+
+// BatchDelete deletes multiple chunks
+func (ls *LocalVectorStore) BatchDelete(ctx context.Context, ids []string) (*BatchResult, error) {
+	result := &BatchResult{Errors: []string{}, ProcessedIDs: ids}
+	for _, id := range ids {
+		if err := ls.Delete(ctx, id); err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, err.Error())
+		} else {
+			result.Success++
+		}
+	}
+	return result, nil
+}
+
+// Cleanup deletes chunks older than the retention period and their vectors
+func (ls *LocalVectorStore) Cleanup(ctx context.Context, retentionDays int) (int, error) {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+
+	cutoff := time.Now().AddDate(0, 0, -retentionDays)
+	deleted := 0
+	for id := range ls.chunks {
+		if ls.chunks[id].Timestamp.Before(cutoff) {
+			delete(ls.chunks, id)
+			ls.index.Remove(id)
+			deleted++
+		}
+	}
+
+	if deleted == 0 {
+		return 0, nil
+	}
+	return deleted, ls.persistLocked()
+}
+
+// DeleteCollection removes all chunks, relationships and vectors
+func (ls *LocalVectorStore) DeleteCollection(ctx context.Context, collection string) error {
+	if err := ls.KeywordStore.DeleteCollection(ctx, collection); err != nil {
+		return err
+	}
+	for _, id := range ls.index.IDs() {
+		ls.index.Remove(id)
+	}
+	return ls.saveIndex()
+}
+
+// ListCollections returns the single collection backing the store
+func (ls *LocalVectorStore) ListCollections(ctx context.Context) ([]string, error) {
+	return []string{"local"}, nil
+}
+
+// Search ranks chunks matching the query filters by cosine similarity to
+// embeddings, or by BM25 keyword score when no embeddings are given
+func (ls *LocalVectorStore) Search(ctx context.Context, query *types.MemoryQuery, embeddings []float64) (*types.SearchResults, error) {
+	if len(embeddings) == 0 {
+		return ls.KeywordStore.Search(ctx, query, nil)
+	}
+	start := time.Now()
+
+	ls.mutex.RLock()
+	defer ls.mutex.RUnlock()
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = len(ls.chunks)
+	}
+	found, err := ls.index.Search(embeddings, limit, hnsw.DefaultEfSearch, func(id string) bool {
+		chunk, ok := ls.chunks[id]
+		return ok && matchesQueryFilters(&chunk, query)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search local vector index: %w", err)
+	}
+
+	results := make([]types.SearchResult, 0, len(found))
+	for _, hit := range found {
+		score := float64(hit.Similarity)
+		if score < query.MinRelevanceScore {
+			continue
+		}
+		results = append(results, types.SearchResult{Chunk: ls.chunks[hit.ID], Score: score})
+	}
+
+	return &types.SearchResults{
+		Results:   results,
+		Total:     len(results),
+		QueryTime: time.Since(start),
+	}, nil
+}
+
+// GetStats returns the keyword store's statistics and the vector size
+func (ls *LocalVectorStore) GetStats(ctx context.Context) (*StoreStats, error) {
+	stats, err := ls.KeywordStore.GetStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats.AverageEmbedding = float64(ls.index.Dimension())
+	return stats, nil
+}
+
+// Close saves the chunks and then the vector index, so a cleanly closed
+// index is never older than the chunks
+func (ls *LocalVectorStore) Close() error {
+	if err := ls.KeywordStore.Close(); err != nil {
+		return err
+	}
+	return ls.saveIndex()
+}
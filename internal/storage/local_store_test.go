@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLocalChunk(t *testing.T, repo, content string, embeddings []float64) *types.ConversationChunk {
+	t.Helper()
+	chunk := newKeywordChunk(t, repo, content, types.ChunkTypeDiscussion)
+	chunk.Embeddings = embeddings
+	return chunk
+}
+
+func TestLocalVectorStoreSearch(t *testing.T) {
+	ctx := context.Background()
+	store := NewLocalVectorStore("")
+	require.NoError(t, store.Initialize(ctx))
+
+	north := newLocalChunk(t, "repo-a", "pointing north", []float64{0, 1, 0})
+	east := newLocalChunk(t, "repo-a", "pointing east", []float64{1, 0, 0})
+	northEast := newLocalChunk(t, "repo-b", "pointing north east", []float64{0.7, 0.7, 0})
+	for _, c := range []*types.ConversationChunk{north, east, northEast} {
+		require.NoError(t, store.Store(ctx, c))
+	}
+
+	query := types.NewMemoryQuery("direction")
+	query.MinRelevanceScore = 0
+	results, err := store.Search(ctx, query, []float64{0.1, 1, 0})
+	require.NoError(t, err)
+	assert.Equal(t, []string{north.ID, northEast.ID, east.ID}, resultIDs(results), "chunks are ranked by cosine similarity")
+	assert.InDelta(t, 0.995, results.Results[0].Score, 0.001)
+
+	repo := "repo-a"
+	query.Repository = &repo
+	query.MinRelevanceScore = 0.5
+	results, err = store.Search(ctx, query, []float64{0.1, 1, 0})
+	require.NoError(t, err)
+	assert.Equal(t, []string{north.ID}, resultIDs(results), "filters and the relevance threshold apply to vector results")
+
+	keyword := types.NewMemoryQuery("east")
+	results, err = store.Search(ctx, keyword, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{east.ID, northEast.ID}, resultIDs(results), "searches without embeddings rank by keyword")
+
+	assert.ErrorContains(t, store.Store(ctx, newLocalChunk(t, "repo-a", "wrong size", []float64{1, 0})), "the local vector index stores 3")
+	require.NoError(t, store.Delete(ctx, north.ID))
+	query.Repository = nil
+	query.MinRelevanceScore = 0
+	results, err = store.Search(ctx, query, []float64{0, 1, 0})
+	require.NoError(t, err)
+	assert.NotContains(t, resultIDs(results), north.ID, "deleted chunks leave the index")
+}
+
+func TestLocalVectorStorePersistence(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	query := types.NewMemoryQuery("anything")
+	query.MinRelevanceScore = 0
+
+	store := NewLocalVectorStore(dir)
+	require.NoError(t, store.Initialize(ctx))
+	first := newLocalChunk(t, "repo", "first", []float64{1, 0})
+	require.NoError(t, store.Store(ctx, first))
+	require.NoError(t, store.Close())
+	assert.FileExists(t, filepath.Join(dir, localIndexFile))
+
+	reopened := NewLocalVectorStore(dir)
+	require.NoError(t, reopened.Initialize(ctx))
+	assert.Equal(t, 1, reopened.index.Len())
+
+	// A chunk stored without a clean close is indexed again on the next start
+	second := newLocalChunk(t, "repo", "second", []float64{0, 1})
+	require.NoError(t, reopened.Store(ctx, second))
+	recovered := NewLocalVectorStore(dir)
+	require.NoError(t, recovered.Initialize(ctx))
+	results, err := recovered.Search(ctx, query, []float64{0, 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{second.ID, first.ID}, resultIDs(results))
+
+	// An unreadable index is rebuilt from the chunks
+	require.NoError(t, os.WriteFile(filepath.Join(dir, localIndexFile), []byte("garbage"), 0o600))
+	rebuilt := NewLocalVectorStore(dir)
+	require.NoError(t, rebuilt.Initialize(ctx))
+	assert.Equal(t, 2, rebuilt.index.Len())
+}
+
+// resultIDs returns the chunk IDs of search results in order
+func resultIDs(results *types.SearchResults) []string {
+	ids := make([]string, 0, len(results.Results))
+	for i := range results.Results {
+		ids = append(ids, results.Results[i].Chunk.ID)
+	}
+	return ids
+}
@@ -66,6 +66,17 @@ func (m *SimpleMockVectorStore) Search(ctx context.Context, query *types.MemoryQ
 	results := make([]types.SearchResult, 0, capacity)
 	for chunkID := range m.chunks {
 		chunk := m.chunks[chunkID]
+		// Skip soft-deleted chunks
+		if chunk.IsDeleted() && !query.IncludeDeleted {
+			continue
+		}
+		if !query.Provenance.Matches(chunk.Metadata.Provenance) {
+			continue
+		}
+		if len(query.Classes) > 0 && !types.HasMemoryClass(query.Classes, chunk.Class()) {
+			continue
+		}
+
 		// Apply repository filter
 		if query.Repository != nil && chunk.Metadata.Repository != *query.Repository {
 			continue
@@ -119,13 +130,23 @@ func (m *SimpleMockVectorStore) GetByID(ctx context.Context, id string) (*types.
 	return &chunk, nil
 }
 
+func (m *SimpleMockVectorStore) GetByIDs(ctx context.Context, ids []string) ([]types.ConversationChunk, error) {
+	byID := make(map[string]*types.ConversationChunk, len(ids))
+	for _, id := range ids {
+		if chunk, exists := m.chunks[id]; exists {
+			byID[id] = &chunk
+		}
+	}
+	return orderChunksByID(ids, byID), nil
+}
+
 func (m *SimpleMockVectorStore) ListByRepository(ctx context.Context, repository string, limit, offset int) ([]types.ConversationChunk, error) {
 	results := make([]types.ConversationChunk, 0, limit)
 	count := 0
 
 	for chunkID := range m.chunks {
 		chunk := m.chunks[chunkID]
-		if chunk.Metadata.Repository == repository {
+		if chunk.Metadata.Repository == repository && listable(ctx, &chunk) {
 			if count >= offset {
 				results = append(results, chunk)
 				if len(results) >= limit {
@@ -144,7 +165,7 @@ func (m *SimpleMockVectorStore) ListBySession(ctx context.Context, sessionID str
 
 	for chunkID := range m.chunks {
 		chunk := m.chunks[chunkID]
-		if chunk.SessionID == sessionID {
+		if chunk.SessionID == sessionID && listable(ctx, &chunk) {
 			results = append(results, chunk)
 		}
 	}
@@ -191,7 +212,10 @@ func (m *SimpleMockVectorStore) Close() error {
 func (m *SimpleMockVectorStore) GetAllChunks(ctx context.Context) ([]types.ConversationChunk, error) {
 	results := make([]types.ConversationChunk, 0, len(m.chunks))
 	for chunkID := range m.chunks {
-		results = append(results, m.chunks[chunkID])
+		chunk := m.chunks[chunkID]
+		if listable(ctx, &chunk) {
+			results = append(results, chunk)
+		}
 	}
 	return results, nil
 }
@@ -0,0 +1,465 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/google/uuid"
+)
+
+// passageSearchFactor widens passage searches so several passages of one
+// parent do not crowd other chunks out of the requested limit
+const passageSearchFactor = 3
+
+// passageListBatch is the smallest page read while listing around passages
+const passageListBatch = 100
+
+// passageBreaks are the boundaries a passage prefers to end at, strongest first
+var passageBreaks = []string{"\n\n", "\n", ". ", " "}
+
+// PassageEmbedder generates the vectors of passages
+type PassageEmbedder interface {
+	GenerateBatchEmbeddings(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// PassageStore wraps a VectorStore so chunks longer than the embedding window
+// are retrievable by any of their sections. Such a chunk is stored as usual
+// and is also split into overlapping passages, each stored as a chunk with
+// its own vector, the parent's metadata and a ParentChunkID. Searches fold
+// passage hits into their parent, scored by its best hit, and listings leave
+// passages out. Updating or deleting a parent rewrites or removes its
+// passages; passages whose text did not change keep their vectors.
+type PassageStore struct {
+	VectorStore
+
+	embedder PassageEmbedder
+	size     int
+	overlap  int
+}
+
+// NewPassageStore splits chunks longer than size runes into passages of at
+// most size runes, each repeating the last overlap runes of the one before
+func NewPassageStore(store VectorStore, embedder PassageEmbedder, size, overlap int) *PassageStore {
+	return &PassageStore{VectorStore: store, embedder: embedder, size: size, overlap: overlap}
+}
+
+// PassageID returns the ID of a chunk's passage. IDs are derived from the
+// parent so passages can be found and replaced without a lookup.
+func PassageID(parentID string, index int) string {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte("passage:"+parentID+":"+strconv.Itoa(index))).String()
+}
+
+// SplitPassages splits content into passages of at most size runes that
+// overlap by about overlap runes, breaking at paragraphs, lines, sentences or
+// words where it can. Content that fits in size returns nil.
+func SplitPassages(content string, size, overlap int) []string {
+	runes := []rune(content)
+	if size <= 0 || len(runes) <= size {
+		return nil
+	}
+	if overlap < 0 || overlap >= size {
+		overlap = 0
+	}
+
+	var passages []string
+	for start := 0; start < len(runes); {
+		end := start + size
+		if end >= len(runes) {
+			end = len(runes)
+		} else {
+			end = passageBreak(runes, start, end)
+		}
+		if passage := strings.TrimSpace(string(runes[start:end])); passage != "" {
+			passages = append(passages, passage)
+		}
+		if end == len(runes) {
+			break
+		}
+
+		// Start the next passage inside the overlap, at a word boundary
+		next := end - overlap
+		for next > start && next < end && !isSpace(runes[next-1]) {
+			next++
+		}
+		if next <= start || next >= end {
+			next = end
+		}
+		start = next
+	}
+	return passages
+}
+
+// passageBreak moves end back to the strongest boundary in the second half of the passage
+func passageBreak(runes []rune, start, end int) int {
+	earliest := start + (end-start)/2
+	for _, boundary := range passageBreaks {
+		b := []rune(boundary)
+		for i := end - len(b); i >= earliest; i-- {
+			if string(runes[i:i+len(b)]) == boundary {
+				return i + len(b)
+			}
+		}
+	}
+	return end
+}
+
+// isSpace reports whether r separates words
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\n' || r == '\t' || r == '\r'
+}
+
+// preparePassages builds the passages of chunk and records their number on it.
+// Passages whose text is unchanged since the stored version reuse their
+// vectors; the rest are embedded in one batch. The IDs of passages the
+// stored version had beyond the new count are returned as stale.
+func (s *PassageStore) preparePassages(ctx context.Context, chunk *types.ConversationChunk) (passages []*types.ConversationChunk, stale []string, err error) {
+	texts := SplitPassages(chunk.Content, s.size, s.overlap)
+
+	previousCount := 0
+	if previous, err := s.VectorStore.GetByID(ctx, chunk.ID); err == nil && previous != nil {
+		previousCount = previous.Metadata.PassageCount
+	}
+	for i := len(texts); i < previousCount; i++ {
+		stale = append(stale, PassageID(chunk.ID, i))
+	}
+	chunk.Metadata.PassageCount = len(texts)
+	if len(texts) == 0 {
+		return nil, stale, nil
+	}
+
+	vectors := make(map[string][]float64)
+	if reusable := min(len(texts), previousCount); reusable > 0 {
+		ids := make([]string, reusable)
+		for i := range ids {
+			ids[i] = PassageID(chunk.ID, i)
+		}
+		if existing, err := s.VectorStore.GetByIDs(ctx, ids); err == nil {
+			for i := range existing {
+				if len(existing[i].Embeddings) > 0 {
+					vectors[existing[i].ID+"\x00"+existing[i].Content] = existing[i].Embeddings
+				}
+			}
+		}
+	}
+
+	passages = make([]*types.ConversationChunk, len(texts))
+	var missing []int
+	for i, text := range texts {
+		passage := *chunk
+		passage.ID = PassageID(chunk.ID, i)
+		passage.Content = text
+		passage.Summary = ""
+		passage.RelatedChunks = nil
+		passage.Metadata.ExtendedMetadata = nil
+		passage.Metadata.ParentChunkID = chunk.ID
+		passage.Metadata.PassageIndex = i
+		passage.Metadata.PassageCount = 0
+		passage.Embeddings = vectors[passage.ID+"\x00"+text]
+		if len(passage.Embeddings) == 0 {
+			missing = append(missing, i)
+		}
+		passages[i] = &passage
+	}
+
+	if len(missing) > 0 {
+		inputs := make([]string, len(missing))
+		for i, index := range missing {
+			inputs[i] = texts[index]
+		}
+		embedded, err := s.embedder.GenerateBatchEmbeddings(ctx, inputs)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to embed passages: %w", err)
+		}
+		if len(embedded) != len(missing) {
+			return nil, nil, fmt.Errorf("failed to embed passages: got %d vectors for %d passages", len(embedded), len(missing))
+		}
+		for i, index := range missing {
+			passages[index].Embeddings = embedded[i]
+		}
+	}
+	return passages, stale, nil
+}
+
+// writePassages stores passages and removes stale ones
+func (s *PassageStore) writePassages(ctx context.Context, passages []*types.ConversationChunk, stale []string) error {
+	if len(passages) > 0 {
+		result, err := s.VectorStore.BatchStore(ctx, passages)
+		if err != nil {
+			return fmt.Errorf("failed to store passages: %w", err)
+		}
+		if result != nil && result.Failed > 0 {
+			return fmt.Errorf("failed to store %d passages: %s", result.Failed, strings.Join(result.Errors, "; "))
+		}
+	}
+	if len(stale) > 0 {
+		if _, err := s.VectorStore.BatchDelete(ctx, stale); err != nil {
+			return fmt.Errorf("failed to remove stale passages: %w", err)
+		}
+	}
+	return nil
+}
+
+// Store stores a chunk and its passages
+func (s *PassageStore) Store(ctx context.Context, chunk *types.ConversationChunk) error {
+	if chunk.IsPassage() {
+		return s.VectorStore.Store(ctx, chunk)
+	}
+	passages, stale, err := s.preparePassages(ctx, chunk)
+	if err != nil {
+		return err
+	}
+	if err := s.VectorStore.Store(ctx, chunk); err != nil {
+		return err
+	}
+	return s.writePassages(ctx, passages, stale)
+}
+
+// StoreChunk is an alias for Store
+func (s *PassageStore) StoreChunk(ctx context.Context, chunk *types.ConversationChunk) error {
+	return s.Store(ctx, chunk)
+}
+
+// Update updates a chunk and rewrites its passages
+func (s *PassageStore) Update(ctx context.Context, chunk *types.ConversationChunk) error {
+	if chunk.IsPassage() {
+		return s.VectorStore.Update(ctx, chunk)
+	}
+	passages, stale, err := s.preparePassages(ctx, chunk)
+	if err != nil {
+		return err
+	}
+	if err := s.VectorStore.Update(ctx, chunk); err != nil {
+		return err
+	}
+	return s.writePassages(ctx, passages, stale)
+}
+
+// BatchStore stores chunks and their passages. A chunk whose passages cannot
+// be embedded is counted as failed and not stored.
+func (s *PassageStore) BatchStore(ctx context.Context, chunks []*types.ConversationChunk) (*BatchResult, error) {
+	failed := &BatchResult{}
+	parents := make([]*types.ConversationChunk, 0, len(chunks))
+	var passages []*types.ConversationChunk
+	var stale []string
+	for _, chunk := range chunks {
+		if chunk.IsPassage() {
+			parents = append(parents, chunk)
+			continue
+		}
+		chunkPassages, chunkStale, err := s.preparePassages(ctx, chunk)
+		if err != nil {
+			failed.Failed++
+			failed.Errors = append(failed.Errors, fmt.Sprintf("chunk %s: %v", chunk.ID, err))
+			continue
+		}
+		parents = append(parents, chunk)
+		passages = append(passages, chunkPassages...)
+		stale = append(stale, chunkStale...)
+	}
+
+	result, err := s.VectorStore.BatchStore(ctx, parents)
+	if err != nil {
+		return nil, err
+	}
+	result.Failed += failed.Failed
+	result.Errors = append(result.Errors, failed.Errors...)
+	if err := s.writePassages(ctx, passages, stale); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// passageIDsOf returns the IDs of the passages of the given chunks
+func (s *PassageStore) passageIDsOf(ctx context.Context, ids []string) []string {
+	chunks, err := s.VectorStore.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil
+	}
+	var passageIDs []string
+	for i := range chunks {
+		for index := 0; index < chunks[i].Metadata.PassageCount; index++ {
+			passageIDs = append(passageIDs, PassageID(chunks[i].ID, index))
+		}
+	}
+	return passageIDs
+}
+
+// Delete removes a chunk and its passages
+func (s *PassageStore) Delete(ctx context.Context, id string) error {
+	passageIDs := s.passageIDsOf(ctx, []string{id})
+	if err := s.VectorStore.Delete(ctx, id); err != nil {
+		return err
+	}
+	if len(passageIDs) > 0 {
+		if _, err := s.VectorStore.BatchDelete(ctx, passageIDs); err != nil {
+			return fmt.Errorf("failed to remove passages: %w", err)
+		}
+	}
+	return nil
+}
+
+// BatchDelete removes chunks and their passages
+func (s *PassageStore) BatchDelete(ctx context.Context, ids []string) (*BatchResult, error) {
+	passageIDs := s.passageIDsOf(ctx, ids)
+	result, err := s.VectorStore.BatchDelete(ctx, ids)
+	if err != nil {
+		return result, err
+	}
+	if len(passageIDs) > 0 {
+		if _, err := s.VectorStore.BatchDelete(ctx, passageIDs); err != nil {
+			return result, fmt.Errorf("failed to remove passages: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// Search finds chunks by vector similarity, counting a passage hit as a hit
+// on its parent. Each parent appears once, scored by its best hit.
+func (s *PassageStore) Search(ctx context.Context, query *types.MemoryQuery, embeddings []float64) (*types.SearchResults, error) {
+	widened := *query
+	if widened.Limit > 0 {
+		widened.Limit *= passageSearchFactor
+	}
+	results, err := s.VectorStore.Search(ctx, &widened, embeddings)
+	if err != nil || results == nil {
+		return results, err
+	}
+
+	merged := make([]types.SearchResult, 0, len(results.Results))
+	byParent := make(map[string]int, len(results.Results))
+	// Parents seen only through their passages, loaded below
+	pending := make(map[string]bool)
+	for _, result := range results.Results {
+		id := result.Chunk.ID
+		if result.Chunk.IsPassage() {
+			id = result.Chunk.Metadata.ParentChunkID
+		}
+		if i, ok := byParent[id]; ok {
+			if !result.Chunk.IsPassage() {
+				merged[i].Chunk = result.Chunk
+				delete(pending, id)
+			}
+			merged[i].Score = max(merged[i].Score, result.Score)
+			continue
+		}
+		byParent[id] = len(merged)
+		if result.Chunk.IsPassage() {
+			pending[id] = true
+			result.Highlight = nil
+		}
+		merged = append(merged, result)
+	}
+
+	if len(pending) > 0 {
+		ids := make([]string, 0, len(pending))
+		for id := range pending {
+			ids = append(ids, id)
+		}
+		parents, err := s.VectorStore.GetByIDs(ctx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load passage parents: %w", err)
+		}
+		for i := range parents {
+			if parents[i].IsDeleted() && !query.IncludeDeleted {
+				continue
+			}
+			merged[byParent[parents[i].ID]].Chunk = parents[i]
+			delete(pending, parents[i].ID)
+		}
+	}
+
+	// Drop passages whose parent is gone, then rank by best hit
+	kept := merged[:0]
+	for _, result := range merged {
+		if !result.Chunk.IsPassage() || !pending[result.Chunk.Metadata.ParentChunkID] {
+			kept = append(kept, result)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].Score > kept[j].Score })
+	if query.Limit > 0 && len(kept) > query.Limit {
+		kept = kept[:query.Limit]
+	}
+	results.Results = kept
+	results.Total = len(kept)
+	return results, nil
+}
+
+// withoutPassages drops passages from chunks in place
+func withoutPassages(chunks []types.ConversationChunk) []types.ConversationChunk {
+	kept := chunks[:0]
+	for i := range chunks {
+		if !chunks[i].IsPassage() {
+			kept = append(kept, chunks[i])
+		}
+	}
+	return kept
+}
+
+// ListByRepository lists a repository's chunks without passages. Passages
+// sit between their parents in the underlying listing, so it is read in
+// pages until the requested window of parents is filled.
+func (s *PassageStore) ListByRepository(ctx context.Context, repository string, limit, offset int) ([]types.ConversationChunk, error) {
+	batch := max(limit+offset, passageListBatch)
+	var chunks []types.ConversationChunk
+	skipped := 0
+	for innerOffset := 0; ; innerOffset += batch {
+		page, err := s.VectorStore.ListByRepository(ctx, repository, batch, innerOffset)
+		if err != nil {
+			return nil, err
+		}
+		for _, chunk := range withoutPassages(page) {
+			if skipped < offset {
+				skipped++
+				continue
+			}
+			chunks = append(chunks, chunk)
+			if limit > 0 && len(chunks) == limit {
+				return chunks, nil
+			}
+		}
+		if len(page) < batch {
+			return chunks, nil
+		}
+	}
+}
+
+// ListBySession lists a session's chunks without passages
+func (s *PassageStore) ListBySession(ctx context.Context, sessionID string) ([]types.ConversationChunk, error) {
+	chunks, err := s.VectorStore.ListBySession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return withoutPassages(chunks), nil
+}
+
+// GetAllChunks returns every chunk without passages, which are rebuilt when
+// the chunks are stored again
+func (s *PassageStore) GetAllChunks(ctx context.Context) ([]types.ConversationChunk, error) {
+	chunks, err := s.VectorStore.GetAllChunks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return withoutPassages(chunks), nil
+}
+
+// FindSimilar finds similar chunks without passages
+func (s *PassageStore) FindSimilar(ctx context.Context, content string, chunkType *types.ChunkType, limit int) ([]types.ConversationChunk, error) {
+	innerLimit := limit
+	if limit > 0 {
+		innerLimit = limit * passageSearchFactor
+	}
+	chunks, err := s.VectorStore.FindSimilar(ctx, content, chunkType, innerLimit)
+	if err != nil {
+		return nil, err
+	}
+	chunks = withoutPassages(chunks)
+	if limit > 0 && len(chunks) > limit {
+		chunks = chunks[:limit]
+	}
+	return chunks, nil
+}
@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingEmbedder returns fixed vectors and counts the texts it embeds
+type countingEmbedder struct {
+	embedded int
+}
+
+func (e *countingEmbedder) GenerateBatchEmbeddings(_ context.Context, texts []string) ([][]float64, error) {
+	e.embedded += len(texts)
+	vectors := make([][]float64, len(texts))
+	for i := range vectors {
+		vectors[i] = []float64{0.3, 0.4}
+	}
+	return vectors, nil
+}
+
+// designDoc is a long document whose sections cover different topics
+func designDoc() string {
+	sections := []string{
+		"Overview of the billing service and its owners.",
+		"Storage uses Postgres with logical replication to the warehouse.",
+		"Retries back off exponentially and give up after five attempts.",
+		"Invoices are rendered as PDF by a headless browser worker.",
+	}
+	var b strings.Builder
+	for _, section := range sections {
+		b.WriteString(section)
+		b.WriteString(" ")
+		b.WriteString(strings.Repeat("filler text ", 8))
+		b.WriteString("\n\n")
+	}
+	return b.String()
+}
+
+func TestSplitPassages(t *testing.T) {
+	content := designDoc()
+	passages := SplitPassages(content, 160, 30)
+	require.Greater(t, len(passages), 2)
+	for _, passage := range passages {
+		assert.LessOrEqual(t, len([]rune(passage)), 160)
+		assert.NotEmpty(t, passage)
+	}
+	assert.True(t, strings.HasPrefix(passages[0], "Overview"), "passages break at paragraphs")
+	assert.Contains(t, strings.Join(passages, " "), "headless browser worker")
+
+	assert.Nil(t, SplitPassages("short", 160, 30), "content within the window is not split")
+	assert.Nil(t, SplitPassages(content, 0, 0), "size 0 disables passages")
+}
+
+func TestPassageStore_StoresAndFoldsPassages(t *testing.T) {
+	ctx := context.Background()
+	inner := NewKeywordStore("")
+	embedder := &countingEmbedder{}
+	store := NewPassageStore(inner, embedder, 160, 30)
+
+	doc := newQueuedChunk("doc")
+	doc.Content = designDoc()
+	doc.Metadata.Repository = "github.com/acme/billing"
+	require.NoError(t, store.Store(ctx, doc))
+	note := newQueuedChunk("note")
+	note.Metadata.Repository = "github.com/acme/billing"
+	require.NoError(t, store.Store(ctx, note))
+
+	count := doc.Metadata.PassageCount
+	require.Greater(t, count, 2)
+	assert.Equal(t, count, embedder.embedded)
+	passage, err := inner.GetByID(ctx, PassageID("doc", count-1))
+	require.NoError(t, err)
+	assert.Equal(t, "doc", passage.Metadata.ParentChunkID)
+	assert.Equal(t, "github.com/acme/billing", passage.Metadata.Repository, "passages share the parent's metadata so filters apply")
+
+	// A hit on any section returns the parent, once
+	results, err := store.Search(ctx, &types.MemoryQuery{Query: "headless browser", Limit: 5}, nil)
+	require.NoError(t, err)
+	require.Len(t, results.Results, 1)
+	assert.Equal(t, "doc", results.Results[0].Chunk.ID)
+	assert.Equal(t, doc.Content, results.Results[0].Chunk.Content)
+
+	// Listings leave passages out
+	listed, err := store.ListByRepository(ctx, "github.com/acme/billing", 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, listed, 2)
+	listed, err = store.ListByRepository(ctx, "github.com/acme/billing", 1, 1)
+	require.NoError(t, err)
+	assert.Len(t, listed, 1)
+	all, err := store.GetAllChunks(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	// Metadata changes keep the passage vectors; shorter content drops stale passages
+	doc.Metadata.Tags = []string{"billing"}
+	require.NoError(t, store.Update(ctx, doc))
+	assert.Equal(t, count, embedder.embedded)
+	passage, err = inner.GetByID(ctx, PassageID("doc", 0))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"billing"}, passage.Metadata.Tags)
+
+	doc.Content = strings.Repeat("shorter text ", 20)
+	require.NoError(t, store.Update(ctx, doc))
+	_, err = inner.GetByID(ctx, PassageID("doc", count-1))
+	assert.Error(t, err)
+
+	// Deleting the parent deletes its passages
+	require.NoError(t, store.Delete(ctx, "doc"))
+	remaining, err := inner.GetAllChunks(ctx)
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, "note", remaining[0].ID)
+}
+
+// passageHitStore answers searches with fixed results
+type passageHitStore struct {
+	VectorStore
+	results []types.SearchResult
+}
+
+func (s *passageHitStore) Search(context.Context, *types.MemoryQuery, []float64) (*types.SearchResults, error) {
+	return &types.SearchResults{Results: s.results, Total: len(s.results)}, nil
+}
+
+func TestPassageStore_SearchScoresParentByBestPassage(t *testing.T) {
+	ctx := context.Background()
+	inner := NewKeywordStore("")
+	parent := newQueuedChunk("parent")
+	parent.Metadata.PassageCount = 2
+	require.NoError(t, inner.Store(ctx, parent))
+	other := newQueuedChunk("other")
+	require.NoError(t, inner.Store(ctx, other))
+
+	passageOf := func(parentID string, index int) types.ConversationChunk {
+		chunk := *newQueuedChunk(PassageID(parentID, index))
+		chunk.Metadata.ParentChunkID = parentID
+		chunk.Metadata.PassageIndex = index
+		return chunk
+	}
+	hits := &passageHitStore{VectorStore: inner, results: []types.SearchResult{
+		{Chunk: passageOf("parent", 1), Score: 0.91},
+		{Chunk: *other, Score: 0.8},
+		{Chunk: passageOf("parent", 0), Score: 0.7},
+		{Chunk: passageOf("gone", 0), Score: 0.95},
+	}}
+	store := NewPassageStore(hits, &countingEmbedder{}, 160, 30)
+
+	results, err := store.Search(ctx, &types.MemoryQuery{Limit: 5}, []float64{0.3, 0.4})
+	require.NoError(t, err)
+	require.Len(t, results.Results, 2, "passages of missing parents are dropped")
+	assert.Equal(t, "parent", results.Results[0].Chunk.ID)
+	assert.InDelta(t, 0.91, results.Results[0].Score, 1e-9)
+	assert.Equal(t, "other", results.Results[1].Chunk.ID)
+	assert.Equal(t, 2, results.Total)
+}
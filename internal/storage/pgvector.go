@@ -0,0 +1,1064 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// pgMigrationsTable records the schema migrations applied to each chunk table
+const pgMigrationsTable = "memory_schema_migrations"
+
+// pgMigration is one versioned change to the schema of a chunk table. Its
+// statements are templates: {table} is the chunk table, {dimension} the
+// embedding size and {hnsw_m}, {hnsw_ef_construction} the HNSW parameters.
+type pgMigration struct {
+	version     int
+	description string
+	statements  []string
+}
+
+// pgMigrations are applied in order, each once per table, by Initialize.
+// Append new migrations; never edit one that has shipped.
+var pgMigrations = []pgMigration{
+	{
+		version:     1,
+		description: "chunk and relationship tables",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS {table} (
+				id TEXT PRIMARY KEY,
+				session_id TEXT NOT NULL,
+				repository TEXT NOT NULL DEFAULT '',
+				type TEXT NOT NULL,
+				memory_class TEXT NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL,
+				deleted_at TIMESTAMPTZ,
+				content TEXT NOT NULL,
+				data JSONB NOT NULL,
+				embedding vector({dimension}) NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS {table}_repository_idx ON {table} (repository, created_at DESC)`,
+			`CREATE INDEX IF NOT EXISTS {table}_session_idx ON {table} (session_id, created_at)`,
+			`CREATE INDEX IF NOT EXISTS {table}_created_at_idx ON {table} (created_at)`,
+			`CREATE TABLE IF NOT EXISTS {table}_relationships (
+				id TEXT PRIMARY KEY,
+				source_chunk_id TEXT NOT NULL,
+				target_chunk_id TEXT NOT NULL,
+				relation_type TEXT NOT NULL,
+				confidence DOUBLE PRECISION NOT NULL,
+				data JSONB NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS {table}_relationships_source_idx ON {table}_relationships (source_chunk_id)`,
+			`CREATE INDEX IF NOT EXISTS {table}_relationships_target_idx ON {table}_relationships (target_chunk_id)`,
+		},
+	},
+	{
+		version:     2,
+		description: "HNSW index over embeddings",
+		statements: []string{
+			`CREATE INDEX IF NOT EXISTS {table}_embedding_hnsw_idx ON {table}
+				USING hnsw (embedding vector_cosine_ops)
+				WITH (m = {hnsw_m}, ef_construction = {hnsw_ef_construction})`,
+		},
+	},
+	{
+		version:     3,
+		description: "full-text index over content",
+		statements: []string{
+			`CREATE INDEX IF NOT EXISTS {table}_content_fts_idx ON {table} USING gin (to_tsvector('simple', content))`,
+		},
+	},
+}
+
+// PgVectorStore implements VectorStore on PostgreSQL with the pgvector
+// extension. Searches rank chunks by cosine similarity through an HNSW index
+// and apply the query's filters in the same SQL statement, so deployments
+// that already run PostgreSQL need no separate vector database.
+type PgVectorStore struct {
+	config *config.PgVectorConfig
+	table  string
+	db     *sql.DB
+}
+
+// NewPgVectorStore creates a pgvector store; Initialize connects and migrates
+func NewPgVectorStore(cfg *config.PgVectorConfig) *PgVectorStore {
+	table := cfg.Table
+	if table == "" {
+		table = "memory_chunks"
+	}
+	return &PgVectorStore{config: cfg, table: table}
+}
+
+// Initialize connects to PostgreSQL, enables the vector extension and
+// applies pending schema migrations
+func (ps *PgVectorStore) Initialize(ctx context.Context) error {
+	if !config.ValidTableName(ps.table) {
+		return fmt.Errorf("invalid pgvector table %q", ps.table)
+	}
+	if ps.config.Dimension <= 0 {
+		return fmt.Errorf("invalid pgvector dimension %d", ps.config.Dimension)
+	}
+
+	db, err := sql.Open("postgres", ps.config.DSN)
+	if err != nil {
+		return fmt.Errorf("failed to open PostgreSQL connection: %w", err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+	}
+	ps.db = db
+
+	if err := ps.migrate(ctx); err != nil {
+		return err
+	}
+	logging.Info("pgvector store initialized", "table", ps.table, "dimension", ps.config.Dimension)
+	return nil
+}
+
+// migrate applies the migrations the table has not had yet. An advisory
+// lock serializes servers starting against the same database.
+func (ps *PgVectorStore) migrate(ctx context.Context) error {
+	tx, err := ps.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start migration: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	setup := []string{
+		`SELECT pg_advisory_xact_lock(hashtext('` + pgMigrationsTable + `'))`,
+		`CREATE EXTENSION IF NOT EXISTS vector`,
+		`CREATE TABLE IF NOT EXISTS ` + pgMigrationsTable + ` (
+			table_name TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			description TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			PRIMARY KEY (table_name, version)
+		)`,
+	}
+	for _, statement := range setup {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to prepare migrations: %w", err)
+		}
+	}
+
+	applied := make(map[int]bool)
+	rows, err := tx.QueryContext(ctx, `SELECT version FROM `+pgMigrationsTable+` WHERE table_name = $1`, ps.table)
+	if err != nil {
+		return fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("failed to read applied migrations: %w", err)
+		}
+		applied[version] = true
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
+	for _, migration := range pgMigrations {
+		if applied[migration.version] {
+			continue
+		}
+		for _, statement := range migration.statements {
+			if _, err := tx.ExecContext(ctx, ps.expand(statement)); err != nil {
+				return fmt.Errorf("pgvector migration %d (%s) failed: %w", migration.version, migration.description, err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO `+pgMigrationsTable+` (table_name, version, description) VALUES ($1, $2, $3)`,
+			ps.table, migration.version, migration.description); err != nil {
+			return fmt.Errorf("failed to record pgvector migration %d: %w", migration.version, err)
+		}
+		logging.Info("Applied pgvector migration", "table", ps.table, "version", migration.version, "description", migration.description)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migrations: %w", err)
+	}
+	return nil
+}
+
+// expand fills in a migration statement's placeholders
+func (ps *PgVectorStore) expand(statement string) string {
+	return strings.NewReplacer(
+		"{table}", ps.table,
+		"{dimension}", strconv.Itoa(ps.config.Dimension),
+		"{hnsw_m}", strconv.Itoa(ps.hnswM()),
+		"{hnsw_ef_construction}", strconv.Itoa(ps.hnswEfConstruction()),
+	).Replace(statement)
+}
+
+// hnswM returns the configured HNSW m, or pgvector's default
+func (ps *PgVectorStore) hnswM() int {
+	if ps.config.HNSWM > 0 {
+		return ps.config.HNSWM
+	}
+	return 16
+}
+
+// hnswEfConstruction returns the configured HNSW ef_construction, or pgvector's default
+func (ps *PgVectorStore) hnswEfConstruction() int {
+	if ps.config.HNSWEfConstruction > 0 {
+		return ps.config.HNSWEfConstruction
+	}
+	return 64
+}
+
+// chunkColumns are the columns chunks are read from, in the order queryChunks scans them
+const chunkColumns = `data, embedding::text`
+
+// upsertChunkSQL inserts a chunk or replaces the stored one
+const upsertChunkSQL = `INSERT INTO {table}
+	(id, session_id, repository, type, memory_class, created_at, deleted_at, content, data, embedding)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10::vector)
+	ON CONFLICT (id) DO UPDATE SET
+		session_id = EXCLUDED.session_id, repository = EXCLUDED.repository, type = EXCLUDED.type,
+		memory_class = EXCLUDED.memory_class, created_at = EXCLUDED.created_at, deleted_at = EXCLUDED.deleted_at,
+		content = EXCLUDED.content, data = EXCLUDED.data, embedding = EXCLUDED.embedding`
+
+// chunkRow returns the column values upsertChunkSQL stores for chunk.
+// Embeddings live in their own column, so they are left out of data.
+func (ps *PgVectorStore) chunkRow(chunk *types.ConversationChunk) ([]interface{}, error) {
+	if err := chunk.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid chunk: %w", err)
+	}
+	if len(chunk.Embeddings) == 0 {
+		return nil, errors.New("chunk must have embeddings before storing")
+	}
+	if len(chunk.Embeddings) != ps.config.Dimension {
+		return nil, fmt.Errorf("chunk has %d-dimensional embeddings, the %s table stores %d", len(chunk.Embeddings), ps.table, ps.config.Dimension)
+	}
+
+	stored := *chunk
+	stored.Embeddings = nil
+	data, err := json.Marshal(&stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode chunk: %w", err)
+	}
+
+	var deletedAt interface{}
+	if chunk.Metadata.DeletedAt != nil {
+		deletedAt = *chunk.Metadata.DeletedAt
+	}
+	// JSON is passed as text; lib/pq would send a []byte as bytea. The
+	// effective class is stored, so class filters need not know type defaults.
+	return []interface{}{
+		chunk.ID, chunk.SessionID, chunk.Metadata.Repository, string(chunk.Type), string(chunk.Class()),
+		chunk.Timestamp, deletedAt, chunk.Content, string(data), vectorLiteral(chunk.Embeddings),
+	}, nil
+}
+
+// Store saves a chunk, replacing a stored chunk with the same ID
+func (ps *PgVectorStore) Store(ctx context.Context, chunk *types.ConversationChunk) error {
+	row, err := ps.chunkRow(chunk)
+	if err != nil {
+		return err
+	}
+	if _, err := ps.db.ExecContext(ctx, ps.expand(upsertChunkSQL), row...); err != nil {
+		return fmt.Errorf("failed to store chunk in PostgreSQL: %w", err)
+	}
+	logging.Debug("Stored chunk in pgvector", "id", chunk.ID, "repository", chunk.Metadata.Repository, "type", chunk.Type)
+	return nil
+}
+
+// Search ranks chunks matching the query's filters by cosine similarity to embeddings
+func (ps *PgVectorStore) Search(ctx context.Context, query *types.MemoryQuery, embeddings []float64) (*types.SearchResults, error) {
+	start := time.Now()
+	if len(embeddings) == 0 {
+		return nil, errors.New("embeddings cannot be empty")
+	}
+
+	args := &pgArgs{}
+	vector := args.add(vectorLiteral(embeddings)) + "::vector"
+	where := buildPgVectorFilter(query, args)
+	where = append(where, fmt.Sprintf("1 - (embedding <=> %s) >= %s", vector, args.add(query.MinRelevanceScore)))
+
+	statement := fmt.Sprintf(`SELECT %s, 1 - (embedding <=> %s) AS score FROM %s WHERE %s ORDER BY embedding <=> %s`,
+		chunkColumns, vector, ps.table, strings.Join(where, " AND "), vector)
+	if query.Limit > 0 {
+		statement += " LIMIT " + args.add(query.Limit)
+	}
+
+	rows, err := ps.queryWithEfSearch(ctx, statement, args.values...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search in PostgreSQL: %w", err)
+	}
+
+	results := &types.SearchResults{Results: make([]types.SearchResult, 0)}
+	for _, row := range rows {
+		results.Results = append(results.Results, types.SearchResult{Chunk: *row.chunk, Score: row.score})
+	}
+	results.Total = len(results.Results)
+	results.QueryTime = time.Since(start)
+	return results, nil
+}
+
+// scoredChunk is a chunk read with its similarity score
+type scoredChunk struct {
+	chunk *types.ConversationChunk
+	score float64
+}
+
+// queryWithEfSearch runs a scored chunk query, raising the HNSW candidate
+// list size for it when configured. The setting is local to a transaction.
+func (ps *PgVectorStore) queryWithEfSearch(ctx context.Context, statement string, args ...interface{}) ([]scoredChunk, error) {
+	tx, err := ps.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if ps.config.HNSWEfSearch > 0 {
+		if _, err := tx.ExecContext(ctx, "SET LOCAL hnsw.ef_search = "+strconv.Itoa(ps.config.HNSWEfSearch)); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var results []scoredChunk
+	for rows.Next() {
+		var data []byte
+		var embedding string
+		var score float64
+		if err := rows.Scan(&data, &embedding, &score); err != nil {
+			return nil, err
+		}
+		chunk, err := decodeChunk(data, embedding)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, scoredChunk{chunk: chunk, score: score})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return results, tx.Commit()
+}
+
+// pgArgs collects the arguments of a SQL statement
+type pgArgs struct {
+	values []interface{}
+}
+
+// add appends value and returns its placeholder
+func (a *pgArgs) add(value interface{}) string {
+	a.values = append(a.values, value)
+	return "$" + strconv.Itoa(len(a.values))
+}
+
+// buildPgVectorFilter returns the WHERE conditions of a query's filters,
+// with the same semantics as the Qdrant store's: repository isolation,
+// chunk types, memory classes, recency, provenance and soft deletion
+func buildPgVectorFilter(query *types.MemoryQuery, args *pgArgs) []string {
+	conditions := make([]string, 0)
+
+	// "global" only matches chunks stored in the global repository
+	if query.Repository != nil && *query.Repository != "" {
+		conditions = append(conditions, "repository = "+args.add(*query.Repository))
+	}
+
+	if len(query.Types) > 0 {
+		values := make([]string, len(query.Types))
+		for i, t := range query.Types {
+			values[i] = string(t)
+		}
+		conditions = append(conditions, "type = ANY("+args.add(pq.Array(values))+")")
+	}
+
+	if len(query.Classes) > 0 {
+		values := make([]string, len(query.Classes))
+		for i, class := range query.Classes {
+			values[i] = string(class)
+		}
+		conditions = append(conditions, "memory_class = ANY("+args.add(pq.Array(values))+")")
+	}
+
+	var cutoff time.Time
+	switch query.Recency {
+	case types.RecencyRecent:
+		cutoff = time.Now().AddDate(0, 0, -7)
+	case types.RecencyLastMonth:
+		cutoff = time.Now().AddDate(0, -1, 0)
+	case types.RecencyAllTime:
+		// No time filtering for all time
+	}
+	if !cutoff.IsZero() {
+		conditions = append(conditions, "created_at >= "+args.add(cutoff))
+	}
+
+	if f := query.Provenance; f != nil {
+		for _, field := range []struct{ key, value string }{
+			{"source_system", f.SourceSystem},
+			{"commit_sha", f.CommitSHA},
+			{"author", f.Author},
+			{"capture_tool", f.CaptureTool},
+		} {
+			if field.value != "" {
+				conditions = append(conditions, fmt.Sprintf("data->'metadata'->'provenance'->>'%s' = %s", field.key, args.add(field.value)))
+			}
+		}
+	}
+
+	if !query.IncludeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+	return conditions
+}
+
+// vectorLiteral formats embeddings in pgvector's text representation
+func vectorLiteral(embeddings []float64) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, value := range embeddings {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(float32(value)), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// parseVectorLiteral parses pgvector's text representation of a vector
+func parseVectorLiteral(literal string) ([]float64, error) {
+	literal = strings.TrimSpace(literal)
+	if len(literal) < 2 || literal[0] != '[' || literal[len(literal)-1] != ']' {
+		return nil, fmt.Errorf("invalid vector %q", literal)
+	}
+	body := literal[1 : len(literal)-1]
+	if body == "" {
+		return []float64{}, nil
+	}
+	parts := strings.Split(body, ",")
+	vector := make([]float64, len(parts))
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vector %q: %w", literal, err)
+		}
+		vector[i] = value
+	}
+	return vector, nil
+}
+
+// decodeChunk rebuilds a chunk from its data and embedding columns
+func decodeChunk(data []byte, embedding string) (*types.ConversationChunk, error) {
+	var chunk types.ConversationChunk
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		return nil, fmt.Errorf("failed to decode chunk: %w", err)
+	}
+	vector, err := parseVectorLiteral(embedding)
+	if err != nil {
+		return nil, err
+	}
+	chunk.Embeddings = vector
+	return &chunk, nil
+}
+
+// queryChunks runs a query selecting chunkColumns
+func (ps *PgVectorStore) queryChunks(ctx context.Context, statement string, args ...interface{}) ([]types.ConversationChunk, error) {
+	rows, err := ps.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	chunks := make([]types.ConversationChunk, 0)
+	for rows.Next() {
+		var data []byte
+		var embedding string
+		if err := rows.Scan(&data, &embedding); err != nil {
+			return nil, err
+		}
+		chunk, err := decodeChunk(data, embedding)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, *chunk)
+	}
+	return chunks, rows.Err()
+}
+
+// GetByID retrieves a chunk by its ID
+func (ps *PgVectorStore) GetByID(ctx context.Context, id string) (*types.ConversationChunk, error) {
+	chunks, err := ps.queryChunks(ctx, `SELECT `+chunkColumns+` FROM `+ps.table+` WHERE id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunk by ID from PostgreSQL: %w", err)
+	}
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("chunk not found with ID: %s", id)
+	}
+	return &chunks[0], nil
+}
+
+// GetByIDs retrieves several chunks with a single query
+func (ps *PgVectorStore) GetByIDs(ctx context.Context, ids []string) ([]types.ConversationChunk, error) {
+	if len(ids) == 0 {
+		return []types.ConversationChunk{}, nil
+	}
+	chunks, err := ps.queryChunks(ctx, `SELECT `+chunkColumns+` FROM `+ps.table+` WHERE id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunks by ID from PostgreSQL: %w", err)
+	}
+	byID := make(map[string]*types.ConversationChunk, len(chunks))
+	for i := range chunks {
+		byID[chunks[i].ID] = &chunks[i]
+	}
+	return orderChunksByID(ids, byID), nil
+}
+
+// ListByRepository lists chunks of a repository, newest first
+func (ps *PgVectorStore) ListByRepository(ctx context.Context, repository string, limit, offset int) ([]types.ConversationChunk, error) {
+	args := &pgArgs{}
+	var conditions []string
+	if repository != "" {
+		conditions = append(conditions, "repository = "+args.add(repository))
+	}
+	if !IncludesDeleted(ctx) {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+	statement := `SELECT ` + chunkColumns + ` FROM ` + ps.table
+	if len(conditions) > 0 {
+		statement += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	statement += " ORDER BY created_at DESC, id"
+	if limit > 0 {
+		statement += " LIMIT " + args.add(limit)
+	}
+	if offset > 0 {
+		statement += " OFFSET " + args.add(offset)
+	}
+	chunks, err := ps.queryChunks(ctx, statement, args.values...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chunks by repository: %w", err)
+	}
+	return chunks, nil
+}
+
+// trashClause is the condition, joined with keyword, that leaves the trash
+// out of a listing made under ctx, or nothing when ctx includes it
+func (ps *PgVectorStore) trashClause(ctx context.Context, keyword string) string {
+	if IncludesDeleted(ctx) {
+		return ""
+	}
+	return keyword + " deleted_at IS NULL"
+}
+
+// ListBySession lists chunks of a session, oldest first
+func (ps *PgVectorStore) ListBySession(ctx context.Context, sessionID string) ([]types.ConversationChunk, error) {
+	chunks, err := ps.queryChunks(ctx, `SELECT `+chunkColumns+` FROM `+ps.table+` WHERE session_id = $1`+ps.trashClause(ctx, " AND")+` ORDER BY created_at, id`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chunks by session: %w", err)
+	}
+	return chunks, nil
+}
+
+// Delete removes a chunk by ID
+func (ps *PgVectorStore) Delete(ctx context.Context, id string) error {
+	result, err := ps.db.ExecContext(ctx, `DELETE FROM `+ps.table+` WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete chunk from PostgreSQL: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return errors.New("chunk not found")
+	}
+	return nil
+}
+
+// Update replaces an existing chunk
+func (ps *PgVectorStore) Update(ctx context.Context, chunk *types.ConversationChunk) error {
+	row, err := ps.chunkRow(chunk)
+	if err != nil {
+		return err
+	}
+	result, err := ps.db.ExecContext(ctx, ps.expand(`UPDATE {table} SET
+		session_id = $2, repository = $3, type = $4, memory_class = $5, created_at = $6,
+		deleted_at = $7, content = $8, data = $9, embedding = $10::vector
+		WHERE id = $1`), row...)
+	if err != nil {
+		return fmt.Errorf("failed to update chunk in PostgreSQL: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return errors.New("chunk not found")
+	}
+	return nil
+}
+
+// HealthCheck verifies the connection to PostgreSQL
+func (ps *PgVectorStore) HealthCheck(ctx context.Context) error {
+	if ps.db == nil {
+		return errors.New("pgvector store is not initialized")
+	}
+	if err := ps.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("pgvector health check failed: %w", err)
+	}
+	return nil
+}
+
+// GetStats returns chunk counts, the stored time range and the table size
+func (ps *PgVectorStore) GetStats(ctx context.Context) (*StoreStats, error) {
+	stats := &StoreStats{
+		ChunksByType: make(map[string]int64),
+		ChunksByRepo: make(map[string]int64),
+	}
+
+	var oldest, newest sql.NullTime
+	var averageEmbedding sql.NullFloat64
+	err := ps.db.QueryRowContext(ctx, `SELECT count(*), min(created_at), max(created_at),
+		avg(vector_dims(embedding)), pg_total_relation_size('`+ps.table+`') FROM `+ps.table).
+		Scan(&stats.TotalChunks, &oldest, &newest, &averageEmbedding, &stats.StorageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats from PostgreSQL: %w", err)
+	}
+	if oldest.Valid && newest.Valid {
+		o, n := oldest.Time.Format(time.RFC3339), newest.Time.Format(time.RFC3339)
+		stats.OldestChunk, stats.NewestChunk = &o, &n
+	}
+	stats.AverageEmbedding = averageEmbedding.Float64
+
+	for column, counts := range map[string]map[string]int64{"type": stats.ChunksByType, "repository": stats.ChunksByRepo} {
+		if err := ps.countBy(ctx, column, counts); err != nil {
+			return nil, err
+		}
+	}
+	return stats, nil
+}
+
+// countBy counts chunks by the non-empty values of column
+func (ps *PgVectorStore) countBy(ctx context.Context, column string, counts map[string]int64) error {
+	rows, err := ps.db.QueryContext(ctx, `SELECT `+column+`, count(*) FROM `+ps.table+` WHERE `+column+` <> '' GROUP BY `+column)
+	if err != nil {
+		return fmt.Errorf("failed to count chunks by %s: %w", column, err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var value string
+		var count int64
+		if err := rows.Scan(&value, &count); err != nil {
+			return fmt.Errorf("failed to count chunks by %s: %w", column, err)
+		}
+		counts[value] = count
+	}
+	return rows.Err()
+}
+
+// Cleanup deletes chunks older than the retention period
+func (ps *PgVectorStore) Cleanup(ctx context.Context, retentionDays int) (int, error) {
+	cutoff := time.Now().AddDate(0, 0, -retentionDays)
+	result, err := ps.db.ExecContext(ctx, `DELETE FROM `+ps.table+` WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to cleanup old chunks: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count cleaned up chunks: %w", err)
+	}
+	logging.Info("Cleaned up old chunks", "deleted_count", deleted, "retention_days", retentionDays)
+	return int(deleted), nil
+}
+
+// Close closes the connection pool
+func (ps *PgVectorStore) Close() error {
+	if ps.db == nil {
+		return nil
+	}
+	return ps.db.Close()
+}
+
+// GetAllChunks retrieves every chunk, oldest first
+func (ps *PgVectorStore) GetAllChunks(ctx context.Context) ([]types.ConversationChunk, error) {
+	chunks, err := ps.queryChunks(ctx, `SELECT `+chunkColumns+` FROM `+ps.table+ps.trashClause(ctx, " WHERE")+` ORDER BY created_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all chunks: %w", err)
+	}
+	return chunks, nil
+}
+
+// DeleteCollection drops a chunk table, its relationships and its migration
+// records; an empty name drops the store's own table
+func (ps *PgVectorStore) DeleteCollection(ctx context.Context, collection string) error {
+	if collection == "" {
+		collection = ps.table
+	}
+	if !config.ValidTableName(collection) {
+		return fmt.Errorf("invalid pgvector table %q", collection)
+	}
+	for _, statement := range []string{
+		`DROP TABLE IF EXISTS ` + collection + `_relationships`,
+		`DROP TABLE IF EXISTS ` + collection,
+	} {
+		if _, err := ps.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to delete collection %s: %w", collection, err)
+		}
+	}
+	if _, err := ps.db.ExecContext(ctx, `DELETE FROM `+pgMigrationsTable+` WHERE table_name = $1`, collection); err != nil {
+		return fmt.Errorf("failed to delete collection %s: %w", collection, err)
+	}
+	logging.Info("Deleted collection", "collection", collection)
+	return nil
+}
+
+// ListCollections lists the chunk tables migrations were applied to
+func (ps *PgVectorStore) ListCollections(ctx context.Context) ([]string, error) {
+	rows, err := ps.db.QueryContext(ctx, `SELECT DISTINCT table_name FROM `+pgMigrationsTable+` ORDER BY table_name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	collections := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to list collections: %w", err)
+		}
+		collections = append(collections, name)
+	}
+	return collections, rows.Err()
+}
+
+// FindSimilar ranks chunks by full-text match with content; it needs no
+// embeddings, unlike Search
+func (ps *PgVectorStore) FindSimilar(ctx context.Context, content string, chunkType *types.ChunkType, limit int) ([]types.ConversationChunk, error) {
+	args := &pgArgs{}
+	terms := args.add(content)
+	where := []string{
+		"to_tsvector('simple', content) @@ plainto_tsquery('simple', " + terms + ")",
+		"deleted_at IS NULL",
+	}
+	if chunkType != nil {
+		where = append(where, "type = "+args.add(string(*chunkType)))
+	}
+	statement := fmt.Sprintf(`SELECT %s FROM %s WHERE %s
+		ORDER BY ts_rank(to_tsvector('simple', content), plainto_tsquery('simple', %s)) DESC`,
+		chunkColumns, ps.table, strings.Join(where, " AND "), terms)
+	if limit > 0 {
+		statement += " LIMIT " + args.add(limit)
+	}
+	chunks, err := ps.queryChunks(ctx, statement, args.values...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find similar chunks: %w", err)
+	}
+	return chunks, nil
+}
+
+// StoreChunk is an alias for Store
+func (ps *PgVectorStore) StoreChunk(ctx context.Context, chunk *types.ConversationChunk) error {
+	return ps.Store(ctx, chunk)
+}
+
+// BatchStore stores chunks in one transaction. Invalid chunks are reported
+// and skipped; if the transaction fails, none of the batch is stored.
+func (ps *PgVectorStore) BatchStore(ctx context.Context, chunks []*types.ConversationChunk) (*BatchResult, error) {
+	result := &BatchResult{Errors: []string{}, ProcessedIDs: []string{}}
+	rows := make([][]interface{}, 0, len(chunks))
+	valid := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		result.ProcessedIDs = append(result.ProcessedIDs, chunk.ID)
+		row, err := ps.chunkRow(chunk)
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("chunk %s: %v", chunk.ID, err))
+			continue
+		}
+		rows = append(rows, row)
+		valid = append(valid, chunk.ID)
+	}
+	if len(rows) == 0 {
+		return result, nil
+	}
+
+	if err := ps.upsertAll(ctx, rows); err != nil {
+		result.Failed += len(valid)
+		result.Errors = append(result.Errors, err.Error())
+		return result, nil
+	}
+	result.Success = len(valid)
+	return result, nil
+}
+
+// upsertAll stores chunk rows in one transaction
+func (ps *PgVectorStore) upsertAll(ctx context.Context, rows [][]interface{}) error {
+	tx, err := ps.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start batch: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	statement, err := tx.PrepareContext(ctx, ps.expand(upsertChunkSQL))
+	if err != nil {
+		return fmt.Errorf("failed to prepare batch: %w", err)
+	}
+	defer func() { _ = statement.Close() }()
+
+	for _, row := range rows {
+		if _, err := statement.ExecContext(ctx, row...); err != nil {
+			return fmt.Errorf("failed to store chunk %v: %w", row[0], err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit batch: %w", err)
+	}
+	return nil
+}
+
+// BatchDelete deletes chunks with one statement; unknown IDs are reported as failures
+func (ps *PgVectorStore) BatchDelete(ctx context.Context, ids []string) (*BatchResult, error) {
+	result := &BatchResult{Errors: []string{}, ProcessedIDs: ids}
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	rows, err := ps.db.QueryContext(ctx, `DELETE FROM `+ps.table+` WHERE id = ANY($1) RETURNING id`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete chunks from PostgreSQL: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	deleted := make(map[string]bool, len(ids))
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to delete chunks from PostgreSQL: %w", err)
+		}
+		deleted[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to delete chunks from PostgreSQL: %w", err)
+	}
+
+	for _, id := range ids {
+		if deleted[id] {
+			result.Success++
+		} else {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("chunk %s: chunk not found", id))
+		}
+	}
+	return result, nil
+}
+
+// StoreRelationship stores a relationship between two chunks
+func (ps *PgVectorStore) StoreRelationship(ctx context.Context, sourceID, targetID string, relationType types.RelationType, confidence float64, source types.ConfidenceSource) (*types.MemoryRelationship, error) {
+	rel := &types.MemoryRelationship{
+		ID:               uuid.New().String(),
+		SourceChunkID:    sourceID,
+		TargetChunkID:    targetID,
+		RelationType:     relationType,
+		Confidence:       confidence,
+		ConfidenceSource: source,
+		CreatedAt:        time.Now(),
+	}
+	data, err := json.Marshal(rel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode relationship: %w", err)
+	}
+	_, err = ps.db.ExecContext(ctx, ps.expand(`INSERT INTO {table}_relationships
+		(id, source_chunk_id, target_chunk_id, relation_type, confidence, data) VALUES ($1, $2, $3, $4, $5, $6)`),
+		rel.ID, sourceID, targetID, string(relationType), confidence, string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to store relationship: %w", err)
+	}
+	return rel, nil
+}
+
+// queryRelationships returns relationships matching query's direction,
+// type and confidence filters, most confident first
+func (ps *PgVectorStore) queryRelationships(ctx context.Context, query *types.RelationshipQuery) ([]types.MemoryRelationship, error) {
+	args := &pgArgs{}
+	chunkID := args.add(query.ChunkID)
+	var where []string
+	switch query.Direction {
+	case "outgoing":
+		where = append(where, "source_chunk_id = "+chunkID)
+	case "incoming":
+		where = append(where, "target_chunk_id = "+chunkID)
+	default:
+		where = append(where, "(source_chunk_id = "+chunkID+" OR target_chunk_id = "+chunkID+")")
+	}
+	if query.MinConfidence > 0 {
+		where = append(where, "confidence >= "+args.add(query.MinConfidence))
+	}
+	if len(query.RelationTypes) > 0 {
+		values := make([]string, len(query.RelationTypes))
+		for i, t := range query.RelationTypes {
+			values[i] = string(t)
+		}
+		where = append(where, "relation_type = ANY("+args.add(pq.Array(values))+")")
+	}
+
+	statement := `SELECT data FROM ` + ps.table + `_relationships WHERE ` + strings.Join(where, " AND ") + ` ORDER BY confidence DESC, id`
+	if query.Limit > 0 {
+		statement += " LIMIT " + args.add(query.Limit)
+	}
+	return ps.scanRelationships(ctx, statement, args.values...)
+}
+
+// scanRelationships runs a query selecting relationship data
+func (ps *PgVectorStore) scanRelationships(ctx context.Context, statement string, args ...interface{}) ([]types.MemoryRelationship, error) {
+	rows, err := ps.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query relationships: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	relationships := make([]types.MemoryRelationship, 0)
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to query relationships: %w", err)
+		}
+		var rel types.MemoryRelationship
+		if err := json.Unmarshal(data, &rel); err != nil {
+			return nil, fmt.Errorf("failed to decode relationship: %w", err)
+		}
+		relationships = append(relationships, rel)
+	}
+	return relationships, rows.Err()
+}
+
+// GetRelationships returns relationships of a chunk matching the query filters
+func (ps *PgVectorStore) GetRelationships(ctx context.Context, query *types.RelationshipQuery) ([]types.RelationshipResult, error) {
+	relationships, err := ps.queryRelationships(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var chunks map[string]*types.ConversationChunk
+	if query.IncludeChunks {
+		ids := make([]string, 0, 2*len(relationships))
+		for i := range relationships {
+			ids = append(ids, relationships[i].SourceChunkID, relationships[i].TargetChunkID)
+		}
+		found, err := ps.GetByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		chunks = make(map[string]*types.ConversationChunk, len(found))
+		for i := range found {
+			chunks[found[i].ID] = &found[i]
+		}
+	}
+
+	results := make([]types.RelationshipResult, 0, len(relationships))
+	for i := range relationships {
+		result := types.RelationshipResult{Relationship: relationships[i]}
+		if chunks != nil {
+			result.SourceChunk = chunks[relationships[i].SourceChunkID]
+			result.TargetChunk = chunks[relationships[i].TargetChunkID]
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// TraverseGraph walks outgoing relationships breadth-first up to maxDepth
+func (ps *PgVectorStore) TraverseGraph(ctx context.Context, startChunkID string, maxDepth int, relationTypes []types.RelationType) (*types.GraphTraversalResult, error) {
+	result := &types.GraphTraversalResult{
+		Paths: []types.GraphPath{},
+		Nodes: []types.GraphNode{},
+		Edges: []types.GraphEdge{},
+	}
+
+	depths := map[string]int{startChunkID: 0}
+	order := []string{startChunkID}
+	degrees := make(map[string]int)
+	for i := 0; i < len(order); i++ {
+		current := order[i]
+		if depths[current] >= maxDepth {
+			continue
+		}
+
+		relationships, err := ps.queryRelationships(ctx, &types.RelationshipQuery{ChunkID: current, RelationTypes: relationTypes, Direction: "outgoing"})
+		if err != nil {
+			return nil, err
+		}
+		for j := range relationships {
+			rel := relationships[j]
+			result.Edges = append(result.Edges, types.GraphEdge{Relationship: rel, Weight: rel.Confidence})
+			degrees[rel.SourceChunkID]++
+			degrees[rel.TargetChunkID]++
+			if _, seen := depths[rel.TargetChunkID]; !seen {
+				depths[rel.TargetChunkID] = depths[current] + 1
+				order = append(order, rel.TargetChunkID)
+			}
+		}
+	}
+
+	chunks, err := ps.GetByIDs(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*types.ConversationChunk, len(chunks))
+	for i := range chunks {
+		byID[chunks[i].ID] = &chunks[i]
+	}
+	for _, id := range order {
+		result.Nodes = append(result.Nodes, types.GraphNode{ChunkID: id, Chunk: byID[id], Degree: degrees[id]})
+	}
+	return result, nil
+}
+
+// UpdateRelationship updates the confidence of a relationship
+func (ps *PgVectorStore) UpdateRelationship(ctx context.Context, relationshipID string, confidence float64, factors types.ConfidenceFactors) error {
+	encodedFactors, err := json.Marshal(factors)
+	if err != nil {
+		return fmt.Errorf("failed to encode confidence factors: %w", err)
+	}
+	result, err := ps.db.ExecContext(ctx, ps.expand(`UPDATE {table}_relationships SET confidence = $2,
+		data = jsonb_set(jsonb_set(data, '{confidence}', to_jsonb($2::double precision)), '{confidence_factors}', $3::jsonb)
+		WHERE id = $1`), relationshipID, confidence, string(encodedFactors))
+	if err != nil {
+		return fmt.Errorf("failed to update relationship: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return errors.New("relationship not found")
+	}
+	return nil
+}
+
+// DeleteRelationship removes a relationship
+func (ps *PgVectorStore) DeleteRelationship(ctx context.Context, relationshipID string) error {
+	result, err := ps.db.ExecContext(ctx, `DELETE FROM `+ps.table+`_relationships WHERE id = $1`, relationshipID)
+	if err != nil {
+		return fmt.Errorf("failed to delete relationship: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return errors.New("relationship not found")
+	}
+	return nil
+}
+
+// GetRelationshipByID returns a relationship by ID
+func (ps *PgVectorStore) GetRelationshipByID(ctx context.Context, relationshipID string) (*types.MemoryRelationship, error) {
+	relationships, err := ps.scanRelationships(ctx, `SELECT data FROM `+ps.table+`_relationships WHERE id = $1`, relationshipID)
+	if err != nil {
+		return nil, err
+	}
+	if len(relationships) == 0 {
+		return nil, errors.New("relationship not found")
+	}
+	return &relationships[0], nil
+}
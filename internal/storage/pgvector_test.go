@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVectorLiteral(t *testing.T) {
+	literal := vectorLiteral([]float64{0.5, -1, 1e-7, 3})
+	assert.Equal(t, "[0.5,-1,1e-07,3]", literal)
+
+	parsed, err := parseVectorLiteral(literal)
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{0.5, -1, 1e-7, 3}, parsed, 1e-12)
+
+	empty, err := parseVectorLiteral("[]")
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	_, err = parseVectorLiteral("0.5,1")
+	assert.Error(t, err)
+	_, err = parseVectorLiteral("[0.5,x]")
+	assert.Error(t, err)
+}
+
+func TestBuildPgVectorFilter(t *testing.T) {
+	repo := "repo-a"
+	query := types.NewMemoryQuery("anything")
+	query.Repository = &repo
+	query.Types = []types.ChunkType{types.ChunkTypeProblem, types.ChunkTypeSolution}
+	query.Classes = []types.MemoryClass{types.MemoryClassSemantic}
+	query.Recency = types.RecencyRecent
+	query.Provenance = &types.ProvenanceFilter{SourceSystem: "git", Author: "dev"}
+
+	args := &pgArgs{}
+	args.add("[1,2]") // The query vector comes first in Search
+	conditions := buildPgVectorFilter(query, args)
+
+	assert.Equal(t, []string{
+		"repository = $2",
+		"type = ANY($3)",
+		"memory_class = ANY($4)",
+		"created_at >= $5",
+		"data->'metadata'->'provenance'->>'source_system' = $6",
+		"data->'metadata'->'provenance'->>'author' = $7",
+		"deleted_at IS NULL",
+	}, conditions)
+	require.Len(t, args.values, 7)
+	assert.Equal(t, "repo-a", args.values[1])
+	assert.Equal(t, pq.Array([]string{"problem", "solution"}), args.values[2])
+	assert.Equal(t, pq.Array([]string{"semantic"}), args.values[3])
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, -7), args.values[4].(time.Time), time.Minute)
+	assert.Equal(t, []interface{}{"git", "dev"}, args.values[5:])
+
+	everything := types.NewMemoryQuery("anything")
+	everything.Recency = types.RecencyAllTime
+	everything.IncludeDeleted = true
+	assert.Empty(t, buildPgVectorFilter(everything, &pgArgs{}), "a query without filters adds no conditions")
+}
+
+func TestPgVectorStoreMigrations(t *testing.T) {
+	store := NewPgVectorStore(&config.PgVectorConfig{Dimension: 768, HNSWM: 24, HNSWEfConstruction: 128})
+
+	versions := make([]int, 0, len(pgMigrations))
+	var statements []string
+	for _, migration := range pgMigrations {
+		versions = append(versions, migration.version)
+		for _, statement := range migration.statements {
+			statements = append(statements, store.expand(statement))
+		}
+	}
+	for i := range versions {
+		assert.Equal(t, i+1, versions[i], "migration versions are sequential")
+	}
+
+	all := strings.Join(statements, "\n")
+	assert.NotContains(t, all, "{", "every placeholder is expanded")
+	assert.Contains(t, all, "CREATE TABLE IF NOT EXISTS memory_chunks (")
+	assert.Contains(t, all, "embedding vector(768) NOT NULL")
+	assert.Contains(t, all, "USING hnsw (embedding vector_cosine_ops)")
+	assert.Contains(t, all, "WITH (m = 24, ef_construction = 128)")
+}
+
+func TestPgVectorStoreRejectsMismatchedEmbeddings(t *testing.T) {
+	store := NewPgVectorStore(&config.PgVectorConfig{Dimension: 4})
+	chunk, err := types.NewConversationChunk("session", "content", types.ChunkTypeProblem, &types.ChunkMetadata{
+		Outcome: types.OutcomeSuccess, Difficulty: types.DifficultySimple,
+	})
+	require.NoError(t, err)
+
+	_, err = store.chunkRow(chunk)
+	assert.ErrorContains(t, err, "must have embeddings")
+
+	chunk.Embeddings = []float64{1, 2, 3}
+	_, err = store.chunkRow(chunk)
+	assert.ErrorContains(t, err, "3-dimensional embeddings")
+
+	chunk.Embeddings = []float64{1, 2, 3, 4}
+	row, err := store.chunkRow(chunk)
+	require.NoError(t, err)
+	assert.Equal(t, "[1,2,3,4]", row[len(row)-1])
+	assert.NotContains(t, row[8], "embeddings\":[", "embeddings are stored in their own column only")
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+
+	"lerian-mcp-memory/internal/projects"
+	"lerian-mcp-memory/pkg/types"
+)
+
+// ProjectGuardedVectorStore wraps a VectorStore and checks the chunks written
+// through it against the project registry: archived projects take no new or
+// changed memories, former IDs of renamed projects are refused, and, when the
+// registry requires it, so are repositories that are not projects. Writes
+// made by project lifecycle operations (see projects.WithLifecycle) pass.
+type ProjectGuardedVectorStore struct {
+	VectorStore
+
+	registry *projects.Store
+}
+
+// NewProjectGuardedVectorStore checks writes to store against registry
+func NewProjectGuardedVectorStore(store VectorStore, registry *projects.Store) *ProjectGuardedVectorStore {
+	return &ProjectGuardedVectorStore{VectorStore: store, registry: registry}
+}
+
+// check fails unless the registry allows writing chunk
+func (s *ProjectGuardedVectorStore) check(ctx context.Context, chunk *types.ConversationChunk) error {
+	if projects.InLifecycle(ctx) {
+		return nil
+	}
+	return s.registry.CheckWrite(chunk.Metadata.Repository)
+}
+
+// Store stores a chunk in a project that takes writes
+func (s *ProjectGuardedVectorStore) Store(ctx context.Context, chunk *types.ConversationChunk) error {
+	if err := s.check(ctx, chunk); err != nil {
+		return err
+	}
+	return s.VectorStore.Store(ctx, chunk)
+}
+
+// StoreChunk is an alias for Store
+func (s *ProjectGuardedVectorStore) StoreChunk(ctx context.Context, chunk *types.ConversationChunk) error {
+	return s.Store(ctx, chunk)
+}
+
+// Update updates a chunk of a project that takes writes
+func (s *ProjectGuardedVectorStore) Update(ctx context.Context, chunk *types.ConversationChunk) error {
+	if err := s.check(ctx, chunk); err != nil {
+		return err
+	}
+	return s.VectorStore.Update(ctx, chunk)
+}
+
+// BatchStore stores chunks, all of which must be in projects that take writes
+func (s *ProjectGuardedVectorStore) BatchStore(ctx context.Context, chunks []*types.ConversationChunk) (*BatchResult, error) {
+	for _, chunk := range chunks {
+		if err := s.check(ctx, chunk); err != nil {
+			return nil, err
+		}
+	}
+	return s.VectorStore.BatchStore(ctx, chunks)
+}
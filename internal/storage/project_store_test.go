@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/projects"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectGuardedVectorStore(t *testing.T) {
+	ctx := context.Background()
+	registry := projects.NewStore("")
+	_, err := registry.Create(projects.Project{ID: "acme/api"})
+	require.NoError(t, err)
+	store := NewProjectGuardedVectorStore(NewKeywordStore(""), registry)
+
+	chunk := newKeywordChunk(t, "acme/api", "connection pool exhausted", types.ChunkTypeProblem)
+	require.NoError(t, store.Store(ctx, chunk))
+	require.NoError(t, store.Store(ctx, newKeywordChunk(t, "acme/unregistered", "scratch note", types.ChunkTypeDiscussion)))
+
+	_, err = registry.SetArchived("acme/api", true)
+	require.NoError(t, err)
+	assert.ErrorIs(t, store.Store(ctx, newKeywordChunk(t, "acme/api", "late note", types.ChunkTypeDiscussion)), projects.ErrArchived)
+	chunk.Content = "changed"
+	assert.ErrorIs(t, store.Update(ctx, chunk), projects.ErrArchived)
+	_, err = store.BatchStore(ctx, []*types.ConversationChunk{newKeywordChunk(t, "acme/api", "batched", types.ChunkTypeDiscussion)})
+	assert.ErrorIs(t, err, projects.ErrArchived)
+	assert.NoError(t, store.Update(projects.WithLifecycle(ctx), chunk), "lifecycle operations write to archived projects")
+
+	registry.SetRequireRegistration(true)
+	assert.ErrorIs(t, store.StoreChunk(ctx, newKeywordChunk(t, "acme/unregistered", "another note", types.ChunkTypeDiscussion)), projects.ErrUnregistered)
+	assert.NoError(t, store.Store(ctx, newKeywordChunk(t, "global", "shared architecture note", types.ChunkTypeDiscussion)))
+
+	stored, err := store.GetByID(ctx, chunk.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "changed", stored.Content, "reads pass through")
+}
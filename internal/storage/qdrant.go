@@ -22,6 +22,9 @@ const (
 	defaultVectorSize       = 1536 // OpenAI embeddings size
 	connectionStatusError   = "error"
 	globalRepository        = "global"
+	// upsertBatchSize bounds the points sent in one upsert, keeping large
+	// batch stores under Qdrant's request size limit
+	upsertBatchSize = 256
 )
 
 // QdrantStore implements VectorStore interface for Qdrant vector database
@@ -30,6 +33,7 @@ type QdrantStore struct {
 	config            *config.QdrantConfig
 	metrics           *StorageMetrics
 	collectionName    string
+	quantization      config.QuantizationConfig
 	relationshipStore *RelationshipStore
 }
 
@@ -43,6 +47,7 @@ func NewQdrantStore(cfg *config.QdrantConfig) *QdrantStore {
 	return &QdrantStore{
 		config:         cfg,
 		collectionName: collectionName,
+		quantization:   cfg.Quantization.ForCollection(collectionName),
 		metrics: &StorageMetrics{
 			OperationCounts:  make(map[string]int64),
 			AverageLatency:   make(map[string]float64),
@@ -100,13 +105,18 @@ func (qs *QdrantStore) Initialize(ctx context.Context) error {
 			VectorsConfig: qdrant.NewVectorsConfig(&qdrant.VectorParams{
 				Size:     uint64(defaultVectorSize),
 				Distance: qdrant.Distance_Cosine,
+				OnDisk:   qdrant.PtrOf(qs.vectorsOnDisk()),
 			}),
+			QuantizationConfig: quantizationConfig(qs.quantization),
 		})
 		if err != nil {
 			qs.metrics.ConnectionStatus = connectionStatusError
 			return fmt.Errorf("failed to create collection %s: %w", qs.collectionName, err)
 		}
-		logging.Info("Created Qdrant collection", "collection", qs.collectionName)
+		logging.Info("Created Qdrant collection", "collection", qs.collectionName, "quantization", qs.quantization.Mode)
+	} else if err := qs.applyQuantization(ctx); err != nil {
+		qs.metrics.ConnectionStatus = connectionStatusError
+		return err
 	}
 
 	qs.metrics.ConnectionStatus = "connected"
@@ -181,6 +191,7 @@ func (qs *QdrantStore) Search(ctx context.Context, query *types.MemoryQuery, emb
 		WithPayload:    qdrant.NewWithPayload(true),
 		Filter:         filter,
 		ScoreThreshold: qdrant.PtrOf(float32(query.MinRelevanceScore)),
+		Params:         quantizationSearchParams(qs.quantization),
 	})
 
 	if err != nil {
@@ -246,6 +257,42 @@ func (qs *QdrantStore) GetByID(ctx context.Context, id string) (*types.Conversat
 	return chunk, nil
 }
 
+// GetByIDs retrieves several chunks with a single Qdrant request
+func (qs *QdrantStore) GetByIDs(ctx context.Context, ids []string) ([]types.ConversationChunk, error) {
+	start := time.Now()
+	defer qs.updateMetrics("get_by_ids", start)
+
+	if len(ids) == 0 {
+		return []types.ConversationChunk{}, nil
+	}
+
+	pointIDs := make([]*qdrant.PointId, 0, len(ids))
+	for _, id := range ids {
+		pointIDs = append(pointIDs, qs.stringToPointID(id))
+	}
+
+	points, err := qs.client.Get(ctx, &qdrant.GetPoints{
+		CollectionName: qs.collectionName,
+		Ids:            pointIDs,
+		WithPayload:    &qdrant.WithPayloadSelector{SelectorOptions: &qdrant.WithPayloadSelector_Enable{Enable: true}},
+		WithVectors:    &qdrant.WithVectorsSelector{SelectorOptions: &qdrant.WithVectorsSelector_Enable{Enable: true}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunks by ID from Qdrant: %w", err)
+	}
+
+	byID := make(map[string]*types.ConversationChunk, len(points))
+	for _, point := range points {
+		chunk, err := qs.pointToChunk(point)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert point to chunk: %w", err)
+		}
+		byID[chunk.ID] = chunk
+	}
+
+	return orderChunksByID(ids, byID), nil
+}
+
 // ListByRepository lists chunks by repository
 func (qs *QdrantStore) ListByRepository(ctx context.Context, repository string, limit, offset int) ([]types.ConversationChunk, error) {
 	start := time.Now()
@@ -266,6 +313,7 @@ func (qs *QdrantStore) ListByRepository(ctx context.Context, repository string,
 			},
 		},
 	}
+	filter = withoutTrash(ctx, filter)
 
 	// Scroll through points (Note: Qdrant Scroll uses cursor-based pagination, not offsets)
 	// For simplicity, we'll get more points and slice manually for offset behavior
@@ -341,6 +389,7 @@ func (qs *QdrantStore) ListBySession(ctx context.Context, sessionID string) ([]t
 			},
 		},
 	}
+	filter = withoutTrash(ctx, filter)
 
 	// Scroll through points
 	points, err := qs.client.Scroll(ctx, &qdrant.ScrollPoints{
@@ -716,6 +765,41 @@ func (qs *QdrantStore) chunkToPoint(chunk *types.ConversationChunk) *qdrant.Poin
 		payload["files_modified"] = qs.stringSliceToValue(chunk.Metadata.FilesModified)
 	}
 
+	// Soft-deleted chunks carry their deletion time; searches filter on its absence
+	if chunk.Metadata.DeletedAt != nil {
+		payload["deleted_at"] = qs.int64ToValue(chunk.Metadata.DeletedAt.Unix())
+	}
+
+	if chunk.Metadata.Version > 0 {
+		payload["version"] = qs.int64ToValue(chunk.Metadata.Version)
+	}
+
+	// Passage links, so passage hits can be traced back to their parent
+	if chunk.Metadata.ParentChunkID != "" {
+		payload["parent_chunk_id"] = qs.stringToValue(chunk.Metadata.ParentChunkID)
+		payload["passage_index"] = qs.int64ToValue(int64(chunk.Metadata.PassageIndex))
+	}
+	if chunk.Metadata.PassageCount > 0 {
+		payload["passage_count"] = qs.int64ToValue(int64(chunk.Metadata.PassageCount))
+	}
+
+	// An explicit memory class; chunks without one filter by their type's default
+	if chunk.Metadata.MemoryClass != "" {
+		payload["memory_class"] = qs.stringToValue(string(chunk.Metadata.MemoryClass))
+	}
+	if chunk.Metadata.PromotedTo != "" {
+		payload["promoted_to"] = qs.stringToValue(chunk.Metadata.PromotedTo)
+	}
+
+	// Provenance fields are flattened so they can be filtered on
+	if p := chunk.Metadata.Provenance; p != nil {
+		for key, field := range provenancePayloadFields(p) {
+			if *field != "" {
+				payload[key] = qs.stringToValue(*field)
+			}
+		}
+	}
+
 	return &qdrant.PointStruct{
 		Id:      qs.stringToPointID(chunk.ID),
 		Vectors: &qdrant.Vectors{VectorsOptions: &qdrant.Vectors_Vector{Vector: &qdrant.Vector{Data: qs.float64ToFloat32(chunk.Embeddings)}}},
@@ -751,6 +835,36 @@ func (qs *QdrantStore) buildChunkFromPayload(id string, embeddings []float64, pa
 		},
 	}
 
+	if deletedAt, ok := payload["deleted_at"]; ok {
+		t := time.Unix(deletedAt.GetIntegerValue(), 0)
+		chunk.Metadata.DeletedAt = &t
+	}
+
+	if version, ok := payload["version"]; ok {
+		chunk.Metadata.Version = version.GetIntegerValue()
+	}
+
+	chunk.Metadata.ParentChunkID = qs.getStringFromPayload(payload, "parent_chunk_id")
+	if index, ok := payload["passage_index"]; ok {
+		chunk.Metadata.PassageIndex = int(index.GetIntegerValue())
+	}
+	if count, ok := payload["passage_count"]; ok {
+		chunk.Metadata.PassageCount = int(count.GetIntegerValue())
+	}
+
+	chunk.Metadata.MemoryClass = types.MemoryClass(qs.getStringFromPayload(payload, "memory_class"))
+	chunk.Metadata.PromotedTo = qs.getStringFromPayload(payload, "promoted_to")
+
+	provenance := &types.Provenance{}
+	for key, field := range provenancePayloadFields(provenance) {
+		if value, ok := payload[key]; ok {
+			*field = value.GetStringValue()
+		}
+	}
+	if !provenance.IsEmpty() {
+		chunk.Metadata.Provenance = provenance
+	}
+
 	return chunk, nil
 }
 
@@ -853,6 +967,11 @@ func (qs *QdrantStore) buildFilter(query *types.MemoryQuery) *qdrant.Filter {
 		})
 	}
 
+	// Memory class filter
+	if len(query.Classes) > 0 {
+		conditions = append(conditions, memoryClassCondition(query.Classes))
+	}
+
 	// Recency-based filtering
 	if query.Recency != "" && query.Recency != types.RecencyAllTime {
 		var cutoffTime time.Time
@@ -879,6 +998,33 @@ func (qs *QdrantStore) buildFilter(query *types.MemoryQuery) *qdrant.Filter {
 		}
 	}
 
+	// Provenance filters
+	if f := query.Provenance; f != nil {
+		for key, value := range map[string]string{
+			"provenance_source_system": f.SourceSystem,
+			"provenance_commit_sha":    f.CommitSHA,
+			"provenance_author":        f.Author,
+			"provenance_capture_tool":  f.CaptureTool,
+		} {
+			if value == "" {
+				continue
+			}
+			conditions = append(conditions, &qdrant.Condition{
+				ConditionOneOf: &qdrant.Condition_Field{
+					Field: &qdrant.FieldCondition{
+						Key:   key,
+						Match: &qdrant.Match{MatchValue: &qdrant.Match_Keyword{Keyword: value}},
+					},
+				},
+			})
+		}
+	}
+
+	// Exclude soft-deleted chunks unless explicitly requested
+	if !query.IncludeDeleted {
+		conditions = append(conditions, notDeletedCondition())
+	}
+
 	if len(conditions) == 0 {
 		return nil
 	}
@@ -886,6 +1032,79 @@ func (qs *QdrantStore) buildFilter(query *types.MemoryQuery) *qdrant.Filter {
 	return &qdrant.Filter{Must: conditions}
 }
 
+// notDeletedCondition matches chunks outside the trash
+func notDeletedCondition() *qdrant.Condition {
+	return &qdrant.Condition{
+		ConditionOneOf: &qdrant.Condition_IsEmpty{
+			IsEmpty: &qdrant.IsEmptyCondition{Key: "deleted_at"},
+		},
+	}
+}
+
+// withoutTrash adds to filter, which may be nil, the condition leaving the
+// trash out of a listing made under ctx
+func withoutTrash(ctx context.Context, filter *qdrant.Filter) *qdrant.Filter {
+	if IncludesDeleted(ctx) {
+		return filter
+	}
+	if filter == nil {
+		filter = &qdrant.Filter{}
+	}
+	filter.Must = append(filter.Must, notDeletedCondition())
+	return filter
+}
+
+// memoryClassCondition matches chunks stored with one of classes, and chunks
+// stored without a class whose type defaults to one of them
+func memoryClassCondition(classes []types.MemoryClass) *qdrant.Condition {
+	classValues := make([]string, len(classes))
+	for i, class := range classes {
+		classValues[i] = string(class)
+	}
+	should := []*qdrant.Condition{{
+		ConditionOneOf: &qdrant.Condition_Field{
+			Field: &qdrant.FieldCondition{
+				Key:   "memory_class",
+				Match: &qdrant.Match{MatchValue: &qdrant.Match_Keywords{Keywords: &qdrant.RepeatedStrings{Strings: classValues}}},
+			},
+		},
+	}}
+
+	if chunkTypes := types.ChunkTypesOfClass(classes); len(chunkTypes) > 0 {
+		typeValues := make([]string, len(chunkTypes))
+		for i, t := range chunkTypes {
+			typeValues[i] = string(t)
+		}
+		should = append(should, &qdrant.Condition{
+			ConditionOneOf: &qdrant.Condition_Filter{
+				Filter: &qdrant.Filter{Must: []*qdrant.Condition{
+					{ConditionOneOf: &qdrant.Condition_IsEmpty{IsEmpty: &qdrant.IsEmptyCondition{Key: "memory_class"}}},
+					{ConditionOneOf: &qdrant.Condition_Field{
+						Field: &qdrant.FieldCondition{
+							Key:   "type",
+							Match: &qdrant.Match{MatchValue: &qdrant.Match_Keywords{Keywords: &qdrant.RepeatedStrings{Strings: typeValues}}},
+						},
+					}},
+				}},
+			},
+		})
+	}
+
+	return &qdrant.Condition{ConditionOneOf: &qdrant.Condition_Filter{Filter: &qdrant.Filter{Should: should}}}
+}
+
+// provenancePayloadFields maps flattened payload keys to the provenance fields they store
+func provenancePayloadFields(p *types.Provenance) map[string]*string {
+	return map[string]*string{
+		"provenance_source_system":        &p.SourceSystem,
+		"provenance_source_url":           &p.SourceURL,
+		"provenance_commit_sha":           &p.CommitSHA,
+		"provenance_author":               &p.Author,
+		"provenance_capture_tool":         &p.CaptureTool,
+		"provenance_capture_tool_version": &p.CaptureToolVersion,
+	}
+}
+
 // Utility conversion methods
 func (qs *QdrantStore) stringToValue(s string) *qdrant.Value {
 	return &qdrant.Value{Kind: &qdrant.Value_StringValue{StringValue: s}}
@@ -978,6 +1197,7 @@ func (qs *QdrantStore) GetAllChunks(ctx context.Context) ([]types.ConversationCh
 	// Use Scroll to get all points with a large limit
 	points, err := qs.client.Scroll(ctx, &qdrant.ScrollPoints{
 		CollectionName: qs.collectionName,
+		Filter:         withoutTrash(ctx, nil),
 		Limit:          qdrant.PtrOf(uint32(10000)), // Large limit, adjust as needed
 		WithPayload:    &qdrant.WithPayloadSelector{SelectorOptions: &qdrant.WithPayloadSelector_Enable{Enable: true}},
 		WithVectors:    &qdrant.WithVectorsSelector{SelectorOptions: &qdrant.WithVectorsSelector_Enable{Enable: true}},
@@ -1089,21 +1309,23 @@ func (qs *QdrantStore) BatchStore(ctx context.Context, chunks []*types.Conversat
 		processedIDs = append(processedIDs, chunk.ID)
 	}
 
-	// Perform batch upsert
-	if len(points) > 0 {
+	// Upsert in batches; chunks of batches that went through stay stored
+	stored := 0
+	for _, batch := range upsertBatches(points, upsertBatchSize) {
 		_, err := qs.client.Upsert(ctx, &qdrant.UpsertPoints{
 			CollectionName: qs.collectionName,
-			Points:         points,
+			Points:         batch,
 		})
 
 		if err != nil {
 			return &BatchResult{
-				Success:      0,
-				Failed:       len(chunks),
+				Success:      stored,
+				Failed:       len(chunks) - stored,
 				Errors:       append(errorMessages, fmt.Sprintf("batch upsert failed: %v", err)),
-				ProcessedIDs: processedIDs,
+				ProcessedIDs: processedIDs[:stored],
 			}, fmt.Errorf("batch store operation failed: %w", err)
 		}
+		stored += len(batch)
 	}
 
 	result := &BatchResult{
@@ -1122,6 +1344,15 @@ func (qs *QdrantStore) BatchStore(ctx context.Context, chunks []*types.Conversat
 	return result, nil
 }
 
+// upsertBatches splits points into batches of at most size points
+func upsertBatches(points []*qdrant.PointStruct, size int) [][]*qdrant.PointStruct {
+	batches := make([][]*qdrant.PointStruct, 0, (len(points)+size-1)/size)
+	for start := 0; start < len(points); start += size {
+		batches = append(batches, points[start:min(start+size, len(points))])
+	}
+	return batches
+}
+
 // BatchDelete deletes multiple chunks by their IDs
 func (qs *QdrantStore) BatchDelete(ctx context.Context, ids []string) (*BatchResult, error) {
 	start := time.Now()
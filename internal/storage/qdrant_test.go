@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/qdrant/go-client/qdrant"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -288,6 +289,15 @@ func (e *NotFoundError) Error() string {
 
 // Test functions
 
+func TestUpsertBatches(t *testing.T) {
+	points := make([]*qdrant.PointStruct, 5)
+	batches := upsertBatches(points, 2)
+	require.Len(t, batches, 3)
+	assert.Len(t, batches[0], 2)
+	assert.Len(t, batches[2], 1)
+	assert.Empty(t, upsertBatches(nil, 2))
+}
+
 func TestQdrantStoreCreation(t *testing.T) {
 	cfg := &config.QdrantConfig{
 		Host:       "localhost",
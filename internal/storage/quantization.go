@@ -0,0 +1,279 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/logging"
+
+	"github.com/qdrant/go-client/qdrant"
+)
+
+const (
+	// float32Bytes is the size of one vector dimension stored unquantized
+	float32Bytes = 4
+
+	// quantizationBinary names binary quantization, which Qdrant supports but
+	// this server does not configure: one bit per dimension
+	quantizationBinary = "binary"
+)
+
+// QuantizationReport describes what quantization saves a collection and what
+// it costs search quality. Recall is the share of the exact top-k neighbours
+// of sampled vectors that quantized search also returns.
+type QuantizationReport struct {
+	Collection     string  `json:"collection"`
+	Mode           string  `json:"mode"`
+	Compression    string  `json:"compression,omitempty"`
+	Points         uint64  `json:"points"`
+	Dimensions     int     `json:"dimensions"`
+	OriginalBytes  int64   `json:"original_bytes"`
+	QuantizedBytes int64   `json:"quantized_bytes"`
+	Reduction      float64 `json:"reduction"`
+	SampledQueries int     `json:"sampled_queries"`
+	K              int     `json:"k"`
+	Recall         float64 `json:"recall"`
+	MinRecall      float64 `json:"min_recall"`
+	Rescore        bool    `json:"rescore"`
+	Oversampling   float64 `json:"oversampling,omitempty"`
+	DurationMs     int64   `json:"duration_ms"`
+}
+
+// quantizationConfig is the Qdrant quantization of a collection, or nil
+// when its vectors are stored unquantized
+func quantizationConfig(q config.QuantizationConfig) *qdrant.QuantizationConfig {
+	switch q.Mode {
+	case config.QuantizationScalar:
+		return qdrant.NewQuantizationScalar(scalarQuantization(q))
+	case config.QuantizationProduct:
+		return qdrant.NewQuantizationProduct(productQuantization(q))
+	default:
+		return nil
+	}
+}
+
+// quantizationDiff is the update that brings a collection to the configured quantization
+func quantizationDiff(q config.QuantizationConfig) *qdrant.QuantizationConfigDiff {
+	switch q.Mode {
+	case config.QuantizationScalar:
+		return qdrant.NewQuantizationDiffScalar(scalarQuantization(q))
+	case config.QuantizationProduct:
+		return qdrant.NewQuantizationDiffProduct(productQuantization(q))
+	default:
+		return qdrant.NewQuantizationDiffDisabled()
+	}
+}
+
+func scalarQuantization(q config.QuantizationConfig) *qdrant.ScalarQuantization {
+	return &qdrant.ScalarQuantization{
+		Type:      qdrant.QuantizationType_Int8,
+		Quantile:  qdrant.PtrOf(float32(q.Quantile)),
+		AlwaysRam: qdrant.PtrOf(q.AlwaysRAM),
+	}
+}
+
+func productQuantization(q config.QuantizationConfig) *qdrant.ProductQuantization {
+	return &qdrant.ProductQuantization{
+		Compression: qdrant.CompressionRatio(qdrant.CompressionRatio_value[q.Compression]),
+		AlwaysRam:   qdrant.PtrOf(q.AlwaysRAM),
+	}
+}
+
+// quantizationSearchParams tells Qdrant how to search a quantized collection,
+// or is nil when the collection is not quantized
+func quantizationSearchParams(q config.QuantizationConfig) *qdrant.SearchParams {
+	if q.Mode != config.QuantizationScalar && q.Mode != config.QuantizationProduct {
+		return nil
+	}
+	return &qdrant.SearchParams{
+		Quantization: &qdrant.QuantizationSearchParams{
+			Rescore:      qdrant.PtrOf(q.Rescore),
+			Oversampling: qdrant.PtrOf(q.Oversampling),
+		},
+	}
+}
+
+// quantizationMode names the quantization a collection currently uses
+func quantizationMode(current *qdrant.QuantizationConfig) string {
+	switch {
+	case current.GetScalar() != nil:
+		return config.QuantizationScalar
+	case current.GetProduct() != nil:
+		return config.QuantizationProduct
+	case current.GetBinary() != nil:
+		return quantizationBinary
+	default:
+		return config.QuantizationNone
+	}
+}
+
+// QuantizedVectorBytes estimates the bytes of the quantized copies of points
+// vectors of dims dimensions
+func QuantizedVectorBytes(q config.QuantizationConfig, points uint64, dims int) int64 {
+	original := int64(points) * int64(dims) * float32Bytes // #nosec G115 -- point counts stay far below int64 limits
+	switch q.Mode {
+	case config.QuantizationScalar:
+		return original / float32Bytes
+	case config.QuantizationProduct:
+		ratio := map[string]int64{"x4": 4, "x8": 8, "x16": 16, "x32": 32, "x64": 64}[q.Compression]
+		if ratio == 0 {
+			ratio = 8
+		}
+		return original / ratio
+	case quantizationBinary:
+		return original / (float32Bytes * 8)
+	default:
+		return original
+	}
+}
+
+// recallAtK is the share of exact that approximate also holds
+func recallAtK(exact, approximate []string) float64 {
+	if len(exact) == 0 {
+		return 1
+	}
+	found := make(map[string]bool, len(approximate))
+	for _, id := range approximate {
+		found[id] = true
+	}
+	hits := 0
+	for _, id := range exact {
+		if found[id] {
+			hits++
+		}
+	}
+	return float64(hits) / float64(len(exact))
+}
+
+// applyQuantization brings an existing collection to the configured
+// quantization. Qdrant rebuilds the quantized vectors in the background.
+func (qs *QdrantStore) applyQuantization(ctx context.Context) error {
+	info, err := qs.client.GetCollectionInfo(ctx, qs.collectionName)
+	if err != nil {
+		return fmt.Errorf("failed to get collection info: %w", err)
+	}
+	current := quantizationMode(info.GetConfig().GetQuantizationConfig())
+	if current == qs.quantization.Mode {
+		return nil
+	}
+
+	update := &qdrant.UpdateCollection{
+		CollectionName:     qs.collectionName,
+		QuantizationConfig: quantizationDiff(qs.quantization),
+		VectorsConfig:      qdrant.NewVectorsConfigDiff(&qdrant.VectorParamsDiff{OnDisk: qdrant.PtrOf(qs.vectorsOnDisk())}),
+	}
+	if err := qs.client.UpdateCollection(ctx, update); err != nil {
+		return fmt.Errorf("failed to change quantization of collection %s: %w", qs.collectionName, err)
+	}
+	logging.Info("Changed Qdrant collection quantization", "collection", qs.collectionName, "from", current, "to", qs.quantization.Mode)
+	return nil
+}
+
+// vectorsOnDisk reports whether original vectors move to disk, which they do
+// when their quantized copies stay in RAM to serve searches
+func (qs *QdrantStore) vectorsOnDisk() bool {
+	return qs.quantization.Mode != config.QuantizationNone && qs.quantization.AlwaysRAM
+}
+
+// QuantizationReport measures the collection's storage footprint under its
+// quantization and the recall of quantized search: sampleSize stored vectors
+// are searched for exactly and through the quantized index, and their top k
+// neighbours compared
+func (qs *QdrantStore) QuantizationReport(ctx context.Context, sampleSize, k int) (*QuantizationReport, error) {
+	start := time.Now()
+	defer qs.updateMetrics("quantization_report", start)
+
+	if sampleSize <= 0 || k <= 0 {
+		return nil, errors.New("sample size and k must be positive")
+	}
+
+	info, err := qs.client.GetCollectionInfo(ctx, qs.collectionName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection info: %w", err)
+	}
+	dims := int(info.GetConfig().GetParams().GetVectorsConfig().GetParams().GetSize()) // #nosec G115 -- vector sizes are small
+	report := &QuantizationReport{
+		Collection:    qs.collectionName,
+		Mode:          quantizationMode(info.GetConfig().GetQuantizationConfig()),
+		Points:        info.GetPointsCount(),
+		Dimensions:    dims,
+		K:             k,
+		OriginalBytes: QuantizedVectorBytes(config.QuantizationConfig{Mode: config.QuantizationNone}, info.GetPointsCount(), dims),
+		Rescore:       qs.quantization.Rescore,
+		Oversampling:  qs.quantization.Oversampling,
+		MinRecall:     1,
+	}
+	// Measure the quantization the collection has, which Qdrant may still be applying
+	footprint := qs.quantization
+	footprint.Mode = report.Mode
+	if product := info.GetConfig().GetQuantizationConfig().GetProduct(); product != nil {
+		footprint.Compression = product.GetCompression().String()
+		report.Compression = footprint.Compression
+	}
+	report.QuantizedBytes = QuantizedVectorBytes(footprint, report.Points, dims)
+	if report.QuantizedBytes > 0 {
+		report.Reduction = float64(report.OriginalBytes) / float64(report.QuantizedBytes)
+	}
+
+	samples, err := qs.client.Scroll(ctx, &qdrant.ScrollPoints{
+		CollectionName: qs.collectionName,
+		Limit:          qdrant.PtrOf(uint32(sampleSize)), // #nosec G115 -- sample sizes are small
+		WithVectors:    qdrant.NewWithVectors(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample vectors: %w", err)
+	}
+
+	exactParams := &qdrant.SearchParams{Exact: qdrant.PtrOf(true), Quantization: &qdrant.QuantizationSearchParams{Ignore: qdrant.PtrOf(true)}}
+	quantizedParams := quantizationSearchParams(footprint)
+	var total float64
+	for _, sample := range samples {
+		vector := sample.GetVectors().GetVector().GetData()
+		if len(vector) == 0 {
+			continue
+		}
+		exact, err := qs.neighbours(ctx, vector, k, exactParams)
+		if err != nil {
+			return nil, err
+		}
+		approximate, err := qs.neighbours(ctx, vector, k, quantizedParams)
+		if err != nil {
+			return nil, err
+		}
+		recall := recallAtK(exact, approximate)
+		total += recall
+		if recall < report.MinRecall {
+			report.MinRecall = recall
+		}
+		report.SampledQueries++
+	}
+	if report.SampledQueries > 0 {
+		report.Recall = total / float64(report.SampledQueries)
+	} else {
+		report.Recall = 1
+	}
+
+	report.DurationMs = time.Since(start).Milliseconds()
+	return report, nil
+}
+
+// neighbours returns the IDs of the k points nearest vector
+func (qs *QdrantStore) neighbours(ctx context.Context, vector []float32, k int, params *qdrant.SearchParams) ([]string, error) {
+	points, err := qs.client.Query(ctx, &qdrant.QueryPoints{
+		CollectionName: qs.collectionName,
+		Query:          qdrant.NewQuery(vector...),
+		Limit:          qdrant.PtrOf(uint64(k)), // #nosec G115 -- k is positive
+		Params:         params,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for neighbours: %w", err)
+	}
+	ids := make([]string, 0, len(points))
+	for _, point := range points {
+		ids = append(ids, qs.pointIDToString(point.GetId()))
+	}
+	return ids, nil
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"testing"
+
+	"lerian-mcp-memory/internal/config"
+
+	"github.com/qdrant/go-client/qdrant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuantizedVectorBytes(t *testing.T) {
+	quantization := config.DefaultConfig().Qdrant.Quantization
+	original := int64(1000 * 1536 * 4)
+
+	assert.Equal(t, original, QuantizedVectorBytes(quantization, 1000, 1536), "mode none keeps full vectors")
+
+	quantization.Mode = config.QuantizationScalar
+	assert.Equal(t, original/4, QuantizedVectorBytes(quantization, 1000, 1536))
+
+	quantization.Mode = config.QuantizationProduct
+	assert.Equal(t, original/8, QuantizedVectorBytes(quantization, 1000, 1536))
+	quantization.Compression = "x32"
+	assert.Equal(t, original/32, QuantizedVectorBytes(quantization, 1000, 1536))
+}
+
+func TestQuantizationSettings(t *testing.T) {
+	quantization := config.DefaultConfig().Qdrant.Quantization
+	quantization.Collections = map[string]string{"claude_memory": config.QuantizationProduct}
+
+	assert.Nil(t, quantizationConfig(quantization.ForCollection("archive")), "unquantized collections have no quantization config")
+	assert.Nil(t, quantizationSearchParams(quantization.ForCollection("archive")))
+	assert.NotNil(t, quantizationDiff(quantization.ForCollection("archive")).GetDisabled(), "mode none disables existing quantization")
+
+	product := quantization.ForCollection("claude_memory")
+	created := quantizationConfig(product)
+	require.NotNil(t, created.GetProduct())
+	assert.Equal(t, qdrant.CompressionRatio_x8, created.GetProduct().GetCompression())
+	assert.Equal(t, config.QuantizationProduct, quantizationMode(created))
+	assert.Equal(t, config.QuantizationNone, quantizationMode(nil))
+
+	params := quantizationSearchParams(product)
+	require.NotNil(t, params)
+	assert.True(t, params.GetQuantization().GetRescore())
+	assert.InDelta(t, 2.0, params.GetQuantization().GetOversampling(), 1e-9)
+
+	scalar := quantization.ForCollection("archive")
+	scalar.Mode = config.QuantizationScalar
+	assert.Equal(t, qdrant.QuantizationType_Int8, quantizationConfig(scalar).GetScalar().GetType())
+}
+
+func TestRecallAtK(t *testing.T) {
+	assert.InDelta(t, 1.0, recallAtK([]string{"a", "b"}, []string{"b", "a"}), 1e-9, "order does not matter")
+	assert.InDelta(t, 0.5, recallAtK([]string{"a", "b"}, []string{"a", "c"}), 1e-9)
+	assert.InDelta(t, 1.0, recallAtK(nil, nil), 1e-9, "nothing to find is full recall")
+}
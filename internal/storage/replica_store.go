@@ -0,0 +1,272 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/pkg/types"
+)
+
+// replicaRetryAfter is how long a replica that failed is skipped
+const replicaRetryAfter = 30 * time.Second
+
+// ReadReplica is a read-only copy of the primary store
+type ReadReplica struct {
+	Name  string
+	Store VectorStore
+}
+
+// ReplicaRoutingStore wraps a primary VectorStore and sends search reads
+// (Search, FindSimilar, GetByID, GetByIDs, ListByRepository, ListBySession)
+// to read replicas in turn. Writes, relationships, statistics and full scans
+// always use the primary.
+//
+// Replicas may lag the primary by up to the staleness bound, so for that long
+// after a chunk write every read goes to the primary and callers read their
+// own writes. A read a replica fails but the primary serves is retried on the
+// primary, and the replica is skipped for replicaRetryAfter.
+type ReplicaRoutingStore struct {
+	VectorStore
+
+	replicas     []*replicaState
+	maxStaleness time.Duration
+
+	mutex     sync.Mutex
+	next      int
+	lastWrite time.Time
+	stats     ReplicaStats
+}
+
+// replicaState tracks one replica's availability
+type replicaState struct {
+	ReadReplica
+	unavailableUntil time.Time
+	status           ReplicaStatus
+}
+
+// ReplicaStats describes read routing
+type ReplicaStats struct {
+	PrimaryReads   int64           `json:"primary_reads"`
+	ReplicaReads   int64           `json:"replica_reads"`
+	Fallbacks      int64           `json:"fallbacks"`
+	MaxStalenessMs int64           `json:"max_staleness_ms"`
+	Replicas       []ReplicaStatus `json:"replicas"`
+}
+
+// ReplicaStatus describes one read replica
+type ReplicaStatus struct {
+	Name      string `json:"name"`
+	Available bool   `json:"available"`
+	Reads     int64  `json:"reads"`
+	Errors    int64  `json:"errors"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// NewReplicaRoutingStore routes reads across replicas of primary. Replicas
+// are assumed to lag the primary by at most maxStaleness.
+func NewReplicaRoutingStore(primary VectorStore, replicas []ReadReplica, maxStaleness time.Duration) *ReplicaRoutingStore {
+	store := &ReplicaRoutingStore{
+		VectorStore:  primary,
+		maxStaleness: maxStaleness,
+	}
+	for _, replica := range replicas {
+		store.replicas = append(store.replicas, &replicaState{
+			ReadReplica: replica,
+			status:      ReplicaStatus{Name: replica.Name},
+		})
+	}
+	return store
+}
+
+// Initialize initializes the primary, then connects to the replicas. A
+// replica that cannot be reached is skipped until it has had time to recover.
+func (s *ReplicaRoutingStore) Initialize(ctx context.Context) error {
+	if err := s.VectorStore.Initialize(ctx); err != nil {
+		return err
+	}
+	for _, replica := range s.replicas {
+		if err := replica.Store.Initialize(ctx); err != nil {
+			logging.Warn("Read replica unavailable, reading from primary", "replica", replica.Name, "error", err)
+			s.markFailed(replica, err)
+		}
+	}
+	return nil
+}
+
+// Close closes the replicas and the primary
+func (s *ReplicaRoutingStore) Close() error {
+	for _, replica := range s.replicas {
+		if err := replica.Store.Close(); err != nil {
+			logging.Warn("Failed to close read replica", "replica", replica.Name, "error", err)
+		}
+	}
+	return s.VectorStore.Close()
+}
+
+// Stats returns routing counters and the state of each replica
+func (s *ReplicaRoutingStore) Stats() ReplicaStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stats := s.stats
+	stats.MaxStalenessMs = s.maxStaleness.Milliseconds()
+	stats.Replicas = make([]ReplicaStatus, len(s.replicas))
+	now := time.Now()
+	for i, replica := range s.replicas {
+		stats.Replicas[i] = replica.status
+		stats.Replicas[i].Available = !now.Before(replica.unavailableUntil)
+	}
+	return stats
+}
+
+// pick returns the replica to serve a read, or nil when the primary must
+func (s *ReplicaRoutingStore) pick() *replicaState {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	if len(s.replicas) == 0 || now.Sub(s.lastWrite) < s.maxStaleness {
+		s.stats.PrimaryReads++
+		return nil
+	}
+	for range s.replicas {
+		replica := s.replicas[s.next]
+		s.next = (s.next + 1) % len(s.replicas)
+		if !now.Before(replica.unavailableUntil) {
+			s.stats.ReplicaReads++
+			replica.status.Reads++
+			return replica
+		}
+	}
+	s.stats.PrimaryReads++
+	return nil
+}
+
+// markFailed takes a replica out of rotation for replicaRetryAfter
+func (s *ReplicaRoutingStore) markFailed(replica *replicaState, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	replica.unavailableUntil = time.Now().Add(replicaRetryAfter)
+	replica.status.Errors++
+	replica.status.LastError = err.Error()
+}
+
+// recordWrite starts the staleness window in which reads use the primary
+func (s *ReplicaRoutingStore) recordWrite() {
+	s.mutex.Lock()
+	s.lastWrite = time.Now()
+	s.mutex.Unlock()
+}
+
+// routeRead runs read on a replica when one may serve it, falling back to the primary
+func routeRead[T any](ctx context.Context, s *ReplicaRoutingStore, read func(store VectorStore) (T, error)) (T, error) {
+	replica := s.pick()
+	if replica == nil {
+		return read(s.VectorStore)
+	}
+
+	result, err := read(replica.Store)
+	if err == nil || ctx.Err() != nil {
+		return result, err
+	}
+
+	s.mutex.Lock()
+	s.stats.Fallbacks++
+	s.stats.PrimaryReads++
+	s.mutex.Unlock()
+
+	result, primaryErr := read(s.VectorStore)
+	if primaryErr == nil {
+		// The primary could serve it, so the replica is down or too far behind
+		logging.Warn("Read replica failed, reading from primary", "replica", replica.Name, "error", err)
+		s.markFailed(replica, err)
+	}
+	return result, primaryErr
+}
+
+// Search runs on a replica
+func (s *ReplicaRoutingStore) Search(ctx context.Context, query *types.MemoryQuery, embeddings []float64) (*types.SearchResults, error) {
+	return routeRead(ctx, s, func(store VectorStore) (*types.SearchResults, error) {
+		return store.Search(ctx, query, embeddings)
+	})
+}
+
+// FindSimilar runs on a replica
+func (s *ReplicaRoutingStore) FindSimilar(ctx context.Context, content string, chunkType *types.ChunkType, limit int) ([]types.ConversationChunk, error) {
+	return routeRead(ctx, s, func(store VectorStore) ([]types.ConversationChunk, error) {
+		return store.FindSimilar(ctx, content, chunkType, limit)
+	})
+}
+
+// GetByID runs on a replica
+func (s *ReplicaRoutingStore) GetByID(ctx context.Context, id string) (*types.ConversationChunk, error) {
+	return routeRead(ctx, s, func(store VectorStore) (*types.ConversationChunk, error) {
+		return store.GetByID(ctx, id)
+	})
+}
+
+// GetByIDs runs on a replica
+func (s *ReplicaRoutingStore) GetByIDs(ctx context.Context, ids []string) ([]types.ConversationChunk, error) {
+	return routeRead(ctx, s, func(store VectorStore) ([]types.ConversationChunk, error) {
+		return store.GetByIDs(ctx, ids)
+	})
+}
+
+// ListByRepository runs on a replica
+func (s *ReplicaRoutingStore) ListByRepository(ctx context.Context, repository string, limit, offset int) ([]types.ConversationChunk, error) {
+	return routeRead(ctx, s, func(store VectorStore) ([]types.ConversationChunk, error) {
+		return store.ListByRepository(ctx, repository, limit, offset)
+	})
+}
+
+// ListBySession runs on a replica
+func (s *ReplicaRoutingStore) ListBySession(ctx context.Context, sessionID string) ([]types.ConversationChunk, error) {
+	return routeRead(ctx, s, func(store VectorStore) ([]types.ConversationChunk, error) {
+		return store.ListBySession(ctx, sessionID)
+	})
+}
+
+// Store writes to the primary
+func (s *ReplicaRoutingStore) Store(ctx context.Context, chunk *types.ConversationChunk) error {
+	defer s.recordWrite()
+	return s.VectorStore.Store(ctx, chunk)
+}
+
+// StoreChunk writes to the primary
+func (s *ReplicaRoutingStore) StoreChunk(ctx context.Context, chunk *types.ConversationChunk) error {
+	defer s.recordWrite()
+	return s.VectorStore.StoreChunk(ctx, chunk)
+}
+
+// Update writes to the primary
+func (s *ReplicaRoutingStore) Update(ctx context.Context, chunk *types.ConversationChunk) error {
+	defer s.recordWrite()
+	return s.VectorStore.Update(ctx, chunk)
+}
+
+// Delete writes to the primary
+func (s *ReplicaRoutingStore) Delete(ctx context.Context, id string) error {
+	defer s.recordWrite()
+	return s.VectorStore.Delete(ctx, id)
+}
+
+// BatchStore writes to the primary
+func (s *ReplicaRoutingStore) BatchStore(ctx context.Context, chunks []*types.ConversationChunk) (*BatchResult, error) {
+	defer s.recordWrite()
+	return s.VectorStore.BatchStore(ctx, chunks)
+}
+
+// BatchDelete writes to the primary
+func (s *ReplicaRoutingStore) BatchDelete(ctx context.Context, ids []string) (*BatchResult, error) {
+	defer s.recordWrite()
+	return s.VectorStore.BatchDelete(ctx, ids)
+}
+
+// Cleanup writes to the primary
+func (s *ReplicaRoutingStore) Cleanup(ctx context.Context, retentionDays int) (int, error) {
+	defer s.recordWrite()
+	return s.VectorStore.Cleanup(ctx, retentionDays)
+}
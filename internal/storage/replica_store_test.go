@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingReadStore fails every read by ID, like an unreachable replica
+type failingReadStore struct {
+	VectorStore
+}
+
+func (s *failingReadStore) GetByID(context.Context, string) (*types.ConversationChunk, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestReplicaRoutingStore_RoutesReads(t *testing.T) {
+	ctx := context.Background()
+	primary := NewKeywordStore("")
+	replica := NewKeywordStore("")
+	require.NoError(t, replica.Store(ctx, newQueuedChunk("replicated")))
+
+	store := NewReplicaRoutingStore(primary, []ReadReplica{{Name: "replica-1", Store: replica}}, 50*time.Millisecond)
+
+	// Reads go to the replica
+	_, err := store.GetByID(ctx, "replicated")
+	require.NoError(t, err)
+
+	// Writes go to the primary, and reads stay there while the replica may lag
+	require.NoError(t, store.Store(ctx, newQueuedChunk("fresh")))
+	_, err = replica.GetByID(ctx, "fresh")
+	assert.Error(t, err)
+	chunk, err := store.GetByID(ctx, "fresh")
+	require.NoError(t, err)
+	assert.Equal(t, "fresh", chunk.ID)
+
+	// Once the staleness window passes, a replica missing the chunk is
+	// bypassed for the primary and taken out of rotation
+	time.Sleep(60 * time.Millisecond)
+	_, err = store.GetByID(ctx, "fresh")
+	require.NoError(t, err)
+
+	stats := store.Stats()
+	assert.Equal(t, int64(2), stats.ReplicaReads)
+	assert.Equal(t, int64(2), stats.PrimaryReads)
+	assert.Equal(t, int64(1), stats.Fallbacks)
+	assert.Equal(t, int64(50), stats.MaxStalenessMs)
+	require.Len(t, stats.Replicas, 1)
+	assert.False(t, stats.Replicas[0].Available)
+	assert.Contains(t, stats.Replicas[0].LastError, "chunk not found")
+
+	// Unavailable replicas are skipped
+	_, err = store.GetByID(ctx, "fresh")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), store.Stats().ReplicaReads)
+}
+
+func TestReplicaRoutingStore_MissingEverywhere(t *testing.T) {
+	ctx := context.Background()
+	replica := &failingReadStore{VectorStore: NewKeywordStore("")}
+	store := NewReplicaRoutingStore(NewKeywordStore(""), []ReadReplica{{Name: "replica-1", Store: replica}}, 0)
+
+	// The primary cannot serve it either, so the replica is not blamed
+	_, err := store.GetByID(ctx, "missing")
+	assert.Error(t, err)
+	stats := store.Stats()
+	assert.Equal(t, int64(1), stats.Fallbacks)
+	assert.True(t, stats.Replicas[0].Available)
+}
@@ -163,6 +163,22 @@ func (r *RetryableVectorStore) GetByID(ctx context.Context, id string) (*types.C
 	return chunk, nil
 }
 
+// GetByIDs retrieves several chunks with retries
+func (r *RetryableVectorStore) GetByIDs(ctx context.Context, ids []string) ([]types.ConversationChunk, error) {
+	var chunks []types.ConversationChunk
+
+	result := r.retrier.Do(ctx, func(ctx context.Context) error {
+		var err error
+		chunks, err = r.store.GetByIDs(ctx, ids)
+		return err
+	})
+
+	if result.Err != nil {
+		return nil, fmt.Errorf("failed to get chunks by ID after %d attempts: %w", result.Attempts, result.Err)
+	}
+	return chunks, nil
+}
+
 // ListByRepository lists chunks with retries
 func (r *RetryableVectorStore) ListByRepository(ctx context.Context, repository string, limit, offset int) ([]types.ConversationChunk, error) {
 	var chunks []types.ConversationChunk
@@ -0,0 +1,569 @@
+// Package storagetest holds the compliance suite every storage.VectorStore
+// backend must pass. It is imported from tests only.
+package storagetest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// suiteKeyword appears in every chunk stored by the suite so that both vector and
+// keyword backends return them for the same query
+const suiteKeyword = "compliance"
+
+// Suite is the compliance suite every storage.VectorStore backend must pass.
+// It pins down the behavior callers rely on regardless of backend: CRUD and not-found
+// errors, search filter semantics including soft-delete exclusion, pagination,
+// retention cleanup (delete by age), batch store/get/delete, deleting what a
+// filter selects, relationships and concurrent access.
+//
+// Backends run it from their own tests:
+//
+//	func TestMyStoreCompliance(t *testing.T) {
+//		suite := &storagetest.Suite{
+//			NewStore: func(t *testing.T) storage.VectorStore { return newMyStore(t) },
+//			Dimension: 1536,
+//		}
+//		suite.Run(t)
+//	}
+type Suite struct {
+	// NewStore returns a fresh, empty store; Initialize is called by the suite. Required.
+	NewStore func(t *testing.T) storage.VectorStore
+
+	// Dimension is the size of the embeddings attached to stored chunks.
+	// Zero stores chunks without embeddings (keyword-only backends).
+	Dimension int
+
+	// Concurrency is the number of goroutines used by the concurrency test (default 8)
+	Concurrency int
+}
+
+// Run executes every compliance test as a subtest of t
+func (s *Suite) Run(t *testing.T) {
+	t.Helper()
+	require.NotNil(t, s.NewStore, "Suite.NewStore is required")
+
+	t.Run("CRUD", s.testCRUD)
+	t.Run("StoreValidation", s.testStoreValidation)
+	t.Run("SearchFilters", s.testSearchFilters)
+	t.Run("SearchMemoryClass", s.testSearchMemoryClass)
+	t.Run("SearchRecency", s.testSearchRecency)
+	t.Run("SearchExcludesDeleted", s.testSearchExcludesDeleted)
+	t.Run("ListingsExcludeDeleted", s.testListingsExcludeDeleted)
+	t.Run("Pagination", s.testPagination)
+	t.Run("ListBySession", s.testListBySession)
+	t.Run("Batch", s.testBatch)
+	t.Run("CleanupByAge", s.testCleanupByAge)
+	t.Run("DeleteByFilter", s.testDeleteByFilter)
+	t.Run("Relationships", s.testRelationships)
+	t.Run("Concurrency", s.testConcurrency)
+}
+
+// newStore creates and initializes a store for one subtest. Close is registered first
+// so that cleanups registered by NewStore (e.g. dropping a collection) run while the store is open.
+func (s *Suite) newStore(t *testing.T) storage.VectorStore {
+	t.Helper()
+	var store storage.VectorStore
+	t.Cleanup(func() {
+		if store != nil {
+			_ = store.Close()
+		}
+	})
+
+	store = s.NewStore(t)
+	require.NoError(t, store.Initialize(context.Background()))
+	return store
+}
+
+// embedding returns a deterministic vector of the configured dimension
+func (s *Suite) embedding() []float64 {
+	if s.Dimension <= 0 {
+		return nil
+	}
+	vector := make([]float64, s.Dimension)
+	for i := range vector {
+		vector[i] = 1.0 / float64(i+1)
+	}
+	return vector
+}
+
+// chunk builds a valid chunk for the suite
+func (s *Suite) chunk(t *testing.T, session, repo, content string, chunkType types.ChunkType) *types.ConversationChunk {
+	t.Helper()
+	chunk, err := types.NewConversationChunk(session, suiteKeyword+" "+content, chunkType, &types.ChunkMetadata{
+		Repository: repo,
+		Outcome:    types.OutcomeSuccess,
+		Difficulty: types.DifficultySimple,
+	})
+	require.NoError(t, err)
+	chunk.Embeddings = s.embedding()
+	return chunk
+}
+
+// query builds a search query matching every suite chunk
+func (s *Suite) query() *types.MemoryQuery {
+	query := types.NewMemoryQuery(suiteKeyword)
+	query.MinRelevanceScore = 0
+	query.Recency = types.RecencyAllTime
+	query.Limit = 100
+	return query
+}
+
+func (s *Suite) testCRUD(t *testing.T) {
+	ctx := context.Background()
+	store := s.newStore(t)
+
+	chunk := s.chunk(t, "crud-session", "crud-repo", "original content", types.ChunkTypeProblem)
+	require.NoError(t, store.Store(ctx, chunk))
+
+	got, err := store.GetByID(ctx, chunk.ID)
+	require.NoError(t, err)
+	assert.Equal(t, chunk.ID, got.ID)
+	assert.Equal(t, chunk.Content, got.Content)
+	assert.Equal(t, chunk.Type, got.Type)
+	assert.Equal(t, "crud-repo", got.Metadata.Repository)
+
+	chunk.Content = suiteKeyword + " updated content"
+	require.NoError(t, store.Update(ctx, chunk))
+	got, err = store.GetByID(ctx, chunk.ID)
+	require.NoError(t, err)
+	assert.Equal(t, chunk.Content, got.Content)
+
+	require.NoError(t, store.Delete(ctx, chunk.ID))
+	_, err = store.GetByID(ctx, chunk.ID)
+	assert.Error(t, err, "GetByID must fail for a deleted chunk")
+
+	missing := s.chunk(t, "crud-session", "crud-repo", "never stored", types.ChunkTypeProblem)
+	_, err = store.GetByID(ctx, missing.ID)
+	assert.Error(t, err, "GetByID must fail for an unknown ID")
+}
+
+func (s *Suite) testStoreValidation(t *testing.T) {
+	ctx := context.Background()
+	store := s.newStore(t)
+
+	noID := s.chunk(t, "validation-session", "repo", "no id", types.ChunkTypeProblem)
+	noID.ID = ""
+	assert.Error(t, store.Store(ctx, noID), "Store must reject chunks without an ID")
+
+	noType := s.chunk(t, "validation-session", "repo", "no type", types.ChunkTypeProblem)
+	noType.Type = ""
+	assert.Error(t, store.Store(ctx, noType), "Store must reject chunks without a type")
+}
+
+func (s *Suite) testSearchFilters(t *testing.T) {
+	ctx := context.Background()
+	store := s.newStore(t)
+
+	target := s.chunk(t, "filter-session", "repo-a", "target", types.ChunkTypeProblem)
+	otherRepo := s.chunk(t, "filter-session", "repo-b", "other repository", types.ChunkTypeProblem)
+	otherType := s.chunk(t, "filter-session", "repo-a", "other type", types.ChunkTypeSolution)
+	for _, c := range []*types.ConversationChunk{target, otherRepo, otherType} {
+		require.NoError(t, store.Store(ctx, c))
+	}
+
+	repo := "repo-a"
+	query := s.query()
+	query.Repository = &repo
+	results, err := store.Search(ctx, query, s.embedding())
+	require.NoError(t, err)
+	ids := resultIDs(results)
+	assert.Contains(t, ids, target.ID)
+	assert.Contains(t, ids, otherType.ID)
+	assert.NotContains(t, ids, otherRepo.ID, "repository filter must exclude other repositories")
+
+	query.Types = []types.ChunkType{types.ChunkTypeProblem}
+	results, err = store.Search(ctx, query, s.embedding())
+	require.NoError(t, err)
+	assert.Equal(t, []string{target.ID}, resultIDs(results), "type filter must combine with repository filter")
+
+	query = s.query()
+	query.Limit = 1
+	results, err = store.Search(ctx, query, s.embedding())
+	require.NoError(t, err)
+	assert.Len(t, results.Results, 1, "search must honor the limit")
+}
+
+func (s *Suite) testSearchMemoryClass(t *testing.T) {
+	ctx := context.Background()
+	store := s.newStore(t)
+
+	episodic := s.chunk(t, "class-session", "class-repo", "session log", types.ChunkTypeProblem)
+	procedural := s.chunk(t, "class-session", "class-repo", "how-to", types.ChunkTypeSolution)
+	semantic := s.chunk(t, "class-session", "class-repo", "distilled fact", types.ChunkTypeDiscussion)
+	semantic.Metadata.MemoryClass = types.MemoryClassSemantic
+	for _, c := range []*types.ConversationChunk{episodic, procedural, semantic} {
+		require.NoError(t, store.Store(ctx, c))
+	}
+
+	query := s.query()
+	query.Classes = []types.MemoryClass{types.MemoryClassSemantic, types.MemoryClassProcedural}
+	results, err := store.Search(ctx, query, s.embedding())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{procedural.ID, semantic.ID}, resultIDs(results),
+		"class filter must match explicit classes and the defaults of chunks without one")
+
+	query.Classes = []types.MemoryClass{types.MemoryClassEpisodic}
+	results, err = store.Search(ctx, query, s.embedding())
+	require.NoError(t, err)
+	assert.Equal(t, []string{episodic.ID}, resultIDs(results), "an explicit class overrides the type's default")
+
+	got, err := store.GetByID(ctx, semantic.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.MemoryClassSemantic, got.Metadata.MemoryClass)
+}
+
+func (s *Suite) testSearchRecency(t *testing.T) {
+	ctx := context.Background()
+	store := s.newStore(t)
+
+	fresh := s.chunk(t, "recency-session", "recency-repo", "fresh", types.ChunkTypeProblem)
+	stale := s.chunk(t, "recency-session", "recency-repo", "stale", types.ChunkTypeProblem)
+	stale.Timestamp = time.Now().AddDate(0, -3, 0)
+	require.NoError(t, store.Store(ctx, fresh))
+	require.NoError(t, store.Store(ctx, stale))
+
+	query := s.query()
+	query.Recency = types.RecencyRecent
+	results, err := store.Search(ctx, query, s.embedding())
+	require.NoError(t, err)
+	ids := resultIDs(results)
+	assert.Contains(t, ids, fresh.ID)
+	assert.NotContains(t, ids, stale.ID, "recent filter must exclude chunks older than a week")
+
+	query.Recency = types.RecencyAllTime
+	results, err = store.Search(ctx, query, s.embedding())
+	require.NoError(t, err)
+	assert.Len(t, results.Results, 2)
+}
+
+func (s *Suite) testSearchExcludesDeleted(t *testing.T) {
+	ctx := context.Background()
+	store := s.newStore(t)
+
+	live := s.chunk(t, "trash-session", "trash-repo", "live", types.ChunkTypeProblem)
+	trashed := s.chunk(t, "trash-session", "trash-repo", "trashed", types.ChunkTypeProblem)
+	deletedAt := time.Now()
+	trashed.Metadata.DeletedAt = &deletedAt
+	require.NoError(t, store.Store(ctx, live))
+	require.NoError(t, store.Store(ctx, trashed))
+
+	results, err := store.Search(ctx, s.query(), s.embedding())
+	require.NoError(t, err)
+	assert.Equal(t, []string{live.ID}, resultIDs(results), "search must exclude soft-deleted chunks by default")
+
+	query := s.query()
+	query.IncludeDeleted = true
+	results, err = store.Search(ctx, query, s.embedding())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{live.ID, trashed.ID}, resultIDs(results))
+
+	got, err := store.GetByID(ctx, trashed.ID)
+	require.NoError(t, err, "soft-deleted chunks stay addressable by ID")
+	require.NotNil(t, got.Metadata.DeletedAt)
+	assert.WithinDuration(t, deletedAt, *got.Metadata.DeletedAt, time.Second)
+}
+
+func (s *Suite) testListingsExcludeDeleted(t *testing.T) {
+	ctx := context.Background()
+	store := s.newStore(t)
+
+	live := s.chunk(t, "list-trash-session", "list-trash-repo", "live", types.ChunkTypeProblem)
+	trashed := s.chunk(t, "list-trash-session", "list-trash-repo", "trashed", types.ChunkTypeProblem)
+	deletedAt := time.Now()
+	trashed.Metadata.DeletedAt = &deletedAt
+	require.NoError(t, store.Store(ctx, live))
+	require.NoError(t, store.Store(ctx, trashed))
+
+	listings := map[string]func(context.Context) ([]types.ConversationChunk, error){
+		"ListByRepository": func(ctx context.Context) ([]types.ConversationChunk, error) {
+			return store.ListByRepository(ctx, "list-trash-repo", 10, 0)
+		},
+		"ListBySession": func(ctx context.Context) ([]types.ConversationChunk, error) {
+			return store.ListBySession(ctx, "list-trash-session")
+		},
+		"GetAllChunks": store.GetAllChunks,
+	}
+	for name, list := range listings {
+		chunks, err := list(ctx)
+		require.NoError(t, err, name)
+		ids := chunkIDs(chunks)
+		assert.Contains(t, ids, live.ID, name)
+		assert.NotContains(t, ids, trashed.ID, "%s must exclude soft-deleted chunks by default", name)
+
+		chunks, err = list(storage.WithDeleted(ctx))
+		require.NoError(t, err, name)
+		assert.Subset(t, chunkIDs(chunks), []string{live.ID, trashed.ID}, "%s must include soft-deleted chunks when asked", name)
+	}
+}
+
+func (s *Suite) testPagination(t *testing.T) {
+	ctx := context.Background()
+	store := s.newStore(t)
+
+	const repo, total = "pagination-repo", 5
+	stored := make(map[string]bool, total)
+	for i := 0; i < total; i++ {
+		chunk := s.chunk(t, "pagination-session", repo, fmt.Sprintf("page item %d", i), types.ChunkTypeProblem)
+		chunk.Timestamp = time.Now().Add(time.Duration(-i) * time.Minute)
+		require.NoError(t, store.Store(ctx, chunk))
+		stored[chunk.ID] = true
+	}
+
+	seen := make(map[string]bool, total)
+	for offset, want := range map[int]int{0: 2, 2: 2, 4: 1, 10: 0} {
+		page, err := store.ListByRepository(ctx, repo, 2, offset)
+		require.NoError(t, err)
+		assert.Len(t, page, want, "page at offset %d", offset)
+		for i := range page {
+			assert.True(t, stored[page[i].ID], "page returned a chunk from another repository")
+			assert.False(t, seen[page[i].ID], "chunk %s returned on more than one page", page[i].ID)
+			seen[page[i].ID] = true
+		}
+	}
+	assert.Len(t, seen, total, "pages must cover every chunk")
+}
+
+func (s *Suite) testListBySession(t *testing.T) {
+	ctx := context.Background()
+	store := s.newStore(t)
+
+	a1 := s.chunk(t, "session-a", "repo", "first", types.ChunkTypeProblem)
+	a2 := s.chunk(t, "session-a", "repo", "second", types.ChunkTypeSolution)
+	b1 := s.chunk(t, "session-b", "repo", "other session", types.ChunkTypeProblem)
+	for _, c := range []*types.ConversationChunk{a1, a2, b1} {
+		require.NoError(t, store.Store(ctx, c))
+	}
+
+	chunks, err := store.ListBySession(ctx, "session-a")
+	require.NoError(t, err)
+	ids := make([]string, 0, len(chunks))
+	for i := range chunks {
+		ids = append(ids, chunks[i].ID)
+	}
+	assert.ElementsMatch(t, []string{a1.ID, a2.ID}, ids)
+}
+
+func (s *Suite) testBatch(t *testing.T) {
+	ctx := context.Background()
+	store := s.newStore(t)
+
+	chunks := make([]*types.ConversationChunk, 0, 3)
+	ids := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		chunk := s.chunk(t, "batch-session", "batch-repo", fmt.Sprintf("batch item %d", i), types.ChunkTypeProblem)
+		chunks = append(chunks, chunk)
+		ids = append(ids, chunk.ID)
+	}
+
+	stored, err := store.BatchStore(ctx, chunks)
+	require.NoError(t, err)
+	assert.Equal(t, 3, stored.Success)
+	assert.Equal(t, 0, stored.Failed)
+
+	unknown := s.chunk(t, "batch-session", "batch-repo", "never stored", types.ChunkTypeProblem)
+	fetched, err := store.GetByIDs(ctx, []string{ids[2], unknown.ID, ids[0], ids[2]})
+	require.NoError(t, err)
+	fetchedIDs := make([]string, 0, len(fetched))
+	for i := range fetched {
+		fetchedIDs = append(fetchedIDs, fetched[i].ID)
+	}
+	assert.Equal(t, []string{ids[2], ids[0]}, fetchedIDs, "GetByIDs must skip unknown IDs, drop duplicates and keep request order")
+
+	empty, err := store.GetByIDs(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	deleted, err := store.BatchDelete(ctx, ids[:2])
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted.Success)
+
+	for _, id := range ids[:2] {
+		_, err := store.GetByID(ctx, id)
+		assert.Error(t, err, "batch-deleted chunk %s must be gone", id)
+	}
+	_, err = store.GetByID(ctx, ids[2])
+	assert.NoError(t, err, "chunks outside the batch must survive")
+}
+
+func (s *Suite) testCleanupByAge(t *testing.T) {
+	ctx := context.Background()
+	store := s.newStore(t)
+
+	old := s.chunk(t, "cleanup-session", "cleanup-repo", "old", types.ChunkTypeProblem)
+	old.Timestamp = time.Now().AddDate(0, 0, -10)
+	current := s.chunk(t, "cleanup-session", "cleanup-repo", "current", types.ChunkTypeProblem)
+	require.NoError(t, store.Store(ctx, old))
+	require.NoError(t, store.Store(ctx, current))
+
+	deleted, err := store.Cleanup(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	_, err = store.GetByID(ctx, old.ID)
+	assert.Error(t, err, "cleanup must delete chunks older than the retention period")
+	_, err = store.GetByID(ctx, current.ID)
+	assert.NoError(t, err, "cleanup must keep chunks inside the retention period")
+}
+
+// testDeleteByFilter deletes the chunks a filtered search selects, as bulk
+// deletes do, and checks that exactly those chunks are gone
+func (s *Suite) testDeleteByFilter(t *testing.T) {
+	ctx := context.Background()
+	store := s.newStore(t)
+
+	target := s.chunk(t, "filter-delete-session", "filter-delete-a", "doomed problem", types.ChunkTypeProblem)
+	otherType := s.chunk(t, "filter-delete-session", "filter-delete-a", "kept solution", types.ChunkTypeSolution)
+	otherRepo := s.chunk(t, "filter-delete-session", "filter-delete-b", "kept problem", types.ChunkTypeProblem)
+	for _, c := range []*types.ConversationChunk{target, otherType, otherRepo} {
+		require.NoError(t, store.Store(ctx, c))
+	}
+
+	repo := "filter-delete-a"
+	query := s.query()
+	query.Repository = &repo
+	query.Types = []types.ChunkType{types.ChunkTypeProblem}
+	results, err := store.Search(ctx, query, s.embedding())
+	require.NoError(t, err)
+	selected := resultIDs(results)
+	require.Equal(t, []string{target.ID}, selected)
+
+	deleted, err := store.BatchDelete(ctx, selected)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted.Success)
+
+	results, err = store.Search(ctx, query, s.embedding())
+	require.NoError(t, err)
+	assert.Empty(t, results.Results, "the filter must select nothing once its chunks are deleted")
+	_, err = store.GetByID(ctx, target.ID)
+	assert.Error(t, err, "the selected chunk must be gone")
+	for _, kept := range []*types.ConversationChunk{otherType, otherRepo} {
+		_, err := store.GetByID(ctx, kept.ID)
+		assert.NoError(t, err, "chunk %s outside the filter must survive", kept.ID)
+	}
+	remaining, err := store.ListByRepository(ctx, repo, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, otherType.ID, remaining[0].ID)
+}
+
+func (s *Suite) testRelationships(t *testing.T) {
+	ctx := context.Background()
+	store := s.newStore(t)
+
+	source := s.chunk(t, "rel-session", "rel-repo", "problem", types.ChunkTypeProblem)
+	target := s.chunk(t, "rel-session", "rel-repo", "solution", types.ChunkTypeSolution)
+	require.NoError(t, store.Store(ctx, source))
+	require.NoError(t, store.Store(ctx, target))
+
+	rel, err := store.StoreRelationship(ctx, source.ID, target.ID, types.RelationSolvedBy, 0.9, types.ConfidenceExplicit)
+	require.NoError(t, err)
+	require.NotEmpty(t, rel.ID)
+
+	got, err := store.GetRelationshipByID(ctx, rel.ID)
+	require.NoError(t, err)
+	assert.Equal(t, source.ID, got.SourceChunkID)
+	assert.Equal(t, target.ID, got.TargetChunkID)
+	assert.Equal(t, types.RelationSolvedBy, got.RelationType)
+
+	outgoing := types.NewRelationshipQuery(source.ID)
+	outgoing.Direction = "outgoing"
+	results, err := store.GetRelationships(ctx, outgoing)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, rel.ID, results[0].Relationship.ID)
+
+	incoming := types.NewRelationshipQuery(source.ID)
+	incoming.Direction = "incoming"
+	results, err = store.GetRelationships(ctx, incoming)
+	require.NoError(t, err)
+	assert.Empty(t, results, "direction filter must exclude outgoing relationships")
+
+	otherType := types.NewRelationshipQuery(source.ID)
+	otherType.RelationTypes = []types.RelationType{types.RelationConflictsWith}
+	results, err = store.GetRelationships(ctx, otherType)
+	require.NoError(t, err)
+	assert.Empty(t, results, "relation type filter must exclude other types")
+
+	require.NoError(t, store.UpdateRelationship(ctx, rel.ID, 0.6, types.ConfidenceFactors{}))
+	got, err = store.GetRelationshipByID(ctx, rel.ID)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.6, got.Confidence, 1e-9)
+
+	require.NoError(t, store.DeleteRelationship(ctx, rel.ID))
+	_, err = store.GetRelationshipByID(ctx, rel.ID)
+	assert.Error(t, err, "deleted relationship must not be found")
+}
+
+func (s *Suite) testConcurrency(t *testing.T) {
+	ctx := context.Background()
+	store := s.newStore(t)
+
+	workers := s.Concurrency
+	if workers <= 0 {
+		workers = 8
+	}
+	const perWorker = 5
+
+	chunks := make([]*types.ConversationChunk, 0, workers*perWorker)
+	for i := 0; i < workers*perWorker; i++ {
+		chunks = append(chunks, s.chunk(t, "concurrency-session", "concurrency-repo", fmt.Sprintf("item %d", i), types.ChunkTypeProblem))
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(chunks)*2)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(batch []*types.ConversationChunk) {
+			defer wg.Done()
+			for _, chunk := range batch {
+				if err := store.Store(ctx, chunk); err != nil {
+					errs <- err
+					continue
+				}
+				if _, err := store.GetByID(ctx, chunk.ID); err != nil {
+					errs <- err
+				}
+				if _, err := store.Search(ctx, s.query(), s.embedding()); err != nil {
+					errs <- err
+				}
+			}
+		}(chunks[w*perWorker : (w+1)*perWorker])
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	stored, err := store.ListBySession(ctx, "concurrency-session")
+	require.NoError(t, err)
+	assert.Len(t, stored, len(chunks), "every concurrent write must be persisted")
+}
+
+// resultIDs returns the chunk IDs of search results in order
+func chunkIDs(chunks []types.ConversationChunk) []string {
+	ids := make([]string, len(chunks))
+	for i := range chunks {
+		ids[i] = chunks[i].ID
+	}
+	return ids
+}
+
+func resultIDs(results *types.SearchResults) []string {
+	ids := make([]string, 0, len(results.Results))
+	for i := range results.Results {
+		ids = append(ids, results.Results[i].Chunk.ID)
+	}
+	return ids
+}
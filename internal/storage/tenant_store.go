@@ -0,0 +1,467 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"lerian-mcp-memory/internal/audit"
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/tenancy"
+	"lerian-mcp-memory/pkg/types"
+)
+
+// TenantIsolatedVectorStore holds storage calls made on behalf of a tenant
+// (see tenancy.WithTenant) to the tenant's projects. Writes and lookups that
+// reach another project fail with tenancy.ErrCrossTenant, and listings and
+// searches drop what belongs to other projects, so the repository a request
+// names cannot widen its reach. Every denial is audit logged. Calls without
+// a tenant, such as from stdio clients and background jobs, pass through.
+type TenantIsolatedVectorStore struct {
+	store       VectorStore
+	auditLogger *audit.Logger
+}
+
+// NewTenantIsolatedVectorStore creates a tenant isolating store
+func NewTenantIsolatedVectorStore(store VectorStore) *TenantIsolatedVectorStore {
+	return &TenantIsolatedVectorStore{store: store}
+}
+
+// SetAuditLogger sets the logger denials are recorded in. Set it before use.
+func (s *TenantIsolatedVectorStore) SetAuditLogger(logger *audit.Logger) {
+	s.auditLogger = logger
+}
+
+// restricted returns the tenant a call is held to, or nil when it is not
+func restricted(ctx context.Context) *tenancy.Tenant {
+	tenant := tenancy.FromContext(ctx)
+	if tenant == nil || tenant.AllowsAll() {
+		return nil
+	}
+	return tenant
+}
+
+// deny records a cross-tenant call and returns its error
+func (s *TenantIsolatedVectorStore) deny(ctx context.Context, tenant *tenancy.Tenant, operation, repository, resourceID string) error {
+	logging.Warn("Cross-tenant storage access denied", "tenant", tenant.ID, "operation", operation, "repository", repository, "resource_id", resourceID)
+	if s.auditLogger != nil {
+		s.auditLogger.LogEvent(ctx, audit.EventTypeAccessDenied, operation, "tenant", resourceID, map[string]interface{}{
+			"tenant":     tenant.ID,
+			"projects":   tenant.Projects,
+			"repository": repository,
+		})
+	}
+	return fmt.Errorf("%w: %s on %q", tenancy.ErrCrossTenant, operation, repository)
+}
+
+// checkChunk fails unless the chunk belongs to one of the tenant's projects
+func (s *TenantIsolatedVectorStore) checkChunk(ctx context.Context, tenant *tenancy.Tenant, operation string, chunk *types.ConversationChunk) error {
+	if tenant.Allows(chunk.Metadata.Repository) {
+		return nil
+	}
+	return s.deny(ctx, tenant, operation, chunk.Metadata.Repository, chunk.ID)
+}
+
+// checkChunkID looks up a chunk and fails unless it belongs to the tenant.
+// Chunks that do not exist are left for the wrapped store to report.
+func (s *TenantIsolatedVectorStore) checkChunkID(ctx context.Context, tenant *tenancy.Tenant, operation, id string) error {
+	chunk, err := s.store.GetByID(ctx, id)
+	if err != nil || chunk == nil {
+		return nil
+	}
+	return s.checkChunk(ctx, tenant, operation, chunk)
+}
+
+// owned filters chunks to those of the tenant, recording those dropped
+func (s *TenantIsolatedVectorStore) owned(ctx context.Context, tenant *tenancy.Tenant, operation string, chunks []types.ConversationChunk) []types.ConversationChunk {
+	kept := chunks[:0]
+	for i := range chunks {
+		if tenant.Allows(chunks[i].Metadata.Repository) {
+			kept = append(kept, chunks[i])
+			continue
+		}
+		_ = s.deny(ctx, tenant, operation, chunks[i].Metadata.Repository, chunks[i].ID)
+	}
+	return kept
+}
+
+// ownedIDs returns which of ids are chunks of the tenant
+func (s *TenantIsolatedVectorStore) ownedIDs(ctx context.Context, tenant *tenancy.Tenant, ids []string) (map[string]bool, error) {
+	chunks, err := s.store.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	owned := make(map[string]bool, len(chunks))
+	for i := range chunks {
+		if tenant.Allows(chunks[i].Metadata.Repository) {
+			owned[chunks[i].ID] = true
+		}
+	}
+	return owned, nil
+}
+
+// checkRelationship fails unless both chunks of a relationship belong to the tenant
+func (s *TenantIsolatedVectorStore) checkRelationship(ctx context.Context, tenant *tenancy.Tenant, operation, relationshipID string) error {
+	relationship, err := s.store.GetRelationshipByID(ctx, relationshipID)
+	if err != nil || relationship == nil {
+		return nil
+	}
+	owned, err := s.ownedIDs(ctx, tenant, []string{relationship.SourceChunkID, relationship.TargetChunkID})
+	if err != nil {
+		return err
+	}
+	if !owned[relationship.SourceChunkID] || !owned[relationship.TargetChunkID] {
+		return s.deny(ctx, tenant, operation, "", relationshipID)
+	}
+	return nil
+}
+
+// requireAllProjects fails calls spanning every project for tenants that do not own them all
+func (s *TenantIsolatedVectorStore) requireAllProjects(ctx context.Context, operation string) error {
+	if tenant := restricted(ctx); tenant != nil {
+		return s.deny(ctx, tenant, operation, tenancy.AllProjects, "")
+	}
+	return nil
+}
+
+// Initialize initializes the store
+func (s *TenantIsolatedVectorStore) Initialize(ctx context.Context) error {
+	return s.store.Initialize(ctx)
+}
+
+// Store stores a chunk in one of the tenant's projects
+func (s *TenantIsolatedVectorStore) Store(ctx context.Context, chunk *types.ConversationChunk) error {
+	if tenant := restricted(ctx); tenant != nil {
+		if err := s.checkChunk(ctx, tenant, "Store", chunk); err != nil {
+			return err
+		}
+	}
+	return s.store.Store(ctx, chunk)
+}
+
+// Search searches the tenant's projects. A search without a repository is
+// narrowed to the tenant's project when it has one, and otherwise returns
+// only results from its projects.
+func (s *TenantIsolatedVectorStore) Search(ctx context.Context, query *types.MemoryQuery, embeddings []float64) (*types.SearchResults, error) {
+	tenant := restricted(ctx)
+	if tenant == nil {
+		return s.store.Search(ctx, query, embeddings)
+	}
+
+	switch {
+	case query.Repository != nil && *query.Repository != "":
+		if !tenant.Allows(*query.Repository) {
+			return nil, s.deny(ctx, tenant, "Search", *query.Repository, "")
+		}
+	case len(tenant.Projects) == 1:
+		narrowed := *query
+		narrowed.Repository = &tenant.Projects[0]
+		query = &narrowed
+	}
+
+	results, err := s.store.Search(ctx, query, embeddings)
+	if err != nil || results == nil {
+		return results, err
+	}
+	kept := results.Results[:0]
+	for i := range results.Results {
+		chunk := &results.Results[i].Chunk
+		if tenant.Allows(chunk.Metadata.Repository) {
+			kept = append(kept, results.Results[i])
+			continue
+		}
+		_ = s.deny(ctx, tenant, "Search", chunk.Metadata.Repository, chunk.ID)
+	}
+	results.Results = kept
+	results.Total = len(kept)
+	return results, nil
+}
+
+// GetByID gets a chunk of the tenant by ID
+func (s *TenantIsolatedVectorStore) GetByID(ctx context.Context, id string) (*types.ConversationChunk, error) {
+	chunk, err := s.store.GetByID(ctx, id)
+	if err != nil || chunk == nil {
+		return chunk, err
+	}
+	if tenant := restricted(ctx); tenant != nil {
+		if err := s.checkChunk(ctx, tenant, "GetByID", chunk); err != nil {
+			return nil, err
+		}
+	}
+	return chunk, nil
+}
+
+// GetByIDs gets the chunks of the tenant among ids
+func (s *TenantIsolatedVectorStore) GetByIDs(ctx context.Context, ids []string) ([]types.ConversationChunk, error) {
+	chunks, err := s.store.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if tenant := restricted(ctx); tenant != nil {
+		chunks = s.owned(ctx, tenant, "GetByIDs", chunks)
+	}
+	return chunks, nil
+}
+
+// ListByRepository lists chunks of one of the tenant's projects
+func (s *TenantIsolatedVectorStore) ListByRepository(ctx context.Context, repository string, limit, offset int) ([]types.ConversationChunk, error) {
+	if tenant := restricted(ctx); tenant != nil && !tenant.Allows(repository) {
+		return nil, s.deny(ctx, tenant, "ListByRepository", repository, "")
+	}
+	return s.store.ListByRepository(ctx, repository, limit, offset)
+}
+
+// ListBySession lists the tenant's chunks of a session
+func (s *TenantIsolatedVectorStore) ListBySession(ctx context.Context, sessionID string) ([]types.ConversationChunk, error) {
+	chunks, err := s.store.ListBySession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if tenant := restricted(ctx); tenant != nil {
+		chunks = s.owned(ctx, tenant, "ListBySession", chunks)
+	}
+	return chunks, nil
+}
+
+// Delete deletes a chunk of the tenant
+func (s *TenantIsolatedVectorStore) Delete(ctx context.Context, id string) error {
+	if tenant := restricted(ctx); tenant != nil {
+		if err := s.checkChunkID(ctx, tenant, "Delete", id); err != nil {
+			return err
+		}
+	}
+	return s.store.Delete(ctx, id)
+}
+
+// Update updates a chunk of the tenant, which must stay in the tenant's projects
+func (s *TenantIsolatedVectorStore) Update(ctx context.Context, chunk *types.ConversationChunk) error {
+	if tenant := restricted(ctx); tenant != nil {
+		if err := s.checkChunkID(ctx, tenant, "Update", chunk.ID); err != nil {
+			return err
+		}
+		if err := s.checkChunk(ctx, tenant, "Update", chunk); err != nil {
+			return err
+		}
+	}
+	return s.store.Update(ctx, chunk)
+}
+
+// HealthCheck checks the store
+func (s *TenantIsolatedVectorStore) HealthCheck(ctx context.Context) error {
+	return s.store.HealthCheck(ctx)
+}
+
+// GetStats returns statistics across every project, for tenants owning them all
+func (s *TenantIsolatedVectorStore) GetStats(ctx context.Context) (*StoreStats, error) {
+	if err := s.requireAllProjects(ctx, "GetStats"); err != nil {
+		return nil, err
+	}
+	return s.store.GetStats(ctx)
+}
+
+// Cleanup removes old chunks of every project, for tenants owning them all
+func (s *TenantIsolatedVectorStore) Cleanup(ctx context.Context, retentionDays int) (int, error) {
+	if err := s.requireAllProjects(ctx, "Cleanup"); err != nil {
+		return 0, err
+	}
+	return s.store.Cleanup(ctx, retentionDays)
+}
+
+// Close closes the store
+func (s *TenantIsolatedVectorStore) Close() error {
+	return s.store.Close()
+}
+
+// GetAllChunks gets the tenant's chunks
+func (s *TenantIsolatedVectorStore) GetAllChunks(ctx context.Context) ([]types.ConversationChunk, error) {
+	chunks, err := s.store.GetAllChunks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tenant := restricted(ctx); tenant != nil {
+		chunks = s.owned(ctx, tenant, "GetAllChunks", chunks)
+	}
+	return chunks, nil
+}
+
+// DeleteCollection deletes a collection, for tenants owning every project
+func (s *TenantIsolatedVectorStore) DeleteCollection(ctx context.Context, collection string) error {
+	if err := s.requireAllProjects(ctx, "DeleteCollection"); err != nil {
+		return err
+	}
+	return s.store.DeleteCollection(ctx, collection)
+}
+
+// ListCollections lists collections
+func (s *TenantIsolatedVectorStore) ListCollections(ctx context.Context) ([]string, error) {
+	return s.store.ListCollections(ctx)
+}
+
+// FindSimilar finds similar chunks of the tenant
+func (s *TenantIsolatedVectorStore) FindSimilar(ctx context.Context, content string, chunkType *types.ChunkType, limit int) ([]types.ConversationChunk, error) {
+	chunks, err := s.store.FindSimilar(ctx, content, chunkType, limit)
+	if err != nil {
+		return nil, err
+	}
+	if tenant := restricted(ctx); tenant != nil {
+		chunks = s.owned(ctx, tenant, "FindSimilar", chunks)
+	}
+	return chunks, nil
+}
+
+// StoreChunk stores a chunk in one of the tenant's projects
+func (s *TenantIsolatedVectorStore) StoreChunk(ctx context.Context, chunk *types.ConversationChunk) error {
+	return s.Store(ctx, chunk)
+}
+
+// BatchStore stores chunks, all of which must be in the tenant's projects
+func (s *TenantIsolatedVectorStore) BatchStore(ctx context.Context, chunks []*types.ConversationChunk) (*BatchResult, error) {
+	if tenant := restricted(ctx); tenant != nil {
+		for _, chunk := range chunks {
+			if err := s.checkChunk(ctx, tenant, "BatchStore", chunk); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s.store.BatchStore(ctx, chunks)
+}
+
+// BatchDelete deletes chunks, all of which must be the tenant's
+func (s *TenantIsolatedVectorStore) BatchDelete(ctx context.Context, ids []string) (*BatchResult, error) {
+	if tenant := restricted(ctx); tenant != nil {
+		chunks, err := s.store.GetByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for i := range chunks {
+			if err := s.checkChunk(ctx, tenant, "BatchDelete", &chunks[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s.store.BatchDelete(ctx, ids)
+}
+
+// StoreRelationship links two chunks of the tenant
+func (s *TenantIsolatedVectorStore) StoreRelationship(ctx context.Context, sourceID, targetID string, relationType types.RelationType, confidence float64, source types.ConfidenceSource) (*types.MemoryRelationship, error) {
+	if tenant := restricted(ctx); tenant != nil {
+		for _, id := range []string{sourceID, targetID} {
+			if err := s.checkChunkID(ctx, tenant, "StoreRelationship", id); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s.store.StoreRelationship(ctx, sourceID, targetID, relationType, confidence, source)
+}
+
+// GetRelationships gets the relationships of a chunk of the tenant to its other chunks
+func (s *TenantIsolatedVectorStore) GetRelationships(ctx context.Context, query *types.RelationshipQuery) ([]types.RelationshipResult, error) {
+	tenant := restricted(ctx)
+	if tenant != nil {
+		if err := s.checkChunkID(ctx, tenant, "GetRelationships", query.ChunkID); err != nil {
+			return nil, err
+		}
+	}
+	results, err := s.store.GetRelationships(ctx, query)
+	if err != nil || tenant == nil {
+		return results, err
+	}
+
+	ids := make([]string, 0, 2*len(results))
+	for i := range results {
+		ids = append(ids, results[i].Relationship.SourceChunkID, results[i].Relationship.TargetChunkID)
+	}
+	owned, err := s.ownedIDs(ctx, tenant, ids)
+	if err != nil {
+		return nil, err
+	}
+	kept := results[:0]
+	for i := range results {
+		relationship := &results[i].Relationship
+		if owned[relationship.SourceChunkID] && owned[relationship.TargetChunkID] {
+			kept = append(kept, results[i])
+			continue
+		}
+		_ = s.deny(ctx, tenant, "GetRelationships", "", relationship.ID)
+	}
+	return kept, nil
+}
+
+// TraverseGraph traverses the graph from a chunk of the tenant, through its chunks only
+func (s *TenantIsolatedVectorStore) TraverseGraph(ctx context.Context, startChunkID string, maxDepth int, relationTypes []types.RelationType) (*types.GraphTraversalResult, error) {
+	tenant := restricted(ctx)
+	if tenant != nil {
+		if err := s.checkChunkID(ctx, tenant, "TraverseGraph", startChunkID); err != nil {
+			return nil, err
+		}
+	}
+	result, err := s.store.TraverseGraph(ctx, startChunkID, maxDepth, relationTypes)
+	if err != nil || result == nil || tenant == nil {
+		return result, err
+	}
+
+	ids := make([]string, 0, len(result.Nodes))
+	for i := range result.Nodes {
+		ids = append(ids, result.Nodes[i].ChunkID)
+	}
+	owned, err := s.ownedIDs(ctx, tenant, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := result.Nodes[:0]
+	for i := range result.Nodes {
+		if owned[result.Nodes[i].ChunkID] {
+			nodes = append(nodes, result.Nodes[i])
+		} else {
+			_ = s.deny(ctx, tenant, "TraverseGraph", "", result.Nodes[i].ChunkID)
+		}
+	}
+	edges := result.Edges[:0]
+	for i := range result.Edges {
+		if owned[result.Edges[i].Relationship.SourceChunkID] && owned[result.Edges[i].Relationship.TargetChunkID] {
+			edges = append(edges, result.Edges[i])
+		}
+	}
+	paths := result.Paths[:0]
+	for i := range result.Paths {
+		ownedPath := true
+		for _, id := range result.Paths[i].ChunkIDs {
+			ownedPath = ownedPath && owned[id]
+		}
+		if ownedPath {
+			paths = append(paths, result.Paths[i])
+		}
+	}
+	result.Nodes, result.Edges, result.Paths = nodes, edges, paths
+	return result, nil
+}
+
+// UpdateRelationship updates a relationship between chunks of the tenant
+func (s *TenantIsolatedVectorStore) UpdateRelationship(ctx context.Context, relationshipID string, confidence float64, factors types.ConfidenceFactors) error {
+	if tenant := restricted(ctx); tenant != nil {
+		if err := s.checkRelationship(ctx, tenant, "UpdateRelationship", relationshipID); err != nil {
+			return err
+		}
+	}
+	return s.store.UpdateRelationship(ctx, relationshipID, confidence, factors)
+}
+
+// DeleteRelationship deletes a relationship between chunks of the tenant
+func (s *TenantIsolatedVectorStore) DeleteRelationship(ctx context.Context, relationshipID string) error {
+	if tenant := restricted(ctx); tenant != nil {
+		if err := s.checkRelationship(ctx, tenant, "DeleteRelationship", relationshipID); err != nil {
+			return err
+		}
+	}
+	return s.store.DeleteRelationship(ctx, relationshipID)
+}
+
+// GetRelationshipByID gets a relationship between chunks of the tenant
+func (s *TenantIsolatedVectorStore) GetRelationshipByID(ctx context.Context, relationshipID string) (*types.MemoryRelationship, error) {
+	if tenant := restricted(ctx); tenant != nil {
+		if err := s.checkRelationship(ctx, tenant, "GetRelationshipByID", relationshipID); err != nil {
+			return nil, err
+		}
+	}
+	return s.store.GetRelationshipByID(ctx, relationshipID)
+}
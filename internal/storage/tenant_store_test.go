@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/audit"
+	"lerian-mcp-memory/internal/tenancy"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantIsolatedVectorStore(t *testing.T) {
+	base := NewKeywordStore("")
+	store := NewTenantIsolatedVectorStore(base)
+	auditLogger, err := audit.NewLogger(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(auditLogger.Stop)
+	store.SetAuditLogger(auditLogger)
+
+	ours := newKeywordChunk(t, "acme/api", "postgres pool exhausted under load", types.ChunkTypeProblem)
+	related := newKeywordChunk(t, "acme/api", "raised the postgres pool size", types.ChunkTypeSolution)
+	theirs := newKeywordChunk(t, "rival/app", "postgres replica lag during deploys", types.ChunkTypeProblem)
+	for _, chunk := range []*types.ConversationChunk{ours, related, theirs} {
+		require.NoError(t, store.Store(context.Background(), chunk), "calls without a tenant are not restricted")
+	}
+	_, err = store.StoreRelationship(context.Background(), ours.ID, theirs.ID, types.RelationRelatedTo, 0.9, types.ConfidenceExplicit)
+	require.NoError(t, err)
+	_, err = store.StoreRelationship(context.Background(), ours.ID, related.ID, types.RelationSolvedBy, 0.9, types.ConfidenceExplicit)
+	require.NoError(t, err)
+
+	ctx := tenancy.WithTenant(context.Background(), &tenancy.Tenant{ID: "api_key:acme", Projects: []string{"acme/api"}})
+
+	_, err = store.GetByID(ctx, theirs.ID)
+	assert.ErrorIs(t, err, tenancy.ErrCrossTenant)
+	chunk, err := store.GetByID(ctx, ours.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ours.ID, chunk.ID)
+
+	intruder := newKeywordChunk(t, "rival/app", "planted note", types.ChunkTypeDiscussion)
+	assert.ErrorIs(t, store.Store(ctx, intruder), tenancy.ErrCrossTenant)
+	moved := *ours
+	moved.Metadata.Repository = "rival/app"
+	assert.ErrorIs(t, store.Update(ctx, &moved), tenancy.ErrCrossTenant, "a chunk cannot be moved to another tenant")
+	assert.ErrorIs(t, store.Delete(ctx, theirs.ID), tenancy.ErrCrossTenant)
+
+	_, err = store.ListByRepository(ctx, "rival/app", 10, 0)
+	assert.ErrorIs(t, err, tenancy.ErrCrossTenant)
+	rival := "rival/app"
+	query := types.NewMemoryQuery("postgres")
+	query.MinRelevanceScore = 0
+	query.Repository = &rival
+	_, err = store.Search(ctx, query, nil)
+	assert.ErrorIs(t, err, tenancy.ErrCrossTenant, "naming another tenant's repository does not widen the search")
+
+	query.Repository = nil
+	results, err := store.Search(ctx, query, nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, results.Results)
+	for _, result := range results.Results {
+		assert.Equal(t, "acme/api", result.Chunk.Metadata.Repository)
+	}
+
+	chunks, err := store.ListBySession(ctx, "session-1")
+	require.NoError(t, err)
+	assert.Len(t, chunks, 2)
+	chunks, err = store.GetByIDs(ctx, []string{ours.ID, theirs.ID})
+	require.NoError(t, err)
+	assert.Len(t, chunks, 1)
+
+	_, err = store.StoreRelationship(ctx, ours.ID, theirs.ID, types.RelationRelatedTo, 0.5, types.ConfidenceExplicit)
+	assert.ErrorIs(t, err, tenancy.ErrCrossTenant)
+	relationships, err := store.GetRelationships(ctx, &types.RelationshipQuery{ChunkID: ours.ID, Direction: "both"})
+	require.NoError(t, err)
+	require.Len(t, relationships, 1, "links to another tenant's chunks are hidden")
+	assert.Equal(t, related.ID, relationships[0].Relationship.TargetChunkID)
+	_, err = store.GetRelationships(ctx, &types.RelationshipQuery{ChunkID: theirs.ID, Direction: "both"})
+	assert.ErrorIs(t, err, tenancy.ErrCrossTenant)
+
+	_, err = store.GetStats(ctx)
+	assert.ErrorIs(t, err, tenancy.ErrCrossTenant)
+	operator := tenancy.WithTenant(context.Background(), &tenancy.Tenant{ID: "api_key:ops", Projects: []string{tenancy.AllProjects}})
+	_, err = store.GetByID(operator, theirs.ID)
+	assert.NoError(t, err)
+
+	denied := auditLogger.GetStatistics()["events_by_type"].(map[audit.EventType]int64)[audit.EventTypeAccessDenied]
+	assert.GreaterOrEqual(t, denied, int64(10), "every denial is audited")
+}
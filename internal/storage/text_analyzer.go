@@ -0,0 +1,224 @@
+package storage
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/pkg/types"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// TextAnalyzer turns text into keyword search terms. Memories and the
+// queries searching them must go through the same analyzer for their terms
+// to meet.
+type TextAnalyzer struct {
+	name           string
+	normalize      bool
+	foldDiacritics bool
+}
+
+// NewTextAnalyzer returns the analyzer of a config.Analyzer* name
+func NewTextAnalyzer(name string) (*TextAnalyzer, error) {
+	switch name {
+	case config.AnalyzerStandard, "":
+		return &TextAnalyzer{name: config.AnalyzerStandard, normalize: true, foldDiacritics: true}, nil
+	case config.AnalyzerAccentSensitive:
+		return &TextAnalyzer{name: name, normalize: true}, nil
+	case config.AnalyzerSimple:
+		return &TextAnalyzer{name: name}, nil
+	default:
+		return nil, fmt.Errorf("unknown text analyzer: %q", name)
+	}
+}
+
+// standardAnalyzer analyzes text when no analyzer is configured
+var standardAnalyzer = &TextAnalyzer{name: config.AnalyzerStandard, normalize: true, foldDiacritics: true}
+
+// Name returns the config name of the analyzer
+func (a *TextAnalyzer) Name() string {
+	return a.name
+}
+
+// Terms returns the search terms of text. Full-width and compatibility forms
+// are unified (NFKC) and case is folded, so "Ｅｒｒｏｒ" and "STRASSE" match
+// "error" and "straße". Latin, Greek and Cyrillic diacritics are dropped
+// unless the analyzer is accent sensitive. Han, kana and Hangul runs carry no
+// spaces between words, so they become overlapping bigrams instead: "東京都"
+// yields "東京" and "京都".
+func (a *TextAnalyzer) Terms(text string) []string {
+	if !a.normalize {
+		return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+	}
+
+	text = cases.Fold().String(norm.NFKC.String(text))
+	if a.foldDiacritics {
+		text = foldDiacritics(text)
+	}
+
+	var terms []string
+	runes := []rune(text)
+	for start := 0; start < len(runes); {
+		if !isTermRune(runes[start]) {
+			start++
+			continue
+		}
+		cjk := isCJK(runes[start])
+		end := start + 1
+		for end < len(runes) && isTermRune(runes[end]) && (isCJK(runes[end]) == cjk || unicode.Is(unicode.M, runes[end])) {
+			end++
+		}
+		if cjk {
+			terms = appendBigrams(terms, runes[start:end])
+		} else {
+			terms = append(terms, string(runes[start:end]))
+		}
+		start = end
+	}
+	return terms
+}
+
+// isTermRune reports whether r belongs in a term. Combining marks do, so
+// scripts that spell vowels with them (Devanagari, Thai) stay whole.
+func isTermRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.M, r)
+}
+
+// isCJK reports whether r is written without spaces between words
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) || r == 'ー'
+}
+
+// appendBigrams appends the overlapping bigrams of a CJK run; a run of one
+// character is a term of its own
+func appendBigrams(terms []string, run []rune) []string {
+	if len(run) == 1 {
+		return append(terms, string(run))
+	}
+	for i := 0; i+1 < len(run); i++ {
+		terms = append(terms, string(run[i:i+2]))
+	}
+	return terms
+}
+
+// foldedLetters are letters with a diacritic that no decomposition removes
+var foldedLetters = map[rune]string{
+	'ø': "o",
+	'đ': "d",
+	'ł': "l",
+	'ħ': "h",
+	'æ': "ae",
+	'œ': "oe",
+}
+
+// foldDiacritics drops the diacritics of case-folded Latin, Greek and
+// Cyrillic letters: "café" becomes "cafe". Marks on other scripts are kept,
+// since they spell vowels (Devanagari) or sounds (the dakuten of kana).
+func foldDiacritics(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	foldBase := false
+	for _, r := range norm.NFD.String(text) {
+		if unicode.Is(unicode.Mn, r) {
+			if !foldBase {
+				b.WriteRune(r)
+			}
+			continue
+		}
+		foldBase = unicode.In(r, unicode.Latin, unicode.Greek, unicode.Cyrillic)
+		if folded, ok := foldedLetters[r]; ok {
+			b.WriteString(folded)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return norm.NFC.String(b.String())
+}
+
+// Analyzers picks the text analyzer of each repository. A nil *Analyzers
+// analyzes everything with the standard analyzer.
+type Analyzers struct {
+	fallback     *TextAnalyzer
+	repositories map[string]*TextAnalyzer
+}
+
+// NewAnalyzers builds the analyzers of a search configuration
+func NewAnalyzers(cfg *config.SearchConfig) (*Analyzers, error) {
+	fallback, err := NewTextAnalyzer(cfg.Analyzer)
+	if err != nil {
+		return nil, err
+	}
+	analyzers := &Analyzers{fallback: fallback, repositories: make(map[string]*TextAnalyzer, len(cfg.RepositoryAnalyzers))}
+	for repository, name := range cfg.RepositoryAnalyzers {
+		analyzer, err := NewTextAnalyzer(name)
+		if err != nil {
+			return nil, fmt.Errorf("repository %s: %w", repository, err)
+		}
+		analyzers.repositories[repository] = analyzer
+	}
+	return analyzers, nil
+}
+
+// For returns the analyzer of a repository
+func (a *Analyzers) For(repository string) *TextAnalyzer {
+	if a == nil {
+		return standardAnalyzer
+	}
+	if analyzer, ok := a.repositories[repository]; ok {
+		return analyzer
+	}
+	return a.fallback
+}
+
+// ChunkTerms returns the searchable terms of a chunk, analyzed as its repository's
+func (a *Analyzers) ChunkTerms(chunk *types.ConversationChunk) []string {
+	analyzer := a.For(chunk.Metadata.Repository)
+	terms := analyzer.Terms(chunk.Content)
+	terms = append(terms, analyzer.Terms(chunk.Summary)...)
+	for _, tag := range chunk.Metadata.Tags {
+		terms = append(terms, analyzer.Terms(tag)...)
+	}
+	return terms
+}
+
+// QueryTerms returns the distinct terms of a query. A query of one
+// repository is analyzed as that repository's memories are; any other is
+// analyzed by every configured analyzer, so it finds memories of them all.
+func (a *Analyzers) QueryTerms(query *types.MemoryQuery) []string {
+	if query.Query == "" {
+		return nil
+	}
+
+	analyzers := []*TextAnalyzer{a.For("")}
+	if query.Repository != nil && *query.Repository != "" {
+		analyzers[0] = a.For(*query.Repository)
+	} else if a != nil {
+		for _, analyzer := range a.repositories {
+			analyzers = append(analyzers, analyzer)
+		}
+		slices.SortStableFunc(analyzers[1:], func(x, y *TextAnalyzer) int { return strings.Compare(x.name, y.name) })
+	}
+
+	var terms []string
+	seen := make(map[string]bool)
+	analyzed := make(map[string]bool, len(analyzers))
+	for _, analyzer := range analyzers {
+		if analyzed[analyzer.name] {
+			continue
+		}
+		analyzed[analyzer.name] = true
+		for _, term := range analyzer.Terms(query.Query) {
+			if !seen[term] {
+				seen[term] = true
+				terms = append(terms, term)
+			}
+		}
+	}
+	return terms
+}
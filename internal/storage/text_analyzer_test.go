@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextAnalyzerTerms(t *testing.T) {
+	standard, err := NewTextAnalyzer(config.AnalyzerStandard)
+	require.NoError(t, err)
+	accents, err := NewTextAnalyzer(config.AnalyzerAccentSensitive)
+	require.NoError(t, err)
+	simple, err := NewTextAnalyzer(config.AnalyzerSimple)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		analyzer *TextAnalyzer
+		text     string
+		want     []string
+	}{
+		{"diacritics fold", standard, "Café Crème à São Paulo", []string{"cafe", "creme", "a", "sao", "paulo"}},
+		{"case folds fully", standard, "STRASSE Straße", []string{"strasse", "strasse"}},
+		{"letters without decomposition", standard, "Øresund Łódź", []string{"oresund", "lodz"}},
+		{"greek and cyrillic", standard, "Αθήνα Йошкар", []string{"αθηνα", "иошкар"}},
+		{"full width forms", standard, "ＥＲＲ＿４０２１", []string{"err", "4021"}},
+		{"han bigrams", standard, "東京都", []string{"東京", "京都"}},
+		{"single han character", standard, "缓 存", []string{"缓", "存"}},
+		{"mixed scripts", standard, "使用Redis缓存", []string{"使用", "redis", "缓存"}},
+		{"dakuten survive", standard, "データベース", []string{"デー", "ータ", "タベ", "ベー", "ース"}},
+		{"hangul", standard, "데이터베이스 연결", []string{"데이", "이터", "터베", "베이", "이스", "연결"}},
+		{"devanagari marks stay", standard, "हिंदी खोज", []string{"हिंदी", "खोज"}},
+		{"accent sensitive", accents, "Café CAFÉ", []string{"café", "café"}},
+		{"simple", simple, "Café 東京都", []string{"café", "東京都"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.analyzer.Terms(tt.text))
+		})
+	}
+
+	_, err = NewTextAnalyzer("klingon")
+	assert.Error(t, err)
+}
+
+func TestAnalyzersPerRepository(t *testing.T) {
+	analyzers, err := NewAnalyzers(&config.SearchConfig{
+		Analyzer:            config.AnalyzerStandard,
+		RepositoryAnalyzers: map[string]string{"repo-fr": config.AnalyzerAccentSensitive},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, config.AnalyzerAccentSensitive, analyzers.For("repo-fr").Name())
+	assert.Equal(t, config.AnalyzerStandard, analyzers.For("repo-a").Name())
+	assert.Equal(t, config.AnalyzerStandard, (*Analyzers)(nil).For("repo-fr").Name(), "nil analyzers are standard")
+
+	query := types.NewMemoryQuery("Résumé parser")
+	assert.Equal(t, []string{"resume", "parser", "résumé"}, analyzers.QueryTerms(query), "queries of no repository use every analyzer")
+	repo := "repo-fr"
+	query.Repository = &repo
+	assert.Equal(t, []string{"résumé", "parser"}, analyzers.QueryTerms(query))
+
+	_, err = NewAnalyzers(&config.SearchConfig{RepositoryAnalyzers: map[string]string{"repo": "klingon"}})
+	assert.Error(t, err)
+}
+
+func TestKeywordSearchInternationalText(t *testing.T) {
+	ctx := context.Background()
+	store := NewKeywordStore("")
+	index := NewKeywordIndex()
+
+	tokyo := newKeywordChunk(t, "repo-a", "東京都のサーバーで接続タイムアウトが発生", types.ChunkTypeProblem)
+	cache := newKeywordChunk(t, "repo-a", "使用Redis缓存会话数据", types.ChunkTypeSolution)
+	cafe := newKeywordChunk(t, "repo-a", "Le café du déploiement a échoué", types.ChunkTypeProblem)
+	for _, chunk := range []*types.ConversationChunk{tokyo, cache, cafe} {
+		require.NoError(t, store.Store(ctx, chunk))
+		index.Add(chunk)
+	}
+
+	for query, want := range map[string]string{
+		"東京":               tokyo.ID,
+		"接続タイムアウト":         tokyo.ID,
+		"redis 缓存":         cache.ID,
+		"CAFE deploiement": cafe.ID,
+		"échoué":           cafe.ID,
+	} {
+		memoryQuery := types.NewMemoryQuery(query)
+		memoryQuery.MinRelevanceScore = 0
+
+		results, err := store.Search(ctx, memoryQuery, nil)
+		require.NoError(t, err)
+		require.NotEmpty(t, results.Results, query)
+		assert.Equal(t, want, results.Results[0].Chunk.ID, "keyword store: %s", query)
+
+		indexed := index.Search(memoryQuery, false)
+		require.NotEmpty(t, indexed.Results, query)
+		assert.Equal(t, want, indexed.Results[0].Chunk.ID, "keyword index: %s", query)
+	}
+
+	// The accent-sensitive repository keeps é apart from e
+	analyzers, err := NewAnalyzers(&config.SearchConfig{RepositoryAnalyzers: map[string]string{"repo-a": config.AnalyzerAccentSensitive}})
+	require.NoError(t, err)
+	index.SetAnalyzers(analyzers)
+	index.Reset([]types.ConversationChunk{*tokyo, *cache, *cafe})
+	repo := "repo-a"
+	query := types.NewMemoryQuery("deploiement")
+	query.Repository = &repo
+	assert.Empty(t, index.Search(query, false).Results)
+	query.Query = "déploiement"
+	assert.Equal(t, []string{cafe.ID}, resultIDs(index.Search(query, false)))
+}
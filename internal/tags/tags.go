@@ -0,0 +1,527 @@
+// Package tags keeps the registry of tags memories and tasks are labelled
+// with. Tags stay free-form strings on the chunks; the registry names the
+// ones a team has agreed on, arranges them in an optional area/subtopic
+// hierarchy and remembers the names they had before being renamed or merged,
+// so new memories using an old name are filed under the current one and
+// near-misses of a registered tag are caught at ingest.
+package tags
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxNameLength bounds tag names, hierarchy included
+	maxNameLength = 128
+
+	// Separator splits a tag name into its area and subtopics
+	Separator = "/"
+
+	// maxSuggestions bounds the registered tags suggested for an unknown one
+	maxSuggestions = 3
+)
+
+// Tag is a registered tag. Aliases are the names it had before a rename and
+// the names of the tags merged into it.
+type Tag struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Aliases     []string `json:"aliases,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Parent returns the tag's area: its name up to the last separator, or ""
+// for a top-level tag
+func (t *Tag) Parent() string {
+	return Parent(t.Name)
+}
+
+// Parent returns the area of a tag name, or "" for a top-level tag
+func Parent(name string) string {
+	if i := strings.LastIndex(name, Separator); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// Leaf returns the last segment of a tag name
+func Leaf(name string) string {
+	return name[strings.LastIndex(name, Separator)+1:]
+}
+
+// IsWithin reports whether name is area itself or one of its subtopics
+func IsWithin(name, area string) bool {
+	return name == area || strings.HasPrefix(name, area+Separator)
+}
+
+// Normalize puts a tag name in canonical form: lower case, spaces turned
+// into dashes and no blank segments
+func Normalize(name string) string {
+	segments := strings.Split(strings.ToLower(strings.TrimSpace(name)), Separator)
+	normalized := segments[:0]
+	for _, segment := range segments {
+		segment = strings.Join(strings.Fields(segment), "-")
+		if segment != "" {
+			normalized = append(normalized, segment)
+		}
+	}
+	return strings.Join(normalized, Separator)
+}
+
+// ValidateName checks that a normalized name can name a tag
+func ValidateName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("tag name is required")
+	case len(name) > maxNameLength:
+		return fmt.Errorf("tag name must not exceed %d characters", maxNameLength)
+	}
+	return nil
+}
+
+// Store is the tag registry. It is persisted to an optional JSON file so it
+// survives restarts.
+type Store struct {
+	mu   sync.RWMutex
+	path string
+	tags map[string]Tag // name -> tag
+}
+
+// NewStore creates a registry persisted at path; an empty path keeps it in memory
+func NewStore(path string) *Store {
+	return &Store{
+		path: path,
+		tags: make(map[string]Tag),
+	}
+}
+
+// Load reads the registry from disk. A missing file is not an error.
+func (s *Store) Load() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read tag registry: %w", err)
+	}
+
+	var tags []Tag
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return fmt.Errorf("failed to parse tag registry: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range tags {
+		s.tags[tags[i].Name] = tags[i]
+	}
+	return nil
+}
+
+// Create registers a tag, and the areas above it that are not registered yet.
+// Its name must not name another tag, now or before a rename or merge.
+func (s *Store) Create(tag Tag) (*Tag, error) {
+	tag.Name = Normalize(tag.Name)
+	if err := ValidateName(tag.Name); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.takenLocked(tag.Name, ""); err != nil {
+		return nil, err
+	}
+	snapshot := s.snapshotLocked()
+	now := time.Now().UTC()
+	for area := Parent(tag.Name); area != ""; area = Parent(area) {
+		if _, ok := s.tags[area]; ok {
+			break
+		}
+		if err := s.takenLocked(area, ""); err != nil {
+			return nil, fmt.Errorf("cannot create area of %q: %w", tag.Name, err)
+		}
+		s.tags[area] = Tag{Name: area, CreatedAt: now, UpdatedAt: now}
+	}
+	tag.Aliases = nil
+	tag.CreatedAt = now
+	tag.UpdatedAt = now
+	s.tags[tag.Name] = tag
+
+	if err := s.persistLocked(); err != nil {
+		s.tags = snapshot
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// Update changes a tag's description
+func (s *Store) Update(name, description string) (*Tag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tag, ok := s.tags[name]
+	if !ok {
+		return nil, fmt.Errorf("tag %q not found", name)
+	}
+	snapshot := s.snapshotLocked()
+	tag.Description = description
+	tag.UpdatedAt = time.Now().UTC()
+	s.tags[name] = tag
+	if err := s.persistLocked(); err != nil {
+		s.tags = snapshot
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// Get returns a tag by its current name
+func (s *Store) Get(name string) (Tag, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	tag, ok := s.tags[name]
+	return tag, ok
+}
+
+// Resolve returns the tag a name stands for, now or before a rename or merge
+func (s *Store) Resolve(name string) (Tag, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.resolveLocked(Normalize(name))
+}
+
+// List returns the registered tags sorted by name, so areas come before
+// their subtopics
+func (s *Store) List() []Tag {
+	s.mu.RLock()
+	tags := make([]Tag, 0, len(s.tags))
+	for name := range s.tags {
+		tags = append(tags, s.tags[name])
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags
+}
+
+// Subtopics returns the names of the tags below an area, at any depth
+func (s *Store) Subtopics(area string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.subtopicsLocked(area)
+}
+
+// Rename gives a tag, and each of its subtopics, a new name and keeps the old
+// ones as aliases. It returns the old name of every renamed tag mapped to the
+// new one; callers rewrite the chunks using them.
+func (s *Store) Rename(name, newName string) (map[string]string, error) {
+	newName = Normalize(newName)
+	if err := ValidateName(newName); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tags[name]; !ok {
+		return nil, fmt.Errorf("tag %q not found", name)
+	}
+	if newName == name {
+		return nil, fmt.Errorf("tag %q already has that name", name)
+	}
+	if IsWithin(newName, name) {
+		return nil, fmt.Errorf("tag %q cannot be moved below itself", name)
+	}
+
+	renames := map[string]string{name: newName}
+	for _, subtopic := range s.subtopicsLocked(name) {
+		renames[subtopic] = newName + strings.TrimPrefix(subtopic, name)
+	}
+	for from, to := range renames {
+		if err := s.takenLocked(to, from); err != nil {
+			return nil, err
+		}
+	}
+
+	snapshot := s.snapshotLocked()
+	now := time.Now().UTC()
+	for from, to := range renames {
+		tag := s.tags[from]
+		delete(s.tags, from)
+		tag.Aliases = append(slices.DeleteFunc(tag.Aliases, func(alias string) bool { return alias == to }), from)
+		tag.Name = to
+		tag.UpdatedAt = now
+		s.tags[to] = tag
+	}
+	for area := Parent(newName); area != ""; area = Parent(area) {
+		if _, ok := s.tags[area]; !ok {
+			s.tags[area] = Tag{Name: area, CreatedAt: now, UpdatedAt: now}
+		}
+	}
+
+	if err := s.persistLocked(); err != nil {
+		s.tags = snapshot
+		return nil, err
+	}
+	return renames, nil
+}
+
+// Merge folds tags into target: their names and aliases become aliases of
+// target and they leave the registry. Sources need not be registered, so
+// tags only ever used on chunks, typos among them, can be merged too.
+// Registered tags with subtopics must have those merged or renamed first.
+// It returns the merged tag.
+func (s *Store) Merge(sources []string, target string) (*Tag, error) {
+	normalized := make([]string, len(sources))
+	for i := range sources {
+		normalized[i] = Normalize(sources[i])
+	}
+	sources = normalized
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	merged, ok := s.tags[target]
+	if !ok {
+		return nil, fmt.Errorf("tag %q not found", target)
+	}
+	if len(sources) == 0 {
+		return nil, errors.New("at least one tag to merge is required")
+	}
+	for _, source := range sources {
+		if err := ValidateName(source); err != nil {
+			return nil, err
+		}
+		if source == target || IsWithin(target, source) {
+			return nil, fmt.Errorf("tag %q cannot be merged into itself or one of its subtopics", source)
+		}
+		if tag, ok := s.resolveLocked(source); ok && tag.Name != source && tag.Name != target {
+			return nil, fmt.Errorf("%q is an alias of tag %q", source, tag.Name)
+		}
+		for _, subtopic := range s.subtopicsLocked(source) {
+			if !slices.Contains(sources, subtopic) {
+				return nil, fmt.Errorf("tag %q has subtopic %q: merge or rename its subtopics first", source, subtopic)
+			}
+		}
+	}
+
+	snapshot := s.snapshotLocked()
+	for _, source := range sources {
+		merged.Aliases = append(merged.Aliases, source)
+		if tag, ok := s.tags[source]; ok {
+			merged.Aliases = append(merged.Aliases, tag.Aliases...)
+			delete(s.tags, source)
+		}
+	}
+	slices.Sort(merged.Aliases)
+	merged.Aliases = slices.Compact(merged.Aliases)
+	merged.UpdatedAt = time.Now().UTC()
+	s.tags[target] = merged
+
+	if err := s.persistLocked(); err != nil {
+		s.tags = snapshot
+		return nil, err
+	}
+	return &merged, nil
+}
+
+// Delete removes a tag from the registry. Tags with subtopics cannot be
+// deleted. Callers deal with the chunks using it.
+func (s *Store) Delete(name string) (*Tag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tag, ok := s.tags[name]
+	if !ok {
+		return nil, fmt.Errorf("tag %q not found", name)
+	}
+	if subtopics := s.subtopicsLocked(name); len(subtopics) > 0 {
+		return nil, fmt.Errorf("tag %q has %d subtopics: delete or rename them first", name, len(subtopics))
+	}
+	delete(s.tags, name)
+	if err := s.persistLocked(); err != nil {
+		s.tags[name] = tag
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// Canonical maps a tag to the registered tag it stands for. Tags that are
+// not registered, and are not aliases of a registered tag, are returned as
+// given.
+func (s *Store) Canonical(name string) string {
+	if tag, ok := s.Resolve(name); ok {
+		return tag.Name
+	}
+	return name
+}
+
+// Suggest returns registered tags an unknown tag may have meant to be, best
+// first: tags a small edit away, and hierarchical tags ending in the same
+// subtopic. It returns nothing for registered tags and their aliases.
+func (s *Store) Suggest(name string) []string {
+	normalized := Normalize(name)
+	if normalized == "" {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.resolveLocked(normalized); ok {
+		return nil
+	}
+
+	type candidate struct {
+		name     string
+		distance int
+	}
+	limit := maxEditDistance(normalized)
+	var candidates []candidate
+	for registered := range s.tags {
+		distance := editDistance(normalized, registered)
+		if leaf := Leaf(registered); leaf != registered && leaf == normalized {
+			distance = 0
+		}
+		if distance <= limit {
+			candidates = append(candidates, candidate{registered, distance})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].name < candidates[j].name
+	})
+
+	suggestions := make([]string, 0, min(len(candidates), maxSuggestions))
+	for i := 0; i < len(candidates) && i < maxSuggestions; i++ {
+		suggestions = append(suggestions, candidates[i].name)
+	}
+	return suggestions
+}
+
+// maxEditDistance is how many edits away from a registered tag a name may be
+// to suggest it: one for short names, two from eight characters on
+func maxEditDistance(name string) int {
+	if len(name) >= 8 {
+		return 2
+	}
+	if len(name) >= 3 {
+		return 1
+	}
+	return 0
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+// resolveLocked finds the tag a name stands for; callers must hold the lock
+func (s *Store) resolveLocked(name string) (Tag, bool) {
+	if tag, ok := s.tags[name]; ok {
+		return tag, true
+	}
+	for key := range s.tags {
+		if slices.Contains(s.tags[key].Aliases, name) {
+			return s.tags[key], true
+		}
+	}
+	return Tag{}, false
+}
+
+// takenLocked fails when name stands for a tag other than owner, now or
+// before a rename or merge; callers must hold the lock
+func (s *Store) takenLocked(name, owner string) error {
+	tag, ok := s.resolveLocked(name)
+	switch {
+	case !ok || tag.Name == owner:
+		return nil
+	case tag.Name == name:
+		return fmt.Errorf("tag %q already exists", name)
+	default:
+		return fmt.Errorf("%q is an alias of tag %q", name, tag.Name)
+	}
+}
+
+// subtopicsLocked lists the tags below an area; callers must hold the lock
+func (s *Store) subtopicsLocked(area string) []string {
+	var subtopics []string
+	for name := range s.tags {
+		if name != area && IsWithin(name, area) {
+			subtopics = append(subtopics, name)
+		}
+	}
+	sort.Strings(subtopics)
+	return subtopics
+}
+
+// snapshotLocked copies the registry so a failed write can be undone; callers must hold the lock
+func (s *Store) snapshotLocked() map[string]Tag {
+	snapshot := make(map[string]Tag, len(s.tags))
+	for name := range s.tags {
+		snapshot[name] = s.tags[name]
+	}
+	return snapshot
+}
+
+// persistLocked writes the registry to disk; callers must hold the write lock
+func (s *Store) persistLocked() error {
+	if s.path == "" {
+		return nil
+	}
+
+	tags := make([]Tag, 0, len(s.tags))
+	for name := range s.tags {
+		tags = append(tags, s.tags[name])
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+
+	data, err := json.MarshalIndent(tags, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode tag registry: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o750); err != nil {
+		return fmt.Errorf("failed to create tag registry folder: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write tag registry: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
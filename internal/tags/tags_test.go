@@ -0,0 +1,90 @@
+package tags
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "infra/kubernetes", Normalize(" Infra / Kubernetes "))
+	assert.Equal(t, "bug-fix", Normalize("Bug  Fix"))
+	assert.Equal(t, "infra", Normalize("/infra//"))
+	assert.Empty(t, Normalize(" / "))
+	assert.Equal(t, "infra", Parent("infra/kubernetes"))
+	assert.Empty(t, Parent("infra"))
+	assert.Equal(t, "kubernetes", Leaf("infra/kubernetes"))
+	assert.True(t, IsWithin("infra/kubernetes", "infra"))
+	assert.False(t, IsWithin("infrastructure", "infra"))
+}
+
+func TestStoreLifecycle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tags.json")
+	store := NewStore(path)
+
+	created, err := store.Create(Tag{Name: "Infra/Kubernetes", Description: "Cluster operations"})
+	require.NoError(t, err)
+	assert.Equal(t, "infra/kubernetes", created.Name)
+	_, ok := store.Get("infra")
+	assert.True(t, ok, "creating a subtopic registers its area")
+	_, err = store.Create(Tag{Name: "infra/kubernetes"})
+	assert.ErrorContains(t, err, "already exists")
+	_, err = store.Create(Tag{Name: " "})
+	assert.Error(t, err)
+
+	renames, err := store.Rename("infra", "platform")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"infra": "platform", "infra/kubernetes": "platform/kubernetes"}, renames)
+	resolved, ok := store.Resolve("infra/kubernetes")
+	require.True(t, ok)
+	assert.Equal(t, "platform/kubernetes", resolved.Name)
+	assert.Equal(t, "Cluster operations", resolved.Description)
+	_, err = store.Create(Tag{Name: "infra"})
+	assert.ErrorContains(t, err, "alias")
+	_, err = store.Rename("platform", "platform/core")
+	assert.ErrorContains(t, err, "below itself")
+
+	_, err = store.Create(Tag{Name: "k8s"})
+	require.NoError(t, err)
+	merged, err := store.Merge([]string{"k8s", "Kubernets"}, "platform/kubernetes")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"infra/kubernetes", "k8s", "kubernets"}, merged.Aliases)
+	_, ok = store.Get("k8s")
+	assert.False(t, ok)
+	assert.Equal(t, "platform/kubernetes", store.Canonical("K8s"))
+	assert.Equal(t, "Unknown", store.Canonical("Unknown"))
+	_, err = store.Merge([]string{"platform"}, "platform/kubernetes")
+	assert.Error(t, err, "a tag cannot be merged into its own subtopic")
+
+	_, err = store.Delete("platform")
+	assert.ErrorContains(t, err, "subtopics")
+
+	reloaded := NewStore(path)
+	require.NoError(t, reloaded.Load())
+	assert.Len(t, reloaded.List(), 2)
+	resolved, ok = reloaded.Resolve("kubernets")
+	require.True(t, ok)
+	assert.Equal(t, "platform/kubernetes", resolved.Name)
+
+	_, err = reloaded.Delete("platform/kubernetes")
+	require.NoError(t, err)
+	_, ok = reloaded.Resolve("k8s")
+	assert.False(t, ok, "a deleted tag frees its aliases")
+}
+
+func TestSuggest(t *testing.T) {
+	store := NewStore("")
+	for _, name := range []string{"performance", "security", "infra/kubernetes", "api"} {
+		_, err := store.Create(Tag{Name: name})
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{"performance"}, store.Suggest("perfromance"))
+	assert.Equal(t, []string{"security"}, store.Suggest("Securty"))
+	assert.Equal(t, []string{"infra/kubernetes"}, store.Suggest("kubernetes"), "a subtopic is suggested for its bare name")
+	assert.Empty(t, store.Suggest("performance"), "registered tags need no suggestion")
+	assert.Empty(t, store.Suggest("ui"), "short names are too ambiguous")
+	assert.Empty(t, store.Suggest("database"))
+}
@@ -0,0 +1,66 @@
+// Package tenancy identifies the tenant behind an authenticated request and
+// the projects, that is repositories, it owns. The storage layer holds every
+// call made on a tenant's behalf to those projects, whatever repository the
+// request names.
+package tenancy
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"lerian-mcp-memory/internal/auth"
+	"lerian-mcp-memory/internal/security"
+)
+
+// AllProjects in a tenant's projects gives it every repository, for operators
+const AllProjects = "*"
+
+// ErrCrossTenant is returned for storage calls that reach another tenant's data
+var ErrCrossTenant = errors.New("access to another tenant's project denied")
+
+// Tenant is the authenticated owner of a request
+type Tenant struct {
+	ID       string   `json:"id"`
+	Projects []string `json:"projects"`
+}
+
+// Allows reports whether the tenant owns repository
+func (t *Tenant) Allows(repository string) bool {
+	return t.AllowsAll() || (repository != "" && slices.Contains(t.Projects, repository))
+}
+
+// AllowsAll reports whether the tenant owns every repository
+func (t *Tenant) AllowsAll() bool {
+	return slices.Contains(t.Projects, AllProjects)
+}
+
+// FromKey returns the tenant of an API key: the key's own ID, owning the
+// key's projects
+func FromKey(key *auth.Key) *Tenant {
+	return &Tenant{ID: "api_key:" + key.ID, Projects: slices.Clone(key.Projects)}
+}
+
+// FromClaims returns the tenant of a bearer token: its client, or its
+// subject for tokens without one, owning the token's projects claim
+func FromClaims(claims *security.TokenClaims) *Tenant {
+	id := claims.ClientID
+	if id == "" {
+		id = claims.Subject
+	}
+	return &Tenant{ID: "oauth:" + id, Projects: slices.Clone(claims.Projects)}
+}
+
+type tenantContextKey struct{}
+
+// WithTenant returns a context whose storage calls are held to tenant's projects
+func WithTenant(ctx context.Context, tenant *Tenant) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// FromContext returns the tenant of the request served with ctx, or nil for
+// requests that are not held to a tenant, such as those over stdio
+func FromContext(ctx context.Context) *Tenant {
+	tenant, _ := ctx.Value(tenantContextKey{}).(*Tenant)
+	return tenant
+}
@@ -0,0 +1,279 @@
+// Package timeline groups stored memories into calendar buckets so clients
+// can browse what happened in a repository day by day or week by week, and
+// drill into a single bucket.
+package timeline
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"lerian-mcp-memory/pkg/types"
+)
+
+// Granularity is the length of a timeline bucket
+type Granularity string
+
+const (
+	// GranularityDay buckets activity by UTC calendar day
+	GranularityDay Granularity = "day"
+	// GranularityWeek buckets activity by ISO week, starting on Monday (UTC)
+	GranularityWeek Granularity = "week"
+)
+
+const (
+	// defaultDays is the window covered by a daily timeline without a start
+	defaultDays = 14
+	// defaultWeeks is the window covered by a weekly timeline without a start
+	defaultWeeks = 8
+	// MaxBuckets bounds the buckets a single timeline may span
+	MaxBuckets = 366
+	// highlightsPerBucket is the number of notable memories listed per bucket
+	highlightsPerBucket = 3
+	// maxSummaryRunes shortens entries of memories without a summary
+	maxSummaryRunes = 120
+)
+
+// Valid checks if the granularity is supported
+func (g Granularity) Valid() bool {
+	return g == GranularityDay || g == GranularityWeek
+}
+
+// Start returns the start of the bucket containing t
+func (g Granularity) Start(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if g == GranularityWeek {
+		// Weekday counts from Sunday; ISO weeks start on Monday
+		offset := (int(day.Weekday()) + 6) % 7
+		day = day.AddDate(0, 0, -offset)
+	}
+	return day
+}
+
+// Next returns the start of the bucket following the one starting at start
+func (g Granularity) Next(start time.Time) time.Time {
+	if g == GranularityWeek {
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// Query selects the window and memories a timeline covers
+type Query struct {
+	Granularity Granularity
+	// From and To bound the window; From defaults to 14 days or 8 weeks
+	// before To, and To defaults to now
+	From time.Time
+	To   time.Time
+	// Types and SessionID narrow the memories counted
+	Types     []types.ChunkType
+	SessionID string
+}
+
+// Normalize fills defaults relative to now, aligns the window to bucket
+// boundaries and validates it
+func (q *Query) Normalize(now time.Time) error {
+	if q.Granularity == "" {
+		q.Granularity = GranularityDay
+	}
+	if !q.Granularity.Valid() {
+		return fmt.Errorf("invalid granularity: %q (expected %s or %s)", q.Granularity, GranularityDay, GranularityWeek)
+	}
+	if q.To.IsZero() {
+		q.To = now
+	}
+	if q.From.IsZero() {
+		if q.Granularity == GranularityWeek {
+			q.From = q.Granularity.Start(q.To).AddDate(0, 0, -7*(defaultWeeks-1))
+		} else {
+			q.From = q.Granularity.Start(q.To).AddDate(0, 0, -(defaultDays - 1))
+		}
+	}
+	if q.To.Before(q.From) {
+		return errors.New("timeline end must not be before its start")
+	}
+
+	q.From = q.Granularity.Start(q.From)
+	q.To = q.Granularity.Next(q.Granularity.Start(q.To))
+	buckets := 0
+	for start := q.From; start.Before(q.To); start = q.Granularity.Next(start) {
+		buckets++
+	}
+	if buckets > MaxBuckets {
+		return fmt.Errorf("timeline spans %d buckets; narrow the window or use a coarser granularity (max %d)", buckets, MaxBuckets)
+	}
+	return nil
+}
+
+// matches reports whether a memory passes the query's type and session filters
+func (q *Query) matches(chunk *types.ConversationChunk) bool {
+	if q.SessionID != "" && chunk.SessionID != q.SessionID {
+		return false
+	}
+	if len(q.Types) == 0 {
+		return true
+	}
+	for _, chunkType := range q.Types {
+		if chunk.Type == chunkType {
+			return true
+		}
+	}
+	return false
+}
+
+// Entry is a memory listed in a timeline
+type Entry struct {
+	ChunkID    string          `json:"chunk_id"`
+	Type       types.ChunkType `json:"type"`
+	Summary    string          `json:"summary"`
+	SessionID  string          `json:"session_id"`
+	Repository string          `json:"repository"`
+	Timestamp  time.Time       `json:"timestamp"`
+}
+
+// Bucket is the activity of one day or week
+type Bucket struct {
+	Start    time.Time      `json:"start"`
+	End      time.Time      `json:"end"`
+	Count    int            `json:"count"`
+	ByType   map[string]int `json:"by_type"`
+	Sessions int            `json:"sessions"`
+	// Highlights are the bucket's most notable memories, decisions first
+	Highlights []Entry `json:"highlights"`
+
+	sessions map[string]bool
+	entries  []Entry
+}
+
+// Timeline is the bucketed activity of a window
+type Timeline struct {
+	Granularity Granularity `json:"granularity"`
+	From        time.Time   `json:"from"`
+	To          time.Time   `json:"to"`
+	Total       int         `json:"total"`
+	// Buckets covers the whole window in order, including empty buckets, so
+	// clients can draw gaps
+	Buckets []*Bucket `json:"buckets"`
+}
+
+// Build buckets the memories matching a normalized query
+func Build(chunks []types.ConversationChunk, q *Query) *Timeline {
+	tl := &Timeline{Granularity: q.Granularity, From: q.From, To: q.To, Buckets: make([]*Bucket, 0)}
+	index := make(map[time.Time]*Bucket)
+	for start := q.From; start.Before(q.To); start = q.Granularity.Next(start) {
+		bucket := &Bucket{Start: start, End: q.Granularity.Next(start), ByType: make(map[string]int), sessions: make(map[string]bool)}
+		tl.Buckets = append(tl.Buckets, bucket)
+		index[start] = bucket
+	}
+
+	for i := range chunks {
+		chunk := &chunks[i]
+		if chunk.Timestamp.Before(q.From) || !chunk.Timestamp.Before(q.To) || !q.matches(chunk) {
+			continue
+		}
+		bucket := index[q.Granularity.Start(chunk.Timestamp)]
+		bucket.Count++
+		bucket.ByType[string(chunk.Type)]++
+		if chunk.SessionID != "" {
+			bucket.sessions[chunk.SessionID] = true
+		}
+		bucket.entries = append(bucket.entries, newEntry(chunk))
+		tl.Total++
+	}
+
+	for _, bucket := range tl.Buckets {
+		bucket.Sessions = len(bucket.sessions)
+		bucket.Highlights = highlights(bucket.entries)
+	}
+	return tl
+}
+
+// Entries lists the memories passing a query's filters in the bucket
+// containing start, newest first. The bucket may lie outside the query's window. It returns the entries after offset, up to
+// limit, and how many there are in total.
+func Entries(chunks []types.ConversationChunk, q *Query, start time.Time, offset, limit int) ([]Entry, int) {
+	start = q.Granularity.Start(start)
+	end := q.Granularity.Next(start)
+
+	entries := make([]Entry, 0)
+	for i := range chunks {
+		chunk := &chunks[i]
+		if chunk.Timestamp.Before(start) || !chunk.Timestamp.Before(end) || !q.matches(chunk) {
+			continue
+		}
+		entries = append(entries, newEntry(chunk))
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Timestamp.After(entries[j].Timestamp) })
+
+	total := len(entries)
+	if offset >= total {
+		return []Entry{}, total
+	}
+	entries = entries[offset:]
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, total
+}
+
+// highlightRank orders memory types by how much they say about a period
+var highlightRank = map[types.ChunkType]int{
+	types.ChunkTypeArchitectureDecision: 0,
+	types.ChunkTypeSolution:             1,
+	types.ChunkTypeProblem:              2,
+	types.ChunkTypeSessionSummary:       3,
+	types.ChunkTypeTask:                 4,
+	types.ChunkTypeCodeChange:           5,
+}
+
+// highlights picks a bucket's most notable entries, newest first within a type
+func highlights(entries []Entry) []Entry {
+	rank := func(e Entry) int {
+		if r, ok := highlightRank[e.Type]; ok {
+			return r
+		}
+		return len(highlightRank)
+	}
+	sorted := append([]Entry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if ri, rj := rank(sorted[i]), rank(sorted[j]); ri != rj {
+			return ri < rj
+		}
+		return sorted[i].Timestamp.After(sorted[j].Timestamp)
+	})
+	if len(sorted) > highlightsPerBucket {
+		sorted = sorted[:highlightsPerBucket]
+	}
+	if sorted == nil {
+		sorted = []Entry{}
+	}
+	return sorted
+}
+
+// newEntry lists a memory in a timeline
+func newEntry(chunk *types.ConversationChunk) Entry {
+	return Entry{
+		ChunkID:    chunk.ID,
+		Type:       chunk.Type,
+		Summary:    entrySummary(chunk),
+		SessionID:  chunk.SessionID,
+		Repository: chunk.Metadata.Repository,
+		Timestamp:  chunk.Timestamp,
+	}
+}
+
+// entrySummary returns the chunk's summary, or the first line of its content
+// shortened to maxSummaryRunes
+func entrySummary(chunk *types.ConversationChunk) string {
+	if chunk.Summary != "" {
+		return chunk.Summary
+	}
+	summary, _, _ := strings.Cut(chunk.Content, "\n")
+	if runes := []rune(summary); len(runes) > maxSummaryRunes {
+		summary = string(runes[:maxSummaryRunes]) + "..."
+	}
+	return summary
+}
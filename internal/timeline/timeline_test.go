@@ -0,0 +1,112 @@
+package timeline
+
+import (
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newChunk(id string, chunkType types.ChunkType, sessionID string, at time.Time) types.ConversationChunk {
+	return types.ConversationChunk{
+		ID:        id,
+		SessionID: sessionID,
+		Type:      chunkType,
+		Content:   "content " + id + "\nmore detail",
+		Timestamp: at,
+		Metadata:  types.ChunkMetadata{Repository: "github.com/acme/api"},
+	}
+}
+
+func TestGranularityStart(t *testing.T) {
+	// Wednesday afternoon
+	at := time.Date(2024, 3, 13, 15, 4, 5, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 3, 13, 0, 0, 0, 0, time.UTC), GranularityDay.Start(at))
+	assert.Equal(t, time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), GranularityWeek.Start(at), "weeks start on Monday")
+	assert.Equal(t, time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), GranularityWeek.Start(time.Date(2024, 3, 17, 23, 0, 0, 0, time.UTC)), "Sunday ends the week")
+}
+
+func TestQueryNormalize(t *testing.T) {
+	now := time.Date(2024, 3, 13, 15, 0, 0, 0, time.UTC)
+
+	q := Query{}
+	require.NoError(t, q.Normalize(now))
+	assert.Equal(t, GranularityDay, q.Granularity)
+	assert.Equal(t, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), q.From, "14 days including today")
+	assert.Equal(t, time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC), q.To)
+
+	q = Query{Granularity: GranularityWeek}
+	require.NoError(t, q.Normalize(now))
+	assert.Equal(t, time.Date(2024, 1, 22, 0, 0, 0, 0, time.UTC), q.From, "8 weeks including this one")
+	assert.Equal(t, time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC), q.To)
+
+	q = Query{Granularity: "month"}
+	assert.ErrorContains(t, q.Normalize(now), "invalid granularity")
+	q = Query{From: now, To: now.AddDate(0, 0, -1)}
+	assert.Error(t, q.Normalize(now))
+	q = Query{From: now.AddDate(-2, 0, 0)}
+	assert.ErrorContains(t, q.Normalize(now), "narrow the window")
+}
+
+func TestBuild(t *testing.T) {
+	day := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	chunks := []types.ConversationChunk{
+		newChunk("a", types.ChunkTypeDiscussion, "s1", day.Add(9*time.Hour)),
+		newChunk("b", types.ChunkTypeArchitectureDecision, "s1", day.Add(10*time.Hour)),
+		newChunk("c", types.ChunkTypeProblem, "s2", day.Add(11*time.Hour)),
+		newChunk("d", types.ChunkTypeSolution, "s2", day.Add(12*time.Hour)),
+		newChunk("e", types.ChunkTypeSolution, "s3", day.AddDate(0, 0, 2)),
+		newChunk("outside", types.ChunkTypeSolution, "s3", day.AddDate(0, 0, 5)),
+	}
+
+	q := Query{From: day, To: day.AddDate(0, 0, 2)}
+	require.NoError(t, q.Normalize(time.Now()))
+	tl := Build(chunks, &q)
+
+	require.Len(t, tl.Buckets, 3, "empty days are kept")
+	assert.Equal(t, 5, tl.Total)
+	first := tl.Buckets[0]
+	assert.Equal(t, 4, first.Count)
+	assert.Equal(t, 2, first.Sessions)
+	assert.Equal(t, map[string]int{"discussion": 1, "architecture_decision": 1, "problem": 1, "solution": 1}, first.ByType)
+	require.Len(t, first.Highlights, 3)
+	assert.Equal(t, []string{"b", "d", "c"}, []string{first.Highlights[0].ChunkID, first.Highlights[1].ChunkID, first.Highlights[2].ChunkID}, "decisions and solutions come first")
+	assert.Equal(t, "content b", first.Highlights[0].Summary)
+	assert.Equal(t, 0, tl.Buckets[1].Count)
+	assert.Empty(t, tl.Buckets[1].Highlights)
+	assert.Equal(t, 1, tl.Buckets[2].Count)
+
+	q = Query{From: day, To: day.AddDate(0, 0, 2), Types: []types.ChunkType{types.ChunkTypeSolution}, SessionID: "s2"}
+	require.NoError(t, q.Normalize(time.Now()))
+	assert.Equal(t, 1, Build(chunks, &q).Total)
+}
+
+func TestEntries(t *testing.T) {
+	day := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	chunks := []types.ConversationChunk{
+		newChunk("a", types.ChunkTypeDiscussion, "s1", day.Add(9*time.Hour)),
+		newChunk("b", types.ChunkTypeSolution, "s1", day.AddDate(0, 0, 3)),
+		newChunk("c", types.ChunkTypeProblem, "s2", day.AddDate(0, 0, 6)),
+		newChunk("next-week", types.ChunkTypeProblem, "s2", day.AddDate(0, 0, 7)),
+	}
+
+	q := Query{Granularity: GranularityWeek}
+	require.NoError(t, q.Normalize(day))
+
+	entries, total := Entries(chunks, &q, day.AddDate(0, 0, 4), 0, 2)
+	assert.Equal(t, 3, total)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "c", entries[0].ChunkID, "newest first")
+	assert.Equal(t, "b", entries[1].ChunkID)
+
+	entries, _ = Entries(chunks, &q, day, 2, 2)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "a", entries[0].ChunkID)
+
+	entries, total = Entries(chunks, &q, day, 5, 2)
+	assert.Empty(t, entries)
+	assert.Equal(t, 3, total)
+}
@@ -0,0 +1,415 @@
+package tsgen
+
+import (
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+// tsconfig builds the package as ES modules with declarations
+const tsconfig = `{
+  "compilerOptions": {
+    "target": "ES2022",
+    "module": "NodeNext",
+    "moduleResolution": "NodeNext",
+    "lib": ["ES2022", "DOM"],
+    "declaration": true,
+    "strict": true,
+    "outDir": "dist",
+    "rootDir": "src"
+  },
+  "include": ["src"]
+}
+`
+
+// versionSource renders the versions the client reports
+func versionSource(opts Options) string {
+	return header + `
+/** Version of this package, sent as the client version on initialize */
+export const VERSION = ` + quote(opts.Version) + `;
+
+/** MCP protocol version the client speaks */
+export const PROTOCOL_VERSION = ` + quote(protocol.Version) + `;
+`
+}
+
+// indexSource is the package entry point
+const indexSource = `
+export * from "./client.js";
+export * from "./events.js";
+export * from "./protocol.js";
+export * from "./tools.js";
+export * from "./version.js";
+`
+
+// protocolSource holds the JSON-RPC and tool result types
+const protocolSource = `
+/** JSON-RPC error code of rate limited requests; data.retry_after_ms says when to retry */
+export const RATE_LIMITED = -32001;
+
+export interface JSONRPCError {
+  code: number;
+  message: string;
+  data?: unknown;
+}
+
+export interface JSONRPCResponse<T = unknown> {
+  jsonrpc: "2.0";
+  id: number | string | null;
+  result?: T;
+  error?: JSONRPCError;
+}
+
+/** A resource embedded in a tool result, with text or base64 blob contents */
+export interface EmbeddedResource {
+  uri: string;
+  mimeType?: string;
+  text?: string;
+  blob?: string;
+}
+
+/** One item of a tool result: text, a base64 image or audio clip, or a resource */
+export interface ToolContent {
+  type: "text" | "image" | "audio" | "resource";
+  text?: string;
+  data?: string;
+  mimeType?: string;
+  resource?: EmbeddedResource;
+}
+
+export interface ToolCallResult {
+  content: ToolContent[];
+  structuredContent?: unknown;
+  isError?: boolean;
+}
+
+/** Why a tool call failed and how to recover, sent as structured content of isError results */
+export interface ToolErrorDetails {
+  code:
+    | "missing_parameter"
+    | "invalid_parameter"
+    | "not_found"
+    | "conflict"
+    | "permission_denied"
+    | "unsupported"
+    | "unavailable"
+    | "timeout"
+    | "internal_error";
+  message: string;
+  parameter?: string;
+  suggestion?: string;
+  /** Arguments that would have worked */
+  example?: unknown;
+  retryable: boolean;
+}
+
+/** A JSON-RPC error the server answered a request with */
+export class RpcError extends Error {
+  constructor(
+    readonly code: number,
+    message: string,
+    readonly data?: unknown,
+  ) {
+    super(message);
+    this.name = "RpcError";
+  }
+
+  /** Milliseconds to wait before retrying a rate limited request, if it was one */
+  get retryAfterMs(): number | undefined {
+    if (this.code !== RATE_LIMITED || typeof this.data !== "object" || this.data === null) {
+      return undefined;
+    }
+    const retryAfter = (this.data as Record<string, unknown>).retry_after_ms;
+    return typeof retryAfter === "number" ? retryAfter : undefined;
+  }
+}
+
+/** An error a tool reported in its result, with its details when the server sent them */
+export class ToolError extends Error {
+  constructor(
+    readonly tool: string,
+    message: string,
+    readonly details?: ToolErrorDetails,
+  ) {
+    super(tool + ": " + message);
+    this.name = "ToolError";
+  }
+}
+`
+
+// eventsSourceTail holds the /ws event types not derived from Go types
+const eventsSourceTail = `
+/** A notification sent on stdio, WebSocket and SSE connections */
+export interface ServerNotification {
+  jsonrpc: "2.0";
+  method: NotificationMethod;
+  params?: Record<string, unknown>;
+}
+
+/** Data of a task event: the task board resource and the status change */
+export interface TaskUpdatedData {
+  uri: string;
+  from_status: string;
+  to_status: string;
+}
+
+/** Sent when a task moves between statuses */
+export interface TaskUpdatedEvent extends MemoryEvent {
+  type: "task";
+  action: "updated";
+  data: TaskUpdatedData;
+}
+
+/** Reports whether event is a task status change */
+export function isTaskUpdatedEvent(event: MemoryEvent): event is TaskUpdatedEvent {
+  return event.type === "task" && event.action === "updated";
+}
+
+/**
+ * A message a client sends on /ws. subscribe and unsubscribe set or clear the
+ * repository and session events are filtered by; ping is answered with a
+ * pong event. Connections also get connection and heartbeat events. A client
+ * held to a tenant only gets events of the tenant's projects, and subscribing
+ * to another repository is answered with an error event.
+ */
+export type ClientMessage =
+  | { type: "subscribe"; repository?: string; session_id?: string }
+  | { type: "unsubscribe"; repository?: string; session_id?: string }
+  | { type: "ping" };
+`
+
+// clientSource holds the HTTP client and the WebSocket event stream
+const clientSource = `
+import type { ClientMessage, MemoryEvent, ServerNotification } from "./events.js";
+import { RpcError, ToolError } from "./protocol.js";
+import type { JSONRPCResponse, ToolCallResult, ToolErrorDetails } from "./protocol.js";
+import type { ToolArguments, ToolName } from "./tools.js";
+import { PROTOCOL_VERSION, VERSION } from "./version.js";
+
+/** Header plain HTTP clients keep their session with */
+export const SESSION_HEADER = "Mcp-Session-Id";
+
+export interface MemoryClientOptions {
+  /** Base URL of the server, such as http://localhost:9080 */
+  baseUrl: string;
+  /** Headers sent with every request, such as request signatures */
+  headers?: Record<string, string>;
+  /** fetch implementation; defaults to the global fetch */
+  fetch?: typeof fetch;
+  /** Client name sent on initialize */
+  clientName?: string;
+}
+
+/**
+ * MemoryClient calls the server over POST /mcp. initialize starts a session
+ * the client keeps with the Mcp-Session-Id header, so tools see the same
+ * session state across calls; close ends it.
+ */
+export class MemoryClient {
+  private readonly baseUrl: string;
+  private readonly fetchFn: typeof fetch;
+  private sessionId: string | undefined;
+  private nextId = 1;
+
+  constructor(private readonly options: MemoryClientOptions) {
+    this.baseUrl = options.baseUrl.replace(/\/+$/, "");
+    this.fetchFn = options.fetch ?? globalThis.fetch.bind(globalThis);
+  }
+
+  /** The session the server assigned on initialize, if any */
+  get session(): string | undefined {
+    return this.sessionId;
+  }
+
+  /** Performs the initialize handshake, starting a session */
+  initialize(): Promise<Record<string, unknown>> {
+    return this.request("initialize", {
+      protocolVersion: PROTOCOL_VERSION,
+      capabilities: {},
+      clientInfo: { name: this.options.clientName ?? "lerian-mcp-memory-ts", version: VERSION },
+    });
+  }
+
+  /** Sends a JSON-RPC request, throwing RpcError when the server answers with an error */
+  async request<T = unknown>(method: string, params?: unknown): Promise<T> {
+    const response = await this.fetchFn(this.baseUrl + "/mcp", {
+      method: "POST",
+      headers: this.headers({ "Content-Type": "application/json" }),
+      body: JSON.stringify({ jsonrpc: "2.0", id: this.nextId++, method, params }),
+    });
+    if (!response.ok) {
+      throw new Error(method + " failed with HTTP " + response.status + ": " + (await response.text()));
+    }
+    const session = response.headers.get(SESSION_HEADER);
+    if (session) {
+      this.sessionId = session;
+    }
+
+    const message = (await response.json()) as JSONRPCResponse<T>;
+    if (message.error) {
+      throw new RpcError(message.error.code, message.error.message, message.error.data);
+    }
+    return message.result as T;
+  }
+
+  /** Calls a tool with arguments checked against its schema */
+  callTool<N extends ToolName>(name: N, args: ToolArguments[N]): Promise<ToolCallResult> {
+    return this.request<ToolCallResult>("tools/call", { name, arguments: args });
+  }
+
+  /** Calls a tool and decodes the JSON of its text result, throwing ToolError when the tool fails */
+  async callToolJSON<N extends ToolName, T = unknown>(name: N, args: ToolArguments[N]): Promise<T> {
+    const result = await this.callTool(name, args);
+    const text = result.content.find((content) => content.type === "text")?.text ?? "";
+    if (result.isError) {
+      const details = (result.structuredContent as { error?: ToolErrorDetails } | undefined)?.error;
+      throw new ToolError(name, text, details);
+    }
+    return JSON.parse(text) as T;
+  }
+
+  /** Ends the session, dropping the state the server kept for it */
+  async close(): Promise<void> {
+    if (!this.sessionId) {
+      return;
+    }
+    const headers = this.headers({});
+    this.sessionId = undefined;
+    const response = await this.fetchFn(this.baseUrl + "/mcp", { method: "DELETE", headers });
+    // The session may have idled out already
+    if (!response.ok && response.status !== 404) {
+      throw new Error("closing the session failed with HTTP " + response.status);
+    }
+  }
+
+  private headers(extra: Record<string, string>): Record<string, string> {
+    const headers = { ...this.options.headers, ...extra };
+    if (this.sessionId) {
+      headers[SESSION_HEADER] = this.sessionId;
+    }
+    return headers;
+  }
+}
+
+export interface MemoryEventStreamOptions {
+  /** WebSocket URL of the server, such as ws://localhost:9080/ws */
+  url: string;
+  /** Only stream events of this repository */
+  repository?: string;
+  /** Only stream events of this session */
+  sessionId?: string;
+  /**
+   * API key or OAuth access token, sent as the access_token query parameter
+   * since browsers cannot set headers on WebSocket connections
+   */
+  accessToken?: string;
+  /** WebSocket implementation; defaults to the global WebSocket */
+  WebSocket?: typeof WebSocket;
+}
+
+/**
+ * MemoryEventStream follows the events the server streams on /ws, such as
+ * task status changes, and the notifications it sends the connection.
+ */
+export class MemoryEventStream {
+  private readonly socket: WebSocket;
+  private readonly eventListeners = new Set<(event: MemoryEvent) => void>();
+  private readonly notificationListeners = new Set<(notification: ServerNotification) => void>();
+
+  /** Resolves once the connection is open */
+  readonly ready: Promise<void>;
+
+  constructor(options: MemoryEventStreamOptions) {
+    const url = new URL(options.url);
+    if (options.repository) {
+      url.searchParams.set("repository", options.repository);
+    }
+    if (options.sessionId) {
+      url.searchParams.set("session_id", options.sessionId);
+    }
+    if (options.accessToken) {
+      url.searchParams.set("access_token", options.accessToken);
+    }
+    const Socket = options.WebSocket ?? globalThis.WebSocket;
+    this.socket = new Socket(url.toString());
+    this.ready = new Promise((resolve, reject) => {
+      this.socket.addEventListener("open", () => resolve(), { once: true });
+      this.socket.addEventListener("error", () => reject(new Error("WebSocket connection failed")), { once: true });
+    });
+    this.socket.addEventListener("message", (message) => this.dispatch(message.data));
+  }
+
+  /** Calls listener with each event; the returned function removes it */
+  onEvent(listener: (event: MemoryEvent) => void): () => void {
+    this.eventListeners.add(listener);
+    return () => this.eventListeners.delete(listener);
+  }
+
+  /** Calls listener with each notification; the returned function removes it */
+  onNotification(listener: (notification: ServerNotification) => void): () => void {
+    this.notificationListeners.add(listener);
+    return () => this.notificationListeners.delete(listener);
+  }
+
+  /** Sends a message, such as a subscription change */
+  send(message: ClientMessage): void {
+    this.socket.send(JSON.stringify(message));
+  }
+
+  /** Closes the connection */
+  close(): void {
+    this.socket.close();
+  }
+
+  private dispatch(data: unknown): void {
+    if (typeof data !== "string") {
+      return;
+    }
+    let message: unknown;
+    try {
+      message = JSON.parse(data);
+    } catch {
+      return;
+    }
+    if (typeof message !== "object" || message === null) {
+      return;
+    }
+
+    // The connection also carries JSON-RPC; only notifications are of interest
+    if ("jsonrpc" in message) {
+      if ("method" in message && !("id" in message)) {
+        this.notificationListeners.forEach((listener) => listener(message as ServerNotification));
+      }
+      return;
+    }
+    this.eventListeners.forEach((listener) => listener(message as MemoryEvent));
+  }
+}
+`
+
+// readme renders the package README
+func readme(opts Options) string {
+	return "# " + opts.PackageName + `
+
+Typed client for the Lerian MCP Memory server, for the web UI and editor
+extensions. It is generated from the server's tool manifest with
+` + "`make ts-client`" + `; do not edit it by hand.
+
+` + "```ts" + `
+import { MemoryClient, MemoryEventStream, isTaskUpdatedEvent } from "` + opts.PackageName + `";
+
+const client = new MemoryClient({ baseUrl: "http://localhost:9080" });
+await client.initialize();
+const results = await client.callToolJSON("memory_read", {
+  operation: "search",
+  scope: "single",
+  options: { repository: "github.com/acme/api", query: "flaky build" },
+});
+
+const events = new MemoryEventStream({ url: "ws://localhost:9080/ws", repository: "github.com/acme/api" });
+events.onEvent((event) => {
+  if (isTaskUpdatedEvent(event)) {
+    console.log(event.chunk_id, event.data.to_status);
+  }
+});
+` + "```" + `
+`
+}
@@ -0,0 +1,411 @@
+// Package tsgen generates the TypeScript client of the memory server, the
+// npm package the web UI and editor extensions use. Tool argument types and
+// annotations come from the tool manifest, the tools the server lists with
+// tools/list, and the WebSocket event types from the hub's event struct, so
+// regenerating after a schema change keeps the client in step with the
+// server. The package is written as source; npm builds it on publish.
+package tsgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"lerian-mcp-memory/internal/mcp"
+	"lerian-mcp-memory/internal/websocket"
+	mcpclient "lerian-mcp-memory/pkg/mcp/client"
+)
+
+// header starts every generated source file
+const header = "// Code generated by `make ts-client`. DO NOT EDIT.\n"
+
+// identifierPattern matches property names TypeScript takes unquoted
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// notificationMethods are the notifications the server sends clients
+var notificationMethods = []string{
+	mcpclient.NotificationProgress,
+	mcpclient.NotificationResourceUpdated,
+	mcpclient.NotificationResourcesListChanged,
+	mcpclient.NotificationToolsListChanged,
+}
+
+// Options names the generated package
+type Options struct {
+	PackageName string // npm package name
+	Version     string // npm package version
+}
+
+// DefaultOptions returns the name and version the package is published with
+func DefaultOptions() Options {
+	return Options{PackageName: "@lerianstudio/mcp-memory-client", Version: "0.1.0"}
+}
+
+// Files renders the package as files keyed by their path relative to the
+// package root
+func Files(tools []mcp.AnnotatedTool, opts Options) (map[string][]byte, error) {
+	pkg, err := packageJSON(opts)
+	if err != nil {
+		return nil, err
+	}
+	tools = append([]mcp.AnnotatedTool(nil), tools...)
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+
+	return map[string][]byte{
+		"package.json":    pkg,
+		"tsconfig.json":   []byte(tsconfig),
+		".gitignore":      []byte("node_modules/\ndist/\n"),
+		"README.md":       []byte(readme(opts)),
+		"src/index.ts":    []byte(header + indexSource),
+		"src/version.ts":  []byte(versionSource(opts)),
+		"src/client.ts":   []byte(header + clientSource),
+		"src/protocol.ts": []byte(header + protocolSource),
+		"src/tools.ts":    []byte(toolsSource(tools)),
+		"src/events.ts":   []byte(eventsSource()),
+	}, nil
+}
+
+// Write renders the package into dir. Files it does not generate, such as
+// node_modules and dist from a local build, are left alone.
+func Write(tools []mcp.AnnotatedTool, opts Options, dir string) error {
+	files, err := Files(tools, opts)
+	if err != nil {
+		return err
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		// The package is published, so its sources are world readable
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil { //nolint:gosec // Published sources
+			return fmt.Errorf("failed to create client directory: %w", err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil { //nolint:gosec // Published sources
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// packageJSON renders the npm manifest
+func packageJSON(opts Options) ([]byte, error) {
+	pkg := struct {
+		Name            string            `json:"name"`
+		Version         string            `json:"version"`
+		Description     string            `json:"description"`
+		License         string            `json:"license"`
+		Type            string            `json:"type"`
+		Main            string            `json:"main"`
+		Types           string            `json:"types"`
+		Files           []string          `json:"files"`
+		Scripts         map[string]string `json:"scripts"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}{
+		Name:        opts.PackageName,
+		Version:     opts.Version,
+		Description: "Typed client for the Lerian MCP Memory server",
+		License:     "Apache-2.0",
+		Type:        "module",
+		Main:        "dist/index.js",
+		Types:       "dist/index.d.ts",
+		Files:       []string{"dist"},
+		Scripts: map[string]string{
+			"build":          "tsc -p .",
+			"prepublishOnly": "npm run build",
+		},
+		DevDependencies: map[string]string{"typescript": "^5.4.0"},
+	}
+	data, err := json.MarshalIndent(pkg, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode package.json: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// toolsSource renders the argument types and annotations of the tools
+func toolsSource(tools []mcp.AnnotatedTool) string {
+	var b strings.Builder
+	b.WriteString(header)
+	b.WriteString(`
+/** Hints describing how a tool behaves; see the MCP tool annotations */
+export interface ToolAnnotations {
+  title?: string;
+  readOnlyHint?: boolean;
+  destructiveHint?: boolean;
+  idempotentHint?: boolean;
+  openWorldHint?: boolean;
+}
+`)
+
+	for i := range tools {
+		tool := &tools[i]
+		b.WriteString("\n")
+		writeDoc(&b, "", tool.Description)
+		fmt.Fprintf(&b, "export type %s = %s;\n", argumentsType(tool.Name), schemaType(jsonSchema(tool.InputSchema), ""))
+	}
+
+	b.WriteString("\n/** Arguments of each tool, by tool name */\nexport interface ToolArguments {\n")
+	for i := range tools {
+		fmt.Fprintf(&b, "  %s: %s;\n", propertyName(tools[i].Name), argumentsType(tools[i].Name))
+	}
+	b.WriteString("}\n\n/** Name of a tool the server lists */\nexport type ToolName = keyof ToolArguments;\n")
+
+	b.WriteString("\n/** Annotations of each tool, by tool name */\nexport const toolAnnotations: Record<ToolName, ToolAnnotations> = {\n")
+	for i := range tools {
+		fmt.Fprintf(&b, "  %s: %s,\n", propertyName(tools[i].Name), annotationsLiteral(tools[i].Annotations))
+	}
+	b.WriteString("};\n")
+	return b.String()
+}
+
+// annotationsLiteral renders tool annotations as a TypeScript object literal
+func annotationsLiteral(annotations *mcp.ToolAnnotations) string {
+	if annotations == nil {
+		return "{}"
+	}
+	var fields []string
+	if annotations.Title != "" {
+		fields = append(fields, "title: "+quote(annotations.Title))
+	}
+	hints := []struct {
+		name  string
+		value *bool
+	}{
+		{"readOnlyHint", annotations.ReadOnlyHint},
+		{"destructiveHint", annotations.DestructiveHint},
+		{"idempotentHint", annotations.IdempotentHint},
+		{"openWorldHint", annotations.OpenWorldHint},
+	}
+	for _, hint := range hints {
+		if hint.value != nil {
+			fields = append(fields, fmt.Sprintf("%s: %t", hint.name, *hint.value))
+		}
+	}
+	if len(fields) == 0 {
+		return "{}"
+	}
+	return "{ " + strings.Join(fields, ", ") + " }"
+}
+
+// eventsSource renders the types of the messages exchanged on /ws
+func eventsSource() string {
+	var b strings.Builder
+	b.WriteString(header)
+	b.WriteString("\n/** An event the server streams on /ws, such as a memory change or heartbeat */\n")
+	fmt.Fprintf(&b, "export interface MemoryEvent %s\n", structType(reflect.TypeOf(websocket.MemoryEvent{})))
+
+	b.WriteString("\n/** A notification the server sends MCP clients */\nexport type NotificationMethod =")
+	for _, method := range notificationMethods {
+		fmt.Fprintf(&b, "\n  | %s", quote(method))
+	}
+	b.WriteString(";\n")
+	b.WriteString(eventsSourceTail)
+	return b.String()
+}
+
+// argumentsType returns the name of a tool's argument type, e.g.
+// MemoryReadArguments for memory_read
+func argumentsType(tool string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(tool, func(r rune) bool { return r == '_' || r == '-' || r == '.' }) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	b.WriteString("Arguments")
+	return b.String()
+}
+
+// jsonSchema returns schema as decoded from JSON. Schemas built in Go hold
+// typed values, such as []string enums, that the conversion does not expect.
+func jsonSchema(schema map[string]interface{}) map[string]interface{} {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return map[string]interface{}{}
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return map[string]interface{}{}
+	}
+	return decoded
+}
+
+// schemaType converts a JSON schema to a TypeScript type. indent is the
+// indentation of the line the type starts on.
+func schemaType(schema map[string]interface{}, indent string) string {
+	if values, ok := schema["enum"].([]interface{}); ok && len(values) > 0 {
+		literals := make([]string, len(values))
+		for i, value := range values {
+			data, _ := json.Marshal(value)
+			literals[i] = string(data)
+		}
+		return strings.Join(literals, " | ")
+	}
+
+	switch schema["type"] {
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "null":
+		return "null"
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		if items == nil {
+			return "unknown[]"
+		}
+		item := schemaType(items, indent)
+		if strings.Contains(item, "|") {
+			item = "(" + item + ")"
+		}
+		return item + "[]"
+	case "object":
+		return objectType(schema, indent)
+	}
+	return "unknown"
+}
+
+// objectType converts an object schema to a TypeScript object type
+func objectType(schema map[string]interface{}, indent string) string {
+	properties, _ := schema["properties"].(map[string]interface{})
+	additional, open := schema["additionalProperties"].(map[string]interface{})
+	if !open {
+		open = schema["additionalProperties"] == true || schema["additionalProperties"] == nil && len(properties) == 0
+	}
+	if len(properties) == 0 {
+		switch {
+		case additional != nil:
+			return "Record<string, " + schemaType(additional, indent) + ">"
+		case open:
+			return "Record<string, unknown>"
+		}
+		return "Record<string, never>"
+	}
+
+	required := make(map[string]bool)
+	if names, ok := schema["required"].([]interface{}); ok {
+		for _, name := range names {
+			if s, ok := name.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	inner := indent + "  "
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, name := range names {
+		property, _ := properties[name].(map[string]interface{})
+		doc, _ := property["description"].(string)
+		if value, ok := property["default"]; ok {
+			data, _ := json.Marshal(value)
+			doc = strings.TrimSpace(doc + "\n@default " + string(data))
+		}
+		writeDoc(&b, inner, doc)
+		optional := "?"
+		if required[name] {
+			optional = ""
+		}
+		fmt.Fprintf(&b, "%s%s%s: %s;\n", inner, propertyName(name), optional, schemaType(property, inner))
+	}
+	if open {
+		fmt.Fprintf(&b, "%s[key: string]: unknown;\n", inner)
+	}
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+// structType converts a JSON-encoded Go struct to a TypeScript object type,
+// making omitempty fields optional
+func structType(t reflect.Type) string {
+	var b strings.Builder
+	b.WriteString("{\n")
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		optional := ""
+		if strings.Contains(options, "omitempty") {
+			optional = "?"
+		}
+		fmt.Fprintf(&b, "  %s%s: %s;\n", propertyName(name), optional, goType(field.Type))
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// goType converts a JSON-encoded Go type to a TypeScript type
+func goType(t reflect.Type) string {
+	if t == reflect.TypeOf(time.Time{}) {
+		return "string"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return goType(t.Elem()) + "[]"
+	case reflect.Map:
+		return "Record<string, " + goType(t.Elem()) + ">"
+	case reflect.Ptr:
+		return goType(t.Elem())
+	}
+	return "unknown"
+}
+
+// writeDoc writes text as a JSDoc comment, if there is any
+func writeDoc(b *strings.Builder, indent, text string) {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "*/", "*\\/"))
+	if text == "" {
+		return
+	}
+	lines := strings.Split(text, "\n")
+	if len(lines) == 1 {
+		fmt.Fprintf(b, "%s/** %s */\n", indent, lines[0])
+		return
+	}
+	fmt.Fprintf(b, "%s/**\n", indent)
+	for _, line := range lines {
+		fmt.Fprintf(b, "%s%s\n", indent, strings.TrimRight(" * "+line, " "))
+	}
+	fmt.Fprintf(b, "%s */\n", indent)
+}
+
+// propertyName returns name as a TypeScript property name, quoted if needed
+func propertyName(name string) string {
+	if identifierPattern.MatchString(name) {
+		return name
+	}
+	return quote(name)
+}
+
+// quote returns s as a TypeScript string literal
+func quote(s string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,91 @@
+package tsgen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lerian-mcp-memory/internal/config"
+	"lerian-mcp-memory/internal/mcp"
+
+	sdk "github.com/fredcamaral/gomcp-sdk"
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaType(t *testing.T) {
+	schema := jsonSchema(sdk.ObjectSchema("Search", map[string]interface{}{
+		"operation": map[string]interface{}{"type": "string", "enum": []string{"search", "get_context"}},
+		"limit":     map[string]interface{}{"type": "integer", "description": "Results to return", "default": 10},
+		"tags":      sdk.ArraySchema("Tags", map[string]interface{}{"type": "string"}),
+		"weights":   map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "number"}},
+		"options":   map[string]interface{}{"type": "object", "additionalProperties": true},
+		"file-path": map[string]interface{}{"type": "string"},
+	}, []string{"operation"}))
+
+	assert.Equal(t, `{
+  "file-path"?: string;
+  /**
+   * Results to return
+   * @default 10
+   */
+  limit?: number;
+  operation: "search" | "get_context";
+  options?: Record<string, unknown>;
+  /** Tags */
+  tags?: string[];
+  weights?: Record<string, number>;
+}`, schemaType(schema, ""))
+
+	assert.Equal(t, `("a" | "b")[]`, schemaType(map[string]interface{}{
+		"type":  "array",
+		"items": map[string]interface{}{"enum": []interface{}{"a", "b"}},
+	}, ""))
+	assert.Equal(t, "unknown", schemaType(map[string]interface{}{}, ""))
+}
+
+func TestFiles(t *testing.T) {
+	readOnly := true
+	tools := []mcp.AnnotatedTool{
+		{Tool: protocol.Tool{Name: "memory_read", Description: "Read memories", InputSchema: sdk.ObjectSchema("Read", map[string]interface{}{
+			"query": sdk.StringParam("Search query", true),
+		}, []string{"query"})}, Annotations: &mcp.ToolAnnotations{ReadOnlyHint: &readOnly}},
+		{Tool: protocol.Tool{Name: "echo", InputSchema: sdk.ObjectSchema("Echo", map[string]interface{}{}, nil)}},
+	}
+	files, err := Files(tools, Options{PackageName: "@acme/memory", Version: "1.2.3"})
+	require.NoError(t, err)
+
+	toolsTS := string(files["src/tools.ts"])
+	assert.Contains(t, toolsTS, "/** Read memories */\nexport type MemoryReadArguments = {\n  /** Search query */\n  query: string;\n};")
+	assert.Contains(t, toolsTS, "export type EchoArguments = Record<string, unknown>;")
+	assert.Contains(t, toolsTS, "export interface ToolArguments {\n  echo: EchoArguments;\n  memory_read: MemoryReadArguments;\n}")
+	assert.Contains(t, toolsTS, "  echo: {},\n  memory_read: { readOnlyHint: true },\n")
+
+	eventsTS := string(files["src/events.ts"])
+	assert.Contains(t, eventsTS, "  chunk_id?: string;\n", "omitempty fields are optional")
+	assert.Contains(t, eventsTS, "  timestamp: string;\n")
+	assert.Contains(t, eventsTS, `  | "notifications/resources/updated"`)
+
+	assert.Contains(t, string(files["package.json"]), `"name": "@acme/memory"`)
+	assert.Contains(t, string(files["src/version.ts"]), `export const VERSION = "1.2.3";`)
+	for name, data := range files {
+		if filepath.Ext(name) == ".ts" {
+			assert.Contains(t, string(data), header, name)
+		}
+	}
+}
+
+// TestGeneratedClientUpToDate keeps the committed client in step with the tools
+func TestGeneratedClientUpToDate(t *testing.T) {
+	tools, err := mcp.ConsolidatedToolManifest(config.DefaultConfig())
+	require.NoError(t, err)
+	files, err := Files(tools, DefaultOptions())
+	require.NoError(t, err)
+
+	for name, data := range files {
+		committed, err := os.ReadFile(filepath.Join("..", "..", "clients", "typescript", filepath.FromSlash(name)))
+		require.NoError(t, err, "run make ts-client")
+		assert.Equal(t, string(data), string(committed), "%s is out of date; run make ts-client", name)
+	}
+}
@@ -0,0 +1,255 @@
+// Package usage meters what each tenant uses of the server, month by month,
+// and reports it, so teams can charge infrastructure cost back internally.
+// Tool calls, the sessions they belong to and the embeddings generated are
+// counted as they happen; the chunks and storage of a tenant's projects are
+// measured when a report is generated.
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"lerian-mcp-memory/internal/logging"
+	"lerian-mcp-memory/internal/tenancy"
+)
+
+// LocalTenant is the tenant of calls not held to one, such as those over
+// stdio and background jobs
+const LocalTenant = "local"
+
+// monthLayout is how months are written: "2026-09"
+const monthLayout = "2006-01"
+
+// MonthOf returns the month of t, in UTC
+func MonthOf(t time.Time) string {
+	return t.UTC().Format(monthLayout)
+}
+
+// ParseMonth returns the start of a month written like "2026-09"
+func ParseMonth(month string) (time.Time, error) {
+	start, err := time.Parse(monthLayout, month)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid month %q: use YYYY-MM", month)
+	}
+	return start, nil
+}
+
+// MonthUsage is what a tenant used in a month
+type MonthUsage struct {
+	Tenant              string   `json:"tenant"`
+	Month               string   `json:"month"`
+	APICalls            int64    `json:"api_calls"`
+	EmbeddingsGenerated int64    `json:"embeddings_generated"`
+	Sessions            []string `json:"sessions,omitempty"` // Distinct sessions named by the tenant's calls
+	Projects            []string `json:"projects,omitempty"` // The tenant's projects and the repositories its calls named
+}
+
+// Meter counts what each tenant uses per month. Counters are kept in memory
+// and saved to an optional JSON file by Flush.
+type Meter struct {
+	mu     sync.Mutex
+	path   string
+	months map[string]*MonthUsage // tenant|month -> usage
+	dirty  bool
+	now    func() time.Time
+}
+
+// NewMeter creates a meter saved at path; an empty path keeps it in memory
+func NewMeter(path string) *Meter {
+	return &Meter{
+		path:   path,
+		months: make(map[string]*MonthUsage),
+		now:    time.Now,
+	}
+}
+
+// monthKey identifies the usage of a tenant in a month
+func monthKey(tenant, month string) string {
+	return tenant + "|" + month
+}
+
+// Load reads saved usage from disk. A missing file is not an error.
+func (m *Meter) Load() error {
+	if m.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(m.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read usage: %w", err)
+	}
+
+	var months []MonthUsage
+	if err := json.Unmarshal(data, &months); err != nil {
+		return fmt.Errorf("failed to parse usage: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range months {
+		usage := months[i]
+		m.months[monthKey(usage.Tenant, usage.Month)] = &usage
+	}
+	return nil
+}
+
+// tenantOf returns the tenant a call is made for
+func tenantOf(ctx context.Context) *tenancy.Tenant {
+	if tenant := tenancy.FromContext(ctx); tenant != nil {
+		return tenant
+	}
+	return &tenancy.Tenant{ID: LocalTenant}
+}
+
+// usageLocked returns the usage of tenant this month, creating it; callers must hold the lock
+func (m *Meter) usageLocked(tenant *tenancy.Tenant) *MonthUsage {
+	month := MonthOf(m.now())
+	key := monthKey(tenant.ID, month)
+	usage, ok := m.months[key]
+	if !ok {
+		usage = &MonthUsage{Tenant: tenant.ID, Month: month}
+		m.months[key] = usage
+	}
+	for _, project := range tenant.Projects {
+		if project != tenancy.AllProjects {
+			usage.Projects = insertSorted(usage.Projects, project)
+		}
+	}
+	m.dirty = true
+	return usage
+}
+
+// RecordCall counts a tool call made in a session, naming a repository;
+// either may be empty
+func (m *Meter) RecordCall(ctx context.Context, sessionID, repository string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	usage := m.usageLocked(tenantOf(ctx))
+	usage.APICalls++
+	if sessionID != "" {
+		usage.Sessions = insertSorted(usage.Sessions, sessionID)
+	}
+	if repository != "" {
+		usage.Projects = insertSorted(usage.Projects, repository)
+	}
+}
+
+// RecordEmbeddings counts embeddings generated for a call
+func (m *Meter) RecordEmbeddings(ctx context.Context, count int) {
+	if count <= 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usageLocked(tenantOf(ctx)).EmbeddingsGenerated += int64(count)
+}
+
+// Usage returns what tenant used in month
+func (m *Meter) Usage(tenant, month string) MonthUsage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	usage, ok := m.months[monthKey(tenant, month)]
+	if !ok {
+		return MonthUsage{Tenant: tenant, Month: month}
+	}
+	copied := *usage
+	copied.Sessions = append([]string(nil), usage.Sessions...)
+	copied.Projects = append([]string(nil), usage.Projects...)
+	return copied
+}
+
+// Tenants returns the tenants that used the server in month, sorted
+func (m *Meter) Tenants(month string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tenants := make([]string, 0)
+	for _, usage := range m.months {
+		if usage.Month == month {
+			tenants = append(tenants, usage.Tenant)
+		}
+	}
+	sort.Strings(tenants)
+	return tenants
+}
+
+// Flush saves the usage counted since the last flush
+func (m *Meter) Flush() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.path == "" || !m.dirty {
+		return nil
+	}
+
+	months := make([]MonthUsage, 0, len(m.months))
+	for _, usage := range m.months {
+		months = append(months, *usage)
+	}
+	sort.Slice(months, func(i, j int) bool {
+		if months[i].Month != months[j].Month {
+			return months[i].Month < months[j].Month
+		}
+		return months[i].Tenant < months[j].Tenant
+	})
+
+	data, err := json.MarshalIndent(months, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode usage: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0o750); err != nil {
+		return fmt.Errorf("failed to create usage directory: %w", err)
+	}
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write usage: %w", err)
+	}
+	if err := os.Rename(tmp, m.path); err != nil {
+		return fmt.Errorf("failed to write usage: %w", err)
+	}
+	m.dirty = false
+	return nil
+}
+
+// Run flushes the meter every interval until the context is cancelled, then once more
+func (m *Meter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := m.Flush(); err != nil {
+				logging.Error("Failed to save usage", "error", err)
+			}
+			return
+		case <-ticker.C:
+			if err := m.Flush(); err != nil {
+				logging.Error("Failed to save usage", "error", err)
+			}
+		}
+	}
+}
+
+// insertSorted adds value to a sorted slice unless it is there already
+func insertSorted(values []string, value string) []string {
+	i := sort.SearchStrings(values, value)
+	if i < len(values) && values[i] == value {
+		return values
+	}
+	values = append(values, "")
+	copy(values[i+1:], values[i:])
+	values[i] = value
+	return values
+}
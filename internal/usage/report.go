@@ -0,0 +1,205 @@
+package usage
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/pkg/types"
+)
+
+// listPageSize is how many chunks are listed at a time when measuring storage
+const listPageSize = 1000
+
+// vectorComponentBytes is the stored size of one vector component, a float32
+const vectorComponentBytes = 4
+
+// Report formats
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// ProjectUsage is the storage a project holds
+type ProjectUsage struct {
+	Project      string `json:"project"`
+	Chunks       int    `json:"chunks"`
+	StorageBytes int64  `json:"storage_bytes"`
+}
+
+// Report is what a tenant used in a month. Calls, sessions and embeddings
+// are counted over the month; chunks and storage are measured when the
+// report is generated, over every project of the tenant, including those it
+// shares with other tenants.
+type Report struct {
+	Tenant              string         `json:"tenant"`
+	Month               string         `json:"month"`
+	Start               time.Time      `json:"start"`
+	End                 time.Time      `json:"end"`
+	GeneratedAt         time.Time      `json:"generated_at"`
+	Chunks              int            `json:"chunks"`
+	StorageBytes        int64          `json:"storage_bytes"`
+	EmbeddingsGenerated int64          `json:"embeddings_generated"`
+	APICalls            int64          `json:"api_calls"`
+	ActiveSessions      int            `json:"active_sessions"`
+	Projects            []ProjectUsage `json:"projects"`
+}
+
+// Reporter generates usage reports from a meter and the vector store
+type Reporter struct {
+	meter     *Meter
+	store     storage.VectorStore
+	dimension int
+}
+
+// NewReporter creates a reporter. Chunks listed without their vector are
+// counted with one of dimension components; 0 counts none.
+func NewReporter(meter *Meter, store storage.VectorStore, dimension int) *Reporter {
+	return &Reporter{meter: meter, store: store, dimension: dimension}
+}
+
+// Generate reports what tenant used in month
+func (r *Reporter) Generate(ctx context.Context, tenant, month string) (*Report, error) {
+	start, err := ParseMonth(month)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := r.meter.Usage(tenant, month)
+	report := &Report{
+		Tenant:              tenant,
+		Month:               month,
+		Start:               start,
+		End:                 start.AddDate(0, 1, 0),
+		GeneratedAt:         time.Now().UTC(),
+		EmbeddingsGenerated: usage.EmbeddingsGenerated,
+		APICalls:            usage.APICalls,
+		ActiveSessions:      len(usage.Sessions),
+		Projects:            make([]ProjectUsage, 0, len(usage.Projects)),
+	}
+	for _, project := range usage.Projects {
+		measured, err := r.measure(ctx, project)
+		if err != nil {
+			return nil, err
+		}
+		report.Projects = append(report.Projects, *measured)
+		report.Chunks += measured.Chunks
+		report.StorageBytes += measured.StorageBytes
+	}
+	return report, nil
+}
+
+// GenerateAll reports what every tenant used in month
+func (r *Reporter) GenerateAll(ctx context.Context, month string) ([]Report, error) {
+	if _, err := ParseMonth(month); err != nil {
+		return nil, err
+	}
+	tenants := r.meter.Tenants(month)
+	reports := make([]Report, 0, len(tenants))
+	for _, tenant := range tenants {
+		report, err := r.Generate(ctx, tenant, month)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, *report)
+	}
+	return reports, nil
+}
+
+// RenderUsage renders the report of tenant, or of every tenant when it is
+// empty, for month
+func (r *Reporter) RenderUsage(ctx context.Context, tenant, month, format string) (string, error) {
+	var reports []Report
+	if tenant == "" {
+		all, err := r.GenerateAll(ctx, month)
+		if err != nil {
+			return "", err
+		}
+		reports = all
+	} else {
+		report, err := r.Generate(ctx, tenant, month)
+		if err != nil {
+			return "", err
+		}
+		reports = []Report{*report}
+	}
+	return Render(reports, format)
+}
+
+// measure counts the chunks of project and the bytes they take. Chunks in
+// the trash still take storage, so they are counted.
+func (r *Reporter) measure(ctx context.Context, project string) (*ProjectUsage, error) {
+	ctx = storage.WithDeleted(ctx)
+	measured := &ProjectUsage{Project: project}
+	for offset := 0; ; offset += listPageSize {
+		chunks, err := r.store.ListByRepository(ctx, project, listPageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to measure storage of %s: %w", project, err)
+		}
+		for i := range chunks {
+			measured.Chunks++
+			measured.StorageBytes += r.chunkBytes(&chunks[i])
+		}
+		if len(chunks) < listPageSize {
+			return measured, nil
+		}
+	}
+}
+
+// chunkBytes estimates the bytes a chunk takes: its JSON without the vector, and the vector
+func (r *Reporter) chunkBytes(chunk *types.ConversationChunk) int64 {
+	components := len(chunk.Embeddings)
+	if components == 0 {
+		components = r.dimension
+	}
+	withoutVector := *chunk
+	withoutVector.Embeddings = nil
+	encoded, _ := json.Marshal(&withoutVector)
+	return int64(len(encoded) + components*vectorComponentBytes)
+}
+
+// Render writes reports as JSON, or as CSV with one row per tenant
+func Render(reports []Report, format string) (string, error) {
+	switch format {
+	case FormatJSON, "":
+		encoded, err := json.MarshalIndent(reports, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to encode usage reports: %w", err)
+		}
+		return string(encoded), nil
+	case FormatCSV:
+		var buf bytes.Buffer
+		writer := csv.NewWriter(&buf)
+		_ = writer.Write([]string{"month", "tenant", "projects", "chunks", "storage_bytes", "embeddings_generated", "api_calls", "active_sessions"})
+		for i := range reports {
+			report := &reports[i]
+			projects := make([]string, len(report.Projects))
+			for j := range report.Projects {
+				projects[j] = report.Projects[j].Project
+			}
+			_ = writer.Write([]string{
+				report.Month,
+				report.Tenant,
+				strings.Join(projects, ";"),
+				strconv.Itoa(report.Chunks),
+				strconv.FormatInt(report.StorageBytes, 10),
+				strconv.FormatInt(report.EmbeddingsGenerated, 10),
+				strconv.FormatInt(report.APICalls, 10),
+				strconv.Itoa(report.ActiveSessions),
+			})
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return "", fmt.Errorf("failed to write usage reports: %w", err)
+		}
+		return buf.String(), nil
+	default:
+		return "", fmt.Errorf("unsupported usage report format: %q (valid: json, csv)", format)
+	}
+}
@@ -0,0 +1,123 @@
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/storage"
+	"lerian-mcp-memory/internal/tenancy"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fixedClock(t time.Time) func() time.Time {
+	return func() time.Time { return t }
+}
+
+func TestMeterRecordAndFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	meter := NewMeter(path)
+	meter.now = fixedClock(time.Date(2026, 9, 14, 10, 0, 0, 0, time.UTC))
+
+	acme := tenancy.WithTenant(context.Background(), &tenancy.Tenant{ID: "acme", Projects: []string{"github.com/acme/api"}})
+	meter.RecordCall(acme, "session-1", "github.com/acme/web")
+	meter.RecordCall(acme, "session-1", "")
+	meter.RecordCall(acme, "session-2", "github.com/acme/api")
+	meter.RecordEmbeddings(acme, 3)
+	meter.RecordCall(context.Background(), "", "")
+
+	usage := meter.Usage("acme", "2026-09")
+	assert.EqualValues(t, 3, usage.APICalls)
+	assert.EqualValues(t, 3, usage.EmbeddingsGenerated)
+	assert.Equal(t, []string{"session-1", "session-2"}, usage.Sessions)
+	assert.Equal(t, []string{"github.com/acme/api", "github.com/acme/web"}, usage.Projects)
+	assert.Equal(t, []string{"acme", LocalTenant}, meter.Tenants("2026-09"))
+	assert.Empty(t, meter.Tenants("2026-08"))
+
+	require.NoError(t, meter.Flush())
+	reloaded := NewMeter(path)
+	require.NoError(t, reloaded.Load())
+	assert.Equal(t, usage, reloaded.Usage("acme", "2026-09"))
+}
+
+func TestMeterLoadMissingFile(t *testing.T) {
+	meter := NewMeter(filepath.Join(t.TempDir(), "missing.json"))
+	assert.NoError(t, meter.Load())
+	assert.Empty(t, meter.Tenants(MonthOf(time.Now())))
+}
+
+func TestReporterGenerate(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewKeywordStore("")
+	for _, content := range []string{"First decision", "Second decision"} {
+		chunk, err := types.NewConversationChunk("session-1", content, types.ChunkTypeArchitectureDecision, &types.ChunkMetadata{
+			Repository: "github.com/acme/api",
+			Outcome:    types.OutcomeSuccess,
+			Difficulty: types.DifficultySimple,
+		})
+		require.NoError(t, err)
+		require.NoError(t, store.Store(ctx, chunk))
+	}
+
+	meter := NewMeter("")
+	meter.now = fixedClock(time.Date(2026, 9, 14, 10, 0, 0, 0, time.UTC))
+	acme := tenancy.WithTenant(ctx, &tenancy.Tenant{ID: "acme", Projects: []string{"github.com/acme/api"}})
+	meter.RecordCall(acme, "session-1", "")
+	meter.RecordEmbeddings(acme, 2)
+
+	reporter := NewReporter(meter, store, 8)
+	report, err := reporter.Generate(ctx, "acme", "2026-09")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), report.Start)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), report.End)
+	assert.Equal(t, 2, report.Chunks)
+	assert.EqualValues(t, 1, report.APICalls)
+	assert.EqualValues(t, 2, report.EmbeddingsGenerated)
+	assert.Equal(t, 1, report.ActiveSessions)
+	require.Len(t, report.Projects, 1)
+	assert.Equal(t, report.StorageBytes, report.Projects[0].StorageBytes)
+	assert.Greater(t, report.StorageBytes, int64(2*8*vectorComponentBytes), "storage must count chunk content and vectors")
+
+	_, err = reporter.Generate(ctx, "acme", "September")
+	assert.Error(t, err)
+
+	all, err := reporter.GenerateAll(ctx, "2026-09")
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.Equal(t, "acme", all[0].Tenant)
+}
+
+func TestRender(t *testing.T) {
+	reports := []Report{{
+		Tenant:              "acme",
+		Month:               "2026-09",
+		Chunks:              4,
+		StorageBytes:        2048,
+		EmbeddingsGenerated: 7,
+		APICalls:            12,
+		ActiveSessions:      2,
+		Projects:            []ProjectUsage{{Project: "github.com/acme/api"}, {Project: "github.com/acme/web"}},
+	}}
+
+	csv, err := Render(reports, FormatCSV)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(csv), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, "month,tenant,projects,chunks,storage_bytes,embeddings_generated,api_calls,active_sessions", lines[0])
+	assert.Equal(t, "2026-09,acme,github.com/acme/api;github.com/acme/web,4,2048,7,12,2", lines[1])
+
+	encoded, err := Render(reports, FormatJSON)
+	require.NoError(t, err)
+	var decoded []Report
+	require.NoError(t, json.Unmarshal([]byte(encoded), &decoded))
+	assert.Equal(t, "acme", decoded[0].Tenant)
+
+	_, err = Render(reports, "xml")
+	assert.Error(t, err)
+}
@@ -1,16 +1,53 @@
 // Package websocket provides WebSocket hub and client management
-// for real-time communication in the MCP Memory Server.
+// for real-time communication in the MCP Memory Server. Besides streaming
+// memory events, a connection carries MCP JSON-RPC in both directions:
+// requests are routed to the hub's RPC handler and each response is
+// written back on the connection the request arrived on.
 package websocket
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"log"
 	"sync"
 	"time"
 
+	"lerian-mcp-memory/internal/auth"
+	"lerian-mcp-memory/internal/security"
+	"lerian-mcp-memory/internal/tenancy"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
 	"github.com/gorilla/websocket"
 )
 
+const (
+	// writeWait is the time allowed to write a message to the peer
+	writeWait = 10 * time.Second
+
+	// pongWait is the time allowed to read the next pong from the peer
+	pongWait = 60 * time.Second
+
+	// pingPeriod sends pings often enough to arrive within pongWait
+	pingPeriod = (pongWait * 9) / 10
+
+	// maxMessageSize bounds an incoming message, large enough for tool calls
+	maxMessageSize = 1 << 20
+)
+
+// RPCHandler serves a JSON-RPC request received on a client connection.
+// The context is cancelled when the connection closes.
+type RPCHandler func(ctx context.Context, client *Client, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse
+
+// BatchHandler serves a JSON-RPC batch received on a client connection and
+// returns the message to send back, or nil when nothing is owed
+type BatchHandler func(ctx context.Context, client *Client, data []byte) interface{}
+
+// ResponseHandler takes a JSON-RPC message from a client that may answer a
+// request the server sent it, reporting whether it did
+type ResponseHandler func(client *Client, data []byte) bool
+
 // MemoryEvent represents a memory change event
 type MemoryEvent struct {
 	Type       string      `json:"type"`
@@ -33,6 +70,25 @@ type Client struct {
 	Hub        *Hub
 	Repository string // Filter events by repository
 	SessionID  string // Filter events by session
+
+	// APIKey is the key the connection was opened with, if any; requests it
+	// sends are held to the tools the key allows
+	APIKey *auth.Key
+
+	// TokenClaims are those of the bearer token the connection was opened
+	// with, if any; requests it sends are held to the token's tenant
+	TokenClaims *security.TokenClaims
+
+	// Tenant owns the projects the connection may see when tenancy is
+	// enforced: events of other projects are withheld from it and
+	// subscriptions to them refused
+	Tenant *tenancy.Tenant
+
+	// responses queues JSON-RPC responses and notifications for the write pump
+	responses chan interface{}
+
+	// pumped is closed when the write pump stops
+	pumped chan struct{}
 }
 
 // Hub manages WebSocket connections and broadcasts
@@ -42,6 +98,16 @@ type Hub struct {
 	unregister chan *Client
 	broadcast  chan MemoryEvent
 	mutex      sync.RWMutex
+	rpcHandler RPCHandler
+
+	// batchHandler serves JSON-RPC batches
+	batchHandler BatchHandler
+
+	// responseHandler takes client responses to server-to-client requests
+	responseHandler ResponseHandler
+
+	// disconnectHandler is told about clients that left
+	disconnectHandler func(*Client)
 }
 
 // NewHub creates a new WebSocket hub
@@ -136,6 +202,9 @@ func (h *Hub) removeClientUnsafe(client *Client) {
 			log.Printf("Error closing client connection: %v", err)
 		}
 		log.Printf("WebSocket client %s disconnected (total: %d)", client.ID, len(h.clients))
+		if h.disconnectHandler != nil {
+			h.disconnectHandler(client)
+		}
 	}
 }
 
@@ -146,6 +215,12 @@ func (h *Hub) shouldSendToClient(client *Client, event *MemoryEvent) bool {
 		return true
 	}
 
+	// Events of projects the client's tenant does not own never leave the
+	// hub, whatever the client subscribed to
+	if !client.MaySee(event.Repository) {
+		return false
+	}
+
 	// Filter by repository if client has preference
 	if client.Repository != "" && event.Repository != "" && client.Repository != event.Repository {
 		return false
@@ -159,6 +234,66 @@ func (h *Hub) shouldSendToClient(client *Client, event *MemoryEvent) bool {
 	return true
 }
 
+// MaySee reports whether the client may receive events of repository: any
+// repository for clients not held to a tenant, else the tenant's projects
+func (c *Client) MaySee(repository string) bool {
+	return c.Tenant == nil || c.Tenant.Allows(repository)
+}
+
+// Expired reports whether the bearer token the connection was opened with
+// has expired by now. Expired connections are closed, since the token is
+// not checked again after the upgrade.
+func (c *Client) Expired(now time.Time) bool {
+	return c.TokenClaims != nil && !c.TokenClaims.ExpiresAt.IsZero() && !now.Before(c.TokenClaims.ExpiresAt)
+}
+
+// closeExpired tells the client its token expired and closes the connection
+func (c *Client) closeExpired() {
+	log.Printf("Closing WebSocket client %s: its bearer token expired at %s", c.ID, c.TokenClaims.ExpiresAt.Format(time.RFC3339))
+	message := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "bearer token has expired")
+	if err := c.Connection.WriteControl(websocket.CloseMessage, message, time.Now().Add(writeWait)); err != nil {
+		log.Printf("Error writing close message: %v", err)
+	}
+}
+
+// Principal names the credentials the connection was opened with: an API
+// key or a bearer token's client, or "" for an anonymous connection
+func (c *Client) Principal() string {
+	switch {
+	case c.APIKey != nil:
+		return tenancy.FromKey(c.APIKey).ID
+	case c.TokenClaims != nil:
+		return tenancy.FromClaims(c.TokenClaims).ID
+	}
+	return ""
+}
+
+// Shutdown closes every client connection cleanly: each client is sent the
+// events and JSON-RPC messages queued for it, then a close frame. It returns
+// once the clients' write pumps stopped or ctx is done.
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.mutex.Lock()
+	clients := make([]*Client, 0, len(h.clients))
+	for client := range h.clients {
+		delete(h.clients, client)
+		close(client.Send)
+		if h.disconnectHandler != nil {
+			h.disconnectHandler(client)
+		}
+		clients = append(clients, client)
+	}
+	h.mutex.Unlock()
+
+	for _, client := range clients {
+		select {
+		case <-client.pumped:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
 // RegisterClient registers a new client with the hub
 func (h *Hub) RegisterClient(client *Client) {
 	h.register <- client
@@ -178,6 +313,69 @@ func (h *Hub) BroadcastMemoryEvent(event *MemoryEvent) {
 	}
 }
 
+// BroadcastNotification queues a JSON-RPC notification, such as
+// notifications/tools/list_changed, for every connected client
+func (h *Hub) BroadcastNotification(method string, params interface{}) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	for client := range h.clients {
+		if err := client.SendNotification(method, params); err != nil {
+			log.Printf("Warning: Dropping %s notification: %v", method, err)
+		}
+	}
+}
+
+// SetRPCHandler enables JSON-RPC over client connections
+func (h *Hub) SetRPCHandler(handler RPCHandler) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.rpcHandler = handler
+}
+
+// SetBatchHandler enables JSON-RPC batches over client connections
+func (h *Hub) SetBatchHandler(handler BatchHandler) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.batchHandler = handler
+}
+
+// getBatchHandler returns the batch handler, or nil when none is set
+func (h *Hub) getBatchHandler() BatchHandler {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return h.batchHandler
+}
+
+// SetResponseHandler lets clients answer requests the server sends them
+func (h *Hub) SetResponseHandler(handler ResponseHandler) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.responseHandler = handler
+}
+
+// getResponseHandler returns the response handler, or nil when none is set
+func (h *Hub) getResponseHandler() ResponseHandler {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return h.responseHandler
+}
+
+// SetDisconnectHandler calls handler with each client that disconnects.
+// It runs while the hub is locked and must not call back into the hub.
+func (h *Hub) SetDisconnectHandler(handler func(*Client)) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.disconnectHandler = handler
+}
+
+// getRPCHandler returns the JSON-RPC handler, or nil when none is set
+func (h *Hub) getRPCHandler() RPCHandler {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return h.rpcHandler
+}
+
 // GetClientCount returns the number of connected clients
 func (h *Hub) GetClientCount() int {
 	h.mutex.RLock()
@@ -194,27 +392,34 @@ func NewClient(id string, conn *websocket.Conn, hub *Hub, repository, sessionID
 		Hub:        hub,
 		Repository: repository,
 		SessionID:  sessionID,
+		responses:  make(chan interface{}, 64),
+		pumped:     make(chan struct{}),
 	}
 }
 
 // WritePump pumps messages from the hub to the websocket connection
 func (c *Client) WritePump(ctx context.Context) {
-	ticker := time.NewTicker(54 * time.Second)
+	ticker := time.NewTicker(pingPeriod)
 	defer func() {
 		ticker.Stop()
 		if err := c.Connection.Close(); err != nil {
 			log.Printf("Error closing connection in WritePump: %v", err)
 		}
+		close(c.pumped)
 	}()
 
 	for {
 		select {
 		case event, ok := <-c.Send:
-			if err := c.Connection.SetWriteDeadline(time.Now().Add(10 * time.Second)); err != nil {
+			if err := c.Connection.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
 				log.Printf("Error setting write deadline: %v", err)
 			}
 			if !ok {
-				// The hub closed the channel
+				// The hub closed the channel; send the JSON-RPC messages
+				// still queued before closing
+				if !c.flushResponses() {
+					return
+				}
 				if err := c.Connection.WriteMessage(websocket.CloseMessage, []byte{}); err != nil {
 					log.Printf("Error writing close message: %v", err)
 				}
@@ -226,10 +431,31 @@ func (c *Client) WritePump(ctx context.Context) {
 				return
 			}
 
+		case message := <-c.responses:
+			if err := c.Connection.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+				log.Printf("Error setting write deadline: %v", err)
+			}
+			if err := c.Connection.WriteJSON(message); err != nil {
+				log.Printf("Error writing JSON-RPC message: %v", err)
+				return
+			}
+
 		case <-ticker.C:
-			if err := c.Connection.SetWriteDeadline(time.Now().Add(10 * time.Second)); err != nil {
+			// Idle connections are closed once their token expires, too
+			if c.Expired(time.Now()) {
+				c.closeExpired()
+				return
+			}
+			if err := c.Connection.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
 				log.Printf("Error setting write deadline for heartbeat: %v", err)
 			}
+			// Ping frames keep the connection alive; the peer's pong extends
+			// the read deadline. Browsers cannot see them, so the JSON
+			// heartbeat is still sent.
+			if err := c.Connection.WriteMessage(websocket.PingMessage, nil); err != nil {
+				log.Printf("Error writing ping: %v", err)
+				return
+			}
 			heartbeat := MemoryEvent{
 				Type:      "heartbeat",
 				Timestamp: time.Now(),
@@ -254,13 +480,17 @@ func (c *Client) ReadPump(ctx context.Context) {
 		}
 	}()
 
+	// JSON-RPC requests in flight are cancelled when the connection closes
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// Set read limits and timeouts
-	c.Connection.SetReadLimit(512)
-	if err := c.Connection.SetReadDeadline(time.Now().Add(60 * time.Second)); err != nil {
+	c.Connection.SetReadLimit(maxMessageSize)
+	if err := c.Connection.SetReadDeadline(time.Now().Add(pongWait)); err != nil {
 		log.Printf("Error setting read deadline: %v", err)
 	}
 	c.Connection.SetPongHandler(func(string) error {
-		if err := c.Connection.SetReadDeadline(time.Now().Add(60 * time.Second)); err != nil {
+		if err := c.Connection.SetReadDeadline(time.Now().Add(pongWait)); err != nil {
 			log.Printf("Error setting read deadline in pong handler: %v", err)
 		}
 		return nil
@@ -272,8 +502,7 @@ func (c *Client) ReadPump(ctx context.Context) {
 			return
 		default:
 			// Read message from client
-			var msg map[string]interface{}
-			err := c.Connection.ReadJSON(&msg)
+			_, data, err := c.Connection.ReadMessage()
 			if err != nil {
 				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 					log.Printf("WebSocket error: %v", err)
@@ -281,12 +510,145 @@ func (c *Client) ReadPump(ctx context.Context) {
 				return
 			}
 
+			// Each message is served only while the connection's token is valid
+			if c.Expired(time.Now()) {
+				c.closeExpired()
+				return
+			}
+
+			// Batches are JSON arrays of requests, served as a whole
+			if isBatch(data) {
+				go c.handleRPCBatch(ctx, data)
+				continue
+			}
+
+			var msg map[string]interface{}
+			if err := json.Unmarshal(data, &msg); err != nil {
+				c.sendResponse(&protocol.JSONRPCResponse{
+					JSONRPC: "2.0",
+					Error:   protocol.NewJSONRPCError(protocol.ParseError, "Parse error", err.Error()),
+				})
+				continue
+			}
+
+			// JSON-RPC requests are served concurrently so a slow tool call
+			// does not hold up pongs or other requests
+			if _, ok := msg["jsonrpc"]; ok {
+				go c.handleRPCMessage(ctx, data)
+				continue
+			}
+
 			// Handle client messages (subscription preferences, etc.)
 			c.handleClientMessage(msg)
 		}
 	}
 }
 
+// handleRPCMessage serves a JSON-RPC request and queues its response for
+// this connection. Notifications, which carry no ID, get no response, and
+// responses to requests the server sent go to the response handler.
+func (c *Client) handleRPCMessage(ctx context.Context, data []byte) {
+	if handler := c.Hub.getResponseHandler(); handler != nil && handler(c, data) {
+		return
+	}
+
+	var req protocol.JSONRPCRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		c.sendResponse(&protocol.JSONRPCResponse{
+			JSONRPC: "2.0",
+			Error:   protocol.NewJSONRPCError(protocol.InvalidRequest, "Invalid request", err.Error()),
+		})
+		return
+	}
+
+	handler := c.Hub.getRPCHandler()
+	if handler == nil {
+		if req.ID != nil {
+			c.sendResponse(&protocol.JSONRPCResponse{
+				JSONRPC: "2.0",
+				ID:      req.ID,
+				Error:   protocol.NewJSONRPCError(protocol.MethodNotFound, "JSON-RPC is not enabled on this connection", nil),
+			})
+		}
+		return
+	}
+
+	resp := handler(ctx, c, &req)
+	if req.ID == nil || resp == nil {
+		return
+	}
+	c.sendResponse(resp)
+}
+
+// handleRPCBatch serves a JSON-RPC batch and queues its response for this connection
+func (c *Client) handleRPCBatch(ctx context.Context, data []byte) {
+	handler := c.Hub.getBatchHandler()
+	if handler == nil {
+		c.sendResponse(&protocol.JSONRPCResponse{
+			JSONRPC: "2.0",
+			Error:   protocol.NewJSONRPCError(protocol.InvalidRequest, "JSON-RPC batches are not enabled on this connection", nil),
+		})
+		return
+	}
+
+	if resp := handler(ctx, c, data); resp != nil {
+		c.sendResponse(resp)
+	}
+}
+
+// isBatch reports whether a message is a JSON array
+func isBatch(data []byte) bool {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// sendResponse queues a JSON-RPC response or batch response for the write pump
+func (c *Client) sendResponse(resp interface{}) {
+	select {
+	case c.responses <- resp:
+	default:
+		log.Printf("Warning: response queue full for client %s, dropping response", c.ID)
+	}
+}
+
+// SendRequest queues a server-to-client JSON-RPC request, such as
+// sampling/createMessage, for the write pump
+func (c *Client) SendRequest(req *protocol.JSONRPCRequest) error {
+	select {
+	case c.responses <- req:
+		return nil
+	default:
+		return fmt.Errorf("response queue full for client %s", c.ID)
+	}
+}
+
+// SendNotification queues a JSON-RPC notification, such as
+// notifications/progress for a request in flight, for the write pump
+func (c *Client) SendNotification(method string, params interface{}) error {
+	select {
+	case c.responses <- &protocol.JSONRPCRequest{JSONRPC: "2.0", Method: method, Params: params}:
+		return nil
+	default:
+		return fmt.Errorf("response queue full for client %s", c.ID)
+	}
+}
+
+// flushResponses writes the JSON-RPC messages queued for the client,
+// reporting false if writing failed
+func (c *Client) flushResponses() bool {
+	for {
+		select {
+		case message := <-c.responses:
+			if err := c.Connection.WriteJSON(message); err != nil {
+				log.Printf("Error writing JSON-RPC message: %v", err)
+				return false
+			}
+		default:
+			return true
+		}
+	}
+}
+
 // handleClientMessage processes messages from the client
 func (c *Client) handleClientMessage(msg map[string]interface{}) {
 	msgType, ok := msg["type"].(string)
@@ -298,6 +660,11 @@ func (c *Client) handleClientMessage(msg map[string]interface{}) {
 	case "subscribe":
 		// Handle subscription requests
 		if repo, ok := msg["repository"].(string); ok {
+			if repo != "" && !c.MaySee(repo) {
+				log.Printf("Denied subscription of client %s (%s) to repository %q", c.ID, c.Principal(), repo)
+				c.refuse("subscribe", repo, "the repository must be one of the tenant's projects")
+				return
+			}
 			c.Repository = repo
 			log.Printf("Client %s subscribed to repository: %s", c.ID, repo)
 		}
@@ -331,6 +698,22 @@ func (c *Client) handleClientMessage(msg map[string]interface{}) {
 	}
 }
 
+// refuse tells the client a message it sent was refused
+func (c *Client) refuse(action, repository, message string) {
+	event := MemoryEvent{
+		Type:       "error",
+		Action:     action,
+		Repository: repository,
+		Timestamp:  time.Now(),
+		Data:       map[string]interface{}{"error": "cross_tenant", "message": message},
+	}
+	select {
+	case c.Send <- event:
+	default:
+		// Channel full, client will be removed
+	}
+}
+
 // NewMemoryEvent creates a new memory event with the specified parameters
 func NewMemoryEvent(eventType, action, chunkID, repository, sessionID string, data interface{}) MemoryEvent {
 	return MemoryEvent{
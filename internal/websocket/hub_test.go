@@ -0,0 +1,86 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/internal/auth"
+	"lerian-mcp-memory/internal/security"
+	"lerian-mcp-memory/internal/tenancy"
+)
+
+func TestHubWithholdsEventsOfOtherTenants(t *testing.T) {
+	hub := NewHub()
+	key := &auth.Key{ID: "k1", Projects: []string{"acme/api"}}
+	client := NewClient("c1", nil, hub, "", "")
+	client.APIKey = key
+	client.Tenant = tenancy.FromKey(key)
+	anonymous := NewClient("c2", nil, hub, "", "")
+
+	tests := []struct {
+		name   string
+		event  MemoryEvent
+		tenant bool
+		anyone bool
+	}{
+		{"owned project", NewMemoryEvent("task", "updated", "t1", "acme/api", "", nil), true, true},
+		{"other project", NewMemoryEvent("task", "updated", "t2", "rival/app", "", nil), false, true},
+		{"no project", NewMemoryEvent("task", "updated", "t3", "", "", nil), false, true},
+		{"system event", NewMemoryEvent("system", "notice", "", "", "", nil), true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hub.shouldSendToClient(client, &tt.event); got != tt.tenant {
+				t.Errorf("tenant client: got %v, want %v", got, tt.tenant)
+			}
+			if got := hub.shouldSendToClient(anonymous, &tt.event); got != tt.anyone {
+				t.Errorf("anonymous client: got %v, want %v", got, tt.anyone)
+			}
+		})
+	}
+
+	if got := client.Principal(); got != "api_key:k1" {
+		t.Errorf("Principal() = %q, want api_key:k1", got)
+	}
+	if got := anonymous.Principal(); got != "" {
+		t.Errorf("Principal() of an anonymous client = %q, want none", got)
+	}
+}
+
+func TestSubscribeRefusesOtherTenantsProjects(t *testing.T) {
+	client := NewClient("c1", nil, NewHub(), "acme/api", "")
+	client.Tenant = &tenancy.Tenant{ID: "api_key:k1", Projects: []string{"acme/api", "acme/web"}}
+
+	client.handleClientMessage(map[string]interface{}{"type": "subscribe", "repository": "rival/app"})
+	if client.Repository != "acme/api" {
+		t.Errorf("subscription changed to %q", client.Repository)
+	}
+	select {
+	case event := <-client.Send:
+		if event.Type != "error" || event.Action != "subscribe" || event.Repository != "rival/app" {
+			t.Errorf("unexpected refusal %+v", event)
+		}
+	default:
+		t.Fatal("the refused subscription was not reported")
+	}
+
+	client.handleClientMessage(map[string]interface{}{"type": "subscribe", "repository": "acme/web"})
+	if client.Repository != "acme/web" {
+		t.Errorf("subscription to an owned project = %q, want acme/web", client.Repository)
+	}
+}
+
+func TestClientExpiresWithItsToken(t *testing.T) {
+	now := time.Now()
+	client := NewClient("c1", nil, NewHub(), "", "")
+	if client.Expired(now) {
+		t.Error("connections without a token do not expire")
+	}
+	client.TokenClaims = &security.TokenClaims{Subject: "jane", ExpiresAt: now.Add(time.Minute)}
+	if client.Expired(now) {
+		t.Error("the token is still valid")
+	}
+	if !client.Expired(now.Add(time.Minute)) {
+		t.Error("the connection outlived its token")
+	}
+}
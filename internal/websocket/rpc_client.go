@@ -0,0 +1,351 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"lerian-mcp-memory/internal/retry"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/gorilla/websocket"
+)
+
+var (
+	// ErrClientClosed is returned by calls on a closed RPCClient
+	ErrClientClosed = errors.New("websocket client closed")
+
+	// ErrDisconnected is returned for calls in flight when the connection drops.
+	// The server may or may not have processed them.
+	ErrDisconnected = errors.New("websocket connection lost")
+)
+
+// RPCClientConfig configures an RPCClient
+type RPCClientConfig struct {
+	// URL of the /ws endpoint. Query parameters such as repository and
+	// session_id are sent again on every reconnect, so the server routes
+	// the new connection to the same session.
+	URL    string
+	Header http.Header
+
+	InitialBackoff time.Duration // First reconnect delay (default 500ms)
+	MaxBackoff     time.Duration // Upper bound for reconnect delays (default 30s)
+	PingInterval   time.Duration // Keepalive ping interval (default 54s)
+}
+
+// RPCClient is a long-lived MCP client over WebSocket. It sends JSON-RPC
+// requests, delivers memory events, keeps the connection alive with pings
+// and reconnects with exponential backoff when the connection drops.
+type RPCClient struct {
+	config  RPCClientConfig
+	dialer  *websocket.Dialer
+	retrier *retry.Retrier
+	nextID  atomic.Int64
+	events  chan MemoryEvent
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	conn      *websocket.Conn
+	connected chan struct{} // Closed while conn is set
+	pending   map[int64]chan *protocol.JSONRPCResponse
+	writeMu   sync.Mutex
+}
+
+// DialRPC connects to the server and keeps the connection open until Close.
+// Only the first connection attempt is reported as an error; later
+// disconnects are retried in the background.
+func DialRPC(ctx context.Context, config RPCClientConfig) (*RPCClient, error) {
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = 500 * time.Millisecond
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 30 * time.Second
+	}
+	if config.PingInterval <= 0 {
+		config.PingInterval = pingPeriod
+	}
+
+	c := &RPCClient{
+		config: config,
+		dialer: websocket.DefaultDialer,
+		retrier: retry.New(&retry.Config{
+			InitialDelay:    config.InitialBackoff,
+			MaxDelay:        config.MaxBackoff,
+			Multiplier:      2.0,
+			RandomizeFactor: 0.2,
+			RetryIf:         func(error) bool { return true },
+		}),
+		events:    make(chan MemoryEvent, 256),
+		connected: make(chan struct{}),
+		pending:   make(map[int64]chan *protocol.JSONRPCResponse),
+	}
+
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	c.setConnection(conn)
+	go c.run(conn)
+	return c, nil
+}
+
+// Events returns memory events pushed by the server. The channel is closed
+// after Close; events are dropped when it is not drained.
+func (c *RPCClient) Events() <-chan MemoryEvent {
+	return c.events
+}
+
+// Call sends a JSON-RPC request and waits for its response. While the
+// client is reconnecting, the call waits for the connection until ctx is done.
+func (c *RPCClient) Call(ctx context.Context, method string, params interface{}) (*protocol.JSONRPCResponse, error) {
+	conn, err := c.waitForConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id := c.nextID.Add(1)
+	ch := make(chan *protocol.JSONRPCResponse, 1)
+	c.mu.Lock()
+	c.pending[id] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	req := &protocol.JSONRPCRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params}
+	if err := c.write(conn, req); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	select {
+	case resp := <-ch:
+		if resp == nil {
+			return nil, ErrDisconnected
+		}
+		return resp, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.ctx.Done():
+		return nil, ErrClientClosed
+	}
+}
+
+// Notify sends a JSON-RPC notification, which gets no response
+func (c *RPCClient) Notify(ctx context.Context, method string, params interface{}) error {
+	conn, err := c.waitForConnection(ctx)
+	if err != nil {
+		return err
+	}
+	return c.write(conn, &protocol.JSONRPCRequest{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// Close stops reconnecting and closes the connection
+func (c *RPCClient) Close() error {
+	c.cancel()
+
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+	if conn == nil {
+		return nil
+	}
+
+	c.writeMu.Lock()
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(writeWait))
+	c.writeMu.Unlock()
+	return conn.Close()
+}
+
+// run serves connections until the client is closed, reconnecting with
+// backoff after each disconnect
+func (c *RPCClient) run(conn *websocket.Conn) {
+	defer close(c.events)
+
+	for {
+		err := c.serve(conn)
+		c.dropConnection()
+		if c.ctx.Err() != nil {
+			return
+		}
+		log.Printf("WebSocket connection to %s lost, reconnecting: %v", c.config.URL, err)
+
+		result := c.retrier.Do(c.ctx, func(ctx context.Context) error {
+			var dialErr error
+			conn, dialErr = c.dial(ctx)
+			return dialErr
+		})
+		if result.Err != nil {
+			return
+		}
+		if c.ctx.Err() != nil {
+			_ = conn.Close()
+			return
+		}
+		log.Printf("WebSocket reconnected to %s after %d attempts", c.config.URL, result.Attempts)
+		c.setConnection(conn)
+	}
+}
+
+// serve reads from conn until it fails, pinging it in the background
+func (c *RPCClient) serve(conn *websocket.Conn) error {
+	stop := make(chan struct{})
+	defer close(stop)
+	go c.keepalive(conn, stop)
+
+	// Without a pong shortly after each ping the connection is considered dead
+	readTimeout := c.config.PingInterval + writeWait
+	conn.SetReadLimit(maxMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(readTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(readTimeout))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			_ = conn.Close()
+			return err
+		}
+		c.dispatch(data)
+	}
+}
+
+// keepalive pings conn until stop is closed
+func (c *RPCClient) keepalive(conn *websocket.Conn, stop <-chan struct{}) {
+	ticker := time.NewTicker(c.config.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.writeMu.Lock()
+			err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait))
+			c.writeMu.Unlock()
+			if err != nil {
+				_ = conn.Close()
+				return
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// dispatch routes a JSON-RPC response to its caller, or delivers a memory event
+func (c *RPCClient) dispatch(data []byte) {
+	var probe struct {
+		JSONRPC string `json:"jsonrpc"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		log.Printf("Ignoring malformed WebSocket message: %v", err)
+		return
+	}
+
+	if probe.JSONRPC == "" {
+		var event MemoryEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			return
+		}
+		select {
+		case c.events <- event:
+		default:
+		}
+		return
+	}
+
+	var resp protocol.JSONRPCResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		log.Printf("Ignoring malformed JSON-RPC response: %v", err)
+		return
+	}
+	id, ok := resp.ID.(float64)
+	if !ok {
+		return
+	}
+
+	c.mu.Lock()
+	ch, ok := c.pending[int64(id)]
+	delete(c.pending, int64(id))
+	c.mu.Unlock()
+	if ok {
+		ch <- &resp
+	}
+}
+
+// dial opens a connection to the configured URL
+func (c *RPCClient) dial(ctx context.Context) (*websocket.Conn, error) {
+	conn, resp, err := c.dialer.DialContext(ctx, c.config.URL, c.config.Header)
+	if resp != nil && resp.Body != nil {
+		_ = resp.Body.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", c.config.URL, err)
+	}
+	return conn, nil
+}
+
+// write sends a message on conn, serialized with keepalive pings
+func (c *RPCClient) write(conn *websocket.Conn, v interface{}) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := conn.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+		return err
+	}
+	return conn.WriteJSON(v)
+}
+
+// waitForConnection returns the current connection, waiting while reconnecting
+func (c *RPCClient) waitForConnection(ctx context.Context) (*websocket.Conn, error) {
+	for {
+		if c.ctx.Err() != nil {
+			return nil, ErrClientClosed
+		}
+
+		c.mu.Lock()
+		conn, connected := c.conn, c.connected
+		c.mu.Unlock()
+		if conn != nil {
+			return conn, nil
+		}
+
+		select {
+		case <-connected:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-c.ctx.Done():
+			return nil, ErrClientClosed
+		}
+	}
+}
+
+// setConnection makes conn current and wakes callers waiting for it
+func (c *RPCClient) setConnection(conn *websocket.Conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn = conn
+	close(c.connected)
+}
+
+// dropConnection clears the current connection and fails calls in flight
+func (c *RPCClient) dropConnection() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn = nil
+	c.connected = make(chan struct{})
+	for id, ch := range c.pending {
+		ch <- nil
+		delete(c.pending, id)
+	}
+}
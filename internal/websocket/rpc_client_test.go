@@ -0,0 +1,220 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/gorilla/websocket"
+)
+
+// newTestHubServer serves /ws from a hub that echoes JSON-RPC methods
+// and returns an accessor for the server-side clients it accepted
+func newTestHubServer(t *testing.T, ctx context.Context) (*Hub, *httptest.Server, func() []*Client) {
+	t.Helper()
+
+	hub := NewHub()
+	go hub.Run(ctx)
+	hub.SetRPCHandler(func(_ context.Context, client *Client, req *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
+		return &protocol.JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: map[string]interface{}{"method": req.Method, "session_id": client.SessionID}}
+	})
+
+	var mu sync.Mutex
+	clients := make([]*Client, 0)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		client := NewClient("client", conn, hub, r.URL.Query().Get("repository"), r.URL.Query().Get("session_id"))
+		mu.Lock()
+		clients = append(clients, client)
+		mu.Unlock()
+		hub.RegisterClient(client)
+		go client.WritePump(ctx)
+		go client.ReadPump(ctx)
+	}))
+	t.Cleanup(server.Close)
+	return hub, server, func() []*Client {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]*Client(nil), clients...)
+	}
+}
+
+func wsURL(server *httptest.Server, query string) string {
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?" + query
+}
+
+func TestRPCClientCallAndEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hub, server, _ := newTestHubServer(t, ctx)
+
+	client, err := DialRPC(ctx, RPCClientConfig{URL: wsURL(server, "session_id=s-1")})
+	if err != nil {
+		t.Fatalf("DialRPC: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	callCtx, callCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer callCancel()
+	resp, err := client.Call(callCtx, "tools/list", nil)
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	result, ok := resp.Result.(map[string]interface{})
+	if !ok || result["method"] != "tools/list" || result["session_id"] != "s-1" {
+		t.Errorf("unexpected result %+v", resp.Result)
+	}
+
+	// The welcome event arrives first, then broadcasts for the session
+	event := NewMemoryEvent("memory", "created", "chunk-1", "", "s-1", nil)
+	hub.BroadcastMemoryEvent(&event)
+	for {
+		select {
+		case got := <-client.Events():
+			if got.Type == "connection" {
+				continue
+			}
+			if got.ChunkID != "chunk-1" {
+				t.Errorf("unexpected event %+v", got)
+			}
+			return
+		case <-callCtx.Done():
+			t.Fatal("timed out waiting for event")
+		}
+	}
+}
+
+func TestRPCClientReconnects(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, server, clients := newTestHubServer(t, ctx)
+
+	client, err := DialRPC(ctx, RPCClientConfig{URL: wsURL(server, "session_id=s-1"), InitialBackoff: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("DialRPC: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	callCtx, callCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer callCancel()
+	if _, err := client.Call(callCtx, "ping", nil); err != nil {
+		t.Fatalf("Call before disconnect: %v", err)
+	}
+
+	// Drop the connection from the server side
+	_ = clients()[0].Connection.Close()
+
+	resp, err := client.Call(callCtx, "ping", nil)
+	for errors.Is(err, ErrDisconnected) {
+		resp, err = client.Call(callCtx, "ping", nil)
+	}
+	if err != nil {
+		t.Fatalf("Call after reconnect: %v", err)
+	}
+	if result := resp.Result.(map[string]interface{}); result["session_id"] != "s-1" {
+		t.Errorf("reconnected connection lost its session: %+v", result)
+	}
+	if accepted := len(clients()); accepted < 2 {
+		t.Errorf("expected a second connection, got %d", accepted)
+	}
+}
+
+func TestRPCClientClose(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, server, _ := newTestHubServer(t, ctx)
+
+	client, err := DialRPC(ctx, RPCClientConfig{URL: wsURL(server, "")})
+	if err != nil {
+		t.Fatalf("DialRPC: %v", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := client.Call(ctx, "ping", nil); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("Call after Close = %v, want ErrClientClosed", err)
+	}
+	for range client.Events() {
+	}
+}
+
+func TestHubWithoutRPCHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hub, server, _ := newTestHubServer(t, ctx)
+	hub.SetRPCHandler(nil)
+
+	client, err := DialRPC(ctx, RPCClientConfig{URL: wsURL(server, "")})
+	if err != nil {
+		t.Fatalf("DialRPC: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	callCtx, callCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer callCancel()
+	resp, err := client.Call(callCtx, "tools/list", nil)
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	if resp.Error == nil || resp.Error.Code != protocol.MethodNotFound {
+		t.Errorf("expected method-not-found error, got %+v", resp)
+	}
+}
+
+func TestHubShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hub, server, clients := newTestHubServer(t, ctx)
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL(server, ""), nil)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	deadline := time.Now().Add(5 * time.Second)
+	for hub.GetClientCount() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if hub.GetClientCount() != 1 {
+		t.Fatal("client was not registered")
+	}
+
+	if err := clients()[0].SendNotification("notifications/message", map[string]interface{}{"data": "bye"}); err != nil {
+		t.Fatalf("SendNotification: %v", err)
+	}
+	shutdownCtx, shutdownCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer shutdownCancel()
+	if err := hub.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	// The queued notification arrives, after the welcome event, before the close frame
+	notified := false
+	for {
+		var message map[string]interface{}
+		err := conn.ReadJSON(&message)
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNoStatusReceived) {
+				t.Errorf("read after shutdown = %v, want a close frame", err)
+			}
+			break
+		}
+		notified = notified || message["method"] == "notifications/message"
+	}
+	if !notified {
+		t.Error("queued notification was not sent before closing")
+	}
+	if hub.GetClientCount() != 0 {
+		t.Errorf("clients after shutdown = %d, want 0", hub.GetClientCount())
+	}
+}
@@ -0,0 +1,143 @@
+// Package client is a typed Go client for the memory server. It wraps the
+// server's MCP tools (memory_create, memory_read, memory_tasks) and
+// resources in methods with request and result structs, retries calls that
+// failed in transit or were rate limited, and streams new chunks of a
+// repository as they are stored.
+//
+//	conn, err := client.Connect(ctx, mcpclient.NewHTTPTransport("http://localhost:9080/mcp"))
+//	stored, err := conn.StoreChunk(ctx, client.StoreChunkRequest{Repository: repo, SessionID: "s1", Content: "..."})
+//	results, err := conn.Search(ctx, client.SearchRequest{Repository: repo, Query: "flaky build"})
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	mcpclient "lerian-mcp-memory/pkg/mcp/client"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+// rateLimitedCode is the JSON-RPC error code the server throttles requests with
+const rateLimitedCode = -32001
+
+// ErrNotFound is returned when the requested item does not exist
+var ErrNotFound = errors.New("not found")
+
+// RetryPolicy bounds how calls are retried. Calls are retried when they got
+// no answer from the server, such as on a dropped connection or a 5xx from
+// the HTTP endpoint, or were rate limited. Errors the tools report are not
+// retried.
+type RetryPolicy struct {
+	MaxAttempts    int           // attempts per call, including the first; below 2 disables retries
+	InitialBackoff time.Duration // wait before the first retry, doubled on each one
+	MaxBackoff     time.Duration // longest wait between attempts
+}
+
+// DefaultRetryPolicy returns the policy new clients use: three attempts,
+// backing off from 200ms up to 5s
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 3, InitialBackoff: 200 * time.Millisecond, MaxBackoff: 5 * time.Second}
+}
+
+// Client calls the memory server over an MCP connection. It is safe for
+// concurrent use.
+type Client struct {
+	conn  *mcpclient.Client
+	retry RetryPolicy
+}
+
+// New creates a client over an MCP connection that completed Initialize
+func New(conn *mcpclient.Client) *Client {
+	return &Client{conn: conn, retry: DefaultRetryPolicy()}
+}
+
+// Connect opens an MCP connection over transport, performs the initialize
+// handshake and returns a client over it
+func Connect(ctx context.Context, transport mcpclient.Transport) (*Client, error) {
+	conn := mcpclient.New(transport)
+	conn.SetClientInfo("lerian-mcp-memory-go", "1.0.0")
+	if _, err := conn.Initialize(ctx); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to initialize connection: %w", err)
+	}
+	return New(conn), nil
+}
+
+// SetRetryPolicy sets how calls are retried. Set it before making calls.
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
+// Conn returns the underlying MCP connection, for tools without typed methods
+func (c *Client) Conn() *mcpclient.Client {
+	return c.conn
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// callTool calls a consolidated tool operation and decodes its JSON result
+// into out
+func (c *Client) callTool(ctx context.Context, tool, operation string, options map[string]interface{}, out interface{}) error {
+	args := map[string]interface{}{"operation": operation, "options": options}
+	return c.withRetry(ctx, func() error {
+		return c.conn.CallToolInto(ctx, tool, args, out)
+	})
+}
+
+// withRetry runs call until it succeeds, fails for good or runs out of attempts
+func (c *Client) withRetry(ctx context.Context, call func() error) error {
+	backoff := c.retry.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := call()
+		wait, retryable := retryDelay(err, backoff)
+		if !retryable || attempt >= c.retry.MaxAttempts {
+			return err
+		}
+		if c.retry.MaxBackoff > 0 && wait > c.retry.MaxBackoff {
+			wait = c.retry.MaxBackoff
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// retryDelay reports whether a failed call may be retried and how long to
+// wait first. Rate limited calls wait as long as the server asks.
+func retryDelay(err error, backoff time.Duration) (time.Duration, bool) {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, mcpclient.ErrClosed) {
+		return 0, false
+	}
+	var toolErr *mcpclient.ToolError
+	if errors.As(err, &toolErr) {
+		return 0, false
+	}
+	var rpcErr *protocol.JSONRPCError
+	if !errors.As(err, &rpcErr) {
+		return backoff, true
+	}
+	if rpcErr.Code != rateLimitedCode {
+		return 0, false
+	}
+
+	var data struct {
+		RetryAfterMS int64 `json:"retry_after_ms"`
+	}
+	if raw, marshalErr := json.Marshal(rpcErr.Data); marshalErr == nil && json.Unmarshal(raw, &data) == nil && data.RetryAfterMS > 0 {
+		return time.Duration(data.RetryAfterMS) * time.Millisecond, true
+	}
+	return backoff, true
+}
@@ -0,0 +1,238 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	mcpclient "lerian-mcp-memory/pkg/mcp/client"
+	"lerian-mcp-memory/pkg/types"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMemoryServer answers the tools and resources the client uses, keeping
+// chunks and todos in memory
+type fakeMemoryServer struct {
+	mu          sync.Mutex
+	out         *json.Encoder
+	chunks      []types.ConversationChunk
+	todos       []Todo
+	rateLimited int // calls left to throttle
+	calls       int
+	lastArgs    map[string]interface{}
+}
+
+// request is a JSON-RPC request as the fake decodes it
+type request struct {
+	ID     interface{} `json:"id"`
+	Method string      `json:"method"`
+	Params struct {
+		Name      string                 `json:"name"`
+		Arguments map[string]interface{} `json:"arguments"`
+		URI       string                 `json:"uri"`
+	} `json:"params"`
+}
+
+// serveFakeMemory connects a client to a fake memory server
+func serveFakeMemory(t *testing.T) (*Client, *fakeMemoryServer) {
+	t.Helper()
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	s := &fakeMemoryServer{out: json.NewEncoder(serverOut)}
+	go s.run(serverIn)
+
+	c, err := Connect(context.Background(), mcpclient.NewStdioTransport(clientIn, clientOut))
+	require.NoError(t, err)
+	c.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond})
+	t.Cleanup(func() {
+		_ = c.Close()
+		_ = serverOut.Close()
+	})
+	return c, s
+}
+
+func (s *fakeMemoryServer) run(in io.Reader) {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		var req request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil || req.ID == nil {
+			continue
+		}
+		result, rpcErr := s.handle(&req)
+		s.send(protocol.JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rpcErr})
+	}
+}
+
+func (s *fakeMemoryServer) send(v interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = s.out.Encode(v)
+}
+
+func (s *fakeMemoryServer) handle(req *request) (interface{}, *protocol.JSONRPCError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch req.Method {
+	case "initialize":
+		return protocol.InitializeResult{ProtocolVersion: protocol.Version, ServerInfo: protocol.ServerInfo{Name: "fake"}}, nil
+	case "resources/subscribe", "resources/unsubscribe":
+		return map[string]interface{}{}, nil
+	case "resources/read":
+		data, _ := json.Marshal(s.chunks)
+		return map[string]interface{}{"contents": []protocol.Content{protocol.NewContent(string(data))}}, nil
+	case "tools/call":
+		s.calls++
+		if s.rateLimited > 0 {
+			s.rateLimited--
+			return nil, protocol.NewJSONRPCError(rateLimitedCode, "Rate limit exceeded", map[string]interface{}{"retry_after_ms": 1})
+		}
+		s.lastArgs = req.Params.Arguments
+		return s.callTool(req.Params.Name, req.Params.Arguments), nil
+	}
+	return nil, protocol.NewJSONRPCError(protocol.MethodNotFound, "Method not found", nil)
+}
+
+func (s *fakeMemoryServer) callTool(name string, args map[string]interface{}) *protocol.ToolCallResult {
+	options, _ := args["options"].(map[string]interface{})
+	var result interface{}
+	switch name + "/" + args["operation"].(string) {
+	case "memory_create/store_chunk":
+		chunk := types.ConversationChunk{
+			ID:        "chunk-" + string(rune('a'+len(s.chunks))),
+			Content:   options["content"].(string),
+			Type:      types.ChunkTypeSolution,
+			Timestamp: time.Now(),
+		}
+		s.chunks = append(s.chunks, chunk)
+		result = map[string]interface{}{"chunk_id": chunk.ID, "type": "solution", "summary": "summary", "stored_at": chunk.Timestamp.Format(time.RFC3339)}
+	case "memory_read/search":
+		results := make([]types.SearchResult, 0, len(s.chunks))
+		for i := range s.chunks {
+			results = append(results, types.SearchResult{Chunk: s.chunks[i], Score: 0.9})
+		}
+		result = map[string]interface{}{"query": options["query"], "total": len(results), "results": results, "query_time": 3}
+	case "memory_read/get_threads":
+		result = map[string]interface{}{"threads": []map[string]interface{}{
+			{"thread_id": "thread-1", "title": "Login outage", "status": "active", "chunk_count": 2, "start_time": "2024-05-01T10:00:00Z"},
+		}}
+	case "memory_tasks/todo_write":
+		data, _ := json.Marshal(options["todos"])
+		_ = json.Unmarshal(data, &s.todos)
+		result = map[string]interface{}{"success": true}
+	case "memory_tasks/todo_read":
+		result = map[string]interface{}{"todos": s.todos}
+	default:
+		return protocol.NewToolCallError("unsupported operation")
+	}
+	data, _ := json.Marshal(result)
+	return protocol.NewToolCallResult(protocol.NewContent(string(data)))
+}
+
+// notifyUpdated reports a change to a resource
+func (s *fakeMemoryServer) notifyUpdated(uri string) {
+	s.send(protocol.JSONRPCRequest{JSONRPC: "2.0", Method: mcpclient.NotificationResourceUpdated, Params: map[string]interface{}{"uri": uri}})
+}
+
+func TestClientStoreAndSearch(t *testing.T) {
+	ctx := context.Background()
+	c, s := serveFakeMemory(t)
+
+	stored, err := c.StoreChunk(ctx, StoreChunkRequest{
+		Repository: "github.com/acme/api",
+		SessionID:  "session-1",
+		Content:    "Fixed the flaky build by pinning the toolchain",
+		Tags:       []string{"ci"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "chunk-a", stored.ChunkID)
+	assert.Equal(t, types.ChunkTypeSolution, stored.Type)
+	assert.False(t, stored.StoredAt.IsZero())
+	assert.Equal(t, map[string]interface{}{
+		"repository": "github.com/acme/api",
+		"session_id": "session-1",
+		"content":    "Fixed the flaky build by pinning the toolchain",
+		"tags":       []interface{}{"ci"},
+	}, s.lastArgs["options"], "requests become tool options")
+
+	results, err := c.Search(ctx, SearchRequest{Repository: "github.com/acme/api", Query: "flaky build", Limit: 5})
+	require.NoError(t, err)
+	assert.Equal(t, 1, results.Total)
+	require.Len(t, results.Results, 1)
+	assert.Equal(t, "chunk-a", results.Results[0].Chunk.ID)
+	assert.Equal(t, int64(3), results.QueryTimeMS)
+}
+
+func TestClientRetriesRateLimitedCalls(t *testing.T) {
+	ctx := context.Background()
+	c, s := serveFakeMemory(t)
+
+	s.rateLimited = 2
+	_, err := c.Search(ctx, SearchRequest{Repository: "r", Query: "q"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, s.calls)
+
+	s.rateLimited = 3
+	_, err = c.Search(ctx, SearchRequest{Repository: "r", Query: "q"})
+	var rpcErr *protocol.JSONRPCError
+	require.ErrorAs(t, err, &rpcErr, "gives up after MaxAttempts")
+	assert.Equal(t, rateLimitedCode, rpcErr.Code)
+
+	// Tool errors are final
+	s.calls = 0
+	err = c.callTool(ctx, "memory_read", "unknown", map[string]interface{}{}, nil)
+	var toolErr *mcpclient.ToolError
+	require.ErrorAs(t, err, &toolErr)
+	assert.Equal(t, 1, s.calls)
+}
+
+func TestClientThreadsAndTodos(t *testing.T) {
+	ctx := context.Background()
+	c, _ := serveFakeMemory(t)
+
+	thread, err := c.GetThread(ctx, "r", "thread-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Login outage", thread.Title)
+	assert.Equal(t, 2, thread.ChunkCount)
+	_, err = c.GetThread(ctx, "r", "thread-2")
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	todos := []Todo{{ID: "1", Content: "Write docs", Status: "pending", Priority: "high"}}
+	require.NoError(t, c.WriteTodos(ctx, "r", "", todos))
+	read, err := c.Todos(ctx, "r", "")
+	require.NoError(t, err)
+	assert.Equal(t, todos, read)
+}
+
+func TestClientStreamChunks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c, s := serveFakeMemory(t)
+
+	_, err := c.StoreChunk(ctx, StoreChunkRequest{Repository: "r", SessionID: "s", Content: "before the stream"})
+	require.NoError(t, err)
+	chunks, err := c.StreamChunks(ctx, "r")
+	require.NoError(t, err)
+
+	_, err = c.StoreChunk(ctx, StoreChunkRequest{Repository: "r", SessionID: "s", Content: "after the stream"})
+	require.NoError(t, err)
+	s.notifyUpdated(recentURIPrefix + "r")
+
+	select {
+	case chunk := <-chunks:
+		assert.Equal(t, "after the stream", chunk.Content, "only chunks stored after the call are sent")
+	case <-time.After(5 * time.Second):
+		t.Fatal("no chunk streamed")
+	}
+
+	cancel()
+	for range chunks {
+		// Drained once the stream stops
+	}
+}
@@ -0,0 +1,257 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	mcpclient "lerian-mcp-memory/pkg/mcp/client"
+	"lerian-mcp-memory/pkg/types"
+)
+
+// recentURIPrefix is the URI prefix of a repository's recent chunks resource
+const recentURIPrefix = "memory://recent/"
+
+// StoreChunkRequest is a chunk to store
+type StoreChunkRequest struct {
+	Repository    string            `json:"repository"`
+	SessionID     string            `json:"session_id"`
+	Content       string            `json:"content"`
+	Branch        string            `json:"branch,omitempty"`
+	Tags          []string          `json:"tags,omitempty"`
+	FilesModified []string          `json:"files_modified,omitempty"`
+	ToolsUsed     []string          `json:"tools_used,omitempty"`
+	Provenance    *types.Provenance `json:"provenance,omitempty"`
+}
+
+// StoredChunk describes a stored chunk
+type StoredChunk struct {
+	ChunkID            string          `json:"chunk_id"`
+	Type               types.ChunkType `json:"type"`
+	Summary            string          `json:"summary"`
+	StoredAt           time.Time       `json:"stored_at"`
+	Queued             bool            `json:"queued,omitempty"` // searchable after the next batch flush
+	ExtractedDecisions []string        `json:"extracted_decisions,omitempty"`
+}
+
+// SearchRequest is a search within a repository
+type SearchRequest struct {
+	Repository   string            `json:"repository"`
+	Query        string            `json:"query"`
+	Limit        int               `json:"limit,omitempty"`
+	MinRelevance float64           `json:"min_relevance,omitempty"`
+	Types        []types.ChunkType `json:"types,omitempty"`
+	Recency      types.Recency     `json:"recency,omitempty"`
+}
+
+// SearchResponse holds the chunks matching a search, best first
+type SearchResponse struct {
+	Query       string               `json:"query"`
+	Total       int                  `json:"total"`
+	Results     []types.SearchResult `json:"results"`
+	QueryTimeMS int64                `json:"query_time"`
+}
+
+// Thread is a thread of related chunks
+type Thread struct {
+	ID          string     `json:"thread_id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Type        string     `json:"type"`
+	Status      string     `json:"status"`
+	Repository  string     `json:"repository"`
+	ChunkCount  int        `json:"chunk_count"`
+	StartTime   time.Time  `json:"start_time"`
+	LastUpdate  time.Time  `json:"last_update"`
+	EndTime     *time.Time `json:"end_time,omitempty"`
+	SessionIDs  []string   `json:"session_ids"`
+	Tags        []string   `json:"tags"`
+	Priority    int        `json:"priority"`
+}
+
+// Todo is a task tracked by memory_tasks
+type Todo struct {
+	ID       string `json:"id"`
+	Content  string `json:"content"`
+	Status   string `json:"status"` // pending, in_progress, completed, cancelled
+	Priority string `json:"priority"`
+}
+
+// StoreChunk stores a chunk
+func (c *Client) StoreChunk(ctx context.Context, req StoreChunkRequest) (*StoredChunk, error) {
+	options, err := toOptions(req)
+	if err != nil {
+		return nil, err
+	}
+	var stored StoredChunk
+	if err := c.callTool(ctx, "memory_create", "store_chunk", options, &stored); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// Search searches a repository's chunks
+func (c *Client) Search(ctx context.Context, req SearchRequest) (*SearchResponse, error) {
+	options, err := toOptions(req)
+	if err != nil {
+		return nil, err
+	}
+	var response SearchResponse
+	if err := c.callTool(ctx, "memory_read", "search", options, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetThread returns a thread of a repository, or ErrNotFound
+func (c *Client) GetThread(ctx context.Context, repository, threadID string) (*Thread, error) {
+	var response struct {
+		Threads []Thread `json:"threads"`
+	}
+	if err := c.callTool(ctx, "memory_read", "get_threads", map[string]interface{}{"repository": repository}, &response); err != nil {
+		return nil, err
+	}
+	for i := range response.Threads {
+		if response.Threads[i].ID == threadID {
+			return &response.Threads[i], nil
+		}
+	}
+	return nil, fmt.Errorf("thread %s: %w", threadID, ErrNotFound)
+}
+
+// Todos returns the todos of a session, or of every active session of the
+// repository when sessionID is empty
+func (c *Client) Todos(ctx context.Context, repository, sessionID string) ([]Todo, error) {
+	options := map[string]interface{}{"repository": repository}
+	if sessionID != "" {
+		options["session_id"] = sessionID
+	}
+	var response struct {
+		Todos []Todo `json:"todos"`
+	}
+	if err := c.callTool(ctx, "memory_tasks", "todo_read", options, &response); err != nil {
+		return nil, err
+	}
+	return response.Todos, nil
+}
+
+// WriteTodos replaces the todos of a session, or the repository-wide todos
+// when sessionID is empty
+func (c *Client) WriteTodos(ctx context.Context, repository, sessionID string, todos []Todo) error {
+	options, err := toOptions(struct {
+		Repository string `json:"repository"`
+		SessionID  string `json:"session_id,omitempty"`
+		Todos      []Todo `json:"todos"`
+	}{repository, sessionID, todos})
+	if err != nil {
+		return err
+	}
+	return c.callTool(ctx, "memory_tasks", "todo_write", options, nil)
+}
+
+// StreamChunks sends the chunks stored in a repository from now on, oldest
+// first, until ctx is done or the connection ends. It follows the
+// repository's memory://recent resource, so a connection can stream each
+// repository once.
+func (c *Client) StreamChunks(ctx context.Context, repository string) (<-chan types.ConversationChunk, error) {
+	uri := recentURIPrefix + repository
+	changed := make(chan struct{}, 1)
+	// Runs on the connection's read loop, so it only signals
+	notify := func(string) {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+	if err := c.withRetry(ctx, func() error { return c.conn.Subscribe(ctx, uri, notify) }); err != nil {
+		return nil, err
+	}
+
+	recent, err := c.readRecent(ctx, uri)
+	if err != nil {
+		_ = c.conn.Unsubscribe(context.Background(), uri)
+		return nil, err
+	}
+	seen := chunkIDs(recent)
+
+	chunks := make(chan types.ConversationChunk)
+	go func() {
+		defer close(chunks)
+		defer func() { _ = c.conn.Unsubscribe(context.Background(), uri) }()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.conn.Done():
+				return
+			case <-changed:
+			}
+
+			recent, err := c.readRecent(ctx, uri)
+			if err != nil {
+				// The next change reads the resource again
+				continue
+			}
+			for i := range recent {
+				if seen[recent[i].ID] {
+					continue
+				}
+				select {
+				case chunks <- recent[i]:
+				case <-ctx.Done():
+					return
+				}
+			}
+			seen = chunkIDs(recent)
+		}
+	}()
+	return chunks, nil
+}
+
+// readRecent reads a recent chunks resource, oldest first
+func (c *Client) readRecent(ctx context.Context, uri string) ([]types.ConversationChunk, error) {
+	var contents []mcpclient.ResourceContents
+	err := c.withRetry(ctx, func() error {
+		var err error
+		contents, err = c.conn.ReadResource(ctx, uri)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var recent []types.ConversationChunk
+	for _, content := range contents {
+		var chunks []types.ConversationChunk
+		if err := json.Unmarshal([]byte(content.Text), &chunks); err != nil {
+			return nil, fmt.Errorf("invalid %s contents: %w", uri, err)
+		}
+		recent = append(recent, chunks...)
+	}
+	sort.SliceStable(recent, func(i, j int) bool { return recent[i].Timestamp.Before(recent[j].Timestamp) })
+	return recent, nil
+}
+
+// chunkIDs returns the set of IDs of chunks
+func chunkIDs(chunks []types.ConversationChunk) map[string]bool {
+	ids := make(map[string]bool, len(chunks))
+	for i := range chunks {
+		ids[chunks[i].ID] = true
+	}
+	return ids
+}
+
+// toOptions converts a request struct to tool options
+func toOptions(req interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	var options map[string]interface{}
+	if err := json.Unmarshal(data, &options); err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	return options, nil
+}
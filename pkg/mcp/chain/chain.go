@@ -0,0 +1,462 @@
+// Package chain runs chains of MCP tool calls. A chain is a declarative list
+// of steps, each calling one tool with arguments that may reference the
+// chain's input and the results of earlier steps by JSONPath, so a search
+// result's ID can feed the next call without string templating. Steps can
+// be conditional, are retried when they fail, and a failed step either stops
+// the chain or, if it may, lets the rest run; either way the result reports
+// what every step did.
+//
+//	c, err := chain.Parse(definition)
+//	result, err := c.Run(ctx, conn, map[string]interface{}{"query": "flaky build"})
+//
+// where definition is
+//
+//	{"steps": [
+//	  {"name": "search", "tool": "memory_read",
+//	   "args": {"operation": "search", "options": {"query": "$.input.query"}}},
+//	  {"name": "related", "tool": "memory_read",
+//	   "if": {"path": "$.steps.search.result.results[0]"},
+//	   "args": {"operation": "find_related", "options": {"chunk_id": "$.steps.search.result.results[0].chunk.id"}},
+//	   "retries": 2, "continue_on_error": true}
+//	]}
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+// Step statuses
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusSkipped   = "skipped" // its condition did not hold
+	StatusNotRun    = "not_run" // an earlier step failed and stopped the chain
+)
+
+// Chain statuses
+const (
+	ChainSucceeded = "succeeded" // no step failed
+	ChainPartial   = "partial"   // some steps failed and others succeeded
+	ChainFailed    = "failed"    // steps failed and none succeeded
+)
+
+// Caller calls tools; *client.Client of pkg/mcp/client is one
+type Caller interface {
+	CallTool(ctx context.Context, name string, args map[string]interface{}) (*protocol.ToolCallResult, error)
+}
+
+// Chain is a list of steps run in order
+type Chain struct {
+	Name  string `json:"name,omitempty"`
+	Steps []Step `json:"steps"`
+}
+
+// Step calls one tool. String arguments starting with $ are JSONPath
+// references into the document {"input": ..., "steps": {NAME: {"status",
+// "result", "error"}}} and are replaced by the value they point at, keeping
+// its type; write $$ for a literal leading $. Tool results that are JSON
+// are referenced as parsed JSON, others as their text.
+type Step struct {
+	Name            string                 `json:"name"`
+	Tool            string                 `json:"tool"`
+	Args            map[string]interface{} `json:"args,omitempty"`
+	If              *Condition             `json:"if,omitempty"`
+	Retries         int                    `json:"retries,omitempty"`        // attempts after the first
+	RetryDelayMS    int                    `json:"retry_delay_ms,omitempty"` // wait between attempts
+	ContinueOnError bool                   `json:"continue_on_error,omitempty"`
+}
+
+// Condition decides whether a step runs. It holds when Path resolves to a
+// value equal to Equals or, without Equals, to a value that is not null,
+// false, 0, "" or empty; Not inverts it. A path that resolves to nothing,
+// such as into a skipped step, does not hold.
+type Condition struct {
+	Path   string      `json:"path"`
+	Equals interface{} `json:"equals,omitempty"`
+	Not    bool        `json:"not,omitempty"`
+}
+
+// Result reports what a chain run did
+type Result struct {
+	Chain      string       `json:"chain,omitempty"`
+	Status     string       `json:"status"`
+	FailedStep string       `json:"failed_step,omitempty"` // the step that stopped the chain
+	Steps      []StepResult `json:"steps"`
+}
+
+// StepResult reports what a step did
+type StepResult struct {
+	Name       string      `json:"name"`
+	Tool       string      `json:"tool"`
+	Status     string      `json:"status"`
+	Attempts   int         `json:"attempts,omitempty"`
+	Result     interface{} `json:"result,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	DurationMS int64       `json:"duration_ms"`
+}
+
+// StepError is returned by Run when a step failed and stopped the chain
+type StepError struct {
+	Step string
+	Tool string
+	Err  error
+}
+
+// Error implements the error interface
+func (e *StepError) Error() string {
+	return fmt.Sprintf("chain step %s (%s) failed: %v", e.Step, e.Tool, e.Err)
+}
+
+// Unwrap returns the step's error
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// toolFailure is the error of a tool that reported a failure
+type toolFailure struct {
+	message string
+}
+
+func (e *toolFailure) Error() string {
+	return "tool reported an error: " + e.message
+}
+
+// Parse decodes a chain from JSON and validates it
+func Parse(data []byte) (*Chain, error) {
+	var c Chain
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid chain definition: %w", err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Validate checks that steps have unique names and a tool, and that their
+// references are valid paths into the input or earlier steps
+func (c *Chain) Validate() error {
+	if len(c.Steps) == 0 {
+		return errors.New("chain has no steps")
+	}
+	earlier := make(map[string]bool, len(c.Steps))
+	for i := range c.Steps {
+		step := &c.Steps[i]
+		switch {
+		case step.Name == "":
+			return fmt.Errorf("step %d has no name", i)
+		case earlier[step.Name]:
+			return fmt.Errorf("step name %s is used twice", step.Name)
+		case step.Tool == "":
+			return fmt.Errorf("step %s has no tool", step.Name)
+		case step.Retries < 0 || step.RetryDelayMS < 0:
+			return fmt.Errorf("step %s has negative retries or retry delay", step.Name)
+		}
+
+		var paths []string
+		collectReferences(step.Args, &paths)
+		if step.If != nil {
+			if step.If.Path == "" {
+				return fmt.Errorf("step %s has a condition without a path", step.Name)
+			}
+			paths = append(paths, step.If.Path)
+		}
+		for _, path := range paths {
+			if err := checkReference(path, earlier); err != nil {
+				return fmt.Errorf("step %s: %w", step.Name, err)
+			}
+		}
+		earlier[step.Name] = true
+	}
+	return nil
+}
+
+// collectReferences appends the references found in value to paths
+func collectReferences(value interface{}, paths *[]string) {
+	switch v := value.(type) {
+	case string:
+		if path, ok := reference(v); ok {
+			*paths = append(*paths, path)
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			collectReferences(item, paths)
+		}
+	case []interface{}:
+		for _, item := range v {
+			collectReferences(item, paths)
+		}
+	}
+}
+
+// checkReference checks that path parses and names the input or an earlier step
+func checkReference(path string, earlier map[string]bool) error {
+	segments, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	if len(segments) == 0 {
+		return nil
+	}
+	switch segments[0].key {
+	case "input":
+		return nil
+	case "steps":
+		if len(segments) < 2 || segments[1].key == "" {
+			return fmt.Errorf("reference %s does not name a step", path)
+		}
+		if !earlier[segments[1].key] {
+			return fmt.Errorf("reference %s names a step that does not run before it", path)
+		}
+		return nil
+	default:
+		return fmt.Errorf("reference %s must start with $.input or $.steps", path)
+	}
+}
+
+// reference returns the path of a string that is a reference
+func reference(s string) (string, bool) {
+	if !strings.HasPrefix(s, "$") || strings.HasPrefix(s, "$$") {
+		return "", false
+	}
+	return s, true
+}
+
+// Run runs the chain's steps in order with input as $.input. It returns the
+// report of every step and, when a step failed and stopped the chain, a
+// *StepError; steps allowed to fail only show in the report.
+func (c *Chain) Run(ctx context.Context, caller Caller, input map[string]interface{}) (*Result, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	normalized, err := normalize(input)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chain input: %w", err)
+	}
+	steps := make(map[string]interface{}, len(c.Steps))
+	document := map[string]interface{}{"input": normalized, "steps": steps}
+
+	result := &Result{Chain: c.Name, Steps: make([]StepResult, 0, len(c.Steps))}
+	var stop *StepError
+	for i := range c.Steps {
+		step := &c.Steps[i]
+		if stop != nil {
+			result.Steps = append(result.Steps, StepResult{Name: step.Name, Tool: step.Tool, Status: StatusNotRun})
+			continue
+		}
+
+		report := runStep(ctx, caller, step, document)
+		result.Steps = append(result.Steps, report.StepResult)
+		recorded := map[string]interface{}{"status": report.Status}
+		if report.Result != nil {
+			recorded["result"] = report.Result
+		}
+		if report.Error != "" {
+			recorded["error"] = report.Error
+		}
+		steps[step.Name] = recorded
+
+		if report.Status == StatusFailed && !step.ContinueOnError {
+			stop = &StepError{Step: step.Name, Tool: step.Tool, Err: report.err}
+			result.FailedStep = step.Name
+		}
+	}
+
+	result.Status = summarize(result.Steps)
+	if stop != nil {
+		return result, stop
+	}
+	return result, nil
+}
+
+// stepReport is a step's result along with its error
+type stepReport struct {
+	StepResult
+	err error
+}
+
+// runStep runs a step, retrying it as allowed
+func runStep(ctx context.Context, caller Caller, step *Step, document map[string]interface{}) stepReport {
+	report := stepReport{StepResult: StepResult{Name: step.Name, Tool: step.Tool}}
+	started := time.Now()
+	defer func() { report.DurationMS = time.Since(started).Milliseconds() }()
+
+	if step.If != nil && !step.If.holds(document) {
+		report.Status = StatusSkipped
+		return report
+	}
+	args, err := resolve(step.Args, document)
+	if err != nil {
+		report.Status, report.Error, report.err = StatusFailed, err.Error(), err
+		return report
+	}
+	argMap, _ := args.(map[string]interface{})
+
+	for attempt := 1; ; attempt++ {
+		report.Attempts = attempt
+		value, err := call(ctx, caller, step.Tool, argMap)
+		if err == nil {
+			report.Status, report.Result = StatusSucceeded, value
+			return report
+		}
+		report.Status, report.Error, report.err = StatusFailed, err.Error(), err
+		if attempt > step.Retries || ctx.Err() != nil {
+			return report
+		}
+
+		timer := time.NewTimer(time.Duration(step.RetryDelayMS) * time.Millisecond)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return report
+		case <-timer.C:
+		}
+	}
+}
+
+// call calls a tool and returns its result, parsed when it is JSON. A tool
+// reporting a failure returns its message as the error.
+func call(ctx context.Context, caller Caller, tool string, args map[string]interface{}) (interface{}, error) {
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	result, err := caller.CallTool(ctx, tool, args)
+	if err != nil {
+		return nil, err
+	}
+	parts := make([]string, 0, len(result.Content))
+	for _, content := range result.Content {
+		if content.Text != "" {
+			parts = append(parts, content.Text)
+		}
+	}
+	text := strings.Join(parts, "\n")
+	if result.IsError {
+		return nil, &toolFailure{message: text}
+	}
+
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(text), &parsed); err == nil {
+		return parsed, nil
+	}
+	return text, nil
+}
+
+// resolve returns value with its references replaced by what they point at
+func resolve(value interface{}, document map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if strings.HasPrefix(v, "$$") {
+			return v[1:], nil
+		}
+		if path, ok := reference(v); ok {
+			return lookup(document, path)
+		}
+		return v, nil
+	case map[string]interface{}:
+		resolved := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			r, err := resolve(item, document)
+			if err != nil {
+				return nil, err
+			}
+			resolved[key] = r
+		}
+		return resolved, nil
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		for i, item := range v {
+			r, err := resolve(item, document)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = r
+		}
+		return resolved, nil
+	default:
+		return v, nil
+	}
+}
+
+// holds evaluates the condition against the document
+func (c *Condition) holds(document map[string]interface{}) bool {
+	value, err := lookup(document, c.Path)
+	found := err == nil
+
+	var match bool
+	if c.Equals != nil {
+		match = found && jsonEqual(value, c.Equals)
+	} else {
+		match = found && truthy(value)
+	}
+	return match != c.Not
+}
+
+// truthy reports whether a JSON value is set: not null, false, 0, "" or empty
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	case []interface{}:
+		return len(v) > 0
+	case map[string]interface{}:
+		return len(v) > 0
+	default:
+		return true
+	}
+}
+
+// jsonEqual compares two values by their JSON encoding, so 3 equals 3.0
+func jsonEqual(a, b interface{}) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
+
+// normalize turns a value into the maps, slices and float64s JSON decodes to
+func normalize(value map[string]interface{}) (interface{}, error) {
+	if value == nil {
+		return map[string]interface{}{}, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var normalized interface{}
+	err = json.Unmarshal(data, &normalized)
+	return normalized, err
+}
+
+// summarize returns a chain's status from its steps
+func summarize(steps []StepResult) string {
+	var succeeded, failed int
+	for _, step := range steps {
+		switch step.Status {
+		case StatusSucceeded:
+			succeeded++
+		case StatusFailed:
+			failed++
+		}
+	}
+	switch {
+	case failed == 0:
+		return ChainSucceeded
+	case succeeded > 0:
+		return ChainPartial
+	default:
+		return ChainFailed
+	}
+}
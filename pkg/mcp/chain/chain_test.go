@@ -0,0 +1,159 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCaller answers tool calls from a function and records them
+type fakeCaller struct {
+	calls  []protocol.ToolCallRequest
+	answer func(name string, args map[string]interface{}, attempt int) (*protocol.ToolCallResult, error)
+}
+
+func (f *fakeCaller) CallTool(_ context.Context, name string, args map[string]interface{}) (*protocol.ToolCallResult, error) {
+	attempt := 1
+	for _, call := range f.calls {
+		if call.Name == name {
+			attempt++
+		}
+	}
+	f.calls = append(f.calls, protocol.ToolCallRequest{Name: name, Arguments: args})
+	return f.answer(name, args, attempt)
+}
+
+func textResult(v interface{}) *protocol.ToolCallResult {
+	data, _ := json.Marshal(v)
+	return &protocol.ToolCallResult{Content: []protocol.Content{{Type: "text", Text: string(data)}}}
+}
+
+func TestLookup(t *testing.T) {
+	var document interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"a": {"b c": [1, {"d": "x"}]}}`), &document))
+
+	tests := []struct {
+		path string
+		want interface{}
+	}{
+		{"$.a['b c'][1].d", "x"},
+		{`$.a["b c"][0]`, float64(1)},
+		{"$.a['b c'][-1].d", "x"},
+	}
+	for _, tt := range tests {
+		got, err := lookup(document, tt.path)
+		require.NoError(t, err, tt.path)
+		assert.Equal(t, tt.want, got, tt.path)
+	}
+
+	_, err := lookup(document, "$.a['b c'][2]")
+	assert.ErrorIs(t, err, ErrPathNotFound)
+	_, err = lookup(document, "$.a.missing")
+	assert.ErrorIs(t, err, ErrPathNotFound)
+	_, err = lookup(document, "a.b")
+	assert.ErrorContains(t, err, "must start with $")
+	_, err = lookup(document, "$.a[x]")
+	assert.ErrorContains(t, err, "neither an index nor a quoted key")
+}
+
+func TestParseValidates(t *testing.T) {
+	tests := []struct {
+		name, definition, errMsg string
+	}{
+		{"no steps", `{"steps": []}`, "no steps"},
+		{"duplicate name", `{"steps": [{"name": "a", "tool": "t"}, {"name": "a", "tool": "t"}]}`, "used twice"},
+		{"no tool", `{"steps": [{"name": "a"}]}`, "has no tool"},
+		{"forward reference", `{"steps": [{"name": "a", "tool": "t", "args": {"x": "$.steps.b.result"}}, {"name": "b", "tool": "t"}]}`, "does not run before it"},
+		{"unknown root", `{"steps": [{"name": "a", "tool": "t", "if": {"path": "$.other"}}]}`, "must start with $.input or $.steps"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.definition))
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
+
+func TestRunPassesResultsByReference(t *testing.T) {
+	c, err := Parse([]byte(`{"name": "search-then-relate", "steps": [
+		{"name": "search", "tool": "memory_read", "args": {"operation": "search", "options": {"query": "$.input.query", "limit": 1}}},
+		{"name": "related", "tool": "memory_read", "if": {"path": "$.steps.search.result.results[0]"},
+		 "args": {"operation": "find_related", "options": {"chunk_id": "$.steps.search.result.results[0].id", "label": "$$literal"}}},
+		{"name": "cleanup", "tool": "memory_delete", "if": {"path": "$.steps.search.result.total", "equals": 0}}
+	]}`))
+	require.NoError(t, err)
+
+	caller := &fakeCaller{answer: func(name string, _ map[string]interface{}, _ int) (*protocol.ToolCallResult, error) {
+		if name == "memory_read" {
+			return textResult(map[string]interface{}{"results": []interface{}{map[string]interface{}{"id": "chunk-1"}}, "total": 1}), nil
+		}
+		return nil, errors.New("unexpected call")
+	}}
+
+	result, err := c.Run(context.Background(), caller, map[string]interface{}{"query": "flaky build"})
+	require.NoError(t, err)
+	assert.Equal(t, ChainSucceeded, result.Status)
+	require.Len(t, caller.calls, 2)
+	assert.Equal(t, "flaky build", caller.calls[0].Arguments["options"].(map[string]interface{})["query"])
+	options := caller.calls[1].Arguments["options"].(map[string]interface{})
+	assert.Equal(t, "chunk-1", options["chunk_id"])
+	assert.Equal(t, "$literal", options["label"])
+	assert.Equal(t, StatusSkipped, result.Steps[2].Status)
+}
+
+func TestRunRetriesAndReportsPartialFailure(t *testing.T) {
+	c := &Chain{Steps: []Step{
+		{Name: "flaky", Tool: "flaky", Retries: 2},
+		{Name: "broken", Tool: "broken", ContinueOnError: true},
+		{Name: "fatal", Tool: "fatal"},
+		{Name: "after", Tool: "after"},
+	}}
+	caller := &fakeCaller{answer: func(name string, _ map[string]interface{}, attempt int) (*protocol.ToolCallResult, error) {
+		switch {
+		case name == "flaky" && attempt < 3:
+			return nil, errors.New("connection reset")
+		case name == "broken":
+			return &protocol.ToolCallResult{IsError: true, Content: []protocol.Content{{Type: "text", Text: "bad input"}}}, nil
+		case name == "fatal":
+			return nil, errors.New("server gone")
+		default:
+			return textResult("ok"), nil
+		}
+	}}
+
+	result, err := c.Run(context.Background(), caller, nil)
+	var stepErr *StepError
+	require.ErrorAs(t, err, &stepErr)
+	assert.Equal(t, "fatal", stepErr.Step)
+	assert.Equal(t, ChainPartial, result.Status)
+	assert.Equal(t, "fatal", result.FailedStep)
+
+	statuses := make(map[string]string)
+	for _, step := range result.Steps {
+		statuses[step.Name] = step.Status
+	}
+	assert.Equal(t, map[string]string{"flaky": StatusSucceeded, "broken": StatusFailed, "fatal": StatusFailed, "after": StatusNotRun}, statuses)
+	assert.Equal(t, 3, result.Steps[0].Attempts)
+	assert.Equal(t, "ok", result.Steps[0].Result)
+	assert.Contains(t, result.Steps[1].Error, "bad input")
+}
+
+func TestRunFailsStepWithMissingReference(t *testing.T) {
+	c := &Chain{Steps: []Step{
+		{Name: "search", Tool: "search"},
+		{Name: "read", Tool: "read", Args: map[string]interface{}{"id": "$.steps.search.result.results[0].id"}},
+	}}
+	caller := &fakeCaller{answer: func(string, map[string]interface{}, int) (*protocol.ToolCallResult, error) {
+		return textResult(map[string]interface{}{"results": []interface{}{}}), nil
+	}}
+
+	result, err := c.Run(context.Background(), caller, nil)
+	assert.ErrorIs(t, err, ErrPathNotFound)
+	assert.Len(t, caller.calls, 1, "a step whose arguments cannot be resolved is not called")
+	assert.Equal(t, StatusFailed, result.Steps[1].Status)
+}
@@ -0,0 +1,107 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrPathNotFound is returned when a reference points at nothing
+var ErrPathNotFound = errors.New("path not found")
+
+// segment is one step of a path: an object key, or an array index when key is empty
+type segment struct {
+	key   string
+	index int
+}
+
+// parsePath parses a JSONPath of the subset references use: the root $
+// followed by .key, ['key'] or ["key"] members and [n] array indexes, where
+// a negative n counts from the end
+func parsePath(path string) ([]segment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("invalid path %q: must start with $", path)
+	}
+	var segments []segment
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("invalid path %q: empty member name", path)
+			}
+			segments = append(segments, segment{key: key})
+			rest = rest[end+1:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: unclosed [", path)
+			}
+			inner := rest[1:end]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				segments = append(segments, segment{key: inner[1 : len(inner)-1]})
+			} else {
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid path %q: [%s] is neither an index nor a quoted key", path, inner)
+				}
+				segments = append(segments, segment{index: index})
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("invalid path %q: unexpected %q", path, rest[0])
+		}
+	}
+	return segments, nil
+}
+
+// lookup resolves path against a document decoded from JSON
+func lookup(document interface{}, path string) (interface{}, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	value := document
+	for i, seg := range segments {
+		switch node := value.(type) {
+		case map[string]interface{}:
+			next, ok := node[seg.key]
+			if seg.key == "" || !ok {
+				return nil, fmt.Errorf("%w: %s at %s", ErrPathNotFound, path, describe(segments[:i+1]))
+			}
+			value = next
+		case []interface{}:
+			index := seg.index
+			if index < 0 {
+				index += len(node)
+			}
+			if seg.key != "" || index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("%w: %s at %s", ErrPathNotFound, path, describe(segments[:i+1]))
+			}
+			value = node[index]
+		default:
+			return nil, fmt.Errorf("%w: %s at %s", ErrPathNotFound, path, describe(segments[:i+1]))
+		}
+	}
+	return value, nil
+}
+
+// describe formats segments back into a path, for errors
+func describe(segments []segment) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, seg := range segments {
+		if seg.key != "" {
+			b.WriteString("." + seg.key)
+		} else {
+			fmt.Fprintf(&b, "[%d]", seg.index)
+		}
+	}
+	return b.String()
+}
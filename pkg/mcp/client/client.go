@@ -0,0 +1,377 @@
+// Package client implements a Model Context Protocol client. It connects to
+// an MCP server over stdio, HTTP, WebSocket or gRPC, performs the initialize
+// handshake, calls tools, reads and subscribes to resources, and answers the
+// requests servers send their clients, such as sampling and roots.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/fredcamaral/gomcp-sdk/roots"
+	"github.com/fredcamaral/gomcp-sdk/sampling"
+)
+
+// MCP methods used by the client
+const (
+	methodInitialize           = "initialize"
+	methodInitialized          = "notifications/initialized"
+	methodPing                 = "ping"
+	methodToolsList            = "tools/list"
+	methodToolsCall            = "tools/call"
+	methodResourcesList        = "resources/list"
+	methodResourcesRead        = "resources/read"
+	methodResourcesSubscribe   = "resources/subscribe"
+	methodResourcesUnsubscribe = "resources/unsubscribe"
+	methodSamplingCreate       = "sampling/createMessage"
+	methodRootsList            = "roots/list"
+
+	// NotificationResourceUpdated reports a change to a subscribed resource
+	NotificationResourceUpdated = "notifications/resources/updated"
+	// NotificationToolsListChanged reports that the server's tools changed
+	NotificationToolsListChanged = "notifications/tools/list_changed"
+	// NotificationResourcesListChanged reports that the server's resources changed
+	NotificationResourcesListChanged = "notifications/resources/list_changed"
+	// NotificationProgress reports progress of a long-running request
+	NotificationProgress = "notifications/progress"
+	// NotificationRootsListChanged tells the server the client's roots changed
+	NotificationRootsListChanged = "notifications/roots/list_changed"
+)
+
+// ErrClosed is returned for calls on a client whose transport closed
+var ErrClosed = errors.New("mcp client is closed")
+
+// NotificationHandler handles a notification from the server
+type NotificationHandler func(method string, params json.RawMessage)
+
+// SamplingHandler answers a server's request for an LLM completion
+type SamplingHandler func(ctx context.Context, req *sampling.CreateMessageRequest) (*sampling.CreateMessageResponse, error)
+
+// Client is a connection to an MCP server. It is safe for concurrent use.
+type Client struct {
+	transport  Transport
+	clientInfo protocol.ClientInfo
+
+	mu            sync.Mutex
+	nextID        int64
+	pending       map[string]chan *response
+	notifications map[string][]NotificationHandler
+	subscriptions *resourceSubscriptions
+	sampling      SamplingHandler
+	roots         []roots.Root
+	shareRoots    bool
+	initialized   *protocol.InitializeResult
+	err           error // why the connection ended
+
+	done chan struct{}
+}
+
+// response is a server's answer to a client request
+type response struct {
+	ID     json.RawMessage        `json:"id"`
+	Result json.RawMessage        `json:"result"`
+	Error  *protocol.JSONRPCError `json:"error"`
+}
+
+// message is any JSON-RPC message from the server
+type message struct {
+	ID     json.RawMessage        `json:"id"`
+	Method string                 `json:"method"`
+	Params json.RawMessage        `json:"params"`
+	Result json.RawMessage        `json:"result"`
+	Error  *protocol.JSONRPCError `json:"error"`
+}
+
+// New creates a client over a connected transport and starts reading the
+// server's messages. Call Initialize before anything else.
+func New(transport Transport) *Client {
+	c := &Client{
+		transport:     transport,
+		clientInfo:    protocol.ClientInfo{Name: "lerian-mcp-memory-client", Version: "1.0.0"},
+		pending:       make(map[string]chan *response),
+		notifications: make(map[string][]NotificationHandler),
+		done:          make(chan struct{}),
+	}
+	go c.readLoop()
+	return c
+}
+
+// SetClientInfo sets the name and version sent on initialize
+func (c *Client) SetClientInfo(name, version string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clientInfo = protocol.ClientInfo{Name: name, Version: version}
+}
+
+// SetSamplingHandler declares the sampling capability and answers the
+// server's sampling/createMessage requests with handler. Set it before
+// Initialize.
+func (c *Client) SetSamplingHandler(handler SamplingHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sampling = handler
+}
+
+// SetRoots declares the roots capability and shares clientRoots with the
+// server. After Initialize, the server is notified that the roots changed.
+func (c *Client) SetRoots(ctx context.Context, clientRoots []roots.Root) error {
+	c.mu.Lock()
+	c.roots = append([]roots.Root(nil), clientRoots...)
+	c.shareRoots = true
+	initialized := c.initialized != nil
+	c.mu.Unlock()
+
+	if !initialized {
+		return nil
+	}
+	return c.Notify(ctx, NotificationRootsListChanged, nil)
+}
+
+// OnNotification registers a handler for a server notification method.
+// Handlers run on the client's read loop and must not block on calls to the
+// server.
+func (c *Client) OnNotification(method string, handler NotificationHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.notifications[method] = append(c.notifications[method], handler)
+}
+
+// Initialize performs the initialize handshake, declaring the capabilities
+// of the handlers set so far, and returns what the server supports
+func (c *Client) Initialize(ctx context.Context) (*protocol.InitializeResult, error) {
+	c.mu.Lock()
+	capabilities := map[string]interface{}{}
+	if c.sampling != nil {
+		capabilities["sampling"] = map[string]interface{}{}
+	}
+	if c.shareRoots {
+		capabilities["roots"] = protocol.RootsCapability{ListChanged: true}
+	}
+	params := map[string]interface{}{
+		"protocolVersion": protocol.Version,
+		"capabilities":    capabilities,
+		"clientInfo":      c.clientInfo,
+	}
+	c.mu.Unlock()
+
+	var result protocol.InitializeResult
+	if err := c.Call(ctx, methodInitialize, params, &result); err != nil {
+		return nil, err
+	}
+	if err := c.Notify(ctx, methodInitialized, nil); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.initialized = &result
+	c.mu.Unlock()
+	return &result, nil
+}
+
+// ServerInfo returns the server's initialize result, or nil before Initialize
+func (c *Client) ServerInfo() *protocol.InitializeResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.initialized
+}
+
+// Call sends a request and decodes its result into result, which may be nil.
+// Errors the server returns are *protocol.JSONRPCError.
+func (c *Client) Call(ctx context.Context, method string, params, result interface{}) error {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+	c.nextID++
+	id := c.nextID
+	key := strconv.FormatInt(id, 10)
+	responses := make(chan *response, 1)
+	c.pending[key] = responses
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, key)
+		c.mu.Unlock()
+	}()
+
+	if err := c.send(ctx, &protocol.JSONRPCRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params}); err != nil {
+		return err
+	}
+
+	select {
+	case resp := <-responses:
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result == nil || len(resp.Result) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("invalid %s result: %w", method, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.done:
+		return c.closedErr()
+	}
+}
+
+// Notify sends a notification, which gets no response
+func (c *Client) Notify(ctx context.Context, method string, params interface{}) error {
+	return c.send(ctx, &protocol.JSONRPCRequest{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// Ping checks that the server is responsive
+func (c *Client) Ping(ctx context.Context) error {
+	return c.Call(ctx, methodPing, nil, nil)
+}
+
+// Close closes the transport and fails calls in flight
+func (c *Client) Close() error {
+	err := c.transport.Close()
+	<-c.done
+	return err
+}
+
+// Done is closed when the connection to the server ends
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// send encodes and writes one message
+func (c *Client) send(ctx context.Context, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	select {
+	case <-c.done:
+		return c.closedErr()
+	default:
+	}
+	return c.transport.Send(ctx, data)
+}
+
+// closedErr returns why the connection ended
+func (c *Client) closedErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// readLoop routes the server's messages until the transport closes
+func (c *Client) readLoop() {
+	for {
+		data, err := c.transport.Receive()
+		if err != nil {
+			c.mu.Lock()
+			c.err = fmt.Errorf("%w: %w", ErrClosed, err)
+			c.mu.Unlock()
+			close(c.done)
+			return
+		}
+
+		var msg message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			// Servers may share the connection with other traffic, such as
+			// WebSocket broadcasts; only JSON-RPC is routed
+			continue
+		}
+		switch {
+		case msg.Method != "" && len(msg.ID) > 0:
+			go c.handleServerRequest(&msg)
+		case msg.Method != "":
+			c.handleNotification(&msg)
+		case len(msg.ID) > 0:
+			c.deliver(&response{ID: msg.ID, Result: msg.Result, Error: msg.Error})
+		}
+	}
+}
+
+// deliver hands a response to the call waiting for it
+func (c *Client) deliver(resp *response) {
+	key := string(resp.ID)
+	// IDs the client sent as numbers may come back as strings
+	if unquoted, err := strconv.Unquote(key); err == nil {
+		key = unquoted
+	}
+	c.mu.Lock()
+	responses, ok := c.pending[key]
+	c.mu.Unlock()
+	if !ok {
+		return
+	}
+	select {
+	case responses <- resp:
+	default:
+		// A duplicate response; the call already has its answer
+	}
+}
+
+// handleNotification runs the handlers registered for a notification
+func (c *Client) handleNotification(msg *message) {
+	c.mu.Lock()
+	handlers := append([]NotificationHandler(nil), c.notifications[msg.Method]...)
+	c.mu.Unlock()
+	for _, handler := range handlers {
+		handler(msg.Method, msg.Params)
+	}
+}
+
+// handleServerRequest answers a request the server sent the client
+func (c *Client) handleServerRequest(msg *message) {
+	var id interface{}
+	_ = json.Unmarshal(msg.ID, &id)
+	reply := &protocol.JSONRPCResponse{JSONRPC: "2.0", ID: id}
+
+	result, err := c.serveRequest(msg)
+	if err != nil {
+		var rpcErr *protocol.JSONRPCError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = protocol.NewJSONRPCError(protocol.InternalError, err.Error(), nil)
+		}
+		reply.Error = rpcErr
+	} else {
+		reply.Result = result
+	}
+	// The connection may have ended; nothing is waiting for the reply
+	_ = c.send(context.Background(), reply)
+}
+
+// serveRequest computes the result of a server request
+func (c *Client) serveRequest(msg *message) (interface{}, error) {
+	c.mu.Lock()
+	samplingHandler, clientRoots, shareRoots := c.sampling, c.roots, c.shareRoots
+	c.mu.Unlock()
+
+	switch msg.Method {
+	case methodPing:
+		return map[string]interface{}{}, nil
+	case methodRootsList:
+		if !shareRoots {
+			break
+		}
+		if clientRoots == nil {
+			clientRoots = []roots.Root{}
+		}
+		return roots.ListRootsResponse{Roots: clientRoots}, nil
+	case methodSamplingCreate:
+		if samplingHandler == nil {
+			break
+		}
+		var req sampling.CreateMessageRequest
+		if err := json.Unmarshal(msg.Params, &req); err != nil {
+			return nil, protocol.NewJSONRPCError(protocol.InvalidParams, err.Error(), nil)
+		}
+		return samplingHandler(context.Background(), &req)
+	}
+	return nil, protocol.NewJSONRPCError(protocol.MethodNotFound, "Method not found: "+msg.Method, nil)
+}
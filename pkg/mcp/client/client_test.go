@@ -0,0 +1,207 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/fredcamaral/gomcp-sdk/roots"
+	"github.com/fredcamaral/gomcp-sdk/sampling"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer answers a client over a pair of pipes the way an MCP server
+// would, including requests of its own to the client
+type fakeServer struct {
+	t       *testing.T
+	mu      sync.Mutex
+	out     *json.Encoder
+	replies map[string]chan message
+}
+
+// serveFake connects a client to a fake server
+func serveFake(t *testing.T) *Client {
+	t.Helper()
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	s := &fakeServer{t: t, out: json.NewEncoder(serverOut), replies: make(map[string]chan message)}
+	go s.run(serverIn)
+
+	c := New(NewStdioTransport(clientIn, clientOut))
+	t.Cleanup(func() {
+		_ = c.Close()
+		_ = serverOut.Close()
+	})
+	return c
+}
+
+func (s *fakeServer) run(in io.Reader) {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		var msg message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+		if msg.Method == "" {
+			// The client's answer to a request of the server
+			s.mu.Lock()
+			replies := s.replies[string(msg.ID)]
+			s.mu.Unlock()
+			replies <- msg
+			continue
+		}
+		if len(msg.ID) == 0 {
+			continue
+		}
+		go s.handle(msg)
+	}
+}
+
+func (s *fakeServer) reply(msg message, result interface{}, rpcErr *protocol.JSONRPCError) {
+	var id interface{}
+	_ = json.Unmarshal(msg.ID, &id)
+	s.send(protocol.JSONRPCResponse{JSONRPC: "2.0", ID: id, Result: result, Error: rpcErr})
+}
+
+func (s *fakeServer) send(v interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = s.out.Encode(v)
+}
+
+// ask sends the client a request and waits for its answer
+func (s *fakeServer) ask(id, method string, params interface{}) message {
+	replies := make(chan message, 1)
+	s.mu.Lock()
+	s.replies[`"`+id+`"`] = replies
+	s.mu.Unlock()
+	s.send(protocol.JSONRPCRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	return <-replies
+}
+
+func (s *fakeServer) handle(msg message) {
+	var params map[string]interface{}
+	_ = json.Unmarshal(msg.Params, &params)
+
+	switch msg.Method {
+	case methodInitialize:
+		s.reply(msg, protocol.InitializeResult{
+			ProtocolVersion: protocol.Version,
+			Capabilities:    protocol.ServerCapabilities{Experimental: params["capabilities"].(map[string]interface{})},
+			ServerInfo:      protocol.ServerInfo{Name: "fake", Version: "1.0.0"},
+		}, nil)
+	case methodToolsList:
+		// Two pages, to exercise cursor following
+		if params["cursor"] == "page-2" {
+			s.reply(msg, map[string]interface{}{"tools": []protocol.Tool{{Name: "memory_store"}}}, nil)
+			return
+		}
+		s.reply(msg, map[string]interface{}{"tools": []protocol.Tool{{Name: "memory_read"}}, "nextCursor": "page-2"}, nil)
+	case methodToolsCall:
+		switch params["name"] {
+		case "memory_read":
+			s.reply(msg, protocol.NewToolCallResult(protocol.NewContent(`{"status":"success","total":2}`)), nil)
+		case "summarize":
+			answer := s.ask("server-1", methodSamplingCreate, sampling.CreateMessageRequest{MaxTokens: 10})
+			var result sampling.CreateMessageResponse
+			_ = json.Unmarshal(answer.Result, &result)
+			s.reply(msg, protocol.NewToolCallResult(protocol.NewContent(result.Content.Text)), nil)
+		case "roots":
+			answer := s.ask("server-2", methodRootsList, nil)
+			s.reply(msg, protocol.NewToolCallResult(protocol.NewContent(string(answer.Result))), nil)
+		default:
+			s.reply(msg, protocol.NewToolCallError("unknown tool"), nil)
+		}
+	case methodResourcesRead:
+		s.reply(msg, map[string]interface{}{"contents": []protocol.Content{protocol.NewContent("recent")}}, nil)
+	case methodResourcesSubscribe:
+		s.reply(msg, map[string]interface{}{}, nil)
+		s.send(protocol.JSONRPCRequest{JSONRPC: "2.0", Method: NotificationResourceUpdated, Params: map[string]interface{}{"uri": params["uri"]}})
+	default:
+		s.reply(msg, nil, protocol.NewJSONRPCError(protocol.MethodNotFound, "Method not found", nil))
+	}
+}
+
+func TestClientToolsAndResources(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c := serveFake(t)
+
+	info, err := c.Initialize(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "fake", info.ServerInfo.Name)
+	assert.Same(t, info, c.ServerInfo())
+
+	tools, err := c.ListTools(ctx)
+	require.NoError(t, err)
+	require.Len(t, tools, 2, "every page is listed")
+	assert.Equal(t, "memory_store", tools[1].Name)
+
+	var result struct {
+		Status string `json:"status"`
+		Total  int    `json:"total"`
+	}
+	require.NoError(t, c.CallToolInto(ctx, "memory_read", map[string]interface{}{"operation": "search"}, &result))
+	assert.Equal(t, 2, result.Total)
+
+	var toolErr *ToolError
+	require.ErrorAs(t, c.CallToolInto(ctx, "missing", nil, nil), &toolErr)
+	assert.Equal(t, "unknown tool", toolErr.Message)
+
+	var rpcErr *protocol.JSONRPCError
+	require.ErrorAs(t, c.Call(ctx, "prompts/list", nil, nil), &rpcErr)
+	assert.Equal(t, protocol.MethodNotFound, rpcErr.Code)
+
+	contents, err := c.ReadResource(ctx, "memory://recent/github.com/acme/api")
+	require.NoError(t, err)
+	require.Len(t, contents, 1)
+	assert.Equal(t, "recent", contents[0].Text)
+
+	updated := make(chan string, 1)
+	require.NoError(t, c.Subscribe(ctx, "memory://recent/github.com/acme/api", func(uri string) { updated <- uri }))
+	select {
+	case uri := <-updated:
+		assert.Equal(t, "memory://recent/github.com/acme/api", uri)
+	case <-ctx.Done():
+		t.Fatal("no resource update received")
+	}
+}
+
+func TestClientAnswersServerRequests(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c := serveFake(t)
+
+	c.SetSamplingHandler(func(_ context.Context, req *sampling.CreateMessageRequest) (*sampling.CreateMessageResponse, error) {
+		return &sampling.CreateMessageResponse{Role: "assistant", Content: sampling.SamplingMessageContent{Type: "text", Text: "summary"}}, nil
+	})
+	require.NoError(t, c.SetRoots(ctx, []roots.Root{{URI: "file:///work/api", Name: "api"}}))
+
+	info, err := c.Initialize(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, info.Capabilities.Experimental, "sampling", "the fake server echoes the declared capabilities")
+	assert.Contains(t, info.Capabilities.Experimental, "roots")
+
+	result, err := c.CallTool(ctx, "summarize", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "summary", result.Content[0].Text)
+
+	result, err = c.CallTool(ctx, "roots", nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"roots":[{"uri":"file:///work/api","name":"api"}]}`, result.Content[0].Text)
+}
+
+func TestClientClose(t *testing.T) {
+	c := serveFake(t)
+	require.NoError(t, c.Close())
+
+	err := c.Call(context.Background(), methodPing, nil, nil)
+	assert.True(t, errors.Is(err, ErrClosed), "calls after close fail with ErrClosed, got %v", err)
+}
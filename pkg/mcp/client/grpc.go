@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"lerian-mcp-memory/pkg/mcp/mcppb"
+
+	"google.golang.org/grpc"
+)
+
+// GRPCTransport exchanges messages over the Connect stream of a gRPC MCP
+// server. A request sent with a deadline carries it as the request's
+// timeout_ms, so the server stops serving it when the caller gives up.
+type GRPCTransport struct {
+	conn   *grpc.ClientConn // dialed by DialGRPC and closed with the transport
+	stream grpc.BidiStreamingClient[mcppb.Message, mcppb.Message]
+	cancel context.CancelFunc
+	sendMu sync.Mutex
+	once   sync.Once
+}
+
+// DialGRPC connects to a gRPC MCP server. opts must set transport
+// credentials, such as grpc.WithTransportCredentials with a client
+// certificate for servers that require mTLS.
+func DialGRPC(target string, opts ...grpc.DialOption) (*GRPCTransport, error) {
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", target, err)
+	}
+	t, err := NewGRPCTransport(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	t.conn = conn
+	return t, nil
+}
+
+// NewGRPCTransport opens a Connect stream on an existing connection, which
+// Close leaves open
+func NewGRPCTransport(cc grpc.ClientConnInterface) (*GRPCTransport, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := mcppb.NewMCPClient(cc).Connect(ctx)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to open MCP stream: %w", err)
+	}
+	return &GRPCTransport{stream: stream, cancel: cancel}, nil
+}
+
+// Send writes a message to the stream
+func (t *GRPCTransport) Send(ctx context.Context, message []byte) error {
+	m, err := mcppb.MessageFromJSON(message)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok && m.GetMethod() != "" {
+		m.TimeoutMs = max(time.Until(deadline).Milliseconds(), 1)
+	}
+
+	t.sendMu.Lock()
+	defer t.sendMu.Unlock()
+	if err := t.stream.Send(m); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
+
+// Receive reads the next message from the stream
+func (t *GRPCTransport) Receive() ([]byte, error) {
+	m, err := t.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	return m.JSONRPC()
+}
+
+// Close ends the stream and closes a connection dialed by DialGRPC
+func (t *GRPCTransport) Close() error {
+	var err error
+	t.once.Do(func() {
+		t.sendMu.Lock()
+		_ = t.stream.CloseSend()
+		t.sendMu.Unlock()
+		t.cancel()
+		if t.conn != nil {
+			err = t.conn.Close()
+		}
+	})
+	return err
+}
@@ -0,0 +1,197 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// sessionHeader carries the session of the MCP streamable HTTP transport
+const sessionHeader = "Mcp-Session-Id"
+
+// HTTPTransport posts each message to an MCP HTTP endpoint. Against an
+// endpoint that assigns sessions, such as /sse, it keeps the session and
+// opens its event stream to receive notifications and server requests.
+type HTTPTransport struct {
+	url    string
+	client *http.Client
+	header http.Header
+
+	mu        sync.Mutex
+	sessionID string
+	streaming bool
+
+	incoming chan []byte
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+// NewHTTPTransport creates a transport for the endpoint at url
+func NewHTTPTransport(url string) *HTTPTransport {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &HTTPTransport{
+		url:      url,
+		client:   http.DefaultClient,
+		header:   make(http.Header),
+		incoming: make(chan []byte, 64),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// SetHTTPClient sets the client used for requests
+func (t *HTTPTransport) SetHTTPClient(client *http.Client) {
+	t.client = client
+}
+
+// SetHeader sets a header sent with every request, such as authorization
+func (t *HTTPTransport) SetHeader(key, value string) {
+	t.header.Set(key, value)
+}
+
+// SessionID returns the session the server assigned, if any
+func (t *HTTPTransport) SessionID() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.sessionID
+}
+
+// Send posts a message. A JSON response body is delivered to Receive.
+func (t *HTTPTransport) Send(ctx context.Context, message []byte) error {
+	req, err := t.newRequest(ctx, http.MethodPost, bytes.NewReader(message))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post message: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("server answered %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	if sessionID := resp.Header.Get(sessionHeader); sessionID != "" {
+		t.startSession(sessionID)
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 || string(body) == "null" {
+		return nil
+	}
+	return t.push(body)
+}
+
+// Receive returns the next response or streamed message
+func (t *HTTPTransport) Receive() ([]byte, error) {
+	select {
+	case message := <-t.incoming:
+		return message, nil
+	case <-t.ctx.Done():
+		return nil, io.EOF
+	}
+}
+
+// Close ends the session, if any, and stops receiving
+func (t *HTTPTransport) Close() error {
+	var err error
+	if t.SessionID() != "" {
+		var req *http.Request
+		req, err = t.newRequest(context.Background(), http.MethodDelete, nil)
+		if err == nil {
+			var resp *http.Response
+			if resp, err = t.client.Do(req); err == nil {
+				_ = resp.Body.Close()
+			}
+		}
+	}
+	t.cancel()
+	return err
+}
+
+// newRequest creates a request to the endpoint with the transport's headers
+// and session
+func (t *HTTPTransport) newRequest(ctx context.Context, method string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, t.url, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range t.header {
+		req.Header[key] = values
+	}
+	if sessionID := t.SessionID(); sessionID != "" {
+		req.Header.Set(sessionHeader, sessionID)
+	}
+	return req, nil
+}
+
+// push delivers a message to Receive unless the transport closed
+func (t *HTTPTransport) push(message []byte) error {
+	select {
+	case t.incoming <- message:
+		return nil
+	case <-t.ctx.Done():
+		return io.EOF
+	}
+}
+
+// startSession remembers the session the server assigned and opens its
+// event stream once
+func (t *HTTPTransport) startSession(sessionID string) {
+	t.mu.Lock()
+	t.sessionID = sessionID
+	start := !t.streaming
+	t.streaming = true
+	t.mu.Unlock()
+
+	if start {
+		go t.stream()
+	}
+}
+
+// stream reads the session's server-sent events until the transport closes
+func (t *HTTPTransport) stream() {
+	req, err := t.newRequest(t.ctx, http.MethodGet, nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		case line == "" && len(data) > 0:
+			if err := t.push([]byte(strings.Join(data, "\n"))); err != nil {
+				return
+			}
+			data = nil
+		}
+	}
+}
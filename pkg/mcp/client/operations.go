@@ -0,0 +1,197 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+)
+
+// ToolError is returned by CallToolInto when the tool reported a failure
+type ToolError struct {
+	Tool    string
+	Message string
+}
+
+// Error implements the error interface
+func (e *ToolError) Error() string {
+	return fmt.Sprintf("tool %s failed: %s", e.Tool, e.Message)
+}
+
+// ResourceContents is one item of a resource read
+type ResourceContents struct {
+	URI      string `json:"uri,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+// ListTools returns the server's tools, following pagination cursors
+func (c *Client) ListTools(ctx context.Context) ([]protocol.Tool, error) {
+	var tools []protocol.Tool
+	cursor := ""
+	for {
+		var result struct {
+			Tools      []protocol.Tool `json:"tools"`
+			NextCursor string          `json:"nextCursor"`
+		}
+		if err := c.Call(ctx, methodToolsList, listParams(cursor), &result); err != nil {
+			return nil, err
+		}
+		tools = append(tools, result.Tools...)
+		if result.NextCursor == "" {
+			return tools, nil
+		}
+		cursor = result.NextCursor
+	}
+}
+
+// listParams returns the parameters of a list request for the page at cursor
+func listParams(cursor string) map[string]interface{} {
+	if cursor == "" {
+		return map[string]interface{}{}
+	}
+	return map[string]interface{}{"cursor": cursor}
+}
+
+// CallTool calls a tool and returns its result as sent. A tool that fails
+// returns a result with IsError set rather than an error.
+func (c *Client) CallTool(ctx context.Context, name string, args map[string]interface{}) (*protocol.ToolCallResult, error) {
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	var result protocol.ToolCallResult
+	if err := c.Call(ctx, methodToolsCall, protocol.ToolCallRequest{Name: name, Arguments: args}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CallToolInto calls a tool and decodes the JSON it returned as text into
+// out. Failed tools return a *ToolError.
+func (c *Client) CallToolInto(ctx context.Context, name string, args map[string]interface{}, out interface{}) error {
+	result, err := c.CallTool(ctx, name, args)
+	if err != nil {
+		return err
+	}
+	text := toolText(result)
+	if result.IsError {
+		return &ToolError{Tool: name, Message: text}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(text), out); err != nil {
+		return fmt.Errorf("tool %s returned a result that is not the expected JSON: %w", name, err)
+	}
+	return nil
+}
+
+// toolText joins the text content of a tool result
+func toolText(result *protocol.ToolCallResult) string {
+	parts := make([]string, 0, len(result.Content))
+	for _, content := range result.Content {
+		if content.Text != "" {
+			parts = append(parts, content.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// ListResources returns the server's resources, following pagination cursors
+func (c *Client) ListResources(ctx context.Context) ([]protocol.Resource, error) {
+	var resources []protocol.Resource
+	cursor := ""
+	for {
+		var result struct {
+			Resources  []protocol.Resource `json:"resources"`
+			NextCursor string              `json:"nextCursor"`
+		}
+		if err := c.Call(ctx, methodResourcesList, listParams(cursor), &result); err != nil {
+			return nil, err
+		}
+		resources = append(resources, result.Resources...)
+		if result.NextCursor == "" {
+			return resources, nil
+		}
+		cursor = result.NextCursor
+	}
+}
+
+// ReadResource reads a resource
+func (c *Client) ReadResource(ctx context.Context, uri string) ([]ResourceContents, error) {
+	var result struct {
+		Contents []ResourceContents `json:"contents"`
+	}
+	if err := c.Call(ctx, methodResourcesRead, map[string]interface{}{"uri": uri}, &result); err != nil {
+		return nil, err
+	}
+	return result.Contents, nil
+}
+
+// Subscribe asks the server to report changes to a resource and calls
+// changed with its URI on each one, until Unsubscribe
+func (c *Client) Subscribe(ctx context.Context, uri string, changed func(uri string)) error {
+	// Listen first, as the server may report a change right after subscribing
+	c.mu.Lock()
+	if c.subscriptions == nil {
+		c.subscriptions = &resourceSubscriptions{byURI: make(map[string]func(string))}
+		c.notifications[NotificationResourceUpdated] = append(c.notifications[NotificationResourceUpdated], c.subscriptions.notify)
+	}
+	subscriptions := c.subscriptions
+	c.mu.Unlock()
+	subscriptions.set(uri, changed)
+
+	if err := c.Call(ctx, methodResourcesSubscribe, map[string]interface{}{"uri": uri}, nil); err != nil {
+		subscriptions.set(uri, nil)
+		return err
+	}
+	return nil
+}
+
+// Unsubscribe stops reporting changes to a resource
+func (c *Client) Unsubscribe(ctx context.Context, uri string) error {
+	c.mu.Lock()
+	if c.subscriptions != nil {
+		c.subscriptions.set(uri, nil)
+	}
+	c.mu.Unlock()
+	return c.Call(ctx, methodResourcesUnsubscribe, map[string]interface{}{"uri": uri}, nil)
+}
+
+// resourceSubscriptions routes resource update notifications by URI
+type resourceSubscriptions struct {
+	mu    sync.Mutex
+	byURI map[string]func(string)
+}
+
+// set registers or, with a nil callback, removes the callback of a URI
+func (rs *resourceSubscriptions) set(uri string, changed func(string)) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if changed == nil {
+		delete(rs.byURI, uri)
+		return
+	}
+	rs.byURI[uri] = changed
+}
+
+// notify calls the callback subscribed to the updated resource
+func (rs *resourceSubscriptions) notify(_ string, params json.RawMessage) {
+	var updated struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(params, &updated); err != nil {
+		return
+	}
+	rs.mu.Lock()
+	changed := rs.byURI[updated.URI]
+	rs.mu.Unlock()
+	if changed != nil {
+		changed(updated.URI)
+	}
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+)
+
+// maxMessageSize bounds a single message read from a stream transport
+const maxMessageSize = 10 << 20
+
+// Transport carries JSON-RPC messages between a client and a server
+type Transport interface {
+	// Send writes one message to the server
+	Send(ctx context.Context, message []byte) error
+	// Receive blocks until the next message from the server arrives. It
+	// returns an error, io.EOF when the server hung up, once the transport
+	// is closed.
+	Receive() ([]byte, error)
+	// Close ends the connection
+	Close() error
+}
+
+// StdioTransport exchanges newline-delimited messages over a pair of
+// streams, such as a server process's stdin and stdout
+type StdioTransport struct {
+	writeMu sync.Mutex
+	writer  io.Writer
+	scanner *bufio.Scanner
+	closers []io.Closer
+	wait    func() error // waits for a spawned server to exit
+	once    sync.Once
+}
+
+// NewStdioTransport creates a transport that reads the server's messages
+// from r and writes to w. Close closes either when it is an io.Closer.
+func NewStdioTransport(r io.Reader, w io.Writer) *StdioTransport {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	t := &StdioTransport{writer: w, scanner: scanner}
+	for _, stream := range []interface{}{w, r} {
+		if closer, ok := stream.(io.Closer); ok {
+			t.closers = append(t.closers, closer)
+		}
+	}
+	return t
+}
+
+// StartCommand starts a server process and returns a transport over its
+// stdin and stdout. Close ends the process's input and waits for it to exit.
+func StartCommand(cmd *exec.Cmd) (*StdioTransport, error) {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open server stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open server stdout: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start server: %w", err)
+	}
+
+	// stdout is closed by Wait once the server exits
+	t := NewStdioTransport(stdout, stdin)
+	t.closers = []io.Closer{stdin}
+	t.wait = cmd.Wait
+	return t, nil
+}
+
+// Send writes a message followed by a newline
+func (t *StdioTransport) Send(_ context.Context, message []byte) error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	if _, err := t.writer.Write(append(message, '\n')); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
+
+// Receive reads the next non-empty line
+func (t *StdioTransport) Receive() ([]byte, error) {
+	for t.scanner.Scan() {
+		if line := t.scanner.Bytes(); len(line) > 0 {
+			return append([]byte(nil), line...), nil
+		}
+	}
+	if err := t.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// Close closes the streams and waits for a spawned server to exit
+func (t *StdioTransport) Close() error {
+	var errs []error
+	t.once.Do(func() {
+		for _, closer := range t.closers {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if t.wait != nil {
+			if err := t.wait(); err != nil {
+				errs = append(errs, fmt.Errorf("server exited: %w", err))
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
@@ -0,0 +1,190 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"lerian-mcp-memory/pkg/mcp/mcppb"
+
+	"github.com/fredcamaral/gomcp-sdk/protocol"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// answer builds the response a test server sends to a request body
+func answer(t *testing.T, body []byte) []byte {
+	t.Helper()
+	var req protocol.JSONRPCRequest
+	require.NoError(t, json.Unmarshal(body, &req))
+	var result interface{} = map[string]interface{}{}
+	if req.Method == methodInitialize {
+		result = protocol.InitializeResult{ProtocolVersion: protocol.Version, ServerInfo: protocol.ServerInfo{Name: "test"}}
+	}
+	data, err := json.Marshal(protocol.JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: result})
+	require.NoError(t, err)
+	return data
+}
+
+func TestHTTPTransportSessions(t *testing.T) {
+	deleted := make(chan string, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sse", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			body, _ := io.ReadAll(r.Body)
+			if strings.Contains(string(body), `"method":"notifications/`) {
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			if r.Header.Get(sessionHeader) == "" && !strings.Contains(string(body), methodInitialize) {
+				http.Error(w, "session required", http.StatusNotFound)
+				return
+			}
+			w.Header().Set(sessionHeader, "session-1")
+			_, _ = w.Write(answer(t, body))
+		case http.MethodGet:
+			assert.Equal(t, "session-1", r.Header.Get(sessionHeader))
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = fmt.Fprint(w, "data: {\"type\":\"connected\"}\n\n")
+			_, _ = fmt.Fprintf(w, "id: 1\ndata: {\"jsonrpc\":\"2.0\",\"method\":%q,\"params\":{\"uri\":\"memory://recent/r\"}}\n\n", NotificationResourceUpdated)
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		case http.MethodDelete:
+			deleted <- r.Header.Get(sessionHeader)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	transport := NewHTTPTransport(server.URL + "/sse")
+	transport.SetHeader("Authorization", "Bearer token")
+	c := New(transport)
+
+	notified := make(chan json.RawMessage, 1)
+	c.OnNotification(NotificationResourceUpdated, func(_ string, params json.RawMessage) { notified <- params })
+
+	info, err := c.Initialize(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "test", info.ServerInfo.Name)
+	assert.Equal(t, "session-1", transport.SessionID())
+	require.NoError(t, c.Ping(ctx), "later requests carry the session")
+
+	select {
+	case params := <-notified:
+		assert.JSONEq(t, `{"uri":"memory://recent/r"}`, string(params))
+	case <-ctx.Done():
+		t.Fatal("no notification streamed")
+	}
+
+	require.NoError(t, c.Close())
+	assert.Equal(t, "session-1", <-deleted, "closing ends the session")
+}
+
+func TestWebSocketTransport(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		// Broadcasts that are not JSON-RPC share the connection
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"memory_update"}`))
+		for {
+			_, body, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if strings.Contains(string(body), `"id"`) {
+				_ = conn.WriteMessage(websocket.TextMessage, answer(t, body))
+			}
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	transport, err := DialWebSocket(ctx, "ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	c := New(transport)
+
+	info, err := c.Initialize(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "test", info.ServerInfo.Name)
+	require.NoError(t, c.Close())
+}
+
+// grpcTestServer answers the requests of Connect streams, recording the
+// timeout each arrived with
+type grpcTestServer struct {
+	t        *testing.T
+	timeouts chan int64
+}
+
+func (s *grpcTestServer) Call(context.Context, *mcppb.Message) (*mcppb.Message, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *grpcTestServer) Connect(stream grpc.BidiStreamingServer[mcppb.Message, mcppb.Message]) error {
+	for {
+		message, err := stream.Recv()
+		if err != nil {
+			return nil
+		}
+		if len(message.GetId()) == 0 {
+			continue
+		}
+		s.timeouts <- message.GetTimeoutMs()
+		body, err := message.JSONRPC()
+		if err != nil {
+			return err
+		}
+		reply, err := mcppb.MessageFromJSON(answer(s.t, body))
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(reply); err != nil {
+			return err
+		}
+	}
+}
+
+func TestGRPCTransport(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	testServer := &grpcTestServer{t: t, timeouts: make(chan int64, 4)}
+	mcppb.RegisterMCPServer(server, testServer)
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	transport, err := DialGRPC("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	c := New(transport)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	info, err := c.Initialize(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "test", info.ServerInfo.Name)
+	timeout := <-testServer.timeouts
+	assert.Greater(t, timeout, int64(1000), "the request's deadline travels with it")
+	assert.LessOrEqual(t, timeout, int64(5000))
+	require.NoError(t, c.Close())
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocketTransport exchanges messages over a WebSocket connection, such
+// as the server's /ws endpoint
+type WebSocketTransport struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+}
+
+// DialWebSocket connects to a WebSocket endpoint. header may carry
+// authorization and may be nil.
+func DialWebSocket(ctx context.Context, url string, header http.Header) (*WebSocketTransport, error) {
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, url, header)
+	if resp != nil && resp.Body != nil {
+		_ = resp.Body.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", url, err)
+	}
+	return &WebSocketTransport{conn: conn}, nil
+}
+
+// Send writes a message as a text frame
+func (t *WebSocketTransport) Send(ctx context.Context, message []byte) error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = t.conn.SetWriteDeadline(deadline)
+		defer func() { _ = t.conn.SetWriteDeadline(time.Time{}) }()
+	}
+	if err := t.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
+
+// Receive reads the next frame
+func (t *WebSocketTransport) Receive() ([]byte, error) {
+	_, message, err := t.conn.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// Close sends a close frame and closes the connection
+func (t *WebSocketTransport) Close() error {
+	t.writeMu.Lock()
+	_ = t.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	t.writeMu.Unlock()
+	return t.conn.Close()
+}
@@ -0,0 +1,109 @@
+package mcppb
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// jsonrpcVersion is the JSON-RPC version of every message
+const jsonrpcVersion = "2.0"
+
+// jsonrpcMessage is the JSON form of a Message that is not a batch
+type jsonrpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *jsonrpcError   `json:"error,omitempty"`
+}
+
+// jsonrpcError is the JSON form of an Error
+type jsonrpcError struct {
+	Code    int32           `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// MessageFromJSON converts a JSON-RPC message, or a batch of them, to a Message
+func MessageFromJSON(data []byte) (*Message, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var elements []json.RawMessage
+		if err := json.Unmarshal(trimmed, &elements); err != nil {
+			return nil, fmt.Errorf("invalid JSON-RPC batch: %w", err)
+		}
+		if len(elements) == 0 {
+			return nil, errors.New("invalid JSON-RPC batch: no messages")
+		}
+		batch := make([]*Message, 0, len(elements))
+		for _, element := range elements {
+			message, err := MessageFromJSON(element)
+			if err != nil {
+				return nil, err
+			}
+			if len(message.Batch) > 0 {
+				return nil, errors.New("invalid JSON-RPC batch: batches cannot be nested")
+			}
+			batch = append(batch, message)
+		}
+		return &Message{Batch: batch}, nil
+	}
+
+	var msg jsonrpcMessage
+	if err := json.Unmarshal(trimmed, &msg); err != nil {
+		return nil, fmt.Errorf("invalid JSON-RPC message: %w", err)
+	}
+	message := &Message{Id: msg.ID, Method: msg.Method, Params: msg.Params, Result: msg.Result}
+	if msg.Error != nil {
+		message.Error = &Error{Code: msg.Error.Code, Message: msg.Error.Message, Data: msg.Error.Data}
+	}
+	return message, nil
+}
+
+// MessageOf converts a value that encodes to a JSON-RPC message, such as a
+// protocol.JSONRPCResponse, to a Message
+func MessageOf(v interface{}) (*Message, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode JSON-RPC message: %w", err)
+	}
+	return MessageFromJSON(data)
+}
+
+// JSONRPC returns the JSON-RPC encoding of the message. A response that
+// carries neither a result nor an error gets a null result.
+func (x *Message) JSONRPC() ([]byte, error) {
+	if len(x.GetBatch()) > 0 {
+		elements := make([]json.RawMessage, 0, len(x.GetBatch()))
+		for _, message := range x.GetBatch() {
+			data, err := message.JSONRPC()
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, data)
+		}
+		return json.Marshal(elements)
+	}
+
+	msg := jsonrpcMessage{
+		JSONRPC: jsonrpcVersion,
+		ID:      x.GetId(),
+		Method:  x.GetMethod(),
+		Params:  x.GetParams(),
+		Result:  x.GetResult(),
+	}
+	if e := x.GetError(); e != nil {
+		msg.Error = &jsonrpcError{Code: e.GetCode(), Message: e.GetMessage(), Data: e.GetData()}
+	}
+	if msg.Method == "" && msg.Error == nil && len(msg.Result) == 0 {
+		msg.Result = json.RawMessage("null")
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON-RPC message: id, params, result and error data must hold JSON: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,38 @@
+package mcppb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageJSONRPCRoundTrip(t *testing.T) {
+	for _, data := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"memory_read"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/progress","params":{"progress":1}}`,
+		`{"jsonrpc":"2.0","id":"a","result":{"content":[]}}`,
+		`{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"Parse error","data":"unexpected EOF"}}`,
+		`[{"jsonrpc":"2.0","id":1,"method":"ping"},{"jsonrpc":"2.0","id":2,"method":"tools/list"}]`,
+	} {
+		message, err := MessageFromJSON([]byte(data))
+		require.NoError(t, err, data)
+		encoded, err := message.JSONRPC()
+		require.NoError(t, err, data)
+		assert.JSONEq(t, data, string(encoded))
+	}
+}
+
+func TestMessageJSONRPC(t *testing.T) {
+	encoded, err := (&Message{Id: []byte("3")}).JSONRPC()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":3,"result":null}`, string(encoded), "responses always carry a result or error")
+
+	_, err = (&Message{Method: "ping", Params: []byte("{")}).JSONRPC()
+	assert.Error(t, err)
+
+	_, err = MessageFromJSON([]byte(`[[{"jsonrpc":"2.0","id":1,"method":"ping"}]]`))
+	assert.Error(t, err, "batches cannot be nested")
+	_, err = MessageFromJSON([]byte(`[]`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,93 @@
+package mcppb
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// ServiceName is the full name of the MCP service
+const ServiceName = "mcp.v1.MCP"
+
+const (
+	callMethod    = "/" + ServiceName + "/Call"
+	connectMethod = "/" + ServiceName + "/Connect"
+)
+
+// MCPServer serves the MCP service
+type MCPServer interface {
+	// Call serves one request, or a batch, and returns its response
+	Call(ctx context.Context, message *Message) (*Message, error)
+	// Connect serves a session of messages in both directions
+	Connect(stream grpc.BidiStreamingServer[Message, Message]) error
+}
+
+// RegisterMCPServer registers srv to serve the MCP service on s
+func RegisterMCPServer(s grpc.ServiceRegistrar, srv MCPServer) {
+	s.RegisterService(&serviceDesc, srv)
+}
+
+// serviceDesc describes the MCP service of api/proto/mcp/v1/mcp.proto
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*MCPServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Call", Handler: callHandler},
+	},
+	Streams: []grpc.StreamDesc{
+		{StreamName: "Connect", Handler: connectHandler, ServerStreams: true, ClientStreams: true},
+	},
+	Metadata: "api/proto/mcp/v1/mcp.proto",
+}
+
+func callHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Message)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MCPServer).Call(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: callMethod}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MCPServer).Call(ctx, req.(*Message))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func connectHandler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MCPServer).Connect(&grpc.GenericServerStream[Message, Message]{ServerStream: stream})
+}
+
+// MCPClient calls the MCP service
+type MCPClient interface {
+	// Call sends one request, or a batch, and waits for its response
+	Call(ctx context.Context, message *Message, opts ...grpc.CallOption) (*Message, error)
+	// Connect opens a session of messages in both directions
+	Connect(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[Message, Message], error)
+}
+
+type mcpClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewMCPClient creates a client of the MCP service over cc
+func NewMCPClient(cc grpc.ClientConnInterface) MCPClient {
+	return &mcpClient{cc: cc}
+}
+
+func (c *mcpClient) Call(ctx context.Context, message *Message, opts ...grpc.CallOption) (*Message, error) {
+	out := new(Message)
+	if err := c.cc.Invoke(ctx, callMethod, message, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mcpClient) Connect(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[Message, Message], error) {
+	stream, err := c.cc.NewStream(ctx, &serviceDesc.Streams[0], connectMethod, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &grpc.GenericClientStream[Message, Message]{ClientStream: stream}, nil
+}
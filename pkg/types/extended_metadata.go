@@ -96,6 +96,32 @@ const (
 	EMKeySemanticCategories = "semantic_categories"
 	EMKeyConfidenceScore    = "confidence_score"
 
+	// AI Enrichment Keys
+	EMKeyExtractedDecisions = "extracted_decisions"
+	EMKeyEnrichedBy         = "ai_enriched_by"
+
+	// Decision Extraction Keys
+	EMKeyDecisionRecords = "decision_record_ids"      // on a source chunk: decision chunks promoted from it
+	EMKeyDecisionSource  = "decision_source_chunk_id" // on a decision chunk: the chunk it was extracted from
+
+	// Deduplication Keys
+	EMKeyMergeHistory = "merge_history"  // on a canonical chunk: the near-duplicates merged into it
+	EMKeyMergedInto   = "merged_into_id" // on a merged duplicate: the canonical chunk it was merged into
+
+	// Stale Knowledge Keys
+	EMKeyStaleReferences = "stale_code_references" // files and symbols the memory mentions that no longer exist
+	EMKeyStaleCheckedAt  = "stale_checked_at"
+
+	// Solution Verification Keys
+	EMKeyVerificationStatus  = "verification_status"  // latest verdict: verified or failed
+	EMKeyVerifiedAt          = "verified_at"          // when the latest verdict was recorded
+	EMKeyVerificationHistory = "verification_history" // every verdict with its evidence links
+
+	// People Keys: IDs of the people directory's entries
+	EMKeyAuthorPersonID       = "author_person_id"        // who wrote the content, from provenance.author
+	EMKeyTaskAssigneePersonID = "task_assignee_person_id" // who the task is assigned to
+	EMKeyTaskCreatorPersonID  = "task_creator_person_id"  // who created the task
+
 	// Usage Analytics Keys
 	EMKeyAccessCount        = "access_count"
 	EMKeyLastAccessed       = "last_accessed_at"
@@ -103,6 +129,8 @@ const (
 	EMKeyEffectivenessScore = "effectiveness_score"
 	EMKeyIsObsolete         = "is_obsolete"
 	EMKeyArchivedAt         = "archived_at"
+	EMKeyArchivedBy         = "archived_by"     // what archived the memory, e.g. a decay policy
+	EMKeyUsefulnessHint     = "usefulness_hint" // 0-1 endorsement of imported content, e.g. from reactions
 )
 
 // Client types
@@ -0,0 +1,84 @@
+package types
+
+// MemoryClass separates memories by how long they stay useful. Episodic
+// memories log what happened in a session, semantic memories hold distilled
+// facts and decisions, and procedural memories hold how-tos. Classes differ
+// in retention, search weighting and promotion: episodic memories are
+// consolidated into semantic ones before they expire.
+type MemoryClass string
+
+const (
+	// MemoryClassEpisodic holds session logs: problems, discussions, changes
+	MemoryClassEpisodic MemoryClass = "episodic"
+	// MemoryClassSemantic holds distilled facts and decisions
+	MemoryClassSemantic MemoryClass = "semantic"
+	// MemoryClassProcedural holds how-tos and repeatable solutions
+	MemoryClassProcedural MemoryClass = "procedural"
+)
+
+// MemoryClasses lists every memory class
+var MemoryClasses = []MemoryClass{MemoryClassEpisodic, MemoryClassSemantic, MemoryClassProcedural}
+
+// Valid returns true if the memory class is valid
+func (mc MemoryClass) Valid() bool {
+	switch mc {
+	case MemoryClassEpisodic, MemoryClassSemantic, MemoryClassProcedural:
+		return true
+	}
+	return false
+}
+
+// DefaultMemoryClass is the class of a chunk type's memories unless one was
+// set when storing them
+func DefaultMemoryClass(chunkType ChunkType) MemoryClass {
+	switch chunkType {
+	case ChunkTypeArchitectureDecision, ChunkTypeAnalysis, ChunkTypeVerification:
+		return MemoryClassSemantic
+	case ChunkTypeSolution:
+		return MemoryClassProcedural
+	default:
+		return MemoryClassEpisodic
+	}
+}
+
+// ChunkTypesOfClass lists the chunk types whose default class is one of
+// classes, for stores that filter chunks stored without a class by type
+func ChunkTypesOfClass(classes []MemoryClass) []ChunkType {
+	all := []ChunkType{
+		ChunkTypeProblem, ChunkTypeSolution, ChunkTypeCodeChange, ChunkTypeDiscussion,
+		ChunkTypeArchitectureDecision, ChunkTypeSessionSummary, ChunkTypeAnalysis,
+		ChunkTypeVerification, ChunkTypeQuestion, ChunkTypeTask, ChunkTypeTaskUpdate,
+		ChunkTypeTaskProgress,
+	}
+	var chunkTypes []ChunkType
+	for _, chunkType := range all {
+		if HasMemoryClass(classes, DefaultMemoryClass(chunkType)) {
+			chunkTypes = append(chunkTypes, chunkType)
+		}
+	}
+	return chunkTypes
+}
+
+// HasMemoryClass reports whether class is among classes
+func HasMemoryClass(classes []MemoryClass, class MemoryClass) bool {
+	for _, c := range classes {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// Class returns the chunk's memory class, defaulting by chunk type
+func (cc *ConversationChunk) Class() MemoryClass {
+	if cc.Metadata.MemoryClass != "" {
+		return cc.Metadata.MemoryClass
+	}
+	return DefaultMemoryClass(cc.Type)
+}
+
+// IsPromoted reports whether an episodic chunk was consolidated into a
+// semantic memory
+func (cc *ConversationChunk) IsPromoted() bool {
+	return cc.Metadata.PromotedTo != ""
+}
@@ -0,0 +1,37 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConversationChunk_Class(t *testing.T) {
+	chunk := &ConversationChunk{Type: ChunkTypeDiscussion}
+	assert.Equal(t, MemoryClassEpisodic, chunk.Class())
+	chunk.Type = ChunkTypeArchitectureDecision
+	assert.Equal(t, MemoryClassSemantic, chunk.Class())
+	chunk.Type = ChunkTypeSolution
+	assert.Equal(t, MemoryClassProcedural, chunk.Class())
+
+	chunk.Metadata.MemoryClass = MemoryClassEpisodic
+	assert.Equal(t, MemoryClassEpisodic, chunk.Class(), "an explicit class overrides the type's default")
+}
+
+func TestChunkTypesOfClass(t *testing.T) {
+	assert.Equal(t, []ChunkType{ChunkTypeSolution}, ChunkTypesOfClass([]MemoryClass{MemoryClassProcedural}))
+	assert.Equal(t, []ChunkType{ChunkTypeArchitectureDecision, ChunkTypeAnalysis, ChunkTypeVerification},
+		ChunkTypesOfClass([]MemoryClass{MemoryClassSemantic}))
+	assert.Len(t, ChunkTypesOfClass(MemoryClasses), 12)
+}
+
+func TestMemoryClass_Validate(t *testing.T) {
+	assert.NoError(t, (&ChunkMetadata{Outcome: OutcomeSuccess, Difficulty: DifficultySimple, MemoryClass: MemoryClassProcedural}).Validate())
+	assert.Error(t, (&ChunkMetadata{Outcome: OutcomeSuccess, Difficulty: DifficultySimple, MemoryClass: "working"}).Validate())
+
+	query := NewMemoryQuery("auth")
+	query.Classes = []MemoryClass{MemoryClassSemantic}
+	assert.NoError(t, query.Validate())
+	query.Classes = []MemoryClass{"long_term"}
+	assert.Error(t, query.Validate())
+}
@@ -0,0 +1,51 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// MergeRecord is one near-duplicate chunk merged into a canonical chunk
+type MergeRecord struct {
+	ChunkID    string    `json:"chunk_id"`
+	SessionID  string    `json:"session_id,omitempty"`
+	Summary    string    `json:"summary,omitempty"`
+	Similarity float64   `json:"similarity"`
+	StoredAt   time.Time `json:"stored_at"`
+	MergedAt   time.Time `json:"merged_at"`
+}
+
+// MergeHistory returns the duplicates merged into the chunk, oldest merge first
+func (cc *ConversationChunk) MergeHistory() []MergeRecord {
+	raw, ok := cc.Metadata.ExtendedMetadata[EMKeyMergeHistory]
+	if !ok {
+		return nil
+	}
+	// Stored history comes back from JSON as generic maps
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var history []MergeRecord
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil
+	}
+	return history
+}
+
+// RecordMerge appends merged duplicates to the chunk's merge history
+func (cc *ConversationChunk) RecordMerge(records ...MergeRecord) {
+	history := append(cc.MergeHistory(), records...)
+
+	if cc.Metadata.ExtendedMetadata == nil {
+		cc.Metadata.ExtendedMetadata = make(map[string]interface{})
+	}
+	cc.Metadata.ExtendedMetadata[EMKeyMergeHistory] = history
+}
+
+// MergedInto returns the ID of the canonical chunk the chunk was merged into,
+// or "" when it was not merged
+func (cc *ConversationChunk) MergedInto() string {
+	id, _ := cc.Metadata.ExtendedMetadata[EMKeyMergedInto].(string)
+	return id
+}
@@ -0,0 +1,91 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+)
+
+// Well-known provenance source systems
+const (
+	SourceSystemMCP     = "mcp"
+	SourceSystemGit     = "git"
+	SourceSystemImport  = "import"
+	SourceSystemCLI     = "cli"
+	SourceSystemUnknown = "unknown"
+)
+
+// commitSHAPattern accepts abbreviated and full git object names
+var commitSHAPattern = regexp.MustCompile(`^[0-9a-f]{7,64}$`)
+
+// Provenance records where a chunk came from and what captured it
+type Provenance struct {
+	SourceSystem       string `json:"source_system,omitempty"` // mcp, git, import, cli, or an external system name
+	SourceURL          string `json:"source_url,omitempty"`
+	CommitSHA          string `json:"commit_sha,omitempty"`
+	Author             string `json:"author,omitempty"`
+	CaptureTool        string `json:"capture_tool,omitempty"`
+	CaptureToolVersion string `json:"capture_tool_version,omitempty"`
+}
+
+// Validate checks the format of URL and commit fields
+func (p *Provenance) Validate() error {
+	if p.SourceURL != "" {
+		u, err := url.Parse(p.SourceURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid provenance source_url: %q", p.SourceURL)
+		}
+	}
+	if p.CommitSHA != "" && !commitSHAPattern.MatchString(p.CommitSHA) {
+		return fmt.Errorf("invalid provenance commit_sha: %q (expected 7-64 lowercase hex characters)", p.CommitSHA)
+	}
+	return nil
+}
+
+// IsEmpty returns true if no provenance field is set
+func (p *Provenance) IsEmpty() bool {
+	return p == nil || *p == Provenance{}
+}
+
+// ProvenanceFromMap builds provenance from a JSON object such as a tool argument.
+// Unknown keys and non-string values are ignored.
+func ProvenanceFromMap(m map[string]interface{}) *Provenance {
+	get := func(key string) string {
+		s, _ := m[key].(string)
+		return s
+	}
+	p := &Provenance{
+		SourceSystem:       get("source_system"),
+		SourceURL:          get("source_url"),
+		CommitSHA:          get("commit_sha"),
+		Author:             get("author"),
+		CaptureTool:        get("capture_tool"),
+		CaptureToolVersion: get("capture_tool_version"),
+	}
+	if p.IsEmpty() {
+		return nil
+	}
+	return p
+}
+
+// ProvenanceFilter restricts search results by provenance. Empty fields match anything.
+type ProvenanceFilter struct {
+	SourceSystem string `json:"source_system,omitempty"`
+	CommitSHA    string `json:"commit_sha,omitempty"`
+	Author       string `json:"author,omitempty"`
+	CaptureTool  string `json:"capture_tool,omitempty"`
+}
+
+// Matches reports whether provenance satisfies every set filter field
+func (f *ProvenanceFilter) Matches(p *Provenance) bool {
+	if f == nil {
+		return true
+	}
+	if p == nil {
+		p = &Provenance{}
+	}
+	return (f.SourceSystem == "" || f.SourceSystem == p.SourceSystem) &&
+		(f.CommitSHA == "" || f.CommitSHA == p.CommitSHA) &&
+		(f.Author == "" || f.Author == p.Author) &&
+		(f.CaptureTool == "" || f.CaptureTool == p.CaptureTool)
+}
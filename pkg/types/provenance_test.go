@@ -0,0 +1,51 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvenance_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       Provenance
+		wantErr bool
+	}{
+		{"empty", Provenance{}, false},
+		{"full", Provenance{SourceSystem: SourceSystemGit, SourceURL: "https://github.com/acme/api/commit/abc1234", CommitSHA: "abc1234", Author: "dev"}, false},
+		{"relative url", Provenance{SourceURL: "/commit/abc1234"}, true},
+		{"short sha", Provenance{CommitSHA: "abc12"}, true},
+		{"uppercase sha", Provenance{CommitSHA: "ABC1234"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.p.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestProvenanceFromMap(t *testing.T) {
+	assert.Nil(t, ProvenanceFromMap(map[string]interface{}{"unknown": "x", "author": 42}))
+
+	p := ProvenanceFromMap(map[string]interface{}{"source_system": "git", "commit_sha": "abc1234"})
+	require.NotNil(t, p)
+	assert.Equal(t, SourceSystemGit, p.SourceSystem)
+	assert.Equal(t, "abc1234", p.CommitSHA)
+}
+
+func TestProvenanceFilter_Matches(t *testing.T) {
+	p := &Provenance{SourceSystem: SourceSystemGit, Author: "dev", CommitSHA: "abc1234"}
+
+	assert.True(t, (*ProvenanceFilter)(nil).Matches(p))
+	assert.True(t, (&ProvenanceFilter{SourceSystem: SourceSystemGit, Author: "dev"}).Matches(p))
+	assert.False(t, (&ProvenanceFilter{SourceSystem: SourceSystemImport}).Matches(p))
+	assert.False(t, (&ProvenanceFilter{Author: "dev"}).Matches(nil))
+}
@@ -0,0 +1,107 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Directionality describes whether a relation reads the same in both directions
+type Directionality string
+
+const (
+	// DirectionalityDirected relations read differently from each end (A led_to B)
+	DirectionalityDirected Directionality = "directed"
+	// DirectionalitySymmetric relations read the same from both ends (A related_to B)
+	DirectionalitySymmetric Directionality = "symmetric"
+)
+
+// Valid returns true if the directionality is known
+func (d Directionality) Valid() bool {
+	return d == DirectionalityDirected || d == DirectionalitySymmetric
+}
+
+// relationTypeNamePattern restricts relation type names to snake_case identifiers
+var relationTypeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,47}$`)
+
+// WellFormed returns true if the relation type is a syntactically valid name,
+// whether built in or defined by a project
+func (rt RelationType) WellFormed() bool {
+	return relationTypeNamePattern.MatchString(string(rt))
+}
+
+// RelationTypeDefinition describes a relation type in the taxonomy
+type RelationTypeDefinition struct {
+	Name           RelationType   `json:"name"`
+	Description    string         `json:"description"`
+	Directionality Directionality `json:"directionality"`
+	Inverse        RelationType   `json:"inverse,omitempty"` // directed types only
+	BuiltIn        bool           `json:"built_in"`
+	Project        string         `json:"project,omitempty"` // empty for built-in types
+	CreatedAt      *time.Time     `json:"created_at,omitempty"`
+}
+
+// Validate checks a custom relation type definition
+func (d *RelationTypeDefinition) Validate() error {
+	if !d.Name.WellFormed() {
+		return fmt.Errorf("invalid relation type name %q: use 2-48 lowercase letters, digits or underscores, starting with a letter", d.Name)
+	}
+	if d.Name.Valid() {
+		return fmt.Errorf("relation type %q is built in and cannot be redefined", d.Name)
+	}
+	if d.Description == "" {
+		return errors.New("relation type description is required")
+	}
+	if !d.Directionality.Valid() {
+		return fmt.Errorf("invalid directionality %q (valid: directed, symmetric)", d.Directionality)
+	}
+	if d.Inverse != "" {
+		if d.Directionality == DirectionalitySymmetric {
+			return errors.New("symmetric relation types cannot declare an inverse")
+		}
+		if !d.Inverse.WellFormed() {
+			return fmt.Errorf("invalid inverse relation type name %q", d.Inverse)
+		}
+	}
+	return nil
+}
+
+// relationTypeDescriptions documents the built-in relation types
+var relationTypeDescriptions = map[RelationType]string{
+	RelationLedTo:         "One chunk led to another, e.g. a problem led to a solution",
+	RelationSolvedBy:      "A problem was solved by another chunk",
+	RelationDependsOn:     "One chunk depends on another",
+	RelationEnables:       "One chunk enables another, e.g. a decision enables a feature",
+	RelationImplements:    "One chunk implements a design or specification",
+	RelationConflictsWith: "Two chunks are in conflict",
+	RelationSupersedes:    "A newer chunk supersedes an older one",
+	RelationRelatedTo:     "General relation between chunks",
+	RelationFollowsUp:     "One chunk follows up on another discussion",
+	RelationPrecedes:      "One chunk temporally precedes another",
+	RelationLearnedFrom:   "Knowledge was derived from another chunk",
+	RelationTeaches:       "One chunk teaches a concept used by another",
+	RelationExemplifes:    "One chunk is an example of a pattern",
+	RelationReferencesBy:  "One chunk is referenced by another",
+	RelationReferences:    "One chunk references another",
+}
+
+// BuiltInRelationTypes returns the definitions of all built-in relation types
+func BuiltInRelationTypes() []RelationTypeDefinition {
+	definitions := make([]RelationTypeDefinition, 0, len(AllValidRelationTypes()))
+	for _, rt := range AllValidRelationTypes() {
+		definition := RelationTypeDefinition{
+			Name:           rt,
+			Description:    relationTypeDescriptions[rt],
+			Directionality: DirectionalityDirected,
+			BuiltIn:        true,
+		}
+		if rt.IsSymmetric() {
+			definition.Directionality = DirectionalitySymmetric
+		} else if inverse, ok := rt.getBidirectionalInverse(); ok {
+			definition.Inverse = inverse
+		}
+		definitions = append(definitions, definition)
+	}
+	return definitions
+}
@@ -177,7 +177,8 @@ func NewMemoryRelationship(sourceID, targetID string, relationType RelationType,
 	if sourceID == targetID {
 		return nil, errors.New("source and target chunk IDs cannot be the same")
 	}
-	if !relationType.Valid() {
+	// Project-defined types are checked against the taxonomy by callers
+	if !relationType.WellFormed() {
 		return nil, fmt.Errorf("invalid relation type: %s", relationType)
 	}
 	if confidence < 0 || confidence > 1 {
@@ -187,6 +187,28 @@ func (r Recency) Valid() bool {
 	return false
 }
 
+// SearchMode selects how a search ranks chunks
+type SearchMode string
+
+const (
+	// SearchModeVector ranks chunks by vector similarity to the query
+	SearchModeVector SearchMode = "vector"
+	// SearchModeKeyword ranks chunks by BM25 keyword score, which finds exact
+	// identifiers such as error codes that embeddings blur
+	SearchModeKeyword SearchMode = "keyword"
+	// SearchModeHybrid runs both and fuses their rankings
+	SearchModeHybrid SearchMode = "hybrid"
+)
+
+// Valid returns true if the search mode is valid
+func (m SearchMode) Valid() bool {
+	switch m {
+	case SearchModeVector, SearchModeKeyword, SearchModeHybrid:
+		return true
+	}
+	return false
+}
+
 // ChunkMetadata contains metadata about a conversation chunk
 type ChunkMetadata struct {
 	Repository       string                 `json:"repository,omitempty"`
@@ -212,6 +234,26 @@ type ChunkMetadata struct {
 	TaskBlocks       []string    `json:"task_blocks,omitempty"`       // IDs of chunks this task blocks
 	TaskEstimate     *int        `json:"task_estimate,omitempty"`     // estimated time in minutes
 	TaskProgress     *int        `json:"task_progress,omitempty"`     // percentage 0-100
+
+	// Soft delete: set when the chunk is moved to the trash, cleared on restore
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	// Optimistic concurrency: incremented on every versioned content update
+	Version int64 `json:"version,omitempty"`
+
+	// Where the chunk came from and what captured it
+	Provenance *Provenance `json:"provenance,omitempty"`
+
+	// Passages: long chunks are also indexed as passages, chunks of their own
+	// that hold one section of the content and point back at their parent
+	ParentChunkID string `json:"parent_chunk_id,omitempty"`
+	PassageIndex  int    `json:"passage_index,omitempty"`
+	PassageCount  int    `json:"passage_count,omitempty"` // set on the parent
+
+	// Memory class: empty means the chunk type's default class. PromotedTo
+	// is set on episodic chunks consolidated into a semantic memory.
+	MemoryClass MemoryClass `json:"memory_class,omitempty"`
+	PromotedTo  string      `json:"promoted_to,omitempty"`
 }
 
 // Validate checks if the metadata is valid
@@ -245,6 +287,16 @@ func (cm *ChunkMetadata) Validate() error {
 		}
 	}
 
+	if cm.Provenance != nil {
+		if err := cm.Provenance.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if cm.MemoryClass != "" && !cm.MemoryClass.Valid() {
+		return fmt.Errorf("invalid memory class: %s", cm.MemoryClass)
+	}
+
 	return nil
 }
 
@@ -309,6 +361,23 @@ func (cc *ConversationChunk) Validate() error {
 	return cc.Metadata.Validate()
 }
 
+// IsDeleted reports whether the chunk is in the trash
+func (cc *ConversationChunk) IsDeleted() bool {
+	return cc.Metadata.DeletedAt != nil
+}
+
+// IsArchived reports whether the chunk is in the archive tier, which default
+// searches leave out
+func (cc *ConversationChunk) IsArchived() bool {
+	_, ok := cc.Metadata.ExtendedMetadata[EMKeyArchivedAt]
+	return ok
+}
+
+// IsPassage reports whether the chunk is a passage of a longer parent chunk
+func (cc *ConversationChunk) IsPassage() bool {
+	return cc.Metadata.ParentChunkID != ""
+}
+
 // ProjectContext represents context about a project
 type ProjectContext struct {
 	Repository             string    `json:"repository"`
@@ -353,6 +422,14 @@ type MemoryQuery struct {
 	Types             []ChunkType `json:"types,omitempty"`
 	MinRelevanceScore float64     `json:"min_relevance_score"`
 	Limit             int         `json:"limit,omitempty"`
+	IncludeDeleted    bool        `json:"include_deleted,omitempty"`  // Include soft-deleted chunks
+	IncludeArchived   bool        `json:"include_archived,omitempty"` // Include archived chunks
+
+	Provenance *ProvenanceFilter `json:"provenance,omitempty"`
+	Classes    []MemoryClass     `json:"classes,omitempty"` // Restrict to memory classes
+
+	// Mode selects vector, keyword or hybrid ranking; empty is vector
+	Mode SearchMode `json:"mode,omitempty"`
 }
 
 // NewMemoryQuery creates a new memory query with defaults
@@ -385,6 +462,14 @@ func (mq *MemoryQuery) Validate() error {
 			return fmt.Errorf("invalid chunk type: %s", chunkType)
 		}
 	}
+	for _, class := range mq.Classes {
+		if !class.Valid() {
+			return fmt.Errorf("invalid memory class: %s", class)
+		}
+	}
+	if mq.Mode != "" && !mq.Mode.Valid() {
+		return fmt.Errorf("invalid search mode: %s", mq.Mode)
+	}
 	return nil
 }
 
@@ -416,12 +501,13 @@ type ConfidenceMetrics struct {
 
 // QualityMetrics represents quality metrics for a memory chunk
 type QualityMetrics struct {
-	Completeness   float64    `json:"completeness"`    // How complete is this memory (0.0-1.0)
-	Clarity        float64    `json:"clarity"`         // How clear/unambiguous (0.0-1.0)
-	RelevanceDecay float64    `json:"relevance_decay"` // How much relevance has decayed (0.0-1.0)
-	FreshnessScore float64    `json:"freshness_score"` // How fresh/current (0.0-1.0)
-	UsageScore     float64    `json:"usage_score"`     // Based on access patterns (0.0-1.0)
-	OverallQuality float64    `json:"overall_quality"` // Weighted combination (0.0-1.0)
+	Completeness   float64    `json:"completeness"`        // How complete is this memory (0.0-1.0)
+	Clarity        float64    `json:"clarity"`             // How clear/unambiguous (0.0-1.0)
+	RelevanceDecay float64    `json:"relevance_decay"`     // How much relevance has decayed (0.0-1.0)
+	FreshnessScore float64    `json:"freshness_score"`     // How fresh/current (0.0-1.0)
+	UsageScore     float64    `json:"usage_score"`         // Based on access patterns (0.0-1.0)
+	OverallQuality float64    `json:"overall_quality"`     // Weighted combination (0.0-1.0)
+	Citations      int        `json:"citations,omitempty"` // Memories referring to this one
 	LastCalculated *time.Time `json:"last_calculated,omitempty"`
 }
 
@@ -478,8 +564,24 @@ func (cc *ChunkingContext) HasCompletedTodos() bool {
 
 // SearchResult represents a search result with relevance score
 type SearchResult struct {
-	Chunk ConversationChunk `json:"chunk"`
-	Score float64           `json:"score"`
+	Chunk     ConversationChunk `json:"chunk"`
+	Score     float64           `json:"score"`
+	Highlight *SearchHighlight  `json:"highlight,omitempty"`
+}
+
+// SearchHighlight shows why a result matched: the best-matching passage with
+// surrounding context and the positions of matched query terms
+type SearchHighlight struct {
+	Snippet      string          `json:"snippet"`
+	Highlighted  string          `json:"highlighted"` // snippet with matches wrapped in **
+	MatchedTerms []string        `json:"matched_terms"`
+	Spans        []HighlightSpan `json:"spans,omitempty"`
+}
+
+// HighlightSpan is a matched term's byte range within a snippet
+type HighlightSpan struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
 }
 
 // SearchResults represents a collection of search results
@@ -415,6 +415,13 @@ func TestMemoryQuery_Validate(t *testing.T) {
 		}
 		assert.Error(t, query.Validate())
 	})
+
+	t.Run("search modes", func(t *testing.T) {
+		query := &MemoryQuery{Query: "test", Recency: RecencyRecent, Mode: SearchModeHybrid}
+		assert.NoError(t, query.Validate())
+		query.Mode = SearchMode("fuzzy")
+		assert.Error(t, query.Validate())
+	})
 }
 
 func TestChunkingContext_Validate(t *testing.T) {
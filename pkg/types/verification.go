@@ -0,0 +1,80 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Verification statuses of a stored solution
+const (
+	VerificationVerified = "verified"
+	VerificationFailed   = "failed"
+)
+
+// VerificationRecord is one verdict on whether a stored solution worked
+type VerificationRecord struct {
+	Status string `json:"status"`
+	// Evidence links to what showed the verdict, such as a CI run, commit or ticket
+	Evidence []string  `json:"evidence,omitempty"`
+	Note     string    `json:"note,omitempty"`
+	At       time.Time `json:"at"`
+}
+
+// Validate checks the status of a verification record
+func (vr *VerificationRecord) Validate() error {
+	if vr.Status != VerificationVerified && vr.Status != VerificationFailed {
+		return fmt.Errorf("invalid verification status: %q (expected %s or %s)", vr.Status, VerificationVerified, VerificationFailed)
+	}
+	for _, link := range vr.Evidence {
+		if link == "" {
+			return errors.New("verification evidence links must not be empty")
+		}
+	}
+	return nil
+}
+
+// VerificationStatus returns the latest verification status of the chunk, or
+// "" when it was never verified
+func (cc *ConversationChunk) VerificationStatus() string {
+	status, _ := cc.Metadata.ExtendedMetadata[EMKeyVerificationStatus].(string)
+	return status
+}
+
+// VerificationHistory returns the verdicts recorded on the chunk, oldest first
+func (cc *ConversationChunk) VerificationHistory() []VerificationRecord {
+	raw, ok := cc.Metadata.ExtendedMetadata[EMKeyVerificationHistory]
+	if !ok {
+		return nil
+	}
+	// Stored history comes back from JSON as generic maps
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var history []VerificationRecord
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil
+	}
+	return history
+}
+
+// RecordVerification appends a verdict to the chunk's history, makes it the
+// current verification status and sets the chunk's outcome to match
+func (cc *ConversationChunk) RecordVerification(record VerificationRecord) {
+	history := append(cc.VerificationHistory(), record)
+
+	if cc.Metadata.ExtendedMetadata == nil {
+		cc.Metadata.ExtendedMetadata = make(map[string]interface{})
+	}
+	cc.Metadata.ExtendedMetadata[EMKeyVerificationStatus] = record.Status
+	cc.Metadata.ExtendedMetadata[EMKeyVerifiedAt] = record.At.UTC().Format(time.RFC3339)
+	cc.Metadata.ExtendedMetadata[EMKeyVerificationHistory] = history
+
+	if record.Status == VerificationVerified {
+		cc.Metadata.Outcome = OutcomeSuccess
+	} else {
+		cc.Metadata.Outcome = OutcomeFailed
+	}
+}
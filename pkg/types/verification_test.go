@@ -0,0 +1,34 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordVerification(t *testing.T) {
+	chunk := &ConversationChunk{Metadata: ChunkMetadata{Outcome: OutcomeInProgress}}
+	assert.Empty(t, chunk.VerificationStatus())
+	assert.Nil(t, chunk.VerificationHistory())
+
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	chunk.RecordVerification(VerificationRecord{Status: VerificationVerified, Evidence: []string{"https://ci.example.com/runs/1"}, At: at})
+	assert.Equal(t, VerificationVerified, chunk.VerificationStatus())
+	assert.Equal(t, OutcomeSuccess, chunk.Metadata.Outcome)
+	assert.Equal(t, "2026-03-01T12:00:00Z", chunk.Metadata.ExtendedMetadata[EMKeyVerifiedAt])
+
+	chunk.RecordVerification(VerificationRecord{Status: VerificationFailed, At: at.Add(time.Hour)})
+	assert.Equal(t, OutcomeFailed, chunk.Metadata.Outcome)
+	history := chunk.VerificationHistory()
+	require.Len(t, history, 2)
+	assert.Equal(t, []string{"https://ci.example.com/runs/1"}, history[0].Evidence)
+	assert.Equal(t, VerificationFailed, history[1].Status)
+}
+
+func TestVerificationRecord_Validate(t *testing.T) {
+	assert.NoError(t, (&VerificationRecord{Status: VerificationFailed}).Validate())
+	assert.Error(t, (&VerificationRecord{Status: "done"}).Validate())
+	assert.Error(t, (&VerificationRecord{Status: VerificationVerified, Evidence: []string{""}}).Validate())
+}